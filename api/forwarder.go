@@ -2,9 +2,11 @@ package api
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
@@ -56,6 +58,13 @@ func OwnerIDFromBackupTarget(m *manager.VolumeManager) func(req *http.Request) (
 	}
 }
 
+// NodeIDFromNode returns the node in the request path
+func NodeIDFromNode() func(req *http.Request) (string, error) {
+	return func(req *http.Request) (string, error) {
+		return mux.Vars(req)["name"], nil
+	}
+}
+
 // NodeHasDefaultEngineImage picks a node that is ready and has default engine image deployed.
 // To prevent the repeatedly forwarding the request around, prioritize the current node if it meets the requirement.
 func NodeHasDefaultEngineImage(m *manager.VolumeManager) func(req *http.Request) (string, error) {
@@ -205,7 +214,7 @@ func UploadParametersForBackingImage(m *manager.VolumeManager) func(req *http.Re
 		if bids.Status.CurrentState != longhorn.BackingImageStatePending {
 			return nil, fmt.Errorf("upload server for backing image %s has not been initiated", name)
 		}
-		return map[string]string{ParameterKeyAddress: fmt.Sprintf("%s:%d", pod.Status.PodIP, engineapi.BackingImageDataSourceDefaultPort)}, nil
+		return map[string]string{ParameterKeyAddress: net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(engineapi.BackingImageDataSourceDefaultPort))}, nil
 	}
 }
 
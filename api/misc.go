@@ -53,6 +53,19 @@ func (s *Server) NodeTagList(rw http.ResponseWriter, req *http.Request) error {
 	return nil
 }
 
+// BackoffList returns the retry backoff state of the controllers running on the node serving the request.
+// The query parameters "name" and "key" narrow the result down to a backoff and a key, e.g. a volume name.
+func (s *Server) BackoffList(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	name := req.URL.Query().Get("name")
+	key := req.URL.Query().Get("key")
+	entries := s.backoffs.List(name, key)
+
+	apiContext.Write(toBackoffCollection(entries, s.m.GetCurrentNodeID()))
+	return nil
+}
+
 func (s *Server) InstanceManagerGet(rw http.ResponseWriter, req *http.Request) error {
 	id := mux.Vars(req)["name"]
 	apiContext := api.GetApiContext(req)
@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/longhorn/longhorn-manager/controller"
+	"github.com/longhorn/longhorn-manager/csi/journal"
 	"github.com/longhorn/longhorn-manager/datastore"
 	"github.com/longhorn/longhorn-manager/engineapi"
 	"github.com/longhorn/longhorn-manager/manager"
@@ -35,7 +37,10 @@ type Volume struct {
 	DisableFrontend             bool                                   `json:"disableFrontend"`
 	FromBackup                  string                                 `json:"fromBackup"`
 	RestoreVolumeRecurringJob   longhorn.RestoreVolumeRecurringJobType `json:"restoreVolumeRecurringJob"`
+	RestoreCredential           map[string]string                      `json:"restoreCredential"`
+	AdoptWarmPoolVolume         bool                                   `json:"adoptWarmPoolVolume"`
 	DataSource                  longhorn.VolumeDataSource              `json:"dataSource"`
+	CloneMode                   longhorn.VolumeCloneMode               `json:"cloneMode"`
 	DataLocality                longhorn.DataLocality                  `json:"dataLocality"`
 	StaleReplicaTimeout         int                                    `json:"staleReplicaTimeout"`
 	State                       longhorn.VolumeState                   `json:"state"`
@@ -60,9 +65,24 @@ type Volume struct {
 	DataEngine                  longhorn.DataEngineType                `json:"dataEngine"`
 	SnapshotMaxCount            int                                    `json:"snapshotMaxCount"`
 	SnapshotMaxSize             string                                 `json:"snapshotMaxSize"`
+	SnapshotMaxChainLength      int                                    `json:"snapshotMaxChainLength"`
 	FreezeFilesystemForSnapshot longhorn.FreezeFilesystemForSnapshot   `json:"freezeFilesystemForSnapshot"`
+	TrimFilesystemOnUnstage     bool                                   `json:"trimFilesystemOnUnstage"`
+	RebuildPriority             int                                    `json:"rebuildPriority"`
 	BackupTargetName            string                                 `json:"backupTargetName"`
 
+	SnapshotRetentionKeepLast int    `json:"snapshotRetentionKeepLast"`
+	SnapshotRetentionMaxAge   string `json:"snapshotRetentionMaxAge"`
+	SnapshotRetentionMaxSize  string `json:"snapshotRetentionMaxSize"`
+
+	IOPauseDeadline string `json:"ioPauseDeadline"`
+
+	AutoResizeThresholdPercentage int    `json:"autoResizeThresholdPercentage"`
+	AutoResizeIncrement           string `json:"autoResizeIncrement"`
+	AutoResizeMaxSize             string `json:"autoResizeMaxSize"`
+
+	BackupMaxAge int `json:"backupMaxAge"`
+
 	DiskSelector         []string                      `json:"diskSelector"`
 	NodeSelector         []string                      `json:"nodeSelector"`
 	RecurringJobSelector []longhorn.VolumeRecurringJob `json:"recurringJobSelector"`
@@ -73,7 +93,17 @@ type Volume struct {
 	Conditions       map[string]longhorn.Condition `json:"conditions"`
 	KubernetesStatus longhorn.KubernetesStatus     `json:"kubernetesStatus"`
 	CloneStatus      longhorn.VolumeCloneStatus    `json:"cloneStatus"`
-	Ready            bool                          `json:"ready"`
+	CloneTargets     []string                      `json:"cloneTargets"`
+
+	PassphraseRotationStatus longhorn.VolumePassphraseRotationStatus `json:"passphraseRotationStatus"`
+	DiskTagMigrationStatus   longhorn.VolumeDiskTagMigrationStatus   `json:"diskTagMigrationStatus"`
+
+	SalvageCandidates []longhorn.SalvageCandidate `json:"salvageCandidates"`
+	SalvageDecision   string                      `json:"salvageDecision"`
+
+	LastFilesystemTrimAt string `json:"lastFilesystemTrimAt"`
+
+	Ready bool `json:"ready"`
 
 	AccessMode    longhorn.AccessMode        `json:"accessMode"`
 	ShareEndpoint string                     `json:"shareEndpoint"`
@@ -100,6 +130,15 @@ type Snapshot struct {
 }
 
 // SnapshotCR struct is used for the snapshotCR* actions
+type SnapshotHashStatus struct {
+	client.Resource
+	Replica           string `json:"replica"`
+	State             string `json:"state"`
+	Checksum          string `json:"checksum"`
+	Error             string `json:"error"`
+	SilentlyCorrupted bool   `json:"silentlyCorrupted"`
+}
+
 type SnapshotCR struct {
 	client.Resource
 	Name           string `json:"name"`
@@ -224,6 +263,7 @@ type Controller struct {
 	LastExpansionError               string `json:"lastExpansionError"`
 	LastExpansionFailedAt            string `json:"lastExpansionFailedAt"`
 	UnmapMarkSnapChainRemovedEnabled bool   `json:"unmapMarkSnapChainRemovedEnabled"`
+	IOPaused                         bool   `json:"ioPaused"`
 }
 
 type Replica struct {
@@ -235,6 +275,9 @@ type Replica struct {
 	Mode       string `json:"mode"`
 	FailedAt   string `json:"failedAt"`
 	DataEngine string `json:"dataEngine"`
+
+	RebuildPaused   bool `json:"rebuildPaused"`
+	RebuildPriority int  `json:"rebuildPriority"`
 }
 
 type Attachment struct {
@@ -313,9 +356,10 @@ type DetachInput struct {
 }
 
 type SnapshotInput struct {
-	Name       string            `json:"name"`
-	Labels     map[string]string `json:"labels"`
-	BackupMode string            `json:"backupMode"`
+	Name             string            `json:"name"`
+	Labels           map[string]string `json:"labels"`
+	BackupMode       string            `json:"backupMode"`
+	BackupTargetName string            `json:"backupTargetName"`
 }
 
 type SnapshotCRInput struct {
@@ -331,10 +375,23 @@ type ReplicaRemoveInput struct {
 	Name string `json:"name"`
 }
 
+type ReplicaRebuildInput struct {
+	Name string `json:"name"`
+}
+
+type UpdateReplicaRebuildPriorityInput struct {
+	Name            string `json:"name"`
+	RebuildPriority int    `json:"rebuildPriority"`
+}
+
 type SalvageInput struct {
 	Names []string `json:"names"`
 }
 
+type PickSalvageCandidateInput struct {
+	ReplicaName string `json:"replicaName"`
+}
+
 type EngineUpgradeInput struct {
 	Image string `json:"image"`
 }
@@ -363,6 +420,49 @@ type UpdateSnapshotMaxCountInput struct {
 	SnapshotMaxCount int `json:"snapshotMaxCount"`
 }
 
+type UpdateRebuildPriorityInput struct {
+	RebuildPriority int `json:"rebuildPriority"`
+}
+
+type UpdateSnapshotRetentionPolicyInput struct {
+	SnapshotRetentionKeepLast int    `json:"snapshotRetentionKeepLast"`
+	SnapshotRetentionMaxAge   string `json:"snapshotRetentionMaxAge"`
+	SnapshotRetentionMaxSize  string `json:"snapshotRetentionMaxSize"`
+}
+
+type UpdateAutoResizePolicyInput struct {
+	ThresholdPercentage int    `json:"thresholdPercentage"`
+	Increment           string `json:"increment"`
+	MaxSize             string `json:"maxSize"`
+}
+
+type UpdateBackupMaxAgeInput struct {
+	BackupMaxAge int `json:"backupMaxAge"`
+}
+
+type UpdateFilesystemConditionInput struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+type PauseIOInput struct {
+	Timeout int64 `json:"timeout"`
+}
+
+type LiveMigrateInput struct {
+	NodeID string `json:"nodeID"`
+}
+
+type MigrateToDiskTagInput struct {
+	DiskSelector []string `json:"diskSelector"`
+}
+
+type UpdateSnapshotMaxChainLengthInput struct {
+	SnapshotMaxChainLength int `json:"snapshotMaxChainLength"`
+}
+
 type UpdateSnapshotMaxSizeInput struct {
 	SnapshotMaxSize string `json:"snapshotMaxSize"`
 }
@@ -411,6 +511,9 @@ type PVCreateInput struct {
 	SecretNamespace string `json:"secretNamespace"`
 
 	StorageClassName string `json:"storageClassName"`
+
+	PVCName      string `json:"pvcName"`
+	PVCNamespace string `json:"pvcNamespace"`
 }
 
 type PVCCreateInput struct {
@@ -422,23 +525,32 @@ type ActivateInput struct {
 	Frontend string `json:"frontend"`
 }
 
+type ActivateWithFinalSyncInput struct {
+	Frontend     string `json:"frontend"`
+	PVName       string `json:"pvName"`
+	FSType       string `json:"fsType"`
+	PVCName      string `json:"pvcName"`
+	PVCNamespace string `json:"pvcNamespace"`
+}
+
 type ExpandInput struct {
 	Size string `json:"size"`
 }
 
 type Node struct {
 	client.Resource
-	Name                      string                        `json:"name"`
-	Address                   string                        `json:"address"`
-	AllowScheduling           bool                          `json:"allowScheduling"`
-	EvictionRequested         bool                          `json:"evictionRequested"`
-	Disks                     map[string]DiskInfo           `json:"disks"`
-	Conditions                map[string]longhorn.Condition `json:"conditions"`
-	Tags                      []string                      `json:"tags"`
-	Region                    string                        `json:"region"`
-	Zone                      string                        `json:"zone"`
-	InstanceManagerCPURequest int                           `json:"instanceManagerCPURequest"`
-	AutoEvicting              bool                          `json:"autoEvicting"`
+	Name                         string                        `json:"name"`
+	Address                      string                        `json:"address"`
+	AllowScheduling              bool                          `json:"allowScheduling"`
+	EvictionRequested            bool                          `json:"evictionRequested"`
+	Disks                        map[string]DiskInfo           `json:"disks"`
+	Conditions                   map[string]longhorn.Condition `json:"conditions"`
+	Tags                         []string                      `json:"tags"`
+	Region                       string                        `json:"region"`
+	Zone                         string                        `json:"zone"`
+	InstanceManagerCPURequest    int                           `json:"instanceManagerCPURequest"`
+	AutoEvicting                 bool                          `json:"autoEvicting"`
+	InstanceManagerMemoryRequest int                           `json:"instanceManagerMemoryRequest"`
 }
 
 type DiskStatus struct {
@@ -449,6 +561,8 @@ type DiskStatus struct {
 	ScheduledReplica      map[string]int64              `json:"scheduledReplica"`
 	ScheduledBackingImage map[string]int64              `json:"scheduledBackingImage"`
 	DiskUUID              string                        `json:"diskUUID"`
+	IOUtilization         int                           `json:"ioUtilization"`
+	IOLatency             int64                         `json:"ioLatency"`
 }
 
 type DiskInfo struct {
@@ -520,6 +634,29 @@ type Tag struct {
 	TagType string `json:"tagType"`
 }
 
+type Backoff struct {
+	client.Resource
+	NodeID     string `json:"nodeID"`
+	Name       string `json:"name"`
+	Key        string `json:"key"`
+	Failures   int    `json:"failures"`
+	Backoff    string `json:"backoff"`
+	LastUpdate string `json:"lastUpdate"`
+}
+
+type MountJournalRecord struct {
+	client.Resource
+	Time      string   `json:"time"`
+	NodeID    string   `json:"nodeID"`
+	Volume    string   `json:"volume"`
+	Operation string   `json:"operation"`
+	Path      string   `json:"path"`
+	Options   []string `json:"options"`
+	Result    string   `json:"result"`
+	Error     string   `json:"error"`
+	Duration  string   `json:"duration"`
+}
+
 type BackupStatus struct {
 	client.Resource
 	Name      string `json:"id"`
@@ -590,11 +727,42 @@ type Orphan struct {
 	longhorn.OrphanSpec
 }
 
+type ReplicaPlacement struct {
+	client.Resource
+	Name             string                    `json:"name"`
+	Size             string                    `json:"size"`
+	NumberOfReplicas int                       `json:"numberOfReplicas"`
+	DataEngine       longhorn.DataEngineType   `json:"dataEngine"`
+	Frontend         longhorn.VolumeFrontend   `json:"frontend"`
+	AccessMode       longhorn.AccessMode       `json:"accessMode"`
+	Encrypted        bool                      `json:"encrypted"`
+	Replicas         []ReplicaPlacementReplica `json:"replicas"`
+}
+
+type ReplicaPlacementReplica struct {
+	Name              string `json:"name"`
+	NodeID            string `json:"nodeID"`
+	DiskID            string `json:"diskID"`
+	DiskPath          string `json:"diskPath"`
+	DataDirectoryName string `json:"dataDirectoryName"`
+	Healthy           bool   `json:"healthy"`
+}
+
 type VolumeRecurringJob struct {
 	client.Resource
 	longhorn.VolumeRecurringJob
 }
 
+type ForceCleanupInput struct {
+	Reason string `json:"reason"`
+}
+
+type ForceCleanupOutput struct {
+	client.Resource
+	Actions []string `json:"actions"`
+	Pending []string `json:"pending"`
+}
+
 type VolumeRecurringJobInput struct {
 	longhorn.VolumeRecurringJob
 }
@@ -619,6 +787,11 @@ type SnapshotListOutput struct {
 	Type string     `json:"type"`
 }
 
+type SnapshotHashStatusListOutput struct {
+	Data []SnapshotHashStatus `json:"data"`
+	Type string               `json:"type"`
+}
+
 type SnapshotCRListOutput struct {
 	Data []SnapshotCR `json:"data"`
 	Type string       `json:"type"`
@@ -639,12 +812,17 @@ func NewSchema() *client.Schemas {
 	schemas.AddType("backupStatus", BackupStatus{})
 	schemas.AddType("syncBackupResource", SyncBackupResource{})
 	schemas.AddType("orphan", Orphan{})
+	schemas.AddType("replicaPlacementReplica", ReplicaPlacementReplica{})
 	schemas.AddType("restoreStatus", RestoreStatus{})
 	schemas.AddType("purgeStatus", PurgeStatus{})
 	schemas.AddType("rebuildStatus", RebuildStatus{})
 	schemas.AddType("replicaRemoveInput", ReplicaRemoveInput{})
+	schemas.AddType("replicaRebuildInput", ReplicaRebuildInput{})
+	schemas.AddType("UpdateReplicaRebuildPriorityInput", UpdateReplicaRebuildPriorityInput{})
 	schemas.AddType("salvageInput", SalvageInput{})
+	schemas.AddType("pickSalvageCandidateInput", PickSalvageCandidateInput{})
 	schemas.AddType("activateInput", ActivateInput{})
+	schemas.AddType("activateWithFinalSyncInput", ActivateWithFinalSyncInput{})
 	schemas.AddType("expandInput", ExpandInput{})
 	schemas.AddType("engineUpgradeInput", EngineUpgradeInput{})
 	schemas.AddType("replica", Replica{})
@@ -657,6 +835,14 @@ func NewSchema() *client.Schemas {
 	schemas.AddType("UpdateSnapshotDataIntegrityInput", UpdateSnapshotDataIntegrityInput{})
 	schemas.AddType("UpdateSnapshotMaxCountInput", UpdateSnapshotMaxCountInput{})
 	schemas.AddType("UpdateSnapshotMaxSizeInput", UpdateSnapshotMaxSizeInput{})
+	schemas.AddType("UpdateSnapshotMaxChainLengthInput", UpdateSnapshotMaxChainLengthInput{})
+	schemas.AddType("UpdateRebuildPriorityInput", UpdateRebuildPriorityInput{})
+	schemas.AddType("UpdateSnapshotRetentionPolicyInput", UpdateSnapshotRetentionPolicyInput{})
+	schemas.AddType("UpdateAutoResizePolicyInput", UpdateAutoResizePolicyInput{})
+	schemas.AddType("UpdateBackupMaxAgeInput", UpdateBackupMaxAgeInput{})
+	schemas.AddType("UpdateFilesystemConditionInput", UpdateFilesystemConditionInput{})
+	schemas.AddType("PauseIOInput", PauseIOInput{})
+	schemas.AddType("LiveMigrateInput", LiveMigrateInput{})
 	schemas.AddType("UpdateBackupCompressionInput", UpdateBackupCompressionMethodInput{})
 	schemas.AddType("UpdateUnmapMarkSnapChainRemovedInput", UpdateUnmapMarkSnapChainRemovedInput{})
 	schemas.AddType("UpdateReplicaSoftAntiAffinityInput", UpdateReplicaSoftAntiAffinityInput{})
@@ -666,10 +852,16 @@ func NewSchema() *client.Schemas {
 	schemas.AddType("UpdateBackupTargetInput", UpdateBackupTargetInput{})
 	schemas.AddType("workloadStatus", longhorn.WorkloadStatus{})
 	schemas.AddType("cloneStatus", longhorn.VolumeCloneStatus{})
+	schemas.AddType("passphraseRotationStatus", longhorn.VolumePassphraseRotationStatus{})
+	schemas.AddType("diskTagMigrationStatus", longhorn.VolumeDiskTagMigrationStatus{})
+	schemas.AddType("salvageCandidate", longhorn.SalvageCandidate{})
+	schemas.AddType("migrateToDiskTagInput", MigrateToDiskTagInput{})
 	schemas.AddType("empty", Empty{})
 
 	schemas.AddType("volumeRecurringJob", VolumeRecurringJob{})
 	schemas.AddType("volumeRecurringJobInput", VolumeRecurringJobInput{})
+	schemas.AddType("forceCleanupInput", ForceCleanupInput{})
+	schemas.AddType("forceCleanupOutput", ForceCleanupOutput{})
 
 	schemas.AddType("PVCreateInput", PVCreateInput{})
 	schemas.AddType("PVCCreateInput", PVCCreateInput{})
@@ -687,6 +879,9 @@ func NewSchema() *client.Schemas {
 
 	schemas.AddType("tag", Tag{})
 
+	schemas.AddType("backoff", Backoff{})
+	schemas.AddType("mountJournalRecord", MountJournalRecord{})
+
 	schemas.AddType("instanceManager", InstanceManager{})
 	schemas.AddType("instanceProcess", longhorn.InstanceProcess{})
 
@@ -705,6 +900,7 @@ func NewSchema() *client.Schemas {
 	backupBackingImageSchema(schemas.AddType("backupBackingImage", BackupBackingImage{}))
 	settingSchema(schemas.AddType("setting", Setting{}))
 	recurringJobSchema(schemas.AddType("recurringJob", RecurringJob{}))
+	replicaPlacementSchema(schemas.AddType("replicaPlacement", ReplicaPlacement{}))
 	engineImageSchema(schemas.AddType("engineImage", EngineImage{}))
 	backingImageSchema(schemas.AddType("backingImage", BackingImage{}))
 	nodeSchema(schemas.AddType("node", Node{}))
@@ -718,6 +914,8 @@ func NewSchema() *client.Schemas {
 	systemBackupSchema(schemas.AddType("systemBackup", SystemBackup{}))
 	systemRestoreSchema(schemas.AddType("systemRestore", SystemRestore{}))
 	snapshotCRListOutputSchema(schemas.AddType("snapshotCRListOutput", SnapshotCRListOutput{}))
+	schemas.AddType("snapshotHashStatus", SnapshotHashStatus{})
+	snapshotHashStatusListOutputSchema(schemas.AddType("snapshotHashStatusListOutput", SnapshotHashStatusListOutput{}))
 
 	return schemas
 }
@@ -983,10 +1181,18 @@ func volumeSchema(volume *client.Schema) {
 			Input:  "salvageInput",
 			Output: "volume",
 		},
+		"pickSalvageCandidate": {
+			Input:  "pickSalvageCandidateInput",
+			Output: "volume",
+		},
 		"activate": {
 			Input:  "activateInput",
 			Output: "volume",
 		},
+		"activateWithFinalSync": {
+			Input:  "activateWithFinalSyncInput",
+			Output: "volume",
+		},
 		"expand": {
 			Input:  "expandInput",
 			Output: "volume",
@@ -994,6 +1200,13 @@ func volumeSchema(volume *client.Schema) {
 		"cancelExpansion": {
 			Output: "volume",
 		},
+		"rotatePassphrase": {
+			Output: "volume",
+		},
+		"migrateToDiskTag": {
+			Input:  "migrateToDiskTagInput",
+			Output: "volume",
+		},
 		"trimFilesystem": {
 			Output: "volume",
 		},
@@ -1024,6 +1237,14 @@ func volumeSchema(volume *client.Schema) {
 			Input:  "snapshotInput",
 			Output: "volume",
 		},
+		"snapshotHash": {
+			Input:  "snapshotInput",
+			Output: "volume",
+		},
+		"snapshotHashStatus": {
+			Input:  "snapshotInput",
+			Output: "snapshotHashStatusListOutput",
+		},
 
 		"snapshotCRCreate": {
 			Input:  "snapshotCRInput",
@@ -1084,6 +1305,45 @@ func volumeSchema(volume *client.Schema) {
 			Input: "UpdateSnapshotMaxSizeInput",
 		},
 
+		"updateSnapshotMaxChainLength": {
+			Input: "UpdateSnapshotMaxChainLengthInput",
+		},
+
+		"updateRebuildPriority": {
+			Input: "UpdateRebuildPriorityInput",
+		},
+
+		"updateSnapshotRetentionPolicy": {
+			Input: "UpdateSnapshotRetentionPolicyInput",
+		},
+
+		"updateAutoResizePolicy": {
+			Input: "UpdateAutoResizePolicyInput",
+		},
+
+		"updateBackupMaxAge": {
+			Input: "UpdateBackupMaxAgeInput",
+		},
+
+		"updateFilesystemCondition": {
+			Input:  "UpdateFilesystemConditionInput",
+			Output: "volume",
+		},
+
+		"pauseIO": {
+			Input:  "PauseIOInput",
+			Output: "volume",
+		},
+
+		"resumeIO": {
+			Output: "volume",
+		},
+
+		"liveMigrate": {
+			Input:  "LiveMigrateInput",
+			Output: "volume",
+		},
+
 		"updateBackupCompressionMethod": {
 			Input: "UpdateBackupCompressionMethodInput",
 		},
@@ -1112,6 +1372,11 @@ func volumeSchema(volume *client.Schema) {
 			Input: "UpdateBackupTargetInput",
 		},
 
+		"forceCleanup": {
+			Input:  "forceCleanupInput",
+			Output: "forceCleanupOutput",
+		},
+
 		"pvCreate": {
 			Input:  "PVCreateInput",
 			Output: "volume",
@@ -1129,6 +1394,21 @@ func volumeSchema(volume *client.Schema) {
 			Output: "volume",
 		},
 
+		"replicaRebuildPause": {
+			Input:  "replicaRebuildInput",
+			Output: "volume",
+		},
+
+		"replicaRebuildResume": {
+			Input:  "replicaRebuildInput",
+			Output: "volume",
+		},
+
+		"updateReplicaRebuildPriority": {
+			Input:  "UpdateReplicaRebuildPriorityInput",
+			Output: "volume",
+		},
+
 		"engineUpgrade": {
 			Input: "engineUpgradeInput",
 		},
@@ -1157,10 +1437,41 @@ func volumeSchema(volume *client.Schema) {
 	volumeFromBackup.Create = true
 	volume.ResourceFields["fromBackup"] = volumeFromBackup
 
+	// The restore credential is write-only and never returned with the volume
+	volumeRestoreCredential := volume.ResourceFields["restoreCredential"]
+	volumeRestoreCredential.Create = true
+	volume.ResourceFields["restoreCredential"] = volumeRestoreCredential
+
+	volumeAdoptWarmPoolVolume := volume.ResourceFields["adoptWarmPoolVolume"]
+	volumeAdoptWarmPoolVolume.Create = true
+	volume.ResourceFields["adoptWarmPoolVolume"] = volumeAdoptWarmPoolVolume
+
 	volumeDataSource := volume.ResourceFields["dataSource"]
 	volumeDataSource.Create = true
 	volume.ResourceFields["dataSource"] = volumeDataSource
 
+	volumeCloneMode := volume.ResourceFields["cloneMode"]
+	volumeCloneMode.Create = true
+	volume.ResourceFields["cloneMode"] = volumeCloneMode
+
+	volumeTrimFilesystemOnUnstage := volume.ResourceFields["trimFilesystemOnUnstage"]
+	volumeTrimFilesystemOnUnstage.Create = true
+	volume.ResourceFields["trimFilesystemOnUnstage"] = volumeTrimFilesystemOnUnstage
+
+	volumeRebuildPriority := volume.ResourceFields["rebuildPriority"]
+	volumeRebuildPriority.Create = true
+	volume.ResourceFields["rebuildPriority"] = volumeRebuildPriority
+
+	volumeSnapshotMaxChainLength := volume.ResourceFields["snapshotMaxChainLength"]
+	volumeSnapshotMaxChainLength.Create = true
+	volume.ResourceFields["snapshotMaxChainLength"] = volumeSnapshotMaxChainLength
+
+	for _, field := range []string{"snapshotRetentionKeepLast", "snapshotRetentionMaxAge", "snapshotRetentionMaxSize"} {
+		volumeSnapshotRetention := volume.ResourceFields[field]
+		volumeSnapshotRetention.Create = true
+		volume.ResourceFields[field] = volumeSnapshotRetention
+	}
+
 	volumeNumberOfReplicas := volume.ResourceFields["numberOfReplicas"]
 	volumeNumberOfReplicas.Create = true
 	volumeNumberOfReplicas.Required = true
@@ -1267,6 +1578,14 @@ func volumeSchema(volume *client.Schema) {
 	cloneStatus.Type = "cloneStatus"
 	volume.ResourceFields["cloneStatus"] = cloneStatus
 
+	passphraseRotationStatus := volume.ResourceFields["passphraseRotationStatus"]
+	passphraseRotationStatus.Type = "passphraseRotationStatus"
+	volume.ResourceFields["passphraseRotationStatus"] = passphraseRotationStatus
+
+	diskTagMigrationStatus := volume.ResourceFields["diskTagMigrationStatus"]
+	diskTagMigrationStatus.Type = "diskTagMigrationStatus"
+	volume.ResourceFields["diskTagMigrationStatus"] = diskTagMigrationStatus
+
 	backupStatus := volume.ResourceFields["backupStatus"]
 	backupStatus.Type = "array[backupStatus]"
 	volume.ResourceFields["backupStatus"] = backupStatus
@@ -1335,6 +1654,22 @@ func systemBackupSchema(systemBackup *client.Schema) {
 	systemBackup.ResourceFields["name"] = name
 }
 
+func replicaPlacementSchema(replicaPlacement *client.Schema) {
+	replicaPlacement.CollectionMethods = []string{"GET", "POST"}
+	replicaPlacement.ResourceMethods = []string{"GET"}
+
+	name := replicaPlacement.ResourceFields["name"]
+	name.Required = true
+	name.Unique = true
+	name.Create = true
+	replicaPlacement.ResourceFields["name"] = name
+
+	replicas := replicaPlacement.ResourceFields["replicas"]
+	replicas.Type = "array[replicaPlacementReplica]"
+	replicas.Create = true
+	replicaPlacement.ResourceFields["replicas"] = replicas
+}
+
 func systemRestoreSchema(systemRestore *client.Schema) {
 	systemRestore.CollectionMethods = []string{"GET", "POST"}
 	systemRestore.ResourceMethods = []string{"GET", "DELETE"}
@@ -1351,6 +1686,12 @@ func systemRestoreSchema(systemRestore *client.Schema) {
 	systemRestore.ResourceFields["systemBackup"] = systemBackup
 }
 
+func snapshotHashStatusListOutputSchema(snapshotHashStatusList *client.Schema) {
+	data := snapshotHashStatusList.ResourceFields["data"]
+	data.Type = "array[snapshotHashStatus]"
+	snapshotHashStatusList.ResourceFields["data"] = data
+}
+
 func snapshotCRListOutputSchema(snapshotList *client.Schema) {
 	data := snapshotList.ResourceFields["data"]
 	data.Type = "array[snapshotCR]"
@@ -1443,6 +1784,7 @@ func toVolumeResource(v *longhorn.Volume, ves []*longhorn.Engine, vrs []*longhor
 			LastExpansionError:               e.Status.LastExpansionError,
 			LastExpansionFailedAt:            e.Status.LastExpansionFailedAt,
 			UnmapMarkSnapChainRemovedEnabled: e.Status.UnmapMarkSnapChainRemovedEnabled,
+			IOPaused:                         e.Status.IOPaused,
 		})
 		if e.Spec.NodeID == v.Status.CurrentNodeID {
 			ve = e
@@ -1525,6 +1867,9 @@ func toVolumeResource(v *longhorn.Volume, ves []*longhorn.Engine, vrs []*longhor
 			Mode:       mode,
 			FailedAt:   r.Spec.FailedAt,
 			DataEngine: string(r.Spec.DataEngine),
+
+			RebuildPaused:   r.Spec.RebuildPaused,
+			RebuildPriority: r.Spec.RebuildPriority,
 		})
 	}
 
@@ -1601,13 +1946,16 @@ func toVolumeResource(v *longhorn.Volume, ves []*longhorn.Engine, vrs []*longhor
 		LastAttachedBy:              v.Spec.LastAttachedBy,
 		FromBackup:                  v.Spec.FromBackup,
 		DataSource:                  v.Spec.DataSource,
+		CloneMode:                   v.Spec.CloneMode,
 		NumberOfReplicas:            v.Spec.NumberOfReplicas,
 		ReplicaAutoBalance:          v.Spec.ReplicaAutoBalance,
 		DataLocality:                v.Spec.DataLocality,
 		SnapshotDataIntegrity:       v.Spec.SnapshotDataIntegrity,
 		SnapshotMaxCount:            v.Spec.SnapshotMaxCount,
 		SnapshotMaxSize:             strconv.FormatInt(v.Spec.SnapshotMaxSize, 10),
+		SnapshotMaxChainLength:      v.Spec.SnapshotMaxChainLength,
 		BackupCompressionMethod:     v.Spec.BackupCompressionMethod,
+		BackupMaxAge:                v.Spec.BackupMaxAge,
 		StaleReplicaTimeout:         v.Spec.StaleReplicaTimeout,
 		Created:                     v.CreationTimestamp.String(),
 		Image:                       v.Spec.Image,
@@ -1617,8 +1965,16 @@ func toVolumeResource(v *longhorn.Volume, ves []*longhorn.Engine, vrs []*longhor
 		NodeSelector:                v.Spec.NodeSelector,
 		RestoreVolumeRecurringJob:   v.Spec.RestoreVolumeRecurringJob,
 		FreezeFilesystemForSnapshot: v.Spec.FreezeFilesystemForSnapshot,
+		TrimFilesystemOnUnstage:     v.Spec.TrimFilesystemOnUnstage,
+		RebuildPriority:             v.Spec.RebuildPriority,
 		BackupTargetName:            v.Spec.BackupTargetName,
 
+		SnapshotRetentionKeepLast: v.Spec.SnapshotRetentionKeepLast,
+		SnapshotRetentionMaxAge:   v.Spec.SnapshotRetentionMaxAge,
+		SnapshotRetentionMaxSize:  strconv.FormatInt(v.Spec.SnapshotRetentionMaxSize, 10),
+
+		IOPauseDeadline: v.Spec.IOPauseDeadline,
+
 		State:                       v.Status.State,
 		Robustness:                  v.Status.Robustness,
 		CurrentImage:                v.Status.CurrentImage,
@@ -1645,6 +2001,15 @@ func toVolumeResource(v *longhorn.Volume, ves []*longhorn.Engine, vrs []*longhor
 		Conditions:       sliceToMap(v.Status.Conditions),
 		KubernetesStatus: v.Status.KubernetesStatus,
 		CloneStatus:      v.Status.CloneStatus,
+		CloneTargets:     v.Status.CloneTargets,
+
+		PassphraseRotationStatus: v.Status.PassphraseRotationStatus,
+		DiskTagMigrationStatus:   v.Status.DiskTagMigrationStatus,
+
+		SalvageCandidates: v.Status.SalvageCandidates,
+		SalvageDecision:   v.Status.SalvageDecision,
+
+		LastFilesystemTrimAt: v.Status.LastFilesystemTrimAt,
 
 		Controllers:      controllers,
 		Replicas:         replicas,
@@ -1654,16 +2019,24 @@ func toVolumeResource(v *longhorn.Volume, ves []*longhorn.Engine, vrs []*longhor
 		RebuildStatus:    rebuildStatuses,
 		VolumeAttachment: volumeAttachment,
 	}
+	if v.Spec.AutoResize != nil {
+		r.AutoResizeThresholdPercentage = v.Spec.AutoResize.ThresholdPercentage
+		r.AutoResizeIncrement = strconv.FormatInt(v.Spec.AutoResize.Increment, 10)
+		r.AutoResizeMaxSize = strconv.FormatInt(v.Spec.AutoResize.MaxSize, 10)
+	}
 
 	// api attach & detach calls are always allowed
 	// the volume manager is responsible for handling them appropriately
+	// the filesystem conditions are reported by the CSI plugin whenever the volume is staged
 	actions := map[string]struct{}{
-		"attach": {},
-		"detach": {},
+		"attach":                    {},
+		"detach":                    {},
+		"updateFilesystemCondition": {},
 	}
 
 	if v.Status.Robustness == longhorn.VolumeRobustnessFaulted {
 		actions["salvage"] = struct{}{}
+		actions["pickSalvageCandidate"] = struct{}{}
 	} else {
 
 		actions["snapshotCRCreate"] = struct{}{}
@@ -1675,6 +2048,7 @@ func toVolumeResource(v *longhorn.Volume, ves []*longhorn.Engine, vrs []*longhor
 		switch v.Status.State {
 		case longhorn.VolumeStateDetached:
 			actions["activate"] = struct{}{}
+			actions["activateWithFinalSync"] = struct{}{}
 			actions["expand"] = struct{}{}
 			actions["cancelExpansion"] = struct{}{}
 			actions["replicaRemove"] = struct{}{}
@@ -1688,6 +2062,11 @@ func toVolumeResource(v *longhorn.Volume, ves []*longhorn.Engine, vrs []*longhor
 			actions["updateSnapshotDataIntegrity"] = struct{}{}
 			actions["updateSnapshotMaxCount"] = struct{}{}
 			actions["updateSnapshotMaxSize"] = struct{}{}
+			actions["updateSnapshotMaxChainLength"] = struct{}{}
+			actions["updateRebuildPriority"] = struct{}{}
+			actions["updateSnapshotRetentionPolicy"] = struct{}{}
+			actions["updateAutoResizePolicy"] = struct{}{}
+			actions["updateBackupMaxAge"] = struct{}{}
 			actions["updateBackupCompressionMethod"] = struct{}{}
 			actions["updateReplicaSoftAntiAffinity"] = struct{}{}
 			actions["updateReplicaZoneSoftAntiAffinity"] = struct{}{}
@@ -1704,6 +2083,7 @@ func toVolumeResource(v *longhorn.Volume, ves []*longhorn.Engine, vrs []*longhor
 			actions["recurringJobList"] = struct{}{}
 		case longhorn.VolumeStateAttached:
 			actions["activate"] = struct{}{}
+			actions["activateWithFinalSync"] = struct{}{}
 			actions["expand"] = struct{}{}
 			actions["snapshotPurge"] = struct{}{}
 			actions["snapshotCreate"] = struct{}{}
@@ -1711,8 +2091,15 @@ func toVolumeResource(v *longhorn.Volume, ves []*longhorn.Engine, vrs []*longhor
 			actions["snapshotGet"] = struct{}{}
 			actions["snapshotDelete"] = struct{}{}
 			actions["snapshotRevert"] = struct{}{}
+			actions["snapshotHash"] = struct{}{}
+			actions["snapshotHashStatus"] = struct{}{}
 			actions["replicaRemove"] = struct{}{}
+			actions["replicaRebuildPause"] = struct{}{}
+			actions["replicaRebuildResume"] = struct{}{}
+			actions["updateReplicaRebuildPriority"] = struct{}{}
 			actions["engineUpgrade"] = struct{}{}
+			actions["migrateToDiskTag"] = struct{}{}
+			actions["liveMigrate"] = struct{}{}
 			actions["updateReplicaCount"] = struct{}{}
 			actions["updateDataLocality"] = struct{}{}
 			actions["updateReplicaAutoBalance"] = struct{}{}
@@ -1720,6 +2107,13 @@ func toVolumeResource(v *longhorn.Volume, ves []*longhorn.Engine, vrs []*longhor
 			actions["updateSnapshotDataIntegrity"] = struct{}{}
 			actions["updateSnapshotMaxCount"] = struct{}{}
 			actions["updateSnapshotMaxSize"] = struct{}{}
+			actions["updateSnapshotMaxChainLength"] = struct{}{}
+			actions["updateRebuildPriority"] = struct{}{}
+			actions["updateSnapshotRetentionPolicy"] = struct{}{}
+			actions["updateAutoResizePolicy"] = struct{}{}
+			actions["updateBackupMaxAge"] = struct{}{}
+			actions["pauseIO"] = struct{}{}
+			actions["resumeIO"] = struct{}{}
 			actions["updateBackupCompressionMethod"] = struct{}{}
 			actions["updateReplicaSoftAntiAffinity"] = struct{}{}
 			actions["updateReplicaZoneSoftAntiAffinity"] = struct{}{}
@@ -1734,6 +2128,15 @@ func toVolumeResource(v *longhorn.Volume, ves []*longhorn.Engine, vrs []*longhor
 			actions["recurringJobDelete"] = struct{}{}
 			actions["recurringJobList"] = struct{}{}
 		}
+
+		if v.Spec.Encrypted && (v.Status.State == longhorn.VolumeStateDetached || v.Status.State == longhorn.VolumeStateAttached) {
+			actions["rotatePassphrase"] = struct{}{}
+		}
+	}
+
+	// a volume stuck in deletion can be cleaned up in place of removing the finalizers manually
+	if v.DeletionTimestamp != nil {
+		actions["forceCleanup"] = struct{}{}
 	}
 
 	for action := range actions {
@@ -1817,6 +2220,24 @@ func toSnapshotCollection(ssList map[string]*longhorn.SnapshotInfo, ssListRO map
 	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "snapshot"}}
 }
 
+func toSnapshotHashStatusOutput(status map[string]*longhorn.HashStatus) *client.GenericCollection {
+	data := []interface{}{}
+	for replica, s := range status {
+		data = append(data, &SnapshotHashStatus{
+			Resource: client.Resource{
+				Id:   replica,
+				Type: "snapshotHashStatus",
+			},
+			Replica:           replica,
+			State:             s.State,
+			Checksum:          s.Checksum,
+			Error:             s.Error,
+			SilentlyCorrupted: s.SilentlyCorrupted,
+		})
+	}
+	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "snapshotHashStatus"}}
+}
+
 func toVolumeRecurringJobResource(obj *longhorn.VolumeRecurringJob) *VolumeRecurringJob {
 	if obj == nil {
 		return nil
@@ -2118,16 +2539,18 @@ func toBackingImageCollection(bis []*longhorn.BackingImage, apiContext *api.ApiC
 }
 
 type Server struct {
-	m   *manager.VolumeManager
-	wsc *controller.WebsocketController
-	fwd *Fwd
+	m        *manager.VolumeManager
+	wsc      *controller.WebsocketController
+	backoffs *controller.BackoffRegistry
+	fwd      *Fwd
 }
 
-func NewServer(m *manager.VolumeManager, wsc *controller.WebsocketController) *Server {
+func NewServer(m *manager.VolumeManager, wsc *controller.WebsocketController, backoffs *controller.BackoffRegistry) *Server {
 	s := &Server{
-		m:   m,
-		wsc: wsc,
-		fwd: NewFwd(m),
+		m:        m,
+		wsc:      wsc,
+		backoffs: backoffs,
+		fwd:      NewFwd(m),
 	}
 	return s
 }
@@ -2140,16 +2563,17 @@ func toNodeResource(node *longhorn.Node, address string, apiContext *api.ApiCont
 			Actions: map[string]string{},
 			Links:   map[string]string{},
 		},
-		Name:                      node.Name,
-		Address:                   address,
-		AllowScheduling:           node.Spec.AllowScheduling,
-		EvictionRequested:         node.Spec.EvictionRequested,
-		Conditions:                sliceToMap(node.Status.Conditions),
-		Tags:                      node.Spec.Tags,
-		Region:                    node.Status.Region,
-		Zone:                      node.Status.Zone,
-		InstanceManagerCPURequest: node.Spec.InstanceManagerCPURequest,
-		AutoEvicting:              node.Status.AutoEvicting,
+		Name:                         node.Name,
+		Address:                      address,
+		AllowScheduling:              node.Spec.AllowScheduling,
+		EvictionRequested:            node.Spec.EvictionRequested,
+		Conditions:                   sliceToMap(node.Status.Conditions),
+		Tags:                         node.Spec.Tags,
+		Region:                       node.Status.Region,
+		Zone:                         node.Status.Zone,
+		InstanceManagerCPURequest:    node.Spec.InstanceManagerCPURequest,
+		AutoEvicting:                 node.Status.AutoEvicting,
+		InstanceManagerMemoryRequest: node.Spec.InstanceManagerMemoryRequest,
 	}
 
 	disks := map[string]DiskInfo{}
@@ -2166,6 +2590,8 @@ func toNodeResource(node *longhorn.Node, address string, apiContext *api.ApiCont
 				ScheduledReplica:      node.Status.DiskStatus[name].ScheduledReplica,
 				ScheduledBackingImage: node.Status.DiskStatus[name].ScheduledBackingImage,
 				DiskUUID:              node.Status.DiskStatus[name].DiskUUID,
+				IOUtilization:         node.Status.DiskStatus[name].IOUtilization,
+				IOLatency:             node.Status.DiskStatus[name].IOLatency,
 			}
 		}
 		disks[name] = di
@@ -2321,6 +2747,55 @@ func toTagCollection(tags []string, tagType string, apiContext *api.ApiContext)
 	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "tag"}}
 }
 
+func toBackoffResource(entry controller.BackoffEntry, nodeID string) *Backoff {
+	return &Backoff{
+		Resource: client.Resource{
+			Id:   entry.Name + "/" + entry.Key,
+			Type: "backoff",
+		},
+		NodeID:     nodeID,
+		Name:       entry.Name,
+		Key:        entry.Key,
+		Failures:   entry.Failures,
+		Backoff:    entry.Backoff,
+		LastUpdate: entry.LastUpdate,
+	}
+}
+
+func toBackoffCollection(entries []controller.BackoffEntry, nodeID string) *client.GenericCollection {
+	var data []interface{}
+	for _, entry := range entries {
+		data = append(data, toBackoffResource(entry, nodeID))
+	}
+	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "backoff"}}
+}
+
+func toMountJournalRecordResource(record journal.Record, index int) *MountJournalRecord {
+	return &MountJournalRecord{
+		Resource: client.Resource{
+			Id:   fmt.Sprintf("%v-%d", record.Node, index),
+			Type: "mountJournalRecord",
+		},
+		Time:      record.Time,
+		NodeID:    record.Node,
+		Volume:    record.Volume,
+		Operation: string(record.Operation),
+		Path:      record.Path,
+		Options:   record.Options,
+		Result:    string(record.Result),
+		Error:     record.Error,
+		Duration:  record.Duration,
+	}
+}
+
+func toMountJournalRecordCollection(records []journal.Record) *client.GenericCollection {
+	var data []interface{}
+	for i, record := range records {
+		data = append(data, toMountJournalRecordResource(record, i))
+	}
+	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "mountJournalRecord"}}
+}
+
 func toInstanceManagerResource(im *longhorn.InstanceManager) *InstanceManager {
 	return &InstanceManager{
 		Resource: client.Resource{
@@ -2400,6 +2875,49 @@ func toOrphanCollection(orphans map[string]*longhorn.Orphan) *client.GenericColl
 	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "orphan"}}
 }
 
+func toReplicaPlacementResource(volume *longhorn.Volume, replicas []*longhorn.Replica) *ReplicaPlacement {
+	replicaPlacementReplicas := []ReplicaPlacementReplica{}
+	for _, r := range replicas {
+		if r.Spec.NodeID == "" {
+			continue
+		}
+		replicaPlacementReplicas = append(replicaPlacementReplicas, ReplicaPlacementReplica{
+			Name:              r.Name,
+			NodeID:            r.Spec.NodeID,
+			DiskID:            r.Spec.DiskID,
+			DiskPath:          r.Spec.DiskPath,
+			DataDirectoryName: r.Spec.DataDirectoryName,
+			Healthy:           r.Spec.HealthyAt != "" && r.Spec.FailedAt == "",
+		})
+	}
+	sort.Slice(replicaPlacementReplicas, func(i, j int) bool {
+		return replicaPlacementReplicas[i].Name < replicaPlacementReplicas[j].Name
+	})
+
+	return &ReplicaPlacement{
+		Resource: client.Resource{
+			Id:   volume.Name,
+			Type: "replicaPlacement",
+		},
+		Name:             volume.Name,
+		Size:             strconv.FormatInt(volume.Spec.Size, 10),
+		NumberOfReplicas: volume.Spec.NumberOfReplicas,
+		DataEngine:       volume.Spec.DataEngine,
+		Frontend:         volume.Spec.Frontend,
+		AccessMode:       volume.Spec.AccessMode,
+		Encrypted:        volume.Spec.Encrypted,
+		Replicas:         replicaPlacementReplicas,
+	}
+}
+
+func toReplicaPlacementCollection(volumes []*longhorn.Volume, volumeReplicas map[string][]*longhorn.Replica) *client.GenericCollection {
+	data := []interface{}{}
+	for _, volume := range volumes {
+		data = append(data, toReplicaPlacementResource(volume, volumeReplicas[volume.Name]))
+	}
+	return &client.GenericCollection{Data: data, Collection: client.Collection{ResourceType: "replicaPlacement"}}
+}
+
 func sliceToMap(conditions []longhorn.Condition) map[string]longhorn.Condition {
 	converted := map[string]longhorn.Condition{}
 	for _, c := range conditions {
@@ -10,6 +10,7 @@ import (
 	"github.com/rancher/go-rancher/api"
 	"github.com/rancher/go-rancher/client"
 
+	"github.com/longhorn/longhorn-manager/csi/journal"
 	"github.com/longhorn/longhorn-manager/util"
 
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
@@ -77,6 +78,7 @@ func (s *Server) NodeUpdate(rw http.ResponseWriter, req *http.Request) error {
 		node.Spec.EvictionRequested = n.EvictionRequested
 		node.Spec.Tags = n.Tags
 		node.Spec.InstanceManagerCPURequest = n.InstanceManagerCPURequest
+		node.Spec.InstanceManagerMemoryRequest = n.InstanceManagerMemoryRequest
 
 		return s.m.UpdateNode(node)
 	})
@@ -128,3 +130,17 @@ func (s *Server) NodeDelete(rw http.ResponseWriter, req *http.Request) error {
 
 	return nil
 }
+
+// NodeMountJournalList returns the mount activity journal of the CSI plugin on the node. The request is forwarded
+// to the node since the journal is kept on the host. The query parameter "volume" narrows the result down to a volume.
+func (s *Server) NodeMountJournalList(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	records, err := journal.List(req.URL.Query().Get("volume"))
+	if err != nil {
+		return errors.Wrap(err, "failed to list mount journal records")
+	}
+
+	apiContext.Write(toMountJournalRecordCollection(records))
+	return nil
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/rancher/go-rancher/api"
+
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+func (s *Server) ReplicaPlacementList(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	volumes, volumeReplicas, err := s.m.ListReplicaPlacements()
+	if err != nil {
+		return errors.Wrap(err, "failed to list replica placements")
+	}
+
+	apiContext.Write(toReplicaPlacementCollection(volumes, volumeReplicas))
+	return nil
+}
+
+func (s *Server) ReplicaPlacementGet(rw http.ResponseWriter, req *http.Request) error {
+	apiContext := api.GetApiContext(req)
+
+	id := mux.Vars(req)["name"]
+
+	volume, replicas, err := s.m.GetReplicaPlacement(id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get replica placement of volume %v", id)
+	}
+
+	apiContext.Write(toReplicaPlacementResource(volume, replicas))
+	return nil
+}
+
+// ReplicaPlacementImport sets the placement hints of the volume to the healthy replicas of the exported replica
+// placement, and creates the volume if it doesn't exist.
+func (s *Server) ReplicaPlacementImport(rw http.ResponseWriter, req *http.Request) error {
+	var input ReplicaPlacement
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	size, err := util.ConvertSize(input.Size)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse size %v", input.Size)
+	}
+
+	hints := []types.ReplicaPlacementHint{}
+	for _, r := range input.Replicas {
+		if !r.Healthy {
+			continue
+		}
+		hints = append(hints, types.ReplicaPlacementHint{
+			NodeID:            r.NodeID,
+			DiskID:            r.DiskID,
+			DiskPath:          r.DiskPath,
+			DataDirectoryName: r.DataDirectoryName,
+		})
+	}
+
+	volume, err := s.m.ImportReplicaPlacement(input.Name, &longhorn.VolumeSpec{
+		Size:             size,
+		NumberOfReplicas: input.NumberOfReplicas,
+		DataEngine:       input.DataEngine,
+		Frontend:         input.Frontend,
+		AccessMode:       input.AccessMode,
+		Encrypted:        input.Encrypted,
+	}, hints)
+	if err != nil {
+		return errors.Wrapf(err, "failed to import replica placement of volume %v", input.Name)
+	}
+
+	_, replicas, err := s.m.GetReplicaPlacement(volume.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get replica placement of volume %v", volume.Name)
+	}
+	apiContext.Write(toReplicaPlacementResource(volume, replicas))
+	return nil
+}
@@ -72,17 +72,30 @@ func NewRouter(s *Server) *mux.Router {
 		"attach":                            s.VolumeAttach,
 		"detach":                            s.VolumeDetach,
 		"salvage":                           s.VolumeSalvage,
+		"pickSalvageCandidate":              s.VolumePickSalvageCandidate,
 		"updateDataLocality":                s.VolumeUpdateDataLocality,
 		"updateAccessMode":                  s.VolumeUpdateAccessMode,
 		"updateUnmapMarkSnapChainRemoved":   s.VolumeUpdateUnmapMarkSnapChainRemoved,
 		"updateSnapshotMaxCount":            s.VolumeUpdateSnapshotMaxCount,
+		"updateSnapshotMaxChainLength":      s.VolumeUpdateSnapshotMaxChainLength,
 		"updateSnapshotMaxSize":             s.VolumeUpdateSnapshotMaxSize,
+		"updateRebuildPriority":             s.VolumeUpdateRebuildPriority,
+		"updateSnapshotRetentionPolicy":     s.VolumeUpdateSnapshotRetentionPolicy,
+		"updateAutoResizePolicy":            s.VolumeUpdateAutoResizePolicy,
+		"updateBackupMaxAge":                s.VolumeUpdateBackupMaxAge,
+		"updateFilesystemCondition":         s.VolumeUpdateFilesystemCondition,
+		"pauseIO":                           s.VolumePauseIO,
+		"resumeIO":                          s.VolumeResumeIO,
+		"liveMigrate":                       s.VolumeLiveMigrate,
 		"updateReplicaSoftAntiAffinity":     s.VolumeUpdateReplicaSoftAntiAffinity,
 		"updateReplicaZoneSoftAntiAffinity": s.VolumeUpdateReplicaZoneSoftAntiAffinity,
 		"updateReplicaDiskSoftAntiAffinity": s.VolumeUpdateReplicaDiskSoftAntiAffinity,
 		"activate":                          s.VolumeActivate,
+		"activateWithFinalSync":             s.VolumeActivateWithFinalSync,
 		"expand":                            s.VolumeExpand,
 		"cancelExpansion":                   s.VolumeCancelExpansion,
+		"rotatePassphrase":                  s.VolumeRotatePassphrase,
+		"migrateToDiskTag":                  s.VolumeMigrateToDiskTag,
 
 		"updateReplicaCount":                s.VolumeUpdateReplicaCount,
 		"updateReplicaAutoBalance":          s.VolumeUpdateReplicaAutoBalance,
@@ -90,7 +103,11 @@ func NewRouter(s *Server) *mux.Router {
 		"updateBackupCompressionMethod":     s.VolumeUpdateBackupCompressionMethod,
 		"updateFreezeFilesystemForSnapshot": s.VolumeUpdateFreezeFilesystemForSnapshot,
 		"updateBackupTargetName":            s.VolumeUpdateBackupTargetName,
+		"forceCleanup":                      s.VolumeForceCleanup,
 		"replicaRemove":                     s.ReplicaRemove,
+		"replicaRebuildPause":               s.ReplicaRebuildPause,
+		"replicaRebuildResume":              s.ReplicaRebuildResume,
+		"updateReplicaRebuildPriority":      s.ReplicaUpdateRebuildPriority,
 
 		"engineUpgrade": s.EngineUpgrade,
 
@@ -104,6 +121,9 @@ func NewRouter(s *Server) *mux.Router {
 		"snapshotRevert": s.fwd.Handler(s.fwd.HandleProxyRequestByNodeID, s.fwd.GetHTTPAddressByNodeID(OwnerIDFromVolume(s.m)), s.SnapshotRevert),
 		"snapshotBackup": s.fwd.Handler(s.fwd.HandleProxyRequestByNodeID, s.fwd.GetHTTPAddressByNodeID(OwnerIDFromVolume(s.m)), s.SnapshotBackup),
 
+		"snapshotHash":       s.fwd.Handler(s.fwd.HandleProxyRequestByNodeID, s.fwd.GetHTTPAddressByNodeID(OwnerIDFromVolume(s.m)), s.SnapshotHash),
+		"snapshotHashStatus": s.fwd.Handler(s.fwd.HandleProxyRequestByNodeID, s.fwd.GetHTTPAddressByNodeID(OwnerIDFromVolume(s.m)), s.SnapshotHashStatus),
+
 		"snapshotCRCreate": s.SnapshotCRCreate,
 		"snapshotCRList":   s.SnapshotCRList,
 		"snapshotCRGet":    s.SnapshotCRGet,
@@ -152,6 +172,7 @@ func NewRouter(s *Server) *mux.Router {
 	r.Methods("GET").Path("/v1/nodes/{name}").Handler(f(schemas, s.NodeGet))
 	r.Methods("PUT").Path("/v1/nodes/{name}").Handler(f(schemas, s.NodeUpdate))
 	r.Methods("DELETE").Path("/v1/nodes/{name}").Handler(f(schemas, s.NodeDelete))
+	r.Methods("GET").Path("/v1/nodes/{name}/mountjournal").Handler(f(schemas, s.fwd.Handler(s.fwd.HandleProxyRequestByNodeID, s.fwd.GetHTTPAddressByNodeID(NodeIDFromNode()), s.NodeMountJournalList)))
 	nodeActions := map[string]func(http.ResponseWriter, *http.Request) error{
 		"diskUpdate": s.DiskUpdate,
 	}
@@ -169,6 +190,8 @@ func NewRouter(s *Server) *mux.Router {
 	r.Methods("GET").Path("/v1/disktags").Handler(f(schemas, s.DiskTagList))
 	r.Methods("GET").Path("/v1/nodetags").Handler(f(schemas, s.NodeTagList))
 
+	r.Methods("GET").Path("/v1/backoffs").Handler(f(schemas, s.BackoffList))
+
 	r.Methods("GET").Path("/v1/instancemanagers").Handler(f(schemas, s.InstanceManagerList))
 	r.Methods("GET").Path("/v1/instancemanagers/{name}").Handler(f(schemas, s.InstanceManagerGet))
 
@@ -207,6 +230,10 @@ func NewRouter(s *Server) *mux.Router {
 	r.Methods("GET").Path("/v1/orphans/{name}").Handler(f(schemas, s.OrphanGet))
 	r.Methods("DELETE").Path("/v1/orphans/{name}").Handler(f(schemas, s.OrphanDelete))
 
+	r.Methods("GET").Path("/v1/replicaplacements").Handler(f(schemas, s.ReplicaPlacementList))
+	r.Methods("GET").Path("/v1/replicaplacements/{name}").Handler(f(schemas, s.ReplicaPlacementGet))
+	r.Methods("POST").Path("/v1/replicaplacements").Handler(f(schemas, s.ReplicaPlacementImport))
+
 	r.Methods("POST").Path("/v1/supportbundles").Handler(f(schemas, s.SupportBundleCreate))
 	r.Methods("GET").Path("/v1/supportbundles").Handler(f(schemas, s.SupportBundleList))
 	r.Methods("GET").Path("/v1/supportbundles/{name}/{bundleName}").Handler(f(schemas,
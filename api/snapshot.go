@@ -195,7 +195,16 @@ func (s *Server) SnapshotBackup(w http.ResponseWriter, req *http.Request) (err e
 		labels[types.KubernetesStatusLabel] = string(kubeStatus)
 	}
 
-	if err := s.m.BackupSnapshot(bsutil.GenerateName("backup"), vol.Spec.BackupTargetName, volName, input.Name, labels, input.BackupMode); err != nil {
+	// The backup goes to the backup target of the volume unless another one is given
+	backupTargetName := vol.Spec.BackupTargetName
+	if input.BackupTargetName != "" {
+		if _, err := s.m.GetBackupTarget(input.BackupTargetName); err != nil {
+			return errors.Wrapf(err, "failed to get backup target %v", input.BackupTargetName)
+		}
+		backupTargetName = input.BackupTargetName
+	}
+
+	if err := s.m.BackupSnapshot(bsutil.GenerateName("backup"), backupTargetName, volName, input.Name, labels, input.BackupMode); err != nil {
 		return err
 	}
 
@@ -215,6 +224,48 @@ func (s *Server) SnapshotPurge(w http.ResponseWriter, req *http.Request) (err er
 	return s.responseWithVolume(w, req, volName, nil)
 }
 
+func (s *Server) SnapshotHash(w http.ResponseWriter, req *http.Request) (err error) {
+	defer func() {
+		err = errors.Wrap(err, "failed to hash snapshot")
+	}()
+
+	var input SnapshotInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	volName := mux.Vars(req)["name"]
+	if err := s.m.HashSnapshot(input.Name, volName); err != nil {
+		return err
+	}
+
+	return s.responseWithVolume(w, req, volName, nil)
+}
+
+func (s *Server) SnapshotHashStatus(w http.ResponseWriter, req *http.Request) (err error) {
+	defer func() {
+		err = errors.Wrap(err, "failed to get snapshot hash status")
+	}()
+
+	var input SnapshotInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return err
+	}
+
+	volName := mux.Vars(req)["name"]
+	status, err := s.m.GetSnapshotHashStatus(input.Name, volName)
+	if err != nil {
+		return err
+	}
+
+	apiContext.Write(toSnapshotHashStatusOutput(status))
+	return nil
+}
+
 func (s *Server) SnapshotCRCreate(w http.ResponseWriter, req *http.Request) (err error) {
 	defer func() {
 		err = errors.Wrap(err, "failed to create snapshot CR")
@@ -60,7 +60,7 @@ func (s *Server) SupportBundleDownload(w http.ResponseWriter, req *http.Request)
 		return fmt.Errorf("support bundle not ready")
 	}
 
-	sourceURL := fmt.Sprintf(types.SupportBundleURLDownloadFmt, supportBundleIP, types.SupportBundleURLPort)
+	sourceURL := fmt.Sprintf(types.SupportBundleURLDownloadFmt, types.GetSupportBundleManagerAddress(supportBundleIP))
 	newReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, sourceURL, nil)
 	if err != nil {
 		return err
@@ -172,6 +172,11 @@ func (s *Server) VolumeCreate(rw http.ResponseWriter, req *http.Request) error {
 		return errors.Wrap(err, "failed to parse snapshot max size")
 	}
 
+	snapshotRetentionMaxSize, err := util.ConvertSize(volume.SnapshotRetentionMaxSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse snapshot retention max size")
+	}
+
 	v, err := s.m.Create(volume.Name, &longhorn.VolumeSpec{
 		Size:                        size,
 		AccessMode:                  volume.AccessMode,
@@ -181,6 +186,7 @@ func (s *Server) VolumeCreate(rw http.ResponseWriter, req *http.Request) error {
 		FromBackup:                  volume.FromBackup,
 		RestoreVolumeRecurringJob:   volume.RestoreVolumeRecurringJob,
 		DataSource:                  volume.DataSource,
+		CloneMode:                   volume.CloneMode,
 		NumberOfReplicas:            volume.NumberOfReplicas,
 		ReplicaAutoBalance:          volume.ReplicaAutoBalance,
 		DataLocality:                volume.DataLocality,
@@ -193,6 +199,7 @@ func (s *Server) VolumeCreate(rw http.ResponseWriter, req *http.Request) error {
 		SnapshotDataIntegrity:       volume.SnapshotDataIntegrity,
 		SnapshotMaxCount:            volume.SnapshotMaxCount,
 		SnapshotMaxSize:             snapshotMaxSize,
+		SnapshotMaxChainLength:      volume.SnapshotMaxChainLength,
 		BackupCompressionMethod:     volume.BackupCompressionMethod,
 		UnmapMarkSnapChainRemoved:   volume.UnmapMarkSnapChainRemoved,
 		ReplicaSoftAntiAffinity:     volume.ReplicaSoftAntiAffinity,
@@ -200,8 +207,14 @@ func (s *Server) VolumeCreate(rw http.ResponseWriter, req *http.Request) error {
 		ReplicaDiskSoftAntiAffinity: volume.ReplicaDiskSoftAntiAffinity,
 		DataEngine:                  volume.DataEngine,
 		FreezeFilesystemForSnapshot: volume.FreezeFilesystemForSnapshot,
+		TrimFilesystemOnUnstage:     volume.TrimFilesystemOnUnstage,
+		RebuildPriority:             volume.RebuildPriority,
 		BackupTargetName:            volume.BackupTargetName,
-	}, volume.RecurringJobSelector)
+
+		SnapshotRetentionKeepLast: volume.SnapshotRetentionKeepLast,
+		SnapshotRetentionMaxAge:   volume.SnapshotRetentionMaxAge,
+		SnapshotRetentionMaxSize:  snapshotRetentionMaxSize,
+	}, volume.RecurringJobSelector, volume.RestoreCredential, volume.AdoptWarmPoolVolume)
 	if err != nil {
 		return errors.Wrap(err, "failed to create volume")
 	}
@@ -218,6 +231,31 @@ func (s *Server) VolumeDelete(rw http.ResponseWriter, req *http.Request) error {
 	return nil
 }
 
+func (s *Server) VolumeForceCleanup(rw http.ResponseWriter, req *http.Request) error {
+	var input ForceCleanupInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read force cleanup input")
+	}
+
+	id := mux.Vars(req)["name"]
+	result, err := s.m.ForceCleanupVolume(id, input.Reason)
+	if err != nil {
+		return err
+	}
+
+	apiContext.Write(&ForceCleanupOutput{
+		Resource: client.Resource{
+			Id:   id,
+			Type: "forceCleanupOutput",
+		},
+		Actions: result.Actions,
+		Pending: result.Pending,
+	})
+	return nil
+}
+
 func (s *Server) VolumeAttach(rw http.ResponseWriter, req *http.Request) error {
 	var input AttachInput
 
@@ -290,6 +328,30 @@ func (s *Server) VolumeSalvage(rw http.ResponseWriter, req *http.Request) error
 	return s.responseWithVolume(rw, req, "", v)
 }
 
+func (s *Server) VolumePickSalvageCandidate(rw http.ResponseWriter, req *http.Request) error {
+	var input PickSalvageCandidateInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read pickSalvageCandidateInput")
+	}
+
+	id := mux.Vars(req)["name"]
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.PickSalvageCandidate(id, input.ReplicaName)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+
+	return s.responseWithVolume(rw, req, "", v)
+}
+
 func (s *Server) VolumeRecurringAdd(rw http.ResponseWriter, req *http.Request) error {
 	var input VolumeRecurringJobInput
 	volName := mux.Vars(req)["name"]
@@ -599,6 +661,30 @@ func (s *Server) VolumeActivate(rw http.ResponseWriter, req *http.Request) error
 	return s.responseWithVolume(rw, req, "", v)
 }
 
+func (s *Server) VolumeActivateWithFinalSync(rw http.ResponseWriter, req *http.Request) error {
+	var input ActivateWithFinalSyncInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read activateWithFinalSyncInput")
+	}
+
+	id := mux.Vars(req)["name"]
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.ActivateWithFinalSync(id, input.Frontend, input.PVName, input.FSType, input.PVCNamespace, input.PVCName)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+
+	return s.responseWithVolume(rw, req, "", v)
+}
+
 func (s *Server) VolumeExpand(rw http.ResponseWriter, req *http.Request) error {
 	var input ExpandInput
 
@@ -653,6 +739,23 @@ func (s *Server) VolumeCancelExpansion(rw http.ResponseWriter, req *http.Request
 	return s.responseWithVolume(rw, req, "", v)
 }
 
+func (s *Server) VolumeRotatePassphrase(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.RotatePassphrase(id)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+
+	return s.responseWithVolume(rw, req, "", v)
+}
+
 func (s *Server) VolumeFilesystemTrim(rw http.ResponseWriter, req *http.Request) error {
 	id := mux.Vars(req)["name"]
 
@@ -689,7 +792,7 @@ func (s *Server) PVCreate(rw http.ResponseWriter, req *http.Request) error {
 	}
 
 	_, err = util.RetryOnConflictCause(func() (interface{}, error) {
-		return s.m.PVCreate(id, input.PVName, input.FSType, input.SecretNamespace, input.SecretName, input.StorageClassName)
+		return s.m.PVCreate(id, input.PVName, input.FSType, input.SecretNamespace, input.SecretName, input.StorageClassName, input.PVCNamespace, input.PVCName)
 	})
 	if err != nil {
 		return err
@@ -741,6 +844,63 @@ func (s *Server) ReplicaRemove(rw http.ResponseWriter, req *http.Request) error
 	return s.responseWithVolume(rw, req, id, nil)
 }
 
+func (s *Server) ReplicaRebuildPause(rw http.ResponseWriter, req *http.Request) error {
+	var input ReplicaRebuildInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read replicaRebuildInput")
+	}
+
+	id := mux.Vars(req)["name"]
+
+	if _, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.PauseReplicaRebuild(id, input.Name)
+	}); err != nil {
+		return err
+	}
+
+	return s.responseWithVolume(rw, req, id, nil)
+}
+
+func (s *Server) ReplicaRebuildResume(rw http.ResponseWriter, req *http.Request) error {
+	var input ReplicaRebuildInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read replicaRebuildInput")
+	}
+
+	id := mux.Vars(req)["name"]
+
+	if _, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.ResumeReplicaRebuild(id, input.Name)
+	}); err != nil {
+		return err
+	}
+
+	return s.responseWithVolume(rw, req, id, nil)
+}
+
+func (s *Server) ReplicaUpdateRebuildPriority(rw http.ResponseWriter, req *http.Request) error {
+	var input UpdateReplicaRebuildPriorityInput
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read UpdateReplicaRebuildPriorityInput")
+	}
+
+	id := mux.Vars(req)["name"]
+
+	if _, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.UpdateReplicaRebuildPriority(id, input.Name, input.RebuildPriority)
+	}); err != nil {
+		return err
+	}
+
+	return s.responseWithVolume(rw, req, id, nil)
+}
+
 func (s *Server) EngineUpgrade(rw http.ResponseWriter, req *http.Request) error {
 	var input EngineUpgradeInput
 
@@ -787,6 +947,28 @@ func (s *Server) VolumeUpdateSnapshotMaxCount(rw http.ResponseWriter, req *http.
 	return s.responseWithVolume(rw, req, "", v)
 }
 
+func (s *Server) VolumeUpdateSnapshotMaxChainLength(rw http.ResponseWriter, req *http.Request) error {
+	var input UpdateSnapshotMaxChainLengthInput
+	id := mux.Vars(req)["name"]
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read SnapshotMaxChainLength input")
+	}
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.UpdateSnapshotMaxChainLength(id, input.SnapshotMaxChainLength)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+	return s.responseWithVolume(rw, req, "", v)
+}
+
 func (s *Server) VolumeUpdateSnapshotMaxSize(rw http.ResponseWriter, req *http.Request) error {
 	var input UpdateSnapshotMaxSize
 	id := mux.Vars(req)["name"]
@@ -814,6 +996,221 @@ func (s *Server) VolumeUpdateSnapshotMaxSize(rw http.ResponseWriter, req *http.R
 	return s.responseWithVolume(rw, req, "", v)
 }
 
+func (s *Server) VolumeUpdateRebuildPriority(rw http.ResponseWriter, req *http.Request) error {
+	var input UpdateRebuildPriorityInput
+	id := mux.Vars(req)["name"]
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read RebuildPriority input")
+	}
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.UpdateRebuildPriority(id, input.RebuildPriority)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeUpdateSnapshotRetentionPolicy(rw http.ResponseWriter, req *http.Request) error {
+	var input UpdateSnapshotRetentionPolicyInput
+	id := mux.Vars(req)["name"]
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read SnapshotRetentionPolicy input")
+	}
+
+	maxSize, err := util.ConvertSize(input.SnapshotRetentionMaxSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse snapshot retention max size")
+	}
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.UpdateSnapshotRetentionPolicy(id, input.SnapshotRetentionKeepLast, input.SnapshotRetentionMaxAge, maxSize)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeUpdateAutoResizePolicy(rw http.ResponseWriter, req *http.Request) error {
+	var input UpdateAutoResizePolicyInput
+	id := mux.Vars(req)["name"]
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read AutoResizePolicy input")
+	}
+
+	// The threshold percentage 0 disables the auto resize
+	var policy *longhorn.VolumeAutoResizePolicy
+	if input.ThresholdPercentage != 0 {
+		increment, err := util.ConvertSize(input.Increment)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse auto resize increment")
+		}
+		maxSize, err := util.ConvertSize(input.MaxSize)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse auto resize max size")
+		}
+		policy = &longhorn.VolumeAutoResizePolicy{
+			ThresholdPercentage: input.ThresholdPercentage,
+			Increment:           increment,
+			MaxSize:             maxSize,
+		}
+	}
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.UpdateAutoResizePolicy(id, policy)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeUpdateBackupMaxAge(rw http.ResponseWriter, req *http.Request) error {
+	var input UpdateBackupMaxAgeInput
+	id := mux.Vars(req)["name"]
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read BackupMaxAge input")
+	}
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.UpdateBackupMaxAge(id, input.BackupMaxAge)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeUpdateFilesystemCondition(rw http.ResponseWriter, req *http.Request) error {
+	var input UpdateFilesystemConditionInput
+	id := mux.Vars(req)["name"]
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read UpdateFilesystemConditionInput")
+	}
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.UpdateFilesystemCondition(id, input.Type, longhorn.ConditionStatus(input.Status), input.Reason, input.Message)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumePauseIO(rw http.ResponseWriter, req *http.Request) error {
+	var input PauseIOInput
+	id := mux.Vars(req)["name"]
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read PauseIO input")
+	}
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.PauseIO(id, input.Timeout)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeResumeIO(rw http.ResponseWriter, req *http.Request) error {
+	id := mux.Vars(req)["name"]
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.ResumeIO(id)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeLiveMigrate(rw http.ResponseWriter, req *http.Request) error {
+	var input LiveMigrateInput
+	id := mux.Vars(req)["name"]
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read LiveMigrate input")
+	}
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.LiveMigrate(id, input.NodeID)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+	return s.responseWithVolume(rw, req, "", v)
+}
+
+func (s *Server) VolumeMigrateToDiskTag(rw http.ResponseWriter, req *http.Request) error {
+	var input MigrateToDiskTagInput
+	id := mux.Vars(req)["name"]
+
+	apiContext := api.GetApiContext(req)
+	if err := apiContext.Read(&input); err != nil {
+		return errors.Wrap(err, "failed to read MigrateToDiskTag input")
+	}
+
+	obj, err := util.RetryOnConflictCause(func() (interface{}, error) {
+		return s.m.MigrateToDiskTag(id, input.DiskSelector)
+	})
+	if err != nil {
+		return err
+	}
+	v, ok := obj.(*longhorn.Volume)
+	if !ok {
+		return fmt.Errorf("failed to convert to volume %v object", id)
+	}
+	return s.responseWithVolume(rw, req, "", v)
+}
+
 func (s *Server) VolumeUpdateFreezeFilesystemForSnapshot(rw http.ResponseWriter, req *http.Request) error {
 	var input UpdateFreezeFilesystemForSnapshotInput
 	id := mux.Vars(req)["name"]
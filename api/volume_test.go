@@ -0,0 +1,217 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/client-go/kubernetes/fake"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/controller"
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/manager"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+)
+
+const (
+	testNamespace  = "longhorn-system"
+	testVolumeName = "test-volume"
+	testNodeName   = "test-node"
+)
+
+type fakeAPIServer struct {
+	router http.Handler
+
+	lhClient          *lhfake.Clientset
+	informerFactories *util.InformerFactories
+}
+
+func newFakeAPIServer() *fakeAPIServer {
+	lhClient := lhfake.NewSimpleClientset()
+	kubeClient := fake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(testNamespace, kubeClient, lhClient, kubecontroller.NoResyncPeriodFunc())
+	ds := datastore.NewDataStore(testNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+
+	m := manager.NewVolumeManager(testNodeName, ds, util.NewAtomicCounter())
+	wsc, err := controller.NewWebsocketController(logrus.StandardLogger(), ds)
+	if err != nil {
+		panic(err)
+	}
+	return &fakeAPIServer{
+		router:            NewRouter(NewServer(m, wsc, controller.NewBackoffRegistry())),
+		lhClient:          lhClient,
+		informerFactories: informerFactories,
+	}
+}
+
+func (s *fakeAPIServer) addVolume(t *testing.T, v *longhorn.Volume) {
+	v, err := s.lhClient.LonghornV1beta2().Volumes(testNamespace).Create(context.TODO(), v, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, s.informerFactories.LhInformerFactory.Longhorn().V1beta2().Volumes().Informer().GetIndexer().Add(v))
+}
+
+func (s *fakeAPIServer) getVolume(t *testing.T, name string) *longhorn.Volume {
+	v, err := s.lhClient.LonghornV1beta2().Volumes(testNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return v
+}
+
+// doVolumeAction posts the action of the volume with the input, and returns the response code and body
+func (s *fakeAPIServer) doVolumeAction(t *testing.T, name, action string, input interface{}) (int, map[string]interface{}) {
+	body, err := json.Marshal(input)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/volumes/"+name+"?action="+action, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	resp := map[string]interface{}{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp), "response %v", rec.Body.String())
+	return rec.Code, resp
+}
+
+func newTestVolume() *longhorn.Volume {
+	return &longhorn.Volume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testVolumeName,
+			Namespace: testNamespace,
+		},
+		Spec: longhorn.VolumeSpec{
+			NumberOfReplicas: 3,
+		},
+		Status: longhorn.VolumeStatus{
+			State: longhorn.VolumeStateAttached,
+		},
+	}
+}
+
+func TestVolumeUpdateFilesystemCondition(t *testing.T) {
+	s := newFakeAPIServer()
+	s.addVolume(t, newTestVolume())
+
+	code, resp := s.doVolumeAction(t, testVolumeName, "updateFilesystemCondition", UpdateFilesystemConditionInput{
+		Type:    longhorn.VolumeConditionTypeFilesystemCheckRequired,
+		Status:  string(longhorn.ConditionStatusTrue),
+		Reason:  longhorn.VolumeConditionReasonFilesystemCheckFailure,
+		Message: "Filesystem check failed on node test-node",
+	})
+	require.Equal(t, http.StatusOK, code, "response %v", resp)
+	require.Equal(t, testVolumeName, resp["name"])
+
+	condition := types.GetCondition(s.getVolume(t, testVolumeName).Status.Conditions, longhorn.VolumeConditionTypeFilesystemCheckRequired)
+	require.Equal(t, longhorn.ConditionStatusTrue, condition.Status)
+	require.Equal(t, longhorn.VolumeConditionReasonFilesystemCheckFailure, condition.Reason)
+	require.Equal(t, "Filesystem check failed on node test-node", condition.Message)
+
+	code, resp = s.doVolumeAction(t, testVolumeName, "updateFilesystemCondition", UpdateFilesystemConditionInput{
+		Type:    longhorn.VolumeConditionTypeFilesystemCheckRequired,
+		Status:  string(longhorn.ConditionStatusFalse),
+		Reason:  longhorn.VolumeConditionReasonFilesystemCheckPassed,
+		Message: "Filesystem check passed on node test-node",
+	})
+	require.Equal(t, http.StatusOK, code, "response %v", resp)
+	condition = types.GetCondition(s.getVolume(t, testVolumeName).Status.Conditions, longhorn.VolumeConditionTypeFilesystemCheckRequired)
+	require.Equal(t, longhorn.ConditionStatusFalse, condition.Status)
+	require.Equal(t, longhorn.VolumeConditionReasonFilesystemCheckPassed, condition.Reason)
+}
+
+func TestVolumeUpdateFilesystemConditionInvalidInput(t *testing.T) {
+	tests := map[string]struct {
+		volumeName string
+		input      UpdateFilesystemConditionInput
+	}{
+		"not a filesystem condition": {
+			volumeName: testVolumeName,
+			input:      UpdateFilesystemConditionInput{Type: longhorn.VolumeConditionTypeScheduled, Status: string(longhorn.ConditionStatusFalse)},
+		},
+		"invalid status": {
+			volumeName: testVolumeName,
+			input:      UpdateFilesystemConditionInput{Type: longhorn.VolumeConditionTypeFilesystemResizeFailed, Status: "Unknown"},
+		},
+		"volume not found": {
+			volumeName: "missing",
+			input:      UpdateFilesystemConditionInput{Type: longhorn.VolumeConditionTypeFilesystemResizeFailed, Status: string(longhorn.ConditionStatusTrue)},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newFakeAPIServer()
+			s.addVolume(t, newTestVolume())
+
+			code, resp := s.doVolumeAction(t, tc.volumeName, "updateFilesystemCondition", tc.input)
+			require.NotEqual(t, http.StatusOK, code, "response %v", resp)
+			require.Equal(t, "error", resp["type"])
+			require.Empty(t, s.getVolume(t, testVolumeName).Status.Conditions)
+		})
+	}
+}
+
+func (s *fakeAPIServer) addReplica(t *testing.T, r *longhorn.Replica) {
+	r, err := s.lhClient.LonghornV1beta2().Replicas(testNamespace).Create(context.TODO(), r, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, s.informerFactories.LhInformerFactory.Longhorn().V1beta2().Replicas().Informer().GetIndexer().Add(r))
+}
+
+func (s *fakeAPIServer) getReplica(t *testing.T, name string) *longhorn.Replica {
+	r, err := s.lhClient.LonghornV1beta2().Replicas(testNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return r
+}
+
+func newTestReplica(name string) *longhorn.Replica {
+	return &longhorn.Replica{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+			Labels:    types.GetVolumeLabels(testVolumeName),
+		},
+		Spec: longhorn.ReplicaSpec{
+			InstanceSpec: longhorn.InstanceSpec{
+				VolumeName:  testVolumeName,
+				NodeID:      testNodeName,
+				DesireState: longhorn.InstanceStateRunning,
+			},
+		},
+	}
+}
+
+func TestVolumeReplicaRebuildActions(t *testing.T) {
+	s := newFakeAPIServer()
+	s.addVolume(t, newTestVolume())
+	s.addReplica(t, newTestReplica("replica-1"))
+
+	code, resp := s.doVolumeAction(t, testVolumeName, "replicaRebuildPause", ReplicaRebuildInput{Name: "replica-1"})
+	require.Equal(t, http.StatusOK, code, "response %v", resp)
+	require.Equal(t, testVolumeName, resp["name"])
+	require.True(t, s.getReplica(t, "replica-1").Spec.RebuildPaused)
+
+	code, resp = s.doVolumeAction(t, testVolumeName, "updateReplicaRebuildPriority",
+		UpdateReplicaRebuildPriorityInput{Name: "replica-1", RebuildPriority: 10})
+	require.Equal(t, http.StatusOK, code, "response %v", resp)
+	require.Equal(t, 10, s.getReplica(t, "replica-1").Spec.RebuildPriority)
+
+	code, resp = s.doVolumeAction(t, testVolumeName, "replicaRebuildResume", ReplicaRebuildInput{Name: "replica-1"})
+	require.Equal(t, http.StatusOK, code, "response %v", resp)
+	require.False(t, s.getReplica(t, "replica-1").Spec.RebuildPaused)
+
+	code, resp = s.doVolumeAction(t, testVolumeName, "replicaRebuildPause", ReplicaRebuildInput{Name: "missing"})
+	require.NotEqual(t, http.StatusOK, code, "response %v", resp)
+	require.Equal(t, "error", resp["type"])
+}
@@ -31,6 +31,50 @@ func CSICommand() cli.Command {
 				Value: "",
 				Usage: "Longhorn manager API URL",
 			},
+			cli.Float64Flag{
+				Name:  "rate-limit",
+				Value: csi.DefaultCSIPluginRateLimit,
+				Usage: "Maximum number of CSI calls handled per second, 0 means unlimited",
+			},
+			cli.IntFlag{
+				Name:  "rate-limit-burst",
+				Value: csi.DefaultCSIPluginRateLimitBurst,
+				Usage: "Maximum burst of CSI calls handled over the rate limit",
+			},
+			cli.DurationFlag{
+				Name:  "wait-initial-interval",
+				Value: csi.DefaultWaitInitialInterval,
+				Usage: "Initial polling interval of the waits for a volume to be created, attached or detached",
+			},
+			cli.DurationFlag{
+				Name:  "wait-max-interval",
+				Value: csi.DefaultWaitMaxInterval,
+				Usage: "Maximum polling interval of the waits for a volume to be created, attached or detached, the interval doubles up to it",
+			},
+			cli.DurationFlag{
+				Name:  "wait-timeout",
+				Value: csi.DefaultWaitTimeout,
+				Usage: "Timeout of the waits for a volume to be created, attached or detached",
+			},
+			cli.DurationFlag{
+				Name:  "force-unmount-timeout",
+				Value: csi.DefaultForceUnmountTimeout,
+				Usage: "Timeout of the force unmounts of the volumes, overridden by the forceUnmountTimeout parameter of the StorageClass and the " + types.PVCAnnotationLonghornForceUnmountTimeout + " annotation of the PVC",
+			},
+			cli.DurationFlag{
+				Name:  "watchdog-threshold",
+				Value: csi.DefaultWatchdogThreshold,
+				Usage: "Duration after which a running CSI call is reported as stuck by the watchdog, 0 disables the watchdog",
+			},
+			cli.BoolFlag{
+				Name:  "watchdog-force-unmount",
+				Usage: "Force unmount the path of the stuck unmount calls to abort them, e.g. when the NFS server of a RWX volume is unreachable",
+			},
+			cli.IntFlag{
+				Name:  "watchdog-port",
+				Value: csi.DefaultCSIWatchdogPort,
+				Usage: "Port of the watchdog liveness endpoint, which responds 503 with the stuck CSI calls",
+			},
 		},
 		Action: func(c *cli.Context) {
 			if err := runCSI(c); err != nil {
@@ -47,5 +91,16 @@ func runCSI(c *cli.Context) error {
 		c.String("nodeid"),
 		c.String("endpoint"),
 		identityVersion,
-		c.String("manager-url"))
+		c.String("manager-url"),
+		c.Float64("rate-limit"),
+		c.Int("rate-limit-burst"),
+		csi.WaitBackoff{
+			InitialInterval: c.Duration("wait-initial-interval"),
+			MaxInterval:     c.Duration("wait-max-interval"),
+			Timeout:         c.Duration("wait-timeout"),
+		},
+		c.Duration("force-unmount-timeout"),
+		c.Duration("watchdog-threshold"),
+		c.Bool("watchdog-force-unmount"),
+		c.Int("watchdog-port"))
 }
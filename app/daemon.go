@@ -309,10 +309,11 @@ func startManager(c *cli.Context) error {
 	}
 
 	proxyConnCounter := util.NewAtomicCounter()
+	backoffs := controller.NewBackoffRegistry()
 
 	wsc, err := controller.StartControllers(logger, clients,
 		currentNodeID, serviceAccount, managerImage, backingImageManagerImage, shareManagerImage,
-		kubeconfigPath, meta.Version, proxyConnCounter)
+		kubeconfigPath, meta.Version, proxyConnCounter, backoffs)
 	if err != nil {
 		return err
 	}
@@ -347,7 +348,7 @@ func startManager(c *cli.Context) error {
 		return err
 	}
 
-	server := api.NewServer(m, wsc)
+	server := api.NewServer(m, wsc, backoffs)
 	router := http.Handler(api.NewRouter(server))
 	router = util.FilteredLoggingHandler(os.Stdout, router)
 	router = handlers.ProxyHeaders(router)
@@ -370,7 +371,22 @@ func startManager(c *cli.Context) error {
 		}
 	}()
 
+	// The previous manager pod of the node may have handed off the node, the new one takes it over now
+	if err := clients.Datastore.ClearNodeManagerHandoff(currentNodeID); err != nil {
+		logger.WithError(err).Warn("Failed to clear the manager handoff of the node")
+	}
+
 	<-ctx.Done()
+
+	// Shutting down gracefully, e.g. during a rolling upgrade. Hand off the node so the resources of the node are
+	// left to the new manager pod of the node instead of being taken over by the managers of the other nodes.
+	// No state of the in-flight operations is checkpointed here. The new manager pod adopts them from the state
+	// already recorded in the engine and backup objects.
+	if err := clients.Datastore.SetNodeManagerHandoff(currentNodeID); err != nil {
+		logger.WithError(err).Warn("Failed to hand off the node")
+	} else {
+		logger.Info("Handed off the node to the next manager pod")
+	}
 	return nil
 }
 
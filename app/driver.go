@@ -53,6 +53,21 @@ const (
 	EnvCSIProvisionerReplicaCount  = "CSI_PROVISIONER_REPLICA_COUNT"
 	EnvCSIResizerReplicaCount      = "CSI_RESIZER_REPLICA_COUNT"
 	EnvCSISnapshotterReplicaCount  = "CSI_SNAPSHOTTER_REPLICA_COUNT"
+
+	FlagCSIPluginRateLimit      = "csi-plugin-rate-limit"
+	FlagCSIPluginRateLimitBurst = "csi-plugin-rate-limit-burst"
+	EnvCSIPluginRateLimit       = "CSI_PLUGIN_RATE_LIMIT"
+	EnvCSIPluginRateLimitBurst  = "CSI_PLUGIN_RATE_LIMIT_BURST"
+
+	FlagCSIPluginWaitInitialInterval = "csi-plugin-wait-initial-interval"
+	FlagCSIPluginWaitMaxInterval     = "csi-plugin-wait-max-interval"
+	FlagCSIPluginWaitTimeout         = "csi-plugin-wait-timeout"
+	EnvCSIPluginWaitInitialInterval  = "CSI_PLUGIN_WAIT_INITIAL_INTERVAL"
+	EnvCSIPluginWaitMaxInterval      = "CSI_PLUGIN_WAIT_MAX_INTERVAL"
+	EnvCSIPluginWaitTimeout          = "CSI_PLUGIN_WAIT_TIMEOUT"
+
+	FlagCSISELinuxMount = "csi-selinux-mount"
+	EnvCSISELinuxMount  = "CSI_SELINUX_MOUNT"
 )
 
 func DeployDriverCmd() cli.Command {
@@ -126,6 +141,41 @@ func DeployDriverCmd() cli.Command {
 				Usage:  "Specify CSI liveness probe image",
 				EnvVar: EnvCSILivenessProbeImage,
 			},
+			cli.Float64Flag{
+				Name:   FlagCSIPluginRateLimit,
+				Usage:  "Specify maximum number of calls per second handled by the CSI plugin, 0 means unlimited",
+				EnvVar: EnvCSIPluginRateLimit,
+				Value:  csi.DefaultCSIPluginRateLimit,
+			},
+			cli.IntFlag{
+				Name:   FlagCSIPluginRateLimitBurst,
+				Usage:  "Specify maximum burst of calls handled by the CSI plugin over the rate limit",
+				EnvVar: EnvCSIPluginRateLimitBurst,
+				Value:  csi.DefaultCSIPluginRateLimitBurst,
+			},
+			cli.DurationFlag{
+				Name:   FlagCSIPluginWaitInitialInterval,
+				Usage:  "Specify initial polling interval of the CSI plugin waits for a volume to be created, attached or detached",
+				EnvVar: EnvCSIPluginWaitInitialInterval,
+				Value:  csi.DefaultWaitInitialInterval,
+			},
+			cli.DurationFlag{
+				Name:   FlagCSIPluginWaitMaxInterval,
+				Usage:  "Specify maximum polling interval of the CSI plugin waits for a volume to be created, attached or detached",
+				EnvVar: EnvCSIPluginWaitMaxInterval,
+				Value:  csi.DefaultWaitMaxInterval,
+			},
+			cli.DurationFlag{
+				Name:   FlagCSIPluginWaitTimeout,
+				Usage:  "Specify timeout of the CSI plugin waits for a volume to be created, attached or detached",
+				EnvVar: EnvCSIPluginWaitTimeout,
+				Value:  csi.DefaultWaitTimeout,
+			},
+			cli.BoolFlag{
+				Name:   FlagCSISELinuxMount,
+				Usage:  "Specify whether the volumes of a single pod are mounted with the SELinux context of the pod rather than relabeled recursively",
+				EnvVar: EnvCSISELinuxMount,
+			},
 			cli.StringFlag{
 				Name:  FlagKubeConfig,
 				Usage: "Specify path to kube config (optional)",
@@ -221,6 +271,16 @@ func deployCSIDriver(kubeClient *clientset.Clientset, lhClient *lhclientset.Clie
 	csiProvisionerReplicaCount := c.Int(FlagCSIProvisionerReplicaCount)
 	csiSnapshotterReplicaCount := c.Int(FlagCSISnapshotterReplicaCount)
 	csiResizerReplicaCount := c.Int(FlagCSIResizerReplicaCount)
+	csiPluginRateLimit := c.Float64(FlagCSIPluginRateLimit)
+	csiPluginRateLimitBurst := c.Int(FlagCSIPluginRateLimitBurst)
+	csiPluginWaitBackoff := csi.WaitBackoff{
+		InitialInterval: c.Duration(FlagCSIPluginWaitInitialInterval),
+		MaxInterval:     c.Duration(FlagCSIPluginWaitMaxInterval),
+		Timeout:         c.Duration(FlagCSIPluginWaitTimeout),
+	}
+	if err := csiPluginWaitBackoff.Validate(); err != nil {
+		return errors.Wrap(err, "invalid CSI plugin wait backoff")
+	}
 	namespace := os.Getenv(types.EnvPodNamespace)
 	serviceAccountName := os.Getenv(types.EnvServiceAccount)
 	rootDir := c.String(FlagKubeletRootDir)
@@ -233,10 +293,6 @@ func deployCSIDriver(kubeClient *clientset.Clientset, lhClient *lhclientset.Clie
 	if err != nil {
 		return err
 	}
-	tolerationsByte, err := json.Marshal(tolerations)
-	if err != nil {
-		return err
-	}
 
 	nodeSelectorSetting, err := lhClient.LonghornV1beta2().Settings(namespace).Get(context.TODO(), string(types.SettingNameSystemManagedComponentsNodeSelector), metav1.GetOptions{})
 	if err != nil {
@@ -253,6 +309,29 @@ func deployCSIDriver(kubeClient *clientset.Clientset, lhClient *lhclientset.Clie
 	}
 	priorityClass := priorityClassSetting.Value
 
+	schedulingOverridesSetting, err := lhClient.LonghornV1beta2().Settings(namespace).Get(context.TODO(), string(types.SettingNameSystemManagedComponentsSchedulingOverrides), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	schedulingOverrides, err := types.UnmarshalSystemManagedComponentsSchedulingOverrides(schedulingOverridesSetting.Value)
+	if err != nil {
+		return err
+	}
+	csiScheduling := schedulingOverrides[types.SystemManagedComponentCSI]
+	if csiScheduling.PriorityClass != "" {
+		priorityClass = csiScheduling.PriorityClass
+	}
+	if csiScheduling.TaintToleration != "" {
+		if tolerations, err = types.UnmarshalTolerations(csiScheduling.TaintToleration); err != nil {
+			return err
+		}
+	}
+	topologySpreadConstraints := csiScheduling.TopologySpreadConstraints
+	tolerationsByte, err := json.Marshal(tolerations)
+	if err != nil {
+		return err
+	}
+
 	registrySecretSetting, err := lhClient.LonghornV1beta2().Settings(namespace).Get(context.TODO(), string(types.SettingNameRegistrySecret), metav1.GetOptions{})
 	if err != nil {
 		return err
@@ -307,32 +386,32 @@ func deployCSIDriver(kubeClient *clientset.Clientset, lhClient *lhclientset.Clie
 		return err
 	}
 
-	csiDriverObjectDeployment := csi.NewCSIDriverObject()
+	csiDriverObjectDeployment := csi.NewCSIDriverObject(c.Bool(FlagCSISELinuxMount))
 	if err := csiDriverObjectDeployment.Deploy(kubeClient); err != nil {
 		return err
 	}
 
-	attacherDeployment := csi.NewAttacherDeployment(namespace, serviceAccountName, csiAttacherImage, rootDir, csiAttacherReplicaCount, tolerations, string(tolerationsByte), priorityClass, registrySecret, imagePullPolicy, nodeSelector)
+	attacherDeployment := csi.NewAttacherDeployment(namespace, serviceAccountName, csiAttacherImage, rootDir, csiAttacherReplicaCount, tolerations, string(tolerationsByte), priorityClass, registrySecret, imagePullPolicy, nodeSelector, topologySpreadConstraints)
 	if err := attacherDeployment.Deploy(kubeClient); err != nil {
 		return err
 	}
 
-	provisionerDeployment := csi.NewProvisionerDeployment(namespace, serviceAccountName, csiProvisionerImage, rootDir, csiProvisionerReplicaCount, tolerations, string(tolerationsByte), priorityClass, registrySecret, imagePullPolicy, nodeSelector)
+	provisionerDeployment := csi.NewProvisionerDeployment(namespace, serviceAccountName, csiProvisionerImage, rootDir, csiProvisionerReplicaCount, tolerations, string(tolerationsByte), priorityClass, registrySecret, imagePullPolicy, nodeSelector, topologySpreadConstraints)
 	if err := provisionerDeployment.Deploy(kubeClient); err != nil {
 		return err
 	}
 
-	resizerDeployment := csi.NewResizerDeployment(namespace, serviceAccountName, csiResizerImage, rootDir, csiResizerReplicaCount, tolerations, string(tolerationsByte), priorityClass, registrySecret, imagePullPolicy, nodeSelector)
+	resizerDeployment := csi.NewResizerDeployment(namespace, serviceAccountName, csiResizerImage, rootDir, csiResizerReplicaCount, tolerations, string(tolerationsByte), priorityClass, registrySecret, imagePullPolicy, nodeSelector, topologySpreadConstraints)
 	if err := resizerDeployment.Deploy(kubeClient); err != nil {
 		return err
 	}
 
-	snapshotterDeployment := csi.NewSnapshotterDeployment(namespace, serviceAccountName, csiSnapshotterImage, rootDir, csiSnapshotterReplicaCount, tolerations, string(tolerationsByte), priorityClass, registrySecret, imagePullPolicy, nodeSelector)
+	snapshotterDeployment := csi.NewSnapshotterDeployment(namespace, serviceAccountName, csiSnapshotterImage, rootDir, csiSnapshotterReplicaCount, tolerations, string(tolerationsByte), priorityClass, registrySecret, imagePullPolicy, nodeSelector, topologySpreadConstraints)
 	if err := snapshotterDeployment.Deploy(kubeClient); err != nil {
 		return err
 	}
 
-	pluginDeployment := csi.NewPluginDeployment(namespace, serviceAccountName, csiNodeDriverRegistrarImage, csiLivenessProbeImage, managerImage, managerURL, rootDir, tolerations, string(tolerationsByte), priorityClass, registrySecret, imagePullPolicy, nodeSelector, storageNetworkSetting, isStorageNetworkForRWXVolumeEnabled)
+	pluginDeployment := csi.NewPluginDeployment(namespace, serviceAccountName, csiNodeDriverRegistrarImage, csiLivenessProbeImage, managerImage, managerURL, rootDir, csiPluginRateLimit, csiPluginRateLimitBurst, csiPluginWaitBackoff, tolerations, string(tolerationsByte), priorityClass, registrySecret, imagePullPolicy, nodeSelector, storageNetworkSetting, isStorageNetworkForRWXVolumeEnabled)
 	if err := pluginDeployment.Deploy(kubeClient); err != nil {
 		return err
 	}
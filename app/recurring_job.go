@@ -76,6 +76,8 @@ func recurringJob(c *cli.Context) (err error) {
 	switch recurringJob.Spec.Task {
 	case longhorn.RecurringJobTypeSystemBackup:
 		return recurringjob.StartSystemBackupJob(job, recurringJob)
+	case longhorn.RecurringJobTypeBackupBackingImage:
+		return recurringjob.StartBackupBackingImageJob(job, recurringJob)
 	default:
 		return recurringjob.StartVolumeJobs(job, recurringJob)
 	}
@@ -0,0 +1,244 @@
+package recurringjob
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	lhutils "github.com/longhorn/go-common-libs/utils"
+
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// StartBackupBackingImageJob backs up the backing images which are new, or changed since they were last backed up,
+// to the backup target. A backing image only has one backup on a backup target, so the retain count applies to the
+// backups created by the job for the backing images which have been deleted since.
+func StartBackupBackingImageJob(job *Job, recurringJob *longhorn.RecurringJob) error {
+	backupBackingImageJob := newBackupBackingImageJob(job)
+
+	defer backupBackingImageJob.cleanup()
+
+	if err := backupBackingImageJob.run(); err != nil {
+		backupBackingImageJob.logger.WithError(err).Error("Failed to run backing image backup job")
+		return err
+	}
+
+	backupBackingImageJob.logger.Info("Created backing image backup job")
+
+	return nil
+}
+
+func newBackupBackingImageJob(job *Job) *BackupBackingImageJob {
+	backupTargetName := job.parameters[types.RecurringJobParameterBackupTarget]
+	if backupTargetName == "" {
+		backupTargetName = types.DefaultBackupTargetName
+	}
+
+	logger := job.logger.WithFields(logrus.Fields{
+		// job-specific fields
+		"job":            job.name,
+		"task":           job.task,
+		"retain":         job.retain,
+		"parameters":     job.parameters,
+		"executionCount": job.executionCount,
+		// backing-image-backup-specific fields
+		"backupTarget": backupTargetName,
+	})
+
+	return &BackupBackingImageJob{
+		Job:              job,
+		logger:           logger,
+		backupTargetName: backupTargetName,
+	}
+}
+
+func (job *BackupBackingImageJob) run() (err error) {
+	job.logger.Info("Starting backing image backup job")
+	defer func() {
+		if err != nil {
+			job.logger.WithError(err).Error("Failed to run backing image backup job")
+		} else {
+			job.logger.Info("Finished running backing image backup job")
+		}
+	}()
+
+	backingImageList, err := job.ListBackingImages()
+	if err != nil {
+		return err
+	}
+
+	backupBackingImages, err := job.listBackupBackingImagesOfBackupTarget()
+	if err != nil {
+		return err
+	}
+
+	createdBackupBackingImages := []string{}
+	for _, backingImage := range backingImageList.Items {
+		log := job.logger.WithField("backingImage", backingImage.Name)
+
+		// v2 backing images don't support backup currently
+		if !types.IsDataEngineV1(backingImage.Spec.DataEngine) {
+			continue
+		}
+		// the checksum is only known once the backing image file is ready
+		if backingImage.Status.Checksum == "" {
+			log.Info("Skipping backing image which is not ready")
+			continue
+		}
+
+		backupBackingImage, exists := backupBackingImages[backingImage.Name]
+		if exists {
+			if backupBackingImage.Status.Checksum == "" || backupBackingImage.Status.Checksum == backingImage.Status.Checksum {
+				continue
+			}
+			log.Infof("Deleting backup backing image %v since the checksum of the backing image changed", backupBackingImage.Name)
+			if err := job.DeleteBackupBackingImage(backupBackingImage.Name); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			if err := job.waitForBackupBackingImageDeletion(backupBackingImage.Name); err != nil {
+				return err
+			}
+		}
+
+		backupBackingImageName := types.GetBackupBackingImageNameFromBIName(backingImage.Name)
+		log.Infof("Creating backup backing image %v", backupBackingImageName)
+		newBackupBackingImage := &longhorn.BackupBackingImage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: backupBackingImageName,
+				Labels: map[string]string{
+					types.LonghornLabelBackupTarget: job.backupTargetName,
+					types.LonghornLabelBackingImage: backingImage.Name,
+					types.GetRecurringJobLabelKey(types.LonghornLabelRecurringJob, string(longhorn.RecurringJobTypeBackupBackingImage)): job.name,
+				},
+			},
+			Spec: longhorn.BackupBackingImageSpec{
+				UserCreated:      true,
+				BackingImage:     backingImage.Name,
+				BackupTargetName: job.backupTargetName,
+			},
+		}
+		if _, err := job.CreateBackupBackingImage(newBackupBackingImage); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+		createdBackupBackingImages = append(createdBackupBackingImages, backupBackingImageName)
+	}
+
+	for _, name := range createdBackupBackingImages {
+		if err := job.waitForBackupBackingImageCompletion(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listBackupBackingImagesOfBackupTarget returns the backups on the backup target of the job by backing image names
+func (job *BackupBackingImageJob) listBackupBackingImagesOfBackupTarget() (map[string]*longhorn.BackupBackingImage, error) {
+	backupBackingImageList, err := job.ListBackupBackingImages()
+	if err != nil {
+		return nil, err
+	}
+
+	backupBackingImages := map[string]*longhorn.BackupBackingImage{}
+	for i := range backupBackingImageList.Items {
+		backupBackingImage := &backupBackingImageList.Items[i]
+		if backupBackingImage.Spec.BackupTargetName != job.backupTargetName || !backupBackingImage.DeletionTimestamp.IsZero() {
+			continue
+		}
+		backupBackingImages[backupBackingImage.Spec.BackingImage] = backupBackingImage
+	}
+	return backupBackingImages, nil
+}
+
+// cleanup deletes the backups created by the job for the backing images which have been deleted, except the
+// latest retained ones.
+func (job *BackupBackingImageJob) cleanup() {
+	job.logger.Info("Cleaning up expired backing image backups")
+	defer job.logger.Info("Finished cleaning up expired backing image backups")
+
+	backingImageList, err := job.ListBackingImages()
+	if err != nil {
+		job.logger.WithError(err).Warn("Failed to list backing images")
+		return
+	}
+	backingImages := map[string]struct{}{}
+	for _, backingImage := range backingImageList.Items {
+		backingImages[backingImage.Name] = struct{}{}
+	}
+
+	backupBackingImageList, err := job.ListBackupBackingImages()
+	if err != nil {
+		job.logger.WithError(err).Warn("Failed to list backup backing images")
+		return
+	}
+
+	labelKey := types.GetRecurringJobLabelKey(types.LonghornLabelRecurringJob, string(longhorn.RecurringJobTypeBackupBackingImage))
+	orphanedBackups := []NameWithTimestamp{}
+	for _, backupBackingImage := range backupBackingImageList.Items {
+		if backupBackingImage.Labels[labelKey] != job.name || backupBackingImage.Spec.BackupTargetName != job.backupTargetName {
+			continue
+		}
+		if _, exists := backingImages[backupBackingImage.Spec.BackingImage]; exists {
+			continue
+		}
+		createdAt, err := time.Parse(time.RFC3339, backupBackingImage.Status.BackupCreatedAt)
+		if err != nil {
+			createdAt = backupBackingImage.CreationTimestamp.Time
+		}
+		orphanedBackups = append(orphanedBackups, NameWithTimestamp{
+			Name:      backupBackingImage.Name,
+			Timestamp: createdAt,
+		})
+	}
+
+	for _, name := range filterExpiredItems(orphanedBackups, job.retain) {
+		job.logger.Infof("Deleting backup backing image %v", name)
+		if err := job.DeleteBackupBackingImage(name); err != nil && !apierrors.IsNotFound(err) {
+			job.logger.WithError(err).Warnf("Failed to delete backup backing image %v", name)
+		}
+	}
+}
+
+func (job *BackupBackingImageJob) waitForBackupBackingImageCompletion(name string) error {
+	failedStates := []longhorn.BackupState{
+		longhorn.BackupStateError,
+		longhorn.BackupStateUnknown,
+	}
+
+	for {
+		backupBackingImage, err := job.GetBackupBackingImage(name)
+		if err != nil {
+			return err
+		}
+
+		switch state := backupBackingImage.Status.State; {
+		case state == longhorn.BackupStateCompleted:
+			job.logger.Infof("Backup backing image %v completed", name)
+			return nil
+		case lhutils.Contains(failedStates, state):
+			return fmt.Errorf("backup backing image %v ended in state %v: %v", name, state, backupBackingImage.Status.Error)
+		}
+
+		job.logger.Infof("Waiting for backup backing image %v to complete, current state is %v", name, backupBackingImage.Status.State)
+		time.Sleep(WaitInterval)
+	}
+}
+
+func (job *BackupBackingImageJob) waitForBackupBackingImageDeletion(name string) error {
+	for {
+		if _, err := job.GetBackupBackingImage(name); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+
+		job.logger.Infof("Waiting for backup backing image %v to be deleted", name)
+		time.Sleep(WaitInterval)
+	}
+}
@@ -85,6 +85,11 @@ func (job *Job) UpdateVolumeStatus(v *longhorn.Volume) (*longhorn.Volume, error)
 	return job.lhClient.LonghornV1beta2().Volumes(job.namespace).UpdateStatus(context.TODO(), v, metav1.UpdateOptions{})
 }
 
+// GetSetting returns the setting searching by name.
+func (job *Job) GetSetting(name types.SettingName) (*longhorn.Setting, error) {
+	return job.lhClient.LonghornV1beta2().Settings(job.namespace).Get(context.TODO(), string(name), metav1.GetOptions{})
+}
+
 // GetSettingAsBool returns boolean of the setting value searching by name.
 func (job *Job) GetSettingAsBool(name types.SettingName) (bool, error) {
 	obj, err := job.lhClient.LonghornV1beta2().Settings(job.namespace).Get(context.TODO(), string(name), metav1.GetOptions{})
@@ -120,3 +125,23 @@ func (job *Job) ListSystemBackup() (*longhorn.SystemBackupList, error) {
 		LabelSelector: label,
 	})
 }
+
+func (job *Job) ListBackingImages() (*longhorn.BackingImageList, error) {
+	return job.lhClient.LonghornV1beta2().BackingImages(job.namespace).List(context.TODO(), metav1.ListOptions{})
+}
+
+func (job *Job) ListBackupBackingImages() (*longhorn.BackupBackingImageList, error) {
+	return job.lhClient.LonghornV1beta2().BackupBackingImages(job.namespace).List(context.TODO(), metav1.ListOptions{})
+}
+
+func (job *Job) GetBackupBackingImage(name string) (*longhorn.BackupBackingImage, error) {
+	return job.lhClient.LonghornV1beta2().BackupBackingImages(job.namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (job *Job) CreateBackupBackingImage(backupBackingImage *longhorn.BackupBackingImage) (*longhorn.BackupBackingImage, error) {
+	return job.lhClient.LonghornV1beta2().BackupBackingImages(job.namespace).Create(context.TODO(), backupBackingImage, metav1.CreateOptions{})
+}
+
+func (job *Job) DeleteBackupBackingImage(name string) error {
+	return job.lhClient.LonghornV1beta2().BackupBackingImages(job.namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
@@ -0,0 +1,299 @@
+package recurringjob
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+
+	etypes "github.com/longhorn/longhorn-engine/pkg/types"
+
+	"github.com/longhorn/longhorn-manager/constant"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhornclient "github.com/longhorn/longhorn-manager/client"
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	MaintenanceStepFilesystemTrim       = "filesystem-trim"
+	MaintenanceStepSnapshotPurge        = "snapshot-purge"
+	MaintenanceStepChecksumVerification = "checksum-verification"
+	MaintenanceStepBackupVerification   = "backup-verification"
+
+	MaintenanceStepResultSucceeded = "succeeded"
+	MaintenanceStepResultFailed    = "failed"
+	MaintenanceStepResultSkipped   = "skipped"
+
+	maintenanceSnapshotHashPollInterval = 5 * time.Second
+	maintenanceSnapshotHashTimeout      = 30 * time.Minute
+)
+
+// MaintenanceStepReport is the result of a step of the maintenance of a volume.
+type MaintenanceStepReport struct {
+	Step    string
+	Result  string
+	Message string
+}
+
+// MaintenanceReport is the consolidated result of the maintenance of a volume.
+type MaintenanceReport struct {
+	VolumeName string
+	Steps      []MaintenanceStepReport
+	Duration   time.Duration
+}
+
+func (r *MaintenanceReport) add(step, result, message string) {
+	r.Steps = append(r.Steps, MaintenanceStepReport{
+		Step:    step,
+		Result:  result,
+		Message: message,
+	})
+}
+
+func (r *MaintenanceReport) failed() bool {
+	for _, step := range r.Steps {
+		if step.Result == MaintenanceStepResultFailed {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MaintenanceReport) String() string {
+	steps := []string{}
+	for _, step := range r.Steps {
+		if step.Message == "" {
+			steps = append(steps, fmt.Sprintf("%v: %v", step.Step, step.Result))
+			continue
+		}
+		steps = append(steps, fmt.Sprintf("%v: %v (%v)", step.Step, step.Result, step.Message))
+	}
+	return fmt.Sprintf("maintenance of volume %v finished in %v: %v", r.VolumeName, r.Duration.Round(time.Second), strings.Join(steps, "; "))
+}
+
+// doRecurringMaintenance trims the filesystem, purges the snapshots, verifies the snapshot checksums and verifies the
+// last backup of the volume in sequence. The steps which are not started within the maintenance window are skipped.
+// The consolidated report is recorded as an event of the recurring job.
+func (job *VolumeJob) doRecurringMaintenance(volume *longhornclient.Volume) (err error) {
+	startTime := time.Now()
+	deadline, err := job.getMaintenanceDeadline(startTime)
+	if err != nil {
+		return err
+	}
+
+	report := &MaintenanceReport{VolumeName: volume.Name}
+	steps := []struct {
+		name string
+		run  func(*longhornclient.Volume, time.Time) (string, string)
+	}{
+		{MaintenanceStepFilesystemTrim, job.maintenanceTrimFilesystem},
+		{MaintenanceStepSnapshotPurge, job.maintenancePurgeSnapshots},
+		{MaintenanceStepChecksumVerification, job.maintenanceVerifyChecksums},
+		{MaintenanceStepBackupVerification, job.maintenanceVerifyLastBackup},
+	}
+	for _, step := range steps {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			report.add(step.name, MaintenanceStepResultSkipped, "maintenance window elapsed")
+			continue
+		}
+
+		job.logger.Infof("Running maintenance step %v", step.name)
+		result, message := step.run(volume, deadline)
+		report.add(step.name, result, message)
+
+		// Retrieve the latest volume state for the next step
+		if latestVolume, err := job.api.Volume.ById(volume.Name); err == nil {
+			volume = latestVolume
+		}
+	}
+	report.Duration = time.Since(startTime)
+
+	eventType, eventReason := corev1.EventTypeNormal, constant.EventReasonMaintained
+	if report.failed() {
+		eventType, eventReason = corev1.EventTypeWarning, constant.EventReasonFailedMaintenance
+		job.logger.Warn(report.String())
+	} else {
+		job.logger.Info(report.String())
+	}
+	if err := job.eventCreate(eventType, eventReason, report.String()); err != nil {
+		job.logger.WithError(err).Warn("Failed to create maintenance report event")
+	}
+
+	if report.failed() {
+		return fmt.Errorf("failed %v", report.String())
+	}
+	return nil
+}
+
+// getMaintenanceDeadline returns the zero time if the job doesn't have a maintenance window
+func (job *VolumeJob) getMaintenanceDeadline(startTime time.Time) (time.Time, error) {
+	windowStr, exists := job.parameters[types.RecurringJobParameterMaintenanceWindow]
+	if !exists {
+		return time.Time{}, nil
+	}
+	window, err := strconv.Atoi(windowStr)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "maintenance window %v is not number", windowStr)
+	}
+	return startTime.Add(time.Duration(window) * time.Minute), nil
+}
+
+func (job *VolumeJob) maintenanceTrimFilesystem(volume *longhornclient.Volume, _ time.Time) (string, string) {
+	if volume.State != string(longhorn.VolumeStateAttached) {
+		return MaintenanceStepResultSkipped, "volume is not attached"
+	}
+	if _, err := job.api.Volume.ActionTrimFilesystem(volume); err != nil {
+		return MaintenanceStepResultFailed, err.Error()
+	}
+	return MaintenanceStepResultSucceeded, ""
+}
+
+func (job *VolumeJob) maintenancePurgeSnapshots(volume *longhornclient.Volume, _ time.Time) (string, string) {
+	if volume.State != string(longhorn.VolumeStateAttached) {
+		return MaintenanceStepResultSkipped, "volume is not attached"
+	}
+	if err := job.purgeSnapshots(volume, job.api.Volume); err != nil {
+		return MaintenanceStepResultFailed, err.Error()
+	}
+	return MaintenanceStepResultSucceeded, ""
+}
+
+// maintenanceVerifyChecksums reads the data of the user created snapshots of the volume again by hashing them on all
+// replicas through the engine. A snapshot fails the verification if a replica fails hashing or is silently corrupted,
+// if the checksums of the replicas differ, or if they differ from the checksum recorded before. The snapshots not
+// hashed in time are reported as unverified rather than failed.
+func (job *VolumeJob) maintenanceVerifyChecksums(volume *longhornclient.Volume, deadline time.Time) (string, string) {
+	dataIntegrity := longhorn.SnapshotDataIntegrity(volume.SnapshotDataIntegrity)
+	if dataIntegrity == longhorn.SnapshotDataIntegrityIgnored || dataIntegrity == "" {
+		setting, err := job.GetSetting(types.SettingNameSnapshotDataIntegrity)
+		if err != nil {
+			return MaintenanceStepResultFailed, errors.Wrapf(err, "failed to get %v setting", types.SettingNameSnapshotDataIntegrity).Error()
+		}
+		dataIntegrity = longhorn.SnapshotDataIntegrity(setting.Value)
+	}
+	if dataIntegrity == longhorn.SnapshotDataIntegrityDisabled {
+		return MaintenanceStepResultSkipped, "snapshot data integrity check is disabled"
+	}
+	if volume.State != string(longhorn.VolumeStateAttached) {
+		return MaintenanceStepResultSkipped, "volume is not attached"
+	}
+
+	snapshotCRList, err := job.api.Volume.ActionSnapshotCRList(volume)
+	if err != nil {
+		return MaintenanceStepResultFailed, err.Error()
+	}
+
+	verified, unverified, failed := 0, 0, []string{}
+	for _, snapshotCR := range snapshotCRList.Data {
+		if !snapshotCR.UserCreated || snapshotCR.MarkRemoved || snapshotCR.Name == etypes.VolumeHeadName {
+			continue
+		}
+
+		hashStatus, err := job.hashSnapshot(volume, snapshotCR.Name, deadline)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%v: %v", snapshotCR.Name, err))
+			continue
+		}
+		if hashStatus == nil {
+			unverified++
+			continue
+		}
+		if err := verifySnapshotHashStatus(hashStatus, snapshotCR.Checksum); err != nil {
+			failed = append(failed, fmt.Sprintf("%v: %v", snapshotCR.Name, err))
+			continue
+		}
+		verified++
+	}
+
+	message := fmt.Sprintf("%v snapshots verified, %v snapshots unverified", verified, unverified)
+	if len(failed) > 0 {
+		return MaintenanceStepResultFailed, fmt.Sprintf("%v, failed snapshots: %v", message, strings.Join(failed, ", "))
+	}
+	return MaintenanceStepResultSucceeded, message
+}
+
+// hashSnapshot hashes the snapshot on all replicas again and waits for the result. It returns nil if the hashing
+// doesn't finish before the deadline.
+func (job *VolumeJob) hashSnapshot(volume *longhornclient.Volume, snapshotName string, deadline time.Time) ([]longhornclient.SnapshotHashStatus, error) {
+	timeout := time.Now().Add(maintenanceSnapshotHashTimeout)
+	if !deadline.IsZero() && deadline.Before(timeout) {
+		timeout = deadline
+	}
+
+	input := &longhornclient.SnapshotInput{Name: snapshotName}
+	if _, err := job.api.Volume.ActionSnapshotHash(volume, input); err != nil {
+		return nil, err
+	}
+	for time.Now().Before(timeout) {
+		time.Sleep(maintenanceSnapshotHashPollInterval)
+
+		output, err := job.api.Volume.ActionSnapshotHashStatus(volume, input)
+		if err != nil {
+			return nil, err
+		}
+		if isSnapshotHashFinished(output.Data) {
+			return output.Data, nil
+		}
+	}
+	job.logger.Warnf("Timed out waiting for hashing snapshot %v", snapshotName)
+	return nil, nil
+}
+
+func isSnapshotHashFinished(hashStatus []longhornclient.SnapshotHashStatus) bool {
+	if len(hashStatus) == 0 {
+		return false
+	}
+	for _, status := range hashStatus {
+		if status.State != string(etypes.ProcessStateComplete) && status.State != string(etypes.ProcessStateError) {
+			return false
+		}
+	}
+	return true
+}
+
+// verifySnapshotHashStatus checks the checksums of the snapshot calculated by all replicas against each other and
+// against the checksum recorded before, if any
+func verifySnapshotHashStatus(hashStatus []longhornclient.SnapshotHashStatus, recordedChecksum string) error {
+	checksum := recordedChecksum
+	for _, status := range hashStatus {
+		if status.State == string(etypes.ProcessStateError) {
+			return fmt.Errorf("failed to hash on replica %v: %v", status.Replica, status.Error)
+		}
+		if status.SilentlyCorrupted {
+			return fmt.Errorf("silently corrupted on replica %v", status.Replica)
+		}
+		if checksum == "" {
+			checksum = status.Checksum
+		}
+		if status.Checksum != checksum {
+			return fmt.Errorf("checksum %v on replica %v mismatches checksum %v", status.Checksum, status.Replica, checksum)
+		}
+	}
+	return nil
+}
+
+func (job *VolumeJob) maintenanceVerifyLastBackup(volume *longhornclient.Volume, _ time.Time) (string, string) {
+	backup, err := job.getLastBackup()
+	if err != nil {
+		return MaintenanceStepResultFailed, err.Error()
+	}
+	if backup == nil {
+		return MaintenanceStepResultSkipped, "volume has no backup"
+	}
+
+	if backup.State != string(longhorn.BackupStateCompleted) {
+		return MaintenanceStepResultFailed, fmt.Sprintf("last backup %v is in state %v: %v", backup.Name, backup.State, backup.Error)
+	}
+	if backup.Error != "" {
+		return MaintenanceStepResultFailed, fmt.Sprintf("last backup %v has error: %v", backup.Name, backup.Error)
+	}
+	if backup.Url == "" {
+		return MaintenanceStepResultFailed, fmt.Sprintf("last backup %v has no URL on the backup target", backup.Name)
+	}
+	return MaintenanceStepResultSucceeded, fmt.Sprintf("last backup %v is completed", backup.Name)
+}
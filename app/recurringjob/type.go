@@ -53,6 +53,16 @@ type SystemBackupJob struct {
 	volumeBackupPolicy longhorn.SystemBackupCreateVolumeBackupPolicy // backup policy used for the SystemBackup.Spec.
 }
 
+// BackupBackingImageJob is a job for backing image backup tasks.
+// It embeds the Job struct and includes additional fields specific to backing image backup operations.
+type BackupBackingImageJob struct {
+	*Job // Embedding the base Job struct.
+
+	logger logrus.FieldLogger // Log messages related to the backing image backup job.
+
+	backupTargetName string // Name of the BackupTarget the backing images are backed up to.
+}
+
 // NameWithTimestamp for resource cleanup.
 type NameWithTimestamp struct {
 	Name      string
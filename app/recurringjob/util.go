@@ -88,7 +88,11 @@ func snapshotCRsToNames(snapshotCRs []longhornclient.SnapshotCR) []string {
 	return result
 }
 
-func filterVolumesForJob(allowDetached bool, volumes []longhorn.Volume, filterNames *[]string) {
+func isBackupTask(task longhorn.RecurringJobType) bool {
+	return task == longhorn.RecurringJobTypeBackup || task == longhorn.RecurringJobTypeBackupForceCreate
+}
+
+func filterVolumesForJob(allowDetached bool, task longhorn.RecurringJobType, volumes []longhorn.Volume, filterNames *[]string) {
 	logger := logrus.StandardLogger()
 	for _, volume := range volumes {
 		// skip duplicates
@@ -101,6 +105,11 @@ func filterVolumesForJob(allowDetached bool, volumes []longhorn.Volume, filterNa
 			continue
 		}
 
+		if isBackupTask(task) && volume.Labels[types.GetLonghornLabelKey(types.LonghornLabelRecurringBackupDisabled)] == types.LonghornLabelValueEnabled {
+			logger.Infof("Bypassed to create job for %v volume with the recurring backups disabled by the maintenance policies", volume.Name)
+			continue
+		}
+
 		if volume.Status.Robustness != longhorn.VolumeRobustnessFaulted &&
 			(volume.Status.State == longhorn.VolumeStateAttached || allowDetached) {
 			*filterNames = append(*filterNames, volume.Name)
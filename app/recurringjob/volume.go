@@ -37,7 +37,7 @@ func StartVolumeJobs(job *Job, recurringJob *longhorn.RecurringJob) error {
 	}
 
 	filteredVolumes := []string{}
-	filterVolumesForJob(allowDetached, volumes, &filteredVolumes)
+	filterVolumesForJob(allowDetached, recurringJob.Spec.Task, volumes, &filteredVolumes)
 
 	jobGroups := recurringJob.Spec.Groups
 	for _, jobGroup := range jobGroups {
@@ -45,7 +45,7 @@ func StartVolumeJobs(job *Job, recurringJob *longhorn.RecurringJob) error {
 		if err != nil {
 			return err
 		}
-		filterVolumesForJob(allowDetached, volumes, &filteredVolumes)
+		filterVolumesForJob(allowDetached, recurringJob.Spec.Task, volumes, &filteredVolumes)
 	}
 
 	job.logger.Infof("Found %v volumes with recurring job %v", len(filteredVolumes), job.name)
@@ -165,6 +165,10 @@ func (job *VolumeJob) run() (err error) {
 		job.logger.Infof("Running recurring filesystem trim for volume %v", volumeName)
 		return job.doRecurringFilesystemTrim(volume)
 
+	case longhorn.RecurringJobTypeMaintenance:
+		job.logger.Infof("Running recurring maintenance for volume %v", volumeName)
+		return job.doRecurringMaintenance(volume)
+
 	case longhorn.RecurringJobTypeBackup, longhorn.RecurringJobTypeBackupForceCreate:
 		job.logger.Infof("Running recurring backup for volume %v", volumeName)
 		return job.doRecurringBackup()
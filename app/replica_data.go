@@ -0,0 +1,233 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhornclient "github.com/longhorn/longhorn-manager/client"
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	FlagOutput           = "output"
+	FlagVolumeName       = "volume-name"
+	FlagNumberOfReplicas = "number-of-replicas"
+	FlagNodeID           = "node-id"
+
+	replicaDataImportAttachmentID = "replica-data-import"
+	replicaDataImportWaitInterval = 5 * time.Second
+	replicaDataImportWaitTimeout  = 5 * time.Minute
+)
+
+// ReplicaDataCmd recovers the data of a volume from the replica data directories salvaged from the disk of a failed
+// node, without the state of the original cluster. The directories of the same volume are validated, and the one with
+// the latest data is exported as a raw image or imported as a new volume.
+func ReplicaDataCmd() cli.Command {
+	return cli.Command{
+		Name:  "replica-data",
+		Usage: "Recover the volume data from salvaged replica data directories",
+		Subcommands: []cli.Command{
+			{
+				Name:      "export",
+				Usage:     "Export the replica data as a raw image",
+				ArgsUsage: "<replica data directory>...",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  FlagOutput,
+						Usage: "Path of the raw image file to create",
+					},
+				},
+				Action: func(c *cli.Context) {
+					if err := exportReplicaData(c); err != nil {
+						logrus.WithError(err).Fatal("Failed to export replica data")
+					}
+				},
+			},
+			{
+				Name:      "import",
+				Usage:     "Import the replica data as a new volume",
+				ArgsUsage: "<replica data directory>...",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  FlagManagerURL,
+						Usage: "Longhorn manager API URL",
+					},
+					cli.StringFlag{
+						Name:  FlagVolumeName,
+						Usage: "Name of the volume to create",
+					},
+					cli.IntFlag{
+						Name:  FlagNumberOfReplicas,
+						Usage: "Number of replicas of the volume to create",
+						Value: 3,
+					},
+					cli.StringFlag{
+						Name:   FlagNodeID,
+						Usage:  "Node to attach the volume to for the import, which must be the node running the command",
+						EnvVar: types.EnvNodeName,
+					},
+				},
+				Action: func(c *cli.Context) {
+					if err := importReplicaData(c); err != nil {
+						logrus.WithError(err).Fatal("Failed to import replica data")
+					}
+				},
+			},
+		},
+	}
+}
+
+func pickReplicaData(c *cli.Context) (*util.ReplicaDataChain, error) {
+	if c.NArg() == 0 {
+		return nil, errors.New("replica data directory is required")
+	}
+	chain, err := util.PickReplicaDataChain(c.Args())
+	if err != nil {
+		return nil, err
+	}
+	logrus.Infof("Picked replica data directory %v with size %v, images %v, revision counter %v and head modified at %v",
+		chain.Directory, chain.Size, chain.Images, chain.RevisionCounter, chain.HeadModifiedAt)
+	return chain, nil
+}
+
+func exportReplicaData(c *cli.Context) error {
+	output := c.String(FlagOutput)
+	if output == "" {
+		return fmt.Errorf("require %v", FlagOutput)
+	}
+
+	chain, err := pickReplicaData(c)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(output, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create output file %v", output)
+	}
+	defer f.Close()
+
+	if err := f.Truncate(chain.Size); err != nil {
+		return errors.Wrapf(err, "failed to resize output file %v", output)
+	}
+	if err := chain.WriteTo(f); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return errors.Wrapf(err, "failed to sync output file %v", output)
+	}
+
+	logrus.Infof("Exported replica data directory %v to %v", chain.Directory, output)
+	return nil
+}
+
+func importReplicaData(c *cli.Context) (err error) {
+	managerURL := c.String(FlagManagerURL)
+	if managerURL == "" {
+		return fmt.Errorf("require %v", FlagManagerURL)
+	}
+	volumeName := c.String(FlagVolumeName)
+	if volumeName == "" {
+		return fmt.Errorf("require %v", FlagVolumeName)
+	}
+	nodeID := c.String(FlagNodeID)
+	if nodeID == "" {
+		return fmt.Errorf("require %v", FlagNodeID)
+	}
+
+	chain, err := pickReplicaData(c)
+	if err != nil {
+		return err
+	}
+
+	apiClient, err := longhornclient.NewRancherClient(&longhornclient.ClientOpts{
+		Url:     managerURL,
+		Timeout: time.Minute,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create longhorn-manager api client")
+	}
+
+	volume, err := apiClient.Volume.Create(&longhornclient.Volume{
+		Name:             volumeName,
+		Size:             strconv.FormatInt(chain.Size, 10),
+		NumberOfReplicas: int64(c.Int(FlagNumberOfReplicas)),
+		Frontend:         string(longhorn.VolumeFrontendBlockDev),
+		DataEngine:       string(longhorn.DataEngineTypeV1),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create volume %v", volumeName)
+	}
+	logrus.Infof("Created volume %v for the import", volumeName)
+
+	if volume, err = waitForReplicaDataImportVolume(apiClient, volumeName, func(v *longhornclient.Volume) bool {
+		return v.State == string(longhorn.VolumeStateDetached)
+	}); err != nil {
+		return err
+	}
+
+	if _, err := apiClient.Volume.ActionAttach(volume, &longhornclient.AttachInput{
+		HostId:       nodeID,
+		AttacherType: string(longhorn.AttacherTypeLonghornAPI),
+		AttachmentID: replicaDataImportAttachmentID,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to attach volume %v to node %v", volumeName, nodeID)
+	}
+	defer func() {
+		volume, detachErr := apiClient.Volume.ById(volumeName)
+		if detachErr == nil && volume != nil {
+			_, detachErr = apiClient.Volume.ActionDetach(volume, &longhornclient.DetachInput{
+				AttachmentID: replicaDataImportAttachmentID,
+				HostId:       nodeID,
+			})
+		}
+		if detachErr != nil {
+			logrus.WithError(detachErr).Warnf("Failed to detach volume %v after the import", volumeName)
+		}
+	}()
+
+	if volume, err = waitForReplicaDataImportVolume(apiClient, volumeName, func(v *longhornclient.Volume) bool {
+		return v.State == string(longhorn.VolumeStateAttached) && len(v.Controllers) > 0 && v.Controllers[0].Endpoint != ""
+	}); err != nil {
+		return err
+	}
+
+	devicePath := volume.Controllers[0].Endpoint
+	device, err := os.OpenFile(devicePath, os.O_WRONLY, 0)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open device %v of volume %v", devicePath, volumeName)
+	}
+	defer device.Close()
+
+	if err := chain.WriteTo(device); err != nil {
+		return err
+	}
+	if err := device.Sync(); err != nil {
+		return errors.Wrapf(err, "failed to sync device %v of volume %v", devicePath, volumeName)
+	}
+
+	logrus.Infof("Imported replica data directory %v to volume %v", chain.Directory, volumeName)
+	return nil
+}
+
+func waitForReplicaDataImportVolume(apiClient *longhornclient.RancherClient, volumeName string, ready func(*longhornclient.Volume) bool) (*longhornclient.Volume, error) {
+	for start := time.Now(); time.Since(start) < replicaDataImportWaitTimeout; time.Sleep(replicaDataImportWaitInterval) {
+		volume, err := apiClient.Volume.ById(volumeName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get volume %v", volumeName)
+		}
+		if volume != nil && ready(volume) {
+			return volume, nil
+		}
+	}
+	return nil, fmt.Errorf("timed out waiting for volume %v", volumeName)
+}
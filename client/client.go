@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gorilla/websocket"
@@ -10,6 +11,8 @@ type RancherBaseClientImpl struct {
 	Opts    *ClientOpts
 	Schemas *Schemas
 	Types   map[string]Schema
+
+	ctx context.Context
 }
 
 type RancherBaseClient interface {
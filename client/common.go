@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -32,6 +33,8 @@ type ClientOpts struct {
 	AccessKey string
 	SecretKey string
 	Timeout   time.Duration
+	// Transport is used to send the requests if set, e.g. to instrument them
+	Transport http.RoundTripper
 }
 
 type ApiError struct {
@@ -152,7 +155,7 @@ func setupRancherBaseClient(rancherClient *RancherBaseClientImpl, opts *ClientOp
 	if opts.Timeout == 0 {
 		opts.Timeout = time.Second * 10
 	}
-	client := &http.Client{Timeout: opts.Timeout}
+	client := &http.Client{Timeout: opts.Timeout, Transport: opts.Transport}
 	req, err := http.NewRequest("GET", opts.Url, nil)
 	if err != nil {
 		return err
@@ -238,12 +241,31 @@ func (rancherClient *RancherBaseClientImpl) newHttpClient() *http.Client {
 	if rancherClient.Opts.Timeout == 0 {
 		rancherClient.Opts.Timeout = time.Minute
 	}
-	return &http.Client{Timeout: rancherClient.Opts.Timeout}
+	return &http.Client{Timeout: rancherClient.Opts.Timeout, Transport: rancherClient.Opts.Transport}
+}
+
+func (rancherClient *RancherBaseClientImpl) context() context.Context {
+	if rancherClient.ctx == nil {
+		return context.Background()
+	}
+	return rancherClient.ctx
+}
+
+// WithContext returns a copy of the client sending the requests with the context, so that the requests are
+// canceled along with the context and the trace context is propagated to the server.
+func (c *RancherClient) WithContext(ctx context.Context) *RancherClient {
+	baseClient, ok := c.RancherBaseClient.(*RancherBaseClientImpl)
+	if !ok {
+		return c
+	}
+	newBaseClient := *baseClient
+	newBaseClient.ctx = ctx
+	return constructClient(&newBaseClient)
 }
 
 func (rancherClient *RancherBaseClientImpl) doDelete(url string) error {
 	client := rancherClient.newHttpClient()
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := http.NewRequestWithContext(rancherClient.context(), "DELETE", url, nil)
 	if err != nil {
 		return err
 	}
@@ -299,7 +321,7 @@ func (rancherClient *RancherBaseClientImpl) doGet(url string, opts *ListOpts, re
 	}
 
 	client := rancherClient.newHttpClient()
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(rancherClient.context(), "GET", url, nil)
 	if err != nil {
 		return err
 	}
@@ -388,7 +410,7 @@ func (rancherClient *RancherBaseClientImpl) doModify(method string, url string,
 	}
 
 	client := rancherClient.newHttpClient()
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(bodyContent))
+	req, err := http.NewRequestWithContext(rancherClient.context(), method, url, bytes.NewBuffer(bodyContent))
 	if err != nil {
 		return err
 	}
@@ -586,7 +608,7 @@ func (rancherClient *RancherBaseClientImpl) doAction(schemaType string, action s
 	}
 
 	client := rancherClient.newHttpClient()
-	req, err := http.NewRequest("POST", actionUrl, input)
+	req, err := http.NewRequestWithContext(rancherClient.context(), "POST", actionUrl, input)
 	if err != nil {
 		return err
 	}
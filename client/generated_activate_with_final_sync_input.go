@@ -0,0 +1,87 @@
+package client
+
+const (
+	ACTIVATE_WITH_FINAL_SYNC_INPUT_TYPE = "activateWithFinalSyncInput"
+)
+
+type ActivateWithFinalSyncInput struct {
+	Resource `yaml:"-"`
+
+	FsType string `json:"fsType,omitempty" yaml:"fs_type,omitempty"`
+
+	Frontend string `json:"frontend,omitempty" yaml:"frontend,omitempty"`
+
+	PvcName string `json:"pvcName,omitempty" yaml:"pvc_name,omitempty"`
+
+	PvcNamespace string `json:"pvcNamespace,omitempty" yaml:"pvc_namespace,omitempty"`
+
+	PvName string `json:"pvName,omitempty" yaml:"pv_name,omitempty"`
+}
+
+type ActivateWithFinalSyncInputCollection struct {
+	Collection
+	Data   []ActivateWithFinalSyncInput `json:"data,omitempty"`
+	client *ActivateWithFinalSyncInputClient
+}
+
+type ActivateWithFinalSyncInputClient struct {
+	rancherClient *RancherClient
+}
+
+type ActivateWithFinalSyncInputOperations interface {
+	List(opts *ListOpts) (*ActivateWithFinalSyncInputCollection, error)
+	Create(opts *ActivateWithFinalSyncInput) (*ActivateWithFinalSyncInput, error)
+	Update(existing *ActivateWithFinalSyncInput, updates interface{}) (*ActivateWithFinalSyncInput, error)
+	ById(id string) (*ActivateWithFinalSyncInput, error)
+	Delete(container *ActivateWithFinalSyncInput) error
+}
+
+func newActivateWithFinalSyncInputClient(rancherClient *RancherClient) *ActivateWithFinalSyncInputClient {
+	return &ActivateWithFinalSyncInputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *ActivateWithFinalSyncInputClient) Create(container *ActivateWithFinalSyncInput) (*ActivateWithFinalSyncInput, error) {
+	resp := &ActivateWithFinalSyncInput{}
+	err := c.rancherClient.doCreate(ACTIVATE_WITH_FINAL_SYNC_INPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *ActivateWithFinalSyncInputClient) Update(existing *ActivateWithFinalSyncInput, updates interface{}) (*ActivateWithFinalSyncInput, error) {
+	resp := &ActivateWithFinalSyncInput{}
+	err := c.rancherClient.doUpdate(ACTIVATE_WITH_FINAL_SYNC_INPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *ActivateWithFinalSyncInputClient) List(opts *ListOpts) (*ActivateWithFinalSyncInputCollection, error) {
+	resp := &ActivateWithFinalSyncInputCollection{}
+	err := c.rancherClient.doList(ACTIVATE_WITH_FINAL_SYNC_INPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *ActivateWithFinalSyncInputCollection) Next() (*ActivateWithFinalSyncInputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &ActivateWithFinalSyncInputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *ActivateWithFinalSyncInputClient) ById(id string) (*ActivateWithFinalSyncInput, error) {
+	resp := &ActivateWithFinalSyncInput{}
+	err := c.rancherClient.doById(ACTIVATE_WITH_FINAL_SYNC_INPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *ActivateWithFinalSyncInputClient) Delete(container *ActivateWithFinalSyncInput) error {
+	return c.rancherClient.doResourceDelete(ACTIVATE_WITH_FINAL_SYNC_INPUT_TYPE, &container.Resource)
+}
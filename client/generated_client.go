@@ -18,9 +18,15 @@ type RancherClient struct {
 	PurgeStatus                            PurgeStatusOperations
 	RebuildStatus                          RebuildStatusOperations
 	ReplicaRemoveInput                     ReplicaRemoveInputOperations
+	ReplicaRebuildInput                    ReplicaRebuildInputOperations
+	UpdateReplicaRebuildPriorityInput      UpdateReplicaRebuildPriorityInputOperations
 	SalvageInput                           SalvageInputOperations
+	PickSalvageCandidateInput              PickSalvageCandidateInputOperations
 	ActivateInput                          ActivateInputOperations
+	ActivateWithFinalSyncInput             ActivateWithFinalSyncInputOperations
 	ExpandInput                            ExpandInputOperations
+	MigrateToDiskTagInput                  MigrateToDiskTagInputOperations
+	LiveMigrateInput                       LiveMigrateInputOperations
 	EngineUpgradeInput                     EngineUpgradeInputOperations
 	Replica                                ReplicaOperations
 	Controller                             ControllerOperations
@@ -31,7 +37,13 @@ type RancherClient struct {
 	UpdateAccessModeInput                  UpdateAccessModeInputOperations
 	UpdateSnapshotDataIntegrityInput       UpdateSnapshotDataIntegrityInputOperations
 	UpdateSnapshotMaxCountInput            UpdateSnapshotMaxCountInputOperations
+	UpdateSnapshotMaxChainLengthInput      UpdateSnapshotMaxChainLengthInputOperations
 	UpdateSnapshotMaxSizeInput             UpdateSnapshotMaxSizeInputOperations
+	UpdateSnapshotRetentionPolicyInput     UpdateSnapshotRetentionPolicyInputOperations
+	UpdateAutoResizePolicyInput            UpdateAutoResizePolicyInputOperations
+	UpdateBackupMaxAgeInput                UpdateBackupMaxAgeInputOperations
+	UpdateFilesystemConditionInput         UpdateFilesystemConditionInputOperations
+	PauseIOInput                           PauseIOInputOperations
 	UpdateBackupCompressionInput           UpdateBackupCompressionInputOperations
 	UpdateUnmapMarkSnapChainRemovedInput   UpdateUnmapMarkSnapChainRemovedInputOperations
 	UpdateReplicaSoftAntiAffinityInput     UpdateReplicaSoftAntiAffinityInputOperations
@@ -40,6 +52,7 @@ type RancherClient struct {
 	UpdateFreezeFSForSnapshotInput         UpdateFreezeFSForSnapshotInputOperations
 	WorkloadStatus                         WorkloadStatusOperations
 	CloneStatus                            CloneStatusOperations
+	SalvageCandidate                       SalvageCandidateOperations
 	Empty                                  EmptyOperations
 	VolumeRecurringJob                     VolumeRecurringJobOperations
 	VolumeRecurringJobInput                VolumeRecurringJobInputOperations
@@ -78,6 +91,8 @@ type RancherClient struct {
 	SystemBackup                           SystemBackupOperations
 	SystemRestore                          SystemRestoreOperations
 	SnapshotCRListOutput                   SnapshotCRListOutputOperations
+	SnapshotHashStatus                     SnapshotHashStatusOperations
+	SnapshotHashStatusListOutput           SnapshotHashStatusListOutputOperations
 }
 
 func constructClient(rancherBaseClient *RancherBaseClientImpl) *RancherClient {
@@ -100,9 +115,15 @@ func constructClient(rancherBaseClient *RancherBaseClientImpl) *RancherClient {
 	client.PurgeStatus = newPurgeStatusClient(client)
 	client.RebuildStatus = newRebuildStatusClient(client)
 	client.ReplicaRemoveInput = newReplicaRemoveInputClient(client)
+	client.ReplicaRebuildInput = newReplicaRebuildInputClient(client)
+	client.UpdateReplicaRebuildPriorityInput = newUpdateReplicaRebuildPriorityInputClient(client)
 	client.SalvageInput = newSalvageInputClient(client)
+	client.PickSalvageCandidateInput = newPickSalvageCandidateInputClient(client)
 	client.ActivateInput = newActivateInputClient(client)
+	client.ActivateWithFinalSyncInput = newActivateWithFinalSyncInputClient(client)
 	client.ExpandInput = newExpandInputClient(client)
+	client.MigrateToDiskTagInput = newMigrateToDiskTagInputClient(client)
+	client.LiveMigrateInput = newLiveMigrateInputClient(client)
 	client.EngineUpgradeInput = newEngineUpgradeInputClient(client)
 	client.Replica = newReplicaClient(client)
 	client.Controller = newControllerClient(client)
@@ -113,7 +134,13 @@ func constructClient(rancherBaseClient *RancherBaseClientImpl) *RancherClient {
 	client.UpdateAccessModeInput = newUpdateAccessModeInputClient(client)
 	client.UpdateSnapshotDataIntegrityInput = newUpdateSnapshotDataIntegrityInputClient(client)
 	client.UpdateSnapshotMaxCountInput = newUpdateSnapshotMaxCountInputClient(client)
+	client.UpdateSnapshotMaxChainLengthInput = newUpdateSnapshotMaxChainLengthInputClient(client)
 	client.UpdateSnapshotMaxSizeInput = newUpdateSnapshotMaxSizeInputClient(client)
+	client.UpdateSnapshotRetentionPolicyInput = newUpdateSnapshotRetentionPolicyInputClient(client)
+	client.UpdateAutoResizePolicyInput = newUpdateAutoResizePolicyInputClient(client)
+	client.UpdateBackupMaxAgeInput = newUpdateBackupMaxAgeInputClient(client)
+	client.UpdateFilesystemConditionInput = newUpdateFilesystemConditionInputClient(client)
+	client.PauseIOInput = newPauseIOInputClient(client)
 	client.UpdateBackupCompressionInput = newUpdateBackupCompressionInputClient(client)
 	client.UpdateUnmapMarkSnapChainRemovedInput = newUpdateUnmapMarkSnapChainRemovedInputClient(client)
 	client.UpdateReplicaSoftAntiAffinityInput = newUpdateReplicaSoftAntiAffinityInputClient(client)
@@ -122,6 +149,7 @@ func constructClient(rancherBaseClient *RancherBaseClientImpl) *RancherClient {
 	client.UpdateFreezeFSForSnapshotInput = newUpdateFreezeFSForSnapshotInputClient(client)
 	client.WorkloadStatus = newWorkloadStatusClient(client)
 	client.CloneStatus = newCloneStatusClient(client)
+	client.SalvageCandidate = newSalvageCandidateClient(client)
 	client.Empty = newEmptyClient(client)
 	client.VolumeRecurringJob = newVolumeRecurringJobClient(client)
 	client.VolumeRecurringJobInput = newVolumeRecurringJobInputClient(client)
@@ -160,6 +188,8 @@ func constructClient(rancherBaseClient *RancherBaseClientImpl) *RancherClient {
 	client.SystemBackup = newSystemBackupClient(client)
 	client.SystemRestore = newSystemRestoreClient(client)
 	client.SnapshotCRListOutput = newSnapshotCRListOutputClient(client)
+	client.SnapshotHashStatus = newSnapshotHashStatusClient(client)
+	client.SnapshotHashStatusListOutput = newSnapshotHashStatusListOutputClient(client)
 
 	return client
 }
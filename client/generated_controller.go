@@ -21,6 +21,8 @@ type Controller struct {
 
 	InstanceManagerName string `json:"instanceManagerName,omitempty" yaml:"instance_manager_name,omitempty"`
 
+	IoPaused bool `json:"ioPaused,omitempty" yaml:"io_paused,omitempty"`
+
 	IsExpanding bool `json:"isExpanding,omitempty" yaml:"is_expanding,omitempty"`
 
 	LastExpansionError string `json:"lastExpansionError,omitempty" yaml:"last_expansion_error,omitempty"`
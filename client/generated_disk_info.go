@@ -9,6 +9,8 @@ type DiskInfo struct {
 
 	AllowScheduling bool `json:"allowScheduling,omitempty" yaml:"allow_scheduling,omitempty"`
 
+	ConcurrentReplicaRebuildLimit int64 `json:"concurrentReplicaRebuildLimit,omitempty" yaml:"concurrent_replica_rebuild_limit,omitempty"`
+
 	Conditions map[string]interface{} `json:"conditions,omitempty" yaml:"conditions,omitempty"`
 
 	DiskType string `json:"diskType,omitempty" yaml:"disk_type,omitempty"`
@@ -17,6 +19,10 @@ type DiskInfo struct {
 
 	EvictionRequested bool `json:"evictionRequested,omitempty" yaml:"eviction_requested,omitempty"`
 
+	IOLatency int64 `json:"ioLatency,omitempty" yaml:"io_latency,omitempty"`
+
+	IOUtilization int64 `json:"ioUtilization,omitempty" yaml:"io_utilization,omitempty"`
+
 	Path string `json:"path,omitempty" yaml:"path,omitempty"`
 
 	ScheduledBackingImage map[string]string `json:"scheduledBackingImage,omitempty" yaml:"scheduled_backing_image,omitempty"`
@@ -0,0 +1,79 @@
+package client
+
+const (
+	LIVE_MIGRATE_INPUT_TYPE = "liveMigrateInput"
+)
+
+type LiveMigrateInput struct {
+	Resource `yaml:"-"`
+
+	NodeID string `json:"nodeID,omitempty" yaml:"node_id,omitempty"`
+}
+
+type LiveMigrateInputCollection struct {
+	Collection
+	Data   []LiveMigrateInput `json:"data,omitempty"`
+	client *LiveMigrateInputClient
+}
+
+type LiveMigrateInputClient struct {
+	rancherClient *RancherClient
+}
+
+type LiveMigrateInputOperations interface {
+	List(opts *ListOpts) (*LiveMigrateInputCollection, error)
+	Create(opts *LiveMigrateInput) (*LiveMigrateInput, error)
+	Update(existing *LiveMigrateInput, updates interface{}) (*LiveMigrateInput, error)
+	ById(id string) (*LiveMigrateInput, error)
+	Delete(container *LiveMigrateInput) error
+}
+
+func newLiveMigrateInputClient(rancherClient *RancherClient) *LiveMigrateInputClient {
+	return &LiveMigrateInputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *LiveMigrateInputClient) Create(container *LiveMigrateInput) (*LiveMigrateInput, error) {
+	resp := &LiveMigrateInput{}
+	err := c.rancherClient.doCreate(LIVE_MIGRATE_INPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *LiveMigrateInputClient) Update(existing *LiveMigrateInput, updates interface{}) (*LiveMigrateInput, error) {
+	resp := &LiveMigrateInput{}
+	err := c.rancherClient.doUpdate(LIVE_MIGRATE_INPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *LiveMigrateInputClient) List(opts *ListOpts) (*LiveMigrateInputCollection, error) {
+	resp := &LiveMigrateInputCollection{}
+	err := c.rancherClient.doList(LIVE_MIGRATE_INPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *LiveMigrateInputCollection) Next() (*LiveMigrateInputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &LiveMigrateInputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *LiveMigrateInputClient) ById(id string) (*LiveMigrateInput, error) {
+	resp := &LiveMigrateInput{}
+	err := c.rancherClient.doById(LIVE_MIGRATE_INPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *LiveMigrateInputClient) Delete(container *LiveMigrateInput) error {
+	return c.rancherClient.doResourceDelete(LIVE_MIGRATE_INPUT_TYPE, &container.Resource)
+}
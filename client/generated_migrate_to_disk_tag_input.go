@@ -0,0 +1,79 @@
+package client
+
+const (
+	MIGRATE_TO_DISK_TAG_INPUT_TYPE = "migrateToDiskTagInput"
+)
+
+type MigrateToDiskTagInput struct {
+	Resource `yaml:"-"`
+
+	DiskSelector []string `json:"diskSelector,omitempty" yaml:"disk_selector,omitempty"`
+}
+
+type MigrateToDiskTagInputCollection struct {
+	Collection
+	Data   []MigrateToDiskTagInput `json:"data,omitempty"`
+	client *MigrateToDiskTagInputClient
+}
+
+type MigrateToDiskTagInputClient struct {
+	rancherClient *RancherClient
+}
+
+type MigrateToDiskTagInputOperations interface {
+	List(opts *ListOpts) (*MigrateToDiskTagInputCollection, error)
+	Create(opts *MigrateToDiskTagInput) (*MigrateToDiskTagInput, error)
+	Update(existing *MigrateToDiskTagInput, updates interface{}) (*MigrateToDiskTagInput, error)
+	ById(id string) (*MigrateToDiskTagInput, error)
+	Delete(container *MigrateToDiskTagInput) error
+}
+
+func newMigrateToDiskTagInputClient(rancherClient *RancherClient) *MigrateToDiskTagInputClient {
+	return &MigrateToDiskTagInputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *MigrateToDiskTagInputClient) Create(container *MigrateToDiskTagInput) (*MigrateToDiskTagInput, error) {
+	resp := &MigrateToDiskTagInput{}
+	err := c.rancherClient.doCreate(MIGRATE_TO_DISK_TAG_INPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *MigrateToDiskTagInputClient) Update(existing *MigrateToDiskTagInput, updates interface{}) (*MigrateToDiskTagInput, error) {
+	resp := &MigrateToDiskTagInput{}
+	err := c.rancherClient.doUpdate(MIGRATE_TO_DISK_TAG_INPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *MigrateToDiskTagInputClient) List(opts *ListOpts) (*MigrateToDiskTagInputCollection, error) {
+	resp := &MigrateToDiskTagInputCollection{}
+	err := c.rancherClient.doList(MIGRATE_TO_DISK_TAG_INPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *MigrateToDiskTagInputCollection) Next() (*MigrateToDiskTagInputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &MigrateToDiskTagInputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *MigrateToDiskTagInputClient) ById(id string) (*MigrateToDiskTagInput, error) {
+	resp := &MigrateToDiskTagInput{}
+	err := c.rancherClient.doById(MIGRATE_TO_DISK_TAG_INPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *MigrateToDiskTagInputClient) Delete(container *MigrateToDiskTagInput) error {
+	return c.rancherClient.doResourceDelete(MIGRATE_TO_DISK_TAG_INPUT_TYPE, &container.Resource)
+}
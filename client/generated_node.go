@@ -21,6 +21,8 @@ type Node struct {
 
 	InstanceManagerCPURequest int64 `json:"instanceManagerCPURequest,omitempty" yaml:"instance_manager_cpurequest,omitempty"`
 
+	InstanceManagerMemoryRequest int64 `json:"instanceManagerMemoryRequest,omitempty" yaml:"instance_manager_memory_request,omitempty"`
+
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
 
 	Region string `json:"region,omitempty" yaml:"region,omitempty"`
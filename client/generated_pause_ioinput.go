@@ -0,0 +1,79 @@
+package client
+
+const (
+	PAUSE_IOINPUT_TYPE = "PauseIOInput"
+)
+
+type PauseIOInput struct {
+	Resource `yaml:"-"`
+
+	Timeout int64 `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+type PauseIOInputCollection struct {
+	Collection
+	Data   []PauseIOInput `json:"data,omitempty"`
+	client *PauseIOInputClient
+}
+
+type PauseIOInputClient struct {
+	rancherClient *RancherClient
+}
+
+type PauseIOInputOperations interface {
+	List(opts *ListOpts) (*PauseIOInputCollection, error)
+	Create(opts *PauseIOInput) (*PauseIOInput, error)
+	Update(existing *PauseIOInput, updates interface{}) (*PauseIOInput, error)
+	ById(id string) (*PauseIOInput, error)
+	Delete(container *PauseIOInput) error
+}
+
+func newPauseIOInputClient(rancherClient *RancherClient) *PauseIOInputClient {
+	return &PauseIOInputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *PauseIOInputClient) Create(container *PauseIOInput) (*PauseIOInput, error) {
+	resp := &PauseIOInput{}
+	err := c.rancherClient.doCreate(PAUSE_IOINPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *PauseIOInputClient) Update(existing *PauseIOInput, updates interface{}) (*PauseIOInput, error) {
+	resp := &PauseIOInput{}
+	err := c.rancherClient.doUpdate(PAUSE_IOINPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *PauseIOInputClient) List(opts *ListOpts) (*PauseIOInputCollection, error) {
+	resp := &PauseIOInputCollection{}
+	err := c.rancherClient.doList(PAUSE_IOINPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *PauseIOInputCollection) Next() (*PauseIOInputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &PauseIOInputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *PauseIOInputClient) ById(id string) (*PauseIOInput, error) {
+	resp := &PauseIOInput{}
+	err := c.rancherClient.doById(PAUSE_IOINPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *PauseIOInputClient) Delete(container *PauseIOInput) error {
+	return c.rancherClient.doResourceDelete(PAUSE_IOINPUT_TYPE, &container.Resource)
+}
@@ -0,0 +1,79 @@
+package client
+
+const (
+	PICK_SALVAGE_CANDIDATE_INPUT_TYPE = "pickSalvageCandidateInput"
+)
+
+type PickSalvageCandidateInput struct {
+	Resource `yaml:"-"`
+
+	ReplicaName string `json:"replicaName,omitempty" yaml:"replica_name,omitempty"`
+}
+
+type PickSalvageCandidateInputCollection struct {
+	Collection
+	Data   []PickSalvageCandidateInput `json:"data,omitempty"`
+	client *PickSalvageCandidateInputClient
+}
+
+type PickSalvageCandidateInputClient struct {
+	rancherClient *RancherClient
+}
+
+type PickSalvageCandidateInputOperations interface {
+	List(opts *ListOpts) (*PickSalvageCandidateInputCollection, error)
+	Create(opts *PickSalvageCandidateInput) (*PickSalvageCandidateInput, error)
+	Update(existing *PickSalvageCandidateInput, updates interface{}) (*PickSalvageCandidateInput, error)
+	ById(id string) (*PickSalvageCandidateInput, error)
+	Delete(container *PickSalvageCandidateInput) error
+}
+
+func newPickSalvageCandidateInputClient(rancherClient *RancherClient) *PickSalvageCandidateInputClient {
+	return &PickSalvageCandidateInputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *PickSalvageCandidateInputClient) Create(container *PickSalvageCandidateInput) (*PickSalvageCandidateInput, error) {
+	resp := &PickSalvageCandidateInput{}
+	err := c.rancherClient.doCreate(PICK_SALVAGE_CANDIDATE_INPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *PickSalvageCandidateInputClient) Update(existing *PickSalvageCandidateInput, updates interface{}) (*PickSalvageCandidateInput, error) {
+	resp := &PickSalvageCandidateInput{}
+	err := c.rancherClient.doUpdate(PICK_SALVAGE_CANDIDATE_INPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *PickSalvageCandidateInputClient) List(opts *ListOpts) (*PickSalvageCandidateInputCollection, error) {
+	resp := &PickSalvageCandidateInputCollection{}
+	err := c.rancherClient.doList(PICK_SALVAGE_CANDIDATE_INPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *PickSalvageCandidateInputCollection) Next() (*PickSalvageCandidateInputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &PickSalvageCandidateInputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *PickSalvageCandidateInputClient) ById(id string) (*PickSalvageCandidateInput, error) {
+	resp := &PickSalvageCandidateInput{}
+	err := c.rancherClient.doById(PICK_SALVAGE_CANDIDATE_INPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *PickSalvageCandidateInputClient) Delete(container *PickSalvageCandidateInput) error {
+	return c.rancherClient.doResourceDelete(PICK_SALVAGE_CANDIDATE_INPUT_TYPE, &container.Resource)
+}
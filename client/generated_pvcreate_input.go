@@ -11,6 +11,10 @@ type PVCreateInput struct {
 
 	PvName string `json:"pvName,omitempty" yaml:"pv_name,omitempty"`
 
+	PvcName string `json:"pvcName,omitempty" yaml:"pvc_name,omitempty"`
+
+	PvcNamespace string `json:"pvcNamespace,omitempty" yaml:"pvc_namespace,omitempty"`
+
 	SecretName string `json:"secretName,omitempty" yaml:"secret_name,omitempty"`
 
 	SecretNamespace string `json:"secretNamespace,omitempty" yaml:"secret_namespace,omitempty"`
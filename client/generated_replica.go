@@ -31,6 +31,10 @@ type Replica struct {
 
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
 
+	RebuildPaused bool `json:"rebuildPaused,omitempty" yaml:"rebuild_paused,omitempty"`
+
+	RebuildPriority int64 `json:"rebuildPriority,omitempty" yaml:"rebuild_priority,omitempty"`
+
 	Running bool `json:"running,omitempty" yaml:"running,omitempty"`
 }
 
@@ -0,0 +1,79 @@
+package client
+
+const (
+	REPLICA_REBUILD_INPUT_TYPE = "replicaRebuildInput"
+)
+
+type ReplicaRebuildInput struct {
+	Resource `yaml:"-"`
+
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+type ReplicaRebuildInputCollection struct {
+	Collection
+	Data   []ReplicaRebuildInput `json:"data,omitempty"`
+	client *ReplicaRebuildInputClient
+}
+
+type ReplicaRebuildInputClient struct {
+	rancherClient *RancherClient
+}
+
+type ReplicaRebuildInputOperations interface {
+	List(opts *ListOpts) (*ReplicaRebuildInputCollection, error)
+	Create(opts *ReplicaRebuildInput) (*ReplicaRebuildInput, error)
+	Update(existing *ReplicaRebuildInput, updates interface{}) (*ReplicaRebuildInput, error)
+	ById(id string) (*ReplicaRebuildInput, error)
+	Delete(container *ReplicaRebuildInput) error
+}
+
+func newReplicaRebuildInputClient(rancherClient *RancherClient) *ReplicaRebuildInputClient {
+	return &ReplicaRebuildInputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *ReplicaRebuildInputClient) Create(container *ReplicaRebuildInput) (*ReplicaRebuildInput, error) {
+	resp := &ReplicaRebuildInput{}
+	err := c.rancherClient.doCreate(REPLICA_REBUILD_INPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *ReplicaRebuildInputClient) Update(existing *ReplicaRebuildInput, updates interface{}) (*ReplicaRebuildInput, error) {
+	resp := &ReplicaRebuildInput{}
+	err := c.rancherClient.doUpdate(REPLICA_REBUILD_INPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *ReplicaRebuildInputClient) List(opts *ListOpts) (*ReplicaRebuildInputCollection, error) {
+	resp := &ReplicaRebuildInputCollection{}
+	err := c.rancherClient.doList(REPLICA_REBUILD_INPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *ReplicaRebuildInputCollection) Next() (*ReplicaRebuildInputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &ReplicaRebuildInputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *ReplicaRebuildInputClient) ById(id string) (*ReplicaRebuildInput, error) {
+	resp := &ReplicaRebuildInput{}
+	err := c.rancherClient.doById(REPLICA_REBUILD_INPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *ReplicaRebuildInputClient) Delete(container *ReplicaRebuildInput) error {
+	return c.rancherClient.doResourceDelete(REPLICA_REBUILD_INPUT_TYPE, &container.Resource)
+}
@@ -0,0 +1,89 @@
+package client
+
+const (
+	SALVAGE_CANDIDATE_TYPE = "salvageCandidate"
+)
+
+type SalvageCandidate struct {
+	Resource `yaml:"-"`
+
+	FailedAt string `json:"failedAt,omitempty" yaml:"failed_at,omitempty"`
+
+	LastModifiedAt string `json:"lastModifiedAt,omitempty" yaml:"last_modified_at,omitempty"`
+
+	NodeID string `json:"nodeID,omitempty" yaml:"node_id,omitempty"`
+
+	ReplicaName string `json:"replicaName,omitempty" yaml:"replica_name,omitempty"`
+
+	RevisionCounter string `json:"revisionCounter,omitempty" yaml:"revision_counter,omitempty"`
+
+	SnapshotChecksumCount int64 `json:"snapshotChecksumCount,omitempty" yaml:"snapshot_checksum_count,omitempty"`
+}
+
+type SalvageCandidateCollection struct {
+	Collection
+	Data   []SalvageCandidate `json:"data,omitempty"`
+	client *SalvageCandidateClient
+}
+
+type SalvageCandidateClient struct {
+	rancherClient *RancherClient
+}
+
+type SalvageCandidateOperations interface {
+	List(opts *ListOpts) (*SalvageCandidateCollection, error)
+	Create(opts *SalvageCandidate) (*SalvageCandidate, error)
+	Update(existing *SalvageCandidate, updates interface{}) (*SalvageCandidate, error)
+	ById(id string) (*SalvageCandidate, error)
+	Delete(container *SalvageCandidate) error
+}
+
+func newSalvageCandidateClient(rancherClient *RancherClient) *SalvageCandidateClient {
+	return &SalvageCandidateClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *SalvageCandidateClient) Create(container *SalvageCandidate) (*SalvageCandidate, error) {
+	resp := &SalvageCandidate{}
+	err := c.rancherClient.doCreate(SALVAGE_CANDIDATE_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *SalvageCandidateClient) Update(existing *SalvageCandidate, updates interface{}) (*SalvageCandidate, error) {
+	resp := &SalvageCandidate{}
+	err := c.rancherClient.doUpdate(SALVAGE_CANDIDATE_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *SalvageCandidateClient) List(opts *ListOpts) (*SalvageCandidateCollection, error) {
+	resp := &SalvageCandidateCollection{}
+	err := c.rancherClient.doList(SALVAGE_CANDIDATE_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *SalvageCandidateCollection) Next() (*SalvageCandidateCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &SalvageCandidateCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *SalvageCandidateClient) ById(id string) (*SalvageCandidate, error) {
+	resp := &SalvageCandidate{}
+	err := c.rancherClient.doById(SALVAGE_CANDIDATE_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *SalvageCandidateClient) Delete(container *SalvageCandidate) error {
+	return c.rancherClient.doResourceDelete(SALVAGE_CANDIDATE_TYPE, &container.Resource)
+}
@@ -0,0 +1,87 @@
+package client
+
+const (
+	SNAPSHOT_HASH_STATUS_TYPE = "snapshotHashStatus"
+)
+
+type SnapshotHashStatus struct {
+	Resource `yaml:"-"`
+
+	Checksum string `json:"checksum,omitempty" yaml:"checksum,omitempty"`
+
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	Replica string `json:"replica,omitempty" yaml:"replica,omitempty"`
+
+	SilentlyCorrupted bool `json:"silentlyCorrupted,omitempty" yaml:"silently_corrupted,omitempty"`
+
+	State string `json:"state,omitempty" yaml:"state,omitempty"`
+}
+
+type SnapshotHashStatusCollection struct {
+	Collection
+	Data   []SnapshotHashStatus `json:"data,omitempty"`
+	client *SnapshotHashStatusClient
+}
+
+type SnapshotHashStatusClient struct {
+	rancherClient *RancherClient
+}
+
+type SnapshotHashStatusOperations interface {
+	List(opts *ListOpts) (*SnapshotHashStatusCollection, error)
+	Create(opts *SnapshotHashStatus) (*SnapshotHashStatus, error)
+	Update(existing *SnapshotHashStatus, updates interface{}) (*SnapshotHashStatus, error)
+	ById(id string) (*SnapshotHashStatus, error)
+	Delete(container *SnapshotHashStatus) error
+}
+
+func newSnapshotHashStatusClient(rancherClient *RancherClient) *SnapshotHashStatusClient {
+	return &SnapshotHashStatusClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *SnapshotHashStatusClient) Create(container *SnapshotHashStatus) (*SnapshotHashStatus, error) {
+	resp := &SnapshotHashStatus{}
+	err := c.rancherClient.doCreate(SNAPSHOT_HASH_STATUS_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *SnapshotHashStatusClient) Update(existing *SnapshotHashStatus, updates interface{}) (*SnapshotHashStatus, error) {
+	resp := &SnapshotHashStatus{}
+	err := c.rancherClient.doUpdate(SNAPSHOT_HASH_STATUS_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *SnapshotHashStatusClient) List(opts *ListOpts) (*SnapshotHashStatusCollection, error) {
+	resp := &SnapshotHashStatusCollection{}
+	err := c.rancherClient.doList(SNAPSHOT_HASH_STATUS_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *SnapshotHashStatusCollection) Next() (*SnapshotHashStatusCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &SnapshotHashStatusCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *SnapshotHashStatusClient) ById(id string) (*SnapshotHashStatus, error) {
+	resp := &SnapshotHashStatus{}
+	err := c.rancherClient.doById(SNAPSHOT_HASH_STATUS_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *SnapshotHashStatusClient) Delete(container *SnapshotHashStatus) error {
+	return c.rancherClient.doResourceDelete(SNAPSHOT_HASH_STATUS_TYPE, &container.Resource)
+}
@@ -0,0 +1,79 @@
+package client
+
+const (
+	SNAPSHOT_HASH_STATUS_LIST_OUTPUT_TYPE = "snapshotHashStatusListOutput"
+)
+
+type SnapshotHashStatusListOutput struct {
+	Resource `yaml:"-"`
+
+	Data []SnapshotHashStatus `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+type SnapshotHashStatusListOutputCollection struct {
+	Collection
+	Data   []SnapshotHashStatusListOutput `json:"data,omitempty"`
+	client *SnapshotHashStatusListOutputClient
+}
+
+type SnapshotHashStatusListOutputClient struct {
+	rancherClient *RancherClient
+}
+
+type SnapshotHashStatusListOutputOperations interface {
+	List(opts *ListOpts) (*SnapshotHashStatusListOutputCollection, error)
+	Create(opts *SnapshotHashStatusListOutput) (*SnapshotHashStatusListOutput, error)
+	Update(existing *SnapshotHashStatusListOutput, updates interface{}) (*SnapshotHashStatusListOutput, error)
+	ById(id string) (*SnapshotHashStatusListOutput, error)
+	Delete(container *SnapshotHashStatusListOutput) error
+}
+
+func newSnapshotHashStatusListOutputClient(rancherClient *RancherClient) *SnapshotHashStatusListOutputClient {
+	return &SnapshotHashStatusListOutputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *SnapshotHashStatusListOutputClient) Create(container *SnapshotHashStatusListOutput) (*SnapshotHashStatusListOutput, error) {
+	resp := &SnapshotHashStatusListOutput{}
+	err := c.rancherClient.doCreate(SNAPSHOT_HASH_STATUS_LIST_OUTPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *SnapshotHashStatusListOutputClient) Update(existing *SnapshotHashStatusListOutput, updates interface{}) (*SnapshotHashStatusListOutput, error) {
+	resp := &SnapshotHashStatusListOutput{}
+	err := c.rancherClient.doUpdate(SNAPSHOT_HASH_STATUS_LIST_OUTPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *SnapshotHashStatusListOutputClient) List(opts *ListOpts) (*SnapshotHashStatusListOutputCollection, error) {
+	resp := &SnapshotHashStatusListOutputCollection{}
+	err := c.rancherClient.doList(SNAPSHOT_HASH_STATUS_LIST_OUTPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *SnapshotHashStatusListOutputCollection) Next() (*SnapshotHashStatusListOutputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &SnapshotHashStatusListOutputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *SnapshotHashStatusListOutputClient) ById(id string) (*SnapshotHashStatusListOutput, error) {
+	resp := &SnapshotHashStatusListOutput{}
+	err := c.rancherClient.doById(SNAPSHOT_HASH_STATUS_LIST_OUTPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *SnapshotHashStatusListOutputClient) Delete(container *SnapshotHashStatusListOutput) error {
+	return c.rancherClient.doResourceDelete(SNAPSHOT_HASH_STATUS_LIST_OUTPUT_TYPE, &container.Resource)
+}
@@ -9,6 +9,8 @@ type SnapshotInput struct {
 
 	BackupMode string `json:"backupMode,omitempty" yaml:"backupMode,omitempty"`
 
+	BackupTargetName string `json:"backupTargetName,omitempty" yaml:"backupTargetName,omitempty"`
+
 	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
 
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
@@ -0,0 +1,83 @@
+package client
+
+const (
+	UPDATE_AUTO_RESIZE_POLICY_INPUT_TYPE = "UpdateAutoResizePolicyInput"
+)
+
+type UpdateAutoResizePolicyInput struct {
+	Resource `yaml:"-"`
+
+	Increment string `json:"increment,omitempty" yaml:"increment,omitempty"`
+
+	MaxSize string `json:"maxSize,omitempty" yaml:"max_size,omitempty"`
+
+	ThresholdPercentage int64 `json:"thresholdPercentage,omitempty" yaml:"threshold_percentage,omitempty"`
+}
+
+type UpdateAutoResizePolicyInputCollection struct {
+	Collection
+	Data   []UpdateAutoResizePolicyInput `json:"data,omitempty"`
+	client *UpdateAutoResizePolicyInputClient
+}
+
+type UpdateAutoResizePolicyInputClient struct {
+	rancherClient *RancherClient
+}
+
+type UpdateAutoResizePolicyInputOperations interface {
+	List(opts *ListOpts) (*UpdateAutoResizePolicyInputCollection, error)
+	Create(opts *UpdateAutoResizePolicyInput) (*UpdateAutoResizePolicyInput, error)
+	Update(existing *UpdateAutoResizePolicyInput, updates interface{}) (*UpdateAutoResizePolicyInput, error)
+	ById(id string) (*UpdateAutoResizePolicyInput, error)
+	Delete(container *UpdateAutoResizePolicyInput) error
+}
+
+func newUpdateAutoResizePolicyInputClient(rancherClient *RancherClient) *UpdateAutoResizePolicyInputClient {
+	return &UpdateAutoResizePolicyInputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *UpdateAutoResizePolicyInputClient) Create(container *UpdateAutoResizePolicyInput) (*UpdateAutoResizePolicyInput, error) {
+	resp := &UpdateAutoResizePolicyInput{}
+	err := c.rancherClient.doCreate(UPDATE_AUTO_RESIZE_POLICY_INPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *UpdateAutoResizePolicyInputClient) Update(existing *UpdateAutoResizePolicyInput, updates interface{}) (*UpdateAutoResizePolicyInput, error) {
+	resp := &UpdateAutoResizePolicyInput{}
+	err := c.rancherClient.doUpdate(UPDATE_AUTO_RESIZE_POLICY_INPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *UpdateAutoResizePolicyInputClient) List(opts *ListOpts) (*UpdateAutoResizePolicyInputCollection, error) {
+	resp := &UpdateAutoResizePolicyInputCollection{}
+	err := c.rancherClient.doList(UPDATE_AUTO_RESIZE_POLICY_INPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *UpdateAutoResizePolicyInputCollection) Next() (*UpdateAutoResizePolicyInputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &UpdateAutoResizePolicyInputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *UpdateAutoResizePolicyInputClient) ById(id string) (*UpdateAutoResizePolicyInput, error) {
+	resp := &UpdateAutoResizePolicyInput{}
+	err := c.rancherClient.doById(UPDATE_AUTO_RESIZE_POLICY_INPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *UpdateAutoResizePolicyInputClient) Delete(container *UpdateAutoResizePolicyInput) error {
+	return c.rancherClient.doResourceDelete(UPDATE_AUTO_RESIZE_POLICY_INPUT_TYPE, &container.Resource)
+}
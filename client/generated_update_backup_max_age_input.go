@@ -0,0 +1,79 @@
+package client
+
+const (
+	UPDATE_BACKUP_MAX_AGE_INPUT_TYPE = "UpdateBackupMaxAgeInput"
+)
+
+type UpdateBackupMaxAgeInput struct {
+	Resource `yaml:"-"`
+
+	BackupMaxAge int64 `json:"backupMaxAge,omitempty" yaml:"backup_max_age,omitempty"`
+}
+
+type UpdateBackupMaxAgeInputCollection struct {
+	Collection
+	Data   []UpdateBackupMaxAgeInput `json:"data,omitempty"`
+	client *UpdateBackupMaxAgeInputClient
+}
+
+type UpdateBackupMaxAgeInputClient struct {
+	rancherClient *RancherClient
+}
+
+type UpdateBackupMaxAgeInputOperations interface {
+	List(opts *ListOpts) (*UpdateBackupMaxAgeInputCollection, error)
+	Create(opts *UpdateBackupMaxAgeInput) (*UpdateBackupMaxAgeInput, error)
+	Update(existing *UpdateBackupMaxAgeInput, updates interface{}) (*UpdateBackupMaxAgeInput, error)
+	ById(id string) (*UpdateBackupMaxAgeInput, error)
+	Delete(container *UpdateBackupMaxAgeInput) error
+}
+
+func newUpdateBackupMaxAgeInputClient(rancherClient *RancherClient) *UpdateBackupMaxAgeInputClient {
+	return &UpdateBackupMaxAgeInputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *UpdateBackupMaxAgeInputClient) Create(container *UpdateBackupMaxAgeInput) (*UpdateBackupMaxAgeInput, error) {
+	resp := &UpdateBackupMaxAgeInput{}
+	err := c.rancherClient.doCreate(UPDATE_BACKUP_MAX_AGE_INPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *UpdateBackupMaxAgeInputClient) Update(existing *UpdateBackupMaxAgeInput, updates interface{}) (*UpdateBackupMaxAgeInput, error) {
+	resp := &UpdateBackupMaxAgeInput{}
+	err := c.rancherClient.doUpdate(UPDATE_BACKUP_MAX_AGE_INPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *UpdateBackupMaxAgeInputClient) List(opts *ListOpts) (*UpdateBackupMaxAgeInputCollection, error) {
+	resp := &UpdateBackupMaxAgeInputCollection{}
+	err := c.rancherClient.doList(UPDATE_BACKUP_MAX_AGE_INPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *UpdateBackupMaxAgeInputCollection) Next() (*UpdateBackupMaxAgeInputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &UpdateBackupMaxAgeInputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *UpdateBackupMaxAgeInputClient) ById(id string) (*UpdateBackupMaxAgeInput, error) {
+	resp := &UpdateBackupMaxAgeInput{}
+	err := c.rancherClient.doById(UPDATE_BACKUP_MAX_AGE_INPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *UpdateBackupMaxAgeInputClient) Delete(container *UpdateBackupMaxAgeInput) error {
+	return c.rancherClient.doResourceDelete(UPDATE_BACKUP_MAX_AGE_INPUT_TYPE, &container.Resource)
+}
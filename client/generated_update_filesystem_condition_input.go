@@ -0,0 +1,85 @@
+package client
+
+const (
+	UPDATE_FILESYSTEM_CONDITION_INPUT_TYPE = "UpdateFilesystemConditionInput"
+)
+
+type UpdateFilesystemConditionInput struct {
+	Resource `yaml:"-"`
+
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+
+	Status string `json:"status,omitempty" yaml:"status,omitempty"`
+
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+}
+
+type UpdateFilesystemConditionInputCollection struct {
+	Collection
+	Data   []UpdateFilesystemConditionInput `json:"data,omitempty"`
+	client *UpdateFilesystemConditionInputClient
+}
+
+type UpdateFilesystemConditionInputClient struct {
+	rancherClient *RancherClient
+}
+
+type UpdateFilesystemConditionInputOperations interface {
+	List(opts *ListOpts) (*UpdateFilesystemConditionInputCollection, error)
+	Create(opts *UpdateFilesystemConditionInput) (*UpdateFilesystemConditionInput, error)
+	Update(existing *UpdateFilesystemConditionInput, updates interface{}) (*UpdateFilesystemConditionInput, error)
+	ById(id string) (*UpdateFilesystemConditionInput, error)
+	Delete(container *UpdateFilesystemConditionInput) error
+}
+
+func newUpdateFilesystemConditionInputClient(rancherClient *RancherClient) *UpdateFilesystemConditionInputClient {
+	return &UpdateFilesystemConditionInputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *UpdateFilesystemConditionInputClient) Create(container *UpdateFilesystemConditionInput) (*UpdateFilesystemConditionInput, error) {
+	resp := &UpdateFilesystemConditionInput{}
+	err := c.rancherClient.doCreate(UPDATE_FILESYSTEM_CONDITION_INPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *UpdateFilesystemConditionInputClient) Update(existing *UpdateFilesystemConditionInput, updates interface{}) (*UpdateFilesystemConditionInput, error) {
+	resp := &UpdateFilesystemConditionInput{}
+	err := c.rancherClient.doUpdate(UPDATE_FILESYSTEM_CONDITION_INPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *UpdateFilesystemConditionInputClient) List(opts *ListOpts) (*UpdateFilesystemConditionInputCollection, error) {
+	resp := &UpdateFilesystemConditionInputCollection{}
+	err := c.rancherClient.doList(UPDATE_FILESYSTEM_CONDITION_INPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *UpdateFilesystemConditionInputCollection) Next() (*UpdateFilesystemConditionInputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &UpdateFilesystemConditionInputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *UpdateFilesystemConditionInputClient) ById(id string) (*UpdateFilesystemConditionInput, error) {
+	resp := &UpdateFilesystemConditionInput{}
+	err := c.rancherClient.doById(UPDATE_FILESYSTEM_CONDITION_INPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *UpdateFilesystemConditionInputClient) Delete(container *UpdateFilesystemConditionInput) error {
+	return c.rancherClient.doResourceDelete(UPDATE_FILESYSTEM_CONDITION_INPUT_TYPE, &container.Resource)
+}
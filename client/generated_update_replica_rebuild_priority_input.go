@@ -0,0 +1,81 @@
+package client
+
+const (
+	UPDATE_REPLICA_REBUILD_PRIORITY_INPUT_TYPE = "UpdateReplicaRebuildPriorityInput"
+)
+
+type UpdateReplicaRebuildPriorityInput struct {
+	Resource `yaml:"-"`
+
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	RebuildPriority int64 `json:"rebuildPriority,omitempty" yaml:"rebuild_priority,omitempty"`
+}
+
+type UpdateReplicaRebuildPriorityInputCollection struct {
+	Collection
+	Data   []UpdateReplicaRebuildPriorityInput `json:"data,omitempty"`
+	client *UpdateReplicaRebuildPriorityInputClient
+}
+
+type UpdateReplicaRebuildPriorityInputClient struct {
+	rancherClient *RancherClient
+}
+
+type UpdateReplicaRebuildPriorityInputOperations interface {
+	List(opts *ListOpts) (*UpdateReplicaRebuildPriorityInputCollection, error)
+	Create(opts *UpdateReplicaRebuildPriorityInput) (*UpdateReplicaRebuildPriorityInput, error)
+	Update(existing *UpdateReplicaRebuildPriorityInput, updates interface{}) (*UpdateReplicaRebuildPriorityInput, error)
+	ById(id string) (*UpdateReplicaRebuildPriorityInput, error)
+	Delete(container *UpdateReplicaRebuildPriorityInput) error
+}
+
+func newUpdateReplicaRebuildPriorityInputClient(rancherClient *RancherClient) *UpdateReplicaRebuildPriorityInputClient {
+	return &UpdateReplicaRebuildPriorityInputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *UpdateReplicaRebuildPriorityInputClient) Create(container *UpdateReplicaRebuildPriorityInput) (*UpdateReplicaRebuildPriorityInput, error) {
+	resp := &UpdateReplicaRebuildPriorityInput{}
+	err := c.rancherClient.doCreate(UPDATE_REPLICA_REBUILD_PRIORITY_INPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *UpdateReplicaRebuildPriorityInputClient) Update(existing *UpdateReplicaRebuildPriorityInput, updates interface{}) (*UpdateReplicaRebuildPriorityInput, error) {
+	resp := &UpdateReplicaRebuildPriorityInput{}
+	err := c.rancherClient.doUpdate(UPDATE_REPLICA_REBUILD_PRIORITY_INPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *UpdateReplicaRebuildPriorityInputClient) List(opts *ListOpts) (*UpdateReplicaRebuildPriorityInputCollection, error) {
+	resp := &UpdateReplicaRebuildPriorityInputCollection{}
+	err := c.rancherClient.doList(UPDATE_REPLICA_REBUILD_PRIORITY_INPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *UpdateReplicaRebuildPriorityInputCollection) Next() (*UpdateReplicaRebuildPriorityInputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &UpdateReplicaRebuildPriorityInputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *UpdateReplicaRebuildPriorityInputClient) ById(id string) (*UpdateReplicaRebuildPriorityInput, error) {
+	resp := &UpdateReplicaRebuildPriorityInput{}
+	err := c.rancherClient.doById(UPDATE_REPLICA_REBUILD_PRIORITY_INPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *UpdateReplicaRebuildPriorityInputClient) Delete(container *UpdateReplicaRebuildPriorityInput) error {
+	return c.rancherClient.doResourceDelete(UPDATE_REPLICA_REBUILD_PRIORITY_INPUT_TYPE, &container.Resource)
+}
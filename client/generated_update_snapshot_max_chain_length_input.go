@@ -0,0 +1,79 @@
+package client
+
+const (
+	UPDATE_SNAPSHOT_MAX_CHAIN_LENGTH_INPUT_TYPE = "UpdateSnapshotMaxChainLengthInput"
+)
+
+type UpdateSnapshotMaxChainLengthInput struct {
+	Resource `yaml:"-"`
+
+	SnapshotMaxChainLength int64 `json:"snapshotMaxChainLength,omitempty" yaml:"snapshot_max_chain_length,omitempty"`
+}
+
+type UpdateSnapshotMaxChainLengthInputCollection struct {
+	Collection
+	Data   []UpdateSnapshotMaxChainLengthInput `json:"data,omitempty"`
+	client *UpdateSnapshotMaxChainLengthInputClient
+}
+
+type UpdateSnapshotMaxChainLengthInputClient struct {
+	rancherClient *RancherClient
+}
+
+type UpdateSnapshotMaxChainLengthInputOperations interface {
+	List(opts *ListOpts) (*UpdateSnapshotMaxChainLengthInputCollection, error)
+	Create(opts *UpdateSnapshotMaxChainLengthInput) (*UpdateSnapshotMaxChainLengthInput, error)
+	Update(existing *UpdateSnapshotMaxChainLengthInput, updates interface{}) (*UpdateSnapshotMaxChainLengthInput, error)
+	ById(id string) (*UpdateSnapshotMaxChainLengthInput, error)
+	Delete(container *UpdateSnapshotMaxChainLengthInput) error
+}
+
+func newUpdateSnapshotMaxChainLengthInputClient(rancherClient *RancherClient) *UpdateSnapshotMaxChainLengthInputClient {
+	return &UpdateSnapshotMaxChainLengthInputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *UpdateSnapshotMaxChainLengthInputClient) Create(container *UpdateSnapshotMaxChainLengthInput) (*UpdateSnapshotMaxChainLengthInput, error) {
+	resp := &UpdateSnapshotMaxChainLengthInput{}
+	err := c.rancherClient.doCreate(UPDATE_SNAPSHOT_MAX_CHAIN_LENGTH_INPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *UpdateSnapshotMaxChainLengthInputClient) Update(existing *UpdateSnapshotMaxChainLengthInput, updates interface{}) (*UpdateSnapshotMaxChainLengthInput, error) {
+	resp := &UpdateSnapshotMaxChainLengthInput{}
+	err := c.rancherClient.doUpdate(UPDATE_SNAPSHOT_MAX_CHAIN_LENGTH_INPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *UpdateSnapshotMaxChainLengthInputClient) List(opts *ListOpts) (*UpdateSnapshotMaxChainLengthInputCollection, error) {
+	resp := &UpdateSnapshotMaxChainLengthInputCollection{}
+	err := c.rancherClient.doList(UPDATE_SNAPSHOT_MAX_CHAIN_LENGTH_INPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *UpdateSnapshotMaxChainLengthInputCollection) Next() (*UpdateSnapshotMaxChainLengthInputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &UpdateSnapshotMaxChainLengthInputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *UpdateSnapshotMaxChainLengthInputClient) ById(id string) (*UpdateSnapshotMaxChainLengthInput, error) {
+	resp := &UpdateSnapshotMaxChainLengthInput{}
+	err := c.rancherClient.doById(UPDATE_SNAPSHOT_MAX_CHAIN_LENGTH_INPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *UpdateSnapshotMaxChainLengthInputClient) Delete(container *UpdateSnapshotMaxChainLengthInput) error {
+	return c.rancherClient.doResourceDelete(UPDATE_SNAPSHOT_MAX_CHAIN_LENGTH_INPUT_TYPE, &container.Resource)
+}
@@ -0,0 +1,83 @@
+package client
+
+const (
+	UPDATE_SNAPSHOT_RETENTION_POLICY_INPUT_TYPE = "UpdateSnapshotRetentionPolicyInput"
+)
+
+type UpdateSnapshotRetentionPolicyInput struct {
+	Resource `yaml:"-"`
+
+	SnapshotRetentionKeepLast int64 `json:"snapshotRetentionKeepLast,omitempty" yaml:"snapshot_retention_keep_last,omitempty"`
+
+	SnapshotRetentionMaxAge string `json:"snapshotRetentionMaxAge,omitempty" yaml:"snapshot_retention_max_age,omitempty"`
+
+	SnapshotRetentionMaxSize string `json:"snapshotRetentionMaxSize,omitempty" yaml:"snapshot_retention_max_size,omitempty"`
+}
+
+type UpdateSnapshotRetentionPolicyInputCollection struct {
+	Collection
+	Data   []UpdateSnapshotRetentionPolicyInput `json:"data,omitempty"`
+	client *UpdateSnapshotRetentionPolicyInputClient
+}
+
+type UpdateSnapshotRetentionPolicyInputClient struct {
+	rancherClient *RancherClient
+}
+
+type UpdateSnapshotRetentionPolicyInputOperations interface {
+	List(opts *ListOpts) (*UpdateSnapshotRetentionPolicyInputCollection, error)
+	Create(opts *UpdateSnapshotRetentionPolicyInput) (*UpdateSnapshotRetentionPolicyInput, error)
+	Update(existing *UpdateSnapshotRetentionPolicyInput, updates interface{}) (*UpdateSnapshotRetentionPolicyInput, error)
+	ById(id string) (*UpdateSnapshotRetentionPolicyInput, error)
+	Delete(container *UpdateSnapshotRetentionPolicyInput) error
+}
+
+func newUpdateSnapshotRetentionPolicyInputClient(rancherClient *RancherClient) *UpdateSnapshotRetentionPolicyInputClient {
+	return &UpdateSnapshotRetentionPolicyInputClient{
+		rancherClient: rancherClient,
+	}
+}
+
+func (c *UpdateSnapshotRetentionPolicyInputClient) Create(container *UpdateSnapshotRetentionPolicyInput) (*UpdateSnapshotRetentionPolicyInput, error) {
+	resp := &UpdateSnapshotRetentionPolicyInput{}
+	err := c.rancherClient.doCreate(UPDATE_SNAPSHOT_RETENTION_POLICY_INPUT_TYPE, container, resp)
+	return resp, err
+}
+
+func (c *UpdateSnapshotRetentionPolicyInputClient) Update(existing *UpdateSnapshotRetentionPolicyInput, updates interface{}) (*UpdateSnapshotRetentionPolicyInput, error) {
+	resp := &UpdateSnapshotRetentionPolicyInput{}
+	err := c.rancherClient.doUpdate(UPDATE_SNAPSHOT_RETENTION_POLICY_INPUT_TYPE, &existing.Resource, updates, resp)
+	return resp, err
+}
+
+func (c *UpdateSnapshotRetentionPolicyInputClient) List(opts *ListOpts) (*UpdateSnapshotRetentionPolicyInputCollection, error) {
+	resp := &UpdateSnapshotRetentionPolicyInputCollection{}
+	err := c.rancherClient.doList(UPDATE_SNAPSHOT_RETENTION_POLICY_INPUT_TYPE, opts, resp)
+	resp.client = c
+	return resp, err
+}
+
+func (cc *UpdateSnapshotRetentionPolicyInputCollection) Next() (*UpdateSnapshotRetentionPolicyInputCollection, error) {
+	if cc != nil && cc.Pagination != nil && cc.Pagination.Next != "" {
+		resp := &UpdateSnapshotRetentionPolicyInputCollection{}
+		err := cc.client.rancherClient.doNext(cc.Pagination.Next, resp)
+		resp.client = cc.client
+		return resp, err
+	}
+	return nil, nil
+}
+
+func (c *UpdateSnapshotRetentionPolicyInputClient) ById(id string) (*UpdateSnapshotRetentionPolicyInput, error) {
+	resp := &UpdateSnapshotRetentionPolicyInput{}
+	err := c.rancherClient.doById(UPDATE_SNAPSHOT_RETENTION_POLICY_INPUT_TYPE, id, resp)
+	if apiError, ok := err.(*ApiError); ok {
+		if apiError.StatusCode == 404 {
+			return nil, nil
+		}
+	}
+	return resp, err
+}
+
+func (c *UpdateSnapshotRetentionPolicyInputClient) Delete(container *UpdateSnapshotRetentionPolicyInput) error {
+	return c.rancherClient.doResourceDelete(UPDATE_SNAPSHOT_RETENTION_POLICY_INPUT_TYPE, &container.Resource)
+}
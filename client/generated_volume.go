@@ -9,16 +9,30 @@ type Volume struct {
 
 	AccessMode string `json:"accessMode,omitempty" yaml:"access_mode,omitempty"`
 
+	AdoptWarmPoolVolume bool `json:"adoptWarmPoolVolume,omitempty" yaml:"adopt_warm_pool_volume,omitempty"`
+
+	AutoResizeIncrement string `json:"autoResizeIncrement,omitempty" yaml:"auto_resize_increment,omitempty"`
+
+	AutoResizeMaxSize string `json:"autoResizeMaxSize,omitempty" yaml:"auto_resize_max_size,omitempty"`
+
+	AutoResizeThresholdPercentage int64 `json:"autoResizeThresholdPercentage,omitempty" yaml:"auto_resize_threshold_percentage,omitempty"`
+
 	BackingImage string `json:"backingImage,omitempty" yaml:"backing_image,omitempty"`
 
 	BackupCompressionMethod string `json:"backupCompressionMethod,omitempty" yaml:"backup_compression_method,omitempty"`
 
+	BackupMaxAge int64 `json:"backupMaxAge,omitempty" yaml:"backup_max_age,omitempty"`
+
 	BackupStatus []BackupStatus `json:"backupStatus,omitempty" yaml:"backup_status,omitempty"`
 
 	BackupTargetName string `json:"backupTargetName,omitempty" yaml:"backup_target_name,omitempty"`
 
+	CloneMode string `json:"cloneMode,omitempty" yaml:"clone_mode,omitempty"`
+
 	CloneStatus CloneStatus `json:"cloneStatus,omitempty" yaml:"clone_status,omitempty"`
 
+	CloneTargets []string `json:"cloneTargets,omitempty" yaml:"clone_targets,omitempty"`
+
 	Conditions map[string]interface{} `json:"conditions,omitempty" yaml:"conditions,omitempty"`
 
 	Controllers []Controller `json:"controllers,omitempty" yaml:"controllers,omitempty"`
@@ -47,6 +61,8 @@ type Volume struct {
 
 	Image string `json:"image,omitempty" yaml:"image,omitempty"`
 
+	IoPauseDeadline string `json:"ioPauseDeadline,omitempty" yaml:"io_pause_deadline,omitempty"`
+
 	KubernetesStatus KubernetesStatus `json:"kubernetesStatus,omitempty" yaml:"kubernetes_status,omitempty"`
 
 	LastAttachedBy string `json:"lastAttachedBy,omitempty" yaml:"last_attached_by,omitempty"`
@@ -55,6 +71,8 @@ type Volume struct {
 
 	LastBackupAt string `json:"lastBackupAt,omitempty" yaml:"last_backup_at,omitempty"`
 
+	LastFilesystemTrimAt string `json:"lastFilesystemTrimAt,omitempty" yaml:"last_filesystem_trim_at,omitempty"`
+
 	Migratable bool `json:"migratable,omitempty" yaml:"migratable,omitempty"`
 
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
@@ -67,6 +85,8 @@ type Volume struct {
 
 	Ready bool `json:"ready,omitempty" yaml:"ready,omitempty"`
 
+	RebuildPriority int64 `json:"rebuildPriority,omitempty" yaml:"rebuild_priority,omitempty"`
+
 	RebuildStatus []RebuildStatus `json:"rebuildStatus,omitempty" yaml:"rebuild_status,omitempty"`
 
 	RecurringJobSelector []VolumeRecurringJob `json:"recurringJobSelector,omitempty" yaml:"recurring_job_selector,omitempty"`
@@ -81,6 +101,8 @@ type Volume struct {
 
 	Replicas []Replica `json:"replicas,omitempty" yaml:"replicas,omitempty"`
 
+	RestoreCredential map[string]string `json:"restoreCredential,omitempty" yaml:"restore_credential,omitempty"`
+
 	RestoreInitiated bool `json:"restoreInitiated,omitempty" yaml:"restore_initiated,omitempty"`
 
 	RestoreRequired bool `json:"restoreRequired,omitempty" yaml:"restore_required,omitempty"`
@@ -93,6 +115,10 @@ type Volume struct {
 
 	Robustness string `json:"robustness,omitempty" yaml:"robustness,omitempty"`
 
+	SalvageCandidates []SalvageCandidate `json:"salvageCandidates,omitempty" yaml:"salvage_candidates,omitempty"`
+
+	SalvageDecision string `json:"salvageDecision,omitempty" yaml:"salvage_decision,omitempty"`
+
 	ShareEndpoint string `json:"shareEndpoint,omitempty" yaml:"share_endpoint,omitempty"`
 
 	ShareState string `json:"shareState,omitempty" yaml:"share_state,omitempty"`
@@ -101,16 +127,26 @@ type Volume struct {
 
 	SnapshotDataIntegrity string `json:"snapshotDataIntegrity,omitempty" yaml:"snapshot_data_integrity,omitempty"`
 
+	SnapshotMaxChainLength int64 `json:"snapshotMaxChainLength,omitempty" yaml:"snapshot_max_chain_length,omitempty"`
+
 	SnapshotMaxCount int64 `json:"snapshotMaxCount,omitempty" yaml:"snapshot_max_count,omitempty"`
 
 	SnapshotMaxSize string `json:"snapshotMaxSize,omitempty" yaml:"snapshot_max_size,omitempty"`
 
+	SnapshotRetentionKeepLast int64 `json:"snapshotRetentionKeepLast,omitempty" yaml:"snapshot_retention_keep_last,omitempty"`
+
+	SnapshotRetentionMaxAge string `json:"snapshotRetentionMaxAge,omitempty" yaml:"snapshot_retention_max_age,omitempty"`
+
+	SnapshotRetentionMaxSize string `json:"snapshotRetentionMaxSize,omitempty" yaml:"snapshot_retention_max_size,omitempty"`
+
 	StaleReplicaTimeout int64 `json:"staleReplicaTimeout,omitempty" yaml:"stale_replica_timeout,omitempty"`
 
 	Standby bool `json:"standby,omitempty" yaml:"standby,omitempty"`
 
 	State string `json:"state,omitempty" yaml:"state,omitempty"`
 
+	TrimFilesystemOnUnstage bool `json:"trimFilesystemOnUnstage,omitempty" yaml:"trim_filesystem_on_unstage,omitempty"`
+
 	UnmapMarkSnapChainRemoved string `json:"unmapMarkSnapChainRemoved,omitempty" yaml:"unmap_mark_snap_chain_removed,omitempty"`
 
 	VolumeAttachment VolumeAttachment `json:"volumeAttachment,omitempty" yaml:"volume_attachment,omitempty"`
@@ -135,6 +171,8 @@ type VolumeOperations interface {
 
 	ActionActivate(*Volume, *ActivateInput) (*Volume, error)
 
+	ActionActivateWithFinalSync(*Volume, *ActivateWithFinalSyncInput) (*Volume, error)
+
 	ActionAttach(*Volume, *AttachInput) (*Volume, error)
 
 	ActionCancelExpansion(*Volume) (*Volume, error)
@@ -143,6 +181,12 @@ type VolumeOperations interface {
 
 	ActionExpand(*Volume, *ExpandInput) (*Volume, error)
 
+	ActionLiveMigrate(*Volume, *LiveMigrateInput) (*Volume, error)
+
+	ActionMigrateToDiskTag(*Volume, *MigrateToDiskTagInput) (*Volume, error)
+
+	ActionPauseIO(*Volume, *PauseIOInput) (*Volume, error)
+
 	ActionPvCreate(*Volume, *PVCreateInput) (*Volume, error)
 
 	ActionPvcCreate(*Volume, *PVCCreateInput) (*Volume, error)
@@ -153,10 +197,20 @@ type VolumeOperations interface {
 
 	ActionRecurringJobList(*Volume) (*VolumeRecurringJob, error)
 
+	ActionReplicaRebuildPause(*Volume, *ReplicaRebuildInput) (*Volume, error)
+
+	ActionReplicaRebuildResume(*Volume, *ReplicaRebuildInput) (*Volume, error)
+
 	ActionReplicaRemove(*Volume, *ReplicaRemoveInput) (*Volume, error)
 
+	ActionResumeIO(*Volume) (*Volume, error)
+
+	ActionRotatePassphrase(*Volume) (*Volume, error)
+
 	ActionSalvage(*Volume, *SalvageInput) (*Volume, error)
 
+	ActionPickSalvageCandidate(*Volume, *PickSalvageCandidateInput) (*Volume, error)
+
 	ActionSnapshotBackup(*Volume, *SnapshotInput) (*Volume, error)
 
 	ActionSnapshotCRCreate(*Volume, *SnapshotCRInput) (*SnapshotCR, error)
@@ -173,6 +227,10 @@ type VolumeOperations interface {
 
 	ActionSnapshotGet(*Volume, *SnapshotInput) (*Snapshot, error)
 
+	ActionSnapshotHash(*Volume, *SnapshotInput) (*Volume, error)
+
+	ActionSnapshotHashStatus(*Volume, *SnapshotInput) (*SnapshotHashStatusListOutput, error)
+
 	ActionSnapshotList(*Volume) (*SnapshotListOutput, error)
 
 	ActionSnapshotPurge(*Volume) (*Volume, error)
@@ -182,6 +240,14 @@ type VolumeOperations interface {
 	ActionTrimFilesystem(*Volume) (*Volume, error)
 
 	ActionUpdateAccessMode(*Volume, *UpdateAccessModeInput) (*Volume, error)
+
+	ActionUpdateAutoResizePolicy(*Volume, *UpdateAutoResizePolicyInput) (*Volume, error)
+
+	ActionUpdateBackupMaxAge(*Volume, *UpdateBackupMaxAgeInput) (*Volume, error)
+
+	ActionUpdateFilesystemCondition(*Volume, *UpdateFilesystemConditionInput) (*Volume, error)
+
+	ActionUpdateReplicaRebuildPriority(*Volume, *UpdateReplicaRebuildPriorityInput) (*Volume, error)
 }
 
 func newVolumeClient(rancherClient *RancherClient) *VolumeClient {
@@ -243,6 +309,15 @@ func (c *VolumeClient) ActionActivate(resource *Volume, input *ActivateInput) (*
 	return resp, err
 }
 
+func (c *VolumeClient) ActionActivateWithFinalSync(resource *Volume, input *ActivateWithFinalSyncInput) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "activateWithFinalSync", &resource.Resource, input, resp)
+
+	return resp, err
+}
+
 func (c *VolumeClient) ActionAttach(resource *Volume, input *AttachInput) (*Volume, error) {
 
 	resp := &Volume{}
@@ -279,6 +354,33 @@ func (c *VolumeClient) ActionExpand(resource *Volume, input *ExpandInput) (*Volu
 	return resp, err
 }
 
+func (c *VolumeClient) ActionLiveMigrate(resource *Volume, input *LiveMigrateInput) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "liveMigrate", &resource.Resource, input, resp)
+
+	return resp, err
+}
+
+func (c *VolumeClient) ActionMigrateToDiskTag(resource *Volume, input *MigrateToDiskTagInput) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "migrateToDiskTag", &resource.Resource, input, resp)
+
+	return resp, err
+}
+
+func (c *VolumeClient) ActionPauseIO(resource *Volume, input *PauseIOInput) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "pauseIO", &resource.Resource, input, resp)
+
+	return resp, err
+}
+
 func (c *VolumeClient) ActionPvCreate(resource *Volume, input *PVCreateInput) (*Volume, error) {
 
 	resp := &Volume{}
@@ -324,6 +426,24 @@ func (c *VolumeClient) ActionRecurringJobList(resource *Volume) (*VolumeRecurrin
 	return resp, err
 }
 
+func (c *VolumeClient) ActionReplicaRebuildPause(resource *Volume, input *ReplicaRebuildInput) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "replicaRebuildPause", &resource.Resource, input, resp)
+
+	return resp, err
+}
+
+func (c *VolumeClient) ActionReplicaRebuildResume(resource *Volume, input *ReplicaRebuildInput) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "replicaRebuildResume", &resource.Resource, input, resp)
+
+	return resp, err
+}
+
 func (c *VolumeClient) ActionReplicaRemove(resource *Volume, input *ReplicaRemoveInput) (*Volume, error) {
 
 	resp := &Volume{}
@@ -333,6 +453,24 @@ func (c *VolumeClient) ActionReplicaRemove(resource *Volume, input *ReplicaRemov
 	return resp, err
 }
 
+func (c *VolumeClient) ActionResumeIO(resource *Volume) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "resumeIO", &resource.Resource, nil, resp)
+
+	return resp, err
+}
+
+func (c *VolumeClient) ActionRotatePassphrase(resource *Volume) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "rotatePassphrase", &resource.Resource, nil, resp)
+
+	return resp, err
+}
+
 func (c *VolumeClient) ActionSalvage(resource *Volume, input *SalvageInput) (*Volume, error) {
 
 	resp := &Volume{}
@@ -342,6 +480,15 @@ func (c *VolumeClient) ActionSalvage(resource *Volume, input *SalvageInput) (*Vo
 	return resp, err
 }
 
+func (c *VolumeClient) ActionPickSalvageCandidate(resource *Volume, input *PickSalvageCandidateInput) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "pickSalvageCandidate", &resource.Resource, input, resp)
+
+	return resp, err
+}
+
 func (c *VolumeClient) ActionSnapshotBackup(resource *Volume, input *SnapshotInput) (*Volume, error) {
 
 	resp := &Volume{}
@@ -414,6 +561,24 @@ func (c *VolumeClient) ActionSnapshotGet(resource *Volume, input *SnapshotInput)
 	return resp, err
 }
 
+func (c *VolumeClient) ActionSnapshotHash(resource *Volume, input *SnapshotInput) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "snapshotHash", &resource.Resource, input, resp)
+
+	return resp, err
+}
+
+func (c *VolumeClient) ActionSnapshotHashStatus(resource *Volume, input *SnapshotInput) (*SnapshotHashStatusListOutput, error) {
+
+	resp := &SnapshotHashStatusListOutput{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "snapshotHashStatus", &resource.Resource, input, resp)
+
+	return resp, err
+}
+
 func (c *VolumeClient) ActionSnapshotList(resource *Volume) (*SnapshotListOutput, error) {
 
 	resp := &SnapshotListOutput{}
@@ -458,3 +623,39 @@ func (c *VolumeClient) ActionUpdateAccessMode(resource *Volume, input *UpdateAcc
 
 	return resp, err
 }
+
+func (c *VolumeClient) ActionUpdateAutoResizePolicy(resource *Volume, input *UpdateAutoResizePolicyInput) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "updateAutoResizePolicy", &resource.Resource, input, resp)
+
+	return resp, err
+}
+
+func (c *VolumeClient) ActionUpdateReplicaRebuildPriority(resource *Volume, input *UpdateReplicaRebuildPriorityInput) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "updateReplicaRebuildPriority", &resource.Resource, input, resp)
+
+	return resp, err
+}
+
+func (c *VolumeClient) ActionUpdateBackupMaxAge(resource *Volume, input *UpdateBackupMaxAgeInput) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "updateBackupMaxAge", &resource.Resource, input, resp)
+
+	return resp, err
+}
+
+func (c *VolumeClient) ActionUpdateFilesystemCondition(resource *Volume, input *UpdateFilesystemConditionInput) (*Volume, error) {
+
+	resp := &Volume{}
+
+	err := c.rancherClient.doAction(VOLUME_TYPE, "updateFilesystemCondition", &resource.Resource, input, resp)
+
+	return resp, err
+}
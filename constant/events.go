@@ -16,6 +16,7 @@ const (
 	EventReasonRebuilt          = "Rebuilt"
 	EventReasonRebuilding       = "Rebuilding"
 	EventReasonFailedRebuilding = "FailedRebuilding"
+	EventReasonRebuildPaused    = "RebuildPaused"
 
 	EventReasonVolumeCloneCompleted = "VolumeCloneCompleted"
 	EventReasonVolumeCloneInitiated = "VolumeCloneInitiated"
@@ -25,13 +26,30 @@ const (
 	EventReasonTimeoutSnapshotPurge        = "TimeoutSnapshotPurge"
 	EventReasonFailedSnapshotPurge         = "FailedSnapshotPurge"
 
+	EventReasonMaintained        = "Maintained"
+	EventReasonFailedMaintenance = "FailedMaintenance"
+
 	EventReasonRestored      = "Restored"
 	EventReasonRestoredFmt   = "Restored %v"
 	EventReasonFailedRestore = "FailedRestore"
 
+	EventReasonReverted     = "Reverted"
+	EventReasonFailedRevert = "FailedRevert"
+
 	EventReasonFailedExpansion    = "FailedExpansion"
 	EventReasonSucceededExpansion = "SucceededExpansion"
 	EventReasonCanceledExpansion  = "CanceledExpansion"
+	EventReasonAutoResized        = "AutoResized"
+	EventReasonFailedAutoResize   = "FailedAutoResize"
+
+	EventReasonPassphraseRotated        = "PassphraseRotated"
+	EventReasonFailedPassphraseRotation = "FailedPassphraseRotation"
+
+	EventReasonFilesystemTrimmed    = "FilesystemTrimmed"
+	EventReasonFailedFilesystemTrim = "FailedFilesystemTrim"
+
+	EventReasonIOPaused  = "IOPaused"
+	EventReasonIOResumed = "IOResumed"
 
 	EventReasonAttached = "Attached"
 	EventReasonDetached = "Detached"
@@ -46,9 +64,21 @@ const (
 	EventReasonEvictionCanceled      = "EvictionCanceled"
 	EventReasonEvictionFailed        = "EvictionFailed"
 
+	EventReasonDiskTagMigrating = "DiskTagMigrating"
+	EventReasonDiskTagMigrated  = "DiskTagMigrated"
+
+	EventReasonCapacityRebalancing = "CapacityRebalancing"
+	EventReasonCapacityRebalanced  = "CapacityRebalanced"
+
 	EventReasonDetachedUnexpectedly = "DetachedUnexpectedly"
+	EventReasonExportedAsBlock      = "ExportedAsBlock"
 	EventReasonRemount              = "Remount"
+	EventReasonFailedRemount        = "FailedRemount"
+	EventReasonRebinding            = "Rebinding"
+	EventReasonRebound              = "Rebound"
+	EventReasonFailedPublish        = "FailedPublish"
 	EventReasonAutoSalvaged         = "AutoSalvaged"
+	EventReasonForceCleanup         = "ForceCleanup"
 
 	EventReasonFetching = "Fetching"
 	EventReasonFetched  = "Fetched"
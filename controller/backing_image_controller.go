@@ -16,7 +16,6 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/kubernetes/pkg/controller"
 
 	corev1 "k8s.io/api/core/v1"
@@ -52,7 +51,7 @@ type BackingImageController struct {
 
 	cacheSyncs []cache.InformerSynced
 
-	v2CopyBackoff *flowcontrol.Backoff
+	v2CopyBackoff *controllerBackoff
 
 	proxyConnCounter util.Counter
 }
@@ -83,10 +82,9 @@ func NewBackingImageController(
 
 		ds: ds,
 
-		v2CopyBackoff: flowcontrol.NewBackOff(time.Minute, time.Minute*5),
-
 		proxyConnCounter: proxyConnCounter,
 	}
+	bic.v2CopyBackoff = bic.newBackoff("longhorn-backing-image/v2-copy", time.Minute, time.Minute*5)
 
 	var err error
 	if _, err = ds.BackingImageInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
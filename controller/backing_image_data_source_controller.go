@@ -3,6 +3,7 @@ package controller
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"reflect"
 	"strconv"
 	"strings"
@@ -17,7 +18,6 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/kubernetes/pkg/controller"
 
 	corev1 "k8s.io/api/core/v1"
@@ -54,7 +54,7 @@ type BackingImageDataSourceController struct {
 
 	ds *datastore.DataStore
 
-	backoff *flowcontrol.Backoff
+	backoff *controllerBackoff
 
 	cacheSyncs []cache.InformerSynced
 
@@ -73,7 +73,7 @@ type BackingImageDataSourceMonitor struct {
 	controllerID string
 	log          *logrus.Entry
 	ds           *datastore.DataStore
-	backoff      *flowcontrol.Backoff
+	backoff      *controllerBackoff
 }
 
 func NewBackingImageDataSourceController(
@@ -102,13 +102,12 @@ func NewBackingImageDataSourceController(
 
 		ds: ds,
 
-		backoff: flowcontrol.NewBackOff(time.Minute, time.Minute*5),
-
 		lock:       &sync.RWMutex{},
 		monitorMap: map[string]chan struct{}{},
 
 		proxyConnCounter: proxyConnCounter,
 	}
+	c.backoff = c.newBackoff("longhorn-backing-image-data-source", time.Minute, time.Minute*5)
 
 	var err error
 	if _, err = ds.BackingImageDataSourceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -469,8 +468,8 @@ func (c *BackingImageDataSourceController) syncBackingImageDataSourcePod(bids *l
 		if bids.Status.StorageIP != storageIP {
 			bids.Status.StorageIP = storageIP
 		}
-		if bids.Status.IP != pod.Status.PodIP {
-			bids.Status.IP = pod.Status.PodIP
+		if podIP := c.ds.GetPodIP(pod); bids.Status.IP != podIP {
+			bids.Status.IP = podIP
 		}
 		if !c.isMonitoring(bids.Name) {
 			c.startMonitoring(bids)
@@ -646,16 +645,16 @@ func (c *BackingImageDataSourceController) generateBackingImageDataSourcePodMani
 		return nil, err
 	}
 
-	tolerations, err := c.ds.GetSettingTaintToleration()
+	listenIP, err := c.ds.GetListenIP()
 	if err != nil {
 		return nil, err
 	}
-	tolerationsByte, err := json.Marshal(tolerations)
+
+	priorityClass, tolerations, _, err := c.ds.GetSystemManagedComponentScheduling(types.SystemManagedComponentBackingImage)
 	if err != nil {
 		return nil, err
 	}
-
-	priorityClass, err := c.ds.GetSettingWithAutoFillingRO(types.SettingNamePriorityClass)
+	tolerationsByte, err := json.Marshal(tolerations)
 	if err != nil {
 		return nil, err
 	}
@@ -676,8 +675,8 @@ func (c *BackingImageDataSourceController) generateBackingImageDataSourcePodMani
 	cmd := []string{
 		"backing-image-manager", "--debug",
 		"data-source",
-		"--listen", fmt.Sprintf("%s:%d", "0.0.0.0", engineapi.BackingImageDataSourceDefaultPort),
-		"--sync-listen", fmt.Sprintf("%s:%d", "0.0.0.0", engineapi.BackingImageSyncServerDefaultPort),
+		"--listen", net.JoinHostPort(listenIP, strconv.Itoa(engineapi.BackingImageDataSourceDefaultPort)),
+		"--sync-listen", net.JoinHostPort(listenIP, strconv.Itoa(engineapi.BackingImageSyncServerDefaultPort)),
 		"--name", bids.Name,
 		"--uuid", bids.Spec.UUID,
 		"--source-type", string(bids.Spec.SourceType),
@@ -760,7 +759,7 @@ func (c *BackingImageDataSourceController) generateBackingImageDataSourcePodMani
 			ServiceAccountName: c.serviceAccount,
 			Tolerations:        util.GetDistinctTolerations(tolerations),
 			NodeSelector:       nodeSelector,
-			PriorityClassName:  priorityClass.Value,
+			PriorityClassName:  priorityClass,
 			Containers: []corev1.Container{
 				{
 					Name:            BackingImageDataSourcePodContainerName,
@@ -942,7 +941,7 @@ func (c *BackingImageDataSourceController) prepareRunningParametersForExport(bid
 			continue
 		}
 		rAddress := e.Status.CurrentReplicaAddressMap[rName]
-		if rAddress == "" || rAddress != fmt.Sprintf("%s:%d", r.Status.StorageIP, r.Status.Port) {
+		if rAddress == "" || rAddress != net.JoinHostPort(r.Status.StorageIP, strconv.Itoa(r.Status.Port)) {
 			continue
 		}
 		bids.Status.RunningParameters[longhorn.DataSourceTypeExportFromVolumeParameterSenderAddress] = rAddress
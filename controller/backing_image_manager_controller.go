@@ -3,7 +3,9 @@ package controller
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"reflect"
+	"strconv"
 	"sync"
 	"time"
 
@@ -15,7 +17,6 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/kubernetes/pkg/controller"
 
 	corev1 "k8s.io/api/core/v1"
@@ -72,7 +73,7 @@ type BackingImageManagerMonitor struct {
 
 	ds                 *datastore.DataStore
 	log                logrus.FieldLogger
-	backoff            *flowcontrol.Backoff
+	backoff            *controllerBackoff
 	lock               *sync.Mutex
 	updateNotification bool
 	// Receive stop signals from main sync loop
@@ -326,9 +327,9 @@ func (c *BackingImageManagerController) syncBackingImageManager(key string) (err
 	}
 
 	backoffValue, _ := c.backoffMap.Load(bim.Name)
-	backoff, ok := backoffValue.(*flowcontrol.Backoff)
+	backoff, ok := backoffValue.(*controllerBackoff)
 	if !ok {
-		backoff = flowcontrol.NewBackOff(time.Minute, time.Minute*5)
+		backoff = c.newBackoff("longhorn-backing-image-manager/"+bim.Name, time.Minute, time.Minute*5)
 		c.backoffMap.Store(bim.Name, backoff)
 	}
 
@@ -373,7 +374,7 @@ func (c *BackingImageManagerController) cleanupBackingImageManager(bim *longhorn
 	if c.isMonitoring(bim.Name) {
 		c.stopMonitoring(bim.Name)
 	}
-	c.backoffMap.Delete(bim.Name)
+	c.deleteBackoff(bim.Name)
 	if err := c.ds.DeletePod(bim.Name); err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
@@ -381,6 +382,14 @@ func (c *BackingImageManagerController) cleanupBackingImageManager(bim *longhorn
 	return nil
 }
 
+func (c *BackingImageManagerController) deleteBackoff(bimName string) {
+	if backoffValue, loaded := c.backoffMap.LoadAndDelete(bimName); loaded {
+		if backoff, ok := backoffValue.(*controllerBackoff); ok {
+			c.removeBackoff(backoff)
+		}
+	}
+}
+
 func (c *BackingImageManagerController) updateForUnknownBackingImageManager(bim *longhorn.BackingImageManager) {
 	if bim.Status.CurrentState != longhorn.BackingImageManagerStateUnknown {
 		return
@@ -389,7 +398,7 @@ func (c *BackingImageManagerController) updateForUnknownBackingImageManager(bim
 	if c.isMonitoring(bim.Name) {
 		c.stopMonitoring(bim.Name)
 	}
-	c.backoffMap.Delete(bim.Name)
+	c.deleteBackoff(bim.Name)
 
 	log := getLoggerForBackingImageManager(c.logger, bim)
 	for biName, info := range bim.Status.BackingImageFileMap {
@@ -418,7 +427,7 @@ func (c *BackingImageManagerController) updateForUnknownBackingImageManager(bim
 
 }
 
-func (c *BackingImageManagerController) syncBackingImageManagerPod(bim *longhorn.BackingImageManager, backoff *flowcontrol.Backoff) (err error) {
+func (c *BackingImageManagerController) syncBackingImageManagerPod(bim *longhorn.BackingImageManager, backoff *controllerBackoff) (err error) {
 	defer func() {
 		err = errors.Wrap(err, "failed to sync backing image manager pod")
 	}()
@@ -488,7 +497,7 @@ func (c *BackingImageManagerController) syncBackingImageManagerPod(bim *longhorn
 					log.Warnf("Inconsistent storage IP from pod %v, update backing image status storage IP %v", pod.Name, bim.Status.StorageIP)
 				}
 
-				bim.Status.IP = pod.Status.PodIP
+				bim.Status.IP = c.ds.GetPodIP(pod)
 			}
 		default:
 			log.Errorf("Unexpected pod phase %v, will update backing image manager to state %v", pod.Status.Phase, longhorn.BackingImageManagerStateError)
@@ -557,7 +566,7 @@ func (c *BackingImageManagerController) syncBackingImageManagerPod(bim *longhorn
 	return nil
 }
 
-func (c *BackingImageManagerController) handleBackingImageFiles(bim *longhorn.BackingImageManager, backoff *flowcontrol.Backoff) (err error) {
+func (c *BackingImageManagerController) handleBackingImageFiles(bim *longhorn.BackingImageManager, backoff *controllerBackoff) (err error) {
 	log := getLoggerForBackingImageManager(c.logger, bim)
 
 	if bim.Status.CurrentState != longhorn.BackingImageManagerStateRunning {
@@ -589,7 +598,7 @@ func (c *BackingImageManagerController) handleBackingImageFiles(bim *longhorn.Ba
 	return nil
 }
 
-func (c *BackingImageManagerController) deleteInvalidBackingImages(bim *longhorn.BackingImageManager, cli *engineapi.BackingImageManagerClient, log logrus.FieldLogger, backoff *flowcontrol.Backoff) (err error) {
+func (c *BackingImageManagerController) deleteInvalidBackingImages(bim *longhorn.BackingImageManager, cli *engineapi.BackingImageManagerClient, log logrus.FieldLogger, backoff *controllerBackoff) (err error) {
 	defer func() {
 		err = errors.Wrap(err, "failed to do cleanup for invalid backing images")
 	}()
@@ -651,7 +660,7 @@ func (c *BackingImageManagerController) deleteInvalidBackingImages(bim *longhorn
 	return nil
 }
 
-func (c *BackingImageManagerController) prepareBackingImageFiles(currentBIM *longhorn.BackingImageManager, cli *engineapi.BackingImageManagerClient, bimLog logrus.FieldLogger, backoff *flowcontrol.Backoff) (err error) {
+func (c *BackingImageManagerController) prepareBackingImageFiles(currentBIM *longhorn.BackingImageManager, cli *engineapi.BackingImageManagerClient, bimLog logrus.FieldLogger, backoff *controllerBackoff) (err error) {
 	defer func() {
 		err = errors.Wrap(err, "failed to prepare backing image files")
 	}()
@@ -709,7 +718,7 @@ func (c *BackingImageManagerController) prepareBackingImageFiles(currentBIM *lon
 				continue
 			}
 			log.Infof("Starting to fetch the data source file from the backing image data source work directory %v", bimtypes.DataSourceDirectoryName)
-			if _, err := cli.Fetch(biRO.Name, biRO.Status.UUID, bids.Status.Checksum, fmt.Sprintf("%s:%d", bids.Status.StorageIP, engineapi.BackingImageDataSourceDefaultPort), bids.Status.Size); err != nil {
+			if _, err := cli.Fetch(biRO.Name, biRO.Status.UUID, bids.Status.Checksum, net.JoinHostPort(bids.Status.StorageIP, strconv.Itoa(engineapi.BackingImageDataSourceDefaultPort)), bids.Status.Size); err != nil {
 				if types.ErrorAlreadyExists(err) {
 					continue
 				}
@@ -809,7 +818,7 @@ func (c *BackingImageManagerController) createBackingImageManagerPod(bim *longho
 		err = errors.Wrap(err, "failed to create backing image manager pod")
 	}()
 
-	tolerations, err := c.ds.GetSettingTaintToleration()
+	_, tolerations, _, err := c.ds.GetSystemManagedComponentScheduling(types.SystemManagedComponentBackingImage)
 	if err != nil {
 		return err
 	}
@@ -841,7 +850,12 @@ func (c *BackingImageManagerController) generateBackingImageManagerPodManifest(b
 		return nil, err
 	}
 
-	priorityClass, err := c.ds.GetSettingWithAutoFillingRO(types.SettingNamePriorityClass)
+	priorityClass, _, _, err := c.ds.GetSystemManagedComponentScheduling(types.SystemManagedComponentBackingImage)
+	if err != nil {
+		return nil, err
+	}
+
+	listenIP, err := c.ds.GetListenIP()
 	if err != nil {
 		return nil, err
 	}
@@ -864,7 +878,7 @@ func (c *BackingImageManagerController) generateBackingImageManagerPodManifest(b
 			ServiceAccountName: c.serviceAccount,
 			Tolerations:        util.GetDistinctTolerations(tolerations),
 			NodeSelector:       nodeSelector,
-			PriorityClassName:  priorityClass.Value,
+			PriorityClassName:  priorityClass,
 			Containers: []corev1.Container{
 				{
 					Name:            BackingImageManagerPodContainerName,
@@ -873,8 +887,8 @@ func (c *BackingImageManagerController) generateBackingImageManagerPodManifest(b
 					Command: []string{
 						"backing-image-manager", "--debug",
 						"daemon",
-						"--listen", fmt.Sprintf("%s:%d", "0.0.0.0", engineapi.BackingImageManagerDefaultPort),
-						"--sync-listen", fmt.Sprintf("%s:%d", "0.0.0.0", engineapi.BackingImageSyncServerDefaultPort),
+						"--listen", net.JoinHostPort(listenIP, strconv.Itoa(engineapi.BackingImageManagerDefaultPort)),
+						"--sync-listen", net.JoinHostPort(listenIP, strconv.Itoa(engineapi.BackingImageSyncServerDefaultPort)),
 					},
 					ReadinessProbe: &corev1.Probe{
 						ProbeHandler: corev1.ProbeHandler{
@@ -1071,7 +1085,7 @@ func (c *BackingImageManagerController) enqueueForBackingImageManagerPod(obj int
 	c.enqueueBackingImageManager(bimRO)
 }
 
-func (c *BackingImageManagerController) startMonitoring(bim *longhorn.BackingImageManager, backoff *flowcontrol.Backoff) {
+func (c *BackingImageManagerController) startMonitoring(bim *longhorn.BackingImageManager, backoff *controllerBackoff) {
 	log := getLoggerForBackingImageManager(c.logger, bim)
 
 	c.lock.Lock()
@@ -0,0 +1,259 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// BackoffEntry is the current backoff state of a key tracked by a controller backoff
+type BackoffEntry struct {
+	Name       string `json:"name"`
+	Key        string `json:"key"`
+	Failures   int    `json:"failures"`
+	Backoff    string `json:"backoff"`
+	LastUpdate string `json:"lastUpdate"`
+}
+
+type backoffSource interface {
+	backoffName() string
+	backoffEntries() []BackoffEntry
+}
+
+// backoffSourceProvider is implemented by the controllers, which own the backoff sources
+type backoffSourceProvider interface {
+	backoffSources() []backoffSource
+}
+
+// BackoffRegistry collects the retry backoffs of the controllers of this manager, so their state can be inspected
+type BackoffRegistry struct {
+	lock        sync.RWMutex
+	controllers []backoffSourceProvider
+}
+
+func NewBackoffRegistry() *BackoffRegistry {
+	return &BackoffRegistry{}
+}
+
+func (r *BackoffRegistry) register(controllers ...backoffSourceProvider) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.controllers = append(r.controllers, controllers...)
+}
+
+// List returns the backoff state of all keys currently tracked by the registered controllers. The result can be
+// narrowed down by the backoff name and by the key. A key also matches the name part of a namespaced queue key, e.g.
+// "vol-1" matches "longhorn-system/vol-1".
+func (r *BackoffRegistry) List(name, key string) []BackoffEntry {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	entries := []BackoffEntry{}
+	for _, controller := range r.controllers {
+		for _, source := range controller.backoffSources() {
+			if name != "" && source.backoffName() != name {
+				continue
+			}
+			for _, entry := range source.backoffEntries() {
+				if key != "" && entry.Key != key && !strings.HasSuffix(entry.Key, "/"+key) {
+					continue
+				}
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// newBackoff creates a backoff of the controller, which is listed by the backoff registry until it's removed
+func (c *baseController) newBackoff(name string, initial, max time.Duration) *controllerBackoff {
+	b := newControllerBackoff(name, initial, max)
+
+	c.backoffLock.Lock()
+	defer c.backoffLock.Unlock()
+	c.backoffs[b] = struct{}{}
+	return b
+}
+
+func (c *baseController) removeBackoff(b *controllerBackoff) {
+	c.backoffLock.Lock()
+	defer c.backoffLock.Unlock()
+	delete(c.backoffs, b)
+}
+
+// backoffSources returns the backoffs of the controller, and the rate limiter of the queue until it's shut down
+func (c *baseController) backoffSources() []backoffSource {
+	c.backoffLock.RLock()
+	defer c.backoffLock.RUnlock()
+
+	sources := []backoffSource{}
+	for b := range c.backoffs {
+		sources = append(sources, b)
+	}
+	if c.rateLimiter != nil && !c.queue.ShuttingDown() {
+		sources = append(sources, c.rateLimiter)
+	}
+	return sources
+}
+
+func newBackoffEntry(name, key string, failures int, backoff time.Duration, lastUpdate time.Time) BackoffEntry {
+	return BackoffEntry{
+		Name:       name,
+		Key:        key,
+		Failures:   failures,
+		Backoff:    backoff.String(),
+		LastUpdate: lastUpdate.UTC().Format(time.RFC3339),
+	}
+}
+
+// controllerBackoff is a deterministic exponential backoff bounded by a max duration,
+// which keeps track of the failures of each key so the state can be inspected.
+type controllerBackoff struct {
+	*flowcontrol.Backoff
+
+	name string
+	max  time.Duration
+
+	lock       sync.RWMutex
+	failures   map[string]int
+	lastUpdate map[string]time.Time
+}
+
+func newControllerBackoff(name string, initial, max time.Duration) *controllerBackoff {
+	return &controllerBackoff{
+		Backoff: flowcontrol.NewBackOff(initial, max),
+
+		name: name,
+		max:  max,
+
+		failures:   map[string]int{},
+		lastUpdate: map[string]time.Time{},
+	}
+}
+
+// Next moves the backoff of the key to the next mark, capping at the max duration
+func (b *controllerBackoff) Next(id string, eventTime time.Time) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	// The underlying backoff starts over once the entry is older than twice the max duration
+	if lastUpdate, ok := b.lastUpdate[id]; !ok || eventTime.Sub(lastUpdate) > 2*b.max {
+		b.failures[id] = 0
+	}
+	b.Backoff.Next(id, eventTime)
+	b.failures[id]++
+	b.lastUpdate[id] = eventTime
+}
+
+func (b *controllerBackoff) Reset(id string) {
+	b.Backoff.Reset(id)
+	b.deleteTrackedEntry(id)
+}
+
+func (b *controllerBackoff) DeleteEntry(id string) {
+	b.Backoff.DeleteEntry(id)
+	b.deleteTrackedEntry(id)
+}
+
+func (b *controllerBackoff) GC() {
+	b.Backoff.GC()
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for id := range b.failures {
+		if b.Backoff.Get(id) == 0 {
+			delete(b.failures, id)
+			delete(b.lastUpdate, id)
+		}
+	}
+}
+
+func (b *controllerBackoff) deleteTrackedEntry(id string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.failures, id)
+	delete(b.lastUpdate, id)
+}
+
+func (b *controllerBackoff) backoffName() string {
+	return b.name
+}
+
+func (b *controllerBackoff) backoffEntries() []BackoffEntry {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	entries := []BackoffEntry{}
+	for id, failures := range b.failures {
+		entries = append(entries, newBackoffEntry(b.name, id, failures, b.Backoff.Get(id), b.lastUpdate[id]))
+	}
+	return entries
+}
+
+// trackedRateLimiter records the requeue delay of each queue key computed by the wrapped rate limiter
+type trackedRateLimiter struct {
+	workqueue.TypedRateLimiter[any]
+
+	name string
+
+	lock       sync.RWMutex
+	delays     map[any]time.Duration
+	lastUpdate map[any]time.Time
+}
+
+func newTrackedRateLimiter(name string, rateLimiter workqueue.TypedRateLimiter[any]) *trackedRateLimiter {
+	return &trackedRateLimiter{
+		TypedRateLimiter: rateLimiter,
+
+		name: name,
+
+		delays:     map[any]time.Duration{},
+		lastUpdate: map[any]time.Time{},
+	}
+}
+
+func (r *trackedRateLimiter) When(item any) time.Duration {
+	delay := r.TypedRateLimiter.When(item)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.delays[item] = delay
+	r.lastUpdate[item] = time.Now()
+	return delay
+}
+
+func (r *trackedRateLimiter) Forget(item any) {
+	r.TypedRateLimiter.Forget(item)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.delays, item)
+	delete(r.lastUpdate, item)
+}
+
+func (r *trackedRateLimiter) backoffName() string {
+	return r.name
+}
+
+func (r *trackedRateLimiter) backoffEntries() []BackoffEntry {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	entries := []BackoffEntry{}
+	for item, delay := range r.delays {
+		entries = append(entries, newBackoffEntry(r.name, fmt.Sprintf("%v", item), r.NumRequeues(item), delay, r.lastUpdate[item]))
+	}
+	return entries
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	. "gopkg.in/check.v1"
+)
+
+func newTestBackoffController(name string) (*baseController, *BackoffRegistry) {
+	c := newBaseController(name, logrus.StandardLogger())
+	backoffs := NewBackoffRegistry()
+	backoffs.register(c)
+	return c, backoffs
+}
+
+func (s *TestSuite) TestControllerBackoff(c *C) {
+	ctrl, backoffs := newTestBackoffController("test-controller")
+	backoff := ctrl.newBackoff("test-backoff", time.Second, 4*time.Second)
+
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		backoff.Next("vol-1", now)
+	}
+	backoff.Next("vol-2", now)
+
+	entries := backoffs.List("test-backoff", "")
+	c.Assert(entries, HasLen, 2)
+	c.Assert(entries[0].Key, Equals, "vol-1")
+	c.Assert(entries[0].Failures, Equals, 4)
+	c.Assert(entries[0].Backoff, Equals, (4 * time.Second).String())
+	c.Assert(entries[1].Key, Equals, "vol-2")
+	c.Assert(entries[1].Failures, Equals, 1)
+	c.Assert(entries[1].Backoff, Equals, time.Second.String())
+
+	entries = backoffs.List("", "vol-2")
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Name, Equals, "test-backoff")
+
+	backoff.DeleteEntry("vol-1")
+	c.Assert(backoffs.List("test-backoff", "vol-1"), HasLen, 0)
+	c.Assert(backoff.IsInBackOffSinceUpdate("vol-1", now), Equals, false)
+
+	// A removed backoff isn't listed anymore
+	ctrl.removeBackoff(backoff)
+	c.Assert(backoffs.List("test-backoff", ""), HasLen, 0)
+}
+
+func (s *TestSuite) TestTrackedRateLimiter(c *C) {
+	ctrl, backoffs := newTestBackoffController("test-rate-limiter")
+
+	key := TestNamespace + "/" + TestVolumeName
+	for i := 0; i < 3; i++ {
+		ctrl.queue.AddRateLimited(key)
+	}
+
+	entries := backoffs.List("test-rate-limiter", TestVolumeName)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Key, Equals, key)
+	c.Assert(entries[0].Failures, Equals, 3)
+	c.Assert(entries[0].Backoff, Equals, (20 * time.Millisecond).String())
+
+	ctrl.queue.Forget(key)
+	c.Assert(backoffs.List("test-rate-limiter", ""), HasLen, 0)
+}
+
+func (s *TestSuite) TestControllerBackoffFailures(c *C) {
+	ctrl, backoffs := newTestBackoffController("test-controller")
+	backoff := ctrl.newBackoff("test-backoff-failures", time.Second, time.Second)
+
+	// The failures keep counting once the backoff reaches the max duration, even if it's the initial duration
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		backoff.Next("vol-1", now)
+	}
+	entries := backoffs.List("test-backoff-failures", "vol-1")
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Failures, Equals, 3)
+
+	// The failures start over once the entry is expired
+	backoff.Next("vol-1", now.Add(3*time.Second))
+	entries = backoffs.List("test-backoff-failures", "vol-1")
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Failures, Equals, 1)
+}
+
+func (s *TestSuite) TestTrackedQueueShutDown(c *C) {
+	ctrl, backoffs := newTestBackoffController("test-tracked-queue")
+	ctrl.queue.AddRateLimited(TestVolumeName)
+	c.Assert(backoffs.List("test-tracked-queue", ""), HasLen, 1)
+
+	ctrl.queue.ShutDown()
+	c.Assert(backoffs.List("test-tracked-queue", ""), HasLen, 0)
+}
@@ -14,7 +14,6 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/kubernetes/pkg/controller"
 
 	corev1 "k8s.io/api/core/v1"
@@ -48,6 +47,7 @@ const (
 	FailedToGetSnapshotMessage             = "Failed to get the Snapshot %v"
 	FailedToDeleteBackupMessage            = "Failed to delete the backup %v in the backupstore, err %v"
 	NoDeletionInProgressRecordMessage      = "No deletion in progress record, retry the deletion command"
+	WaitForBackupTargetAvailableMessage    = "Waiting for the backup target %v to be available to resume the backup failed with error: %v"
 )
 
 const (
@@ -89,8 +89,12 @@ type BackupController struct {
 	deletingMapLock       sync.Mutex
 	inProgressDeletingMap map[string]*DeletingStatus
 
-	deletingBackoff      *flowcontrol.Backoff
+	deletingBackoff      *controllerBackoff
 	creationRetryCounter *util.TimedCounter
+
+	// Use to track the backups failed while the backup target may be unreachable
+	backupTargetUnavailableHoldsLock sync.Mutex
+	backupTargetUnavailableHolds     map[string]*backupTargetUnavailableHold
 }
 
 func NewBackupController(
@@ -128,9 +132,12 @@ func NewBackupController(
 		deletingMapLock:       sync.Mutex{},
 		inProgressDeletingMap: map[string]*DeletingStatus{},
 
-		deletingBackoff:      flowcontrol.NewBackOff(DeletionMinInterval, DeletionMaxInterval),
 		creationRetryCounter: util.NewTimedCounter(creationRetryCounterExpiredDuration),
+
+		backupTargetUnavailableHoldsLock: sync.Mutex{},
+		backupTargetUnavailableHolds:     map[string]*backupTargetUnavailableHold{},
 	}
+	bc.deletingBackoff = bc.newBackoff("longhorn-backup/deletion", DeletionMinInterval, DeletionMaxInterval)
 
 	var err error
 	if _, err = ds.BackupInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -155,6 +162,16 @@ func (bc *BackupController) enqueueBackup(obj interface{}) {
 	bc.queue.Add(key)
 }
 
+func (bc *BackupController) enqueueBackupAfter(obj interface{}, duration time.Duration) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("enqueueBackupAfter: couldn't get key for object %#v: %v", obj, err))
+		return
+	}
+
+	bc.queue.AddAfter(key, duration)
+}
+
 func (bc *BackupController) enqueueBackupForMonitor(key string) {
 	bc.queue.Add(key)
 }
@@ -357,6 +374,7 @@ func (bc *BackupController) reconcile(backupName string) (err error) {
 		bc.disableBackupMonitor(backup.Name)
 
 		bc.creationRetryCounter.DeleteEntry(backup.Name)
+		bc.deleteBackupTargetUnavailableHold(backup.Name)
 
 		if backup.Status.State == longhorn.BackupStateError || backup.Status.State == longhorn.BackupStateUnknown {
 			bc.eventRecorder.Eventf(backup, corev1.EventTypeWarning, string(backup.Status.State), "Failed backup %s has been deleted: %s", backup.Name, backup.Status.Error)
@@ -439,11 +457,15 @@ func (bc *BackupController) reconcile(backupName string) (err error) {
 
 		// v2 backing image currently doesn't support backup
 		if types.IsDataEngineV1(volume.Spec.DataEngine) {
-			if err := bc.backupBackingImage(volume); err != nil {
+			if err := bc.backupBackingImage(volume, backupTargetName); err != nil {
 				return err
 			}
 		}
 
+		if held, err := bc.checkBackupTargetUnavailableHold(backup, backupTarget, log); err != nil || held {
+			return err
+		}
+
 		monitor, err := bc.checkMonitor(backup, volume, backupTarget)
 		if err != nil {
 			if backup.Status.State == longhorn.BackupStateError {
@@ -467,8 +489,14 @@ func (bc *BackupController) reconcile(backupName string) (err error) {
 		case longhorn.BackupStateCompleted:
 			bc.disableBackupMonitor(backup.Name)
 		case longhorn.BackupStateError, longhorn.BackupStateUnknown:
-			backup.Status.LastSyncedAt = syncTime
 			bc.disableBackupMonitor(backup.Name)
+			if backup.Status.State == longhorn.BackupStateError {
+				held, err := bc.holdBackupOnBackupTargetUnavailable(backup, backupTarget, log)
+				if err != nil || held {
+					return err
+				}
+			}
+			backup.Status.LastSyncedAt = syncTime
 			return nil
 		}
 	}
@@ -730,7 +758,9 @@ func (bc *BackupController) validateBackingImageChecksum(volName, biName string)
 	return bi.Status.Checksum, nil
 }
 
-func (bc *BackupController) backupBackingImage(volume *longhorn.Volume) error {
+// backupBackingImage backs up the backing image of the volume to the backup target of the backup, which may be
+// another one than the backup target of the volume
+func (bc *BackupController) backupBackingImage(volume *longhorn.Volume, backupTargetName string) error {
 	if volume == nil {
 		return nil
 	}
@@ -746,7 +776,6 @@ func (bc *BackupController) backupBackingImage(volume *longhorn.Volume) error {
 		return errors.Wrapf(err, "failed to get backing image %v", biName)
 	}
 
-	backupTargetName := volume.Spec.BackupTargetName
 	if backupTargetName == "" {
 		backupTargetName = types.DefaultBackupTargetName
 	}
@@ -1027,6 +1056,67 @@ func (bc *BackupController) disableBackupMonitor(backupName string) {
 	monitor.Close()
 }
 
+// holdBackupOnBackupTargetUnavailable holds the failed backup in the pending state if the backup target may be
+// unreachable, according to the backup target unavailable policy
+func (bc *BackupController) holdBackupOnBackupTargetUnavailable(backup *longhorn.Backup, backupTarget *longhorn.BackupTarget, log logrus.FieldLogger) (bool, error) {
+	hold := newBackupTargetUnavailableHold(time.Now(), backup.Status.Error)
+	action, err := checkBackupTargetUnavailableHold(bc.ds, log, hold, backupTarget, false)
+	if err != nil {
+		return false, err
+	}
+	if action == backupTargetUnavailableActionFail {
+		return false, nil
+	}
+
+	log.Warnf("Holding backup failed with error %v until backup target %v is available", backup.Status.Error, backupTarget.Name)
+	bc.backupTargetUnavailableHoldsLock.Lock()
+	bc.backupTargetUnavailableHolds[backup.Name] = hold
+	bc.backupTargetUnavailableHoldsLock.Unlock()
+
+	backup.Status.State = longhorn.BackupStatePending
+	backup.Status.Error = ""
+	backup.Status.Messages[MessageTypeReconcileInfo] = fmt.Sprintf(WaitForBackupTargetAvailableMessage, backupTarget.Name, hold.err)
+	bc.enqueueBackupAfter(backup, backupTargetUnavailableSyncInterval)
+	return true, nil
+}
+
+// checkBackupTargetUnavailableHold returns true if the backup is still held. The backup is resumed once the backup
+// target is available again, or fails with its original error once the hold is over.
+func (bc *BackupController) checkBackupTargetUnavailableHold(backup *longhorn.Backup, backupTarget *longhorn.BackupTarget, log logrus.FieldLogger) (bool, error) {
+	bc.backupTargetUnavailableHoldsLock.Lock()
+	hold := bc.backupTargetUnavailableHolds[backup.Name]
+	bc.backupTargetUnavailableHoldsLock.Unlock()
+	if hold == nil {
+		return false, nil
+	}
+
+	action, err := checkBackupTargetUnavailableHold(bc.ds, log, hold, backupTarget, false)
+	if err != nil {
+		return false, err
+	}
+	switch action {
+	case backupTargetUnavailableActionHold:
+		bc.enqueueBackupAfter(backup, backupTargetUnavailableSyncInterval)
+		return true, nil
+	case backupTargetUnavailableActionRetry:
+		log.Infof("Resuming backup since backup target %v is available", backupTarget.Name)
+		delete(backup.Status.Messages, MessageTypeReconcileInfo)
+	default:
+		log.Warnf("Failing backup held since backup target %v is still unavailable or the failure is not caused by it", backupTarget.Name)
+		backup.Status.State = longhorn.BackupStateError
+		backup.Status.Error = hold.err
+		backup.Status.LastSyncedAt = metav1.Time{Time: time.Now().UTC()}
+	}
+	bc.deleteBackupTargetUnavailableHold(backup.Name)
+	return action != backupTargetUnavailableActionRetry, nil
+}
+
+func (bc *BackupController) deleteBackupTargetUnavailableHold(backupName string) {
+	bc.backupTargetUnavailableHoldsLock.Lock()
+	defer bc.backupTargetUnavailableHoldsLock.Unlock()
+	delete(bc.backupTargetUnavailableHolds, backupName)
+}
+
 func (bc *BackupController) syncBackupStatusWithSnapshotCreationTimeAndVolumeSize(volume *longhorn.Volume, backup *longhorn.Backup) {
 	backup.Status.VolumeSize = strconv.FormatInt(volume.Spec.Size, 10)
 	engineCliClient, err := bc.getEngineBinaryClient(volume.Name)
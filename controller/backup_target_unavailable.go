@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	// backupTargetUnavailableSyncInterval is the minimal interval between the backup target syncs requested while
+	// a backup or a restore is held, as well as the interval between the checks of the held operations
+	backupTargetUnavailableSyncInterval = 30 * time.Second
+)
+
+type backupTargetUnavailableAction string
+
+const (
+	// backupTargetUnavailableActionFail lets the failure of the operation be handled as usual
+	backupTargetUnavailableActionFail = backupTargetUnavailableAction("fail")
+	// backupTargetUnavailableActionHold holds the failed operation until the backup target is reachable again
+	backupTargetUnavailableActionHold = backupTargetUnavailableAction("hold")
+	// backupTargetUnavailableActionRetry retries the failed operation since the backup target is reachable again
+	backupTargetUnavailableActionRetry = backupTargetUnavailableAction("retry")
+)
+
+// backupTargetUnavailableHold tracks a backup or a restore which failed while its backup target may be unreachable.
+// A failure cannot be attributed to the backup target by its error, so the operation is held until the backup target
+// controller syncs the backup target again: the operation is retried if the backup target was found unreachable in
+// the meantime, otherwise the failure is a genuine one.
+type backupTargetUnavailableHold struct {
+	// since is the time of the first failure of the operation
+	since time.Time
+	// unavailable is set once the backup target is found unreachable after the failure
+	unavailable bool
+	// err is the error of the first failure of the operation
+	err string
+}
+
+func newBackupTargetUnavailableHold(now time.Time, err string) *backupTargetUnavailableHold {
+	return &backupTargetUnavailableHold{
+		since: now,
+		err:   err,
+	}
+}
+
+// evaluate returns the action for the held operation according to the backup target unavailable policy. The restore
+// pending policy only holds the restores, including the ones of the DR volumes.
+func (h *backupTargetUnavailableHold) evaluate(policy types.BackupTargetUnavailablePolicy, timeout time.Duration,
+	backupTarget *longhorn.BackupTarget, isRestore bool, now time.Time) backupTargetUnavailableAction {
+	switch policy {
+	case types.BackupTargetUnavailablePolicyPauseAndResume:
+		if now.Sub(h.since) > timeout {
+			return backupTargetUnavailableActionFail
+		}
+	case types.BackupTargetUnavailablePolicyRestorePending:
+		if !isRestore {
+			return backupTargetUnavailableActionFail
+		}
+	default:
+		return backupTargetUnavailableActionFail
+	}
+
+	if backupTarget == nil {
+		return backupTargetUnavailableActionFail
+	}
+	if !isBackupTargetAvailable(backupTarget) {
+		h.unavailable = true
+		return backupTargetUnavailableActionHold
+	}
+	// Wait for the backup target to be synced since the failure
+	if backupTarget.Status.LastSyncedAt.Time.Before(h.since) {
+		return backupTargetUnavailableActionHold
+	}
+	if h.unavailable {
+		return backupTargetUnavailableActionRetry
+	}
+	return backupTargetUnavailableActionFail
+}
+
+// checkBackupTargetUnavailableHold evaluates the held operation with the current settings, and requests the backup
+// target controller to sync the backup target while the operation is held.
+func checkBackupTargetUnavailableHold(ds *datastore.DataStore, log logrus.FieldLogger, hold *backupTargetUnavailableHold,
+	backupTarget *longhorn.BackupTarget, isRestore bool) (backupTargetUnavailableAction, error) {
+	policy, err := ds.GetSettingValueExisted(types.SettingNameBackupTargetUnavailablePolicy)
+	if err != nil {
+		return backupTargetUnavailableActionFail, err
+	}
+	timeout, err := ds.GetSettingAsInt(types.SettingNameBackupTargetUnavailableTimeout)
+	if err != nil {
+		return backupTargetUnavailableActionFail, err
+	}
+
+	now := time.Now()
+	action := hold.evaluate(types.BackupTargetUnavailablePolicy(policy), time.Duration(timeout)*time.Minute, backupTarget, isRestore, now)
+	if action == backupTargetUnavailableActionHold {
+		if err := requestBackupTargetSync(ds, backupTarget.Name, now); err != nil {
+			log.WithError(err).Warnf("Failed to request the sync of backup target %v", backupTarget.Name)
+		}
+	}
+	return action, nil
+}
+
+func requestBackupTargetSync(ds *datastore.DataStore, backupTargetName string, now time.Time) error {
+	backupTarget, err := ds.GetBackupTarget(backupTargetName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get backup target %v", backupTargetName)
+	}
+	if backupTarget.Spec.SyncRequestedAt.Time.After(now.Add(-backupTargetUnavailableSyncInterval)) {
+		return nil
+	}
+	backupTarget.Spec.SyncRequestedAt = metav1.Time{Time: now.UTC()}
+	if _, err := ds.UpdateBackupTarget(backupTarget); err != nil && !apierrors.IsConflict(errors.Cause(err)) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestBackupTargetUnavailableHold(c *C) {
+	now := time.Now()
+	timeout := 30 * time.Minute
+	backupTarget := &longhorn.BackupTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: types.DefaultBackupTargetName,
+		},
+		Spec: longhorn.BackupTargetSpec{
+			BackupTargetURL: "s3://backupbucket@us-east-1/backupstore",
+		},
+		Status: longhorn.BackupTargetStatus{
+			Available:    true,
+			LastSyncedAt: metav1.Time{Time: now.Add(-time.Minute)},
+		},
+	}
+
+	// The failure is handled as usual with the fail fast policy
+	hold := newBackupTargetUnavailableHold(now, "failed")
+	c.Assert(hold.evaluate(types.BackupTargetUnavailablePolicyFailFast, timeout, backupTarget, true, now),
+		Equals, backupTargetUnavailableActionFail)
+
+	// The restore pending policy only holds the restores
+	hold = newBackupTargetUnavailableHold(now, "failed")
+	c.Assert(hold.evaluate(types.BackupTargetUnavailablePolicyRestorePending, timeout, backupTarget, false, now),
+		Equals, backupTargetUnavailableActionFail)
+	c.Assert(hold.evaluate(types.BackupTargetUnavailablePolicyRestorePending, timeout, backupTarget, true, now),
+		Equals, backupTargetUnavailableActionHold)
+
+	// The operation is held until the backup target is synced since the failure, then fails if the backup target
+	// was not unavailable
+	hold = newBackupTargetUnavailableHold(now, "failed")
+	c.Assert(hold.evaluate(types.BackupTargetUnavailablePolicyPauseAndResume, timeout, backupTarget, false, now),
+		Equals, backupTargetUnavailableActionHold)
+	backupTarget.Status.LastSyncedAt = metav1.Time{Time: now.Add(time.Minute)}
+	c.Assert(hold.evaluate(types.BackupTargetUnavailablePolicyPauseAndResume, timeout, backupTarget, false, now.Add(time.Minute)),
+		Equals, backupTargetUnavailableActionFail)
+
+	// The operation is retried once the unavailable backup target is available again
+	hold = newBackupTargetUnavailableHold(now, "failed")
+	backupTarget.Status.Available = false
+	c.Assert(hold.evaluate(types.BackupTargetUnavailablePolicyPauseAndResume, timeout, backupTarget, false, now),
+		Equals, backupTargetUnavailableActionHold)
+	backupTarget.Status.Available = true
+	backupTarget.Status.LastSyncedAt = metav1.Time{Time: now.Add(2 * time.Minute)}
+	c.Assert(hold.evaluate(types.BackupTargetUnavailablePolicyPauseAndResume, timeout, backupTarget, false, now.Add(2*time.Minute)),
+		Equals, backupTargetUnavailableActionRetry)
+
+	// The operation fails once the backup target is unavailable longer than the timeout
+	hold = newBackupTargetUnavailableHold(now, "failed")
+	backupTarget.Status.Available = false
+	c.Assert(hold.evaluate(types.BackupTargetUnavailablePolicyPauseAndResume, timeout, backupTarget, false, now.Add(timeout/2)),
+		Equals, backupTargetUnavailableActionHold)
+	c.Assert(hold.evaluate(types.BackupTargetUnavailablePolicyPauseAndResume, timeout, backupTarget, false, now.Add(2*timeout)),
+		Equals, backupTargetUnavailableActionFail)
+	c.Assert(hold.evaluate(types.BackupTargetUnavailablePolicyRestorePending, timeout, backupTarget, true, now.Add(2*timeout)),
+		Equals, backupTargetUnavailableActionHold)
+}
@@ -1,9 +1,13 @@
 package controller
 
 import (
+	"sync"
+	"time"
+
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 )
 
 var (
@@ -19,12 +23,23 @@ type baseController struct {
 	name   string
 	logger *logrus.Entry
 	queue  workqueue.TypedRateLimitingInterface[any]
+
+	// clock is the source of the current time of the controller, which is replaced by a virtual clock in simulations
+	clock clock.Clock
+
+	// rateLimiter and backoffs are the retry backoffs of the controller listed by the backoff registry
+	rateLimiter *trackedRateLimiter
+	backoffLock sync.RWMutex
+	backoffs    map[*controllerBackoff]struct{}
 }
 
 func newBaseController(name string, logger logrus.FieldLogger) *baseController {
 	nameConfig := workqueue.TypedRateLimitingQueueConfig[any]{Name: name}
-	return newBaseControllerWithQueue(name, logger,
-		workqueue.NewTypedRateLimitingQueueWithConfig[any](EnhancedDefaultControllerRateLimiter(), nameConfig))
+	rateLimiter := newTrackedRateLimiter(name, EnhancedDefaultControllerRateLimiter())
+	c := newBaseControllerWithQueue(name, logger,
+		workqueue.NewTypedRateLimitingQueueWithConfig[any](rateLimiter, nameConfig))
+	c.rateLimiter = rateLimiter
+	return c
 }
 
 func newBaseControllerWithQueue(name string, logger logrus.FieldLogger,
@@ -33,7 +48,21 @@ func newBaseControllerWithQueue(name string, logger logrus.FieldLogger,
 		name:   name,
 		logger: logger.WithField("controller", name),
 		queue:  queue,
+
+		clock: clock.RealClock{},
+
+		backoffs: map[*controllerBackoff]struct{}{},
 	}
 
 	return c
 }
+
+// SetClock replaces the clock of the controller, e.g. with a fake clock to control the time in simulations
+func (c *baseController) SetClock(clock clock.Clock) {
+	c.clock = clock
+}
+
+// now returns the current time of the controller clock in the format of the timestamps in the object status
+func (c *baseController) now() string {
+	return c.clock.Now().UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,417 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/longhorn/longhorn-manager/constant"
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/scheduler"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	// The progress of the moves is checked periodically, since the replicas and the disks aren't watched
+	capacityRebalanceCheckInterval = 1 * time.Minute
+
+	// The planned moves beyond the limit are neither reported nor executed in the round
+	capacityRebalanceMaxPlannedMoves = 50
+)
+
+// CapacityRebalanceController lowers the maximum scheduled storage utilization of the disks by moving the replicas of
+// the attached healthy volumes from the most utilized disks to the least utilized ones. The planned moves are reported
+// in the CapacityRebalancePlanned condition of the capacity-rebalance setting. Once enabled, the moves are started
+// round by round at the capacity rebalance interval, and are limited by the concurrent capacity rebalance per cluster
+// limit. A move is recorded in the annotation of the volume, and executed by the volume controller.
+type CapacityRebalanceController struct {
+	*baseController
+
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the controller
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds         *datastore.DataStore
+	cacheSyncs []cache.InformerSynced
+
+	scheduler *scheduler.ReplicaScheduler
+
+	// The time the last round of the moves were started, which is reset by the restart of the manager
+	lastRoundAt time.Time
+}
+
+func NewCapacityRebalanceController(
+	logger logrus.FieldLogger,
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	kubeClient clientset.Interface,
+	controllerID string,
+	namespace string,
+) (*CapacityRebalanceController, error) {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	crc := &CapacityRebalanceController{
+		baseController: newBaseController("longhorn-capacity-rebalance", logger),
+
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		ds: ds,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-capacity-rebalance-controller"}),
+
+		scheduler: scheduler.NewReplicaScheduler(ds),
+	}
+
+	var err error
+	if _, err = ds.SettingInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: isSettingCapacityRebalance,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    crc.enqueueCapacityRebalance,
+			UpdateFunc: func(old, cur interface{}) { crc.enqueueCapacityRebalance(cur) },
+		},
+	}); err != nil {
+		return nil, err
+	}
+	crc.cacheSyncs = append(crc.cacheSyncs, ds.SettingInformer.HasSynced)
+
+	return crc, nil
+}
+
+func isSettingCapacityRebalance(obj interface{}) bool {
+	setting, ok := obj.(*longhorn.Setting)
+	if !ok {
+		return false
+	}
+	switch types.SettingName(setting.Name) {
+	case types.SettingNameCapacityRebalance,
+		types.SettingNameCapacityRebalanceInterval,
+		types.SettingNameCapacityRebalanceUtilizationGap,
+		types.SettingNameConcurrentCapacityRebalancePerClusterLimit:
+		return true
+	}
+	return false
+}
+
+// enqueueCapacityRebalance enqueues the single key of the controller, since the plan covers all the volumes and is
+// written to the same setting.
+func (crc *CapacityRebalanceController) enqueueCapacityRebalance(obj interface{}) {
+	crc.queue.Add(crc.namespace + "/" + string(types.SettingNameCapacityRebalance))
+}
+
+func (crc *CapacityRebalanceController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer crc.queue.ShutDown()
+
+	crc.logger.Info("Starting Longhorn capacity rebalance controller")
+	defer crc.logger.Info("Shut down Longhorn capacity rebalance controller")
+
+	if !cache.WaitForNamedCacheSync(crc.name, stopCh, crc.cacheSyncs...) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(crc.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (crc *CapacityRebalanceController) worker() {
+	for crc.processNextWorkItem() {
+	}
+}
+
+func (crc *CapacityRebalanceController) processNextWorkItem() bool {
+	key, quit := crc.queue.Get()
+	if quit {
+		return false
+	}
+	defer crc.queue.Done(key)
+	err := crc.syncHandler(key.(string))
+	crc.handleErr(err, key)
+	return true
+}
+
+func (crc *CapacityRebalanceController) handleErr(err error, key interface{}) {
+	if err == nil {
+		crc.queue.Forget(key)
+		return
+	}
+
+	log := crc.logger.WithField("Setting", key)
+	handleReconcileErrorLogging(log, err, "Failed to rebalance Longhorn disk capacity")
+	crc.queue.AddRateLimited(key)
+}
+
+func (crc *CapacityRebalanceController) syncHandler(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "%v: failed to rebalance disk capacity", crc.name)
+	}()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != crc.namespace {
+		return nil
+	}
+
+	crc.queue.AddAfter(key, capacityRebalanceCheckInterval)
+
+	// Only one manager plans the moves, otherwise the same disks can be rebalanced twice
+	responsibleNodeID, err := getResponsibleNodeID(crc.ds)
+	if err != nil {
+		return err
+	}
+	if responsibleNodeID != crc.controllerID {
+		return nil
+	}
+
+	return crc.reconcile(name)
+}
+
+func (crc *CapacityRebalanceController) reconcile(name string) error {
+	setting, err := crc.ds.GetSettingExact(types.SettingName(name))
+	if err != nil {
+		if datastore.ErrorIsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	existingSetting := setting.DeepCopy()
+	mode := types.CapacityRebalanceMode(setting.Value)
+
+	volumes, err := crc.ds.ListVolumesRO()
+	if err != nil {
+		return err
+	}
+	replicas, err := crc.ds.ListReplicasRO()
+	if err != nil {
+		return err
+	}
+	volumeReplicas := map[string][]*longhorn.Replica{}
+	for _, r := range replicas {
+		volumeReplicas[r.Spec.VolumeName] = append(volumeReplicas[r.Spec.VolumeName], r)
+	}
+
+	movingCount := 0
+	candidates := map[string]*longhorn.Volume{}
+	for _, v := range volumes {
+		moving, err := crc.syncCapacityRebalanceMove(v, volumeReplicas[v.Name])
+		if err != nil {
+			return err
+		}
+		if moving {
+			movingCount++
+			continue
+		}
+		if isCapacityRebalanceCandidate(v, volumeReplicas[v.Name]) {
+			candidates[v.Name] = v
+		}
+	}
+
+	if mode == types.CapacityRebalanceModeDisabled {
+		setting.Status.Conditions = types.SetCondition(setting.Status.Conditions, longhorn.SettingConditionTypeCapacityRebalancePlanned,
+			longhorn.ConditionStatusFalse, longhorn.SettingConditionReasonCapacityRebalanceDisabled, "")
+		return crc.updateSettingStatus(existingSetting, setting)
+	}
+
+	utilizationGap, err := crc.ds.GetSettingAsInt(types.SettingNameCapacityRebalanceUtilizationGap)
+	if err != nil {
+		return err
+	}
+	moves, err := crc.scheduler.PlanCapacityRebalance(candidates, capacityRebalanceMaxPlannedMoves, utilizationGap)
+	if err != nil {
+		return errors.Wrap(err, "failed to plan capacity rebalance")
+	}
+
+	if len(moves) == 0 {
+		setting.Status.Conditions = types.SetCondition(setting.Status.Conditions, longhorn.SettingConditionTypeCapacityRebalancePlanned,
+			longhorn.ConditionStatusFalse, longhorn.SettingConditionReasonCapacityRebalanceBalanced, "")
+		return crc.updateSettingStatus(existingSetting, setting)
+	}
+
+	reason := longhorn.SettingConditionReasonCapacityRebalanceDryRun
+	if mode == types.CapacityRebalanceModeEnabled {
+		reason = longhorn.SettingConditionReasonCapacityRebalanceMoving
+	}
+	setting.Status.Conditions = types.SetCondition(setting.Status.Conditions, longhorn.SettingConditionTypeCapacityRebalancePlanned,
+		longhorn.ConditionStatusTrue, reason, getCapacityRebalancePlanMessage(moves))
+	if err := crc.updateSettingStatus(existingSetting, setting); err != nil {
+		return err
+	}
+
+	if mode != types.CapacityRebalanceModeEnabled {
+		return nil
+	}
+
+	interval, err := crc.ds.GetSettingAsInt(types.SettingNameCapacityRebalanceInterval)
+	if err != nil {
+		return err
+	}
+	if !crc.lastRoundAt.IsZero() && crc.clock.Now().Before(crc.lastRoundAt.Add(time.Duration(interval)*time.Minute)) {
+		return nil
+	}
+	limit, err := crc.ds.GetSettingAsInt(types.SettingNameConcurrentCapacityRebalancePerClusterLimit)
+	if err != nil {
+		return err
+	}
+	if int64(movingCount) >= limit {
+		return nil
+	}
+
+	for i := 0; i < len(moves) && int64(movingCount+i) < limit; i++ {
+		if err := crc.startCapacityRebalanceMove(moves[i]); err != nil {
+			return err
+		}
+	}
+	crc.lastRoundAt = crc.clock.Now()
+	return nil
+}
+
+func (crc *CapacityRebalanceController) updateSettingStatus(existingSetting, setting *longhorn.Setting) error {
+	if reflect.DeepEqual(existingSetting.Status, setting.Status) {
+		return nil
+	}
+	_, err := crc.ds.UpdateSettingStatus(setting)
+	return err
+}
+
+// syncCapacityRebalanceMove returns true if the volume is still being moved. The move is cleaned up from the volume
+// once the moved replica is removed, or the move cannot continue.
+func (crc *CapacityRebalanceController) syncCapacityRebalanceMove(v *longhorn.Volume, replicas []*longhorn.Replica) (bool, error) {
+	log := getLoggerForVolume(crc.logger, v)
+
+	move, err := types.GetCapacityRebalanceMove(v)
+	if err != nil {
+		log.WithError(err).Warn("Cleaning up invalid capacity rebalance move")
+		return false, crc.cleanupCapacityRebalanceMove(v.Name)
+	}
+	if move == nil {
+		return false, nil
+	}
+
+	var movingReplica *longhorn.Replica
+	for _, r := range replicas {
+		if r.Name == move.ReplicaName {
+			movingReplica = r
+			break
+		}
+	}
+
+	switch {
+	case movingReplica == nil:
+		log.Infof("Moved replica %v to disk %v on node %v for capacity rebalance", move.ReplicaName, move.TargetDiskID, move.TargetNodeID)
+		crc.eventRecorder.Eventf(v, corev1.EventTypeNormal, constant.EventReasonCapacityRebalanced,
+			"moved replica %v of volume %v to disk %v on node %v for capacity rebalance", move.ReplicaName, v.Name, move.TargetDiskID, move.TargetNodeID)
+	case v.Status.State != longhorn.VolumeStateAttached || !datastore.IsAvailableHealthyReplica(movingReplica):
+		log.Infof("Canceled moving replica %v for capacity rebalance since the volume is detached or the replica is not healthy", move.ReplicaName)
+	default:
+		return true, nil
+	}
+	return false, crc.cleanupCapacityRebalanceMove(v.Name)
+}
+
+func (crc *CapacityRebalanceController) cleanupCapacityRebalanceMove(volumeName string) error {
+	v, err := crc.ds.GetVolume(volumeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	delete(v.Annotations, types.GetLonghornLabelKey(types.LonghornAnnotationCapacityRebalanceMove))
+	_, err = crc.ds.UpdateVolume(v)
+	return err
+}
+
+func (crc *CapacityRebalanceController) startCapacityRebalanceMove(move *scheduler.CapacityRebalanceMove) error {
+	v, err := crc.ds.GetVolume(move.VolumeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	value, err := json.Marshal(&types.CapacityRebalanceMove{
+		ReplicaName:  move.ReplicaName,
+		TargetNodeID: move.TargetNodeID,
+		TargetDiskID: move.TargetDiskID,
+		StartedAt:    crc.now(),
+	})
+	if err != nil {
+		return err
+	}
+	if v.Annotations == nil {
+		v.Annotations = map[string]string{}
+	}
+	v.Annotations[types.GetLonghornLabelKey(types.LonghornAnnotationCapacityRebalanceMove)] = string(value)
+	if v, err = crc.ds.UpdateVolume(v); err != nil {
+		return errors.Wrapf(err, "failed to start moving replica %v of volume %v", move.ReplicaName, move.VolumeName)
+	}
+
+	getLoggerForVolume(crc.logger, v).Infof("Moving replica %v from disk %v on node %v to disk %v on node %v for capacity rebalance",
+		move.ReplicaName, move.SourceDiskID, move.SourceNodeID, move.TargetDiskID, move.TargetNodeID)
+	crc.eventRecorder.Eventf(v, corev1.EventTypeNormal, constant.EventReasonCapacityRebalancing,
+		"moving replica %v of volume %v from disk %v on node %v to disk %v on node %v for capacity rebalance",
+		move.ReplicaName, v.Name, move.SourceDiskID, move.SourceNodeID, move.TargetDiskID, move.TargetNodeID)
+	return nil
+}
+
+// isCapacityRebalanceCandidate returns true if a replica of the volume can be moved. The volume should be attached
+// and healthy, with exactly the desired number of healthy replicas and no other replica operation in progress.
+func isCapacityRebalanceCandidate(v *longhorn.Volume, replicas []*longhorn.Replica) bool {
+	if v.DeletionTimestamp != nil || v.Status.State != longhorn.VolumeStateAttached || v.Status.Robustness != longhorn.VolumeRobustnessHealthy {
+		return false
+	}
+	if v.Spec.MigrationNodeID != "" || v.Status.RestoreRequired || isDiskTagMigrationRequested(v) {
+		return false
+	}
+	if len(replicas) != v.Spec.NumberOfReplicas {
+		return false
+	}
+	for _, r := range replicas {
+		if !datastore.IsAvailableHealthyReplica(r) || r.Spec.EvictionRequested {
+			return false
+		}
+	}
+	return true
+}
+
+// getCapacityRebalancePlanMessage describes the planned moves in the order they are executed
+func getCapacityRebalancePlanMessage(moves []*scheduler.CapacityRebalanceMove) string {
+	descriptions := []string{}
+	for _, move := range moves {
+		descriptions = append(descriptions, fmt.Sprintf("replica %v of volume %v from disk %v on node %v (%v%%) to disk %v on node %v (%v%%)",
+			move.ReplicaName, move.VolumeName, move.SourceDiskID, move.SourceNodeID, move.SourceUtilization,
+			move.TargetDiskID, move.TargetNodeID, move.TargetUtilization))
+	}
+	return fmt.Sprintf("%v replica moves planned: %v", len(moves), strings.Join(descriptions, "; "))
+}
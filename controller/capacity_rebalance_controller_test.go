@@ -0,0 +1,45 @@
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestIsCapacityRebalanceCandidate(c *C) {
+	v := &longhorn.Volume{
+		ObjectMeta: metav1.ObjectMeta{Name: TestVolumeName},
+		Spec:       longhorn.VolumeSpec{NumberOfReplicas: 2},
+		Status: longhorn.VolumeStatus{
+			State:      longhorn.VolumeStateAttached,
+			Robustness: longhorn.VolumeRobustnessHealthy,
+		},
+	}
+	newReplica := func(name string) *longhorn.Replica {
+		return &longhorn.Replica{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: longhorn.ReplicaSpec{
+				InstanceSpec: longhorn.InstanceSpec{VolumeName: TestVolumeName},
+				HealthyAt:    "2026-01-01T00:00:00Z",
+			},
+		}
+	}
+	replicas := []*longhorn.Replica{newReplica("r1"), newReplica("r2")}
+	c.Assert(isCapacityRebalanceCandidate(v, replicas), Equals, true)
+
+	// A replica is missing or being rebuilt
+	c.Assert(isCapacityRebalanceCandidate(v, replicas[:1]), Equals, false)
+	rebuilding := newReplica("r3")
+	rebuilding.Spec.HealthyAt = ""
+	c.Assert(isCapacityRebalanceCandidate(v, []*longhorn.Replica{replicas[0], rebuilding}), Equals, false)
+
+	// The volume is degraded or migrating
+	degraded := v.DeepCopy()
+	degraded.Status.Robustness = longhorn.VolumeRobustnessDegraded
+	c.Assert(isCapacityRebalanceCandidate(degraded, replicas), Equals, false)
+	migrating := v.DeepCopy()
+	migrating.Spec.MigrationNodeID = TestNode2
+	c.Assert(isCapacityRebalanceCandidate(migrating, replicas), Equals, false)
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/longhorn/longhorn-manager/util"
 	"github.com/longhorn/longhorn-manager/util/client"
 
+	wqmetrics "github.com/longhorn/longhorn-manager/metrics_collector/workqueue"
+
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
 
@@ -32,7 +34,7 @@ var (
 // StartControllers initiates all Longhorn component controllers and monitors to manage the creating, updating, and deletion of Longhorn resources
 func StartControllers(logger logrus.FieldLogger, clients *client.Clients,
 	controllerID, serviceAccount, managerImage, backingImageManagerImage, shareManagerImage,
-	kubeconfigPath, version string, proxyConnCounter util.Counter) (*WebsocketController, error) {
+	kubeconfigPath, version string, proxyConnCounter util.Counter, backoffs *BackoffRegistry) (*WebsocketController, error) {
 	namespace := clients.Namespace
 	kubeClient := clients.K8s
 	metricsClient := clients.MetricsClient
@@ -41,7 +43,7 @@ func StartControllers(logger logrus.FieldLogger, clients *client.Clients,
 	stopCh := clients.StopCh
 
 	// Longhorn controllers
-	replicaController, err := NewReplicaController(logger, ds, scheme, kubeClient, namespace, controllerID)
+	replicaController, err := NewReplicaController(logger, ds, scheme, kubeClient, namespace, controllerID, proxyConnCounter)
 	if err != nil {
 		return nil, err
 	}
@@ -145,6 +147,10 @@ func StartControllers(logger logrus.FieldLogger, clients *client.Clients,
 	if err != nil {
 		return nil, err
 	}
+	volumePassphraseRotationController, err := NewVolumePassphraseRotationController(logger, ds, scheme, kubeClient, controllerID, namespace)
+	if err != nil {
+		return nil, err
+	}
 	volumeCloneController, err := NewVolumeCloneController(logger, ds, scheme, kubeClient, controllerID, namespace)
 	if err != nil {
 		return nil, err
@@ -153,6 +159,38 @@ func StartControllers(logger logrus.FieldLogger, clients *client.Clients,
 	if err != nil {
 		return nil, err
 	}
+	volumeAutoResizeController, err := NewVolumeAutoResizeController(logger, ds, scheme, kubeClient, controllerID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	volumeBackupFreshnessController, err := NewVolumeBackupFreshnessController(logger, ds, scheme, kubeClient, controllerID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	volumeWarmPoolController, err := NewVolumeWarmPoolController(logger, ds, scheme, kubeClient, controllerID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	maintenancePolicyController, err := NewMaintenancePolicyController(logger, ds, scheme, kubeClient, controllerID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	engineImageGarbageCollectionController, err := NewEngineImageGarbageCollectionController(logger, ds, scheme, kubeClient, controllerID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	nodeDrainController, err := NewNodeDrainController(logger, ds, scheme, kubeClient, controllerID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	upgradeCompatibilityController, err := NewUpgradeCompatibilityController(logger, ds, scheme, kubeClient, controllerID, namespace)
+	if err != nil {
+		return nil, err
+	}
+	capacityRebalanceController, err := NewCapacityRebalanceController(logger, ds, scheme, kubeClient, controllerID, namespace)
+	if err != nil {
+		return nil, err
+	}
 
 	// Kubernetes controllers
 	kubernetesPVController, err := NewKubernetesPVController(logger, ds, scheme, kubeClient, controllerID)
@@ -167,6 +205,10 @@ func StartControllers(logger logrus.FieldLogger, clients *client.Clients,
 	if err != nil {
 		return nil, err
 	}
+	kubernetesStaleAttachmentController, err := NewKubernetesStaleAttachmentController(logger, ds, scheme, kubeClient, controllerID)
+	if err != nil {
+		return nil, err
+	}
 	kubernetesConfigMapController, err := NewKubernetesConfigMapController(logger, ds, scheme, kubeClient, controllerID, namespace)
 	if err != nil {
 		return nil, err
@@ -184,48 +226,103 @@ func StartControllers(logger logrus.FieldLogger, clients *client.Clients,
 		return nil, err
 	}
 
+	// The retry backoffs of the controllers are inspected through the backoff registry
+	backoffs.register(replicaController, engineController, volumeController, engineImageController, nodeController,
+		websocketController, settingController, instanceManagerController, shareManagerController,
+		backingImageController, backingImageManagerController, backingImageDataSourceController,
+		backupTargetController, backupVolumeController, backupController, backupBackingImageController,
+		recurringJobController, orphanController, snapshotController, supportBundleController,
+		systemBackupController, systemRestoreController, volumeAttachmentController, volumeRestoreController,
+		volumeRebuildingController, volumeEvictionController, volumePassphraseRotationController,
+		volumeCloneController, volumeExpansionController, volumeAutoResizeController,
+		volumeBackupFreshnessController, volumeWarmPoolController, maintenancePolicyController,
+		engineImageGarbageCollectionController, nodeDrainController, upgradeCompatibilityController,
+		capacityRebalanceController, kubernetesPVController, kubernetesNodeController, kubernetesPodController,
+		kubernetesStaleAttachmentController, kubernetesConfigMapController, kubernetesSecretController,
+		kubernetesPDBController, kubernetesEndpointController)
+
+	controllerWorkers, err := getControllerWorkers(ds)
+	if err != nil {
+		return nil, err
+	}
+
 	// Start goroutines for Longhorn controllers
-	go replicaController.Run(Workers, stopCh)
-	go engineController.Run(Workers, stopCh)
-	go volumeController.Run(Workers, stopCh)
-	go engineImageController.Run(Workers, stopCh)
-	go nodeController.Run(Workers, stopCh)
+	go replicaController.Run(controllerWorkers.get(replicaController.name), stopCh)
+	go engineController.Run(controllerWorkers.get(engineController.name), stopCh)
+	go volumeController.Run(controllerWorkers.get(volumeController.name), stopCh)
+	go engineImageController.Run(controllerWorkers.get(engineImageController.name), stopCh)
+	go nodeController.Run(controllerWorkers.get(nodeController.name), stopCh)
 	go websocketController.Run(stopCh)
 	go settingController.Run(stopCh)
-	go instanceManagerController.Run(Workers, stopCh)
-	go shareManagerController.Run(Workers, stopCh)
-	go backingImageController.Run(Workers, stopCh)
-	go backingImageManagerController.Run(Workers, stopCh)
-	go backingImageDataSourceController.Run(Workers, stopCh)
-	go backupTargetController.Run(Workers, stopCh)
-	go backupVolumeController.Run(Workers, stopCh)
-	go backupController.Run(Workers, stopCh)
-	go backupBackingImageController.Run(Workers, stopCh)
-	go recurringJobController.Run(Workers, stopCh)
-	go orphanController.Run(Workers, stopCh)
-	go snapshotController.Run(Workers, stopCh)
-	go supportBundleController.Run(Workers, stopCh)
-	go systemBackupController.Run(Workers, stopCh)
-	go systemRestoreController.Run(Workers, stopCh)
-	go volumeAttachmentController.Run(Workers, stopCh)
-	go volumeRestoreController.Run(Workers, stopCh)
-	go volumeRebuildingController.Run(Workers, stopCh)
-	go volumeEvictionController.Run(Workers, stopCh)
-	go volumeCloneController.Run(Workers, stopCh)
-	go volumeExpansionController.Run(Workers, stopCh)
+	go instanceManagerController.Run(controllerWorkers.get(instanceManagerController.name), stopCh)
+	go shareManagerController.Run(controllerWorkers.get(shareManagerController.name), stopCh)
+	go backingImageController.Run(controllerWorkers.get(backingImageController.name), stopCh)
+	go backingImageManagerController.Run(controllerWorkers.get(backingImageManagerController.name), stopCh)
+	go backingImageDataSourceController.Run(controllerWorkers.get(backingImageDataSourceController.name), stopCh)
+	go backupTargetController.Run(controllerWorkers.get(backupTargetController.name), stopCh)
+	go backupVolumeController.Run(controllerWorkers.get(backupVolumeController.name), stopCh)
+	go backupController.Run(controllerWorkers.get(backupController.name), stopCh)
+	go backupBackingImageController.Run(controllerWorkers.get(backupBackingImageController.name), stopCh)
+	go recurringJobController.Run(controllerWorkers.get(recurringJobController.name), stopCh)
+	go orphanController.Run(controllerWorkers.get(orphanController.name), stopCh)
+	go snapshotController.Run(controllerWorkers.get(snapshotController.name), stopCh)
+	go supportBundleController.Run(controllerWorkers.get(supportBundleController.name), stopCh)
+	go systemBackupController.Run(controllerWorkers.get(systemBackupController.name), stopCh)
+	go systemRestoreController.Run(controllerWorkers.get(systemRestoreController.name), stopCh)
+	go volumeAttachmentController.Run(controllerWorkers.get(volumeAttachmentController.name), stopCh)
+	go volumeRestoreController.Run(controllerWorkers.get(volumeRestoreController.name), stopCh)
+	go volumeRebuildingController.Run(controllerWorkers.get(volumeRebuildingController.name), stopCh)
+	go volumeEvictionController.Run(controllerWorkers.get(volumeEvictionController.name), stopCh)
+	go volumePassphraseRotationController.Run(controllerWorkers.get(volumePassphraseRotationController.name), stopCh)
+	go volumeCloneController.Run(controllerWorkers.get(volumeCloneController.name), stopCh)
+	go volumeExpansionController.Run(controllerWorkers.get(volumeExpansionController.name), stopCh)
+	go volumeAutoResizeController.Run(controllerWorkers.get(volumeAutoResizeController.name), stopCh)
+	go volumeBackupFreshnessController.Run(controllerWorkers.get(volumeBackupFreshnessController.name), stopCh)
+	go volumeWarmPoolController.Run(controllerWorkers.get(volumeWarmPoolController.name), stopCh)
+	go maintenancePolicyController.Run(controllerWorkers.get(maintenancePolicyController.name), stopCh)
+	go engineImageGarbageCollectionController.Run(controllerWorkers.get(engineImageGarbageCollectionController.name), stopCh)
+	go nodeDrainController.Run(controllerWorkers.get(nodeDrainController.name), stopCh)
+	go upgradeCompatibilityController.Run(controllerWorkers.get(upgradeCompatibilityController.name), stopCh)
+	go capacityRebalanceController.Run(controllerWorkers.get(capacityRebalanceController.name), stopCh)
 
 	// Start goroutines for Kubernetes controllers
-	go kubernetesPVController.Run(Workers, stopCh)
-	go kubernetesNodeController.Run(Workers, stopCh)
-	go kubernetesPodController.Run(Workers, stopCh)
-	go kubernetesConfigMapController.Run(Workers, stopCh)
-	go kubernetesSecretController.Run(Workers, stopCh)
-	go kubernetesPDBController.Run(Workers, stopCh)
-	go kubernetesEndpointController.Run(Workers, stopCh)
+	go kubernetesPVController.Run(controllerWorkers.get(kubernetesPVController.name), stopCh)
+	go kubernetesNodeController.Run(controllerWorkers.get(kubernetesNodeController.name), stopCh)
+	go kubernetesPodController.Run(controllerWorkers.get(kubernetesPodController.name), stopCh)
+	go kubernetesStaleAttachmentController.Run(controllerWorkers.get(kubernetesStaleAttachmentController.name), stopCh)
+	go kubernetesConfigMapController.Run(controllerWorkers.get(kubernetesConfigMapController.name), stopCh)
+	go kubernetesSecretController.Run(controllerWorkers.get(kubernetesSecretController.name), stopCh)
+	go kubernetesPDBController.Run(controllerWorkers.get(kubernetesPDBController.name), stopCh)
+	go kubernetesEndpointController.Run(controllerWorkers.get(kubernetesEndpointController.name), stopCh)
 
 	return websocketController, nil
 }
 
+// controllerWorkers is the number of the workers of the controllers configured by the controller workers setting
+type controllerWorkers map[string]int
+
+func getControllerWorkers(ds *datastore.DataStore) (controllerWorkers, error) {
+	setting, err := ds.GetSettingWithAutoFillingRO(types.SettingNameControllerWorkers)
+	if err != nil {
+		return nil, err
+	}
+	workers, err := types.UnmarshalControllerWorkers(setting.Value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %v setting", types.SettingNameControllerWorkers)
+	}
+	return workers, nil
+}
+
+// get returns the number of the workers of the controller, and records it in the workqueue metrics
+func (cw controllerWorkers) get(name string) int {
+	workers, ok := cw[name]
+	if !ok {
+		workers = Workers
+	}
+	wqmetrics.SetWorkers(name, workers)
+	return workers
+}
+
 func ParseResourceRequirement(val string) (*corev1.ResourceRequirements, error) {
 	quantity, err := resource.ParseQuantity(val)
 	if err != nil {
@@ -241,7 +338,8 @@ func ParseResourceRequirement(val string) (*corev1.ResourceRequirements, error)
 	}, nil
 }
 
-// GetInstanceManagerCPURequirement returns the instance manager CPU requirement
+// GetInstanceManagerCPURequirement returns the instance manager CPU requirement, including the part scaled with the
+// number of the instances running in the instance manager
 func GetInstanceManagerCPURequirement(ds *datastore.DataStore, imName string) (*corev1.ResourceRequirements, error) {
 	im, err := ds.GetInstanceManager(imName)
 	if err != nil {
@@ -288,9 +386,65 @@ func GetInstanceManagerCPURequirement(ds *datastore.DataStore, imName string) (*
 		return nil, fmt.Errorf("unknown data engine %v", im.Spec.DataEngine)
 	}
 
+	cpuPerInstance, err := ds.GetSettingAsInt(types.SettingNameInstanceManagerCPUPerInstance)
+	if err != nil {
+		return nil, err
+	}
+	cpuRequest += int(cpuPerInstance) * getInstanceManagerRunningInstanceCount(im)
+
 	return ParseResourceRequirement(fmt.Sprintf("%dm", cpuRequest))
 }
 
+// v2DataEngineDefaultInstanceManagerMemoryRequest is the memory request of the v2 data engine instance manager pods in
+// MiB, if it's not set on the node
+const v2DataEngineDefaultInstanceManagerMemoryRequest = 128
+
+// GetInstanceManagerMemoryRequirement returns the instance manager memory requirement, including the part scaled with
+// the number of the instances running in the instance manager
+func GetInstanceManagerMemoryRequirement(ds *datastore.DataStore, imName string) (*corev1.ResourceRequirements, error) {
+	im, err := ds.GetInstanceManagerRO(imName)
+	if err != nil {
+		return nil, err
+	}
+
+	lhNode, err := ds.GetNodeRO(im.Spec.NodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	memoryPerInstance, err := ds.GetSettingAsInt(types.SettingNameInstanceManagerMemoryPerInstance)
+	if err != nil {
+		return nil, err
+	}
+	memoryRequest := int64(lhNode.Spec.InstanceManagerMemoryRequest)
+	if memoryRequest == 0 && types.IsDataEngineV2(im.Spec.DataEngine) {
+		memoryRequest = v2DataEngineDefaultInstanceManagerMemoryRequest
+	}
+	memoryRequest += memoryPerInstance * int64(getInstanceManagerRunningInstanceCount(im))
+	if memoryRequest == 0 {
+		return nil, nil
+	}
+
+	return &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceMemory: *resource.NewQuantity(memoryRequest*util.MiB, resource.BinarySI),
+		},
+	}, nil
+}
+
+// getInstanceManagerRunningInstanceCount returns the number of the engine and replica processes running or starting in
+// the instance manager
+func getInstanceManagerRunningInstanceCount(im *longhorn.InstanceManager) int {
+	count := 0
+	// nolint:all
+	for _, instance := range types.ConsolidateInstances(im.Status.InstanceEngines, im.Status.InstanceReplicas, im.Status.Instances) {
+		if instance.Status.State == longhorn.InstanceStateRunning || instance.Status.State == longhorn.InstanceStateStarting {
+			count++
+		}
+	}
+	return count
+}
+
 func isControllerResponsibleFor(controllerID string, ds *datastore.DataStore, name, preferredOwnerID, currentOwnerID string) bool {
 	// we use this approach so that if there is an issue with the data store
 	// we don't accidentally transfer ownership
@@ -329,3 +483,16 @@ func IsSameGuaranteedCPURequirement(a, b *corev1.ResourceRequirements) bool {
 	}
 	return (&aQ).Cmp(bQ) == 0
 }
+
+// IsSameMemoryRequirement returns true if the memory request of the resource requirement a is equal to the one of the
+// resource requirement b
+func IsSameMemoryRequirement(a, b *corev1.ResourceRequirements) bool {
+	var aQ, bQ resource.Quantity
+	if a != nil && a.Requests != nil {
+		aQ = a.Requests[corev1.ResourceMemory]
+	}
+	if b != nil && b.Requests != nil {
+		bQ = b.Requests[corev1.ResourceMemory]
+	}
+	return (&aQ).Cmp(bQ) == 0
+}
@@ -13,6 +13,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/kubernetes/pkg/controller"
 
+	clocktesting "k8s.io/utils/clock/testing"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -600,6 +602,11 @@ func getTestNow() string {
 	return TestTimeNow
 }
 
+func getTestClock() *clocktesting.FakeClock {
+	now, _ := time.Parse(time.RFC3339, TestTimeNow)
+	return clocktesting.NewFakeClock(now)
+}
+
 func randomIP() string {
 	b := []string{}
 	for i := 0; i < 4; i++ {
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,12 +17,13 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/kubernetes/pkg/controller"
 
 	corev1 "k8s.io/api/core/v1"
@@ -70,6 +72,12 @@ var (
 	sizeUpdateLimit = 30 * time.Second
 	// number of consecutive actual size updates allowed during bursts
 	sizeUpdateBurst = 3
+
+	// amount of time between the retrievals of the salvage info from the replicas
+	replicaSalvageInfoRefreshInterval = 1 * time.Minute
+
+	// maximum amount of time waiting for the snapshot checksums before the delta rebuild of a reused replica
+	deltaRebuildHashTimeout = 2 * time.Minute
 )
 
 const (
@@ -91,7 +99,7 @@ type EngineController struct {
 
 	cacheSyncs []cache.InformerSynced
 
-	backoff *flowcontrol.Backoff
+	backoff *controllerBackoff
 
 	instanceHandler *InstanceHandler
 
@@ -103,6 +111,10 @@ type EngineController struct {
 
 	restoringCounter      util.Counter
 	restoringCounterMutex *sync.Mutex
+
+	// the replica rebuilds supervised by this controller, by engine and replica names
+	supervisedRebuilds     map[string]struct{}
+	supervisedRebuildMutex *sync.Mutex
 }
 
 type EngineMonitor struct {
@@ -115,11 +127,13 @@ type EngineMonitor struct {
 	Name             string
 	engines          engineapi.EngineClientCollection
 	stopCh           chan struct{}
-	expansionBackoff *flowcontrol.Backoff
-	restoreBackoff   *flowcontrol.Backoff
+	expansionBackoff *controllerBackoff
+	restoreBackoff   *controllerBackoff
 
 	expansionUpdateTime time.Time
 
+	replicaSalvageInfoUpdateTime time.Time
+
 	controllerID string
 	// used to notify the controller that monitoring has stopped
 	monitorVoluntaryStopCh chan struct{}
@@ -130,6 +144,9 @@ type EngineMonitor struct {
 	restoringCounterAcquired bool
 	restoringCounterMutex    *sync.Mutex
 
+	// restoreHold tracks the restore failed while the backup target may be unreachable
+	restoreHold *backupTargetUnavailableHold
+
 	sizeUpdateLimiter *rate.Limiter
 }
 
@@ -157,8 +174,6 @@ func NewEngineController(
 		kubeClient:    kubeClient,
 		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-engine-controller"}),
 
-		backoff: flowcontrol.NewBackOff(time.Second*10, time.Minute*5),
-
 		engines:            engines,
 		engineMonitorMutex: &sync.RWMutex{},
 		engineMonitorMap:   map[string]chan struct{}{},
@@ -166,7 +181,12 @@ func NewEngineController(
 		proxyConnCounter:      proxyConnCounter,
 		restoringCounter:      util.NewAtomicCounter(),
 		restoringCounterMutex: &sync.Mutex{},
+
+		supervisedRebuilds:     map[string]struct{}{},
+		supervisedRebuildMutex: &sync.Mutex{},
 	}
+	ec.backoff = ec.newBackoff("longhorn-engine/replica-rebuild", time.Second*10, time.Minute*5)
+
 	ec.instanceHandler = NewInstanceHandler(ds, ec, ec.eventRecorder)
 
 	var err error
@@ -311,6 +331,9 @@ func (ec *EngineController) syncEngine(key string) (err error) {
 		if err := ec.DeleteInstance(engine); err != nil {
 			return errors.Wrapf(err, "failed to clean up the related engine instance before deleting engine %v", engine.Name)
 		}
+		if err := engineapi.RemoveWebIdentityTokenFile(engine.Name); err != nil {
+			log.WithError(err).Warn("Failed to clean up the web identity token file of the restore credential")
+		}
 		return ec.ds.RemoveFinalizerForEngine(engine)
 	}
 
@@ -350,6 +373,7 @@ func (ec *EngineController) syncEngine(key string) (err error) {
 		return err
 	}
 
+	var ioPauseErr error
 	if engine.Status.CurrentState == longhorn.InstanceStateRunning {
 		// we allow across monitoring temporarily due to migration case
 		if !ec.isMonitoring(engine) {
@@ -362,14 +386,24 @@ func (ec *EngineController) syncEngine(key string) (err error) {
 				return err
 			}
 		}
-	} else if ec.isMonitoring(engine) {
-		// engine is not running
-		ec.resetAndStopMonitoring(engine)
+		if engine.Spec.DesireState != longhorn.InstanceStateStopped {
+			// The IO pause failure shouldn't block the following snapshot CRs sync.
+			ioPauseErr = ec.reconcileIOPause(engine)
+		}
+	} else {
+		// A restarted engine process doesn't inherit the paused IO
+		engine.Status.IOPaused = false
+		if ec.isMonitoring(engine) {
+			ec.resetAndStopMonitoring(engine)
+		}
 	}
 
 	if err := ec.syncSnapshotCRs(engine); err != nil {
 		return errors.Wrapf(err, "failed to sync with snapshot CRs for engine %v", engine.Name)
 	}
+	if ioPauseErr != nil {
+		return ioPauseErr
+	}
 
 	// Clean up CloneStatus for later retry
 	if engine.Spec.RequestedDataSource == "" && failedCloneBefore(engine) {
@@ -379,6 +413,59 @@ func (ec *EngineController) syncEngine(key string) (err error) {
 	return nil
 }
 
+// reconcileIOPause suspends the IO of the running engine until the pause deadline, and resumes it once the deadline
+// passes or the pause is canceled. The deadline guarantees the IO is never left paused by a forgotten request.
+func (ec *EngineController) reconcileIOPause(e *longhorn.Engine) error {
+	// Only the v2 data engine supports suspending the IO
+	if !types.IsDataEngineV2(e.Spec.DataEngine) {
+		return nil
+	}
+
+	shouldPause := false
+	if e.Spec.IOPauseDeadline != "" {
+		deadline, err := util.ParseTime(e.Spec.IOPauseDeadline)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse IO pause deadline %v", e.Spec.IOPauseDeadline)
+		}
+		if remaining := deadline.Sub(ec.clock.Now()); remaining > 0 {
+			shouldPause = true
+			ec.enqueueEngineAfter(e, remaining)
+		}
+	}
+	if shouldPause == e.Status.IOPaused {
+		return nil
+	}
+
+	im, err := ec.ds.GetInstanceManagerRO(e.Status.InstanceManagerName)
+	if err != nil {
+		return err
+	}
+	c, err := engineapi.NewInstanceManagerClient(im, false)
+	if err != nil {
+		return err
+	}
+	defer func(c io.Closer) {
+		if closeErr := c.Close(); closeErr != nil {
+			ec.logger.WithError(closeErr).Warn("Failed to close instance manager client")
+		}
+	}(c)
+
+	if shouldPause {
+		if err := c.InstanceSuspend(e.Spec.DataEngine, e.Name, string(longhorn.InstanceManagerTypeEngine)); err != nil {
+			return errors.Wrapf(err, "failed to pause IO of engine %v", e.Name)
+		}
+		ec.eventRecorder.Eventf(e, corev1.EventTypeNormal, constant.EventReasonIOPaused,
+			"Paused IO of engine %v until %v", e.Name, e.Spec.IOPauseDeadline)
+	} else {
+		if err := c.InstanceResume(e.Spec.DataEngine, e.Name, string(longhorn.InstanceManagerTypeEngine)); err != nil {
+			return errors.Wrapf(err, "failed to resume IO of engine %v", e.Name)
+		}
+		ec.eventRecorder.Eventf(e, corev1.EventTypeNormal, constant.EventReasonIOResumed, "Resumed IO of engine %v", e.Name)
+	}
+	e.Status.IOPaused = shouldPause
+	return nil
+}
+
 func failedCloneBefore(e *longhorn.Engine) bool {
 	for _, status := range e.Status.CloneStatus {
 		if status.State == engineapi.ProcessStateError {
@@ -398,6 +485,16 @@ func (ec *EngineController) enqueueEngine(obj interface{}) {
 	ec.queue.Add(key)
 }
 
+func (ec *EngineController) enqueueEngineAfter(obj interface{}, duration time.Duration) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("enqueueEngineAfter: couldn't get key for object %#v: %v", obj, err))
+		return
+	}
+
+	ec.queue.AddAfter(key, duration)
+}
+
 func (ec *EngineController) enqueueInstanceManagerChange(obj interface{}) {
 	im, isInstanceManager := obj.(*longhorn.InstanceManager)
 	if !isInstanceManager {
@@ -682,8 +779,6 @@ func (ec *EngineController) startMonitoring(e *longhorn.Engine) {
 		engines:                ec.engines,
 		stopCh:                 stopCh,
 		monitorVoluntaryStopCh: monitorVoluntaryStopCh,
-		expansionBackoff:       flowcontrol.NewBackOff(time.Second*10, time.Minute*5),
-		restoreBackoff:         flowcontrol.NewBackOff(time.Second*10, restoreMaxInterval),
 		controllerID:           ec.controllerID,
 		proxyConnCounter:       ec.proxyConnCounter,
 		restoringCounter:       ec.restoringCounter,
@@ -699,9 +794,14 @@ func (ec *EngineController) startMonitoring(e *longhorn.Engine) {
 	}
 	ec.engineMonitorMap[e.Name] = stopCh
 
+	monitor.expansionBackoff = ec.newBackoff("longhorn-engine-monitor/expansion", time.Second*10, time.Minute*5)
+	monitor.restoreBackoff = ec.newBackoff("longhorn-engine-monitor/restore", time.Second*10, restoreMaxInterval)
+
 	go monitor.Run()
 	go func() {
 		<-monitorVoluntaryStopCh
+		ec.removeBackoff(monitor.expansionBackoff)
+		ec.removeBackoff(monitor.restoreBackoff)
 		ec.engineMonitorMutex.Lock()
 		delete(ec.engineMonitorMap, e.Name)
 		ec.engineMonitorMutex.Unlock()
@@ -991,6 +1091,8 @@ func (m *EngineMonitor) refresh(engine *longhorn.Engine) error {
 		engine.Status.PurgeStatus = purgeStatus
 	}
 
+	m.refreshReplicaSalvageInfo(engine, engineClientProxy)
+
 	removeInvalidEngineOpStatus(engine)
 
 	// Make sure the engine object is updated before engineapi calls.
@@ -1021,6 +1123,8 @@ func (m *EngineMonitor) refresh(engine *longhorn.Engine) error {
 		// Cannot continue to start restoration if expansion is not complete
 		if m.expansionBackoff.IsInBackOffSinceUpdate(engine.Name, time.Now()) {
 			m.logger.Debug("Cannot start engine expansion since it is in the back-off window")
+		} else if blockedBy := getBlockingVolumeOperation(engine, longhorn.VolumeOperationTypeExpansion); blockedBy != "" {
+			m.logger.Infof("Waiting for %v to complete before starting engine expansion", blockedBy)
 		} else {
 			m.logger.Infof("Starting engine expansion from %v to %v", engine.Status.CurrentSize, engine.Spec.VolumeSize)
 			// The error info and the backoff interval will be updated later.
@@ -1050,6 +1154,13 @@ func (m *EngineMonitor) refresh(engine *longhorn.Engine) error {
 			return
 		}
 
+		// The restore may fail to start due to the backup target as well
+		if m.holdRestoreOnBackupTargetUnavailable(engine, rsMap) {
+			if err := m.acquireRestoringCounter(false); err != nil {
+				m.logger.WithError(err).Warn("Engine Monitor: Failed to unacquire restoring counter")
+			}
+		}
+
 		engine.Status.RestoreStatus = rsMap
 
 		removeInvalidEngineOpStatus(engine)
@@ -1073,9 +1184,12 @@ func (m *EngineMonitor) refresh(engine *longhorn.Engine) error {
 		}
 	}()
 
-	needRestore, err := preRestoreCheckAndSync(m.logger, engine, rsMap, addressReplicaMap, cliAPIVersion, m.ds, engineClientProxy)
-	if err != nil {
-		return err
+	needRestore := false
+	if !m.holdRestoreOnBackupTargetUnavailable(engine, rsMap) {
+		needRestore, err = preRestoreCheckAndSync(m.logger, engine, rsMap, addressReplicaMap, cliAPIVersion, m.ds, engineClientProxy)
+		if err != nil {
+			return err
+		}
 	}
 	// Incremental restoration will implicitly expand the DR volume once the backup volume is expanded
 	if needRestore {
@@ -1142,6 +1256,77 @@ func isBackupRestoreFailed(rsMap map[string]*longhorn.RestoreStatus) bool {
 	return false
 }
 
+// refreshReplicaSalvageInfo records the salvage info of the RW replicas, which is used to rank the salvage candidates
+// once all the replicas fail. The info of the replicas not in RW mode is kept as it was last recorded.
+func (m *EngineMonitor) refreshReplicaSalvageInfo(engine *longhorn.Engine, engineClientProxy engineapi.EngineClientProxy) {
+	if !types.IsDataEngineV1(engine.Spec.DataEngine) {
+		return
+	}
+	if time.Since(m.replicaSalvageInfoUpdateTime) < replicaSalvageInfoRefreshInterval {
+		return
+	}
+	m.replicaSalvageInfoUpdateTime = time.Now()
+
+	replicas, err := m.ds.ListVolumeReplicasRO(engine.Spec.VolumeName)
+	if err != nil {
+		m.logger.WithError(err).Warn("Failed to list replicas for salvage info")
+		return
+	}
+	snapshots, err := m.ds.ListVolumeSnapshotsRO(engine.Spec.VolumeName)
+	if err != nil {
+		m.logger.WithError(err).Warn("Failed to list snapshots for salvage info")
+		return
+	}
+
+	salvageInfoMap := map[string]*longhorn.ReplicaSalvageInfo{}
+	for replicaName, salvageInfo := range engine.Status.ReplicaSalvageInfoMap {
+		if _, ok := replicas[replicaName]; ok {
+			salvageInfoMap[replicaName] = salvageInfo
+		}
+	}
+	for replicaName, mode := range engine.Status.ReplicaModeMap {
+		address, ok := engine.Status.CurrentReplicaAddressMap[replicaName]
+		if _, exists := replicas[replicaName]; !exists || !ok || mode != longhorn.ReplicaModeRW {
+			continue
+		}
+		replicaInfo, err := engineClientProxy.ReplicaInfoGet(engine, replicaName, address)
+		if err != nil {
+			if status.Code(err) == codes.Unimplemented || err.Error() == engineapi.ErrNotImplement {
+				// The instance manager doesn't support it, and the candidates are ranked without the info
+				m.logger.WithError(err).Debug("Skipped getting salvage info of replicas")
+				break
+			}
+			m.logger.WithError(err).Warnf("Failed to get salvage info of replica %v", replicaName)
+			continue
+		}
+		salvageInfoMap[replicaName] = getReplicaSalvageInfo(salvageInfoMap[replicaName], replicaInfo, snapshots)
+	}
+	engine.Status.ReplicaSalvageInfoMap = salvageInfoMap
+}
+
+// getReplicaSalvageInfo returns the salvage info of the replica info. The existing info is returned as it is if the
+// data is unchanged, so that the engine status doesn't churn.
+func getReplicaSalvageInfo(existing *longhorn.ReplicaSalvageInfo, replicaInfo *engineapi.ReplicaInfo, snapshots map[string]*longhorn.Snapshot) *longhorn.ReplicaSalvageInfo {
+	salvageInfo := &longhorn.ReplicaSalvageInfo{
+		RevisionCounter: replicaInfo.RevisionCounter,
+	}
+	if !replicaInfo.LastModifyTime.IsZero() {
+		salvageInfo.LastModifiedAt = replicaInfo.LastModifyTime.UTC().Format(time.RFC3339)
+	}
+	for _, snapshotName := range replicaInfo.Snapshots {
+		if snapshot, ok := snapshots[snapshotName]; ok && snapshot.Status.Checksum != "" {
+			salvageInfo.SnapshotChecksumCount++
+		}
+	}
+	if existing != nil && existing.RevisionCounter == salvageInfo.RevisionCounter &&
+		existing.LastModifiedAt == salvageInfo.LastModifiedAt &&
+		existing.SnapshotChecksumCount == salvageInfo.SnapshotChecksumCount {
+		return existing
+	}
+	salvageInfo.RecordedAt = util.Now()
+	return salvageInfo
+}
+
 func (m *EngineMonitor) acquireRestoringCounter(acquire bool) error {
 	m.restoringCounterMutex.Lock()
 	defer m.restoringCounterMutex.Unlock()
@@ -1448,6 +1633,28 @@ func (m *EngineMonitor) restoreBackup(engine *longhorn.Engine, rsMap map[string]
 		return errors.Wrapf(err, "cannot get backup target config for backup restoration of engine %v", engine.Name)
 	}
 
+	// The volume can be restored with its own credential, e.g. the namespace-scoped one of the CSI provisioner
+	volume, err := m.ds.GetVolumeRO(engine.Spec.VolumeName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get volume %v for backup restoration of engine %v", engine.Spec.VolumeName, engine.Name)
+	}
+	if volume.Spec.RestoreCredentialSecret != "" {
+		credential, err := m.ds.GetCredentialFromSecret(volume.Spec.RestoreCredentialSecret)
+		if err != nil {
+			// The secret is created right after the volume, so the restoration waits for it without holding the
+			// restoring counter
+			if apierrors.IsNotFound(err) {
+				m.logger.Infof("Waiting for restore credential secret %v for backup restoration", volume.Spec.RestoreCredentialSecret)
+				return m.acquireRestoringCounter(false)
+			}
+			return errors.Wrapf(err, "failed to get restore credential secret %v for backup restoration of engine %v", volume.Spec.RestoreCredentialSecret, engine.Name)
+		}
+		if err := engineapi.PrepareWebIdentityTokenFile(credential, engine.Name); err != nil {
+			return errors.Wrapf(err, "failed to prepare restore credential for backup restoration of engine %v", engine.Name)
+		}
+		backupTargetClient.Credential = credential
+	}
+
 	mlog := m.logger.WithFields(logrus.Fields{
 		"backupTarget":                backupTargetClient.URL,
 		"backupVolume":                engine.Spec.BackupVolume,
@@ -1479,6 +1686,65 @@ func (m *EngineMonitor) restoreBackup(engine *longhorn.Engine, rsMap map[string]
 	return nil
 }
 
+// holdRestoreOnBackupTargetUnavailable returns true if the failed restore is held until the backup target is
+// reachable again, according to the backup target unavailable policy. The restore errors of a held or retried restore
+// are cleared, so the replicas are not failed by the volume controller and the restore can be invoked again.
+func (m *EngineMonitor) holdRestoreOnBackupTargetUnavailable(engine *longhorn.Engine, rsMap map[string]*longhorn.RestoreStatus) bool {
+	restoreErr := ""
+	for _, status := range rsMap {
+		if status.Error != "" {
+			restoreErr = status.Error
+			break
+		}
+	}
+	held := m.restoreHold != nil
+	if !held {
+		if restoreErr == "" {
+			return false
+		}
+		m.restoreHold = newBackupTargetUnavailableHold(time.Now(), restoreErr)
+	}
+
+	action := backupTargetUnavailableActionFail
+	backupTarget, err := m.getBackupTargetForRestore(engine)
+	if err == nil {
+		action, err = checkBackupTargetUnavailableHold(m.ds, m.logger, m.restoreHold, backupTarget, true)
+	}
+	if err != nil {
+		m.logger.WithError(err).Warn("Failed to check the failed restore against the backup target")
+	}
+
+	switch action {
+	case backupTargetUnavailableActionHold:
+		if !held {
+			m.logger.Warnf("Holding restore failed with error %v until backup target %v is available", m.restoreHold.err, backupTarget.Name)
+		}
+		for _, status := range rsMap {
+			status.Error = ""
+		}
+		return true
+	case backupTargetUnavailableActionRetry:
+		m.logger.Infof("Resuming restore failed with error %v since backup target %v is available", m.restoreHold.err, backupTarget.Name)
+		for _, status := range rsMap {
+			status.Error = ""
+		}
+		m.restoreBackoff.DeleteEntry(engine.Name)
+	}
+	m.restoreHold = nil
+	return false
+}
+
+func (m *EngineMonitor) getBackupTargetForRestore(engine *longhorn.Engine) (*longhorn.BackupTarget, error) {
+	if engine.Spec.BackupVolume == "" {
+		return nil, fmt.Errorf("backup volume is empty for backup restoration of engine %v", engine.Name)
+	}
+	backupVolume, err := m.ds.GetBackupVolumeRO(engine.Spec.BackupVolume)
+	if err != nil {
+		return nil, err
+	}
+	return m.ds.GetBackupTargetRO(backupVolume.Spec.BackupTargetName)
+}
+
 func (m *EngineMonitor) isReachedConcurrentVolumeBackupRestoreLimit() (isUnderLimit bool, err error) {
 	limit, err := m.ds.GetSettingAsInt(types.SettingNameConcurrentBackupRestorePerNodeLimit)
 	if err != nil {
@@ -1488,7 +1754,7 @@ func (m *EngineMonitor) isReachedConcurrentVolumeBackupRestoreLimit() (isUnderLi
 	return int(m.restoringCounter.GetCount()) >= int(limit), nil
 }
 
-func handleRestoreError(log logrus.FieldLogger, engine *longhorn.Engine, rsMap map[string]*longhorn.RestoreStatus, backoff *flowcontrol.Backoff, err error) error {
+func handleRestoreError(log logrus.FieldLogger, engine *longhorn.Engine, rsMap map[string]*longhorn.RestoreStatus, backoff *controllerBackoff, err error) error {
 	taskErr, ok := err.(imclient.TaskError)
 	if !ok {
 		return errors.Wrapf(err, "failed to restore backup %v in engine monitor, will retry the restore later",
@@ -1527,7 +1793,7 @@ func isReplicaRestoreFailedLockError(err *imclient.ReplicaError) bool {
 	return failedLock.MatchString(err.Error())
 }
 
-func handleRestoreErrorForCompatibleEngine(log logrus.FieldLogger, engine *longhorn.Engine, rsMap map[string]*longhorn.RestoreStatus, backoff *flowcontrol.Backoff, err error) error {
+func handleRestoreErrorForCompatibleEngine(log logrus.FieldLogger, engine *longhorn.Engine, rsMap map[string]*longhorn.RestoreStatus, backoff *controllerBackoff, err error) error {
 	taskErr, ok := err.(imclient.TaskError)
 	if !ok {
 		return errors.Wrapf(err, "failed to restore backup %v with last restored backup %v in engine monitor",
@@ -1685,29 +1951,105 @@ func (ec *EngineController) removeUnknownReplica(e *longhorn.Engine) error {
 }
 
 func (ec *EngineController) rebuildNewReplica(e *longhorn.Engine) error {
-	rebuildingInProgress := false
+	rebuildingReplica := ""
 	replicaExists := make(map[string]bool)
 	for replica, mode := range e.Status.ReplicaModeMap {
 		replicaExists[replica] = true
 		if mode == longhorn.ReplicaModeWO {
-			rebuildingInProgress = true
+			rebuildingReplica = replica
 			break
 		}
 	}
 	// We cannot rebuild more than one replica at one time
-	if rebuildingInProgress {
+	if rebuildingReplica != "" {
+		paused, err := ec.isReplicaRebuildPaused(rebuildingReplica)
+		if err != nil {
+			return err
+		}
+		if paused {
+			return ec.pauseRebuilding(e, rebuildingReplica, e.Status.CurrentReplicaAddressMap[rebuildingReplica])
+		}
 		ec.logger.WithField("volume", e.Spec.VolumeName).Info("Skipped rebuilding of replica because there is another rebuild in progress")
+		ec.adoptRebuilding(e, rebuildingReplica, e.Status.CurrentReplicaAddressMap[rebuildingReplica])
+		return nil
+	}
+	if blockedBy := getBlockingVolumeOperation(e, longhorn.VolumeOperationTypeRebuild); blockedBy != "" {
+		ec.logger.WithField("volume", e.Spec.VolumeName).Infof("Waiting for %v to complete before rebuilding replica", blockedBy)
 		return nil
 	}
 	for replica, addr := range e.Status.CurrentReplicaAddressMap {
 		// one is enough
 		if !replicaExists[replica] {
+			paused, err := ec.isReplicaRebuildPaused(replica)
+			if err != nil {
+				return err
+			}
+			if paused {
+				ec.logger.WithField("volume", e.Spec.VolumeName).Debugf("Skipped rebuilding of replica %v because its rebuild is paused", replica)
+				continue
+			}
 			return ec.startRebuilding(e, replica, addr)
 		}
 	}
 	return nil
 }
 
+// isReplicaRebuildPaused returns true if the rebuild of the replica is paused by the user. A replica that is gone is
+// left to the other reconciliations.
+func (ec *EngineController) isReplicaRebuildPaused(replicaName string) (bool, error) {
+	r, err := ec.ds.GetReplicaRO(replicaName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return r.Spec.RebuildPaused, nil
+}
+
+// pauseRebuilding removes the rebuilding replica from the engine and stops the replica to stop the in progress
+// rebuild. The replica is not marked as failed, and is started and added back to the engine once the rebuild is
+// resumed.
+func (ec *EngineController) pauseRebuilding(e *longhorn.Engine, replicaName, addr string) error {
+	engineClientProxy, err := ec.getEngineClientProxy(e, e.Status.CurrentImage)
+	if err != nil {
+		return err
+	}
+	defer engineClientProxy.Close()
+
+	if err := engineClientProxy.ReplicaRemove(e, engineapi.GetBackendReplicaURL(addr), replicaName); err != nil {
+		return errors.Wrapf(err, "failed to remove rebuilding replica %v to pause the rebuild", replicaName)
+	}
+
+	// The files keep being synced to the replica after it is removed from the engine. Stopping the replica cancels the
+	// in-flight transfer, and the replica is not started again while the rebuild is paused.
+	if err := ec.stopPausedRebuildingReplica(replicaName); err != nil {
+		return err
+	}
+
+	ec.eventRecorder.Eventf(e, corev1.EventTypeNormal, constant.EventReasonRebuildPaused,
+		"Paused rebuilding replica %v with Address %v for volume %v", replicaName, addr, e.Spec.VolumeName)
+	return nil
+}
+
+func (ec *EngineController) stopPausedRebuildingReplica(replicaName string) error {
+	r, err := ec.ds.GetReplica(replicaName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if r.Spec.DesireState == longhorn.InstanceStateStopped {
+		return nil
+	}
+	r.Spec.DesireState = longhorn.InstanceStateStopped
+	if _, err := ec.ds.UpdateReplica(r); err != nil {
+		return errors.Wrapf(err, "failed to stop rebuilding replica %v to pause the rebuild", replicaName)
+	}
+	return nil
+}
+
 func doesAddressExistInEngine(e *longhorn.Engine, addr string, engineClientProxy engineapi.EngineClientProxy) (bool, error) {
 	replicaURLModeMap, err := engineClientProxy.ReplicaList(e)
 	if err != nil {
@@ -1731,6 +2073,13 @@ func (ec *EngineController) startRebuilding(e *longhorn.Engine, replicaName, add
 
 	log := ec.logger.WithFields(logrus.Fields{"volume": e.Spec.VolumeName, "engine": e.Name})
 
+	if ec.isRebuildSupervised(e, replicaName) {
+		// The rebuild adopted from the previous manager pod is still in progress, e.g. while the replica mode
+		// reported by the engine is not updated yet
+		log.Infof("Replica %v is being rebuilt already", replicaName)
+		return nil
+	}
+
 	engineClientProxy, err := ec.getEngineClientProxy(e, e.Status.CurrentImage)
 	if err != nil {
 		return err
@@ -1749,7 +2098,14 @@ func (ec *EngineController) startRebuilding(e *longhorn.Engine, replicaName, add
 	}
 
 	replicaURL := engineapi.GetBackendReplicaURL(addr)
+	if !ec.superviseRebuild(e, replicaName) {
+		// The rebuild adopted from the previous manager pod is still in progress
+		log.Infof("Replica %v is being rebuilt already", replicaName)
+		return nil
+	}
 	go func() {
+		defer ec.unsuperviseRebuild(e, replicaName)
+
 		autoCleanupSystemGeneratedSnapshot, err := ec.ds.GetSettingAsBool(types.SettingNameAutoCleanupSystemGeneratedSnapshot)
 		if err != nil {
 			log.WithError(err).Errorf("Failed to get %v setting", types.SettingDefinitionAutoCleanupSystemGeneratedSnapshot)
@@ -1849,6 +2205,15 @@ func (ec *EngineController) startRebuilding(e *longhorn.Engine, replicaName, add
 			ec.logger.WithError(err).Warnf("Failed to initiate file local sync for replica %v, use remote sync", replicaName)
 		}
 
+		// A reused failed replica still has most of the snapshot disk files. With the checksums of the snapshots, the
+		// fast sync skips the unchanged ones and only transfers the deltas and the volume head.
+		if fastReplicaRebuild && types.IsDataEngineV1(e.Spec.DataEngine) && e.Spec.RequestedBackupRestore == "" &&
+			isReusedReplicaWithData(replica) {
+			if !ec.prepareDeltaRebuild(e, engineClientProxy, replica, addr, log) {
+				return
+			}
+		}
+
 		// start rebuild
 		if e.Spec.RequestedBackupRestore != "" {
 			if e.Spec.NodeID != "" {
@@ -1864,79 +2229,299 @@ func (ec *EngineController) startRebuilding(e *longhorn.Engine, replicaName, add
 
 		// For v2 engine, the rebuilding is an async call. We need to wait for the rebuilding start then complete here
 		if err == nil && types.IsDataEngineV2(e.Spec.DataEngine) {
-			err = ec.waitForV2EngineRebuild(e, replicaName, grpcTimeoutSeconds)
+			err = ec.waitForEngineRebuild(e, replicaName, grpcTimeoutSeconds)
 		}
 
-		if err != nil {
-			replicaRebuildErrMsg := err.Error()
+		ec.finishRebuilding(e, engineClientProxy, replica, addr, autoCleanupSystemGeneratedSnapshot, err, log)
+	}()
+
+	// Wait until engine confirmed that rebuild started
+	if err := wait.PollUntilContextTimeout(context.Background(), EnginePollInterval, EnginePollTimeout, true, func(context.Context) (bool, error) {
+		return doesAddressExistInEngine(e, addr, engineClientProxy)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// finishRebuilding handles the result of the rebuild of the replica. A failed rebuilding replica is removed from the
+// engine, and marked as failed unless the engine is still in the rebuild backoff period.
+func (ec *EngineController) finishRebuilding(e *longhorn.Engine, engineClientProxy engineapi.EngineClientProxy, replica *longhorn.Replica,
+	addr string, autoCleanupSystemGeneratedSnapshot bool, err error, log *logrus.Entry) {
+	replicaName := replica.Name
+	replicaURL := engineapi.GetBackendReplicaURL(addr)
+
+	if err != nil {
+		replicaRebuildErrMsg := err.Error()
 
+		// The rebuild is interrupted by the pause rather than failed. The replica is cleaned up the same way, but not
+		// marked as failed.
+		paused, pausedErr := ec.isReplicaRebuildPaused(replicaName)
+		if pausedErr != nil {
+			log.WithError(pausedErr).Warnf("Failed to check if the rebuild of replica %v is paused", replicaName)
+		}
+		if paused {
+			log.Infof("Rebuilding replica %v is paused", addr)
+		} else {
 			log.WithError(err).Errorf("Failed to rebuild replica %v", addr)
 			ec.eventRecorder.Eventf(e, corev1.EventTypeWarning, constant.EventReasonFailedRebuilding, "Failed rebuilding replica with Address %v: %v", addr, err)
-			// we've sent out event to notify user. we don't want to
-			// automatically handle it because it may cause chain
-			// reaction to create numerous new replicas if we set
-			// the replica to failed.
-			// user can decide to delete it then we will try again
-			log.Infof("Removing failed rebuilding replica %v", addr)
-			if err := engineClientProxy.ReplicaRemove(e, replicaURL, replicaName); err != nil {
-				log.WithError(err).Warnf("Failed to remove rebuilding replica %v", addr)
-				ec.eventRecorder.Eventf(e, corev1.EventTypeWarning, constant.EventReasonFailedDeleting,
-					"Failed to remove rebuilding replica %v with address %v for engine %v and volume %v due to rebuilding failure: %v",
-					replicaName, addr, e.Name, e.Spec.VolumeName, err)
+		}
+		// we've sent out event to notify user. we don't want to
+		// automatically handle it because it may cause chain
+		// reaction to create numerous new replicas if we set
+		// the replica to failed.
+		// user can decide to delete it then we will try again
+		log.Infof("Removing failed rebuilding replica %v", addr)
+		if err := engineClientProxy.ReplicaRemove(e, replicaURL, replicaName); err != nil {
+			log.WithError(err).Warnf("Failed to remove rebuilding replica %v", addr)
+			ec.eventRecorder.Eventf(e, corev1.EventTypeWarning, constant.EventReasonFailedDeleting,
+				"Failed to remove rebuilding replica %v with address %v for engine %v and volume %v due to rebuilding failure: %v",
+				replicaName, addr, e.Name, e.Spec.VolumeName, err)
+		}
+		if paused {
+			if err := ec.stopPausedRebuildingReplica(replicaName); err != nil {
+				log.WithError(err).Warnf("Failed to stop paused rebuilding replica %v", replicaName)
 			}
+			return
+		}
 
-			// Before we mark the Replica as Failed automatically, we want to check the Backoff to avoid recreating new
-			// Replicas too quickly. If the Replica is still in the Backoff period, we will leave the Replica alone. If
-			// it is past the Backoff period, we'll try to mark the Replica as Failed and increase the Backoff period
-			// for the next failure.
-			if !ec.backoff.IsInBackOffSinceUpdate(e.Name, time.Now()) {
-				replica, err = ec.updateReplicaRebuildFailedCondition(replica, replicaRebuildErrMsg)
-				if err != nil {
-					log.WithError(err).Errorf("Failed to update rebuild status information on replica %v", replicaName)
-					return
-				}
+		// Before we mark the Replica as Failed automatically, we want to check the Backoff to avoid recreating new
+		// Replicas too quickly. If the Replica is still in the Backoff period, we will leave the Replica alone. If
+		// it is past the Backoff period, we'll try to mark the Replica as Failed and increase the Backoff period
+		// for the next failure.
+		if !ec.backoff.IsInBackOffSinceUpdate(e.Name, time.Now()) {
+			replica, err = ec.updateReplicaRebuildFailedCondition(replica, replicaRebuildErrMsg)
+			if err != nil {
+				log.WithError(err).Errorf("Failed to update rebuild status information on replica %v", replicaName)
+				return
+			}
 
-				setReplicaFailedAt(replica, util.Now())
-				replica.Spec.DesireState = longhorn.InstanceStateStopped
-				if _, err := ec.ds.UpdateReplica(replica); err != nil {
-					log.WithError(err).Errorf("Unable to mark failed rebuild on replica %v", replicaName)
-					return
-				}
-				// Now that the Replica can actually be recreated, we can move up the Backoff.
-				ec.backoff.Next(e.Name, time.Now())
-				backoffTime := ec.backoff.Get(e.Name).Seconds()
-				log.Infof("Marked failed rebuild on replica %v, backoff period is now %v seconds", replicaName, backoffTime)
+			setReplicaFailedAt(replica, util.Now())
+			replica.Spec.DesireState = longhorn.InstanceStateStopped
+			if _, err := ec.ds.UpdateReplica(replica); err != nil {
+				log.WithError(err).Errorf("Unable to mark failed rebuild on replica %v", replicaName)
 				return
 			}
-			log.Debugf("Engine is still in backoff for replica %v rebuild failure", replicaName)
+			// Now that the Replica can actually be recreated, we can move up the Backoff.
+			ec.backoff.Next(e.Name, time.Now())
+			backoffTime := ec.backoff.Get(e.Name).Seconds()
+			log.Infof("Marked failed rebuild on replica %v, backoff period is now %v seconds", replicaName, backoffTime)
+			return
+		}
+		log.Debugf("Engine is still in backoff for replica %v rebuild failure", replicaName)
+		return
+	}
+	// Replica rebuild succeeded, clear Backoff.
+	ec.backoff.DeleteEntry(e.Name)
+	ec.eventRecorder.Eventf(e, corev1.EventTypeNormal, constant.EventReasonRebuilt,
+		"Replica %v with Address %v has been rebuilt for volume %v", replicaName, addr, e.Spec.VolumeName)
+
+	// If enabled, call SnapshotPurge to clean up system generated snapshot after rebuilding.
+	// It is not necessary to check the value of DisableSnapshotPurge here because the webhook prevents enabling
+	// AutoCleanupSystemGeneratedSnapshot and DisableSnapshot purge simultaneously.
+	if autoCleanupSystemGeneratedSnapshot {
+		log.Info("Starting snapshot purge after rebuilding")
+		if err := engineClientProxy.SnapshotPurge(e); err != nil {
+			log.WithError(err).Error("Failed to start snapshot purge after rebuilding")
+			ec.eventRecorder.Eventf(e, corev1.EventTypeWarning, constant.EventReasonFailedStartingSnapshotPurge,
+				"Failed to start snapshot purge for engine %v and volume %v after rebuilding: %v", e.Name, e.Spec.VolumeName, err)
 			return
 		}
-		// Replica rebuild succeeded, clear Backoff.
-		ec.backoff.DeleteEntry(e.Name)
-		ec.eventRecorder.Eventf(e, corev1.EventTypeNormal, constant.EventReasonRebuilt,
-			"Replica %v with Address %v has been rebuilt for volume %v", replicaName, addr, e.Spec.VolumeName)
+	}
+}
 
-		// If enabled, call SnapshotPurge to clean up system generated snapshot after rebuilding.
-		// It is not necessary to check the value of DisableSnapshotPurge here because the webhook prevents enabling
-		// AutoCleanupSystemGeneratedSnapshot and DisableSnapshot purge simultaneously.
-		if autoCleanupSystemGeneratedSnapshot {
-			log.Info("Starting snapshot purge after rebuilding")
-			if err := engineClientProxy.SnapshotPurge(e); err != nil {
-				log.WithError(err).Error("Failed to start snapshot purge after rebuilding")
-				ec.eventRecorder.Eventf(e, corev1.EventTypeWarning, constant.EventReasonFailedStartingSnapshotPurge,
-					"Failed to start snapshot purge for engine %v and volume %v after rebuilding: %v", e.Name, e.Spec.VolumeName, err)
-				return
-			}
+func getSupervisedRebuildKey(e *longhorn.Engine, replicaName string) string {
+	return e.Name + "/" + replicaName
+}
+
+// superviseRebuild returns false if the rebuild of the replica is supervised already
+func (ec *EngineController) superviseRebuild(e *longhorn.Engine, replicaName string) bool {
+	ec.supervisedRebuildMutex.Lock()
+	defer ec.supervisedRebuildMutex.Unlock()
+
+	key := getSupervisedRebuildKey(e, replicaName)
+	if _, exists := ec.supervisedRebuilds[key]; exists {
+		return false
+	}
+	ec.supervisedRebuilds[key] = struct{}{}
+	return true
+}
+
+// isRebuildSupervised returns true if the rebuild of the replica is supervised already
+func (ec *EngineController) isRebuildSupervised(e *longhorn.Engine, replicaName string) bool {
+	ec.supervisedRebuildMutex.Lock()
+	defer ec.supervisedRebuildMutex.Unlock()
+
+	_, exists := ec.supervisedRebuilds[getSupervisedRebuildKey(e, replicaName)]
+	return exists
+}
+
+func (ec *EngineController) unsuperviseRebuild(e *longhorn.Engine, replicaName string) {
+	ec.supervisedRebuildMutex.Lock()
+	defer ec.supervisedRebuildMutex.Unlock()
+
+	delete(ec.supervisedRebuilds, getSupervisedRebuildKey(e, replicaName))
+}
+
+// adoptRebuilding supervises the rebuild of the replica in progress in the engine, which has not been started by
+// this controller, e.g. started by the previous manager pod before a restart. The result of the rebuild is then
+// handled as if the rebuild had been started here, instead of the rebuild being left unsupervised.
+func (ec *EngineController) adoptRebuilding(e *longhorn.Engine, replicaName, addr string) {
+	if addr == "" || !ec.superviseRebuild(e, replicaName) {
+		return
+	}
+
+	log := ec.logger.WithFields(logrus.Fields{"volume": e.Spec.VolumeName, "engine": e.Name, "replica": replicaName})
+	log.Infof("Adopting the rebuild of replica %v with address %v in progress", replicaName, addr)
+
+	go func() {
+		defer ec.unsuperviseRebuild(e, replicaName)
+
+		autoCleanupSystemGeneratedSnapshot, err := ec.ds.GetSettingAsBool(types.SettingNameAutoCleanupSystemGeneratedSnapshot)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to get %v setting", types.SettingNameAutoCleanupSystemGeneratedSnapshot)
+			return
+		}
+
+		grpcTimeoutSeconds, err := ec.ds.GetSettingAsInt(types.SettingNameLongGPRCTimeOut)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to get %v setting", types.SettingNameLongGPRCTimeOut)
+			return
+		}
+
+		engineClientProxy, err := ec.getEngineClientProxy(e, e.Status.CurrentImage)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to adopt the rebuild of replica %v", addr)
+			return
 		}
+		defer engineClientProxy.Close()
+
+		replica, err := ec.ds.GetReplica(replicaName)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to get replica %v", replicaName)
+			return
+		}
+
+		err = ec.waitForEngineRebuild(e, replicaName, grpcTimeoutSeconds)
+		ec.finishRebuilding(e, engineClientProxy, replica, addr, autoCleanupSystemGeneratedSnapshot, err, log)
 	}()
+}
 
-	// Wait until engine confirmed that rebuild started
-	if err := wait.PollUntilContextTimeout(context.Background(), EnginePollInterval, EnginePollTimeout, true, func(context.Context) (bool, error) {
-		return doesAddressExistInEngine(e, addr, engineClientProxy)
-	}); err != nil {
-		return err
+// isReusedReplicaWithData returns true if the rebuilding replica is a reused failed replica that was healthy before
+// it failed, so its data directory still contains the snapshot disk files.
+func isReusedReplicaWithData(r *longhorn.Replica) bool {
+	return r.Spec.HealthyAt == "" && r.Spec.LastHealthyAt != "" && r.Spec.LastFailedAt != ""
+}
+
+// getDeltaRebuildSnapshotsToHash returns the sorted snapshots of the engine without a recorded checksum that were
+// created before the reused replica failed. Only these snapshots may still be on the reused replica, so only their
+// checksums let the fast sync of the rebuild skip unchanged snapshots. The later ones are transferred entirely anyway.
+func getDeltaRebuildSnapshotsToHash(e *longhorn.Engine, snapshots map[string]*longhorn.Snapshot, replicaFailedAt time.Time) []string {
+	snapshotNames := []string{}
+	for name, snapshot := range e.Status.Snapshots {
+		if name == etypes.VolumeHeadName || snapshot.Removed {
+			continue
+		}
+		if snapshotCR, ok := snapshots[name]; ok && snapshotCR.Status.Checksum != "" {
+			continue
+		}
+		createdAt, err := util.ParseTime(snapshot.Created)
+		if err != nil || !createdAt.Before(replicaFailedAt) {
+			continue
+		}
+		snapshotNames = append(snapshotNames, name)
 	}
-	return nil
+	sort.Strings(snapshotNames)
+	return snapshotNames
+}
+
+// prepareDeltaRebuild hashes the snapshots the reused replica may still have on the healthy replicas before the
+// reused replica is added to the engine. The reused replica is not in the engine yet, so its snapshot disk files are
+// checksummed by the sync agent during the rebuild rather than here. The hashing of all snapshots is requested at
+// once, and the rebuild proceeds once the hashing is done or after deltaRebuildHashTimeout, while the replicas keep
+// hashing in the background. Failing to get a checksum only makes the rebuild transfer the whole snapshot.
+// Returns false if the rebuild should not proceed anymore.
+func (ec *EngineController) prepareDeltaRebuild(e *longhorn.Engine, engineClientProxy engineapi.EngineClientProxy,
+	replica *longhorn.Replica, addr string, log *logrus.Entry) bool {
+	replicaName := replica.Name
+	replicaFailedAt, err := util.ParseTime(replica.Spec.LastFailedAt)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to parse the failed time of replica %v for the delta rebuild", replicaName)
+		return true
+	}
+	snapshots, err := ec.ds.ListVolumeSnapshotsRO(e.Spec.VolumeName)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to list snapshots for the delta rebuild of replica %v", replicaName)
+		return true
+	}
+
+	pendingSnapshots := map[string]struct{}{}
+	for _, snapshotName := range getDeltaRebuildSnapshotsToHash(e, snapshots, replicaFailedAt) {
+		if err := engineClientProxy.SnapshotHash(e, snapshotName, false); err != nil {
+			log.WithError(err).Warnf("Failed to request hashing snapshot %v for the delta rebuild of replica %v", snapshotName, replicaName)
+			continue
+		}
+		pendingSnapshots[snapshotName] = struct{}{}
+	}
+	if len(pendingSnapshots) == 0 {
+		return true
+	}
+
+	ec.eventRecorder.Eventf(e, corev1.EventTypeNormal, constant.EventReasonRebuilding,
+		"Preparing delta rebuild of reused replica %v with Address %v, hashing %v snapshots without checksums", replicaName, addr, len(pendingSnapshots))
+
+	proceed := true
+	err = wait.PollUntilContextTimeout(context.Background(), EnginePollInterval, deltaRebuildHashTimeout, false,
+		func(ctx context.Context) (bool, error) {
+			// Should we proceed?
+			latestEngine, err := ec.ds.GetEngineRO(e.Name)
+			if err != nil {
+				log.WithError(err).Error("Failed to get engine and wait for the snapshot hashing before rebuilding")
+				proceed = false
+				return true, nil
+			}
+			if !shouldProceedToWaitAndRebuild(latestEngine, replicaName, addr, log) {
+				proceed = false
+				return true, nil
+			}
+
+			for snapshotName := range pendingSnapshots {
+				status, err := engineClientProxy.SnapshotHashStatus(e, snapshotName)
+				if err != nil {
+					log.WithError(err).Warnf("Failed to get the hashing status of snapshot %v for the delta rebuild of replica %v", snapshotName, replicaName)
+					continue
+				}
+				inProgress, err := getSnapshotHashResult(status, snapshotName)
+				if inProgress {
+					continue
+				}
+				// Failing to hash only makes the rebuild transfer the whole snapshot
+				if err != nil {
+					log.WithError(err).Warnf("Failed to hash snapshot %v for the delta rebuild of replica %v", snapshotName, replicaName)
+				}
+				delete(pendingSnapshots, snapshotName)
+			}
+			return len(pendingSnapshots) == 0, nil
+		})
+	if err != nil {
+		log.WithError(err).Warnf("Timeout waiting for hashing %v snapshots before the delta rebuild of replica %v, proceeding with the rebuild",
+			len(pendingSnapshots), replicaName)
+	}
+	return proceed
+}
+
+// getSnapshotHashResult returns true if any replica is still hashing the snapshot. Otherwise, it returns the error of
+// the replicas failing to hash the snapshot, if any.
+func getSnapshotHashResult(status map[string]*longhorn.HashStatus, snapshotName string) (inProgress bool, err error) {
+	for address, replicaStatus := range status {
+		if replicaStatus.State == string(engineapi.ProcessStateInProgress) {
+			return true, nil
+		}
+		if replicaStatus.State == string(longhorn.SnapshotHashStatusError) {
+			err = fmt.Errorf("failed to hash snapshot %v on replica %v: %v", snapshotName, address, replicaStatus.Error)
+		}
+	}
+	return false, err
 }
 
 // getFileLocalSync retrieves details for local file sync between the target replica
@@ -2040,11 +2625,8 @@ func getReplicaRebuildFailedReasonFromError(errMsg string) (string, longhorn.Con
 	}
 }
 
-func (ec *EngineController) waitForV2EngineRebuild(e *longhorn.Engine, replicaName string, timeout int64) (err error) {
-	if !types.IsDataEngineV2(e.Spec.DataEngine) {
-		return nil
-	}
-
+// waitForEngineRebuild waits for the rebuild of the replica in progress in the engine to complete
+func (ec *EngineController) waitForEngineRebuild(e *longhorn.Engine, replicaName string, timeout int64) (err error) {
 	ticker := time.NewTicker(EnginePollInterval)
 	defer ticker.Stop()
 	timer := time.NewTimer(time.Duration(timeout) * time.Second)
@@ -5,13 +5,28 @@ import (
 	"io"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+
 	etypes "github.com/longhorn/longhorn-engine/pkg/types"
-	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+
+	"github.com/longhorn/longhorn-manager/constant"
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/engineapi"
+	"github.com/longhorn/longhorn-manager/types"
 	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
 )
 
 func TestNeedStatusUpdate(t *testing.T) {
@@ -132,3 +147,253 @@ func TestNeedStatusUpdate(t *testing.T) {
 		assert.Equal(tc.expectRateLimited, rateLimited, "rateLimited")
 	}
 }
+
+func TestGetDeltaRebuildSnapshotsToHash(t *testing.T) {
+	assert := require.New(t)
+
+	e := &longhorn.Engine{
+		Status: longhorn.EngineStatus{
+			Snapshots: map[string]*longhorn.SnapshotInfo{
+				"snap-hashed":         {Name: "snap-hashed", Created: "2026-01-01T00:00:00Z"},
+				"snap-unhashed":       {Name: "snap-unhashed", Created: "2026-01-01T00:00:00Z"},
+				"snap-removed":        {Name: "snap-removed", Created: "2026-01-01T00:00:00Z", Removed: true},
+				"snap-without-cr":     {Name: "snap-without-cr", Created: "2026-01-01T00:00:00Z"},
+				"snap-after-failure":  {Name: "snap-after-failure", Created: "2026-01-03T00:00:00Z"},
+				"snap-without-time":   {Name: "snap-without-time"},
+				etypes.VolumeHeadName: {Name: etypes.VolumeHeadName},
+			},
+		},
+	}
+	snapshots := map[string]*longhorn.Snapshot{
+		"snap-hashed":        {Status: longhorn.SnapshotStatus{Checksum: "checksum"}},
+		"snap-unhashed":      {},
+		"snap-removed":       {},
+		"snap-after-failure": {},
+	}
+	replicaFailedAt := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal([]string{"snap-unhashed", "snap-without-cr"}, getDeltaRebuildSnapshotsToHash(e, snapshots, replicaFailedAt))
+}
+
+func TestGetSnapshotHashResult(t *testing.T) {
+	assert := require.New(t)
+
+	inProgress, err := getSnapshotHashResult(map[string]*longhorn.HashStatus{
+		"tcp://1.2.3.4:10000": {State: string(engineapi.ProcessStateComplete)},
+		"tcp://5.6.7.8:10000": {State: string(engineapi.ProcessStateInProgress)},
+	}, "snap")
+	assert.True(inProgress)
+	assert.NoError(err)
+
+	inProgress, err = getSnapshotHashResult(map[string]*longhorn.HashStatus{
+		"tcp://1.2.3.4:10000": {State: string(engineapi.ProcessStateComplete)},
+		"tcp://5.6.7.8:10000": {State: string(longhorn.SnapshotHashStatusError), Error: "failed"},
+	}, "snap")
+	assert.False(inProgress)
+	assert.EqualError(err, "failed to hash snapshot snap on replica tcp://5.6.7.8:10000: failed")
+
+	inProgress, err = getSnapshotHashResult(map[string]*longhorn.HashStatus{
+		"tcp://1.2.3.4:10000": {State: string(engineapi.ProcessStateComplete)},
+	}, "snap")
+	assert.False(inProgress)
+	assert.NoError(err)
+}
+
+func TestIsReusedReplicaWithData(t *testing.T) {
+	assert := require.New(t)
+
+	type testCase struct {
+		healthyAt     string
+		lastHealthyAt string
+		lastFailedAt  string
+		expected      bool
+	}
+	tests := map[string]testCase{
+		"new replica":                    {"", "", "", false},
+		"healthy replica":                {"t1", "t1", "", false},
+		"reused failed replica":          {"", "t1", "t2", true},
+		"reused never healthy replica":   {"", "", "t2", false},
+		"failed replica rebuilt already": {"t3", "t3", "t2", false},
+	}
+
+	for name, tc := range tests {
+		r := &longhorn.Replica{}
+		r.Spec.HealthyAt = tc.healthyAt
+		r.Spec.LastHealthyAt = tc.lastHealthyAt
+		r.Spec.LastFailedAt = tc.lastFailedAt
+		assert.Equal(tc.expected, isReusedReplicaWithData(r), name)
+	}
+}
+
+func TestGetReplicaSalvageInfo(t *testing.T) {
+	assert := require.New(t)
+
+	snapshots := map[string]*longhorn.Snapshot{
+		"snap-1": {Status: longhorn.SnapshotStatus{Checksum: "checksum-1"}},
+		"snap-2": {},
+	}
+	replicaInfo := &engineapi.ReplicaInfo{
+		RevisionCounter: 100,
+		LastModifyTime:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Snapshots:       []string{"snap-1", "snap-2", "snap-without-cr"},
+	}
+
+	salvageInfo := getReplicaSalvageInfo(nil, replicaInfo, snapshots)
+	assert.Equal(int64(100), salvageInfo.RevisionCounter)
+	assert.Equal("2026-01-02T03:04:05Z", salvageInfo.LastModifiedAt)
+	assert.Equal(1, salvageInfo.SnapshotChecksumCount)
+	assert.NotEmpty(salvageInfo.RecordedAt)
+
+	// The recorded info is kept as it is if the data is unchanged
+	existing := salvageInfo.DeepCopy()
+	existing.RecordedAt = "2026-01-01T00:00:00Z"
+	assert.Same(existing, getReplicaSalvageInfo(existing, replicaInfo, snapshots))
+
+	replicaInfo.RevisionCounter++
+	salvageInfo = getReplicaSalvageInfo(existing, replicaInfo, snapshots)
+	assert.Equal(int64(101), salvageInfo.RevisionCounter)
+	assert.NotEqual(existing.RecordedAt, salvageInfo.RecordedAt)
+}
+
+func TestReconcileIOPause(t *testing.T) {
+	pauseDeadline := getTestClock().Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	passedDeadline := getTestClock().Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+
+	tests := map[string]struct {
+		dataEngine       longhorn.DataEngineType
+		ioPauseDeadline  string
+		ioPaused         bool
+		expectError      bool
+		expectedIOPaused bool
+	}{
+		"v1 engine with pause deadline": {
+			dataEngine:      longhorn.DataEngineTypeV1,
+			ioPauseDeadline: pauseDeadline,
+		},
+		"v2 engine paused before deadline": {
+			dataEngine:       longhorn.DataEngineTypeV2,
+			ioPauseDeadline:  pauseDeadline,
+			ioPaused:         true,
+			expectedIOPaused: true,
+		},
+		"v2 engine not paused after deadline": {
+			dataEngine:      longhorn.DataEngineTypeV2,
+			ioPauseDeadline: passedDeadline,
+		},
+		"v2 engine to be paused": {
+			// The instance manager is missing so the suspension fails
+			dataEngine:      longhorn.DataEngineTypeV2,
+			ioPauseDeadline: pauseDeadline,
+			expectError:     true,
+		},
+		"v2 engine to be resumed": {
+			dataEngine:       longhorn.DataEngineTypeV2,
+			ioPaused:         true,
+			expectError:      true,
+			expectedIOPaused: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset()
+			lhClient := lhfake.NewSimpleClientset()
+			extensionsClient := apiextensionsfake.NewSimpleClientset()
+			informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+			ds := datastore.NewDataStore(TestNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+
+			ec, err := NewEngineController(logrus.StandardLogger(), ds, scheme.Scheme, kubeClient, nil,
+				TestNamespace, TestNode1, util.NewAtomicCounter())
+			require.NoError(t, err)
+			ec.eventRecorder = record.NewFakeRecorder(100)
+			ec.SetClock(getTestClock())
+
+			e := newEngineForVolume(newVolume(TestVolumeName, 2))
+			e.Spec.DataEngine = tc.dataEngine
+			e.Spec.IOPauseDeadline = tc.ioPauseDeadline
+			e.Status.IOPaused = tc.ioPaused
+			e.Status.InstanceManagerName = TestInstanceManagerName
+
+			err = ec.reconcileIOPause(e)
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expectedIOPaused, e.Status.IOPaused)
+		})
+	}
+}
+
+func TestAdoptRebuilding(t *testing.T) {
+	assert := require.New(t)
+
+	defer func(interval time.Duration) { EnginePollInterval = interval }(EnginePollInterval)
+	EnginePollInterval = 10 * time.Millisecond
+
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+	ds := datastore.NewDataStore(TestNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+	lhInformers := informerFactories.LhInformerFactory.Longhorn().V1beta2()
+
+	ec, err := NewEngineController(logrus.StandardLogger(), ds, scheme.Scheme, kubeClient, &engineapi.EngineCollection{},
+		TestNamespace, TestNode1, util.NewAtomicCounter())
+	assert.NoError(err)
+	fakeRecorder := record.NewFakeRecorder(100)
+	ec.eventRecorder = fakeRecorder
+
+	// The instance manager doesn't support the proxy, so the engine binary client is used without contacting the engine
+	im := newInstanceManager(TestInstanceManagerName, longhorn.InstanceManagerStateRunning, TestNode1, TestNode1, TestIP1,
+		nil, nil, longhorn.DataEngineTypeV1, TestInstanceManagerImage, false)
+	assert.NoError(lhInformers.InstanceManagers().Informer().GetIndexer().Add(im))
+	setting := newSetting(string(types.SettingNameAutoCleanupSystemGeneratedSnapshot), "false")
+	assert.NoError(lhInformers.Settings().Informer().GetIndexer().Add(setting))
+
+	v := newVolume(TestVolumeName, 2)
+	e := newEngineForVolume(v)
+	r := newReplicaForVolume(v, e, TestNode1, TestDiskID1)
+	r.Namespace = TestNamespace
+	r.Status.CurrentState = longhorn.InstanceStateRunning
+	assert.NoError(lhInformers.Replicas().Informer().GetIndexer().Add(r))
+
+	addr := TestIP1 + ":10000"
+	e.Spec.ReplicaAddressMap = map[string]string{r.Name: addr}
+	e.Status.CurrentState = longhorn.InstanceStateRunning
+	e.Status.CurrentImage = TestEngineImage
+	e.Status.IP = TestIP1
+	e.Status.Port = randomPort()
+	e.Status.InstanceManagerName = im.Name
+	e.Status.CurrentReplicaAddressMap = map[string]string{r.Name: addr}
+	e.Status.ReplicaModeMap = map[string]longhorn.ReplicaMode{r.Name: longhorn.ReplicaModeWO}
+	engineIndexer := lhInformers.Engines().Informer().GetIndexer()
+	assert.NoError(engineIndexer.Add(e))
+
+	// The rebuild in progress in the engine, which isn't started by this controller, is adopted and supervised
+	assert.NoError(ec.rebuildNewReplica(e))
+	assert.True(ec.isRebuildSupervised(e, r.Name))
+
+	// Another replica isn't rebuilt while the adopted rebuild is in progress
+	pending := e.DeepCopy()
+	pending.Status.CurrentReplicaAddressMap["pending-replica"] = TestIP2 + ":10000"
+	assert.NoError(ec.rebuildNewReplica(pending))
+	assert.False(ec.isRebuildSupervised(pending, "pending-replica"))
+
+	// The rebuild isn't started again while it's supervised, even if the engine doesn't report the replica yet. The
+	// engine binary isn't available, so contacting the engine to start a rebuild would fail.
+	unreported := e.DeepCopy()
+	unreported.Status.ReplicaModeMap = map[string]longhorn.ReplicaMode{}
+	assert.NoError(ec.rebuildNewReplica(unreported))
+	assert.True(ec.isRebuildSupervised(e, r.Name))
+
+	// The result of the adopted rebuild is handled as for a rebuild started by this controller
+	rebuilt := e.DeepCopy()
+	rebuilt.Status.ReplicaModeMap[r.Name] = longhorn.ReplicaModeRW
+	assert.NoError(engineIndexer.Update(rebuilt))
+	assert.Eventually(func() bool {
+		return !ec.isRebuildSupervised(e, r.Name)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Len(fakeRecorder.Events, 1)
+	assert.Contains(<-fakeRecorder.Events, constant.EventReasonRebuilt)
+}
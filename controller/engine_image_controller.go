@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/robfig/cron"
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -36,8 +38,6 @@ import (
 
 var (
 	ownerKindEngineImage = longhorn.SchemeGroupVersion.WithKind("EngineImage").String()
-
-	ExpiredEngineImageTimeout = 60 * time.Minute
 )
 
 type EngineImageController struct {
@@ -57,7 +57,6 @@ type EngineImageController struct {
 	cacheSyncs []cache.InformerSynced
 
 	// for unit test
-	nowHandler                func() string
 	engineBinaryChecker       func(string) bool
 	engineImageVersionUpdater func(*longhorn.EngineImage) error
 }
@@ -86,7 +85,6 @@ func NewEngineImageController(
 
 		ds: ds,
 
-		nowHandler:                util.Now,
 		engineBinaryChecker:       types.EngineBinaryExistOnHostForImage,
 		engineImageVersionUpdater: updateEngineImageVersion,
 	}
@@ -119,6 +117,16 @@ func NewEngineImageController(
 	}
 	ic.cacheSyncs = append(ic.cacheSyncs, ds.DaemonSetInformer.HasSynced)
 
+	// The volumes of the engine upgrade policies are upgraded when syncing the default engine image
+	if _, err = ds.EngineUpgradePolicyInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ic.enqueueDefaultEngineImage,
+		UpdateFunc: func(old, cur interface{}) { ic.enqueueDefaultEngineImage(cur) },
+		DeleteFunc: ic.enqueueDefaultEngineImage,
+	}); err != nil {
+		return nil, err
+	}
+	ic.cacheSyncs = append(ic.cacheSyncs, ds.EngineUpgradePolicyInformer.HasSynced)
+
 	return ic, nil
 }
 
@@ -313,10 +321,6 @@ func (ic *EngineImageController) syncEngineImage(key string) (err error) {
 		return errors.Wrapf(err, "failed to update RefCount for engine image %v(%v)", engineImage.Name, engineImage.Spec.Image)
 	}
 
-	if err := ic.cleanupExpiredEngineImage(engineImage); err != nil {
-		return err
-	}
-
 	if err := ic.syncNodeDeploymentMap(engineImage); err != nil {
 		return err
 	}
@@ -425,7 +429,13 @@ func (ic *EngineImageController) handleAutoUpgradeEngineImageToDefaultEngineImag
 	if err != nil {
 		return err
 	}
-	if concurrentAutomaticEngineUpgradePerNodeLimit <= 0 {
+
+	policies, err := ic.ds.ListEngineUpgradePoliciesRO()
+	if err != nil {
+		return err
+	}
+
+	if concurrentAutomaticEngineUpgradePerNodeLimit <= 0 && len(policies) == 0 {
 		return nil
 	}
 
@@ -437,28 +447,192 @@ func (ic *EngineImageController) handleAutoUpgradeEngineImageToDefaultEngineImag
 
 	candidates, inProgress := ic.getVolumesForEngineImageUpgrading(volumes, defaultEngineImageResource)
 
-	limitedCandidates := limitAutomaticEngineUpgradePerNode(candidates, inProgress, int(concurrentAutomaticEngineUpgradePerNodeLimit))
+	// The volumes matching an engine upgrade policy are upgraded as the policy specifies, regardless of the
+	// concurrent automatic engine upgrade per node limit.
+	policyVolumes := map[string][]*longhorn.Volume{}
+	volumePolicies := map[string]string{}
+	for _, v := range volumes {
+		if policy := getEngineUpgradePolicyForVolume(policies, v); policy != nil {
+			policyVolumes[policy.Name] = append(policyVolumes[policy.Name], v)
+			volumePolicies[v.Name] = policy.Name
+		}
+	}
+
+	// The candidates limited per node are keyed by the node names, and the ones limited per policy are kept apart in
+	// limitedPolicyCandidates keyed by the policy names
+	limitedCandidates := map[string][]*longhorn.Volume{}
+	if concurrentAutomaticEngineUpgradePerNodeLimit > 0 {
+		limitedCandidates = limitAutomaticEngineUpgradePerNode(
+			excludeEngineUpgradePolicyVolumes(candidates, volumePolicies),
+			excludeEngineUpgradePolicyVolumes(inProgress, volumePolicies),
+			int(concurrentAutomaticEngineUpgradePerNodeLimit))
+	}
 
-	for _, vs := range limitedCandidates {
+	policyCandidates := map[string][]*longhorn.Volume{}
+	for _, vs := range candidates {
 		for _, v := range vs {
-			ic.logger.WithFields(logrus.Fields{"volume": v.Name, "image": v.Spec.Image}).Infof("Upgrading volume engine image to the default engine image %v automatically", defaultEngineImage)
-
-			if types.IsDataEngineV2(v.Spec.DataEngine) {
-				ic.logger.WithFields(logrus.Fields{"volume": v.Name, "image": v.Spec.Image}).Infof("Skip upgrading volume engine image to the default engine image %v automatically since it is using v2 data engine", defaultEngineImage)
-				continue
+			if policyName, ok := volumePolicies[v.Name]; ok {
+				policyCandidates[policyName] = append(policyCandidates[policyName], v)
 			}
+		}
+	}
 
-			v.Spec.Image = defaultEngineImage
-			_, err = ic.ds.UpdateVolume(v)
-			if err != nil {
-				return err
-			}
+	limitedPolicyCandidates := map[string][]*longhorn.Volume{}
+	now := ic.clock.Now()
+	var nextWindowTransition time.Time
+	for _, policy := range policies {
+		if !policy.DeletionTimestamp.IsZero() {
+			continue
+		}
+		log := ic.logger.WithField("engineUpgradePolicy", policy.Name)
+
+		inWindow, transition, err := isInEngineUpgradeMaintenanceWindow(policy.Spec.MaintenanceWindow, now)
+		if err != nil {
+			log.WithError(err).Warn("Failed to check the maintenance window of engine upgrade policy")
+			continue
+		}
+		if !transition.IsZero() && (nextWindowTransition.IsZero() || transition.Before(nextWindowTransition)) {
+			nextWindowTransition = transition
+		}
+
+		if inWindow {
+			limitedPolicyCandidates[policy.Name] = limitEngineUpgradePolicyCandidates(policyCandidates[policy.Name], policyVolumes[policy.Name], policy.Spec.MaxConcurrent)
+		}
+
+		if err := ic.updateEngineUpgradePolicyStatus(policy, policyVolumes[policy.Name], defaultEngineImage, inWindow); err != nil {
+			log.WithError(err).Warn("Failed to update engine upgrade policy status")
+		}
+	}
+	if !nextWindowTransition.IsZero() {
+		ic.enqueueEngineImageAfter(defaultEngineImageResource, nextWindowTransition.Sub(now))
+	}
+
+	upgradeCandidates := []*longhorn.Volume{}
+	for _, vs := range limitedCandidates {
+		upgradeCandidates = append(upgradeCandidates, vs...)
+	}
+	for _, vs := range limitedPolicyCandidates {
+		upgradeCandidates = append(upgradeCandidates, vs...)
+	}
+
+	for _, v := range upgradeCandidates {
+		ic.logger.WithFields(logrus.Fields{"volume": v.Name, "image": v.Spec.Image}).Infof("Upgrading volume engine image to the default engine image %v automatically", defaultEngineImage)
+
+		if types.IsDataEngineV2(v.Spec.DataEngine) {
+			ic.logger.WithFields(logrus.Fields{"volume": v.Name, "image": v.Spec.Image}).Infof("Skip upgrading volume engine image to the default engine image %v automatically since it is using v2 data engine", defaultEngineImage)
+			continue
+		}
+
+		v.Spec.Image = defaultEngineImage
+		_, err = ic.ds.UpdateVolume(v)
+		if err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+func (ic *EngineImageController) updateEngineUpgradePolicyStatus(policy *longhorn.EngineUpgradePolicy, volumes []*longhorn.Volume, defaultEngineImage string, inWindow bool) error {
+	status := longhorn.EngineUpgradePolicyStatus{
+		InMaintenanceWindow: inWindow,
+		PendingVolumes:      []string{},
+		UpgradingVolumes:    []string{},
+	}
+	for _, v := range volumes {
+		if v.Spec.Image != v.Status.CurrentImage {
+			status.UpgradingVolumes = append(status.UpgradingVolumes, v.Name)
+		} else if v.Spec.Image != defaultEngineImage {
+			status.PendingVolumes = append(status.PendingVolumes, v.Name)
+		}
+	}
+	sort.Strings(status.PendingVolumes)
+	sort.Strings(status.UpgradingVolumes)
+
+	if reflect.DeepEqual(policy.Status, status) {
+		return nil
+	}
+	policy = policy.DeepCopy()
+	policy.Status = status
+	if _, err := ic.ds.UpdateEngineUpgradePolicyStatus(policy); err != nil && !apierrors.IsConflict(errors.Cause(err)) {
+		return err
+	}
+	return nil
+}
+
+// getEngineUpgradePolicyForVolume returns the engine upgrade policy the volume follows, which is the first one in the
+// order of their names matching the volume labels. The v2 volumes never follow a policy since their engine image cannot
+// be upgraded automatically.
+func getEngineUpgradePolicyForVolume(policies map[string]*longhorn.EngineUpgradePolicy, v *longhorn.Volume) *longhorn.EngineUpgradePolicy {
+	if types.IsDataEngineV2(v.Spec.DataEngine) {
+		return nil
+	}
+
+	var matched *longhorn.EngineUpgradePolicy
+	for _, policy := range policies {
+		if !policy.DeletionTimestamp.IsZero() || !isMaintenancePolicySelectorMatched(policy.Spec.Selector, v.Labels) {
+			continue
+		}
+		if matched == nil || policy.Name < matched.Name {
+			matched = policy
+		}
+	}
+	return matched
+}
+
+// isInEngineUpgradeMaintenanceWindow returns whether the time is in the maintenance window, and the time the window
+// opens or closes next. The cron of the window is in UTC, regardless of the time zone of the manager. A nil window is
+// always open.
+func isInEngineUpgradeMaintenanceWindow(window *longhorn.EngineUpgradeMaintenanceWindow, now time.Time) (bool, time.Time, error) {
+	if window == nil {
+		return true, time.Time{}, nil
+	}
+	now = now.UTC()
+
+	schedule, err := cron.ParseStandard(window.Cron)
+	if err != nil {
+		return false, time.Time{}, errors.Wrapf(err, "invalid maintenance window cron %v", window.Cron)
+	}
+	duration := time.Duration(window.Duration) * time.Minute
+
+	// The window is open if it started within the last duration
+	start := schedule.Next(now.Add(-duration))
+	if start.After(now) {
+		return false, start, nil
+	}
+	return true, start.Add(duration), nil
+}
+
+func excludeEngineUpgradePolicyVolumes(volumes map[string][]*longhorn.Volume, volumePolicies map[string]string) map[string][]*longhorn.Volume {
+	excluded := map[string][]*longhorn.Volume{}
+	for node, vs := range volumes {
+		for _, v := range vs {
+			if _, ok := volumePolicies[v.Name]; !ok {
+				excluded[node] = append(excluded[node], v)
+			}
+		}
+	}
+	return excluded
+}
+
+// limitEngineUpgradePolicyCandidates returns the candidates to be upgraded without exceeding the max concurrent
+// upgrades of the policy, in the order of the volume names.
+func limitEngineUpgradePolicyCandidates(candidates, volumes []*longhorn.Volume, maxConcurrent int) []*longhorn.Volume {
+	upgrading := 0
+	for _, v := range volumes {
+		if v.Spec.Image != v.Status.CurrentImage {
+			upgrading++
+		}
+	}
+	if upgrading >= maxConcurrent {
+		return nil
+	}
+
+	sorted := append([]*longhorn.Volume{}, candidates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted[:util.MinInt(maxConcurrent-upgrading, len(sorted))]
+}
+
 func limitAutomaticEngineUpgradePerNode(candidates, inProgress map[string][]*longhorn.Volume, maxLimit int) (limitedCandidates map[string][]*longhorn.Volume) {
 	limitedCandidates = make(map[string][]*longhorn.Volume)
 	for node := range candidates {
@@ -646,7 +820,7 @@ func (ic *EngineImageController) updateEngineImageRefCount(ei *longhorn.EngineIm
 	ei.Status.RefCount = refCount
 	if ei.Status.RefCount == 0 {
 		if ei.Status.NoRefSince == "" {
-			ei.Status.NoRefSince = ic.nowHandler()
+			ei.Status.NoRefSince = ic.now()
 		}
 	} else {
 		ei.Status.NoRefSince = ""
@@ -655,46 +829,36 @@ func (ic *EngineImageController) updateEngineImageRefCount(ei *longhorn.EngineIm
 
 }
 
-func (ic *EngineImageController) cleanupExpiredEngineImage(ei *longhorn.EngineImage) (err error) {
-	defer func() {
-		err = errors.Wrapf(err, "cannot cleanup engine image %v (%v)", ei.Name, ei.Spec.Image)
-	}()
-
-	if ei.Status.RefCount != 0 {
-		return nil
-	}
-	if ei.Status.NoRefSince == "" {
-		return nil
+func (ic *EngineImageController) enqueueEngineImage(obj interface{}) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %#v: %v", obj, err))
+		return
 	}
-	if util.TimestampAfterTimeout(ei.Status.NoRefSince, ExpiredEngineImageTimeout) {
-		defaultEngineImageValue, err := ic.ds.GetSettingValueExisted(types.SettingNameDefaultEngineImage)
-		if err != nil {
-			return err
-		}
-		// Don't delete the default image
-		if ei.Spec.Image == defaultEngineImageValue {
-			return nil
-		}
 
-		log := getLoggerForEngineImage(ic.logger, ei)
-		log.Info("Cleaning engine image since it expired")
-		// TODO: Need to consider if the engine image can be removed in engine image controller
-		if err := ic.ds.DeleteEngineImage(ei.Name); err != nil {
-			return err
-		}
-		return nil
-	}
-	return nil
+	ic.queue.Add(key)
 }
 
-func (ic *EngineImageController) enqueueEngineImage(obj interface{}) {
+func (ic *EngineImageController) enqueueEngineImageAfter(obj interface{}, duration time.Duration) {
 	key, err := controller.KeyFunc(obj)
 	if err != nil {
-		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %#v: %v", obj, err))
+		utilruntime.HandleError(fmt.Errorf("enqueueEngineImageAfter: couldn't get key for object %#v: %v", obj, err))
 		return
 	}
 
-	ic.queue.Add(key)
+	ic.queue.AddAfter(key, duration)
+}
+
+func (ic *EngineImageController) enqueueDefaultEngineImage(obj interface{}) {
+	defaultEngineImage, err := ic.ds.GetSettingValueExisted(types.SettingNameDefaultEngineImage)
+	if err != nil {
+		return
+	}
+	engineImage, err := ic.ds.GetEngineImage(types.GetEngineImageChecksumName(defaultEngineImage))
+	if err != nil {
+		return
+	}
+	ic.enqueueEngineImage(engineImage)
 }
 
 func (ic *EngineImageController) enqueueVolumes(volumes ...interface{}) {
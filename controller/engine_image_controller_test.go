@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -68,7 +69,7 @@ func newTestEngineImageController(lhClient *lhfake.Clientset, kubeClient *fake.C
 	for index := range ic.cacheSyncs {
 		ic.cacheSyncs[index] = alwaysReady
 	}
-	ic.nowHandler = getTestNow
+	ic.SetClock(getTestClock())
 	ic.engineBinaryChecker = fakeEngineBinaryChecker
 	ic.engineImageVersionUpdater = fakeEngineImageUpdater
 
@@ -294,3 +295,184 @@ func (s *TestSuite) TestEngineImage(c *C) {
 		}
 	}
 }
+
+func (s *TestSuite) TestEngineUpgradePolicy(c *C) {
+	// Every Saturday from 02:00 to 04:00
+	window := &longhorn.EngineUpgradeMaintenanceWindow{Cron: "0 2 * * 6", Duration: 120}
+	saturday := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	inWindow, transition, err := isInEngineUpgradeMaintenanceWindow(window, saturday.Add(time.Hour))
+	c.Assert(err, IsNil)
+	c.Assert(inWindow, Equals, false)
+	c.Assert(transition.Equal(saturday.Add(2*time.Hour)), Equals, true)
+
+	inWindow, transition, err = isInEngineUpgradeMaintenanceWindow(window, saturday.Add(3*time.Hour))
+	c.Assert(err, IsNil)
+	c.Assert(inWindow, Equals, true)
+	c.Assert(transition.Equal(saturday.Add(4*time.Hour)), Equals, true)
+
+	inWindow, transition, err = isInEngineUpgradeMaintenanceWindow(window, saturday.Add(5*time.Hour))
+	c.Assert(err, IsNil)
+	c.Assert(inWindow, Equals, false)
+	c.Assert(transition.Equal(saturday.Add(7*24*time.Hour+2*time.Hour)), Equals, true)
+
+	// The window is in UTC, so Saturday 03:00 in UTC+8 is before the window opens
+	inWindow, transition, err = isInEngineUpgradeMaintenanceWindow(window, time.Date(2024, time.June, 1, 3, 0, 0, 0, time.FixedZone("UTC+8", 8*60*60)))
+	c.Assert(err, IsNil)
+	c.Assert(inWindow, Equals, false)
+	c.Assert(transition.Equal(saturday.Add(2*time.Hour)), Equals, true)
+
+	inWindow, transition, err = isInEngineUpgradeMaintenanceWindow(nil, saturday)
+	c.Assert(err, IsNil)
+	c.Assert(inWindow, Equals, true)
+	c.Assert(transition.IsZero(), Equals, true)
+
+	_, _, err = isInEngineUpgradeMaintenanceWindow(&longhorn.EngineUpgradeMaintenanceWindow{Cron: "invalid", Duration: 1}, saturday)
+	c.Assert(err, NotNil)
+
+	policies := map[string]*longhorn.EngineUpgradePolicy{
+		"all": {
+			ObjectMeta: metav1.ObjectMeta{Name: "all"},
+		},
+		"a-prod": {
+			ObjectMeta: metav1.ObjectMeta{Name: "a-prod"},
+			Spec:       longhorn.EngineUpgradePolicySpec{Selector: map[string]string{"env": "prod"}},
+		},
+	}
+	prod := &longhorn.Volume{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"env": "prod"}}}
+	dev := &longhorn.Volume{ObjectMeta: metav1.ObjectMeta{Name: "dev", Labels: map[string]string{"env": "dev"}}}
+	v2 := &longhorn.Volume{
+		ObjectMeta: metav1.ObjectMeta{Name: "v2", Labels: map[string]string{"env": "prod"}},
+		Spec:       longhorn.VolumeSpec{DataEngine: longhorn.DataEngineTypeV2},
+	}
+	c.Assert(getEngineUpgradePolicyForVolume(policies, prod).Name, Equals, "a-prod")
+	c.Assert(getEngineUpgradePolicyForVolume(policies, dev).Name, Equals, "all")
+	c.Assert(getEngineUpgradePolicyForVolume(policies, v2), IsNil)
+
+	newVolume := func(name, specImage, currentImage string) *longhorn.Volume {
+		return &longhorn.Volume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       longhorn.VolumeSpec{Image: specImage},
+			Status:     longhorn.VolumeStatus{CurrentImage: currentImage},
+		}
+	}
+	upgrading := newVolume("upgrading", "new", "old")
+	candidate1 := newVolume("candidate-1", "old", "old")
+	candidate2 := newVolume("candidate-2", "old", "old")
+	candidates := []*longhorn.Volume{candidate2, candidate1}
+	volumes := []*longhorn.Volume{upgrading, candidate1, candidate2}
+
+	c.Assert(limitEngineUpgradePolicyCandidates(candidates, volumes, 1), HasLen, 0)
+	limited := limitEngineUpgradePolicyCandidates(candidates, volumes, 2)
+	c.Assert(limited, HasLen, 1)
+	c.Assert(limited[0].Name, Equals, "candidate-1")
+	c.Assert(limitEngineUpgradePolicyCandidates(candidates, volumes, 5), HasLen, 2)
+}
+
+func (s *TestSuite) TestReconcileEngineUpgradePolicy(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+	nodeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Nodes().Informer().GetIndexer()
+	settingIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Settings().Informer().GetIndexer()
+	eiIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().EngineImages().Informer().GetIndexer()
+	vIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Volumes().Informer().GetIndexer()
+	rIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Replicas().Informer().GetIndexer()
+	policyIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().EngineUpgradePolicies().Informer().GetIndexer()
+
+	ic, err := newTestEngineImageController(lhClient, kubeClient, extensionsClient, informerFactories)
+	c.Assert(err, IsNil)
+
+	for name, value := range map[types.SettingName]string{
+		types.SettingNameDefaultEngineImage:                           TestUpgradedEngineImage,
+		types.SettingNameConcurrentAutomaticEngineUpgradePerNodeLimit: "1",
+	} {
+		setting, err := lhClient.LonghornV1beta2().Settings(TestNamespace).Create(context.TODO(), newSetting(string(name), value), metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(settingIndexer.Add(setting), IsNil)
+	}
+
+	node, err := lhClient.LonghornV1beta2().Nodes(TestNamespace).Create(context.TODO(), newNode(TestNode1, TestNamespace, true, longhorn.ConditionStatusTrue, ""), metav1.CreateOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(nodeIndexer.Add(node), IsNil)
+
+	for _, image := range []string{TestEngineImage, TestUpgradedEngineImage} {
+		ei := newEngineImage(image, longhorn.EngineImageStateDeployed)
+		ei.Status.NodeDeploymentMap = map[string]bool{TestNode1: true}
+		ei, err = lhClient.LonghornV1beta2().EngineImages(TestNamespace).Create(context.TODO(), ei, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(eiIndexer.Add(ei), IsNil)
+	}
+
+	// The test clock is Friday 00:00 UTC. The policy named after the node is in its window, and the other one isn't.
+	policies := []*longhorn.EngineUpgradePolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: TestNode1, Namespace: TestNamespace},
+			Spec: longhorn.EngineUpgradePolicySpec{
+				Selector:          map[string]string{"policy": "open"},
+				MaintenanceWindow: &longhorn.EngineUpgradeMaintenanceWindow{Cron: "0 0 * * 5", Duration: 60},
+				MaxConcurrent:     1,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "closed", Namespace: TestNamespace},
+			Spec: longhorn.EngineUpgradePolicySpec{
+				Selector:          map[string]string{"policy": "closed"},
+				MaintenanceWindow: &longhorn.EngineUpgradeMaintenanceWindow{Cron: "0 2 * * 6", Duration: 120},
+				MaxConcurrent:     1,
+			},
+		},
+	}
+	for _, policy := range policies {
+		policy, err = lhClient.LonghornV1beta2().EngineUpgradePolicies(TestNamespace).Create(context.TODO(), policy, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(policyIndexer.Add(policy), IsNil)
+	}
+
+	volumeLabels := map[string]map[string]string{
+		"open-1":   {"policy": "open"},
+		"open-2":   {"policy": "open"},
+		"closed-1": {"policy": "closed"},
+		"other-1":  {},
+		"other-2":  {},
+	}
+	for name, labels := range volumeLabels {
+		v := newVolume(name, 1)
+		v.Namespace = TestNamespace
+		v.Labels = labels
+		v.Status.State = longhorn.VolumeStateDetached
+		v.Status.CurrentImage = TestEngineImage
+		v, err = lhClient.LonghornV1beta2().Volumes(TestNamespace).Create(context.TODO(), v, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(vIndexer.Add(v), IsNil)
+
+		r := newReplicaForVolume(v, newEngineForVolume(v), TestNode1, TestDiskID1)
+		r.Namespace = TestNamespace
+		c.Assert(rIndexer.Add(r), IsNil)
+	}
+
+	c.Assert(ic.handleAutoUpgradeEngineImageToDefaultEngineImage(TestUpgradedEngineImage), IsNil)
+
+	upgraded := map[string]bool{}
+	for name := range volumeLabels {
+		v, err := lhClient.LonghornV1beta2().Volumes(TestNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+		upgraded[name] = v.Spec.Image == TestUpgradedEngineImage
+	}
+	// The policy and the per node limit are applied separately, even if the policy is named after the node
+	c.Assert(upgraded["open-1"], Equals, true)
+	c.Assert(upgraded["open-2"], Equals, false)
+	c.Assert(upgraded["closed-1"], Equals, false)
+	c.Assert(upgraded["other-1"] != upgraded["other-2"], Equals, true)
+
+	policy, err := lhClient.LonghornV1beta2().EngineUpgradePolicies(TestNamespace).Get(context.TODO(), TestNode1, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(policy.Status.InMaintenanceWindow, Equals, true)
+	c.Assert(policy.Status.PendingVolumes, DeepEquals, []string{"open-1", "open-2"})
+	policy, err = lhClient.LonghornV1beta2().EngineUpgradePolicies(TestNamespace).Get(context.TODO(), "closed", metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(policy.Status.InMaintenanceWindow, Equals, false)
+	c.Assert(policy.Status.PendingVolumes, DeepEquals, []string{"closed-1"})
+}
@@ -0,0 +1,287 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/longhorn/longhorn-manager/constant"
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// EngineImageGarbageCollectionController deletes the engine images, together with their daemonsets, that haven't
+// been used by any volume, engine or replica for the wait interval. It's disabled by default. The default engine image
+// and the most recently used engine images up to the keep count are never deleted.
+type EngineImageGarbageCollectionController struct {
+	*baseController
+
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the controller
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds         *datastore.DataStore
+	cacheSyncs []cache.InformerSynced
+}
+
+func NewEngineImageGarbageCollectionController(
+	logger logrus.FieldLogger,
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	kubeClient clientset.Interface,
+	controllerID string,
+	namespace string,
+) (*EngineImageGarbageCollectionController, error) {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	igc := &EngineImageGarbageCollectionController{
+		baseController: newBaseController("longhorn-engine-image-garbage-collection", logger),
+
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		ds: ds,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-engine-image-garbage-collection-controller"}),
+	}
+
+	var err error
+	// The keep count depends on all the engine images, so any change re-evaluates all of them
+	if _, err = ds.EngineImageInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    igc.enqueueAllEngineImages,
+		UpdateFunc: func(old, cur interface{}) { igc.enqueueAllEngineImages(cur) },
+		DeleteFunc: igc.enqueueAllEngineImages,
+	}); err != nil {
+		return nil, err
+	}
+	igc.cacheSyncs = append(igc.cacheSyncs, ds.EngineImageInformer.HasSynced)
+
+	if _, err = ds.SettingInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: isSettingEngineImageGarbageCollection,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    igc.enqueueAllEngineImages,
+			UpdateFunc: func(old, cur interface{}) { igc.enqueueAllEngineImages(cur) },
+		},
+	}); err != nil {
+		return nil, err
+	}
+	igc.cacheSyncs = append(igc.cacheSyncs, ds.SettingInformer.HasSynced)
+
+	return igc, nil
+}
+
+func isSettingEngineImageGarbageCollection(obj interface{}) bool {
+	setting, ok := obj.(*longhorn.Setting)
+	if !ok {
+		return false
+	}
+	switch types.SettingName(setting.Name) {
+	case types.SettingNameEngineImageGarbageCollection,
+		types.SettingNameEngineImageGarbageCollectionWaitInterval,
+		types.SettingNameEngineImageGarbageCollectionKeepCount:
+		return true
+	}
+	return false
+}
+
+func (igc *EngineImageGarbageCollectionController) enqueueEngineImage(obj interface{}) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %#v: %v", obj, err))
+		return
+	}
+
+	igc.queue.Add(key)
+}
+
+func (igc *EngineImageGarbageCollectionController) enqueueEngineImageAfter(obj interface{}, duration time.Duration) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("enqueueEngineImageAfter: couldn't get key for object %#v: %v", obj, err))
+		return
+	}
+
+	igc.queue.AddAfter(key, duration)
+}
+
+func (igc *EngineImageGarbageCollectionController) enqueueAllEngineImages(obj interface{}) {
+	engineImages, err := igc.ds.ListEngineImages()
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to list engine images: %v", err))
+		return
+	}
+	for _, ei := range engineImages {
+		igc.enqueueEngineImage(ei)
+	}
+}
+
+func (igc *EngineImageGarbageCollectionController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer igc.queue.ShutDown()
+
+	igc.logger.Info("Starting Longhorn engine image garbage collection controller")
+	defer igc.logger.Info("Shut down Longhorn engine image garbage collection controller")
+
+	if !cache.WaitForNamedCacheSync(igc.name, stopCh, igc.cacheSyncs...) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(igc.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (igc *EngineImageGarbageCollectionController) worker() {
+	for igc.processNextWorkItem() {
+	}
+}
+
+func (igc *EngineImageGarbageCollectionController) processNextWorkItem() bool {
+	key, quit := igc.queue.Get()
+	if quit {
+		return false
+	}
+	defer igc.queue.Done(key)
+	err := igc.syncHandler(key.(string))
+	igc.handleErr(err, key)
+	return true
+}
+
+func (igc *EngineImageGarbageCollectionController) handleErr(err error, key interface{}) {
+	if err == nil {
+		igc.queue.Forget(key)
+		return
+	}
+
+	log := igc.logger.WithField("EngineImage", key)
+	handleReconcileErrorLogging(log, err, "Failed to sync Longhorn engine image")
+	igc.queue.AddRateLimited(key)
+}
+
+func (igc *EngineImageGarbageCollectionController) syncHandler(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "%v: failed to sync engine image %v", igc.name, key)
+	}()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != igc.namespace {
+		return nil
+	}
+	return igc.reconcile(name)
+}
+
+func (igc *EngineImageGarbageCollectionController) reconcile(name string) error {
+	enabled, err := igc.ds.GetSettingAsBool(types.SettingNameEngineImageGarbageCollection)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+
+	engineImages, err := igc.ds.ListEngineImages()
+	if err != nil {
+		return err
+	}
+	ei, ok := engineImages[name]
+	if !ok || ei.Status.OwnerID != igc.controllerID || ei.DeletionTimestamp != nil {
+		return nil
+	}
+
+	defaultEngineImage, err := igc.ds.GetSettingValueExisted(types.SettingNameDefaultEngineImage)
+	if err != nil {
+		return err
+	}
+	keepCount, err := igc.ds.GetSettingAsInt(types.SettingNameEngineImageGarbageCollectionKeepCount)
+	if err != nil {
+		return err
+	}
+	waitInterval, err := igc.ds.GetSettingAsInt(types.SettingNameEngineImageGarbageCollectionWaitInterval)
+	if err != nil {
+		return err
+	}
+
+	if getKeptUnusedEngineImages(engineImages, defaultEngineImage, int(keepCount))[name] || !isEngineImageUnused(ei, defaultEngineImage) {
+		return nil
+	}
+
+	noRefSince, err := util.ParseTime(ei.Status.NoRefSince)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the time engine image %v became unused", name)
+	}
+	if waitDuration := noRefSince.Add(time.Duration(waitInterval) * time.Minute).Sub(igc.clock.Now()); waitDuration > 0 {
+		igc.enqueueEngineImageAfter(ei, waitDuration)
+		return nil
+	}
+
+	log := getLoggerForEngineImage(igc.logger, ei)
+	dsName := types.GetDaemonSetNameFromEngineImageName(ei.Name)
+	if err := igc.ds.DeleteDaemonSet(dsName); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete daemonset %v of engine image %v", dsName, name)
+	}
+	if err := igc.ds.DeleteEngineImage(name); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	log.Infof("Deleted engine image since it has been unused since %v", ei.Status.NoRefSince)
+	igc.eventRecorder.Eventf(ei, corev1.EventTypeNormal, constant.EventReasonDelete,
+		"Deleted engine image %v (%v) since it has been unused since %v", ei.Name, ei.Spec.Image, ei.Status.NoRefSince)
+	return nil
+}
+
+func isEngineImageUnused(ei *longhorn.EngineImage, defaultEngineImage string) bool {
+	return ei.Spec.Image != defaultEngineImage && ei.Status.RefCount == 0 && ei.Status.NoRefSince != ""
+}
+
+// getKeptUnusedEngineImages returns the names of the unused engine images kept by the garbage collection, which are
+// the ones that became unused most recently.
+func getKeptUnusedEngineImages(engineImages map[string]*longhorn.EngineImage, defaultEngineImage string, keepCount int) map[string]bool {
+	unused := []*longhorn.EngineImage{}
+	for _, ei := range engineImages {
+		if isEngineImageUnused(ei, defaultEngineImage) {
+			unused = append(unused, ei)
+		}
+	}
+	// The timestamps are in RFC3339, so they can be compared as strings
+	sort.Slice(unused, func(i, j int) bool {
+		if unused[i].Status.NoRefSince != unused[j].Status.NoRefSince {
+			return unused[i].Status.NoRefSince > unused[j].Status.NoRefSince
+		}
+		return unused[i].Name < unused[j].Name
+	})
+
+	kept := map[string]bool{}
+	for i := 0; i < keepCount && i < len(unused); i++ {
+		kept[unused[i].Name] = true
+	}
+	return kept
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestGetKeptUnusedEngineImages(c *C) {
+	newEngineImage := func(name string, refCount int, noRefSince string) *longhorn.EngineImage {
+		return &longhorn.EngineImage{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       longhorn.EngineImageSpec{Image: "longhornio/longhorn-engine:" + name},
+			Status:     longhorn.EngineImageStatus{RefCount: refCount, NoRefSince: noRefSince},
+		}
+	}
+	engineImages := map[string]*longhorn.EngineImage{
+		"default": newEngineImage("default", 0, "2024-01-05T00:00:00Z"),
+		"used":    newEngineImage("used", 2, ""),
+		"ei-1":    newEngineImage("ei-1", 0, "2024-01-01T00:00:00Z"),
+		"ei-2":    newEngineImage("ei-2", 0, "2024-01-03T00:00:00Z"),
+		"ei-3":    newEngineImage("ei-3", 0, "2024-01-03T00:00:00Z"),
+		"ei-4":    newEngineImage("ei-4", 0, "2024-01-02T00:00:00Z"),
+	}
+	defaultEngineImage := engineImages["default"].Spec.Image
+
+	c.Assert(isEngineImageUnused(engineImages["default"], defaultEngineImage), Equals, false)
+	c.Assert(isEngineImageUnused(engineImages["used"], defaultEngineImage), Equals, false)
+	c.Assert(isEngineImageUnused(engineImages["ei-1"], defaultEngineImage), Equals, true)
+
+	c.Assert(getKeptUnusedEngineImages(engineImages, defaultEngineImage, 0), HasLen, 0)
+	// the engine images became unused at the same time are ordered by the names
+	c.Assert(getKeptUnusedEngineImages(engineImages, defaultEngineImage, 1), DeepEquals, map[string]bool{"ei-2": true})
+	c.Assert(getKeptUnusedEngineImages(engineImages, defaultEngineImage, 3), DeepEquals, map[string]bool{"ei-2": true, "ei-3": true, "ei-4": true})
+	c.Assert(getKeptUnusedEngineImages(engineImages, defaultEngineImage, 10), HasLen, 4)
+}
+
+func (s *TestSuite) TestEngineImageGarbageCollectionDefaults(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+	settingIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Settings().Informer().GetIndexer()
+	eiIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().EngineImages().Informer().GetIndexer()
+
+	ds := datastore.NewDataStore(TestNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+	igc, err := NewEngineImageGarbageCollectionController(logrus.StandardLogger(), ds, scheme.Scheme, kubeClient, TestNode1, TestNamespace)
+	c.Assert(err, IsNil)
+	igc.eventRecorder = record.NewFakeRecorder(100)
+	clock := getTestClock()
+	igc.SetClock(clock)
+
+	// Only the default engine image setting is set, the garbage collection settings are left with the defaults
+	setting := newSetting(string(types.SettingNameDefaultEngineImage), TestEngineImage)
+	c.Assert(settingIndexer.Add(setting), IsNil)
+
+	stale := newEngineImage("longhornio/longhorn-engine:stale", longhorn.EngineImageStateDeployed)
+	stale.Status.NoRefSince = clock.Now().Add(-3 * time.Hour).UTC().Format(time.RFC3339)
+	previous := newEngineImage("longhornio/longhorn-engine:previous", longhorn.EngineImageStateDeployed)
+	previous.Status.NoRefSince = clock.Now().Add(-90 * time.Minute).UTC().Format(time.RFC3339)
+	defaultEI := newEngineImage(TestEngineImage, longhorn.EngineImageStateDeployed)
+	defaultEI.Status.NoRefSince = clock.Now().Add(-4 * time.Hour).UTC().Format(time.RFC3339)
+	engineImages := []*longhorn.EngineImage{stale, previous, defaultEI}
+	for _, ei := range engineImages {
+		ei, err = lhClient.LonghornV1beta2().EngineImages(TestNamespace).Create(context.TODO(), ei, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(eiIndexer.Add(ei), IsNil)
+	}
+
+	// The garbage collection is opt-in, no engine image is deleted by default
+	for _, ei := range engineImages {
+		c.Assert(igc.reconcile(ei.Name), IsNil)
+	}
+	for _, ei := range engineImages {
+		_, err = lhClient.LonghornV1beta2().EngineImages(TestNamespace).Get(context.TODO(), ei.Name, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+	}
+
+	// Once enabled, the most recently used unused engine image is kept for rolling back by the default keep count
+	setting = newSetting(string(types.SettingNameEngineImageGarbageCollection), "true")
+	c.Assert(settingIndexer.Add(setting), IsNil)
+	for _, ei := range engineImages {
+		c.Assert(igc.reconcile(ei.Name), IsNil)
+	}
+	_, err = lhClient.LonghornV1beta2().EngineImages(TestNamespace).Get(context.TODO(), stale.Name, metav1.GetOptions{})
+	c.Assert(apierrors.IsNotFound(err), Equals, true)
+	_, err = lhClient.LonghornV1beta2().EngineImages(TestNamespace).Get(context.TODO(), previous.Name, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+	_, err = lhClient.LonghornV1beta2().EngineImages(TestNamespace).Get(context.TODO(), defaultEI.Name, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+}
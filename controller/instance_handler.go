@@ -326,11 +326,20 @@ func (h *InstanceHandler) ReconcileInstanceState(obj interface{}, spec *longhorn
 			break
 		}
 
-		if i, exists := instances[instanceName]; exists && i.Status.State == longhorn.InstanceStateRunning {
+		i, exists := instances[instanceName]
+		if exists && i.Status.State == longhorn.InstanceStateRunning {
 			status.Started = true
 			break
 		}
 
+		// The instance lost with the restart of the instance manager is started again as it is, see RebindRequestedAt
+		if !exists && spec.RebindRequestedAt != "" && status.Started &&
+			im.Status.CurrentState == longhorn.InstanceManagerStateRunning && im.DeletionTimestamp == nil {
+			log.Warnf("Starting instance %v lost with the restart of instance manager %v again", instanceName, im.Name)
+			status.Started = false
+			status.CurrentState = longhorn.InstanceStateStopped
+		}
+
 		// there is a delay between createInstance() invocation and InstanceManager update,
 		// createInstance() may be called multiple times.
 		if status.CurrentState != longhorn.InstanceStateStopped {
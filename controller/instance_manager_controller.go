@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -60,10 +62,15 @@ type InstanceManagerController struct {
 	instanceManagerMonitorMutex *sync.Mutex
 	instanceManagerMonitorMap   map[string]chan struct{}
 
+	// the last failures of resizing the instance manager pods, which are logged only when they change
+	podResizeFailureMutex *sync.Mutex
+	podResizeFailureMap   map[string]string
+
 	proxyConnCounter util.Counter
 
 	// for unit test
-	versionUpdater func(*longhorn.InstanceManager) error
+	versionUpdater            func(*longhorn.InstanceManager) error
+	circuitBreakerStateGetter func(name, ip string) engineapi.InstanceManagerCircuitBreakerState
 }
 
 type InstanceManagerMonitor struct {
@@ -135,9 +142,13 @@ func NewInstanceManagerController(
 		instanceManagerMonitorMutex: &sync.Mutex{},
 		instanceManagerMonitorMap:   map[string]chan struct{}{},
 
+		podResizeFailureMutex: &sync.Mutex{},
+		podResizeFailureMap:   map[string]string{},
+
 		proxyConnCounter: proxyConnCounter,
 
-		versionUpdater: updateInstanceManagerVersion,
+		versionUpdater:            updateInstanceManagerVersion,
+		circuitBreakerStateGetter: engineapi.GetInstanceManagerCircuitBreakerState,
 	}
 
 	var err error
@@ -200,7 +211,9 @@ func (imc *InstanceManagerController) isResponsibleForSetting(obj interface{}) b
 	}
 
 	return types.SettingName(setting.Name) == types.SettingNameKubernetesClusterAutoscalerEnabled ||
-		types.SettingName(setting.Name) == types.SettingNameV2DataEngineCPUMask
+		types.SettingName(setting.Name) == types.SettingNameV2DataEngineCPUMask ||
+		types.SettingName(setting.Name) == types.SettingNameInstanceManagerCPUPerInstance ||
+		types.SettingName(setting.Name) == types.SettingNameInstanceManagerMemoryPerInstance
 }
 
 func isInstanceManagerPod(obj interface{}) bool {
@@ -352,6 +365,8 @@ func (imc *InstanceManagerController) syncInstanceManager(key string) (err error
 		return err
 	}
 
+	imc.syncDegradedCondition(im)
+
 	// An instance manager pod for v2 volume need to consume huge pages, and disks managed by the
 	// pod is unable to managed by another pod. Therefore, if an instance manager pod is running on a node,
 	// an extra instance manager pod for v2 volume should not be created.
@@ -373,6 +388,10 @@ func (imc *InstanceManagerController) syncInstanceManager(key string) (err error
 		return err
 	}
 
+	if err := imc.syncOrphanedInstances(im); err != nil {
+		return err
+	}
+
 	if err := imc.handlePod(im); err != nil {
 		return err
 	}
@@ -468,7 +487,7 @@ func (imc *InstanceManagerController) syncStatusWithPod(im *longhorn.InstanceMan
 
 		if isReady {
 			im.Status.CurrentState = longhorn.InstanceManagerStateRunning
-			im.Status.IP = pod.Status.PodIP
+			im.Status.IP = imc.ds.GetPodIP(pod)
 		} else {
 			im.Status.CurrentState = longhorn.InstanceManagerStateStarting
 		}
@@ -513,6 +532,111 @@ func (imc *InstanceManagerController) syncInstanceStatus(im *longhorn.InstanceMa
 	return nil
 }
 
+// syncOrphanedInstances creates orphans for the engine and replica instances running in the instance manager without
+// a corresponding engine or replica, and deletes the orphans whose instances are gone or adopted again.
+func (imc *InstanceManagerController) syncOrphanedInstances(im *longhorn.InstanceManager) error {
+	// The instance status is not reliable until the instance manager is running or stopped.
+	if im.Status.CurrentState == longhorn.InstanceManagerStateUnknown ||
+		im.Status.CurrentState == longhorn.InstanceManagerStateStarting {
+		return nil
+	}
+
+	orphans, err := imc.ds.ListOrphansByInstanceManagerRO(im.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list orphans for instance manager %v", im.Name)
+	}
+
+	orphanedInstances := map[string]bool{}
+	if im.Status.CurrentState == longhorn.InstanceManagerStateRunning {
+		for _, instance := range im.Status.InstanceEngines {
+			orphaned, err := imc.isInstanceOrphaned(instance.Spec.Name, longhorn.OrphanTypeEngineInstance)
+			if err != nil {
+				return err
+			}
+			if orphaned {
+				if err := imc.createOrphanForInstance(im, instance, longhorn.OrphanTypeEngineInstance); err != nil {
+					return err
+				}
+				orphanedInstances[types.GetOrphanChecksumNameForOrphanedInstance(instance.Spec.Name, im.Name, string(instance.Spec.DataEngine))] = true
+			}
+		}
+		for _, instance := range im.Status.InstanceReplicas {
+			orphaned, err := imc.isInstanceOrphaned(instance.Spec.Name, longhorn.OrphanTypeReplicaInstance)
+			if err != nil {
+				return err
+			}
+			if orphaned {
+				if err := imc.createOrphanForInstance(im, instance, longhorn.OrphanTypeReplicaInstance); err != nil {
+					return err
+				}
+				orphanedInstances[types.GetOrphanChecksumNameForOrphanedInstance(instance.Spec.Name, im.Name, string(instance.Spec.DataEngine))] = true
+			}
+		}
+	}
+
+	for _, orphan := range orphans {
+		if orphanedInstances[orphan.Name] || !orphan.DeletionTimestamp.IsZero() {
+			continue
+		}
+		imc.logger.Infof("Deleting orphan %v since instance %v is no longer orphaned in instance manager %v",
+			orphan.Name, orphan.Spec.Parameters[longhorn.OrphanInstanceName], im.Name)
+		if err := imc.ds.DeleteOrphan(orphan.Name); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete orphan %v", orphan.Name)
+		}
+	}
+
+	return nil
+}
+
+func (imc *InstanceManagerController) isInstanceOrphaned(instanceName string, orphanType longhorn.OrphanType) (bool, error) {
+	var err error
+	switch orphanType {
+	case longhorn.OrphanTypeEngineInstance:
+		_, err = imc.ds.GetEngineRO(instanceName)
+	case longhorn.OrphanTypeReplicaInstance:
+		_, err = imc.ds.GetReplicaRO(instanceName)
+	default:
+		return false, fmt.Errorf("unknown orphan type %v for instance %v", orphanType, instanceName)
+	}
+	if err == nil {
+		return false, nil
+	}
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	return false, errors.Wrapf(err, "failed to get %v for instance %v", orphanType, instanceName)
+}
+
+func (imc *InstanceManagerController) createOrphanForInstance(im *longhorn.InstanceManager, instance longhorn.InstanceProcess, orphanType longhorn.OrphanType) error {
+	name := types.GetOrphanChecksumNameForOrphanedInstance(instance.Spec.Name, im.Name, string(instance.Spec.DataEngine))
+
+	_, err := imc.ds.GetOrphanRO(name)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	orphan := &longhorn.Orphan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: longhorn.OrphanSpec{
+			NodeID: im.Spec.NodeID,
+			Type:   orphanType,
+			Parameters: map[string]string{
+				longhorn.OrphanInstanceName:    instance.Spec.Name,
+				longhorn.OrphanInstanceManager: im.Name,
+				longhorn.OrphanDataEngine:      string(instance.Spec.DataEngine),
+			},
+		},
+	}
+
+	imc.logger.Infof("Creating orphan %v for %v %v running in instance manager %v", name, orphanType, instance.Spec.Name, im.Name)
+	if _, err := imc.ds.CreateOrphan(orphan); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create orphan for %v %v in instance manager %v", orphanType, instance.Spec.Name, im.Name)
+	}
+	return nil
+}
+
 func (imc *InstanceManagerController) isDateEngineCPUMaskApplied(im *longhorn.InstanceManager) (bool, error) {
 	if types.IsDataEngineV1(im.Spec.DataEngine) {
 		return true, nil
@@ -602,6 +726,13 @@ func (imc *InstanceManagerController) handlePod(im *longhorn.InstanceManager) er
 		log.WithError(err).Warnf("Failed to sync log settings to instance manager pod %v", im.Name)
 	}
 
+	resized, err := imc.syncInstanceManagerPodResources(im)
+	imc.recordPodResizeFailure(log, im.Name, err)
+	if resized {
+		// The pod in the cache is not resized yet
+		return nil
+	}
+
 	dataEngineCPUMaskIsApplied, err := imc.isDateEngineCPUMaskApplied(im)
 	if err != nil {
 		log.WithError(err).Warnf("Failed to sync date engine CPU mask to instance manager pod %v", im.Name)
@@ -707,14 +838,12 @@ func (imc *InstanceManagerController) areDangerZoneSettingsSyncedToIMPod(im *lon
 			return false, false, false, err
 		}
 		switch settingName {
-		case types.SettingNameTaintToleration:
-			isSettingSynced, err = imc.isSettingTaintTolerationSynced(setting, pod)
+		case types.SettingNameTaintToleration, types.SettingNamePriorityClass, types.SettingNameSystemManagedComponentsSchedulingOverrides:
+			isSettingSynced, err = imc.isSchedulingSynced(pod)
 		case types.SettingNameSystemManagedComponentsNodeSelector:
 			isSettingSynced, err = imc.isSettingNodeSelectorSynced(setting, pod)
 		case types.SettingNameGuaranteedInstanceManagerCPU, types.SettingNameV2DataEngineGuaranteedInstanceManagerCPU:
 			isSettingSynced, err = imc.isSettingGuaranteedInstanceManagerCPUSynced(setting, pod)
-		case types.SettingNamePriorityClass:
-			isSettingSynced, err = imc.isSettingPriorityClassSynced(setting, pod)
 		case types.SettingNameStorageNetwork:
 			isSettingSynced, err = imc.isSettingStorageNetworkSynced(setting, pod)
 		case types.SettingNameV1DataEngine, types.SettingNameV2DataEngine:
@@ -728,14 +857,28 @@ func (imc *InstanceManagerController) areDangerZoneSettingsSyncedToIMPod(im *lon
 		}
 	}
 
+	isMemoryRequestSynced, err := imc.isInstanceManagerMemoryRequestSynced(pod)
+	if err != nil {
+		return false, false, false, err
+	}
+	if !isMemoryRequestSynced {
+		return false, false, false, nil
+	}
+
 	return true, false, false, nil
 }
 
-func (imc *InstanceManagerController) isSettingTaintTolerationSynced(setting *longhorn.Setting, pod *corev1.Pod) (bool, error) {
-	newTolerationsList, err := types.UnmarshalTolerations(setting.Value)
+// isSchedulingSynced checks the tolerations and the priority class of the pod against the settings taint toleration
+// and priority class, and the scheduling overrides of the instance managers.
+func (imc *InstanceManagerController) isSchedulingSynced(pod *corev1.Pod) (bool, error) {
+	priorityClass, newTolerationsList, _, err := imc.ds.GetSystemManagedComponentScheduling(types.SystemManagedComponentInstanceManager)
 	if err != nil {
 		return false, err
 	}
+	if pod.Spec.PriorityClassName != priorityClass {
+		return false, nil
+	}
+
 	newTolerationsMap := util.TolerationListToMap(newTolerationsList)
 	lastAppliedTolerations, err := getLastAppliedTolerationsList(pod)
 	if err != nil {
@@ -774,8 +917,109 @@ func (imc *InstanceManagerController) isSettingGuaranteedInstanceManagerCPUSynce
 	return IsSameGuaranteedCPURequirement(resourceReq, &podResourceReq), nil
 }
 
-func (imc *InstanceManagerController) isSettingPriorityClassSynced(setting *longhorn.Setting, pod *corev1.Pod) (bool, error) {
-	return pod.Spec.PriorityClassName == setting.Value, nil
+func (imc *InstanceManagerController) isInstanceManagerMemoryRequestSynced(pod *corev1.Pod) (bool, error) {
+	lhNode, err := imc.ds.GetNode(pod.Spec.NodeName)
+	if err != nil {
+		return false, err
+	}
+	if types.GetCondition(lhNode.Status.Conditions, longhorn.NodeConditionTypeReady).Status != longhorn.ConditionStatusTrue {
+		return true, nil
+	}
+
+	resourceReq, err := GetInstanceManagerMemoryRequirement(imc.ds, pod.Name)
+	if err != nil {
+		return false, err
+	}
+	podResourceReq := pod.Spec.Containers[0].Resources
+	return IsSameMemoryRequirement(resourceReq, &podResourceReq), nil
+}
+
+// syncInstanceManagerPodResources resizes the instance manager pod in place once the CPU or memory request scaled with
+// the number of the running instances changes. It needs the in-place pod resize support of Kubernetes, otherwise the
+// pod keeps its requests. It returns true if the pod is resized.
+func (imc *InstanceManagerController) syncInstanceManagerPodResources(im *longhorn.InstanceManager) (bool, error) {
+	if im.Status.CurrentState != longhorn.InstanceManagerStateRunning || imc.controllerID != im.Spec.NodeID {
+		return false, nil
+	}
+
+	cpuPerInstance, err := imc.ds.GetSettingAsInt(types.SettingNameInstanceManagerCPUPerInstance)
+	if err != nil {
+		return false, err
+	}
+	memoryPerInstance, err := imc.ds.GetSettingAsInt(types.SettingNameInstanceManagerMemoryPerInstance)
+	if err != nil {
+		return false, err
+	}
+	if cpuPerInstance == 0 && memoryPerInstance == 0 {
+		return false, nil
+	}
+
+	pod, err := imc.ds.GetPodRO(im.Namespace, im.Name)
+	if err != nil {
+		return false, err
+	}
+	if pod == nil || pod.DeletionTimestamp != nil {
+		return false, nil
+	}
+
+	cpuResourceReq, err := GetInstanceManagerCPURequirement(imc.ds, im.Name)
+	if err != nil {
+		return false, err
+	}
+	memoryResourceReq, err := GetInstanceManagerMemoryRequirement(imc.ds, im.Name)
+	if err != nil {
+		return false, err
+	}
+	podResourceReq := pod.Spec.Containers[0].Resources
+	if IsSameGuaranteedCPURequirement(cpuResourceReq, &podResourceReq) && IsSameMemoryRequirement(memoryResourceReq, &podResourceReq) {
+		return false, nil
+	}
+
+	pod = pod.DeepCopy()
+	requests := corev1.ResourceList{}
+	for name, quantity := range pod.Spec.Containers[0].Resources.Requests {
+		requests[name] = quantity
+	}
+	for name, resourceReq := range map[corev1.ResourceName]*corev1.ResourceRequirements{
+		corev1.ResourceCPU:    cpuResourceReq,
+		corev1.ResourceMemory: memoryResourceReq,
+	} {
+		if resourceReq == nil {
+			delete(requests, name)
+			continue
+		}
+		requests[name] = resourceReq.Requests[name]
+	}
+	pod.Spec.Containers[0].Resources.Requests = requests
+
+	imc.logger.Infof("Resizing instance manager pod %v to requests %v for %v running instances",
+		pod.Name, requests, getInstanceManagerRunningInstanceCount(im))
+	if _, err := imc.kubeClient.CoreV1().Pods(pod.Namespace).UpdateResize(context.TODO(), pod.Name, pod, metav1.UpdateOptions{}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// recordPodResizeFailure logs the failure of resizing the instance manager pod only when it changes. The resize is
+// retried on every sync, and keeps failing e.g. if Kubernetes doesn't support the in-place pod resize.
+func (imc *InstanceManagerController) recordPodResizeFailure(log logrus.FieldLogger, imName string, err error) {
+	imc.podResizeFailureMutex.Lock()
+	defer imc.podResizeFailureMutex.Unlock()
+
+	lastFailure, failedBefore := imc.podResizeFailureMap[imName]
+	if err == nil {
+		if failedBefore {
+			delete(imc.podResizeFailureMap, imName)
+			log.Infof("Instance manager pod %v is no longer failing to resize", imName)
+		}
+		return
+	}
+	if failedBefore && lastFailure == err.Error() {
+		return
+	}
+	imc.podResizeFailureMap[imName] = err.Error()
+	log.WithError(err).Warnf("Failed to resize instance manager pod %v", imName)
 }
 
 func (imc *InstanceManagerController) isSettingStorageNetworkSynced(setting *longhorn.Setting, pod *corev1.Pod) (bool, error) {
@@ -802,6 +1046,33 @@ func (imc *InstanceManagerController) isSettingDataEngineSynced(settingName type
 	return true, nil
 }
 
+// syncDegradedCondition surfaces the circuit breaker of the instance manager in this manager as the Degraded
+// condition, so the instance manager short-circuited after repeated timeouts is visible. The circuit breakers are
+// kept per longhorn-manager process and only the manager owning the instance manager publishes its view, which the
+// condition message says. The instance manager is requeued to clear the condition once the backoff ends.
+func (imc *InstanceManagerController) syncDegradedCondition(im *longhorn.InstanceManager) {
+	state := imc.circuitBreakerStateGetter(im.Name, im.Status.IP)
+	if state.Open {
+		im.Status.Conditions = types.SetConditionAndRecord(im.Status.Conditions,
+			longhorn.InstanceManagerConditionTypeDegraded, longhorn.ConditionStatusTrue,
+			longhorn.InstanceManagerConditionReasonCircuitBreakerOpen,
+			fmt.Sprintf("Calls from longhorn-manager on node %v to instance manager %v are short-circuited until %v after %v consecutive timeouts: %v",
+				imc.controllerID, im.Name, state.OpenUntil.UTC().Format(time.RFC3339), state.Failures, state.LastError),
+			imc.eventRecorder, im, corev1.EventTypeWarning)
+		if key, err := controller.KeyFunc(im); err == nil {
+			imc.queue.AddAfter(key, time.Until(state.OpenUntil))
+		}
+		return
+	}
+
+	if types.GetCondition(im.Status.Conditions, longhorn.InstanceManagerConditionTypeDegraded).Status == longhorn.ConditionStatusTrue {
+		im.Status.Conditions = types.SetConditionAndRecord(im.Status.Conditions,
+			longhorn.InstanceManagerConditionTypeDegraded, longhorn.ConditionStatusFalse,
+			"", fmt.Sprintf("Instance manager %v is responsive to longhorn-manager on node %v", im.Name, imc.controllerID),
+			imc.eventRecorder, im, corev1.EventTypeNormal)
+	}
+}
+
 func (imc *InstanceManagerController) syncInstanceManagerAPIVersion(im *longhorn.InstanceManager) error {
 	// Avoid changing API versions when InstanceManagers are state Unknown.
 	// Then once required (in the future), the monitor could still talk with the pod and update processes in some corner cases. e.g., kubelet restart.
@@ -1282,6 +1553,7 @@ func (imc *InstanceManagerController) enqueueSettingChange(obj interface{}) {
 func (imc *InstanceManagerController) cleanupInstanceManagerPod(imName string) error {
 	imc.stopMonitoring(imName)
 	imc.stopBackingImageMonitoring(imName)
+	imc.recordPodResizeFailure(imc.logger, imName, nil)
 
 	pod, err := imc.ds.GetPodRO(imc.namespace, imName)
 	if err != nil {
@@ -1300,9 +1572,9 @@ func (imc *InstanceManagerController) cleanupInstanceManagerPod(imName string) e
 func (imc *InstanceManagerController) createInstanceManagerPod(im *longhorn.InstanceManager) error {
 	log := getLoggerForInstanceManager(imc.logger, im)
 
-	tolerations, err := imc.ds.GetSettingTaintToleration()
+	_, tolerations, _, err := imc.ds.GetSystemManagedComponentScheduling(types.SystemManagedComponentInstanceManager)
 	if err != nil {
-		return errors.Wrap(err, "failed to get taint toleration setting before creating instance manager pod")
+		return errors.Wrap(err, "failed to get tolerations before creating instance manager pod")
 	}
 
 	nodeSelector, err := imc.ds.GetSettingSystemManagedComponentsNodeSelector()
@@ -1350,7 +1622,7 @@ func (imc *InstanceManagerController) createGenericManagerPodSpec(im *longhorn.I
 		return nil, err
 	}
 
-	priorityClass, err := imc.ds.GetSettingWithAutoFillingRO(types.SettingNamePriorityClass)
+	priorityClass, _, _, err := imc.ds.GetSystemManagedComponentScheduling(types.SystemManagedComponentInstanceManager)
 	if err != nil {
 		return nil, err
 	}
@@ -1372,7 +1644,7 @@ func (imc *InstanceManagerController) createGenericManagerPodSpec(im *longhorn.I
 			ServiceAccountName: imc.serviceAccount,
 			Tolerations:        util.GetDistinctTolerations(tolerations),
 			NodeSelector:       nodeSelector,
-			PriorityClassName:  priorityClass.Value,
+			PriorityClassName:  priorityClass,
 			Containers: []corev1.Container{
 				{
 					Image:           im.Spec.Image,
@@ -1404,6 +1676,16 @@ func (imc *InstanceManagerController) createGenericManagerPodSpec(im *longhorn.I
 	if cpuResourceReq != nil {
 		podSpec.Spec.Containers[0].Resources = *cpuResourceReq
 	}
+	memoryResourceReq, err := GetInstanceManagerMemoryRequirement(imc.ds, im.Name)
+	if err != nil {
+		return nil, err
+	}
+	if memoryResourceReq != nil {
+		if podSpec.Spec.Containers[0].Resources.Requests == nil {
+			podSpec.Spec.Containers[0].Resources.Requests = corev1.ResourceList{}
+		}
+		podSpec.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory] = memoryResourceReq.Requests[corev1.ResourceMemory]
+	}
 
 	return podSpec, nil
 }
@@ -1414,6 +1696,12 @@ func (imc *InstanceManagerController) createInstanceManagerPodSpec(im *longhorn.
 		return nil, err
 	}
 
+	listenIP, err := imc.ds.GetListenIP()
+	if err != nil {
+		return nil, err
+	}
+	listenAddress := net.JoinHostPort(listenIP, strconv.Itoa(engineapi.InstanceManagerProcessManagerServiceDefaultPort))
+
 	secretIsOptional := true
 	podSpec.Labels = types.GetInstanceManagerLabels(imc.controllerID, im.Spec.Image, longhorn.InstanceManagerTypeAllInOne, dataEngine)
 	podSpec.Spec.Containers[0].Name = "instance-manager"
@@ -1450,7 +1738,7 @@ func (imc *InstanceManagerController) createInstanceManagerPodSpec(im *longhorn.
 			"--enable-spdk", "--debug",
 			"daemon",
 			"--spdk-enabled",
-			"--listen", fmt.Sprintf("0.0.0.0:%d", engineapi.InstanceManagerProcessManagerServiceDefaultPort)}
+			"--listen", listenAddress}
 
 		imc.logger.Infof("Creating instance manager pod %v with args %+v", podSpec.Name, args)
 
@@ -1461,11 +1749,6 @@ func (imc *InstanceManagerController) createInstanceManagerPodSpec(im *longhorn.
 			return nil, err
 		}
 
-		if podSpec.Spec.Containers[0].Resources.Requests == nil {
-			podSpec.Spec.Containers[0].Resources.Requests = corev1.ResourceList{}
-		}
-		podSpec.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory] = resource.MustParse("128Mi")
-
 		if podSpec.Spec.Containers[0].Resources.Limits == nil {
 			podSpec.Spec.Containers[0].Resources.Limits = corev1.ResourceList{}
 		}
@@ -1479,7 +1762,7 @@ func (imc *InstanceManagerController) createInstanceManagerPodSpec(im *longhorn.
 		}
 	} else {
 		podSpec.Spec.Containers[0].Args = []string{
-			"instance-manager", "--debug", "daemon", "--listen", fmt.Sprintf("0.0.0.0:%d", engineapi.InstanceManagerProcessManagerServiceDefaultPort),
+			"instance-manager", "--debug", "daemon", "--listen", listenAddress,
 		}
 	}
 
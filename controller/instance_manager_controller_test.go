@@ -1,8 +1,11 @@
 package controller
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -311,3 +314,273 @@ func (s *TestSuite) TestSyncInstanceManager(c *C) {
 		c.Assert(updatedIM.Status, DeepEquals, tc.expectedStatus)
 	}
 }
+
+func (s *TestSuite) TestInstanceManagerResourceRequirement(c *C) {
+	testCases := map[string]struct {
+		cpuPerInstance    string
+		memoryPerInstance string
+
+		expectedCPU    string
+		expectedMemory string
+	}{
+		"node overrides only": {
+			cpuPerInstance:    "0",
+			memoryPerInstance: "0",
+			expectedCPU:       "500m",
+			expectedMemory:    "256Mi",
+		},
+		"scaled with running instances": {
+			cpuPerInstance:    "100",
+			memoryPerInstance: "64",
+			expectedCPU:       "700m",
+			expectedMemory:    "384Mi",
+		},
+	}
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		lhClient := lhfake.NewSimpleClientset()
+		extensionsClient := apiextensionsfake.NewSimpleClientset()
+		informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+		kubeNodeIndexer := informerFactories.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+		imIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().InstanceManagers().Informer().GetIndexer()
+		sIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Settings().Informer().GetIndexer()
+		lhNodeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Nodes().Informer().GetIndexer()
+
+		ds := datastore.NewDataStore(TestNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+
+		for settingName, value := range map[types.SettingName]string{
+			types.SettingNameInstanceManagerCPUPerInstance:    tc.cpuPerInstance,
+			types.SettingNameInstanceManagerMemoryPerInstance: tc.memoryPerInstance,
+		} {
+			setting, err := lhClient.LonghornV1beta2().Settings(TestNamespace).Create(context.TODO(), newSetting(string(settingName), value), metav1.CreateOptions{})
+			c.Assert(err, IsNil)
+			c.Assert(sIndexer.Add(setting), IsNil)
+		}
+
+		kubeNode := newKubernetesNode(TestNode1, corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionTrue)
+		c.Assert(kubeNodeIndexer.Add(kubeNode), IsNil)
+
+		lhNode := newNode(TestNode1, TestNamespace, true, longhorn.ConditionStatusTrue, "")
+		lhNode.Spec.InstanceManagerCPURequest = 500
+		lhNode.Spec.InstanceManagerMemoryRequest = 256
+		c.Assert(lhNodeIndexer.Add(lhNode), IsNil)
+
+		runningInstance := func(name string) map[string]longhorn.InstanceProcess {
+			return map[string]longhorn.InstanceProcess{
+				name: {
+					Spec:   longhorn.InstanceProcessSpec{Name: name},
+					Status: longhorn.InstanceProcessStatus{State: longhorn.InstanceStateRunning},
+				},
+			}
+		}
+		im := newInstanceManager(
+			TestInstanceManagerName, longhorn.InstanceManagerStateRunning,
+			TestNode1, TestNode1, TestIP1,
+			runningInstance(TestEngineName), runningInstance(TestReplicaName),
+			longhorn.DataEngineTypeV1,
+			TestInstanceManagerImage,
+			false,
+		)
+		c.Assert(imIndexer.Add(im), IsNil)
+
+		cpuResourceReq, err := GetInstanceManagerCPURequirement(ds, im.Name)
+		c.Assert(err, IsNil)
+		c.Assert(IsSameGuaranteedCPURequirement(cpuResourceReq, &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(tc.expectedCPU)},
+		}), Equals, true)
+
+		memoryResourceReq, err := GetInstanceManagerMemoryRequirement(ds, im.Name)
+		c.Assert(err, IsNil)
+		c.Assert(IsSameMemoryRequirement(memoryResourceReq, &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(tc.expectedMemory)},
+		}), Equals, true)
+	}
+}
+
+func (s *TestSuite) TestSyncOrphanedInstances(c *C) {
+	datastore.SkipListerCheck = true
+
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+	eIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Engines().Informer().GetIndexer()
+	oIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Orphans().Informer().GetIndexer()
+
+	imc, err := newTestInstanceManagerController(lhClient, kubeClient, extensionsClient, informerFactories, TestNode1)
+	c.Assert(err, IsNil)
+
+	runningInstances := func(names ...string) map[string]longhorn.InstanceProcess {
+		instances := map[string]longhorn.InstanceProcess{}
+		for _, name := range names {
+			instances[name] = longhorn.InstanceProcess{
+				Spec:   longhorn.InstanceProcessSpec{Name: name, DataEngine: longhorn.DataEngineTypeV1},
+				Status: longhorn.InstanceProcessStatus{State: longhorn.InstanceStateRunning},
+			}
+		}
+		return instances
+	}
+	orphanedEngineName := "orphaned-engine"
+	im := newInstanceManager(
+		TestInstanceManagerName, longhorn.InstanceManagerStateRunning,
+		TestNode1, TestNode1, TestIP1,
+		runningInstances(TestEngineName, orphanedEngineName), runningInstances(TestReplicaName),
+		longhorn.DataEngineTypeV1,
+		TestInstanceManagerImage,
+		false,
+	)
+
+	engine := newEngine(TestEngineName, TestEngineImage, TestInstanceManagerName, TestNode1, TestIP1, 0, true, longhorn.InstanceStateRunning, longhorn.InstanceStateRunning)
+	c.Assert(eIndexer.Add(engine), IsNil)
+
+	// The orphan of an instance which is no longer running should be removed
+	staleOrphanName := types.GetOrphanChecksumNameForOrphanedInstance("stale-engine", im.Name, string(longhorn.DataEngineTypeV1))
+	staleOrphan := &longhorn.Orphan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      staleOrphanName,
+			Namespace: TestNamespace,
+			Labels:    types.GetOrphanLabelsForOrphanedInstance(TestNode1, im.Name, longhorn.OrphanTypeEngineInstance),
+		},
+		Spec: longhorn.OrphanSpec{
+			NodeID: TestNode1,
+			Type:   longhorn.OrphanTypeEngineInstance,
+			Parameters: map[string]string{
+				longhorn.OrphanInstanceName:    "stale-engine",
+				longhorn.OrphanInstanceManager: im.Name,
+				longhorn.OrphanDataEngine:      string(longhorn.DataEngineTypeV1),
+			},
+		},
+	}
+	staleOrphan, err = lhClient.LonghornV1beta2().Orphans(TestNamespace).Create(context.TODO(), staleOrphan, metav1.CreateOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(oIndexer.Add(staleOrphan), IsNil)
+
+	err = imc.syncOrphanedInstances(im)
+	c.Assert(err, IsNil)
+
+	orphans, err := lhClient.LonghornV1beta2().Orphans(TestNamespace).List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	orphanTypes := map[string]longhorn.OrphanType{}
+	for _, orphan := range orphans.Items {
+		orphanTypes[orphan.Spec.Parameters[longhorn.OrphanInstanceName]] = orphan.Spec.Type
+	}
+	c.Assert(orphanTypes, DeepEquals, map[string]longhorn.OrphanType{
+		orphanedEngineName: longhorn.OrphanTypeEngineInstance,
+		TestReplicaName:    longhorn.OrphanTypeReplicaInstance,
+	})
+}
+
+func (s *TestSuite) TestRecordPodResizeFailure(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+	imc, err := newTestInstanceManagerController(lhClient, kubeClient, extensionsClient, informerFactories, TestNode1)
+	c.Assert(err, IsNil)
+
+	output := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(output)
+
+	resizeErr := fmt.Errorf("pod resize is not supported")
+	for _, err := range []error{resizeErr, resizeErr, fmt.Errorf("pod is being deleted"), nil, nil, resizeErr} {
+		imc.recordPodResizeFailure(logger, TestInstanceManagerName, err)
+	}
+	c.Assert(strings.Count(output.String(), "Failed to resize instance manager pod"), Equals, 3)
+	c.Assert(strings.Count(output.String(), "is no longer failing to resize"), Equals, 1)
+}
+
+func (s *TestSuite) TestSyncDegradedCondition(c *C) {
+	type testCase struct {
+		circuitBreakerState engineapi.InstanceManagerCircuitBreakerState
+		degraded            bool
+
+		expectedStatus longhorn.ConditionStatus
+		expectedReason string
+	}
+	testCases := map[string]testCase{
+		"circuit breaker open": {
+			circuitBreakerState: engineapi.InstanceManagerCircuitBreakerState{
+				Open:      true,
+				OpenUntil: time.Now().Add(time.Minute),
+				Failures:  engineapi.InstanceManagerCircuitBreakerFailureThreshold,
+				LastError: "rpc error: code = DeadlineExceeded desc = context deadline exceeded",
+			},
+			expectedStatus: longhorn.ConditionStatusTrue,
+			expectedReason: longhorn.InstanceManagerConditionReasonCircuitBreakerOpen,
+		},
+		"circuit breaker closed again": {
+			degraded:       true,
+			expectedStatus: longhorn.ConditionStatusFalse,
+		},
+		"circuit breaker closed": {
+			expectedStatus: longhorn.ConditionStatusUnknown,
+		},
+	}
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		lhClient := lhfake.NewSimpleClientset()
+		extensionsClient := apiextensionsfake.NewSimpleClientset()
+		informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+		pIndexer := informerFactories.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+		kubeNodeIndexer := informerFactories.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+		imIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().InstanceManagers().Informer().GetIndexer()
+		sIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Settings().Informer().GetIndexer()
+		lhNodeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Nodes().Informer().GetIndexer()
+
+		imc, err := newTestInstanceManagerController(lhClient, kubeClient, extensionsClient, informerFactories, TestNode1)
+		c.Assert(err, IsNil)
+		imc.circuitBreakerStateGetter = func(name, ip string) engineapi.InstanceManagerCircuitBreakerState {
+			c.Assert(name, Equals, TestInstanceManagerName)
+			c.Assert(ip, Equals, TestIP1)
+			return tc.circuitBreakerState
+		}
+
+		c.Assert(sIndexer.Add(newTolerationSetting()), IsNil)
+		c.Assert(sIndexer.Add(newDefaultInstanceManagerImageSetting()), IsNil)
+
+		kubeNode := newKubernetesNode(TestNode1, corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionTrue)
+		c.Assert(kubeNodeIndexer.Add(kubeNode), IsNil)
+		c.Assert(lhNodeIndexer.Add(newNode(TestNode1, TestNamespace, true, longhorn.ConditionStatusTrue, "")), IsNil)
+
+		im := newInstanceManager(TestInstanceManagerName, longhorn.InstanceManagerStateRunning, TestNode1, TestNode1, TestIP1,
+			nil, nil, longhorn.DataEngineTypeV1, TestInstanceManagerImage, false)
+		im.Status.APIMinVersion = engineapi.MinInstanceManagerAPIVersion
+		im.Status.APIVersion = engineapi.CurrentInstanceManagerAPIVersion
+		if tc.degraded {
+			im.Status.Conditions = types.SetCondition(im.Status.Conditions, longhorn.InstanceManagerConditionTypeDegraded,
+				longhorn.ConditionStatusTrue, longhorn.InstanceManagerConditionReasonCircuitBreakerOpen, "")
+		}
+		im, err = lhClient.LonghornV1beta2().InstanceManagers(im.Namespace).Create(context.TODO(), im, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(imIndexer.Add(im), IsNil)
+
+		pod := newPod(&corev1.PodStatus{PodIP: TestIP1, Phase: corev1.PodRunning}, im.Name, im.Namespace, im.Spec.NodeID)
+		pod.Spec.Containers = []corev1.Container{{
+			Name:      "instance-manager",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{"cpu": resource.MustParse("480m")}},
+		}}
+		c.Assert(pIndexer.Add(pod), IsNil)
+		_, err = kubeClient.CoreV1().Pods(im.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+
+		err = imc.syncInstanceManager(getKey(im, c))
+		c.Assert(err, IsNil)
+
+		updatedIM, err := lhClient.LonghornV1beta2().InstanceManagers(im.Namespace).Get(context.TODO(), im.Name, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+		condition := types.GetCondition(updatedIM.Status.Conditions, longhorn.InstanceManagerConditionTypeDegraded)
+		c.Assert(condition.Status, Equals, tc.expectedStatus)
+		c.Assert(condition.Reason, Equals, tc.expectedReason)
+	}
+}
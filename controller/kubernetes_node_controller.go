@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
@@ -23,6 +24,7 @@ import (
 
 	"github.com/longhorn/longhorn-manager/datastore"
 	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
 
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
@@ -111,7 +113,8 @@ func isSettingCreateDefaultDiskLabeledNodes(obj interface{}) bool {
 		}
 	}
 
-	return types.SettingName(setting.Name) == types.SettingNameCreateDefaultDiskLabeledNodes
+	return types.SettingName(setting.Name) == types.SettingNameCreateDefaultDiskLabeledNodes ||
+		types.SettingName(setting.Name) == types.SettingNameNodeTagLabels
 }
 
 func (knc *KubernetesNodeController) Run(workers int, stopCh <-chan struct{}) {
@@ -206,7 +209,7 @@ func (knc *KubernetesNodeController) syncKubernetesNode(key string) (err error)
 
 	existingNode := node.DeepCopy()
 	defer func() {
-		if err == nil && !reflect.DeepEqual(existingNode.Spec, node.Spec) {
+		if err == nil && (!reflect.DeepEqual(existingNode.Spec, node.Spec) || !reflect.DeepEqual(existingNode.Annotations, node.Annotations)) {
 			_, err = knc.ds.UpdateNode(node)
 		}
 		// requeue if it's conflict
@@ -226,6 +229,9 @@ func (knc *KubernetesNodeController) syncKubernetesNode(key string) (err error)
 	if err := knc.syncDefaultNodeTags(node); err != nil {
 		return err
 	}
+	if err := knc.syncLabelNodeTags(node, kubeNode); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -352,3 +358,58 @@ func (knc *KubernetesNodeController) syncDefaultNodeTags(node *longhorn.Node) er
 	}
 	return nil
 }
+
+// syncLabelNodeTags synchronizes the node tags from the Kubernetes node labels of the node tag labels setting. The
+// synchronized tags are recorded in an annotation of the node, so the tags added manually are kept, while the
+// synchronized tags are removed once their label is removed.
+func (knc *KubernetesNodeController) syncLabelNodeTags(node *longhorn.Node, kubeNode *corev1.Node) error {
+	setting, err := knc.ds.GetSettingWithAutoFillingRO(types.SettingNameNodeTagLabels)
+	if err != nil {
+		return err
+	}
+	tagLabels, err := types.UnmarshalNodeTagLabels(setting.Value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse %v setting", types.SettingNameNodeTagLabels)
+	}
+
+	annotationKey := types.GetLonghornLabelKey(types.LonghornAnnotationSyncedNodeTags)
+	previousTags := []string{}
+	if val, ok := node.Annotations[annotationKey]; ok {
+		if err := json.Unmarshal([]byte(val), &previousTags); err != nil {
+			knc.logger.WithError(err).Warnf("Failed to parse annotation %v of node %v", annotationKey, node.Name)
+		}
+	}
+
+	syncedTags := types.GetNodeTagsFromLabels(kubeNode.Labels, tagLabels)
+	if len(syncedTags) == 0 && len(previousTags) == 0 {
+		return nil
+	}
+
+	previous := map[string]struct{}{}
+	for _, tag := range previousTags {
+		previous[tag] = struct{}{}
+	}
+	tags := append([]string{}, syncedTags...)
+	for _, tag := range node.Spec.Tags {
+		if _, ok := previous[tag]; !ok {
+			tags = append(tags, tag)
+		}
+	}
+	if node.Spec.Tags, err = util.ValidateTags(tags); err != nil {
+		return err
+	}
+
+	if len(syncedTags) == 0 {
+		delete(node.Annotations, annotationKey)
+		return nil
+	}
+	val, err := json.Marshal(syncedTags)
+	if err != nil {
+		return err
+	}
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+	node.Annotations[annotationKey] = string(val)
+	return nil
+}
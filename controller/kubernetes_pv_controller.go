@@ -25,7 +25,6 @@ import (
 	"github.com/longhorn/longhorn-manager/constant"
 	"github.com/longhorn/longhorn-manager/datastore"
 	"github.com/longhorn/longhorn-manager/types"
-	"github.com/longhorn/longhorn-manager/util"
 
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
@@ -45,9 +44,6 @@ type KubernetesPVController struct {
 
 	// key is <PVName>, value is <VolumeName>
 	pvToVolumeCache sync.Map
-
-	// for unit test
-	nowHandler func() string
 }
 
 func NewKubernetesPVController(
@@ -73,8 +69,6 @@ func NewKubernetesPVController(
 		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-kubernetes-pv-controller"}),
 
 		pvToVolumeCache: sync.Map{},
-
-		nowHandler: util.Now,
 	}
 
 	var err error
@@ -233,9 +227,9 @@ func (kc *KubernetesPVController) syncKubernetesStatus(key string) (err error) {
 			ks.LastPVCRefAt = ""
 		} else if lastPVStatus == string(corev1.VolumeBound) && ks.LastPVCRefAt == "" {
 			// PVC is no longer bound with PV. indicate historic data by setting <LastPVCRefAt>
-			ks.LastPVCRefAt = kc.nowHandler()
+			ks.LastPVCRefAt = kc.now()
 			if len(ks.WorkloadsStatus) != 0 && ks.LastPodRefAt == "" {
-				ks.LastPodRefAt = kc.nowHandler()
+				ks.LastPodRefAt = kc.now()
 			}
 		}
 	} else {
@@ -245,9 +239,9 @@ func (kc *KubernetesPVController) syncKubernetesStatus(key string) (err error) {
 			}
 			// The associated PVC is removed from the PV ClaimRef
 			if ks.PVCName != "" {
-				ks.LastPVCRefAt = kc.nowHandler()
+				ks.LastPVCRefAt = kc.now()
 				if len(ks.WorkloadsStatus) != 0 && ks.LastPodRefAt == "" {
-					ks.LastPodRefAt = kc.nowHandler()
+					ks.LastPodRefAt = kc.now()
 				}
 			}
 		}
@@ -397,10 +391,10 @@ func (kc *KubernetesPVController) cleanupForPVDeletion(pvName string) (bool, err
 	if datastore.ErrorIsNotFound(err) || pv.DeletionTimestamp != nil {
 		ks := &volume.Status.KubernetesStatus
 		if ks.PVCName != "" && ks.LastPVCRefAt == "" {
-			volume.Status.KubernetesStatus.LastPVCRefAt = kc.nowHandler()
+			volume.Status.KubernetesStatus.LastPVCRefAt = kc.now()
 		}
 		if len(ks.WorkloadsStatus) != 0 && ks.LastPodRefAt == "" {
-			volume.Status.KubernetesStatus.LastPodRefAt = kc.nowHandler()
+			volume.Status.KubernetesStatus.LastPodRefAt = kc.now()
 		}
 		volume.Status.KubernetesStatus.PVName = ""
 		volume.Status.KubernetesStatus.PVStatus = ""
@@ -462,7 +456,7 @@ func (kc *KubernetesPVController) setWorkloads(ks *longhorn.KubernetesStatus, po
 		if len(ks.WorkloadsStatus) == 0 || ks.LastPodRefAt != "" {
 			return
 		}
-		ks.LastPodRefAt = kc.nowHandler()
+		ks.LastPodRefAt = kc.now()
 		return
 	}
 
@@ -182,7 +182,7 @@ func newTestKubernetesPVController(lhClient *lhfake.Clientset, kubeClient *fake.
 	for index := range kc.cacheSyncs {
 		kc.cacheSyncs[index] = alwaysReady
 	}
-	kc.nowHandler = getTestNow
+	kc.SetClock(getTestClock())
 
 	return kc, nil
 }
@@ -0,0 +1,375 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/longhorn/longhorn-manager/constant"
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	// staleAttachmentRecheckInterval is how often a pending pod is checked again while the stale attachments of its
+	// volumes are being deleted
+	staleAttachmentRecheckInterval = 10 * time.Second
+)
+
+// KubernetesStaleAttachmentController speeds up the failover of the RWO volumes from a node that is down. Kubernetes
+// keeps a volume attached to the node that is down until the detachment times out, which blocks the replacement pod
+// scheduled to another node from attaching the volume. Once the pods using the volume are gone from the node that is
+// down, the controller on the node of the replacement pod deletes the stale VolumeAttachment and the stale CSI
+// attachment ticket of the volume, so the volume is attached to the new node right away.
+type KubernetesStaleAttachmentController struct {
+	*baseController
+
+	// use as the OwnerID of the controller
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds *datastore.DataStore
+
+	cacheSyncs []cache.InformerSynced
+}
+
+// staleAttachmentCandidate is a Longhorn RWO volume used by a pending pod through a PVC
+type staleAttachmentCandidate struct {
+	pvcName    string
+	volumeName string
+}
+
+func NewKubernetesStaleAttachmentController(
+	logger logrus.FieldLogger,
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	kubeClient clientset.Interface,
+	controllerID string) (*KubernetesStaleAttachmentController, error) {
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	kc := &KubernetesStaleAttachmentController{
+		baseController: newBaseController("longhorn-kubernetes-stale-attachment", logger),
+
+		controllerID: controllerID,
+
+		ds: ds,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-kubernetes-stale-attachment-controller"}),
+	}
+
+	var err error
+	if _, err = ds.PodInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: kc.isPendingPodOnNode,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    kc.enqueuePod,
+			UpdateFunc: func(old, cur interface{}) { kc.enqueuePod(cur) },
+		},
+	}); err != nil {
+		return nil, err
+	}
+	kc.cacheSyncs = append(kc.cacheSyncs, ds.PodInformer.HasSynced, ds.VolumeAttachmentInformer.HasSynced, ds.LHVolumeAttachmentInformer.HasSynced)
+
+	return kc, nil
+}
+
+func (kc *KubernetesStaleAttachmentController) isPendingPodOnNode(obj interface{}) bool {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return false
+	}
+	return pod.Spec.NodeName == kc.controllerID && pod.Status.Phase == corev1.PodPending
+}
+
+func (kc *KubernetesStaleAttachmentController) enqueuePod(obj interface{}) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %#v: %v", obj, err))
+		return
+	}
+
+	kc.queue.Add(key)
+}
+
+func (kc *KubernetesStaleAttachmentController) enqueuePodAfter(obj interface{}, duration time.Duration) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("enqueuePodAfter: couldn't get key for object %#v: %v", obj, err))
+		return
+	}
+
+	kc.queue.AddAfter(key, duration)
+}
+
+func (kc *KubernetesStaleAttachmentController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer kc.queue.ShutDown()
+
+	kc.logger.Info("Starting Longhorn Kubernetes stale attachment controller")
+	defer kc.logger.Info("Shut down Longhorn Kubernetes stale attachment controller")
+
+	if !cache.WaitForNamedCacheSync(kc.name, stopCh, kc.cacheSyncs...) {
+		return
+	}
+	for i := 0; i < workers; i++ {
+		go wait.Until(kc.worker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+func (kc *KubernetesStaleAttachmentController) worker() {
+	for kc.processNextWorkItem() {
+	}
+}
+
+func (kc *KubernetesStaleAttachmentController) processNextWorkItem() bool {
+	key, quit := kc.queue.Get()
+	if quit {
+		return false
+	}
+	defer kc.queue.Done(key)
+	err := kc.syncHandler(key.(string))
+	kc.handleErr(err, key)
+	return true
+}
+
+func (kc *KubernetesStaleAttachmentController) handleErr(err error, key interface{}) {
+	if err == nil {
+		kc.queue.Forget(key)
+		return
+	}
+
+	log := kc.logger.WithField("Pod", key)
+	if kc.queue.NumRequeues(key) < maxRetries {
+		handleReconcileErrorLogging(log, err, "Failed to sync stale attachments of pod")
+		kc.queue.AddRateLimited(key)
+		return
+	}
+
+	handleReconcileErrorLogging(log, err, "Dropping pod out of the stale attachment queue")
+	kc.queue.Forget(key)
+	utilruntime.HandleError(err)
+}
+
+func (kc *KubernetesStaleAttachmentController) syncHandler(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "%v: failed to sync stale attachments of pod %v", kc.name, key)
+	}()
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	return kc.reconcile(namespace, name)
+}
+
+func (kc *KubernetesStaleAttachmentController) reconcile(namespace, name string) error {
+	enabled, err := kc.ds.GetSettingAsBool(types.SettingNameNodeDownStaleAttachmentCleanup)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+
+	pod, err := kc.ds.GetPodRO(namespace, name)
+	if err != nil {
+		return err
+	}
+	if pod == nil || pod.DeletionTimestamp != nil || !kc.isPendingPodOnNode(pod) {
+		return nil
+	}
+
+	candidates, err := kc.getStaleAttachmentCandidates(pod)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	pods, err := kc.ds.ListPodsRO(pod.Namespace)
+	if err != nil {
+		return err
+	}
+
+	log := getLoggerForPod(kc.logger, pod)
+	volumeAttachments, err := kc.ds.ListVolumeAttachmentsRO()
+	if err != nil {
+		return err
+	}
+	existingVolumeAttachments := map[string]bool{}
+	waitForDeletion := false
+	for _, va := range volumeAttachments {
+		existingVolumeAttachments[va.Name] = true
+
+		if va.Spec.Attacher != types.LonghornDriverName || va.Spec.Source.PersistentVolumeName == nil {
+			continue
+		}
+		candidate, ok := candidates[*va.Spec.Source.PersistentVolumeName]
+		if !ok || va.Spec.NodeName == pod.Spec.NodeName {
+			continue
+		}
+		isStale, err := kc.isStaleAttachment(va.Spec.NodeName, candidate.pvcName, pods)
+		if err != nil {
+			return err
+		}
+		if !isStale {
+			continue
+		}
+
+		waitForDeletion = true
+		if va.DeletionTimestamp != nil {
+			continue
+		}
+		if err := kc.kubeClient.StorageV1().VolumeAttachments().Delete(context.TODO(), va.Name, metav1.DeleteOptions{}); err != nil && !datastore.ErrorIsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete stale volume attachment %v", va.Name)
+		}
+		log.Infof("Deleted stale volume attachment %v of volume %v on node %v that is down", va.Name, candidate.volumeName, va.Spec.NodeName)
+		kc.eventRecorder.Eventf(pod, corev1.EventTypeNormal, constant.EventReasonDelete,
+			"Deleted stale volume attachment %v of volume %v on node %v that is down", va.Name, candidate.volumeName, va.Spec.NodeName)
+	}
+
+	for _, candidate := range candidates {
+		if err := kc.cleanupStaleAttachmentTickets(pod, candidate, pods, existingVolumeAttachments); err != nil {
+			return err
+		}
+	}
+
+	if waitForDeletion {
+		kc.enqueuePodAfter(pod, staleAttachmentRecheckInterval)
+	}
+	return nil
+}
+
+// getStaleAttachmentCandidates returns the Longhorn RWO volumes used by the pod, by the names of their PVs. The RWX
+// volumes are skipped since they can be attached to multiple nodes.
+func (kc *KubernetesStaleAttachmentController) getStaleAttachmentCandidates(pod *corev1.Pod) (map[string]staleAttachmentCandidate, error) {
+	candidates := map[string]staleAttachmentCandidate{}
+	for _, podVolume := range pod.Spec.Volumes {
+		if podVolume.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := kc.ds.GetPersistentVolumeClaimRO(pod.Namespace, podVolume.PersistentVolumeClaim.ClaimName)
+		if err != nil {
+			if datastore.ErrorIsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := kc.ds.GetPersistentVolumeRO(pvc.Spec.VolumeName)
+		if err != nil {
+			if datastore.ErrorIsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != types.LonghornDriverName {
+			continue
+		}
+		volume, err := kc.ds.GetVolumeRO(pv.Spec.CSI.VolumeHandle)
+		if err != nil {
+			if datastore.ErrorIsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if volume.Spec.AccessMode != longhorn.AccessModeReadWriteOnce {
+			continue
+		}
+		candidates[pv.Name] = staleAttachmentCandidate{
+			pvcName:    pvc.Name,
+			volumeName: volume.Name,
+		}
+	}
+	return candidates, nil
+}
+
+// isStaleAttachment checks if the attachment of the volume of the PVC to the node is stale, which means the node is
+// down and no pod on the node uses the PVC anymore. The pods that are still terminating on the node are waited for,
+// since they are deleted either by the setting Pod Deletion Policy When Node is Down or by the users.
+func (kc *KubernetesStaleAttachmentController) isStaleAttachment(nodeName, pvcName string, pods []*corev1.Pod) (bool, error) {
+	if nodeName == "" {
+		return false, nil
+	}
+	isNodeDown, err := kc.ds.IsNodeDownOrDeleted(nodeName)
+	if err != nil {
+		return false, err
+	}
+	if !isNodeDown {
+		return false, nil
+	}
+	for _, pod := range pods {
+		if pod.Spec.NodeName != nodeName {
+			continue
+		}
+		for _, podVolume := range pod.Spec.Volumes {
+			if podVolume.PersistentVolumeClaim != nil && podVolume.PersistentVolumeClaim.ClaimName == pvcName {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// cleanupStaleAttachmentTickets removes the CSI attachment tickets of the volume to the nodes that are down if their
+// VolumeAttachments are already gone, in case the CSI attacher couldn't remove them when detaching the volume.
+func (kc *KubernetesStaleAttachmentController) cleanupStaleAttachmentTickets(pod *corev1.Pod, candidate staleAttachmentCandidate, pods []*corev1.Pod, existingVolumeAttachments map[string]bool) error {
+	va, err := kc.ds.GetLHVolumeAttachmentByVolumeName(candidate.volumeName)
+	if err != nil {
+		if datastore.ErrorIsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	staleTicketIDs := []string{}
+	for id, ticket := range va.Spec.AttachmentTickets {
+		if ticket.Type != longhorn.AttacherTypeCSIAttacher || ticket.NodeID == pod.Spec.NodeName || existingVolumeAttachments[id] {
+			continue
+		}
+		isStale, err := kc.isStaleAttachment(ticket.NodeID, candidate.pvcName, pods)
+		if err != nil {
+			return err
+		}
+		if isStale {
+			staleTicketIDs = append(staleTicketIDs, id)
+		}
+	}
+	if len(staleTicketIDs) == 0 {
+		return nil
+	}
+
+	for _, id := range staleTicketIDs {
+		delete(va.Spec.AttachmentTickets, id)
+	}
+	if _, err := kc.ds.UpdateLHVolumeAttachment(va); err != nil {
+		return err
+	}
+	getLoggerForPod(kc.logger, pod).Infof("Removed stale attachment tickets %v of volume %v on nodes that are down", staleTicketIDs, candidate.volumeName)
+	return nil
+}
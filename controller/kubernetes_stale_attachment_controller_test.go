@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+
+	. "gopkg.in/check.v1"
+)
+
+type staleAttachmentTestCase struct {
+	enabled      bool
+	oldNodeReady bool
+	oldPodExists bool
+
+	expectCleanup bool
+}
+
+func newStorageVolumeAttachment(pvName, nodeName string) *storagev1.VolumeAttachment {
+	return &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("csi-%v-%v", pvName, nodeName),
+		},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: types.LonghornDriverName,
+			NodeName: nodeName,
+			Source: storagev1.VolumeAttachmentSource{
+				PersistentVolumeName: &pvName,
+			},
+		},
+	}
+}
+
+func (s *TestSuite) TestKubernetesStaleAttachmentCleanup(c *C) {
+	testCases := map[string]staleAttachmentTestCase{
+		"old pod is gone from the node that is down": {
+			enabled:       true,
+			expectCleanup: true,
+		},
+		"old pod is still terminating on the node that is down": {
+			enabled:      true,
+			oldPodExists: true,
+		},
+		"old node is ready": {
+			enabled:      true,
+			oldNodeReady: true,
+		},
+		"setting is disabled": {},
+	}
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		lhClient := lhfake.NewSimpleClientset()
+		extensionsClient := apiextensionsfake.NewSimpleClientset()
+		informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+		settingIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Settings().Informer().GetIndexer()
+		nodeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Nodes().Informer().GetIndexer()
+		volumeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Volumes().Informer().GetIndexer()
+		lhVolumeAttachmentIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().VolumeAttachments().Informer().GetIndexer()
+		pvIndexer := informerFactories.KubeInformerFactory.Core().V1().PersistentVolumes().Informer().GetIndexer()
+		pvcIndexer := informerFactories.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
+		podIndexer := informerFactories.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+		volumeAttachmentIndexer := informerFactories.KubeInformerFactory.Storage().V1().VolumeAttachments().Informer().GetIndexer()
+
+		ds := datastore.NewDataStore(TestNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+		kc, err := NewKubernetesStaleAttachmentController(logrus.StandardLogger(), ds, scheme.Scheme, kubeClient, TestNode1)
+		c.Assert(err, IsNil)
+		kc.eventRecorder = record.NewFakeRecorder(100)
+
+		setting, err := lhClient.LonghornV1beta2().Settings(TestNamespace).Create(context.TODO(),
+			newSetting(string(types.SettingNameNodeDownStaleAttachmentCleanup), fmt.Sprintf("%v", tc.enabled)), metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(settingIndexer.Add(setting), IsNil)
+
+		oldNodeStatus, oldNodeReason := longhorn.ConditionStatusFalse, string(longhorn.NodeConditionReasonKubernetesNodeNotReady)
+		if tc.oldNodeReady {
+			oldNodeStatus, oldNodeReason = longhorn.ConditionStatusTrue, ""
+		}
+		for _, node := range []*longhorn.Node{
+			newNode(TestNode1, TestNamespace, true, longhorn.ConditionStatusTrue, ""),
+			newNode(TestNode2, TestNamespace, true, oldNodeStatus, oldNodeReason),
+		} {
+			node, err = lhClient.LonghornV1beta2().Nodes(TestNamespace).Create(context.TODO(), node, metav1.CreateOptions{})
+			c.Assert(err, IsNil)
+			c.Assert(nodeIndexer.Add(node), IsNil)
+		}
+
+		volume := newVolume(TestVolumeName, 2)
+		volume.Spec.AccessMode = longhorn.AccessModeReadWriteOnce
+		volume, err = lhClient.LonghornV1beta2().Volumes(TestNamespace).Create(context.TODO(), volume, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(volumeIndexer.Add(volume), IsNil)
+
+		pv, err := kubeClient.CoreV1().PersistentVolumes().Create(context.TODO(), newPV(), metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(pvIndexer.Add(pv), IsNil)
+
+		pvc := newPVC()
+		pvc.Namespace = TestNamespace
+		pvc, err = kubeClient.CoreV1().PersistentVolumeClaims(TestNamespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(pvcIndexer.Add(pvc), IsNil)
+
+		newPod := newPodWithPVC(TestPod1)
+		newPod.Spec.NodeName = TestNode1
+		newPod.Status.Phase = corev1.PodPending
+		pods := []*corev1.Pod{newPod}
+		if tc.oldPodExists {
+			oldPod := newPodWithPVC(TestPod2)
+			oldPod.Spec.NodeName = TestNode2
+			oldPod.DeletionTimestamp = &metav1.Time{}
+			pods = append(pods, oldPod)
+		}
+		for _, pod := range pods {
+			pod, err = kubeClient.CoreV1().Pods(TestNamespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+			c.Assert(err, IsNil)
+			c.Assert(podIndexer.Add(pod), IsNil)
+		}
+
+		staleVolumeAttachment, err := kubeClient.StorageV1().VolumeAttachments().Create(context.TODO(),
+			newStorageVolumeAttachment(TestPVName, TestNode2), metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(volumeAttachmentIndexer.Add(staleVolumeAttachment), IsNil)
+
+		// The ticket whose VolumeAttachment is already gone
+		lhVolumeAttachment := newVolumeAttachment(TestVolumeName)
+		lhVolumeAttachment.Spec.AttachmentTickets = map[string]*longhorn.AttachmentTicket{
+			"csi-gone": {
+				ID:     "csi-gone",
+				Type:   longhorn.AttacherTypeCSIAttacher,
+				NodeID: TestNode2,
+			},
+		}
+		lhVolumeAttachment, err = lhClient.LonghornV1beta2().VolumeAttachments(TestNamespace).Create(context.TODO(), lhVolumeAttachment, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(lhVolumeAttachmentIndexer.Add(lhVolumeAttachment), IsNil)
+
+		err = kc.syncHandler(getKey(newPod, c))
+		c.Assert(err, IsNil)
+
+		volumeAttachments, err := kubeClient.StorageV1().VolumeAttachments().List(context.TODO(), metav1.ListOptions{})
+		c.Assert(err, IsNil)
+		lhVolumeAttachment, err = lhClient.LonghornV1beta2().VolumeAttachments(TestNamespace).Get(context.TODO(), lhVolumeAttachment.Name, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+		if tc.expectCleanup {
+			c.Assert(volumeAttachments.Items, HasLen, 0)
+			c.Assert(lhVolumeAttachment.Spec.AttachmentTickets, HasLen, 0)
+		} else {
+			c.Assert(volumeAttachments.Items, HasLen, 1)
+			c.Assert(lhVolumeAttachment.Spec.AttachmentTickets, HasLen, 1)
+		}
+	}
+}
@@ -0,0 +1,481 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	maintenancePolicyKey = "maintenance-policy"
+
+	maintenancePolicyResyncPeriod = 5 * time.Minute
+)
+
+// MaintenancePolicyController applies the rules of the maintenance policies to the volumes and nodes matching
+// their selectors.
+type MaintenancePolicyController struct {
+	*baseController
+
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the controller
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds         *datastore.DataStore
+	cacheSyncs []cache.InformerSynced
+}
+
+func NewMaintenancePolicyController(
+	logger logrus.FieldLogger,
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	kubeClient clientset.Interface,
+	controllerID string,
+	namespace string,
+) (*MaintenancePolicyController, error) {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+
+	mpc := &MaintenancePolicyController{
+		baseController: newBaseController("longhorn-maintenance-policy", logger),
+
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		ds: ds,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-maintenance-policy-controller"}),
+	}
+
+	var err error
+	if _, err = ds.MaintenancePolicyInformer.AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { mpc.enqueue() },
+		UpdateFunc: func(old, cur interface{}) { mpc.enqueue() },
+		DeleteFunc: func(obj interface{}) { mpc.enqueue() },
+	}, maintenancePolicyResyncPeriod); err != nil {
+		return nil, err
+	}
+	mpc.cacheSyncs = append(mpc.cacheSyncs, ds.MaintenancePolicyInformer.HasSynced)
+
+	if _, err = ds.VolumeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { mpc.enqueue() },
+		UpdateFunc: mpc.enqueueVolumeChange,
+	}); err != nil {
+		return nil, err
+	}
+	mpc.cacheSyncs = append(mpc.cacheSyncs, ds.VolumeInformer.HasSynced)
+
+	if _, err = ds.NodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { mpc.enqueue() },
+		UpdateFunc: mpc.enqueueNodeChange,
+	}); err != nil {
+		return nil, err
+	}
+	mpc.cacheSyncs = append(mpc.cacheSyncs, ds.NodeInformer.HasSynced)
+
+	if _, err = ds.KubeNodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: mpc.enqueueKubernetesNodeChange,
+	}); err != nil {
+		return nil, err
+	}
+	mpc.cacheSyncs = append(mpc.cacheSyncs, ds.KubeNodeInformer.HasSynced)
+
+	return mpc, nil
+}
+
+func (mpc *MaintenancePolicyController) enqueue() {
+	mpc.queue.Add(maintenancePolicyKey)
+}
+
+// enqueueVolumeChange only reacts to the changes that can affect the rules applied to the volume, since the volume
+// status changes all the time.
+func (mpc *MaintenancePolicyController) enqueueVolumeChange(old, cur interface{}) {
+	oldVolume, ok := old.(*longhorn.Volume)
+	if !ok {
+		return
+	}
+	curVolume, ok := cur.(*longhorn.Volume)
+	if !ok {
+		return
+	}
+	if !reflect.DeepEqual(oldVolume.Labels, curVolume.Labels) || !reflect.DeepEqual(oldVolume.Spec, curVolume.Spec) {
+		mpc.enqueue()
+	}
+}
+
+func (mpc *MaintenancePolicyController) enqueueNodeChange(old, cur interface{}) {
+	oldNode, ok := old.(*longhorn.Node)
+	if !ok {
+		return
+	}
+	curNode, ok := cur.(*longhorn.Node)
+	if !ok {
+		return
+	}
+	if !reflect.DeepEqual(oldNode.Labels, curNode.Labels) || oldNode.Spec.AllowScheduling != curNode.Spec.AllowScheduling {
+		mpc.enqueue()
+	}
+}
+
+func (mpc *MaintenancePolicyController) enqueueKubernetesNodeChange(old, cur interface{}) {
+	oldNode, ok := old.(*corev1.Node)
+	if !ok {
+		return
+	}
+	curNode, ok := cur.(*corev1.Node)
+	if !ok {
+		return
+	}
+	if !reflect.DeepEqual(oldNode.Labels, curNode.Labels) {
+		mpc.enqueue()
+	}
+}
+
+func (mpc *MaintenancePolicyController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer mpc.queue.ShutDown()
+
+	mpc.logger.Info("Starting Longhorn maintenance policy controller")
+	defer mpc.logger.Info("Shut down Longhorn maintenance policy controller")
+
+	if !cache.WaitForNamedCacheSync(mpc.name, stopCh, mpc.cacheSyncs...) {
+		return
+	}
+
+	// There is only one key in the queue, so a single worker is enough
+	go wait.Until(mpc.worker, time.Second, stopCh)
+
+	<-stopCh
+}
+
+func (mpc *MaintenancePolicyController) worker() {
+	for mpc.processNextWorkItem() {
+	}
+}
+
+func (mpc *MaintenancePolicyController) processNextWorkItem() bool {
+	key, quit := mpc.queue.Get()
+	if quit {
+		return false
+	}
+	defer mpc.queue.Done(key)
+	err := mpc.syncHandler(key.(string))
+	mpc.handleErr(err, key)
+	return true
+}
+
+func (mpc *MaintenancePolicyController) handleErr(err error, key interface{}) {
+	if err == nil {
+		mpc.queue.Forget(key)
+		return
+	}
+
+	handleReconcileErrorLogging(mpc.logger, err, "Failed to sync Longhorn maintenance policies")
+	mpc.queue.AddRateLimited(key)
+}
+
+func (mpc *MaintenancePolicyController) syncHandler(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "%v: failed to sync %v", mpc.name, key)
+	}()
+
+	// Only one manager applies the policies, otherwise the managers can apply them concurrently
+	responsibleNodeID, err := getResponsibleNodeID(mpc.ds)
+	if err != nil {
+		return err
+	}
+	if responsibleNodeID != mpc.controllerID {
+		return nil
+	}
+
+	return mpc.reconcile()
+}
+
+func (mpc *MaintenancePolicyController) reconcile() error {
+	policies, err := mpc.ds.ListMaintenancePoliciesRO()
+	if err != nil {
+		return errors.Wrap(err, "failed to list maintenance policies")
+	}
+
+	matchedResources := map[string][]string{}
+	for name := range policies {
+		matchedResources[name] = []string{}
+	}
+
+	var errs []error
+	if err := mpc.reconcileVolumes(policies, matchedResources); err != nil {
+		errs = append(errs, err)
+	}
+	if err := mpc.reconcileNodes(policies, matchedResources); err != nil {
+		errs = append(errs, err)
+	}
+
+	for name, policy := range policies {
+		resources := matchedResources[name]
+		sort.Strings(resources)
+		if reflect.DeepEqual(policy.Status.MatchedResources, resources) {
+			continue
+		}
+		policy = policy.DeepCopy()
+		policy.Status.MatchedResources = resources
+		if _, err := mpc.ds.UpdateMaintenancePolicyStatus(policy); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(err, "failed to update the status of maintenance policy %v", name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+func (mpc *MaintenancePolicyController) reconcileVolumes(policies map[string]*longhorn.MaintenancePolicy, matchedResources map[string][]string) error {
+	volumes, err := mpc.ds.ListVolumesRO()
+	if err != nil {
+		return errors.Wrap(err, "failed to list volumes")
+	}
+
+	var errs []error
+	for _, volume := range volumes {
+		if !volume.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		matched := getMatchingMaintenancePolicies(policies, longhorn.MaintenancePolicyResourceTypeVolume, volume.Labels)
+		for _, policy := range matched {
+			matchedResources[policy.Name] = append(matchedResources[policy.Name], volume.Name)
+		}
+
+		rules := mergeMaintenancePolicyVolumeRules(matched)
+		updated := volume.DeepCopy()
+		if !applyMaintenancePolicyVolumeRules(updated, rules) {
+			continue
+		}
+		mpc.logger.WithField("volume", volume.Name).Infof("Applying the rules of maintenance policies %v", getMaintenancePolicyNames(matched))
+		if _, err := mpc.ds.UpdateVolume(updated); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(err, "failed to apply the maintenance policies to volume %v", volume.Name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+func (mpc *MaintenancePolicyController) reconcileNodes(policies map[string]*longhorn.MaintenancePolicy, matchedResources map[string][]string) error {
+	nodes, err := mpc.ds.ListNodesRO()
+	if err != nil {
+		return errors.Wrap(err, "failed to list nodes")
+	}
+
+	var errs []error
+	for _, node := range nodes {
+		if !node.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		kubeNode, err := mpc.ds.GetKubernetesNodeRO(node.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			errs = append(errs, errors.Wrapf(err, "failed to get Kubernetes node %v", node.Name))
+			continue
+		}
+
+		matched := getMatchingMaintenancePolicies(policies, longhorn.MaintenancePolicyResourceTypeNode, kubeNode.Labels)
+		for _, policy := range matched {
+			matchedResources[policy.Name] = append(matchedResources[policy.Name], node.Name)
+		}
+
+		rules := mergeMaintenancePolicyNodeRules(matched)
+		updated := node.DeepCopy()
+		if !applyMaintenancePolicyNodeRules(updated, rules) {
+			continue
+		}
+		mpc.logger.WithField("node", node.Name).Infof("Applying the rules of maintenance policies %v", getMaintenancePolicyNames(matched))
+		if _, err := mpc.ds.UpdateNode(updated); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(err, "failed to apply the maintenance policies to node %v", node.Name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+func isMaintenancePolicySelectorMatched(selector, labels map[string]string) bool {
+	for key, value := range selector {
+		if labelValue, ok := labels[key]; !ok || labelValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// getMatchingMaintenancePolicies returns the policies of the resource type matching the labels, in the order they
+// are applied: the rules of a policy override the rules of the policies before it.
+func getMatchingMaintenancePolicies(policies map[string]*longhorn.MaintenancePolicy, resourceType longhorn.MaintenancePolicyResourceType, labels map[string]string) []*longhorn.MaintenancePolicy {
+	matched := []*longhorn.MaintenancePolicy{}
+	for _, policy := range policies {
+		if !policy.DeletionTimestamp.IsZero() || policy.Spec.ResourceType != resourceType {
+			continue
+		}
+		if isMaintenancePolicySelectorMatched(policy.Spec.Selector, labels) {
+			matched = append(matched, policy)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].Spec.Priority != matched[j].Spec.Priority {
+			return matched[i].Spec.Priority < matched[j].Spec.Priority
+		}
+		return matched[i].Name < matched[j].Name
+	})
+	return matched
+}
+
+func getMaintenancePolicyNames(policies []*longhorn.MaintenancePolicy) []string {
+	names := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		names = append(names, policy.Name)
+	}
+	return names
+}
+
+func mergeMaintenancePolicyVolumeRules(policies []*longhorn.MaintenancePolicy) *longhorn.MaintenancePolicyVolumeRules {
+	merged := &longhorn.MaintenancePolicyVolumeRules{}
+	for _, policy := range policies {
+		rules := policy.Spec.VolumeRules
+		if rules == nil {
+			continue
+		}
+		if rules.NumberOfReplicas != nil {
+			merged.NumberOfReplicas = rules.NumberOfReplicas
+		}
+		if rules.DataLocality != nil {
+			merged.DataLocality = rules.DataLocality
+		}
+		if rules.SnapshotMaxCount != nil {
+			merged.SnapshotMaxCount = rules.SnapshotMaxCount
+		}
+		if rules.TrimFilesystemOnUnstage != nil {
+			merged.TrimFilesystemOnUnstage = rules.TrimFilesystemOnUnstage
+		}
+		if rules.UnmapMarkSnapChainRemoved != nil {
+			merged.UnmapMarkSnapChainRemoved = rules.UnmapMarkSnapChainRemoved
+		}
+		if rules.RecurringBackupDisabled != nil {
+			merged.RecurringBackupDisabled = rules.RecurringBackupDisabled
+		}
+	}
+	return merged
+}
+
+func mergeMaintenancePolicyNodeRules(policies []*longhorn.MaintenancePolicy) *longhorn.MaintenancePolicyNodeRules {
+	merged := &longhorn.MaintenancePolicyNodeRules{}
+	for _, policy := range policies {
+		rules := policy.Spec.NodeRules
+		if rules == nil {
+			continue
+		}
+		if rules.AllowScheduling != nil {
+			merged.AllowScheduling = rules.AllowScheduling
+		}
+		if rules.SoleReplicaDisallowed != nil {
+			merged.SoleReplicaDisallowed = rules.SoleReplicaDisallowed
+		}
+	}
+	return merged
+}
+
+// setMaintenancePolicyLabel sets the label owned by the policies if the rule is enabled, and removes it otherwise, so
+// the label goes away once no policy sets the rule anymore. It returns true if the labels are changed.
+func setMaintenancePolicyLabel(labels *map[string]string, label string, enabled *bool) bool {
+	key := types.GetLonghornLabelKey(label)
+	_, exists := (*labels)[key]
+	if enabled != nil && *enabled {
+		if exists {
+			return false
+		}
+		if *labels == nil {
+			*labels = map[string]string{}
+		}
+		(*labels)[key] = types.LonghornLabelValueEnabled
+		return true
+	}
+	if !exists {
+		return false
+	}
+	delete(*labels, key)
+	return true
+}
+
+// applyMaintenancePolicyVolumeRules applies the rules to the volume. The spec fields not set by the rules are left
+// as they are. It returns true if the volume is changed.
+func applyMaintenancePolicyVolumeRules(v *longhorn.Volume, rules *longhorn.MaintenancePolicyVolumeRules) bool {
+	changed := false
+	if rules.NumberOfReplicas != nil && v.Spec.NumberOfReplicas != *rules.NumberOfReplicas {
+		v.Spec.NumberOfReplicas = *rules.NumberOfReplicas
+		changed = true
+	}
+	if rules.DataLocality != nil && v.Spec.DataLocality != *rules.DataLocality {
+		v.Spec.DataLocality = *rules.DataLocality
+		changed = true
+	}
+	if rules.SnapshotMaxCount != nil && v.Spec.SnapshotMaxCount != *rules.SnapshotMaxCount {
+		v.Spec.SnapshotMaxCount = *rules.SnapshotMaxCount
+		changed = true
+	}
+	if rules.TrimFilesystemOnUnstage != nil && v.Spec.TrimFilesystemOnUnstage != *rules.TrimFilesystemOnUnstage {
+		v.Spec.TrimFilesystemOnUnstage = *rules.TrimFilesystemOnUnstage
+		changed = true
+	}
+	if rules.UnmapMarkSnapChainRemoved != nil && v.Spec.UnmapMarkSnapChainRemoved != *rules.UnmapMarkSnapChainRemoved {
+		v.Spec.UnmapMarkSnapChainRemoved = *rules.UnmapMarkSnapChainRemoved
+		changed = true
+	}
+	if setMaintenancePolicyLabel(&v.Labels, types.LonghornLabelRecurringBackupDisabled, rules.RecurringBackupDisabled) {
+		changed = true
+	}
+	return changed
+}
+
+// applyMaintenancePolicyNodeRules applies the rules to the node. The spec fields not set by the rules are left as
+// they are. It returns true if the node is changed.
+func applyMaintenancePolicyNodeRules(node *longhorn.Node, rules *longhorn.MaintenancePolicyNodeRules) bool {
+	changed := false
+	if rules.AllowScheduling != nil && node.Spec.AllowScheduling != *rules.AllowScheduling {
+		node.Spec.AllowScheduling = *rules.AllowScheduling
+		changed = true
+	}
+	if setMaintenancePolicyLabel(&node.Labels, types.LonghornLabelSoleReplicaDisallowed, rules.SoleReplicaDisallowed) {
+		changed = true
+	}
+	return changed
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestApplyMaintenancePolicies(c *C) {
+	intPtr := func(i int) *int { return &i }
+	boolPtr := func(b bool) *bool { return &b }
+	newPolicy := func(name string, priority int, selector map[string]string, rules *longhorn.MaintenancePolicyVolumeRules) *longhorn.MaintenancePolicy {
+		return &longhorn.MaintenancePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: longhorn.MaintenancePolicySpec{
+				ResourceType: longhorn.MaintenancePolicyResourceTypeVolume,
+				Selector:     selector,
+				Priority:     priority,
+				VolumeRules:  rules,
+			},
+		}
+	}
+	backupDisabledLabel := types.GetLonghornLabelKey(types.LonghornLabelRecurringBackupDisabled)
+
+	policies := map[string]*longhorn.MaintenancePolicy{
+		"dev": newPolicy("dev", 0, map[string]string{"tier": "dev"}, &longhorn.MaintenancePolicyVolumeRules{
+			NumberOfReplicas:        intPtr(1),
+			TrimFilesystemOnUnstage: boolPtr(true),
+			RecurringBackupDisabled: boolPtr(true),
+		}),
+		"dev-critical": newPolicy("dev-critical", 10, map[string]string{"tier": "dev", "critical": "true"}, &longhorn.MaintenancePolicyVolumeRules{
+			NumberOfReplicas:        intPtr(3),
+			RecurringBackupDisabled: boolPtr(false),
+		}),
+		"all": newPolicy("all", 0, nil, &longhorn.MaintenancePolicyVolumeRules{
+			SnapshotMaxCount: intPtr(10),
+			NumberOfReplicas: intPtr(2),
+		}),
+	}
+	policies["nodes"] = &longhorn.MaintenancePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes"},
+		Spec: longhorn.MaintenancePolicySpec{
+			ResourceType: longhorn.MaintenancePolicyResourceTypeNode,
+			NodeRules:    &longhorn.MaintenancePolicyNodeRules{SoleReplicaDisallowed: boolPtr(true)},
+		},
+	}
+
+	// the policies of the same priority are applied in the order of their names
+	matched := getMatchingMaintenancePolicies(policies, longhorn.MaintenancePolicyResourceTypeVolume, map[string]string{"tier": "dev"})
+	c.Assert(getMaintenancePolicyNames(matched), DeepEquals, []string{"all", "dev"})
+	v := &longhorn.Volume{}
+	v.Spec.NumberOfReplicas = 3
+	c.Assert(applyMaintenancePolicyVolumeRules(v, mergeMaintenancePolicyVolumeRules(matched)), Equals, true)
+	c.Assert(v.Spec.NumberOfReplicas, Equals, 1)
+	c.Assert(v.Spec.SnapshotMaxCount, Equals, 10)
+	c.Assert(v.Spec.TrimFilesystemOnUnstage, Equals, true)
+	c.Assert(v.Labels[backupDisabledLabel], Equals, types.LonghornLabelValueEnabled)
+	c.Assert(applyMaintenancePolicyVolumeRules(v, mergeMaintenancePolicyVolumeRules(matched)), Equals, false)
+
+	// a policy of a higher priority overrides the rules, and the label goes away
+	matched = getMatchingMaintenancePolicies(policies, longhorn.MaintenancePolicyResourceTypeVolume, map[string]string{"tier": "dev", "critical": "true"})
+	c.Assert(getMaintenancePolicyNames(matched), DeepEquals, []string{"all", "dev", "dev-critical"})
+	c.Assert(applyMaintenancePolicyVolumeRules(v, mergeMaintenancePolicyVolumeRules(matched)), Equals, true)
+	c.Assert(v.Spec.NumberOfReplicas, Equals, 3)
+	c.Assert(v.Spec.TrimFilesystemOnUnstage, Equals, true)
+	_, exists := v.Labels[backupDisabledLabel]
+	c.Assert(exists, Equals, false)
+
+	// the node policies only match the nodes
+	matched = getMatchingMaintenancePolicies(policies, longhorn.MaintenancePolicyResourceTypeNode, map[string]string{"tier": "dev"})
+	c.Assert(getMaintenancePolicyNames(matched), DeepEquals, []string{"nodes"})
+	node := &longhorn.Node{}
+	node.Spec.AllowScheduling = true
+	c.Assert(applyMaintenancePolicyNodeRules(node, mergeMaintenancePolicyNodeRules(matched)), Equals, true)
+	c.Assert(node.Spec.AllowScheduling, Equals, true)
+	c.Assert(node.Labels[types.GetLonghornLabelKey(types.LonghornLabelSoleReplicaDisallowed)], Equals, types.LonghornLabelValueEnabled)
+	c.Assert(applyMaintenancePolicyNodeRules(node, mergeMaintenancePolicyNodeRules(nil)), Equals, true)
+	c.Assert(node.Labels, HasLen, 0)
+}
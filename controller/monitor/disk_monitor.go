@@ -14,6 +14,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/wait"
 
+	lhns "github.com/longhorn/go-common-libs/ns"
 	lhtypes "github.com/longhorn/go-common-libs/types"
 
 	"github.com/longhorn/longhorn-manager/datastore"
@@ -50,6 +51,17 @@ type DiskMonitor struct {
 	getDiskConfigHandler        GetDiskConfigHandler
 	generateDiskConfigHandler   GenerateDiskConfigHandler
 	getReplicaDataStoresHandler GetReplicaDataStoresHandler
+	getDiskIOStatsHandler       GetDiskIOStatsHandler
+
+	getBackingImageDirectoriesHandler GetBackingImageDirectoriesHandler
+
+	diskIOSamplesLock sync.Mutex
+	diskIOSamples     map[string]diskIOSample
+}
+
+type diskIOSample struct {
+	stats     *diskIOStats
+	sampledAt time.Time
 }
 
 type CollectedDiskInfo struct {
@@ -61,13 +73,19 @@ type CollectedDiskInfo struct {
 	DiskDriver                longhorn.DiskDriver
 	Condition                 *longhorn.Condition
 	OrphanedReplicaDataStores map[string]string
-	InstanceManagerName       string
+	// OrphanedBackingImageDirectories maps the orphaned backing image directory names to the backing file sizes
+	OrphanedBackingImageDirectories map[string]int64
+	InstanceManagerName             string
+	IOUtilization                   int
+	IOLatency                       int64
 }
 
 type GetDiskStatHandler func(longhorn.DiskType, string, string, longhorn.DiskDriver, *DiskServiceClient) (*lhtypes.DiskStat, error)
 type GetDiskConfigHandler func(longhorn.DiskType, string, string, longhorn.DiskDriver, *DiskServiceClient) (*util.DiskConfig, error)
 type GenerateDiskConfigHandler func(longhorn.DiskType, string, string, string, string, *DiskServiceClient) (*util.DiskConfig, error)
 type GetReplicaDataStoresHandler func(longhorn.DiskType, *longhorn.Node, string, string, string, string, *DiskServiceClient) (map[string]string, error)
+type GetDiskIOStatsHandler func(string) (*diskIOStats, error)
+type GetBackingImageDirectoriesHandler func(*longhorn.Node, string, string, string) (map[string]int64, error)
 
 func NewDiskMonitor(logger logrus.FieldLogger, ds *datastore.DataStore, nodeName string, syncCallback func(key string)) (*DiskMonitor, error) {
 	ctx, quit := context.WithCancel(context.Background())
@@ -87,6 +105,11 @@ func NewDiskMonitor(logger logrus.FieldLogger, ds *datastore.DataStore, nodeName
 		getDiskConfigHandler:        getDiskConfig,
 		generateDiskConfigHandler:   generateDiskConfig,
 		getReplicaDataStoresHandler: getReplicaDataStores,
+		getDiskIOStatsHandler:       getDiskIOStats,
+
+		getBackingImageDirectoriesHandler: getBackingImageDirectories,
+
+		diskIOSamples: make(map[string]diskIOSample),
 	}
 
 	go m.Start()
@@ -323,11 +346,41 @@ func (m *DiskMonitor) collectDiskData(node *longhorn.Node) map[string]*Collected
 
 		diskInfoMap[diskName] = NewDiskInfo(diskConfig.DiskName, diskConfig.DiskUUID, disk.Path, diskConfig.DiskDriver, nodeOrDiskEvicted, stat,
 			orphanedReplicaDataStores, instanceManagerName, string(longhorn.DiskConditionReasonNoDiskInfo), "")
+		if disk.Type == longhorn.DiskTypeFilesystem {
+			diskInfoMap[diskName].IOUtilization, diskInfoMap[diskName].IOLatency = m.getDiskIOLoad(diskName, disk.Path)
+
+			backingImageDirectories, err := m.getBackingImageDirectoriesHandler(node, diskName, diskConfig.DiskUUID, disk.Path)
+			if err != nil {
+				m.logger.WithError(err).Debugf("Failed to get backing image directories for disk %v(%v) on node %v", diskName, disk.Path, node.Name)
+			}
+			diskInfoMap[diskName].OrphanedBackingImageDirectories = m.getOrphanedBackingImageDirectories(diskConfig.DiskUUID, backingImageDirectories)
+		}
 	}
 
 	return diskInfoMap
 }
 
+// getDiskIOLoad samples the IO stats of the disk and returns the IO utilization and the average IO latency since the
+// previous sample. Both are 0 for the first sample or if the IO stats cannot be retrieved.
+func (m *DiskMonitor) getDiskIOLoad(diskName, diskPath string) (int, int64) {
+	stats, err := m.getDiskIOStatsHandler(diskPath)
+	if err != nil {
+		m.logger.WithError(err).Debugf("Failed to get IO stats of disk %v(%v)", diskName, diskPath)
+		return 0, 0
+	}
+
+	m.diskIOSamplesLock.Lock()
+	defer m.diskIOSamplesLock.Unlock()
+
+	now := time.Now()
+	prev, exists := m.diskIOSamples[diskName]
+	m.diskIOSamples[diskName] = diskIOSample{stats: stats, sampledAt: now}
+	if !exists {
+		return 0, 0
+	}
+	return calculateDiskIOUtilization(prev.stats.ioTicks, stats.ioTicks, now.Sub(prev.sampledAt)), calculateDiskIOLatency(prev.stats, stats)
+}
+
 func isNodeOrDiskEvicted(node *longhorn.Node, disk longhorn.DiskSpec) bool {
 	return node.Spec.EvictionRequested || disk.EvictionRequested
 }
@@ -357,6 +410,29 @@ func getReplicaDirectoryNames(node *longhorn.Node, diskName, diskUUID, diskPath
 	return possibleReplicaDirectoryNames, nil
 }
 
+func getBackingImageDirectories(node *longhorn.Node, diskName, diskUUID, diskPath string) (map[string]int64, error) {
+	backingImageDirectories := map[string]int64{}
+	if !canCollectDiskData(node, diskName, diskUUID, diskPath) {
+		return backingImageDirectories, nil
+	}
+
+	directoryNames, err := util.GetPossibleBackingImageDirectoryNames(diskPath)
+	if err != nil {
+		return backingImageDirectories, err
+	}
+
+	for directoryName := range directoryNames {
+		var size int64
+		fileInfo, err := lhns.GetFileInfo(filepath.Join(types.GetBackingImageManagerDirectoryOnHost(diskPath), directoryName, types.BackingImageFileName))
+		if err == nil {
+			size = fileInfo.Size()
+		}
+		backingImageDirectories[directoryName] = size
+	}
+
+	return backingImageDirectories, nil
+}
+
 func canCollectDiskData(node *longhorn.Node, diskName, diskUUID, diskPath string) bool {
 	return !node.Spec.EvictionRequested &&
 		!node.Spec.Disks[diskName].EvictionRequested &&
@@ -447,6 +523,48 @@ func (m *DiskMonitor) getOrphanedReplicaDirectoryNames(diskUUID, diskPath string
 	return replicaDataStores, nil
 }
 
+// getOrphanedBackingImageDirectories filters out the backing image directories still used by a backing image or a
+// backing image manager of the disk.
+func (m *DiskMonitor) getOrphanedBackingImageDirectories(diskUUID string, backingImageDirectories map[string]int64) map[string]int64 {
+	if len(backingImageDirectories) == 0 {
+		return map[string]int64{}
+	}
+
+	bims, err := m.ds.ListBackingImageManagersByDiskUUID(diskUUID)
+	if err != nil {
+		m.logger.WithError(err).Errorf("Failed to list backing image managers for disk UUID %v", diskUUID)
+		return map[string]int64{}
+	}
+	for _, bim := range bims {
+		for biName, biUUID := range bim.Spec.BackingImages {
+			delete(backingImageDirectories, types.GetBackingImageDirectoryName(biName, biUUID))
+		}
+	}
+
+	for directoryName := range backingImageDirectories {
+		biName, biUUID, err := types.GetBackingImageNameAndUUIDFromDirectoryName(directoryName)
+		if err != nil {
+			delete(backingImageDirectories, directoryName)
+			continue
+		}
+		bi, err := m.ds.GetBackingImageRO(biName)
+		if err != nil {
+			if !datastore.ErrorIsNotFound(err) {
+				delete(backingImageDirectories, directoryName)
+			}
+			continue
+		}
+		if bi.Status.UUID != biUUID {
+			continue
+		}
+		if _, exists := bi.Spec.DiskFileSpecMap[diskUUID]; exists {
+			delete(backingImageDirectories, directoryName)
+		}
+	}
+
+	return backingImageDirectories
+}
+
 func isVolumeMetaFileExist(diskPath, replicaDirectoryName string) error {
 	path := filepath.Join(diskPath, "replicas", replicaDirectoryName, volumeMetaData)
 	_, err := util.GetVolumeMeta(path)
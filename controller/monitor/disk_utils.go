@@ -3,10 +3,14 @@ package monitor
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
 
 	grpccodes "google.golang.org/grpc/codes"
 	grpcstatus "google.golang.org/grpc/status"
@@ -36,6 +40,85 @@ func getDiskStat(diskType longhorn.DiskType, diskName, diskPath string, diskDriv
 	}
 }
 
+// diskIOStats is the subset of the block device stat in the sysfs used to calculate the IO load of a disk.
+type diskIOStats struct {
+	// completedIOs is the number of the completed reads and writes
+	completedIOs uint64
+	// ioWaitTicks is the milliseconds the completed reads and writes have waited in total
+	ioWaitTicks uint64
+	// ioTicks is the milliseconds the device has spent doing IO
+	ioTicks uint64
+}
+
+// getDiskIOStats returns the IO stats of the block device backing the given directory.
+func getDiskIOStats(diskPath string) (*diskIOStats, error) {
+	fn := func() (interface{}, error) {
+		var st unix.Stat_t
+		if err := unix.Stat(diskPath, &st); err != nil {
+			return nil, errors.Wrapf(err, "failed to stat %v", diskPath)
+		}
+		statPath := fmt.Sprintf("/sys/dev/block/%d:%d/stat", unix.Major(st.Dev), unix.Minor(st.Dev))
+		content, err := os.ReadFile(statPath)
+		if err != nil {
+			return nil, err
+		}
+		return parseDiskIOStats(string(content))
+	}
+
+	rawResult, err := lhns.RunFunc(fn, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get disk IO stats of %v", diskPath)
+	}
+	stats, ok := rawResult.(*diskIOStats)
+	if !ok {
+		return nil, fmt.Errorf("failed to cast disk IO stats %v of %v", rawResult, diskPath)
+	}
+	return stats, nil
+}
+
+// parseDiskIOStats parses the block device stat, see https://www.kernel.org/doc/Documentation/block/stat.txt
+func parseDiskIOStats(stat string) (*diskIOStats, error) {
+	fields := strings.Fields(stat)
+	if len(fields) < 10 {
+		return nil, fmt.Errorf("invalid disk stat %q", stat)
+	}
+	values := make([]uint64, 10)
+	for i := range values {
+		value, err := strconv.ParseUint(fields[i], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid disk stat %q", stat)
+		}
+		values[i] = value
+	}
+	return &diskIOStats{
+		completedIOs: values[0] + values[4],
+		ioWaitTicks:  values[3] + values[7],
+		ioTicks:      values[9],
+	}, nil
+}
+
+// calculateDiskIOUtilization returns the percentage of the elapsed time the disk spent doing IO. The result is
+// rounded down to a multiple of 10 to avoid updating the node status on every small fluctuation.
+func calculateDiskIOUtilization(prevIOTicks, curIOTicks uint64, elapsed time.Duration) int {
+	elapsedMilliseconds := elapsed.Milliseconds()
+	if curIOTicks < prevIOTicks || elapsedMilliseconds <= 0 {
+		return 0
+	}
+	utilization := int((curIOTicks - prevIOTicks) * 100 / uint64(elapsedMilliseconds))
+	if utilization > 100 {
+		utilization = 100
+	}
+	return utilization / 10 * 10
+}
+
+// calculateDiskIOLatency returns the average milliseconds a read or write completed between the samples waited.
+func calculateDiskIOLatency(prev, cur *diskIOStats) int64 {
+	if cur.completedIOs <= prev.completedIOs || cur.ioWaitTicks < prev.ioWaitTicks {
+		return 0
+	}
+	return int64((cur.ioWaitTicks - prev.ioWaitTicks) / (cur.completedIOs - prev.completedIOs))
+}
+
 func getBlockTypeDiskStat(client *DiskServiceClient, diskName, diskPath string, diskDriver longhorn.DiskDriver) (stat *lhtypes.DiskStat, err error) {
 	if client == nil || client.c == nil {
 		return nil, errors.New("disk service client is nil")
@@ -0,0 +1,44 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDiskIOStats(t *testing.T) {
+	assert := require.New(t)
+
+	stats, err := parseDiskIOStats("  190929    36582 13391386   105658   159212   142117  7829464   452810        0   191424   593706        0        0        0        0     4530    35237\n")
+	assert.NoError(err)
+	assert.Equal(&diskIOStats{completedIOs: 350141, ioWaitTicks: 558468, ioTicks: 191424}, stats)
+
+	_, err = parseDiskIOStats("1 2 3")
+	assert.Error(err)
+	_, err = parseDiskIOStats("1 2 3 4 5 6 7 8 9 x")
+	assert.Error(err)
+}
+
+func TestCalculateDiskIOUtilization(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(0, calculateDiskIOUtilization(0, 0, 30*time.Second))
+	assert.Equal(50, calculateDiskIOUtilization(1000, 16000, 30*time.Second))
+	// rounded down to a multiple of 10
+	assert.Equal(40, calculateDiskIOUtilization(1000, 15900, 30*time.Second))
+	// capped since the IO ticks can run slightly ahead of the sampling interval
+	assert.Equal(100, calculateDiskIOUtilization(1000, 32000, 30*time.Second))
+	// the counter is reset
+	assert.Equal(0, calculateDiskIOUtilization(1000, 10, 30*time.Second))
+	assert.Equal(0, calculateDiskIOUtilization(1000, 2000, 0))
+}
+
+func TestCalculateDiskIOLatency(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(int64(0), calculateDiskIOLatency(&diskIOStats{completedIOs: 100, ioWaitTicks: 100}, &diskIOStats{completedIOs: 100, ioWaitTicks: 100}))
+	assert.Equal(int64(5), calculateDiskIOLatency(&diskIOStats{completedIOs: 100, ioWaitTicks: 100}, &diskIOStats{completedIOs: 300, ioWaitTicks: 1100}))
+	// the counters are reset
+	assert.Equal(int64(0), calculateDiskIOLatency(&diskIOStats{completedIOs: 100, ioWaitTicks: 100}, &diskIOStats{completedIOs: 10, ioWaitTicks: 10}))
+}
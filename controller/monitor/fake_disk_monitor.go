@@ -39,6 +39,11 @@ func NewFakeDiskMonitor(logger logrus.FieldLogger, ds *datastore.DataStore, node
 		getDiskConfigHandler:        fakeGetDiskConfig,
 		generateDiskConfigHandler:   fakeGenerateDiskConfig,
 		getReplicaDataStoresHandler: fakeGetReplicaDataStores,
+		getDiskIOStatsHandler:       fakeGetDiskIOStats,
+
+		getBackingImageDirectoriesHandler: fakeGetBackingImageDirectories,
+
+		diskIOSamples: make(map[string]diskIOSample),
 	}
 
 	return m, nil
@@ -50,6 +55,14 @@ func fakeGetReplicaDataStores(diskType longhorn.DiskType, node *longhorn.Node, d
 	}, nil
 }
 
+func fakeGetBackingImageDirectories(node *longhorn.Node, diskName, diskUUID, diskPath string) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
+
+func fakeGetDiskIOStats(diskPath string) (*diskIOStats, error) {
+	return &diskIOStats{}, nil
+}
+
 func fakeGetDiskStat(diskType longhorn.DiskType, name, directory string, diskDriver longhorn.DiskDriver, client *DiskServiceClient) (*lhtypes.DiskStat, error) {
 	switch diskType {
 	case longhorn.DiskTypeFilesystem:
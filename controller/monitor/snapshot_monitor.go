@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -55,6 +56,8 @@ type snapshotCheckTask struct {
 	volumeName   string
 	snapshotName string
 	changeEvent  bool
+	// scrub checks all the user created snapshots of the volume, and records the result in the replicas
+	scrub bool
 }
 
 type SnapshotMonitorStatus struct {
@@ -76,6 +79,9 @@ type SnapshotMonitor struct {
 	inProgressSnapshotCheckTasks     map[string]struct{}
 	inProgressSnapshotCheckTasksLock sync.RWMutex
 
+	// scrubAdmissionLock serializes checking the scrubbing volume count and marking the replicas as scrubbing
+	scrubAdmissionLock sync.Mutex
+
 	existingDataIntegrityCronJob string
 
 	syncCallback func(key string)
@@ -167,27 +173,27 @@ func (m *SnapshotMonitor) checkSnapshots() {
 	}()
 
 	for _, engine := range engines {
-		m.logger.WithField("monitor", monitorName).Infof("Populating engine %v snapshots", engine.Name)
-		m.populateEngineSnapshots(engine)
+		m.logger.WithField("monitor", monitorName).Infof("Scrubbing engine %v snapshots", engine.Name)
+		m.snapshotCheckTaskQueue.Add(snapshotCheckTask{
+			volumeName: engine.Spec.VolumeName,
+			scrub:      true,
+		})
 	}
 }
 
-func (m *SnapshotMonitor) populateEngineSnapshots(engine *longhorn.Engine) {
-	snapshots := engine.Status.Snapshots
-	for _, snapshot := range snapshots {
+func getEngineSnapshotsToCheck(engine *longhorn.Engine) []string {
+	snapshotNames := []string{}
+	for _, snapshot := range engine.Status.Snapshots {
 		// Skip volume-head because it is not a real snapshot.
 		// A system-generated snapshot is also ignored, because the prune operations the snapshots are out of
 		// sync during replica rebuilding. More investigation is in https://github.com/longhorn/longhorn/issues/4513
-		if snapshot.Name == etypes.VolumeHeadName || !snapshot.UserCreated {
+		if snapshot.Name == etypes.VolumeHeadName || !snapshot.UserCreated || snapshot.Removed {
 			continue
 		}
-
-		m.snapshotCheckTaskQueue.Add(snapshotCheckTask{
-			volumeName:   engine.Spec.VolumeName,
-			snapshotName: snapshot.Name,
-			changeEvent:  false,
-		})
+		snapshotNames = append(snapshotNames, snapshot.Name)
 	}
+	sort.Strings(snapshotNames)
+	return snapshotNames
 }
 
 func (m *SnapshotMonitor) processNextWorkItem(id int) bool {
@@ -208,7 +214,11 @@ func (m *SnapshotMonitor) processNextWorkItem(id int) bool {
 		return true
 	}
 
-	err = m.run(task)
+	if task.scrub {
+		err = m.scrub(task)
+	} else {
+		err = m.run(task)
+	}
 	m.handleErr(err, key)
 
 	return true
@@ -369,7 +379,199 @@ func (m *SnapshotMonitor) run(arg interface{}) error {
 		return err
 	}
 
-	return m.waitAndHandleSnapshotHashing(engine, engineClientProxy, task.snapshotName)
+	_, err = m.waitAndHandleSnapshotHashing(engine, engineClientProxy, task.snapshotName)
+	return err
+}
+
+// scrub checks the data integrity of all the user created snapshots of the volume on its healthy replicas one by
+// one, and records the result in the replica status. The scrubbing volume count in the cluster is limited by the
+// setting concurrent-replica-scrub-per-cluster-limit.
+func (m *SnapshotMonitor) scrub(task snapshotCheckTask) error {
+	engine, err := m.ds.GetVolumeCurrentEngine(task.volumeName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get engine for volume %v", task.volumeName)
+	}
+
+	replicas, err := m.ds.ListVolumeReplicasRO(task.volumeName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list replicas for volume %v", task.volumeName)
+	}
+	replicaNames := []string{}
+	for name, mode := range engine.Status.ReplicaModeMap {
+		if _, ok := replicas[name]; ok && mode == longhorn.ReplicaModeRW {
+			replicaNames = append(replicaNames, name)
+		}
+	}
+	if len(replicaNames) < 2 {
+		m.logger.WithField("monitor", monitorName).Debugf("Skipping scrubbing volume %v since it has less than 2 healthy replicas", task.volumeName)
+		return nil
+	}
+
+	if err := m.canRequestSnapshotHash(engine); err != nil {
+		return errors.Wrapf(err, etypes.CannotRequestHashingSnapshotPrefix)
+	}
+
+	admitted, err := m.admitScrub(task.volumeName, replicaNames)
+	if err != nil {
+		return err
+	}
+	if !admitted {
+		return errors.Wrapf(fmt.Errorf("the count of the scrubbing volumes in the cluster reaches the limit"), etypes.CannotRequestHashingSnapshotPrefix)
+	}
+
+	corruptedReplicaNames, err := m.scrubSnapshots(engine)
+	if err != nil {
+		m.logger.WithField("monitor", monitorName).WithError(err).Warnf("Failed to scrub volume %v", task.volumeName)
+	}
+	m.finishScrub(replicaNames, corruptedReplicaNames, err)
+	return err
+}
+
+// admitScrub marks the replicas as scrubbing, then checks the scrubbing volume count in the cluster. The volumes
+// marked earlier are admitted first, so the managers admitting the scrubbing concurrently agree on which proceed. The
+// marks are removed if the volume isn't admitted.
+func (m *SnapshotMonitor) admitScrub(volumeName string, replicaNames []string) (admitted bool, err error) {
+	m.scrubAdmissionLock.Lock()
+	defer m.scrubAdmissionLock.Unlock()
+
+	limit, err := m.ds.GetSettingAsInt(types.SettingNameConcurrentReplicaScrubPerClusterLimit)
+	if err != nil {
+		return false, err
+	}
+
+	startedAt := util.Now()
+	if err := m.updateReplicaScrubStatus(replicaNames, func(r *longhorn.Replica) {
+		r.Status.ScrubStartedAt = startedAt
+	}); err != nil {
+		return false, errors.Wrapf(err, "failed to mark replicas of volume %v as scrubbing", volumeName)
+	}
+	defer func() {
+		if admitted {
+			return
+		}
+		if cleanupErr := m.updateReplicaScrubStatus(replicaNames, func(r *longhorn.Replica) {
+			r.Status.ScrubStartedAt = ""
+		}); cleanupErr != nil {
+			m.logger.WithField("monitor", monitorName).WithError(cleanupErr).Warnf("Failed to unmark replicas of volume %v as scrubbing", volumeName)
+		}
+	}()
+
+	allReplicas, err := m.ds.ListReplicasRO()
+	if err != nil {
+		return false, err
+	}
+	return isScrubAdmitted(volumeName, allReplicas, int(limit)), nil
+}
+
+// isScrubAdmitted returns if the volume is among the first limit scrubbing volumes ordered by the time the scrubbing
+// started. Only the running replicas are counted, so the marks left by an interrupted scrubbing don't block others.
+func isScrubAdmitted(volumeName string, replicas []*longhorn.Replica, limit int) bool {
+	startedAtMap := map[string]string{}
+	for _, r := range replicas {
+		if r.Status.ScrubStartedAt == "" || r.Status.CurrentState != longhorn.InstanceStateRunning {
+			continue
+		}
+		if startedAt, ok := startedAtMap[r.Spec.VolumeName]; !ok || r.Status.ScrubStartedAt < startedAt {
+			startedAtMap[r.Spec.VolumeName] = r.Status.ScrubStartedAt
+		}
+	}
+
+	volumeNames := []string{}
+	for name := range startedAtMap {
+		volumeNames = append(volumeNames, name)
+	}
+	sort.Slice(volumeNames, func(i, j int) bool {
+		a, b := volumeNames[i], volumeNames[j]
+		if startedAtMap[a] != startedAtMap[b] {
+			return startedAtMap[a] < startedAtMap[b]
+		}
+		return a < b
+	})
+	for i, name := range volumeNames {
+		if i >= limit {
+			break
+		}
+		if name == volumeName {
+			return true
+		}
+	}
+	return false
+}
+
+// scrubSnapshots hashes the snapshots on the replicas one by one, and returns the names of the replicas kicked out
+// for having a checksum different from the voted one.
+func (m *SnapshotMonitor) scrubSnapshots(engine *longhorn.Engine) (map[string]struct{}, error) {
+	engineCliClient, err := engineapi.GetEngineBinaryClient(m.ds, engine.Spec.VolumeName, m.nodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	engineClientProxy, err := engineapi.GetCompatibleClient(engine, engineCliClient, m.ds, m.logger, m.proxyConnCounter)
+	if err != nil {
+		return nil, err
+	}
+	defer engineClientProxy.Close()
+
+	corruptedReplicaNames := map[string]struct{}{}
+	for _, snapshotName := range getEngineSnapshotsToCheck(engine) {
+		if !m.shouldAddToInProgressSnapshotCheckTasks(snapshotName) {
+			continue
+		}
+		corruptedAddresses, err := func() (map[string]struct{}, error) {
+			defer m.deleteFromInProgressSnapshotCheckTasks(snapshotName)
+
+			if err := m.requestSnapshotHashing(engine, engineClientProxy, snapshotName, false); err != nil {
+				return nil, err
+			}
+			return m.waitAndHandleSnapshotHashing(engine, engineClientProxy, snapshotName)
+		}()
+		if err != nil {
+			return corruptedReplicaNames, err
+		}
+		for address := range corruptedAddresses {
+			for name, replicaAddress := range engine.Status.CurrentReplicaAddressMap {
+				if replicaAddress == engineapi.GetAddressFromBackendReplicaURL(address) {
+					corruptedReplicaNames[name] = struct{}{}
+				}
+			}
+		}
+	}
+	return corruptedReplicaNames, nil
+}
+
+// finishScrub records the scrubbing result in the replicas and unmarks them as scrubbing
+func (m *SnapshotMonitor) finishScrub(replicaNames []string, corruptedReplicaNames map[string]struct{}, scrubErr error) {
+	finishedAt := util.Now()
+	if err := m.updateReplicaScrubStatus(replicaNames, func(r *longhorn.Replica) {
+		result := longhorn.ReplicaScrubResultPassed
+		if _, ok := corruptedReplicaNames[r.Name]; ok {
+			result = longhorn.ReplicaScrubResultDivergent
+		} else if scrubErr != nil {
+			result = longhorn.ReplicaScrubResultFailed
+		}
+		r.Status.ScrubStartedAt = ""
+		r.Status.LastScrubbedAt = finishedAt
+		r.Status.LastScrubResult = result
+	}); err != nil {
+		m.logger.WithField("monitor", monitorName).WithError(err).Warn("Failed to record the scrubbing result of replicas")
+	}
+}
+
+func (m *SnapshotMonitor) updateReplicaScrubStatus(replicaNames []string, update func(r *longhorn.Replica)) error {
+	for _, name := range replicaNames {
+		r, err := m.ds.GetReplica(name)
+		if err != nil {
+			if datastore.ErrorIsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		update(r)
+		if _, err := m.ds.UpdateReplicaStatus(r); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (m *SnapshotMonitor) canRequestSnapshotHash(engine *longhorn.Engine) error {
@@ -413,7 +615,7 @@ func (m *SnapshotMonitor) requestSnapshotHashing(engine *longhorn.Engine, engine
 }
 
 func (m *SnapshotMonitor) waitAndHandleSnapshotHashing(engine *longhorn.Engine, engineClientProxy engineapi.EngineClientProxy,
-	snapshotName string) error {
+	snapshotName string) (corruptedAddresses map[string]struct{}, err error) {
 	opts := []retry.Option{
 		retry.Context(m.ctx),
 		retry.Attempts(snapshotHashSyncStatusAttempts),
@@ -430,12 +632,13 @@ func (m *SnapshotMonitor) waitAndHandleSnapshotHashing(engine *longhorn.Engine,
 
 	// retry does periodically fetching and syncing the snapshot hashing status.
 	if err := retry.Do(func() (err error) {
-		return m.syncHashStatusFromEngineReplicas(engine, engineClientProxy, snapshotName)
+		corruptedAddresses, err = m.syncHashStatusFromEngineReplicas(engine, engineClientProxy, snapshotName)
+		return err
 	}, opts...); err != nil {
-		return errors.Wrapf(err, "failed to sync hash status for snapshot %v since %v", snapshotName, err)
+		return nil, errors.Wrapf(err, "failed to sync hash status for snapshot %v since %v", snapshotName, err)
 	}
 
-	return nil
+	return corruptedAddresses, nil
 }
 
 func (m *SnapshotMonitor) checkVolumeNotInMigration(volumeName string) error {
@@ -468,25 +671,25 @@ func (m *SnapshotMonitor) checkVolumeIsNotRestoring(engine *longhorn.Engine) err
 }
 
 func (m *SnapshotMonitor) syncHashStatusFromEngineReplicas(engine *longhorn.Engine, engineClientProxy engineapi.EngineClientProxy,
-	snapshotName string) error {
+	snapshotName string) (map[string]struct{}, error) {
 	hashStatus, err := engineClientProxy.SnapshotHashStatus(engine, snapshotName)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get hash status for snapshot %v", snapshotName)
+		return nil, errors.Wrapf(err, "failed to get hash status for snapshot %v", snapshotName)
 	}
 
 	for _, status := range hashStatus {
 		if status.State == string(longhorn.SnapshotHashStatusError) {
-			return fmt.Errorf("failed to hash snapshot %v since %v", snapshotName, status.Error)
+			return nil, fmt.Errorf("failed to hash snapshot %v since %v", snapshotName, status.Error)
 		}
 
 		if status.State == string(engineapi.ProcessStateInProgress) {
-			return errors.New(string(engineapi.ProcessStateInProgress))
+			return nil, errors.New(string(engineapi.ProcessStateInProgress))
 		}
 	}
 
 	snapshot, err := m.ds.GetSnapshot(snapshotName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	existingSnapshot := snapshot.DeepCopy()
 
@@ -494,28 +697,28 @@ func (m *SnapshotMonitor) syncHashStatusFromEngineReplicas(engine *longhorn.Engi
 	if err != nil {
 		m.eventRecorder.Eventf(engine, corev1.EventTypeWarning, constant.EventReasonFailedSnapshotDataIntegrityCheck,
 			"Failed to check the data integrity of snapshot %v for volume %v", snapshotName, engine.Spec.VolumeName)
-		return errors.Wrapf(err, "failed to determine checksum for snapshot %v", snapshotName)
+		return nil, errors.Wrapf(err, "failed to determine checksum for snapshot %v", snapshotName)
 	}
 
 	snapshot.Status.Checksum = checksum
 
 	if !reflect.DeepEqual(existingSnapshot.Status, snapshot.Status) {
 		if _, err := m.ds.UpdateSnapshotStatus(snapshot); err != nil {
-			return errors.Wrapf(err, "failed to update status for snapshot %v", snapshotName)
+			return nil, errors.Wrapf(err, "failed to update status for snapshot %v", snapshotName)
 		}
 	}
 
-	m.kickOutCorruptedReplicas(engine, engineClientProxy, checksum, hashStatus)
-
-	return nil
+	return m.kickOutCorruptedReplicas(engine, engineClientProxy, checksum, hashStatus), nil
 }
 
 func (m *SnapshotMonitor) kickOutCorruptedReplicas(engine *longhorn.Engine, engineClientProxy engineapi.EngineClientProxy,
-	checksum string, hashStatus map[string]*longhorn.HashStatus) {
+	checksum string, hashStatus map[string]*longhorn.HashStatus) map[string]struct{} {
+	corruptedAddresses := map[string]struct{}{}
 	for address, status := range hashStatus {
 		if status.Checksum == checksum {
 			continue
 		}
+		corruptedAddresses[address] = struct{}{}
 
 		m.eventRecorder.Eventf(engine, corev1.EventTypeWarning, constant.EventReasonFaulted, "Detected corrupted replica %v", address)
 
@@ -523,6 +726,7 @@ func (m *SnapshotMonitor) kickOutCorruptedReplicas(engine *longhorn.Engine, engi
 			m.logger.WithField("monitor", monitorName).Errorf("failed to update replica %v mode to ERR", address)
 		}
 	}
+	return corruptedAddresses
 }
 
 func determineChecksumFromHashStatus(log logrus.FieldLogger, snapshotName, existingChecksum string, hashStatus map[string]*longhorn.HashStatus) (string, error) {
@@ -7,6 +7,8 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
 
@@ -546,3 +548,34 @@ func TestDetermineChecksumFromHashStatus(t *testing.T) {
 		}
 	}
 }
+
+func TestIsScrubAdmitted(t *testing.T) {
+	assert := require.New(t)
+
+	newReplica := func(name, volumeName, scrubStartedAt string, state longhorn.InstanceState) *longhorn.Replica {
+		r := &longhorn.Replica{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		r.Spec.VolumeName = volumeName
+		r.Status.ScrubStartedAt = scrubStartedAt
+		r.Status.CurrentState = state
+		return r
+	}
+	replicas := []*longhorn.Replica{
+		newReplica("vol-1-r-1", "vol-1", "2026-01-01T00:00:02Z", longhorn.InstanceStateRunning),
+		newReplica("vol-1-r-2", "vol-1", "2026-01-01T00:00:02Z", longhorn.InstanceStateRunning),
+		newReplica("vol-2-r-1", "vol-2", "2026-01-01T00:00:01Z", longhorn.InstanceStateRunning),
+		newReplica("vol-3-r-1", "vol-3", "2026-01-01T00:00:02Z", longhorn.InstanceStateRunning),
+		newReplica("vol-4-r-1", "vol-4", "2026-01-01T00:00:00Z", longhorn.InstanceStateStopped),
+		newReplica("vol-5-r-1", "vol-5", "", longhorn.InstanceStateRunning),
+	}
+
+	// The volumes marked earlier are admitted first, and the ones marked at the same time are ordered by names
+	assert.True(isScrubAdmitted("vol-2", replicas, 1))
+	assert.False(isScrubAdmitted("vol-1", replicas, 1))
+	assert.True(isScrubAdmitted("vol-1", replicas, 2))
+	assert.False(isScrubAdmitted("vol-3", replicas, 2))
+	assert.True(isScrubAdmitted("vol-3", replicas, 3))
+
+	// The marks left on the stopped replicas by an interrupted scrubbing are not counted
+	assert.False(isScrubAdmitted("vol-4", replicas, 4))
+	assert.False(isScrubAdmitted("vol-5", replicas, 4))
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -195,7 +196,10 @@ func (nc *NodeController) isResponsibleForSetting(obj interface{}) bool {
 	return types.SettingName(setting.Name) == types.SettingNameStorageMinimalAvailablePercentage ||
 		types.SettingName(setting.Name) == types.SettingNameBackingImageCleanupWaitInterval ||
 		types.SettingName(setting.Name) == types.SettingNameOrphanAutoDeletion ||
-		types.SettingName(setting.Name) == types.SettingNameNodeDrainPolicy
+		types.SettingName(setting.Name) == types.SettingNameOrphanBackingImageAutoDeletion ||
+		types.SettingName(setting.Name) == types.SettingNameNodeDrainPolicy ||
+		types.SettingName(setting.Name) == types.SettingNameNodeZoneLabelKeys ||
+		types.SettingName(setting.Name) == types.SettingNameNodeRegionLabelKeys
 }
 
 func (nc *NodeController) isResponsibleForReplica(obj interface{}) bool {
@@ -424,7 +428,9 @@ func (nc *NodeController) syncNode(key string) (err error) {
 		return err
 	}
 
-	node.Status.Region, node.Status.Zone = types.GetRegionAndZone(kubeNode.Labels)
+	if node.Status.Region, node.Status.Zone, err = nc.getRegionAndZone(kubeNode); err != nil {
+		return err
+	}
 
 	if nc.controllerID != node.Name {
 		return nil
@@ -535,6 +541,26 @@ func (nc *NodeController) enqueueNodeRateLimited(obj interface{}) {
 	nc.queue.AddRateLimited(key)
 }
 
+// getRegionAndZone returns the region and the zone of the node from the Kubernetes node labels of the node region and
+// zone label keys settings, unless they are overridden by the annotations of the Kubernetes node
+func (nc *NodeController) getRegionAndZone(kubeNode *corev1.Node) (string, string, error) {
+	labelKeys := map[types.SettingName][]string{}
+	for _, settingName := range []types.SettingName{types.SettingNameNodeRegionLabelKeys, types.SettingNameNodeZoneLabelKeys} {
+		setting, err := nc.ds.GetSettingWithAutoFillingRO(settingName)
+		if err != nil {
+			return "", "", err
+		}
+		keys, err := types.UnmarshalNodeLabelKeys(setting.Value)
+		if err != nil {
+			return "", "", errors.Wrapf(err, "failed to parse %v setting", settingName)
+		}
+		labelKeys[settingName] = keys
+	}
+	region, zone := types.GetRegionAndZone(kubeNode.Labels, kubeNode.Annotations,
+		labelKeys[types.SettingNameNodeRegionLabelKeys], labelKeys[types.SettingNameNodeZoneLabelKeys])
+	return region, zone, nil
+}
+
 func (nc *NodeController) enqueueSetting(obj interface{}) {
 	nodes, err := nc.ds.ListNodesRO()
 	if err != nil {
@@ -820,6 +846,8 @@ func (nc *NodeController) updateReadyDiskStatusReadyCondition(node *longhorn.Nod
 			diskStatus.StorageAvailable = usableStorage
 			diskStatus.StorageMaximum = diskInfoMap[diskName].DiskStat.StorageMaximum
 			diskStatus.InstanceManagerName = diskInfoMap[diskName].InstanceManagerName
+			diskStatus.IOUtilization = diskInfoMap[diskName].IOUtilization
+			diskStatus.IOLatency = diskInfoMap[diskName].IOLatency
 			diskStatusMap[diskName].Conditions = types.SetConditionAndRecord(diskStatusMap[diskName].Conditions,
 				longhorn.DiskConditionTypeReady, longhorn.ConditionStatusTrue,
 				"", fmt.Sprintf("Disk %v(%v) on node %v is ready", diskName, diskInfoMap[diskName].Path, node.Name),
@@ -1322,6 +1350,10 @@ func (nc *NodeController) syncOrphans(node *longhorn.Node, collectedDataInfo map
 		if err := nc.deleteOrphans(node, diskName, diskInfo, missingOrphanedReplicaDataStores); err != nil {
 			return errors.Wrapf(err, "failed to delete orphans for disk %v", diskName)
 		}
+
+		if err := nc.syncBackingImageOrphans(node, diskName, diskInfo); err != nil {
+			return errors.Wrapf(err, "failed to sync backing image orphans for disk %v", diskName)
+		}
 	}
 
 	return nil
@@ -1350,7 +1382,8 @@ func (nc *NodeController) getNewAndMissingOrphanedReplicaDataStores(diskName, di
 	}
 
 	for _, orphan := range orphanMap {
-		if orphan.Spec.Parameters[longhorn.OrphanDiskName] != diskName ||
+		if orphan.Spec.Type != longhorn.OrphanTypeReplica ||
+			orphan.Spec.Parameters[longhorn.OrphanDiskName] != diskName ||
 			orphan.Spec.Parameters[longhorn.OrphanDiskUUID] != diskUUID ||
 			orphan.Spec.Parameters[longhorn.OrphanDiskPath] != diskPath {
 			continue
@@ -1370,6 +1403,10 @@ func (nc *NodeController) deleteOrphans(node *longhorn.Node, diskName string, di
 	if err != nil {
 		return errors.Wrapf(err, "failed to get %v setting", types.SettingNameOrphanAutoDeletion)
 	}
+	backingImageAutoDeletionEnabled, err := nc.ds.GetSettingAsBool(types.SettingNameOrphanBackingImageAutoDeletion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %v setting", types.SettingNameOrphanBackingImageAutoDeletion)
+	}
 
 	for dataStore := range missingOrphanedReplicaDataStores {
 		orphanName := types.GetOrphanChecksumNameForOrphanedDataStore(node.Name, diskName, diskInfo.Path, diskInfo.DiskUUID, dataStore)
@@ -1393,7 +1430,10 @@ func (nc *NodeController) deleteOrphans(node *longhorn.Node, diskName string, di
 			continue
 		}
 
-		if autoDeletionEnabled || dataCleanableCondition.Status == longhorn.ConditionStatusFalse {
+		// The orphaned instances are automatically deleted by the orphan controller after the grace period.
+		if (autoDeletionEnabled && orphan.Spec.Type == longhorn.OrphanTypeReplica) ||
+			(backingImageAutoDeletionEnabled && orphan.Spec.Type == longhorn.OrphanTypeBackingImage) ||
+			dataCleanableCondition.Status == longhorn.ConditionStatusFalse {
 			if err := nc.ds.DeleteOrphan(orphan.Name); err != nil && !apierrors.IsNotFound(err) {
 				return errors.Wrapf(err, "failed to delete orphan %v", orphan.Name)
 			}
@@ -1442,6 +1482,70 @@ func (nc *NodeController) createOrphan(node *longhorn.Node, diskName, replicaDat
 	return err
 }
 
+// syncBackingImageOrphans creates orphans for the backing image directories in the disk that no backing image uses and
+// deletes the orphans whose directories are gone or used again.
+func (nc *NodeController) syncBackingImageOrphans(node *longhorn.Node, diskName string, diskInfo *monitor.CollectedDiskInfo) error {
+	orphans, err := nc.ds.ListOrphansByNodeRO(node.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list orphans for node %v", node.Name)
+	}
+
+	for _, orphan := range orphans {
+		if orphan.Spec.Type != longhorn.OrphanTypeBackingImage ||
+			orphan.Spec.Parameters[longhorn.OrphanDiskName] != diskName ||
+			orphan.Spec.Parameters[longhorn.OrphanDiskUUID] != diskInfo.DiskUUID ||
+			orphan.Spec.Parameters[longhorn.OrphanDiskPath] != diskInfo.Path {
+			continue
+		}
+		if _, ok := diskInfo.OrphanedBackingImageDirectories[orphan.Spec.Parameters[longhorn.OrphanDataName]]; ok {
+			continue
+		}
+		if err := nc.ds.DeleteOrphan(orphan.Name); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete orphan %v", orphan.Name)
+		}
+	}
+
+	for directoryName, size := range diskInfo.OrphanedBackingImageDirectories {
+		if err := nc.createBackingImageOrphan(node, diskName, directoryName, size, diskInfo); err != nil && !apierrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "failed to create orphan for orphaned backing image directory %v in disk %v on node %v",
+				directoryName, diskInfo.Path, node.Name)
+		}
+	}
+
+	return nil
+}
+
+func (nc *NodeController) createBackingImageOrphan(node *longhorn.Node, diskName, directoryName string, size int64, diskInfo *monitor.CollectedDiskInfo) error {
+	name := types.GetOrphanChecksumNameForOrphanedBackingImage(node.Name, diskName, diskInfo.Path, diskInfo.DiskUUID, directoryName)
+
+	_, err := nc.ds.GetOrphanRO(name)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	orphan := &longhorn.Orphan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: longhorn.OrphanSpec{
+			NodeID: node.Name,
+			Type:   longhorn.OrphanTypeBackingImage,
+			Parameters: map[string]string{
+				longhorn.OrphanDataName: directoryName,
+				longhorn.OrphanDiskName: diskName,
+				longhorn.OrphanDiskUUID: diskInfo.DiskUUID,
+				longhorn.OrphanDiskPath: diskInfo.Path,
+				longhorn.OrphanDiskType: string(node.Spec.Disks[diskName].Type),
+				longhorn.OrphanDataSize: strconv.FormatInt(size, 10),
+			},
+		},
+	}
+
+	_, err = nc.ds.CreateOrphan(orphan)
+
+	return err
+}
+
 func (nc *NodeController) syncWithDiskMonitor(node *longhorn.Node) (map[string]*monitor.CollectedDiskInfo, error) {
 	v, err := nc.diskMonitor.GetCollectedData()
 	if err != nil {
@@ -1807,6 +1911,9 @@ func (nc *NodeController) setReadyAndSchedulableConditions(node *longhorn.Node,
 	if err != nil {
 		return errors.Wrapf(err, "failed to get %v setting", types.SettingNameDisableSchedulingOnCordonedNode)
 	}
+	if err := nc.syncSchedulingCooldown(node, kubeNode); err != nil {
+		return err
+	}
 	nc.SetSchedulableCondition(node, kubeNode, disableSchedulingOnCordonedNode)
 
 	return nil
@@ -1883,7 +1990,8 @@ func (nc *NodeController) setReadyConditionForKubeNode(node *longhorn.Node, kube
 	return nodeReady
 }
 
-// Update node condition based on DisableSchedulingOnCordonedNode setting and Kubernetes node status.
+// Update node condition based on DisableSchedulingOnCordonedNode setting, Kubernetes node status and the scheduling
+// cooldown of the flapping node.
 func (nc *NodeController) SetSchedulableCondition(node *longhorn.Node, kubeNode *corev1.Node,
 	disableSchedulingOnCordonedNode bool) {
 	kubeSpec := kubeNode.Spec
@@ -1897,6 +2005,15 @@ func (nc *NodeController) SetSchedulableCondition(node *longhorn.Node, kubeNode
 				fmt.Sprintf("Node %v is cordoned", node.Name),
 				nc.eventRecorder, node,
 				corev1.EventTypeNormal)
+	} else if node.Status.SchedulingCooldownUntil != "" {
+		node.Status.Conditions =
+			types.SetConditionAndRecord(node.Status.Conditions,
+				longhorn.NodeConditionTypeSchedulable,
+				longhorn.ConditionStatusFalse,
+				string(longhorn.NodeConditionReasonKubernetesNodeFlapping),
+				fmt.Sprintf("Node %v is flapping between Ready and NotReady, replica scheduling is disabled until %v", node.Name, node.Status.SchedulingCooldownUntil),
+				nc.eventRecorder, node,
+				corev1.EventTypeWarning)
 	} else {
 		node.Status.Conditions =
 			types.SetConditionAndRecord(node.Status.Conditions,
@@ -0,0 +1,444 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/longhorn/longhorn-manager/constant"
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// NodeDrainController prepares the node for draining when the Kubernetes node is cordoned and annotated with
+// node.longhorn.io/drain=true. It evicts the replicas, migrates the share manager pods of the RWX volumes to other
+// nodes, removes the instance manager PDBs once nothing is left to protect, and reports the drain readiness in the
+// DrainReady condition of the Longhorn node.
+type NodeDrainController struct {
+	*baseController
+
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the controller
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds *datastore.DataStore
+
+	cacheSyncs []cache.InformerSynced
+}
+
+func NewNodeDrainController(
+	logger logrus.FieldLogger,
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	kubeClient clientset.Interface,
+	controllerID string,
+	namespace string) (*NodeDrainController, error) {
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	// TODO: remove the wrapper when every clients have moved to use the clientset.
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	ndc := &NodeDrainController{
+		baseController: newBaseController("longhorn-node-drain", logger),
+
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-node-drain-controller"}),
+
+		ds: ds,
+	}
+
+	var err error
+	if _, err = ds.KubeNodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ndc.enqueueKubernetesNode,
+		UpdateFunc: func(old, cur interface{}) { ndc.enqueueKubernetesNode(cur) },
+	}); err != nil {
+		return nil, err
+	}
+	ndc.cacheSyncs = append(ndc.cacheSyncs, ds.KubeNodeInformer.HasSynced)
+
+	if _, err = ds.NodeInformer.AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ndc.enqueueLonghornNode,
+		UpdateFunc: func(old, cur interface{}) { ndc.enqueueLonghornNode(cur) },
+	}, 0); err != nil {
+		return nil, err
+	}
+	ndc.cacheSyncs = append(ndc.cacheSyncs, ds.NodeInformer.HasSynced)
+
+	if _, err = ds.ReplicaInformer.AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ndc.enqueueReplica,
+		UpdateFunc: func(old, cur interface{}) { ndc.enqueueReplica(cur) },
+		DeleteFunc: ndc.enqueueReplica,
+	}, 0); err != nil {
+		return nil, err
+	}
+	ndc.cacheSyncs = append(ndc.cacheSyncs, ds.ReplicaInformer.HasSynced)
+
+	if _, err = ds.PodInformer.AddEventHandlerWithResyncPeriod(cache.FilteringResourceEventHandler{
+		FilterFunc: isShareManagerPod,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    ndc.enqueuePod,
+			UpdateFunc: func(old, cur interface{}) { ndc.enqueuePod(cur) },
+			DeleteFunc: ndc.enqueuePod,
+		},
+	}, 0); err != nil {
+		return nil, err
+	}
+	ndc.cacheSyncs = append(ndc.cacheSyncs, ds.PodInformer.HasSynced)
+
+	if _, err = ds.InstanceManagerInformer.AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, cur interface{}) { ndc.enqueueInstanceManager(cur) },
+	}, 0); err != nil {
+		return nil, err
+	}
+	ndc.cacheSyncs = append(ndc.cacheSyncs, ds.InstanceManagerInformer.HasSynced)
+
+	return ndc, nil
+}
+
+func (ndc *NodeDrainController) enqueueNodeName(nodeName string) {
+	if nodeName != ndc.controllerID {
+		return
+	}
+	ndc.queue.Add(nodeName)
+}
+
+func (ndc *NodeDrainController) enqueueKubernetesNode(obj interface{}) {
+	kubeNode, ok := obj.(*corev1.Node)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("received unexpected obj: %#v", obj))
+		return
+	}
+	ndc.enqueueNodeName(kubeNode.Name)
+}
+
+func (ndc *NodeDrainController) enqueueLonghornNode(obj interface{}) {
+	node, ok := obj.(*longhorn.Node)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("received unexpected obj: %#v", obj))
+		return
+	}
+	ndc.enqueueNodeName(node.Name)
+}
+
+func (ndc *NodeDrainController) enqueueReplica(obj interface{}) {
+	replica, ok := obj.(*longhorn.Replica)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("received unexpected obj: %#v", obj))
+			return
+		}
+
+		// use the last known state, to enqueue, dependent objects
+		replica, ok = deletedState.Obj.(*longhorn.Replica)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("DeletedFinalStateUnknown contained invalid object: %#v", deletedState.Obj))
+			return
+		}
+	}
+	ndc.enqueueNodeName(replica.Spec.NodeID)
+}
+
+func (ndc *NodeDrainController) enqueuePod(obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("received unexpected obj: %#v", obj))
+			return
+		}
+
+		// use the last known state, to enqueue, dependent objects
+		pod, ok = deletedState.Obj.(*corev1.Pod)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("DeletedFinalStateUnknown contained invalid object: %#v", deletedState.Obj))
+			return
+		}
+	}
+	ndc.enqueueNodeName(pod.Spec.NodeName)
+}
+
+func (ndc *NodeDrainController) enqueueInstanceManager(obj interface{}) {
+	im, ok := obj.(*longhorn.InstanceManager)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("received unexpected obj: %#v", obj))
+		return
+	}
+	ndc.enqueueNodeName(im.Spec.NodeID)
+}
+
+func (ndc *NodeDrainController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer ndc.queue.ShutDown()
+
+	ndc.logger.Info("Starting Longhorn node drain controller")
+	defer ndc.logger.Info("Shut down Longhorn node drain controller")
+
+	if !cache.WaitForNamedCacheSync(ndc.name, stopCh, ndc.cacheSyncs...) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(ndc.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (ndc *NodeDrainController) worker() {
+	for ndc.processNextWorkItem() {
+	}
+}
+
+func (ndc *NodeDrainController) processNextWorkItem() bool {
+	key, quit := ndc.queue.Get()
+	if quit {
+		return false
+	}
+	defer ndc.queue.Done(key)
+
+	err := ndc.syncNodeDrain(key.(string))
+	ndc.handleErr(err, key)
+
+	return true
+}
+
+func (ndc *NodeDrainController) handleErr(err error, key interface{}) {
+	if err == nil {
+		ndc.queue.Forget(key)
+		return
+	}
+
+	log := ndc.logger.WithField("node", key)
+	if ndc.queue.NumRequeues(key) < maxRetries {
+		handleReconcileErrorLogging(log, err, "Failed to sync node drain")
+		ndc.queue.AddRateLimited(key)
+		return
+	}
+
+	utilruntime.HandleError(err)
+	handleReconcileErrorLogging(log, err, "Dropping node drain out of the queue")
+	ndc.queue.Forget(key)
+}
+
+func (ndc *NodeDrainController) syncNodeDrain(nodeName string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "failed to sync drain for node %v", nodeName)
+	}()
+
+	if nodeName != ndc.controllerID {
+		return nil
+	}
+
+	kubeNode, err := ndc.ds.GetKubernetesNodeRO(nodeName)
+	if err != nil {
+		if datastore.ErrorIsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	node, err := ndc.ds.GetNode(nodeName)
+	if err != nil {
+		if datastore.ErrorIsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !isNodeDrainRequested(kubeNode) {
+		return ndc.cancelNodeDrain(node)
+	}
+
+	if node, err = ndc.requestReplicaEviction(node); err != nil {
+		return err
+	}
+
+	if err := ndc.migrateShareManagerPods(node); err != nil {
+		return err
+	}
+
+	ready, reason, message, err := ndc.isNodeDrainReady(node)
+	if err != nil {
+		return err
+	}
+
+	if ready {
+		if err := ndc.deleteInstanceManagerPDBs(node); err != nil {
+			return err
+		}
+	}
+
+	return ndc.updateDrainReadyCondition(node, ready, reason, message)
+}
+
+func isNodeDrainRequested(kubeNode *corev1.Node) bool {
+	return kubeNode.Spec.Unschedulable && kubeNode.Annotations[types.KubeNodeDrainAnnotationKey] == types.KubeNodeDrainAnnotationValueTrue
+}
+
+// requestReplicaEviction requests the eviction of the replicas on the node, and records it in an annotation so that the
+// eviction request can be reverted once the drain request is canceled.
+func (ndc *NodeDrainController) requestReplicaEviction(node *longhorn.Node) (*longhorn.Node, error) {
+	if node.Spec.EvictionRequested {
+		return node, nil
+	}
+
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+	node.Annotations[types.GetLonghornLabelKey(types.LonghornAnnotationDrainEvictionRequested)] = ""
+	node.Spec.EvictionRequested = true
+
+	updatedNode, err := ndc.ds.UpdateNode(node)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to request replica eviction")
+	}
+
+	ndc.logger.Infof("Requested replica eviction for draining node %v", node.Name)
+	ndc.eventRecorder.Eventf(updatedNode, corev1.EventTypeNormal, constant.EventReasonStart, "Requested replica eviction for draining node %v", node.Name)
+	return updatedNode, nil
+}
+
+// migrateShareManagerPods deletes the share manager pods on the node, so that the share manager controller recreates
+// them on the other schedulable nodes.
+func (ndc *NodeDrainController) migrateShareManagerPods(node *longhorn.Node) error {
+	pods, err := ndc.ds.ListShareManagerPods()
+	if err != nil {
+		return errors.Wrap(err, "failed to list share manager pods")
+	}
+
+	for _, pod := range pods {
+		if pod.Spec.NodeName != node.Name || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		ndc.logger.Infof("Deleting share manager pod %v to migrate it away from draining node %v", pod.Name, node.Name)
+		if err := ndc.ds.DeletePod(pod.Name); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete share manager pod %v", pod.Name)
+		}
+		ndc.eventRecorder.Eventf(node, corev1.EventTypeNormal, constant.EventReasonDelete, "Deleted share manager pod %v for draining node %v", pod.Name, node.Name)
+	}
+
+	return nil
+}
+
+func (ndc *NodeDrainController) isNodeDrainReady(node *longhorn.Node) (ready bool, reason, message string, err error) {
+	replicas, err := ndc.ds.ListReplicasByNodeRO(node.Name)
+	if err != nil && !datastore.ErrorIsNotFound(err) {
+		return false, "", "", errors.Wrapf(err, "failed to list replicas on node %v", node.Name)
+	}
+	if len(replicas) > 0 {
+		return false, longhorn.NodeConditionReasonReplicasNotEvicted,
+			fmt.Sprintf("Waiting for the eviction of replicas %v", formatReplicaMessage(replicas)), nil
+	}
+
+	pods, err := ndc.ds.ListShareManagerPods()
+	if err != nil {
+		return false, "", "", errors.Wrap(err, "failed to list share manager pods")
+	}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == node.Name {
+			return false, longhorn.NodeConditionReasonShareManagersNotMigrated,
+				fmt.Sprintf("Waiting for share manager pod %v to be migrated", pod.Name), nil
+		}
+	}
+
+	return true, "", fmt.Sprintf("Node %v is ready to be drained", node.Name), nil
+}
+
+// deleteInstanceManagerPDBs removes the PDBs of the instance managers on the node without engines, since the replicas
+// are evicted and there is no data left to protect.
+func (ndc *NodeDrainController) deleteInstanceManagerPDBs(node *longhorn.Node) error {
+	ims, err := ndc.ds.ListInstanceManagersRO()
+	if err != nil {
+		return errors.Wrap(err, "failed to list instance managers")
+	}
+
+	for _, im := range ims {
+		if im.Spec.NodeID != node.Name {
+			continue
+		}
+		if len(im.Status.InstanceEngines)+len(im.Status.Instances) > 0 { // nolint: staticcheck
+			continue
+		}
+
+		pdbName := types.GetPDBName(im)
+		if _, err := ndc.ds.GetPDBRO(pdbName); err != nil {
+			if datastore.ErrorIsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		ndc.logger.Infof("Deleting %v PDB for draining node %v", pdbName, node.Name)
+		if err := ndc.ds.DeletePDB(pdbName); err != nil && !datastore.ErrorIsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete %v PDB", pdbName)
+		}
+	}
+
+	return nil
+}
+
+func (ndc *NodeDrainController) updateDrainReadyCondition(node *longhorn.Node, ready bool, reason, message string) error {
+	existingNode := node.DeepCopy()
+
+	status := longhorn.ConditionStatusFalse
+	if ready {
+		status = longhorn.ConditionStatusTrue
+	}
+	node.Status.Conditions = types.SetConditionAndRecord(node.Status.Conditions, longhorn.NodeConditionTypeDrainReady,
+		status, reason, message, ndc.eventRecorder, node, corev1.EventTypeNormal)
+
+	if reflect.DeepEqual(existingNode.Status, node.Status) {
+		return nil
+	}
+	_, err := ndc.ds.UpdateNodeStatus(node)
+	return err
+}
+
+// cancelNodeDrain reverts the replica eviction requested for the drain and removes the DrainReady condition.
+func (ndc *NodeDrainController) cancelNodeDrain(node *longhorn.Node) error {
+	annotationKey := types.GetLonghornLabelKey(types.LonghornAnnotationDrainEvictionRequested)
+	if _, ok := node.Annotations[annotationKey]; ok {
+		delete(node.Annotations, annotationKey)
+		node.Spec.EvictionRequested = false
+
+		updatedNode, err := ndc.ds.UpdateNode(node)
+		if err != nil {
+			return errors.Wrapf(err, "failed to cancel replica eviction")
+		}
+		node = updatedNode
+		ndc.logger.Infof("Canceled replica eviction since the drain request of node %v is removed", node.Name)
+	}
+
+	if types.GetCondition(node.Status.Conditions, longhorn.NodeConditionTypeDrainReady).Status == longhorn.ConditionStatusUnknown {
+		return nil
+	}
+	node.Status.Conditions = types.RemoveCondition(node.Status.Conditions, longhorn.NodeConditionTypeDrainReady)
+	_, err := ndc.ds.UpdateNodeStatus(node)
+	return err
+}
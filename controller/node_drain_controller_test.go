@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+
+	. "gopkg.in/check.v1"
+)
+
+func newTestNodeDrainController(lhClient *lhfake.Clientset, kubeClient *fake.Clientset, extensionsClient *apiextensionsfake.Clientset,
+	informerFactories *util.InformerFactories, controllerID string) (*NodeDrainController, error) {
+	ds := datastore.NewDataStore(TestNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+
+	logger := logrus.StandardLogger()
+	ndc, err := NewNodeDrainController(logger, ds, scheme.Scheme, kubeClient, controllerID, TestNamespace)
+	if err != nil {
+		return nil, err
+	}
+	ndc.eventRecorder = record.NewFakeRecorder(100)
+	for index := range ndc.cacheSyncs {
+		ndc.cacheSyncs[index] = alwaysReady
+	}
+	return ndc, nil
+}
+
+func (s *TestSuite) TestNodeDrain(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+	kubeNodeIndexer := informerFactories.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+	podIndexer := informerFactories.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	pdbIndexer := informerFactories.KubeNamespaceFilteredInformerFactory.Policy().V1().PodDisruptionBudgets().Informer().GetIndexer()
+	lhNodeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Nodes().Informer().GetIndexer()
+	rIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Replicas().Informer().GetIndexer()
+	imIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().InstanceManagers().Informer().GetIndexer()
+
+	ndc, err := newTestNodeDrainController(lhClient, kubeClient, extensionsClient, informerFactories, TestNode1)
+	c.Assert(err, IsNil)
+
+	kubeNode := newKubernetesNode(TestNode1, corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionTrue)
+	kubeNode.Spec.Unschedulable = true
+	kubeNode.Annotations = map[string]string{types.KubeNodeDrainAnnotationKey: types.KubeNodeDrainAnnotationValueTrue}
+	c.Assert(kubeNodeIndexer.Add(kubeNode), IsNil)
+
+	node := newNode(TestNode1, TestNamespace, true, longhorn.ConditionStatusTrue, "")
+	node, err = lhClient.LonghornV1beta2().Nodes(TestNamespace).Create(context.TODO(), node, metav1.CreateOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(lhNodeIndexer.Add(node), IsNil)
+
+	volume := newVolume(TestVolumeName, 2)
+	replica := newReplicaForVolume(volume, newEngineForVolume(volume), TestNode1, TestDiskID1)
+	replica.Namespace = TestNamespace
+	c.Assert(rIndexer.Add(replica), IsNil)
+
+	smPod := newPod(&corev1.PodStatus{Phase: corev1.PodRunning}, types.GetShareManagerPodNameFromShareManagerName(TestVolumeName), TestNamespace, TestNode1)
+	smPod.Labels = types.GetShareManagerComponentLabel()
+	smPod, err = kubeClient.CoreV1().Pods(TestNamespace).Create(context.TODO(), smPod, metav1.CreateOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(podIndexer.Add(smPod), IsNil)
+
+	im := newInstanceManager(TestInstanceManagerName, longhorn.InstanceManagerStateRunning, TestNode1, TestNode1, TestIP1,
+		nil, nil, longhorn.DataEngineTypeV1, TestInstanceManagerImage, false)
+	c.Assert(imIndexer.Add(im), IsNil)
+	pdb := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: types.GetPDBName(im), Namespace: TestNamespace}}
+	pdb, err = kubeClient.PolicyV1().PodDisruptionBudgets(TestNamespace).Create(context.TODO(), pdb, metav1.CreateOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(pdbIndexer.Add(pdb), IsNil)
+
+	syncNode := func() *longhorn.Node {
+		c.Assert(ndc.syncNodeDrain(TestNode1), IsNil)
+		node, err := lhClient.LonghornV1beta2().Nodes(TestNamespace).Get(context.TODO(), TestNode1, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(lhNodeIndexer.Update(node), IsNil)
+		return node
+	}
+
+	// The replicas are evicted and the share manager pods are migrated for the drain
+	node = syncNode()
+	c.Assert(node.Spec.EvictionRequested, Equals, true)
+	condition := types.GetCondition(node.Status.Conditions, longhorn.NodeConditionTypeDrainReady)
+	c.Assert(condition.Status, Equals, longhorn.ConditionStatusFalse)
+	c.Assert(condition.Reason, Equals, longhorn.NodeConditionReasonReplicasNotEvicted)
+	_, err = kubeClient.CoreV1().Pods(TestNamespace).Get(context.TODO(), smPod.Name, metav1.GetOptions{})
+	c.Assert(datastore.ErrorIsNotFound(err), Equals, true)
+	_, err = kubeClient.PolicyV1().PodDisruptionBudgets(TestNamespace).Get(context.TODO(), pdb.Name, metav1.GetOptions{})
+	c.Assert(err, IsNil)
+
+	// The node is ready to be drained once the replicas and share manager pods are gone
+	c.Assert(rIndexer.Delete(replica), IsNil)
+	c.Assert(podIndexer.Delete(smPod), IsNil)
+	node = syncNode()
+	condition = types.GetCondition(node.Status.Conditions, longhorn.NodeConditionTypeDrainReady)
+	c.Assert(condition.Status, Equals, longhorn.ConditionStatusTrue)
+	_, err = kubeClient.PolicyV1().PodDisruptionBudgets(TestNamespace).Get(context.TODO(), pdb.Name, metav1.GetOptions{})
+	c.Assert(datastore.ErrorIsNotFound(err), Equals, true)
+
+	// The eviction requested for the drain is reverted once the drain request is removed
+	kubeNode = kubeNode.DeepCopy()
+	kubeNode.Spec.Unschedulable = false
+	c.Assert(kubeNodeIndexer.Update(kubeNode), IsNil)
+	node = syncNode()
+	c.Assert(node.Spec.EvictionRequested, Equals, false)
+	c.Assert(types.GetCondition(node.Status.Conditions, longhorn.NodeConditionTypeDrainReady).Status, Equals, longhorn.ConditionStatusUnknown)
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// syncSchedulingCooldown records the transitions of the Kubernetes node between Ready and NotReady, and disables
+// replica scheduling on the node for the cooldown once the transitions within the detection window reach the
+// threshold. The cooldown restarts on every transition while the node keeps flapping, and is cleared once it ends.
+func (nc *NodeController) syncSchedulingCooldown(node *longhorn.Node, kubeNode *corev1.Node) error {
+	cooldown, err := nc.ds.GetSettingAsInt(types.SettingNameNodeFlappingSchedulingCooldown)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %v setting", types.SettingNameNodeFlappingSchedulingCooldown)
+	}
+	threshold, err := nc.ds.GetSettingAsInt(types.SettingNameNodeFlappingThreshold)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %v setting", types.SettingNameNodeFlappingThreshold)
+	}
+	window, err := nc.ds.GetSettingAsInt(types.SettingNameNodeFlappingDetectionWindow)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %v setting", types.SettingNameNodeFlappingDetectionWindow)
+	}
+
+	now := nc.clock.Now()
+	transitionTimes, transitioned := recordReadyTransitionTime(node.Status.ReadyTransitionTimes, kubeNode, now, time.Duration(window)*time.Minute)
+	node.Status.ReadyTransitionTimes = transitionTimes
+
+	if cooldown == 0 {
+		node.Status.SchedulingCooldownUntil = ""
+		return nil
+	}
+
+	if transitioned && int64(len(transitionTimes)) >= threshold {
+		node.Status.SchedulingCooldownUntil = now.Add(time.Duration(cooldown) * time.Minute).UTC().Format(time.RFC3339)
+		getLoggerForNode(nc.logger, node).Warnf("Kubernetes node transitioned between Ready and NotReady %v times in %v minutes, disabling replica scheduling until %v",
+			len(transitionTimes), window, node.Status.SchedulingCooldownUntil)
+		return nil
+	}
+
+	if node.Status.SchedulingCooldownUntil != "" {
+		until, err := util.ParseTime(node.Status.SchedulingCooldownUntil)
+		if err != nil || !now.Before(until) {
+			node.Status.SchedulingCooldownUntil = ""
+		}
+	}
+	return nil
+}
+
+// recordReadyTransitionTime appends the last transition time of the Ready condition of the Kubernetes node to the
+// transition times, unless it's recorded already, and drops the times out of the detection window. It returns true if
+// a new transition is recorded.
+func recordReadyTransitionTime(transitionTimes []string, kubeNode *corev1.Node, now time.Time, window time.Duration) ([]string, bool) {
+	recorded := []string{}
+	for _, transitionTime := range transitionTimes {
+		if t, err := util.ParseTime(transitionTime); err == nil && now.Sub(t) <= window {
+			recorded = append(recorded, transitionTime)
+		}
+	}
+
+	transitioned := false
+	for _, con := range kubeNode.Status.Conditions {
+		if con.Type != corev1.NodeReady || con.LastTransitionTime.IsZero() {
+			continue
+		}
+		lastTransitionTime := con.LastTransitionTime.UTC().Format(time.RFC3339)
+		if !util.Contains(transitionTimes, lastTransitionTime) && now.Sub(con.LastTransitionTime.Time) <= window {
+			recorded = append(recorded, lastTransitionTime)
+			transitioned = true
+		}
+		break
+	}
+
+	if len(recorded) == 0 {
+		return nil, false
+	}
+	return recorded, transitioned
+}
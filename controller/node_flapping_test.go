@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *NodeControllerSuite) TestNodeFlappingSchedulingCooldown(c *C) {
+	type testCase struct {
+		cooldownSetting         string
+		cordoned                bool
+		readyTransitionAgo      time.Duration
+		readyTransitionTimesAgo []time.Duration
+		cooldownUntilIn         time.Duration
+
+		expectedTransitionTimes int
+		expectedCooldownUntilIn time.Duration
+		expectedSchedulable     longhorn.ConditionStatus
+		expectedReason          string
+	}
+	testCases := map[string]testCase{
+		"flapping node": {
+			readyTransitionAgo:      time.Minute,
+			readyTransitionTimesAgo: []time.Duration{8 * time.Minute, 6 * time.Minute, 4 * time.Minute},
+			expectedTransitionTimes: 4,
+			expectedCooldownUntilIn: 30 * time.Minute,
+			expectedSchedulable:     longhorn.ConditionStatusFalse,
+			expectedReason:          longhorn.NodeConditionReasonKubernetesNodeFlapping,
+		},
+		"transitions below threshold": {
+			readyTransitionAgo:      time.Minute,
+			readyTransitionTimesAgo: []time.Duration{4 * time.Minute},
+			expectedTransitionTimes: 2,
+			expectedSchedulable:     longhorn.ConditionStatusTrue,
+		},
+		"transitions out of detection window": {
+			readyTransitionAgo:      time.Minute,
+			readyTransitionTimesAgo: []time.Duration{20 * time.Minute, 15 * time.Minute, 12 * time.Minute},
+			expectedTransitionTimes: 1,
+			expectedSchedulable:     longhorn.ConditionStatusTrue,
+		},
+		"cooldown in progress": {
+			readyTransitionAgo:      time.Minute,
+			readyTransitionTimesAgo: []time.Duration{time.Minute},
+			cooldownUntilIn:         10 * time.Minute,
+			expectedTransitionTimes: 1,
+			expectedCooldownUntilIn: 10 * time.Minute,
+			expectedSchedulable:     longhorn.ConditionStatusFalse,
+			expectedReason:          longhorn.NodeConditionReasonKubernetesNodeFlapping,
+		},
+		"cooldown expired": {
+			readyTransitionAgo:      time.Hour,
+			cooldownUntilIn:         -time.Minute,
+			expectedTransitionTimes: 0,
+			expectedSchedulable:     longhorn.ConditionStatusTrue,
+		},
+		"cooldown disabled": {
+			cooldownSetting:         "0",
+			readyTransitionAgo:      time.Minute,
+			readyTransitionTimesAgo: []time.Duration{8 * time.Minute, 6 * time.Minute, 4 * time.Minute},
+			cooldownUntilIn:         10 * time.Minute,
+			expectedTransitionTimes: 4,
+			expectedSchedulable:     longhorn.ConditionStatusTrue,
+		},
+		"cordoned flapping node": {
+			cordoned:                true,
+			readyTransitionAgo:      time.Minute,
+			readyTransitionTimesAgo: []time.Duration{8 * time.Minute, 6 * time.Minute, 4 * time.Minute},
+			expectedTransitionTimes: 4,
+			expectedCooldownUntilIn: 30 * time.Minute,
+			expectedSchedulable:     longhorn.ConditionStatusFalse,
+			expectedReason:          longhorn.NodeConditionReasonKubernetesNodeCordoned,
+		},
+	}
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		s.SetUpTest(c)
+		s.controller.SetClock(getTestClock())
+		now := s.controller.clock.Now()
+
+		node := newNode(TestNode1, TestNamespace, true, longhorn.ConditionStatusUnknown, "")
+		for _, ago := range tc.readyTransitionTimesAgo {
+			node.Status.ReadyTransitionTimes = append(node.Status.ReadyTransitionTimes, now.Add(-ago).UTC().Format(time.RFC3339))
+		}
+		if tc.cooldownUntilIn != 0 {
+			node.Status.SchedulingCooldownUntil = now.Add(tc.cooldownUntilIn).UTC().Format(time.RFC3339)
+		}
+
+		kubeNode := newKubernetesNode(TestNode1, corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionFalse,
+			corev1.ConditionFalse, corev1.ConditionFalse, corev1.ConditionTrue)
+		kubeNode.Status.Conditions[0].LastTransitionTime = metav1.NewTime(now.Add(-tc.readyTransitionAgo))
+		kubeNode.Spec.Unschedulable = tc.cordoned
+
+		fixture := &NodeControllerFixture{
+			lhNodes: map[string]*longhorn.Node{
+				TestNode1: node,
+			},
+			lhSettings: map[string]*longhorn.Setting{
+				string(types.SettingNameDefaultInstanceManagerImage): newDefaultInstanceManagerImageSetting(),
+			},
+			lhInstanceManagers: map[string]*longhorn.InstanceManager{
+				TestInstanceManagerName: DefaultInstanceManagerTestNode1,
+			},
+			lhOrphans: map[string]*longhorn.Orphan{
+				DefaultOrphanTestNode1.Name: DefaultOrphanTestNode1,
+			},
+			pods: map[string]*corev1.Pod{
+				TestDaemon1: newDaemonPod(corev1.PodRunning, TestDaemon1, TestNamespace, TestNode1, TestIP1, &MountPropagationBidirectional),
+			},
+			nodes: map[string]*corev1.Node{
+				TestNode1: kubeNode,
+			},
+		}
+		if tc.cooldownSetting != "" {
+			fixture.lhSettings[string(types.SettingNameNodeFlappingSchedulingCooldown)] =
+				newSetting(string(types.SettingNameNodeFlappingSchedulingCooldown), tc.cooldownSetting)
+		}
+		s.initTest(c, fixture)
+
+		err := s.controller.diskMonitor.RunOnce()
+		c.Assert(err, IsNil)
+		err = s.controller.environmentCheckMonitor.RunOnce()
+		c.Assert(err, IsNil)
+
+		err = s.controller.syncNode(getKey(node, c))
+		c.Assert(err, IsNil)
+
+		n, err := s.lhClient.LonghornV1beta2().Nodes(TestNamespace).Get(context.TODO(), TestNode1, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+
+		c.Assert(n.Status.ReadyTransitionTimes, HasLen, tc.expectedTransitionTimes)
+		expectedCooldownUntil := ""
+		if tc.expectedCooldownUntilIn != 0 {
+			expectedCooldownUntil = now.Add(tc.expectedCooldownUntilIn).UTC().Format(time.RFC3339)
+		}
+		c.Assert(n.Status.SchedulingCooldownUntil, Equals, expectedCooldownUntil)
+
+		condition := types.GetCondition(n.Status.Conditions, longhorn.NodeConditionTypeSchedulable)
+		c.Assert(condition.Status, Equals, tc.expectedSchedulable)
+		c.Assert(condition.Reason, Equals, tc.expectedReason)
+	}
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -45,6 +46,9 @@ type OrphanController struct {
 	ds *datastore.DataStore
 
 	cacheSyncs []cache.InformerSynced
+
+	// for unit test
+	instanceDeleter func(im *longhorn.InstanceManager, dataEngine longhorn.DataEngineType, name, kind string) error
 }
 
 func NewOrphanController(
@@ -72,6 +76,8 @@ func NewOrphanController(
 
 		kubeClient:    kubeClient,
 		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-orphan-controller"}),
+
+		instanceDeleter: deleteInstanceManagerInstance,
 	}
 
 	var err error
@@ -93,9 +99,54 @@ func NewOrphanController(
 	}
 	oc.cacheSyncs = append(oc.cacheSyncs, ds.NodeInformer.HasSynced)
 
+	if _, err = ds.SettingInformer.AddEventHandlerWithResyncPeriod(cache.FilteringResourceEventHandler{
+		FilterFunc: isSettingOrphanInstanceAutoDeletion,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    oc.enqueueOrphanedInstances,
+			UpdateFunc: func(old, cur interface{}) { oc.enqueueOrphanedInstances(cur) },
+		},
+	}, 0); err != nil {
+		return nil, err
+	}
+	oc.cacheSyncs = append(oc.cacheSyncs, ds.SettingInformer.HasSynced)
+
 	return oc, nil
 }
 
+func isSettingOrphanInstanceAutoDeletion(obj interface{}) bool {
+	setting, ok := obj.(*longhorn.Setting)
+	if !ok {
+		return false
+	}
+
+	return types.SettingName(setting.Name) == types.SettingNameOrphanAutoDeletion ||
+		types.SettingName(setting.Name) == types.SettingNameOrphanInstanceAutoDeletionGracePeriod
+}
+
+func (oc *OrphanController) enqueueOrphanedInstances(obj interface{}) {
+	orphans, err := oc.ds.ListOrphansRO()
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to list orphans since %v", err))
+		return
+	}
+
+	for _, orphan := range orphans {
+		if isOrphanedInstance(orphan) {
+			oc.enqueueOrphan(orphan)
+		}
+	}
+}
+
+func (oc *OrphanController) enqueueOrphanAfter(obj interface{}, duration time.Duration) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to get key for object %#v: %v", obj, err))
+		return
+	}
+
+	oc.queue.AddAfter(key, duration)
+}
+
 func (oc *OrphanController) enqueueOrphan(obj interface{}) {
 	key, err := controller.KeyFunc(obj)
 	if err != nil {
@@ -254,6 +305,48 @@ func (oc *OrphanController) reconcile(orphanName string) (err error) {
 		return errors.Wrapf(err, "failed to update conditions for orphan %v", orphan.Name)
 	}
 
+	if isOrphanedInstance(orphan) {
+		return oc.handleOrphanedInstanceAutoDeletion(orphan)
+	}
+
+	return nil
+}
+
+func isOrphanedInstance(orphan *longhorn.Orphan) bool {
+	return orphan.Spec.Type == longhorn.OrphanTypeEngineInstance || orphan.Spec.Type == longhorn.OrphanTypeReplicaInstance
+}
+
+// handleOrphanedInstanceAutoDeletion deletes the orphaned instance once it outlives the grace period, so an instance
+// whose engine or replica is only temporarily missing is not removed.
+func (oc *OrphanController) handleOrphanedInstanceAutoDeletion(orphan *longhorn.Orphan) error {
+	autoDeletionEnabled, err := oc.ds.GetSettingAsBool(types.SettingNameOrphanAutoDeletion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %v setting", types.SettingNameOrphanAutoDeletion)
+	}
+	if !autoDeletionEnabled {
+		return nil
+	}
+
+	if types.GetCondition(orphan.Status.Conditions, longhorn.OrphanConditionTypeDataCleanable).Status != longhorn.ConditionStatusTrue {
+		return nil
+	}
+
+	gracePeriod, err := oc.ds.GetSettingAsInt(types.SettingNameOrphanInstanceAutoDeletionGracePeriod)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %v setting", types.SettingNameOrphanInstanceAutoDeletionGracePeriod)
+	}
+
+	remaining := orphan.CreationTimestamp.Add(time.Duration(gracePeriod) * time.Second).Sub(oc.clock.Now())
+	if remaining > 0 {
+		oc.enqueueOrphanAfter(orphan, remaining)
+		return nil
+	}
+
+	getLoggerForOrphan(oc.logger, orphan).Infof("Deleting orphan %v since the orphaned instance outlived the grace period %v seconds",
+		orphan.Name, gracePeriod)
+	if err := oc.ds.DeleteOrphan(orphan.Name); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete orphan %v", orphan.Name)
+	}
 	return nil
 }
 
@@ -298,7 +391,35 @@ func (oc *OrphanController) cleanupOrphanedData(orphan *longhorn.Orphan) (err er
 
 	switch orphan.Spec.Type {
 	case longhorn.OrphanTypeReplica:
+		adopted, adoptErr := oc.isOrphanedReplicaDataAdopted(orphan)
+		if adoptErr != nil {
+			return adoptErr
+		}
+		if adopted {
+			log.Infof("Orphan %v replica data store is adopted by a replica, so just delete the orphan resource object", orphan.Name)
+			return nil
+		}
 		err = oc.deleteOrphanedReplica(orphan)
+	case longhorn.OrphanTypeEngineInstance, longhorn.OrphanTypeReplicaInstance:
+		adopted, adoptErr := oc.isOrphanedInstanceAdopted(orphan)
+		if adoptErr != nil {
+			return adoptErr
+		}
+		if adopted {
+			log.Infof("Orphan %v instance is adopted by an engine or replica, so just delete the orphan resource object", orphan.Name)
+			return nil
+		}
+		err = oc.deleteOrphanedInstance(orphan)
+	case longhorn.OrphanTypeBackingImage:
+		adopted, adoptErr := oc.isOrphanedBackingImageAdopted(orphan)
+		if adoptErr != nil {
+			return adoptErr
+		}
+		if adopted {
+			log.Infof("Orphan %v backing image directory is used by a backing image, so just delete the orphan resource object", orphan.Name)
+			return nil
+		}
+		err = oc.deleteOrphanedBackingImage(orphan)
 	default:
 		err = fmt.Errorf("unknown orphan type %v", orphan.Spec.Type)
 	}
@@ -310,6 +431,133 @@ func (oc *OrphanController) cleanupOrphanedData(orphan *longhorn.Orphan) (err er
 	return err
 }
 
+// isOrphanedReplicaDataAdopted returns true if the orphaned replica data store is used by a replica again, e.g. a
+// replica scheduled by a replica placement hint after the cluster is rebuilt.
+func (oc *OrphanController) isOrphanedReplicaDataAdopted(orphan *longhorn.Orphan) (bool, error) {
+	replicas, err := oc.ds.ListReplicasByNodeRO(orphan.Spec.NodeID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list replicas on node %v", orphan.Spec.NodeID)
+	}
+	for _, replica := range replicas {
+		if replica.Spec.DiskID == orphan.Spec.Parameters[longhorn.OrphanDiskUUID] &&
+			replica.Spec.DataDirectoryName == orphan.Spec.Parameters[longhorn.OrphanDataName] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isOrphanedBackingImageAdopted returns true if the orphaned backing image directory is used by a backing image or a
+// backing image manager of the disk again.
+func (oc *OrphanController) isOrphanedBackingImageAdopted(orphan *longhorn.Orphan) (bool, error) {
+	diskUUID := orphan.Spec.Parameters[longhorn.OrphanDiskUUID]
+	directoryName := orphan.Spec.Parameters[longhorn.OrphanDataName]
+
+	bims, err := oc.ds.ListBackingImageManagersByDiskUUID(diskUUID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list backing image managers for disk %v", diskUUID)
+	}
+	for _, bim := range bims {
+		for biName, biUUID := range bim.Spec.BackingImages {
+			if types.GetBackingImageDirectoryName(biName, biUUID) == directoryName {
+				return true, nil
+			}
+		}
+	}
+
+	biName, biUUID, err := types.GetBackingImageNameAndUUIDFromDirectoryName(directoryName)
+	if err != nil {
+		return false, err
+	}
+	bi, err := oc.ds.GetBackingImageRO(biName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to get backing image %v", biName)
+	}
+	_, exists := bi.Spec.DiskFileSpecMap[diskUUID]
+	return bi.Status.UUID == biUUID && exists, nil
+}
+
+func (oc *OrphanController) deleteOrphanedBackingImage(orphan *longhorn.Orphan) error {
+	diskPath := orphan.Spec.Parameters[longhorn.OrphanDiskPath]
+	directoryName := orphan.Spec.Parameters[longhorn.OrphanDataName]
+
+	oc.logger.Infof("Deleting orphan %v backing image directory %v in disk %v on node %v",
+		orphan.Name, directoryName, diskPath, orphan.Status.OwnerID)
+
+	err := lhns.DeletePath(filepath.Join(types.GetBackingImageManagerDirectoryOnHost(diskPath), directoryName))
+	return errors.Wrapf(err, "failed to delete orphan backing image directory %v in disk %v", directoryName, diskPath)
+}
+
+// isOrphanedInstanceAdopted returns true if the engine or replica of the orphaned instance shows up again.
+func (oc *OrphanController) isOrphanedInstanceAdopted(orphan *longhorn.Orphan) (bool, error) {
+	instanceName := orphan.Spec.Parameters[longhorn.OrphanInstanceName]
+
+	var err error
+	if orphan.Spec.Type == longhorn.OrphanTypeEngineInstance {
+		_, err = oc.ds.GetEngineRO(instanceName)
+	} else {
+		_, err = oc.ds.GetReplicaRO(instanceName)
+	}
+	if err == nil {
+		return true, nil
+	}
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return false, errors.Wrapf(err, "failed to get %v for instance %v", orphan.Spec.Type, instanceName)
+}
+
+func (oc *OrphanController) deleteOrphanedInstance(orphan *longhorn.Orphan) (err error) {
+	instanceName := orphan.Spec.Parameters[longhorn.OrphanInstanceName]
+	imName := orphan.Spec.Parameters[longhorn.OrphanInstanceManager]
+
+	oc.logger.Infof("Deleting orphan %v instance %v in instance manager %v on node %v",
+		orphan.Name, instanceName, imName, orphan.Status.OwnerID)
+
+	im, err := oc.ds.GetInstanceManagerRO(imName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get instance manager %v for orphan %v", imName, orphan.Name)
+	}
+	// The instances are gone with the instance manager pod
+	if im.Status.CurrentState != longhorn.InstanceManagerStateRunning {
+		return nil
+	}
+
+	kind := longhorn.InstanceManagerTypeEngine
+	if orphan.Spec.Type == longhorn.OrphanTypeReplicaInstance {
+		kind = longhorn.InstanceManagerTypeReplica
+	}
+
+	err = oc.instanceDeleter(im, longhorn.DataEngineType(orphan.Spec.Parameters[longhorn.OrphanDataEngine]), instanceName, string(kind))
+	if err != nil && !types.ErrorIsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete orphan instance %v in instance manager %v", instanceName, imName)
+	}
+
+	return nil
+}
+
+// deleteInstanceManagerInstance only stops the process of the instance and keeps the replica data, which is handled by
+// the replica data store orphan if needed.
+func deleteInstanceManagerInstance(im *longhorn.InstanceManager, dataEngine longhorn.DataEngineType, name, kind string) error {
+	c, err := engineapi.NewInstanceManagerClient(im, true)
+	if err != nil {
+		return err
+	}
+	defer func(c io.Closer) {
+		if closeErr := c.Close(); closeErr != nil {
+			logrus.WithError(closeErr).Warn("Failed to close instance manager client")
+		}
+	}(c)
+
+	return c.InstanceDelete(dataEngine, name, kind, "", false)
+}
+
 func (oc *OrphanController) deleteOrphanedReplica(orphan *longhorn.Orphan) error {
 	oc.logger.Infof("Deleting orphan %v replica data store %v in disk %v on node %v",
 		orphan.Name, orphan.Spec.Parameters[longhorn.OrphanDataName],
@@ -406,19 +654,24 @@ func (oc *OrphanController) updateDataCleanableCondition(orphan *longhorn.Orphan
 		return nil
 	}
 
+	// The orphaned instances hold no data, so it is safe to delete them regardless of the eviction
+	if isOrphanedInstance(orphan) {
+		return nil
+	}
+
 	if node.Spec.EvictionRequested {
 		reason = longhorn.OrphanConditionTypeDataCleanableReasonNodeEvicted
 		return nil
 	}
 
-	if orphan.Spec.Type == longhorn.OrphanTypeReplica {
-		reason = oc.checkOrphanedReplicaDataCleanable(node, orphan)
+	if orphan.Spec.Type == longhorn.OrphanTypeReplica || orphan.Spec.Type == longhorn.OrphanTypeBackingImage {
+		reason = oc.checkOrphanedDiskDataCleanable(node, orphan)
 	}
 
 	return nil
 }
 
-func (oc *OrphanController) checkOrphanedReplicaDataCleanable(node *longhorn.Node, orphan *longhorn.Orphan) string {
+func (oc *OrphanController) checkOrphanedDiskDataCleanable(node *longhorn.Node, orphan *longhorn.Orphan) string {
 	diskName, err := oc.ds.GetReadyDisk(node.Name, orphan.Spec.Parameters[longhorn.OrphanDiskUUID])
 	if err != nil {
 		if strings.Contains(err.Error(), "cannot find the ready disk") {
@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+
+	. "gopkg.in/check.v1"
+)
+
+type deletedInstance struct {
+	instanceManager string
+	dataEngine      longhorn.DataEngineType
+	name            string
+	kind            string
+}
+
+func newTestOrphanController(lhClient *lhfake.Clientset, kubeClient *fake.Clientset, extensionsClient *apiextensionsfake.Clientset,
+	informerFactories *util.InformerFactories, controllerID string) (*OrphanController, error) {
+	ds := datastore.NewDataStore(TestNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+
+	logger := logrus.StandardLogger()
+	oc, err := NewOrphanController(logger, ds, scheme.Scheme, kubeClient, controllerID, TestNamespace)
+	if err != nil {
+		return nil, err
+	}
+	fakeRecorder := record.NewFakeRecorder(100)
+	oc.eventRecorder = fakeRecorder
+	for index := range oc.cacheSyncs {
+		oc.cacheSyncs[index] = alwaysReady
+	}
+	oc.SetClock(getTestClock())
+
+	return oc, nil
+}
+
+func newOrphanedInstance(orphanType longhorn.OrphanType, instanceName string, createdAt time.Time) *longhorn.Orphan {
+	dataEngine := string(longhorn.DataEngineTypeV1)
+	return &longhorn.Orphan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              types.GetOrphanChecksumNameForOrphanedInstance(instanceName, TestInstanceManagerName, dataEngine),
+			Namespace:         TestNamespace,
+			CreationTimestamp: metav1.NewTime(createdAt),
+			Labels:            types.GetOrphanLabelsForOrphanedInstance(TestNode1, TestInstanceManagerName, orphanType),
+		},
+		Spec: longhorn.OrphanSpec{
+			NodeID: TestNode1,
+			Type:   orphanType,
+			Parameters: map[string]string{
+				longhorn.OrphanInstanceName:    instanceName,
+				longhorn.OrphanInstanceManager: TestInstanceManagerName,
+				longhorn.OrphanDataEngine:      dataEngine,
+			},
+		},
+		Status: longhorn.OrphanStatus{
+			OwnerID: TestNode1,
+		},
+	}
+}
+
+func (s *TestSuite) TestOrphanedInstanceAutoDeletion(c *C) {
+	type testCase struct {
+		autoDeletion  bool
+		orphanAge     time.Duration
+		nodeReady     bool
+		expectDeleted bool
+	}
+	testCases := map[string]testCase{
+		"grace period passed": {
+			autoDeletion:  true,
+			orphanAge:     10 * time.Minute,
+			nodeReady:     true,
+			expectDeleted: true,
+		},
+		"within grace period": {
+			autoDeletion: true,
+			orphanAge:    time.Minute,
+			nodeReady:    true,
+		},
+		"auto deletion disabled": {
+			orphanAge: 10 * time.Minute,
+			nodeReady: true,
+		},
+		"node unavailable": {
+			autoDeletion: true,
+			orphanAge:    10 * time.Minute,
+		},
+	}
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		lhClient := lhfake.NewSimpleClientset()
+		extensionsClient := apiextensionsfake.NewSimpleClientset()
+		informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+		nodeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Nodes().Informer().GetIndexer()
+		settingIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Settings().Informer().GetIndexer()
+		orphanIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Orphans().Informer().GetIndexer()
+
+		oc, err := newTestOrphanController(lhClient, kubeClient, extensionsClient, informerFactories, TestNode1)
+		c.Assert(err, IsNil)
+
+		c.Assert(settingIndexer.Add(newSetting(string(types.SettingNameOrphanAutoDeletion), strconv.FormatBool(tc.autoDeletion))), IsNil)
+		c.Assert(settingIndexer.Add(newSetting(string(types.SettingNameOrphanInstanceAutoDeletionGracePeriod), "300")), IsNil)
+
+		node := newNode(TestNode1, TestNamespace, true, longhorn.ConditionStatusTrue, "")
+		if !tc.nodeReady {
+			node = newNode(TestNode1, TestNamespace, true, longhorn.ConditionStatusFalse, string(longhorn.NodeConditionReasonKubernetesNodeNotReady))
+		}
+		c.Assert(nodeIndexer.Add(node), IsNil)
+
+		orphan := newOrphanedInstance(longhorn.OrphanTypeEngineInstance, "orphaned-engine", oc.clock.Now().Add(-tc.orphanAge))
+		orphan, err = lhClient.LonghornV1beta2().Orphans(TestNamespace).Create(context.TODO(), orphan, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(orphanIndexer.Add(orphan), IsNil)
+
+		err = oc.reconcile(orphan.Name)
+		c.Assert(err, IsNil)
+
+		_, err = lhClient.LonghornV1beta2().Orphans(TestNamespace).Get(context.TODO(), orphan.Name, metav1.GetOptions{})
+		if tc.expectDeleted {
+			c.Assert(apierrors.IsNotFound(err), Equals, true, Commentf("unexpected error %v", err))
+		} else {
+			c.Assert(err, IsNil)
+		}
+	}
+}
+
+func (s *TestSuite) TestDeleteOrphanedInstance(c *C) {
+	type testCase struct {
+		orphanType       longhorn.OrphanType
+		imState          longhorn.InstanceManagerState
+		deleteErr        error
+		expectError      bool
+		expectedDeletion *deletedInstance
+	}
+	testCases := map[string]testCase{
+		"engine instance": {
+			orphanType: longhorn.OrphanTypeEngineInstance,
+			imState:    longhorn.InstanceManagerStateRunning,
+			expectedDeletion: &deletedInstance{
+				instanceManager: TestInstanceManagerName,
+				dataEngine:      longhorn.DataEngineTypeV1,
+				name:            "orphaned-instance",
+				kind:            string(longhorn.InstanceManagerTypeEngine),
+			},
+		},
+		"replica instance": {
+			orphanType: longhorn.OrphanTypeReplicaInstance,
+			imState:    longhorn.InstanceManagerStateRunning,
+			expectedDeletion: &deletedInstance{
+				instanceManager: TestInstanceManagerName,
+				dataEngine:      longhorn.DataEngineTypeV1,
+				name:            "orphaned-instance",
+				kind:            string(longhorn.InstanceManagerTypeReplica),
+			},
+		},
+		"instance already gone": {
+			orphanType: longhorn.OrphanTypeEngineInstance,
+			imState:    longhorn.InstanceManagerStateRunning,
+			deleteErr:  fmt.Errorf("cannot find instance orphaned-instance"),
+			expectedDeletion: &deletedInstance{
+				instanceManager: TestInstanceManagerName,
+				dataEngine:      longhorn.DataEngineTypeV1,
+				name:            "orphaned-instance",
+				kind:            string(longhorn.InstanceManagerTypeEngine),
+			},
+		},
+		"instance deletion failure": {
+			orphanType:  longhorn.OrphanTypeEngineInstance,
+			imState:     longhorn.InstanceManagerStateRunning,
+			deleteErr:   fmt.Errorf("failed to stop instance"),
+			expectError: true,
+			expectedDeletion: &deletedInstance{
+				instanceManager: TestInstanceManagerName,
+				dataEngine:      longhorn.DataEngineTypeV1,
+				name:            "orphaned-instance",
+				kind:            string(longhorn.InstanceManagerTypeEngine),
+			},
+		},
+		"instance manager stopped": {
+			orphanType: longhorn.OrphanTypeEngineInstance,
+			imState:    longhorn.InstanceManagerStateStopped,
+		},
+		"instance manager missing": {
+			orphanType: longhorn.OrphanTypeEngineInstance,
+		},
+	}
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		lhClient := lhfake.NewSimpleClientset()
+		extensionsClient := apiextensionsfake.NewSimpleClientset()
+		informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+		imIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().InstanceManagers().Informer().GetIndexer()
+
+		oc, err := newTestOrphanController(lhClient, kubeClient, extensionsClient, informerFactories, TestNode1)
+		c.Assert(err, IsNil)
+
+		var deletion *deletedInstance
+		oc.instanceDeleter = func(im *longhorn.InstanceManager, dataEngine longhorn.DataEngineType, name, kind string) error {
+			deletion = &deletedInstance{instanceManager: im.Name, dataEngine: dataEngine, name: name, kind: kind}
+			return tc.deleteErr
+		}
+
+		if tc.imState != "" {
+			im := newInstanceManager(TestInstanceManagerName, tc.imState, TestNode1, TestNode1, TestIP1,
+				nil, nil, longhorn.DataEngineTypeV1, TestInstanceManagerImage, false)
+			c.Assert(imIndexer.Add(im), IsNil)
+		}
+
+		err = oc.deleteOrphanedInstance(newOrphanedInstance(tc.orphanType, "orphaned-instance", oc.clock.Now()))
+		if tc.expectError {
+			c.Assert(err, NotNil)
+		} else {
+			c.Assert(err, IsNil)
+		}
+		c.Assert(deletion, DeepEquals, tc.expectedDeletion)
+	}
+}
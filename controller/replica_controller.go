@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -33,6 +34,7 @@ import (
 	"github.com/longhorn/longhorn-manager/datastore"
 	"github.com/longhorn/longhorn-manager/engineapi"
 	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
 
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
@@ -56,6 +58,8 @@ type ReplicaController struct {
 
 	rebuildingLock          *sync.Mutex
 	inProgressRebuildingMap map[string]struct{}
+
+	proxyConnCounter util.Counter
 }
 
 func NewReplicaController(
@@ -63,7 +67,8 @@ func NewReplicaController(
 	ds *datastore.DataStore,
 	scheme *runtime.Scheme,
 	kubeClient clientset.Interface,
-	namespace string, controllerID string) (*ReplicaController, error) {
+	namespace string, controllerID string,
+	proxyConnCounter util.Counter) (*ReplicaController, error) {
 
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartLogging(logrus.Infof)
@@ -83,6 +88,8 @@ func NewReplicaController(
 
 		rebuildingLock:          &sync.Mutex{},
 		inProgressRebuildingMap: map[string]struct{}{},
+
+		proxyConnCounter: proxyConnCounter,
 	}
 	rc.instanceHandler = NewInstanceHandler(ds, rc, rc.eventRecorder)
 
@@ -450,12 +457,17 @@ func (rc *ReplicaController) GetBackingImagePathForReplicaStarting(r *longhorn.R
 }
 
 func IsRebuildingReplica(r *longhorn.Replica) bool {
-	return r.Spec.RebuildRetryCount != 0 && r.Spec.HealthyAt == "" && r.Spec.FailedAt == ""
+	return datastore.IsReplicaRebuilding(r)
 }
 
 func (rc *ReplicaController) CanStartRebuildingReplica(r *longhorn.Replica) (bool, error) {
 	log := getLoggerForReplica(rc.logger, r)
 
+	if r.Spec.RebuildPaused {
+		log.Debug("Replica rebuilding is paused")
+		return false, nil
+	}
+
 	concurrentRebuildingLimit, err := rc.ds.GetSettingAsInt(types.SettingNameConcurrentReplicaRebuildPerNodeLimit)
 	if err != nil {
 		return false, err
@@ -468,6 +480,13 @@ func (rc *ReplicaController) CanStartRebuildingReplica(r *longhorn.Replica) (boo
 		return true, nil
 	}
 
+	// The priority of the waiting rebuildings may require the IO activity of the volumes from the engines, so it's
+	// collected before taking the rebuilding lock
+	outrankingReplicas, err := rc.getOutrankingWaitingRebuilds(r, int(concurrentRebuildingLimit))
+	if err != nil {
+		return false, err
+	}
+
 	// This is the only place in which the controller will operate
 	// the in progress rebuilding replica map. Then the main reconcile loop
 	// and the normal replicas will not be affected by the locking.
@@ -483,7 +502,7 @@ func (rc *ReplicaController) CanStartRebuildingReplica(r *longhorn.Replica) (boo
 		rsMap[replicaOnTheSameNode.Name] = replicaOnTheSameNode
 		// Just in case, this means the replica controller will try to recall
 		// in-progress rebuilding replicas even if the longhorn manager pod is restarted.
-		if IsRebuildingReplica(replicaOnTheSameNode) &&
+		if IsRebuildingReplica(replicaOnTheSameNode) && !replicaOnTheSameNode.Spec.RebuildPaused &&
 			(replicaOnTheSameNode.Status.CurrentState == longhorn.InstanceStateStarting ||
 				replicaOnTheSameNode.Status.CurrentState == longhorn.InstanceStateRunning) {
 			rc.inProgressRebuildingMap[replicaOnTheSameNode.Name] = struct{}{}
@@ -501,7 +520,8 @@ func (rc *ReplicaController) CanStartRebuildingReplica(r *longhorn.Replica) (boo
 			delete(rc.inProgressRebuildingMap, inProgressReplicaName)
 			continue
 		}
-		if !IsRebuildingReplica(replicaOnTheSameNode) {
+		// The paused rebuilding replica releases its slot
+		if !IsRebuildingReplica(replicaOnTheSameNode) || replicaOnTheSameNode.Spec.RebuildPaused {
 			delete(rc.inProgressRebuildingMap, inProgressReplicaName)
 		}
 	}
@@ -512,11 +532,60 @@ func (rc *ReplicaController) CanStartRebuildingReplica(r *longhorn.Replica) (boo
 		return false, nil
 	}
 
+	diskRebuildingLimit, err := rc.getDiskConcurrentRebuildLimit(r)
+	if err != nil {
+		return false, err
+	}
+	if diskRebuildingLimit > 0 {
+		inProgressOnTheSameDisk := getInProgressRebuildingReplicasOnDisk(rc.inProgressRebuildingMap, rsMap, r.Spec.DiskID)
+		if len(inProgressOnTheSameDisk) >= diskRebuildingLimit {
+			log.Warnf("Replica rebuildings for %+v are in progress on disk %v, which reaches or exceeds the concurrent limit value %v of the disk",
+				inProgressOnTheSameDisk, r.Spec.DiskID, diskRebuildingLimit)
+			return false, nil
+		}
+	}
+
+	if rc.isOutrankedByWaitingRebuilds(outrankingReplicas, int(concurrentRebuildingLimit)-len(rc.inProgressRebuildingMap)) {
+		log.Infof("Replica rebuilding is deferred for the waiting rebuildings with a higher priority on this node")
+		return false, nil
+	}
+
 	rc.inProgressRebuildingMap[r.Name] = struct{}{}
 
 	return true, nil
 }
 
+// getDiskConcurrentRebuildLimit returns the concurrent replica rebuild limit of the disk of the replica, 0 if the disk
+// doesn't have a limit or is not found on the node
+func (rc *ReplicaController) getDiskConcurrentRebuildLimit(r *longhorn.Replica) (int, error) {
+	node, err := rc.ds.GetNodeRO(r.Spec.NodeID)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	for diskName, diskStatus := range node.Status.DiskStatus {
+		if diskStatus.DiskUUID != r.Spec.DiskID {
+			continue
+		}
+		return node.Spec.Disks[diskName].ConcurrentReplicaRebuildLimit, nil
+	}
+	return 0, nil
+}
+
+// getInProgressRebuildingReplicasOnDisk returns the names of the in progress rebuilding replicas on the disk
+func getInProgressRebuildingReplicasOnDisk(inProgressRebuildingMap map[string]struct{}, replicas map[string]*longhorn.Replica, diskUUID string) []string {
+	names := []string{}
+	for name := range inProgressRebuildingMap {
+		if r, exists := replicas[name]; exists && r.Spec.DiskID == diskUUID {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (rc *ReplicaController) DeleteInstance(obj interface{}) (err error) {
 	r, ok := obj.(*longhorn.Replica)
 	if !ok {
@@ -783,11 +852,13 @@ func (rc *ReplicaController) enqueueNodeChange(oldObj, currObj interface{}) {
 	}
 
 	// if a node or disk changes its EvictionRequested, enqueue all replicas on that node/disk
+	// if a disk changes its ConcurrentReplicaRebuildLimit, enqueue all replicas on that disk to recheck the waiting rebuildings
 	evictionRequestedChangeOnNodeLevel := currNode.Spec.EvictionRequested != oldNode.Spec.EvictionRequested
 	for diskName, newDiskSpec := range currNode.Spec.Disks {
 		oldDiskSpec, ok := oldNode.Spec.Disks[diskName]
 		evictionRequestedChangeOnDiskLevel := !ok || (newDiskSpec.EvictionRequested != oldDiskSpec.EvictionRequested)
-		if diskStatus, existed := currNode.Status.DiskStatus[diskName]; existed && (evictionRequestedChangeOnNodeLevel || evictionRequestedChangeOnDiskLevel) {
+		rebuildLimitChangeOnDiskLevel := ok && newDiskSpec.ConcurrentReplicaRebuildLimit != oldDiskSpec.ConcurrentReplicaRebuildLimit
+		if diskStatus, existed := currNode.Status.DiskStatus[diskName]; existed && (evictionRequestedChangeOnNodeLevel || evictionRequestedChangeOnDiskLevel || rebuildLimitChangeOnDiskLevel) {
 			for replicaName := range diskStatus.ScheduledReplica {
 				if replica, err := rc.ds.GetReplica(replicaName); err == nil {
 					rc.enqueueReplica(replica)
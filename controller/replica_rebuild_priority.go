@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/longhorn/longhorn-manager/engineapi"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// rebuildCandidate is a rebuilding replica ranked by the replica rebuild priority policy
+type rebuildCandidate struct {
+	replica *longhorn.Replica
+	volume  *longhorn.Volume
+	// ioActivity is the IOPS of the volume, only collected for the io-activity policy
+	ioActivity uint64
+}
+
+// rebuildPriorityComparators compare the priority of two rebuilding replicas under each policy. A positive value means
+// the first replica is rebuilt first, and the replicas with the same priority are rebuilt in queue order.
+var rebuildPriorityComparators = map[types.ReplicaRebuildPriorityPolicy]func(a, b *rebuildCandidate) int{
+	types.ReplicaRebuildPriorityPolicyVolumePriority: func(a, b *rebuildCandidate) int {
+		return a.volume.Spec.RebuildPriority - b.volume.Spec.RebuildPriority
+	},
+	types.ReplicaRebuildPriorityPolicyAttachedFirst: func(a, b *rebuildCandidate) int {
+		isAttached := func(c *rebuildCandidate) int {
+			if c.volume.Status.State == longhorn.VolumeStateAttached {
+				return 1
+			}
+			return 0
+		}
+		return isAttached(a) - isAttached(b)
+	},
+	types.ReplicaRebuildPriorityPolicyIOActivity: func(a, b *rebuildCandidate) int {
+		switch {
+		case a.ioActivity > b.ioActivity:
+			return 1
+		case a.ioActivity < b.ioActivity:
+			return -1
+		}
+		return 0
+	},
+}
+
+// compareRebuildPriority compares the priority of two rebuilding replicas. The rebuild priority of the replicas set by
+// the user is compared first, then the replica rebuild priority policy breaks the tie. The comparator of the policy
+// is nil for the queue order policy.
+func compareRebuildPriority(a, b *rebuildCandidate, policyCompare func(a, b *rebuildCandidate) int) int {
+	if a.replica.Spec.RebuildPriority != b.replica.Spec.RebuildPriority {
+		return a.replica.Spec.RebuildPriority - b.replica.Spec.RebuildPriority
+	}
+	if policyCompare == nil {
+		return 0
+	}
+	return policyCompare(a, b)
+}
+
+// isWaitingForRebuildingSlot returns true if the rebuilding replica is waiting to be started, and would be started
+// once a rebuilding slot on the node is available
+func isWaitingForRebuildingSlot(r *longhorn.Replica) bool {
+	if !IsRebuildingReplica(r) || r.DeletionTimestamp != nil {
+		return false
+	}
+	if r.Spec.DesireState != longhorn.InstanceStateRunning || r.Spec.RebuildPaused {
+		return false
+	}
+	if r.Status.CurrentState != "" && r.Status.CurrentState != longhorn.InstanceStateStopped {
+		return false
+	}
+	waitForBackingImage := types.GetCondition(r.Status.Conditions, longhorn.ReplicaConditionTypeWaitForBackingImage)
+	return waitForBackingImage.Status != longhorn.ConditionStatusTrue
+}
+
+// getOutrankingWaitingRebuilds returns the waiting rebuilding replicas on the node of the replica with a higher
+// priority than the replica under the replica rebuild priority policy. The in progress rebuildings are copied under
+// the rebuilding lock, which is released before the priorities are collected, since the IO activity of the volumes is
+// read from the engines.
+func (rc *ReplicaController) getOutrankingWaitingRebuilds(r *longhorn.Replica, concurrentRebuildingLimit int) ([]*longhorn.Replica, error) {
+	policy, err := rc.ds.GetSettingValueExisted(types.SettingNameReplicaRebuildPriorityPolicy)
+	if err != nil {
+		return nil, err
+	}
+	policyCompare := rebuildPriorityComparators[types.ReplicaRebuildPriorityPolicy(policy)]
+
+	rc.rebuildingLock.Lock()
+	inProgressRebuildings := make(map[string]struct{}, len(rc.inProgressRebuildingMap))
+	for replicaName := range rc.inProgressRebuildingMap {
+		inProgressRebuildings[replicaName] = struct{}{}
+	}
+	rc.rebuildingLock.Unlock()
+
+	rs, err := rc.ds.ListReplicasByNodeRO(r.Spec.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	waitingReplicas := []*longhorn.Replica{}
+	for _, replica := range rs {
+		if replica.Name == r.Name {
+			continue
+		}
+		if _, inProgress := inProgressRebuildings[replica.Name]; inProgress {
+			continue
+		}
+		if isWaitingForRebuildingSlot(replica) {
+			waitingReplicas = append(waitingReplicas, replica)
+		}
+	}
+	// There are enough slots for all the waiting replicas
+	if len(waitingReplicas) < concurrentRebuildingLimit-len(inProgressRebuildings) {
+		return nil, nil
+	}
+
+	candidate, err := rc.getRebuildCandidate(r, types.ReplicaRebuildPriorityPolicy(policy))
+	if err != nil || candidate == nil {
+		return nil, err
+	}
+	outrankingReplicas := []*longhorn.Replica{}
+	for _, replica := range waitingReplicas {
+		waitingCandidate, err := rc.getRebuildCandidate(replica, types.ReplicaRebuildPriorityPolicy(policy))
+		if err != nil {
+			return nil, err
+		}
+		if waitingCandidate != nil && compareRebuildPriority(waitingCandidate, candidate, policyCompare) > 0 {
+			outrankingReplicas = append(outrankingReplicas, replica)
+		}
+	}
+	return outrankingReplicas, nil
+}
+
+// isOutrankedByWaitingRebuilds checks if the available rebuilding slots on the node should be left to the outranking
+// replicas which are still waiting. The outranking replicas are enqueued, so they take the slots instead. The caller
+// must hold the rebuilding lock.
+func (rc *ReplicaController) isOutrankedByWaitingRebuilds(outrankingReplicas []*longhorn.Replica, availableSlots int) bool {
+	waitingReplicas := []*longhorn.Replica{}
+	for _, replica := range outrankingReplicas {
+		if _, inProgress := rc.inProgressRebuildingMap[replica.Name]; !inProgress {
+			waitingReplicas = append(waitingReplicas, replica)
+		}
+	}
+	if len(waitingReplicas) < availableSlots {
+		return false
+	}
+
+	for _, replica := range waitingReplicas {
+		rc.enqueueReplica(replica)
+	}
+	return true
+}
+
+// getRebuildCandidate returns nil if the volume of the replica is gone
+func (rc *ReplicaController) getRebuildCandidate(r *longhorn.Replica, policy types.ReplicaRebuildPriorityPolicy) (*rebuildCandidate, error) {
+	v, err := rc.ds.GetVolumeRO(r.Spec.VolumeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	candidate := &rebuildCandidate{
+		replica: r,
+		volume:  v,
+	}
+	if policy == types.ReplicaRebuildPriorityPolicyIOActivity {
+		candidate.ioActivity = rc.getVolumeIOActivity(v)
+	}
+	return candidate, nil
+}
+
+// getVolumeIOActivity returns the current IOPS of the volume, or 0 if the volume is not running or the metrics are
+// unavailable
+func (rc *ReplicaController) getVolumeIOActivity(v *longhorn.Volume) uint64 {
+	log := rc.logger.WithFields(logrus.Fields{"volume": v.Name})
+
+	if v.Status.State != longhorn.VolumeStateAttached {
+		return 0
+	}
+
+	engines, err := rc.ds.ListVolumeEnginesRO(v.Name)
+	if err != nil {
+		log.WithError(err).Debug("Failed to list engines for the IO activity of the volume")
+		return 0
+	}
+	for _, e := range engines {
+		if e.Status.CurrentState != longhorn.InstanceStateRunning || e.Status.InstanceManagerName == "" {
+			continue
+		}
+		im, err := rc.ds.GetInstanceManagerRO(e.Status.InstanceManagerName)
+		if err != nil {
+			log.WithError(err).Debugf("Failed to get instance manager %v for the IO activity of the volume", e.Status.InstanceManagerName)
+			return 0
+		}
+		engineClientProxy, err := engineapi.NewEngineClientProxy(im, log, rc.proxyConnCounter, rc.ds)
+		if err != nil {
+			log.WithError(err).Debug("Failed to get engine proxy for the IO activity of the volume")
+			return 0
+		}
+		metrics, err := engineClientProxy.MetricsGet(e)
+		engineClientProxy.Close()
+		if err != nil {
+			log.WithError(err).Debug("Failed to get metrics for the IO activity of the volume")
+			return 0
+		}
+		return metrics.ReadIOPS + metrics.WriteIOPS
+	}
+	return 0
+}
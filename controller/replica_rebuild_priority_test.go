@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestRebuildPriorityComparators(c *C) {
+	newCandidate := func(priority int, state longhorn.VolumeState, ioActivity uint64) *rebuildCandidate {
+		return &rebuildCandidate{
+			volume: &longhorn.Volume{
+				Spec:   longhorn.VolumeSpec{RebuildPriority: priority},
+				Status: longhorn.VolumeStatus{State: state},
+			},
+			ioActivity: ioActivity,
+		}
+	}
+	idle := newCandidate(10, longhorn.VolumeStateDetached, 0)
+	busy := newCandidate(0, longhorn.VolumeStateAttached, 500)
+	quiet := newCandidate(0, longhorn.VolumeStateAttached, 10)
+
+	compare := rebuildPriorityComparators[types.ReplicaRebuildPriorityPolicyVolumePriority]
+	c.Assert(compare(idle, busy) > 0, Equals, true)
+	c.Assert(compare(busy, quiet), Equals, 0)
+
+	compare = rebuildPriorityComparators[types.ReplicaRebuildPriorityPolicyAttachedFirst]
+	c.Assert(compare(busy, idle) > 0, Equals, true)
+	c.Assert(compare(busy, quiet), Equals, 0)
+
+	compare = rebuildPriorityComparators[types.ReplicaRebuildPriorityPolicyIOActivity]
+	c.Assert(compare(busy, quiet) > 0, Equals, true)
+	c.Assert(compare(idle, quiet) < 0, Equals, true)
+
+	// The queue order policy doesn't reorder the rebuildings
+	_, exists := rebuildPriorityComparators[types.ReplicaRebuildPriorityPolicyQueueOrder]
+	c.Assert(exists, Equals, false)
+}
+
+func (s *TestSuite) TestIsWaitingForRebuildingSlot(c *C) {
+	r := &longhorn.Replica{
+		Spec: longhorn.ReplicaSpec{
+			InstanceSpec:      longhorn.InstanceSpec{DesireState: longhorn.InstanceStateRunning},
+			RebuildRetryCount: 1,
+		},
+		Status: longhorn.ReplicaStatus{
+			InstanceStatus: longhorn.InstanceStatus{CurrentState: longhorn.InstanceStateStopped},
+		},
+	}
+	c.Assert(isWaitingForRebuildingSlot(r), Equals, true)
+
+	r.Status.Conditions = types.SetCondition(r.Status.Conditions, longhorn.ReplicaConditionTypeWaitForBackingImage,
+		longhorn.ConditionStatusTrue, longhorn.ReplicaConditionReasonWaitForBackingImageWaiting, "")
+	c.Assert(isWaitingForRebuildingSlot(r), Equals, false)
+
+	r.Status.Conditions = nil
+	r.Status.CurrentState = longhorn.InstanceStateRunning
+	c.Assert(isWaitingForRebuildingSlot(r), Equals, false)
+
+	r.Status.CurrentState = longhorn.InstanceStateStopped
+	r.Spec.RebuildPaused = true
+	c.Assert(isWaitingForRebuildingSlot(r), Equals, false)
+
+	r.Spec.RebuildPaused = false
+	r.Spec.HealthyAt = "2024-01-01T00:00:00Z"
+	c.Assert(isWaitingForRebuildingSlot(r), Equals, false)
+}
+
+func (s *TestSuite) TestCompareRebuildPriority(c *C) {
+	newCandidate := func(replicaPriority, volumePriority int) *rebuildCandidate {
+		return &rebuildCandidate{
+			replica: &longhorn.Replica{Spec: longhorn.ReplicaSpec{RebuildPriority: replicaPriority}},
+			volume:  &longhorn.Volume{Spec: longhorn.VolumeSpec{RebuildPriority: volumePriority}},
+		}
+	}
+	volumePriority := rebuildPriorityComparators[types.ReplicaRebuildPriorityPolicyVolumePriority]
+
+	// The replica rebuild priority set by the user overrides the policy
+	bumped := newCandidate(1, 0)
+	important := newCandidate(0, 10)
+	c.Assert(compareRebuildPriority(bumped, important, volumePriority) > 0, Equals, true)
+
+	demoted := newCandidate(-1, 10)
+	regular := newCandidate(0, 0)
+	c.Assert(compareRebuildPriority(demoted, regular, nil) < 0, Equals, true)
+
+	// The policy breaks the tie
+	c.Assert(compareRebuildPriority(important, regular, volumePriority) > 0, Equals, true)
+	c.Assert(compareRebuildPriority(important, regular, nil), Equals, 0)
+}
@@ -27,6 +27,7 @@ import (
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -280,6 +281,7 @@ func (sc *SettingController) syncDangerZoneSettingsForManagedComponents(settingN
 		types.SettingNameTaintToleration,
 		types.SettingNameSystemManagedComponentsNodeSelector,
 		types.SettingNamePriorityClass,
+		types.SettingNameSystemManagedComponentsSchedulingOverrides,
 		types.SettingNameStorageNetwork,
 	}
 
@@ -297,6 +299,16 @@ func (sc *SettingController) syncDangerZoneSettingsForManagedComponents(settingN
 			if err := sc.updatePriorityClass(); err != nil {
 				return err
 			}
+		case types.SettingNameSystemManagedComponentsSchedulingOverrides:
+			if err := sc.updateTaintToleration(); err != nil {
+				return err
+			}
+			if err := sc.updatePriorityClass(); err != nil {
+				return err
+			}
+			if err := sc.updateTopologySpreadConstraints(); err != nil {
+				return err
+			}
 		case types.SettingNameStorageNetwork:
 			funcPreupdate := func() error {
 				detached, err := sc.ds.AreAllVolumesDetachedState()
@@ -396,24 +408,23 @@ func getResponsibleNodeID(ds *datastore.DataStore) (string, error) {
 	return responsibleNodes[0], nil
 }
 
-// updateTaintToleration deletes all user-deployed and system-managed components immediately with the updated taint toleration.
-func (sc *SettingController) updateTaintToleration() error {
-	setting, err := sc.ds.GetSettingWithAutoFillingRO(types.SettingNameTaintToleration)
+// getSystemManagedComponentScheduling returns the priority class, the tolerations and the topology spread constraints
+// the system managed object should have, according to the group of the component it belongs to.
+func (sc *SettingController) getSystemManagedComponentScheduling(obj runtime.Object) (string, []corev1.Toleration, []corev1.TopologySpreadConstraint, error) {
+	objMeta, err := meta.Accessor(obj)
 	if err != nil {
-		return err
+		return "", nil, nil, err
 	}
-	newTolerations := setting.Value
-	newTolerationsList, err := types.UnmarshalTolerations(newTolerations)
-	if err != nil {
-		return err
-	}
-	newTolerationsMap := util.TolerationListToMap(newTolerationsList)
+	return sc.ds.GetSystemManagedComponentScheduling(types.GetSystemManagedComponentByLabels(objMeta.GetLabels()))
+}
 
+// updateTaintToleration deletes all user-deployed and system-managed components immediately with the updated taint toleration.
+func (sc *SettingController) updateTaintToleration() error {
 	updatingRuntimeObjects, err := sc.collectRuntimeObjects()
 	if err != nil {
 		return errors.Wrap(err, "failed to collect runtime objects for toleration update")
 	}
-	notUpdatedTolerationObjs, err := getNotUpdatedTolerationList(newTolerationsMap, updatingRuntimeObjects...)
+	notUpdatedTolerationObjs, err := sc.getNotUpdatedTolerationList(updatingRuntimeObjects...)
 	if err != nil {
 		return err
 	}
@@ -430,6 +441,11 @@ func (sc *SettingController) updateTaintToleration() error {
 	}
 
 	for _, obj := range notUpdatedTolerationObjs {
+		_, newTolerationsList, _, err := sc.getSystemManagedComponentScheduling(obj)
+		if err != nil {
+			return err
+		}
+		newTolerationsMap := util.TolerationListToMap(newTolerationsList)
 		lastAppliedTolerationsList, err := getLastAppliedTolerationsList(obj)
 		if err != nil {
 			return err
@@ -500,14 +516,18 @@ func (sc *SettingController) collectRuntimeObjects() (returnCollectRuntimeObject
 	return returnCollectRuntimeObjects, nil
 }
 
-func getNotUpdatedTolerationList(newTolerationsMap map[string]corev1.Toleration, objs ...runtime.Object) ([]runtime.Object, error) {
+func (sc *SettingController) getNotUpdatedTolerationList(objs ...runtime.Object) ([]runtime.Object, error) {
 	notUpdatedObjsList := []runtime.Object{}
 	for _, obj := range objs {
+		_, newTolerationsList, _, err := sc.getSystemManagedComponentScheduling(obj)
+		if err != nil {
+			return notUpdatedObjsList, err
+		}
 		lastAppliedTolerationsList, err := getLastAppliedTolerationsList(obj)
 		if err != nil {
 			return notUpdatedObjsList, err
 		}
-		if !reflect.DeepEqual(util.TolerationListToMap(lastAppliedTolerationsList), newTolerationsMap) {
+		if !reflect.DeepEqual(util.TolerationListToMap(lastAppliedTolerationsList), util.TolerationListToMap(newTolerationsList)) {
 			notUpdatedObjsList = append(notUpdatedObjsList, obj)
 		}
 	}
@@ -574,17 +594,11 @@ func getLastAppliedTolerationsList(obj runtime.Object) ([]corev1.Toleration, err
 
 // updatePriorityClass deletes all user-deployed and system-managed components immediately with the updated priority class.
 func (sc *SettingController) updatePriorityClass() error {
-	setting, err := sc.ds.GetSettingWithAutoFillingRO(types.SettingNamePriorityClass)
-	if err != nil {
-		return err
-	}
-	newPriorityClass := setting.Value
-
 	updatingRuntimeObjects, err := sc.collectRuntimeObjects()
 	if err != nil {
 		return errors.Wrap(err, "failed to collect runtime objects for priority class update")
 	}
-	notUpdatedPriorityClassObjs, err := getNotUpdatedPriorityClassList(newPriorityClass, updatingRuntimeObjects...)
+	notUpdatedPriorityClassObjs, err := sc.getNotUpdatedPriorityClassList(updatingRuntimeObjects...)
 	if err != nil {
 		return err
 	}
@@ -601,6 +615,10 @@ func (sc *SettingController) updatePriorityClass() error {
 	}
 
 	for _, obj := range notUpdatedPriorityClassObjs {
+		newPriorityClass, _, _, err := sc.getSystemManagedComponentScheduling(obj)
+		if err != nil {
+			return err
+		}
 		switch objType := obj.(type) {
 		case *appsv1.DaemonSet:
 			ds := obj.(*appsv1.DaemonSet)
@@ -630,10 +648,14 @@ func (sc *SettingController) updatePriorityClass() error {
 	return nil
 }
 
-func getNotUpdatedPriorityClassList(newPriorityClassName string, objs ...runtime.Object) ([]runtime.Object, error) {
+func (sc *SettingController) getNotUpdatedPriorityClassList(objs ...runtime.Object) ([]runtime.Object, error) {
 	notUpdatedObjsList := []runtime.Object{}
 	oldPriorityClassName := ""
 	for _, obj := range objs {
+		newPriorityClassName, _, _, err := sc.getSystemManagedComponentScheduling(obj)
+		if err != nil {
+			return nil, err
+		}
 		switch objType := obj.(type) {
 		case *appsv1.DaemonSet:
 			ds := obj.(*appsv1.DaemonSet)
@@ -657,6 +679,74 @@ func getNotUpdatedPriorityClassList(newPriorityClassName string, objs ...runtime
 	return notUpdatedObjsList, nil
 }
 
+// updateTopologySpreadConstraints updates the CSI deployments and deletes the share manager pods immediately with the
+// updated topology spread constraints. The other system managed components run on specific nodes.
+func (sc *SettingController) updateTopologySpreadConstraints() error {
+	updatingRuntimeObjects, err := sc.collectRuntimeObjects()
+	if err != nil {
+		return errors.Wrap(err, "failed to collect runtime objects for topology spread constraints update")
+	}
+
+	notUpdatedObjs := []runtime.Object{}
+	for _, obj := range updatingRuntimeObjects {
+		_, _, topologySpreadConstraints, err := sc.getSystemManagedComponentScheduling(obj)
+		if err != nil {
+			return err
+		}
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			if types.GetSystemManagedComponentByLabels(o.Labels) != types.SystemManagedComponentCSI {
+				continue
+			}
+			newConstraints := util.GetTopologySpreadConstraintsWithDefaultSelector(topologySpreadConstraints, o.Spec.Selector.MatchLabels)
+			if !reflect.DeepEqual(o.Spec.Template.Spec.TopologySpreadConstraints, newConstraints) {
+				notUpdatedObjs = append(notUpdatedObjs, obj)
+			}
+		case *corev1.Pod:
+			if types.GetSystemManagedComponentByLabels(o.Labels) != types.SystemManagedComponentShareManager {
+				continue
+			}
+			newConstraints := util.GetTopologySpreadConstraintsWithDefaultSelector(topologySpreadConstraints, types.GetShareManagerComponentLabel())
+			if !reflect.DeepEqual(o.Spec.TopologySpreadConstraints, newConstraints) {
+				notUpdatedObjs = append(notUpdatedObjs, obj)
+			}
+		}
+	}
+	if len(notUpdatedObjs) == 0 {
+		return nil
+	}
+
+	detached, err := sc.ds.AreAllVolumesDetachedState()
+	if err != nil {
+		return errors.Wrapf(err, "failed to check volume detachment for %v setting update", types.SettingNameSystemManagedComponentsSchedulingOverrides)
+	}
+	if !detached {
+		return &types.ErrorInvalidState{Reason: fmt.Sprintf("failed to apply %v setting to Longhorn components when there are attached volumes. It will be eventually applied", types.SettingNameSystemManagedComponentsSchedulingOverrides)}
+	}
+
+	for _, obj := range notUpdatedObjs {
+		_, _, topologySpreadConstraints, err := sc.getSystemManagedComponentScheduling(obj)
+		if err != nil {
+			return err
+		}
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			sc.logger.Infof("Updating the topology spread constraints for %v", o.Name)
+			o.Spec.Template.Spec.TopologySpreadConstraints = util.GetTopologySpreadConstraintsWithDefaultSelector(topologySpreadConstraints, o.Spec.Selector.MatchLabels)
+			if _, err := sc.ds.UpdateDeployment(o); err != nil {
+				return err
+			}
+		case *corev1.Pod:
+			sc.logger.Infof("Deleting pod %v to update the topology spread constraints", o.Name)
+			if err := sc.ds.DeletePod(o.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func (sc *SettingController) updateKubernetesClusterAutoscalerEnabled() error {
 	// IM pods annotation will be handled in the instance manager controller
 
@@ -1548,12 +1638,14 @@ func (info *ClusterInfo) collectResourceUsage() error {
 
 func (info *ClusterInfo) collectSettings() error {
 	includeAsBoolean := map[types.SettingName]bool{
-		types.SettingNameTaintToleration:                     true,
-		types.SettingNameSystemManagedComponentsNodeSelector: true,
-		types.SettingNameRegistrySecret:                      true,
-		types.SettingNamePriorityClass:                       true,
-		types.SettingNameSnapshotDataIntegrityCronJob:        true,
-		types.SettingNameStorageNetwork:                      true,
+		types.SettingNameTaintToleration:                            true,
+		types.SettingNameSystemManagedComponentsNodeSelector:        true,
+		types.SettingNameRegistrySecret:                             true,
+		types.SettingNamePriorityClass:                              true,
+		types.SettingNameSnapshotDataIntegrityCronJob:               true,
+		types.SettingNameStorageNetwork:                             true,
+		types.SettingNameSystemManagedComponentsSchedulingOverrides: true,
+		types.SettingNameControllerWorkers:                          true,
 	}
 
 	include := map[types.SettingName]bool{
@@ -1561,41 +1653,70 @@ func (info *ClusterInfo) collectSettings() error {
 		types.SettingNameAllowVolumeCreationWithDegradedAvailability:              true,
 		types.SettingNameAutoCleanupSystemGeneratedSnapshot:                       true,
 		types.SettingNameAutoDeletePodWhenVolumeDetachedUnexpectedly:              true,
+		types.SettingNameAutoFilesystemTrim:                                       true,
+		types.SettingNameAutoFilesystemTrimDeletedSpaceThreshold:                  true,
+		types.SettingNameAutoFilesystemTrimInterval:                               true,
 		types.SettingNameAutoSalvage:                                              true,
 		types.SettingNameBackingImageCleanupWaitInterval:                          true,
 		types.SettingNameBackingImageRecoveryWaitInterval:                         true,
 		types.SettingNameBackupCompressionMethod:                                  true,
 		types.SettingNameBackupConcurrentLimit:                                    true,
+		types.SettingNameCapacityRebalance:                                        true,
+		types.SettingNameCapacityRebalanceInterval:                                true,
+		types.SettingNameCapacityRebalanceUtilizationGap:                          true,
+		types.SettingNameConcurrentAutoFilesystemTrimPerNodeLimit:                 true,
 		types.SettingNameConcurrentAutomaticEngineUpgradePerNodeLimit:             true,
 		types.SettingNameConcurrentBackupRestorePerNodeLimit:                      true,
+		types.SettingNameConcurrentCapacityRebalancePerClusterLimit:               true,
 		types.SettingNameConcurrentReplicaRebuildPerNodeLimit:                     true,
+		types.SettingNameConcurrentReplicaScrubPerClusterLimit:                    true,
 		types.SettingNameConcurrentBackingImageCopyReplenishPerNodeLimit:          true,
 		types.SettingNameCRDAPIVersion:                                            true,
 		types.SettingNameCreateDefaultDiskLabeledNodes:                            true,
+		types.SettingNameDataLocalityLoadAwareDiskUsageThreshold:                  true,
+		types.SettingNameDataLocalityLoadAwareIOUtilizationThreshold:              true,
 		types.SettingNameDefaultDataLocality:                                      true,
 		types.SettingNameDefaultMinNumberOfBackingImageCopies:                     true,
 		types.SettingNameDefaultReplicaCount:                                      true,
 		types.SettingNameDisableRevisionCounter:                                   true,
 		types.SettingNameDisableSchedulingOnCordonedNode:                          true,
+		types.SettingNameEngineImageGarbageCollection:                             true,
+		types.SettingNameEngineImageGarbageCollectionKeepCount:                    true,
+		types.SettingNameEngineImageGarbageCollectionWaitInterval:                 true,
+		types.SettingNameEngineRebindTimeout:                                      true,
 		types.SettingNameEngineReplicaTimeout:                                     true,
 		types.SettingNameFailedBackupTTL:                                          true,
 		types.SettingNameFastReplicaRebuildEnabled:                                true,
+		types.SettingNameFIPSModeEnabled:                                          true,
 		types.SettingNameGuaranteedInstanceManagerCPU:                             true,
+		types.SettingNameInstanceManagerCPUPerInstance:                            true,
+		types.SettingNameInstanceManagerMemoryPerInstance:                         true,
+		types.SettingNameIPFamily:                                                 true,
 		types.SettingNameKubernetesClusterAutoscalerEnabled:                       true,
 		types.SettingNameNodeDownPodDeletionPolicy:                                true,
+		types.SettingNameNodeDownStaleAttachmentCleanup:                           true,
 		types.SettingNameNodeDrainPolicy:                                          true,
+		types.SettingNameNodeFlappingDetectionWindow:                              true,
+		types.SettingNameNodeFlappingSchedulingCooldown:                           true,
+		types.SettingNameNodeFlappingThreshold:                                    true,
 		types.SettingNameOrphanAutoDeletion:                                       true,
+		types.SettingNameOrphanInstanceAutoDeletionGracePeriod:                    true,
+		types.SettingNameOrphanBackingImageAutoDeletion:                           true,
 		types.SettingNameRecurringFailedJobsHistoryLimit:                          true,
 		types.SettingNameRecurringSuccessfulJobsHistoryLimit:                      true,
 		types.SettingNameRemoveSnapshotsDuringFilesystemTrim:                      true,
 		types.SettingNameReplicaAutoBalance:                                       true,
+		types.SettingNameReplicaAutoBalanceDiskIOLatencyThreshold:                 true,
+		types.SettingNameReplicaAutoBalanceDiskPressureInterval:                   true,
 		types.SettingNameReplicaAutoBalanceDiskPressurePercentage:                 true,
 		types.SettingNameReplicaFileSyncHTTPClientTimeout:                         true,
+		types.SettingNameReplicaRebuildPriorityPolicy:                             true,
 		types.SettingNameReplicaReplenishmentWaitInterval:                         true,
 		types.SettingNameReplicaSoftAntiAffinity:                                  true,
 		types.SettingNameReplicaZoneSoftAntiAffinity:                              true,
 		types.SettingNameReplicaDiskSoftAntiAffinity:                              true,
 		types.SettingNameRestoreConcurrentLimit:                                   true,
+		types.SettingNameRestoreReplicaPlacementCheck:                             true,
 		types.SettingNameRestoreVolumeRecurringJobs:                               true,
 		types.SettingNameRWXVolumeFastFailover:                                    true,
 		types.SettingNameSnapshotDataIntegrity:                                    true,
@@ -1610,6 +1731,7 @@ func (info *ClusterInfo) collectSettings() error {
 		types.SettingNameV1DataEngine:                                             true,
 		types.SettingNameV2DataEngine:                                             true,
 		types.SettingNameV2DataEngineGuaranteedInstanceManagerCPU:                 true,
+		types.SettingNameVolumeReconciliationSharding:                             true,
 	}
 
 	settings, err := info.ds.ListSettings()
@@ -39,6 +39,11 @@ import (
 
 const shareManagerLeaseDurationSeconds = 7 // This should be slightly more than twice the share-manager lease renewal interval.
 
+const (
+	shareManagerFilesystemResizeRetryInterval    = 30 * time.Second
+	maxShareManagerFilesystemResizeRetryInterval = 5 * time.Minute
+)
+
 type nfsServerConfig struct {
 	enableFastFailover bool
 	leaseLifetime      int
@@ -57,6 +62,9 @@ type ShareManagerController struct {
 
 	ds *datastore.DataStore
 
+	// filesystemResizeBackoff spaces the retries of the filesystem resize while it keeps failing
+	filesystemResizeBackoff *controllerBackoff
+
 	cacheSyncs []cache.InformerSynced
 }
 
@@ -88,6 +96,8 @@ func NewShareManagerController(
 
 		ds: ds,
 	}
+	c.filesystemResizeBackoff = c.newBackoff("longhorn-share-manager/filesystem-resize",
+		shareManagerFilesystemResizeRetryInterval, maxShareManagerFilesystemResizeRetryInterval)
 
 	var err error
 	// need shared volume manager informer
@@ -147,6 +157,16 @@ func (c *ShareManagerController) enqueueShareManager(obj interface{}) {
 	c.queue.Add(key)
 }
 
+func (c *ShareManagerController) enqueueShareManagerAfter(obj interface{}, duration time.Duration) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %#v: %v", obj, err))
+		return
+	}
+
+	c.queue.AddAfter(key, duration)
+}
+
 func (c *ShareManagerController) enqueueShareManagerForVolume(obj interface{}) {
 	volume, isVolume := obj.(*longhorn.Volume)
 	if !isVolume {
@@ -620,6 +640,41 @@ func (c *ShareManagerController) mountShareManagerVolume(sm *longhorn.ShareManag
 	return nil
 }
 
+// resizeShareManagerFilesystem resizes the exported filesystem once the engine of the volume is expanded, so the NFS
+// clients see the new size regardless of which node, if any, gets the NodeExpandVolume call of the CSI plugin.
+func (c *ShareManagerController) resizeShareManagerFilesystem(sm *longhorn.ShareManager, volume *longhorn.Volume) error {
+	if volume.Status.ExpansionRequired || sm.Status.FilesystemSize == volume.Spec.Size {
+		return nil
+	}
+
+	podName := types.GetShareManagerPodNameFromShareManagerName(sm.Name)
+	pod, err := c.ds.GetPod(podName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to retrieve pod %v for share manager from datastore", podName)
+	}
+	if pod == nil {
+		return fmt.Errorf("pod %v for share manager not found", podName)
+	}
+
+	client, err := engineapi.NewShareManagerClient(sm, pod)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create share manager client for pod %v", podName)
+	}
+	defer func(client io.Closer) {
+		if closeErr := client.Close(); closeErr != nil {
+			c.logger.WithError(closeErr).Warn("Failed to close share manager client")
+		}
+	}(client)
+
+	if err := client.FilesystemResize(); err != nil {
+		return errors.Wrapf(err, "failed to resize filesystem of share manager pod %v", podName)
+	}
+
+	getLoggerForShareManager(c.logger, sm).Infof("Resized share manager filesystem to volume size %v", volume.Spec.Size)
+	sm.Status.FilesystemSize = volume.Spec.Size
+	return nil
+}
+
 func (c *ShareManagerController) detachShareManagerVolume(sm *longhorn.ShareManager, va *longhorn.VolumeAttachment) {
 	log := getLoggerForShareManager(c.logger, sm)
 
@@ -687,7 +742,21 @@ func (c *ShareManagerController) syncShareManagerVolume(sm *longhorn.ShareManage
 		if err != nil {
 			log.WithError(err).Error("Failed to mount share manager volume")
 			sm.Status.State = longhorn.ShareManagerStateError
+			return nil
+		}
+		now := c.clock.Now()
+		if c.filesystemResizeBackoff.IsInBackOffSinceUpdate(sm.Name, now) {
+			// The resize is retried once the backoff is over
+			return nil
 		}
+		if err := c.resizeShareManagerFilesystem(sm, volume); err != nil {
+			log.WithError(err).Warn("Failed to resize share manager filesystem")
+			// The successful sync resets the rate limiter of the queue, so the resize is retried after the backoff
+			c.filesystemResizeBackoff.Next(sm.Name, now)
+			c.enqueueShareManagerAfter(sm, c.filesystemResizeBackoff.Get(sm.Name))
+			return nil
+		}
+		c.filesystemResizeBackoff.DeleteEntry(sm.Name)
 		return nil
 	}
 
@@ -716,6 +785,7 @@ func (c *ShareManagerController) syncShareManagerVolume(sm *longhorn.ShareManage
 	if sm.Status.State != longhorn.ShareManagerStateStarting {
 		log.Info("Starting share manager")
 		sm.Status.State = longhorn.ShareManagerStateStarting
+		sm.Status.FilesystemSize = 0
 	}
 
 	// For the RWX volume attachment, VolumeAttachment controller will not directly handle
@@ -1165,9 +1235,9 @@ func (c *ShareManagerController) createServiceAndEndpoint(shareManager *longhorn
 func (c *ShareManagerController) createShareManagerPod(sm *longhorn.ShareManager) (*corev1.Pod, error) {
 	log := getLoggerForShareManager(c.logger, sm)
 
-	tolerations, err := c.ds.GetSettingTaintToleration()
+	priorityClass, tolerations, topologySpreadConstraints, err := c.ds.GetSystemManagedComponentScheduling(types.SystemManagedComponentShareManager)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get taint toleration setting before creating share manager pod")
+		return nil, errors.Wrap(err, "failed to get scheduling settings before creating share manager pod")
 	}
 
 	nodeSelector, err := c.ds.GetSettingSystemManagedComponentsNodeSelector()
@@ -1192,12 +1262,6 @@ func (c *ShareManagerController) createShareManagerPod(sm *longhorn.ShareManager
 	}
 	registrySecret := setting.Value
 
-	setting, err = c.ds.GetSettingWithAutoFillingRO(types.SettingNamePriorityClass)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get priority class setting before creating share manager pod")
-	}
-	priorityClass := setting.Value
-
 	err = c.cleanupService(sm)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to cleanup service for share manager %v", sm.Name)
@@ -1309,6 +1373,8 @@ func (c *ShareManagerController) createShareManagerPod(sm *longhorn.ShareManager
 
 	manifest := c.createPodManifest(sm, volume.Spec.DataEngine, annotations, tolerations, affinity, imagePullPolicy, nil, registrySecret,
 		priorityClass, nodeSelector, fsType, mountOptions, cryptoKey, cryptoParams, nfsConfig)
+	manifest.Spec.TopologySpreadConstraints = util.GetTopologySpreadConstraintsWithDefaultSelector(topologySpreadConstraints,
+		map[string]string{types.GetLonghornLabelComponentKey(): types.LonghornLabelShareManager})
 
 	storageNetwork, err := c.ds.GetSettingWithAutoFillingRO(types.SettingNameStorageNetwork)
 	if err != nil {
@@ -1362,6 +1428,18 @@ func (c *ShareManagerController) createServiceManifest(sm *longhorn.ShareManager
 		log.WithError(err).Warnf("Failed to check storage network for RWX volume")
 	}
 
+	// The service is of the same IP family as the share manager endpoint on a dual-stack cluster
+	if ipv6, err := c.ds.IsIPv6Preferred(); err != nil {
+		log.WithError(err).Warn("Failed to get the preferred IP family, using the default IP family of the cluster for the service")
+	} else {
+		ipFamilyPolicy := corev1.IPFamilyPolicySingleStack
+		service.Spec.IPFamilyPolicy = &ipFamilyPolicy
+		service.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol}
+		if ipv6 {
+			service.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv6Protocol}
+		}
+	}
+
 	if storageNetworkForRWXVolume {
 		// Create a headless service do it doesn't use a cluster IP. This allows
 		// directly reaching the share manager pods using their individual
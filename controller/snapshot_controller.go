@@ -3,11 +3,15 @@ package controller
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,6 +37,12 @@ import (
 
 const (
 	snapshotErrorLost = "lost track of the corresponding snapshot info inside volume engine"
+
+	safetySnapshotBackupWaitInterval = time.Minute
+
+	// shareManagerFilesystemFreezeTimeout is the longest time the filesystem of a RWX volume is kept frozen while
+	// its snapshot is created
+	shareManagerFilesystemFreezeTimeout = time.Minute
 )
 
 type SnapshotController struct {
@@ -100,6 +110,11 @@ func NewSnapshotController(
 	sc.cacheSyncs = append(sc.cacheSyncs, ds.EngineInformer.HasSynced)
 
 	if _, err = ds.VolumeInformer.AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, cur interface{}) {
+			sc.enqueueVolumeSnapshotMaxChainLengthChange(old, cur)
+			sc.enqueueVolumeSnapshotQuotaChange(old, cur)
+			sc.enqueueVolumeSnapshotRevertChange(old, cur)
+		},
 		DeleteFunc: sc.enqueueVolumeChange,
 	}, 0); err != nil {
 		return nil, err
@@ -169,6 +184,7 @@ func (sc *SnapshotController) enqueueEngineChange(oldObj, curObj interface{}) {
 
 	needEnqueueSnapshots := curEngine.Status.CurrentState != oldEngine.Status.CurrentState ||
 		!reflect.DeepEqual(curEngine.Status.PurgeStatus, oldEngine.Status.PurgeStatus) ||
+		isSnapshotPurgeUnblocked(oldEngine, curEngine) ||
 		!reflect.DeepEqual(curEngine.Status.Snapshots, oldEngine.Status.Snapshots)
 
 	if !needEnqueueSnapshots {
@@ -187,6 +203,13 @@ func (sc *SnapshotController) enqueueEngineChange(oldObj, curObj interface{}) {
 	}
 }
 
+// isSnapshotPurgeUnblocked returns true if the snapshot purge of the deleted snapshots stops waiting for the expansion
+// or the rebuild of the engine
+func isSnapshotPurgeUnblocked(oldEngine, curEngine *longhorn.Engine) bool {
+	return getBlockingVolumeOperation(oldEngine, longhorn.VolumeOperationTypeSnapshotPurge) != "" &&
+		getBlockingVolumeOperation(curEngine, longhorn.VolumeOperationTypeSnapshotPurge) == ""
+}
+
 func filterSnapshotsForEngineEnqueuing(oldEngine, curEngine *longhorn.Engine, snapshots map[string]*longhorn.Snapshot) map[string]*longhorn.Snapshot {
 	targetSnapshots := make(map[string]*longhorn.Snapshot)
 
@@ -194,7 +217,7 @@ func filterSnapshotsForEngineEnqueuing(oldEngine, curEngine *longhorn.Engine, sn
 		return snapshots
 	}
 
-	if !reflect.DeepEqual(curEngine.Status.PurgeStatus, oldEngine.Status.PurgeStatus) {
+	if !reflect.DeepEqual(curEngine.Status.PurgeStatus, oldEngine.Status.PurgeStatus) || isSnapshotPurgeUnblocked(oldEngine, curEngine) {
 		for snapName, snap := range snapshots {
 			if snap.DeletionTimestamp != nil {
 				targetSnapshots[snapName] = snap
@@ -240,6 +263,89 @@ func (sc *SnapshotController) enqueueVolumeChange(obj interface{}) {
 	}
 }
 
+// enqueueVolumeSnapshotMaxChainLengthChange enqueues the snapshots of the volume to enforce the updated snapshot max
+// chain length
+func (sc *SnapshotController) enqueueVolumeSnapshotMaxChainLengthChange(oldObj, curObj interface{}) {
+	oldVol, ok := oldObj.(*longhorn.Volume)
+	if !ok {
+		return
+	}
+	curVol, ok := curObj.(*longhorn.Volume)
+	if !ok {
+		return
+	}
+	if oldVol.Spec.SnapshotMaxChainLength == curVol.Spec.SnapshotMaxChainLength || curVol.Spec.SnapshotMaxChainLength == 0 {
+		return
+	}
+
+	snapshots, err := sc.ds.ListVolumeSnapshotsRO(curVol.Name)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("snapshot controller failed to list snapshots when enqueuing volume %v: %v", curVol.Name, err))
+		return
+	}
+	for _, snap := range snapshots {
+		sc.enqueueSnapshot(snap)
+	}
+}
+
+// enqueueVolumeSnapshotQuotaChange enqueues the snapshots waiting to be taken once the snapshot quota of the volume is
+// no longer exceeded
+func (sc *SnapshotController) enqueueVolumeSnapshotQuotaChange(oldObj, curObj interface{}) {
+	oldVol, ok := oldObj.(*longhorn.Volume)
+	if !ok {
+		return
+	}
+	curVol, ok := curObj.(*longhorn.Volume)
+	if !ok {
+		return
+	}
+	if types.GetCondition(oldVol.Status.Conditions, longhorn.VolumeConditionTypeSnapshotQuotaExceeded).Status != longhorn.ConditionStatusTrue ||
+		types.GetCondition(curVol.Status.Conditions, longhorn.VolumeConditionTypeSnapshotQuotaExceeded).Status == longhorn.ConditionStatusTrue {
+		return
+	}
+
+	snapshots, err := sc.ds.ListVolumeSnapshotsRO(curVol.Name)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("snapshot controller failed to list snapshots when enqueuing volume %v: %v", curVol.Name, err))
+		return
+	}
+	for _, snap := range snapshots {
+		if snap.Spec.CreateSnapshot && snap.Status.CreationTime == "" {
+			sc.enqueueSnapshot(snap)
+		}
+	}
+}
+
+// enqueueVolumeSnapshotRevertChange enqueues the snapshots requesting a revert once the volume may become ready to be
+// reverted, or the volume is attached in maintenance mode for the revert
+func (sc *SnapshotController) enqueueVolumeSnapshotRevertChange(oldObj, curObj interface{}) {
+	oldVol, ok := oldObj.(*longhorn.Volume)
+	if !ok {
+		return
+	}
+	curVol, ok := curObj.(*longhorn.Volume)
+	if !ok {
+		return
+	}
+	if oldVol.Status.State == curVol.Status.State &&
+		oldVol.Status.FrontendDisabled == curVol.Status.FrontendDisabled &&
+		oldVol.Status.RestoreRequired == curVol.Status.RestoreRequired &&
+		oldVol.Spec.MigrationNodeID == curVol.Spec.MigrationNodeID {
+		return
+	}
+
+	snapshots, err := sc.ds.ListVolumeSnapshotsRO(curVol.Name)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("snapshot controller failed to list snapshots when enqueuing volume %v: %v", curVol.Name, err))
+		return
+	}
+	for _, snap := range snapshots {
+		if snap.Spec.RevertRequested {
+			sc.enqueueSnapshot(snap)
+		}
+	}
+}
+
 // If DisableSnapshotPurge is transitioning from true to false, there may be a backlog of snapshots with
 // deletionTimestamps that we are ignoring. Requeue all such snapshots.
 func (sc *SnapshotController) enqueueSettingChange(obj interface{}) {
@@ -259,6 +365,11 @@ func (sc *SnapshotController) enqueueSettingChange(obj interface{}) {
 		}
 	}
 
+	if types.SettingName(setting.Name) == types.SettingNameSafetySnapshotRetentionPeriod {
+		sc.enqueueSafetySnapshots()
+		return
+	}
+
 	if types.SettingName(setting.Name) != types.SettingNameDisableSnapshotPurge || setting.Value == "true" {
 		return
 	}
@@ -276,6 +387,20 @@ func (sc *SnapshotController) enqueueSettingChange(obj interface{}) {
 	}
 }
 
+func (sc *SnapshotController) enqueueSafetySnapshots() {
+	snapshots, err := sc.ds.ListSnapshotsRO(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("snapshot controller failed to list snapshots when enqueuing setting %v: %v",
+			types.SettingNameSafetySnapshotRetentionPeriod, err))
+		return
+	}
+	for _, snap := range snapshots {
+		if isSafetySnapshot(snap) {
+			sc.enqueueSnapshot(snap)
+		}
+	}
+}
+
 func (sc *SnapshotController) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
 	defer sc.queue.ShutDown()
@@ -378,6 +503,10 @@ func (sc *SnapshotController) reconcile(snapshotName string) (err error) {
 			return sc.ds.RemoveFinalizerForSnapshot(snapshot)
 		}
 
+		if err := sc.handleRevertAttachmentTicketDeletion(snapshot); err != nil {
+			return err
+		}
+
 		engine, err := sc.getTheOnlyEngineCRforSnapshotRO(snapshot)
 		if err != nil {
 			return err
@@ -464,6 +593,11 @@ func (sc *SnapshotController) reconcile(snapshotName string) (err error) {
 			snapshot.Status.Error = fmt.Sprintf("failed to take snapshot because the volume engine %v is not running. Waiting for the volume to be attached", engine.Name)
 			return nil
 		}
+		if err := sc.ds.CheckVolumeSnapshotQuota(snapshot.Spec.Volume); err != nil {
+			// The snapshot is taken once the snapshots are removed, see enqueueVolumeSnapshotQuotaChange
+			snapshot.Status.Error = fmt.Sprintf("failed to take snapshot: %v", err)
+			return nil
+		}
 		err = sc.handleSnapshotCreate(snapshot, engine)
 		if err != nil {
 			snapshot.Status.Error = err.Error()
@@ -485,23 +619,356 @@ func (sc *SnapshotController) reconcile(snapshotName string) (err error) {
 		}
 		// Newly created snapshotCR, wait for the snapshotInfo to be appeared inside engine.Status.Snapshot
 		snapshot.Status.ReadyToUse = false
-		return nil
+		return sc.handleSnapshotRevert(snapshot, engine)
 	}
 
 	if err := syncSnapshotWithSnapshotInfo(snapshot, snapshotInfo, engine.Spec.VolumeSize); err != nil {
 		return err
 	}
 
+	if err := sc.handleSnapshotRevert(snapshot, engine); err != nil {
+		return err
+	}
+
+	if err := sc.handleSafetySnapshotExpiration(snapshot); err != nil {
+		return err
+	}
+
+	return sc.handleSnapshotMaxChainLength(snapshot)
+}
+
+func isSafetySnapshot(snapshot *longhorn.Snapshot) bool {
+	_, ok := snapshot.Status.Labels[types.GetLonghornLabelKey(types.LonghornLabelSafetySnapshot)]
+	return ok
+}
+
+// handleSafetySnapshotExpiration deletes a safety snapshot taken before a destructive operation once its retention
+// period is over. Otherwise the snapshot is enqueued again when the period will be over.
+func (sc *SnapshotController) handleSafetySnapshotExpiration(snapshot *longhorn.Snapshot) error {
+	if !isSafetySnapshot(snapshot) || snapshot.Status.CreationTime == "" || !snapshot.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	retentionPeriod, err := sc.ds.GetSettingAsInt(types.SettingNameSafetySnapshotRetentionPeriod)
+	if err != nil {
+		return err
+	}
+	if retentionPeriod == 0 {
+		return nil
+	}
+
+	creationTime, err := time.Parse(time.RFC3339, snapshot.Status.CreationTime)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse creation time %v of snapshot %v", snapshot.Status.CreationTime, snapshot.Name)
+	}
+	key, err := controller.KeyFunc(snapshot)
+	if err != nil {
+		return err
+	}
+	if remaining := time.Until(creationTime.Add(time.Duration(retentionPeriod) * time.Hour)); remaining > 0 {
+		sc.queue.AddAfter(key, remaining)
+		return nil
+	}
+
+	// The backup of the snapshot taken with the snapshot-and-backup policy must complete first
+	backups, err := sc.ds.ListBackupsRO()
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups {
+		if backup.Spec.SnapshotName != snapshot.Name {
+			continue
+		}
+		switch backup.Status.State {
+		case longhorn.BackupStateCompleted, longhorn.BackupStateError, longhorn.BackupStateUnknown, longhorn.BackupStateDeleting:
+		default:
+			sc.logger.Infof("Waiting for backup %v to complete before deleting expired safety snapshot %v", backup.Name, snapshot.Name)
+			sc.queue.AddAfter(key, safetySnapshotBackupWaitInterval)
+			return nil
+		}
+	}
+
+	sc.logger.Infof("Deleting safety snapshot %v of volume %v after its retention period of %v hours", snapshot.Name, snapshot.Spec.Volume, retentionPeriod)
+	return sc.ds.DeleteSnapshot(snapshot.Name)
+}
+
+// handleSnapshotMaxChainLength deletes the oldest user created snapshots in the chain of the volume head exceeding the
+// snapshot max chain length of the volume, so they are coalesced into their children by the snapshot purge. Long
+// chains degrade the read latency of the volume. It is handled when reconciling the parent of the volume head, which
+// is the newest snapshot of the chain.
+func (sc *SnapshotController) handleSnapshotMaxChainLength(snapshot *longhorn.Snapshot) error {
+	if _, ok := snapshot.Status.Children["volume-head"]; !ok || !snapshot.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	volume, err := sc.ds.GetVolumeRO(snapshot.Spec.Volume)
+	if err != nil {
+		return err
+	}
+	if volume.Spec.SnapshotMaxChainLength == 0 {
+		return nil
+	}
+
+	disablePurge, err := sc.ds.GetSettingAsBool(types.SettingNameDisableSnapshotPurge)
+	if err != nil {
+		return err
+	}
+	if disablePurge {
+		return nil
+	}
+
+	isProtected, err := getSnapshotProtectionChecker(sc.ds, sc.clock.Now())
+	if err != nil {
+		return err
+	}
+	snapshots, err := sc.ds.ListVolumeSnapshotsRO(volume.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, snap := range getSnapshotsToCoalesce(snapshots, volume.Spec.SnapshotMaxChainLength, isProtected) {
+		sc.logger.Infof("Deleting snapshot %v of volume %v to coalesce it since the snapshot chain exceeds the max length %v",
+			snap.Name, volume.Name, volume.Spec.SnapshotMaxChainLength)
+		if err := sc.ds.DeleteSnapshot(snap.Name); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
 	return nil
 }
 
+// getSnapshotProtectionChecker returns a function checking if a snapshot cannot be removed automatically: the
+// snapshots of the CSI VolumeSnapshots, the safety snapshots within their retention period at the given time, and the
+// snapshots of the backups in progress
+func getSnapshotProtectionChecker(ds *datastore.DataStore, now time.Time) (func(*longhorn.Snapshot) bool, error) {
+	retentionPeriod, err := ds.GetSettingAsInt(types.SettingNameSafetySnapshotRetentionPeriod)
+	if err != nil {
+		return nil, err
+	}
+	backups, err := ds.ListBackupsRO()
+	if err != nil {
+		return nil, err
+	}
+	backupSnapshots := map[string]bool{}
+	for _, backup := range backups {
+		switch backup.Status.State {
+		case longhorn.BackupStateCompleted, longhorn.BackupStateError, longhorn.BackupStateUnknown, longhorn.BackupStateDeleting:
+		default:
+			backupSnapshots[backup.Spec.SnapshotName] = true
+		}
+	}
+	snapshotHandles, err := ds.ListVolumeSnapshotContentSnapshotHandles()
+	if err != nil {
+		return nil, err
+	}
+	return newSnapshotProtectionChecker(retentionPeriod, backupSnapshots, snapshotHandles, now), nil
+}
+
+// newSnapshotProtectionChecker returns a function checking if a snapshot is protected by a backup in progress or a CSI
+// snapshot handle, or if it's a safety snapshot within the retention period in hours at the given time
+func newSnapshotProtectionChecker(retentionPeriod int64, backupSnapshots, snapshotHandles map[string]bool, now time.Time) func(*longhorn.Snapshot) bool {
+	return func(snapshot *longhorn.Snapshot) bool {
+		if backupSnapshots[snapshot.Name] {
+			return true
+		}
+		if snapshotHandles[string(types.NewVolumeDataSourceTypeSnapshot(snapshot.Spec.Volume, snapshot.Name))] {
+			return true
+		}
+		if !isSafetySnapshot(snapshot) {
+			return false
+		}
+		if retentionPeriod == 0 {
+			return true
+		}
+		creationTime, err := time.Parse(time.RFC3339, snapshot.Status.CreationTime)
+		if err != nil {
+			return true
+		}
+		return now.Sub(creationTime) < time.Duration(retentionPeriod)*time.Hour
+	}
+}
+
+// getSnapshotsToCoalesce returns the oldest user created snapshots in the chain of the volume head exceeding the max
+// chain length. The snapshots already removed are not counted, since they are going to be coalesced by the purge.
+// The parent of the volume head is never returned because it cannot be coalesced.
+func getSnapshotsToCoalesce(snapshots map[string]*longhorn.Snapshot, maxChainLength int, isProtected func(*longhorn.Snapshot) bool) []*longhorn.Snapshot {
+	var head *longhorn.Snapshot
+	for _, snap := range snapshots {
+		if _, ok := snap.Status.Children["volume-head"]; ok {
+			head = snap
+			break
+		}
+	}
+
+	// The chain is ordered from the newest to the oldest snapshot
+	chain := []*longhorn.Snapshot{}
+	visited := map[string]bool{}
+	for snap := head; snap != nil && !visited[snap.Name]; snap = snapshots[snap.Status.Parent] {
+		visited[snap.Name] = true
+		if snap.Status.MarkRemoved || !snap.DeletionTimestamp.IsZero() {
+			continue
+		}
+		chain = append(chain, snap)
+	}
+
+	excess := len(chain) - maxChainLength
+	if maxChainLength == 0 || excess <= 0 {
+		return nil
+	}
+	toCoalesce := []*longhorn.Snapshot{}
+	for i := len(chain) - 1; i > 0 && len(toCoalesce) < excess; i-- {
+		if !chain[i].Status.UserCreated || isProtected(chain[i]) {
+			continue
+		}
+		toCoalesce = append(toCoalesce, chain[i])
+	}
+	return toCoalesce
+}
+
+// handleSnapshotRevert reverts the volume to the snapshot once requested by spec.revertRequested. The volume is
+// attached in maintenance mode for the revert, so the revert stays pending while any workload uses the volume. The
+// attachment is released once the revert is done, so the volume goes back to be detached or used by the workloads.
+func (sc *SnapshotController) handleSnapshotRevert(snapshot *longhorn.Snapshot, engine *longhorn.Engine) error {
+	if !snapshot.Spec.RevertRequested {
+		snapshot.Status.RevertState = longhorn.SnapshotRevertStateNone
+		snapshot.Status.RevertMessage = ""
+		return sc.handleRevertAttachmentTicketDeletion(snapshot)
+	}
+	if snapshot.Status.RevertState == longhorn.SnapshotRevertStateCompleted ||
+		snapshot.Status.RevertState == longhorn.SnapshotRevertStateError {
+		return sc.handleRevertAttachmentTicketDeletion(snapshot)
+	}
+
+	volume, err := sc.ds.GetVolumeRO(snapshot.Spec.Volume)
+	if err != nil {
+		return err
+	}
+	va, err := sc.ds.GetLHVolumeAttachmentByVolumeName(volume.Name)
+	if err != nil {
+		return err
+	}
+	snapshots, err := sc.ds.ListVolumeSnapshotsRO(volume.Name)
+	if err != nil {
+		return err
+	}
+
+	if blocker := getSnapshotRevertBlocker(snapshot, volume, va, snapshots); blocker != "" {
+		snapshot.Status.RevertState = longhorn.SnapshotRevertStatePending
+		snapshot.Status.RevertMessage = blocker
+		return sc.handleRevertAttachmentTicketDeletion(snapshot)
+	}
+
+	snapshot.Status.RevertState = longhorn.SnapshotRevertStateInProgress
+	snapshot.Status.RevertMessage = ""
+
+	attachmentTicketID := longhorn.GetAttachmentTicketID(longhorn.AttacherTypeSnapshotRevertController, snapshot.Name)
+	if _, ok := va.Spec.AttachmentTickets[attachmentTicketID]; !ok {
+		createOrUpdateAttachmentTicket(va, attachmentTicketID, volume.Status.OwnerID, longhorn.TrueValue, longhorn.AttacherTypeSnapshotRevertController)
+		if _, err := sc.ds.UpdateLHVolumeAttachment(va); err != nil {
+			return err
+		}
+	}
+
+	// Wait for the volume to be attached in maintenance mode
+	if volume.Status.State != longhorn.VolumeStateAttached || !volume.Status.FrontendDisabled ||
+		engine.Status.CurrentState != longhorn.InstanceStateRunning {
+		return nil
+	}
+
+	// The revert can never succeed once the snapshot is gone. Other failures, e.g. the engine being unreachable for a
+	// moment, are retried.
+	snapshotInfo, ok := engine.Status.Snapshots[snapshot.Name]
+	if !ok || snapshotInfo.Removed {
+		snapshot.Status.RevertState = longhorn.SnapshotRevertStateError
+		snapshot.Status.RevertMessage = fmt.Sprintf("snapshot %v is not found or marked as removed in engine %v", snapshot.Name, engine.Name)
+		return sc.handleRevertAttachmentTicketDeletion(snapshot)
+	}
+	if err := sc.revertSnapshot(snapshot, engine, volume); err != nil {
+		snapshot.Status.RevertMessage = err.Error()
+		return reconcileError{error: err, shouldUpdateObject: true}
+	}
+	snapshot.Status.RevertState = longhorn.SnapshotRevertStateCompleted
+	return sc.handleRevertAttachmentTicketDeletion(snapshot)
+}
+
+// getSnapshotRevertBlocker returns the reason the volume cannot be reverted to the snapshot yet, or an empty string
+// if the revert is safe. The attachment tickets requiring the frontend belong to the workloads using the volume.
+func getSnapshotRevertBlocker(snapshot *longhorn.Snapshot, volume *longhorn.Volume, va *longhorn.VolumeAttachment,
+	snapshots map[string]*longhorn.Snapshot) string {
+	if !snapshot.Status.ReadyToUse {
+		return fmt.Sprintf("snapshot %v is not ready to use", snapshot.Name)
+	}
+	if volume.Spec.MigrationNodeID != "" {
+		return fmt.Sprintf("volume %v is migrating", volume.Name)
+	}
+	if volume.Status.RestoreRequired {
+		return fmt.Sprintf("volume %v is restoring", volume.Name)
+	}
+	for _, snap := range snapshots {
+		if snap.Name != snapshot.Name && snap.Spec.RevertRequested &&
+			snap.Status.RevertState == longhorn.SnapshotRevertStateInProgress {
+			return fmt.Sprintf("volume %v is being reverted to snapshot %v", volume.Name, snap.Name)
+		}
+	}
+
+	workloadTicketIDs := []string{}
+	for id, ticket := range va.Spec.AttachmentTickets {
+		if ticket.Parameters[longhorn.AttachmentParameterDisableFrontend] == longhorn.FalseValue {
+			workloadTicketIDs = append(workloadTicketIDs, id)
+		}
+	}
+	if len(workloadTicketIDs) != 0 {
+		sort.Strings(workloadTicketIDs)
+		return fmt.Sprintf("volume %v is in use by attachment tickets %v", volume.Name, workloadTicketIDs)
+	}
+	return ""
+}
+
+// revertSnapshot reaches out to engine process to take the safety snapshot of the volume and revert the volume to the
+// snapshot
+func (sc *SnapshotController) revertSnapshot(snapshot *longhorn.Snapshot, engine *longhorn.Engine, volume *longhorn.Volume) error {
+	engineCliClient, err := GetBinaryClientForEngine(engine, sc.engineClientCollection, engine.Status.CurrentImage)
+	if err != nil {
+		return err
+	}
+	engineClientProxy, err := engineapi.GetCompatibleClient(engine, engineCliClient, sc.ds, sc.logger, sc.proxyConnCounter)
+	if err != nil {
+		return err
+	}
+	defer engineClientProxy.Close()
+
+	snapshotInfo, err := engineClientProxy.SnapshotGet(engine, snapshot.Name)
+	if err != nil {
+		return err
+	}
+	if snapshotInfo == nil {
+		return fmt.Errorf("snapshot %v is not found in engine %v", snapshot.Name, engine.Name)
+	}
+	if snapshotInfo.Removed {
+		return fmt.Errorf("snapshot %v has been marked as removed", snapshot.Name)
+	}
+
+	if err := engineapi.TakeSafetySnapshot(sc.ds, engineClientProxy, engine, volume, "snapshot-revert"); err != nil {
+		return err
+	}
+
+	sc.logger.Infof("Reverting volume %v to snapshot %v", snapshot.Spec.Volume, snapshot.Name)
+	return engineClientProxy.SnapshotRevert(engine, snapshot.Name)
+}
+
+// handleRevertAttachmentTicketDeletion releases the volume attached in maintenance mode for the revert
+func (sc *SnapshotController) handleRevertAttachmentTicketDeletion(snap *longhorn.Snapshot) error {
+	return errors.Wrap(sc.deleteAttachmentTicket(snap.Spec.Volume, longhorn.GetAttachmentTicketID(longhorn.AttacherTypeSnapshotRevertController, snap.Name)),
+		"handleRevertAttachmentTicketDeletion: failed to clean up attachment")
+}
+
 // handleAttachmentTicketDeletion check and delete attachment so that the source volume is detached if needed
-func (sc *SnapshotController) handleAttachmentTicketDeletion(snap *longhorn.Snapshot) (err error) {
-	defer func() {
-		err = errors.Wrap(err, "handleAttachmentTicketDeletion: failed to clean up attachment")
-	}()
+func (sc *SnapshotController) handleAttachmentTicketDeletion(snap *longhorn.Snapshot) error {
+	return errors.Wrap(sc.deleteAttachmentTicket(snap.Spec.Volume, longhorn.GetAttachmentTicketID(longhorn.AttacherTypeSnapshotController, snap.Name)),
+		"handleAttachmentTicketDeletion: failed to clean up attachment")
+}
 
-	va, err := sc.ds.GetLHVolumeAttachmentByVolumeName(snap.Spec.Volume)
+func (sc *SnapshotController) deleteAttachmentTicket(volumeName, attachmentTicketID string) error {
+	va, err := sc.ds.GetLHVolumeAttachmentByVolumeName(volumeName)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return nil
@@ -509,8 +976,6 @@ func (sc *SnapshotController) handleAttachmentTicketDeletion(snap *longhorn.Snap
 		return err
 	}
 
-	attachmentTicketID := longhorn.GetAttachmentTicketID(longhorn.AttacherTypeSnapshotController, snap.Name)
-
 	if _, ok := va.Spec.AttachmentTickets[attachmentTicketID]; ok {
 		delete(va.Spec.AttachmentTickets, attachmentTicketID)
 		if _, err = sc.ds.UpdateLHVolumeAttachment(va); err != nil {
@@ -582,6 +1047,16 @@ func (sc *SnapshotController) generatingEventsForSnapshot(existingSnapshot, snap
 			sc.eventRecorder.Eventf(snapshot, corev1.EventTypeWarning, constant.EventReasonFailed, "%v", snapshot.Status.Error)
 		}
 	}
+	if existingSnapshot.Status.RevertState != snapshot.Status.RevertState {
+		switch snapshot.Status.RevertState {
+		case longhorn.SnapshotRevertStateCompleted:
+			sc.eventRecorder.Eventf(snapshot, corev1.EventTypeNormal, constant.EventReasonReverted, "reverted volume %v to the snapshot", snapshot.Spec.Volume)
+		case longhorn.SnapshotRevertStateError:
+			sc.eventRecorder.Eventf(snapshot, corev1.EventTypeWarning, constant.EventReasonFailedRevert, "failed to revert volume %v to the snapshot: %v", snapshot.Spec.Volume, snapshot.Status.RevertMessage)
+		case longhorn.SnapshotRevertStatePending:
+			sc.eventRecorder.Eventf(snapshot, corev1.EventTypeNormal, constant.EventReasonUpdate, "revert of volume %v is pending: %v", snapshot.Spec.Volume, snapshot.Status.RevertMessage)
+		}
+	}
 	if existingSnapshot.Status.ReadyToUse != snapshot.Status.ReadyToUse {
 		if snapshot.Status.ReadyToUse {
 			sc.eventRecorder.Event(snapshot, corev1.EventTypeNormal, constant.EventReasonUpdate, "snapshot becomes ready to use")
@@ -662,6 +1137,19 @@ func (sc *SnapshotController) handleSnapshotCreate(snapshot *longhorn.Snapshot,
 		return err
 	}
 	if snapshotInfo == nil {
+		// The engine cannot freeze the filesystem of a RWX volume, which is frozen in the share manager instead
+		if freezeFilesystem {
+			thaw, err := sc.freezeShareManagerFilesystem(engine)
+			if err != nil {
+				return err
+			}
+			if thaw != nil {
+				freezeFilesystem = false
+				log := sc.logger.WithFields(logrus.Fields{"volume": snapshot.Spec.Volume, "snapshot": snapshot.Name})
+				defer thawWithDeadline(thaw, shareManagerFilesystemFreezeTimeout, log)()
+			}
+		}
+
 		sc.logger.Infof("Creating snapshot %v of volume %v", snapshot.Name, snapshot.Spec.Volume)
 		_, err = engineClientProxy.SnapshotCreate(engine, snapshot.Name, snapshot.Spec.Labels, freezeFilesystem)
 		if err != nil {
@@ -671,6 +1159,83 @@ func (sc *SnapshotController) handleSnapshotCreate(snapshot *longhorn.Snapshot,
 	return nil
 }
 
+// freezeShareManagerFilesystem freezes the filesystem of a RWX volume mounted by its share manager, so the snapshot
+// isn't torn across the in-flight writes of the NFS clients. The filesystem is frozen through the share manager gRPC
+// service, or in the mount namespace of the share manager pod on this node if the share manager doesn't support it.
+// It returns nil if the volume isn't exported by a running share manager.
+func (sc *SnapshotController) freezeShareManagerFilesystem(engine *longhorn.Engine) (func() error, error) {
+	volume, err := sc.ds.GetVolumeRO(engine.Spec.VolumeName)
+	if err != nil {
+		return nil, err
+	}
+	if volume.Spec.AccessMode != longhorn.AccessModeReadWriteMany || volume.Spec.Migratable ||
+		volume.Spec.Frontend != longhorn.VolumeFrontendBlockDev {
+		return nil, nil
+	}
+
+	sm, err := sc.ds.GetShareManager(volume.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if sm.Status.State != longhorn.ShareManagerStateRunning {
+		return nil, nil
+	}
+	pod, err := sc.ds.GetPodRO(sm.Namespace, types.GetShareManagerPodNameFromShareManagerName(sm.Name))
+	if err != nil {
+		return nil, err
+	}
+	if pod == nil {
+		return nil, nil
+	}
+
+	client, err := engineapi.NewShareManagerClient(sm, pod)
+	if err != nil {
+		return nil, err
+	}
+	err = client.FilesystemFreeze()
+	if err == nil {
+		return func() error {
+			defer client.Close()
+			return errors.Wrapf(client.FilesystemUnfreeze(), "failed to thaw filesystem of share manager for volume %v", volume.Name)
+		}, nil
+	}
+	client.Close()
+	if status.Code(err) != codes.Unimplemented {
+		return nil, errors.Wrapf(err, "failed to freeze filesystem of share manager for volume %v", volume.Name)
+	}
+
+	if pod.Spec.NodeName != sc.controllerID {
+		sc.logger.Warnf("Cannot freeze filesystem of volume %v since its share manager doesn't support it and its pod is not running on node %v", volume.Name, sc.controllerID)
+		return nil, nil
+	}
+	return util.FreezeShareManagerFilesystem(volume.Name, volume.Spec.Encrypted)
+}
+
+// thawWithDeadline returns the function thawing the frozen filesystem, which is deferred once the snapshot is
+// created. The filesystem is thawed anyway once the deadline passes, so the writes of the workloads aren't blocked
+// if creating the snapshot hangs. The filesystem is thawed only once.
+func thawWithDeadline(thaw func() error, deadline time.Duration, log logrus.FieldLogger) func() {
+	var once sync.Once
+	thawOnce := func() {
+		once.Do(func() {
+			if err := thaw(); err != nil {
+				log.WithError(err).Error("Failed to thaw filesystem after creating snapshot")
+			}
+		})
+	}
+	timer := time.AfterFunc(deadline, func() {
+		log.Warnf("Thawing filesystem since creating snapshot takes longer than %v", deadline)
+		thawOnce()
+	})
+	return func() {
+		timer.Stop()
+		thawOnce()
+	}
+}
+
 // handleSnapshotDeletion reaches out to engine process to check and delete the snapshot
 func (sc *SnapshotController) handleSnapshotDeletion(snapshot *longhorn.Snapshot, engine *longhorn.Engine) error {
 	engineCliClient, err := GetBinaryClientForEngine(engine, sc.engineClientCollection, engine.Status.CurrentImage)
@@ -710,6 +1275,10 @@ func (sc *SnapshotController) handleSnapshotDeletion(snapshot *longhorn.Snapshot
 		}
 	}
 	if !isPurging {
+		if blockedBy := getBlockingVolumeOperation(engine, longhorn.VolumeOperationTypeSnapshotPurge); blockedBy != "" {
+			sc.logger.Infof("Waiting for %v to complete before starting SnapshotPurge to delete snapshot %v", blockedBy, snapshot.Name)
+			return nil
+		}
 		// We checked DisableSnapshotPurge at a higher level, so we do not need to check it again here.
 		sc.logger.Infof("Starting SnapshotPurge to delete snapshot %v", snapshot.Name)
 		if err := engineClientProxy.SnapshotPurge(engine); err != nil {
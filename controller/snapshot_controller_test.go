@@ -2,7 +2,15 @@ package controller
 
 import (
 	"fmt"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
 
 func TestShouldUpdateObject(t *testing.T) {
@@ -28,3 +36,199 @@ func TestShouldUpdateObject(t *testing.T) {
 		t.Fatal("reconcileErr1 must be non-updatable error")
 	}
 }
+
+func TestGetSnapshotsToCoalesce(t *testing.T) {
+	// snap-1 <- snap-2 <- snap-3 <- snap-4 <- snap-5 <- volume-head
+	newSnapshot := func(name, parent, child string, userCreated bool) *longhorn.Snapshot {
+		snap := &longhorn.Snapshot{}
+		snap.Name = name
+		snap.Status.Parent = parent
+		snap.Status.Children = map[string]bool{child: true}
+		snap.Status.UserCreated = userCreated
+		return snap
+	}
+	snapshots := map[string]*longhorn.Snapshot{
+		"snap-1": newSnapshot("snap-1", "", "snap-2", true),
+		"snap-2": newSnapshot("snap-2", "snap-1", "snap-3", false),
+		"snap-3": newSnapshot("snap-3", "snap-2", "snap-4", true),
+		"snap-4": newSnapshot("snap-4", "snap-3", "snap-5", true),
+		"snap-5": newSnapshot("snap-5", "snap-4", "volume-head", true),
+	}
+	notProtected := func(*longhorn.Snapshot) bool { return false }
+
+	toCoalesce := getSnapshotsToCoalesce(snapshots, 0, notProtected)
+	if len(toCoalesce) != 0 {
+		t.Fatalf("unlimited chain must not be coalesced, got %v snapshots", len(toCoalesce))
+	}
+
+	toCoalesce = getSnapshotsToCoalesce(snapshots, 5, notProtected)
+	if len(toCoalesce) != 0 {
+		t.Fatalf("chain within the max length must not be coalesced, got %v snapshots", len(toCoalesce))
+	}
+
+	// The system snapshot snap-2 is skipped
+	toCoalesce = getSnapshotsToCoalesce(snapshots, 3, notProtected)
+	if len(toCoalesce) != 2 || toCoalesce[0].Name != "snap-1" || toCoalesce[1].Name != "snap-3" {
+		t.Fatalf("the oldest user created snapshots must be coalesced, got %v", toCoalesce)
+	}
+
+	// The protected snapshot snap-1 and the parent of the volume head are kept
+	isProtected := func(snap *longhorn.Snapshot) bool { return snap.Name == "snap-1" }
+	toCoalesce = getSnapshotsToCoalesce(snapshots, 2, isProtected)
+	if len(toCoalesce) != 2 || toCoalesce[0].Name != "snap-3" || toCoalesce[1].Name != "snap-4" {
+		t.Fatalf("the protected snapshots must be kept, got %v", toCoalesce)
+	}
+
+	// The removed snapshots are not counted
+	snapshots["snap-3"].Status.MarkRemoved = true
+	toCoalesce = getSnapshotsToCoalesce(snapshots, 4, notProtected)
+	if len(toCoalesce) != 0 {
+		t.Fatalf("removed snapshots must not be counted, got %v", toCoalesce)
+	}
+}
+
+func TestGetSnapshotRevertBlocker(t *testing.T) {
+	snapshot := &longhorn.Snapshot{}
+	snapshot.Name = "snap-1"
+	snapshot.Spec.RevertRequested = true
+	volume := &longhorn.Volume{}
+	volume.Name = "vol-1"
+	va := &longhorn.VolumeAttachment{}
+	va.Spec.AttachmentTickets = map[string]*longhorn.AttachmentTicket{}
+	snapshots := map[string]*longhorn.Snapshot{snapshot.Name: snapshot}
+
+	if blocker := getSnapshotRevertBlocker(snapshot, volume, va, snapshots); blocker == "" {
+		t.Fatal("the revert must wait for the snapshot to be ready to use")
+	}
+
+	snapshot.Status.ReadyToUse = true
+	if blocker := getSnapshotRevertBlocker(snapshot, volume, va, snapshots); blocker != "" {
+		t.Fatalf("the revert of an unused volume must not be blocked, got %v", blocker)
+	}
+
+	// The tickets of the controllers not requiring the frontend do not block the revert
+	va.Spec.AttachmentTickets["backup-controller-backup-1"] = &longhorn.AttachmentTicket{
+		Type:       longhorn.AttacherTypeBackupController,
+		Parameters: map[string]string{longhorn.AttachmentParameterDisableFrontend: longhorn.AnyValue},
+	}
+	if blocker := getSnapshotRevertBlocker(snapshot, volume, va, snapshots); blocker != "" {
+		t.Fatalf("the revert must not be blocked by a ticket not requiring the frontend, got %v", blocker)
+	}
+
+	va.Spec.AttachmentTickets["csi-attacher-pv-1"] = &longhorn.AttachmentTicket{
+		Type:       longhorn.AttacherTypeCSIAttacher,
+		Parameters: map[string]string{longhorn.AttachmentParameterDisableFrontend: longhorn.FalseValue},
+	}
+	if blocker := getSnapshotRevertBlocker(snapshot, volume, va, snapshots); blocker == "" {
+		t.Fatal("the revert must wait for the workload to stop using the volume")
+	}
+	delete(va.Spec.AttachmentTickets, "csi-attacher-pv-1")
+
+	volume.Spec.MigrationNodeID = "node-2"
+	if blocker := getSnapshotRevertBlocker(snapshot, volume, va, snapshots); blocker == "" {
+		t.Fatal("the revert must wait for the migration to complete")
+	}
+	volume.Spec.MigrationNodeID = ""
+
+	other := &longhorn.Snapshot{}
+	other.Name = "snap-2"
+	other.Spec.RevertRequested = true
+	other.Status.RevertState = longhorn.SnapshotRevertStateInProgress
+	snapshots[other.Name] = other
+	if blocker := getSnapshotRevertBlocker(snapshot, volume, va, snapshots); blocker == "" {
+		t.Fatal("the revert must wait for the revert in progress to another snapshot")
+	}
+}
+
+func TestNewSnapshotProtectionChecker(t *testing.T) {
+	now := time.Now()
+	newSafetySnapshot := func(name string, age time.Duration) *longhorn.Snapshot {
+		snap := &longhorn.Snapshot{}
+		snap.Name = name
+		snap.Spec.Volume = "vol-1"
+		snap.Status.Labels = map[string]string{types.GetLonghornLabelKey(types.LonghornLabelSafetySnapshot): "revert"}
+		snap.Status.CreationTime = now.Add(-age).Format(time.RFC3339)
+		return snap
+	}
+	csiSnapshot := &longhorn.Snapshot{}
+	csiSnapshot.Name = "snapshot-1"
+	csiSnapshot.Spec.Volume = "vol-1"
+	backupSnapshot := &longhorn.Snapshot{}
+	backupSnapshot.Name = "snap-backup"
+	backupSnapshot.Spec.Volume = "vol-1"
+	userSnapshot := &longhorn.Snapshot{}
+	userSnapshot.Name = "snap-user"
+	userSnapshot.Spec.Volume = "vol-1"
+
+	backupSnapshots := map[string]bool{"snap-backup": true}
+	snapshotHandles := map[string]bool{"snap://vol-1/snapshot-1": true, "bak://vol-1/backup-1": true}
+	isProtected := newSnapshotProtectionChecker(24, backupSnapshots, snapshotHandles, now)
+	if !isProtected(csiSnapshot) {
+		t.Fatal("the snapshot of a VolumeSnapshotContent must be protected")
+	}
+	if !isProtected(backupSnapshot) {
+		t.Fatal("the snapshot of a backup in progress must be protected")
+	}
+	if isProtected(userSnapshot) {
+		t.Fatal("the user created snapshot must not be protected")
+	}
+	if !isProtected(newSafetySnapshot("safety-1", 23*time.Hour)) {
+		t.Fatal("the safety snapshot within the retention period must be protected")
+	}
+	if isProtected(newSafetySnapshot("safety-2", 25*time.Hour)) {
+		t.Fatal("the safety snapshot beyond the retention period must not be protected")
+	}
+
+	// The age of the safety snapshots is evaluated at the time of the controller clock
+	isProtected = newSnapshotProtectionChecker(24, nil, nil, now.Add(-2*time.Hour))
+	if !isProtected(newSafetySnapshot("safety-2", 25*time.Hour)) {
+		t.Fatal("the safety snapshot age must be evaluated at the given time")
+	}
+
+	// The snapshot of another volume with the same name is not referenced by the VolumeSnapshotContent
+	isProtected = newSnapshotProtectionChecker(24, backupSnapshots, snapshotHandles, now)
+	csiSnapshot.Spec.Volume = "vol-2"
+	if isProtected(csiSnapshot) {
+		t.Fatal("the snapshot of another volume must not be protected")
+	}
+}
+
+func TestThawWithDeadline(t *testing.T) {
+	var lock sync.Mutex
+	thawed := 0
+	thaw := func() error {
+		lock.Lock()
+		defer lock.Unlock()
+		thawed++
+		return nil
+	}
+	getThawed := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return thawed
+	}
+
+	stop := thawWithDeadline(thaw, time.Hour, logrus.StandardLogger())
+	if getThawed() != 0 {
+		t.Fatal("the filesystem must not be thawed before the snapshot is created or the deadline passes")
+	}
+	stop()
+	stop()
+	if getThawed() != 1 {
+		t.Fatalf("the filesystem must be thawed once after the snapshot is created, but thawed %v times", getThawed())
+	}
+
+	thawed = 0
+	stop = thawWithDeadline(thaw, 10*time.Millisecond, logrus.StandardLogger())
+	deadline := time.Now().Add(10 * time.Second)
+	for getThawed() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if getThawed() != 1 {
+		t.Fatal("the filesystem must be thawed once the deadline passes")
+	}
+	stop()
+	if getThawed() != 1 {
+		t.Fatalf("the filesystem thawed by the deadline must not be thawed again, but thawed %v times", getThawed())
+	}
+}
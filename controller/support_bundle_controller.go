@@ -402,7 +402,7 @@ func (c *SupportBundleController) reconcile(name string) (err error) {
 
 		message := fmt.Sprintf(longhorn.SupportBundleMsgGeneratedFmt,
 			supportBundle.Status.Filename,
-			fmt.Sprintf(types.SupportBundleURLDownloadFmt, supportBundleManager.podIP, types.SupportBundleURLPort),
+			fmt.Sprintf(types.SupportBundleURLDownloadFmt, types.GetSupportBundleManagerAddress(supportBundleManager.podIP)),
 		)
 		err = c.updateSupportBundleRecord(record,
 			supportBundleRecordNormal, longhorn.SupportBundleStateReady,
@@ -529,7 +529,7 @@ func (c *SupportBundleController) getSupportBundleManager(supportBundle *longhor
 		return nil, err
 	}
 
-	url := fmt.Sprintf(types.SupportBundleURLStatusFmt, supportBundleManager.podIP, types.SupportBundleURLPort)
+	url := fmt.Sprintf(types.SupportBundleURLStatusFmt, types.GetSupportBundleManagerAddress(supportBundleManager.podIP))
 	status, err := c.getSupportBundleStatusFromManager(url)
 	if err != nil {
 		return nil, err
@@ -1186,12 +1186,14 @@ func (c *SystemBackupController) generateSystemBackupYAMLsForLonghorn(dir string
 
 	// TODO: handle BackingImage in https://github.com/longhorn/longhorn/issues/4165
 	resourceGetFns := map[string]func() (runtime.Object, error){
-		"setting":       c.ds.GetAllLonghornSettings,
-		"engineimages":  c.ds.GetAllLonghornEngineImages,
-		"volumes":       c.ds.GetAllLonghornVolumes,
-		"recurringjobs": c.ds.GetAllLonghornRecurringJobs,
-		"backingimages": c.ds.GetAllLonghornBackingImages,
-		"backuptargets": c.ds.GetAllLonghornBackupTargets,
+		"setting":               c.ds.GetAllLonghornSettings,
+		"engineimages":          c.ds.GetAllLonghornEngineImages,
+		"volumes":               c.ds.GetAllLonghornVolumes,
+		"recurringjobs":         c.ds.GetAllLonghornRecurringJobs,
+		"maintenancepolicies":   c.ds.GetAllLonghornMaintenancePolicies,
+		"engineupgradepolicies": c.ds.GetAllLonghornEngineUpgradePolicies,
+		"backingimages":         c.ds.GetAllLonghornBackingImages,
+		"backuptargets":         c.ds.GetAllLonghornBackupTargets,
 	}
 
 	for name, fn := range resourceGetFns {
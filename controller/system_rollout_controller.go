@@ -44,6 +44,7 @@ import (
 	"github.com/longhorn/longhorn-manager/constant"
 	"github.com/longhorn/longhorn-manager/datastore"
 	"github.com/longhorn/longhorn-manager/engineapi"
+	"github.com/longhorn/longhorn-manager/scheduler"
 	"github.com/longhorn/longhorn-manager/types"
 	"github.com/longhorn/longhorn-manager/util"
 
@@ -57,6 +58,7 @@ const (
 	SystemRolloutErrFailedConvertToObjectFmt = "failed converting %v to %v object"
 	SystemRolloutErrFailedToCreateFmt        = "failed to create item: %v %v"
 	SystemRolloutErrMissingDependencyFmt     = "cannot rollout %v due to missing dependency: %v %v"
+	SystemRolloutErrUnschedulableFmt         = "cannot schedule the replicas of volume %v: %v"
 
 	SystemRolloutMsgDownloadedFmt       = "Downloaded from %v"
 	SystemRolloutMsgIdentical           = "identical"
@@ -113,12 +115,14 @@ type extractedResources struct {
 
 	storageClassList *storagev1.StorageClassList
 
-	engineImageList  *longhorn.EngineImageList
-	recurringJobList *longhorn.RecurringJobList
-	settingList      *longhorn.SettingList
-	volumeList       *longhorn.VolumeList
-	backingImageList *longhorn.BackingImageList
-	backupTargetList *longhorn.BackupTargetList
+	engineImageList         *longhorn.EngineImageList
+	recurringJobList        *longhorn.RecurringJobList
+	maintenancePolicyList   *longhorn.MaintenancePolicyList
+	engineUpgradePolicyList *longhorn.EngineUpgradePolicyList
+	settingList             *longhorn.SettingList
+	volumeList              *longhorn.VolumeList
+	backingImageList        *longhorn.BackingImageList
+	backupTargetList        *longhorn.BackupTargetList
 }
 
 type SystemRolloutController struct {
@@ -131,7 +135,8 @@ type SystemRolloutController struct {
 
 	eventRecorder record.EventRecorder
 
-	ds *datastore.DataStore
+	ds        *datastore.DataStore
+	scheduler *scheduler.ReplicaScheduler
 
 	backupTargetClient     engineapi.SystemBackupOperationInterface
 	backupTargetURL        string
@@ -184,6 +189,7 @@ func NewSystemRolloutController(
 		kubeClient: kubeClient,
 
 		ds:            ds,
+		scheduler:     scheduler.NewReplicaScheduler(ds),
 		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: SystemRolloutControllerName + "-controller"}),
 
 		systemRestoreName: systemRestoreName,
@@ -449,6 +455,8 @@ func (c *SystemRolloutController) systemRollout() error {
 			types.LonghornKindBackupTargetList:         c.restoreBackupTargets,
 			types.LonghornKindBackingImageList:         c.restoreBackingIamges,
 			types.LonghornKindRecurringJobList:         c.restoreRecurringJobs,
+			types.LonghornKindMaintenancePolicyList:    c.restoreMaintenancePolicies,
+			types.LonghornKindEngineUpgradePolicyList:  c.restoreEngineUpgradePolicies,
 		}
 		wg.Add(len(restoreFns))
 		for k, v := range restoreFns {
@@ -692,6 +700,10 @@ func (c *SystemRolloutController) cacheResourcesFromDirectory(name string, schem
 			c.engineImageList = obj.(*longhorn.EngineImageList)
 		case types.LonghornKindRecurringJobList:
 			c.recurringJobList = obj.(*longhorn.RecurringJobList)
+		case types.LonghornKindMaintenancePolicyList:
+			c.maintenancePolicyList = obj.(*longhorn.MaintenancePolicyList)
+		case types.LonghornKindEngineUpgradePolicyList:
+			c.engineUpgradePolicyList = obj.(*longhorn.EngineUpgradePolicyList)
 		case types.LonghornKindSettingList:
 			c.settingList = obj.(*longhorn.SettingList)
 		case types.LonghornKindVolumeList:
@@ -1455,6 +1467,118 @@ func (c *SystemRolloutController) restoreRecurringJobs() (err error) {
 	return nil
 }
 
+func (c *SystemRolloutController) restoreMaintenancePolicies() (err error) {
+	if c.maintenancePolicyList == nil {
+		return nil
+	}
+
+	for _, restore := range c.maintenancePolicyList.Items {
+		log := c.logger.WithField(types.LonghornKindMaintenancePolicy, restore.Name)
+
+		existRO, err := c.ds.GetMaintenancePolicyRO(restore.Name)
+		if err != nil {
+			if !datastore.ErrorIsNotFound(err) {
+				return err
+			}
+
+			restore.ResourceVersion = ""
+
+			log.Info(SystemRolloutMsgCreating)
+
+			fnCreate := func(restore runtime.Object) (runtime.Object, error) {
+				obj, ok := restore.(*longhorn.MaintenancePolicy)
+				if !ok {
+					return nil, fmt.Errorf(SystemRolloutErrFailedConvertToObjectFmt, restore.GetObjectKind(), types.LonghornKindMaintenancePolicy)
+				}
+				return c.ds.CreateMaintenancePolicy(obj)
+			}
+			_, err := c.rolloutResource(&restore, fnCreate, false, log, SystemRolloutMsgRestoredItem)
+			if err != nil && !apierrors.IsAlreadyExists(err) {
+				return err
+			}
+			continue
+		}
+
+		exist := existRO.DeepCopy()
+		isSkipped := true
+		if !reflect.DeepEqual(exist.Spec, restore.Spec) {
+			log.Info(SystemRolloutMsgUpdating)
+			exist.Spec = restore.Spec
+
+			isSkipped = false
+		}
+		fnUpdate := func(exist runtime.Object) (runtime.Object, error) {
+			obj, ok := exist.(*longhorn.MaintenancePolicy)
+			if !ok {
+				return nil, fmt.Errorf(SystemRolloutErrFailedConvertToObjectFmt, exist.GetObjectKind(), types.LonghornKindMaintenancePolicy)
+			}
+			return c.ds.UpdateMaintenancePolicy(obj)
+		}
+		_, err = c.rolloutResource(exist, fnUpdate, isSkipped, log, SystemRolloutMsgSkipIdentical)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *SystemRolloutController) restoreEngineUpgradePolicies() (err error) {
+	if c.engineUpgradePolicyList == nil {
+		return nil
+	}
+
+	for _, restore := range c.engineUpgradePolicyList.Items {
+		log := c.logger.WithField(types.LonghornKindEngineUpgradePolicy, restore.Name)
+
+		existRO, err := c.ds.GetEngineUpgradePolicyRO(restore.Name)
+		if err != nil {
+			if !datastore.ErrorIsNotFound(err) {
+				return err
+			}
+
+			restore.ResourceVersion = ""
+
+			log.Info(SystemRolloutMsgCreating)
+
+			fnCreate := func(restore runtime.Object) (runtime.Object, error) {
+				obj, ok := restore.(*longhorn.EngineUpgradePolicy)
+				if !ok {
+					return nil, fmt.Errorf(SystemRolloutErrFailedConvertToObjectFmt, restore.GetObjectKind(), types.LonghornKindEngineUpgradePolicy)
+				}
+				return c.ds.CreateEngineUpgradePolicy(obj)
+			}
+			_, err := c.rolloutResource(&restore, fnCreate, false, log, SystemRolloutMsgRestoredItem)
+			if err != nil && !apierrors.IsAlreadyExists(err) {
+				return err
+			}
+			continue
+		}
+
+		exist := existRO.DeepCopy()
+		isSkipped := true
+		if !reflect.DeepEqual(exist.Spec, restore.Spec) {
+			log.Info(SystemRolloutMsgUpdating)
+			exist.Spec = restore.Spec
+
+			isSkipped = false
+		}
+		fnUpdate := func(exist runtime.Object) (runtime.Object, error) {
+			obj, ok := exist.(*longhorn.EngineUpgradePolicy)
+			if !ok {
+				return nil, fmt.Errorf(SystemRolloutErrFailedConvertToObjectFmt, exist.GetObjectKind(), types.LonghornKindEngineUpgradePolicy)
+			}
+			return c.ds.UpdateEngineUpgradePolicy(obj)
+		}
+		_, err = c.rolloutResource(exist, fnUpdate, isSkipped, log, SystemRolloutMsgSkipIdentical)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (c *SystemRolloutController) restoreRoles() (err error) {
 	if c.roleList == nil {
 		return nil
@@ -1953,6 +2077,11 @@ func (c *SystemRolloutController) restoreVolumes() (err error) {
 		return nil
 	}
 
+	placementErrors, err := c.checkVolumesReplicaPlacement()
+	if err != nil {
+		return err
+	}
+
 	for _, restore := range c.volumeList.Items {
 		log := c.logger.WithField(types.LonghornKindVolume, restore.Name)
 		log = getLoggerForVolume(log, &restore)
@@ -1967,6 +2096,28 @@ func (c *SystemRolloutController) restoreVolumes() (err error) {
 				return err
 			}
 
+			if multiError, unschedulable := placementErrors[restore.Name]; unschedulable {
+				err := fmt.Errorf(SystemRolloutErrUnschedulableFmt, restore.Name, multiError.Join())
+				message := util.CapitalizeFirstLetter(err.Error())
+				log.Warn(message)
+
+				reason := fmt.Sprintf(constant.EventReasonFailedCreatingFmt, types.LonghornKindVolume, restore.Name)
+				c.eventRecorder.Event(c.systemRestore, corev1.EventTypeWarning, reason, message)
+
+				// The volume is skipped rather than restored into an unschedulable volume, and reported in the error
+				// of the system restore
+				c.cacheErrors.Append(util.NewMultiError(err.Error()))
+
+				if err = c.ignorePersistenVolumeDueToMissingVolume(&restore); err != nil {
+					return err
+				}
+
+				if err = c.ignorePersistenVolumeClaimDueToMissingVolume(&restore); err != nil {
+					return err
+				}
+				continue
+			}
+
 			restore.ResourceVersion = ""
 			restore.Spec.NodeID = ""
 
@@ -2024,6 +2175,43 @@ func (c *SystemRolloutController) restoreVolumes() (err error) {
 	return nil
 }
 
+// checkVolumesReplicaPlacement returns the scheduling failures of the volumes to be created whose replicas cannot be
+// scheduled, if the replica placement check is enabled
+func (c *SystemRolloutController) checkVolumesReplicaPlacement() (map[string]util.MultiError, error) {
+	enabled, err := c.ds.GetSettingAsBool(types.SettingNameRestoreReplicaPlacementCheck)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	volumes := []*longhorn.Volume{}
+	for i := range c.volumeList.Items {
+		restore := &c.volumeList.Items[i]
+		_, err := c.ds.GetVolumeRO(restore.Name)
+		if err == nil {
+			continue
+		}
+		if !datastore.ErrorIsNotFound(err) {
+			return nil, err
+		}
+		volumes = append(volumes, restore)
+	}
+	if len(volumes) == 0 {
+		return nil, nil
+	}
+
+	placementErrors, err := c.scheduler.CheckReplicaPlacement(volumes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check replica placement")
+	}
+	if len(placementErrors) > 0 {
+		c.logger.Warnf("Cannot schedule the replicas of %v", scheduler.FormatReplicaPlacementErrors(placementErrors))
+	}
+	return placementErrors, nil
+}
+
 func (c *SystemRolloutController) ignorePersistenVolumeDueToMissingVolume(volume *longhorn.Volume) error {
 	newPersistentVolumeListItems := []corev1.PersistentVolume{}
 	for _, persistenVolume := range c.persistentVolumeList.Items {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -46,6 +47,8 @@ const (
 	CRDRecurringJobName           = "recurringjobs.longhorn.io"
 	CRDOrphanName                 = "orphans.longhorn.io"
 	CRDSnapshotName               = "snapshots.longhorn.io"
+	CRDMaintenancePolicyName      = "maintenancepolicies.longhorn.io"
+	CRDEngineUpgradePolicyName    = "engineupgradepolicies.longhorn.io"
 
 	EnvLonghornNamespace = "LONGHORN_NAMESPACE"
 )
@@ -191,6 +194,18 @@ func NewUninstallController(
 		}
 		cacheSyncs = append(cacheSyncs, ds.RecurringJobInformer.HasSynced)
 	}
+	if _, err := extensionsClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), CRDMaintenancePolicyName, metav1.GetOptions{}); err == nil {
+		if _, err = ds.MaintenancePolicyInformer.AddEventHandler(c.controlleeHandler()); err != nil {
+			return nil, err
+		}
+		cacheSyncs = append(cacheSyncs, ds.MaintenancePolicyInformer.HasSynced)
+	}
+	if _, err := extensionsClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), CRDEngineUpgradePolicyName, metav1.GetOptions{}); err == nil {
+		if _, err = ds.EngineUpgradePolicyInformer.AddEventHandler(c.controlleeHandler()); err != nil {
+			return nil, err
+		}
+		cacheSyncs = append(cacheSyncs, ds.EngineUpgradePolicyInformer.HasSynced)
+	}
 	if _, err := extensionsClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), CRDOrphanName, metav1.GetOptions{}); err == nil {
 		if _, err = ds.OrphanInformer.AddEventHandler(c.controlleeHandler()); err != nil {
 			return nil, err
@@ -601,6 +616,20 @@ func (c *UninstallController) deleteCRs() (bool, error) {
 		return true, c.deleteRecurringJobs(recurringJobs)
 	}
 
+	if maintenancePolicies, err := c.ds.ListMaintenancePoliciesRO(); err != nil {
+		return true, err
+	} else if len(maintenancePolicies) > 0 {
+		c.logger.Infof("Found %d maintenance policies remaining", len(maintenancePolicies))
+		return true, c.deleteMaintenancePolicies(maintenancePolicies)
+	}
+
+	if engineUpgradePolicies, err := c.ds.ListEngineUpgradePoliciesRO(); err != nil {
+		return true, err
+	} else if len(engineUpgradePolicies) > 0 {
+		c.logger.Infof("Found %d engine upgrade policies remaining", len(engineUpgradePolicies))
+		return true, c.deleteEngineUpgradePolicies(engineUpgradePolicies)
+	}
+
 	if nodes, err := c.ds.ListNodes(); err != nil {
 		return true, err
 	} else if len(nodes) > 0 {
@@ -636,7 +665,15 @@ func (c *UninstallController) deleteVolumes(vols map[string]*longhorn.Volume) (e
 	defer func() {
 		err = errors.Wrapf(err, "failed to delete volumes")
 	}()
+	// The source volume of an active cloning cannot be deleted until the target volume is deleted
+	sortedVols := make([]*longhorn.Volume, 0, len(vols))
 	for _, vol := range vols {
+		sortedVols = append(sortedVols, vol)
+	}
+	sort.SliceStable(sortedVols, func(i, j int) bool {
+		return isTargetVolumeOfAnActiveCloning(sortedVols[i]) && !isTargetVolumeOfAnActiveCloning(sortedVols[j])
+	})
+	for _, vol := range sortedVols {
 		log := getLoggerForVolume(c.logger, vol)
 
 		timeout := metav1.NewTime(time.Now().Add(-gracePeriod))
@@ -1129,6 +1166,50 @@ func (c *UninstallController) deleteRecurringJobs(recurringJobs map[string]*long
 	return nil
 }
 
+func (c *UninstallController) deleteMaintenancePolicies(maintenancePolicies map[string]*longhorn.MaintenancePolicy) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "failed to delete maintenance policies")
+	}()
+	for _, policy := range maintenancePolicies {
+		log := c.logger.WithField("maintenancePolicy", policy.Name)
+		if policy.DeletionTimestamp == nil {
+			if errDelete := c.ds.DeleteMaintenancePolicy(policy.Name); errDelete != nil {
+				if datastore.ErrorIsNotFound(errDelete) {
+					log.Info("MaintenancePolicy is not found")
+				} else {
+					err = errors.Wrap(errDelete, "failed to mark for deletion")
+					return
+				}
+			} else {
+				log.Info("Marked for deletion")
+			}
+		}
+	}
+	return nil
+}
+
+func (c *UninstallController) deleteEngineUpgradePolicies(engineUpgradePolicies map[string]*longhorn.EngineUpgradePolicy) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "failed to delete engine upgrade policies")
+	}()
+	for _, policy := range engineUpgradePolicies {
+		log := c.logger.WithField("engineUpgradePolicy", policy.Name)
+		if policy.DeletionTimestamp == nil {
+			if errDelete := c.ds.DeleteEngineUpgradePolicy(policy.Name); errDelete != nil {
+				if datastore.ErrorIsNotFound(errDelete) {
+					log.Info("EngineUpgradePolicy is not found")
+				} else {
+					err = errors.Wrap(errDelete, "failed to mark for deletion")
+					return
+				}
+			} else {
+				log.Info("Marked for deletion")
+			}
+		}
+	}
+	return nil
+}
+
 func (c *UninstallController) deleteOrphans(orphans map[string]*longhorn.Orphan) (err error) {
 	defer func() {
 		err = errors.Wrapf(err, "failed to delete orphans")
@@ -0,0 +1,277 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	// The CRD stored versions aren't watched, so they are rechecked periodically
+	upgradeCompatibilityCheckInterval = 5 * time.Minute
+)
+
+// UpgradeCompatibilityController validates that the cluster can be upgraded before the manager daemonset rolls. It
+// checks the engine images in use and the stored versions of the Longhorn CRDs, and records the result as the
+// UpgradeCompatible condition of the current-longhorn-version setting. The upgrade of the new manager version refuses
+// to start while the condition is False, so the cluster isn't left half upgraded. The setting values are validated by
+// the new manager version itself before the upgrade, since only it knows the rules of the target version.
+type UpgradeCompatibilityController struct {
+	*baseController
+
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the controller
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds         *datastore.DataStore
+	cacheSyncs []cache.InformerSynced
+}
+
+func NewUpgradeCompatibilityController(
+	logger logrus.FieldLogger,
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	kubeClient clientset.Interface,
+	controllerID string,
+	namespace string,
+) (*UpgradeCompatibilityController, error) {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	ucc := &UpgradeCompatibilityController{
+		baseController: newBaseController("longhorn-upgrade-compatibility", logger),
+
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		ds: ds,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-upgrade-compatibility-controller"}),
+	}
+
+	var err error
+	if _, err = ds.EngineImageInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ucc.enqueueUpgradeCompatibilityCheck,
+		UpdateFunc: func(old, cur interface{}) { ucc.enqueueUpgradeCompatibilityCheck(cur) },
+		DeleteFunc: ucc.enqueueUpgradeCompatibilityCheck,
+	}); err != nil {
+		return nil, err
+	}
+	ucc.cacheSyncs = append(ucc.cacheSyncs, ds.EngineImageInformer.HasSynced)
+
+	if _, err = ds.SettingInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ucc.enqueueUpgradeCompatibilityCheck,
+		UpdateFunc: func(old, cur interface{}) { ucc.enqueueUpgradeCompatibilityCheck(cur) },
+	}); err != nil {
+		return nil, err
+	}
+	ucc.cacheSyncs = append(ucc.cacheSyncs, ds.SettingInformer.HasSynced)
+
+	if _, err = ds.NodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ucc.enqueueUpgradeCompatibilityCheck,
+		UpdateFunc: func(old, cur interface{}) { ucc.enqueueUpgradeCompatibilityCheck(cur) },
+		DeleteFunc: ucc.enqueueUpgradeCompatibilityCheck,
+	}); err != nil {
+		return nil, err
+	}
+	ucc.cacheSyncs = append(ucc.cacheSyncs, ds.NodeInformer.HasSynced)
+
+	return ucc, nil
+}
+
+// enqueueUpgradeCompatibilityCheck enqueues the single key of the controller, since every check result is written
+// to the same setting.
+func (ucc *UpgradeCompatibilityController) enqueueUpgradeCompatibilityCheck(obj interface{}) {
+	ucc.queue.Add(ucc.namespace + "/" + string(types.SettingNameCurrentLonghornVersion))
+}
+
+func (ucc *UpgradeCompatibilityController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer ucc.queue.ShutDown()
+
+	ucc.logger.Info("Starting Longhorn upgrade compatibility controller")
+	defer ucc.logger.Info("Shut down Longhorn upgrade compatibility controller")
+
+	if !cache.WaitForNamedCacheSync(ucc.name, stopCh, ucc.cacheSyncs...) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(ucc.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (ucc *UpgradeCompatibilityController) worker() {
+	for ucc.processNextWorkItem() {
+	}
+}
+
+func (ucc *UpgradeCompatibilityController) processNextWorkItem() bool {
+	key, quit := ucc.queue.Get()
+	if quit {
+		return false
+	}
+	defer ucc.queue.Done(key)
+	err := ucc.syncHandler(key.(string))
+	ucc.handleErr(err, key)
+	return true
+}
+
+func (ucc *UpgradeCompatibilityController) handleErr(err error, key interface{}) {
+	if err == nil {
+		ucc.queue.Forget(key)
+		return
+	}
+
+	log := ucc.logger.WithField("Setting", key)
+	handleReconcileErrorLogging(log, err, "Failed to check Longhorn upgrade compatibility")
+	ucc.queue.AddRateLimited(key)
+}
+
+func (ucc *UpgradeCompatibilityController) syncHandler(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "%v: failed to check upgrade compatibility", ucc.name)
+	}()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != ucc.namespace {
+		return nil
+	}
+
+	ucc.queue.AddAfter(key, upgradeCompatibilityCheckInterval)
+
+	// Only one manager records the result to avoid fighting over the setting status
+	responsibleNodeID, err := getResponsibleNodeID(ucc.ds)
+	if err != nil {
+		return err
+	}
+	if responsibleNodeID != ucc.controllerID {
+		return nil
+	}
+
+	return ucc.reconcile(name)
+}
+
+func (ucc *UpgradeCompatibilityController) reconcile(name string) error {
+	setting, err := ucc.ds.GetSettingExact(types.SettingName(name))
+	if err != nil {
+		if datastore.ErrorIsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	existingSetting := setting.DeepCopy()
+
+	status := longhorn.ConditionStatusTrue
+	reason := ""
+	eventType := corev1.EventTypeNormal
+	messages := []string{}
+	for _, check := range []struct {
+		reason string
+		fn     func() ([]string, error)
+	}{
+		{longhorn.SettingConditionReasonIncompatibleEngineImage, ucc.checkEngineImages},
+		{longhorn.SettingConditionReasonStaleCRDStoredVersion, ucc.checkCRDStoredVersions},
+	} {
+		problems, err := check.fn()
+		if err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			continue
+		}
+		if status == longhorn.ConditionStatusTrue {
+			status = longhorn.ConditionStatusFalse
+			reason = check.reason
+			eventType = corev1.EventTypeWarning
+		}
+		messages = append(messages, problems...)
+	}
+
+	setting.Status.Conditions = types.SetConditionAndRecord(setting.Status.Conditions, longhorn.SettingConditionTypeUpgradeCompatible,
+		status, reason, strings.Join(messages, "; "), ucc.eventRecorder, setting, eventType)
+
+	if reflect.DeepEqual(existingSetting.Status, setting.Status) {
+		return nil
+	}
+	_, err = ucc.ds.UpdateSettingStatus(setting)
+	return err
+}
+
+// checkEngineImages returns the engine images that are still in use but incompatible with the current manager.
+func (ucc *UpgradeCompatibilityController) checkEngineImages() ([]string, error) {
+	engineImages, err := ucc.ds.ListEngineImages()
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []string{}
+	for _, ei := range engineImages {
+		if ei.Status.Incompatible && ei.Status.RefCount > 0 {
+			problems = append(problems, fmt.Sprintf("engine image %v (%v) is incompatible but still used by %v resources", ei.Name, ei.Spec.Image, ei.Status.RefCount))
+		}
+	}
+	sort.Strings(problems)
+	return problems, nil
+}
+
+// checkCRDStoredVersions returns the Longhorn CRDs that still have objects stored in a version no longer served. The
+// CRDs of the upgrade version are applied before the manager daemonset rolls, so the served versions are the ones the
+// upgrade version still supports. The objects stored in any other version cannot be read after the upgrade.
+func (ucc *UpgradeCompatibilityController) checkCRDStoredVersions() ([]string, error) {
+	obj, err := ucc.ds.GetAllLonghornCustomResourceDefinitions()
+	if err != nil {
+		return nil, err
+	}
+	crdList, ok := obj.(*apiextensionsv1.CustomResourceDefinitionList)
+	if !ok {
+		return nil, fmt.Errorf("BUG: invalid object type %T for the custom resource definition list", obj)
+	}
+
+	problems := []string{}
+	for _, crd := range crdList.Items {
+		servedVersions := map[string]bool{}
+		for _, version := range crd.Spec.Versions {
+			servedVersions[version.Name] = version.Served
+		}
+		for _, version := range crd.Status.StoredVersions {
+			if !servedVersions[version] {
+				problems = append(problems, fmt.Sprintf("CRD %v has objects stored in version %v which is not served anymore", crd.Name, version))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems, nil
+}
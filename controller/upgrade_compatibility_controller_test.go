@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhornapis "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn"
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+
+	. "gopkg.in/check.v1"
+)
+
+func newTestUpgradeCompatibilityController(lhClient *lhfake.Clientset, kubeClient *fake.Clientset, extensionsClient *apiextensionsfake.Clientset,
+	informerFactories *util.InformerFactories, controllerID string) (*UpgradeCompatibilityController, error) {
+	ds := datastore.NewDataStore(TestNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+
+	logger := logrus.StandardLogger()
+	ucc, err := NewUpgradeCompatibilityController(logger, ds, scheme.Scheme, kubeClient, controllerID, TestNamespace)
+	if err != nil {
+		return nil, err
+	}
+	ucc.eventRecorder = record.NewFakeRecorder(100)
+	for index := range ucc.cacheSyncs {
+		ucc.cacheSyncs[index] = alwaysReady
+	}
+	return ucc, nil
+}
+
+func (s *TestSuite) TestUpgradeCompatibility(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+	sIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Settings().Informer().GetIndexer()
+	eiIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().EngineImages().Informer().GetIndexer()
+
+	ucc, err := newTestUpgradeCompatibilityController(lhClient, kubeClient, extensionsClient, informerFactories, TestNode1)
+	c.Assert(err, IsNil)
+
+	setting := newSetting(string(types.SettingNameCurrentLonghornVersion), "v1.8.0")
+	setting, err = lhClient.LonghornV1beta2().Settings(TestNamespace).Create(context.TODO(), setting, metav1.CreateOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(sIndexer.Add(setting), IsNil)
+
+	reconcile := func() longhorn.Condition {
+		c.Assert(ucc.reconcile(setting.Name), IsNil)
+		setting, err := lhClient.LonghornV1beta2().Settings(TestNamespace).Get(context.TODO(), setting.Name, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(sIndexer.Update(setting), IsNil)
+		return types.GetCondition(setting.Status.Conditions, longhorn.SettingConditionTypeUpgradeCompatible)
+	}
+
+	// The cluster is compatible for the upgrade
+	condition := reconcile()
+	c.Assert(condition.Status, Equals, longhorn.ConditionStatusTrue)
+
+	// An incompatible engine image in use blocks the upgrade
+	ei := newEngineImage(TestEngineImage, longhorn.EngineImageStateDeployed)
+	ei.Status.Incompatible = true
+	ei.Status.RefCount = 1
+	c.Assert(eiIndexer.Add(ei), IsNil)
+	condition = reconcile()
+	c.Assert(condition.Status, Equals, longhorn.ConditionStatusFalse)
+	c.Assert(condition.Reason, Equals, longhorn.SettingConditionReasonIncompatibleEngineImage)
+
+	// An invalid setting value is left to the upgrade version to validate
+	invalidSetting := newSetting(string(types.SettingNameOrphanBackingImageAutoDeletion), "invalid")
+	c.Assert(sIndexer.Add(invalidSetting), IsNil)
+
+	// A CRD with objects stored in a version no longer served blocks the upgrade, and is reported after the engine image
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "volumes." + longhornapis.GroupName},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: longhornapis.GroupName,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1beta1", Served: false},
+				{Name: "v1beta2", Served: true, Storage: true},
+			},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: []string{"v1beta1", "v1beta2"}},
+	}
+	crd, err = extensionsClient.ApiextensionsV1().CustomResourceDefinitions().Create(context.TODO(), crd, metav1.CreateOptions{})
+	c.Assert(err, IsNil)
+	condition = reconcile()
+	c.Assert(condition.Status, Equals, longhorn.ConditionStatusFalse)
+	c.Assert(condition.Reason, Equals, longhorn.SettingConditionReasonIncompatibleEngineImage)
+	c.Assert(condition.Message, Matches, ".*CRD volumes.longhorn.io has objects stored in version v1beta1.*")
+
+	ei.Status.RefCount = 0
+	c.Assert(eiIndexer.Update(ei), IsNil)
+	condition = reconcile()
+	c.Assert(condition.Status, Equals, longhorn.ConditionStatusFalse)
+	c.Assert(condition.Reason, Equals, longhorn.SettingConditionReasonStaleCRDStoredVersion)
+
+	// The objects stored in an older version still served by the upgrade version don't block the upgrade
+	crd.Spec.Versions[0].Served = true
+	crd, err = extensionsClient.ApiextensionsV1().CustomResourceDefinitions().Update(context.TODO(), crd, metav1.UpdateOptions{})
+	c.Assert(err, IsNil)
+	condition = reconcile()
+	c.Assert(condition.Status, Equals, longhorn.ConditionStatusTrue)
+
+	// The upgrade is unblocked once the objects are migrated to a served version
+	crd.Spec.Versions[0].Served = false
+	crd.Status.StoredVersions = []string{"v1beta2"}
+	_, err = extensionsClient.ApiextensionsV1().CustomResourceDefinitions().Update(context.TODO(), crd, metav1.UpdateOptions{})
+	c.Assert(err, IsNil)
+	condition = reconcile()
+	c.Assert(condition.Status, Equals, longhorn.ConditionStatusTrue)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/longhorn/longhorn-manager/datastore"
 	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
 
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
@@ -29,10 +30,7 @@ func isVolumeUpgrading(v *longhorn.Volume) bool {
 
 // isTargetVolumeOfAnActiveCloning checks if the input volume is the target volume of an on-going cloning process
 func isTargetVolumeOfAnActiveCloning(v *longhorn.Volume) bool {
-	isCloningDesired := types.IsDataFromVolume(v.Spec.DataSource)
-	isCloningCompletedOrFailed := v.Status.CloneStatus.State == longhorn.VolumeCloneStateCompleted ||
-		v.Status.CloneStatus.State == longhorn.VolumeCloneStateFailed
-	return isCloningDesired && !isCloningCompletedOrFailed
+	return datastore.IsActiveCloneTargetVolume(v)
 }
 
 // isCloningRequiredAndNotCompleted returns true if the volume requires cloning and the cloning hasn't completed
@@ -93,7 +91,7 @@ func isRegularRWXVolume(v *longhorn.Volume) bool {
 	if v == nil {
 		return false
 	}
-	return v.Spec.AccessMode == longhorn.AccessModeReadWriteMany && !v.Spec.Migratable
+	return v.Spec.AccessMode == longhorn.AccessModeReadWriteMany && !v.Spec.Migratable && !util.IsRWXBlockVolume(v)
 }
 
 func checkIfRemoteDataCleanupIsNeeded(obj runtime.Object, bt *longhorn.BackupTarget) (bool, error) {
@@ -336,6 +336,8 @@ func (vac *VolumeAttachmentController) reconcile(vaName string) (err error) {
 
 	vac.handleVolumeMigration(va, vol)
 
+	vac.handleLiveMigrationTicketCleanup(va, vol)
+
 	return vac.handleVAStatusUpdate(va, vol)
 }
 
@@ -377,7 +379,7 @@ func (vac *VolumeAttachmentController) handleNodeCordoned(va *longhorn.VolumeAtt
 }
 
 func (vac *VolumeAttachmentController) handleVolumeMigration(va *longhorn.VolumeAttachment, vol *longhorn.Volume) {
-	if !util.IsMigratableVolume(vol) {
+	if !util.IsMigratableVolume(vol) && !isLiveMigratingVolume(va, vol) {
 		return
 	}
 
@@ -416,8 +418,8 @@ func (vac *VolumeAttachmentController) handleVolumeMigrationStart(va *longhorn.V
 	}
 	// Found one csi attachmentTicket that is requesting volume to attach to the current node
 
-	if attachmentTicket := getCSIAttachmentTicketNotRequestingNode(vol.Spec.NodeID, va, vol); attachmentTicket != nil {
-		// Found one csi attachmentTicket that is requesting volume to attach to a different node
+	if attachmentTicket := getMigrationAttachmentTicketNotRequestingNode(vol.Spec.NodeID, va, vol); attachmentTicket != nil {
+		// Found one csi (or live migration for a RWO volume) attachmentTicket that is requesting volume to attach to a different node
 		vol.Spec.MigrationNodeID = attachmentTicket.NodeID
 		log := getLoggerForMigratingLHVolumeAttachment(vac.logger, va, vol)
 		log.Info("Starting migration")
@@ -533,13 +535,36 @@ func (vac *VolumeAttachmentController) handleVolumeMigrationRollback(va *longhor
 		return
 	}
 
-	if !hasCSIAttachmentTicketRequestingNode(vol.Spec.MigrationNodeID, va, vol) {
+	if getMigrationAttachmentTicketRequestingNode(vol.Spec.MigrationNodeID, va, vol) == nil {
 		vol.Spec.MigrationNodeID = ""
 		log := getLoggerForMigratingLHVolumeAttachment(vac.logger, va, vol)
 		log.Info("Rolling back migration")
 	}
 }
 
+// handleLiveMigrationTicketCleanup removes the live migration ticket once the workload is attached to the node the RWO
+// volume was migrated to, since the CSI ticket keeps the volume attached from then on.
+func (vac *VolumeAttachmentController) handleLiveMigrationTicketCleanup(va *longhorn.VolumeAttachment, vol *longhorn.Volume) {
+	if util.IsVolumeMigrating(vol) {
+		return
+	}
+
+	for ticketID, ticket := range va.Spec.AttachmentTickets {
+		if ticket.Type != longhorn.AttacherTypeLiveMigrationController {
+			continue
+		}
+		if vol.Status.State != longhorn.VolumeStateAttached || vol.Status.CurrentNodeID != ticket.NodeID {
+			continue
+		}
+		if !hasCSIAttachmentTicketRequestingNode(ticket.NodeID, va, vol) {
+			continue
+		}
+		log := getLoggerForLHVolumeAttachment(vac.logger, va)
+		log.Infof("Deleting live migration attachment ticket %v since the workload is attached to node %v", ticketID, ticket.NodeID)
+		delete(va.Spec.AttachmentTickets, ticketID)
+	}
+}
+
 func (vac *VolumeAttachmentController) handleVolumeDetachment(va *longhorn.VolumeAttachment, vol *longhorn.Volume) {
 	log := getLoggerForLHVolumeAttachment(vac.logger, va)
 
@@ -568,7 +593,7 @@ func (vac *VolumeAttachmentController) shouldDoDetach(va *longhorn.VolumeAttachm
 	if vol.Status.Robustness == longhorn.VolumeRobustnessFaulted {
 		return true
 	}
-	if util.IsMigratableVolume(vol) && util.IsVolumeMigrating(vol) {
+	if (util.IsMigratableVolume(vol) || isLiveMigratingVolume(va, vol)) && util.IsVolumeMigrating(vol) {
 		// if the volume is migrating, the detachment will be handled by handleVolumeMigration()
 		return false
 	}
@@ -584,6 +609,11 @@ func (vac *VolumeAttachmentController) shouldDoDetach(va *longhorn.VolumeAttachm
 		if isCSIAttacherTicketOfRegularRWXVolume(attachmentTicket, vol) {
 			continue
 		}
+		// The CSI tickets of a RWX block volume on any node are served by the engine on the current node
+		if isCSIAttacherTicketOfRWXBlockVolume(attachmentTicket, vol) {
+			currentAttachmentTickets[attachmentTicket.ID] = attachmentTicket
+			continue
+		}
 		if attachmentTicket.NodeID == vol.Spec.NodeID && verifyAttachmentParameters(attachmentTicket.Parameters, vol) {
 			currentAttachmentTickets[attachmentTicket.ID] = attachmentTicket
 		}
@@ -619,7 +649,8 @@ func hasUninterruptibleTicket(attachmentTickets map[string]*longhorn.AttachmentT
 	for _, ticket := range attachmentTickets {
 		if ticket.Type != longhorn.AttacherTypeSnapshotController &&
 			ticket.Type != longhorn.AttacherTypeBackupController &&
-			ticket.Type != longhorn.AttacherTypeVolumeRebuildingController {
+			ticket.Type != longhorn.AttacherTypeVolumeRebuildingController &&
+			ticket.Type != longhorn.AttacherTypeLiveMigrationController {
 			return true
 		}
 	}
@@ -801,7 +832,7 @@ func (vac *VolumeAttachmentController) updateStatusForDesiredAttachingAttachment
 		return
 	}
 
-	if isMigratingCSIAttacherTicket(attachmentTicket, vol) {
+	if isMigratingCSIAttacherTicket(attachmentTicket, vol) || isMigratingLiveMigrationTicket(attachmentTicket, vol) {
 		if vac.isVolumeAvailableOnNode(vol.Name, attachmentTicket.NodeID) {
 			attachmentTicketStatus.Satisfied = true
 			attachmentTicketStatus.Conditions = types.SetCondition(
@@ -825,6 +856,29 @@ func (vac *VolumeAttachmentController) updateStatusForDesiredAttachingAttachment
 		return
 	}
 
+	if isCSIAttacherTicketOfRWXBlockVolume(attachmentTicket, vol) && attachmentTicket.NodeID != vol.Status.CurrentNodeID {
+		if vol.Status.CurrentNodeID != "" && vol.Status.State == longhorn.VolumeStateAttached {
+			attachmentTicketStatus.Satisfied = true
+			attachmentTicketStatus.Conditions = types.SetCondition(
+				attachmentTicketStatus.Conditions,
+				longhorn.AttachmentStatusConditionTypeSatisfied,
+				longhorn.ConditionStatusTrue,
+				"",
+				fmt.Sprintf("The attachment ticket is satisfied by the volume exported from node %v", vol.Status.CurrentNodeID),
+			)
+			return
+		}
+		attachmentTicketStatus.Satisfied = false
+		attachmentTicketStatus.Conditions = types.SetCondition(
+			attachmentTicketStatus.Conditions,
+			longhorn.AttachmentStatusConditionTypeSatisfied,
+			longhorn.ConditionStatusFalse,
+			"",
+			"Waiting for volume to be exported",
+		)
+		return
+	}
+
 	if vol.Status.CurrentNodeID == "" || vol.Status.State != longhorn.VolumeStateAttached {
 		attachmentTicketStatus.Satisfied = false
 		attachmentTicketStatus.Conditions = types.SetCondition(
@@ -940,6 +994,10 @@ func isCSIAttacherTicketOfRegularRWXVolume(attachmentTicket *longhorn.Attachment
 	return isRegularRWXVolume(v) && isCSIAttacherTicket(attachmentTicket)
 }
 
+func isCSIAttacherTicketOfRWXBlockVolume(attachmentTicket *longhorn.AttachmentTicket, v *longhorn.Volume) bool {
+	return v != nil && util.IsRWXBlockVolume(v) && isCSIAttacherTicket(attachmentTicket)
+}
+
 func isCSIAttacherTicket(ticket *longhorn.AttachmentTicket) bool {
 	if ticket == nil {
 		return false
@@ -956,6 +1014,32 @@ func isMigratingCSIAttacherTicket(attachmentTicket *longhorn.AttachmentTicket, v
 	return util.IsMigratableVolume(vol) && util.IsVolumeMigrating(vol) && isCSIAttacherTicket && isMigratingTicket
 }
 
+func isMigratingLiveMigrationTicket(attachmentTicket *longhorn.AttachmentTicket, vol *longhorn.Volume) bool {
+	if attachmentTicket == nil || vol == nil {
+		return false
+	}
+	isLiveMigrationTicket := attachmentTicket.Type == longhorn.AttacherTypeLiveMigrationController
+	isMigratingTicket := attachmentTicket.NodeID == vol.Status.CurrentMigrationNodeID
+	return util.IsVolumeMigrating(vol) && isLiveMigrationTicket && isMigratingTicket
+}
+
+// isLiveMigratingVolume returns true if the RWO volume is requested to migrate by a live migration ticket, or if it's
+// still migrating after the ticket is gone.
+func isLiveMigratingVolume(va *longhorn.VolumeAttachment, vol *longhorn.Volume) bool {
+	if vol.Spec.AccessMode != longhorn.AccessModeReadWriteOnce {
+		return false
+	}
+	if util.IsVolumeMigrating(vol) {
+		return true
+	}
+	for _, attachmentTicket := range va.Spec.AttachmentTickets {
+		if attachmentTicket.Type == longhorn.AttacherTypeLiveMigrationController {
+			return true
+		}
+	}
+	return false
+}
+
 func isVolumeShareAvailable(vol *longhorn.Volume) bool {
 	return vol.Spec.AccessMode == longhorn.AccessModeReadWriteMany &&
 		vol.Status.ShareState == longhorn.ShareManagerStateRunning &&
@@ -999,9 +1083,23 @@ func hasCSIAttachmentTicketRequestingNode(nodeID string, va *longhorn.VolumeAtta
 	return false
 }
 
-func getCSIAttachmentTicketNotRequestingNode(nodeID string, va *longhorn.VolumeAttachment, vol *longhorn.Volume) *longhorn.AttachmentTicket {
+// getMigrationAttachmentTicketRequestingNode returns the ticket driving the migration to the node, which is a csi ticket
+// for a migratable RWX volume, or the live migration ticket for a RWO volume.
+func getMigrationAttachmentTicketRequestingNode(nodeID string, va *longhorn.VolumeAttachment, vol *longhorn.Volume) *longhorn.AttachmentTicket {
 	for _, attachmentTicket := range va.Spec.AttachmentTickets {
-		if attachmentTicket.Type != longhorn.AttacherTypeCSIAttacher {
+		if !isMigrationAttachmentTicket(attachmentTicket, vol) {
+			continue
+		}
+		if attachmentTicket.NodeID == nodeID && verifyAttachmentParameters(attachmentTicket.Parameters, vol) {
+			return attachmentTicket
+		}
+	}
+	return nil
+}
+
+func getMigrationAttachmentTicketNotRequestingNode(nodeID string, va *longhorn.VolumeAttachment, vol *longhorn.Volume) *longhorn.AttachmentTicket {
+	for _, attachmentTicket := range va.Spec.AttachmentTickets {
+		if !isMigrationAttachmentTicket(attachmentTicket, vol) {
 			continue
 		}
 		if attachmentTicket.NodeID != nodeID && verifyAttachmentParameters(attachmentTicket.Parameters, vol) {
@@ -1010,3 +1108,10 @@ func getCSIAttachmentTicketNotRequestingNode(nodeID string, va *longhorn.VolumeA
 	}
 	return nil
 }
+
+func isMigrationAttachmentTicket(attachmentTicket *longhorn.AttachmentTicket, vol *longhorn.Volume) bool {
+	if util.IsMigratableVolume(vol) {
+		return attachmentTicket.Type == longhorn.AttacherTypeCSIAttacher
+	}
+	return attachmentTicket.Type == longhorn.AttacherTypeLiveMigrationController
+}
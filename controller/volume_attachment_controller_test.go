@@ -409,6 +409,87 @@ func (s *TestSuite) TestVolumeAttachmentLifeCycle(c *C) {
 	testCases["test case 10: ticket with higher priority interrupts ticket with lower priority"] = tc
 	///////////////////////////////////////////////////////////////////
 
+	///////////////////////////////////////////////////////////////////
+	tc = generateVolumeAttachmentTestCaseTemplate(TestVolumeName)
+	tc.volAttachment.Spec.AttachmentTickets = map[string]*longhorn.AttachmentTicket{
+		"attachment-01": &longhorn.AttachmentTicket{
+			ID:         "attachment-01",
+			Type:       longhorn.AttacherTypeCSIAttacher,
+			NodeID:     TestNode1,
+			Parameters: map[string]string{},
+			Generation: 0,
+		},
+		"attachment-02": &longhorn.AttachmentTicket{
+			ID:         "attachment-02",
+			Type:       longhorn.AttacherTypeLiveMigrationController,
+			NodeID:     TestNode2,
+			Parameters: map[string]string{},
+			Generation: 0,
+		},
+	}
+	tc.vol.Status.OwnerID = TestNode1
+	tc.vol.Spec.NodeID = TestNode1
+	tc.vol.Spec.AccessMode = longhorn.AccessModeReadWriteOnce
+	tc.vol.Status.CurrentNodeID = TestNode1
+	tc.vol.Status.State = longhorn.VolumeStateAttached
+	tc.copyCurrentToExpect()
+	tc.expectedVolAttachment.Status.AttachmentTicketStatuses = map[string]*longhorn.AttachmentTicketStatus{
+		"attachment-01": &longhorn.AttachmentTicketStatus{
+			ID:        "attachment-01",
+			Satisfied: true,
+			Conditions: types.SetConditionWithoutTimestamp([]longhorn.Condition{},
+				longhorn.AttachmentStatusConditionTypeSatisfied, longhorn.ConditionStatusTrue, "", ""),
+			Generation: 0,
+		},
+		"attachment-02": &longhorn.AttachmentTicketStatus{
+			ID:        "attachment-02",
+			Satisfied: false,
+			Conditions: types.SetConditionWithoutTimestamp([]longhorn.Condition{},
+				longhorn.AttachmentStatusConditionTypeSatisfied, longhorn.ConditionStatusFalse, "",
+				fmt.Sprintf("the volume is currently attached to different node %v ", TestNode1)),
+			Generation: 0,
+		},
+	}
+	tc.expectedVol.Spec.MigrationNodeID = TestNode2
+	testCases["test case 11: live migration ticket starts the migration of RWO volume"] = tc
+	///////////////////////////////////////////////////////////////////
+
+	///////////////////////////////////////////////////////////////////
+	tc = generateVolumeAttachmentTestCaseTemplate(TestVolumeName)
+	tc.volAttachment.Spec.AttachmentTickets = map[string]*longhorn.AttachmentTicket{
+		"attachment-01": &longhorn.AttachmentTicket{
+			ID:         "attachment-01",
+			Type:       longhorn.AttacherTypeCSIAttacher,
+			NodeID:     TestNode2,
+			Parameters: map[string]string{},
+			Generation: 0,
+		},
+		"attachment-02": &longhorn.AttachmentTicket{
+			ID:         "attachment-02",
+			Type:       longhorn.AttacherTypeLiveMigrationController,
+			NodeID:     TestNode2,
+			Parameters: map[string]string{},
+			Generation: 0,
+		},
+	}
+	tc.vol.Status.OwnerID = TestNode1
+	tc.vol.Spec.NodeID = TestNode2
+	tc.vol.Spec.AccessMode = longhorn.AccessModeReadWriteOnce
+	tc.vol.Status.CurrentNodeID = TestNode2
+	tc.vol.Status.State = longhorn.VolumeStateAttached
+	tc.copyCurrentToExpect()
+	tc.expectedVolAttachment.Status.AttachmentTicketStatuses = map[string]*longhorn.AttachmentTicketStatus{
+		"attachment-01": &longhorn.AttachmentTicketStatus{
+			ID:        "attachment-01",
+			Satisfied: true,
+			Conditions: types.SetConditionWithoutTimestamp([]longhorn.Condition{},
+				longhorn.AttachmentStatusConditionTypeSatisfied, longhorn.ConditionStatusTrue, "", ""),
+			Generation: 0,
+		},
+	}
+	testCases["test case 12: live migration ticket is removed once the workload is attached to the new node"] = tc
+	///////////////////////////////////////////////////////////////////
+
 	for name, tc := range testCases {
 		//uncomment this block to test individual test case
 		//if name != "test case 10: ticket with higher priority interrupts ticket with lower priority" {
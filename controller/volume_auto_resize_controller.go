@@ -0,0 +1,288 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/longhorn/longhorn-manager/constant"
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	volumeAutoResizeCheckInterval = time.Minute
+)
+
+// VolumeAutoResizeController expands the volumes with an auto resize policy through their PVCs once their filesystems
+// are filling up. The filesystem usage is checked on the node the volume is attached to, and the CSI resizer expands
+// the volume and its filesystem as it does for the expansion requested by the users.
+type VolumeAutoResizeController struct {
+	*baseController
+
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the controller
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds         *datastore.DataStore
+	cacheSyncs []cache.InformerSynced
+
+	// for unit test
+	getFilesystemUsage func(volumeName string, encryptedDevice bool) (total, available int64, err error)
+}
+
+func NewVolumeAutoResizeController(
+	logger logrus.FieldLogger,
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	kubeClient clientset.Interface,
+	controllerID string,
+	namespace string,
+) (*VolumeAutoResizeController, error) {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	// TODO: remove the wrapper when every clients have moved to use the clientset.
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	vrc := &VolumeAutoResizeController{
+		baseController: newBaseController("longhorn-volume-auto-resize", logger),
+
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		ds: ds,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-volume-auto-resize-controller"}),
+
+		getFilesystemUsage: util.GetFilesystemUsage,
+	}
+
+	var err error
+	if _, err = ds.VolumeInformer.AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
+		AddFunc:    vrc.enqueueVolume,
+		UpdateFunc: func(old, cur interface{}) { vrc.enqueueVolume(cur) },
+		DeleteFunc: vrc.enqueueVolume,
+	}, 0); err != nil {
+		return nil, err
+	}
+	vrc.cacheSyncs = append(vrc.cacheSyncs, ds.VolumeInformer.HasSynced)
+
+	return vrc, nil
+}
+
+func (vrc *VolumeAutoResizeController) enqueueVolume(obj interface{}) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %#v: %v", obj, err))
+		return
+	}
+
+	vrc.queue.Add(key)
+}
+
+func (vrc *VolumeAutoResizeController) enqueueVolumeAfter(obj interface{}, duration time.Duration) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("enqueueVolumeAfter: failed to get key for object %#v: %v", obj, err))
+		return
+	}
+
+	vrc.queue.AddAfter(key, duration)
+}
+
+func (vrc *VolumeAutoResizeController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer vrc.queue.ShutDown()
+
+	vrc.logger.Info("Starting Longhorn volume auto resize controller")
+	defer vrc.logger.Info("Shut down Longhorn volume auto resize controller")
+
+	if !cache.WaitForNamedCacheSync(vrc.name, stopCh, vrc.cacheSyncs...) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(vrc.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (vrc *VolumeAutoResizeController) worker() {
+	for vrc.processNextWorkItem() {
+	}
+}
+
+func (vrc *VolumeAutoResizeController) processNextWorkItem() bool {
+	key, quit := vrc.queue.Get()
+	if quit {
+		return false
+	}
+	defer vrc.queue.Done(key)
+	err := vrc.syncHandler(key.(string))
+	vrc.handleErr(err, key)
+	return true
+}
+
+func (vrc *VolumeAutoResizeController) handleErr(err error, key interface{}) {
+	if err == nil {
+		vrc.queue.Forget(key)
+		return
+	}
+
+	log := vrc.logger.WithField("Volume", key)
+	handleReconcileErrorLogging(log, err, "Failed to sync Longhorn volume")
+	vrc.queue.AddRateLimited(key)
+}
+
+func (vrc *VolumeAutoResizeController) syncHandler(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "%v: failed to sync volume %v", vrc.name, key)
+	}()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != vrc.namespace {
+		return nil
+	}
+	return vrc.reconcile(name)
+}
+
+func (vrc *VolumeAutoResizeController) reconcile(volName string) error {
+	vol, err := vrc.ds.GetVolumeRO(volName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	if !vrc.isResponsibleFor(vol) {
+		return nil
+	}
+
+	// The filesystem of the regular RWX volume is mounted in the share manager pod rather than on the host. The
+	// webhook rejects the auto resize policy of the RWX volumes, this guards the ones set before.
+	if vol.Spec.AccessMode == longhorn.AccessModeReadWriteMany {
+		return nil
+	}
+	// The usage is checked again after the interval, the volume events don't tell when the filesystem is filling up
+	defer vrc.enqueueVolumeAfter(vol, volumeAutoResizeCheckInterval)
+
+	policy := vol.Spec.AutoResize
+	if vol.Spec.Size >= policy.MaxSize {
+		return nil
+	}
+
+	expanding, err := vrc.isVolumeExpanding(vol)
+	if err != nil {
+		return err
+	}
+	if expanding {
+		return nil
+	}
+
+	ks := vol.Status.KubernetesStatus
+	if ks.PVCName == "" || ks.LastPVCRefAt != "" {
+		return nil
+	}
+	pvc, err := vrc.ds.GetPersistentVolumeClaim(ks.Namespace, ks.PVCName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	requestedSize := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if requestedSize.Value() > vol.Spec.Size {
+		// The expansion of the PVC is not handled by the CSI resizer yet
+		return nil
+	}
+
+	log := getLoggerForVolume(vrc.logger, vol)
+
+	total, available, err := vrc.getFilesystemUsage(vol.Name, vol.Spec.Encrypted)
+	if err != nil {
+		// The block volumes and the volumes not mounted yet don't have a filesystem to check
+		log.WithError(err).Debug("Skipped checking filesystem usage for auto resize")
+		return nil
+	}
+	if total <= 0 {
+		return nil
+	}
+	usedPercentage := (total - available) * 100 / total
+	if usedPercentage < int64(policy.ThresholdPercentage) {
+		return nil
+	}
+
+	newSize := util.RoundUpSize(vol.Spec.Size + policy.Increment)
+	if newSize > policy.MaxSize {
+		newSize = policy.MaxSize
+	}
+	if newSize <= vol.Spec.Size {
+		return nil
+	}
+
+	log.Infof("Expanding volume automatically from %v to %v since %v%% of the filesystem is used", vol.Spec.Size, newSize, usedPercentage)
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = *resource.NewQuantity(newSize, resource.BinarySI)
+	if _, err := vrc.ds.UpdatePersistentVolumeClaim(pvc.Namespace, pvc); err != nil {
+		vrc.eventRecorder.Eventf(vol, corev1.EventTypeWarning, constant.EventReasonFailedAutoResize,
+			"Failed to expand volume automatically from %v to %v through PVC %v: %v", vol.Spec.Size, newSize, pvc.Name, err)
+		return errors.Wrapf(err, "failed to expand PVC %v for auto resize", pvc.Name)
+	}
+	vrc.eventRecorder.Eventf(vol, corev1.EventTypeNormal, constant.EventReasonAutoResized,
+		"Expanding volume automatically from %v to %v through PVC %v since %v%% of the filesystem is used",
+		vol.Spec.Size, newSize, pvc.Name, usedPercentage)
+
+	return nil
+}
+
+// isVolumeExpanding returns true if the previous expansion of the volume is not done yet, so the filesystem usage is
+// not reliable for the next one.
+func (vrc *VolumeAutoResizeController) isVolumeExpanding(vol *longhorn.Volume) (bool, error) {
+	if vol.Status.ExpansionRequired {
+		return true, nil
+	}
+	es, err := vrc.ds.ListVolumeEnginesRO(vol.Name)
+	if err != nil {
+		return false, err
+	}
+	e, err := vrc.ds.PickVolumeCurrentEngine(vol, es)
+	if err != nil {
+		return false, err
+	}
+	if e == nil || e.Status.CurrentState != longhorn.InstanceStateRunning {
+		return true, nil
+	}
+	return e.Status.CurrentSize != vol.Spec.Size, nil
+}
+
+func (vrc *VolumeAutoResizeController) isResponsibleFor(vol *longhorn.Volume) bool {
+	return vol.Spec.AutoResize != nil &&
+		vol.Status.State == longhorn.VolumeStateAttached &&
+		vol.Status.CurrentNodeID == vrc.controllerID
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/util"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+
+	. "gopkg.in/check.v1"
+)
+
+type volumeAutoResizeTestCase struct {
+	policy          *longhorn.VolumeAutoResizePolicy
+	usedPercentage  int64
+	engineSize      int64
+	currentNodeID   string
+	expectedPVCSize int64
+}
+
+func (s *TestSuite) TestVolumeAutoResize(c *C) {
+	policy := &longhorn.VolumeAutoResizePolicy{
+		ThresholdPercentage: 80,
+		Increment:           TestVolumeSize,
+		MaxSize:             4 * TestVolumeSize,
+	}
+	testCases := map[string]volumeAutoResizeTestCase{
+		"filesystem usage reaches threshold": {
+			policy:          policy,
+			usedPercentage:  85,
+			expectedPVCSize: 2 * TestVolumeSize,
+		},
+		"filesystem usage below threshold": {
+			policy:          policy,
+			usedPercentage:  50,
+			expectedPVCSize: TestVolumeSize,
+		},
+		"new size capped by max size": {
+			policy: &longhorn.VolumeAutoResizePolicy{
+				ThresholdPercentage: 80,
+				Increment:           TestVolumeSize,
+				MaxSize:             TestVolumeSize + TestVolumeSize/2,
+			},
+			usedPercentage:  85,
+			expectedPVCSize: TestVolumeSize + TestVolumeSize/2,
+		},
+		"rounded new size capped by max size": {
+			policy: &longhorn.VolumeAutoResizePolicy{
+				ThresholdPercentage: 80,
+				Increment:           1,
+				MaxSize:             TestVolumeSize + 1024,
+			},
+			usedPercentage:  85,
+			expectedPVCSize: TestVolumeSize + 1024,
+		},
+		"previous expansion in progress": {
+			policy:          policy,
+			usedPercentage:  85,
+			engineSize:      TestVolumeSize / 2,
+			expectedPVCSize: TestVolumeSize,
+		},
+		"volume attached to another node": {
+			policy:          policy,
+			usedPercentage:  85,
+			currentNodeID:   TestNode2,
+			expectedPVCSize: TestVolumeSize,
+		},
+		"auto resize disabled": {
+			usedPercentage:  85,
+			expectedPVCSize: TestVolumeSize,
+		},
+	}
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		lhClient := lhfake.NewSimpleClientset()
+		extensionsClient := apiextensionsfake.NewSimpleClientset()
+		informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+		volumeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Volumes().Informer().GetIndexer()
+		engineIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Engines().Informer().GetIndexer()
+		pvcIndexer := informerFactories.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer()
+
+		ds := datastore.NewDataStore(TestNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+		vrc, err := NewVolumeAutoResizeController(logrus.StandardLogger(), ds, scheme.Scheme, kubeClient, TestNode1, TestNamespace)
+		c.Assert(err, IsNil)
+		vrc.eventRecorder = record.NewFakeRecorder(100)
+		vrc.getFilesystemUsage = func(volumeName string, encryptedDevice bool) (int64, int64, error) {
+			return 100, 100 - tc.usedPercentage, nil
+		}
+
+		currentNodeID := TestNode1
+		if tc.currentNodeID != "" {
+			currentNodeID = tc.currentNodeID
+		}
+		volume := newVolume(TestVolumeName, 2)
+		volume.Spec.AccessMode = longhorn.AccessModeReadWriteOnce
+		volume.Spec.AutoResize = tc.policy
+		volume.Status.State = longhorn.VolumeStateAttached
+		volume.Status.CurrentNodeID = currentNodeID
+		volume.Status.KubernetesStatus = longhorn.KubernetesStatus{
+			Namespace: TestNamespace,
+			PVCName:   TestPVCName,
+		}
+		volume, err = lhClient.LonghornV1beta2().Volumes(TestNamespace).Create(context.TODO(), volume, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(volumeIndexer.Add(volume), IsNil)
+
+		engineSize := int64(TestVolumeSize)
+		if tc.engineSize != 0 {
+			engineSize = tc.engineSize
+		}
+		engine := newEngineForVolume(volume)
+		engine.Spec.NodeID = currentNodeID
+		engine.Spec.DesireState = longhorn.InstanceStateRunning
+		engine.Status.CurrentState = longhorn.InstanceStateRunning
+		engine.Status.CurrentSize = engineSize
+		engine, err = lhClient.LonghornV1beta2().Engines(TestNamespace).Create(context.TODO(), engine, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(engineIndexer.Add(engine), IsNil)
+
+		pvc := newPVC()
+		pvc.Namespace = TestNamespace
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = *resource.NewQuantity(TestVolumeSize, resource.BinarySI)
+		pvc, err = kubeClient.CoreV1().PersistentVolumeClaims(TestNamespace).Create(context.TODO(), pvc, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(pvcIndexer.Add(pvc), IsNil)
+
+		err = vrc.syncHandler(getKey(volume, c))
+		c.Assert(err, IsNil)
+
+		pvc, err = kubeClient.CoreV1().PersistentVolumeClaims(TestNamespace).Get(context.TODO(), TestPVCName, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+		requestedSize := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		c.Assert(requestedSize.Value(), Equals, tc.expectedPVCSize)
+	}
+}
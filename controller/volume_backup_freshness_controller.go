@@ -0,0 +1,236 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// VolumeBackupFreshnessController maintains the BackupFresh condition of the volumes with a backup max age, which turns
+// false once the last completed backup of the volume is older than the backup max age, so the volumes silently missing
+// their backup window can be alerted on.
+type VolumeBackupFreshnessController struct {
+	*baseController
+
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the controller
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds         *datastore.DataStore
+	cacheSyncs []cache.InformerSynced
+}
+
+func NewVolumeBackupFreshnessController(
+	logger logrus.FieldLogger,
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	kubeClient clientset.Interface,
+	controllerID string,
+	namespace string,
+) (*VolumeBackupFreshnessController, error) {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	vbfc := &VolumeBackupFreshnessController{
+		baseController: newBaseController("longhorn-volume-backup-freshness", logger),
+
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		ds: ds,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-volume-backup-freshness-controller"}),
+	}
+
+	var err error
+	if _, err = ds.VolumeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    vbfc.enqueueVolume,
+		UpdateFunc: func(old, cur interface{}) { vbfc.enqueueVolume(cur) },
+	}); err != nil {
+		return nil, err
+	}
+	vbfc.cacheSyncs = append(vbfc.cacheSyncs, ds.VolumeInformer.HasSynced)
+
+	return vbfc, nil
+}
+
+func (vbfc *VolumeBackupFreshnessController) enqueueVolume(obj interface{}) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %#v: %v", obj, err))
+		return
+	}
+
+	vbfc.queue.Add(key)
+}
+
+func (vbfc *VolumeBackupFreshnessController) enqueueVolumeAfter(obj interface{}, duration time.Duration) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("enqueueVolumeAfter: failed to get key for object %#v: %v", obj, err))
+		return
+	}
+
+	vbfc.queue.AddAfter(key, duration)
+}
+
+func (vbfc *VolumeBackupFreshnessController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer vbfc.queue.ShutDown()
+
+	vbfc.logger.Info("Starting Longhorn volume backup freshness controller")
+	defer vbfc.logger.Info("Shut down Longhorn volume backup freshness controller")
+
+	if !cache.WaitForNamedCacheSync(vbfc.name, stopCh, vbfc.cacheSyncs...) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(vbfc.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (vbfc *VolumeBackupFreshnessController) worker() {
+	for vbfc.processNextWorkItem() {
+	}
+}
+
+func (vbfc *VolumeBackupFreshnessController) processNextWorkItem() bool {
+	key, quit := vbfc.queue.Get()
+	if quit {
+		return false
+	}
+	defer vbfc.queue.Done(key)
+	err := vbfc.syncHandler(key.(string))
+	vbfc.handleErr(err, key)
+	return true
+}
+
+func (vbfc *VolumeBackupFreshnessController) handleErr(err error, key interface{}) {
+	if err == nil {
+		vbfc.queue.Forget(key)
+		return
+	}
+
+	log := vbfc.logger.WithField("Volume", key)
+	handleReconcileErrorLogging(log, err, "Failed to sync Longhorn volume")
+	vbfc.queue.AddRateLimited(key)
+}
+
+func (vbfc *VolumeBackupFreshnessController) syncHandler(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "%v: failed to sync volume %v", vbfc.name, key)
+	}()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != vbfc.namespace {
+		return nil
+	}
+	return vbfc.reconcile(name)
+}
+
+func (vbfc *VolumeBackupFreshnessController) reconcile(volName string) (err error) {
+	vol, err := vbfc.ds.GetVolume(volName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	if vol.Status.OwnerID != vbfc.controllerID || !vol.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	existingVolume := vol.DeepCopy()
+	defer func() {
+		if reflect.DeepEqual(existingVolume.Status, vol.Status) {
+			return
+		}
+		if _, updateErr := vbfc.ds.UpdateVolumeStatus(vol); updateErr != nil {
+			err = errors.Wrapf(updateErr, "failed to update backup fresh condition of volume %v", volName)
+		}
+	}()
+
+	// The DR volumes are restored from the backups of another volume rather than backed up
+	if vol.Spec.BackupMaxAge == 0 || vol.Spec.Standby {
+		vol.Status.Conditions = types.RemoveCondition(vol.Status.Conditions, longhorn.VolumeConditionTypeBackupFresh)
+		return nil
+	}
+
+	now := vbfc.clock.Now()
+	fresh, reason, message, staleAt := getVolumeBackupFreshness(vol, now)
+	if !fresh {
+		vol.Status.Conditions = types.SetConditionAndRecord(vol.Status.Conditions,
+			longhorn.VolumeConditionTypeBackupFresh, longhorn.ConditionStatusFalse, reason, message,
+			vbfc.eventRecorder, vol, corev1.EventTypeWarning)
+		return nil
+	}
+
+	vol.Status.Conditions = types.SetConditionAndRecord(vol.Status.Conditions,
+		longhorn.VolumeConditionTypeBackupFresh, longhorn.ConditionStatusTrue, "", "",
+		vbfc.eventRecorder, vol, corev1.EventTypeNormal)
+	// The volume events don't tell when the last backup goes stale
+	vbfc.enqueueVolumeAfter(vol, staleAt.Sub(now)+time.Second)
+	return nil
+}
+
+// getVolumeBackupFreshness returns false with the reason and the message of the BackupFresh condition if the last
+// completed backup of the volume is older than the backup max age of the volume. Otherwise, it returns true with the
+// time the last backup goes stale. A volume never backed up goes stale once it's older than the backup max age.
+func getVolumeBackupFreshness(vol *longhorn.Volume, now time.Time) (fresh bool, reason, message string, staleAt time.Time) {
+	maxAge := time.Duration(vol.Spec.BackupMaxAge) * time.Minute
+
+	if vol.Status.LastBackupAt == "" {
+		staleAt = vol.CreationTimestamp.Add(maxAge)
+		if now.Before(staleAt) {
+			return true, "", "", staleAt
+		}
+		return false, longhorn.VolumeConditionReasonBackupNotFound,
+			fmt.Sprintf("volume has no completed backup within the backup max age %v", maxAge), time.Time{}
+	}
+
+	lastBackupAt, err := util.ParseTime(vol.Status.LastBackupAt)
+	if err != nil {
+		return false, longhorn.VolumeConditionReasonBackupStale,
+			fmt.Sprintf("failed to parse the completion time %v of the last backup %v: %v", vol.Status.LastBackupAt, vol.Status.LastBackup, err), time.Time{}
+	}
+	staleAt = lastBackupAt.Add(maxAge)
+	if now.Before(staleAt) {
+		return true, "", "", staleAt
+	}
+	return false, longhorn.VolumeConditionReasonBackupStale,
+		fmt.Sprintf("the last backup %v completed at %v is older than the backup max age %v", vol.Status.LastBackup, vol.Status.LastBackupAt, maxAge), time.Time{}
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+
+	. "gopkg.in/check.v1"
+)
+
+func newTestVolumeBackupFreshnessController(lhClient *lhfake.Clientset, kubeClient *fake.Clientset, extensionsClient *apiextensionsfake.Clientset,
+	informerFactories *util.InformerFactories, controllerID string) (*VolumeBackupFreshnessController, error) {
+	ds := datastore.NewDataStore(TestNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+
+	logger := logrus.StandardLogger()
+	vbfc, err := NewVolumeBackupFreshnessController(logger, ds, scheme.Scheme, kubeClient, controllerID, TestNamespace)
+	if err != nil {
+		return nil, err
+	}
+	fakeRecorder := record.NewFakeRecorder(100)
+	vbfc.eventRecorder = fakeRecorder
+	for index := range vbfc.cacheSyncs {
+		vbfc.cacheSyncs[index] = alwaysReady
+	}
+	vbfc.SetClock(getTestClock())
+
+	return vbfc, nil
+}
+
+func (s *TestSuite) TestVolumeBackupFreshness(c *C) {
+	type testCase struct {
+		ownerID      string
+		standby      bool
+		backupMaxAge int
+		volumeAge    time.Duration
+		lastBackupAt time.Duration
+		existingCond longhorn.ConditionStatus
+
+		expectedStatus longhorn.ConditionStatus
+		expectedReason string
+	}
+	testCases := map[string]testCase{
+		"fresh backup": {
+			backupMaxAge:   60,
+			volumeAge:      24 * time.Hour,
+			lastBackupAt:   30 * time.Minute,
+			expectedStatus: longhorn.ConditionStatusTrue,
+		},
+		"stale backup": {
+			backupMaxAge:   60,
+			volumeAge:      24 * time.Hour,
+			lastBackupAt:   2 * time.Hour,
+			existingCond:   longhorn.ConditionStatusTrue,
+			expectedStatus: longhorn.ConditionStatusFalse,
+			expectedReason: longhorn.VolumeConditionReasonBackupStale,
+		},
+		"new volume never backed up": {
+			backupMaxAge:   60,
+			volumeAge:      30 * time.Minute,
+			expectedStatus: longhorn.ConditionStatusTrue,
+		},
+		"old volume never backed up": {
+			backupMaxAge:   60,
+			volumeAge:      2 * time.Hour,
+			expectedStatus: longhorn.ConditionStatusFalse,
+			expectedReason: longhorn.VolumeConditionReasonBackupNotFound,
+		},
+		"backup max age unset": {
+			volumeAge:      2 * time.Hour,
+			existingCond:   longhorn.ConditionStatusFalse,
+			expectedStatus: longhorn.ConditionStatusUnknown,
+		},
+		"DR volume": {
+			standby:        true,
+			backupMaxAge:   60,
+			volumeAge:      2 * time.Hour,
+			existingCond:   longhorn.ConditionStatusFalse,
+			expectedStatus: longhorn.ConditionStatusUnknown,
+		},
+		"volume owned by another node": {
+			ownerID:        TestNode2,
+			backupMaxAge:   60,
+			volumeAge:      2 * time.Hour,
+			expectedStatus: longhorn.ConditionStatusUnknown,
+		},
+	}
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		lhClient := lhfake.NewSimpleClientset()
+		extensionsClient := apiextensionsfake.NewSimpleClientset()
+		informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+		vIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Volumes().Informer().GetIndexer()
+
+		vbfc, err := newTestVolumeBackupFreshnessController(lhClient, kubeClient, extensionsClient, informerFactories, TestNode1)
+		c.Assert(err, IsNil)
+		now := vbfc.clock.Now()
+
+		v := newVolume(TestVolumeName, 2)
+		v.CreationTimestamp = metav1.NewTime(now.Add(-tc.volumeAge))
+		v.Spec.Standby = tc.standby
+		v.Spec.BackupMaxAge = tc.backupMaxAge
+		v.Status.OwnerID = TestNode1
+		if tc.ownerID != "" {
+			v.Status.OwnerID = tc.ownerID
+		}
+		if tc.lastBackupAt != 0 {
+			v.Status.LastBackup = "backup-1"
+			v.Status.LastBackupAt = now.Add(-tc.lastBackupAt).UTC().Format(time.RFC3339)
+		}
+		if tc.existingCond != "" {
+			v.Status.Conditions = types.SetCondition(v.Status.Conditions, longhorn.VolumeConditionTypeBackupFresh,
+				tc.existingCond, "", "")
+		}
+		v, err = lhClient.LonghornV1beta2().Volumes(TestNamespace).Create(context.TODO(), v, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(vIndexer.Add(v), IsNil)
+
+		err = vbfc.reconcile(TestVolumeName)
+		c.Assert(err, IsNil)
+
+		v, err = lhClient.LonghornV1beta2().Volumes(TestNamespace).Get(context.TODO(), TestVolumeName, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+		condition := types.GetCondition(v.Status.Conditions, longhorn.VolumeConditionTypeBackupFresh)
+		c.Assert(condition.Status, Equals, tc.expectedStatus)
+		c.Assert(condition.Reason, Equals, tc.expectedReason)
+	}
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// getCapacityRebalanceMovingReplica returns the healthy replica being moved by the capacity rebalance, or nil if the
+// volume isn't being moved
+func getCapacityRebalanceMovingReplica(v *longhorn.Volume, rs map[string]*longhorn.Replica) *longhorn.Replica {
+	move, err := types.GetCapacityRebalanceMove(v)
+	if err != nil || move == nil {
+		return nil
+	}
+	r, exists := rs[move.ReplicaName]
+	if !exists || !datastore.IsAvailableHealthyReplica(r) {
+		return nil
+	}
+	return r
+}
+
+// moveReplicaForCapacityRebalance rebuilds a new replica to replace the replica moved by the capacity rebalance. The
+// new replica is scheduled to the target disk of the move, and the moved replica is removed by
+// cleanupCapacityRebalanceReplica once the new one is healthy.
+func (c *VolumeController) moveReplicaForCapacityRebalance(v *longhorn.Volume, e *longhorn.Engine, rs map[string]*longhorn.Replica) error {
+	if getCapacityRebalanceMovingReplica(v, rs) == nil {
+		return nil
+	}
+	return c.replenishReplicas(v, e, rs, "")
+}
+
+// cleanupCapacityRebalanceReplica removes the replica moved by the capacity rebalance, once there are enough healthy
+// replicas without it
+func (c *VolumeController) cleanupCapacityRebalanceReplica(v *longhorn.Volume, rs map[string]*longhorn.Replica) (bool, error) {
+	r := getCapacityRebalanceMovingReplica(v, rs)
+	if r == nil {
+		return false, nil
+	}
+
+	healthyCount := 0
+	for _, replica := range rs {
+		if replica.Name != r.Name && datastore.IsAvailableHealthyReplica(replica) {
+			healthyCount++
+		}
+	}
+	if healthyCount < v.Spec.NumberOfReplicas {
+		return false, nil
+	}
+
+	if err := c.deleteReplica(r, rs); err != nil {
+		return false, errors.Wrapf(err, "failed to clean up replica %v moved by the capacity rebalance", r.Name)
+	}
+	getLoggerForVolume(c.logger, v).Infof("Cleaned up replica %v moved by the capacity rebalance", r.Name)
+	return true, nil
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
@@ -228,12 +229,26 @@ func (vcc *VolumeCloneController) reconcile(volName string) (err error) {
 	if err != nil {
 		return err
 	}
+	sourceNodeID, sourceDisableFrontend := "", ""
+	cloneTargets := []string{}
 	for _, v := range vols {
+		if !isTargetVolumeOfAnActiveCloning(v) || types.GetVolumeName(v.Spec.DataSource) != vol.Name {
+			continue
+		}
+		cloneTargets = append(cloneTargets, v.Name)
 		attachmentTicketID := longhorn.GetAttachmentTicketID(longhorn.AttacherTypeVolumeCloneController, v.Name)
-		if isTargetVolumeOfAnActiveCloning(v) && types.GetVolumeName(v.Spec.DataSource) == vol.Name {
-			createOrUpdateAttachmentTicket(va, attachmentTicketID, vol.Status.OwnerID, longhorn.AnyValue, longhorn.AttacherTypeVolumeCloneController)
-			expectedAttachmentTickets[attachmentTicketID] = true
+		expectedAttachmentTickets[attachmentTicketID] = true
+		// Keep the existing tickets as they are, so the source volume is not moved in the middle of the cloning
+		if ticket, ok := va.Spec.AttachmentTickets[attachmentTicketID]; ok && !vcc.isNodeDown(ticket.NodeID) {
+			continue
+		}
+		if sourceNodeID == "" {
+			if sourceNodeID, sourceDisableFrontend, err = vcc.getSourceVolumeAttachmentNode(vol, va); err != nil {
+				return err
+			}
 		}
+		createOrUpdateAttachmentTicket(va, attachmentTicketID, sourceNodeID, sourceDisableFrontend, longhorn.AttacherTypeVolumeCloneController)
+		va.Spec.AttachmentTickets[attachmentTicketID].Parameters[longhorn.AttachmentParameterDisableFrontend] = sourceDisableFrontend
 	}
 
 	// Delete unexpected attachment tickets
@@ -245,9 +260,82 @@ func (vcc *VolumeCloneController) reconcile(volName string) (err error) {
 		}
 	}
 
+	return vcc.updateCloneTargets(vol, cloneTargets)
+}
+
+// updateCloneTargets records the volumes being cloned from the source volume in the status, which prevents the source
+// volume from being deleted until the cloning completes
+func (vcc *VolumeCloneController) updateCloneTargets(vol *longhorn.Volume, cloneTargets []string) error {
+	sort.Strings(cloneTargets)
+	if len(cloneTargets) == 0 {
+		cloneTargets = nil
+	}
+	if reflect.DeepEqual(vol.Status.CloneTargets, cloneTargets) {
+		return nil
+	}
+
+	getLoggerForVolume(vcc.logger, vol).Infof("Updating clone targets from %v to %v", vol.Status.CloneTargets, cloneTargets)
+	vol.Status.CloneTargets = cloneTargets
+	if _, err := vcc.ds.UpdateVolumeStatus(vol); err != nil {
+		return errors.Wrapf(err, "failed to update clone targets of volume %v", vol.Name)
+	}
 	return nil
 }
 
+// getSourceVolumeAttachmentNode returns the node and the frontend option the source volume of a clone is attached with.
+// The tickets of the other clones of the source are followed if there are any. A source volume used by others is
+// attached to where it's used. A detached and unused source volume is attached in maintenance mode, with the frontend
+// disabled, to a ready node holding a healthy replica of it, so the cold volumes can be cloned as well. It's detached
+// once the clones complete and the tickets are removed.
+func (vcc *VolumeCloneController) getSourceVolumeAttachmentNode(vol *longhorn.Volume, va *longhorn.VolumeAttachment) (string, string, error) {
+	for _, ticket := range va.Spec.AttachmentTickets {
+		if ticket.Type == longhorn.AttacherTypeVolumeCloneController && !vcc.isNodeDown(ticket.NodeID) &&
+			ticket.ID != longhorn.GetAttachmentTicketID(longhorn.AttacherTypeVolumeCloneController, vol.Name) {
+			return ticket.NodeID, ticket.Parameters[longhorn.AttachmentParameterDisableFrontend], nil
+		}
+	}
+
+	if vol.Spec.NodeID != "" {
+		return vol.Spec.NodeID, longhorn.AnyValue, nil
+	}
+	for _, ticket := range va.Spec.AttachmentTickets {
+		if ticket.Type != longhorn.AttacherTypeVolumeCloneController {
+			return vol.Status.OwnerID, longhorn.AnyValue, nil
+		}
+	}
+
+	replicas, err := vcc.ds.ListVolumeReplicasRO(vol.Name)
+	if err != nil {
+		return "", "", err
+	}
+	candidates := []string{}
+	for _, r := range replicas {
+		if r.Spec.NodeID == "" || r.Spec.HealthyAt == "" || r.Spec.FailedAt != "" {
+			continue
+		}
+		if vcc.isNodeDown(r.Spec.NodeID) {
+			continue
+		}
+		if isReady, err := vcc.ds.CheckDataEngineImageReadiness(vol.Spec.Image, vol.Spec.DataEngine, r.Spec.NodeID); err != nil || !isReady {
+			continue
+		}
+		if r.Spec.NodeID == vol.Status.OwnerID {
+			return r.Spec.NodeID, longhorn.TrueValue, nil
+		}
+		candidates = append(candidates, r.Spec.NodeID)
+	}
+	if len(candidates) == 0 {
+		return vol.Status.OwnerID, longhorn.TrueValue, nil
+	}
+	sort.Strings(candidates)
+	return candidates[0], longhorn.TrueValue, nil
+}
+
+func (vcc *VolumeCloneController) isNodeDown(nodeID string) bool {
+	isDown, err := vcc.ds.IsNodeDownOrDeletedOrMissingManager(nodeID)
+	return err != nil || isDown
+}
+
 func (vcc *VolumeCloneController) isResponsibleFor(vol *longhorn.Volume) bool {
 	return vcc.controllerID == vol.Status.OwnerID
 }
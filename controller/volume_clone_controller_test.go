@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+
+	. "gopkg.in/check.v1"
+)
+
+func newTestVolumeCloneController(lhClient *lhfake.Clientset, kubeClient *fake.Clientset, extensionsClient *apiextensionsfake.Clientset,
+	informerFactories *util.InformerFactories, controllerID string) (*VolumeCloneController, error) {
+	ds := datastore.NewDataStore(TestNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+
+	logger := logrus.StandardLogger()
+	vcc, err := NewVolumeCloneController(logger, ds, scheme.Scheme, kubeClient, controllerID, TestNamespace)
+	if err != nil {
+		return nil, err
+	}
+	fakeRecorder := record.NewFakeRecorder(100)
+	vcc.eventRecorder = fakeRecorder
+	for index := range vcc.cacheSyncs {
+		vcc.cacheSyncs[index] = alwaysReady
+	}
+
+	return vcc, nil
+}
+
+func newCloneTargetVolume(name, sourceVolumeName string, state longhorn.VolumeCloneState) *longhorn.Volume {
+	v := newVolume(name, 1)
+	v.Spec.DataSource = types.NewVolumeDataSourceTypeVolume(sourceVolumeName)
+	v.Status.CloneStatus.State = state
+	return v
+}
+
+func (s *TestSuite) TestVolumeCloneTargets(c *C) {
+	type testCase struct {
+		existingCloneTargets []string
+		targets              []*longhorn.Volume
+
+		expectedCloneTargets []string
+	}
+	testCases := map[string]testCase{
+		"active clones": {
+			targets: []*longhorn.Volume{
+				newCloneTargetVolume("target-2", TestVolumeName, longhorn.VolumeCloneStateInitiated),
+				newCloneTargetVolume("target-1", TestVolumeName, longhorn.VolumeCloneStateEmpty),
+				newCloneTargetVolume("target-3", TestVolumeName, longhorn.VolumeCloneStateCompleted),
+				newCloneTargetVolume("target-4", "other-volume", longhorn.VolumeCloneStateInitiated),
+			},
+			expectedCloneTargets: []string{"target-1", "target-2"},
+		},
+		"clones finished": {
+			existingCloneTargets: []string{"target-1", "target-2"},
+			targets: []*longhorn.Volume{
+				newCloneTargetVolume("target-1", TestVolumeName, longhorn.VolumeCloneStateCompleted),
+				newCloneTargetVolume("target-2", TestVolumeName, longhorn.VolumeCloneStateFailed),
+			},
+		},
+		"no clone": {},
+	}
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		lhClient := lhfake.NewSimpleClientset()
+		extensionsClient := apiextensionsfake.NewSimpleClientset()
+		informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+		vIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Volumes().Informer().GetIndexer()
+		vaIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().VolumeAttachments().Informer().GetIndexer()
+
+		vcc, err := newTestVolumeCloneController(lhClient, kubeClient, extensionsClient, informerFactories, TestOwnerID1)
+		c.Assert(err, IsNil)
+
+		source := newVolume(TestVolumeName, 1)
+		source.Spec.NodeID = TestNode1
+		source.Status.CloneTargets = tc.existingCloneTargets
+		source, err = lhClient.LonghornV1beta2().Volumes(TestNamespace).Create(context.TODO(), source, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(vIndexer.Add(source), IsNil)
+		for _, target := range tc.targets {
+			target, err = lhClient.LonghornV1beta2().Volumes(TestNamespace).Create(context.TODO(), target, metav1.CreateOptions{})
+			c.Assert(err, IsNil)
+			c.Assert(vIndexer.Add(target), IsNil)
+		}
+
+		va := newVolumeAttachment(TestVolumeName)
+		va.Spec.AttachmentTickets = map[string]*longhorn.AttachmentTicket{}
+		va, err = lhClient.LonghornV1beta2().VolumeAttachments(TestNamespace).Create(context.TODO(), va, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(vaIndexer.Add(va), IsNil)
+
+		err = vcc.reconcile(TestVolumeName)
+		c.Assert(err, IsNil)
+
+		source, err = lhClient.LonghornV1beta2().Volumes(TestNamespace).Get(context.TODO(), TestVolumeName, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(source.Status.CloneTargets, DeepEquals, tc.expectedCloneTargets)
+
+		va, err = lhClient.LonghornV1beta2().VolumeAttachments(TestNamespace).Get(context.TODO(), TestVolumeName, metav1.GetOptions{})
+		c.Assert(err, IsNil)
+		c.Assert(va.Spec.AttachmentTickets, HasLen, len(tc.expectedCloneTargets))
+		for _, target := range tc.expectedCloneTargets {
+			ticket := va.Spec.AttachmentTickets[longhorn.GetAttachmentTicketID(longhorn.AttacherTypeVolumeCloneController, target)]
+			c.Assert(ticket, NotNil)
+			c.Assert(ticket.NodeID, Equals, TestNode1)
+		}
+	}
+}
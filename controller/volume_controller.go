@@ -1,12 +1,15 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -18,8 +21,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/kubernetes/pkg/controller"
+	"k8s.io/utils/clock"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -30,10 +33,13 @@ import (
 
 	"github.com/longhorn/backupstore"
 
+	etypes "github.com/longhorn/longhorn-engine/pkg/types"
+
 	imtypes "github.com/longhorn/longhorn-instance-manager/pkg/types"
 	imutil "github.com/longhorn/longhorn-instance-manager/pkg/util"
 
 	"github.com/longhorn/longhorn-manager/constant"
+	"github.com/longhorn/longhorn-manager/csi/crypto"
 	"github.com/longhorn/longhorn-manager/datastore"
 	"github.com/longhorn/longhorn-manager/engineapi"
 	"github.com/longhorn/longhorn-manager/scheduler"
@@ -60,6 +66,15 @@ const (
 
 	initialCloneRetryInterval = 30 * time.Second
 	maxCloneRetry             = 10
+
+	replicaSchedulingRetryInterval    = 30 * time.Second
+	maxReplicaSchedulingRetryInterval = 5 * time.Minute
+
+	dataLocalityLoadAwareRetryInterval = 1 * time.Minute
+
+	filesystemTrimRetryInterval = 1 * time.Minute
+
+	volumeShardMembersRefreshInterval = 30 * time.Second
 )
 
 type VolumeController struct {
@@ -81,12 +96,21 @@ type VolumeController struct {
 
 	scheduler *scheduler.ReplicaScheduler
 
-	backoff *flowcontrol.Backoff
-
-	// for unit test
-	nowHandler func() string
+	backoff *controllerBackoff
+	// schedulingBackoff spaces the retries of the replica scheduling while it keeps failing
+	schedulingBackoff *controllerBackoff
 
 	proxyConnCounter util.Counter
+
+	// filesystemTrimmingVolumes tracks the volumes attached to this node whose filesystems are being trimmed
+	filesystemTrimLock        sync.Mutex
+	filesystemTrimmingVolumes map[string]bool
+
+	// shardMembers caches the nodes the detached volumes are sharded across. It's rebuilt once the nodes or the sharding
+	// setting change, or it's older than volumeShardMembersRefreshInterval.
+	shardMembersLock    sync.Mutex
+	shardMembers        []string
+	shardMembersBuiltAt time.Time
 }
 
 func NewVolumeController(
@@ -115,12 +139,12 @@ func NewVolumeController(
 		kubeClient:    kubeClient,
 		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-volume-controller"}),
 
-		backoff: flowcontrol.NewBackOff(time.Minute, time.Minute*3),
-
-		nowHandler: util.Now,
-
 		proxyConnCounter: proxyConnCounter,
+
+		filesystemTrimmingVolumes: map[string]bool{},
 	}
+	c.backoff = c.newBackoff("longhorn-volume/replica-reuse", time.Minute, time.Minute*3)
+	c.schedulingBackoff = c.newBackoff("longhorn-volume/replica-scheduling", replicaSchedulingRetryInterval, maxReplicaSchedulingRetryInterval)
 
 	c.scheduler = scheduler.NewReplicaScheduler(ds)
 
@@ -179,9 +203,22 @@ func NewVolumeController(
 	c.cacheSyncs = append(c.cacheSyncs, ds.BackingImageDataSourceInformer.HasSynced)
 
 	if _, err = ds.NodeInformer.AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
-		AddFunc:    c.enqueueNodeChange,
-		UpdateFunc: func(old, cur interface{}) { c.enqueueNodeChange(cur) },
-		DeleteFunc: c.enqueueNodeChange,
+		AddFunc: func(obj interface{}) {
+			c.enqueueNodeChange(obj)
+			c.invalidateShardMembers()
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			c.enqueueNodeChange(cur)
+			c.invalidateShardMembers()
+			if isNodeReadinessChanged(old, cur) {
+				c.enqueueVolumesForShardRebalance(false)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueNodeChange(obj)
+			c.invalidateShardMembers()
+			c.enqueueVolumesForShardRebalance(false)
+		},
 	}, 0); err != nil {
 		return nil, err
 	}
@@ -196,11 +233,33 @@ func NewVolumeController(
 	}, 0); err != nil {
 		return nil, err
 	}
+	if _, err = ds.SettingInformer.AddEventHandlerWithResyncPeriod(cache.FilteringResourceEventHandler{
+		FilterFunc: isSettingVolumeReconciliationSharding,
+		Handler: cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(old, cur interface{}) {
+				c.invalidateShardMembers()
+				c.enqueueVolumesForShardRebalance(true)
+			},
+		},
+	}, 0); err != nil {
+		return nil, err
+	}
 	c.cacheSyncs = append(c.cacheSyncs, ds.SettingInformer.HasSynced)
 
 	return c, nil
 }
 
+// SetClock replaces the clock of the controller and the replica scheduler
+func (c *VolumeController) SetClock(clock clock.Clock) {
+	c.baseController.SetClock(clock)
+	c.scheduler.SetClock(clock)
+}
+
+// Sync reconciles the volume of the key once, so the controller can be driven step by step in simulations
+func (c *VolumeController) Sync(key string) error {
+	return c.syncVolume(key)
+}
+
 func (c *VolumeController) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
 	defer c.queue.ShutDown()
@@ -507,6 +566,8 @@ func (c *VolumeController) syncVolume(key string) (err error) {
 		return err
 	}
 
+	c.syncVolumeIOPause(volume, engines)
+
 	if err := c.updateRecurringJobs(volume); err != nil {
 		return err
 	}
@@ -535,6 +596,14 @@ func (c *VolumeController) syncVolume(key string) (err error) {
 		return err
 	}
 
+	if err := c.reconcileSnapshotRetention(volume, engines); err != nil {
+		return err
+	}
+
+	if err := c.reconcileFilesystemTrim(volume, engines); err != nil {
+		return err
+	}
+
 	if err := c.cleanupReplicas(volume, engines, replicas); err != nil {
 		return err
 	}
@@ -675,7 +744,7 @@ func (c *VolumeController) ReconcileEngineReplicaState(v *longhorn.Volume, es ma
 					r.Name, r.Status.CurrentState, r.Spec.EngineName, r.Spec.Active, isNoAvailableBackend)
 				e.Spec.LogRequested = true
 				r.Spec.LogRequested = true
-				setReplicaFailedAt(r, c.nowHandler())
+				setReplicaFailedAt(r, c.now())
 				r.Spec.DesireState = longhorn.InstanceStateStopped
 			}
 		}
@@ -729,13 +798,13 @@ func (c *VolumeController) ReconcileEngineReplicaState(v *longhorn.Volume, es ma
 			}
 			if r.Spec.FailedAt == "" {
 				log.Warnf("Replica %v is marked as failed, current state %v, mode %v, engine name %v, active %v", r.Name, r.Status.CurrentState, mode, r.Spec.EngineName, r.Spec.Active)
-				setReplicaFailedAt(r, c.nowHandler())
+				setReplicaFailedAt(r, c.now())
 				e.Spec.LogRequested = true
 				r.Spec.LogRequested = true
 			}
 			r.Spec.DesireState = longhorn.InstanceStateStopped
 		} else if mode == longhorn.ReplicaModeRW {
-			now := c.nowHandler()
+			now := c.now()
 			if r.Spec.HealthyAt == "" {
 				c.backoff.DeleteEntry(r.Name)
 				// Set HealthyAt to distinguish this replica from one that has never been rebuilt.
@@ -766,7 +835,7 @@ func (c *VolumeController) ReconcileEngineReplicaState(v *longhorn.Volume, es ma
 				continue
 			}
 			if r.Spec.FailedAt == "" {
-				setReplicaFailedAt(r, c.nowHandler())
+				setReplicaFailedAt(r, c.now())
 				e.Spec.LogRequested = true
 				r.Spec.LogRequested = true
 				shouldLogWarning = true
@@ -786,7 +855,7 @@ func (c *VolumeController) ReconcileEngineReplicaState(v *longhorn.Volume, es ma
 				r.Name, r.Status.CurrentState, r.Spec.EngineName, r.Spec.Active)
 			e.Spec.LogRequested = true
 			r.Spec.LogRequested = true
-			setReplicaFailedAt(r, c.nowHandler())
+			setReplicaFailedAt(r, c.now())
 			r.Spec.DesireState = longhorn.InstanceStateStopped
 		}
 	}
@@ -810,13 +879,36 @@ func (c *VolumeController) ReconcileEngineReplicaState(v *longhorn.Volume, es ma
 				return err
 			}
 
+			// Migrate replicas to the disks matching the disk selector when requested
+			if v.Status.State == longhorn.VolumeStateAttached {
+				if err := c.migrateReplicasToDiskTag(v, e, rs); err != nil {
+					return err
+				}
+			}
+
+			// Move the replica to the target disk of the capacity rebalance
+			if v.Status.State == longhorn.VolumeStateAttached {
+				if err := c.moveReplicaForCapacityRebalance(v, e, rs); err != nil {
+					return err
+				}
+			}
+
 			// Migrate local replica when Data Locality is on
 			// We turn off data locality while doing auto-attaching or restoring (e.g. frontend is disabled)
 			if v.Status.State == longhorn.VolumeStateAttached && !v.Status.FrontendDisabled &&
 				isDataLocalityBestEffort(v) && !hasLocalReplicaOnSameNodeAsEngine(e, rs) {
-				if err := c.replenishReplicas(v, e, rs, e.Spec.NodeID); err != nil {
+				canMigrate, err := c.canMigrateLocalReplicaForDataLocality(v, e.Spec.NodeID)
+				if err != nil {
 					return err
 				}
+				if canMigrate {
+					if err := c.replenishReplicas(v, e, rs, e.Spec.NodeID); err != nil {
+						return err
+					}
+				} else {
+					log.Debugf("Deferred migrating the local replica to node %v since none of its disks is below the data locality load thresholds", e.Spec.NodeID)
+					c.enqueueVolumeAfter(v, dataLocalityLoadAwareRetryInterval)
+				}
 			}
 
 			setting := c.ds.GetAutoBalancedReplicasSetting(v, log)
@@ -829,7 +921,7 @@ func (c *VolumeController) ReconcileEngineReplicaState(v *longhorn.Volume, es ma
 	} else { // healthyCount < v.Spec.NumberOfReplicas
 		v.Status.Robustness = longhorn.VolumeRobustnessDegraded
 		if oldRobustness != longhorn.VolumeRobustnessDegraded {
-			v.Status.LastDegradedAt = c.nowHandler()
+			v.Status.LastDegradedAt = c.now()
 			c.eventRecorder.Eventf(v, corev1.EventTypeNormal, constant.EventReasonDegraded, "volume %v became degraded", v.Name)
 		}
 
@@ -893,6 +985,64 @@ func isAutoSalvageNeeded(rs map[string]*longhorn.Replica) bool {
 	return getHealthyAndActiveReplicaCount(rs) == 0 && getFailedReplicaCount(rs) > 0
 }
 
+// getRankedSalvageCandidates returns the salvage candidates in the order of preference: the higher revision counter,
+// the later last modified time, the more snapshots having a checksum, then the later failure.
+func getRankedSalvageCandidates(failedUsableReplicas map[string]*longhorn.Replica, salvageInfoMap map[string]*longhorn.ReplicaSalvageInfo) []longhorn.SalvageCandidate {
+	candidates := []longhorn.SalvageCandidate{}
+	for _, r := range failedUsableReplicas {
+		candidate := longhorn.SalvageCandidate{
+			ReplicaName: r.Name,
+			NodeID:      r.Spec.NodeID,
+			FailedAt:    r.Spec.FailedAt,
+		}
+		if salvageInfo := salvageInfoMap[r.Name]; salvageInfo != nil {
+			candidate.RevisionCounter = salvageInfo.RevisionCounter
+			candidate.LastModifiedAt = salvageInfo.LastModifiedAt
+			candidate.SnapshotChecksumCount = salvageInfo.SnapshotChecksumCount
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.RevisionCounter != b.RevisionCounter {
+			return a.RevisionCounter > b.RevisionCounter
+		}
+		// The timestamps are in the same RFC3339 format
+		if a.LastModifiedAt != b.LastModifiedAt {
+			return a.LastModifiedAt > b.LastModifiedAt
+		}
+		if a.SnapshotChecksumCount != b.SnapshotChecksumCount {
+			return a.SnapshotChecksumCount > b.SnapshotChecksumCount
+		}
+		if a.FailedAt != b.FailedAt {
+			return a.FailedAt > b.FailedAt
+		}
+		return a.ReplicaName < b.ReplicaName
+	})
+	return candidates
+}
+
+// getAutoSalvageReplicaNames returns the ranked candidates failed around the last failure, skipping the ones known to
+// have a lower revision counter than the top candidate. The candidates without the recorded info are kept, and the
+// engine picks the replicas to use among them.
+func getAutoSalvageReplicaNames(candidates []longhorn.SalvageCandidate, lastFailedAt time.Time) []string {
+	names := []string{}
+	var topRevisionCounter int64
+	for _, candidate := range candidates {
+		if !util.TimestampWithinLimit(lastFailedAt, candidate.FailedAt, AutoSalvageTimeLimit) {
+			continue
+		}
+		if len(names) == 0 {
+			topRevisionCounter = candidate.RevisionCounter
+		} else if candidate.RevisionCounter != 0 && candidate.RevisionCounter < topRevisionCounter {
+			continue
+		}
+		names = append(names, candidate.ReplicaName)
+	}
+	return names
+}
+
 func areAllReplicasFailed(rs map[string]*longhorn.Replica) bool {
 	for _, r := range rs {
 		if r.Spec.FailedAt == "" {
@@ -1105,6 +1255,14 @@ func (c *VolumeController) cleanupExtraHealthyReplicas(v *longhorn.Volume, e *lo
 		return err
 	}
 
+	if cleaned, err = c.cleanupDiskTagMigrationReplica(v, rs); err != nil || cleaned {
+		return err
+	}
+
+	if cleaned, err = c.cleanupCapacityRebalanceReplica(v, rs); err != nil || cleaned {
+		return err
+	}
+
 	if cleaned, err = c.cleanupDataLocalityReplicas(v, e, rs); err != nil || cleaned {
 		return err
 	}
@@ -1175,6 +1333,9 @@ func (c *VolumeController) cleanupAutoBalancedReplicas(v *longhorn.Volume, e *lo
 	var rNames []string
 	if setting == longhorn.ReplicaAutoBalanceBestEffort {
 		_, rNames, _ = c.getReplicaCountForAutoBalanceBestEffort(v, e, rs, c.getReplicaCountForAutoBalanceNode)
+		if len(rNames) == 0 {
+			_, rNames, _ = c.getReplicaCountForAutoBalanceDiskPressure(v, rs)
+		}
 		if len(rNames) == 0 {
 			_, rNames, _ = c.getReplicaCountForAutoBalanceBestEffort(v, e, rs, c.getReplicaCountForAutoBalanceZone)
 		}
@@ -1282,6 +1443,282 @@ func (c *VolumeController) syncVolumeSnapshotSetting(v *longhorn.Volume, es map[
 	return nil
 }
 
+// reconcileSnapshotRetention deletes the snapshots of the volume not kept by its snapshot retention policy, so they
+// are marked as removed and purged by the snapshot controller. It's independent of the recurring jobs, and only
+// applies to an attached volume since the purge requires a running engine.
+func (c *VolumeController) reconcileSnapshotRetention(v *longhorn.Volume, es map[string]*longhorn.Engine) error {
+	if v.Spec.SnapshotRetentionKeepLast == 0 && v.Spec.SnapshotRetentionMaxAge == "" && v.Spec.SnapshotRetentionMaxSize == 0 {
+		return nil
+	}
+	if v.Status.State != longhorn.VolumeStateAttached || len(es) != 1 {
+		return nil
+	}
+	for _, e := range es {
+		if e.Status.CurrentState != longhorn.InstanceStateRunning {
+			return nil
+		}
+	}
+
+	var err error
+	maxAge := time.Duration(0)
+	if v.Spec.SnapshotRetentionMaxAge != "" {
+		if maxAge, err = time.ParseDuration(v.Spec.SnapshotRetentionMaxAge); err != nil {
+			return errors.Wrapf(err, "invalid snapshot retention max age %v", v.Spec.SnapshotRetentionMaxAge)
+		}
+	}
+
+	disablePurge, err := c.ds.GetSettingAsBool(types.SettingNameDisableSnapshotPurge)
+	if err != nil {
+		return err
+	}
+	if disablePurge {
+		return nil
+	}
+
+	isProtected, err := getSnapshotProtectionChecker(c.ds, c.clock.Now())
+	if err != nil {
+		return err
+	}
+	snapshots, err := c.ds.ListVolumeSnapshotsRO(v.Name)
+	if err != nil {
+		return err
+	}
+
+	log := getLoggerForVolume(c.logger, v)
+	toPrune, requeueAfter := getSnapshotsToPrune(snapshots, v.Spec.SnapshotRetentionKeepLast, maxAge,
+		v.Spec.SnapshotRetentionMaxSize, c.clock.Now(), isProtected)
+	for _, snap := range toPrune {
+		log.Infof("Deleting snapshot %v since it's not kept by the snapshot retention policy of the volume", snap.Name)
+		if err := c.ds.DeleteSnapshot(snap.Name); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	if requeueAfter > 0 {
+		c.enqueueVolumeAfter(v, requeueAfter)
+	}
+	return nil
+}
+
+// reconcileFilesystemTrim periodically trims the filesystem of the volume attached to this node, independently of the
+// recurring jobs, if the auto filesystem trim is enabled. Since the trim goes through the snapshots marked as removed
+// only if the snapshots are removed during the trim, the volume is trimmed only then and once the removed snapshots take
+// more space than the threshold. The trim runs in the background the same way as the manual trim, limited per node and
+// bounded by a deadline.
+func (c *VolumeController) reconcileFilesystemTrim(v *longhorn.Volume, es map[string]*longhorn.Engine) error {
+	enabled, err := c.ds.GetSettingAsBool(types.SettingNameAutoFilesystemTrim)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+
+	if v.Status.State != longhorn.VolumeStateAttached || v.Status.FrontendDisabled || v.Status.CurrentNodeID != c.controllerID || len(es) != 1 {
+		return nil
+	}
+	// The block volumes don't have a filesystem to trim
+	if v.Spec.AccessMode == longhorn.AccessModeReadWriteMany && !isRegularRWXVolume(v) {
+		return nil
+	}
+	// Same as the manual trim, the degraded v2 volume is not trimmed to keep the volume head size reliable for the
+	// failed usable replica candidate selection
+	if types.IsDataEngineV2(v.Spec.DataEngine) && v.Status.Robustness == longhorn.VolumeRobustnessDegraded {
+		return nil
+	}
+	e, err := c.ds.PickVolumeCurrentEngine(v, es)
+	if err != nil {
+		return err
+	}
+	if e == nil || e.Status.CurrentState != longhorn.InstanceStateRunning {
+		return nil
+	}
+
+	unmapMarkEnabled, err := c.isUnmapMarkSnapChainRemovedEnabled(v)
+	if err != nil {
+		return err
+	}
+	if !unmapMarkEnabled {
+		return nil
+	}
+
+	interval, err := c.ds.GetSettingAsInt(types.SettingNameAutoFilesystemTrimInterval)
+	if err != nil {
+		return err
+	}
+	if v.Status.LastFilesystemTrimAt != "" {
+		lastTrimAt, err := util.ParseTime(v.Status.LastFilesystemTrimAt)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse the last filesystem trim time %v", v.Status.LastFilesystemTrimAt)
+		}
+		if waitDuration := lastTrimAt.Add(time.Duration(interval) * time.Minute).Sub(c.clock.Now()); waitDuration > 0 {
+			c.enqueueVolumeAfter(v, waitDuration)
+			return nil
+		}
+	}
+
+	threshold, err := c.ds.GetSettingAsInt(types.SettingNameAutoFilesystemTrimDeletedSpaceThreshold)
+	if err != nil {
+		return err
+	}
+	deletedSpace := getFilesystemTrimDeletedSpace(e)
+	if deletedSpace < threshold*util.MiB {
+		return nil
+	}
+
+	limit, err := c.ds.GetSettingAsInt(types.SettingNameConcurrentAutoFilesystemTrimPerNodeLimit)
+	if err != nil {
+		return err
+	}
+	acquired, trimming := c.acquireFilesystemTrimSlot(v.Name, int(limit))
+	if trimming {
+		return nil
+	}
+	if !acquired {
+		c.enqueueVolumeAfter(v, filesystemTrimRetryInterval)
+		return nil
+	}
+
+	// Record the trim when it starts, so a failed trim is not retried until the next interval
+	v.Status.LastFilesystemTrimAt = util.Now()
+
+	log := getLoggerForVolume(c.logger, v)
+	log.Infof("Trimming filesystem automatically since the snapshots marked as removed take %v bytes", deletedSpace)
+	go func(v *longhorn.Volume) {
+		defer c.releaseFilesystemTrimSlot(v.Name)
+
+		// Same as the manual trim, so the trim slot is released once the deadline is reached even if the trim hangs
+		ctx, cancel := context.WithTimeout(context.Background(), engineapi.FilesystemTrimTimeout)
+		defer cancel()
+		if err := engineapi.TrimVolumeFilesystem(ctx, c.ds, v); err != nil {
+			log.WithError(err).Warn("Failed to trim filesystem automatically")
+			c.eventRecorder.Eventf(v, corev1.EventTypeWarning, constant.EventReasonFailedFilesystemTrim,
+				"Failed to trim filesystem automatically: %v", err)
+			return
+		}
+		c.eventRecorder.Eventf(v, corev1.EventTypeNormal, constant.EventReasonFilesystemTrimmed,
+			"Trimmed filesystem automatically to reclaim the space of %v bytes of the snapshots marked as removed", deletedSpace)
+	}(v.DeepCopy())
+
+	return nil
+}
+
+// getFilesystemTrimDeletedSpace returns the space taken by the snapshots marked as removed, which the filesystem trim
+// can reclaim
+func getFilesystemTrimDeletedSpace(e *longhorn.Engine) int64 {
+	deletedSpace := int64(0)
+	for name, snapshot := range e.Status.Snapshots {
+		if name == etypes.VolumeHeadName || !snapshot.Removed {
+			continue
+		}
+		size, err := util.ConvertSize(snapshot.Size)
+		if err != nil {
+			continue
+		}
+		deletedSpace += size
+	}
+	return deletedSpace
+}
+
+// acquireFilesystemTrimSlot returns whether the volume can be trimmed without exceeding the limit of the volumes
+// trimmed at the same time on the node, and whether the volume is being trimmed already.
+func (c *VolumeController) acquireFilesystemTrimSlot(volumeName string, limit int) (acquired, trimming bool) {
+	c.filesystemTrimLock.Lock()
+	defer c.filesystemTrimLock.Unlock()
+
+	if c.filesystemTrimmingVolumes[volumeName] {
+		return false, true
+	}
+	if len(c.filesystemTrimmingVolumes) >= limit {
+		return false, false
+	}
+	c.filesystemTrimmingVolumes[volumeName] = true
+	return true, false
+}
+
+func (c *VolumeController) releaseFilesystemTrimSlot(volumeName string) {
+	c.filesystemTrimLock.Lock()
+	defer c.filesystemTrimLock.Unlock()
+
+	delete(c.filesystemTrimmingVolumes, volumeName)
+}
+
+// getSnapshotsToPrune returns the snapshots not kept by the snapshot retention policy, from the newest to the
+// oldest, and the duration after which the policy should be checked again, 0 if there is no need. A snapshot is
+// kept if it's one of the newest keepLast snapshots, not older than maxAge, and the total size of it and the newer
+// snapshots does not exceed maxSize. The policy of the zero value is not applied. The parent of the volume head and
+// the protected snapshots are never pruned, but they are still counted.
+func getSnapshotsToPrune(snapshots map[string]*longhorn.Snapshot, keepLast int, maxAge time.Duration, maxSize int64,
+	now time.Time, isProtected func(*longhorn.Snapshot) bool) ([]*longhorn.Snapshot, time.Duration) {
+	type snapshotInfo struct {
+		snapshot     *longhorn.Snapshot
+		creationTime time.Time
+	}
+
+	infos := []snapshotInfo{}
+	for _, snap := range snapshots {
+		if snap.Status.MarkRemoved || !snap.DeletionTimestamp.IsZero() {
+			continue
+		}
+		creationTime, err := time.Parse(time.RFC3339, snap.Status.CreationTime)
+		if err != nil {
+			continue
+		}
+		infos = append(infos, snapshotInfo{snapshot: snap, creationTime: creationTime})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].creationTime.Equal(infos[j].creationTime) {
+			return infos[i].creationTime.After(infos[j].creationTime)
+		}
+		return infos[i].snapshot.Name > infos[j].snapshot.Name
+	})
+
+	toPrune := []*longhorn.Snapshot{}
+	requeueAfter := time.Duration(0)
+	setRequeueAfter := func(d time.Duration) {
+		if requeueAfter == 0 || d < requeueAfter {
+			requeueAfter = d
+		}
+	}
+
+	totalSize := int64(0)
+	for i, info := range infos {
+		totalSize += info.snapshot.Status.Size
+		age := now.Sub(info.creationTime)
+
+		expired := (keepLast > 0 && i >= keepLast) ||
+			(maxAge > 0 && age > maxAge) ||
+			(maxSize > 0 && totalSize > maxSize)
+		if !expired {
+			if maxAge > 0 {
+				setRequeueAfter(maxAge - age + time.Second)
+			}
+			continue
+		}
+
+		if _, ok := info.snapshot.Status.Children["volume-head"]; ok {
+			continue
+		}
+		if isProtected(info.snapshot) {
+			setRequeueAfter(time.Minute)
+			continue
+		}
+		toPrune = append(toPrune, info.snapshot)
+	}
+	return toPrune, requeueAfter
+}
+
+// syncVolumeIOPause propagates the IO pause deadline of the volume to the current engine, which pauses the IO until
+// the deadline. The other engines, e.g. the one of the migration target, are never paused.
+func (c *VolumeController) syncVolumeIOPause(v *longhorn.Volume, es map[string]*longhorn.Engine) {
+	for _, e := range es {
+		if e.Spec.NodeID == v.Status.CurrentNodeID && v.Spec.IOPauseDeadline != "" {
+			e.Spec.IOPauseDeadline = v.Spec.IOPauseDeadline
+		} else {
+			e.Spec.IOPauseDeadline = ""
+		}
+	}
+}
+
 // ReconcileVolumeState handles the attaching and detaching of volume
 func (c *VolumeController) ReconcileVolumeState(v *longhorn.Volume, es map[string]*longhorn.Engine, rs map[string]*longhorn.Replica) (err error) {
 	defer func() {
@@ -1341,6 +1778,17 @@ func (c *VolumeController) ReconcileVolumeState(v *longhorn.Volume, es map[strin
 		e.Spec.SalvageRequested = false
 	}
 
+	// Clear RebindRequestedAt flag once the lost engine runs again. The frontend device of the engine is new, so the
+	// workloads need to remount the volume, which restarts their pods the same as the reattachment does.
+	if e.Spec.RebindRequestedAt != "" && e.Status.CurrentState == longhorn.InstanceStateRunning {
+		e.Spec.RebindRequestedAt = ""
+		c.eventRecorder.Eventf(v, corev1.EventTypeNormal, constant.EventReasonRebound,
+			"Engine %v of volume %v is running again after the restart of its instance manager", e.Name, v.Name)
+		v.Status.RemountRequestedAt = c.now()
+		msg := fmt.Sprintf("Volume %v requested remount at %v after rebinding the engine", v.Name, v.Status.RemountRequestedAt)
+		c.eventRecorder.Eventf(v, corev1.EventTypeNormal, constant.EventReasonRemount, msg)
+	}
+
 	if isAutoSalvageNeeded(rs) {
 		v.Status.Robustness = longhorn.VolumeRobustnessFaulted
 		// If the volume is faulted, we don't need to have RWX fast failover.
@@ -1369,8 +1817,10 @@ func (c *VolumeController) ReconcileVolumeState(v *longhorn.Volume, es map[strin
 			log.Infof("All replicas are failed, set engine salvageRequested to %v", e.Spec.SalvageRequested)
 		}
 		// make sure the volume is detached before automatically salvage replicas
-		if autoSalvage && v.Status.State == longhorn.VolumeStateDetached && !v.Status.IsStandby && !v.Status.RestoreRequired {
-			log.Info("All replicas are failed, auto-salvaging volume")
+		if v.Status.State == longhorn.VolumeStateDetached && !v.Status.IsStandby && !v.Status.RestoreRequired {
+			if autoSalvage {
+				log.Info("All replicas are failed, auto-salvaging volume")
+			}
 
 			lastFailedAt := time.Time{}
 			failedUsableReplicas := map[string]*longhorn.Replica{}
@@ -1393,6 +1843,7 @@ func (c *VolumeController) ReconcileVolumeState(v *longhorn.Volume, es map[strin
 				node, err := c.ds.GetNodeRO(r.Spec.NodeID)
 				if err != nil {
 					log.WithField("replica", r.Name).WithError(err).Warnf("Failed to get node %v for failed replica", r.Spec.NodeID)
+					continue
 				}
 				diskSchedulable := false
 				for _, diskStatus := range node.Status.DiskStatus {
@@ -1417,26 +1868,30 @@ func (c *VolumeController) ReconcileVolumeState(v *longhorn.Volume, es map[strin
 				// all failedUsableReplica contains data
 				failedUsableReplicas[r.Name] = r
 			}
-			if !dataExists {
+			// The candidates are recorded even if auto salvage is disabled, so that one can be picked manually
+			v.Status.SalvageCandidates = getRankedSalvageCandidates(failedUsableReplicas, e.Status.ReplicaSalvageInfoMap)
+
+			if !autoSalvage {
+				v.Status.SalvageDecision = "Auto salvage is disabled, waiting for a salvage candidate to be picked"
+			} else if !dataExists {
 				log.Warn("Failed to auto salvage volume: no data exists")
 			} else {
-				log.Infof("Bringing up %v replicas for auto-salvage", len(failedUsableReplicas))
+				salvageReplicaNames := getAutoSalvageReplicaNames(v.Status.SalvageCandidates, lastFailedAt)
+				log.Infof("Bringing up %v of %v replicas for auto-salvage", len(salvageReplicaNames), len(failedUsableReplicas))
 
-				// This salvage is for revision counter enabled case
-				salvaged := false
 				// Bring up the replicas for auto-salvage
-				for _, r := range failedUsableReplicas {
-					if util.TimestampWithinLimit(lastFailedAt, r.Spec.FailedAt, AutoSalvageTimeLimit) {
-						setReplicaFailedAt(r, "")
-						log.WithField("replica", r.Name).Warn("Automatically salvaging volume replica")
-						msg := fmt.Sprintf("Replica %v of volume %v will be automatically salvaged", r.Name, v.Name)
-						c.eventRecorder.Event(v, corev1.EventTypeWarning, constant.EventReasonAutoSalvaged, msg)
-						salvaged = true
-					}
+				for _, name := range salvageReplicaNames {
+					r := failedUsableReplicas[name]
+					setReplicaFailedAt(r, "")
+					log.WithField("replica", r.Name).Warn("Automatically salvaging volume replica")
+					msg := fmt.Sprintf("Replica %v of volume %v will be automatically salvaged", r.Name, v.Name)
+					c.eventRecorder.Event(v, corev1.EventTypeWarning, constant.EventReasonAutoSalvaged, msg)
 				}
-				if salvaged {
+				if len(salvageReplicaNames) > 0 {
+					v.Status.SalvageDecision = fmt.Sprintf("Replicas %v were automatically salvaged at %v, ranked first among %v candidates by the revision counter, the last modified time and the snapshot checksum count",
+						salvageReplicaNames, c.now(), len(v.Status.SalvageCandidates))
 					// remount the reattached volume later if possible
-					v.Status.RemountRequestedAt = c.nowHandler()
+					v.Status.RemountRequestedAt = c.now()
 					msg := fmt.Sprintf("Volume %v requested remount at %v after automatically salvaging replicas", v.Name, v.Status.RemountRequestedAt)
 					c.eventRecorder.Eventf(v, corev1.EventTypeNormal, constant.EventReasonRemount, msg)
 					v.Status.Robustness = longhorn.VolumeRobustnessUnknown
@@ -1445,11 +1900,12 @@ func (c *VolumeController) ReconcileVolumeState(v *longhorn.Volume, es map[strin
 			}
 		}
 	} else { // !isAutoSalvageNeeded
+		v.Status.SalvageCandidates = nil
 		if v.Status.Robustness == longhorn.VolumeRobustnessFaulted && v.Status.State == longhorn.VolumeStateDetached {
 			v.Status.Robustness = longhorn.VolumeRobustnessUnknown
 			// The volume was faulty and there are usable replicas.
 			// Therefore, we set RemountRequestedAt so that KubernetesPodController restarts the workload pod
-			v.Status.RemountRequestedAt = c.nowHandler()
+			v.Status.RemountRequestedAt = c.now()
 			msg := fmt.Sprintf("Volume %v requested remount at %v", v.Name, v.Status.RemountRequestedAt)
 			c.eventRecorder.Eventf(v, corev1.EventTypeNormal, constant.EventReasonRemount, msg)
 			return nil
@@ -1459,7 +1915,11 @@ func (c *VolumeController) ReconcileVolumeState(v *longhorn.Volume, es map[strin
 		// - volume is detached unexpectedly and there are still healthy replicas
 		// - engine dead unexpectedly and there are still healthy replicas when the volume is not attached
 		if e.Status.CurrentState == longhorn.InstanceStateError {
-			if v.Status.CurrentNodeID != "" || (v.Spec.NodeID != "" && v.Status.CurrentNodeID == "" && v.Status.State != longhorn.VolumeStateAttached) {
+			isRebinding, err := c.reconcileEngineRebind(v, e, rs, log)
+			if err != nil {
+				return err
+			}
+			if !isRebinding && (v.Status.CurrentNodeID != "" || (v.Spec.NodeID != "" && v.Status.CurrentNodeID == "" && v.Status.State != longhorn.VolumeStateAttached)) {
 				log.Warn("Reattaching the volume since engine of volume dead unexpectedly")
 				msg := fmt.Sprintf("Engine of volume %v dead unexpectedly, reattach the volume", v.Name)
 				c.eventRecorder.Event(v, corev1.EventTypeWarning, constant.EventReasonDetachedUnexpectedly, msg)
@@ -1503,11 +1963,123 @@ func (c *VolumeController) ReconcileVolumeState(v *longhorn.Volume, es map[strin
 			v.Status.ExpansionRequired = false
 			v.Status.FrontendDisabled = false
 		}
+
+		purgeRequested, err := c.isSnapshotPurgeRequested(v, e)
+		if err != nil {
+			return err
+		}
+		v.Status.QueuedOperations = getQueuedVolumeOperations(e, purgeRequested)
+	} else {
+		v.Status.QueuedOperations = nil
 	}
 
 	return c.checkAndFinishVolumeRestore(v, e, rs)
 }
 
+// isSnapshotPurgeRequested returns true if a snapshot of the volume is being deleted but not removed from the engine yet
+func (c *VolumeController) isSnapshotPurgeRequested(v *longhorn.Volume, e *longhorn.Engine) (bool, error) {
+	snapshots, err := c.ds.ListVolumeSnapshotsRO(v.Name)
+	if err != nil {
+		return false, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.DeletionTimestamp == nil {
+			continue
+		}
+		if _, ok := e.Status.Snapshots[snapshot.Name]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reconcileEngineRebind returns true if the engine of the attached volume, lost with the restart of its instance
+// manager, is going to be started again against the same replicas. Then the volume doesn't need to be detached and
+// reattached. The rebinding is given up once the restarted instance manager doesn't run again within the timeout.
+func (c *VolumeController) reconcileEngineRebind(v *longhorn.Volume, e *longhorn.Engine, rs map[string]*longhorn.Replica, log *logrus.Entry) (bool, error) {
+	timeout, err := c.ds.GetSettingAsInt(types.SettingNameEngineRebindTimeout)
+	if err != nil {
+		return false, err
+	}
+	isRebindable := false
+	if timeout > 0 {
+		if isRebindable, err = c.isEngineRebindable(v, e, rs); err != nil {
+			return false, err
+		}
+	}
+	if !isRebindable {
+		e.Spec.RebindRequestedAt = ""
+		return false, nil
+	}
+
+	if e.Spec.RebindRequestedAt == "" {
+		e.Spec.RebindRequestedAt = c.now()
+		log.Warnf("Engine was lost with the restart of its instance manager, waiting %v seconds to start it again against the same replicas", timeout)
+		c.eventRecorder.Eventf(v, corev1.EventTypeWarning, constant.EventReasonRebinding,
+			"Engine %v of volume %v was lost with the restart of its instance manager, starting it again against the same replicas", e.Name, v.Name)
+		c.enqueueVolumeAfter(v, time.Duration(timeout)*time.Second)
+		return true, nil
+	}
+
+	requestedAt, err := util.ParseTime(e.Spec.RebindRequestedAt)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse the rebind requested time %v of engine %v", e.Spec.RebindRequestedAt, e.Name)
+	}
+	if remaining := requestedAt.Add(time.Duration(timeout) * time.Second).Sub(c.clock.Now()); remaining > 0 {
+		c.enqueueVolumeAfter(v, remaining)
+		return true, nil
+	}
+
+	log.Warnf("Engine isn't started again within %v seconds after the restart of its instance manager, detaching the volume", timeout)
+	e.Spec.RebindRequestedAt = ""
+	return false, nil
+}
+
+// isEngineRebindable returns true if the engine of the attached volume was lost with the restart of its instance
+// manager and there is still a healthy replica outside of the instance manager to start it again against.
+func (c *VolumeController) isEngineRebindable(v *longhorn.Volume, e *longhorn.Engine, rs map[string]*longhorn.Replica) (bool, error) {
+	if !types.IsDataEngineV1(v.Spec.DataEngine) || util.IsVolumeMigrating(v) ||
+		v.Status.State != longhorn.VolumeStateAttached || v.Status.CurrentNodeID == "" ||
+		v.Spec.NodeID != v.Status.CurrentNodeID || e.Spec.NodeID != v.Status.CurrentNodeID ||
+		e.Spec.DesireState != longhorn.InstanceStateRunning || !e.Status.Started {
+		return false, nil
+	}
+
+	isDownOrDeleted, err := c.ds.IsNodeDownOrDeleted(e.Spec.NodeID)
+	if err != nil {
+		return false, err
+	}
+	if isDownOrDeleted {
+		return false, nil
+	}
+
+	im, err := c.ds.GetInstanceManagerByInstanceRO(e)
+	if err != nil {
+		if datastore.ErrorIsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if im.DeletionTimestamp != nil {
+		return false, nil
+	}
+	// The engine crashed instead of being lost if the running instance manager still has its process
+	if im.Status.CurrentState == longhorn.InstanceManagerStateRunning {
+		instances := types.ConsolidateInstances(im.Status.InstanceEngines, im.Status.Instances) // nolint: staticcheck
+		if _, exists := instances[e.Name]; exists {
+			return false, nil
+		}
+	}
+
+	for _, r := range rs {
+		if r.Spec.FailedAt == "" && r.Spec.HealthyAt != "" && r.Spec.NodeID != e.Spec.NodeID &&
+			r.Status.CurrentState == longhorn.InstanceStateRunning {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (c *VolumeController) handleDelinquentAndStaleStateForFaultedRWXVolume(v *longhorn.Volume) error {
 	if !isRegularRWXVolume(v) {
 		return nil
@@ -1663,7 +2235,8 @@ func (c *VolumeController) reconcileAttachDetachStateMachine(v *longhorn.Volume,
 						c.eventRecorder.Eventf(v, corev1.EventTypeNormal, constant.EventReasonDetached, "volume %v has been detached", v.Name)
 					}
 				case longhorn.VolumeStateAttached:
-					if v.Spec.Migratable && v.Spec.AccessMode == longhorn.AccessModeReadWriteMany && v.Status.CurrentMigrationNodeID != "" {
+					// the migratable RWX volumes and the live migrated RWO volumes
+					if v.Status.CurrentMigrationNodeID != "" {
 						if err := c.openVolumeDependentResources(v, e, rs, log); err != nil {
 							return err
 						}
@@ -1723,6 +2296,76 @@ func (c *VolumeController) reconcileLogRequest(e *longhorn.Engine, rs map[string
 	}
 }
 
+// reconcileFIPSNonCompliantCondition sets the condition FIPSNonCompliant of an encrypted volume if FIPS mode is
+// enabled and the encryption parameters in its secret are not approved by FIPS 140-3.
+func (c *VolumeController) reconcileFIPSNonCompliantCondition(v *longhorn.Volume) error {
+	if !v.Spec.Encrypted {
+		return nil
+	}
+
+	fipsModeEnabled, err := c.ds.GetSettingAsBool(types.SettingNameFIPSModeEnabled)
+	if err != nil {
+		return err
+	}
+	if !fipsModeEnabled {
+		v.Status.Conditions = types.SetCondition(v.Status.Conditions,
+			longhorn.VolumeConditionTypeFIPSNonCompliant, longhorn.ConditionStatusFalse, "", "")
+		return nil
+	}
+
+	secret, err := c.ds.GetVolumeEncryptionSecretRO(v)
+	if err != nil {
+		return err
+	}
+	cryptoParams := crypto.NewEncryptParams(
+		string(secret.Data[types.CryptoKeyProvider]),
+		string(secret.Data[types.CryptoKeyCipher]),
+		string(secret.Data[types.CryptoKeyHash]),
+		string(secret.Data[types.CryptoKeySize]),
+		string(secret.Data[types.CryptoPBKDF]))
+	if err := cryptoParams.ValidateFIPS(); err != nil {
+		v.Status.Conditions = types.SetCondition(v.Status.Conditions,
+			longhorn.VolumeConditionTypeFIPSNonCompliant, longhorn.ConditionStatusTrue,
+			longhorn.VolumeConditionReasonFIPSNonCompliantEncryption,
+			fmt.Sprintf("Encryption parameters in secret %v/%v are not FIPS compliant: %v", secret.Namespace, secret.Name, err))
+		return nil
+	}
+	v.Status.Conditions = types.SetCondition(v.Status.Conditions,
+		longhorn.VolumeConditionTypeFIPSNonCompliant, longhorn.ConditionStatusFalse, "", "")
+	return nil
+}
+
+// getSnapshotQuotaExceededReason returns the reason and the message of the SnapshotQuotaExceeded condition if the
+// snapshots of the engine reach the snapshot max count or the snapshot max size of the volume, or empty strings
+// otherwise
+func getSnapshotQuotaExceededReason(v *longhorn.Volume, e *longhorn.Engine) (string, string) {
+	count, size := getSnapshotUsage(e)
+	if v.Spec.SnapshotMaxCount > 0 && count >= v.Spec.SnapshotMaxCount {
+		return longhorn.VolumeConditionReasonSnapshotCountQuotaExceeded,
+			fmt.Sprintf("Snapshots count %v reaches the snapshot max count %v", count, v.Spec.SnapshotMaxCount)
+	}
+	if v.Spec.SnapshotMaxSize > 0 && size >= v.Spec.SnapshotMaxSize {
+		return longhorn.VolumeConditionReasonSnapshotSizeQuotaExceeded,
+			fmt.Sprintf("Snapshots size %v reaches the snapshot max size %v", size, v.Spec.SnapshotMaxSize)
+	}
+	return "", ""
+}
+
+// getSnapshotUsage returns the number and the total size of the snapshots of the engine, not counting the volume
+// head and the removed snapshots
+func getSnapshotUsage(e *longhorn.Engine) (count int, size int64) {
+	for name, snapshot := range e.Status.Snapshots {
+		if snapshot == nil || snapshot.Removed || name == etypes.VolumeHeadName {
+			continue
+		}
+		count++
+		if snapshotSize, err := strconv.ParseInt(snapshot.Size, 10, 64); err == nil {
+			size += snapshotSize
+		}
+	}
+	return count, size
+}
+
 func (c *VolumeController) reconcileVolumeCondition(v *longhorn.Volume, e *longhorn.Engine,
 	rs map[string]*longhorn.Replica, log *logrus.Entry) error {
 	numSnapshots := len(e.Status.Snapshots) - 1 // Counting volume-head here would be confusing.
@@ -1738,8 +2381,23 @@ func (c *VolumeController) reconcileVolumeCondition(v *longhorn.Volume, e *longh
 			"", "")
 	}
 
+	if reason, message := getSnapshotQuotaExceededReason(v, e); reason != "" {
+		v.Status.Conditions = types.SetConditionAndRecord(v.Status.Conditions,
+			longhorn.VolumeConditionTypeSnapshotQuotaExceeded, longhorn.ConditionStatusTrue,
+			reason, message, c.eventRecorder, v, corev1.EventTypeWarning)
+	} else {
+		v.Status.Conditions = types.SetConditionAndRecord(v.Status.Conditions,
+			longhorn.VolumeConditionTypeSnapshotQuotaExceeded, longhorn.ConditionStatusFalse,
+			"", "", c.eventRecorder, v, corev1.EventTypeNormal)
+	}
+
+	if err := c.reconcileFIPSNonCompliantCondition(v); err != nil {
+		log.WithError(err).Warn("Failed to check FIPS compliance of volume encryption")
+	}
+
 	scheduled := true
 	aggregatedReplicaScheduledError := util.NewMultiError()
+	replicaSchedulingFailures := []longhorn.ReplicaSchedulingFailure{}
 	for _, r := range rs {
 		// check whether the replica need to be scheduled
 		if r.Spec.NodeID != "" {
@@ -1752,7 +2410,8 @@ func (c *VolumeController) reconcileVolumeCondition(v *longhorn.Volume, e *longh
 
 			r.Spec.HardNodeAffinity = v.Spec.NodeID
 		}
-		scheduledReplica, multiError, err := c.scheduler.ScheduleReplica(r, rs, v)
+		failure := longhorn.ReplicaSchedulingFailure{Replica: r.Name}
+		scheduledReplica, multiError, err := c.scheduler.ScheduleReplica(r, rs, v, &failure)
 		if err != nil {
 			return err
 		}
@@ -1770,13 +2429,22 @@ func (c *VolumeController) reconcileVolumeCondition(v *longhorn.Volume, e *longh
 					longhorn.VolumeConditionTypeScheduled, longhorn.ConditionStatusFalse,
 					longhorn.VolumeConditionReasonLocalReplicaSchedulingFailure, "")
 			}
+			replicaSchedulingFailures = append(replicaSchedulingFailures, failure)
 			scheduled = false
-			// requeue the volume to retry to schedule the replica after 30s
-			c.enqueueVolumeAfter(v, 30*time.Second)
 		} else {
 			rs[r.Name] = scheduledReplica
 		}
 	}
+	if len(replicaSchedulingFailures) > 0 {
+		// requeue the volume to retry to schedule the replicas, and back off further once the retry fails again
+		now := c.clock.Now()
+		if !c.schedulingBackoff.IsInBackOffSinceUpdate(v.Name, now) {
+			c.schedulingBackoff.Next(v.Name, now)
+		}
+		c.enqueueVolumeAfter(v, c.schedulingBackoff.Get(v.Name))
+	} else {
+		c.schedulingBackoff.DeleteEntry(v.Name)
+	}
 
 	failureMessage := ""
 
@@ -1784,7 +2452,7 @@ func (c *VolumeController) reconcileVolumeCondition(v *longhorn.Volume, e *longh
 		scheduled = false
 	}
 
-	replenishCount, _ := c.getReplenishReplicasCount(v, rs, e)
+	replenishCount, _, _ := c.getReplenishReplicasCount(v, rs, e)
 	if scheduled && replenishCount == 0 {
 		v.Status.Conditions = types.SetCondition(v.Status.Conditions,
 			longhorn.VolumeConditionTypeScheduled, longhorn.ConditionStatusTrue, "", "")
@@ -1824,6 +2492,15 @@ func (c *VolumeController) reconcileVolumeCondition(v *longhorn.Volume, e *longh
 				longhorn.VolumeConditionTypeScheduled, longhorn.ConditionStatusFalse,
 				scheduledCondition.Reason, failureMessage)
 		}
+		// Keep the failure of the new replica recorded by the replenishment if there is no unscheduled replica
+		if len(replicaSchedulingFailures) > 0 {
+			sort.Slice(replicaSchedulingFailures, func(i, j int) bool {
+				return replicaSchedulingFailures[i].Replica < replicaSchedulingFailures[j].Replica
+			})
+			v.Status.ReplicaSchedulingFailures = replicaSchedulingFailures
+		}
+	} else {
+		v.Status.ReplicaSchedulingFailures = nil
 	}
 
 	if err := c.ds.UpdatePVAnnotation(v, types.PVAnnotationLonghornVolumeSchedulingError, failureMessage); err != nil {
@@ -1878,7 +2555,7 @@ func (c *VolumeController) openVolumeDependentResources(v *longhorn.Volume, e *l
 				}
 				log.WithField("replica", r.Name).Warn(msg)
 				if r.Spec.FailedAt == "" {
-					setReplicaFailedAt(r, c.nowHandler())
+					setReplicaFailedAt(r, c.now())
 				}
 				r.Spec.DesireState = longhorn.InstanceStateStopped
 			}
@@ -2001,7 +2678,7 @@ func (c *VolumeController) closeVolumeDependentResources(v *longhorn.Volume, e *
 	for _, r := range rs {
 		if r.Spec.HealthyAt == "" && r.Spec.FailedAt == "" && dataExists {
 			// This replica must have been rebuilding. Mark it as failed.
-			setReplicaFailedAt(r, c.nowHandler())
+			setReplicaFailedAt(r, c.now())
 			// Unscheduled replicas are marked failed here when volume is detached.
 			// Check if NodeId or DiskID is empty to avoid deleting reusableFailedReplica when replenished.
 			if r.Spec.NodeID == "" || r.Spec.DiskID == "" {
@@ -2220,7 +2897,49 @@ func findValueWithBiggestLength(m map[string][]string) []string {
 }
 
 func isDataLocalityBestEffort(v *longhorn.Volume) bool {
-	return v.Spec.DataLocality == longhorn.DataLocalityBestEffort
+	return v.Spec.DataLocality == longhorn.DataLocalityBestEffort || v.Spec.DataLocality == longhorn.DataLocalityBestEffortLoadAware
+}
+
+// canMigrateLocalReplicaForDataLocality returns false if the volume is in the load-aware data locality mode and
+// none of the disks on the node is below both the disk usage and the IO utilization thresholds.
+func (c *VolumeController) canMigrateLocalReplicaForDataLocality(v *longhorn.Volume, nodeID string) (bool, error) {
+	if v.Spec.DataLocality != longhorn.DataLocalityBestEffortLoadAware {
+		return true, nil
+	}
+
+	usageThreshold, err := c.ds.GetSettingAsInt(types.SettingNameDataLocalityLoadAwareDiskUsageThreshold)
+	if err != nil {
+		return false, err
+	}
+	ioUtilizationThreshold, err := c.ds.GetSettingAsInt(types.SettingNameDataLocalityLoadAwareIOUtilizationThreshold)
+	if err != nil {
+		return false, err
+	}
+	node, err := c.ds.GetNodeRO(nodeID)
+	if err != nil {
+		return false, err
+	}
+	return hasDiskBelowDataLocalityLoadThresholds(node, v.Spec.DataEngine, usageThreshold, ioUtilizationThreshold), nil
+}
+
+func hasDiskBelowDataLocalityLoadThresholds(node *longhorn.Node, dataEngine longhorn.DataEngineType, usageThreshold, ioUtilizationThreshold int64) bool {
+	for diskName, diskStatus := range node.Status.DiskStatus {
+		diskSpec, exists := node.Spec.Disks[diskName]
+		if !exists || !diskSpec.AllowScheduling || util.GetDataEngineForDiskType(diskSpec.Type) != dataEngine {
+			continue
+		}
+		if types.GetCondition(diskStatus.Conditions, longhorn.DiskConditionTypeSchedulable).Status != longhorn.ConditionStatusTrue {
+			continue
+		}
+		if diskStatus.StorageMaximum <= 0 {
+			continue
+		}
+		usage := (diskStatus.StorageMaximum - diskStatus.StorageAvailable) * 100 / diskStatus.StorageMaximum
+		if usage < usageThreshold && int64(diskStatus.IOUtilization) < ioUtilizationThreshold {
+			return true
+		}
+	}
+	return false
 }
 
 func isDataLocalityDisabled(v *longhorn.Volume) bool {
@@ -2282,7 +3001,7 @@ func (c *VolumeController) replenishReplicas(v *longhorn.Volume, e *longhorn.Eng
 
 	log := getLoggerForVolume(c.logger, v)
 
-	replenishCount, updateNodeAffinity := c.getReplenishReplicasCount(v, rs, e)
+	replenishCount, updateNodeAffinity, diskPressureReplica := c.getReplenishReplicasCount(v, rs, e)
 	if hardNodeAffinity == "" && updateNodeAffinity != "" {
 		hardNodeAffinity = updateNodeAffinity
 	}
@@ -2300,7 +3019,7 @@ func (c *VolumeController) replenishReplicas(v *longhorn.Volume, e *longhorn.Eng
 		}
 
 		if reusableFailedReplica != nil {
-			if !c.backoff.IsInBackOffSinceUpdate(reusableFailedReplica.Name, time.Now()) {
+			if !c.backoff.IsInBackOffSinceUpdate(reusableFailedReplica.Name, c.clock.Now()) {
 				log.Infof("Failed replica %v will be reused during rebuilding", reusableFailedReplica.Name)
 				setReplicaFailedAt(reusableFailedReplica, "")
 				reusableFailedReplica.Spec.HealthyAt = ""
@@ -2308,7 +3027,7 @@ func (c *VolumeController) replenishReplicas(v *longhorn.Volume, e *longhorn.Eng
 				if datastore.IsReplicaRebuildingFailed(reusableFailedReplica) {
 					reusableFailedReplica.Spec.RebuildRetryCount++
 				}
-				c.backoff.Next(reusableFailedReplica.Name, time.Now())
+				c.backoff.Next(reusableFailedReplica.Name, c.clock.Now())
 
 				rs[reusableFailedReplica.Name] = reusableFailedReplica
 				continue
@@ -2320,11 +3039,18 @@ func (c *VolumeController) replenishReplicas(v *longhorn.Volume, e *longhorn.Eng
 		}
 		if checkBackDuration := c.scheduler.RequireNewReplica(rs, v, hardNodeAffinity); checkBackDuration == 0 {
 			newReplica := c.newReplica(v, e, hardNodeAffinity)
+			if diskPressureReplica != nil {
+				// Mark the source disk at admission, so the disk isn't rebalanced again while the new replica is
+				// rebuilding or within the disk pressure interval, even across restarts.
+				types.SetDiskPressureRebalance(newReplica, diskPressureReplica.Spec.DiskID, c.clock.Now())
+			}
 
 			// Bypassing the precheck when hardNodeAffinity is provided, because
 			// we expect the new replica to be relocated to a specific node.
 			if hardNodeAffinity == "" {
-				if multiError, err := c.precheckCreateReplica(newReplica, rs, v); err != nil {
+				// The new replica is not created, hence the failure is not tied to a replica
+				failure := longhorn.ReplicaSchedulingFailure{}
+				if multiError, err := c.precheckCreateReplica(newReplica, rs, v, &failure); err != nil {
 					log.WithError(err).Warnf("Unable to create new replica %v", newReplica.Name)
 
 					aggregatedReplicaScheduledError := util.NewMultiError(longhorn.ErrorReplicaSchedulePrecheckNewReplicaFailed)
@@ -2335,6 +3061,7 @@ func (c *VolumeController) replenishReplicas(v *longhorn.Volume, e *longhorn.Eng
 					v.Status.Conditions = types.SetCondition(v.Status.Conditions,
 						longhorn.VolumeConditionTypeScheduled, longhorn.ConditionStatusFalse,
 						longhorn.VolumeConditionReasonReplicaSchedulingFailure, aggregatedReplicaScheduledError.Join())
+					v.Status.ReplicaSchedulingFailures = []longhorn.ReplicaSchedulingFailure{failure}
 					continue
 				}
 			}
@@ -2468,49 +3195,89 @@ func (c *VolumeController) getReplicaCountForAutoBalanceBestEffort(v *longhorn.V
 		log.Infof("Found %v replicas from %v to balance to one of node in %v", adjustCount, mostExtraRList, leastExtraROwners)
 	}
 
-	if adjustCount == 0 {
-		replicasUnderDiskPressure, err := c.getReplicasUnderDiskPressure()
-		if err != nil {
-			log.WithError(err).Warn("Failed to get replicas in disk pressure")
-			return 0, nil, []string{}
-		}
+	return adjustCount, mostExtraRList, leastExtraROwners
+}
 
-		for replicaName, replica := range rs {
-			if !replicasUnderDiskPressure[replicaName] {
-				continue
-			}
+// getReplicaCountForAutoBalanceDiskPressure returns the replicas on the disks under pressure that can be moved to
+// another disk of the same node, and the nodes of the replicas.
+func (c *VolumeController) getReplicaCountForAutoBalanceDiskPressure(v *longhorn.Volume, rs map[string]*longhorn.Replica) (int, []string, []string) {
+	log := getLoggerForVolume(c.logger, v).WithField("replicaAutoBalanceOption", longhorn.ReplicaAutoBalanceBestEffort)
 
-			err := c.checkReplicaDiskPressuredSchedulableCandidates(v, replica)
-			if err != nil {
-				log.WithError(err).Tracef("Cannot find replica %v disk pressure candidates", replicaName)
-				continue
-			}
+	setting := c.ds.GetAutoBalancedReplicasSetting(v, log)
+	if setting != longhorn.ReplicaAutoBalanceBestEffort {
+		return 0, nil, []string{}
+	}
+
+	if v.Status.Robustness != longhorn.VolumeRobustnessHealthy {
+		return 0, nil, []string{}
+	}
+
+	replicasUnderDiskPressure, err := c.getReplicasUnderDiskPressure()
+	if err != nil {
+		log.WithError(err).Warn("Failed to get replicas in disk pressure")
+		return 0, nil, []string{}
+	}
+
+	rNames, err := util.SortKeys(rs)
+	if err != nil {
+		log.WithError(err).Warn("Failed to sort replicas")
+		return 0, nil, []string{}
+	}
 
-			adjustCount++
-			mostExtraRList = append(mostExtraRList, replicaName)
-			leastExtraROwners = append(leastExtraROwners, replica.Spec.NodeID)
+	adjustCount := 0
+	var replicaNames []string
+	owners := []string{}
+	for _, replicaName := range rNames {
+		if !replicasUnderDiskPressure[replicaName] {
+			continue
 		}
 
-		if adjustCount == 0 {
-			log.Trace("No replicas in disk pressure")
-		} else {
-			log.Infof("Found %v replicas in disk pressure with schedulable candidates", adjustCount)
+		replica := rs[replicaName]
+		if err := c.checkReplicaDiskPressuredSchedulableCandidates(v, replica); err != nil {
+			log.WithError(err).Tracef("Cannot find replica %v disk pressure candidates", replicaName)
+			continue
 		}
+
+		adjustCount++
+		replicaNames = append(replicaNames, replicaName)
+		owners = append(owners, replica.Spec.NodeID)
 	}
 
-	return adjustCount, mostExtraRList, leastExtraROwners
+	if adjustCount == 0 {
+		log.Trace("No replicas in disk pressure")
+	} else {
+		log.Infof("Found %v replicas in disk pressure with schedulable candidates", adjustCount)
+	}
+
+	return adjustCount, replicaNames, owners
 }
 
+// getReplicasUnderDiskPressure returns the replicas on the disks under space or IO latency pressure. The replicas on
+// a disk are skipped while a replica moved off the disk is rebuilding or within the disk pressure interval.
 func (c *VolumeController) getReplicasUnderDiskPressure() (map[string]bool, error) {
 	settingDiskPressurePercentage, err := c.ds.GetSettingAsInt(types.SettingNameReplicaAutoBalanceDiskPressurePercentage)
 	if err != nil {
 		return nil, err
 	}
+	ioLatencyThreshold, err := c.ds.GetSettingAsInt(types.SettingNameReplicaAutoBalanceDiskIOLatencyThreshold)
+	if err != nil {
+		return nil, err
+	}
 
-	if settingDiskPressurePercentage == 0 {
+	if settingDiskPressurePercentage == 0 && ioLatencyThreshold == 0 {
 		return nil, nil
 	}
 
+	interval, err := c.ds.GetSettingAsInt(types.SettingNameReplicaAutoBalanceDiskPressureInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimitedDisks, err := c.getDiskPressureRebalanceRateLimitedDisks(time.Duration(interval) * time.Second)
+	if err != nil {
+		return nil, err
+	}
+
 	nodes, err := c.ds.ListNodesRO()
 	if err != nil {
 		return nil, err
@@ -2531,7 +3298,9 @@ func (c *VolumeController) getReplicasUnderDiskPressure() (map[string]bool, erro
 				return nil, err
 			}
 
-			if c.scheduler.IsDiskUnderPressure(settingDiskPressurePercentage, diskInfo) {
+			underPressure := (settingDiskPressurePercentage != 0 && c.scheduler.IsDiskUnderPressure(settingDiskPressurePercentage, diskInfo)) ||
+				isDiskUnderIOLatencyPressure(ioLatencyThreshold, diskStatus)
+			if underPressure && !rateLimitedDisks[diskStatus.DiskUUID] {
 				for replicaName := range diskStatus.ScheduledReplica {
 					replicasInPressure[replicaName] = true
 				}
@@ -2541,6 +3310,32 @@ func (c *VolumeController) getReplicasUnderDiskPressure() (map[string]bool, erro
 	return replicasInPressure, nil
 }
 
+func isDiskUnderIOLatencyPressure(ioLatencyThreshold int64, diskStatus *longhorn.DiskStatus) bool {
+	return ioLatencyThreshold > 0 && diskStatus.IOLatency >= ioLatencyThreshold
+}
+
+// getDiskPressureRebalanceRateLimitedDisks returns the UUIDs of the disks a replica was moved off, while the new
+// replica is still rebuilding or within the interval since the move was admitted.
+func (c *VolumeController) getDiskPressureRebalanceRateLimitedDisks(interval time.Duration) (map[string]bool, error) {
+	replicas, err := c.ds.ListReplicasRO()
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimitedDisks := map[string]bool{}
+	for _, r := range replicas {
+		diskUUID, rebalancedAt := types.GetDiskPressureRebalance(r)
+		if diskUUID == "" {
+			continue
+		}
+		rebuilding := r.Spec.HealthyAt == "" && r.Spec.FailedAt == ""
+		if rebuilding || c.clock.Now().Before(rebalancedAt.Add(interval)) {
+			rateLimitedDisks[diskUUID] = true
+		}
+	}
+	return rateLimitedDisks, nil
+}
+
 func (c *VolumeController) checkDiskPressuredReplicaIsFirstCandidate(replica *longhorn.Replica, node *longhorn.Node) error {
 	var replicaScheduledDiskStatus *longhorn.DiskStatus
 	for _, diskStatus := range node.Status.DiskStatus {
@@ -2575,8 +3370,14 @@ func (c *VolumeController) checkReplicaDiskPressuredSchedulableCandidates(volume
 		return err
 	}
 
-	if diskPressurePercentage == 0 {
-		return errors.Errorf("%v setting is 0, skip auto-balance replicas in disk pressure", types.SettingNameReplicaAutoBalanceDiskPressurePercentage)
+	ioLatencyThreshold, err := c.ds.GetSettingAsInt(types.SettingNameReplicaAutoBalanceDiskIOLatencyThreshold)
+	if err != nil {
+		return err
+	}
+
+	if diskPressurePercentage == 0 && ioLatencyThreshold == 0 {
+		return errors.Errorf("%v and %v settings are 0, skip auto-balance replicas in disk pressure",
+			types.SettingNameReplicaAutoBalanceDiskPressurePercentage, types.SettingNameReplicaAutoBalanceDiskIOLatencyThreshold)
 	}
 
 	nodes, err := c.ds.ListNodesRO()
@@ -2628,7 +3429,15 @@ func (c *VolumeController) checkReplicaDiskPressuredSchedulableCandidates(volume
 			continue
 		}
 
-		if c.scheduler.IsSchedulableToDiskConsiderDiskPressure(diskPressurePercentage, volume.Spec.Size, volume.Status.ActualSize, diskInfo) {
+		if isDiskUnderIOLatencyPressure(ioLatencyThreshold, diskStatus) {
+			continue
+		}
+
+		if diskPressurePercentage == 0 {
+			if c.scheduler.IsSchedulableToDisk(volume.Spec.Size, volume.Status.ActualSize, diskInfo) {
+				schedulableDisks[diskName] = true
+			}
+		} else if c.scheduler.IsSchedulableToDiskConsiderDiskPressure(diskPressurePercentage, volume.Spec.Size, volume.Status.ActualSize, diskInfo) {
 			schedulableDisks[diskName] = true
 		}
 	}
@@ -2899,14 +3708,25 @@ func (c *VolumeController) getReplicaCountForAutoBalanceNode(v *longhorn.Volume,
 	return adjustCount, nodeExtraRs, err
 }
 
-func (c *VolumeController) getReplenishReplicasCount(v *longhorn.Volume, rs map[string]*longhorn.Replica, e *longhorn.Engine) (int, string) {
+// getReplenishReplicasCount returns the count of the replicas to create, the node the new replicas must be scheduled
+// to, and the replica on a disk under pressure the new replica is created to replace.
+func (c *VolumeController) getReplenishReplicasCount(v *longhorn.Volume, rs map[string]*longhorn.Replica, e *longhorn.Engine) (int, string, *longhorn.Replica) {
 	usableCount := 0
+	movingReplica := getCapacityRebalanceMovingReplica(v, rs)
 	for _, r := range rs {
 		// The failed to schedule local replica shouldn't be counted
 		if isDataLocalityBestEffort(v) && r.Spec.HealthyAt == "" && r.Spec.FailedAt == "" && r.Spec.NodeID == "" &&
 			v.Status.CurrentNodeID != "" && r.Spec.HardNodeAffinity == v.Status.CurrentNodeID {
 			continue
 		}
+		// Skip the replica being replaced by the disk tag migration.
+		if isDiskTagMigrationRequested(v) && r.Name == v.Status.DiskTagMigrationStatus.ReplacingReplica {
+			continue
+		}
+		// Skip the replica being moved by the capacity rebalance.
+		if movingReplica != nil && r.Name == movingReplica.Name {
+			continue
+		}
 		// Skip the replica has been requested eviction.
 		if r.Spec.FailedAt == "" && (!r.Spec.EvictionRequested) && r.Spec.Active {
 			usableCount++
@@ -2916,27 +3736,32 @@ func (c *VolumeController) getReplenishReplicasCount(v *longhorn.Volume, rs map[
 	// Only create 1 replica while volume is in cloning process
 	if isCloningRequiredAndNotCompleted(v) {
 		if usableCount == 0 {
-			return 1, ""
+			return 1, "", nil
 		}
-		return 0, ""
+		return 0, "", nil
 	}
 
 	switch {
 	case v.Spec.NumberOfReplicas < usableCount:
-		return 0, ""
+		return 0, "", nil
 	case v.Spec.NumberOfReplicas > usableCount:
-		return v.Spec.NumberOfReplicas - usableCount, ""
+		return v.Spec.NumberOfReplicas - usableCount, "", nil
 	case v.Spec.NumberOfReplicas == usableCount:
 		if adjustCount := c.getReplicaCountForAutoBalanceLeastEffort(v, e, rs, c.getReplicaCountForAutoBalanceZone); adjustCount != 0 {
-			return adjustCount, ""
+			return adjustCount, "", nil
 		}
 		if adjustCount := c.getReplicaCountForAutoBalanceLeastEffort(v, e, rs, c.getReplicaCountForAutoBalanceNode); adjustCount != 0 {
-			return adjustCount, ""
+			return adjustCount, "", nil
 		}
 
 		var nCandidates []string
 		adjustCount, _, nCandidates := c.getReplicaCountForAutoBalanceBestEffort(v, e, rs, c.getReplicaCountForAutoBalanceNode)
 		if adjustCount == 0 {
+			var rNames []string
+			if adjustCount, rNames, nCandidates = c.getReplicaCountForAutoBalanceDiskPressure(v, rs); adjustCount != 0 {
+				return adjustCount, nCandidates[0], rs[rNames[0]]
+			}
+
 			adjustCount, _, zCandidates := c.getReplicaCountForAutoBalanceBestEffort(v, e, rs, c.getReplicaCountForAutoBalanceZone)
 			if adjustCount != 0 {
 				nCandidates = c.getNodeCandidatesForAutoBalanceZone(v, e, rs, zCandidates)
@@ -2944,12 +3769,12 @@ func (c *VolumeController) getReplenishReplicasCount(v *longhorn.Volume, rs map[
 		}
 		if adjustCount != 0 && len(nCandidates) != 0 {
 			// TODO: https://github.com/longhorn/longhorn/issues/2667
-			return adjustCount, nCandidates[0]
+			return adjustCount, nCandidates[0], nil
 		}
 
-		return adjustCount, ""
+		return adjustCount, "", nil
 	}
-	return 0, ""
+	return 0, "", nil
 }
 
 func (c *VolumeController) getNodeCandidatesForAutoBalanceZone(v *longhorn.Volume, e *longhorn.Engine, rs map[string]*longhorn.Replica, zones []string) (candidateNames []string) {
@@ -3526,7 +4351,7 @@ func (c *VolumeController) updateRequestedDataSourceForVolumeCloning(v *longhorn
 	return nil
 }
 
-func shouldInitVolumeClone(v *longhorn.Volume, log *logrus.Entry) bool {
+func shouldInitVolumeClone(v *longhorn.Volume, now time.Time, log *logrus.Entry) bool {
 	if !types.IsDataFromVolume(v.Spec.DataSource) {
 		return false
 	}
@@ -3543,7 +4368,7 @@ func shouldInitVolumeClone(v *longhorn.Volume, log *logrus.Entry) bool {
 			log.Warnf("Failed to check shouldInitVolumeClone %v", err)
 			return false
 		}
-		return time.Now().After(t)
+		return now.After(t)
 	}
 	return false
 }
@@ -3558,7 +4383,7 @@ func (c *VolumeController) checkAndInitVolumeClone(v *longhorn.Volume, e *longho
 	}
 
 	dataSource := v.Spec.DataSource
-	if !shouldInitVolumeClone(v, log) {
+	if !shouldInitVolumeClone(v, c.clock.Now(), log) {
 		return nil
 	}
 
@@ -3618,7 +4443,7 @@ func (c *VolumeController) checkAndInitVolumeClone(v *longhorn.Volume, e *longho
 	v.Status.CloneStatus.Snapshot = snapshotName
 	v.Status.CloneStatus.State = longhorn.VolumeCloneStateInitiated
 	d := time.Duration(math.Exp2(float64(v.Status.CloneStatus.AttemptCount))) * initialCloneRetryInterval
-	v.Status.CloneStatus.NextAllowedAttemptAt = util.TimestampAfterDuration(d)
+	v.Status.CloneStatus.NextAllowedAttemptAt = c.clock.Now().Add(d).UTC().Format(time.RFC3339)
 	v.Status.CloneStatus.AttemptCount += 1
 	c.eventRecorder.Eventf(v, corev1.EventTypeNormal, constant.EventReasonVolumeCloneInitiated, "source volume %v, snapshot %v", sourceVolName, snapshotName)
 
@@ -3734,8 +4559,8 @@ func (c *VolumeController) newReplica(v *longhorn.Volume, e *longhorn.Engine, ha
 	}
 }
 
-func (c *VolumeController) precheckCreateReplica(replica *longhorn.Replica, replicas map[string]*longhorn.Replica, volume *longhorn.Volume) (util.MultiError, error) {
-	diskCandidates, multiError, err := c.scheduler.FindDiskCandidates(replica, replicas, volume)
+func (c *VolumeController) precheckCreateReplica(replica *longhorn.Replica, replicas map[string]*longhorn.Replica, volume *longhorn.Volume, failure *longhorn.ReplicaSchedulingFailure) (util.MultiError, error) {
+	diskCandidates, multiError, err := c.scheduler.FindDiskCandidates(replica, replicas, volume, failure)
 	if err != nil {
 		return nil, err
 	}
@@ -4157,7 +4982,8 @@ func (c *VolumeController) processMigration(v *longhorn.Volume, es map[string]*l
 		err = errors.Wrapf(err, "failed to process migration for %v", v.Name)
 	}()
 
-	if !util.IsMigratableVolume(v) {
+	// a RWO volume is migrated only when it's requested by the live migration ticket
+	if !util.IsMigratableVolume(v) && !util.IsVolumeMigrating(v) {
 		return nil
 	}
 
@@ -4521,6 +5347,15 @@ func (c *VolumeController) isResponsibleFor(v *longhorn.Volume, defaultEngineIma
 		return false, err
 	}
 	preferredOwnerID := v.Spec.NodeID
+	if preferredOwnerID == "" {
+		// The detached volume is sharded across the managers if volume reconciliation sharding is enabled. The
+		// attached volume is kept owned by the node it's attached to, since the node-local work like the filesystem
+		// trim and the clone source replica selection relies on it.
+		if preferredOwnerID, err = c.getVolumeShardOwner(v.Name); err != nil {
+			return false, err
+		}
+	}
+	dataEnginePreferredOwnerID := preferredOwnerID
 	if isOwnerNodeDelinquent || isSpecNodeDelinquent {
 		sm, err := c.ds.GetShareManager(v.Name)
 		if err != nil && !apierrors.IsNotFound(err) {
@@ -4545,7 +5380,7 @@ func (c *VolumeController) isResponsibleFor(v *longhorn.Volume, defaultEngineIma
 		}
 	}
 
-	preferredOwnerDataEngineAvailable, err := c.ds.CheckDataEngineImageReadiness(defaultEngineImage, v.Spec.DataEngine, v.Spec.NodeID)
+	preferredOwnerDataEngineAvailable, err := c.ds.CheckDataEngineImageReadiness(defaultEngineImage, v.Spec.DataEngine, dataEnginePreferredOwnerID)
 	if err != nil {
 		return false, err
 	}
@@ -4631,6 +5466,20 @@ func (c *VolumeController) ReconcileShareManagerState(volume *longhorn.Volume) e
 		return nil
 	}
 
+	// A non-migratable ReadWriteMany volume with the iSCSI frontend is exported to the nodes as a raw block device
+	// by its engine. The share manager of a volume created before the block mode was supported is removed.
+	if util.IsRWXBlockVolume(volume) {
+		if sm != nil {
+			c.eventRecorder.Eventf(volume, corev1.EventTypeWarning, constant.EventReasonExportedAsBlock,
+				"Volume %v with frontend %v is exported as a raw block device over iSCSI instead of being shared by share manager %v, removing the share manager",
+				volume.Name, volume.Spec.Frontend, sm.Name)
+			if err := c.ds.DeleteShareManager(volume.Name); err != nil && !datastore.ErrorIsNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// no ShareManager create a new one
 	if sm == nil {
 		sm, err = c.createShareManagerForVolume(volume, c.smImage)
@@ -4653,7 +5502,7 @@ func (c *VolumeController) ReconcileShareManagerState(volume *longhorn.Volume) e
 	// Easiest approach is to set the RemountRequestedAt variable.  Pods will make that decision
 	// in the kubernetes_pod_controller.
 	if sm.Status.State == longhorn.ShareManagerStateError || sm.Status.State == longhorn.ShareManagerStateUnknown {
-		volume.Status.RemountRequestedAt = c.nowHandler()
+		volume.Status.RemountRequestedAt = c.now()
 		msg := fmt.Sprintf("Volume %v requested remount at %v", volume.Name, volume.Status.RemountRequestedAt)
 		c.eventRecorder.Eventf(volume, corev1.EventTypeNormal, constant.EventReasonRemount, msg)
 	}
@@ -4661,9 +5510,33 @@ func (c *VolumeController) ReconcileShareManagerState(volume *longhorn.Volume) e
 	// sync the share state and endpoint
 	volume.Status.ShareState = sm.Status.State
 	volume.Status.ShareEndpoint = sm.Status.Endpoint
+	reconcileExpansionPendingCondition(volume, sm)
 	return nil
 }
 
+// reconcileExpansionPendingCondition sets the condition ExpansionPending of a shared volume until both the engine and
+// the filesystem exported by the share manager are expanded, since the NFS clients see the size of the latter.
+func reconcileExpansionPendingCondition(volume *longhorn.Volume, sm *longhorn.ShareManager) {
+	switch {
+	case sm.Status.State != longhorn.ShareManagerStateRunning:
+		volume.Status.Conditions = types.SetCondition(volume.Status.Conditions,
+			longhorn.VolumeConditionTypeExpansionPending, longhorn.ConditionStatusFalse, "", "")
+	case volume.Status.ExpansionRequired:
+		volume.Status.Conditions = types.SetCondition(volume.Status.Conditions,
+			longhorn.VolumeConditionTypeExpansionPending, longhorn.ConditionStatusTrue,
+			longhorn.VolumeConditionReasonEngineExpansionInProgress,
+			fmt.Sprintf("Waiting for the engine to expand the volume to size %v", volume.Spec.Size))
+	case sm.Status.FilesystemSize != volume.Spec.Size:
+		volume.Status.Conditions = types.SetCondition(volume.Status.Conditions,
+			longhorn.VolumeConditionTypeExpansionPending, longhorn.ConditionStatusTrue,
+			longhorn.VolumeConditionReasonShareManagerResizePending,
+			fmt.Sprintf("Waiting for the share manager to resize the exported filesystem to size %v", volume.Spec.Size))
+	default:
+		volume.Status.Conditions = types.SetCondition(volume.Status.Conditions,
+			longhorn.VolumeConditionTypeExpansionPending, longhorn.ConditionStatusFalse, "", "")
+	}
+}
+
 func (c *VolumeController) createShareManagerForVolume(volume *longhorn.Volume, image string) (*longhorn.ShareManager, error) {
 	sm := &longhorn.ShareManager{
 		ObjectMeta: metav1.ObjectMeta{
@@ -4790,6 +5663,82 @@ func (c *VolumeController) enqueueNodeChange(obj interface{}) {
 	}
 }
 
+// isNodeReadinessChanged returns true if the node joins or leaves the managers the detached volumes are sharded across.
+func isNodeReadinessChanged(old, cur interface{}) bool {
+	oldNode, ok := old.(*longhorn.Node)
+	if !ok {
+		return false
+	}
+	curNode, ok := cur.(*longhorn.Node)
+	if !ok {
+		return false
+	}
+	oldCond := types.GetCondition(oldNode.Status.Conditions, longhorn.NodeConditionTypeReady)
+	curCond := types.GetCondition(curNode.Status.Conditions, longhorn.NodeConditionTypeReady)
+	return oldCond.Status != curCond.Status || oldCond.Reason != curCond.Reason
+}
+
+// enqueueVolumesForShardRebalance enqueues the detached volumes, so their ownership moves to the new shard owners
+// after the managers the volumes are sharded across or the sharding setting changes.
+func (c *VolumeController) enqueueVolumesForShardRebalance(settingChanged bool) {
+	if !settingChanged {
+		enabled, err := c.ds.GetSettingAsBool(types.SettingNameVolumeReconciliationSharding)
+		if err != nil {
+			utilruntime.HandleError(fmt.Errorf("failed to get %v setting when rebalancing volume shards: %v", types.SettingNameVolumeReconciliationSharding, err))
+			return
+		}
+		if !enabled {
+			return
+		}
+	}
+
+	vs, err := c.ds.ListVolumesRO()
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to list volumes when rebalancing volume shards: %v", err))
+		return
+	}
+	for _, v := range vs {
+		if v.Spec.NodeID == "" {
+			c.enqueueVolume(v)
+		}
+	}
+}
+
+func (c *VolumeController) invalidateShardMembers() {
+	c.shardMembersLock.Lock()
+	defer c.shardMembersLock.Unlock()
+
+	c.shardMembersBuiltAt = time.Time{}
+}
+
+// getVolumeShardOwner returns the node whose manager should reconcile the detached volume, picked by consistent
+// hashing of the volume name over the cached shard members. It returns empty if volume reconciliation sharding is
+// disabled.
+func (c *VolumeController) getVolumeShardOwner(volumeName string) (string, error) {
+	c.shardMembersLock.Lock()
+	defer c.shardMembersLock.Unlock()
+
+	// The members are refreshed periodically as well, since the manager handoff grace period of a node expires
+	// without any node change
+	if c.shardMembersBuiltAt.IsZero() || c.clock.Since(c.shardMembersBuiltAt) >= volumeShardMembersRefreshInterval {
+		members, err := c.ds.ListVolumeShardMembers()
+		if err != nil {
+			return "", err
+		}
+		c.shardMembers = members
+		c.shardMembersBuiltAt = c.clock.Now()
+	}
+	return util.GetConsistentHashMember(volumeName, c.shardMembers), nil
+}
+
+func isSettingVolumeReconciliationSharding(obj interface{}) bool {
+	setting, ok := obj.(*longhorn.Setting)
+	if !ok {
+		return false
+	}
+	return types.SettingName(setting.Name) == types.SettingNameVolumeReconciliationSharding
+}
+
 func isSettingRelatedToVolume(obj interface{}) bool {
 	setting, ok := obj.(*longhorn.Setting)
 	if !ok {
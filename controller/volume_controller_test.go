@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 
 	imutil "github.com/longhorn/longhorn-instance-manager/pkg/util"
 
+	"github.com/longhorn/longhorn-manager/constant"
 	"github.com/longhorn/longhorn-manager/datastore"
 	"github.com/longhorn/longhorn-manager/types"
 	"github.com/longhorn/longhorn-manager/util"
@@ -68,7 +70,7 @@ func newTestVolumeController(lhClient *lhfake.Clientset, kubeClient *fake.Client
 	for index := range vc.cacheSyncs {
 		vc.cacheSyncs[index] = alwaysReady
 	}
-	vc.nowHandler = getTestNow
+	vc.SetClock(getTestClock())
 
 	return vc, nil
 }
@@ -88,6 +90,7 @@ type VolumeTestCase struct {
 	volumeAutoSalvage                           string
 	replicaReplenishmentWaitInterval            string
 	allowVolumeCreationWithDegradedAvailability string
+	engineRebindTimeout                         string
 }
 
 func (s *TestSuite) TestVolumeLifeCycle(c *C) {
@@ -135,6 +138,9 @@ func (s *TestSuite) TestVolumeLifeCycle(c *C) {
 	tc.expectVolume.Status.Conditions = setVolumeConditionWithoutTimestamp(tc.expectVolume.Status.Conditions,
 		longhorn.VolumeConditionTypeScheduled, longhorn.ConditionStatusFalse, longhorn.VolumeConditionReasonReplicaSchedulingFailure,
 		fmt.Sprintf("%s;%s", longhorn.ErrorReplicaScheduleNodeUnavailable, longhorn.ErrorReplicaSchedulePrecheckNewReplicaFailed))
+	tc.expectVolume.Status.ReplicaSchedulingFailures = []longhorn.ReplicaSchedulingFailure{
+		{UnavailableNodes: len(tc.nodes)},
+	}
 	testCases["volume create - replica creation failure"] = tc
 
 	// unable to create volume because no node to schedule
@@ -158,6 +164,15 @@ func (s *TestSuite) TestVolumeLifeCycle(c *C) {
 	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.Image
 	tc.expectVolume.Status.Conditions = setVolumeConditionWithoutTimestamp(tc.expectVolume.Status.Conditions,
 		longhorn.VolumeConditionTypeScheduled, longhorn.ConditionStatusFalse, longhorn.VolumeConditionReasonReplicaSchedulingFailure, longhorn.ErrorReplicaScheduleNodeUnavailable)
+	replicaNames := []string{}
+	for name := range tc.expectReplicas {
+		replicaNames = append(replicaNames, name)
+	}
+	sort.Strings(replicaNames)
+	for _, name := range replicaNames {
+		tc.expectVolume.Status.ReplicaSchedulingFailures = append(tc.expectVolume.Status.ReplicaSchedulingFailures,
+			longhorn.ReplicaSchedulingFailure{Replica: name, UnavailableNodes: len(tc.nodes)})
+	}
 	testCases["volume create - replica scheduling failure"] = tc
 
 	// detaching after creation
@@ -269,6 +284,93 @@ func (s *TestSuite) TestVolumeLifeCycle(c *C) {
 	}
 	testCases["volume attached"] = tc
 
+	// the engine of the attached volume is lost with the restart of its instance manager, and is started again against
+	// the replica on the other node
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.NodeID = TestNode1
+	tc.volume.Status.CurrentNodeID = TestNode1
+	tc.volume.Status.State = longhorn.VolumeStateAttached
+	tc.volume.Status.Robustness = longhorn.VolumeRobustnessHealthy
+	for _, e := range tc.engines {
+		e.Spec.NodeID = tc.volume.Spec.NodeID
+		e.Spec.DesireState = longhorn.InstanceStateRunning
+		e.Status.CurrentState = longhorn.InstanceStateError
+		e.Status.Started = true
+	}
+	for name, r := range tc.replicas {
+		r.Spec.DesireState = longhorn.InstanceStateRunning
+		r.Spec.HealthyAt = getTestNow()
+		r.Spec.LastHealthyAt = r.Spec.HealthyAt
+		// the replica on the node of the engine is lost with the restart as well
+		if r.Spec.NodeID == TestNode1 {
+			r.Status.CurrentState = longhorn.InstanceStateError
+			continue
+		}
+		r.Status.CurrentState = longhorn.InstanceStateRunning
+		r.Status.IP = randomIP()
+		r.Status.StorageIP = r.Status.IP
+		r.Status.Port = randomPort()
+		for _, e := range tc.engines {
+			e.Spec.ReplicaAddressMap[name] = imutil.GetURL(r.Status.StorageIP, r.Status.Port)
+		}
+	}
+	tc.engineRebindTimeout = "60"
+	tc.copyCurrentToExpect()
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.Image
+	for _, e := range tc.expectEngines {
+		e.Spec.LogRequested = true
+		e.Spec.RebindRequestedAt = getTestNow()
+	}
+	for _, r := range tc.expectReplicas {
+		if r.Spec.NodeID == TestNode1 {
+			r.Spec.DesireState = longhorn.InstanceStateStopped
+			r.Spec.LogRequested = true
+			r.Spec.FailedAt = getTestNow()
+			r.Spec.LastFailedAt = r.Spec.FailedAt
+		}
+	}
+	testCases["volume attached - engine lost with instance manager restart"] = tc
+
+	// the lost engine runs again, so the workloads remount the volume
+	tc = generateVolumeTestCaseTemplate()
+	tc.volume.Spec.NodeID = TestNode1
+	tc.volume.Status.CurrentNodeID = TestNode1
+	tc.volume.Status.State = longhorn.VolumeStateAttached
+	tc.volume.Status.Robustness = longhorn.VolumeRobustnessHealthy
+	for _, e := range tc.engines {
+		e.Spec.NodeID = tc.volume.Spec.NodeID
+		e.Spec.DesireState = longhorn.InstanceStateRunning
+		e.Spec.RebindRequestedAt = getTestNow()
+		e.Status.CurrentState = longhorn.InstanceStateRunning
+		e.Status.Started = true
+		e.Status.IP = randomIP()
+		e.Status.StorageIP = e.Status.IP
+		e.Status.Port = randomPort()
+		e.Status.Endpoint = "/dev/" + tc.volume.Name
+		e.Status.ReplicaModeMap = map[string]longhorn.ReplicaMode{}
+	}
+	for name, r := range tc.replicas {
+		r.Spec.DesireState = longhorn.InstanceStateRunning
+		r.Spec.HealthyAt = getTestNow()
+		r.Spec.LastHealthyAt = r.Spec.HealthyAt
+		r.Status.CurrentState = longhorn.InstanceStateRunning
+		r.Status.IP = randomIP()
+		r.Status.StorageIP = r.Status.IP
+		r.Status.Port = randomPort()
+		for _, e := range tc.engines {
+			e.Spec.ReplicaAddressMap[name] = imutil.GetURL(r.Status.StorageIP, r.Status.Port)
+			e.Status.ReplicaModeMap[name] = longhorn.ReplicaModeRW
+		}
+	}
+	tc.engineRebindTimeout = "60"
+	tc.copyCurrentToExpect()
+	tc.expectVolume.Status.CurrentImage = tc.volume.Spec.Image
+	tc.expectVolume.Status.RemountRequestedAt = getTestNow()
+	for _, e := range tc.expectEngines {
+		e.Spec.RebindRequestedAt = ""
+	}
+	testCases["volume attached - lost engine rebound"] = tc
+
 	tc = generateVolumeTestCaseTemplate()
 	tc.volume.Spec.NodeID = TestNode1
 	tc.volume.Spec.FromBackup = testBackupURL
@@ -962,6 +1064,21 @@ func (s *TestSuite) TestVolumeLifeCycle(c *C) {
 	tc.expectVolume.Status.CurrentNodeID = ""
 	tc.expectVolume.Status.Robustness = longhorn.VolumeRobustnessUnknown
 	tc.expectVolume.Status.RemountRequestedAt = getTestNow()
+	// all the candidates have the same rank without the recorded salvage info, so they are ordered by names
+	salvageReplicaNames := []string{}
+	for _, r := range tc.replicas {
+		salvageReplicaNames = append(salvageReplicaNames, r.Name)
+	}
+	sort.Strings(salvageReplicaNames)
+	for _, name := range salvageReplicaNames {
+		tc.expectVolume.Status.SalvageCandidates = append(tc.expectVolume.Status.SalvageCandidates, longhorn.SalvageCandidate{
+			ReplicaName: name,
+			NodeID:      tc.replicas[name].Spec.NodeID,
+			FailedAt:    getTestNow(),
+		})
+	}
+	tc.expectVolume.Status.SalvageDecision = fmt.Sprintf("Replicas %v were automatically salvaged at %v, ranked first among %v candidates by the revision counter, the last modified time and the snapshot checksum count",
+		salvageReplicaNames, getTestNow(), len(salvageReplicaNames))
 
 	testCases["volume salvage requested - all replica failed"] = tc
 
@@ -1150,6 +1267,10 @@ func newVolume(name string, replicaCount int) *longhorn.Volume {
 					Type:   string(longhorn.VolumeConditionTypeTooManySnapshots),
 					Status: longhorn.ConditionStatusFalse,
 				},
+				{
+					Type:   string(longhorn.VolumeConditionTypeSnapshotQuotaExceeded),
+					Status: longhorn.ConditionStatusFalse,
+				},
 				{
 					Type:   string(longhorn.VolumeConditionTypeScheduled),
 					Status: longhorn.ConditionStatusTrue,
@@ -1448,6 +1569,16 @@ func (s *TestSuite) runTestCases(c *C, testCases map[string]*VolumeTestCase) {
 			err = sIndexer.Add(setting)
 			c.Assert(err, IsNil)
 		}
+		// Set engine rebind timeout setting
+		if tc.engineRebindTimeout != "" {
+			s := initSettingsNameValue(
+				string(types.SettingNameEngineRebindTimeout), tc.engineRebindTimeout)
+			setting, err :=
+				lhClient.LonghornV1beta2().Settings(TestNamespace).Create(context.TODO(), s, metav1.CreateOptions{})
+			c.Assert(err, IsNil)
+			err = sIndexer.Add(setting)
+			c.Assert(err, IsNil)
+		}
 		// Set Default Engine Image
 		s := initSettingsNameValue(
 			string(types.SettingNameDefaultEngineImage), TestEngineImage)
@@ -1571,3 +1702,534 @@ func (s *TestSuite) runTestCases(c *C, testCases map[string]*VolumeTestCase) {
 		}
 	}
 }
+
+func (s *TestSuite) TestGetSnapshotQuotaExceededReason(c *C) {
+	v := &longhorn.Volume{}
+	v.Spec.SnapshotMaxCount = 2
+	e := &longhorn.Engine{}
+	e.Status.Snapshots = map[string]*longhorn.SnapshotInfo{
+		"volume-head": {Name: "volume-head", Size: "4096"},
+		"snap-1":      {Name: "snap-1", Size: "1024"},
+		"snap-2":      {Name: "snap-2", Size: "1024", Removed: true},
+	}
+
+	// the volume head and the removed snapshots are not counted
+	reason, _ := getSnapshotQuotaExceededReason(v, e)
+	c.Assert(reason, Equals, "")
+
+	e.Status.Snapshots["snap-3"] = &longhorn.SnapshotInfo{Name: "snap-3", Size: "2048"}
+	reason, _ = getSnapshotQuotaExceededReason(v, e)
+	c.Assert(reason, Equals, longhorn.VolumeConditionReasonSnapshotCountQuotaExceeded)
+
+	v.Spec.SnapshotMaxCount = 250
+	v.Spec.SnapshotMaxSize = 3072
+	reason, _ = getSnapshotQuotaExceededReason(v, e)
+	c.Assert(reason, Equals, longhorn.VolumeConditionReasonSnapshotSizeQuotaExceeded)
+
+	v.Spec.SnapshotMaxSize = 0
+	reason, _ = getSnapshotQuotaExceededReason(v, e)
+	c.Assert(reason, Equals, "")
+}
+
+func (s *TestSuite) TestGetSnapshotsToPrune(c *C) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	// snap-1 <- snap-2 <- snap-3 <- snap-4 <- volume-head, created one day after another
+	newSnapshot := func(name, child string, daysAgo int, size int64, userCreated bool) *longhorn.Snapshot {
+		snap := &longhorn.Snapshot{}
+		snap.Name = name
+		snap.Status.Children = map[string]bool{child: true}
+		snap.Status.CreationTime = now.Add(-time.Duration(daysAgo) * 24 * time.Hour).Format(time.RFC3339)
+		snap.Status.Size = size
+		snap.Status.UserCreated = userCreated
+		return snap
+	}
+	snapshots := map[string]*longhorn.Snapshot{
+		"snap-1": newSnapshot("snap-1", "snap-2", 4, 100, true),
+		"snap-2": newSnapshot("snap-2", "snap-3", 3, 100, false),
+		"snap-3": newSnapshot("snap-3", "snap-4", 2, 100, true),
+		"snap-4": newSnapshot("snap-4", "volume-head", 1, 100, true),
+	}
+	notProtected := func(*longhorn.Snapshot) bool { return false }
+	getNames := func(snapshots []*longhorn.Snapshot) []string {
+		names := []string{}
+		for _, snap := range snapshots {
+			names = append(names, snap.Name)
+		}
+		return names
+	}
+
+	toPrune, requeueAfter := getSnapshotsToPrune(snapshots, 0, 0, 0, now, notProtected)
+	c.Assert(toPrune, HasLen, 0)
+	c.Assert(requeueAfter, Equals, time.Duration(0))
+
+	// both the user created and the system snapshots are pruned
+	toPrune, _ = getSnapshotsToPrune(snapshots, 2, 0, 0, now, notProtected)
+	c.Assert(getNames(toPrune), DeepEquals, []string{"snap-2", "snap-1"})
+
+	toPrune, requeueAfter = getSnapshotsToPrune(snapshots, 0, 60*time.Hour, 0, now, notProtected)
+	c.Assert(getNames(toPrune), DeepEquals, []string{"snap-2", "snap-1"})
+	c.Assert(requeueAfter, Equals, 12*time.Hour+time.Second)
+
+	toPrune, _ = getSnapshotsToPrune(snapshots, 0, 0, 150, now, notProtected)
+	c.Assert(getNames(toPrune), DeepEquals, []string{"snap-3", "snap-2", "snap-1"})
+
+	// the parent of the volume head is never pruned
+	toPrune, _ = getSnapshotsToPrune(snapshots, 0, time.Hour, 0, now, notProtected)
+	c.Assert(getNames(toPrune), DeepEquals, []string{"snap-3", "snap-2", "snap-1"})
+
+	// the protected snapshots are kept and checked again later
+	isProtected := func(snap *longhorn.Snapshot) bool { return snap.Name == "snap-1" }
+	toPrune, requeueAfter = getSnapshotsToPrune(snapshots, 3, 0, 0, now, isProtected)
+	c.Assert(toPrune, HasLen, 0)
+	c.Assert(requeueAfter, Equals, time.Minute)
+
+	// the snapshots of the CSI VolumeSnapshotContents are kept
+	for _, snap := range snapshots {
+		snap.Spec.Volume = "vol-1"
+	}
+	isProtected = newSnapshotProtectionChecker(0, nil, map[string]bool{"snap://vol-1/snap-1": true}, now)
+	toPrune, _ = getSnapshotsToPrune(snapshots, 2, 0, 0, now, isProtected)
+	c.Assert(getNames(toPrune), DeepEquals, []string{"snap-2"})
+
+	// the removed snapshots are not counted
+	snapshots["snap-3"].Status.MarkRemoved = true
+	toPrune, _ = getSnapshotsToPrune(snapshots, 2, 0, 0, now, notProtected)
+	c.Assert(getNames(toPrune), DeepEquals, []string{"snap-1"})
+}
+
+func (s *TestSuite) TestGetRankedSalvageCandidates(c *C) {
+	now := time.Now().UTC()
+	newReplica := func(name string, failedAt time.Time) *longhorn.Replica {
+		r := &longhorn.Replica{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		r.Spec.NodeID = "node-1"
+		r.Spec.FailedAt = failedAt.Format(time.RFC3339)
+		return r
+	}
+	failedUsableReplicas := map[string]*longhorn.Replica{
+		"r-1": newReplica("r-1", now),
+		"r-2": newReplica("r-2", now.Add(-10*time.Second)),
+		"r-3": newReplica("r-3", now.Add(-time.Hour)),
+		"r-4": newReplica("r-4", now.Add(-20*time.Second)),
+		"r-5": newReplica("r-5", now.Add(-5*time.Second)),
+	}
+	modifiedAt := now.Add(-time.Minute).Format(time.RFC3339)
+	salvageInfoMap := map[string]*longhorn.ReplicaSalvageInfo{
+		"r-1": {RevisionCounter: 90, LastModifiedAt: modifiedAt},
+		"r-2": {RevisionCounter: 100, LastModifiedAt: modifiedAt, SnapshotChecksumCount: 1},
+		"r-3": {RevisionCounter: 200, LastModifiedAt: modifiedAt},
+		"r-4": {RevisionCounter: 100, LastModifiedAt: modifiedAt, SnapshotChecksumCount: 2},
+	}
+
+	getNames := func(candidates []longhorn.SalvageCandidate) []string {
+		names := []string{}
+		for _, candidate := range candidates {
+			names = append(names, candidate.ReplicaName)
+		}
+		return names
+	}
+
+	candidates := getRankedSalvageCandidates(failedUsableReplicas, salvageInfoMap)
+	c.Assert(getNames(candidates), DeepEquals, []string{"r-3", "r-4", "r-2", "r-1", "r-5"})
+	c.Assert(candidates[0].RevisionCounter, Equals, int64(200))
+	c.Assert(candidates[0].NodeID, Equals, "node-1")
+
+	// r-3 failed too long before the others, r-1 has a known lower revision counter while the one of r-5 is unknown
+	c.Assert(getAutoSalvageReplicaNames(candidates, now), DeepEquals, []string{"r-4", "r-2", "r-5"})
+
+	// without the recorded info, all the replicas failed around the last failure are salvaged
+	candidates = getRankedSalvageCandidates(failedUsableReplicas, nil)
+	c.Assert(getNames(candidates), DeepEquals, []string{"r-1", "r-5", "r-2", "r-4", "r-3"})
+	c.Assert(getAutoSalvageReplicaNames(candidates, now), DeepEquals, []string{"r-1", "r-5", "r-2", "r-4"})
+}
+
+func (s *TestSuite) TestHasDiskBelowDataLocalityLoadThresholds(c *C) {
+	newDisk := func(diskType longhorn.DiskType, maximum, available int64, ioUtilization int, schedulable bool) (longhorn.DiskSpec, *longhorn.DiskStatus) {
+		status := longhorn.ConditionStatusFalse
+		if schedulable {
+			status = longhorn.ConditionStatusTrue
+		}
+		return longhorn.DiskSpec{Type: diskType, AllowScheduling: true}, &longhorn.DiskStatus{
+			StorageMaximum:   maximum,
+			StorageAvailable: available,
+			IOUtilization:    ioUtilization,
+			Conditions: []longhorn.Condition{
+				{Type: longhorn.DiskConditionTypeSchedulable, Status: status},
+			},
+		}
+	}
+	newNode := func() *longhorn.Node {
+		return &longhorn.Node{
+			Spec:   longhorn.NodeSpec{Disks: map[string]longhorn.DiskSpec{}},
+			Status: longhorn.NodeStatus{DiskStatus: map[string]*longhorn.DiskStatus{}},
+		}
+	}
+
+	node := newNode()
+	c.Assert(hasDiskBelowDataLocalityLoadThresholds(node, longhorn.DataEngineTypeV1, 80, 50), Equals, false)
+
+	// 90% used
+	node.Spec.Disks["full"], node.Status.DiskStatus["full"] = newDisk(longhorn.DiskTypeFilesystem, 100, 10, 0, true)
+	// busy
+	node.Spec.Disks["busy"], node.Status.DiskStatus["busy"] = newDisk(longhorn.DiskTypeFilesystem, 100, 90, 60, true)
+	// idle but not schedulable
+	node.Spec.Disks["unschedulable"], node.Status.DiskStatus["unschedulable"] = newDisk(longhorn.DiskTypeFilesystem, 100, 90, 0, false)
+	// idle but for the other data engine
+	node.Spec.Disks["block"], node.Status.DiskStatus["block"] = newDisk(longhorn.DiskTypeBlock, 100, 90, 0, true)
+	c.Assert(hasDiskBelowDataLocalityLoadThresholds(node, longhorn.DataEngineTypeV1, 80, 50), Equals, false)
+	c.Assert(hasDiskBelowDataLocalityLoadThresholds(node, longhorn.DataEngineTypeV1, 100, 50), Equals, true)
+	c.Assert(hasDiskBelowDataLocalityLoadThresholds(node, longhorn.DataEngineTypeV1, 80, 70), Equals, true)
+	c.Assert(hasDiskBelowDataLocalityLoadThresholds(node, longhorn.DataEngineTypeV2, 80, 50), Equals, true)
+
+	node.Spec.Disks["idle"], node.Status.DiskStatus["idle"] = newDisk(longhorn.DiskTypeFilesystem, 100, 50, 10, true)
+	c.Assert(hasDiskBelowDataLocalityLoadThresholds(node, longhorn.DataEngineTypeV1, 80, 50), Equals, true)
+}
+
+func (s *TestSuite) TestDiskPressureRebalanceRateLimit(c *C) {
+	c.Assert(isDiskUnderIOLatencyPressure(0, &longhorn.DiskStatus{IOLatency: 100}), Equals, false)
+	c.Assert(isDiskUnderIOLatencyPressure(50, &longhorn.DiskStatus{IOLatency: 49}), Equals, false)
+	c.Assert(isDiskUnderIOLatencyPressure(50, &longhorn.DiskStatus{IOLatency: 50}), Equals, true)
+
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+	replicaIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Replicas().Informer().GetIndexer()
+
+	vc, err := newTestVolumeController(lhClient, kubeClient, extensionsClient, informerFactories, TestOwnerID1)
+	c.Assert(err, IsNil)
+	clock := getTestClock()
+	vc.SetClock(clock)
+
+	volume := newVolume(TestVolumeName, 2)
+	engine := newEngineForVolume(volume)
+
+	// The replica moved off disk-1 is still rebuilding
+	rebuilding := newReplicaForVolume(volume, engine, TestNode1, "disk-2")
+	types.SetDiskPressureRebalance(rebuilding, "disk-1", clock.Now().Add(-time.Hour))
+	rebuilding.Spec.HealthyAt = ""
+	rebuilding.Namespace = TestNamespace
+	c.Assert(replicaIndexer.Add(rebuilding), IsNil)
+
+	// The replica moved off disk-3 is rebuilt, and the move was admitted within the interval
+	rebuilt := newReplicaForVolume(volume, engine, TestNode1, "disk-4")
+	types.SetDiskPressureRebalance(rebuilt, "disk-3", clock.Now().Add(-time.Minute))
+	rebuilt.Spec.HealthyAt = getTestNow()
+	rebuilt.Namespace = TestNamespace
+	c.Assert(replicaIndexer.Add(rebuilt), IsNil)
+
+	// The replica moved off disk-5 is rebuilt, and the move was admitted before the interval
+	expired := newReplicaForVolume(volume, engine, TestNode2, "disk-6")
+	types.SetDiskPressureRebalance(expired, "disk-5", clock.Now().Add(-time.Hour))
+	expired.Spec.HealthyAt = getTestNow()
+	expired.Namespace = TestNamespace
+	c.Assert(replicaIndexer.Add(expired), IsNil)
+
+	// The replica isn't created for the disk pressure
+	regular := newReplicaForVolume(volume, engine, TestNode2, "disk-7")
+	regular.Spec.HealthyAt = ""
+	regular.Namespace = TestNamespace
+	c.Assert(replicaIndexer.Add(regular), IsNil)
+
+	rateLimitedDisks, err := vc.getDiskPressureRebalanceRateLimitedDisks(5 * time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(rateLimitedDisks, DeepEquals, map[string]bool{"disk-1": true, "disk-3": true})
+
+	rateLimitedDisks, err = vc.getDiskPressureRebalanceRateLimitedDisks(0)
+	c.Assert(err, IsNil)
+	c.Assert(rateLimitedDisks, DeepEquals, map[string]bool{"disk-1": true})
+
+	// The mark is persisted in the replica, so it's kept across restarts of the controller
+	clock.Step(5 * time.Minute)
+	vc, err = newTestVolumeController(lhClient, kubeClient, extensionsClient, informerFactories, TestOwnerID1)
+	c.Assert(err, IsNil)
+	vc.SetClock(clock)
+	rateLimitedDisks, err = vc.getDiskPressureRebalanceRateLimitedDisks(5 * time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(rateLimitedDisks, DeepEquals, map[string]bool{"disk-1": true})
+}
+
+func (s *TestSuite) TestFilesystemTrimLimit(c *C) {
+	e := &longhorn.Engine{
+		Status: longhorn.EngineStatus{
+			Snapshots: map[string]*longhorn.SnapshotInfo{
+				"volume-head": {Name: "volume-head", Size: "4096"},
+				"snap-1":      {Name: "snap-1", Size: "1048576", Removed: true},
+				"snap-2":      {Name: "snap-2", Size: "2097152", Removed: true},
+				"snap-3":      {Name: "snap-3", Size: "8192"},
+			},
+		},
+	}
+	c.Assert(getFilesystemTrimDeletedSpace(e), Equals, int64(3*util.MiB))
+
+	vc := &VolumeController{
+		filesystemTrimmingVolumes: map[string]bool{},
+	}
+	acquired, trimming := vc.acquireFilesystemTrimSlot("vol-1", 1)
+	c.Assert(acquired, Equals, true)
+	c.Assert(trimming, Equals, false)
+
+	acquired, trimming = vc.acquireFilesystemTrimSlot("vol-1", 1)
+	c.Assert(acquired, Equals, false)
+	c.Assert(trimming, Equals, true)
+
+	acquired, trimming = vc.acquireFilesystemTrimSlot("vol-2", 1)
+	c.Assert(acquired, Equals, false)
+	c.Assert(trimming, Equals, false)
+
+	vc.releaseFilesystemTrimSlot("vol-1")
+	acquired, _ = vc.acquireFilesystemTrimSlot("vol-2", 1)
+	c.Assert(acquired, Equals, true)
+}
+
+func (s *TestSuite) TestVolumeReconciliationSharding(c *C) {
+	type testCase struct {
+		shardingEnabled bool
+		downNode        string
+		attached        bool
+	}
+	testCases := map[string]testCase{
+		"sharding enabled": {
+			shardingEnabled: true,
+		},
+		"sharding enabled with volume attached": {
+			shardingEnabled: true,
+			attached:        true,
+		},
+		"sharding disabled with volume attached": {
+			attached: true,
+		},
+		"sharding enabled with shard owner down": {
+			shardingEnabled: true,
+			downNode:        "shard-owner",
+		},
+		"sharding disabled": {},
+	}
+
+	for name, tc := range testCases {
+		fmt.Printf("testing %v\n", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		lhClient := lhfake.NewSimpleClientset()
+		extensionsClient := apiextensionsfake.NewSimpleClientset()
+		informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+		nodeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Nodes().Informer().GetIndexer()
+		settingIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Settings().Informer().GetIndexer()
+		volumeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Volumes().Informer().GetIndexer()
+
+		setting := newSetting(string(types.SettingNameVolumeReconciliationSharding), strconv.FormatBool(tc.shardingEnabled))
+		c.Assert(settingIndexer.Add(setting), IsNil)
+
+		volume := newVolume(TestVolumeName, 2)
+		volume.Spec.DataEngine = longhorn.DataEngineTypeV2
+		volume.Spec.NodeID = ""
+		shardOwner := util.GetConsistentHashMember(volume.Name, []string{TestNode1, TestNode2})
+		otherNode := TestNode1
+		if shardOwner == TestNode1 {
+			otherNode = TestNode2
+		}
+		// The volume is taken over by the other manager before sharding
+		volume.Status.OwnerID = otherNode
+		if tc.attached {
+			volume.Spec.NodeID = otherNode
+		}
+		c.Assert(volumeIndexer.Add(volume), IsNil)
+
+		for _, nodeName := range []string{TestNode1, TestNode2} {
+			node := newNode(nodeName, TestNamespace, true, longhorn.ConditionStatusTrue, "")
+			if tc.downNode == "shard-owner" && nodeName == shardOwner {
+				node = newNode(nodeName, TestNamespace, true, longhorn.ConditionStatusFalse, string(longhorn.NodeConditionReasonKubernetesNodeNotReady))
+			}
+			c.Assert(nodeIndexer.Add(node), IsNil)
+		}
+
+		// The attached volume is kept owned by the node it's attached to
+		expectedOwner := otherNode
+		if tc.shardingEnabled && tc.downNode == "" && !tc.attached {
+			expectedOwner = shardOwner
+		}
+		for _, nodeName := range []string{TestNode1, TestNode2} {
+			vc, err := newTestVolumeController(lhClient, kubeClient, extensionsClient, informerFactories, nodeName)
+			c.Assert(err, IsNil)
+			isResponsible, err := vc.isResponsibleFor(volume, TestEngineImage)
+			c.Assert(err, IsNil)
+			c.Assert(isResponsible, Equals, nodeName == expectedOwner, Commentf("node %v", nodeName))
+		}
+	}
+}
+
+func (s *TestSuite) TestVolumeShardMembersCache(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+	nodeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Nodes().Informer().GetIndexer()
+	settingIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Settings().Informer().GetIndexer()
+
+	c.Assert(settingIndexer.Add(newSetting(string(types.SettingNameVolumeReconciliationSharding), "true")), IsNil)
+	for _, nodeName := range []string{TestNode1, TestNode2} {
+		c.Assert(nodeIndexer.Add(newNode(nodeName, TestNamespace, true, longhorn.ConditionStatusTrue, "")), IsNil)
+	}
+
+	vc, err := newTestVolumeController(lhClient, kubeClient, extensionsClient, informerFactories, TestNode1)
+	c.Assert(err, IsNil)
+	fakeClock := getTestClock()
+	vc.SetClock(fakeClock)
+
+	shardOwner, err := vc.getVolumeShardOwner(TestVolumeName)
+	c.Assert(err, IsNil)
+	c.Assert(shardOwner, Equals, util.GetConsistentHashMember(TestVolumeName, []string{TestNode1, TestNode2}))
+	otherNode := TestNode1
+	if shardOwner == TestNode1 {
+		otherNode = TestNode2
+	}
+
+	// The cached members are used until the node change invalidates them
+	c.Assert(nodeIndexer.Update(newNode(shardOwner, TestNamespace, true, longhorn.ConditionStatusFalse,
+		string(longhorn.NodeConditionReasonKubernetesNodeNotReady))), IsNil)
+	owner, err := vc.getVolumeShardOwner(TestVolumeName)
+	c.Assert(err, IsNil)
+	c.Assert(owner, Equals, shardOwner)
+
+	vc.invalidateShardMembers()
+	owner, err = vc.getVolumeShardOwner(TestVolumeName)
+	c.Assert(err, IsNil)
+	c.Assert(owner, Equals, otherNode)
+
+	// The cached members are refreshed periodically as well
+	c.Assert(nodeIndexer.Update(newNode(shardOwner, TestNamespace, true, longhorn.ConditionStatusTrue, "")), IsNil)
+	owner, err = vc.getVolumeShardOwner(TestVolumeName)
+	c.Assert(err, IsNil)
+	c.Assert(owner, Equals, otherNode)
+
+	fakeClock.Step(volumeShardMembersRefreshInterval)
+	owner, err = vc.getVolumeShardOwner(TestVolumeName)
+	c.Assert(err, IsNil)
+	c.Assert(owner, Equals, shardOwner)
+}
+
+func (s *TestSuite) TestReconcileEngineRebind(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+	nodeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Nodes().Informer().GetIndexer()
+	settingIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Settings().Informer().GetIndexer()
+	imIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().InstanceManagers().Informer().GetIndexer()
+
+	timeoutSetting := newSetting(string(types.SettingNameEngineRebindTimeout), "60")
+	c.Assert(settingIndexer.Add(timeoutSetting), IsNil)
+	c.Assert(settingIndexer.Add(newSetting(string(types.SettingNameDefaultInstanceManagerImage), TestInstanceManagerImage)), IsNil)
+	c.Assert(nodeIndexer.Add(newNode(TestNode1, TestNamespace, true, longhorn.ConditionStatusTrue, "")), IsNil)
+	// The instance manager of the engine is restarting
+	im := newInstanceManager(TestInstanceManagerName, longhorn.InstanceManagerStateStarting, TestOwnerID1, TestNode1, TestIP1,
+		nil, nil, longhorn.DataEngineTypeV1, TestInstanceManagerImage, false)
+	c.Assert(imIndexer.Add(im), IsNil)
+
+	vc, err := newTestVolumeController(lhClient, kubeClient, extensionsClient, informerFactories, TestOwnerID1)
+	c.Assert(err, IsNil)
+	clock := getTestClock()
+	vc.SetClock(clock)
+	log := getLoggerForVolume(vc.logger, newVolume(TestVolumeName, 2))
+
+	v := newVolume(TestVolumeName, 2)
+	v.Spec.NodeID = TestNode1
+	v.Status.CurrentNodeID = TestNode1
+	v.Status.State = longhorn.VolumeStateAttached
+	e := newEngineForVolume(v)
+	e.Spec.NodeID = TestNode1
+	e.Spec.DataEngine = longhorn.DataEngineTypeV1
+	e.Spec.DesireState = longhorn.InstanceStateRunning
+	e.Status.CurrentState = longhorn.InstanceStateError
+	e.Status.Started = true
+	localReplica := newReplicaForVolume(v, e, TestNode1, TestDiskID1)
+	localReplica.Status.CurrentState = longhorn.InstanceStateError
+	remoteReplica := newReplicaForVolume(v, e, TestNode2, TestDiskID1)
+	remoteReplica.Spec.HealthyAt = TestTimeNow
+	remoteReplica.Status.CurrentState = longhorn.InstanceStateRunning
+	rs := map[string]*longhorn.Replica{localReplica.Name: localReplica, remoteReplica.Name: remoteReplica}
+
+	// The lost engine is requested to be started again against the healthy remote replica
+	isRebinding, err := vc.reconcileEngineRebind(v, e, rs, log)
+	c.Assert(err, IsNil)
+	c.Assert(isRebinding, Equals, true)
+	c.Assert(e.Spec.RebindRequestedAt, Equals, TestTimeNow)
+
+	// The rebinding waits for the instance manager within the timeout
+	clock.Step(30 * time.Second)
+	isRebinding, err = vc.reconcileEngineRebind(v, e, rs, log)
+	c.Assert(err, IsNil)
+	c.Assert(isRebinding, Equals, true)
+
+	// The engine crashed in the running instance manager cannot be rebound
+	crashedIM := im.DeepCopy()
+	crashedIM.Status.CurrentState = longhorn.InstanceManagerStateRunning
+	crashedIM.Status.InstanceEngines = map[string]longhorn.InstanceProcess{
+		e.Name: {Status: longhorn.InstanceProcessStatus{State: longhorn.InstanceStateError}},
+	}
+	c.Assert(imIndexer.Update(crashedIM), IsNil)
+	isRebinding, err = vc.reconcileEngineRebind(v, e, rs, log)
+	c.Assert(err, IsNil)
+	c.Assert(isRebinding, Equals, false)
+	c.Assert(e.Spec.RebindRequestedAt, Equals, "")
+	c.Assert(imIndexer.Update(im), IsNil)
+
+	// The rebinding is given up after the timeout
+	e.Spec.RebindRequestedAt = TestTimeNow
+	clock.Step(31 * time.Second)
+	isRebinding, err = vc.reconcileEngineRebind(v, e, rs, log)
+	c.Assert(err, IsNil)
+	c.Assert(isRebinding, Equals, false)
+	c.Assert(e.Spec.RebindRequestedAt, Equals, "")
+
+	// No healthy replica is left outside of the restarted instance manager
+	remoteReplica.Status.CurrentState = longhorn.InstanceStateError
+	isRebinding, err = vc.reconcileEngineRebind(v, e, rs, log)
+	c.Assert(err, IsNil)
+	c.Assert(isRebinding, Equals, false)
+	remoteReplica.Status.CurrentState = longhorn.InstanceStateRunning
+
+	// The rebinding is disabled
+	timeoutSetting.Value = "0"
+	c.Assert(settingIndexer.Update(timeoutSetting), IsNil)
+	isRebinding, err = vc.reconcileEngineRebind(v, e, rs, log)
+	c.Assert(err, IsNil)
+	c.Assert(isRebinding, Equals, false)
+}
+
+func (s *TestSuite) TestReconcileShareManagerStateForRWXBlockVolume(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+	smIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().ShareManagers().Informer().GetIndexer()
+
+	vc, err := newTestVolumeController(lhClient, kubeClient, extensionsClient, informerFactories, TestOwnerID1)
+	c.Assert(err, IsNil)
+
+	v := newVolume(TestVolumeName, 2)
+	v.Spec.AccessMode = longhorn.AccessModeReadWriteMany
+	v.Spec.Frontend = longhorn.VolumeFrontendISCSI
+
+	// No share manager is created for a block volume
+	c.Assert(vc.ReconcileShareManagerState(v), IsNil)
+	sms, err := lhClient.LonghornV1beta2().ShareManagers(TestNamespace).List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(sms.Items, HasLen, 0)
+
+	// The share manager of a volume created before the block mode was supported is removed with an event
+	sm := &longhorn.ShareManager{ObjectMeta: metav1.ObjectMeta{Name: v.Name, Namespace: TestNamespace}}
+	sm, err = lhClient.LonghornV1beta2().ShareManagers(TestNamespace).Create(context.TODO(), sm, metav1.CreateOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(smIndexer.Add(sm), IsNil)
+	c.Assert(vc.ReconcileShareManagerState(v), IsNil)
+	sms, err = lhClient.LonghornV1beta2().ShareManagers(TestNamespace).List(context.TODO(), metav1.ListOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(sms.Items, HasLen, 0)
+	events := vc.eventRecorder.(*record.FakeRecorder).Events
+	c.Assert(events, HasLen, 1)
+	c.Assert(<-events, Matches, "Warning "+constant.EventReasonExportedAsBlock+" .*")
+}
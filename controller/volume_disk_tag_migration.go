@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/longhorn/longhorn-manager/constant"
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// isDiskTagMigrationRequested returns true if the replicas of the volume are requested to be migrated to the disks
+// matching the disk selector, and the request is not completed yet
+func isDiskTagMigrationRequested(v *longhorn.Volume) bool {
+	if v.Spec.DiskTagMigrationRequestedAt == "" {
+		return false
+	}
+	migrationStatus := v.Status.DiskTagMigrationStatus
+	return migrationStatus.RequestedAt != v.Spec.DiskTagMigrationRequestedAt ||
+		migrationStatus.State != longhorn.VolumeDiskTagMigrationStateCompleted
+}
+
+// migrateReplicasToDiskTag replaces the healthy replicas of the volume not on the disks matching the disk selector.
+// The replicas are replaced one at a time: a new replica is rebuilt on a matching disk first, and the replaced
+// replica is removed by cleanupDiskTagMigrationReplica once the new one is healthy. The rebuilding is also throttled
+// by the concurrent replica rebuild per node limit.
+func (c *VolumeController) migrateReplicasToDiskTag(v *longhorn.Volume, e *longhorn.Engine, rs map[string]*longhorn.Replica) error {
+	if !isDiskTagMigrationRequested(v) {
+		return nil
+	}
+	log := getLoggerForVolume(c.logger, v)
+
+	migrationStatus := &v.Status.DiskTagMigrationStatus
+	if migrationStatus.RequestedAt != v.Spec.DiskTagMigrationRequestedAt {
+		*migrationStatus = longhorn.VolumeDiskTagMigrationStatus{
+			RequestedAt:  v.Spec.DiskTagMigrationRequestedAt,
+			State:        longhorn.VolumeDiskTagMigrationStateInProgress,
+			DiskSelector: v.Spec.DiskSelector,
+		}
+		log.Infof("Migrating replicas to disks with tags %v", v.Spec.DiskSelector)
+		c.eventRecorder.Eventf(v, corev1.EventTypeNormal, constant.EventReasonDiskTagMigrating,
+			"migrating replicas of volume %v to disks with tags %v", v.Name, v.Spec.DiskSelector)
+	}
+	// The disk selector can be changed again during the migration
+	if !reflect.DeepEqual(migrationStatus.DiskSelector, v.Spec.DiskSelector) {
+		migrationStatus.DiskSelector = v.Spec.DiskSelector
+	}
+
+	// The replaced replica is gone or failed, so the next one can be replaced
+	if r, exists := rs[migrationStatus.ReplacingReplica]; !exists || !datastore.IsAvailableHealthyReplica(r) {
+		migrationStatus.ReplacingReplica = ""
+	}
+
+	replicasToMigrate, migratedCount, err := c.getReplicasToMigrateToDiskTag(v, rs)
+	if err != nil {
+		return err
+	}
+	migrationStatus.TotalReplicas = v.Spec.NumberOfReplicas
+	migrationStatus.MigratedReplicas = migratedCount
+	if migratedCount > v.Spec.NumberOfReplicas {
+		migrationStatus.MigratedReplicas = v.Spec.NumberOfReplicas
+	}
+
+	if len(replicasToMigrate) == 0 {
+		if migratedCount < v.Spec.NumberOfReplicas {
+			// Wait for the replicas being rebuilt
+			return nil
+		}
+		migrationStatus.State = longhorn.VolumeDiskTagMigrationStateCompleted
+		migrationStatus.ReplacingReplica = ""
+		migrationStatus.CompletedAt = c.now()
+		log.Infof("Migrated replicas to disks with tags %v", v.Spec.DiskSelector)
+		c.eventRecorder.Eventf(v, corev1.EventTypeNormal, constant.EventReasonDiskTagMigrated,
+			"migrated replicas of volume %v to disks with tags %v", v.Name, v.Spec.DiskSelector)
+		return nil
+	}
+
+	if migrationStatus.ReplacingReplica == "" {
+		// Replace a replica only when the volume is fully healthy without the replicas being rebuilt
+		for _, r := range rs {
+			if r.Spec.FailedAt == "" && r.Spec.HealthyAt == "" {
+				return nil
+			}
+		}
+		migrationStatus.ReplacingReplica = replicasToMigrate[0].Name
+		log.Infof("Replacing replica %v on disk %v of node %v for the disk tag migration",
+			replicasToMigrate[0].Name, replicasToMigrate[0].Spec.DiskID, replicasToMigrate[0].Spec.NodeID)
+	}
+
+	return c.replenishReplicas(v, e, rs, "")
+}
+
+// getReplicasToMigrateToDiskTag returns the healthy replicas not on the disks matching the disk selector sorted by
+// name, and the number of the healthy replicas on the matching disks
+func (c *VolumeController) getReplicasToMigrateToDiskTag(v *longhorn.Volume, rs map[string]*longhorn.Replica) ([]*longhorn.Replica, int, error) {
+	allowEmptyDiskSelectorVolume, err := c.ds.GetSettingAsBool(types.SettingNameAllowEmptyDiskSelectorVolume)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to get %v setting", types.SettingNameAllowEmptyDiskSelectorVolume)
+	}
+
+	replicasToMigrate := []*longhorn.Replica{}
+	migratedCount := 0
+	for _, r := range rs {
+		if !datastore.IsAvailableHealthyReplica(r) {
+			continue
+		}
+		onSelectedDisk, err := c.isReplicaOnDiskMatchingSelector(r, v.Spec.DiskSelector, allowEmptyDiskSelectorVolume)
+		if err != nil {
+			return nil, 0, err
+		}
+		if onSelectedDisk {
+			migratedCount++
+		} else {
+			replicasToMigrate = append(replicasToMigrate, r)
+		}
+	}
+	sort.Slice(replicasToMigrate, func(i, j int) bool { return replicasToMigrate[i].Name < replicasToMigrate[j].Name })
+	return replicasToMigrate, migratedCount, nil
+}
+
+func (c *VolumeController) isReplicaOnDiskMatchingSelector(r *longhorn.Replica, diskSelector []string, allowEmptyDiskSelectorVolume bool) (bool, error) {
+	node, err := c.ds.GetNodeRO(r.Spec.NodeID)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for diskName, diskStatus := range node.Status.DiskStatus {
+		if diskStatus.DiskUUID != r.Spec.DiskID {
+			continue
+		}
+		diskSpec, exists := node.Spec.Disks[diskName]
+		if !exists {
+			return false, nil
+		}
+		return types.IsSelectorsInTags(diskSpec.Tags, diskSelector, allowEmptyDiskSelectorVolume), nil
+	}
+	return false, nil
+}
+
+// cleanupDiskTagMigrationReplica removes the replica replaced by the disk tag migration, once there are enough
+// healthy replicas without it
+func (c *VolumeController) cleanupDiskTagMigrationReplica(v *longhorn.Volume, rs map[string]*longhorn.Replica) (bool, error) {
+	if !isDiskTagMigrationRequested(v) {
+		return false, nil
+	}
+	migrationStatus := &v.Status.DiskTagMigrationStatus
+	r, exists := rs[migrationStatus.ReplacingReplica]
+	if !exists {
+		return false, nil
+	}
+
+	healthyCount := 0
+	for _, replica := range rs {
+		if replica.Name != r.Name && datastore.IsAvailableHealthyReplica(replica) {
+			healthyCount++
+		}
+	}
+	if healthyCount < v.Spec.NumberOfReplicas {
+		return false, nil
+	}
+
+	if err := c.deleteReplica(r, rs); err != nil {
+		return false, errors.Wrapf(err, "failed to clean up replica %v replaced by the disk tag migration", r.Name)
+	}
+	getLoggerForVolume(c.logger, v).Infof("Cleaned up replica %v replaced by the disk tag migration", r.Name)
+	migrationStatus.ReplacingReplica = ""
+	return true, nil
+}
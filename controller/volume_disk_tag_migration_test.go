@@ -0,0 +1,28 @@
+package controller
+
+import (
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestIsDiskTagMigrationRequested(c *C) {
+	v := &longhorn.Volume{}
+	c.Assert(isDiskTagMigrationRequested(v), Equals, false)
+
+	v.Spec.DiskTagMigrationRequestedAt = "2024-01-01T00:00:00Z"
+	c.Assert(isDiskTagMigrationRequested(v), Equals, true)
+
+	v.Status.DiskTagMigrationStatus = longhorn.VolumeDiskTagMigrationStatus{
+		RequestedAt: v.Spec.DiskTagMigrationRequestedAt,
+		State:       longhorn.VolumeDiskTagMigrationStateInProgress,
+	}
+	c.Assert(isDiskTagMigrationRequested(v), Equals, true)
+
+	v.Status.DiskTagMigrationStatus.State = longhorn.VolumeDiskTagMigrationStateCompleted
+	c.Assert(isDiskTagMigrationRequested(v), Equals, false)
+
+	// A new request after the completed one
+	v.Spec.DiskTagMigrationRequestedAt = "2024-01-02T00:00:00Z"
+	c.Assert(isDiskTagMigrationRequested(v), Equals, true)
+}
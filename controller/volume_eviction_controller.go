@@ -221,7 +221,7 @@ func (vec *VolumeEvictionController) hasDiskCandidateForReplicaEviction(replicas
 		replicaCopy := replica.DeepCopy()
 		replicaCopy.Spec.HardNodeAffinity = ""
 
-		diskCandidates, multiError, err := vec.scheduler.FindDiskCandidates(replicaCopy, replicas, volume)
+		diskCandidates, multiError, err := vec.scheduler.FindDiskCandidates(replicaCopy, replicas, volume, nil)
 		if err != nil {
 			vec.logger.WithError(err).Warnf("Failed to find disk candidates for evicting replica %q", replica.Name)
 			return false
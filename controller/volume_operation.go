@@ -0,0 +1,133 @@
+package controller
+
+import (
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// The expansion, the replica rebuild and the snapshot purge of a volume are sequenced by priority, since some
+// overlaps leave the expansion stuck: the expansion grows the replicas being rebuilt or coalesced underneath the
+// engine. An operation waits while an operation of a lower priority is in progress, or while an operation of a
+// higher priority is pending, so the pending operations are started in priority order and never wait for each other.
+//
+//  1. The expansion is pending until the engine reaches the volume size. It's not considered pending while it's
+//     backing off from a failure, so a failing expansion doesn't block the rebuild of a degraded volume.
+//  2. The rebuild is pending while a replica of the engine is not added to the engine yet.
+//  3. The snapshot purge is started on demand when a snapshot is deleted, so it doesn't block the other operations
+//     until it's in progress.
+var volumeOperationPriority = []longhorn.VolumeOperationType{
+	longhorn.VolumeOperationTypeExpansion,
+	longhorn.VolumeOperationTypeRebuild,
+	longhorn.VolumeOperationTypeSnapshotPurge,
+}
+
+func isEngineExpanding(e *longhorn.Engine) bool {
+	return e.Status.IsExpanding
+}
+
+func isEngineExpansionPending(e *longhorn.Engine) bool {
+	if e.Status.IsExpanding {
+		return true
+	}
+	return e.Status.CurrentSize != 0 && e.Spec.VolumeSize > e.Status.CurrentSize && e.Status.LastExpansionError == ""
+}
+
+func isEngineRebuilding(e *longhorn.Engine) bool {
+	for _, mode := range e.Status.ReplicaModeMap {
+		if mode == longhorn.ReplicaModeWO {
+			return true
+		}
+	}
+	for _, status := range e.Status.RebuildStatus {
+		if status != nil && status.IsRebuilding {
+			return true
+		}
+	}
+	return false
+}
+
+func isEngineRebuildPending(e *longhorn.Engine) bool {
+	if isEngineRebuilding(e) {
+		return true
+	}
+	for replicaName := range e.Status.CurrentReplicaAddressMap {
+		if _, ok := e.Status.ReplicaModeMap[replicaName]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isEngineSnapshotPurging(e *longhorn.Engine) bool {
+	for _, status := range e.Status.PurgeStatus {
+		if status != nil && status.IsPurging {
+			return true
+		}
+	}
+	return false
+}
+
+func isVolumeOperationInProgress(e *longhorn.Engine, operation longhorn.VolumeOperationType) bool {
+	switch operation {
+	case longhorn.VolumeOperationTypeExpansion:
+		return isEngineExpanding(e)
+	case longhorn.VolumeOperationTypeRebuild:
+		return isEngineRebuilding(e)
+	case longhorn.VolumeOperationTypeSnapshotPurge:
+		return isEngineSnapshotPurging(e)
+	}
+	return false
+}
+
+func isVolumeOperationPending(e *longhorn.Engine, operation longhorn.VolumeOperationType) bool {
+	switch operation {
+	case longhorn.VolumeOperationTypeExpansion:
+		return isEngineExpansionPending(e)
+	case longhorn.VolumeOperationTypeRebuild:
+		return isEngineRebuildPending(e)
+	}
+	return false
+}
+
+// getBlockingVolumeOperation returns the operation the operation must wait for before it's started on the engine, or
+// an empty string if it can be started
+func getBlockingVolumeOperation(e *longhorn.Engine, operation longhorn.VolumeOperationType) longhorn.VolumeOperationType {
+	higherPriority := true
+	for _, other := range volumeOperationPriority {
+		if other == operation {
+			higherPriority = false
+			continue
+		}
+		if higherPriority && isVolumeOperationPending(e, other) {
+			return other
+		}
+		if !higherPriority && isVolumeOperationInProgress(e, other) {
+			return other
+		}
+	}
+	return ""
+}
+
+// getQueuedVolumeOperations returns the pending operations of the engine waiting for another operation. The snapshot
+// purge is only known to be pending by the snapshot controller, so it's pending if purgeRequested is set.
+func getQueuedVolumeOperations(e *longhorn.Engine, purgeRequested bool) []longhorn.QueuedVolumeOperation {
+	var queued []longhorn.QueuedVolumeOperation
+	for _, operation := range volumeOperationPriority {
+		if isVolumeOperationInProgress(e, operation) {
+			continue
+		}
+		if operation == longhorn.VolumeOperationTypeSnapshotPurge {
+			if !purgeRequested {
+				continue
+			}
+		} else if !isVolumeOperationPending(e, operation) {
+			continue
+		}
+		if blockedBy := getBlockingVolumeOperation(e, operation); blockedBy != "" {
+			queued = append(queued, longhorn.QueuedVolumeOperation{
+				Type:      operation,
+				BlockedBy: blockedBy,
+			})
+		}
+	}
+	return queued
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestGetBlockingVolumeOperation(c *C) {
+	newEngine := func() *longhorn.Engine {
+		e := &longhorn.Engine{
+			Status: longhorn.EngineStatus{
+				CurrentSize:              2048,
+				CurrentReplicaAddressMap: map[string]string{"r1": "10.0.0.1:10000", "r2": "10.0.0.2:10000"},
+				ReplicaModeMap:           map[string]longhorn.ReplicaMode{"r1": longhorn.ReplicaModeRW, "r2": longhorn.ReplicaModeRW},
+			},
+		}
+		e.Spec.VolumeSize = 2048
+		return e
+	}
+
+	// nothing blocks the operations of an idle engine
+	e := newEngine()
+	for _, operation := range volumeOperationPriority {
+		c.Assert(getBlockingVolumeOperation(e, operation), Equals, longhorn.VolumeOperationType(""))
+	}
+	c.Assert(getQueuedVolumeOperations(e, true), IsNil)
+
+	// a pending expansion blocks new rebuilds and purges, while it waits for the running rebuild
+	e = newEngine()
+	e.Spec.VolumeSize = 4096
+	e.Status.ReplicaModeMap["r2"] = longhorn.ReplicaModeWO
+	c.Assert(getBlockingVolumeOperation(e, longhorn.VolumeOperationTypeExpansion), Equals, longhorn.VolumeOperationTypeRebuild)
+	c.Assert(getBlockingVolumeOperation(e, longhorn.VolumeOperationTypeSnapshotPurge), Equals, longhorn.VolumeOperationTypeExpansion)
+	c.Assert(getQueuedVolumeOperations(e, true), DeepEquals, []longhorn.QueuedVolumeOperation{
+		{Type: longhorn.VolumeOperationTypeExpansion, BlockedBy: longhorn.VolumeOperationTypeRebuild},
+		{Type: longhorn.VolumeOperationTypeSnapshotPurge, BlockedBy: longhorn.VolumeOperationTypeExpansion},
+	})
+
+	// the rebuild of a new replica waits for the pending expansion
+	e = newEngine()
+	e.Spec.VolumeSize = 4096
+	delete(e.Status.ReplicaModeMap, "r2")
+	c.Assert(getBlockingVolumeOperation(e, longhorn.VolumeOperationTypeExpansion), Equals, longhorn.VolumeOperationType(""))
+	c.Assert(getBlockingVolumeOperation(e, longhorn.VolumeOperationTypeRebuild), Equals, longhorn.VolumeOperationTypeExpansion)
+
+	// a failing expansion doesn't block the rebuild
+	e.Status.LastExpansionError = "failed to expand"
+	c.Assert(getBlockingVolumeOperation(e, longhorn.VolumeOperationTypeRebuild), Equals, longhorn.VolumeOperationType(""))
+
+	// a running purge blocks the expansion and the rebuild
+	e = newEngine()
+	e.Spec.VolumeSize = 4096
+	e.Status.PurgeStatus = map[string]*longhorn.PurgeStatus{"tcp://10.0.0.1:10000": {IsPurging: true}}
+	c.Assert(getBlockingVolumeOperation(e, longhorn.VolumeOperationTypeExpansion), Equals, longhorn.VolumeOperationTypeSnapshotPurge)
+	c.Assert(getQueuedVolumeOperations(e, false), DeepEquals, []longhorn.QueuedVolumeOperation{
+		{Type: longhorn.VolumeOperationTypeExpansion, BlockedBy: longhorn.VolumeOperationTypeSnapshotPurge},
+	})
+}
@@ -0,0 +1,313 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/longhorn/longhorn-manager/constant"
+	"github.com/longhorn/longhorn-manager/csi/crypto"
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	// passphraseRotationPendingRecheckInterval is the interval rechecking whether the LUKS device of an attached
+	// volume has been opened by the CSI plugin
+	passphraseRotationPendingRecheckInterval = 30 * time.Second
+)
+
+// errLUKSDeviceNotOpen is returned if the LUKS device of the volume is not opened on the node
+var errLUKSDeviceNotOpen = errors.New("LUKS device is not open")
+
+// VolumePassphraseRotationController rotates the LUKS passphrase of an encrypted volume on the node the volume is
+// attached to, using the passphrase and the previous passphrase in the node stage secret of the volume PV.
+// Volumes not staged by the CSI plugin are left pending until staged, since the CSI plugin rotates the passphrase
+// while staging.
+type VolumePassphraseRotationController struct {
+	*baseController
+
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the controller
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds         *datastore.DataStore
+	cacheSyncs []cache.InformerSynced
+
+	luksDeviceGetter  func(vol *longhorn.Volume) (string, error)
+	passphraseRotator func(devicePath, passphrase, previousPassphrase, pbkdf string) (bool, error)
+}
+
+func NewVolumePassphraseRotationController(
+	logger logrus.FieldLogger,
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	kubeClient clientset.Interface,
+	controllerID string,
+	namespace string,
+) (*VolumePassphraseRotationController, error) {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: v1core.New(kubeClient.CoreV1().RESTClient()).Events("")})
+
+	vprc := &VolumePassphraseRotationController{
+		baseController: newBaseController("longhorn-volume-passphrase-rotation", logger),
+
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		ds: ds,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-volume-passphrase-rotation-controller"}),
+
+		luksDeviceGetter:  getVolumeLUKSDevice,
+		passphraseRotator: crypto.RotatePassphrase,
+	}
+
+	var err error
+	if _, err = ds.VolumeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    vprc.enqueueVolume,
+		UpdateFunc: func(old, cur interface{}) { vprc.enqueueVolume(cur) },
+	}); err != nil {
+		return nil, err
+	}
+	vprc.cacheSyncs = append(vprc.cacheSyncs, ds.VolumeInformer.HasSynced)
+
+	return vprc, nil
+}
+
+func (vprc *VolumePassphraseRotationController) enqueueVolume(obj interface{}) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %#v: %v", obj, err))
+		return
+	}
+
+	vprc.queue.Add(key)
+}
+
+func (vprc *VolumePassphraseRotationController) enqueueVolumeAfter(obj interface{}, duration time.Duration) {
+	key, err := controller.KeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("enqueueVolumeAfter: failed to get key for object %#v: %v", obj, err))
+		return
+	}
+
+	vprc.queue.AddAfter(key, duration)
+}
+
+func (vprc *VolumePassphraseRotationController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer vprc.queue.ShutDown()
+
+	vprc.logger.Info("Starting Longhorn volume passphrase rotation controller")
+	defer vprc.logger.Info("Shut down Longhorn volume passphrase rotation controller")
+
+	if !cache.WaitForNamedCacheSync(vprc.name, stopCh, vprc.cacheSyncs...) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(vprc.worker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (vprc *VolumePassphraseRotationController) worker() {
+	for vprc.processNextWorkItem() {
+	}
+}
+
+func (vprc *VolumePassphraseRotationController) processNextWorkItem() bool {
+	key, quit := vprc.queue.Get()
+	if quit {
+		return false
+	}
+	defer vprc.queue.Done(key)
+	err := vprc.syncHandler(key.(string))
+	vprc.handleErr(err, key)
+	return true
+}
+
+func (vprc *VolumePassphraseRotationController) handleErr(err error, key interface{}) {
+	if err == nil {
+		vprc.queue.Forget(key)
+		return
+	}
+
+	log := vprc.logger.WithField("Volume", key)
+	handleReconcileErrorLogging(log, err, "Failed to sync Longhorn volume")
+	vprc.queue.AddRateLimited(key)
+}
+
+func (vprc *VolumePassphraseRotationController) syncHandler(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "%v: failed to sync volume %v", vprc.name, key)
+	}()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	if namespace != vprc.namespace {
+		return nil
+	}
+	return vprc.reconcile(name)
+}
+
+func (vprc *VolumePassphraseRotationController) reconcile(volName string) (err error) {
+	vol, err := vprc.ds.GetVolume(volName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	if !vol.Spec.Encrypted || vol.Spec.PassphraseRotationRequestedAt == "" {
+		return nil
+	}
+
+	rotationStatus := &vol.Status.PassphraseRotationStatus
+	if rotationStatus.RequestedAt == vol.Spec.PassphraseRotationRequestedAt &&
+		rotationStatus.State == longhorn.VolumePassphraseRotationStateCompleted {
+		return nil
+	}
+
+	if !vprc.isResponsibleFor(vol) {
+		return nil
+	}
+
+	existingVolume := vol.DeepCopy()
+	defer func() {
+		if reflect.DeepEqual(existingVolume.Status, vol.Status) {
+			return
+		}
+		if _, updateErr := vprc.ds.UpdateVolumeStatus(vol); updateErr != nil {
+			err = errors.Wrapf(updateErr, "failed to update passphrase rotation status of volume %v", volName)
+		}
+	}()
+
+	rotationStatus.RequestedAt = vol.Spec.PassphraseRotationRequestedAt
+
+	if vol.Status.State != longhorn.VolumeStateAttached {
+		rotationStatus.State = longhorn.VolumePassphraseRotationStatePending
+		rotationStatus.NodeID = ""
+		rotationStatus.Error = ""
+		return nil
+	}
+
+	rotated, err := vprc.rotatePassphrase(vol)
+	if errors.Is(err, errLUKSDeviceNotOpen) {
+		rotationStatus.State = longhorn.VolumePassphraseRotationStatePending
+		rotationStatus.NodeID = ""
+		rotationStatus.Error = ""
+		// The volume events don't tell when the CSI plugin opens the LUKS device
+		vprc.enqueueVolumeAfter(vol, passphraseRotationPendingRecheckInterval)
+		return nil
+	}
+	if err != nil {
+		rotationStatus.State = longhorn.VolumePassphraseRotationStateFailed
+		rotationStatus.NodeID = vprc.controllerID
+		rotationStatus.Error = err.Error()
+		vprc.eventRecorder.Eventf(vol, corev1.EventTypeWarning, constant.EventReasonFailedPassphraseRotation,
+			"Failed to rotate passphrase on node %v: %v", vprc.controllerID, err)
+		return err
+	}
+
+	rotationStatus.State = longhorn.VolumePassphraseRotationStateCompleted
+	rotationStatus.NodeID = vprc.controllerID
+	rotationStatus.LastRotatedAt = util.Now()
+	rotationStatus.Error = ""
+	if rotated {
+		vprc.eventRecorder.Eventf(vol, corev1.EventTypeNormal, constant.EventReasonPassphraseRotated,
+			"Rotated passphrase on node %v", vprc.controllerID)
+	}
+	return nil
+}
+
+// rotatePassphrase returns false if the passphrase in the secret already unlocks the volume,
+// e.g. it has been rotated by the CSI plugin when the volume was staged.
+func (vprc *VolumePassphraseRotationController) rotatePassphrase(vol *longhorn.Volume) (bool, error) {
+	passphrase, previousPassphrase, pbkdf, err := vprc.getPassphrases(vol)
+	if err != nil {
+		return false, err
+	}
+
+	fipsModeEnabled, err := vprc.ds.GetSettingAsBool(types.SettingNameFIPSModeEnabled)
+	if err != nil {
+		return false, err
+	}
+	if fipsModeEnabled {
+		pbkdf = crypto.CryptoFIPSPBKDF
+	}
+
+	devicePath, err := vprc.luksDeviceGetter(vol)
+	if err != nil {
+		return false, err
+	}
+
+	return vprc.passphraseRotator(devicePath, passphrase, previousPassphrase, pbkdf)
+}
+
+// getVolumeLUKSDevice returns the device underlying the LUKS mapping opened by the CSI plugin for the volume on this
+// node. The engine endpoint is not necessarily the LUKS device, e.g. for the NVMe-oF and ublk frontends.
+func getVolumeLUKSDevice(vol *longhorn.Volume) (string, error) {
+	mapperPath := crypto.VolumeMapper(vol.Name, string(vol.Spec.DataEngine))
+	devicePath, mapper, err := crypto.DeviceEncryptionStatus(mapperPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the LUKS device of %v", mapperPath)
+	}
+	if mapper == "" || devicePath == "" || devicePath == "(null)" {
+		return "", errors.Wrapf(errLUKSDeviceNotOpen, "no LUKS device is mapped to %v", mapperPath)
+	}
+	return devicePath, nil
+}
+
+func (vprc *VolumePassphraseRotationController) getPassphrases(vol *longhorn.Volume) (passphrase, previousPassphrase, pbkdf string, err error) {
+	secret, err := vprc.ds.GetVolumeEncryptionSecretRO(vol)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	if keyProvider := string(secret.Data[types.CryptoKeyProvider]); keyProvider != "" && keyProvider != "secret" {
+		return "", "", "", fmt.Errorf("unsupported key provider %v", keyProvider)
+	}
+	passphrase = string(secret.Data[types.CryptoKeyValue])
+	if passphrase == "" {
+		return "", "", "", fmt.Errorf("missing passphrase in secret %v/%v", secret.Namespace, secret.Name)
+	}
+	return passphrase, string(secret.Data[types.CryptoPreviousKeyValue]), string(secret.Data[types.CryptoPBKDF]), nil
+}
+
+// isResponsibleFor returns true for the node the volume is attached to, or for the volume owner if it's not attached.
+func (vprc *VolumePassphraseRotationController) isResponsibleFor(vol *longhorn.Volume) bool {
+	if vol.Status.State == longhorn.VolumeStateAttached {
+		return vprc.controllerID == vol.Status.CurrentNodeID
+	}
+	return vprc.controllerID == vol.Status.OwnerID
+}
@@ -0,0 +1,336 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	volumeWarmPoolKey          = "volume-warm-pool"
+	volumeWarmPoolVolumePrefix = "pool-"
+
+	volumeWarmPoolResyncPeriod = 5 * time.Minute
+)
+
+// VolumeWarmPoolController maintains a pool of detached volumes per StorageClass and size,
+// so that the volume manager can hand out a pooled volume to the CSI CreateVolume call.
+type VolumeWarmPoolController struct {
+	*baseController
+
+	// which namespace controller is running with
+	namespace string
+	// use as the OwnerID of the controller
+	controllerID string
+
+	kubeClient    clientset.Interface
+	eventRecorder record.EventRecorder
+
+	ds         *datastore.DataStore
+	cacheSyncs []cache.InformerSynced
+}
+
+func NewVolumeWarmPoolController(
+	logger logrus.FieldLogger,
+	ds *datastore.DataStore,
+	scheme *runtime.Scheme,
+	kubeClient clientset.Interface,
+	controllerID string,
+	namespace string,
+) (*VolumeWarmPoolController, error) {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(logrus.Infof)
+
+	vwpc := &VolumeWarmPoolController{
+		baseController: newBaseController("longhorn-volume-warm-pool", logger),
+
+		namespace:    namespace,
+		controllerID: controllerID,
+
+		ds: ds,
+
+		kubeClient:    kubeClient,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-volume-warm-pool-controller"}),
+	}
+
+	var err error
+	if _, err = ds.SettingInformer.AddEventHandlerWithResyncPeriod(cache.FilteringResourceEventHandler{
+		FilterFunc: isSettingVolumeWarmPool,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { vwpc.enqueue() },
+			UpdateFunc: func(old, cur interface{}) { vwpc.enqueue() },
+		},
+	}, volumeWarmPoolResyncPeriod); err != nil {
+		return nil, err
+	}
+	vwpc.cacheSyncs = append(vwpc.cacheSyncs, ds.SettingInformer.HasSynced)
+
+	if _, err = ds.VolumeInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: isVolumeInWarmPool,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { vwpc.enqueue() },
+			UpdateFunc: func(old, cur interface{}) { vwpc.enqueue() },
+			DeleteFunc: func(obj interface{}) { vwpc.enqueue() },
+		},
+	}); err != nil {
+		return nil, err
+	}
+	vwpc.cacheSyncs = append(vwpc.cacheSyncs, ds.VolumeInformer.HasSynced)
+
+	if _, err = ds.StorageClassInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { vwpc.enqueue() },
+		UpdateFunc: func(old, cur interface{}) { vwpc.enqueue() },
+		DeleteFunc: func(obj interface{}) { vwpc.enqueue() },
+	}); err != nil {
+		return nil, err
+	}
+	vwpc.cacheSyncs = append(vwpc.cacheSyncs, ds.StorageClassInformer.HasSynced)
+
+	return vwpc, nil
+}
+
+func isSettingVolumeWarmPool(obj interface{}) bool {
+	setting, ok := obj.(*longhorn.Setting)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return false
+		}
+		setting, ok = deletedState.Obj.(*longhorn.Setting)
+		if !ok {
+			return false
+		}
+	}
+	return types.SettingName(setting.Name) == types.SettingNameVolumeWarmPool
+}
+
+func isVolumeInWarmPool(obj interface{}) bool {
+	vol, ok := obj.(*longhorn.Volume)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return false
+		}
+		vol, ok = deletedState.Obj.(*longhorn.Volume)
+		if !ok {
+			return false
+		}
+	}
+	_, ok = vol.Labels[types.GetLonghornLabelKey(types.LonghornLabelVolumeWarmPool)]
+	return ok
+}
+
+func (vwpc *VolumeWarmPoolController) enqueue() {
+	vwpc.queue.Add(volumeWarmPoolKey)
+}
+
+func (vwpc *VolumeWarmPoolController) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer vwpc.queue.ShutDown()
+
+	vwpc.logger.Info("Starting Longhorn volume warm pool controller")
+	defer vwpc.logger.Info("Shut down Longhorn volume warm pool controller")
+
+	if !cache.WaitForNamedCacheSync(vwpc.name, stopCh, vwpc.cacheSyncs...) {
+		return
+	}
+
+	// There is only one key in the queue, so a single worker is enough
+	go wait.Until(vwpc.worker, time.Second, stopCh)
+
+	<-stopCh
+}
+
+func (vwpc *VolumeWarmPoolController) worker() {
+	for vwpc.processNextWorkItem() {
+	}
+}
+
+func (vwpc *VolumeWarmPoolController) processNextWorkItem() bool {
+	key, quit := vwpc.queue.Get()
+	if quit {
+		return false
+	}
+	defer vwpc.queue.Done(key)
+	err := vwpc.syncHandler(key.(string))
+	vwpc.handleErr(err, key)
+	return true
+}
+
+func (vwpc *VolumeWarmPoolController) handleErr(err error, key interface{}) {
+	if err == nil {
+		vwpc.queue.Forget(key)
+		return
+	}
+
+	handleReconcileErrorLogging(vwpc.logger, err, "Failed to sync Longhorn volume warm pool")
+	vwpc.queue.AddRateLimited(key)
+}
+
+func (vwpc *VolumeWarmPoolController) syncHandler(key string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "%v: failed to sync %v", vwpc.name, key)
+	}()
+
+	// Only one manager creates and deletes pooled volumes, otherwise the pool can be refilled twice
+	responsibleNodeID, err := getResponsibleNodeID(vwpc.ds)
+	if err != nil {
+		return err
+	}
+	if responsibleNodeID != vwpc.controllerID {
+		return nil
+	}
+
+	return vwpc.reconcile()
+}
+
+func (vwpc *VolumeWarmPoolController) reconcile() error {
+	warmPoolSetting, err := vwpc.ds.GetSettingWithAutoFillingRO(types.SettingNameVolumeWarmPool)
+	if err != nil {
+		return err
+	}
+	entries, err := types.UnmarshalVolumeWarmPool(warmPoolSetting.Value)
+	if err != nil {
+		return err
+	}
+
+	pooledVolumes, err := vwpc.listPooledVolumes()
+	if err != nil {
+		return err
+	}
+
+	expected := map[string]int{}
+	for _, entry := range entries {
+		log := vwpc.logger.WithFields(logrus.Fields{"storageClass": entry.StorageClassName, "size": entry.Size})
+
+		sc, err := vwpc.ds.GetStorageClassRO(entry.StorageClassName)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				log.Warn("Skipping volume warm pool entry since the storage class is not found")
+				continue
+			}
+			return err
+		}
+		if sc.Provisioner != types.LonghornDriverName {
+			log.Warnf("Skipping volume warm pool entry since the storage class provisioner is %v", sc.Provisioner)
+			continue
+		}
+
+		spec, err := getVolumeWarmPoolSpec(sc.Parameters, entry.Size)
+		if err != nil {
+			log.WithError(err).Warn("Skipping volume warm pool entry with unsupported storage class parameters")
+			continue
+		}
+		specHash := types.GetVolumeWarmPoolSpecHash(*spec)
+		expected[specHash] += entry.Count
+
+		for i := len(pooledVolumes[specHash]); i < expected[specHash]; i++ {
+			if err := vwpc.createPooledVolume(sc, spec); err != nil {
+				return err
+			}
+		}
+	}
+
+	for specHash, vols := range pooledVolumes {
+		for i := expected[specHash]; i < len(vols); i++ {
+			vol := vols[i]
+			vwpc.logger.Infof("Deleting unclaimed volume %v from the warm pool", vol.Name)
+			if err := vwpc.ds.DeleteVolume(vol.Name); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// listPooledVolumes returns the unclaimed pooled volumes grouped by the spec hash, with the newest volumes last
+func (vwpc *VolumeWarmPoolController) listPooledVolumes() (map[string][]*longhorn.Volume, error) {
+	selector, err := labels.Parse(types.GetLonghornLabelKey(types.LonghornLabelVolumeWarmPool))
+	if err != nil {
+		return nil, err
+	}
+	vols, err := vwpc.ds.ListVolumesBySelectorRO(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	pooledVolumes := map[string][]*longhorn.Volume{}
+	for _, vol := range vols {
+		if vol.DeletionTimestamp != nil {
+			continue
+		}
+		specHash := vol.Labels[types.GetLonghornLabelKey(types.LonghornLabelVolumeWarmPool)]
+		pooledVolumes[specHash] = append(pooledVolumes[specHash], vol)
+	}
+	for _, vols := range pooledVolumes {
+		sort.Slice(vols, func(i, j int) bool {
+			return vols[i].CreationTimestamp.Before(&vols[j].CreationTimestamp)
+		})
+	}
+	return pooledVolumes, nil
+}
+
+func (vwpc *VolumeWarmPoolController) createPooledVolume(sc *storagev1.StorageClass, spec *longhorn.VolumeSpec) error {
+	vol := &longhorn.Volume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   volumeWarmPoolVolumePrefix + util.RandomID(),
+			Labels: types.GetVolumeWarmPoolLabels(*spec),
+			Annotations: map[string]string{
+				types.GetLonghornLabelKey(types.LonghornAnnotationVolumeWarmPoolStorageClass): sc.Name,
+			},
+		},
+		Spec: *spec.DeepCopy(),
+	}
+	vol.Spec.BackupTargetName = types.DefaultBackupTargetName
+
+	vol, err := vwpc.ds.CreateVolume(vol)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create pooled volume for storage class %v", sc.Name)
+	}
+	vwpc.logger.Infof("Created volume %v in the warm pool for storage class %v", vol.Name, sc.Name)
+	return nil
+}
+
+// getVolumeWarmPoolSpec converts the StorageClass parameters to the volume spec the CSI driver
+// would request from the volume manager for a new volume of the given size.
+func getVolumeWarmPoolSpec(parameters map[string]string, size int64) (*longhorn.VolumeSpec, error) {
+	for _, unsupported := range []string{"fromBackup", "dataSource", longhorn.BackingImageParameterDataSourceType} {
+		if parameters[unsupported] != "" {
+			return nil, fmt.Errorf("parameter %v is not supported by the volume warm pool", unsupported)
+		}
+	}
+
+	spec, err := types.ParseVolumeParameters(parameters)
+	if err != nil {
+		return nil, err
+	}
+	spec.Size = size
+	if spec.Frontend == "" {
+		spec.Frontend = longhorn.VolumeFrontendBlockDev
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/kubernetes/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestVolumeWarmPoolResponsibleNode(c *C) {
+	testCases := map[string]struct {
+		node1Status longhorn.ConditionStatus
+		node1Reason string
+
+		expectedOwner string
+	}{
+		"first manager node is responsible": {
+			node1Status:   longhorn.ConditionStatusTrue,
+			expectedOwner: TestNode1,
+		},
+		"first manager node is down": {
+			node1Status:   longhorn.ConditionStatusFalse,
+			node1Reason:   string(longhorn.NodeConditionReasonKubernetesNodeNotReady),
+			expectedOwner: TestNode2,
+		},
+		"first manager node is gone": {
+			node1Status:   longhorn.ConditionStatusFalse,
+			node1Reason:   string(longhorn.NodeConditionReasonKubernetesNodeGone),
+			expectedOwner: TestNode2,
+		},
+	}
+
+	for name, tc := range testCases {
+		c.Logf("testing %v", name)
+
+		kubeClient := fake.NewSimpleClientset()
+		lhClient := lhfake.NewSimpleClientset()
+		extensionsClient := apiextensionsfake.NewSimpleClientset()
+		informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+		podIndexer := informerFactories.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+		lhNodeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Nodes().Informer().GetIndexer()
+
+		c.Assert(podIndexer.Add(newDaemonPod(corev1.PodRunning, TestDaemon1, TestNamespace, TestNode1, TestIP1, nil)), IsNil)
+		c.Assert(podIndexer.Add(newDaemonPod(corev1.PodRunning, TestDaemon2, TestNamespace, TestNode2, TestIP2, nil)), IsNil)
+		c.Assert(lhNodeIndexer.Add(newNode(TestNode1, TestNamespace, true, tc.node1Status, tc.node1Reason)), IsNil)
+		c.Assert(lhNodeIndexer.Add(newNode(TestNode2, TestNamespace, true, longhorn.ConditionStatusTrue, "")), IsNil)
+
+		ds := datastore.NewDataStore(TestNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+		for _, nodeID := range []string{TestNode1, TestNode2} {
+			vwpc, err := NewVolumeWarmPoolController(logrus.StandardLogger(), ds, scheme.Scheme, kubeClient, nodeID, TestNamespace)
+			c.Assert(err, IsNil)
+
+			responsibleNodeID, err := getResponsibleNodeID(vwpc.ds)
+			c.Assert(err, IsNil)
+			c.Assert(responsibleNodeID == vwpc.controllerID, Equals, nodeID == tc.expectedOwner)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package csi
+
+import (
+	"fmt"
+	"time"
+)
+
+// The defaults poll at a fixed interval, a larger max interval lowers the load of the waits on the manager
+const (
+	DefaultWaitInitialInterval = 2 * time.Second
+	DefaultWaitMaxInterval     = 2 * time.Second
+	// we wait 1m30s for the volume state polling by default, this leaves 20s for the rest of the function call
+	DefaultWaitTimeout = 90 * time.Second
+)
+
+// WaitBackoff is the polling policy of the waits for a volume to reach a state, e.g. to be attached, detached or
+// created. The polling interval starts from InitialInterval and doubles up to MaxInterval, until Timeout or the
+// deadline of the call.
+type WaitBackoff struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Timeout         time.Duration
+}
+
+func DefaultWaitBackoff() WaitBackoff {
+	return WaitBackoff{
+		InitialInterval: DefaultWaitInitialInterval,
+		MaxInterval:     DefaultWaitMaxInterval,
+		Timeout:         DefaultWaitTimeout,
+	}
+}
+
+func (b WaitBackoff) Validate() error {
+	if b.InitialInterval <= 0 {
+		return fmt.Errorf("invalid initial interval %v", b.InitialInterval)
+	}
+	if b.MaxInterval < b.InitialInterval {
+		return fmt.Errorf("max interval %v is less than initial interval %v", b.MaxInterval, b.InitialInterval)
+	}
+	if b.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout %v", b.Timeout)
+	}
+	return nil
+}
+
+// next returns the interval following the given one
+func (b WaitBackoff) next(interval time.Duration) time.Duration {
+	interval *= 2
+	if interval > b.MaxInterval {
+		return b.MaxInterval
+	}
+	return interval
+}
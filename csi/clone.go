@@ -0,0 +1,338 @@
+package csi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	longhornclient "github.com/longhorn/longhorn-manager/client"
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	"github.com/longhorn/longhorn-manager/types"
+)
+
+const (
+	// crossDataEngineCloneSourceKey is the volume context key of the source volume of a volume cloned from a volume
+	// of the other data engine
+	crossDataEngineCloneSourceKey = "crossDataEngineCloneSource"
+	// crossDataEngineCloneSnapshotName is the snapshot taken on the cloned volume once the data is copied, which
+	// marks the clone as completed
+	crossDataEngineCloneSnapshotName = "cross-data-engine-clone"
+
+	crossDataEngineCloneBlockSize = 4 << 20
+)
+
+// crossDataEngineCloner copies the data between the volumes of different data engines, which cannot be cloned by
+// the engines. Both volumes are attached to the node of the plugin and the block device of the source volume is
+// streamed to the block device of the target volume. Since the copy takes longer than a CSI call, it runs in the
+// background and ControllerPublishVolume doesn't attach the target volume until the copy is completed.
+//
+// The attachment ticket of the target volume records the node copying the data, so the copy is neither started
+// twice by the plugins of different nodes nor lost when the plugin restarts.
+type crossDataEngineCloner struct {
+	cs *ControllerServer
+
+	lock sync.Mutex
+	// clones tracks the copies running in this plugin by target volume, and the error of the failed ones until they
+	// are retried
+	clones map[string]error
+}
+
+var errCloneInProgress = errors.New("clone in progress")
+
+func newCrossDataEngineCloner(cs *ControllerServer) *crossDataEngineCloner {
+	return &crossDataEngineCloner{
+		cs:     cs,
+		clones: map[string]error{},
+	}
+}
+
+// isCrossDataEngineClone returns true if the source volume cannot be cloned by the engine of the target volume
+func isCrossDataEngineClone(sourceVolume *longhornclient.Volume, dataEngine string) bool {
+	sourceDataEngine := sourceVolume.DataEngine
+	if sourceDataEngine == "" {
+		sourceDataEngine = string(longhorn.DataEngineTypeV1)
+	}
+	if dataEngine == "" {
+		dataEngine = string(longhorn.DataEngineTypeV1)
+	}
+	return sourceDataEngine != dataEngine
+}
+
+// validateCrossDataEngineCloneSource requires the source volume not to be used, since the data is copied from its
+// live block device rather than from a snapshot
+func validateCrossDataEngineCloneSource(sourceVolume *longhornclient.Volume, targetVolumeName string) error {
+	attachmentID := generateCloneAttachmentID(sourceVolume.Name, targetVolumeName)
+	for id := range sourceVolume.VolumeAttachment.Attachments {
+		if id != attachmentID {
+			return fmt.Errorf("source volume %v is in use, it must be detached to be cloned to a volume of another data engine", sourceVolume.Name)
+		}
+	}
+	return nil
+}
+
+func generateCloneAttachmentID(sourceVolumeName, targetVolumeName string) string {
+	result := sha256.Sum256([]byte(fmt.Sprintf("%s%s", sourceVolumeName, targetVolumeName)))
+	return fmt.Sprintf("clone-%x", result)
+}
+
+// check returns nil if the clone is completed, errCloneInProgress if it's being copied, or the error of the last
+// failed copy. A copy is started if there is none running, e.g. after the plugin restarted or the copy failed.
+func (c *crossDataEngineCloner) check(ctx context.Context, sourceVolumeName, targetVolumeName string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err, exists := c.clones[targetVolumeName]; exists {
+		if err == nil {
+			return errCloneInProgress
+		}
+		delete(c.clones, targetVolumeName)
+		return err
+	}
+
+	targetVolume, err := c.cs.apiClient.WithContext(ctx).Volume.ById(targetVolumeName)
+	if err != nil {
+		return err
+	}
+	if targetVolume == nil {
+		return fmt.Errorf("volume %v not found", targetVolumeName)
+	}
+	completed, err := c.isCompleted(ctx, targetVolume)
+	if err != nil {
+		return err
+	}
+	if completed {
+		return nil
+	}
+
+	// The copy recorded by the ticket on another node is left to the plugin there, unless the node is gone. The one
+	// recorded on this node without a running copy is interrupted by the restart of the plugin, and is started again.
+	attachmentID := generateCloneAttachmentID(sourceVolumeName, targetVolumeName)
+	if attachment, ok := targetVolume.VolumeAttachment.Attachments[attachmentID]; ok && attachment.NodeID != c.cs.nodeID {
+		isReady, err := c.isNodeReady(ctx, attachment.NodeID)
+		if err != nil {
+			return err
+		}
+		if isReady {
+			return errCloneInProgress
+		}
+		c.cs.log.Warnf("Taking over the clone of volume %v from volume %v from node %v which is not ready",
+			targetVolumeName, sourceVolumeName, attachment.NodeID)
+	}
+
+	c.clones[targetVolumeName] = nil
+	go c.run(sourceVolumeName, targetVolumeName)
+	return errCloneInProgress
+}
+
+func (c *crossDataEngineCloner) isCompleted(ctx context.Context, targetVolume *longhornclient.Volume) (bool, error) {
+	snapshotCRList, err := c.cs.apiClient.WithContext(ctx).Volume.ActionSnapshotCRList(targetVolume)
+	if err != nil {
+		return false, err
+	}
+	for _, snapshotCR := range snapshotCRList.Data {
+		if snapshotCR.Name == crossDataEngineCloneSnapshotName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *crossDataEngineCloner) isNodeReady(ctx context.Context, nodeID string) (bool, error) {
+	node, err := c.cs.apiClient.WithContext(ctx).Node.ById(nodeID)
+	if err != nil {
+		return false, err
+	}
+	if node == nil {
+		return false, nil
+	}
+	condition, _ := node.Conditions[string(longhorn.NodeConditionTypeReady)].(map[string]interface{})
+	status, _ := condition["status"].(string)
+	return status == string(longhorn.ConditionStatusTrue), nil
+}
+
+func (c *crossDataEngineCloner) run(sourceVolumeName, targetVolumeName string) {
+	log := c.cs.log.WithFields(logrus.Fields{
+		"function":     "crossDataEngineClone",
+		"sourceVolume": sourceVolumeName,
+		"targetVolume": targetVolumeName,
+	})
+
+	err := c.clone(context.Background(), sourceVolumeName, targetVolumeName, log)
+	if err != nil {
+		log.WithError(err).Error("Failed to clone volume")
+		err = errors.Wrapf(err, "failed to clone volume %v from volume %v", targetVolumeName, sourceVolumeName)
+	} else {
+		log.Info("Cloned volume")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err != nil {
+		c.clones[targetVolumeName] = err
+	} else {
+		delete(c.clones, targetVolumeName)
+	}
+}
+
+func (c *crossDataEngineCloner) clone(ctx context.Context, sourceVolumeName, targetVolumeName string, log *logrus.Entry) error {
+	attachmentID := generateCloneAttachmentID(sourceVolumeName, targetVolumeName)
+
+	sourceVolume, err := c.cs.apiClient.WithContext(ctx).Volume.ById(sourceVolumeName)
+	if err != nil {
+		return err
+	}
+	if sourceVolume == nil {
+		return fmt.Errorf("source volume %v not found", sourceVolumeName)
+	}
+	if err := validateCrossDataEngineCloneSource(sourceVolume, targetVolumeName); err != nil {
+		return err
+	}
+
+	log.Infof("Attaching volumes to node %v for the clone", c.cs.nodeID)
+	sourceDevice, err := c.attach(ctx, sourceVolumeName, attachmentID)
+	defer c.detach(ctx, sourceVolumeName, attachmentID, log)
+	if err != nil {
+		return err
+	}
+	targetDevice, err := c.attach(ctx, targetVolumeName, attachmentID)
+	defer c.detach(ctx, targetVolumeName, attachmentID, log)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Copying data from device %v to device %v", sourceDevice, targetDevice)
+	copied, err := copyBlockDevice(sourceDevice, targetDevice)
+	if err != nil {
+		return err
+	}
+	log.Infof("Copied %v bytes of data", copied)
+
+	targetVolume, err := c.cs.apiClient.WithContext(ctx).Volume.ById(targetVolumeName)
+	if err != nil {
+		return err
+	}
+	if targetVolume == nil {
+		return fmt.Errorf("volume %v not found", targetVolumeName)
+	}
+	_, err = c.cs.apiClient.WithContext(ctx).Volume.ActionSnapshotCreate(targetVolume, &longhornclient.SnapshotInput{
+		Name:   crossDataEngineCloneSnapshotName,
+		Labels: map[string]string{types.GetLonghornLabelKey(types.LonghornLabelCrossDataEngineCloneSource): sourceVolumeName},
+	})
+	return err
+}
+
+// attach attaches the volume to the node of the plugin and returns its block device
+func (c *crossDataEngineCloner) attach(ctx context.Context, volumeName, attachmentID string) (string, error) {
+	volume, err := c.cs.apiClient.WithContext(ctx).Volume.ById(volumeName)
+	if err != nil {
+		return "", err
+	}
+	if volume == nil {
+		return "", fmt.Errorf("volume %v not found", volumeName)
+	}
+	if volume.Frontend != string(longhorn.VolumeFrontendBlockDev) && volume.Frontend != string(longhorn.VolumeFrontendNvmf) {
+		return "", fmt.Errorf("volume %v frontend %v is not supported for the clone", volumeName, volume.Frontend)
+	}
+
+	if _, err := c.cs.apiClient.WithContext(ctx).Volume.ActionAttach(volume, &longhornclient.AttachInput{
+		HostId:       c.cs.nodeID,
+		AttacherType: string(longhorn.AttacherTypeCrossDataEngineCloner),
+		AttachmentID: attachmentID,
+	}); err != nil {
+		return "", err
+	}
+
+	checkVolumeAttached := func(vol *longhornclient.Volume) bool {
+		attachment, ok := vol.VolumeAttachment.Attachments[attachmentID]
+		return ok && attachment.Satisfied && isVolumeAvailableOn(vol, c.cs.nodeID)
+	}
+	if !c.cs.waitForVolumeState(ctx, volumeName, "volume attached for clone", checkVolumeAttached, false, false) {
+		return "", fmt.Errorf("timed out waiting for volume %v to be attached to node %v", volumeName, c.cs.nodeID)
+	}
+
+	volume, err = c.cs.apiClient.WithContext(ctx).Volume.ById(volumeName)
+	if err != nil {
+		return "", err
+	}
+	devicePath := volume.Controllers[0].Endpoint
+	if isNvmfEndpoint(devicePath) {
+		return connectNvmfTarget(devicePath)
+	}
+	return devicePath, nil
+}
+
+func (c *crossDataEngineCloner) detach(ctx context.Context, volumeName, attachmentID string, log *logrus.Entry) {
+	volume, err := c.cs.apiClient.WithContext(ctx).Volume.ById(volumeName)
+	if err != nil || volume == nil {
+		log.WithError(err).Warnf("Failed to get volume %v to detach it", volumeName)
+		return
+	}
+	if _, ok := volume.VolumeAttachment.Attachments[attachmentID]; !ok {
+		return
+	}
+
+	for _, controller := range volume.Controllers {
+		if controller.HostId == c.cs.nodeID && isNvmfEndpoint(controller.Endpoint) {
+			if err := disconnectNvmfTarget(controller.Endpoint); err != nil {
+				log.WithError(err).Warnf("Failed to disconnect volume %v from NVMe-oF endpoint %v", volumeName, controller.Endpoint)
+			}
+		}
+	}
+
+	if _, err := c.cs.apiClient.WithContext(ctx).Volume.ActionDetach(volume, &longhornclient.DetachInput{
+		AttachmentID: attachmentID,
+		HostId:       c.cs.nodeID,
+	}); err != nil {
+		log.WithError(err).Warnf("Failed to detach volume %v", volumeName)
+	}
+}
+
+// copyBlockDevice copies the source device to the target device, which is newly created. The blocks of zeros are
+// skipped, so the target volume is as sparse as the source one.
+func copyBlockDevice(sourceDevice, targetDevice string) (int64, error) {
+	source, err := os.Open(sourceDevice)
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(targetDevice, os.O_WRONLY, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer target.Close()
+
+	buf := make([]byte, crossDataEngineCloneBlockSize)
+	zero := make([]byte, crossDataEngineCloneBlockSize)
+	var offset, copied int64
+	for {
+		n, err := io.ReadFull(source, buf)
+		if n > 0 {
+			if !bytes.Equal(buf[:n], zero[:n]) {
+				if _, err := target.WriteAt(buf[:n], offset); err != nil {
+					return copied, errors.Wrapf(err, "failed to write device %v at offset %v", targetDevice, offset)
+				}
+				copied += int64(n)
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return copied, errors.Wrapf(err, "failed to read device %v at offset %v", sourceDevice, offset)
+		}
+	}
+
+	if err := target.Sync(); err != nil {
+		return copied, errors.Wrapf(err, "failed to sync device %v", targetDevice)
+	}
+	return copied, nil
+}
@@ -0,0 +1,118 @@
+package csi
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	longhornclient "github.com/longhorn/longhorn-manager/client"
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+func TestIsCrossDataEngineClone(t *testing.T) {
+	tests := map[string]struct {
+		sourceDataEngine string
+		dataEngine       string
+		expected         bool
+	}{
+		"both default":          {expected: false},
+		"default and v1":        {dataEngine: string(longhorn.DataEngineTypeV1), expected: false},
+		"v2 and v2":             {sourceDataEngine: string(longhorn.DataEngineTypeV2), dataEngine: string(longhorn.DataEngineTypeV2), expected: false},
+		"default source and v2": {dataEngine: string(longhorn.DataEngineTypeV2), expected: true},
+		"v2 source and default": {sourceDataEngine: string(longhorn.DataEngineTypeV2), expected: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sourceVolume := &longhornclient.Volume{DataEngine: tc.sourceDataEngine}
+			require.Equal(t, tc.expected, isCrossDataEngineClone(sourceVolume, tc.dataEngine))
+		})
+	}
+}
+
+func TestValidateCrossDataEngineCloneSource(t *testing.T) {
+	sourceVolume := &longhornclient.Volume{Name: "source"}
+	require.NoError(t, validateCrossDataEngineCloneSource(sourceVolume, "target"))
+
+	// The source volume attached for the clone itself is not in use
+	sourceVolume.VolumeAttachment.Attachments = map[string]longhornclient.Attachment{
+		generateCloneAttachmentID("source", "target"): {},
+	}
+	require.NoError(t, validateCrossDataEngineCloneSource(sourceVolume, "target"))
+
+	sourceVolume.VolumeAttachment.Attachments["csi-workload"] = longhornclient.Attachment{}
+	require.Error(t, validateCrossDataEngineCloneSource(sourceVolume, "target"))
+}
+
+func TestCopyBlockDevice(t *testing.T) {
+	block := func(b byte) []byte {
+		return bytes.Repeat([]byte{b}, crossDataEngineCloneBlockSize)
+	}
+
+	tests := map[string]struct {
+		source         []byte
+		expectedCopied int64
+	}{
+		"empty device": {
+			source:         []byte{},
+			expectedCopied: 0,
+		},
+		"zero device": {
+			source:         block(0),
+			expectedCopied: 0,
+		},
+		"zero block skipped": {
+			source:         append(append(block(1), block(0)...), block(2)...),
+			expectedCopied: 2 * crossDataEngineCloneBlockSize,
+		},
+		"partial last block": {
+			source:         append(block(1), []byte("tail")...),
+			expectedCopied: crossDataEngineCloneBlockSize + 4,
+		},
+		"partial zero last block": {
+			source:         append(block(1), make([]byte, 512)...),
+			expectedCopied: crossDataEngineCloneBlockSize,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			sourceDevice := filepath.Join(dir, "source")
+			targetDevice := filepath.Join(dir, "target")
+			require.NoError(t, os.WriteFile(sourceDevice, tc.source, 0600))
+			// The target device is newly created with the same size as the source device
+			require.NoError(t, os.WriteFile(targetDevice, nil, 0600))
+			require.NoError(t, os.Truncate(targetDevice, int64(len(tc.source))))
+
+			copied, err := copyBlockDevice(sourceDevice, targetDevice)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCopied, copied)
+
+			target, err := os.ReadFile(targetDevice)
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(tc.source, target))
+		})
+	}
+}
+
+func TestCopyBlockDeviceFailure(t *testing.T) {
+	dir := t.TempDir()
+	sourceDevice := filepath.Join(dir, "source")
+	targetDevice := filepath.Join(dir, "target")
+
+	_, err := copyBlockDevice(sourceDevice, targetDevice)
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(sourceDevice, []byte("data"), 0600))
+	_, err = copyBlockDevice(sourceDevice, targetDevice)
+	require.Error(t, err, "the target device is not created by the copy")
+
+	// Reading a directory fails after it's opened
+	require.NoError(t, os.WriteFile(targetDevice, nil, 0600))
+	_, err = copyBlockDevice(dir, targetDevice)
+	require.ErrorContains(t, err, "failed to read device")
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +19,13 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/client-go/rest"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+
+	etypes "github.com/longhorn/longhorn-engine/pkg/types"
 
 	"github.com/longhorn/longhorn-manager/datastore"
 	"github.com/longhorn/longhorn-manager/types"
@@ -28,9 +36,6 @@ import (
 )
 
 const (
-	// we wait 1m30s for the volume state polling, this leaves 20s for the rest of the function call
-	timeoutAttachDetach         = 90 * time.Second
-	tickAttachDetach            = 2 * time.Second
 	timeoutBackupControllerSync = 30 * time.Second
 	tickBackupControllerSync    = 2 * time.Second
 	backupStateCompleted        = "Completed"
@@ -43,21 +48,40 @@ const (
 	csiSnapshotTypeLonghornBackingImage     = "bi"
 	csiSnapshotTypeLonghornBackup           = "bak"
 	deprecatedCSISnapshotTypeLonghornBackup = "bs"
+
+	// The PVC of the volume is passed in the parameters when the provisioner runs with --extra-create-metadata
+	pvcNameKey      = "csi.storage.k8s.io/pvc/name"
+	pvcNamespaceKey = "csi.storage.k8s.io/pvc/namespace"
 )
 
 type ControllerServer struct {
 	csi.UnimplementedControllerServer
 	apiClient   *longhornclient.RancherClient
+	kubeClient  *clientset.Clientset
 	nodeID      string
 	caps        []*csi.ControllerServiceCapability
 	accessModes []*csi.VolumeCapability_AccessMode
+	waitBackoff WaitBackoff
+	cloner      *crossDataEngineCloner
 	log         *logrus.Entry
 }
 
-func NewControllerServer(apiClient *longhornclient.RancherClient, nodeID string) *ControllerServer {
-	return &ControllerServer{
-		apiClient: apiClient,
-		nodeID:    nodeID,
+func NewControllerServer(apiClient *longhornclient.RancherClient, nodeID string, waitBackoff WaitBackoff) (*ControllerServer, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get client config")
+	}
+
+	kubeClient, err := clientset.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get k8s client")
+	}
+
+	cs := &ControllerServer{
+		apiClient:   apiClient,
+		kubeClient:  kubeClient,
+		nodeID:      nodeID,
+		waitBackoff: waitBackoff,
 		caps: getControllerServiceCapabilities(
 			[]csi.ControllerServiceCapability_RPC_Type{
 				csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
@@ -65,6 +89,11 @@ func NewControllerServer(apiClient *longhornclient.RancherClient, nodeID string)
 				csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
 				csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
 				csi.ControllerServiceCapability_RPC_CLONE_VOLUME,
+				csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
+				csi.ControllerServiceCapability_RPC_LIST_VOLUMES_PUBLISHED_NODES,
+				csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS,
+				csi.ControllerServiceCapability_RPC_GET_VOLUME,
+				csi.ControllerServiceCapability_RPC_VOLUME_CONDITION,
 			}),
 		accessModes: getVolumeCapabilityAccessModes(
 			[]csi.VolumeCapability_AccessMode_Mode{
@@ -73,6 +102,8 @@ func NewControllerServer(apiClient *longhornclient.RancherClient, nodeID string)
 			}),
 		log: logrus.StandardLogger().WithField("component", "csi-controller-server"),
 	}
+	cs.cloner = newCrossDataEngineCloner(cs)
+	return cs, nil
 }
 
 func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
@@ -92,6 +123,9 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 	if volumeParameters == nil {
 		volumeParameters = map[string]string{}
 	}
+	if err := cs.applyPVCVolumeParameterOverrides(ctx, volumeParameters); err != nil {
+		return nil, err
+	}
 	var reqVolSizeBytes int64
 	if req.GetCapacityRange() != nil {
 		reqVolSizeBytes = req.GetCapacityRange().GetRequiredBytes()
@@ -120,6 +154,9 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 					if id == "" {
 						return nil, status.Errorf(codes.NotFound, "volume source snapshot %v is not found", snapshot.SnapshotId)
 					}
+					if err := cs.validateRestoreFromSnapshot(ctx, snapshot.SnapshotId, sourceVolumeName, volumeParameters, reqVolSizeBytes); err != nil {
+						return nil, err
+					}
 					dataSource, _ := types.NewVolumeDataSource(longhorn.VolumeDataSourceTypeSnapshot, map[string]string{types.VolumeNameKey: sourceVolumeName, types.SnapshotNameKey: id})
 					volumeParameters["dataSource"] = string(dataSource)
 				case csiSnapshotTypeLonghornBackup:
@@ -127,7 +164,7 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 						return nil, status.Errorf(codes.NotFound, "volume source snapshot %v is not found", snapshot.SnapshotId)
 					}
 					backupName := id
-					bvs, err := cs.getBackupVolumes(sourceVolumeName)
+					bvs, err := cs.getBackupVolumes(ctx, sourceVolumeName)
 					if err != nil {
 						return nil, status.Errorf(codes.Internal, "failed to retrieve backup volumes of volume %v: %v", sourceVolumeName, err)
 					}
@@ -136,7 +173,7 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 					}
 					var backup *longhornclient.Backup
 					for _, bv := range bvs {
-						backup, err = cs.apiClient.BackupVolume.ActionBackupGet(bv, &longhornclient.BackupInput{Name: backupName})
+						backup, err = cs.apiClient.WithContext(ctx).BackupVolume.ActionBackupGet(bv, &longhornclient.BackupInput{Name: backupName})
 						if err != nil {
 							return nil, status.Errorf(codes.NotFound, "failed to restore CSI snapshot %v : failed to get backup %v: %v", snapshot.SnapshotId, backupName, err)
 						}
@@ -148,9 +185,17 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 						return nil, status.Errorf(codes.NotFound, "failed to restore CSI snapshot %v backup %s unavailable", snapshot.SnapshotId, backupName)
 					}
 
+					if err := cs.validateRestoreFromBackup(ctx, snapshot.SnapshotId, sourceVolumeName, backup, volumeParameters, reqVolSizeBytes); err != nil {
+						return nil, err
+					}
+
 					// use the fromBackup method for the csi snapshot restores as well
 					// the same parameter was previously only used for restores based on the storage class
 					volumeParameters["fromBackup"] = backup.Url
+					// The access mode is set by the requested capabilities rather than inherited from the backup
+					if volumeParameters["share"] == "" {
+						volumeParameters["share"] = "false"
+					}
 				default:
 					return nil, status.Errorf(codes.InvalidArgument, "invalid CSI snapshot type: %v. Must be %v, %v or %v",
 						csiSnapshotType, csiSnapshotTypeLonghornSnapshot, csiSnapshotTypeLonghornBackup, csiSnapshotTypeLonghornBackingImage)
@@ -158,7 +203,7 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 			}
 		case *csi.VolumeContentSource_Volume:
 			if srcVolume := volumeSource.GetVolume(); srcVolume != nil {
-				longhornSrcVol, err := cs.apiClient.Volume.ById(srcVolume.VolumeId)
+				longhornSrcVol, err := cs.apiClient.WithContext(ctx).Volume.ById(srcVolume.VolumeId)
 				if err != nil {
 					return nil, status.Errorf(codes.NotFound, "failed to clone volume: source volume %s is unavailable", srcVolume.VolumeId)
 				}
@@ -175,6 +220,15 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 					return nil, status.Errorf(codes.OutOfRange, "failed to clone volume: the requested size (%v bytes) is different than the source volume size (%v bytes)", reqVolSizeBytes, srcVolSizeBytes)
 				}
 
+				// The engines cannot clone a volume of the other data engine, the data is copied by the plugin instead
+				if isCrossDataEngineClone(longhornSrcVol, volumeParameters["dataEngine"]) {
+					if err := validateCrossDataEngineCloneSource(longhornSrcVol, volumeID); err != nil {
+						return nil, status.Errorf(codes.FailedPrecondition, "failed to clone volume: %v", err)
+					}
+					volumeParameters[crossDataEngineCloneSourceKey] = srcVolume.VolumeId
+					break
+				}
+
 				dataSource, _ := types.NewVolumeDataSource(longhorn.VolumeDataSourceTypeVolume, map[string]string{types.VolumeNameKey: srcVolume.VolumeId})
 				volumeParameters["dataSource"] = string(dataSource)
 			}
@@ -192,7 +246,7 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 		}
 	}
 
-	existVol, err := cs.apiClient.Volume.ById(volumeID)
+	existVol, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeID)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -212,6 +266,7 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 		// We won't wait for clone/restore to complete but return OK immediately here so that
 		// if Kubernetes wants to abort/delete the cloning/restoring volume, it has the volume ID and is able to do so.
 		// We will wait for clone/restore to complete inside ControllerPublishVolume.
+		cs.startCrossDataEngineClone(ctx, volumeParameters, existVol.Id)
 		rsp := &csi.CreateVolumeResponse{
 			Volume: &csi.Volume{
 				VolumeId:      existVol.Id,
@@ -229,6 +284,11 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 	for _, cap := range volumeCaps {
 		if requiresSharedAccess(nil, cap) {
 			volumeParameters["share"] = "true"
+			if cap.GetBlock() != nil {
+				if err := setRWXBlockVolumeParameters(volumeParameters); err != nil {
+					return nil, status.Error(codes.InvalidArgument, err.Error())
+				}
+			}
 			break
 		}
 	}
@@ -238,16 +298,39 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	if err = cs.checkAndPrepareBackingImage(volumeID, vol.BackingImage, volumeParameters, vol.DataEngine); err != nil {
+	if err := cs.validateVolumeImport(ctx, vol, volumeParameters, volumeSource); err != nil {
+		return nil, err
+	}
+
+	// The recurring jobs of the StorageClass replace the ones recorded in the backup
+	if vol.FromBackup != "" && len(vol.RecurringJobSelector) > 0 {
+		vol.RestoreVolumeRecurringJob = string(longhorn.RestoreVolumeRecurringJobDisabled)
+	}
+
+	// The restore credential is passed along with the request rather than the volume parameters, since the
+	// parameters are recorded in the PV
+	if vol.FromBackup != "" {
+		if vol.RestoreCredential, err = getRestoreCredential(req.GetSecrets()); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err := requestRestoreServiceAccountToken(ctx, cs.kubeClient, volumeParameters[pvcNamespaceKey], vol.RestoreCredential); err != nil {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+	}
+
+	if err = cs.checkAndPrepareBackingImage(ctx, volumeID, vol.BackingImage, volumeParameters, vol.DataEngine); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	vol.Name = volumeID
 	vol.Size = fmt.Sprintf("%d", reqVolSizeBytes)
+	// Only the volumes provisioned for PVCs adopt pooled volumes, since the CSI volume handle is the name of the
+	// returned volume rather than the requested one
+	vol.AdoptWarmPoolVolume = true
 
 	log.Infof("Creating a volume by API client, name: %s, size: %s, accessMode: %v, dataEngine: %v",
 		vol.Name, vol.Size, vol.AccessMode, vol.DataEngine)
-	resVol, err := cs.apiClient.Volume.Create(vol)
+	resVol, err := cs.apiClient.WithContext(ctx).Volume.Create(vol)
 	// TODO: implement error response code for Longhorn API to differentiate different error type.
 	// For example, creating a volume from a non-existing snapshot should return codes.NotFound instead of codes.Internal
 	if err != nil {
@@ -262,10 +345,12 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 		return vol.State != "" && vol.State != string(longhorn.VolumeStateCreating)
 	}
 
-	if !cs.waitForVolumeState(resVol.Id, "volume created", checkVolumeCreated, true, false) {
+	if !cs.waitForVolumeState(ctx, resVol.Id, "volume created", checkVolumeCreated, true, false) {
 		return nil, status.Error(codes.DeadlineExceeded, "failed to wait for volume creation to complete")
 	}
 
+	cs.startCrossDataEngineClone(ctx, volumeParameters, resVol.Id)
+
 	return &csi.CreateVolumeResponse{
 		Volume: &csi.Volume{
 			VolumeId:      resVol.Id,
@@ -276,10 +361,79 @@ func (cs *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVol
 	}, nil
 }
 
-func (cs *ControllerServer) getBackupVolumes(volumeName string) ([]*longhornclient.BackupVolume, error) {
+// applyPVCVolumeParameterOverrides overrides the StorageClass parameters with the parameter annotations of the PVC
+// the volume is provisioned for. The annotations are already validated by the PVC webhook.
+func (cs *ControllerServer) applyPVCVolumeParameterOverrides(ctx context.Context, volumeParameters map[string]string) error {
+	pvcName, pvcNamespace := volumeParameters[pvcNameKey], volumeParameters[pvcNamespaceKey]
+	if pvcName == "" || pvcNamespace == "" {
+		return nil
+	}
+
+	pvc, err := cs.kubeClient.CoreV1().PersistentVolumeClaims(pvcNamespace).Get(ctx, pvcName, metav1.GetOptions{})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get PVC %v/%v: %v", pvcNamespace, pvcName, err)
+	}
+
+	overrides, err := types.GetPVCVolumeParameterOverrides(pvc.Annotations)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to override parameters by PVC %v/%v: %v", pvcNamespace, pvcName, err)
+	}
+	for name, value := range overrides {
+		cs.log.Infof("Overriding parameter %v from %q to %q by PVC %v/%v", name, volumeParameters[name], value, pvcNamespace, pvcName)
+		volumeParameters[name] = value
+	}
+	return nil
+}
+
+// startCrossDataEngineClone starts copying the data of a volume cloned from a volume of the other data engine, so
+// the copy is done or in progress by the time the volume is published
+func (cs *ControllerServer) startCrossDataEngineClone(ctx context.Context, volumeParameters map[string]string, volumeName string) {
+	sourceVolumeName := volumeParameters[crossDataEngineCloneSourceKey]
+	if sourceVolumeName == "" {
+		return
+	}
+	if err := cs.cloner.check(ctx, sourceVolumeName, volumeName); err != nil && !errors.Is(err, errCloneInProgress) {
+		cs.log.WithError(err).Warnf("Failed to start cloning volume %v from volume %v", volumeName, sourceVolumeName)
+	}
+}
+
+// validateVolumeImport validates the import of a local block device or file image into a new volume. The source must
+// be inside the directories allowed by the admin. The data is copied into the block device of the volume by the node
+// server, so the volume cannot have any other data source and cannot be encrypted or shared.
+func (cs *ControllerServer) validateVolumeImport(ctx context.Context, vol *longhornclient.Volume, volumeParameters map[string]string, volumeSource *csi.VolumeContentSource) error {
+	importSource, importNode := volumeParameters[volumeImportSourceKey], volumeParameters[volumeImportNodeKey]
+	if importSource == "" && importNode == "" {
+		return nil
+	}
+	if importSource == "" || importNode == "" {
+		return status.Errorf(codes.InvalidArgument, "both %v and %v are required to import a volume", volumeImportSourceKey, volumeImportNodeKey)
+	}
+	if volumeSource != nil || vol.FromBackup != "" || vol.DataSource != "" || vol.BackingImage != "" {
+		return status.Error(codes.InvalidArgument, "cannot import a volume with another data source")
+	}
+	if vol.Encrypted || vol.AccessMode == string(longhorn.AccessModeReadWriteMany) {
+		return status.Error(codes.InvalidArgument, "cannot import an encrypted or shared volume")
+	}
+
+	allowedDirectories, err := getVolumeImportAllowedSourceDirectories(ctx, cs.apiClient)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if !types.IsVolumeImportSourceAllowed(importSource, allowedDirectories) {
+		return status.Errorf(codes.InvalidArgument, "import source %v is not inside the directories of setting %v", importSource, types.SettingNameVolumeImportAllowedSourceDirectories)
+	}
+
+	// TODO: #1875 API returns error instead of not found, so we cannot differentiate between a retrieval failure and non existing resource
+	if _, err := cs.apiClient.WithContext(ctx).Node.ById(importNode); err != nil {
+		return status.Errorf(codes.NotFound, "import node %s not found", importNode)
+	}
+	return nil
+}
+
+func (cs *ControllerServer) getBackupVolumes(ctx context.Context, volumeName string) ([]*longhornclient.BackupVolume, error) {
 	bvs := []*longhornclient.BackupVolume{}
 	log := cs.log.WithFields(logrus.Fields{"function": "getBackupVolume"})
-	list, err := cs.apiClient.BackupVolume.List(&longhornclient.ListOpts{})
+	list, err := cs.apiClient.WithContext(ctx).BackupVolume.List(&longhornclient.ListOpts{})
 	if err != nil {
 		return nil, err
 	}
@@ -294,7 +448,67 @@ func (cs *ControllerServer) getBackupVolumes(volumeName string) ([]*longhornclie
 	return bvs, nil
 }
 
-func (cs *ControllerServer) checkAndPrepareBackingImage(volumeName, backingImageName string, volumeParameters map[string]string, dataEngine string) error {
+// validateRestoreFromSnapshot validates the volume cloned from a CSI snapshot with the parameters of its StorageClass.
+// The clone may have different replicas or data locality than the source volume, but the engine can only clone a
+// snapshot into a volume of the same size and data engine.
+func (cs *ControllerServer) validateRestoreFromSnapshot(ctx context.Context, snapshotID, sourceVolumeName string, volumeParameters map[string]string, reqVolSizeBytes int64) error {
+	sourceVolume, err := cs.apiClient.WithContext(ctx).Volume.ById(sourceVolumeName)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to restore CSI snapshot %v: failed to get source volume %v: %v", snapshotID, sourceVolumeName, err)
+	}
+	if sourceVolume == nil {
+		return status.Errorf(codes.NotFound, "failed to restore CSI snapshot %v: source volume %v is not found", snapshotID, sourceVolumeName)
+	}
+
+	sourceVolSizeBytes, err := strconv.ParseInt(sourceVolume.Size, 10, 64)
+	if err != nil {
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	if reqVolSizeBytes != sourceVolSizeBytes {
+		return status.Errorf(codes.OutOfRange, "failed to restore CSI snapshot %v: the requested size (%v bytes) is different than the source volume size (%v bytes)",
+			snapshotID, reqVolSizeBytes, sourceVolSizeBytes)
+	}
+	if isCrossDataEngineClone(sourceVolume, volumeParameters["dataEngine"]) {
+		return status.Errorf(codes.InvalidArgument, "failed to restore CSI snapshot %v: the data engine %v is different than the source volume data engine %v",
+			snapshotID, volumeParameters["dataEngine"], sourceVolume.DataEngine)
+	}
+	return validateRestoreEncryption(snapshotID, sourceVolume, volumeParameters)
+}
+
+// validateRestoreFromBackup validates the volume restored from a CSI snapshot backup with the parameters of its
+// StorageClass. The backup cannot be restored into a volume smaller than the backed up volume.
+func (cs *ControllerServer) validateRestoreFromBackup(ctx context.Context, snapshotID, sourceVolumeName string, backup *longhornclient.Backup, volumeParameters map[string]string, reqVolSizeBytes int64) error {
+	if backup.VolumeSize != "" {
+		backupVolSizeBytes, err := util.ConvertSize(backup.VolumeSize)
+		if err != nil {
+			return status.Errorf(codes.Internal, "%v", err)
+		}
+		if reqVolSizeBytes < backupVolSizeBytes {
+			return status.Errorf(codes.OutOfRange, "failed to restore CSI snapshot %v: the requested size (%v bytes) is smaller than the backup volume size (%v bytes)",
+				snapshotID, reqVolSizeBytes, backupVolSizeBytes)
+		}
+	}
+
+	// The backup doesn't record the encryption, so it can only be checked while the source volume exists
+	sourceVolume, err := cs.apiClient.WithContext(ctx).Volume.ById(sourceVolumeName)
+	if err != nil || sourceVolume == nil {
+		return nil
+	}
+	return validateRestoreEncryption(snapshotID, sourceVolume, volumeParameters)
+}
+
+// validateRestoreEncryption rejects the restores which would encrypt or decrypt the data, since the data is copied
+// as is from the source volume
+func validateRestoreEncryption(snapshotID string, sourceVolume *longhornclient.Volume, volumeParameters map[string]string) error {
+	encrypted, _ := strconv.ParseBool(volumeParameters["encrypted"])
+	if encrypted != sourceVolume.Encrypted {
+		return status.Errorf(codes.InvalidArgument, "failed to restore CSI snapshot %v: the encryption %v is different than the source volume %v encryption %v",
+			snapshotID, encrypted, sourceVolume.Name, sourceVolume.Encrypted)
+	}
+	return nil
+}
+
+func (cs *ControllerServer) checkAndPrepareBackingImage(ctx context.Context, volumeName, backingImageName string, volumeParameters map[string]string, dataEngine string) error {
 	if backingImageName == "" {
 		return nil
 	}
@@ -309,7 +523,7 @@ func (cs *ControllerServer) checkAndPrepareBackingImage(volumeName, backingImage
 	}
 
 	// There will be an empty BackingImage object rather than nil returned even if there is an error
-	existingBackingImage, err := cs.apiClient.BackingImage.ById(backingImageName)
+	existingBackingImage, err := cs.apiClient.WithContext(ctx).BackingImage.ById(backingImageName)
 	if err != nil && !strings.Contains(err.Error(), "not found") {
 		return fmt.Errorf("volume %s is unable to retrieve backing image %s: %v", volumeName, backingImageName, err)
 	}
@@ -356,7 +570,7 @@ func (cs *ControllerServer) checkAndPrepareBackingImage(volumeName, backingImage
 			backingImage.DiskSelector = strings.Split(diskSelector, ",")
 		}
 
-		_, err = cs.apiClient.BackingImage.Create(backingImage)
+		_, err = cs.apiClient.WithContext(ctx).BackingImage.Create(backingImage)
 		return err
 	}
 
@@ -383,7 +597,7 @@ func (cs *ControllerServer) DeleteVolume(ctx context.Context, req *csi.DeleteVol
 		return nil, status.Error(codes.InvalidArgument, "volume id missing in request")
 	}
 
-	existVol, err := cs.apiClient.Volume.ById(volumeID)
+	existVol, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeID)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -392,14 +606,14 @@ func (cs *ControllerServer) DeleteVolume(ctx context.Context, req *csi.DeleteVol
 	}
 
 	log.Infof("Deleting volume %v", volumeID)
-	if err = cs.apiClient.Volume.Delete(existVol); err != nil {
+	if err = cs.apiClient.WithContext(ctx).Volume.Delete(existVol); err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	checkVolumeDeleted := func(vol *longhornclient.Volume) bool {
 		return vol == nil
 	}
-	if !cs.waitForVolumeState(req.GetVolumeId(), "volume deleted", checkVolumeDeleted, false, true) {
+	if !cs.waitForVolumeState(ctx, req.GetVolumeId(), "volume deleted", checkVolumeDeleted, false, true) {
 		return nil, status.Errorf(codes.DeadlineExceeded, "failed to delete volume %s", volumeID)
 	}
 
@@ -418,7 +632,7 @@ func (cs *ControllerServer) ValidateVolumeCapabilities(ctx context.Context, req
 		return nil, status.Error(codes.InvalidArgument, "volume id missing in request")
 	}
 
-	existVol, err := cs.apiClient.Volume.ById(volumeID)
+	existVol, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeID)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -461,11 +675,11 @@ func (cs *ControllerServer) ControllerPublishVolume(ctx context.Context, req *cs
 	}
 
 	// TODO: #1875 API returns error instead of not found, so we cannot differentiate between a retrieval failure and non existing resource
-	if _, err := cs.apiClient.Node.ById(nodeID); err != nil {
+	if _, err := cs.apiClient.WithContext(ctx).Node.ById(nodeID); err != nil {
 		return nil, status.Errorf(codes.NotFound, "node %s not found", nodeID)
 	}
 
-	volume, err := cs.apiClient.Volume.ById(volumeID)
+	volume, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeID)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -473,12 +687,12 @@ func (cs *ControllerServer) ControllerPublishVolume(ctx context.Context, req *cs
 		return nil, status.Errorf(codes.NotFound, "volume %s not found", volumeID)
 	}
 
-	if volume.Frontend != string(longhorn.VolumeFrontendBlockDev) && volume.Frontend != "ublk" {
+	if !isSupportedVolumeFrontend(volume) {
 		return nil, status.Errorf(codes.InvalidArgument, "volume %s invalid frontend type %s", volumeID, volume.Frontend)
 	}
 
 	if requiresSharedAccess(volume, volumeCapability) {
-		volume, err = cs.updateVolumeAccessMode(volume, longhorn.AccessModeReadWriteMany)
+		volume, err = cs.updateVolumeAccessMode(ctx, volume, longhorn.AccessModeReadWriteMany)
 		if err != nil {
 			return nil, err
 		}
@@ -488,13 +702,33 @@ func (cs *ControllerServer) ControllerPublishVolume(ctx context.Context, req *cs
 	// TODO: JM should readiness be handled by the caller?
 	//  Most of the readiness conditions are covered by the attach, except auto attachment which requires changes to the design
 	//  should be handled by the processing of the api return codes
+	if sourceVolumeName := req.GetVolumeContext()[crossDataEngineCloneSourceKey]; sourceVolumeName != "" {
+		if err := cs.cloner.check(ctx, sourceVolumeName, volumeID); err != nil {
+			if errors.Is(err, errCloneInProgress) {
+				return nil, status.Errorf(codes.Aborted, "volume %s is being cloned from volume %s", volumeID, sourceVolumeName)
+			}
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	// The data is imported from the node of the import source when the volume is staged for the first time
+	if importNode := req.GetVolumeContext()[volumeImportNodeKey]; importNode != "" && importNode != nodeID {
+		imported, err := isVolumeImported(ctx, cs.apiClient, volume)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if !imported {
+			return nil, status.Errorf(codes.FailedPrecondition, "volume %s must be published to node %s to import its data first", volumeID, importNode)
+		}
+	}
+
 	if !volume.Ready {
 		return nil, status.Errorf(codes.Aborted, "volume %s is not ready for workloads", volumeID)
 	}
 
 	attachmentID := generateAttachmentID(volumeID, nodeID)
 
-	return cs.publishVolume(volume, nodeID, attachmentID, func() error {
+	return cs.publishVolume(ctx, volume, nodeID, attachmentID, func() error {
 		checkVolumePublished := func(vol *longhornclient.Volume) bool {
 			isRegularRWXVolume := vol.AccessMode == string(longhorn.AccessModeReadWriteMany) && !vol.Migratable
 			attachment, ok := vol.VolumeAttachment.Attachments[attachmentID]
@@ -503,9 +737,9 @@ func (cs *ControllerServer) ControllerPublishVolume(ctx context.Context, req *cs
 			}
 			return ok && attachment.Satisfied && isVolumeAvailableOn(vol, nodeID)
 		}
-		if !cs.waitForVolumeState(volumeID, "volume published", checkVolumePublished, false, false) {
+		if !cs.waitForVolumeState(ctx, volumeID, "volume published", checkVolumePublished, false, false) {
 			// check if there is error while attaching
-			if existVol, err := cs.apiClient.Volume.ById(volumeID); err == nil && existVol != nil {
+			if existVol, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeID); err == nil && existVol != nil {
 				if attachment, ok := existVol.VolumeAttachment.Attachments[attachmentID]; ok {
 					for _, condition := range attachment.Conditions {
 						if condition.Type == longhorn.AttachmentStatusConditionTypeSatisfied && condition.Status == string(longhorn.ConditionStatusFalse) && condition.Message != "" {
@@ -528,7 +762,7 @@ func generateAttachmentID(volName, nodeID string) string {
 }
 
 // publishVolume sends the actual attach request to the longhorn api and executes the passed waitForResult func
-func (cs *ControllerServer) publishVolume(volume *longhornclient.Volume, nodeID, attachmentID string, waitForResult func() error) (*csi.ControllerPublishVolumeResponse, error) {
+func (cs *ControllerServer) publishVolume(ctx context.Context, volume *longhornclient.Volume, nodeID, attachmentID string, waitForResult func() error) (*csi.ControllerPublishVolumeResponse, error) {
 	log := cs.log.WithFields(logrus.Fields{"function": "publishVolume"})
 
 	input := &longhornclient.AttachInput{
@@ -539,7 +773,7 @@ func (cs *ControllerServer) publishVolume(volume *longhornclient.Volume, nodeID,
 	}
 
 	log.Infof("Volume %v with accessMode %v requesting publishing with attachInput %+v", volume.Name, volume.AccessMode, input)
-	if _, err := cs.apiClient.Volume.ActionAttach(volume, input); err != nil {
+	if _, err := cs.apiClient.WithContext(ctx).Volume.ActionAttach(volume, input); err != nil {
 		// TODO: JM process the returned error and return the correct error responses for kubernetes
 		//  i.e. FailedPrecondition if the RWO volume is already attached to a different node
 		return nil, status.Error(codes.Internal, err.Error())
@@ -552,7 +786,7 @@ func (cs *ControllerServer) publishVolume(volume *longhornclient.Volume, nodeID,
 	return &csi.ControllerPublishVolumeResponse{}, nil
 }
 
-func (cs *ControllerServer) updateVolumeAccessMode(volume *longhornclient.Volume, accessMode longhorn.AccessMode) (*longhornclient.Volume, error) {
+func (cs *ControllerServer) updateVolumeAccessMode(ctx context.Context, volume *longhornclient.Volume, accessMode longhorn.AccessMode) (*longhornclient.Volume, error) {
 	log := cs.log.WithFields(logrus.Fields{"function": "updateVolumeAccessMode"})
 
 	mode := string(accessMode)
@@ -564,7 +798,7 @@ func (cs *ControllerServer) updateVolumeAccessMode(volume *longhornclient.Volume
 	input := &longhornclient.UpdateAccessModeInput{AccessMode: mode}
 
 	log.Infof("Changing volume %s access mode to %s", volumeName, mode)
-	volume, err := cs.apiClient.Volume.ActionUpdateAccessMode(volume, input)
+	volume, err := cs.apiClient.WithContext(ctx).Volume.ActionUpdateAccessMode(volume, input)
 	if err != nil {
 		log.WithError(err).Errorf("Failed to change volume %s access mode to %s", volumeName, mode)
 		return nil, status.Error(codes.Internal, err.Error())
@@ -586,7 +820,7 @@ func (cs *ControllerServer) ControllerUnpublishVolume(ctx context.Context, req *
 
 	nodeID := req.GetNodeId()
 
-	volume, err := cs.apiClient.Volume.ById(volumeID)
+	volume, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeID)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -601,13 +835,13 @@ func (cs *ControllerServer) ControllerUnpublishVolume(ctx context.Context, req *
 
 	// TODO: handle cases in which NodeID is empty. Should we detach the volume from all nodes???
 
-	return cs.unpublishVolume(volume, nodeID, attachmentID, func() error {
+	return cs.unpublishVolume(ctx, volume, nodeID, attachmentID, func() error {
 		checkVolumeUnpublished := func(vol *longhornclient.Volume) bool {
 			_, ok := vol.VolumeAttachment.Attachments[attachmentID]
 			return !ok
 		}
 
-		if !cs.waitForVolumeState(volumeID, "volume unpublished", checkVolumeUnpublished, false, true) {
+		if !cs.waitForVolumeState(ctx, volumeID, "volume unpublished", checkVolumeUnpublished, false, true) {
 			return status.Errorf(codes.DeadlineExceeded, "Failed to detach volume %s from node %s", volumeID, nodeID)
 		}
 		return nil
@@ -615,7 +849,7 @@ func (cs *ControllerServer) ControllerUnpublishVolume(ctx context.Context, req *
 }
 
 // unpublishVolume sends the actual detach request to the longhorn api and executes the passed waitForResult func
-func (cs *ControllerServer) unpublishVolume(volume *longhornclient.Volume, nodeID, attachmentID string, waitForResult func() error) (*csi.ControllerUnpublishVolumeResponse, error) {
+func (cs *ControllerServer) unpublishVolume(ctx context.Context, volume *longhornclient.Volume, nodeID, attachmentID string, waitForResult func() error) (*csi.ControllerUnpublishVolumeResponse, error) {
 	log := cs.log.WithFields(logrus.Fields{"function": "unpublishVolume"})
 
 	log.Infof("Requesting volume %v detachment for node %v with attachmentID %v ", volume.Name, nodeID, attachmentID)
@@ -625,7 +859,7 @@ func (cs *ControllerServer) unpublishVolume(volume *longhornclient.Volume, nodeI
 		// if nodeID == "" means to detach from all nodes
 		ForceDetach: nodeID == "",
 	}
-	_, err := cs.apiClient.Volume.ActionDetach(volume, detachInput)
+	_, err := cs.apiClient.WithContext(ctx).Volume.ActionDetach(volume, detachInput)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -637,8 +871,81 @@ func (cs *ControllerServer) unpublishVolume(volume *longhornclient.Volume, nodeI
 	return &csi.ControllerUnpublishVolumeResponse{}, nil
 }
 
-func (cs *ControllerServer) ListVolumes(context.Context, *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+func (cs *ControllerServer) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
+	log := cs.log.WithFields(logrus.Fields{"function": "ListVolumes"})
+
+	log.Tracef("ListVolumes is called with req %+v", req)
+
+	volumeCollection, err := cs.apiClient.WithContext(ctx).Volume.List(&longhornclient.ListOpts{})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	volumes := volumeCollection.Data
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+
+	start, end, nextToken, err := paginateListEntries(len(volumes), req.GetStartingToken(), req.GetMaxEntries())
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*csi.ListVolumesResponse_Entry{}
+	for _, volume := range volumes[start:end] {
+		capacity, err := strconv.ParseInt(volume.Size, 10, 64)
+		if err != nil {
+			log.WithError(err).Warnf("Failed to parse size %v of volume %v", volume.Size, volume.Name)
+		}
+		entries = append(entries, &csi.ListVolumesResponse_Entry{
+			Volume: &csi.Volume{
+				VolumeId:      volume.Name,
+				CapacityBytes: capacity,
+			},
+			Status: &csi.ListVolumesResponse_VolumeStatus{
+				PublishedNodeIds: getPublishedNodeIDs(&volume),
+			},
+		})
+	}
+
+	return &csi.ListVolumesResponse{
+		Entries:   entries,
+		NextToken: nextToken,
+	}, nil
+}
+
+// getPublishedNodeIDs returns the nodes the volume is published to by ControllerPublishVolume
+func getPublishedNodeIDs(volume *longhornclient.Volume) []string {
+	nodeIDs := []string{}
+	for _, attachment := range volume.VolumeAttachment.Attachments {
+		if attachment.AttachmentType != string(longhorn.AttacherTypeCSIAttacher) || attachment.NodeID == "" {
+			continue
+		}
+		if !util.Contains(nodeIDs, attachment.NodeID) {
+			nodeIDs = append(nodeIDs, attachment.NodeID)
+		}
+	}
+	sort.Strings(nodeIDs)
+	return nodeIDs
+}
+
+// paginateListEntries returns the range of the entries to list and the token of the next page. The starting token is
+// the index of the first entry to list, as returned in the next token of the previous page.
+func paginateListEntries(total int, startingToken string, maxEntries int32) (start, end int, nextToken string, err error) {
+	if maxEntries < 0 {
+		return 0, 0, "", status.Errorf(codes.InvalidArgument, "invalid max entries %v", maxEntries)
+	}
+
+	if startingToken != "" {
+		start, err = strconv.Atoi(startingToken)
+		if err != nil || start < 0 || start > total {
+			return 0, 0, "", status.Errorf(codes.Aborted, "invalid starting token %v", startingToken)
+		}
+	}
+
+	end = total
+	if maxEntries > 0 && start+int(maxEntries) < total {
+		end = start + int(maxEntries)
+		nextToken = strconv.Itoa(end)
+	}
+	return start, end, nextToken, nil
 }
 
 func (cs *ControllerServer) GetCapacity(context.Context, *csi.GetCapacityRequest) (*csi.GetCapacityResponse, error) {
@@ -661,12 +968,12 @@ func (cs *ControllerServer) CreateSnapshot(ctx context.Context, req *csi.CreateS
 	csiSnapshotType := normalizeCSISnapshotType(req.Parameters["type"])
 	switch csiSnapshotType {
 	case csiSnapshotTypeLonghornSnapshot:
-		rsp, err = cs.createCSISnapshotTypeLonghornSnapshot(req)
+		rsp, err = cs.createCSISnapshotTypeLonghornSnapshot(ctx, req)
 	case csiSnapshotTypeLonghornBackingImage:
-		rsp, err = cs.createCSISnapshotTypeLonghornBackingImage(req)
+		rsp, err = cs.createCSISnapshotTypeLonghornBackingImage(ctx, req)
 	case "", csiSnapshotTypeLonghornBackup:
 		// For backward compatibility, empty type is considered as csiSnapshotTypeLonghornBackup
-		rsp, err = cs.createCSISnapshotTypeLonghornBackup(req)
+		rsp, err = cs.createCSISnapshotTypeLonghornBackup(ctx, req)
 	default:
 		return nil, status.Errorf(codes.InvalidArgument, "invalid CSI snapshot type: %v. Must be %v or %v or \"\"", csiSnapshotType, csiSnapshotTypeLonghornSnapshot, csiSnapshotTypeLonghornBackup)
 	}
@@ -674,7 +981,7 @@ func (cs *ControllerServer) CreateSnapshot(ctx context.Context, req *csi.CreateS
 	return rsp, err
 }
 
-func (cs *ControllerServer) createCSISnapshotTypeLonghornBackingImage(req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
+func (cs *ControllerServer) createCSISnapshotTypeLonghornBackingImage(ctx context.Context, req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
 	log := cs.log.WithFields(logrus.Fields{"function": "createCSISnapshotTypeLonghornBackingImage"})
 
 	csiSnapshotName := req.GetName()
@@ -685,7 +992,7 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornBackingImage(req *csi.C
 		return nil, status.Error(codes.InvalidArgument, "Snapshot name must be provided")
 	}
 
-	vol, err := cs.apiClient.Volume.ById(csiVolumeName)
+	vol, err := cs.apiClient.WithContext(ctx).Volume.ById(csiVolumeName)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -694,7 +1001,7 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornBackingImage(req *csi.C
 	}
 
 	var backingImage *longhornclient.BackingImage
-	backingImageListOutput, err := cs.apiClient.BackingImage.List(&longhornclient.ListOpts{})
+	backingImageListOutput, err := cs.apiClient.WithContext(ctx).BackingImage.List(&longhornclient.ListOpts{})
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -734,7 +1041,7 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornBackingImage(req *csi.C
 		}
 
 		log.Infof("Creating backing image type snapshot %v exported from volume %s", csiSnapshotName, vol.Name)
-		backingImage, err = cs.apiClient.BackingImage.Create(backingImage)
+		backingImage, err = cs.apiClient.WithContext(ctx).BackingImage.Create(backingImage)
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
@@ -743,7 +1050,7 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornBackingImage(req *csi.C
 	return createSnapshotResponseForSnapshotTypeLonghornBackingImage(vol.Name, snapshotID, time.Now().UTC().Format(time.RFC3339), vol.Size, true), nil
 }
 
-func (cs *ControllerServer) createCSISnapshotTypeLonghornSnapshot(req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
+func (cs *ControllerServer) createCSISnapshotTypeLonghornSnapshot(ctx context.Context, req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
 	log := cs.log.WithFields(logrus.Fields{"function": "createCSISnapshotTypeLonghornSnapshot"})
 
 	csiLabels := req.Parameters
@@ -755,7 +1062,7 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornSnapshot(req *csi.Creat
 		return nil, status.Error(codes.InvalidArgument, "Snapshot name must be provided")
 	}
 
-	vol, err := cs.apiClient.Volume.ById(csiVolumeName)
+	vol, err := cs.apiClient.WithContext(ctx).Volume.ById(csiVolumeName)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -764,7 +1071,7 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornSnapshot(req *csi.Creat
 	}
 
 	var snapshotCR *longhornclient.SnapshotCR
-	snapshotCRs, err := cs.apiClient.Volume.ActionSnapshotCRList(vol)
+	snapshotCRs, err := cs.apiClient.WithContext(ctx).Volume.ActionSnapshotCRList(vol)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -777,7 +1084,7 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornSnapshot(req *csi.Creat
 
 	if snapshotCR == nil {
 		log.Infof("Creating volume %s snapshot %s", vol.Name, csiSnapshotName)
-		snapshotCR, err = cs.apiClient.Volume.ActionSnapshotCRCreate(vol, &longhornclient.SnapshotCRInput{
+		snapshotCR, err = cs.apiClient.WithContext(ctx).Volume.ActionSnapshotCRCreate(vol, &longhornclient.SnapshotCRInput{
 			Labels: csiLabels,
 			Name:   csiSnapshotName,
 		})
@@ -787,7 +1094,7 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornSnapshot(req *csi.Creat
 	}
 
 	// wait for the snapshot creation to be fully finished
-	snapshotCR, err = cs.waitForSnapshotToBeReady(snapshotCR.Name, vol.Name)
+	snapshotCR, err = cs.waitForSnapshotToBeReady(ctx, snapshotCR.Name, vol.Name)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -796,7 +1103,7 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornSnapshot(req *csi.Creat
 	return createSnapshotResponseForSnapshotTypeLonghornSnapshot(vol.Name, snapshotID, snapshotCR), nil
 }
 
-func (cs *ControllerServer) createCSISnapshotTypeLonghornBackup(req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
+func (cs *ControllerServer) createCSISnapshotTypeLonghornBackup(ctx context.Context, req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
 	log := cs.log.WithFields(logrus.Fields{"function": "createCSISnapshotTypeLonghornBackup"})
 
 	csiLabels := req.Parameters
@@ -813,9 +1120,19 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornBackup(req *csi.CreateS
 		backupMode = string(longhorn.BackupModeIncremental)
 	}
 
+	// The backup goes to the backup target selected by the VolumeSnapshotClass, or else by the StorageClass of the
+	// volume, or else to the backup target of the volume
+	backupTargetName := req.Parameters["backupTargetName"]
+	if backupTargetName == "" {
+		var err error
+		if backupTargetName, err = cs.getSnapshotBackupTargetName(ctx, csiVolumeName); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
 	// We check for backup existence first, since it's possible that the actual volume is no longer available but the
 	// backup still is.
-	backup, err := cs.getBackup(csiVolumeName, csiSnapshotName)
+	backup, err := cs.getBackup(ctx, csiVolumeName, csiSnapshotName, backupTargetName)
 	if err != nil {
 		// Status code set in waitForBackupControllerSync.
 		return nil, err
@@ -835,7 +1152,7 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornBackup(req *csi.CreateS
 		return rsp, nil
 	}
 
-	existVol, err := cs.apiClient.Volume.ById(csiVolumeName)
+	existVol, err := cs.apiClient.WithContext(ctx).Volume.ById(csiVolumeName)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -843,19 +1160,22 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornBackup(req *csi.CreateS
 		return nil, status.Errorf(codes.NotFound, "volume %s not found", csiVolumeName)
 	}
 
-	existBackupTarget, err := cs.apiClient.BackupTarget.ById(existVol.BackupTargetName)
+	if backupTargetName == "" {
+		backupTargetName = existVol.BackupTargetName
+	}
+	existBackupTarget, err := cs.apiClient.WithContext(ctx).BackupTarget.ById(backupTargetName)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	if existBackupTarget == nil {
-		return nil, status.Errorf(codes.NotFound, "backup target %s not found", existVol.BackupTargetName)
+		return nil, status.Errorf(codes.NotFound, "backup target %s not found", backupTargetName)
 	}
 	if !existBackupTarget.Available {
-		return nil, status.Errorf(codes.Aborted, "backup target %s is not available", existVol.BackupTargetName)
+		return nil, status.Errorf(codes.Aborted, "backup target %s is not available", backupTargetName)
 	}
 
 	var snapshotCR *longhornclient.SnapshotCR
-	snapshotCRs, err := cs.apiClient.Volume.ActionSnapshotCRList(existVol)
+	snapshotCRs, err := cs.apiClient.WithContext(ctx).Volume.ActionSnapshotCRList(existVol)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -869,7 +1189,7 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornBackup(req *csi.CreateS
 	// no existing backup and no local snapshot, create a new one
 	if snapshotCR == nil {
 		log.Infof("Creating Volume %s snapshot %s", existVol.Name, csiSnapshotName)
-		snapshotCR, err = cs.apiClient.Volume.ActionSnapshotCRCreate(existVol, &longhornclient.SnapshotCRInput{
+		snapshotCR, err = cs.apiClient.WithContext(ctx).Volume.ActionSnapshotCRCreate(existVol, &longhornclient.SnapshotCRInput{
 			Labels: csiLabels,
 			Name:   csiSnapshotName,
 		})
@@ -880,17 +1200,18 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornBackup(req *csi.CreateS
 	}
 
 	// wait for the snapshot creation to be fully finished
-	snapshotCR, err = cs.waitForSnapshotToBeReady(snapshotCR.Name, existVol.Name)
+	snapshotCR, err = cs.waitForSnapshotToBeReady(ctx, snapshotCR.Name, existVol.Name)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	// create backup based on local volume snapshot
-	log.Infof("Creating volume %s backup for snapshot %s", existVol.Name, csiSnapshotName)
-	existVol, err = cs.apiClient.Volume.ActionSnapshotBackup(existVol, &longhornclient.SnapshotInput{
-		Labels:     csiLabels,
-		Name:       csiSnapshotName,
-		BackupMode: backupMode,
+	log.Infof("Creating volume %s backup for snapshot %s on backup target %s", existVol.Name, csiSnapshotName, backupTargetName)
+	existVol, err = cs.apiClient.WithContext(ctx).Volume.ActionSnapshotBackup(existVol, &longhornclient.SnapshotInput{
+		Labels:           csiLabels,
+		Name:             csiSnapshotName,
+		BackupMode:       backupMode,
+		BackupTargetName: backupTargetName,
 	})
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
@@ -900,7 +1221,7 @@ func (cs *ControllerServer) createCSISnapshotTypeLonghornBackup(req *csi.CreateS
 	// status. It's possible that the backup operation can't actually be completed, but we need to return quickly so the
 	// CO can unfreeze I/O (if freezing is supported) and without error (if possible) so the CO knows our ID and can use
 	// it in future calls.
-	backup, err = cs.waitForBackupControllerSync(existVol.Name, csiSnapshotName)
+	backup, err = cs.waitForBackupControllerSync(ctx, existVol.Name, csiSnapshotName, backupTargetName)
 	if err != nil {
 		// Status code set in waitForBackupControllerSync.
 		return nil, err
@@ -1035,21 +1356,21 @@ func (cs *ControllerServer) DeleteSnapshot(ctx context.Context, req *csi.DeleteS
 	csiSnapshotType, sourceVolumeName, id := decodeSnapshotID(snapshotID)
 	switch csiSnapshotType {
 	case csiSnapshotTypeLonghornBackingImage:
-		if err := cs.cleanupBackingImage(snapshotID); err != nil {
+		if err := cs.cleanupBackingImage(ctx, snapshotID); err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
 	case csiSnapshotTypeLonghornSnapshot:
 		if id == "" {
 			return nil, status.Errorf(codes.NotFound, "volume source snapshot %v is not found", snapshotID)
 		}
-		if err := cs.cleanupSnapshot(sourceVolumeName, id); err != nil {
+		if err := cs.cleanupSnapshot(ctx, sourceVolumeName, id); err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
 	case csiSnapshotTypeLonghornBackup:
 		if id == "" {
 			return nil, status.Errorf(codes.NotFound, "volume source snapshot %v is not found", snapshotID)
 		}
-		if err := cs.cleanupBackup(sourceVolumeName, id); err != nil {
+		if err := cs.cleanupBackup(ctx, sourceVolumeName, id); err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
 	}
@@ -1057,27 +1378,27 @@ func (cs *ControllerServer) DeleteSnapshot(ctx context.Context, req *csi.DeleteS
 	return &csi.DeleteSnapshotResponse{}, nil
 }
 
-func (cs *ControllerServer) cleanupBackingImage(snapshotID string) error {
+func (cs *ControllerServer) cleanupBackingImage(ctx context.Context, snapshotID string) error {
 	backingImageParameters := decodeSnapshoBackingImageID(snapshotID)
-	backingImage, err := cs.apiClient.BackingImage.ById(backingImageParameters[longhorn.BackingImageParameterName])
+	backingImage, err := cs.apiClient.WithContext(ctx).BackingImage.ById(backingImageParameters[longhorn.BackingImageParameterName])
 	if err != nil {
 		return err
 	}
 	if backingImage != nil {
-		if err := cs.apiClient.BackingImage.Delete(backingImage); err != nil {
+		if err := cs.apiClient.WithContext(ctx).BackingImage.Delete(backingImage); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (cs *ControllerServer) cleanupSnapshot(sourceVolumeName, id string) error {
-	volume, err := cs.apiClient.Volume.ById(sourceVolumeName)
+func (cs *ControllerServer) cleanupSnapshot(ctx context.Context, sourceVolumeName, id string) error {
+	volume, err := cs.apiClient.WithContext(ctx).Volume.ById(sourceVolumeName)
 	if err != nil {
 		return err
 	}
 	if volume != nil {
-		if _, err := cs.apiClient.Volume.ActionSnapshotCRDelete(volume, &longhornclient.SnapshotCRInput{
+		if _, err := cs.apiClient.WithContext(ctx).Volume.ActionSnapshotCRDelete(volume, &longhornclient.SnapshotCRInput{
 			Name: id,
 		}); err != nil {
 			return err
@@ -1086,9 +1407,9 @@ func (cs *ControllerServer) cleanupSnapshot(sourceVolumeName, id string) error {
 	return nil
 }
 
-func (cs *ControllerServer) cleanupBackup(sourceVolumeName, id string) error {
+func (cs *ControllerServer) cleanupBackup(ctx context.Context, sourceVolumeName, id string) error {
 	backupVolumeName, backupName := sourceVolumeName, id
-	backupVolumes, err := cs.getBackupVolumes(backupVolumeName)
+	backupVolumes, err := cs.getBackupVolumes(ctx, backupVolumeName)
 	if err != nil {
 		return err
 	}
@@ -1097,7 +1418,7 @@ func (cs *ControllerServer) cleanupBackup(sourceVolumeName, id string) error {
 		// The bv is there for backward compatibility.
 		// Any bv will work
 		if bv.Name != "" {
-			_, err = cs.apiClient.BackupVolume.ActionBackupDelete(bv, &longhornclient.BackupInput{
+			_, err = cs.apiClient.WithContext(ctx).BackupVolume.ActionBackupDelete(bv, &longhornclient.BackupInput{
 				Name: backupName,
 			})
 			if err != nil {
@@ -1110,8 +1431,116 @@ func (cs *ControllerServer) cleanupBackup(sourceVolumeName, id string) error {
 	return nil
 }
 
-func (cs *ControllerServer) ListSnapshots(context.Context, *csi.ListSnapshotsRequest) (*csi.ListSnapshotsResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+func (cs *ControllerServer) ListSnapshots(ctx context.Context, req *csi.ListSnapshotsRequest) (*csi.ListSnapshotsResponse, error) {
+	log := cs.log.WithFields(logrus.Fields{"function": "ListSnapshots"})
+
+	log.Tracef("ListSnapshots is called with req %+v", req)
+
+	sourceVolumeName := req.GetSourceVolumeId()
+	if req.GetSnapshotId() != "" {
+		csiSnapshotType, snapshotVolumeName, _ := decodeSnapshotID(req.GetSnapshotId())
+		if csiSnapshotType == "" || (sourceVolumeName != "" && sourceVolumeName != snapshotVolumeName) {
+			return &csi.ListSnapshotsResponse{}, nil
+		}
+		sourceVolumeName = snapshotVolumeName
+	}
+
+	snapshots, err := cs.listCSISnapshots(ctx, sourceVolumeName)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetSnapshotId() != "" {
+		filtered := []*csi.Snapshot{}
+		for _, snapshot := range snapshots {
+			if snapshot.SnapshotId == req.GetSnapshotId() {
+				filtered = append(filtered, snapshot)
+			}
+		}
+		snapshots = filtered
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].SnapshotId < snapshots[j].SnapshotId })
+
+	start, end, nextToken, err := paginateListEntries(len(snapshots), req.GetStartingToken(), req.GetMaxEntries())
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*csi.ListSnapshotsResponse_Entry{}
+	for _, snapshot := range snapshots[start:end] {
+		entries = append(entries, &csi.ListSnapshotsResponse_Entry{Snapshot: snapshot})
+	}
+
+	return &csi.ListSnapshotsResponse{
+		Entries:   entries,
+		NextToken: nextToken,
+	}, nil
+}
+
+// listCSISnapshots returns the Longhorn snapshots and backups of the volume, or of all volumes if the volume name is
+// empty, with the same snapshot IDs as the ones returned by CreateSnapshot
+func (cs *ControllerServer) listCSISnapshots(ctx context.Context, volumeName string) ([]*csi.Snapshot, error) {
+	snapshots := []*csi.Snapshot{}
+
+	volumes := []longhornclient.Volume{}
+	if volumeName != "" {
+		volume, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeName)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if volume != nil {
+			volumes = append(volumes, *volume)
+		}
+	} else {
+		volumeCollection, err := cs.apiClient.WithContext(ctx).Volume.List(&longhornclient.ListOpts{})
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		volumes = volumeCollection.Data
+	}
+	for _, volume := range volumes {
+		snapshotCRs, err := cs.apiClient.WithContext(ctx).Volume.ActionSnapshotCRList(&volume)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		for _, snapshotCR := range snapshotCRs.Data {
+			if snapshotCR.Name == "" || snapshotCR.Name == etypes.VolumeHeadName {
+				continue
+			}
+			snapshotID := encodeSnapshotID(csiSnapshotTypeLonghornSnapshot, volume.Name, snapshotCR.Name)
+			rsp := createSnapshotResponseForSnapshotTypeLonghornSnapshot(volume.Name, snapshotID, &snapshotCR)
+			snapshots = append(snapshots, rsp.Snapshot)
+		}
+	}
+
+	// The backups are listed even if the volume has been deleted, since they can still be restored
+	backupVolumes, err := cs.apiClient.WithContext(ctx).BackupVolume.List(&longhornclient.ListOpts{})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	listedBackupVolumes := map[string]bool{}
+	for _, bv := range backupVolumes.Data {
+		if volumeName != "" && bv.VolumeName != volumeName {
+			continue
+		}
+		// A backup volume exists on each backup target, and lists the backups of the volume on all of them
+		if bv.VolumeName == "" || listedBackupVolumes[bv.VolumeName] {
+			continue
+		}
+		listedBackupVolumes[bv.VolumeName] = true
+
+		backupListOutput, err := cs.apiClient.WithContext(ctx).BackupVolume.ActionBackupListByVolume(&bv, &longhornclient.Volume{Name: bv.VolumeName})
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		for _, backup := range backupListOutput.Data {
+			snapshotID := encodeSnapshotID(csiSnapshotTypeLonghornBackup, bv.VolumeName, backup.Name)
+			rsp := createSnapshotResponseForSnapshotTypeLonghornBackup(bv.VolumeName, snapshotID,
+				backup.SnapshotCreated, backup.VolumeSize, backup.State == string(longhorn.BackupStateCompleted))
+			snapshots = append(snapshots, rsp.Snapshot)
+		}
+	}
+
+	return snapshots, nil
 }
 
 func (cs *ControllerServer) ControllerExpandVolume(ctx context.Context, req *csi.ControllerExpandVolumeRequest) (*csi.ControllerExpandVolumeResponse, error) {
@@ -1134,7 +1563,7 @@ func (cs *ControllerServer) ControllerExpandVolume(ctx context.Context, req *csi
 	}
 	isAccessModeMount := req.VolumeCapability.GetMount() != nil
 
-	existVol, err := cs.apiClient.Volume.ById(volumeID)
+	existVol, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "%v", err)
 	}
@@ -1154,7 +1583,7 @@ func (cs *ControllerServer) ControllerExpandVolume(ctx context.Context, req *csi
 
 	isOnlineExpansion := existVol.State == string(longhorn.VolumeStateAttached)
 
-	if existVol, err = cs.apiClient.Volume.ActionExpand(existVol, &longhornclient.ExpandInput{
+	if existVol, err = cs.apiClient.WithContext(ctx).Volume.ActionExpand(existVol, &longhornclient.ExpandInput{
 		Size: strconv.FormatInt(requestedSize, 10),
 	}); err != nil {
 		// TODO: This manual error code parsing should be refactored once Longhorn API implements error code response
@@ -1182,7 +1611,7 @@ func (cs *ControllerServer) ControllerExpandVolume(ctx context.Context, req *csi
 	// we wait for completion of the expansion, to ensure that longhorn and kubernetes state are in sync
 	// should this time out kubernetes will retry the expansion call since the call is idempotent
 	// we will exit early if the volume already has the requested size
-	if !cs.waitForVolumeState(volumeID, "volume expansion", volumeExpansionComplete, false, false) {
+	if !cs.waitForVolumeState(ctx, volumeID, "volume expansion", volumeExpansionComplete, false, false) {
 		return nil, status.Errorf(codes.DeadlineExceeded, "volume %s expansion from existing capacity %v to requested capacity %v failed",
 			volumeID, existingSize, requestedSize)
 	}
@@ -1205,8 +1634,51 @@ func (cs *ControllerServer) ControllerExpandVolume(ctx context.Context, req *csi
 	}, nil
 }
 
-func (cs *ControllerServer) ControllerGetVolume(context.Context, *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+func (cs *ControllerServer) ControllerGetVolume(ctx context.Context, req *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
+	log := cs.log.WithFields(logrus.Fields{"function": "ControllerGetVolume"})
+
+	log.Tracef("ControllerGetVolume is called with req %+v", req)
+
+	volumeID := req.GetVolumeId()
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "volume id missing in request")
+	}
+
+	volume, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if volume == nil {
+		return nil, status.Errorf(codes.NotFound, "volume %s not found", volumeID)
+	}
+
+	capacity, err := strconv.ParseInt(volume.Size, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to parse size %v of volume %v: %v", volume.Size, volumeID, err)
+	}
+
+	return &csi.ControllerGetVolumeResponse{
+		Volume: &csi.Volume{
+			VolumeId:      volume.Name,
+			CapacityBytes: capacity,
+		},
+		Status: &csi.ControllerGetVolumeResponse_VolumeStatus{
+			PublishedNodeIds: getPublishedNodeIDs(volume),
+			VolumeCondition:  getVolumeCondition(volume, capacity),
+		},
+	}, nil
+}
+
+// getVolumeCondition reports a faulted volume as abnormal, along with the used bytes of the volume if they are known
+func getVolumeCondition(volume *longhornclient.Volume, capacity int64) *csi.VolumeCondition {
+	message := fmt.Sprintf("Volume is %v", volume.Robustness)
+	if usedBytes, err := getVolumeActualSize(volume); err == nil {
+		message = fmt.Sprintf("%v, %v of %v bytes used", message, usedBytes, capacity)
+	}
+	return &csi.VolumeCondition{
+		Abnormal: volume.Robustness == string(longhorn.VolumeRobustnessFaulted),
+		Message:  message,
+	}
 }
 
 // isVolumeAvailableOn checks that the volume is attached and that an engine is running on the requested node
@@ -1229,24 +1701,31 @@ func isVolumeShareAvailable(vol *longhornclient.Volume) bool {
 		vol.ShareState == string(longhorn.ShareManagerStateRunning) && vol.ShareEndpoint != ""
 }
 
-func (cs *ControllerServer) waitForVolumeState(volumeID string, stateDescription string,
+func (cs *ControllerServer) waitForVolumeState(ctx context.Context, volumeID string, stateDescription string,
 	predicate func(vol *longhornclient.Volume) bool, notFoundRetry, notFoundReturn bool) bool {
 	log := cs.log.WithFields(logrus.Fields{"function": "waitForVolumeState"})
-	timer := time.NewTimer(timeoutAttachDetach)
+	timer := time.NewTimer(cs.waitBackoff.Timeout)
 	defer timer.Stop()
 	timeout := timer.C
 
-	ticker := time.NewTicker(tickAttachDetach)
-	defer ticker.Stop()
-	tick := ticker.C
+	interval := cs.waitBackoff.InitialInterval
+	pollTimer := time.NewTimer(interval)
+	defer pollTimer.Stop()
+	tick := pollTimer.C
 
 	for {
 		select {
+		case <-ctx.Done():
+			log.Warnf("Canceled while waiting for volume %s state %s: %v", volumeID, stateDescription, ctx.Err())
+			return false
 		case <-timeout:
 			log.Warnf("Timeout while waiting for volume %s state %s", volumeID, stateDescription)
 			return false
 		case <-tick:
-			existVol, err := cs.apiClient.Volume.ById(volumeID)
+			interval = cs.waitBackoff.next(interval)
+			pollTimer.Reset(interval)
+
+			existVol, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeID)
 			if err != nil {
 				log.WithError(err).Warnf("Failed to get volume while waiting for volume %s state %s", volumeID, stateDescription)
 				continue
@@ -1265,12 +1744,35 @@ func (cs *ControllerServer) waitForVolumeState(volumeID string, stateDescription
 	}
 }
 
+// getSnapshotBackupTargetName returns the backup target selected by the StorageClass parameter
+// snapshotBackupTargetName, which is kept in the volume attributes of the PV of the volume
+func (cs *ControllerServer) getSnapshotBackupTargetName(ctx context.Context, volumeName string) (string, error) {
+	volume, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeName)
+	if err != nil {
+		return "", err
+	}
+	if volume == nil || volume.KubernetesStatus.PvName == "" {
+		return "", nil
+	}
+	pv, err := cs.kubeClient.CoreV1().PersistentVolumes().Get(ctx, volume.KubernetesStatus.PvName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if pv.Spec.CSI == nil {
+		return "", nil
+	}
+	return pv.Spec.CSI.VolumeAttributes["snapshotBackupTargetName"], nil
+}
+
 // waitForBackupControllerSync returns the backup of the given snapshot of the given volume. It does not return until
 // the backup controller has synced at least once (so the backup contains information we need). This function does not
 // wait for the existence of a backup. If one doesn't exist, it returns without error immediately.
-func (cs *ControllerServer) waitForBackupControllerSync(volumeName, snapshotName string) (*longhornclient.Backup, error) {
+func (cs *ControllerServer) waitForBackupControllerSync(ctx context.Context, volumeName, snapshotName, backupTargetName string) (*longhornclient.Backup, error) {
 	// Don't wait if we don't need to.
-	backup, err := cs.getBackup(volumeName, snapshotName)
+	backup, err := cs.getBackup(ctx, volumeName, snapshotName, backupTargetName)
 	if err != nil {
 		return nil, err
 	}
@@ -1295,7 +1797,7 @@ func (cs *ControllerServer) waitForBackupControllerSync(volumeName, snapshotName
 			logrus.Warn(msg)
 			return nil, status.Error(codes.DeadlineExceeded, msg)
 		case <-tick:
-			backup, err := cs.getBackup(volumeName, snapshotName)
+			backup, err := cs.getBackup(ctx, volumeName, snapshotName, backupTargetName)
 			if err != nil {
 				return nil, err
 			}
@@ -1310,24 +1812,26 @@ func (cs *ControllerServer) waitForBackupControllerSync(volumeName, snapshotName
 // snapshot. It does not rely on volume.BackupStatus because volume.BackupStatus.Snapshot is only set if the backup
 // successfully initializes and after the backup monitor has had a chance to sync. We want to retrieve the backup
 // (and in particular, its name) as quickly as possible and in any state.
+// The backup is looked for on the given backup target, or on the backup target of the volume if it's empty.
 // Note: if the backup doesn't exist it will return nil for the backup and nil for the error
-func (cs *ControllerServer) getBackup(volumeName, snapshotName string) (*longhornclient.Backup, error) {
-	backupTargetName := ""
-	v, err := cs.apiClient.Volume.ById(volumeName)
-	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
-	}
-	if v != nil {
-		backupTargetName = v.BackupTargetName
+func (cs *ControllerServer) getBackup(ctx context.Context, volumeName, snapshotName, backupTargetName string) (*longhornclient.Backup, error) {
+	if backupTargetName == "" {
+		v, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeName)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if v != nil {
+			backupTargetName = v.BackupTargetName
+		}
 	}
 
-	bv, err := cs.apiClient.BackupVolume.ById(volumeName)
+	bv, err := cs.apiClient.WithContext(ctx).BackupVolume.ById(volumeName)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	var backup *longhornclient.Backup
-	backupListOutput, err := cs.apiClient.BackupVolume.ActionBackupListByVolume(bv, &longhornclient.Volume{Name: volumeName})
+	backupListOutput, err := cs.apiClient.WithContext(ctx).BackupVolume.ActionBackupListByVolume(bv, &longhornclient.Volume{Name: volumeName})
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -1407,7 +1911,7 @@ func toProtoTimestamp(s string) (*timestamppb.Timestamp, error) {
 	return timestamppb.New(t), nil
 }
 
-func (cs *ControllerServer) waitForSnapshotToBeReady(snapshotName, volumeName string) (*longhornclient.SnapshotCR, error) {
+func (cs *ControllerServer) waitForSnapshotToBeReady(ctx context.Context, snapshotName, volumeName string) (*longhornclient.SnapshotCR, error) {
 	timer := time.NewTimer(timeoutSnapshotCreation)
 	defer timer.Stop()
 	timeout := timer.C
@@ -1425,14 +1929,14 @@ func (cs *ControllerServer) waitForSnapshotToBeReady(snapshotName, volumeName st
 			}
 			return nil, fmt.Errorf("waitForSnapshotToBeReady: timeout while waiting for snapshot %v to be ready", snapshotName)
 		case <-tick:
-			vol, err := cs.apiClient.Volume.ById(volumeName)
+			vol, err := cs.apiClient.WithContext(ctx).Volume.ById(volumeName)
 			if err != nil {
 				return nil, fmt.Errorf("waitForSnapshotToBeReady: fail to get source volume %v: %v", volumeName, err)
 			}
 			if vol == nil {
 				return nil, fmt.Errorf("waitForSnapshotToBeReady: volume %s not found", volumeName)
 			}
-			existSnapshotCR, err = cs.apiClient.Volume.ActionSnapshotCRGet(vol, &longhornclient.SnapshotCRInput{
+			existSnapshotCR, err = cs.apiClient.WithContext(ctx).Volume.ActionSnapshotCRGet(vol, &longhornclient.SnapshotCRInput{
 				Name: snapshotName,
 			})
 			if err != nil {
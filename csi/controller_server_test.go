@@ -0,0 +1,136 @@
+package csi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	longhornclient "github.com/longhorn/longhorn-manager/client"
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// fakeAPIResources are the resources served by the fake manager API
+type fakeAPIResources struct {
+	volumes  []longhornclient.Volume
+	settings []longhornclient.Setting
+	nodes    []longhornclient.Node
+}
+
+// newFakeAPIControllerServer returns a controller server whose manager API serves the given resources
+func newFakeAPIControllerServer(t *testing.T, resources fakeAPIResources) *ControllerServer {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	collections := map[string]map[string]interface{}{
+		"volume":  {},
+		"setting": {},
+		"node":    {},
+	}
+	for _, volume := range resources.volumes {
+		collections["volume"][volume.Name] = volume
+	}
+	for _, setting := range resources.settings {
+		collections["setting"][setting.Name] = setting
+	}
+	for _, node := range resources.nodes {
+		collections["node"][node.Name] = node
+	}
+
+	schemas := longhornclient.Schemas{}
+	for schemaType, collection := range collections {
+		collectionURL := server.URL + "/v1/" + schemaType + "s"
+		schemas.Data = append(schemas.Data, longhornclient.Schema{
+			Resource:          longhornclient.Resource{Id: schemaType, Links: map[string]string{"collection": collectionURL}},
+			CollectionMethods: []string{"GET"},
+			ResourceMethods:   []string{"GET"},
+		})
+		mux.HandleFunc("/v1/"+schemaType+"s/", func(w http.ResponseWriter, r *http.Request) {
+			resource, exists := collection[strings.TrimPrefix(r.URL.Path, "/v1/"+schemaType+"s/")]
+			if !exists {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(resource)
+		})
+	}
+	mux.HandleFunc("/v1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-API-Schemas", server.URL+"/v1")
+		_ = json.NewEncoder(w).Encode(schemas)
+	})
+	mux.HandleFunc("/v1/volumes", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(longhornclient.VolumeCollection{Data: resources.volumes})
+	})
+
+	apiClient, err := longhornclient.NewRancherClient(&longhornclient.ClientOpts{Url: server.URL + "/v1"})
+	require.NoError(t, err)
+
+	return &ControllerServer{
+		apiClient: apiClient,
+		log:       logrus.StandardLogger().WithField("component", "csi-controller-server"),
+	}
+}
+
+func TestListVolumesPagination(t *testing.T) {
+	volumes := []longhornclient.Volume{}
+	for _, name := range []string{"vol-e", "vol-c", "vol-a", "vol-d", "vol-b"} {
+		volumes = append(volumes, longhornclient.Volume{Name: name, Size: "1073741824"})
+	}
+	volumes[2].VolumeAttachment = longhornclient.VolumeAttachment{
+		Attachments: map[string]longhornclient.Attachment{
+			"csi-1": {AttachmentType: string(longhorn.AttacherTypeCSIAttacher), NodeID: "node-2"},
+			"csi-2": {AttachmentType: string(longhorn.AttacherTypeCSIAttacher), NodeID: "node-1"},
+			"api":   {AttachmentType: string(longhorn.AttacherTypeLonghornAPI), NodeID: "node-3"},
+		},
+	}
+	cs := newFakeAPIControllerServer(t, fakeAPIResources{volumes: volumes})
+
+	listedVolumeIDs := []string{}
+	nextTokens := []string{}
+	startingToken := ""
+	for {
+		rsp, err := cs.ListVolumes(context.Background(), &csi.ListVolumesRequest{
+			MaxEntries:    2,
+			StartingToken: startingToken,
+		})
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(rsp.Entries), 2)
+		for _, entry := range rsp.Entries {
+			listedVolumeIDs = append(listedVolumeIDs, entry.Volume.VolumeId)
+			require.Equal(t, int64(1073741824), entry.Volume.CapacityBytes)
+			if entry.Volume.VolumeId == "vol-a" {
+				require.Equal(t, []string{"node-1", "node-2"}, entry.Status.PublishedNodeIds)
+			} else {
+				require.Empty(t, entry.Status.PublishedNodeIds)
+			}
+		}
+		if rsp.NextToken == "" {
+			break
+		}
+		nextTokens = append(nextTokens, rsp.NextToken)
+		startingToken = rsp.NextToken
+	}
+	require.Equal(t, []string{"vol-a", "vol-b", "vol-c", "vol-d", "vol-e"}, listedVolumeIDs)
+	require.Equal(t, []string{"2", "4"}, nextTokens)
+
+	rsp, err := cs.ListVolumes(context.Background(), &csi.ListVolumesRequest{})
+	require.NoError(t, err)
+	require.Len(t, rsp.Entries, 5)
+	require.Empty(t, rsp.NextToken)
+
+	for _, startingToken := range []string{"x", "-1", "6"} {
+		_, err := cs.ListVolumes(context.Background(), &csi.ListVolumesRequest{StartingToken: startingToken})
+		require.Equal(t, codes.Aborted, status.Code(err), "starting token %v", startingToken)
+	}
+	_, err = cs.ListVolumes(context.Background(), &csi.ListVolumesRequest{MaxEntries: -1})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
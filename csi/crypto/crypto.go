@@ -2,8 +2,10 @@ package crypto
 
 import (
 	"fmt"
+	"os/exec"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -13,6 +15,7 @@ import (
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 
 	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
 )
 
 const (
@@ -23,6 +26,11 @@ const (
 	CryptoKeyDefaultHash   = "sha256"
 	CryptoKeyDefaultSize   = "256"
 	CryptoDefaultPBKDF     = "argon2i"
+	// CryptoFIPSPBKDF is the PBKDF approved by FIPS 140-3
+	CryptoFIPSPBKDF = "pbkdf2"
+
+	// cryptsetupExitCodeNoPermission is returned by cryptsetup for a bad passphrase
+	cryptsetupExitCodeNoPermission = 2
 
 	// Luks2MinimalVolumeSize the minimal volume size for the LUKS2format encryption.
 	//  https://gitlab.com/cryptsetup/cryptsetup/-/wikis/FrequentlyAskedQuestions
@@ -31,6 +39,17 @@ const (
 	Luks2MinimalVolumeSize = 16 * 1024 * 1024
 )
 
+// The LUKS parameters approved by FIPS 140-3. XTS splits the key into two AES keys, so a key size of 256 or 512
+// bits means AES-128 or AES-256.
+var (
+	fipsKeySizes = map[string][]string{
+		"aes-xts-plain64":      {"256", "512"},
+		"aes-cbc-essiv:sha256": {"128", "192", "256"},
+	}
+	fipsKeyHashes = []string{"sha256", "sha384", "sha512"}
+	fipsPBKDFs    = []string{CryptoFIPSPBKDF}
+)
+
 // EncryptParams keeps the customized cipher options from the secret CR
 type EncryptParams struct {
 	KeyProvider string
@@ -72,6 +91,24 @@ func (cp *EncryptParams) GetPBKDF() string {
 	return cp.PBKDF
 }
 
+// ValidateFIPS returns an error if the parameters, with the defaults applied, are not approved by FIPS 140-3
+func (cp *EncryptParams) ValidateFIPS() error {
+	keySizes, ok := fipsKeySizes[cp.GetKeyCipher()]
+	if !ok {
+		return fmt.Errorf("cipher %v is not FIPS approved", cp.GetKeyCipher())
+	}
+	if !util.Contains(keySizes, cp.GetKeySize()) {
+		return fmt.Errorf("key size %v of cipher %v is not FIPS approved", cp.GetKeySize(), cp.GetKeyCipher())
+	}
+	if !util.Contains(fipsKeyHashes, cp.GetKeyHash()) {
+		return fmt.Errorf("hash %v is not FIPS approved", cp.GetKeyHash())
+	}
+	if !util.Contains(fipsPBKDFs, cp.GetPBKDF()) {
+		return fmt.Errorf("PBKDF %v is not FIPS approved", cp.GetPBKDF())
+	}
+	return nil
+}
+
 // VolumeMapper returns the path for mapped encrypted device.
 func VolumeMapper(volume, dataEngine string) string {
 	if types.IsDataEngineV2(longhorn.DataEngineType(dataEngine)) {
@@ -187,6 +224,103 @@ func ResizeEncryptoDevice(volume, dataEngine, passphrase string) error {
 	return err
 }
 
+// cryptsetupExecutor runs cryptsetup with the passphrase written to its stdin
+type cryptsetupExecutor interface {
+	CryptsetupWithPassphrase(passphrase string, args []string, timeout time.Duration) (stdout string, err error)
+}
+
+// IsPassphraseValid tests if the passphrase unlocks a key slot of the LUKS device without activating it.
+func IsPassphraseValid(devicePath, passphrase string) (bool, error) {
+	namespaces := []lhtypes.Namespace{lhtypes.NamespaceMnt, lhtypes.NamespaceIpc}
+	nsexec, err := lhns.NewNamespaceExecutor(lhtypes.ProcessNone, lhtypes.HostProcDirectory, namespaces)
+	if err != nil {
+		return false, err
+	}
+	return isPassphraseValid(nsexec, devicePath, passphrase)
+}
+
+func isPassphraseValid(executor cryptsetupExecutor, devicePath, passphrase string) (bool, error) {
+	args := []string{"luksOpen", "--test-passphrase", devicePath, "-d", "-"}
+	if _, err := executor.CryptsetupWithPassphrase(passphrase, args, lhtypes.LuksTimeout); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == cryptsetupExitCodeNoPermission {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// RotatePassphrase replaces the previous passphrase of the LUKS device with the passphrase.
+// The new key slot is derived by the pbkdf, or by the cryptsetup default if it's empty.
+// It returns false if the device can already be unlocked by the passphrase only.
+func RotatePassphrase(devicePath, passphrase, previousPassphrase, pbkdf string) (rotated bool, err error) {
+	namespaces := []lhtypes.Namespace{lhtypes.NamespaceMnt, lhtypes.NamespaceIpc}
+	nsexec, err := lhns.NewNamespaceExecutor(lhtypes.ProcessNone, lhtypes.HostProcDirectory, namespaces)
+	if err != nil {
+		return false, err
+	}
+	return rotatePassphrase(nsexec, devicePath, passphrase, previousPassphrase, pbkdf)
+}
+
+// rotatePassphrase adds the passphrase to the LUKS device, verifies that it unlocks the device, and only then removes
+// the previous passphrase. If the added passphrase cannot be verified, the previous passphrase is kept so the device
+// stays accessible. A rotation interrupted before the previous passphrase is removed is completed by the next call, and
+// swapping the passphrases rolls back a completed rotation.
+func rotatePassphrase(executor cryptsetupExecutor, devicePath, passphrase, previousPassphrase, pbkdf string) (rotated bool, err error) {
+	isValid, err := isPassphraseValid(executor, devicePath, passphrase)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to test passphrase of device %v", devicePath)
+	}
+	if previousPassphrase == "" || previousPassphrase == passphrase {
+		if isValid {
+			return false, nil
+		}
+		return false, fmt.Errorf("passphrase cannot unlock device %v and the previous passphrase is not provided", devicePath)
+	}
+
+	if !isValid {
+		if strings.Contains(passphrase, "\n") || strings.Contains(previousPassphrase, "\n") {
+			return false, fmt.Errorf("rotating passphrase containing a newline is not supported for device %v", devicePath)
+		}
+
+		// Without a key file, cryptsetup reads the existing and the new passphrase from stdin line by line
+		logrus.Infof("Adding the rotated passphrase to LUKS device %s", devicePath)
+		stdin := previousPassphrase + "\n" + passphrase + "\n"
+		args := []string{"luksAddKey", devicePath}
+		if pbkdf != "" {
+			args = append(args, "--pbkdf", pbkdf)
+		}
+		if _, err := executor.CryptsetupWithPassphrase(stdin, args, lhtypes.LuksTimeout); err != nil {
+			return false, errors.Wrapf(err, "failed to add the rotated passphrase to device %v", devicePath)
+		}
+
+		isValid, err = isPassphraseValid(executor, devicePath, passphrase)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to verify the rotated passphrase of device %v, keeping the previous passphrase", devicePath)
+		}
+		if !isValid {
+			return false, fmt.Errorf("rotated passphrase cannot unlock device %v, keeping the previous passphrase", devicePath)
+		}
+		rotated = true
+	}
+
+	isPreviousValid, err := isPassphraseValid(executor, devicePath, previousPassphrase)
+	if err != nil {
+		return rotated, errors.Wrapf(err, "failed to test the previous passphrase of device %v", devicePath)
+	}
+	if !isPreviousValid {
+		return rotated, nil
+	}
+
+	logrus.Infof("Removing the previous passphrase from LUKS device %s", devicePath)
+	args := []string{"luksRemoveKey", devicePath, "-d", "-"}
+	if _, err := executor.CryptsetupWithPassphrase(previousPassphrase, args, lhtypes.LuksTimeout); err != nil {
+		return rotated, errors.Wrapf(err, "failed to remove the previous passphrase from device %v", devicePath)
+	}
+	return true, nil
+}
+
 // IsDeviceMappedToNullPath determines if encrypted device is already open at a null path. The command 'cryptsetup status [crypted_device]' show "device:  (null)"
 func IsDeviceMappedToNullPath(device string) (bool, error) {
 	devPath, mappedFile, err := DeviceEncryptionStatus(device)
@@ -0,0 +1,140 @@
+package crypto
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testDevicePath = "/dev/longhorn/vol-1"
+
+// fakeLUKSDevice simulates the key slots of a LUKS device for the cryptsetup commands used by the passphrase rotation
+type fakeLUKSDevice struct {
+	passphrases map[string]bool
+	commands    []string
+
+	// addedPassphraseSuffix simulates a passphrase added differently than it's read by the test
+	addedPassphraseSuffix string
+	failRemoveKey         bool
+}
+
+func newFakeLUKSDevice(passphrases ...string) *fakeLUKSDevice {
+	device := &fakeLUKSDevice{passphrases: map[string]bool{}}
+	for _, passphrase := range passphrases {
+		device.passphrases[passphrase] = true
+	}
+	return device
+}
+
+func (d *fakeLUKSDevice) CryptsetupWithPassphrase(passphrase string, args []string, timeout time.Duration) (string, error) {
+	d.commands = append(d.commands, args[0])
+
+	switch args[0] {
+	case "luksOpen":
+		if !d.passphrases[passphrase] {
+			return "", newNoPermissionError()
+		}
+	case "luksAddKey":
+		lines := strings.Split(passphrase, "\n")
+		if !d.passphrases[lines[0]] {
+			return "", newNoPermissionError()
+		}
+		d.passphrases[lines[1]+d.addedPassphraseSuffix] = true
+	case "luksRemoveKey":
+		if d.failRemoveKey {
+			return "", errors.New("interrupted")
+		}
+		if !d.passphrases[passphrase] {
+			return "", newNoPermissionError()
+		}
+		delete(d.passphrases, passphrase)
+	default:
+		return "", errors.New("unexpected command " + args[0])
+	}
+	return "", nil
+}
+
+func newNoPermissionError() error {
+	return exec.Command("sh", "-c", "exit 2").Run()
+}
+
+func TestRotatePassphrase(t *testing.T) {
+	// rotate
+	device := newFakeLUKSDevice("old")
+	rotated, err := rotatePassphrase(device, testDevicePath, "new", "old", CryptoFIPSPBKDF)
+	require.NoError(t, err)
+	require.True(t, rotated)
+	require.Equal(t, map[string]bool{"new": true}, device.passphrases)
+	require.Equal(t, []string{"luksOpen", "luksAddKey", "luksOpen", "luksOpen", "luksRemoveKey"}, device.commands)
+
+	// already rotated
+	device.commands = nil
+	rotated, err = rotatePassphrase(device, testDevicePath, "new", "old", CryptoFIPSPBKDF)
+	require.NoError(t, err)
+	require.False(t, rotated)
+	require.Equal(t, map[string]bool{"new": true}, device.passphrases)
+	require.Equal(t, []string{"luksOpen", "luksOpen"}, device.commands)
+
+	// rollback of the completed rotation by swapping the passphrases
+	device = newFakeLUKSDevice("new")
+	rotated, err = rotatePassphrase(device, testDevicePath, "old", "new", "")
+	require.NoError(t, err)
+	require.True(t, rotated)
+	require.Equal(t, map[string]bool{"old": true}, device.passphrases)
+
+	// no previous passphrase
+	device = newFakeLUKSDevice("new")
+	rotated, err = rotatePassphrase(device, testDevicePath, "new", "", "")
+	require.NoError(t, err)
+	require.False(t, rotated)
+
+	device = newFakeLUKSDevice("old")
+	_, err = rotatePassphrase(device, testDevicePath, "new", "", "")
+	require.ErrorContains(t, err, "the previous passphrase is not provided")
+	require.Equal(t, map[string]bool{"old": true}, device.passphrases)
+
+	// wrong previous passphrase
+	device = newFakeLUKSDevice("old")
+	_, err = rotatePassphrase(device, testDevicePath, "new", "wrong", "")
+	require.ErrorContains(t, err, "failed to add the rotated passphrase")
+	require.Equal(t, map[string]bool{"old": true}, device.passphrases)
+
+	// passphrase with a newline
+	device = newFakeLUKSDevice("old")
+	_, err = rotatePassphrase(device, testDevicePath, "new\n", "old", "")
+	require.ErrorContains(t, err, "newline is not supported")
+	require.Equal(t, map[string]bool{"old": true}, device.passphrases)
+}
+
+func TestRotatePassphraseVerifyFailure(t *testing.T) {
+	device := newFakeLUKSDevice("old")
+	device.addedPassphraseSuffix = " "
+
+	_, err := rotatePassphrase(device, testDevicePath, "new", "old", "")
+	require.ErrorContains(t, err, "rotated passphrase cannot unlock device")
+	// The previous passphrase is kept, so the device is still accessible
+	require.True(t, device.passphrases["old"])
+	require.NotContains(t, device.commands, "luksRemoveKey")
+}
+
+func TestRotatePassphraseInterrupted(t *testing.T) {
+	device := newFakeLUKSDevice("old")
+	device.failRemoveKey = true
+
+	_, err := rotatePassphrase(device, testDevicePath, "new", "old", "")
+	require.ErrorContains(t, err, "failed to remove the previous passphrase")
+	require.Equal(t, map[string]bool{"old": true, "new": true}, device.passphrases)
+
+	// The retry with the previous passphrase still in the secret completes the rotation without adding the key again
+	device.failRemoveKey = false
+	device.commands = nil
+	rotated, err := rotatePassphrase(device, testDevicePath, "new", "old", "")
+	require.NoError(t, err)
+	require.True(t, rotated)
+	require.Equal(t, map[string]bool{"new": true}, device.passphrases)
+	require.Equal(t, []string{"luksOpen", "luksOpen", "luksRemoveKey"}, device.commands)
+}
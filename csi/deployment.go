@@ -25,6 +25,9 @@ const (
 	DefaultCSIResizerReplicaCount     = 3
 	DefaultCSISnapshotterReplicaCount = 3
 
+	DefaultCSIPluginRateLimit      = 0
+	DefaultCSIPluginRateLimitBurst = 10
+
 	DefaultCSISocketFileName             = "csi.sock"
 	DefaultCSIRegistrationDirSuffix      = "/plugins_registry"
 	DefaultCSIPluginsDirSuffix           = "/plugins/"
@@ -47,7 +50,8 @@ type AttacherDeployment struct {
 }
 
 func NewAttacherDeployment(namespace, serviceAccount, attacherImage, rootDir string, replicaCount int, tolerations []corev1.Toleration,
-	tolerationsString, priorityClass, registrySecret string, imagePullPolicy corev1.PullPolicy, nodeSelector map[string]string) *AttacherDeployment {
+	tolerationsString, priorityClass, registrySecret string, imagePullPolicy corev1.PullPolicy, nodeSelector map[string]string,
+	topologySpreadConstraints []corev1.TopologySpreadConstraint) *AttacherDeployment {
 
 	deployment := getCommonDeployment(
 		types.CSIAttacherName,
@@ -72,6 +76,7 @@ func NewAttacherDeployment(namespace, serviceAccount, attacherImage, rootDir str
 		registrySecret,
 		imagePullPolicy,
 		nodeSelector,
+		topologySpreadConstraints,
 		[]corev1.ContainerPort{
 			{
 				Name:          types.CSISidecarPortNameAttacher,
@@ -102,7 +107,8 @@ type ProvisionerDeployment struct {
 }
 
 func NewProvisionerDeployment(namespace, serviceAccount, provisionerImage, rootDir string, replicaCount int, tolerations []corev1.Toleration,
-	tolerationsString, priorityClass, registrySecret string, imagePullPolicy corev1.PullPolicy, nodeSelector map[string]string) *ProvisionerDeployment {
+	tolerationsString, priorityClass, registrySecret string, imagePullPolicy corev1.PullPolicy, nodeSelector map[string]string,
+	topologySpreadConstraints []corev1.TopologySpreadConstraint) *ProvisionerDeployment {
 
 	deployment := getCommonDeployment(
 		types.CSIProvisionerName,
@@ -117,6 +123,7 @@ func NewProvisionerDeployment(namespace, serviceAccount, provisionerImage, rootD
 			"--leader-election",
 			"--leader-election-namespace=$(POD_NAMESPACE)",
 			"--default-fstype=ext4",
+			"--extra-create-metadata",
 			fmt.Sprintf("--kube-api-qps=%v", types.KubeAPIQPS),
 			fmt.Sprintf("--kube-api-burst=%v", types.KubeAPIBurst),
 			fmt.Sprintf("--http-endpoint=:%v", types.CSISidecarMetricsPort),
@@ -128,6 +135,7 @@ func NewProvisionerDeployment(namespace, serviceAccount, provisionerImage, rootD
 		registrySecret,
 		imagePullPolicy,
 		nodeSelector,
+		topologySpreadConstraints,
 		[]corev1.ContainerPort{
 			{
 				Name:          types.CSISidecarPortNameProvisioner,
@@ -158,7 +166,8 @@ type ResizerDeployment struct {
 }
 
 func NewResizerDeployment(namespace, serviceAccount, resizerImage, rootDir string, replicaCount int, tolerations []corev1.Toleration,
-	tolerationsString, priorityClass, registrySecret string, imagePullPolicy corev1.PullPolicy, nodeSelector map[string]string) *ResizerDeployment {
+	tolerationsString, priorityClass, registrySecret string, imagePullPolicy corev1.PullPolicy, nodeSelector map[string]string,
+	topologySpreadConstraints []corev1.TopologySpreadConstraint) *ResizerDeployment {
 
 	deployment := getCommonDeployment(
 		types.CSIResizerName,
@@ -193,6 +202,7 @@ func NewResizerDeployment(namespace, serviceAccount, resizerImage, rootDir strin
 		registrySecret,
 		imagePullPolicy,
 		nodeSelector,
+		topologySpreadConstraints,
 		[]corev1.ContainerPort{
 			{
 				Name:          types.CSISidecarPortNameResizer,
@@ -223,7 +233,8 @@ type SnapshotterDeployment struct {
 }
 
 func NewSnapshotterDeployment(namespace, serviceAccount, snapshotterImage, rootDir string, replicaCount int, tolerations []corev1.Toleration,
-	tolerationsString, priorityClass, registrySecret string, imagePullPolicy corev1.PullPolicy, nodeSelector map[string]string) *SnapshotterDeployment {
+	tolerationsString, priorityClass, registrySecret string, imagePullPolicy corev1.PullPolicy, nodeSelector map[string]string,
+	topologySpreadConstraints []corev1.TopologySpreadConstraint) *SnapshotterDeployment {
 
 	deployment := getCommonDeployment(
 		types.CSISnapshotterName,
@@ -248,6 +259,7 @@ func NewSnapshotterDeployment(namespace, serviceAccount, snapshotterImage, rootD
 		registrySecret,
 		imagePullPolicy,
 		nodeSelector,
+		topologySpreadConstraints,
 		[]corev1.ContainerPort{
 			{
 				Name:          types.CSISidecarPortNameSnapshotter,
@@ -277,7 +289,7 @@ type PluginDeployment struct {
 	daemonSet *appsv1.DaemonSet
 }
 
-func NewPluginDeployment(namespace, serviceAccount, nodeDriverRegistrarImage, livenessProbeImage, managerImage, managerURL, rootDir string,
+func NewPluginDeployment(namespace, serviceAccount, nodeDriverRegistrarImage, livenessProbeImage, managerImage, managerURL, rootDir string, rateLimit float64, rateLimitBurst int, waitBackoff WaitBackoff,
 	tolerations []corev1.Toleration, tolerationsString, priorityClass, registrySecret string, imagePullPolicy corev1.PullPolicy, nodeSelector map[string]string,
 	storageNetworkSetting *longhorn.Setting, isStorageNetworkForRWXVolumeEnabled bool) *PluginDeployment {
 
@@ -379,6 +391,11 @@ func NewPluginDeployment(namespace, serviceAccount, nodeDriverRegistrarImage, li
 									ContainerPort: DefaultCSILivenessProbePort,
 									Protocol:      corev1.ProtocolTCP,
 								},
+								{
+									Name:          "watchdog",
+									ContainerPort: DefaultCSIWatchdogPort,
+									Protocol:      corev1.ProtocolTCP,
+								},
 							},
 							LivenessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
@@ -410,6 +427,11 @@ func NewPluginDeployment(namespace, serviceAccount, nodeDriverRegistrarImage, li
 								"--endpoint=$(CSI_ENDPOINT)",
 								fmt.Sprintf("--drivername=%s", types.LonghornDriverName),
 								"--manager-url=" + managerURL,
+								fmt.Sprintf("--rate-limit=%v", rateLimit),
+								fmt.Sprintf("--rate-limit-burst=%v", rateLimitBurst),
+								fmt.Sprintf("--wait-initial-interval=%v", waitBackoff.InitialInterval),
+								fmt.Sprintf("--wait-max-interval=%v", waitBackoff.MaxInterval),
+								fmt.Sprintf("--wait-timeout=%v", waitBackoff.Timeout),
 							},
 							Env: []corev1.EnvVar{
 								{
@@ -578,7 +600,9 @@ type DriverObjectDeployment struct {
 	obj *storagev1.CSIDriver
 }
 
-func NewCSIDriverObject() *DriverObjectDeployment {
+// NewCSIDriverObject returns the CSI driver object deployment. The SELinux mount is opt-in, since kubelet then passes
+// the SELinux context of the pods to the driver in place of relabeling the volumes.
+func NewCSIDriverObject(seLinuxMount bool) *DriverObjectDeployment {
 	falseFlag := true
 	obj := &storagev1.CSIDriver{
 		ObjectMeta: metav1.ObjectMeta{
@@ -586,6 +610,7 @@ func NewCSIDriverObject() *DriverObjectDeployment {
 		},
 		Spec: storagev1.CSIDriverSpec{
 			PodInfoOnMount: &falseFlag,
+			SELinuxMount:   &seLinuxMount,
 		},
 	}
 	return &DriverObjectDeployment{
@@ -594,6 +619,13 @@ func NewCSIDriverObject() *DriverObjectDeployment {
 }
 
 func (d *DriverObjectDeployment) Deploy(kubeClient *clientset.Clientset) error {
+	// The SELinux mount can be toggled without an upgrade, so the driver object of a different one is redeployed
+	if existing, err := csiDriverObjectGetFunc(kubeClient, d.obj.Name, ""); err == nil &&
+		ptr.Deref(existing.(*storagev1.CSIDriver).Spec.SELinuxMount, false) != ptr.Deref(d.obj.Spec.SELinuxMount, false) {
+		if err := cleanup(kubeClient, d.obj, "CSI Driver", csiDriverObjectDeleteFunc, csiDriverObjectGetFunc); err != nil {
+			return err
+		}
+	}
 	return deploy(kubeClient, d.obj, "CSI Driver",
 		csiDriverObjectCreateFunc, csiDriverObjectDeleteFunc, csiDriverObjectGetFunc)
 }
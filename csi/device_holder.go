@@ -0,0 +1,138 @@
+package csi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	lhns "github.com/longhorn/go-common-libs/ns"
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const (
+	multipathBinary = "multipath"
+
+	sysBlockDirectory = "/sys/class/block"
+
+	// multipathUUIDPrefix is the prefix of the device-mapper UUID of the maps created by multipathd
+	multipathUUIDPrefix = "mpath-"
+)
+
+type deviceHolder struct {
+	// name is the kernel name of the holder, e.g. dm-3
+	name string
+	// mapperName is the device-mapper name of the holder, e.g. mpatha
+	mapperName string
+	uuid       string
+}
+
+func (h *deviceHolder) isMultipath() bool {
+	return strings.HasPrefix(h.uuid, multipathUUIDPrefix)
+}
+
+func (h *deviceHolder) String() string {
+	if h.mapperName == "" {
+		return h.name
+	}
+	return fmt.Sprintf("%v (%v)", h.name, h.mapperName)
+}
+
+// getDeviceHolders returns the devices stacked on top of the block device, e.g. the device-mapper devices of
+// multipathd or LVM, as well as the crypto device of an encrypted volume
+func getDeviceHolders(devicePath string) ([]*deviceHolder, error) {
+	resolvedPath, err := filepath.EvalSymlinks(devicePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve device %v", devicePath)
+	}
+
+	holdersDir := filepath.Join(sysBlockDirectory, filepath.Base(resolvedPath), "holders")
+	entries, err := os.ReadDir(holdersDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to list holders of device %v", devicePath)
+	}
+
+	holders := []*deviceHolder{}
+	for _, entry := range entries {
+		holder := &deviceHolder{name: entry.Name()}
+		// Only the device-mapper devices have the dm directory
+		if name, err := os.ReadFile(filepath.Join(sysBlockDirectory, entry.Name(), "dm", "name")); err == nil {
+			holder.mapperName = strings.TrimSpace(string(name))
+		}
+		if uuid, err := os.ReadFile(filepath.Join(sysBlockDirectory, entry.Name(), "dm", "uuid")); err == nil {
+			holder.uuid = strings.TrimSpace(string(uuid))
+		}
+		holders = append(holders, holder)
+	}
+	return holders, nil
+}
+
+// ensureDeviceNotClaimed checks that the Longhorn block device of the volume is not claimed by multipathd or another
+// device-mapper consumer, since formatting or mounting the device underneath them corrupts the data. The multipath
+// maps are flushed, which fails if a map is in use. The crypto device of the volume is the only expected holder.
+func ensureDeviceNotClaimed(volumeID, devicePath, cryptoDevice string, log logrus.FieldLogger) error {
+	getForeignHolders := func() ([]*deviceHolder, error) {
+		holders, err := getDeviceHolders(devicePath)
+		if err != nil {
+			return nil, err
+		}
+		foreignHolders := []*deviceHolder{}
+		for _, holder := range holders {
+			if holder.mapperName != "" && holder.mapperName == filepath.Base(cryptoDevice) {
+				continue
+			}
+			foreignHolders = append(foreignHolders, holder)
+		}
+		return foreignHolders, nil
+	}
+
+	foreignHolders, err := getForeignHolders()
+	if err != nil {
+		return err
+	}
+	if len(foreignHolders) == 0 {
+		return nil
+	}
+
+	for _, holder := range foreignHolders {
+		if !holder.isMultipath() {
+			continue
+		}
+		log.Warnf("Flushing multipath map %v claiming device %v of volume %v", holder, devicePath, volumeID)
+		if err := flushMultipathMap(holder.mapperName); err != nil {
+			log.WithError(err).Warnf("Failed to flush multipath map %v", holder)
+		}
+	}
+
+	// multipathd may claim the device again right after the flush if the device is not blacklisted
+	if foreignHolders, err = getForeignHolders(); err != nil {
+		return err
+	}
+	if len(foreignHolders) > 0 {
+		names := []string{}
+		for _, holder := range foreignHolders {
+			names = append(names, holder.String())
+		}
+		return fmt.Errorf("device %v of volume %v is claimed by %v, Longhorn devices should be excluded from multipathd "+
+			"and other device-mapper consumers", devicePath, volumeID, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+func flushMultipathMap(mapperName string) error {
+	namespaces := []lhtypes.Namespace{lhtypes.NamespaceMnt}
+	nsexec, err := lhns.NewNamespaceExecutor(lhtypes.ProcessNone, lhtypes.HostProcDirectory, namespaces)
+	if err != nil {
+		return err
+	}
+	if _, err := nsexec.Execute(nil, multipathBinary, []string{"-f", mapperName}, lhtypes.ExecuteDefaultTimeout); err != nil {
+		return errors.Wrapf(err, "failed to flush multipath map %v", mapperName)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package csi
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/longhorn/go-iscsi-helper/iscsidev"
+
+	longhornclient "github.com/longhorn/longhorn-manager/client"
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const iscsiSessionClassDirectory = "/sys/class/iscsi_session"
+
+var iscsiSessionRegex = regexp.MustCompile(`^session[0-9]+$`)
+
+// verifyDeviceIdentity checks that the device is the one of the volume before it's formatted or mounted, since the
+// device node may be stale or point to another device after a udev race. The device must match the endpoint and the
+// size reported by the engine, and be connected to the iSCSI target or the NVMe-oF subsystem exporting the engine.
+func verifyDeviceIdentity(volume *longhornclient.Volume, devicePath string) error {
+	endpoint := volume.Controllers[0].Endpoint
+	if !isNvmfEndpoint(endpoint) && volume.Frontend != string(longhorn.VolumeFrontendUblk) && filepath.Base(endpoint) != volume.Name {
+		return fmt.Errorf("engine endpoint %v doesn't belong to volume %v", endpoint, volume.Name)
+	}
+
+	isBlock, err := isBlockDevice(devicePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check device %v", devicePath)
+	}
+	if !isBlock {
+		return fmt.Errorf("%v is not a block device", devicePath)
+	}
+
+	deviceSize, err := getDeviceSize(devicePath)
+	if err != nil {
+		return err
+	}
+	engineSize, err := strconv.ParseInt(volume.Controllers[0].Size, 10, 64)
+	if err == nil && engineSize > 0 && deviceSize != engineSize {
+		// The engine reports the new size before the device is expanded
+		if volumeSize, err := strconv.ParseInt(volume.Size, 10, 64); err != nil || deviceSize != volumeSize {
+			return fmt.Errorf("device %v size %v doesn't match size %v reported by the engine of volume %v",
+				devicePath, deviceSize, engineSize, volume.Name)
+		}
+	}
+
+	expectedTarget, err := getEngineExportedTarget(volume)
+	if err != nil || expectedTarget == "" {
+		return err
+	}
+	target, err := getDeviceTarget(devicePath)
+	if err != nil {
+		return err
+	}
+	if target != expectedTarget {
+		return fmt.Errorf("device %v is exported by target %q instead of target %v of the engine of volume %v",
+			devicePath, target, expectedTarget, volume.Name)
+	}
+	return nil
+}
+
+// getEngineExportedTarget returns the iSCSI target or the NVMe-oF subsystem exporting the engine endpoint of the
+// volume, or an empty string if it's unknown. The engine doesn't report a serial or a WWN of the device, but the
+// target names are unique per volume.
+func getEngineExportedTarget(volume *longhornclient.Volume) (string, error) {
+	endpoint := volume.Controllers[0].Endpoint
+	switch {
+	case isNvmfEndpoint(endpoint):
+		target, err := parseNvmfEndpoint(endpoint)
+		if err != nil {
+			return "", err
+		}
+		return target.nqn, nil
+	case volume.Frontend == string(longhorn.VolumeFrontendBlockDev) && volume.DataEngine == string(longhorn.DataEngineTypeV1):
+		// The block device of a v1 engine is the local iSCSI disk of the tgt target of the engine
+		return iscsidev.GetTargetName(volume.Name), nil
+	}
+	// The v2 engine doesn't report the subsystem behind its block device
+	return "", nil
+}
+
+// getDeviceTarget returns the iSCSI target or the NVMe-oF subsystem the block device is connected to, according to
+// the sysfs of the device
+func getDeviceTarget(devicePath string) (string, error) {
+	resolvedPath, err := filepath.EvalSymlinks(devicePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve device %v", devicePath)
+	}
+	deviceDir := filepath.Join(sysBlockDirectory, filepath.Base(resolvedPath), "device")
+
+	// The device of an NVMe namespace is its controller or, with the native multipath, its subsystem
+	if subsysNQN, err := os.ReadFile(filepath.Join(deviceDir, "subsysnqn")); err == nil {
+		return strings.TrimSpace(string(subsysNQN)), nil
+	}
+
+	// The device of a SCSI disk is under the iSCSI session it's attached by, e.g. host3/session1/target3:0:0/3:0:0:1
+	scsiDevicePath, err := filepath.EvalSymlinks(deviceDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve sysfs device of %v", devicePath)
+	}
+	session := getISCSISessionName(scsiDevicePath)
+	if session == "" {
+		return "", fmt.Errorf("device %v is neither an NVMe namespace nor an iSCSI disk", devicePath)
+	}
+	targetName, err := os.ReadFile(filepath.Join(iscsiSessionClassDirectory, session, "targetname"))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get iSCSI target of device %v", devicePath)
+	}
+	return strings.TrimSpace(string(targetName)), nil
+}
+
+// getISCSISessionName returns the iSCSI session in the sysfs path of a SCSI device, or an empty string if the
+// device isn't attached by an iSCSI session
+func getISCSISessionName(scsiDevicePath string) string {
+	for _, element := range strings.Split(scsiDevicePath, string(filepath.Separator)) {
+		if iscsiSessionRegex.MatchString(element) {
+			return element
+		}
+	}
+	return ""
+}
+
+func getDeviceSize(devicePath string) (int64, error) {
+	f, err := os.Open(devicePath)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to open device %v", devicePath)
+	}
+	defer f.Close()
+
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get size of device %v", devicePath)
+	}
+	return size, nil
+}
@@ -0,0 +1,39 @@
+package csi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	longhornclient "github.com/longhorn/longhorn-manager/client"
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+func TestGetEngineExportedTarget(t *testing.T) {
+	newVolume := func(dataEngine longhorn.DataEngineType, frontend longhorn.VolumeFrontend, endpoint string) *longhornclient.Volume {
+		return &longhornclient.Volume{
+			Name:        "vol-1",
+			DataEngine:  string(dataEngine),
+			Frontend:    string(frontend),
+			Controllers: []longhornclient.Controller{{Endpoint: endpoint}},
+		}
+	}
+
+	target, err := getEngineExportedTarget(newVolume(longhorn.DataEngineTypeV1, longhorn.VolumeFrontendBlockDev, "/dev/longhorn/vol-1"))
+	require.NoError(t, err)
+	require.Equal(t, "iqn.2019-10.io.longhorn:vol-1", target)
+
+	target, err = getEngineExportedTarget(newVolume(longhorn.DataEngineTypeV2, longhorn.VolumeFrontendNvmf, "nvmf://10.42.0.12:20001/nqn.2023-01.io.longhorn.spdk:vol-1-e-0"))
+	require.NoError(t, err)
+	require.Equal(t, "nqn.2023-01.io.longhorn.spdk:vol-1-e-0", target)
+
+	// The subsystem behind the block device of the v2 engine is unknown
+	target, err = getEngineExportedTarget(newVolume(longhorn.DataEngineTypeV2, longhorn.VolumeFrontendBlockDev, "/dev/longhorn/vol-1"))
+	require.NoError(t, err)
+	require.Empty(t, target)
+}
+
+func TestGetISCSISessionName(t *testing.T) {
+	require.Equal(t, "session12", getISCSISessionName("/sys/devices/platform/host3/session12/target3:0:0/3:0:0:1"))
+	require.Empty(t, getISCSISessionName("/sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0"))
+}
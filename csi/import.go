@@ -0,0 +1,196 @@
+package csi
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	longhornclient "github.com/longhorn/longhorn-manager/client"
+	"github.com/longhorn/longhorn-manager/types"
+)
+
+const (
+	// volumeImportSourceKey is the volume parameter of the host path of a block device or a file image, whose data
+	// is imported into the volume when it's staged for the first time on the node of volumeImportNodeKey
+	volumeImportSourceKey = "importSource"
+	volumeImportNodeKey   = "importNode"
+	// volumeImportSnapshotName is the snapshot taken on the volume once the data is imported, which marks the
+	// import as completed
+	volumeImportSnapshotName  = "volume-import"
+	volumeImportSnapshotLabel = "longhorn.io/volume-import"
+)
+
+// volumeImporter copies the data of a pre-existing local block device or file image into a new volume, so the
+// workloads can migrate off hostPath or local path provisioners. The volume must be staged on the node of the
+// source. Since the copy takes longer than a CSI call, it runs in the background and NodeStageVolume doesn't stage
+// the volume until the copy is completed.
+type volumeImporter struct {
+	ns *NodeServer
+
+	lock sync.Mutex
+	// imports tracks the running copies by volume, and the error of the failed ones until they are retried
+	imports map[string]error
+}
+
+var errImportInProgress = errors.New("import in progress")
+
+func newVolumeImporter(ns *NodeServer) *volumeImporter {
+	return &volumeImporter{
+		ns:      ns,
+		imports: map[string]error{},
+	}
+}
+
+// getHostPath returns the path of the host file in the mount namespace of the host
+func getHostPath(path string) string {
+	return filepath.Join(lhtypes.HostProcDirectory, "1", "root", path)
+}
+
+// getVolumeImportSourceSize returns the size of the import source, which is either a block device or a file image
+func getVolumeImportSourceSize(sourcePath string) (int64, error) {
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		return 0, err
+	}
+	if info.Mode()&os.ModeDevice != 0 {
+		return getBlockDeviceSize(sourcePath)
+	}
+	if !info.Mode().IsRegular() {
+		return 0, fmt.Errorf("import source %v is neither a block device nor a file image", sourcePath)
+	}
+	return info.Size(), nil
+}
+
+// getVolumeImportAllowedSourceDirectories returns the host directories the volumes can be imported from, which are
+// set by the admin in the setting volume-import-allowed-source-directories
+func getVolumeImportAllowedSourceDirectories(ctx context.Context, apiClient *longhornclient.RancherClient) ([]string, error) {
+	setting, err := apiClient.WithContext(ctx).Setting.ById(string(types.SettingNameVolumeImportAllowedSourceDirectories))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get setting %v", types.SettingNameVolumeImportAllowedSourceDirectories)
+	}
+	if setting == nil {
+		return []string{}, nil
+	}
+	return types.UnmarshalVolumeImportAllowedSourceDirectories(setting.Value)
+}
+
+// copyVolumeImportSource copies the data of the import source into the device of the volume, which cannot be smaller
+// than the source
+func copyVolumeImportSource(sourcePath, devicePath string, deviceSize int64) (int64, error) {
+	sourceSize, err := getVolumeImportSourceSize(sourcePath)
+	if err != nil {
+		return 0, err
+	}
+	if sourceSize > deviceSize {
+		return 0, fmt.Errorf("import source size %v is larger than the volume device size %v", sourceSize, deviceSize)
+	}
+	return copyBlockDevice(sourcePath, devicePath)
+}
+
+// isVolumeImported returns true if the volume has the snapshot taken once the import is completed
+func isVolumeImported(ctx context.Context, apiClient *longhornclient.RancherClient, volume *longhornclient.Volume) (bool, error) {
+	snapshotCRList, err := apiClient.WithContext(ctx).Volume.ActionSnapshotCRList(volume)
+	if err != nil {
+		return false, err
+	}
+	for _, snapshotCR := range snapshotCRList.Data {
+		if snapshotCR.Name == volumeImportSnapshotName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// check returns nil if the import is completed, errImportInProgress if it's being copied, or the error of the last
+// failed copy. A copy is started if there is none running, e.g. after the plugin restarted or the copy failed.
+func (i *volumeImporter) check(ctx context.Context, volume *longhornclient.Volume, source, devicePath string) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if err, exists := i.imports[volume.Name]; exists {
+		if err == nil {
+			return errImportInProgress
+		}
+		delete(i.imports, volume.Name)
+		return err
+	}
+
+	imported, err := isVolumeImported(ctx, i.ns.apiClient, volume)
+	if err != nil {
+		return err
+	}
+	if imported {
+		return nil
+	}
+
+	i.imports[volume.Name] = nil
+	go i.run(volume.Name, source, devicePath)
+	return errImportInProgress
+}
+
+func (i *volumeImporter) run(volumeName, source, devicePath string) {
+	log := i.ns.log.WithFields(logrus.Fields{
+		"function": "volumeImport",
+		"volume":   volumeName,
+		"source":   source,
+	})
+
+	err := i.importVolume(context.Background(), volumeName, source, devicePath, log)
+	if err != nil {
+		log.WithError(err).Error("Failed to import volume")
+		err = errors.Wrapf(err, "failed to import volume %v from %v", volumeName, source)
+	} else {
+		log.Info("Imported volume")
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if err != nil {
+		i.imports[volumeName] = err
+	} else {
+		delete(i.imports, volumeName)
+	}
+}
+
+func (i *volumeImporter) importVolume(ctx context.Context, volumeName, source, devicePath string, log *logrus.Entry) error {
+	// The allowed directories are checked again, since they may be changed after the volume is created
+	allowedDirectories, err := getVolumeImportAllowedSourceDirectories(ctx, i.ns.apiClient)
+	if err != nil {
+		return err
+	}
+	if !types.IsVolumeImportSourceAllowed(source, allowedDirectories) {
+		return fmt.Errorf("import source %v is not inside the directories of setting %v", source, types.SettingNameVolumeImportAllowedSourceDirectories)
+	}
+
+	deviceSize, err := getBlockDeviceSize(devicePath)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Copying data from %v to device %v", source, devicePath)
+	copied, err := copyVolumeImportSource(getHostPath(source), devicePath, deviceSize)
+	if err != nil {
+		return err
+	}
+	log.Infof("Copied %v bytes of data", copied)
+
+	volume, err := i.ns.apiClient.WithContext(ctx).Volume.ById(volumeName)
+	if err != nil {
+		return err
+	}
+	if volume == nil {
+		return fmt.Errorf("volume %v not found", volumeName)
+	}
+	_, err = i.ns.apiClient.WithContext(ctx).Volume.ActionSnapshotCreate(volume, &longhornclient.SnapshotInput{
+		Name:   volumeImportSnapshotName,
+		Labels: map[string]string{volumeImportSnapshotLabel: "true"},
+	})
+	return err
+}
@@ -0,0 +1,122 @@
+package csi
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	longhornclient "github.com/longhorn/longhorn-manager/client"
+	"github.com/longhorn/longhorn-manager/types"
+)
+
+func TestValidateVolumeImport(t *testing.T) {
+	tests := map[string]struct {
+		allowedDirectories string
+		importSource       string
+		importNode         string
+		volume             longhornclient.Volume
+		expectedCode       codes.Code
+	}{
+		"source inside allowed directory": {
+			allowedDirectories: "/var/lib/images;/dev/disk/by-id",
+			importSource:       "/dev/disk/by-id/nvme-disk-1",
+			importNode:         "node-1",
+			expectedCode:       codes.OK,
+		},
+		"no import": {
+			expectedCode: codes.OK,
+		},
+		"import disabled": {
+			importSource: "/var/lib/images/disk.img",
+			importNode:   "node-1",
+			expectedCode: codes.InvalidArgument,
+		},
+		"source outside allowed directories": {
+			allowedDirectories: "/var/lib/images",
+			importSource:       "/etc/shadow",
+			importNode:         "node-1",
+			expectedCode:       codes.InvalidArgument,
+		},
+		"source sharing the prefix of allowed directory": {
+			allowedDirectories: "/var/lib/images",
+			importSource:       "/var/lib/images-private/disk.img",
+			importNode:         "node-1",
+			expectedCode:       codes.InvalidArgument,
+		},
+		"allowed directory itself": {
+			allowedDirectories: "/var/lib/images",
+			importSource:       "/var/lib/images",
+			importNode:         "node-1",
+			expectedCode:       codes.InvalidArgument,
+		},
+		"missing import node": {
+			allowedDirectories: "/var/lib/images",
+			importSource:       "/var/lib/images/disk.img",
+			expectedCode:       codes.InvalidArgument,
+		},
+		"encrypted volume": {
+			allowedDirectories: "/var/lib/images",
+			importSource:       "/var/lib/images/disk.img",
+			importNode:         "node-1",
+			volume:             longhornclient.Volume{Encrypted: true},
+			expectedCode:       codes.InvalidArgument,
+		},
+		"volume with other data source": {
+			allowedDirectories: "/var/lib/images",
+			importSource:       "/var/lib/images/disk.img",
+			importNode:         "node-1",
+			volume:             longhornclient.Volume{FromBackup: "s3://backupbucket@us-east-1/?backup=backup-1&volume=vol-1"},
+			expectedCode:       codes.InvalidArgument,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cs := newFakeAPIControllerServer(t, fakeAPIResources{
+				settings: []longhornclient.Setting{
+					{Name: string(types.SettingNameVolumeImportAllowedSourceDirectories), Value: tc.allowedDirectories},
+				},
+				nodes: []longhornclient.Node{{Name: "node-1"}},
+			})
+
+			volumeParameters := map[string]string{}
+			if tc.importSource != "" {
+				volumeParameters[volumeImportSourceKey] = tc.importSource
+			}
+			if tc.importNode != "" {
+				volumeParameters[volumeImportNodeKey] = tc.importNode
+			}
+			err := cs.validateVolumeImport(context.Background(), &tc.volume, volumeParameters, nil)
+			require.Equal(t, tc.expectedCode, status.Code(err), "error %v", err)
+		})
+	}
+}
+
+func TestCopyVolumeImportSource(t *testing.T) {
+	dir := t.TempDir()
+	data := append(bytes.Repeat([]byte{0}, crossDataEngineCloneBlockSize), bytes.Repeat([]byte("longhorn"), 1024)...)
+	sourcePath := filepath.Join(dir, "disk.img")
+	require.NoError(t, os.WriteFile(sourcePath, data, 0600))
+
+	devicePath := filepath.Join(dir, "device")
+	require.NoError(t, os.WriteFile(devicePath, nil, 0600))
+
+	copied, err := copyVolumeImportSource(sourcePath, devicePath, int64(len(data)))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)-crossDataEngineCloneBlockSize), copied)
+	deviceData, err := os.ReadFile(devicePath)
+	require.NoError(t, err)
+	require.Equal(t, data, deviceData)
+
+	_, err = copyVolumeImportSource(sourcePath, devicePath, int64(len(data)-1))
+	require.ErrorContains(t, err, "is larger than the volume device size")
+
+	_, err = copyVolumeImportSource(dir, devicePath, int64(len(data)))
+	require.ErrorContains(t, err, "neither a block device nor a file image")
+}
@@ -0,0 +1,86 @@
+package csi
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// inFlight tracks the operations being processed per volume. The CSI sidecars retry the calls which time out while
+// the first call is still running, e.g. when the Kubernetes API server is slow. Rejecting the retries with Aborted
+// until the first call returns prevents them from racing with it and sending duplicate requests to the manager.
+type inFlight struct {
+	lock       sync.Mutex
+	operations map[string]string
+}
+
+func newInFlight() *inFlight {
+	return &inFlight{
+		operations: map[string]string{},
+	}
+}
+
+// insert returns false and the method of the running operation if there is already an operation for the key
+func (f *inFlight) insert(key, method string) (string, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if runningMethod, exists := f.operations[key]; exists {
+		return runningMethod, false
+	}
+	f.operations[key] = method
+	return "", true
+}
+
+func (f *inFlight) delete(key string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.operations, key)
+}
+
+// getInFlightKey returns the key of the object the request operates on, or an empty string if the request doesn't
+// need to be serialized
+func getInFlightKey(req interface{}) string {
+	switch r := req.(type) {
+	case *csi.NodeGetVolumeStatsRequest:
+		// The stats are read-only and polled by kubelet
+		return ""
+	case *csi.CreateVolumeRequest:
+		return "volume/" + r.GetName()
+	case *csi.CreateSnapshotRequest:
+		return "snapshot/" + r.GetName()
+	case *csi.DeleteSnapshotRequest:
+		return "snapshot/" + r.GetSnapshotId()
+	case interface{ GetVolumeId() string }:
+		if r.GetVolumeId() == "" {
+			return ""
+		}
+		return "volume/" + r.GetVolumeId()
+	}
+	return ""
+}
+
+// serializeGRPC rejects the calls operating on a volume or a snapshot which already has an operation in flight
+func (f *inFlight) serializeGRPC(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+
+	key := getInFlightKey(req)
+	if key == "" {
+		return handler(ctx, req)
+	}
+
+	if runningMethod, ok := f.insert(key, method); !ok {
+		logrus.Warnf("%s: rejected since %s is in progress for %v", method, runningMethod, key)
+		return nil, status.Errorf(codes.Aborted, "an operation %s is already in progress for %v", runningMethod, key)
+	}
+	defer f.delete(key)
+
+	return handler(ctx, req)
+}
@@ -0,0 +1,261 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	lhns "github.com/longhorn/go-common-libs/ns"
+
+	"github.com/longhorn/longhorn-manager/types"
+)
+
+type Operation string
+
+const (
+	OperationMount   = Operation("mount")
+	OperationUnmount = Operation("unmount")
+	OperationFormat  = Operation("format")
+	OperationExpand  = Operation("expand")
+)
+
+type Result string
+
+const (
+	ResultSucceeded = Result("succeeded")
+	ResultFailed    = Result("failed")
+)
+
+const (
+	journalFileName = "mount-journal.jsonl"
+
+	// MaxRecords is the number of records kept in the journal of a node, the oldest records are dropped first
+	MaxRecords = 1000
+)
+
+// Record is a mount activity of the CSI plugin on a node
+type Record struct {
+	Time      string    `json:"time"`
+	Node      string    `json:"node"`
+	Volume    string    `json:"volume"`
+	Operation Operation `json:"operation"`
+	Path      string    `json:"path"`
+	Options   []string  `json:"options"`
+	Result    Result    `json:"result"`
+	Error     string    `json:"error"`
+	Duration  string    `json:"duration"`
+}
+
+// NewRecord creates a record of an operation started at startTime, which failed if err is not nil
+func NewRecord(nodeID, volumeID string, operation Operation, path string, options []string, startTime time.Time, err error) Record {
+	record := Record{
+		Time:      startTime.UTC().Format(time.RFC3339Nano),
+		Node:      nodeID,
+		Volume:    volumeID,
+		Operation: operation,
+		Path:      path,
+		Options:   options,
+		Result:    ResultSucceeded,
+		Duration:  time.Since(startTime).String(),
+	}
+	if err != nil {
+		record.Result = ResultFailed
+		record.Error = err.Error()
+	}
+	return record
+}
+
+// The journal is only written by the CSI plugin of the node
+var journalLock sync.Mutex
+
+// journalRecordCount is the number of records in the journal file, which is counted on the first append
+var journalRecordCount = -1
+
+func getJournalFilePath() string {
+	return filepath.Join(types.CSIMountJournalDirectoryOnHost, journalFileName)
+}
+
+// Append adds the record to the journal on the host. The record is also logged, so it's collected with the
+// CSI plugin logs by the support bundle.
+func Append(record Record) error {
+	logrus.WithFields(logrus.Fields{
+		"mountJournal": true,
+		"volume":       record.Volume,
+		"operation":    record.Operation,
+		"path":         record.Path,
+		"options":      record.Options,
+		"result":       record.Result,
+		"error":        record.Error,
+		"duration":     record.Duration,
+	}).Info("Recorded mount activity")
+
+	journalLock.Lock()
+	defer journalLock.Unlock()
+
+	if _, err := lhns.CreateDirectory(types.CSIMountJournalDirectoryOnHost, time.Now()); err != nil {
+		return err
+	}
+	_, err := lhns.RunFunc(func() (interface{}, error) {
+		return nil, appendRecord(getJournalFilePath(), record)
+	}, 0)
+	return err
+}
+
+// List returns the records in the journal on the host from the oldest to the newest.
+// The records can be narrowed down by the volume.
+func List(volumeID string) ([]Record, error) {
+	journalLock.Lock()
+	defer journalLock.Unlock()
+
+	rawRecords, err := lhns.RunFunc(func() (interface{}, error) {
+		return readRecords(getJournalFilePath())
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	records, ok := rawRecords.([]Record)
+	if !ok {
+		return nil, errors.Errorf("unexpected mount journal records type %T", rawRecords)
+	}
+	if volumeID == "" {
+		return records, nil
+	}
+
+	filtered := []Record{}
+	for _, record := range records {
+		if record.Volume == volumeID {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered, nil
+}
+
+// appendRecord appends the record as a JSON line to the journal file. Once the journal holds twice MaxRecords
+// records, it's compacted down to the newest MaxRecords records, so the whole journal isn't rewritten on every
+// append. The caller must hold the journal lock.
+func appendRecord(journalPath string, record Record) error {
+	if journalRecordCount < 0 {
+		records, err := readRecords(journalPath)
+		if err != nil {
+			return err
+		}
+		journalRecordCount = len(records)
+	}
+
+	content, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(journalPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Write(append(content, '\n')); err != nil {
+		return errors.Wrapf(err, "failed to append to mount journal %v", journalPath)
+	}
+	if err := file.Sync(); err != nil {
+		return errors.Wrapf(err, "failed to sync mount journal %v", journalPath)
+	}
+	journalRecordCount++
+
+	if journalRecordCount < 2*MaxRecords {
+		return nil
+	}
+	return compact(journalPath)
+}
+
+// compact keeps the newest MaxRecords records of the journal. The records are written to a temporary file, which is
+// synced and renamed over the journal, so a crash never leaves a truncated journal behind.
+func compact(journalPath string) error {
+	records, err := readRecords(journalPath)
+	if err != nil {
+		return err
+	}
+	if len(records) > MaxRecords {
+		records = records[len(records)-MaxRecords:]
+	}
+
+	var buf bytes.Buffer
+	for _, record := range records {
+		content, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(content, '\n'))
+	}
+
+	tmpPath := journalPath + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return errors.Wrapf(err, "failed to write mount journal %v", tmpPath)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return errors.Wrapf(err, "failed to sync mount journal %v", tmpPath)
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, journalPath); err != nil {
+		return errors.Wrapf(err, "failed to replace mount journal %v", journalPath)
+	}
+	journalRecordCount = len(records)
+	return nil
+}
+
+// readRecords returns the records of the journal file. A journal which can't be parsed, for example because of a
+// partially written record, is renamed aside so the CSI plugin starts a new journal instead of failing forever.
+// The caller must hold the journal lock.
+func readRecords(journalPath string) ([]Record, error) {
+	records := []Record{}
+
+	content, err := os.ReadFile(journalPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return records, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read mount journal %v", journalPath)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		record := Record{}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return rotateCorruptedJournal(journalPath, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return rotateCorruptedJournal(journalPath, err)
+	}
+	return records, nil
+}
+
+func rotateCorruptedJournal(journalPath string, parseErr error) ([]Record, error) {
+	corruptedPath := fmt.Sprintf("%v.corrupted-%v", journalPath, time.Now().UTC().Format("20060102T150405Z"))
+	logrus.WithError(parseErr).Warnf("Failed to parse mount journal %v, moving it to %v and starting a new journal", journalPath, corruptedPath)
+	if err := os.Rename(journalPath, corruptedPath); err != nil {
+		return nil, errors.Wrapf(err, "failed to move corrupted mount journal %v aside", journalPath)
+	}
+	journalRecordCount = 0
+	return []Record{}, nil
+}
@@ -0,0 +1,56 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendRecordCompactsJournal(t *testing.T) {
+	journalPath := filepath.Join(t.TempDir(), journalFileName)
+	journalRecordCount = -1
+
+	for i := 0; i < 2*MaxRecords; i++ {
+		require.NoError(t, appendRecord(journalPath, NewRecord("node-1", "vol-1", OperationMount, "/mnt", nil, time.Now(), nil)))
+	}
+
+	records, err := readRecords(journalPath)
+	require.NoError(t, err)
+	require.Len(t, records, MaxRecords)
+	require.Equal(t, MaxRecords, journalRecordCount)
+
+	_, err = os.Stat(journalPath + ".tmp")
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestReadRecordsRotatesCorruptedJournal(t *testing.T) {
+	dir := t.TempDir()
+	journalPath := filepath.Join(dir, journalFileName)
+	journalRecordCount = -1
+
+	require.NoError(t, appendRecord(journalPath, NewRecord("node-1", "vol-1", OperationMount, "/mnt", nil, time.Now(), nil)))
+
+	// A crash in the middle of an append leaves a partial record behind
+	file, err := os.OpenFile(journalPath, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"time":"2026-`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	records, err := readRecords(journalPath)
+	require.NoError(t, err)
+	require.Empty(t, records)
+
+	corrupted, err := filepath.Glob(journalPath + ".corrupted-*")
+	require.NoError(t, err)
+	require.Len(t, corrupted, 1)
+
+	require.NoError(t, appendRecord(journalPath, NewRecord("node-1", "vol-2", OperationUnmount, "/mnt", nil, time.Now(), nil)))
+	records, err = readRecords(journalPath)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, "vol-2", records[0].Volume)
+}
@@ -1,6 +1,10 @@
 package csi
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
@@ -19,11 +23,29 @@ func GetCSIManager() *Manager {
 	return &Manager{}
 }
 
-func (m *Manager) Run(driverName, nodeID, endpoint, identityVersion, managerURL string) error {
+func (m *Manager) Run(driverName, nodeID, endpoint, identityVersion, managerURL string, rateLimit float64, rateLimitBurst int, waitBackoff WaitBackoff, forceUnmountTimeout time.Duration,
+	watchdogThreshold time.Duration, watchdogForceUnmount bool, watchdogPort int) error {
 	logrus.Infof("CSI Driver: %v version: %v, manager URL %v", driverName, identityVersion, managerURL)
 
+	if err := waitBackoff.Validate(); err != nil {
+		return errors.Wrap(err, "Invalid wait backoff")
+	}
+	if forceUnmountTimeout <= 0 {
+		return fmt.Errorf("invalid force unmount timeout %v", forceUnmountTimeout)
+	}
+
+	shutdownTracing, err := initTracing(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "Failed to initialize tracing")
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logrus.WithError(err).Warn("Failed to shut down tracing")
+		}
+	}()
+
 	// Longhorn API Client
-	clientOpts := &longhornclient.ClientOpts{Url: managerURL}
+	clientOpts := &longhornclient.ClientOpts{Url: managerURL, Transport: newTracingTransport()}
 	apiClient, err := longhornclient.NewRancherClient(clientOpts)
 	if err != nil {
 		return errors.Wrap(err, "Failed to initialize Longhorn API client")
@@ -31,14 +53,26 @@ func (m *Manager) Run(driverName, nodeID, endpoint, identityVersion, managerURL
 
 	// Create GRPC servers
 	m.ids = NewIdentityServer(driverName, identityVersion)
-	m.ns, err = NewNodeServer(apiClient, nodeID)
+	m.ns, err = NewNodeServer(apiClient, nodeID, forceUnmountTimeout)
 	if err != nil {
 		return errors.Wrap(err, "Failed to create CSI node server ")
 	}
 
-	m.cs = NewControllerServer(apiClient, nodeID)
-	s := NewNonBlockingGRPCServer()
+	m.cs, err = NewControllerServer(apiClient, nodeID, waitBackoff)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create CSI controller server")
+	}
+	s := NewNonBlockingGRPCServer(rateLimit, rateLimitBurst, watchdogThreshold, watchdogForceUnmount)
 	s.Start(endpoint, m.ids, m.cs, m.ns)
+	go m.ns.mountHealer.run(s.inFlight)
+	if s.watchdog != nil {
+		go s.watchdog.run()
+		go func() {
+			if err := s.watchdog.serve(watchdogPort); err != nil {
+				logrus.WithError(err).Error("Failed to serve the CSI watchdog liveness endpoint")
+			}
+		}()
+	}
 	s.Wait()
 
 	return nil
@@ -0,0 +1,281 @@
+package csi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/mount-utils"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/constant"
+
+	longhornclient "github.com/longhorn/longhorn-manager/client"
+)
+
+const (
+	mountHealthCheckInterval = 1 * time.Minute
+	mountHealingTimeout      = 2 * time.Minute
+
+	mountHealerMethod = "MountHealer"
+)
+
+// mountHealer periodically checks the filesystem mounts staged and published by the node server, and remounts the
+// corrupted ones by staging and publishing the volume again, instead of waiting for the workloads to fail on them.
+// The mounts are only known from the requests handled since the plugin started. The secrets of the requests, e.g. the
+// passphrase of an encrypted volume, are not kept, and are read again from the PV when the volume is staged again.
+type mountHealer struct {
+	ns  *NodeServer
+	log *logrus.Entry
+
+	lock sync.Mutex
+	// staged tracks the stage requests by staging target path
+	staged map[string]*csi.NodeStageVolumeRequest
+	// published tracks the publish requests by target path
+	published map[string]*csi.NodePublishVolumeRequest
+}
+
+func newMountHealer(ns *NodeServer) *mountHealer {
+	return &mountHealer{
+		ns:        ns,
+		log:       logrus.StandardLogger().WithField("component", "csi-mount-healer"),
+		staged:    map[string]*csi.NodeStageVolumeRequest{},
+		published: map[string]*csi.NodePublishVolumeRequest{},
+	}
+}
+
+func (h *mountHealer) trackStage(req *csi.NodeStageVolumeRequest) {
+	if req.GetVolumeCapability().GetMount() == nil {
+		return
+	}
+	stageReq := proto.Clone(req).(*csi.NodeStageVolumeRequest)
+	stageReq.Secrets = nil
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.staged[req.GetStagingTargetPath()] = stageReq
+}
+
+func (h *mountHealer) untrackStage(stagingTargetPath string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.staged, stagingTargetPath)
+}
+
+func (h *mountHealer) trackPublish(req *csi.NodePublishVolumeRequest) {
+	if req.GetVolumeCapability().GetMount() == nil {
+		return
+	}
+	publishReq := proto.Clone(req).(*csi.NodePublishVolumeRequest)
+	publishReq.Secrets = nil
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.published[req.GetTargetPath()] = publishReq
+}
+
+func (h *mountHealer) untrackPublish(targetPath string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.published, targetPath)
+}
+
+// run checks the mounts until the plugin exits. The healing of a volume is serialized with the CSI calls of the
+// volume by inFlight, so the calls from kubelet are rejected and retried while the volume is being remounted.
+func (h *mountHealer) run(inFlight *inFlight) {
+	wait.Forever(func() {
+		for _, volumeID := range h.listVolumes() {
+			key := "volume/" + volumeID
+			if _, ok := inFlight.insert(key, mountHealerMethod); !ok {
+				continue
+			}
+			h.healVolume(volumeID)
+			inFlight.delete(key)
+		}
+	}, mountHealthCheckInterval)
+}
+
+func (h *mountHealer) listVolumes() []string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	volumes := map[string]struct{}{}
+	for _, req := range h.published {
+		volumes[req.GetVolumeId()] = struct{}{}
+	}
+	volumeIDs := []string{}
+	for volumeID := range volumes {
+		volumeIDs = append(volumeIDs, volumeID)
+	}
+	return volumeIDs
+}
+
+// getVolumeRequests returns the tracked requests of the volume, which may be untracked by the CSI calls handled
+// since the volumes were listed
+func (h *mountHealer) getVolumeRequests(volumeID string) (map[string]*csi.NodeStageVolumeRequest, []*csi.NodePublishVolumeRequest) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	stageReqs := map[string]*csi.NodeStageVolumeRequest{}
+	for stagingTargetPath, req := range h.staged {
+		if req.GetVolumeId() == volumeID {
+			stageReqs[stagingTargetPath] = req
+		}
+	}
+	publishReqs := []*csi.NodePublishVolumeRequest{}
+	for _, req := range h.published {
+		if req.GetVolumeId() == volumeID {
+			publishReqs = append(publishReqs, req)
+		}
+	}
+	return stageReqs, publishReqs
+}
+
+func (h *mountHealer) healVolume(volumeID string) {
+	log := h.log.WithField("volume", volumeID)
+	mounter := mount.New("")
+
+	stageReqs, publishReqs := h.getVolumeRequests(volumeID)
+
+	corruptedStagingPaths := map[string]bool{}
+	corruptedPublishReqs := []*csi.NodePublishVolumeRequest{}
+	for _, req := range publishReqs {
+		stagingTargetPath := req.GetStagingTargetPath()
+		stagingCorrupted, checked := corruptedStagingPaths[stagingTargetPath]
+		if !checked {
+			stageReq := stageReqs[stagingTargetPath]
+			stagingCorrupted = stageReq != nil && isMountPointCorrupted(stagingTargetPath, isReadOnlyMount(stageReq.GetVolumeCapability(), false), mounter)
+			corruptedStagingPaths[stagingTargetPath] = stagingCorrupted
+		}
+		// The published mounts are bind mounts of the staged one, so they are corrupted along with it
+		if stagingCorrupted || isMountPointCorrupted(req.GetTargetPath(), isReadOnlyMount(req.GetVolumeCapability(), req.GetReadonly()), mounter) {
+			corruptedPublishReqs = append(corruptedPublishReqs, req)
+		}
+	}
+	if len(corruptedPublishReqs) == 0 {
+		return
+	}
+
+	targetPaths := []string{}
+	for _, req := range corruptedPublishReqs {
+		targetPaths = append(targetPaths, req.GetTargetPath())
+	}
+	log.Warnf("Remounting corrupted mount points %v", targetPaths)
+
+	ctx, cancel := context.WithTimeout(context.Background(), mountHealingTimeout)
+	defer cancel()
+
+	volume, _ := h.ns.apiClient.WithContext(ctx).Volume.ById(volumeID)
+	forceUnmountTimeout := h.ns.getForceUnmountTimeout(volume)
+
+	err := func() error {
+		for stagingTargetPath, corrupted := range corruptedStagingPaths {
+			if !corrupted {
+				continue
+			}
+			for _, req := range corruptedPublishReqs {
+				if req.GetStagingTargetPath() != stagingTargetPath {
+					continue
+				}
+				if err := unmount(req.GetTargetPath(), mounter, forceUnmountTimeout); err != nil {
+					return fmt.Errorf("failed to unmount corrupted mount point %v: %v", req.GetTargetPath(), err)
+				}
+			}
+			stageReq, err := h.getStageRequestWithSecrets(ctx, volume, stageReqs[stagingTargetPath])
+			if err != nil {
+				return fmt.Errorf("failed to get secrets to stage volume at %v again: %v", stagingTargetPath, err)
+			}
+			_, err = h.ns.NodeStageVolume(ctx, stageReq)
+			clear(stageReq.Secrets)
+			if err != nil {
+				return fmt.Errorf("failed to stage volume at %v again: %v", stagingTargetPath, err)
+			}
+		}
+		for _, req := range corruptedPublishReqs {
+			if _, err := h.ns.NodePublishVolume(ctx, req); err != nil {
+				return fmt.Errorf("failed to publish volume at %v again: %v", req.GetTargetPath(), err)
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		log.WithError(err).Errorf("Failed to remount corrupted mount points %v", targetPaths)
+		h.ns.recordVolumeEvent(volumeID, corev1.EventTypeWarning, constant.EventReasonFailedRemount,
+			"Failed to remount corrupted mount points %v on node %v: %v", strings.Join(targetPaths, ", "), h.ns.nodeID, err)
+		return
+	}
+
+	log.Infof("Remounted corrupted mount points %v", targetPaths)
+	h.ns.recordVolumeEvent(volumeID, corev1.EventTypeNormal, constant.EventReasonRemount,
+		"Remounted corrupted mount points %v on node %v", strings.Join(targetPaths, ", "), h.ns.nodeID)
+}
+
+// getStageRequestWithSecrets returns the stage request of an encrypted volume along with the node stage secrets
+// referenced by its PV. The data of the secret is zeroed once it's copied to the request, which is cleared by the
+// caller after use.
+func (h *mountHealer) getStageRequestWithSecrets(ctx context.Context, volume *longhornclient.Volume, req *csi.NodeStageVolumeRequest) (*csi.NodeStageVolumeRequest, error) {
+	if volume == nil {
+		return nil, fmt.Errorf("volume %v not found", req.GetVolumeId())
+	}
+	if !volume.Encrypted {
+		return req, nil
+	}
+
+	pv, err := h.ns.kubeClient.CoreV1().PersistentVolumes().Get(ctx, volume.KubernetesStatus.PvName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if pv.Spec.CSI == nil || pv.Spec.CSI.NodeStageSecretRef == nil {
+		return nil, fmt.Errorf("PV %v of encrypted volume %v has no node stage secret", pv.Name, volume.Name)
+	}
+	secretRef := pv.Spec.CSI.NodeStageSecretRef
+	secret, err := h.ns.kubeClient.CoreV1().Secrets(secretRef.Namespace).Get(ctx, secretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	stageReq := proto.Clone(req).(*csi.NodeStageVolumeRequest)
+	stageReq.Secrets = map[string]string{}
+	for key, value := range secret.Data {
+		stageReq.Secrets[key] = string(value)
+		clear(value)
+	}
+	return stageReq, nil
+}
+
+// isMountPointCorrupted checks the mount the same way as ensureMountPoint. The sync check writes to the mount, so
+// only the directory is read for a read-only mount.
+func isMountPointCorrupted(path string, readOnly bool, mounter mount.Interface) bool {
+	isMnt, err := mounter.IsMountPoint(path)
+	if mount.IsCorruptedMnt(err) {
+		return true
+	}
+	if err != nil || !isMnt {
+		return false
+	}
+	if readOnly {
+		return readMountPointDirectory(path) != nil
+	}
+	return syncMountPointDirectory(path) != nil
+}
+
+func isReadOnlyMount(volumeCapability *csi.VolumeCapability, readOnly bool) bool {
+	if readOnly {
+		return true
+	}
+	for _, flag := range volumeCapability.GetMount().GetMountFlags() {
+		if flag == "ro" {
+			return true
+		}
+	}
+	return false
+}
@@ -3,12 +3,12 @@ package csi
 import (
 	"context"
 	"fmt"
-	"io"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/pkg/errors"
@@ -17,7 +17,9 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/mount-utils"
 
 	corev1 "k8s.io/api/core/v1"
@@ -25,12 +27,14 @@ import (
 	clientset "k8s.io/client-go/kubernetes"
 	utilexec "k8s.io/utils/exec"
 
+	"github.com/longhorn/longhorn-manager/constant"
 	"github.com/longhorn/longhorn-manager/csi/crypto"
-	"github.com/longhorn/longhorn-manager/engineapi"
+	"github.com/longhorn/longhorn-manager/csi/journal"
 	"github.com/longhorn/longhorn-manager/types"
 
 	lhns "github.com/longhorn/go-common-libs/ns"
 
+	apputil "github.com/longhorn/longhorn-manager/app/util"
 	longhornclient "github.com/longhorn/longhorn-manager/client"
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 	lhclientset "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned"
@@ -62,9 +66,17 @@ type NodeServer struct {
 	lhNamespace string
 	kubeClient  *clientset.Clientset
 	lhClient    *lhclientset.Clientset
+
+	// forceUnmountTimeout is the timeout of the force unmounts of the volumes without their own timeout
+	forceUnmountTimeout time.Duration
+
+	eventRecorder record.EventRecorder
+
+	mountHealer *mountHealer
+	importer    *volumeImporter
 }
 
-func NewNodeServer(apiClient *longhornclient.RancherClient, nodeID string) (*NodeServer, error) {
+func NewNodeServer(apiClient *longhornclient.RancherClient, nodeID string, forceUnmountTimeout time.Duration) (*NodeServer, error) {
 	lhNamespace := os.Getenv(types.EnvPodNamespace)
 	if lhNamespace == "" {
 		return nil, fmt.Errorf("failed to detect pod namespace, environment variable %v is missing", types.EnvPodNamespace)
@@ -85,7 +97,16 @@ func NewNodeServer(apiClient *longhornclient.RancherClient, nodeID string) (*Nod
 		return nil, errors.Wrap(err, "failed to get longhorn clientset")
 	}
 
-	return &NodeServer{
+	scheme := runtime.NewScheme()
+	if err := longhorn.SchemeBuilder.AddToScheme(scheme); err != nil {
+		return nil, errors.Wrap(err, "failed to create scheme")
+	}
+	eventBroadcaster, err := apputil.CreateEventBroadcaster(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ns := &NodeServer{
 		apiClient: apiClient,
 		nodeID:    nodeID,
 		caps: getNodeServiceCapabilities(
@@ -93,20 +114,35 @@ func NewNodeServer(apiClient *longhornclient.RancherClient, nodeID string) (*Nod
 				csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
 				csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
 				csi.NodeServiceCapability_RPC_EXPAND_VOLUME,
+				csi.NodeServiceCapability_RPC_VOLUME_MOUNT_GROUP,
 			}),
 		log:         logrus.StandardLogger().WithField("component", "csi-node-server"),
 		lhNamespace: lhNamespace,
 		kubeClient:  kubeClient,
 		lhClient:    lhClient,
-	}, nil
+
+		forceUnmountTimeout: forceUnmountTimeout,
+
+		eventRecorder: eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "longhorn-csi-plugin"}),
+	}
+	ns.mountHealer = newMountHealer(ns)
+	ns.importer = newVolumeImporter(ns)
+	return ns, nil
 }
 
 // NodePublishVolume will mount the volume /dev/longhorn/<volume_name> to target_path
-func (ns *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) (*csi.NodePublishVolumeResponse, error) {
+func (ns *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) (resp *csi.NodePublishVolumeResponse, err error) {
 	log := ns.log.WithFields(logrus.Fields{"function": "NodePublishVolume"})
 
 	log.Infof("NodePublishVolume is called with req %+v", req)
 
+	defer func(startTime time.Time) {
+		ns.recordMountJournal(req.GetVolumeId(), journal.OperationMount, req.GetTargetPath(), req.GetVolumeCapability().GetMount().GetMountFlags(), startTime, err)
+		if err == nil {
+			ns.mountHealer.trackPublish(req)
+		}
+	}(time.Now())
+
 	targetPath := req.GetTargetPath()
 	if targetPath == "" {
 		return nil, status.Error(codes.InvalidArgument, "target path missing in request")
@@ -127,13 +163,14 @@ func (ns *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 		return nil, status.Error(codes.InvalidArgument, "volume id missing in request")
 	}
 
-	volume, err := ns.apiClient.Volume.ById(volumeID)
+	volume, err := ns.apiClient.WithContext(ctx).Volume.ById(volumeID)
 	if err != nil {
 		return nil, status.Error(codes.Internal, errors.Wrapf(err, "failed to get volume %s for publishing volume", volumeID).Error())
 	}
 	if volume == nil {
 		return nil, status.Errorf(codes.NotFound, "volume %s not found", volumeID)
 	}
+	forceUnmountTimeout := ns.getForceUnmountTimeout(volume)
 
 	mounter, err := ns.getMounter(volume, volumeCapability, req.VolumeContext)
 	if err != nil {
@@ -149,7 +186,7 @@ func (ns *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 		return nil, status.Errorf(codes.InvalidArgument, "volume %s frontend is disabled", volumeID)
 	}
 
-	if volume.Frontend != string(longhorn.VolumeFrontendBlockDev) && volume.Frontend != "ublk" {
+	if !isSupportedVolumeFrontend(volume) {
 		return nil, status.Errorf(codes.InvalidArgument, "volume %s has invalid frontend type %v", volumeID, volume.Frontend)
 	}
 
@@ -157,7 +194,7 @@ func (ns *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 	if types.IsDataEngineV1(longhorn.DataEngineType(volume.DataEngine)) {
 		if volume.State != string(longhorn.VolumeStateAttached) || volume.Controllers[0].Endpoint == "" {
 			log.WithField("state", volume.State).Infof("Volume %v hasn't been attached yet, unmounting potential mount point %v", volumeID, targetPath)
-			if err := unmount(targetPath, mounter); err != nil {
+			if err := unmount(targetPath, mounter, forceUnmountTimeout); err != nil {
 				log.WithError(err).Warnf("Failed to unmount targetPath %v", targetPath)
 			}
 			return nil, status.Errorf(codes.InvalidArgument, "volume %s hasn't been attached yet", volumeID)
@@ -178,12 +215,12 @@ func (ns *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 	// FailedPrecondition and expect kubelet to call NodeStageVolume again, but as of Kubernetes v1.27 it does not.
 	isBlock := volumeCapability.GetBlock() != nil
 
-	storageNetworkSetting, err := ns.apiClient.Setting.ById(string(types.SettingNameStorageNetwork))
+	storageNetworkSetting, err := ns.apiClient.WithContext(ctx).Setting.ById(string(types.SettingNameStorageNetwork))
 	if err != nil {
 		log.WithError(err).Warnf("Skipping restaging condition check for storage network setting")
 	}
 
-	restageRequired, err := restageRequired(volume, volumeID, stagingTargetPath, mounter, isBlock, storageNetworkSetting.Value != "")
+	restageRequired, err := restageRequired(volume, volumeID, stagingTargetPath, mounter, forceUnmountTimeout, isBlock, storageNetworkSetting.Value != "")
 	if restageRequired {
 		msg := fmt.Sprintf("Staging target path %v is no longer valid for volume %v", stagingTargetPath, volumeID)
 		log.WithError(err).Warn(msg)
@@ -227,7 +264,7 @@ func (ns *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 		return &csi.NodePublishVolumeResponse{}, nil
 	}
 
-	isMnt, err := ensureMountPoint(targetPath, mounter)
+	isMnt, err := ensureMountPoint(targetPath, mounter, forceUnmountTimeout)
 	if err != nil {
 		msg := fmt.Sprintf("Failed to prepare mount point for volume %v error %v", volumeID, err)
 		log.WithError(err).Error(msg)
@@ -237,16 +274,22 @@ func (ns *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 		return &csi.NodePublishVolumeResponse{}, nil
 	}
 
-	mountOptions := []string{"bind"}
-	if req.GetReadonly() {
-		mountOptions = append(mountOptions, "ro")
+	if !req.GetReadonly() {
+		if err := ns.checkStagingFilesystem(volumeID, stagingTargetPath); err != nil {
+			return nil, err
+		}
 	}
-	mountOptions = append(mountOptions, volumeCapability.GetMount().GetMountFlags()...)
 
+	mountOptions := getPublishMountOptions(volumeCapability, req.GetReadonly(), requiresSharedAccess(volume, volumeCapability))
 	if err := mounter.Mount(stagingTargetPath, targetPath, "", mountOptions); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to bind mount volume %v", volumeID)
 	}
 
+	// the volume is only staged once on a node, so the group of each pod is applied when it's published
+	if err := ns.applyVolumeMountGroup(volume, volumeCapability, targetPath, req.GetReadonly()); err != nil {
+		return nil, err
+	}
+
 	return &csi.NodePublishVolumeResponse{}, nil
 }
 
@@ -261,10 +304,10 @@ func (ns *NodeServer) collectWorkloadPodsStatus(volume *longhornclient.Volume, l
 	return podsStatus
 }
 
-func (ns *NodeServer) nodeStageSharedVolume(volumeID, shareEndpoint, targetPath string, mounter mount.Interface, customMountOptions []string) error {
+func (ns *NodeServer) nodeStageSharedVolume(volumeID, shareEndpoint, targetPath string, mounter mount.Interface, customMountOptions []string, forceUnmountTimeout time.Duration) error {
 	log := ns.log.WithFields(logrus.Fields{"function": "nodeStageSharedVolume"})
 
-	isMnt, err := ensureMountPoint(targetPath, mounter)
+	isMnt, err := ensureMountPoint(targetPath, mounter, forceUnmountTimeout)
 	if err != nil {
 		return status.Errorf(codes.Internal, "failed to prepare mount point for shared volume %v: %v", volumeID, err)
 	}
@@ -329,11 +372,19 @@ func (ns *NodeServer) nodeStageSharedVolume(volumeID, shareEndpoint, targetPath
 	return nil
 }
 
-func (ns *NodeServer) nodeStageMountVolume(volumeID, devicePath, stagingTargetPath, fsType string, mountFlags []string, mounter *mount.SafeFormatAndMount) error {
+// nodeStageMountVolume formats the device if needed and mounts it at stagingTargetPath. A corrupted mount at
+// stagingTargetPath is cleaned up and requires a filesystem check. If checkFilesystem is set, the filesystem of a
+// volume requiring a check is checked read-only before it's mounted.
+func (ns *NodeServer) nodeStageMountVolume(volumeID, devicePath, stagingTargetPath, fsType string, mountFlags []string, mounter *mount.SafeFormatAndMount, forceUnmountTimeout time.Duration, checkFilesystem bool) (err error) {
 	log := ns.log.WithFields(logrus.Fields{"function": "nodeStageMountVolume"})
 	log.Infof("nodeStageMountVolume called with volumeID: %v, devicePath: %v, stagingTargetPath: %v, fsType: %v, mountFlags: %v", volumeID, devicePath, stagingTargetPath, fsType, mountFlags)
 
-	isMnt, err := ensureMountPoint(stagingTargetPath, mounter)
+	if isMountPointCorrupted(stagingTargetPath, false, mounter) {
+		ns.setVolumeCondition(volumeID, longhorn.VolumeConditionTypeFilesystemCheckRequired, longhorn.ConditionStatusTrue,
+			longhorn.VolumeConditionReasonCorruptedMount, fmt.Sprintf("Corrupted mount point %v was cleaned up on node %v", stagingTargetPath, ns.nodeID))
+	}
+
+	isMnt, err := ensureMountPoint(stagingTargetPath, mounter, forceUnmountTimeout)
 	if err != nil {
 		return status.Errorf(codes.Internal, "failed to prepare mount point %v for volume %v: %v", stagingTargetPath, volumeID, err)
 	}
@@ -345,6 +396,17 @@ func (ns *NodeServer) nodeStageMountVolume(volumeID, devicePath, stagingTargetPa
 		return status.Error(codes.Internal, errors.Wrapf(err, "failed to check if device %v exists", devicePath).Error())
 	}
 
+	if checkFilesystem {
+		ns.checkFilesystemAfterUncleanDetach(volumeID, devicePath, fsType, mounter)
+	}
+
+	// The device is formatted by FormatAndMount if it doesn't contain a filesystem yet
+	if diskFormat, formatErr := getDiskFormat(devicePath); formatErr == nil && diskFormat == "" {
+		defer func(startTime time.Time) {
+			ns.recordMountJournal(volumeID, journal.OperationFormat, devicePath, []string{"fsType=" + fsType}, startTime, err)
+		}(time.Now())
+	}
+
 	log.Infof("Formatting device %v with fsType %v and mounting at %v with mount flags %v", devicePath, fsType, stagingTargetPath, mountFlags)
 	if err := mounter.FormatAndMount(devicePath, stagingTargetPath, fsType, mountFlags); err != nil {
 		return status.Error(codes.Internal, err.Error())
@@ -352,6 +414,229 @@ func (ns *NodeServer) nodeStageMountVolume(volumeID, devicePath, stagingTargetPa
 	return nil
 }
 
+// resizeStagedFilesystem grows the filesystem mounted at stagingTargetPath to the size of the device. If the online
+// resize fails, the filesystem is unmounted and resized offline when the filesystem supports it, then mounted again.
+// If the filesystem still cannot be resized, the volume condition FilesystemResizeFailed is set and the mount is
+// failed, unless the setting block-mount-on-filesystem-resize-failure allows mounting the smaller filesystem.
+func (ns *NodeServer) resizeStagedFilesystem(volumeID, devicePath, stagingTargetPath, fsType string, mountFlags []string, mounter *mount.SafeFormatAndMount, forceUnmountTimeout time.Duration) error {
+	log := ns.log.WithFields(logrus.Fields{"function": "resizeStagedFilesystem"})
+
+	resizer := mount.NewResizeFs(mounter.Exec)
+	needsResize, err := resizer.NeedResize(devicePath, stagingTargetPath)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if !needsResize {
+		log.Infof("Mounted volume %v on node %v does not require filesystem resize", volumeID, ns.nodeID)
+		ns.setFilesystemResizeFailedCondition(volumeID, nil)
+		return nil
+	}
+
+	resized, resizeErr := resizer.Resize(devicePath, stagingTargetPath)
+	if resizeErr == nil {
+		if resized {
+			log.Infof("Mounted volume %v on node %v successfully resized filesystem after mount", volumeID, ns.nodeID)
+		} else {
+			log.Infof("Mounted volume %v on node %v already has correct filesystem size", volumeID, ns.nodeID)
+		}
+		ns.setFilesystemResizeFailedCondition(volumeID, nil)
+		return nil
+	}
+	log.WithError(resizeErr).Warnf("Mounted volume %v on node %v failed online filesystem resize", volumeID, ns.nodeID)
+
+	if isOfflineResizeSupported(fsType) {
+		log.Infof("Unmounting volume %v from %v to resize filesystem %v offline", volumeID, stagingTargetPath, fsType)
+		if err := unmount(stagingTargetPath, mounter, forceUnmountTimeout); err != nil {
+			return status.Error(codes.Internal, errors.Wrapf(err, "failed to unmount volume %v for offline filesystem resize", volumeID).Error())
+		}
+		offlineResizeErr := resizeFilesystemOffline(devicePath, mounter.Exec)
+		if err := mounter.FormatAndMount(devicePath, stagingTargetPath, fsType, mountFlags); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if offlineResizeErr == nil {
+			log.Infof("Volume %v on node %v successfully resized filesystem offline", volumeID, ns.nodeID)
+			ns.setFilesystemResizeFailedCondition(volumeID, nil)
+			return nil
+		}
+		log.WithError(offlineResizeErr).Warnf("Volume %v on node %v failed offline filesystem resize", volumeID, ns.nodeID)
+		resizeErr = errors.Wrapf(offlineResizeErr, "failed offline resize after online resize failure %v", resizeErr)
+	}
+
+	ns.setFilesystemResizeFailedCondition(volumeID, resizeErr)
+
+	blockMount, err := ns.isMountBlockedOnFilesystemResizeFailure()
+	if err != nil {
+		log.WithError(err).Warnf("Failed to get setting %v, blocking the mount of volume %v", types.SettingNameBlockMountOnFilesystemResizeFailure, volumeID)
+		blockMount = true
+	}
+	if !blockMount {
+		log.WithError(resizeErr).Warnf("Mounted volume %v on node %v with a filesystem smaller than the volume", volumeID, ns.nodeID)
+		return nil
+	}
+
+	if err := unmount(stagingTargetPath, mounter, forceUnmountTimeout); err != nil {
+		log.WithError(err).Warnf("Failed to unmount volume %v after filesystem resize failure", volumeID)
+	}
+	return status.Errorf(codes.FailedPrecondition, "failed to resize filesystem of volume %v: %v", volumeID, resizeErr)
+}
+
+func (ns *NodeServer) isMountBlockedOnFilesystemResizeFailure() (bool, error) {
+	setting, err := ns.apiClient.Setting.ById(string(types.SettingNameBlockMountOnFilesystemResizeFailure))
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(setting.Value)
+}
+
+func (ns *NodeServer) isFIPSModeEnabled() (bool, error) {
+	setting, err := ns.apiClient.Setting.ById(string(types.SettingNameFIPSModeEnabled))
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(setting.Value)
+}
+
+// checkFilesystemAfterUncleanDetach checks the filesystem of the device read-only if the volume condition
+// FilesystemCheckRequired is set, and updates the condition with the result. The volume is mounted regardless of the
+// result, so the check only surfaces the corruption.
+func (ns *NodeServer) checkFilesystemAfterUncleanDetach(volumeID, devicePath, fsType string, mounter *mount.SafeFormatAndMount) {
+	log := ns.log.WithFields(logrus.Fields{"function": "checkFilesystemAfterUncleanDetach", "volume": volumeID})
+
+	volume, err := ns.apiClient.Volume.ById(volumeID)
+	if err != nil || volume == nil {
+		log.WithError(err).Warnf("Failed to get volume to check condition %v", longhorn.VolumeConditionTypeFilesystemCheckRequired)
+		return
+	}
+	condition, _ := volume.Conditions[longhorn.VolumeConditionTypeFilesystemCheckRequired].(map[string]interface{})
+	if conditionStatus, _ := condition["status"].(string); conditionStatus != string(longhorn.ConditionStatusTrue) {
+		return
+	}
+	// there is nothing to check before the device is formatted
+	if diskFormat, err := getDiskFormat(devicePath); err != nil || diskFormat == "" {
+		return
+	}
+	// the filesystem mounted elsewhere is being changed, so the check would report the in-flight changes as errors
+	if isMounted, err := isDeviceMounted(devicePath, mounter); err != nil || isMounted {
+		log.WithError(err).Warnf("Skipped filesystem check of device %v since it's mounted or its mounts cannot be listed", devicePath)
+		return
+	}
+
+	log.Infof("Checking filesystem %v of device %v after unclean detach", fsType, devicePath)
+	err = checkFilesystem(devicePath, fsType, mounter.Exec)
+	if errors.Is(err, errFilesystemLogDirty) {
+		log.Warnf("Skipped filesystem check of device %v since the journal is replayed by the mount, it's checked on the next stage", devicePath)
+		return
+	}
+	if err != nil {
+		log.WithError(err).Warn("Filesystem check failed after unclean detach")
+		ns.setVolumeCondition(volumeID, longhorn.VolumeConditionTypeFilesystemCheckRequired, longhorn.ConditionStatusTrue,
+			longhorn.VolumeConditionReasonFilesystemCheckFailure, fmt.Sprintf("Filesystem check failed on node %v: %v", ns.nodeID, err))
+		return
+	}
+	log.Info("Filesystem check passed after unclean detach")
+	ns.setVolumeCondition(volumeID, longhorn.VolumeConditionTypeFilesystemCheckRequired, longhorn.ConditionStatusFalse,
+		longhorn.VolumeConditionReasonFilesystemCheckPassed, fmt.Sprintf("Filesystem check passed on node %v", ns.nodeID))
+}
+
+// setFilesystemResizeFailedCondition sets the volume condition FilesystemResizeFailed if resizeErr is not nil, or
+// clears it otherwise
+func (ns *NodeServer) setFilesystemResizeFailedCondition(volumeID string, resizeErr error) {
+	if resizeErr == nil {
+		ns.clearVolumeCondition(volumeID, longhorn.VolumeConditionTypeFilesystemResizeFailed)
+		return
+	}
+	ns.setVolumeCondition(volumeID, longhorn.VolumeConditionTypeFilesystemResizeFailed, longhorn.ConditionStatusTrue,
+		longhorn.VolumeConditionReasonFilesystemResizeFailure, fmt.Sprintf("Failed to resize filesystem on node %v: %v", ns.nodeID, resizeErr))
+}
+
+// clearVolumeCondition sets the volume condition to false if it's true. There is nothing to clear for a volume which
+// never had the condition set.
+func (ns *NodeServer) clearVolumeCondition(volumeID, conditionType string) {
+	ns.setVolumeCondition(volumeID, conditionType, longhorn.ConditionStatusFalse, "", "")
+}
+
+// checkStagingFilesystem fails the publish of a writable mount if the staged filesystem is read-only or has no space
+// or inodes left, since the workload would only fail on its first write without a clear reason
+func (ns *NodeServer) checkStagingFilesystem(volumeID, stagingTargetPath string) error {
+	log := ns.log.WithFields(logrus.Fields{"function": "checkStagingFilesystem"})
+
+	stats, err := getFilesystemStatistics(stagingTargetPath)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to get filesystem statistics of staging path %v of volume %v, skipping the check", stagingTargetPath, volumeID)
+		return nil
+	}
+
+	var problem string
+	switch {
+	case stats.readOnly:
+		problem = "read-only"
+	case stats.availableBytes == 0:
+		problem = "full"
+	case stats.totalInodes > 0 && stats.availableInodes == 0:
+		problem = "out of inodes"
+	default:
+		return nil
+	}
+
+	msg := fmt.Sprintf("filesystem of volume %v staged at %v is %v", volumeID, stagingTargetPath, problem)
+	log.Warn(msg)
+	ns.recordVolumeEvent(volumeID, corev1.EventTypeWarning, constant.EventReasonFailedPublish,
+		"Failed to publish volume on node %v: %v", ns.nodeID, msg)
+	return status.Error(codes.FailedPrecondition, msg)
+}
+
+// recordVolumeEvent records an event of the volume. The events are only informative, so a failure to get the volume
+// is just logged.
+func (ns *NodeServer) recordVolumeEvent(volumeID, eventType, reason, messageFmt string, args ...interface{}) {
+	volume, err := ns.lhClient.LonghornV1beta2().Volumes(ns.lhNamespace).Get(context.TODO(), volumeID, metav1.GetOptions{})
+	if err != nil {
+		ns.log.WithError(err).Warnf("Failed to get volume %v to record event %v", volumeID, reason)
+		return
+	}
+	ns.eventRecorder.Eventf(volume, eventType, reason, messageFmt, args...)
+}
+
+// setVolumeCondition sets the volume condition through the longhorn-manager API, which owns the volume status. The
+// conditions set by the node server are only informative, so a failure to update them is just logged.
+func (ns *NodeServer) setVolumeCondition(volumeID, conditionType string, conditionStatus longhorn.ConditionStatus, reason, message string) {
+	log := ns.log.WithFields(logrus.Fields{"function": "setVolumeCondition"})
+
+	volume, err := ns.apiClient.Volume.ById(volumeID)
+	if err != nil || volume == nil {
+		log.WithError(err).Warnf("Failed to get volume %v to update condition %v", volumeID, conditionType)
+		return
+	}
+
+	input := &longhornclient.UpdateFilesystemConditionInput{
+		Type:    conditionType,
+		Status:  string(conditionStatus),
+		Reason:  reason,
+		Message: message,
+	}
+	if _, err := ns.apiClient.Volume.ActionUpdateFilesystemCondition(volume, input); err != nil {
+		log.WithError(err).Warnf("Failed to update condition %v of volume %v", conditionType, volumeID)
+	}
+}
+
+// applyVolumeMountGroup applies the fsGroup delegated by kubelet to a mounted volume. The ownership of a shared
+// volume is only changed at the root of the export, since walking the whole filesystem over NFS is too slow. Like
+// kubelet, the group is not applied to a read-only mount, which cannot be changed.
+func (ns *NodeServer) applyVolumeMountGroup(volume *longhornclient.Volume, volumeCapability *csi.VolumeCapability, mountPath string, readOnly bool) error {
+	log := ns.log.WithFields(logrus.Fields{"function": "applyVolumeMountGroup"})
+
+	volumeMountGroup := volumeCapability.GetMount().GetVolumeMountGroup()
+	if volumeMountGroup == "" || isReadOnlyMount(volumeCapability, readOnly) {
+		return nil
+	}
+
+	recursive := !requiresSharedAccess(volume, volumeCapability) || volume.Migratable
+	log.Infof("Applying volume mount group %v to volume %v at %v, recursive: %v", volumeMountGroup, volume.Name, mountPath, recursive)
+	if err := applyVolumeMountGroup(mountPath, volumeMountGroup, recursive); err != nil {
+		return status.Errorf(codes.Internal, "failed to apply volume mount group %v to volume %v: %v", volumeMountGroup, volume.Name, err)
+	}
+	return nil
+}
+
 // nodeStageBlockVolume utilizes the stagingTargetPath to create a volumeID file to bind mount the devicePath
 // this is valid since the csi plugin is in control of the staging path
 func (ns *NodeServer) nodeStageBlockVolume(volumeID, devicePath, stagingTargetPath string, mounter mount.Interface) error {
@@ -382,11 +667,18 @@ func (ns *NodeServer) nodePublishBlockVolume(volumeID, devicePath, targetPath st
 	return nil
 }
 
-func (ns *NodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpublishVolumeRequest) (*csi.NodeUnpublishVolumeResponse, error) {
+func (ns *NodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpublishVolumeRequest) (resp *csi.NodeUnpublishVolumeResponse, err error) {
 	log := ns.log.WithFields(logrus.Fields{"function": "NodeUnpublishVolume"})
 
 	log.Infof("NodeUnpublishVolume is called with req %+v", req)
 
+	defer func(startTime time.Time) {
+		ns.recordMountJournal(req.GetVolumeId(), journal.OperationUnmount, req.GetTargetPath(), nil, startTime, err)
+		if err == nil {
+			ns.mountHealer.untrackPublish(req.GetTargetPath())
+		}
+	}(time.Now())
+
 	targetPath := req.GetTargetPath()
 	if targetPath == "" {
 		return nil, status.Error(codes.InvalidArgument, "target path missing in request")
@@ -397,7 +689,9 @@ func (ns *NodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpu
 		return nil, status.Error(codes.InvalidArgument, "volume id missing in request")
 	}
 
-	if err := unmountAndCleanupMountPoint(targetPath, mount.New("")); err != nil {
+	// The volume is optional here, the driver timeout is used if it's gone
+	volume, _ := ns.apiClient.WithContext(ctx).Volume.ById(volumeID)
+	if err := unmountAndCleanupMountPoint(targetPath, mount.New(""), ns.getForceUnmountTimeout(volume)); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to cleanup volume %s mount point %v: %v", volumeID, targetPath, err)
 	}
 
@@ -405,11 +699,18 @@ func (ns *NodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpu
 	return &csi.NodeUnpublishVolumeResponse{}, nil
 }
 
-func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolumeRequest) (*csi.NodeStageVolumeResponse, error) {
+func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolumeRequest) (resp *csi.NodeStageVolumeResponse, err error) {
 	log := ns.log.WithFields(logrus.Fields{"function": "NodeStageVolume"})
 
 	log.Infof("NodeStageVolume is called with req %+v", req)
 
+	defer func(startTime time.Time) {
+		ns.recordMountJournal(req.GetVolumeId(), journal.OperationMount, req.GetStagingTargetPath(), req.GetVolumeCapability().GetMount().GetMountFlags(), startTime, err)
+		if err == nil {
+			ns.mountHealer.trackStage(req)
+		}
+	}(time.Now())
+
 	stagingTargetPath := req.GetStagingTargetPath()
 	if stagingTargetPath == "" {
 		return nil, status.Error(codes.InvalidArgument, "staging target path missing in request")
@@ -425,13 +726,14 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 		return nil, status.Error(codes.InvalidArgument, "volume id missing in request")
 	}
 
-	volume, err := ns.apiClient.Volume.ById(volumeID)
+	volume, err := ns.apiClient.WithContext(ctx).Volume.ById(volumeID)
 	if err != nil {
 		return nil, status.Error(codes.Internal, errors.Wrapf(err, "failed to get volume %s for staging volume", volumeID).Error())
 	}
 	if volume == nil {
 		return nil, status.Errorf(codes.NotFound, "volume %s not found", volumeID)
 	}
+	forceUnmountTimeout := ns.getForceUnmountTimeout(volume)
 
 	mounter, err := ns.getMounter(volume, volumeCapability, req.VolumeContext)
 	if err != nil {
@@ -447,14 +749,14 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 		return nil, status.Errorf(codes.InvalidArgument, "volume %s frontend is disabled", volumeID)
 	}
 
-	if volume.Frontend != string(longhorn.VolumeFrontendBlockDev) && volume.Frontend != "ublk" {
+	if !isSupportedVolumeFrontend(volume) {
 		return nil, status.Errorf(codes.InvalidArgument, "volume %s has invalid frontend type %v", volumeID, volume.Frontend)
 	}
 
 	// Check volume attachment status
 	if volume.State != string(longhorn.VolumeStateAttached) || volume.Controllers[0].Endpoint == "" {
 		log.Infof("Volume %v hasn't been attached yet, unmounting potential mount point %v", volumeID, stagingTargetPath)
-		if err := unmount(stagingTargetPath, mounter); err != nil {
+		if err := unmount(stagingTargetPath, mounter, forceUnmountTimeout); err != nil {
 			log.WithError(err).Warnf("Failed to unmount stagingTargetPath %v", stagingTargetPath)
 		}
 		return nil, status.Errorf(codes.InvalidArgument, "volume %s hasn't been attached yet", volumeID)
@@ -464,6 +766,27 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 		return nil, status.Errorf(codes.Aborted, "volume %s is not ready for workloads", volumeID)
 	}
 
+	// A RWX block volume is exported over iSCSI by its engine, each node logs in to the target to use the device
+	if isRWXBlockVolume(volume) {
+		if volumeCapability.GetBlock() == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "volume %s is exported as a raw block device and cannot be mounted", volumeID)
+		}
+
+		devicePath, err := loginISCSITarget(volume.Controllers[0].Endpoint)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if err := ensureDeviceNotClaimed(volumeID, devicePath, "", log); err != nil {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		if err := ns.nodeStageBlockVolume(volumeID, devicePath, stagingTargetPath, mounter); err != nil {
+			return nil, err
+		}
+
+		log.Infof("Volume %v device %v exported from node %v available for usage as block device", volumeID, devicePath, volume.Controllers[0].HostId)
+		return &csi.NodeStageVolumeResponse{}, nil
+	}
+
 	if requiresSharedAccess(volume, volumeCapability) && !volume.Migratable {
 		if volume.AccessMode != string(longhorn.AccessModeReadWriteMany) {
 			return nil, status.Errorf(codes.FailedPrecondition, "volume %s requires shared access but is not marked for shared use", volumeID)
@@ -473,14 +796,21 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 			return nil, status.Errorf(codes.Aborted, "volume %s share not yet available", volumeID)
 		}
 
-		// undocumented field to allow testing different nfs mount options
-		// this can be used to enable the default host (ubuntu) client async mode
+		// The nfsOptions parameter of the StorageClass, or of the PVC annotation, replaces the default NFS mount options,
+		// e.g. vers=4.2,nconnect=4,hard. It's validated when the volume is created.
 		var mountOptions []string
-		if len(req.VolumeContext["nfsOptions"]) > 0 {
-			mountOptions = strings.Split(req.VolumeContext["nfsOptions"], ",")
+		if nfsOptions := req.VolumeContext["nfsOptions"]; nfsOptions != "" {
+			if err := types.ValidateNFSOptions(nfsOptions); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid nfsOptions for volume %v: %v", volumeID, err)
+			}
+			mountOptions = strings.Split(nfsOptions, ",")
 		}
 
-		if err := ns.nodeStageSharedVolume(volumeID, volume.ShareEndpoint, stagingTargetPath, mounter, mountOptions); err != nil {
+		if err := ns.nodeStageSharedVolume(volumeID, volume.ShareEndpoint, stagingTargetPath, mounter, mountOptions, forceUnmountTimeout); err != nil {
+			return nil, err
+		}
+
+		if err := ns.applyVolumeMountGroup(volume, volumeCapability, stagingTargetPath, false); err != nil {
 			return nil, err
 		}
 
@@ -492,13 +822,40 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 	if volume.Frontend == "ublk" {
 		devicePath = "/dev/ublkb0"
 	}
+	if isNvmfEndpoint(devicePath) {
+		nvmfDevicePath, err := connectNvmfTarget(devicePath)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		log.Infof("Volume %v connected to NVMe-oF endpoint %v via device %v", volumeID, devicePath, nvmfDevicePath)
+		devicePath = nvmfDevicePath
+	}
+
+	dataEngine := volume.DataEngine
+	if err := ensureDeviceNotClaimed(volumeID, devicePath, crypto.VolumeMapper(volumeID, dataEngine), log); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err := verifyDeviceIdentity(volume, devicePath); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to verify device of volume %v: %v", volumeID, err)
+	}
+	// The data of the import source is copied before the device is formatted, so the existing filesystem is used
+	if importSource := req.VolumeContext[volumeImportSourceKey]; importSource != "" {
+		if importNode := req.VolumeContext[volumeImportNodeKey]; importNode != ns.nodeID {
+			return nil, status.Errorf(codes.FailedPrecondition, "volume %s must be staged on node %s to import data from %s", volumeID, importNode, importSource)
+		}
+		if err := ns.importer.check(ctx, volume, importSource, devicePath); err != nil {
+			if errors.Is(err, errImportInProgress) {
+				return nil, status.Errorf(codes.Aborted, "volume %s is being imported from %s", volumeID, importSource)
+			}
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
 
 	diskFormat, err := getDiskFormat(devicePath)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to evaluate device filesystem %v format: %v", devicePath, err)
 	}
 
-	dataEngine := volume.DataEngine
 	log.Infof("Volume %v (%v) device %v contains filesystem of format %v", volumeID, dataEngine, devicePath, diskFormat)
 
 	if volume.Encrypted {
@@ -519,8 +876,18 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 
 		cryptoParams := crypto.NewEncryptParams(keyProvider, secrets[types.CryptoKeyCipher], secrets[types.CryptoKeyHash], secrets[types.CryptoKeySize], secrets[types.CryptoPBKDF])
 
+		fipsModeEnabled, err := ns.isFIPSModeEnabled()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get %v setting: %v", types.SettingNameFIPSModeEnabled, err)
+		}
+
 		// initial setup of longhorn device for crypto
 		if diskFormat == "" {
+			if fipsModeEnabled {
+				if err := cryptoParams.ValidateFIPS(); err != nil {
+					return nil, status.Errorf(codes.InvalidArgument, "failed to encrypt volume %v in FIPS mode: %v", volumeID, err)
+				}
+			}
 			if err := crypto.EncryptVolume(devicePath, passphrase, cryptoParams); err != nil {
 				return nil, status.Error(codes.Internal, err.Error())
 			}
@@ -541,6 +908,21 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 			}
 		}
 
+		// the passphrase in the secret replaces the previous one when it is being rotated
+		if previousPassphrase := secrets[types.CryptoPreviousKeyValue]; diskFormat == "crypto_LUKS" && previousPassphrase != "" {
+			pbkdf := cryptoParams.PBKDF
+			if fipsModeEnabled {
+				pbkdf = crypto.CryptoFIPSPBKDF
+			}
+			rotated, err := crypto.RotatePassphrase(devicePath, passphrase, previousPassphrase, pbkdf)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			if rotated {
+				log.Infof("Rotated passphrase of encrypted volume %v", volumeID)
+			}
+		}
+
 		if err := crypto.OpenVolume(volumeID, dataEngine, devicePath, passphrase); err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
@@ -558,7 +940,8 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 		return &csi.NodeStageVolumeResponse{}, nil
 	}
 
-	options := volumeCapability.GetMount().GetMountFlags()
+	// The SELinux context of the pod is applied when the volume is published
+	_, options := splitSELinuxMountOptions(volumeCapability.GetMount().GetMountFlags())
 	fsType := volumeCapability.GetMount().GetFsType()
 	if fsType == "" {
 		fsType = defaultFsType
@@ -568,13 +951,21 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 		// Force ignore this uuid to be able to mount volume + its clone / restored snapshot on the same node.
 		options = append(options, "nouuid")
 	}
+	xfsProjectQuotaPercentage := 0.0
+	if fsType == "xfs" {
+		xfsProjectQuotaPercentage = getXFSProjectQuotaPercentage(req.VolumeContext)
+	}
+	if xfsProjectQuotaPercentage > 0 {
+		options = append(options, "prjquota")
+	}
 
 	formatMounter, ok := mounter.(*mount.SafeFormatAndMount)
 	if !ok {
 		return nil, status.Errorf(codes.Internal, "volume %v cannot get format mounter that support filesystem %v creation", volumeID, fsType)
 	}
 
-	if err := ns.nodeStageMountVolume(volumeID, devicePath, stagingTargetPath, fsType, options, formatMounter); err != nil {
+	checkFilesystem := isFilesystemCheckAfterUncleanDetachEnabled(req.VolumeContext)
+	if err := ns.nodeStageMountVolume(volumeID, devicePath, stagingTargetPath, fsType, options, formatMounter, forceUnmountTimeout, checkFilesystem); err != nil {
 		return nil, err
 	}
 
@@ -584,31 +975,36 @@ func (ns *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 	// some refs below for more details
 	// https://github.com/kubernetes/kubernetes/issues/94929
 	// https://github.com/kubernetes-sigs/aws-ebs-csi-driver/pull/753
-	resizer := mount.NewResizeFs(utilexec.New())
-	if needsResize, err := resizer.NeedResize(devicePath, stagingTargetPath); err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
-	} else if needsResize {
-		if resized, err := resizer.Resize(devicePath, stagingTargetPath); err != nil {
-			log.WithError(err).Errorf("Mounted volume %v on node %v failed required filesystem resize", volumeID, ns.nodeID)
+	if err := ns.resizeStagedFilesystem(volumeID, devicePath, stagingTargetPath, fsType, options, formatMounter, forceUnmountTimeout); err != nil {
+		return nil, err
+	}
+
+	if xfsProjectQuotaPercentage > 0 {
+		if err := ns.applyXFSProjectQuota(volume, stagingTargetPath, xfsProjectQuotaPercentage); err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
-		} else if resized {
-			log.Infof("Mounted volume %v on node %v successfully resized filesystem after mount", volumeID, ns.nodeID)
-		} else {
-			log.Infof("Mounted volume %v on node %v already has correct filesystem size", volumeID, ns.nodeID)
 		}
-	} else {
-		log.Infof("Mounted volume %v on node %v does not require filesystem resize", volumeID, ns.nodeID)
+	}
+
+	if err := ns.applyVolumeMountGroup(volume, volumeCapability, stagingTargetPath, false); err != nil {
+		return nil, err
 	}
 
 	log.Infof("Mounted volume %v on node %v via device %v", volumeID, ns.nodeID, devicePath)
 	return &csi.NodeStageVolumeResponse{}, nil
 }
 
-func (ns *NodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstageVolumeRequest) (*csi.NodeUnstageVolumeResponse, error) {
+func (ns *NodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstageVolumeRequest) (resp *csi.NodeUnstageVolumeResponse, err error) {
 	log := ns.log.WithFields(logrus.Fields{"function": "NodeUnstageVolume"})
 
 	log.Infof("NodeUnstageVolume is called with req %+v", req)
 
+	defer func(startTime time.Time) {
+		ns.recordMountJournal(req.GetVolumeId(), journal.OperationUnmount, req.GetStagingTargetPath(), nil, startTime, err)
+		if err == nil {
+			ns.mountHealer.untrackStage(req.GetStagingTargetPath())
+		}
+	}(time.Now())
+
 	stagingTargetPath := req.GetStagingTargetPath()
 	if stagingTargetPath == "" {
 		return nil, status.Error(codes.InvalidArgument, "staging target path missing in request")
@@ -621,8 +1017,24 @@ func (ns *NodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstag
 
 	mounter := mount.New("")
 
+	// optionally try to retrieve the volume and check if it's an RWX volume
+	// if it is we let the share-manager clean up the crypto device
+	volume, _ := ns.apiClient.WithContext(ctx).Volume.ById(volumeID)
+	sharedAccess := requiresSharedAccess(volume, nil)
+	forceUnmountTimeout := ns.getForceUnmountTimeout(volume)
+
+	// The staging path of a shared volume is an NFS mount of the share manager, which trims the filesystem itself
+	if volume != nil && volume.TrimFilesystemOnUnstage && (!sharedAccess || volume.Migratable) {
+		if isMnt, err := mounter.IsMountPoint(stagingTargetPath); err == nil && isMnt {
+			log.Infof("Trimming filesystem of volume %v mounted at %v before unstaging", volumeID, stagingTargetPath)
+			if err := trimFilesystem(stagingTargetPath, unstageTrimTimeout); err != nil {
+				log.WithError(err).Warnf("Failed to trim filesystem of volume %v before unstaging", volumeID)
+			}
+		}
+	}
+
 	// CO owns the staging_path so we only unmount but not remove the path
-	if err := unmount(stagingTargetPath, mounter); err != nil {
+	if err := unmount(stagingTargetPath, mounter, forceUnmountTimeout); err != nil {
 		return nil, status.Error(codes.Internal, errors.Wrapf(err, "failed to unmount volume %s mount point %v", volumeID, stagingTargetPath).Error())
 	}
 
@@ -632,18 +1044,28 @@ func (ns *NodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstag
 	//
 	// The unmount of the parent is a no op for block mode, this is also important for backwards compatibility of the existing block devices.
 	deviceFilePath := getStageBlockVolumePath(stagingTargetPath, volumeID)
-	if err := unmountAndCleanupMountPoint(deviceFilePath, mounter); err != nil {
+	if err := unmountAndCleanupMountPoint(deviceFilePath, mounter, forceUnmountTimeout); err != nil {
 		return nil, status.Error(codes.Internal, errors.Wrapf(err, "failed to clean up volume %s device mount point %v", volumeID, deviceFilePath).Error())
 	}
 
-	// optionally try to retrieve the volume and check if it's an RWX volume
-	// if it is we let the share-manager clean up the crypto device
-	volume, _ := ns.apiClient.Volume.ById(volumeID)
 	dataEngine := string(longhorn.DataEngineTypeV1)
 	if volume != nil {
 		dataEngine = volume.DataEngine
 	}
-	sharedAccess := requiresSharedAccess(volume, nil)
+	if volume != nil && len(volume.Controllers) > 0 && isNvmfEndpoint(volume.Controllers[0].Endpoint) {
+		log.Infof("Disconnecting the NVMe-oF endpoint %v of volume %v", volume.Controllers[0].Endpoint, volumeID)
+		if err := disconnectNvmfTarget(volume.Controllers[0].Endpoint); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	if isRWXBlockVolume(volume) {
+		log.Infof("Logging out of the iSCSI target of volume %v", volumeID)
+		if err := logoutISCSITarget(volumeID); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
 	cleanupCryptoDevice := !sharedAccess || (sharedAccess && volume.Migratable)
 	if cleanupCryptoDevice {
 		cryptoDevice := crypto.VolumeMapper(volumeID, dataEngine)
@@ -672,7 +1094,7 @@ func (ns *NodeServer) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVo
 		return nil, status.Error(codes.InvalidArgument, "volume id missing in request")
 	}
 
-	existVol, err := ns.apiClient.Volume.ById(volumeID)
+	existVol, err := ns.apiClient.WithContext(ctx).Volume.ById(volumeID)
 	if err != nil {
 		return nil, status.Error(codes.Internal, errors.Wrapf(err, "failed to get volume %s for volume statistics", volumeID).Error())
 	}
@@ -691,17 +1113,23 @@ func (ns *NodeServer) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVo
 	}
 
 	if isBlockVolume {
-		volCapacity, err := strconv.ParseInt(existVol.Size, 10, 64)
+		totalBytes, err := getBlockDeviceSize(volumePath)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to convert volume size %v for volume %v: %v", existVol.Size, volumeID, err)
+			return nil, status.Errorf(codes.Internal, "failed to retrieve capacity of block volume path %v for volume %v: %v", volumePath, volumeID, err)
+		}
+		usage := &csi.VolumeUsage{
+			Total: totalBytes,
+			Unit:  csi.VolumeUsage_BYTES,
+		}
+		// There is no filesystem to tell the used bytes of a block volume, the actual size of the volume data is used instead
+		if usedBytes, err := getVolumeActualSize(existVol); err != nil {
+			ns.log.WithError(err).Warnf("Failed to get used bytes of block volume %v", volumeID)
+		} else {
+			usage.Used = usedBytes
+			usage.Available = max(totalBytes-usedBytes, 0)
 		}
 		return &csi.NodeGetVolumeStatsResponse{
-			Usage: []*csi.VolumeUsage{
-				&csi.VolumeUsage{
-					Total: volCapacity,
-					Unit:  csi.VolumeUsage_BYTES,
-				},
-			},
+			Usage: []*csi.VolumeUsage{usage},
 		}, nil
 	}
 
@@ -733,51 +1161,100 @@ func (ns *NodeServer) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVo
 	}, nil
 }
 
-// NodeExpandShared Volume is designed to expand the file system in an RWX volume for ONLINE expansion.
-// It does so with a gRPC call into the share-manager pod.
-func (ns *NodeServer) NodeExpandSharedVolume(volumeName string) error {
-	log := ns.log.WithFields(logrus.Fields{"function": "NodeExpandSharedVolume"})
-
+// NodeExpandSharedVolume waits for the share manager to resize the filesystem of an RWX volume for ONLINE
+// expansion. The share manager controller resizes the filesystem once the engine is expanded, so the resize is done
+// once rather than by every node with a workload pod, and the NFS clients see the new size when it's completed.
+func (ns *NodeServer) NodeExpandSharedVolume(volumeName string, requestedSize int64) error {
 	sm, err := ns.lhClient.LonghornV1beta2().ShareManagers(ns.lhNamespace).Get(context.TODO(), volumeName, metav1.GetOptions{})
 	if err != nil {
-		return errors.Wrap(err, "failed to get ShareManager CR")
+		return status.Errorf(codes.Internal, "failed to get ShareManager CR: %v", err)
 	}
 
-	podName := types.GetShareManagerPodNameFromShareManagerName(sm.Name)
-	pod, err := ns.kubeClient.CoreV1().Pods(ns.lhNamespace).Get(context.TODO(), podName, metav1.GetOptions{})
+	if sm.Status.State != longhorn.ShareManagerStateRunning || sm.Status.FilesystemSize < requestedSize {
+		return status.Errorf(codes.Unavailable, "waiting for share manager %v to resize the filesystem of shared volume %v to size %v",
+			sm.Name, volumeName, requestedSize)
+	}
+	return nil
+}
+
+// getNodeStageSecrets returns the node stage secret referenced by the PV of the volume, which holds the passphrase
+// of an encrypted volume. Returns nil if the volume has no PV or the PV doesn't reference a node stage secret.
+func (ns *NodeServer) getNodeStageSecrets(volume *longhornclient.Volume) (map[string]string, error) {
+	pvName := volume.KubernetesStatus.PvName
+	if pvName == "" {
+		return nil, nil
+	}
+
+	pv, err := ns.kubeClient.CoreV1().PersistentVolumes().Get(context.TODO(), pvName, metav1.GetOptions{})
 	if err != nil {
-		return errors.Wrap(err, "failed to get ShareManager pod")
+		return nil, errors.Wrapf(err, "failed to get PV %v", pvName)
+	}
+	if pv.Spec.CSI == nil || pv.Spec.CSI.NodeStageSecretRef == nil {
+		return nil, nil
 	}
 
-	client, err := engineapi.NewShareManagerClient(sm, pod)
+	secretRef := pv.Spec.CSI.NodeStageSecretRef
+	secret, err := ns.kubeClient.CoreV1().Secrets(secretRef.Namespace).Get(context.TODO(), secretRef.Name, metav1.GetOptions{})
 	if err != nil {
-		return errors.Wrapf(err, "failed to launch gRPC client for share manager before resizing volume %v", volumeName)
+		return nil, errors.Wrapf(err, "failed to get secret %v/%v", secretRef.Namespace, secretRef.Name)
+	}
+
+	secrets := map[string]string{}
+	for key, value := range secret.Data {
+		secrets[key] = string(value)
 	}
-	defer func(client io.Closer) {
-		if closeErr := client.Close(); closeErr != nil {
-			ns.log.WithError(closeErr).Warn("Failed to close share manager client")
+	return secrets, nil
+}
+
+// getForceUnmountTimeout returns the timeout of the force unmounts of the volume. It's taken from the PVC annotation,
+// then the forceUnmountTimeout parameter of the StorageClass kept in the PV, then the timeout of the driver.
+func (ns *NodeServer) getForceUnmountTimeout(volume *longhornclient.Volume) time.Duration {
+	if volume == nil {
+		return ns.forceUnmountTimeout
+	}
+	log := ns.log.WithFields(logrus.Fields{"function": "getForceUnmountTimeout", "volume": volume.Name})
+
+	kubeStatus := volume.KubernetesStatus
+	if kubeStatus.PvcName != "" && kubeStatus.LastPVCRefAt == "" {
+		pvc, err := ns.kubeClient.CoreV1().PersistentVolumeClaims(kubeStatus.Namespace).Get(context.TODO(), kubeStatus.PvcName, metav1.GetOptions{})
+		if err != nil {
+			log.WithError(err).Warnf("Failed to get PVC %v/%v for the force unmount timeout", kubeStatus.Namespace, kubeStatus.PvcName)
+		} else if value, ok := pvc.Annotations[types.PVCAnnotationLonghornForceUnmountTimeout]; ok {
+			timeout, err := types.ParseForceUnmountTimeout(value)
+			if err == nil {
+				return timeout
+			}
+			log.WithError(err).Warnf("Ignoring invalid annotation %v of PVC %v/%v", types.PVCAnnotationLonghornForceUnmountTimeout, kubeStatus.Namespace, kubeStatus.PvcName)
 		}
-	}(client)
+	}
 
-	// Each node with a workload pod will send an RPC request.  The first will win, and the others are no-ops.
-	err = client.FilesystemResize()
-	if status.Code(err) == codes.Unimplemented {
-		// This is a downrev longhorn-share-manager image.  It will be necessary either to kill the share-manager pod
-		// and let it restart with the new image, or scale the workload down and back up to accomplish the same thing.
-		// It might be tempting to delete the pod here, but there will be one of these calls for each workload pod,
-		// and it would be messy to have multiple kill requests at once.  So the kill will need to be done by the user.
-		log.WithError(err).Warn("Share Manager image is down-rev, does not implement RPC FilesystemResize.  Share Manager pod must be restarted with current image.")
+	if kubeStatus.PvName != "" {
+		pv, err := ns.kubeClient.CoreV1().PersistentVolumes().Get(context.TODO(), kubeStatus.PvName, metav1.GetOptions{})
+		if err != nil {
+			log.WithError(err).Warnf("Failed to get PV %v for the force unmount timeout", kubeStatus.PvName)
+		} else if pv.Spec.CSI != nil && pv.Spec.CSI.VolumeAttributes["forceUnmountTimeout"] != "" {
+			value := pv.Spec.CSI.VolumeAttributes["forceUnmountTimeout"]
+			timeout, err := types.ParseForceUnmountTimeout(value)
+			if err == nil {
+				return timeout
+			}
+			log.WithError(err).Warnf("Ignoring invalid parameter forceUnmountTimeout of PV %v", kubeStatus.PvName)
+		}
 	}
 
-	return errors.Wrapf(err, "failed to expand shared volume %v", volumeName)
+	return ns.forceUnmountTimeout
 }
 
 // NodeExpandVolume is designed to expand the file system for ONLINE expansion,
-func (ns *NodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandVolumeRequest) (*csi.NodeExpandVolumeResponse, error) {
+func (ns *NodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandVolumeRequest) (resp *csi.NodeExpandVolumeResponse, err error) {
 	log := ns.log.WithFields(logrus.Fields{"function": "NodeExpandVolume"})
 
 	log.Infof("NodeExpandVolume is called with req %+v", req)
 
+	defer func(startTime time.Time) {
+		ns.recordMountJournal(req.GetVolumeId(), journal.OperationExpand, req.GetVolumePath(), []string{fmt.Sprintf("size=%v", req.GetCapacityRange().GetRequiredBytes())}, startTime, err)
+	}(time.Now())
+
 	if req.CapacityRange == nil {
 		return nil, status.Error(codes.InvalidArgument, "capacity range missing in request")
 	}
@@ -798,7 +1275,7 @@ func (ns *NodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandV
 		return &csi.NodeExpandVolumeResponse{}, nil
 	}
 
-	volume, err := ns.apiClient.Volume.ById(volumeID)
+	volume, err := ns.apiClient.WithContext(ctx).Volume.ById(volumeID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "%v", err)
 	}
@@ -817,7 +1294,7 @@ func (ns *NodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandV
 			return nil, status.Errorf(codes.FailedPrecondition, "volume %s requires shared access but is not marked for shared use", volumeID)
 		}
 
-		if err := ns.NodeExpandSharedVolume(volumeID); err != nil {
+		if err := ns.NodeExpandSharedVolume(volumeID, requestedSize); err != nil {
 			log.WithError(err).Errorf("failed to expand shared volume %v", volumeID)
 			return nil, err
 		}
@@ -826,6 +1303,12 @@ func (ns *NodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandV
 	}
 
 	devicePath := volume.Controllers[0].Endpoint
+	if isNvmfEndpoint(devicePath) {
+		// the target is already connected while the volume is staged, so this only resolves the device
+		if devicePath, err = connectNvmfTarget(devicePath); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
 
 	mounter := &mount.SafeFormatAndMount{Interface: mount.New(""), Exec: utilexec.New()}
 	diskFormat, err := mounter.GetDiskFormat(devicePath)
@@ -851,7 +1334,14 @@ func (ns *NodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandV
 		// https://kubernetes.io/blog/2022/09/21/kubernetes-1-25-use-secrets-while-expanding-csi-volumes-on-node-alpha/
 		secrets := req.GetSecrets()
 		if len(secrets) == 0 {
-			log.Infof("Skip encrypto device resizing for volume %v node expansion since the secret empty, maybe the related feature gate is not enabled", volumeID)
+			log.Infof("Node expansion secret of volume %v is empty, maybe the related feature gate is not enabled, falling back to the node stage secret", volumeID)
+			secrets, err = ns.getNodeStageSecrets(volume)
+			if err != nil {
+				return "", status.Errorf(codes.Internal, "failed to get node stage secret for encrypted volume %v node expansion: %v", volumeID, err)
+			}
+		}
+		if len(secrets) == 0 {
+			log.Infof("Skip encrypto device resizing for volume %v node expansion since the secret empty", volumeID)
 			return devicePath, nil
 		}
 		keyProvider := secrets[types.CryptoKeyProvider]
@@ -889,16 +1379,96 @@ func (ns *NodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandV
 		log.Infof("Volume %v on node %v does not require filesystem resize", volumeID, ns.nodeID)
 	}
 
+	if diskFormat == "xfs" && isXFSProjectQuotaMounted(req.StagingTargetPath) {
+		if percentage := ns.getXFSProjectQuotaPercentage(volume); percentage > 0 {
+			if err := setXFSProjectQuota(req.StagingTargetPath, volumeID, requestedSize, percentage); err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			log.Infof("Volume %v on node %v updated xfs project quota to %v%% of %v bytes", volumeID, ns.nodeID, percentage, requestedSize)
+		}
+	}
+
 	return &csi.NodeExpandVolumeResponse{CapacityBytes: requestedSize}, nil
 }
 
+// applyXFSProjectQuota limits the XFS filesystem staged at stagingTargetPath to the percentage of the volume size
+func (ns *NodeServer) applyXFSProjectQuota(volume *longhornclient.Volume, stagingTargetPath string, percentage float64) error {
+	size, err := strconv.ParseInt(volume.Size, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse size %v of volume %v", volume.Size, volume.Name)
+	}
+
+	if err := setXFSProjectQuota(stagingTargetPath, volume.Name, size, percentage); err != nil {
+		return err
+	}
+
+	ns.log.Infof("Volume %v on node %v enforced xfs project quota of %v%% of %v bytes", volume.Name, ns.nodeID, percentage, size)
+	return nil
+}
+
+// getXFSProjectQuotaPercentage returns the xfsProjectQuotaPercentage parameter of the StorageClass kept in the PV of
+// the volume, since the volume context is not passed to the node expansion. It returns 0 if the volume has no PV or
+// the parameter is not set.
+func (ns *NodeServer) getXFSProjectQuotaPercentage(volume *longhornclient.Volume) float64 {
+	pvName := volume.KubernetesStatus.PvName
+	if pvName == "" {
+		return 0
+	}
+
+	pv, err := ns.kubeClient.CoreV1().PersistentVolumes().Get(context.TODO(), pvName, metav1.GetOptions{})
+	if err != nil {
+		ns.log.WithError(err).Warnf("Failed to get PV %v for the xfs project quota of volume %v", pvName, volume.Name)
+		return 0
+	}
+	if pv.Spec.CSI == nil {
+		return 0
+	}
+	return getXFSProjectQuotaPercentage(pv.Spec.CSI.VolumeAttributes)
+}
+
+// recordMountJournal records a finished node operation in the mount journal of the node
+func (ns *NodeServer) recordMountJournal(volumeID string, operation journal.Operation, path string, options []string, startTime time.Time, err error) {
+	record := journal.NewRecord(ns.nodeID, volumeID, operation, path, options, startTime, err)
+	if err := journal.Append(record); err != nil {
+		ns.log.WithError(err).Warnf("Failed to record %v of volume %v in the mount journal", operation, volumeID)
+	}
+}
+
 func (ns *NodeServer) NodeGetInfo(ctx context.Context, req *csi.NodeGetInfoRequest) (*csi.NodeGetInfoResponse, error) {
+	maxVolumesPerNode, err := ns.getMaxVolumesPerNode(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
 	return &csi.NodeGetInfoResponse{
 		NodeId:            ns.nodeID,
-		MaxVolumesPerNode: 0, // technically the scsi kernel limit is the max limit of volumes
+		MaxVolumesPerNode: maxVolumesPerNode,
 	}, nil
 }
 
+// getMaxVolumesPerNode returns the limit of the volumes on the node from the annotation of the Kubernetes node,
+// or from the setting csi-max-volumes-per-node. 0 means no limit, technically the scsi kernel limit is the max limit
+// of volumes.
+func (ns *NodeServer) getMaxVolumesPerNode(ctx context.Context) (int64, error) {
+	kubeNode, err := ns.kubeClient.CoreV1().Nodes().Get(ctx, ns.nodeID, metav1.GetOptions{})
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get Kubernetes node %v", ns.nodeID)
+	}
+	if value, ok := kubeNode.Annotations[types.KubeNodeCSIMaxVolumesPerNodeAnnotationKey]; ok {
+		maxVolumesPerNode, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || maxVolumesPerNode < 0 {
+			return 0, fmt.Errorf("invalid value %v of annotation %v on node %v", value, types.KubeNodeCSIMaxVolumesPerNodeAnnotationKey, ns.nodeID)
+		}
+		return maxVolumesPerNode, nil
+	}
+
+	setting, err := ns.apiClient.WithContext(ctx).Setting.ById(string(types.SettingNameCSIMaxVolumesPerNode))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get setting %v", types.SettingNameCSIMaxVolumesPerNode)
+	}
+	return strconv.ParseInt(setting.Value, 10, 64)
+}
+
 func (ns *NodeServer) NodeGetCapabilities(ctx context.Context, req *csi.NodeGetCapabilitiesRequest) (*csi.NodeGetCapabilitiesResponse, error) {
 	return &csi.NodeGetCapabilitiesResponse{
 		Capabilities: ns.caps,
@@ -979,6 +1549,12 @@ func (ns *NodeServer) getMounter(volume *longhornclient.Volume, volumeCapability
 			params += fsParams.formatParameters
 		}
 
+		// Override the mke2fs default of reserving 5% of the blocks for the super-user,
+		// which wastes significant space on large volumes.
+		if reservedBlocksPercentage, ok := volumeContext["ext4ReservedBlocksPercentage"]; ok && reservedBlocksPercentage != "" && fsType == "ext4" {
+			params += " -m " + reservedBlocksPercentage
+		}
+
 		//If the user specifies parameters in the storage class, the parameters are appended after the default value.
 		if mkfsParams, ok := volumeContext["mkfsParams"]; ok && mkfsParams != "" {
 			params += " " + mkfsParams
@@ -1008,7 +1584,7 @@ func (ns *NodeServer) getMounter(volume *longhornclient.Volume, volumeCapability
 // effects are neither harmful nor helpful, as ensureMountPoint will be called again in the restage flow.
 func restageRequired(volume *longhornclient.Volume,
 	volumeID, stagingTargetPath string,
-	mounter mount.Interface,
+	mounter mount.Interface, forceUnmountTimeout time.Duration,
 	isBlock, isStorageNetworkConfigured bool) (bool, error) {
 
 	if volume.DataEngine == string(longhorn.DataEngineTypeV2) {
@@ -1031,6 +1607,6 @@ func restageRequired(volume *longhornclient.Volume,
 		//   not affect the original direct bind mount.
 		return !isStaged, err
 	}
-	isStaged, err := ensureMountPoint(stagingTargetPath, mounter)
+	isStaged, err := ensureMountPoint(stagingTargetPath, mounter, forceUnmountTimeout)
 	return !isStaged, err
 }
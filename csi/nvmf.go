@@ -0,0 +1,232 @@
+package csi
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	lhns "github.com/longhorn/go-common-libs/ns"
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const (
+	nvmeBinary = "nvme"
+
+	nvmeClassDirectory = "/sys/class/nvme"
+
+	nvmfEndpointScheme = "nvmf"
+
+	nvmeNamespaceWaitInterval = time.Second
+	nvmeNamespaceWaitTimeout  = 30 * time.Second
+)
+
+// nvmeNamespaceRegex matches the namespaces of a controller in sysfs. A namespace is named nvme<subsystem>c<controller>n<id>
+// when the native NVMe multipath is enabled, and its block device is then nvme<subsystem>n<id>.
+var nvmeNamespaceRegex = regexp.MustCompile(`^nvme(\d+)(c\d+)?n(\d+)$`)
+
+type nvmfTarget struct {
+	ip   string
+	port string
+	nqn  string
+}
+
+// parseNvmfEndpoint parses an engine endpoint like nvmf://10.42.0.12:20001/nqn.2023-01.io.longhorn.spdk:vol-name-e-0
+func parseNvmfEndpoint(endpoint string) (*nvmfTarget, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != nvmfEndpointScheme {
+		return nil, fmt.Errorf("invalid NVMe-oF endpoint %v", endpoint)
+	}
+
+	target := &nvmfTarget{
+		ip:   u.Hostname(),
+		port: u.Port(),
+		nqn:  strings.TrimPrefix(u.Path, "/"),
+	}
+	if target.ip == "" || target.port == "" || target.nqn == "" {
+		return nil, fmt.Errorf("invalid NVMe-oF endpoint %v", endpoint)
+	}
+	return target, nil
+}
+
+type nvmeController struct {
+	name    string
+	nqn     string
+	address string
+}
+
+// matches returns true if the controller is connected to the target
+func (c *nvmeController) matches(target *nvmfTarget) bool {
+	if c.nqn != target.nqn {
+		return false
+	}
+	fields := map[string]string{}
+	for _, field := range strings.Split(c.address, ",") {
+		if key, value, found := strings.Cut(strings.TrimSpace(field), "="); found {
+			fields[key] = value
+		}
+	}
+	return fields["traddr"] == target.ip && fields["trsvcid"] == target.port
+}
+
+func newNvmeNamespaceExecutor() (*lhns.Executor, error) {
+	namespaces := []lhtypes.Namespace{lhtypes.NamespaceMnt, lhtypes.NamespaceNet}
+	return lhns.NewNamespaceExecutor(lhtypes.ProcessNone, lhtypes.HostProcDirectory, namespaces)
+}
+
+// listNvmeControllers returns the NVMe controllers of the host connected to the subsystem nqn
+func listNvmeControllers(nqn string) ([]*nvmeController, error) {
+	entries, err := lhns.ReadDirectory(nvmeClassDirectory)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %v", nvmeClassDirectory)
+	}
+
+	controllers := []*nvmeController{}
+	for _, entry := range entries {
+		controllerPath := filepath.Join(nvmeClassDirectory, entry.Name())
+		subsysNQN, err := lhns.ReadFileContent(filepath.Join(controllerPath, "subsysnqn"))
+		if err != nil {
+			// the controller may be removed in the meantime
+			continue
+		}
+		if strings.TrimSpace(subsysNQN) != nqn {
+			continue
+		}
+		address, err := lhns.ReadFileContent(filepath.Join(controllerPath, "address"))
+		if err != nil {
+			continue
+		}
+		controllers = append(controllers, &nvmeController{
+			name:    entry.Name(),
+			nqn:     nqn,
+			address: strings.TrimSpace(address),
+		})
+	}
+	return controllers, nil
+}
+
+// getNvmeControllerDevice returns the block device of the first namespace of the controller
+func getNvmeControllerDevice(controller string) (string, error) {
+	entries, err := lhns.ReadDirectory(filepath.Join(nvmeClassDirectory, controller))
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		matches := nvmeNamespaceRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		return filepath.Join("/dev", fmt.Sprintf("nvme%sn%s", matches[1], matches[3])), nil
+	}
+	return "", fmt.Errorf("no namespace found for NVMe controller %v", controller)
+}
+
+// connectNvmfTarget connects the node to the NVMe-oF target of the engine endpoint over TCP and returns the block
+// device of its namespace. The controllers connected to the same subsystem at other addresses, e.g. left behind by
+// an engine previously running on another node, are disconnected first. Otherwise the native NVMe multipath would
+// aggregate them as the paths of the same device.
+func connectNvmfTarget(endpoint string) (string, error) {
+	log := logrus.WithFields(logrus.Fields{"function": "connectNvmfTarget", "endpoint": endpoint})
+
+	target, err := parseNvmfEndpoint(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	nsexec, err := newNvmeNamespaceExecutor()
+	if err != nil {
+		return "", err
+	}
+
+	controllers, err := listNvmeControllers(target.nqn)
+	if err != nil {
+		return "", err
+	}
+
+	connectedController := ""
+	for _, controller := range controllers {
+		if controller.matches(target) {
+			connectedController = controller.name
+			continue
+		}
+		log.Warnf("Disconnecting NVMe controller %v connected to subsystem %v at stale address %v", controller.name, target.nqn, controller.address)
+		if _, err := nsexec.Execute(nil, nvmeBinary, []string{"disconnect", "-d", controller.name}, lhtypes.ExecuteDefaultTimeout); err != nil {
+			return "", errors.Wrapf(err, "failed to disconnect NVMe controller %v", controller.name)
+		}
+	}
+
+	if connectedController == "" {
+		log.Infof("Connecting to NVMe-oF subsystem %v at %v:%v", target.nqn, target.ip, target.port)
+		args := []string{"connect", "-t", "tcp", "-a", target.ip, "-s", target.port, "-n", target.nqn}
+		if _, err := nsexec.Execute(nil, nvmeBinary, args, lhtypes.ExecuteDefaultTimeout); err != nil {
+			return "", errors.Wrapf(err, "failed to connect to NVMe-oF subsystem %v at %v:%v", target.nqn, target.ip, target.port)
+		}
+	}
+
+	// The namespace shows up asynchronously after the controller is connected
+	var lastErr error
+	for start := time.Now(); time.Since(start) < nvmeNamespaceWaitTimeout; time.Sleep(nvmeNamespaceWaitInterval) {
+		controllers, err := listNvmeControllers(target.nqn)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, controller := range controllers {
+			if !controller.matches(target) {
+				continue
+			}
+			devicePath, err := getNvmeControllerDevice(controller.name)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if _, err := lhns.GetFileInfo(devicePath); err != nil {
+				lastErr = err
+				continue
+			}
+			return devicePath, nil
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no NVMe controller connected")
+	}
+	return "", errors.Wrapf(lastErr, "timed out waiting for the namespace of NVMe-oF subsystem %v", target.nqn)
+}
+
+// disconnectNvmfTarget disconnects all the NVMe controllers of the node connected to the subsystem of the endpoint
+func disconnectNvmfTarget(endpoint string) error {
+	target, err := parseNvmfEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+
+	controllers, err := listNvmeControllers(target.nqn)
+	if err != nil {
+		return err
+	}
+	if len(controllers) == 0 {
+		return nil
+	}
+
+	nsexec, err := newNvmeNamespaceExecutor()
+	if err != nil {
+		return err
+	}
+	if _, err := nsexec.Execute(nil, nvmeBinary, []string{"disconnect", "-n", target.nqn}, lhtypes.ExecuteDefaultTimeout); err != nil {
+		return errors.Wrapf(err, "failed to disconnect NVMe-oF subsystem %v", target.nqn)
+	}
+	return nil
+}
+
+// isNvmfEndpoint returns true if the endpoint is an NVMe-oF target the node has to connect to
+func isNvmfEndpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, nvmfEndpointScheme+"://")
+}
@@ -0,0 +1,52 @@
+package csi
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// unlimitedMethods are the calls which don't reach the manager, so they are not rate limited
+var unlimitedMethods = map[string]struct{}{
+	"GetPluginInfo":             {},
+	"GetPluginCapabilities":     {},
+	"Probe":                     {},
+	"ControllerGetCapabilities": {},
+	"NodeGetCapabilities":       {},
+}
+
+// rateLimiter limits the rate of the CSI calls, so a burst of calls from the sidecars doesn't overload the manager.
+// The calls over the limit wait for their turn until their deadline.
+type rateLimiter struct {
+	limiter *rate.Limiter
+}
+
+// newRateLimiter returns nil if the limit is not positive, which means the calls are not rate limited.
+// The burst defaults to 1 if it's not positive.
+func newRateLimiter(limit float64, burst int) *rateLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return &rateLimiter{
+		limiter: rate.NewLimiter(rate.Limit(limit), burst),
+	}
+}
+
+func (l *rateLimiter) limitGRPC(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+	if _, ok := unlimitedMethods[method]; ok {
+		return handler(ctx, req)
+	}
+
+	if err := l.limiter.Wait(ctx); err != nil {
+		return nil, status.Errorf(codes.ResourceExhausted, "%s: rate limit exceeded: %v", method, err)
+	}
+	return handler(ctx, req)
+}
@@ -26,20 +26,34 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/kubernetes-csi/csi-lib-utils/protosanitizer"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 )
 
-func NewNonBlockingGRPCServer() *NonBlockingGRPCServer {
-	return &NonBlockingGRPCServer{}
+// NewNonBlockingGRPCServer creates a server which rate limits the calls to rateLimit per second with bursts of
+// rateLimitBurst calls. The calls are not rate limited if rateLimit is not positive. The calls running longer than
+// watchdogThreshold are reported as stuck, and the stuck unmounts are force unmounted if watchdogForceUnmount is set.
+// The calls are not watched if watchdogThreshold is not positive.
+func NewNonBlockingGRPCServer(rateLimit float64, rateLimitBurst int, watchdogThreshold time.Duration, watchdogForceUnmount bool) *NonBlockingGRPCServer {
+	return &NonBlockingGRPCServer{
+		inFlight:    newInFlight(),
+		rateLimiter: newRateLimiter(rateLimit, rateLimitBurst),
+		watchdog:    newWatchdog(watchdogThreshold, watchdogForceUnmount),
+	}
 }
 
 type NonBlockingGRPCServer struct {
 	wg     sync.WaitGroup
 	server *grpc.Server
+
+	inFlight    *inFlight
+	rateLimiter *rateLimiter
+	watchdog    *watchdog
 }
 
 func (s *NonBlockingGRPCServer) Start(endpoint string, ids csi.IdentityServer, cs csi.ControllerServer, ns csi.NodeServer) {
@@ -81,8 +95,16 @@ func (s *NonBlockingGRPCServer) serve(endpoint string, ids csi.IdentityServer, c
 		logrus.Fatalf("Failed to listen: %v", err)
 	}
 
+	interceptors := []grpc.UnaryServerInterceptor{traceGRPC, logGRPC, s.inFlight.serializeGRPC}
+	if s.rateLimiter != nil {
+		interceptors = append(interceptors, s.rateLimiter.limitGRPC)
+	}
+	if s.watchdog != nil {
+		interceptors = append(interceptors, s.watchdog.watchGRPC)
+	}
 	opts := []grpc.ServerOption{
-		grpc.UnaryInterceptor(logGRPC),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(interceptors...),
 	}
 	server := grpc.NewServer(opts...)
 	s.server = server
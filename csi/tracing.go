@@ -0,0 +1,90 @@
+package csi
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	tracingServiceName = "longhorn-csi-plugin"
+
+	// The standard OTLP exporter environment variables, tracing is enabled when either endpoint is set
+	otlpEndpointEnv       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	otlpTracesEndpointEnv = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+
+	traceAttributeVolumeID   = attribute.Key("longhorn.volume.id")
+	traceAttributeSnapshotID = attribute.Key("longhorn.snapshot.id")
+	traceAttributeNodeID     = attribute.Key("longhorn.node.id")
+	traceAttributeOperation  = attribute.Key("csi.operation")
+)
+
+// initTracing exports the spans of the CSI plugin over OTLP/gRPC, configured by the standard OTEL_EXPORTER_OTLP_*
+// environment variables. The trace context is propagated with the W3C Trace Context and Baggage headers.
+// The returned function flushes the pending spans and stops the exporter.
+func initTracing(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if os.Getenv(otlpEndpointEnv) == "" && os.Getenv(otlpTracesEndpointEnv) == "" {
+		logrus.Info("Tracing is disabled since no OTLP endpoint is configured")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create OTLP trace exporter")
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(tracingServiceName))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+
+	logrus.Info("Tracing is enabled")
+	return tracerProvider.Shutdown, nil
+}
+
+// newTracingTransport returns the transport of the Longhorn API client, which traces the requests and propagates
+// the trace context of the requests to the manager.
+func newTracingTransport() http.RoundTripper {
+	return otelhttp.NewTransport(http.DefaultTransport)
+}
+
+// traceGRPC annotates the span of the gRPC call created by the stats handler with the CSI operation and the
+// Longhorn objects it's about.
+func traceGRPC(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.SetAttributes(traceAttributeOperation.String(info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]))
+		if r, ok := req.(interface{ GetVolumeId() string }); ok && r.GetVolumeId() != "" {
+			span.SetAttributes(traceAttributeVolumeID.String(r.GetVolumeId()))
+		}
+		if r, ok := req.(interface{ GetSnapshotId() string }); ok && r.GetSnapshotId() != "" {
+			span.SetAttributes(traceAttributeSnapshotID.String(r.GetSnapshotId()))
+		}
+		if r, ok := req.(interface{ GetNodeId() string }); ok && r.GetNodeId() != "" {
+			span.SetAttributes(traceAttributeNodeID.String(r.GetNodeId()))
+		}
+		if r, ok := req.(*csi.CreateVolumeRequest); ok {
+			span.SetAttributes(traceAttributeVolumeID.String(r.GetName()))
+		}
+	}
+	return handler(ctx, req)
+}
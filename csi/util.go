@@ -4,23 +4,35 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"math"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
+	"unsafe"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sys/unix"
 
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/mount-utils"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilexec "k8s.io/utils/exec"
 
+	"github.com/longhorn/go-iscsi-helper/iscsi"
+
+	lhns "github.com/longhorn/go-common-libs/ns"
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
 	"github.com/longhorn/longhorn-manager/types"
 
 	longhornclient "github.com/longhorn/longhorn-manager/client"
@@ -28,15 +40,35 @@ import (
 )
 
 const (
-	// defaultStaleReplicaTimeout set to 48 hours (2880 minutes)
-	defaultStaleReplicaTimeout                         = 2880
-	defaultStorageClassDisableRevisionCounterParameter = true
-
-	defaultForceUmountTimeout = 30 * time.Second
+	// DefaultForceUnmountTimeout is the timeout of the force unmounts of the volumes without their own timeout
+	DefaultForceUnmountTimeout = 30 * time.Second
 
 	tempTestMountPointValidStatusFile = ".longhorn-volume-mount-point-test.tmp"
+
+	// iscsiTargetPrefix is the prefix of the iSCSI target name the engine exports a volume with
+	iscsiTargetPrefix = "iqn.2019-10.io.longhorn:"
+
+	// xfsQuotaLimitUnit is the unit of the XFS project quota hard limit, which the limit is rounded up to
+	xfsQuotaLimitUnit = 1024
+
+	// unstageTrimTimeout bounds the filesystem trim before unstaging, so a slow trim cannot block the pod from
+	// moving to another node
+	unstageTrimTimeout = 2 * time.Minute
+
+	// restoreServiceAccountKey is the key of the provisioner secret naming the service account in the PVC namespace,
+	// whose token is requested for the AWS STS audience and used as the web identity token of the IAM role
+	restoreServiceAccountKey = "serviceAccountName"
+	// awsSTSAudience is the audience of the service account token exchanged for the credential of an AWS IAM role
+	awsSTSAudience = "sts.amazonaws.com"
+	// restoreServiceAccountTokenExpiration is the requested lifetime of the service account token, which is long
+	// enough for the restoration to exchange it for the credential of the IAM role again while the volume is restored
+	restoreServiceAccountTokenExpiration = 24 * time.Hour
 )
 
+// seLinuxMountOptionPrefixes are the prefixes of the SELinux mount options kubelet passes to the drivers supporting
+// SELinux mounts, so the volumes are mounted with the context of the pod rather than relabeled recursively
+var seLinuxMountOptionPrefixes = []string{"context=", "fscontext=", "defcontext=", "rootcontext="}
+
 // NewForcedParamsExec creates a osExecutor that allows for adding additional params to later occurring Run calls
 func NewForcedParamsExec(cmdParamMapping map[string]string) utilexec.Interface {
 	return &forcedParamsOsExec{
@@ -58,6 +90,8 @@ type volumeFilesystemStatistics struct {
 	availableInodes int64
 	totalInodes     int64
 	usedInodes      int64
+
+	readOnly bool
 }
 
 func (e *forcedParamsOsExec) Command(cmd string, args ...string) utilexec.Cmd {
@@ -65,7 +99,7 @@ func (e *forcedParamsOsExec) Command(cmd string, args ...string) utilexec.Cmd {
 	if value := e.cmdParamMapping[cmd]; value != "" {
 		// we prepend the user params, since options are conventionally before the final args
 		// command [-option(s)] [argument(s)]
-		params = append(params, strings.Split(value, " ")...)
+		params = append(params, strings.Fields(value)...)
 	}
 	params = append(params, args...)
 	return e.exec.Command(cmd, params...)
@@ -93,170 +127,148 @@ func updateVolumeParamsForBackingImage(volumeParameters map[string]string, backi
 	volumeParameters[longhorn.BackingImageParameterDataSourceParameters] = string(backingImageParametersStr)
 }
 
-func getVolumeOptions(volumeID string, volOptions map[string]string) (*longhornclient.Volume, error) {
-	vol := &longhornclient.Volume{}
-
-	if staleReplicaTimeout, ok := volOptions["staleReplicaTimeout"]; ok {
-		srt, err := strconv.Atoi(staleReplicaTimeout)
-		if err != nil {
-			return nil, errors.Wrap(err, "invalid parameter staleReplicaTimeout")
+// isSELinuxMountOption returns true if the mount option sets the SELinux context of the mount
+func isSELinuxMountOption(option string) bool {
+	for _, prefix := range seLinuxMountOptionPrefixes {
+		if strings.HasPrefix(option, prefix) {
+			return true
 		}
-		vol.StaleReplicaTimeout = int64(srt)
 	}
-	if vol.StaleReplicaTimeout <= 0 {
-		vol.StaleReplicaTimeout = defaultStaleReplicaTimeout
-	}
-
-	if share, ok := volOptions["share"]; ok {
-		isShared, err := strconv.ParseBool(share)
-		if err != nil {
-			return nil, errors.Wrap(err, "invalid parameter share")
-		}
+	return false
+}
 
-		if isShared {
-			vol.AccessMode = string(longhorn.AccessModeReadWriteMany)
+// splitSELinuxMountOptions splits the mount flags into the SELinux mount options and the others
+func splitSELinuxMountOptions(mountFlags []string) (seLinuxOptions, otherOptions []string) {
+	for _, flag := range mountFlags {
+		if isSELinuxMountOption(flag) {
+			seLinuxOptions = append(seLinuxOptions, flag)
 		} else {
-			vol.AccessMode = string(longhorn.AccessModeReadWriteOnce)
+			otherOptions = append(otherOptions, flag)
 		}
 	}
+	return seLinuxOptions, otherOptions
+}
 
-	if migratable, ok := volOptions["migratable"]; ok {
-		isMigratable, err := strconv.ParseBool(migratable)
-		if err != nil {
-			return nil, errors.Wrap(err, "invalid parameter migratable")
-		}
-
-		if isMigratable && vol.AccessMode != string(longhorn.AccessModeReadWriteMany) {
-			logrus.Infof("Cannot mark volume %v as migratable, "+
-				"since access mode is not RWX proceeding with RWO non migratable volume creation", volumeID)
-			volOptions["migratable"] = strconv.FormatBool(false)
-			isMigratable = false
-		}
-		vol.Migratable = isMigratable
+// getPublishMountOptions returns the options of the bind mount publishing the staged filesystem of the volume. The
+// SELinux mount options are only applied to the volumes of a single pod, since the staging mount of a shared volume is
+// used by all the pods on the node, whose SELinux contexts can differ.
+func getPublishMountOptions(volumeCapability *csi.VolumeCapability, readOnly, sharedAccess bool) []string {
+	mountOptions := []string{"bind"}
+	if readOnly {
+		mountOptions = append(mountOptions, "ro")
 	}
-
-	if encrypted, ok := volOptions["encrypted"]; ok {
-		isEncrypted, err := strconv.ParseBool(encrypted)
-		if err != nil {
-			return nil, errors.Wrap(err, "invalid parameter encrypted")
-		}
-		vol.Encrypted = isEncrypted
+	seLinuxOptions, otherOptions := splitSELinuxMountOptions(volumeCapability.GetMount().GetMountFlags())
+	mountOptions = append(mountOptions, otherOptions...)
+	if !sharedAccess {
+		mountOptions = append(mountOptions, seLinuxOptions...)
 	}
+	return mountOptions
+}
 
-	if numberOfReplicas, ok := volOptions["numberOfReplicas"]; ok {
-		nor, err := strconv.Atoi(numberOfReplicas)
-		if err != nil || nor < 0 {
-			return nil, errors.Wrap(err, "invalid parameter numberOfReplicas")
-		}
-		vol.NumberOfReplicas = int64(nor)
+// getRestoreCredential returns the backup store credential for restoring a volume from backup out of the secrets
+// of the CreateVolume request. The provisioner secret can be templated with the PVC namespace, so each tenant can
+// restore from the backup store with its own credential.
+func getRestoreCredential(secrets map[string]string) (map[string]string, error) {
+	if len(secrets) == 0 {
+		return nil, nil
 	}
 
-	if replicaAutoBalance, ok := volOptions["replicaAutoBalance"]; ok {
-		err := types.ValidateReplicaAutoBalance(longhorn.ReplicaAutoBalance(replicaAutoBalance))
-		if err != nil {
-			return nil, errors.Wrap(err, "invalid parameter replicaAutoBalance")
-		}
-		vol.ReplicaAutoBalance = replicaAutoBalance
+	credential := map[string]string{}
+	for key, value := range secrets {
+		credential[key] = value
 	}
-
-	if locality, ok := volOptions["dataLocality"]; ok {
-		if err := types.ValidateDataLocality(longhorn.DataLocality(locality)); err != nil {
-			return nil, errors.Wrap(err, "invalid parameter dataLocality")
-		}
-		vol.DataLocality = locality
+	if credential[restoreServiceAccountKey] != "" && credential[types.AWSIAMRoleArn] == "" {
+		return nil, fmt.Errorf("%v is required for the token of service account %v", types.AWSIAMRoleArn, credential[restoreServiceAccountKey])
 	}
+	return credential, nil
+}
 
-	if revisionCounterDisabled, ok := volOptions["disableRevisionCounter"]; ok {
-		revCounterDisabled, err := strconv.ParseBool(revisionCounterDisabled)
-		if err != nil {
-			return nil, errors.Wrap(err, "invalid parameter disableRevisionCounter")
-		}
-		vol.RevisionCounterDisabled = revCounterDisabled
-	} else {
-		vol.RevisionCounterDisabled = defaultStorageClassDisableRevisionCounterParameter
+// requestRestoreServiceAccountToken requests the token of the service account of the restore credential in the PVC
+// namespace for the AWS STS audience, and replaces the service account with the token as the web identity token of
+// the IAM role. The provisioner doesn't pass the service account tokens to CreateVolume, so they are requested here.
+func requestRestoreServiceAccountToken(ctx context.Context, kubeClient kubernetes.Interface, namespace string, credential map[string]string) error {
+	serviceAccountName := credential[restoreServiceAccountKey]
+	if serviceAccountName == "" {
+		return nil
 	}
-
-	if unmapMarkSnapChainRemoved, ok := volOptions["unmapMarkSnapChainRemoved"]; ok {
-		if err := types.ValidateUnmapMarkSnapChainRemoved(longhorn.DataEngineType(vol.DataEngine), longhorn.UnmapMarkSnapChainRemoved(unmapMarkSnapChainRemoved)); err != nil {
-			return nil, errors.Wrap(err, "invalid parameter unmapMarkSnapChainRemoved")
-		}
-		vol.UnmapMarkSnapChainRemoved = unmapMarkSnapChainRemoved
+	if namespace == "" {
+		return fmt.Errorf("the PVC namespace is required for the token of service account %v, enable the extra create metadata of the provisioner", serviceAccountName)
 	}
 
-	if replicaSoftAntiAffinity, ok := volOptions["replicaSoftAntiAffinity"]; ok {
-		if err := types.ValidateReplicaSoftAntiAffinity(longhorn.ReplicaSoftAntiAffinity(replicaSoftAntiAffinity)); err != nil {
-			return nil, errors.Wrap(err, "invalid parameter replicaSoftAntiAffinity")
-		}
-		vol.ReplicaSoftAntiAffinity = replicaSoftAntiAffinity
+	expirationSeconds := int64(restoreServiceAccountTokenExpiration.Seconds())
+	tokenRequest, err := kubeClient.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, serviceAccountName, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         []string{awsSTSAudience},
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to request token of service account %v/%v", namespace, serviceAccountName)
 	}
 
-	if replicaZoneSoftAntiAffinity, ok := volOptions["replicaZoneSoftAntiAffinity"]; ok {
-		if err := types.ValidateReplicaZoneSoftAntiAffinity(longhorn.ReplicaZoneSoftAntiAffinity(replicaZoneSoftAntiAffinity)); err != nil {
-			return nil, errors.Wrap(err, "invalid parameter replicaZoneSoftAntiAffinity")
-		}
-		vol.ReplicaZoneSoftAntiAffinity = replicaZoneSoftAntiAffinity
-	}
+	delete(credential, restoreServiceAccountKey)
+	credential[types.AWSWebIdentityToken] = tokenRequest.Status.Token
+	return nil
+}
 
-	if replicaDiskSoftAntiAffinity, ok := volOptions["replicaDiskSoftAntiAffinity"]; ok {
-		if err := types.ValidateReplicaDiskSoftAntiAffinity(longhorn.ReplicaDiskSoftAntiAffinity(replicaDiskSoftAntiAffinity)); err != nil {
-			return nil, errors.Wrap(err, "invalid parameter replicaDiskSoftAntiAffinity")
+func getVolumeOptions(volumeID string, volOptions map[string]string) (*longhornclient.Volume, error) {
+	if isMigratable, err := strconv.ParseBool(volOptions["migratable"]); err == nil && isMigratable {
+		if isShared, _ := strconv.ParseBool(volOptions["share"]); !isShared {
+			logrus.Infof("Cannot mark volume %v as migratable, "+
+				"since access mode is not RWX proceeding with RWO non migratable volume creation", volumeID)
+			volOptions["migratable"] = strconv.FormatBool(false)
 		}
-		vol.ReplicaDiskSoftAntiAffinity = replicaDiskSoftAntiAffinity
-	}
-
-	if fromBackup, ok := volOptions["fromBackup"]; ok {
-		vol.FromBackup = fromBackup
-	}
-
-	if backupTargetName, ok := volOptions["backupTargetName"]; ok {
-		vol.BackupTargetName = backupTargetName
-	}
-
-	if dataSource, ok := volOptions["dataSource"]; ok {
-		vol.DataSource = dataSource
 	}
 
-	if backingImage, ok := volOptions[longhorn.BackingImageParameterName]; ok {
-		vol.BackingImage = backingImage
+	spec, err := types.ParseVolumeParameters(volOptions)
+	if err != nil {
+		return nil, err
 	}
 
-	recurringJobSelector := []longhornclient.VolumeRecurringJob{}
-	if jsonRecurringJobSelector, ok := volOptions["recurringJobSelector"]; ok {
-		err := json.Unmarshal([]byte(jsonRecurringJobSelector), &recurringJobSelector)
-		if err != nil {
+	vol := &longhornclient.Volume{
+		StaleReplicaTimeout:         int64(spec.StaleReplicaTimeout),
+		AccessMode:                  string(spec.AccessMode),
+		Migratable:                  spec.Migratable,
+		Encrypted:                   spec.Encrypted,
+		NumberOfReplicas:            int64(spec.NumberOfReplicas),
+		ReplicaAutoBalance:          string(spec.ReplicaAutoBalance),
+		DataLocality:                string(spec.DataLocality),
+		RevisionCounterDisabled:     spec.RevisionCounterDisabled,
+		DataEngine:                  string(spec.DataEngine),
+		UnmapMarkSnapChainRemoved:   string(spec.UnmapMarkSnapChainRemoved),
+		ReplicaSoftAntiAffinity:     string(spec.ReplicaSoftAntiAffinity),
+		ReplicaZoneSoftAntiAffinity: string(spec.ReplicaZoneSoftAntiAffinity),
+		ReplicaDiskSoftAntiAffinity: string(spec.ReplicaDiskSoftAntiAffinity),
+		FromBackup:                  spec.FromBackup,
+		BackupTargetName:            spec.BackupTargetName,
+		DataSource:                  string(spec.DataSource),
+		CloneMode:                   string(spec.CloneMode),
+		BackingImage:                spec.BackingImage,
+		DiskSelector:                spec.DiskSelector,
+		NodeSelector:                spec.NodeSelector,
+		Frontend:                    string(spec.Frontend),
+		FreezeFilesystemForSnapshot: string(spec.FreezeFilesystemForSnapshot),
+		TrimFilesystemOnUnstage:     spec.TrimFilesystemOnUnstage,
+		RebuildPriority:             int64(spec.RebuildPriority),
+		SnapshotMaxChainLength:      int64(spec.SnapshotMaxChainLength),
+
+		SnapshotRetentionKeepLast: int64(spec.SnapshotRetentionKeepLast),
+		SnapshotRetentionMaxAge:   spec.SnapshotRetentionMaxAge,
+		SnapshotRetentionMaxSize:  strconv.FormatInt(spec.SnapshotRetentionMaxSize, 10),
+	}
+
+	if jsonRecurringJobSelector := volOptions["recurringJobSelector"]; jsonRecurringJobSelector != "" {
+		recurringJobSelector := []longhornclient.VolumeRecurringJob{}
+		if err := json.Unmarshal([]byte(jsonRecurringJobSelector), &recurringJobSelector); err != nil {
 			return nil, errors.Wrap(err, "invalid json format of recurringJobSelector")
 		}
 		vol.RecurringJobSelector = recurringJobSelector
 	}
 
-	if diskSelector, ok := volOptions["diskSelector"]; ok {
-		vol.DiskSelector = strings.Split(diskSelector, ",")
-	}
-
-	if nodeSelector, ok := volOptions["nodeSelector"]; ok {
-		vol.NodeSelector = strings.Split(nodeSelector, ",")
-	}
-
-	vol.DataEngine = string(longhorn.DataEngineTypeV1)
-	if driver, ok := volOptions["dataEngine"]; ok {
-		vol.DataEngine = driver
-	}
-
-	if frontend, ok := volOptions["frontend"]; ok {
-		vol.Frontend = frontend
-	}
-
-	if freezeFilesystemForSnapshot, ok := volOptions["freezeFilesystemForSnapshot"]; ok {
-		if err := types.ValidateFreezeFilesystemForSnapshot(longhorn.FreezeFilesystemForSnapshot(freezeFilesystemForSnapshot)); err != nil {
-			return nil, errors.Wrap(err, "invalid parameter freezeFilesystemForSnapshot")
-		}
-		vol.FreezeFilesystemForSnapshot = freezeFilesystemForSnapshot
-	}
-
 	return vol, nil
 }
 
-func syncMountPointDirectory(targetPath string) error {
+func readMountPointDirectory(targetPath string) error {
 	d, err := os.OpenFile(targetPath, os.O_SYNC, 0750)
 	if err != nil {
 		return err
@@ -272,6 +284,13 @@ func syncMountPointDirectory(targetPath string) error {
 			return err
 		}
 	}
+	return nil
+}
+
+func syncMountPointDirectory(targetPath string) error {
+	if err := readMountPointDirectory(targetPath); err != nil {
+		return err
+	}
 
 	// it would not always return `Input/Output Error` or `read-only file system` errors if we only use ReadDir() or Readdirnames() without an I/O operation
 	// an I/O operation will make the targetPath mount point invalid immediately
@@ -299,7 +318,7 @@ func syncMountPointDirectory(targetPath string) error {
 // ensureMountPoint evaluates whether a path is a valid mountPoint
 // in case the path does not exists it will create a path and return false
 // in case where the mount point exists but is corrupt, the mount point will be cleaned up and a error is returned
-func ensureMountPoint(path string, mounter mount.Interface) (bool, error) {
+func ensureMountPoint(path string, mounter mount.Interface, forceUnmountTimeout time.Duration) (bool, error) {
 	logrus.Infof("Trying to ensure mount point %v", path)
 	isMnt, err := mounter.IsMountPoint(path)
 	if os.IsNotExist(err) {
@@ -316,7 +335,7 @@ func ensureMountPoint(path string, mounter mount.Interface) (bool, error) {
 	}
 
 	if IsCorruptedMnt {
-		unmountErr := unmount(path, mounter)
+		unmountErr := unmount(path, mounter, forceUnmountTimeout)
 		if unmountErr != nil {
 			return false, fmt.Errorf("failed to unmount corrupt mount point %v umount error: %v eval error: %v",
 				path, unmountErr, err)
@@ -351,11 +370,11 @@ func ensureDirectory(path string) (bool, error) {
 	return false, fmt.Errorf("path %v exists but is not a folder", path)
 }
 
-func unmount(path string, mounter mount.Interface) (err error) {
+func unmount(path string, mounter mount.Interface, forceUnmountTimeout time.Duration) (err error) {
 	forceUnmounter, ok := mounter.(mount.MounterForceUnmounter)
 	if ok {
 		logrus.Infof("Trying to force unmount potential mount point %v", path)
-		err = forceUnmounter.UnmountWithForce(path, defaultForceUmountTimeout)
+		err = forceUnmounter.UnmountWithForce(path, forceUnmountTimeout)
 	} else {
 		logrus.Infof("Trying to unmount potential mount point %v", path)
 		err = mounter.Unmount(path)
@@ -374,10 +393,10 @@ func unmount(path string, mounter mount.Interface) (err error) {
 }
 
 // unmountAndCleanupMountPoint ensures all mount layers for the path are unmounted and the mount directory is removed
-func unmountAndCleanupMountPoint(path string, mounter mount.Interface) error {
+func unmountAndCleanupMountPoint(path string, mounter mount.Interface, forceUnmountTimeout time.Duration) error {
 	// we just try to unmount since the path check would get stuck for nfs mounts
 	logrus.Infof("Trying to umount mount point %v", path)
-	if err := unmount(path, mounter); err != nil {
+	if err := unmount(path, mounter, forceUnmountTimeout); err != nil {
 		logrus.WithError(err).Warnf("Failed to unmount %v during cleanup", path)
 		return err
 	}
@@ -403,11 +422,129 @@ func isBlockDevice(volumePath string) (bool, error) {
 	return false, nil
 }
 
+// getBlockDeviceSize returns the size of the block device in bytes by the BLKGETSIZE64 ioctl
+func getBlockDeviceSize(devicePath string) (int64, error) {
+	file, err := os.Open(devicePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var size uint64
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, file.Fd(), unix.BLKGETSIZE64, uintptr(unsafe.Pointer(&size))); errno != 0 {
+		return 0, errors.Wrapf(errno, "failed to get size of block device %v", devicePath)
+	}
+	return int64(size), nil
+}
+
+// getVolumeActualSize returns the actual size of the volume data reported by its engine, which includes the data
+// kept in the snapshots
+func getVolumeActualSize(vol *longhornclient.Volume) (int64, error) {
+	if len(vol.Controllers) == 0 || vol.Controllers[0].ActualSize == "" {
+		return 0, fmt.Errorf("actual size of volume %v is not reported by its engine", vol.Name)
+	}
+	return strconv.ParseInt(vol.Controllers[0].ActualSize, 10, 64)
+}
+
 func getDiskFormat(devicePath string) (string, error) {
 	m := mount.SafeFormatAndMount{Interface: mount.New(""), Exec: utilexec.New()}
 	return m.GetDiskFormat(devicePath)
 }
 
+// getXFSProjectQuotaPercentage returns the percentage of the volume size the XFS project quota limits the volume to,
+// taken from the volume context or the volume attributes of the PV. It returns 0 if the quota is not requested.
+func getXFSProjectQuotaPercentage(volumeContext map[string]string) float64 {
+	value := volumeContext["xfsProjectQuotaPercentage"]
+	if value == "" {
+		return 0
+	}
+	if err := types.ValidateXFSProjectQuotaPercentage(value); err != nil {
+		logrus.WithError(err).Warnf("Ignoring invalid parameter xfsProjectQuotaPercentage %v", value)
+		return 0
+	}
+	percentage, _ := strconv.ParseFloat(value, 64)
+	return percentage
+}
+
+// isFilesystemCheckAfterUncleanDetachEnabled returns true if the volume context requests a read-only filesystem
+// check before the volume is mounted after an unclean detach
+func isFilesystemCheckAfterUncleanDetachEnabled(volumeContext map[string]string) bool {
+	enabled, err := strconv.ParseBool(volumeContext["filesystemCheckAfterUncleanDetach"])
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// isXFSProjectQuotaMounted returns true if the filesystem at mountPath is mounted with project quota accounting
+func isXFSProjectQuotaMounted(mountPath string) bool {
+	mountInfos, err := mount.ParseMountInfo("/proc/self/mountinfo")
+	if err != nil {
+		logrus.WithError(err).Warnf("Failed to parse mount info for checking xfs project quota of %v", mountPath)
+		return false
+	}
+
+	for _, info := range mountInfos {
+		if info.MountPoint != mountPath {
+			continue
+		}
+		for _, opt := range append(info.MountOptions, info.SuperOptions...) {
+			if opt == "prjquota" || opt == "pquota" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getXFSProjectID returns the XFS project ID of the volume, derived from the hash of the volume name, so the
+// project of a volume never changes and differs from the projects of the other volumes on the node. Project ID 0 is
+// the default project of all files, so it's never returned.
+func getXFSProjectID(volumeName string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(volumeName))
+	return h.Sum32()%(math.MaxUint32-1) + 1
+}
+
+// getXFSProjectQuotaCommands returns the xfs_quota commands assigning the root directory of the XFS filesystem mounted
+// at mountPath to the project and limiting the project to the percentage of sizeBytes, rounded up to the KiB unit of
+// the limit.
+func getXFSProjectQuotaCommands(mountPath string, projectID uint32, sizeBytes int64, percentage float64) []string {
+	limitBytes := int64(math.Ceil(float64(sizeBytes) * percentage / 100))
+	limitKiB := (limitBytes + xfsQuotaLimitUnit - 1) / xfsQuotaLimitUnit
+	return []string{
+		fmt.Sprintf("project -s -p %s %d", mountPath, projectID),
+		fmt.Sprintf("limit -p bhard=%dk %d", limitKiB, projectID),
+	}
+}
+
+// setXFSProjectQuota assigns the root directory of the XFS filesystem mounted at mountPath to the project of the volume
+// and limits the project to the percentage of sizeBytes, so the data written through the filesystem leaves the rest of
+// the volume free. The filesystem must be mounted with the prjquota option.
+func setXFSProjectQuota(mountPath, volumeName string, sizeBytes int64, percentage float64) error {
+	exec := utilexec.New()
+
+	projectID := getXFSProjectID(volumeName)
+	for _, cmd := range getXFSProjectQuotaCommands(mountPath, projectID, sizeBytes, percentage) {
+		if out, err := exec.Command("xfs_quota", "-x", "-c", cmd, mountPath).CombinedOutput(); err != nil {
+			return errors.Wrapf(err, "failed to run xfs_quota command %q for project %d on %v: %s", cmd, projectID, mountPath, string(out))
+		}
+	}
+	return nil
+}
+
+// trimFilesystem discards the unused blocks of the filesystem mounted at mountPath, so the space is reclaimed by the
+// engine.
+func trimFilesystem(mountPath string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if out, err := utilexec.New().CommandContext(ctx, "fstrim", mountPath).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "failed to trim filesystem mounted at %v: %s", mountPath, string(out))
+	}
+	return nil
+}
+
 func getFilesystemStatistics(volumePath string) (*volumeFilesystemStatistics, error) {
 	var statfs unix.Statfs_t
 	// See http://man7.org/linux/man-pages/man2/statfs.2.html for details.
@@ -424,11 +561,109 @@ func getFilesystemStatistics(volumePath string) (*volumeFilesystemStatistics, er
 		availableInodes: int64(statfs.Ffree),
 		totalInodes:     int64(statfs.Files),
 		usedInodes:      int64(statfs.Files) - int64(statfs.Ffree),
+
+		readOnly: statfs.Flags&unix.ST_RDONLY != 0,
 	}
 
 	return volStats, nil
 }
 
+// isOfflineResizeSupported returns true if the filesystem can be resized while it's not mounted.
+// XFS can only be grown online.
+func isOfflineResizeSupported(fsType string) bool {
+	switch fsType {
+	case "ext2", "ext3", "ext4":
+		return true
+	}
+	return false
+}
+
+// resizeFilesystemOffline checks and grows the unmounted ext filesystem of the device to the size of the device
+func resizeFilesystemOffline(devicePath string, exec utilexec.Interface) error {
+	// resize2fs requires a freshly checked filesystem, exit code 1 means e2fsck corrected some errors
+	if output, err := exec.Command("e2fsck", "-f", "-p", devicePath).CombinedOutput(); err != nil {
+		if exitErr, ok := err.(utilexec.ExitError); !ok || exitErr.ExitStatus() != 1 {
+			return errors.Wrapf(err, "failed to check filesystem of device %v: %v", devicePath, string(output))
+		}
+	}
+	if output, err := exec.Command("resize2fs", devicePath).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "failed to resize filesystem of device %v: %v", devicePath, string(output))
+	}
+	return nil
+}
+
+// isDeviceMounted returns true if the device, or the device its path links to, is mounted anywhere on the node
+func isDeviceMounted(devicePath string, mounter mount.Interface) (bool, error) {
+	resolvedPath, err := filepath.EvalSymlinks(devicePath)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to resolve device %v", devicePath)
+	}
+	mountPoints, err := mounter.List()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list mount points")
+	}
+	for _, mountPoint := range mountPoints {
+		if mountPoint.Device == devicePath || mountPoint.Device == resolvedPath {
+			return true, nil
+		}
+		if path, err := filepath.EvalSymlinks(mountPoint.Device); err == nil && path == resolvedPath {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// errFilesystemLogDirty is returned by checkFilesystem if the ext journal or the xfs log must be replayed by a mount
+// before the check
+var errFilesystemLogDirty = errors.New("filesystem log needs to be replayed")
+
+// isExtJournalRecoveryNeeded returns true if the superblock dumped by dumpe2fs -h has the feature needs_recovery,
+// which means the journal hasn't been replayed since the filesystem was detached uncleanly
+func isExtJournalRecoveryNeeded(superblock string) bool {
+	for _, line := range strings.Split(superblock, "\n") {
+		features, found := strings.CutPrefix(line, "Filesystem features:")
+		if !found {
+			continue
+		}
+		for _, feature := range strings.Fields(features) {
+			if feature == "needs_recovery" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkFilesystem checks the unmounted filesystem of the device without repairing it. It returns an error containing
+// the output of the check if the filesystem is corrupted or cannot be checked.
+func checkFilesystem(devicePath, fsType string, exec utilexec.Interface) error {
+	switch fsType {
+	case "ext2", "ext3", "ext4":
+		// e2fsck -n doesn't replay the journal, so the pending journal transactions are reported as errors
+		superblock, err := exec.Command("dumpe2fs", "-h", devicePath).CombinedOutput()
+		if err != nil {
+			return errors.Wrapf(err, "failed to read superblock of device %v: %v", devicePath, string(superblock))
+		}
+		if isExtJournalRecoveryNeeded(string(superblock)) {
+			return errFilesystemLogDirty
+		}
+		// exit code 4 means errors were found and left uncorrected
+		if output, err := exec.Command("e2fsck", "-n", "-f", devicePath).CombinedOutput(); err != nil {
+			return errors.Wrapf(err, "filesystem check of device %v failed: %v", devicePath, string(output))
+		}
+	case "xfs":
+		if output, err := exec.Command("xfs_repair", "-n", devicePath).CombinedOutput(); err != nil {
+			if exitErr, ok := err.(utilexec.ExitError); ok && exitErr.ExitStatus() == 2 {
+				return errFilesystemLogDirty
+			}
+			return errors.Wrapf(err, "filesystem check of device %v failed: %v", devicePath, string(output))
+		}
+	default:
+		return fmt.Errorf("filesystem check of %v is not supported", fsType)
+	}
+	return nil
+}
+
 // makeFile creates an empty file.
 // If pathname already exists, whether a file or directory, no error is returned.
 func makeFile(pathname string) error {
@@ -445,6 +680,172 @@ func makeFile(pathname string) error {
 	return nil
 }
 
+// applyVolumeMountGroup grants the group the access to the filesystem mounted at mountPath the same way kubelet
+// applies the fsGroup of a pod. The root directory is made setgid, so new files inherit the group. The filesystem is
+// walked only when recursive and the root directory doesn't belong to the group yet, since a volume is mounted for
+// every pod using it while the ownership only needs to be changed once.
+func applyVolumeMountGroup(mountPath, volumeMountGroup string, recursive bool) error {
+	gid, err := strconv.Atoi(volumeMountGroup)
+	if err != nil {
+		return errors.Wrapf(err, "invalid volume mount group %v", volumeMountGroup)
+	}
+
+	info, err := os.Stat(mountPath)
+	if err != nil {
+		return err
+	}
+	if stat, ok := info.Sys().(*unix.Stat_t); ok && int(stat.Gid) == gid && info.Mode()&os.ModeSetgid != 0 {
+		return nil
+	}
+
+	if !recursive {
+		return setVolumeMountGroup(mountPath, info, gid)
+	}
+
+	return filepath.Walk(mountPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return setVolumeMountGroup(path, info, gid)
+	})
+}
+
+func setVolumeMountGroup(path string, info os.FileInfo, gid int) error {
+	if err := os.Lchown(path, -1, gid); err != nil {
+		return errors.Wrapf(err, "failed to change group of %v to %v", path, gid)
+	}
+
+	// the permissions of a symlink are the ones of its target
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+
+	mask := os.FileMode(0660)
+	if info.IsDir() {
+		mask |= os.ModeSetgid | 0110
+	}
+	if err := os.Chmod(path, info.Mode()|mask); err != nil {
+		return errors.Wrapf(err, "failed to change mode of %v", path)
+	}
+	return nil
+}
+
+// isRWXBlockVolume returns true for a ReadWriteMany volume exported to the nodes as a raw block device over iSCSI
+// by its engine, instead of being shared over NFS by a share manager
+func isRWXBlockVolume(vol *longhornclient.Volume) bool {
+	return vol != nil && vol.AccessMode == string(longhorn.AccessModeReadWriteMany) && !vol.Migratable &&
+		vol.Frontend == string(longhorn.VolumeFrontendISCSI)
+}
+
+// isSupportedVolumeFrontend returns true if the CSI plugin can stage the volume with its frontend. Besides the
+// block devices, the iSCSI frontend is used by RWX block volumes and the NVMe-oF frontend by v2 volumes.
+func isSupportedVolumeFrontend(vol *longhornclient.Volume) bool {
+	switch longhorn.VolumeFrontend(vol.Frontend) {
+	case longhorn.VolumeFrontendBlockDev, longhorn.VolumeFrontendUblk:
+		return true
+	case longhorn.VolumeFrontendISCSI:
+		return isRWXBlockVolume(vol)
+	case longhorn.VolumeFrontendNvmf:
+		return vol.DataEngine == string(longhorn.DataEngineTypeV2)
+	}
+	return false
+}
+
+// setRWXBlockVolumeParameters makes a ReadWriteMany volume requested in block mode exported over iSCSI.
+// Migratable volumes are excluded since they are attached to the nodes directly during the migration.
+func setRWXBlockVolumeParameters(volOptions map[string]string) error {
+	if isMigratable, _ := strconv.ParseBool(volOptions["migratable"]); isMigratable {
+		return nil
+	}
+	if dataEngine := volOptions["dataEngine"]; dataEngine != "" && dataEngine != string(longhorn.DataEngineTypeV1) {
+		return fmt.Errorf("ReadWriteMany block volumes are not supported for data engine %v", dataEngine)
+	}
+	if frontend := volOptions["frontend"]; frontend != "" && frontend != string(longhorn.VolumeFrontendISCSI) {
+		return fmt.Errorf("ReadWriteMany block volumes are exported over iSCSI, frontend %v is not supported", frontend)
+	}
+	if isEncrypted, _ := strconv.ParseBool(volOptions["encrypted"]); isEncrypted {
+		return fmt.Errorf("ReadWriteMany block volumes cannot be encrypted")
+	}
+	volOptions["frontend"] = string(longhorn.VolumeFrontendISCSI)
+	return nil
+}
+
+// parseISCSIEndpoint parses an engine endpoint like iscsi://10.42.0.12:3260/iqn.2019-10.io.longhorn:vol-name/1
+func parseISCSIEndpoint(endpoint string) (ip, target string, lun int, err error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if u.Scheme != "iscsi" {
+		return "", "", 0, fmt.Errorf("invalid iSCSI endpoint %v", endpoint)
+	}
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", 0, fmt.Errorf("invalid iSCSI endpoint %v", endpoint)
+	}
+	lun, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return "", "", 0, errors.Wrapf(err, "invalid LUN of iSCSI endpoint %v", endpoint)
+	}
+	return u.Hostname(), parts[0], lun, nil
+}
+
+func newISCSINamespaceExecutor() (*lhns.Executor, error) {
+	// iscsiadm talks to the host iscsid over an abstract unix socket, which requires the host network namespace
+	namespaces := []lhtypes.Namespace{lhtypes.NamespaceMnt, lhtypes.NamespaceNet}
+	return lhns.NewNamespaceExecutor(lhtypes.ProcessNone, lhtypes.HostProcDirectory, namespaces)
+}
+
+// loginISCSITarget logs the node in to the iSCSI target of the engine endpoint and returns the block device
+func loginISCSITarget(endpoint string) (string, error) {
+	ip, target, lun, err := parseISCSIEndpoint(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	nsexec, err := newISCSINamespaceExecutor()
+	if err != nil {
+		return "", err
+	}
+
+	if !iscsi.IsTargetLoggedIn(ip, target, nsexec) {
+		if err := iscsi.DiscoverTarget(ip, target, nsexec); err != nil {
+			return "", errors.Wrapf(err, "failed to discover iSCSI target %v on %v", target, ip)
+		}
+		if err := iscsi.LoginTarget(ip, target, nsexec); err != nil {
+			return "", errors.Wrapf(err, "failed to log in to iSCSI target %v on %v", target, ip)
+		}
+	}
+
+	device, err := iscsi.GetDevice(ip, target, lun, nsexec)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the device of iSCSI target %v on %v", target, ip)
+	}
+	return filepath.Join("/dev", device.Name), nil
+}
+
+// logoutISCSITarget logs the node out of the iSCSI target of the volume on all portals
+func logoutISCSITarget(volumeName string) error {
+	target := iscsiTargetPrefix + volumeName
+
+	nsexec, err := newISCSINamespaceExecutor()
+	if err != nil {
+		return err
+	}
+
+	if iscsi.IsTargetLoggedIn("", target, nsexec) {
+		if err := iscsi.LogoutTarget("", target, nsexec); err != nil {
+			return errors.Wrapf(err, "failed to log out of iSCSI target %v", target)
+		}
+	}
+	if iscsi.IsTargetDiscovered("", target, nsexec) {
+		if err := iscsi.DeleteDiscoveredTarget("", target, nsexec); err != nil {
+			return errors.Wrapf(err, "failed to delete discovered iSCSI target %v", target)
+		}
+	}
+	return nil
+}
+
 // requiresSharedAccess checks if the volume is requested to be multi node capable
 // a volume that is already in shared access mode, must be used via shared access
 // even if single node access is requested.
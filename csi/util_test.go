@@ -0,0 +1,198 @@
+package csi
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/require"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/mount-utils"
+
+	"github.com/longhorn/longhorn-manager/types"
+)
+
+func TestGetXFSProjectID(t *testing.T) {
+	projectID := getXFSProjectID("vol-1")
+	require.NotZero(t, projectID)
+	require.Equal(t, projectID, getXFSProjectID("vol-1"))
+	require.NotEqual(t, projectID, getXFSProjectID("vol-2"))
+}
+
+func TestGetXFSProjectQuotaPercentage(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		expected float64
+	}{
+		"not set":        {value: "", expected: 0},
+		"percentage":     {value: "90", expected: 90},
+		"fraction":       {value: "92.5", expected: 92.5},
+		"invalid":        {value: "all", expected: 0},
+		"zero":           {value: "0", expected: 0},
+		"whole volume":   {value: "100", expected: 0},
+		"above 100":      {value: "120", expected: 0},
+		"negative value": {value: "-10", expected: 0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, getXFSProjectQuotaPercentage(map[string]string{"xfsProjectQuotaPercentage": tc.value}))
+		})
+	}
+}
+
+func TestGetXFSProjectQuotaCommands(t *testing.T) {
+	tests := map[string]struct {
+		sizeBytes     int64
+		percentage    float64
+		expectedLimit string
+	}{
+		"limit aligned to KiB":   {sizeBytes: 2 * 1024 * 1024 * 1024, percentage: 50, expectedLimit: "limit -p bhard=1048576k 42"},
+		"fractional percentage":  {sizeBytes: 1024 * 1024 * 1024, percentage: 92.5, expectedLimit: "limit -p bhard=969933k 42"},
+		"limit rounded up":       {sizeBytes: 2*1024*1024*1024 + 2, percentage: 50, expectedLimit: "limit -p bhard=1048577k 42"},
+		"limit smaller than KiB": {sizeBytes: 1024, percentage: 10, expectedLimit: "limit -p bhard=1k 42"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, []string{
+				"project -s -p /var/lib/kubelet/staging/vol-1 42",
+				tc.expectedLimit,
+			}, getXFSProjectQuotaCommands("/var/lib/kubelet/staging/vol-1", 42, tc.sizeBytes, tc.percentage))
+		})
+	}
+}
+
+func TestGetRestoreCredential(t *testing.T) {
+	credential, err := getRestoreCredential(nil)
+	require.NoError(t, err)
+	require.Nil(t, credential)
+
+	secrets := map[string]string{
+		types.AWSIAMRoleArn:      "arn:aws:iam::013456789:role/tenant",
+		restoreServiceAccountKey: "restore",
+	}
+	credential, err = getRestoreCredential(secrets)
+	require.NoError(t, err)
+	require.Equal(t, secrets, credential)
+
+	_, err = getRestoreCredential(map[string]string{restoreServiceAccountKey: "restore"})
+	require.Error(t, err)
+}
+
+func TestRequestRestoreServiceAccountToken(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	var tokenRequest *authenticationv1.TokenRequest
+	serviceAccountName := ""
+	kubeClient.PrependReactor("create", "serviceaccounts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		createAction := action.(clienttesting.CreateActionImpl)
+		require.Equal(t, "token", createAction.GetSubresource())
+		require.Equal(t, "tenant", createAction.GetNamespace())
+		serviceAccountName = createAction.Name
+		tokenRequest = createAction.GetObject().(*authenticationv1.TokenRequest)
+		return true, &authenticationv1.TokenRequest{Status: authenticationv1.TokenRequestStatus{Token: "token"}}, nil
+	})
+
+	credential := map[string]string{types.AWSAccessKey: "key"}
+	require.NoError(t, requestRestoreServiceAccountToken(context.Background(), kubeClient, "tenant", credential))
+	require.Equal(t, map[string]string{types.AWSAccessKey: "key"}, credential)
+	require.Nil(t, tokenRequest)
+
+	credential = map[string]string{
+		types.AWSIAMRoleArn:      "arn:aws:iam::013456789:role/tenant",
+		restoreServiceAccountKey: "restore",
+	}
+	require.Error(t, requestRestoreServiceAccountToken(context.Background(), kubeClient, "", credential))
+	require.NoError(t, requestRestoreServiceAccountToken(context.Background(), kubeClient, "tenant", credential))
+	require.Equal(t, "restore", serviceAccountName)
+	require.Equal(t, []string{awsSTSAudience}, tokenRequest.Spec.Audiences)
+	require.Equal(t, map[string]string{
+		types.AWSIAMRoleArn:       "arn:aws:iam::013456789:role/tenant",
+		types.AWSWebIdentityToken: "token",
+	}, credential)
+}
+
+func TestGetPublishMountOptions(t *testing.T) {
+	volumeCapability := &csi.VolumeCapability{
+		AccessType: &csi.VolumeCapability_Mount{
+			Mount: &csi.VolumeCapability_MountVolume{
+				MountFlags: []string{"context=\"system_u:object_r:container_file_t:s0:c1,c2\"", "noatime"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		readOnly     bool
+		sharedAccess bool
+		expected     []string
+	}{
+		"single pod volume": {
+			expected: []string{"bind", "noatime", "context=\"system_u:object_r:container_file_t:s0:c1,c2\""},
+		},
+		"read-only single pod volume": {
+			readOnly: true,
+			expected: []string{"bind", "ro", "noatime", "context=\"system_u:object_r:container_file_t:s0:c1,c2\""},
+		},
+		"shared volume": {
+			sharedAccess: true,
+			expected:     []string{"bind", "noatime"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, getPublishMountOptions(volumeCapability, tc.readOnly, tc.sharedAccess))
+		})
+	}
+}
+
+func TestNewCSIDriverObject(t *testing.T) {
+	require.False(t, *NewCSIDriverObject(false).obj.Spec.SELinuxMount)
+	require.True(t, *NewCSIDriverObject(true).obj.Spec.SELinuxMount)
+}
+
+func TestIsExtJournalRecoveryNeeded(t *testing.T) {
+	superblock := "Filesystem volume name:   <none>\n" +
+		"Filesystem features:      has_journal ext_attr resize_inode dir_index filetype needs_recovery extent 64bit flex_bg sparse_super\n" +
+		"Filesystem state:         clean\n"
+	require.True(t, isExtJournalRecoveryNeeded(superblock))
+
+	superblock = "Filesystem volume name:   <none>\n" +
+		"Filesystem features:      has_journal ext_attr resize_inode dir_index filetype extent 64bit flex_bg sparse_super\n" +
+		"Journal features:         journal_incompat_revoke\n"
+	require.False(t, isExtJournalRecoveryNeeded(superblock))
+	require.False(t, isExtJournalRecoveryNeeded(""))
+}
+
+func TestIsDeviceMounted(t *testing.T) {
+	dir := t.TempDir()
+	devicePath := filepath.Join(dir, "sdb")
+	require.NoError(t, os.WriteFile(devicePath, nil, 0600))
+	linkPath := filepath.Join(dir, "vol-1")
+	require.NoError(t, os.Symlink(devicePath, linkPath))
+
+	mounter := mount.NewFakeMounter([]mount.MountPoint{{Device: "/dev/sdc", Path: "/mnt/other"}})
+	isMounted, err := isDeviceMounted(linkPath, mounter)
+	require.NoError(t, err)
+	require.False(t, isMounted)
+
+	// The device is mounted by the path it's linked from
+	mounter.MountPoints = append(mounter.MountPoints, mount.MountPoint{Device: devicePath, Path: "/mnt/staging"})
+	isMounted, err = isDeviceMounted(linkPath, mounter)
+	require.NoError(t, err)
+	require.True(t, isMounted)
+
+	mounter.MountPoints = []mount.MountPoint{{Device: linkPath, Path: "/mnt/staging"}}
+	isMounted, err = isDeviceMounted(devicePath, mounter)
+	require.NoError(t, err)
+	require.True(t, isMounted)
+
+	_, err = isDeviceMounted(filepath.Join(dir, "missing"), mounter)
+	require.Error(t, err)
+}
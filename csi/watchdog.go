@@ -0,0 +1,225 @@
+package csi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// DefaultWatchdogThreshold is the duration after which a running CSI call is reported as stuck
+	DefaultWatchdogThreshold = 10 * time.Minute
+	DefaultCSIWatchdogPort   = 9809
+
+	watchdogCheckInterval = 30 * time.Second
+	watchdogLivenessPath  = "/livez"
+)
+
+// watchedOperation is a CSI call being handled
+type watchedOperation struct {
+	Method    string    `json:"method"`
+	VolumeID  string    `json:"volumeID,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+	Duration  string    `json:"duration"`
+	// ForceUnmounted is true once the path of the stuck unmount has been force unmounted
+	ForceUnmounted bool `json:"forceUnmounted"`
+}
+
+// watchdog tracks the running CSI calls and reports the ones running longer than the threshold, e.g. an unmount of a
+// RWX volume hung on an unreachable NFS server. A stuck call blocks the following calls of the volume, and holds the
+// plugin in an uninterruptible sleep if it's in a syscall, so it's reported through a dedicated liveness endpoint
+// instead of waiting for the sidecars to time out. Optionally, the stuck unmounts are aborted by force unmounting
+// their path, since the path was about to be unmounted anyway.
+type watchdog struct {
+	threshold    time.Duration
+	forceUnmount bool
+
+	lock       sync.Mutex
+	lastID     uint64
+	operations map[uint64]*watchedOperation
+}
+
+// newWatchdog returns nil if the threshold is not positive, which means the calls are not watched
+func newWatchdog(threshold time.Duration, forceUnmount bool) *watchdog {
+	if threshold <= 0 {
+		return nil
+	}
+	return &watchdog{
+		threshold:    threshold,
+		forceUnmount: forceUnmount,
+		operations:   map[uint64]*watchedOperation{},
+	}
+}
+
+// getWatchedPath returns the path the request mounts, unmounts or reads, which is the path blocked by a hung mount
+func getWatchedPath(req interface{}) string {
+	switch r := req.(type) {
+	case *csi.NodeStageVolumeRequest:
+		return r.GetStagingTargetPath()
+	case *csi.NodeUnstageVolumeRequest:
+		return r.GetStagingTargetPath()
+	case *csi.NodePublishVolumeRequest:
+		return r.GetTargetPath()
+	case *csi.NodeUnpublishVolumeRequest:
+		return r.GetTargetPath()
+	case *csi.NodeGetVolumeStatsRequest:
+		return r.GetVolumePath()
+	case *csi.NodeExpandVolumeRequest:
+		return r.GetVolumePath()
+	}
+	return ""
+}
+
+// isUnmountMethod returns true for the calls which unmount their path, so force unmounting it doesn't break a mount
+// still in use
+func isUnmountMethod(method string) bool {
+	return method == "NodeUnstageVolume" || method == "NodeUnpublishVolume"
+}
+
+func (w *watchdog) insert(method string, req interface{}) uint64 {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	operation := &watchedOperation{
+		Method:    method,
+		Path:      getWatchedPath(req),
+		StartedAt: time.Now(),
+	}
+	if r, ok := req.(interface{ GetVolumeId() string }); ok {
+		operation.VolumeID = r.GetVolumeId()
+	}
+
+	w.lastID++
+	w.operations[w.lastID] = operation
+	return w.lastID
+}
+
+func (w *watchdog) delete(id uint64) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	delete(w.operations, id)
+}
+
+// watchGRPC tracks the calls while they are handled
+func (w *watchdog) watchGRPC(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+
+	id := w.insert(method, req)
+	defer w.delete(id)
+
+	return handler(ctx, req)
+}
+
+// listStuckOperations returns copies of the operations running longer than the threshold, the longest first
+func (w *watchdog) listStuckOperations() []watchedOperation {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	now := time.Now()
+	stuck := []watchedOperation{}
+	for _, operation := range w.operations {
+		duration := now.Sub(operation.StartedAt)
+		if duration < w.threshold {
+			continue
+		}
+		o := *operation
+		o.Duration = duration.Round(time.Second).String()
+		stuck = append(stuck, o)
+	}
+	sort.Slice(stuck, func(i, j int) bool {
+		return stuck[i].StartedAt.Before(stuck[j].StartedAt)
+	})
+	return stuck
+}
+
+// listForceUnmountPaths marks the stuck unmounts as force unmounted and returns their paths, so each path is only
+// force unmounted once
+func (w *watchdog) listForceUnmountPaths() []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	paths := []string{}
+	for _, operation := range w.operations {
+		if operation.ForceUnmounted || operation.Path == "" || !isUnmountMethod(operation.Method) {
+			continue
+		}
+		if time.Since(operation.StartedAt) < w.threshold {
+			continue
+		}
+		operation.ForceUnmounted = true
+		paths = append(paths, operation.Path)
+	}
+	return paths
+}
+
+func (w *watchdog) run() {
+	wait.Forever(w.check, watchdogCheckInterval)
+}
+
+func (w *watchdog) check() {
+	for _, operation := range w.listStuckOperations() {
+		logrus.WithFields(logrus.Fields{
+			"method":   operation.Method,
+			"volume":   operation.VolumeID,
+			"path":     operation.Path,
+			"duration": operation.Duration,
+		}).Warn("CSI call is stuck")
+	}
+
+	if !w.forceUnmount {
+		return
+	}
+	for _, path := range w.listForceUnmountPaths() {
+		// The force unmount aborts the pending requests to the NFS server, so the stuck unmount returns. It's run
+		// in the background in case it blocks as well.
+		go func(path string) {
+			log := logrus.WithField("path", path)
+			log.Warn("Force unmounting the path of the stuck CSI call")
+			if err := unix.Unmount(path, unix.MNT_FORCE|unix.MNT_DETACH); err != nil {
+				log.WithError(err).Warn("Failed to force unmount the path of the stuck CSI call")
+			}
+		}(path)
+	}
+}
+
+// ServeHTTP responds 200 if no CSI call is stuck, or 503 with the stuck calls
+func (w *watchdog) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	stuck := w.listStuckOperations()
+	if len(stuck) == 0 {
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte("ok"))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusServiceUnavailable)
+	if err := json.NewEncoder(rw).Encode(stuck); err != nil {
+		logrus.WithError(err).Warn("Failed to write the stuck CSI calls")
+	}
+}
+
+// serve serves the liveness endpoint on the port
+func (w *watchdog) serve(port int) error {
+	mux := http.NewServeMux()
+	mux.Handle(watchdogLivenessPath, w)
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%v", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
@@ -0,0 +1,93 @@
+package csi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestNewWatchdog(t *testing.T) {
+	require.Nil(t, newWatchdog(0, true))
+	require.Nil(t, newWatchdog(-time.Minute, true))
+	require.NotNil(t, newWatchdog(time.Minute, false))
+}
+
+func TestWatchdog(t *testing.T) {
+	// Every running call is stuck with the threshold of 1ns
+	w := newWatchdog(time.Nanosecond, true)
+
+	unstageStarted, unstageDone := make(chan struct{}), make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		defer close(unstageDone)
+		_, _ = w.watchGRPC(context.Background(), &csi.NodeUnstageVolumeRequest{VolumeId: "vol-1", StagingTargetPath: "/staging/vol-1"},
+			&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Node/NodeUnstageVolume"},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				close(unstageStarted)
+				<-release
+				return &csi.NodeUnstageVolumeResponse{}, nil
+			})
+	}()
+	<-unstageStarted
+
+	statsStarted, statsDone := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(statsDone)
+		_, _ = w.watchGRPC(context.Background(), &csi.NodeGetVolumeStatsRequest{VolumeId: "vol-2", VolumePath: "/publish/vol-2"},
+			&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Node/NodeGetVolumeStats"},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				close(statsStarted)
+				<-release
+				return &csi.NodeGetVolumeStatsResponse{}, nil
+			})
+	}()
+	<-statsStarted
+
+	// The liveness endpoint reports the stuck calls, the longest first
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, watchdogLivenessPath, nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	stuck := []watchedOperation{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&stuck))
+	require.Len(t, stuck, 2)
+	require.Equal(t, "NodeUnstageVolume", stuck[0].Method)
+	require.Equal(t, "vol-1", stuck[0].VolumeID)
+	require.Equal(t, "/staging/vol-1", stuck[0].Path)
+	require.Equal(t, "NodeGetVolumeStats", stuck[1].Method)
+	require.Equal(t, "vol-2", stuck[1].VolumeID)
+
+	// Only the path of the stuck unmount is force unmounted, and only once
+	require.Equal(t, []string{"/staging/vol-1"}, w.listForceUnmountPaths())
+	require.Empty(t, w.listForceUnmountPaths())
+
+	close(release)
+	<-unstageDone
+	<-statsDone
+
+	rec = httptest.NewRecorder()
+	w.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, watchdogLivenessPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestWatchdogThreshold(t *testing.T) {
+	w := newWatchdog(time.Hour, true)
+	id := w.insert("NodeUnpublishVolume", &csi.NodeUnpublishVolumeRequest{VolumeId: "vol-1", TargetPath: "/publish/vol-1"})
+	require.Empty(t, w.listStuckOperations())
+	require.Empty(t, w.listForceUnmountPaths())
+
+	w.operations[id].StartedAt = time.Now().Add(-2 * time.Hour)
+	stuck := w.listStuckOperations()
+	require.Len(t, stuck, 1)
+	require.Equal(t, "2h0m0s", stuck[0].Duration)
+	require.Equal(t, []string{"/publish/vol-1"}, w.listForceUnmountPaths())
+
+	w.delete(id)
+	require.Empty(t, w.listStuckOperations())
+}
@@ -15,6 +15,7 @@ import (
 	policylisters "k8s.io/client-go/listers/policy/v1"
 	schedulinglisters "k8s.io/client-go/listers/scheduling/v1"
 	storagelisters_v1 "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/utils/clock"
 
 	"github.com/longhorn/longhorn-manager/util"
 
@@ -78,6 +79,10 @@ type DataStore struct {
 	BackupInformer                 cache.SharedInformer
 	recurringJobLister             lhlisters.RecurringJobLister
 	RecurringJobInformer           cache.SharedInformer
+	maintenancePolicyLister        lhlisters.MaintenancePolicyLister
+	MaintenancePolicyInformer      cache.SharedInformer
+	engineUpgradePolicyLister      lhlisters.EngineUpgradePolicyLister
+	EngineUpgradePolicyInformer    cache.SharedInformer
 	orphanLister                   lhlisters.OrphanLister
 	OrphanInformer                 cache.SharedInformer
 	snapshotLister                 lhlisters.SnapshotLister
@@ -128,6 +133,9 @@ type DataStore struct {
 	LeaseInformer                 cache.SharedInformer
 
 	extensionsClient apiextensionsclientset.Interface
+
+	// clock is the source of the current time of the datastore, which is replaced by a virtual clock in simulations
+	clock clock.PassiveClock
 }
 
 // NewDataStore creates new DataStore object
@@ -167,6 +175,10 @@ func NewDataStore(namespace string, lhClient lhclientset.Interface, kubeClient c
 	cacheSyncs = append(cacheSyncs, backupInformer.Informer().HasSynced)
 	recurringJobInformer := informerFactories.LhInformerFactory.Longhorn().V1beta2().RecurringJobs()
 	cacheSyncs = append(cacheSyncs, recurringJobInformer.Informer().HasSynced)
+	maintenancePolicyInformer := informerFactories.LhInformerFactory.Longhorn().V1beta2().MaintenancePolicies()
+	cacheSyncs = append(cacheSyncs, maintenancePolicyInformer.Informer().HasSynced)
+	engineUpgradePolicyInformer := informerFactories.LhInformerFactory.Longhorn().V1beta2().EngineUpgradePolicies()
+	cacheSyncs = append(cacheSyncs, engineUpgradePolicyInformer.Informer().HasSynced)
 	orphanInformer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Orphans()
 	cacheSyncs = append(cacheSyncs, orphanInformer.Informer().HasSynced)
 	snapshotInformer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Snapshots()
@@ -256,6 +268,10 @@ func NewDataStore(namespace string, lhClient lhclientset.Interface, kubeClient c
 		BackupInformer:                 backupInformer.Informer(),
 		recurringJobLister:             recurringJobInformer.Lister(),
 		RecurringJobInformer:           recurringJobInformer.Informer(),
+		maintenancePolicyLister:        maintenancePolicyInformer.Lister(),
+		MaintenancePolicyInformer:      maintenancePolicyInformer.Informer(),
+		engineUpgradePolicyLister:      engineUpgradePolicyInformer.Lister(),
+		EngineUpgradePolicyInformer:    engineUpgradePolicyInformer.Informer(),
 		orphanLister:                   orphanInformer.Lister(),
 		OrphanInformer:                 orphanInformer.Informer(),
 		snapshotLister:                 snapshotInformer.Lister(),
@@ -307,9 +323,16 @@ func NewDataStore(namespace string, lhClient lhclientset.Interface, kubeClient c
 		DeploymentInformer:          deploymentInformer.Informer(),
 
 		extensionsClient: extensionsClient,
+
+		clock: clock.RealClock{},
 	}
 }
 
+// SetClock replaces the clock of the datastore, e.g. with a fake clock to control the time in simulations
+func (s *DataStore) SetClock(clock clock.PassiveClock) {
+	s.clock = clock
+}
+
 // Sync returns WaitForCacheSync for Longhorn DataStore
 func (s *DataStore) Sync(stopCh <-chan struct{}) bool {
 	return cache.WaitForNamedCacheSync("longhorn datastore", stopCh, s.cacheSyncs...)
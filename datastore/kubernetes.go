@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -29,6 +30,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
 
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
@@ -105,7 +107,7 @@ func (s *DataStore) GetManagerNodeIPMap() (map[string]string, error) {
 		if nodeIPMap[pod.Spec.NodeName] != "" {
 			return nil, fmt.Errorf("multiple managers on the node %v", pod.Spec.NodeName)
 		}
-		nodeIPMap[pod.Spec.NodeName] = pod.Status.PodIP
+		nodeIPMap[pod.Spec.NodeName] = s.GetPodIP(pod)
 	}
 	return nodeIPMap, nil
 }
@@ -875,6 +877,29 @@ func (s *DataStore) GetSecretRO(namespace, name string) (*corev1.Secret, error)
 	return s.kubeClient.CoreV1().Secrets(namespace).Get(context.Background(), name, metav1.GetOptions{})
 }
 
+// GetVolumeEncryptionSecretRO returns the node stage secret of the PV of the encrypted volume, which keeps the
+// passphrase and the encryption parameters
+func (s *DataStore) GetVolumeEncryptionSecretRO(volume *longhorn.Volume) (*corev1.Secret, error) {
+	pvName := volume.Status.KubernetesStatus.PVName
+	if pvName == "" {
+		return nil, fmt.Errorf("volume %v has no PV", volume.Name)
+	}
+	pv, err := s.GetPersistentVolumeRO(pvName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get PV %v", pvName)
+	}
+	if pv.Spec.CSI == nil || pv.Spec.CSI.NodeStageSecretRef == nil {
+		return nil, fmt.Errorf("PV %v has no node stage secret", pvName)
+	}
+
+	secretRef := pv.Spec.CSI.NodeStageSecretRef
+	secret, err := s.GetSecretRO(secretRef.Namespace, secretRef.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get secret %v/%v", secretRef.Namespace, secretRef.Name)
+	}
+	return secret, nil
+}
+
 // GetSecret return a new Secret object with the given namespace and name
 func (s *DataStore) GetSecret(namespace, name string) (resultRO *corev1.Secret, err error) {
 	if namespace == s.namespace {
@@ -889,6 +914,11 @@ func (s *DataStore) GetSecret(namespace, name string) (resultRO *corev1.Secret,
 	return resultRO.DeepCopy(), nil
 }
 
+// CreateSecret creates the Secret resource with the given object and namespace
+func (s *DataStore) CreateSecret(namespace string, secret *corev1.Secret) (*corev1.Secret, error) {
+	return s.kubeClient.CoreV1().Secrets(namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+}
+
 // UpdateSecret updates the Secret resource with the given object and namespace
 func (s *DataStore) UpdateSecret(namespace string, secret *corev1.Secret) (*corev1.Secret, error) {
 	return s.kubeClient.CoreV1().Secrets(namespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
@@ -1150,6 +1180,66 @@ func NewPVCManifest(size int64, pvName, ns, pvcName, storageClassName string, ac
 	}
 }
 
+// IsIPv6Preferred returns true if Longhorn uses the IPv6 addresses of its
+// component pods according to the ip-family setting. For the auto option, it
+// follows the IP family of the current Longhorn manager pod.
+func (s *DataStore) IsIPv6Preferred() (bool, error) {
+	ipFamily, err := s.GetSettingWithAutoFillingRO(types.SettingNameIPFamily)
+	if err != nil {
+		return false, err
+	}
+
+	switch types.IPFamily(ipFamily.Value) {
+	case types.IPFamilyIPv4:
+		return false, nil
+	case types.IPFamilyIPv6:
+		return true, nil
+	}
+	return util.IsIPv6(os.Getenv(types.EnvPodIP)), nil
+}
+
+// GetListenIP returns the unspecified address of the preferred IP family for
+// the Longhorn component servers to listen on.
+func (s *DataStore) GetListenIP() (string, error) {
+	ipv6, err := s.IsIPv6Preferred()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the preferred IP family")
+	}
+	return util.GetUnspecifiedIP(ipv6), nil
+}
+
+// GetPodIP returns the pod IP of the IP family chosen by the ip-family
+// setting. It falls back to the primary pod IP if the pod has no IP of the
+// family, or if the setting is auto.
+func (s *DataStore) GetPodIP(pod *corev1.Pod) string {
+	ipFamily, err := s.GetSettingWithAutoFillingRO(types.SettingNameIPFamily)
+	if err != nil {
+		logrus.WithError(err).Warnf("Failed to get %v setting, use %v pod IP %v", types.SettingNameIPFamily, pod.Name, pod.Status.PodIP)
+		return pod.Status.PodIP
+	}
+	if types.IPFamily(ipFamily.Value) == types.IPFamilyAuto {
+		return pod.Status.PodIP
+	}
+
+	podIPs := []string{}
+	for _, podIP := range pod.Status.PodIPs {
+		podIPs = append(podIPs, podIP.IP)
+	}
+	if ip := util.GetIPByFamily(podIPs, types.IPFamily(ipFamily.Value) == types.IPFamilyIPv6); ip != "" {
+		return ip
+	}
+	return pod.Status.PodIP
+}
+
+func (s *DataStore) getIPByPreferredFamily(ips []string) string {
+	ipv6, err := s.IsIPv6Preferred()
+	if err != nil {
+		logrus.WithError(err).Warnf("Failed to get the preferred IP family")
+		return ""
+	}
+	return util.GetIPByFamily(ips, ipv6)
+}
+
 // GetStorageIPFromPod returns the given pod network-status IP of the name matching the storage-network setting value.
 // If the storage-network setting is empty or encountered an error, return the pod IP instead.
 // For below example, given "kube-system/demo-192-168-0-0" will return "192.168.1.175".
@@ -1179,15 +1269,17 @@ func NewPVCManifest(size int64, pvName, ns, pvcName, storageClassName string, ac
 //	  	  "dns": {}
 //	    }]
 func (s *DataStore) GetStorageIPFromPod(pod *corev1.Pod) string {
+	podIP := s.GetPodIP(pod)
+
 	storageNetwork, err := s.GetSettingWithAutoFillingRO(types.SettingNameStorageNetwork)
 	if err != nil {
-		logrus.Warnf("Failed to get %v setting, use %v pod IP %v", types.SettingNameStorageNetwork, pod.Name, pod.Status.PodIP)
-		return pod.Status.PodIP
+		logrus.Warnf("Failed to get %v setting, use %v pod IP %v", types.SettingNameStorageNetwork, pod.Name, podIP)
+		return podIP
 	}
 
 	if storageNetwork.Value == types.CniNetworkNone {
-		logrus.Tracef("Found %v setting is empty, use %v pod IP %v", types.SettingNameStorageNetwork, pod.Name, pod.Status.PodIP)
-		return pod.Status.PodIP
+		logrus.Tracef("Found %v setting is empty, use %v pod IP %v", types.SettingNameStorageNetwork, pod.Name, podIP)
+		return podIP
 	}
 
 	// Check if the network-status annotation exists.
@@ -1199,16 +1291,16 @@ func (s *DataStore) GetStorageIPFromPod(pod *corev1.Pod) string {
 
 		// If the deprecated annotation is also missing, use the pod IP.
 		if !ok {
-			logrus.Warnf("Missing %v annotation, use %v pod IP %v", types.CNIAnnotationNetworkStatus, pod.Name, pod.Status.PodIP)
-			return pod.Status.PodIP
+			logrus.Warnf("Missing %v annotation, use %v pod IP %v", types.CNIAnnotationNetworkStatus, pod.Name, podIP)
+			return podIP
 		}
 	}
 
 	nets := []types.CniNetwork{}
 	err = json.Unmarshal([]byte(status), &nets)
 	if err != nil {
-		logrus.Warnf("Failed to unmarshal %v annotation, use %v pod IP %v", types.CNIAnnotationNetworkStatus, pod.Name, pod.Status.PodIP)
-		return pod.Status.PodIP
+		logrus.Warnf("Failed to unmarshal %v annotation, use %v pod IP %v", types.CNIAnnotationNetworkStatus, pod.Name, podIP)
+		return podIP
 	}
 
 	for _, net := range nets {
@@ -1218,12 +1310,15 @@ func (s *DataStore) GetStorageIPFromPod(pod *corev1.Pod) string {
 
 		sort.Strings(net.IPs)
 		if net.IPs != nil {
+			if ip := s.getIPByPreferredFamily(net.IPs); ip != "" {
+				return ip
+			}
 			return net.IPs[0]
 		}
 	}
 
-	logrus.Warnf("Failed to get storage IP from %v pod, use IP %v", pod.Name, pod.Status.PodIP)
-	return pod.Status.PodIP
+	logrus.Warnf("Failed to get storage IP from %v pod, use IP %v", pod.Name, podIP)
+	return podIP
 }
 
 func (s *DataStore) UpdatePVAnnotation(volume *longhorn.Volume, annotationKey, annotationVal string) error {
@@ -1343,3 +1438,55 @@ func (s *DataStore) GetRoleBinding(name string) (*rbacv1.RoleBinding, error) {
 func (s *DataStore) UpdateRoleBinding(roleBinding *rbacv1.RoleBinding) (*rbacv1.RoleBinding, error) {
 	return s.kubeClient.RbacV1().RoleBindings(s.namespace).Update(context.TODO(), roleBinding, metav1.UpdateOptions{})
 }
+
+// CreateEvent creates the event directly rather than through an event recorder, for one-shot records like the audit
+// records of the API operations
+func (s *DataStore) CreateEvent(event *corev1.Event) (*corev1.Event, error) {
+	return s.kubeClient.CoreV1().Events(s.namespace).Create(context.TODO(), event, metav1.CreateOptions{})
+}
+
+// volumeSnapshotContentList is the part of the CSI VolumeSnapshotContent list holding the snapshot handles, since the
+// client of the CSI snapshot CRDs is not a dependency
+type volumeSnapshotContentList struct {
+	Items []struct {
+		Spec struct {
+			Source struct {
+				SnapshotHandle string `json:"snapshotHandle"`
+			} `json:"source"`
+		} `json:"spec"`
+		Status struct {
+			SnapshotHandle string `json:"snapshotHandle"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// ListVolumeSnapshotContentSnapshotHandles returns the snapshot handles referenced by the CSI VolumeSnapshotContents,
+// e.g. snap://<volume>/<snapshot> for the Longhorn snapshots. It's empty if the CSI snapshot CRDs are not installed.
+// It's an uncached list directly from the API server.
+func (s *DataStore) ListVolumeSnapshotContentSnapshotHandles() (map[string]bool, error) {
+	handles := map[string]bool{}
+	restClient := s.kubeClient.Discovery().RESTClient()
+	if restClient == nil {
+		return handles, nil
+	}
+
+	raw, err := restClient.Get().AbsPath("/apis/snapshot.storage.k8s.io/v1/volumesnapshotcontents").DoRaw(context.TODO())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return handles, nil
+		}
+		return nil, errors.Wrap(err, "failed to list VolumeSnapshotContents")
+	}
+	contents := &volumeSnapshotContentList{}
+	if err := json.Unmarshal(raw, contents); err != nil {
+		return nil, errors.Wrap(err, "failed to decode VolumeSnapshotContents")
+	}
+	for _, content := range contents.Items {
+		for _, handle := range []string{content.Spec.Source.SnapshotHandle, content.Status.SnapshotHandle} {
+			if handle != "" {
+				handles[handle] = true
+			}
+		}
+	}
+	return handles, nil
+}
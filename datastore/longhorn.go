@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -296,6 +297,19 @@ func (s *DataStore) ValidateSetting(name, value string) (err error) {
 				return errors.Wrapf(err, "failed to get priority class %v before modifying priority class setting", value)
 			}
 		}
+	case types.SettingNameSystemManagedComponentsSchedulingOverrides:
+		overrides, err := types.UnmarshalSystemManagedComponentsSchedulingOverrides(value)
+		if err != nil {
+			return err
+		}
+		for component, scheduling := range overrides {
+			if scheduling.PriorityClass == "" {
+				continue
+			}
+			if _, err := s.GetPriorityClass(scheduling.PriorityClass); err != nil {
+				return errors.Wrapf(err, "failed to get priority class %v of system managed component %v", scheduling.PriorityClass, component)
+			}
+		}
 	case types.SettingNameGuaranteedInstanceManagerCPU, types.SettingNameV2DataEngineGuaranteedInstanceManagerCPU:
 		guaranteedInstanceManagerCPU, err := s.GetSettingWithAutoFillingRO(sName)
 		if err != nil {
@@ -782,6 +796,7 @@ func (s *DataStore) GetCredentialFromSecret(secretName string) (map[string]strin
 	credentialSecret[types.AWSSecretKey] = string(secret.Data[types.AWSSecretKey])
 	credentialSecret[types.AWSEndPoint] = string(secret.Data[types.AWSEndPoint])
 	credentialSecret[types.AWSCert] = string(secret.Data[types.AWSCert])
+	credentialSecret[types.AWSWebIdentityToken] = string(secret.Data[types.AWSWebIdentityToken])
 	credentialSecret[types.CIFSUsername] = string(secret.Data[types.CIFSUsername])
 	credentialSecret[types.CIFSPassword] = string(secret.Data[types.CIFSPassword])
 	credentialSecret[types.AZBlobAccountName] = string(secret.Data[types.AZBlobAccountName])
@@ -982,6 +997,20 @@ func (s *DataStore) GetVolume(name string) (*longhorn.Volume, error) {
 	return resultRO.DeepCopy(), nil
 }
 
+// CheckVolumeSnapshotQuota returns an error if the snapshots of the volume reach its snapshot max count or snapshot
+// max size, so no new snapshot can be taken until some snapshots are removed
+func (s *DataStore) CheckVolumeSnapshotQuota(volumeName string) error {
+	v, err := s.GetVolumeRO(volumeName)
+	if err != nil {
+		return err
+	}
+	condition := types.GetCondition(v.Status.Conditions, longhorn.VolumeConditionTypeSnapshotQuotaExceeded)
+	if condition.Status == longhorn.ConditionStatusTrue {
+		return fmt.Errorf("snapshot quota of volume %v is exceeded, please remove snapshots first: %v", volumeName, condition.Message)
+	}
+	return nil
+}
+
 // ListVolumesRO returns a list of all Volumes for the given namespace
 func (s *DataStore) ListVolumesRO() ([]*longhorn.Volume, error) {
 	return s.volumeLister.Volumes(s.namespace).List(labels.Everything())
@@ -1026,7 +1055,7 @@ func (s *DataStore) IsRegularRWXVolume(volumeName string) (bool, error) {
 		}
 		return false, err
 	}
-	return v.Spec.AccessMode == longhorn.AccessModeReadWriteMany && !v.Spec.Migratable, nil
+	return v.Spec.AccessMode == longhorn.AccessModeReadWriteMany && !v.Spec.Migratable && !util.IsRWXBlockVolume(v), nil
 }
 
 func MarshalLabelToVolumeRecurringJob(labels map[string]string) map[string]*longhorn.VolumeRecurringJob {
@@ -1161,6 +1190,30 @@ func (s *DataStore) ListVolumesFollowsGlobalSettingsRO(followedSettingNames map[
 	return itemMap, nil
 }
 
+// ListActiveCloneTargetVolumeNamesRO returns the sorted names of the volumes being cloned from the source volume
+func (s *DataStore) ListActiveCloneTargetVolumeNamesRO(sourceVolumeName string) ([]string, error) {
+	volumes, err := s.ListVolumesRO()
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, v := range volumes {
+		if IsActiveCloneTargetVolume(v) && types.GetVolumeName(v.Spec.DataSource) == sourceVolumeName {
+			names = append(names, v.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// IsActiveCloneTargetVolume returns true if the volume is the target volume of an on-going cloning process
+func IsActiveCloneTargetVolume(v *longhorn.Volume) bool {
+	isCloningDesired := types.IsDataFromVolume(v.Spec.DataSource)
+	isCloningCompletedOrFailed := v.Status.CloneStatus.State == longhorn.VolumeCloneStateCompleted ||
+		v.Status.CloneStatus.State == longhorn.VolumeCloneStateFailed
+	return isCloningDesired && !isCloningCompletedOrFailed
+}
+
 // ListVolumesByBackupVolumeRO returns an object contains all Volumes with the specified backup-volume label
 func (s *DataStore) ListVolumesByBackupVolumeRO(backupVolumeName string) (map[string]*longhorn.Volume, error) {
 	itemMap := make(map[string]*longhorn.Volume)
@@ -1787,6 +1840,11 @@ func (s *DataStore) getRunningReplicaInstanceManagerRO(r *longhorn.Replica) (im
 	return im, nil
 }
 
+// IsReplicaRebuilding returns true if the replica is created or reused for rebuilding, and is not healthy or failed yet.
+func IsReplicaRebuilding(r *longhorn.Replica) bool {
+	return r.Spec.RebuildRetryCount != 0 && r.Spec.HealthyAt == "" && r.Spec.FailedAt == ""
+}
+
 // IsReplicaRebuildingFailed returns true if the rebuilding replica failed not caused by network issues.
 func IsReplicaRebuildingFailed(reusableFailedReplica *longhorn.Replica) bool {
 	replicaRebuildFailedCondition := types.GetCondition(reusableFailedReplica.Status.Conditions, longhorn.ReplicaConditionTypeRebuildFailed)
@@ -2967,13 +3025,97 @@ func (s *DataStore) IsNodeDownOrDeletedOrMissingManager(name string) (bool, erro
 	cond := types.GetCondition(node.Status.Conditions, longhorn.NodeConditionTypeReady)
 	if cond.Status == longhorn.ConditionStatusFalse &&
 		(cond.Reason == string(longhorn.NodeConditionReasonKubernetesNodeGone) ||
-			cond.Reason == string(longhorn.NodeConditionReasonKubernetesNodeNotReady) ||
-			cond.Reason == string(longhorn.NodeConditionReasonManagerPodMissing)) {
+			cond.Reason == string(longhorn.NodeConditionReasonKubernetesNodeNotReady)) {
 		return true, nil
 	}
+	if cond.Status == longhorn.ConditionStatusFalse && cond.Reason == string(longhorn.NodeConditionReasonManagerPodMissing) {
+		return !s.isNodeManagerHandingOff(node), nil
+	}
 	return false, nil
 }
 
+// ListVolumeShardMembers returns the nodes with a running manager, which the detached volumes are sharded across by
+// consistent hashing of the volume names. It returns nil if volume reconciliation sharding is disabled.
+func (s *DataStore) ListVolumeShardMembers() ([]string, error) {
+	enabled, err := s.GetSettingAsBool(types.SettingNameVolumeReconciliationSharding)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	nodes, err := s.ListNodesRO()
+	if err != nil {
+		return nil, err
+	}
+	members := []string{}
+	for _, node := range nodes {
+		isUnavailable, err := s.IsNodeDownOrDeletedOrMissingManager(node.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !isUnavailable {
+			members = append(members, node.Name)
+		}
+	}
+	return members, nil
+}
+
+// SetNodeManagerHandoff records on the node that its manager is shutting down gracefully, so the managers of the
+// other nodes leave the resources of the node to the new manager of the node within the handoff grace period.
+// The node is read from and updated by the API server directly, since the informers may be already stopped.
+func (s *DataStore) SetNodeManagerHandoff(name string) error {
+	return s.updateNodeManagerHandoffAnnotation(name, s.clock.Now().UTC().Format(time.RFC3339))
+}
+
+// ClearNodeManagerHandoff removes the handoff of the node once its new manager is running
+func (s *DataStore) ClearNodeManagerHandoff(name string) error {
+	return s.updateNodeManagerHandoffAnnotation(name, "")
+}
+
+func (s *DataStore) updateNodeManagerHandoffAnnotation(name, handoffAt string) error {
+	node, err := s.lhClient.LonghornV1beta2().Nodes(s.namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	annotationKey := types.GetLonghornLabelKey(types.LonghornAnnotationManagerHandoffAt)
+	if node.Annotations[annotationKey] == handoffAt {
+		return nil
+	}
+	if handoffAt == "" {
+		delete(node.Annotations, annotationKey)
+	} else {
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[annotationKey] = handoffAt
+	}
+	_, err = s.lhClient.LonghornV1beta2().Nodes(s.namespace).Update(context.TODO(), node, metav1.UpdateOptions{})
+	return err
+}
+
+// isNodeManagerHandingOff returns true if the manager of the node has shut down gracefully within the handoff grace
+// period
+func (s *DataStore) isNodeManagerHandingOff(node *longhorn.Node) bool {
+	handoffAt := node.Annotations[types.GetLonghornLabelKey(types.LonghornAnnotationManagerHandoffAt)]
+	if handoffAt == "" {
+		return false
+	}
+	handoffTime, err := time.Parse(time.RFC3339, handoffAt)
+	if err != nil {
+		logrus.WithError(err).Warnf("Failed to parse the manager handoff time %v of node %v", handoffAt, node.Name)
+		return false
+	}
+	gracePeriod, err := s.GetSettingAsInt(types.SettingNameManagerHandoffGracePeriod)
+	if err != nil {
+		logrus.WithError(err).Warnf("Failed to get %v setting", types.SettingNameManagerHandoffGracePeriod)
+		return false
+	}
+	return s.clock.Since(handoffTime) < time.Duration(gracePeriod)*time.Second
+}
+
 // IsNodeDownOrDeleted gets Node for the given name and namespace and checks
 // if the Node condition is gone or not ready
 func (s *DataStore) IsNodeDownOrDeleted(name string) (bool, error) {
@@ -3495,6 +3637,44 @@ func (s *DataStore) GetSettingSystemManagedComponentsNodeSelector() (map[string]
 	return nodeSelector, nil
 }
 
+// GetSystemManagedComponentScheduling returns the priority class, the tolerations and the topology spread constraints
+// of the given group of the system managed components. The overrides of the group take precedence over the settings
+// priority class and taint toleration.
+func (s *DataStore) GetSystemManagedComponentScheduling(component types.SystemManagedComponent) (priorityClass string, tolerations []corev1.Toleration, topologySpreadConstraints []corev1.TopologySpreadConstraint, err error) {
+	priorityClassSetting, err := s.GetSettingWithAutoFillingRO(types.SettingNamePriorityClass)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	priorityClass = priorityClassSetting.Value
+
+	tolerations, err = s.GetSettingTaintToleration()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	overridesSetting, err := s.GetSettingWithAutoFillingRO(types.SettingNameSystemManagedComponentsSchedulingOverrides)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	overrides, err := types.UnmarshalSystemManagedComponentsSchedulingOverrides(overridesSetting.Value)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	scheduling, ok := overrides[component]
+	if !ok {
+		return priorityClass, tolerations, nil, nil
+	}
+	if scheduling.PriorityClass != "" {
+		priorityClass = scheduling.PriorityClass
+	}
+	if scheduling.TaintToleration != "" {
+		if tolerations, err = types.UnmarshalTolerations(scheduling.TaintToleration); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	return priorityClass, tolerations, scheduling.TopologySpreadConstraints, nil
+}
+
 // ResetMonitoringEngineStatus clean and update Engine status
 func (s *DataStore) ResetMonitoringEngineStatus(e *longhorn.Engine) (*longhorn.Engine, error) {
 	e.Status.Endpoint = ""
@@ -4990,6 +5170,152 @@ func (s *DataStore) DeleteRecurringJob(name string) error {
 	)
 }
 
+// ListMaintenancePoliciesRO returns a map of read-only MaintenancePolicies indexed by name
+func (s *DataStore) ListMaintenancePoliciesRO() (map[string]*longhorn.MaintenancePolicy, error) {
+	itemMap := map[string]*longhorn.MaintenancePolicy{}
+
+	list, err := s.maintenancePolicyLister.MaintenancePolicies(s.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, itemRO := range list {
+		itemMap[itemRO.Name] = itemRO
+	}
+	return itemMap, nil
+}
+
+func (s *DataStore) GetMaintenancePolicyRO(name string) (*longhorn.MaintenancePolicy, error) {
+	return s.maintenancePolicyLister.MaintenancePolicies(s.namespace).Get(name)
+}
+
+// CreateMaintenancePolicy creates a Longhorn MaintenancePolicy resource and verifies creation
+func (s *DataStore) CreateMaintenancePolicy(policy *longhorn.MaintenancePolicy) (*longhorn.MaintenancePolicy, error) {
+	ret, err := s.lhClient.LonghornV1beta2().MaintenancePolicies(s.namespace).Create(context.TODO(), policy, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if SkipListerCheck {
+		return ret, nil
+	}
+
+	obj, err := verifyCreation(ret.Name, "maintenance policy", func(name string) (k8sruntime.Object, error) {
+		return s.GetMaintenancePolicyRO(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret, ok := obj.(*longhorn.MaintenancePolicy)
+	if !ok {
+		return nil, fmt.Errorf("BUG: datastore: verifyCreation returned wrong type for maintenance policy")
+	}
+
+	return ret.DeepCopy(), nil
+}
+
+// UpdateMaintenancePolicy updates Longhorn MaintenancePolicy resource and verifies update
+func (s *DataStore) UpdateMaintenancePolicy(policy *longhorn.MaintenancePolicy) (*longhorn.MaintenancePolicy, error) {
+	obj, err := s.lhClient.LonghornV1beta2().MaintenancePolicies(s.namespace).Update(context.TODO(), policy, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	verifyUpdate(policy.Name, obj, func(name string) (k8sruntime.Object, error) {
+		return s.GetMaintenancePolicyRO(name)
+	})
+	return obj, nil
+}
+
+// UpdateMaintenancePolicyStatus updates Longhorn MaintenancePolicy resource status and
+// verifies update
+func (s *DataStore) UpdateMaintenancePolicyStatus(policy *longhorn.MaintenancePolicy) (*longhorn.MaintenancePolicy, error) {
+	obj, err := s.lhClient.LonghornV1beta2().MaintenancePolicies(s.namespace).UpdateStatus(context.TODO(), policy, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	verifyUpdate(policy.Name, obj, func(name string) (k8sruntime.Object, error) {
+		return s.GetMaintenancePolicyRO(name)
+	})
+	return obj, nil
+}
+
+// DeleteMaintenancePolicy deletes the MaintenancePolicy resource with the given name
+func (s *DataStore) DeleteMaintenancePolicy(name string) error {
+	return s.lhClient.LonghornV1beta2().MaintenancePolicies(s.namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+// ListEngineUpgradePoliciesRO returns a map of read-only EngineUpgradePolicies indexed by name
+func (s *DataStore) ListEngineUpgradePoliciesRO() (map[string]*longhorn.EngineUpgradePolicy, error) {
+	itemMap := map[string]*longhorn.EngineUpgradePolicy{}
+
+	list, err := s.engineUpgradePolicyLister.EngineUpgradePolicies(s.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, itemRO := range list {
+		itemMap[itemRO.Name] = itemRO
+	}
+	return itemMap, nil
+}
+
+func (s *DataStore) GetEngineUpgradePolicyRO(name string) (*longhorn.EngineUpgradePolicy, error) {
+	return s.engineUpgradePolicyLister.EngineUpgradePolicies(s.namespace).Get(name)
+}
+
+// CreateEngineUpgradePolicy creates a Longhorn EngineUpgradePolicy resource and verifies creation
+func (s *DataStore) CreateEngineUpgradePolicy(policy *longhorn.EngineUpgradePolicy) (*longhorn.EngineUpgradePolicy, error) {
+	ret, err := s.lhClient.LonghornV1beta2().EngineUpgradePolicies(s.namespace).Create(context.TODO(), policy, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if SkipListerCheck {
+		return ret, nil
+	}
+
+	obj, err := verifyCreation(ret.Name, "engine upgrade policy", func(name string) (k8sruntime.Object, error) {
+		return s.GetEngineUpgradePolicyRO(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret, ok := obj.(*longhorn.EngineUpgradePolicy)
+	if !ok {
+		return nil, fmt.Errorf("BUG: datastore: verifyCreation returned wrong type for engine upgrade policy")
+	}
+
+	return ret.DeepCopy(), nil
+}
+
+// UpdateEngineUpgradePolicy updates Longhorn EngineUpgradePolicy resource and verifies update
+func (s *DataStore) UpdateEngineUpgradePolicy(policy *longhorn.EngineUpgradePolicy) (*longhorn.EngineUpgradePolicy, error) {
+	obj, err := s.lhClient.LonghornV1beta2().EngineUpgradePolicies(s.namespace).Update(context.TODO(), policy, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	verifyUpdate(policy.Name, obj, func(name string) (k8sruntime.Object, error) {
+		return s.GetEngineUpgradePolicyRO(name)
+	})
+	return obj, nil
+}
+
+// UpdateEngineUpgradePolicyStatus updates Longhorn EngineUpgradePolicy resource status and
+// verifies update
+func (s *DataStore) UpdateEngineUpgradePolicyStatus(policy *longhorn.EngineUpgradePolicy) (*longhorn.EngineUpgradePolicy, error) {
+	obj, err := s.lhClient.LonghornV1beta2().EngineUpgradePolicies(s.namespace).UpdateStatus(context.TODO(), policy, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	verifyUpdate(policy.Name, obj, func(name string) (k8sruntime.Object, error) {
+		return s.GetEngineUpgradePolicyRO(name)
+	})
+	return obj, nil
+}
+
+// DeleteEngineUpgradePolicy deletes the EngineUpgradePolicy resource with the given name
+func (s *DataStore) DeleteEngineUpgradePolicy(name string) error {
+	return s.lhClient.LonghornV1beta2().EngineUpgradePolicies(s.namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
 func ValidateRecurringJob(job longhorn.RecurringJobSpec) error {
 	if job.Cron == "" || job.Task == "" || job.Name == "" {
 		return fmt.Errorf("invalid job %+v", job)
@@ -5032,6 +5358,18 @@ func ValidateRecurringJobParameters(task longhorn.RecurringJobType, parameters m
 				return errors.Wrapf(err, "failed to validate recurring job backup task parameters")
 			}
 		}
+	case longhorn.RecurringJobTypeBackupBackingImage:
+		for key, value := range parameters {
+			if err := validateRecurringJobBackupBackingImageParameter(key, value); err != nil {
+				return errors.Wrapf(err, "failed to validate recurring job backing image backup task parameters")
+			}
+		}
+	case longhorn.RecurringJobTypeMaintenance:
+		for key, value := range parameters {
+			if err := validateRecurringJobMaintenanceParameter(key, value); err != nil {
+				return errors.Wrapf(err, "failed to validate recurring job maintenance task parameters")
+			}
+		}
 	// we don't support any parameters for other tasks currently
 	default:
 		return nil
@@ -5064,6 +5402,36 @@ func validateRecurringJobBackupParameter(key, value string) error {
 	return nil
 }
 
+func validateRecurringJobBackupBackingImageParameter(key, value string) error {
+	switch key {
+	case types.RecurringJobParameterBackupTarget:
+		if value == "" {
+			return fmt.Errorf("%v cannot be empty", key)
+		}
+	default:
+		return fmt.Errorf("%v:%v is not a valid parameter", key, value)
+	}
+
+	return nil
+}
+
+func validateRecurringJobMaintenanceParameter(key, value string) error {
+	switch key {
+	case types.RecurringJobParameterMaintenanceWindow:
+		window, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.Wrapf(err, "%v:%v is not number", key, value)
+		}
+		if window <= 0 {
+			return fmt.Errorf("%v:%v must be a positive number of minutes", key, value)
+		}
+	default:
+		return fmt.Errorf("%v:%v is not a valid parameter", key, value)
+	}
+
+	return nil
+}
+
 func isValidRecurringJobTask(task longhorn.RecurringJobType) bool {
 	return task == longhorn.RecurringJobTypeBackup ||
 		task == longhorn.RecurringJobTypeBackupForceCreate ||
@@ -5072,7 +5440,9 @@ func isValidRecurringJobTask(task longhorn.RecurringJobType) bool {
 		task == longhorn.RecurringJobTypeSnapshotForceCreate ||
 		task == longhorn.RecurringJobTypeSnapshotCleanup ||
 		task == longhorn.RecurringJobTypeSnapshotDelete ||
-		task == longhorn.RecurringJobTypeSystemBackup
+		task == longhorn.RecurringJobTypeSystemBackup ||
+		task == longhorn.RecurringJobTypeBackupBackingImage ||
+		task == longhorn.RecurringJobTypeMaintenance
 }
 
 // ValidateRecurringJobs validates data and formats for recurring jobs
@@ -5227,6 +5597,20 @@ func (s *DataStore) ListOrphansByNodeRO(name string) ([]*longhorn.Orphan, error)
 	return s.orphanLister.Orphans(s.namespace).List(nodeSelector)
 }
 
+// ListOrphansByInstanceManagerRO returns a list of all orphaned instances of the instance manager imName for the given namespace,
+// the list contains direct references to the internal cache objects and should not be mutated.
+func (s *DataStore) ListOrphansByInstanceManagerRO(imName string) ([]*longhorn.Orphan, error) {
+	imSelector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			types.GetLonghornLabelKey(types.LonghornLabelInstanceManager): imName,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.orphanLister.Orphans(s.namespace).List(imSelector)
+}
+
 // DeleteOrphan won't result in immediately deletion since finalizer was set by default
 func (s *DataStore) DeleteOrphan(orphanName string) error {
 	return s.lhClient.LonghornV1beta2().Orphans(s.namespace).Delete(context.TODO(), orphanName, metav1.DeleteOptions{})
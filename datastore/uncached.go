@@ -359,6 +359,20 @@ func (s *DataStore) GetAllLonghornRecurringJobs() (runtime.Object, error) {
 	return s.lhClient.LonghornV1beta2().RecurringJobs(s.namespace).List(context.TODO(), metav1.ListOptions{})
 }
 
+// GetAllLonghornMaintenancePolicies returns an uncached list of MaintenancePolicies in
+// Longhorn namespace directly from the API server.
+// Direct retrieval from the API server should only be used for one-shot tasks.
+func (s *DataStore) GetAllLonghornMaintenancePolicies() (runtime.Object, error) {
+	return s.lhClient.LonghornV1beta2().MaintenancePolicies(s.namespace).List(context.TODO(), metav1.ListOptions{})
+}
+
+// GetAllLonghornEngineUpgradePolicies returns an uncached list of EngineUpgradePolicies in
+// Longhorn namespace directly from the API server.
+// Direct retrieval from the API server should only be used for one-shot tasks.
+func (s *DataStore) GetAllLonghornEngineUpgradePolicies() (runtime.Object, error) {
+	return s.lhClient.LonghornV1beta2().EngineUpgradePolicies(s.namespace).List(context.TODO(), metav1.ListOptions{})
+}
+
 // GetAllLonghornCustomResourceDefinitions returns an uncached list of Longhorn grouped
 // CustomResourceDefinitions directly from the API server.
 // Direct retrieval from the API server should only be used for one-shot tasks.
@@ -117,6 +117,11 @@ func NewBackupMonitor(logger logrus.FieldLogger, ds *datastore.DataStore, backup
 
 		m.backupStatus = backup.Status
 		m.replicaAddress = replicaAddress
+	} else {
+		// Adopt the backup in progress, e.g. started by the previous manager pod before it was restarted, from the
+		// status recorded in the backup. Otherwise the backup status would be reset until the first sync.
+		m.backupStatus = backup.Status
+		m.replicaAddress = backup.Status.ReplicaAddress
 	}
 
 	// Create a goroutine to monitor the replica backup state/progress
@@ -3,6 +3,7 @@ package engineapi
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"sort"
 	"time"
@@ -118,6 +119,14 @@ func getBackupCredentialEnv(backupTarget string, credential map[string]string) (
 		envs = append(envs, fmt.Sprintf("%s=%s", types.HTTPProxy, credential[types.HTTPProxy]))
 		envs = append(envs, fmt.Sprintf("%s=%s", types.NOProxy, credential[types.NOProxy]))
 		envs = append(envs, fmt.Sprintf("%s=%s", types.VirtualHostedStyle, credential[types.VirtualHostedStyle]))
+		// The AWS SDK exchanges the web identity token read from the file for the credential of the IAM role
+		if credential[types.AWSWebIdentityTokenFile] != "" {
+			if credential[types.AWSIAMRoleArn] == "" {
+				return nil, fmt.Errorf("could not backup to %s, missing %v for the web identity token in the secret", backupType, types.AWSIAMRoleArn)
+			}
+			envs = append(envs, fmt.Sprintf("%s=%s", types.AWSRoleArn, credential[types.AWSIAMRoleArn]))
+			envs = append(envs, fmt.Sprintf("%s=%s", types.AWSWebIdentityTokenFile, credential[types.AWSWebIdentityTokenFile]))
+		}
 	case types.BackupStoreTypeCIFS:
 		envs = append(envs, fmt.Sprintf("%s=%s", types.CIFSUsername, credential[types.CIFSUsername]))
 		envs = append(envs, fmt.Sprintf("%s=%s", types.CIFSPassword, credential[types.CIFSPassword]))
@@ -133,6 +142,44 @@ func getBackupCredentialEnv(backupTarget string, credential map[string]string) (
 	return envs, nil
 }
 
+// PrepareWebIdentityTokenFile writes the web identity token of the credential to the file of the given name on the
+// host, and replaces the token with the path of the file in the instance manager pods, since the AWS SDK only reads
+// the token from a file.
+func PrepareWebIdentityTokenFile(credential map[string]string, name string) error {
+	return prepareWebIdentityTokenFile(credential, types.WebIdentityTokenDirectoryOnHost, types.WebIdentityTokenDirectoryInContainer, name)
+}
+
+func prepareWebIdentityTokenFile(credential map[string]string, hostDirectory, containerDirectory, name string) error {
+	token := credential[types.AWSWebIdentityToken]
+	delete(credential, types.AWSWebIdentityToken)
+	if token == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(hostDirectory, 0700); err != nil {
+		return errors.Wrapf(err, "failed to create web identity token directory %v", hostDirectory)
+	}
+	// Replace the file at once, so the token is never read half written
+	path := filepath.Join(hostDirectory, name)
+	if err := os.WriteFile(path+".tmp", []byte(token), 0600); err != nil {
+		return errors.Wrapf(err, "failed to write web identity token file %v", path)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return errors.Wrapf(err, "failed to write web identity token file %v", path)
+	}
+	credential[types.AWSWebIdentityTokenFile] = filepath.Join(containerDirectory, name)
+	return nil
+}
+
+// RemoveWebIdentityTokenFile removes the web identity token file of the given name from the host
+func RemoveWebIdentityTokenFile(name string) error {
+	path := filepath.Join(types.WebIdentityTokenDirectoryOnHost, name)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove web identity token file %v", path)
+	}
+	return nil
+}
+
 func (btc *BackupTargetClient) ExecuteEngineBinary(args ...string) (string, error) {
 	envs, err := getBackupCredentialEnv(btc.URL, btc.Credential)
 	if err != nil {
@@ -1,12 +1,16 @@
 package engineapi
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/longhorn/longhorn-manager/types"
+
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
 
@@ -147,6 +151,53 @@ func TestGetBackupCredentialEnv(t *testing.T) {
 	}
 }
 
+func TestGetBackupCredentialEnvWithWebIdentityTokenFile(t *testing.T) {
+	assert := require.New(t)
+
+	envs, err := getBackupCredentialEnv("s3://backupbucket@us-east-1/", map[string]string{
+		"AWS_IAM_ROLE_ARN":            "arn:aws:iam::013456789:role/tenant",
+		"AWS_WEB_IDENTITY_TOKEN_FILE": "/host/var/lib/longhorn/web-identity-tokens/e-1",
+	})
+	assert.Nil(err)
+	assert.Contains(envs, "AWS_ROLE_ARN=arn:aws:iam::013456789:role/tenant")
+	assert.Contains(envs, "AWS_WEB_IDENTITY_TOKEN_FILE=/host/var/lib/longhorn/web-identity-tokens/e-1")
+	for _, env := range envs {
+		assert.False(strings.HasPrefix(env, "AWS_WEB_IDENTITY_TOKEN="))
+	}
+
+	_, err = getBackupCredentialEnv("s3://backupbucket@us-east-1/", map[string]string{
+		"AWS_ACCESS_KEY_ID":           "my-aws-access-key-id",
+		"AWS_SECRET_ACCESS_KEY":       "my-aws-secret-access-key",
+		"AWS_WEB_IDENTITY_TOKEN_FILE": "/host/var/lib/longhorn/web-identity-tokens/e-1",
+	})
+	assert.NotNil(err)
+}
+
+func TestPrepareWebIdentityTokenFile(t *testing.T) {
+	assert := require.New(t)
+
+	hostDirectory := filepath.Join(t.TempDir(), "web-identity-tokens")
+	credential := map[string]string{
+		types.AWSIAMRoleArn:       "arn:aws:iam::013456789:role/tenant",
+		types.AWSWebIdentityToken: "token",
+	}
+	assert.Nil(prepareWebIdentityTokenFile(credential, hostDirectory, "/host/web-identity-tokens", "e-1"))
+	assert.Equal(map[string]string{
+		types.AWSIAMRoleArn:           "arn:aws:iam::013456789:role/tenant",
+		types.AWSWebIdentityTokenFile: "/host/web-identity-tokens/e-1",
+	}, credential)
+	token, err := os.ReadFile(filepath.Join(hostDirectory, "e-1"))
+	assert.Nil(err)
+	assert.Equal("token", string(token))
+
+	// The credential without a token is left without a token file
+	credential = map[string]string{types.AWSAccessKey: "key", types.AWSWebIdentityToken: ""}
+	assert.Nil(prepareWebIdentityTokenFile(credential, hostDirectory, "/host/web-identity-tokens", "e-2"))
+	assert.Equal(map[string]string{types.AWSAccessKey: "key"}, credential)
+	_, err = os.Stat(filepath.Join(hostDirectory, "e-2"))
+	assert.True(os.IsNotExist(err))
+}
+
 func TestParseBackupVolumeNamesList(t *testing.T) {
 	assert := require.New(t)
 
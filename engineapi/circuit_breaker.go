@@ -0,0 +1,170 @@
+package engineapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// InstanceManagerCircuitBreakerFailureThreshold is the number of the consecutive timeouts of the calls to an
+	// instance manager, at which the circuit breaker of the instance manager opens
+	InstanceManagerCircuitBreakerFailureThreshold = 3
+
+	// The backoff period the calls are short-circuited for once the circuit breaker opens, which is doubled every time
+	// the trial call after the backoff times out again
+	instanceManagerCircuitBreakerInitialBackoff = 10 * time.Second
+	instanceManagerCircuitBreakerMaxBackoff     = 5 * time.Minute
+)
+
+// InstanceManagerCircuitBreakerState is the state of the circuit breaker of an instance manager
+type InstanceManagerCircuitBreakerState struct {
+	// Open is true if the calls to the instance manager are short-circuited until OpenUntil
+	Open      bool
+	OpenUntil time.Time
+	// The consecutive timeouts and the last one of the calls to the instance manager
+	Failures  int
+	LastError string
+}
+
+type instanceManagerCircuitBreaker struct {
+	ip        string
+	failures  int
+	backoff   time.Duration
+	openUntil time.Time
+	lastError string
+}
+
+// The circuit breakers are shared by all the controllers in the process, so an unresponsive instance manager doesn't
+// make every controller time out independently
+var (
+	instanceManagerCircuitBreakersLock sync.Mutex
+	instanceManagerCircuitBreakers     = map[string]*instanceManagerCircuitBreaker{}
+
+	circuitBreakerNow = time.Now
+)
+
+// getInstanceManagerCircuitBreaker returns the circuit breaker of the instance manager. The circuit breaker is reset
+// once the IP of the instance manager changes, since the instance manager pod has been recreated.
+func getInstanceManagerCircuitBreaker(name, ip string) *instanceManagerCircuitBreaker {
+	cb, ok := instanceManagerCircuitBreakers[name]
+	if !ok || cb.ip != ip {
+		cb = &instanceManagerCircuitBreaker{ip: ip}
+		instanceManagerCircuitBreakers[name] = cb
+	}
+	return cb
+}
+
+// checkInstanceManagerCircuitBreaker returns an error if the calls to the instance manager are short-circuited
+func checkInstanceManagerCircuitBreaker(name, ip string) error {
+	instanceManagerCircuitBreakersLock.Lock()
+	defer instanceManagerCircuitBreakersLock.Unlock()
+
+	cb := getInstanceManagerCircuitBreaker(name, ip)
+	if circuitBreakerNow().Before(cb.openUntil) {
+		return &InstanceManagerCircuitOpenError{
+			InstanceManagerName: name,
+			OpenUntil:           cb.openUntil,
+			LastError:           cb.lastError,
+		}
+	}
+	return nil
+}
+
+// recordInstanceManagerCallResult records the result of a call to the instance manager. The circuit breaker opens
+// after the consecutive timeouts reach the threshold, and closes once a call doesn't time out. It must only be called
+// for the connection setup and the calls the instance manager serves by itself, like the version get and the instance
+// list, since the errors of the proxied calls come from the engines and don't tell whether the instance manager hangs.
+func recordInstanceManagerCallResult(name, ip string, err error) {
+	if IsInstanceManagerCircuitOpenError(err) {
+		return
+	}
+
+	instanceManagerCircuitBreakersLock.Lock()
+	defer instanceManagerCircuitBreakersLock.Unlock()
+
+	cb := getInstanceManagerCircuitBreaker(name, ip)
+	if !isInstanceManagerTimeoutError(err) {
+		if cb.failures >= InstanceManagerCircuitBreakerFailureThreshold {
+			logrus.Infof("Closing circuit breaker of instance manager %v since it's responsive again", name)
+		}
+		cb.failures = 0
+		cb.backoff = 0
+		cb.openUntil = time.Time{}
+		cb.lastError = ""
+		return
+	}
+
+	cb.failures++
+	cb.lastError = err.Error()
+	if cb.failures < InstanceManagerCircuitBreakerFailureThreshold {
+		return
+	}
+	if cb.backoff == 0 {
+		cb.backoff = instanceManagerCircuitBreakerInitialBackoff
+	} else {
+		cb.backoff *= 2
+		if cb.backoff > instanceManagerCircuitBreakerMaxBackoff {
+			cb.backoff = instanceManagerCircuitBreakerMaxBackoff
+		}
+	}
+	cb.openUntil = circuitBreakerNow().Add(cb.backoff)
+	logrus.WithError(err).Warnf("Opening circuit breaker of instance manager %v for %v after %v consecutive timeouts",
+		name, cb.backoff, cb.failures)
+}
+
+// GetInstanceManagerCircuitBreakerState returns the state of the circuit breaker of the instance manager
+func GetInstanceManagerCircuitBreakerState(name, ip string) InstanceManagerCircuitBreakerState {
+	instanceManagerCircuitBreakersLock.Lock()
+	defer instanceManagerCircuitBreakersLock.Unlock()
+
+	cb := getInstanceManagerCircuitBreaker(name, ip)
+	return InstanceManagerCircuitBreakerState{
+		Open:      circuitBreakerNow().Before(cb.openUntil),
+		OpenUntil: cb.openUntil,
+		Failures:  cb.failures,
+		LastError: cb.lastError,
+	}
+}
+
+// InstanceManagerCircuitOpenError is returned instead of calling the instance manager while its circuit breaker is open
+type InstanceManagerCircuitOpenError struct {
+	InstanceManagerName string
+	OpenUntil           time.Time
+	LastError           string
+}
+
+func (e *InstanceManagerCircuitOpenError) Error() string {
+	return fmt.Sprintf("instance manager %v is degraded, calls are short-circuited until %v after repeated timeouts: %v",
+		e.InstanceManagerName, e.OpenUntil.UTC().Format(time.RFC3339), e.LastError)
+}
+
+// IsInstanceManagerCircuitOpenError returns true if the error is caused by the open circuit breaker of an instance
+// manager
+func IsInstanceManagerCircuitOpenError(err error) bool {
+	var circuitOpenErr *InstanceManagerCircuitOpenError
+	return errors.As(err, &circuitOpenErr)
+}
+
+// isInstanceManagerTimeoutError returns true if the call to the instance manager itself timed out. The results are
+// only recorded for the connection setup and the calls served by the instance manager, so the errors forwarded from the
+// engines through the proxy never reach here. Unavailable isn't counted, since the instance manager refuses the
+// connections while its pod is starting, which is not a hang.
+func isInstanceManagerTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if s, ok := status.FromError(errors.Cause(err)); ok {
+		return s.Code() == codes.DeadlineExceeded
+	}
+	return false
+}
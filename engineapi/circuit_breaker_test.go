@@ -0,0 +1,83 @@
+package engineapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+func TestInstanceManagerCircuitBreaker(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	circuitBreakerNow = func() time.Time { return now }
+	defer func() { circuitBreakerNow = time.Now }()
+
+	name, ip := "instance-manager-circuit-breaker-test", "10.0.0.1"
+	timeoutErr := errors.Wrap(status.Error(codes.DeadlineExceeded, "context deadline exceeded"), "failed to list instances")
+
+	// The non-timeout errors don't open the circuit breaker, including the refused connections of a starting pod
+	for i := 0; i < InstanceManagerCircuitBreakerFailureThreshold; i++ {
+		recordInstanceManagerCallResult(name, ip, fmt.Errorf("instance not found"))
+		recordInstanceManagerCallResult(name, ip, status.Error(codes.Unavailable, "connection refused"))
+		recordInstanceManagerCallResult(name, ip, fmt.Errorf("failed to list instances: context deadline exceeded"))
+	}
+	assert.NoError(checkInstanceManagerCircuitBreaker(name, ip))
+
+	// The consecutive timeouts open the circuit breaker
+	for i := 0; i < InstanceManagerCircuitBreakerFailureThreshold-1; i++ {
+		recordInstanceManagerCallResult(name, ip, timeoutErr)
+	}
+	assert.NoError(checkInstanceManagerCircuitBreaker(name, ip))
+	recordInstanceManagerCallResult(name, ip, context.DeadlineExceeded)
+	err := checkInstanceManagerCircuitBreaker(name, ip)
+	assert.True(IsInstanceManagerCircuitOpenError(errors.Wrap(err, "failed to get instance manager client")))
+	state := GetInstanceManagerCircuitBreakerState(name, ip)
+	assert.True(state.Open)
+	assert.Equal(now.Add(instanceManagerCircuitBreakerInitialBackoff), state.OpenUntil)
+
+	// The trial call after the backoff times out again, and the backoff is doubled
+	now = now.Add(instanceManagerCircuitBreakerInitialBackoff)
+	assert.NoError(checkInstanceManagerCircuitBreaker(name, ip))
+	recordInstanceManagerCallResult(name, ip, timeoutErr)
+	assert.Equal(now.Add(2*instanceManagerCircuitBreakerInitialBackoff), GetInstanceManagerCircuitBreakerState(name, ip).OpenUntil)
+
+	// The circuit breaker is reset for the recreated instance manager pod
+	assert.NoError(checkInstanceManagerCircuitBreaker(name, "10.0.0.2"))
+	assert.False(GetInstanceManagerCircuitBreakerState(name, "10.0.0.2").Open)
+
+	// The circuit breaker closes once a call succeeds
+	recordInstanceManagerCallResult(name, "10.0.0.2", timeoutErr)
+	recordInstanceManagerCallResult(name, "10.0.0.2", nil)
+	state = GetInstanceManagerCircuitBreakerState(name, "10.0.0.2")
+	assert.False(state.Open)
+	assert.Equal(0, state.Failures)
+}
+
+func TestInstanceManagerCircuitBreakerShortCircuitsCalls(t *testing.T) {
+	assert := require.New(t)
+
+	name, ip := "instance-manager-short-circuit-test", "10.0.0.3"
+	for i := 0; i < InstanceManagerCircuitBreakerFailureThreshold; i++ {
+		recordInstanceManagerCallResult(name, ip, context.DeadlineExceeded)
+	}
+
+	// The calls are short-circuited before the gRPC clients are used
+	proxy := &Proxy{imName: name, imIP: ip}
+	assert.True(IsInstanceManagerCircuitOpenError(proxy.VolumeExpand(&longhorn.Engine{})))
+	_, err := proxy.SnapshotList(&longhorn.Engine{})
+	assert.True(IsInstanceManagerCircuitOpenError(err))
+
+	client := &InstanceManagerClient{name: name, ip: ip, apiMinVersion: MinInstanceManagerAPIVersion, apiVersion: CurrentInstanceManagerAPIVersion}
+	assert.True(IsInstanceManagerCircuitOpenError(client.InstanceDelete(longhorn.DataEngineTypeV1, "engine-1", "engine", "", false)))
+	_, _, _, _, err = client.VersionGet()
+	assert.True(IsInstanceManagerCircuitOpenError(err))
+}
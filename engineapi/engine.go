@@ -360,6 +360,10 @@ func (e *EngineBinary) RemountReadOnlyVolume(*longhorn.Engine) error {
 	return errors.New(ErrNotImplement)
 }
 
+func (e *EngineBinary) ReplicaInfoGet(engine *longhorn.Engine, replicaName, replicaAddress string) (*ReplicaInfo, error) {
+	return nil, errors.New(ErrNotImplement)
+}
+
 // addFlags always adds required flags to args. In addition, if the engine version is high enough, it adds additional
 // engine identity validation flags.
 func (e *EngineBinary) addFlags(args []string) ([]string, error) {
@@ -287,6 +287,10 @@ func (e *EngineSimulator) RemountReadOnlyVolume(*longhorn.Engine) error {
 	return errors.New(ErrNotImplement)
 }
 
+func (e *EngineSimulator) ReplicaInfoGet(engine *longhorn.Engine, replicaName, replicaAddress string) (*ReplicaInfo, error) {
+	return nil, errors.New(ErrNotImplement)
+}
+
 func (e *EngineSimulator) SPDKBackingImageCreate(name, backingImageUUID, diskUUID, checksum, fromAddress, srcDiskUUID string, size uint64) (*imapi.BackingImage, error) {
 	return nil, errors.New(ErrNotImplement)
 }
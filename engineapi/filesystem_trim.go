@@ -0,0 +1,81 @@
+package engineapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	// FilesystemTrimTimeout is the longest time a filesystem trim of a volume is waited for
+	FilesystemTrimTimeout = time.Hour
+)
+
+// TrimVolumeFilesystem trims the filesystem of the attached volume. The filesystem of the RWX volume is trimmed
+// through its share manager, and the one of the other volumes on the host of the node the volume is attached to, so
+// it must be called on that node. The trim is given up once the context is done.
+func TrimVolumeFilesystem(ctx context.Context, ds *datastore.DataStore, v *longhorn.Volume) error {
+	timeout := FilesystemTrimTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if timeout <= 0 {
+		return errors.Wrapf(context.DeadlineExceeded, "failed to trim filesystem for volume %v", v.Name)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if v.Spec.AccessMode == longhorn.AccessModeReadWriteMany {
+			errCh <- trimRWXVolumeFilesystem(ds, v.Name, v.Spec.Encrypted)
+			return
+		}
+		errCh <- util.TrimFilesystem(v.Name, v.Spec.Encrypted, timeout)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "failed to trim filesystem for volume %v", v.Name)
+	}
+}
+
+func trimRWXVolumeFilesystem(ds *datastore.DataStore, volumeName string, encryptedDevice bool) error {
+	sm, err := ds.GetShareManager(volumeName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get share manager for trimming volume %v", volumeName)
+	}
+	pod, err := ds.GetPodRO(sm.Namespace, types.GetShareManagerPodNameFromShareManagerName(sm.Name))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get share manager pod for trimming volume %v in namespace", volumeName)
+	}
+	if pod == nil {
+		return fmt.Errorf("share manager pod is not found for trimming volume %v in namespace", volumeName)
+	}
+
+	if sm.Status.State != longhorn.ShareManagerStateRunning {
+		return fmt.Errorf("share manager %v is not running", sm.Name)
+	}
+
+	client, err := NewShareManagerClient(sm, pod)
+	if err != nil {
+		return errors.Wrapf(err, "failed to launch gRPC client for share manager before trimming volume %v", volumeName)
+	}
+	defer func(client io.Closer) {
+		if closeErr := client.Close(); closeErr != nil {
+			logrus.WithError(closeErr).Warn("Failed to close share manager client")
+		}
+	}(client)
+
+	return client.FilesystemTrim(encryptedDevice)
+}
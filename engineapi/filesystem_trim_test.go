@@ -0,0 +1,25 @@
+package engineapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+func TestTrimVolumeFilesystemDeadline(t *testing.T) {
+	assert := require.New(t)
+
+	v := &longhorn.Volume{}
+	v.Name = "trim-deadline-test"
+
+	// The trim isn't started once the deadline is reached
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	err := TrimVolumeFilesystem(ctx, nil, v)
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+}
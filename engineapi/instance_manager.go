@@ -44,6 +44,7 @@ const (
 )
 
 type InstanceManagerClient struct {
+	name          string
 	ip            string
 	apiMinVersion int
 	apiVersion    int
@@ -58,6 +59,16 @@ func (c *InstanceManagerClient) GetAPIVersion() int {
 	return c.apiVersion
 }
 
+// checkCircuitBreaker returns an error if the calls to the instance manager are short-circuited
+func (c *InstanceManagerClient) checkCircuitBreaker() error {
+	return checkInstanceManagerCircuitBreaker(c.name, c.ip)
+}
+
+// recordCallResult records the result of a call to the instance manager in its circuit breaker
+func (c *InstanceManagerClient) recordCallResult(err error) {
+	recordInstanceManagerCallResult(c.name, c.ip, err)
+}
+
 func (c *InstanceManagerClient) Close() error {
 	var err error
 
@@ -110,6 +121,15 @@ func NewInstanceManagerClient(im *longhorn.InstanceManager, allowUnknown bool) (
 		return nil, fmt.Errorf("invalid instance manager %v, state %v, IP %v", im.Name, im.Status.CurrentState, im.Status.IP)
 	}
 
+	if err := checkInstanceManagerCircuitBreaker(im.Name, im.Status.IP); err != nil {
+		return nil, err
+	}
+	c, err := newInstanceManagerClient(im)
+	recordInstanceManagerCallResult(im.Name, im.Status.IP, err)
+	return c, err
+}
+
+func newInstanceManagerClient(im *longhorn.InstanceManager) (*InstanceManagerClient, error) {
 	// TODO: Initialize the following gRPC clients are similar. This can be simplified via factory method.
 
 	initProcessManagerTLSClient := func(endpoint string) (processManagerClient *imclient.ProcessManagerClient, err error) {
@@ -218,6 +238,7 @@ func NewInstanceManagerClient(im *longhorn.InstanceManager, allowUnknown bool) (
 		}
 
 		return &InstanceManagerClient{
+			name:                     im.Name,
 			ip:                       im.Status.IP,
 			apiMinVersion:            im.Status.APIMinVersion,
 			apiVersion:               im.Status.APIVersion,
@@ -264,6 +285,7 @@ func NewInstanceManagerClient(im *longhorn.InstanceManager, allowUnknown bool) (
 	// This way we don't need the per call compatibility check, ref: `CheckInstanceManagerCompatibility`
 
 	return &InstanceManagerClient{
+		name:                      im.Name,
 		ip:                        im.Status.IP,
 		apiMinVersion:             im.Status.APIMinVersion,
 		apiVersion:                im.Status.APIVersion,
@@ -488,6 +510,9 @@ func (c *InstanceManagerClient) EngineInstanceCreate(req *EngineInstanceCreateRe
 		replicaAddresses = req.Engine.Status.CurrentReplicaAddressMap
 	}
 
+	if err := c.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	if c.GetAPIVersion() < 4 {
 		/* Fall back to the old way of creating engine process */
 		process, err := c.processManagerGrpcClient.ProcessCreate(req.Engine.Name, binary, DefaultEnginePortCount, args, []string{DefaultPortArg})
@@ -547,6 +572,9 @@ func (c *InstanceManagerClient) ReplicaInstanceCreate(req *ReplicaInstanceCreate
 		binary, args = getBinaryAndArgsForReplicaProcessCreation(req.Replica, req.DataPath, req.BackingImagePath, req.DataLocality, DefaultReplicaPortCountV1, req.EngineCLIAPIVersion)
 	}
 
+	if err := c.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	if c.GetAPIVersion() < 4 {
 		/* Fall back to the old way of creating replica process */
 		process, err := c.processManagerGrpcClient.ProcessCreate(req.Replica.Name, binary, DefaultReplicaPortCountV1, args, []string{DefaultPortArg})
@@ -588,6 +616,10 @@ func (c *InstanceManagerClient) ReplicaInstanceCreate(req *ReplicaInstanceCreate
 
 // InstanceDelete deletes the instance
 func (c *InstanceManagerClient) InstanceDelete(dataEngine longhorn.DataEngineType, name, kind, diskUUID string, cleanupRequired bool) (err error) {
+	if err := c.checkCircuitBreaker(); err != nil {
+		return err
+	}
+
 	if c.GetAPIVersion() < 4 {
 		/* Fall back to the old way of deleting process */
 		_, err = c.processManagerGrpcClient.ProcessDelete(name)
@@ -603,6 +635,9 @@ func (c *InstanceManagerClient) InstanceGet(dataEngine longhorn.DataEngineType,
 	if err := CheckInstanceManagerCompatibility(c.apiMinVersion, c.apiVersion); err != nil {
 		return nil, err
 	}
+	if err := c.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 
 	if c.GetAPIVersion() < 4 {
 		/* Fall back to the old way of getting process */
@@ -620,12 +655,48 @@ func (c *InstanceManagerClient) InstanceGet(dataEngine longhorn.DataEngineType,
 	return parseInstance(instance), nil
 }
 
+// InstanceSuspend suspends the IO of the instance
+func (c *InstanceManagerClient) InstanceSuspend(dataEngine longhorn.DataEngineType, name, kind string) error {
+	if err := CheckInstanceManagerCompatibility(c.apiMinVersion, c.apiVersion); err != nil {
+		return err
+	}
+
+	if c.GetAPIVersion() < 6 {
+		return fmt.Errorf("instance manager API version %v doesn't support suspending instance %v", c.GetAPIVersion(), name)
+	}
+
+	if err := c.checkCircuitBreaker(); err != nil {
+		return err
+	}
+	return c.instanceServiceGrpcClient.InstanceSuspend(string(dataEngine), name, kind)
+}
+
+// InstanceResume resumes the IO of the suspended instance
+func (c *InstanceManagerClient) InstanceResume(dataEngine longhorn.DataEngineType, name, kind string) error {
+	if err := CheckInstanceManagerCompatibility(c.apiMinVersion, c.apiVersion); err != nil {
+		return err
+	}
+
+	if c.GetAPIVersion() < 6 {
+		return fmt.Errorf("instance manager API version %v doesn't support resuming instance %v", c.GetAPIVersion(), name)
+	}
+
+	if err := c.checkCircuitBreaker(); err != nil {
+		return err
+	}
+	return c.instanceServiceGrpcClient.InstanceResume(string(dataEngine), name, kind)
+}
+
 // InstanceGetBinary returns the binary name of the instance
 func (c *InstanceManagerClient) InstanceGetBinary(dataEngine longhorn.DataEngineType, name, kind, diskUUID string) (string, error) {
 	if err := CheckInstanceManagerCompatibility(c.apiMinVersion, c.apiVersion); err != nil {
 		return "", err
 	}
 
+	if err := c.checkCircuitBreaker(); err != nil {
+		return "", err
+	}
+
 	if c.GetAPIVersion() < 4 {
 		/* Fall back to the old way of getting binary name */
 		process, err := c.processManagerGrpcClient.ProcessGet(name)
@@ -652,6 +723,10 @@ func (c *InstanceManagerClient) InstanceLog(ctx context.Context, dataEngine long
 		return nil, err
 	}
 
+	if err := c.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
+
 	if c.GetAPIVersion() < 4 {
 		/* Fall back to the old way of logging process */
 		return c.processManagerGrpcClient.ProcessLog(ctx, name)
@@ -666,6 +741,10 @@ func (c *InstanceManagerClient) InstanceWatch(ctx context.Context) (interface{},
 		return nil, err
 	}
 
+	if err := c.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
+
 	if c.GetAPIVersion() < 4 {
 		/* Fall back to the old way of creating replica process */
 		return c.processManagerGrpcClient.ProcessWatch(ctx)
@@ -680,11 +759,16 @@ func (c *InstanceManagerClient) InstanceList() (map[string]longhorn.InstanceProc
 		return nil, err
 	}
 
+	if err := c.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
+
 	result := map[string]longhorn.InstanceProcess{}
 
 	if c.GetAPIVersion() < 4 {
 		/* Fall back to the old way of listing processes */
 		processes, err := c.processManagerGrpcClient.ProcessList()
+		c.recordCallResult(err)
 		if err != nil {
 			return nil, err
 		}
@@ -696,6 +780,7 @@ func (c *InstanceManagerClient) InstanceList() (map[string]longhorn.InstanceProc
 	}
 
 	instances, err := c.instanceServiceGrpcClient.InstanceList()
+	c.recordCallResult(err)
 	if err != nil {
 		return nil, err
 	}
@@ -777,6 +862,10 @@ func (c *InstanceManagerClient) engineInstanceUpgrade(req *EngineInstanceUpgrade
 
 	binary := filepath.Join(types.GetEngineBinaryDirectoryForEngineManagerContainer(req.Engine.Spec.Image), types.EngineBinaryName)
 
+	if err := c.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
+
 	if c.GetAPIVersion() < 4 {
 		process, err := c.processManagerGrpcClient.ProcessReplace(
 			req.Engine.Name, binary, DefaultEnginePortCount, args, []string{DefaultPortArg}, DefaultTerminateSignal)
@@ -796,6 +885,10 @@ func (c *InstanceManagerClient) engineInstanceUpgrade(req *EngineInstanceUpgrade
 
 // VersionGet returns the version of the instance manager
 func (c *InstanceManagerClient) VersionGet() (int, int, int, int, error) {
+	if err := c.checkCircuitBreaker(); err != nil {
+		return 0, 0, 0, 0, err
+	}
+
 	var err error
 	var output *immeta.VersionOutput
 
@@ -805,6 +898,7 @@ func (c *InstanceManagerClient) VersionGet() (int, int, int, int, error) {
 	} else {
 		output, err = c.instanceServiceGrpcClient.VersionGet()
 	}
+	c.recordCallResult(err)
 	if err != nil {
 		return 0, 0, 0, 0, err
 	}
@@ -821,6 +915,9 @@ func (c *InstanceManagerClient) LogSetLevel(dataEngine longhorn.DataEngineType,
 		return nil
 	}
 
+	if err := c.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return c.instanceServiceGrpcClient.LogSetLevel(string(dataEngine), component, level)
 }
 
@@ -833,5 +930,8 @@ func (c *InstanceManagerClient) LogSetFlags(dataEngine longhorn.DataEngineType,
 		return nil
 	}
 
+	if err := c.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return c.instanceServiceGrpcClient.LogSetFlags(string(dataEngine), component, flags)
 }
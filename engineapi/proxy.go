@@ -1,6 +1,7 @@
 package engineapi
 
 import (
+	"crypto/tls"
 	"path/filepath"
 
 	"github.com/pkg/errors"
@@ -83,6 +84,14 @@ func NewEngineClientProxy(im *longhorn.InstanceManager, logger logrus.FieldLogge
 		return nil, err
 	}
 
+	if err = checkInstanceManagerCircuitBreaker(im.Name, im.Status.IP); err != nil {
+		return nil, err
+	}
+	defer func() {
+		recordInstanceManagerCallResult(im.Name, im.Status.IP, err)
+	}()
+
+	var tlsConfig *tls.Config
 	initProxyTLSClient := func(ip string) (proxyClient *imclient.ProxyClient, err error) {
 		defer func() {
 			if err != nil && proxyClient != nil {
@@ -95,18 +104,20 @@ func NewEngineClientProxy(im *longhorn.InstanceManager, logger logrus.FieldLogge
 
 		// check for tls cert file presence
 		ctx, cancel := context.WithCancel(context.Background())
-		proxyClient, err = imclient.NewProxyClientWithTLS(ctx,
-			cancel,
-			ip,
-			InstanceManagerProxyServiceDefaultPort,
+		tlsConfig, err = imutil.LoadClientTLS(
 			filepath.Join(types.TLSDirectoryInContainer, types.TLSCAFile),
 			filepath.Join(types.TLSDirectoryInContainer, types.TLSCertFile),
 			filepath.Join(types.TLSDirectoryInContainer, types.TLSKeyFile),
 			"longhorn-backend.longhorn-system",
 		)
 		if err != nil {
+			cancel()
 			return nil, errors.Wrap(err, "failed to load Instance Manager Proxy Client TLS files")
 		}
+		proxyClient, err = imclient.NewProxyClient(ctx, cancel, ip, InstanceManagerProxyServiceDefaultPort, tlsConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to initialize Instance Manager Proxy Client with TLS")
+		}
 		if err = proxyClient.CheckConnection(); err != nil {
 			return proxyClient, errors.Wrap(err, "failed to check Instance Manager Proxy Client with TLS connection")
 		}
@@ -128,6 +139,7 @@ func NewEngineClientProxy(im *longhorn.InstanceManager, logger logrus.FieldLogge
 			im.Name, im.Status.IP)
 		// fallback to non tls client, there is no way to differentiate between im versions unless we get the version via the im client
 		// TODO: remove this im client fallback mechanism in a future version maybe 2.4 / 2.5 or the next time we update the api version
+		tlsConfig = nil
 		ctx, cancel := context.WithCancel(context.Background())
 		proxyClient, err = imclient.NewProxyClient(ctx, cancel, im.Status.IP, InstanceManagerProxyServiceDefaultPort, nil)
 		if err != nil {
@@ -145,7 +157,10 @@ func NewEngineClientProxy(im *longhorn.InstanceManager, logger logrus.FieldLogge
 
 	return &Proxy{
 		logger:           logger,
+		imName:           im.Name,
+		imIP:             im.Status.IP,
 		grpcClient:       proxyClient,
+		tlsConfig:        tlsConfig,
 		proxyConnCounter: proxyConnCounter,
 		ds:               ds,
 	}, nil
@@ -153,7 +168,10 @@ func NewEngineClientProxy(im *longhorn.InstanceManager, logger logrus.FieldLogge
 
 type Proxy struct {
 	logger     logrus.FieldLogger
+	imName     string
+	imIP       string
 	grpcClient *imclient.ProxyClient
+	tlsConfig  *tls.Config
 	ds         *datastore.DataStore
 
 	proxyConnCounter util.Counter
@@ -165,6 +183,11 @@ type EngineClientProxy interface {
 	Close()
 }
 
+// checkCircuitBreaker returns an error if the calls to the instance manager of the proxy are short-circuited
+func (p *Proxy) checkCircuitBreaker() error {
+	return checkInstanceManagerCircuitBreaker(p.imName, p.imIP)
+}
+
 func (p *Proxy) Close() {
 	if p.grpcClient == nil {
 		p.logger.WithError(errors.New("gRPC client not exist")).Warn("Failed to close engine proxy service client")
@@ -200,6 +223,9 @@ func (p *Proxy) VersionGet(e *longhorn.Engine, clientOnly bool) (version *Engine
 		}, nil
 	}
 
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	recvServerVersion, err := p.grpcClient.ServerVersionGet(p.DirectToURL(e))
 	if err != nil {
 		return nil, err
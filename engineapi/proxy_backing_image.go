@@ -11,18 +11,30 @@ import (
 )
 
 func (p *Proxy) SPDKBackingImageCreate(name, backingImageUUID, diskUUID, checksum, fromAddress, srcDiskUUID string, size uint64) (*imapi.BackingImage, error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	return p.grpcClient.SPDKBackingImageCreate(name, backingImageUUID, diskUUID, checksum, fromAddress, srcDiskUUID, size)
 }
 
 func (p *Proxy) SPDKBackingImageDelete(name, diskUUID string) error {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.SPDKBackingImageDelete(name, diskUUID)
 }
 
 func (p *Proxy) SPDKBackingImageGet(name, diskUUID string) (*imapi.BackingImage, error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	return p.grpcClient.SPDKBackingImageGet(name, diskUUID)
 }
 
 func (p *Proxy) SPDKBackingImageList() (map[string]longhorn.BackingImageV2CopyInfo, error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	result := map[string]longhorn.BackingImageV2CopyInfo{}
 
 	v2BackingImages, err := p.grpcClient.SPDKBackingImageList()
@@ -38,6 +50,9 @@ func (p *Proxy) SPDKBackingImageList() (map[string]longhorn.BackingImageV2CopyIn
 }
 
 func (p *Proxy) SPDKBackingImageWatch(ctx context.Context) (*imapi.BackingImageStream, error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	return p.grpcClient.SPDKBackingImageWatch(ctx)
 }
 
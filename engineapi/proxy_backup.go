@@ -38,6 +38,9 @@ func (p *Proxy) SnapshotBackup(e *longhorn.Engine, snapshotName, backupName, bac
 		return "", "", err
 	}
 
+	if err := p.checkCircuitBreaker(); err != nil {
+		return "", "", err
+	}
 	backupID, replicaAddress, err := p.grpcClient.SnapshotBackup(string(e.Spec.DataEngine), e.Name,
 		e.Spec.VolumeName, p.DirectToURL(e), backupName, snapshotName, backupTarget, backingImageName,
 		backingImageChecksum, compressionMethod, concurrentLimit, storageClassName, labels, credentialEnv, parameters,
@@ -51,6 +54,9 @@ func (p *Proxy) SnapshotBackup(e *longhorn.Engine, snapshotName, backupName, bac
 
 func (p *Proxy) SnapshotBackupStatus(e *longhorn.Engine, backupName, replicaAddress,
 	replicaName string) (status *longhorn.EngineBackupStatus, err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	recv, err := p.grpcClient.SnapshotBackupStatus(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e), backupName, replicaAddress, replicaName)
 	if err != nil {
@@ -70,11 +76,17 @@ func (p *Proxy) BackupRestore(e *longhorn.Engine, backupTarget, backupName, back
 		return err
 	}
 
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.BackupRestore(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName, p.DirectToURL(e),
 		backupURL, backupTarget, backupVolumeName, envs, concurrentLimit)
 }
 
 func (p *Proxy) BackupRestoreStatus(e *longhorn.Engine) (status map[string]*longhorn.RestoreStatus, err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	recv, err := p.grpcClient.BackupRestoreStatus(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e))
 	if err != nil {
@@ -89,5 +101,8 @@ func (p *Proxy) BackupRestoreStatus(e *longhorn.Engine) (status map[string]*long
 }
 
 func (p *Proxy) CleanupBackupMountPoints() (err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.CleanupBackupMountPoints()
 }
@@ -5,6 +5,9 @@ import (
 )
 
 func (p *Proxy) MetricsGet(e *longhorn.Engine) (*Metrics, error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	metrics, err := p.grpcClient.MetricsGet(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName, p.DirectToURL(e))
 	if err != nil {
 		return nil, err
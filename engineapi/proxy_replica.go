@@ -1,22 +1,52 @@
 package engineapi
 
 import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
 	etypes "github.com/longhorn/longhorn-engine/pkg/types"
+	imclient "github.com/longhorn/longhorn-instance-manager/pkg/client"
+	imutil "github.com/longhorn/longhorn-instance-manager/pkg/util"
+	enginerpc "github.com/longhorn/types/pkg/generated/enginerpc"
+	imrpc "github.com/longhorn/types/pkg/generated/imrpc"
+
+	"github.com/longhorn/longhorn-manager/types"
 
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
 
+const (
+	// proxyReplicaInfoGetMethod forwards the replica get call to the replica through the engine proxy. Its request
+	// shares the layout of the replica verify rebuild request, and its response the layout of the replica get response.
+	proxyReplicaInfoGetMethod = "/imrpc.ProxyEngineService/ReplicaInfoGet"
+
+	replicaSnapshotDiskPrefix = "volume-snap-"
+	replicaDiskSuffix         = ".img"
+)
+
 func (p *Proxy) ReplicaAdd(e *longhorn.Engine, replicaName, replicaAddress string, restore, fastSync bool, localSync *etypes.FileLocalSync, replicaFileSyncHTTPClientTimeout, grpcTimeoutSeconds int64) (err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.ReplicaAdd(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName, p.DirectToURL(e),
 		replicaName, replicaAddress, restore, e.Spec.VolumeSize, e.Status.CurrentSize,
 		int(replicaFileSyncHTTPClientTimeout), fastSync, localSync, grpcTimeoutSeconds)
 }
 
 func (p *Proxy) ReplicaRemove(e *longhorn.Engine, address, replicaName string) (err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.ReplicaRemove(string(e.Spec.DataEngine), p.DirectToURL(e), e.Name, address, replicaName)
 }
 
 func (p *Proxy) ReplicaList(e *longhorn.Engine) (replicas map[string]*Replica, err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	resp, err := p.grpcClient.ReplicaList(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e))
 	if err != nil {
@@ -38,6 +68,9 @@ func (p *Proxy) ReplicaList(e *longhorn.Engine) (replicas map[string]*Replica, e
 }
 
 func (p *Proxy) ReplicaRebuildStatus(e *longhorn.Engine) (status map[string]*longhorn.RebuildStatus, err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	recv, err := p.grpcClient.ReplicaRebuildingStatus(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e))
 	if err != nil {
@@ -55,6 +88,9 @@ func (p *Proxy) ReplicaRebuildVerify(e *longhorn.Engine, replicaName, url string
 	if err := ValidateReplicaURL(url); err != nil {
 		return err
 	}
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.ReplicaVerifyRebuild(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e), url, replicaName)
 }
@@ -64,5 +100,66 @@ func (p *Proxy) ReplicaModeUpdate(e *longhorn.Engine, url, mode string) (err err
 		return err
 	}
 
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.ReplicaModeUpdate(string(e.Spec.DataEngine), p.DirectToURL(e), url, mode)
 }
+
+// ReplicaInfoGet retrieves the revision counter, the last modified time and the snapshots of the running replica
+// through the engine proxy, since the engine doesn't report them. The instance managers not supporting it return the
+// gRPC code Unimplemented.
+func (p *Proxy) ReplicaInfoGet(e *longhorn.Engine, replicaName, replicaAddress string) (info *ReplicaInfo, err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
+	conn, err := imutil.Connect(imutil.GetURL(p.imIP, InstanceManagerProxyServiceDefaultPort), p.tlsConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect engine proxy of instance manager %v", p.imName)
+	}
+	defer conn.Close()
+
+	dataEngine := imrpc.DataEngine_DATA_ENGINE_V1
+	if types.IsDataEngineV2(e.Spec.DataEngine) {
+		dataEngine = imrpc.DataEngine_DATA_ENGINE_V2
+	}
+	req := &imrpc.EngineReplicaVerifyRebuildRequest{
+		ProxyEngineRequest: &imrpc.ProxyEngineRequest{
+			Address:    p.DirectToURL(e),
+			EngineName: e.Name,
+			DataEngine: dataEngine,
+			VolumeName: e.Spec.VolumeName,
+		},
+		ReplicaAddress: replicaAddress,
+		ReplicaName:    replicaName,
+	}
+	resp := &enginerpc.ReplicaGetResponse{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), imclient.GRPCServiceTimeout)
+	defer cancel()
+	if err := conn.Invoke(ctx, proxyReplicaInfoGetMethod, req, resp); err != nil {
+		return nil, err
+	}
+	if resp.Replica == nil {
+		return nil, errors.Errorf("missing info of replica %v in the engine proxy response", replicaName)
+	}
+
+	return getReplicaInfo(resp.Replica), nil
+}
+
+func getReplicaInfo(r *enginerpc.Replica) *ReplicaInfo {
+	info := &ReplicaInfo{
+		RevisionCounter: r.RevisionCounter,
+		Snapshots:       []string{},
+	}
+	if r.LastModifyTime != 0 {
+		info.LastModifyTime = time.Unix(0, r.LastModifyTime)
+	}
+	for disk := range r.Disks {
+		if !strings.HasPrefix(disk, replicaSnapshotDiskPrefix) {
+			continue
+		}
+		info.Snapshots = append(info.Snapshots, strings.TrimSuffix(strings.TrimPrefix(disk, replicaSnapshotDiskPrefix), replicaDiskSuffix))
+	}
+	return info
+}
@@ -10,11 +10,17 @@ import (
 
 func (p *Proxy) SnapshotCreate(e *longhorn.Engine, name string, labels map[string]string,
 	freezeFilesystem bool) (string, error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return "", err
+	}
 	return p.grpcClient.VolumeSnapshot(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName, p.DirectToURL(e),
 		name, labels, freezeFilesystem)
 }
 
 func (p *Proxy) SnapshotList(e *longhorn.Engine) (snapshots map[string]*longhorn.SnapshotInfo, err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	recv, err := p.grpcClient.SnapshotList(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e))
 	if err != nil {
@@ -39,11 +45,17 @@ func (p *Proxy) SnapshotGet(e *longhorn.Engine, name string) (snapshot *longhorn
 
 func (p *Proxy) SnapshotClone(e *longhorn.Engine, snapshotName, fromEngineAddress, fromVolumeName, fromEngineName string,
 	fileSyncHTTPClientTimeout, grpcTimeoutSeconds int64) (err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.SnapshotClone(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName, p.DirectToURL(e),
 		snapshotName, fromEngineAddress, fromVolumeName, fromEngineName, int(fileSyncHTTPClientTimeout), grpcTimeoutSeconds)
 }
 
 func (p *Proxy) SnapshotCloneStatus(e *longhorn.Engine) (status map[string]*longhorn.SnapshotCloneStatus, err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	recv, err := p.grpcClient.SnapshotCloneStatus(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e))
 	if err != nil {
@@ -58,6 +70,9 @@ func (p *Proxy) SnapshotCloneStatus(e *longhorn.Engine) (status map[string]*long
 }
 
 func (p *Proxy) SnapshotRevert(e *longhorn.Engine, snapshotName string) (err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.SnapshotRevert(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName, p.DirectToURL(e),
 		snapshotName)
 }
@@ -72,11 +87,17 @@ func (p *Proxy) SnapshotPurge(e *longhorn.Engine) (err error) {
 		return errors.Wrapf(err, "failed to start snapshot purge for engine %v and volume %v because the volume is migrating", e.Name, e.Spec.VolumeName)
 	}
 
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.SnapshotPurge(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName, p.DirectToURL(e),
 		true)
 }
 
 func (p *Proxy) SnapshotPurgeStatus(e *longhorn.Engine) (status map[string]*longhorn.PurgeStatus, err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	recv, err := p.grpcClient.SnapshotPurgeStatus(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e))
 	if err != nil {
@@ -91,16 +112,25 @@ func (p *Proxy) SnapshotPurgeStatus(e *longhorn.Engine) (status map[string]*long
 }
 
 func (p *Proxy) SnapshotDelete(e *longhorn.Engine, name string) (err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.SnapshotRemove(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName, p.DirectToURL(e),
 		[]string{name})
 }
 
 func (p *Proxy) SnapshotHash(e *longhorn.Engine, snapshotName string, rehash bool) error {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.SnapshotHash(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName, p.DirectToURL(e),
 		snapshotName, rehash)
 }
 
 func (p *Proxy) SnapshotHashStatus(e *longhorn.Engine, snapshotName string) (status map[string]*longhorn.HashStatus, err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	recv, err := p.grpcClient.SnapshotHashStatus(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e), snapshotName)
 	if err != nil {
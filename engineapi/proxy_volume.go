@@ -7,6 +7,9 @@ import (
 )
 
 func (p *Proxy) VolumeGet(e *longhorn.Engine) (volume *Volume, err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return nil, err
+	}
 	recv, err := p.grpcClient.VolumeGet(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName, p.DirectToURL(e))
 	if err != nil {
 		return nil, err
@@ -16,6 +19,9 @@ func (p *Proxy) VolumeGet(e *longhorn.Engine) (volume *Volume, err error) {
 }
 
 func (p *Proxy) VolumeExpand(e *longhorn.Engine) (err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.VolumeExpand(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName, p.DirectToURL(e),
 		e.Spec.VolumeSize)
 }
@@ -30,30 +36,48 @@ func (p *Proxy) VolumeFrontendStart(e *longhorn.Engine) (err error) {
 		return fmt.Errorf("cannot start empty frontend")
 	}
 
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.VolumeFrontendStart(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e), frontendName)
 }
 
 func (p *Proxy) VolumeFrontendShutdown(e *longhorn.Engine) (err error) {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.VolumeFrontendShutdown(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e))
 }
 
 func (p *Proxy) VolumeUnmapMarkSnapChainRemovedSet(e *longhorn.Engine) error {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.VolumeUnmapMarkSnapChainRemovedSet(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e), e.Spec.UnmapMarkSnapChainRemovedEnabled)
 }
 
 func (p *Proxy) VolumeSnapshotMaxCountSet(e *longhorn.Engine) error {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.VolumeSnapshotMaxCountSet(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e), e.Spec.SnapshotMaxCount)
 }
 
 func (p *Proxy) VolumeSnapshotMaxSizeSet(e *longhorn.Engine) error {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.VolumeSnapshotMaxSizeSet(string(e.Spec.DataEngine), e.Name, e.Spec.VolumeName,
 		p.DirectToURL(e), e.Spec.SnapshotMaxSize)
 }
 
 func (p *Proxy) RemountReadOnlyVolume(e *longhorn.Engine) error {
+	if err := p.checkCircuitBreaker(); err != nil {
+		return err
+	}
 	return p.grpcClient.RemountReadOnlyVolume(e.Spec.VolumeName)
 }
@@ -0,0 +1,89 @@
+package engineapi
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	bsutil "github.com/longhorn/backupstore/util"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// IsSafetySnapshotRequired returns true if a safety snapshot of the volume is taken before a destructive operation
+// according to the setting safety-snapshot-policy. A detached volume is skipped, since its data can only be changed by
+// the operation once it's attached again.
+func IsSafetySnapshotRequired(ds *datastore.DataStore, v *longhorn.Volume) (bool, error) {
+	policy, err := ds.GetSettingValueExisted(types.SettingNameSafetySnapshotPolicy)
+	if err != nil {
+		return false, err
+	}
+	return types.SafetySnapshotPolicy(policy) != types.SafetySnapshotPolicyDisabled &&
+		v.Status.State == longhorn.VolumeStateAttached, nil
+}
+
+// TakeSafetySnapshot takes a snapshot of the volume through its running engine right before the destructive operation,
+// and backs it up according to the setting safety-snapshot-policy. Nothing is done if IsSafetySnapshotRequired is
+// false.
+func TakeSafetySnapshot(ds *datastore.DataStore, engineClientProxy EngineClientProxy, e *longhorn.Engine, v *longhorn.Volume, operation string) (err error) {
+	defer func() {
+		err = errors.Wrapf(err, "failed to take safety snapshot of volume %v before %v", v.Name, operation)
+	}()
+
+	isRequired, err := IsSafetySnapshotRequired(ds, v)
+	if err != nil || !isRequired {
+		return err
+	}
+	policy, err := ds.GetSettingValueExisted(types.SettingNameSafetySnapshotPolicy)
+	if err != nil {
+		return err
+	}
+	if v.Spec.MigrationNodeID != "" {
+		return fmt.Errorf("cannot operate during migration")
+	}
+
+	if err := ds.CheckVolumeSnapshotQuota(v.Name); err != nil {
+		return err
+	}
+	freezeFilesystem, err := ds.GetFreezeFilesystemForSnapshotSetting(e)
+	if err != nil {
+		return err
+	}
+
+	labels := map[string]string{
+		types.GetLonghornLabelKey(types.LonghornLabelSafetySnapshot): operation,
+	}
+	snapshotName, err := engineClientProxy.SnapshotCreate(e, bsutil.GenerateName("safety"), labels, freezeFilesystem)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Took safety snapshot %v of volume %v before %v", snapshotName, v.Name, operation)
+
+	if types.SafetySnapshotPolicy(policy) != types.SafetySnapshotPolicySnapshotAndBackup {
+		return nil
+	}
+	backup := &longhorn.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: bsutil.GenerateName("backup"),
+			Labels: map[string]string{
+				types.LonghornLabelBackupTarget: v.Spec.BackupTargetName,
+			},
+		},
+		Spec: longhorn.BackupSpec{
+			SnapshotName: snapshotName,
+			Labels:       labels,
+			BackupMode:   longhorn.BackupModeIncremental,
+		},
+	}
+	if backup, err = ds.CreateBackup(backup, v.Name); err != nil {
+		return errors.Wrapf(err, "failed to back up safety snapshot %v", snapshotName)
+	}
+	logrus.Infof("Requested backup %v of safety snapshot %v of volume %v", backup.Name, snapshotName, v.Name)
+	return nil
+}
@@ -1,28 +1,44 @@
 package engineapi
 
 import (
-	"fmt"
+	"context"
+	"net"
+	"strconv"
 
 	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
 
 	corev1 "k8s.io/api/core/v1"
 
 	smclient "github.com/longhorn/longhorn-share-manager/pkg/client"
+	smtypes "github.com/longhorn/longhorn-share-manager/pkg/types"
 
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
 
+const (
+	// The share manager freezes the filesystem of the volume in the mount namespace of its pod, and thaws it by
+	// itself once the freeze is held for longer than its deadline
+	shareManagerFilesystemFreezeMethod   = "/ShareManagerService/FilesystemFreeze"
+	shareManagerFilesystemUnfreezeMethod = "/ShareManagerService/FilesystemUnfreeze"
+)
+
 type ShareManagerClient struct {
+	address    string
 	grpcClient *smclient.ShareManagerClient
 }
 
 func NewShareManagerClient(sm *longhorn.ShareManager, pod *corev1.Pod) (*ShareManagerClient, error) {
-	client, err := smclient.NewShareManagerClient(fmt.Sprintf("%s:%d", pod.Status.PodIP, ShareManagerDefaultPort))
+	address := net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(ShareManagerDefaultPort))
+	client, err := smclient.NewShareManagerClient(address)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create Share Manager client for %v", sm.Name)
 	}
 
 	return &ShareManagerClient{
+		address:    address,
 		grpcClient: client,
 	}, nil
 }
@@ -50,3 +66,27 @@ func (c *ShareManagerClient) Unmount() error {
 func (c *ShareManagerClient) Mount() error {
 	return c.grpcClient.Mount()
 }
+
+// FilesystemFreeze flushes and freezes the filesystem exported by the share manager. The share managers not
+// supporting it return the gRPC code Unimplemented.
+func (c *ShareManagerClient) FilesystemFreeze() error {
+	return c.invoke(shareManagerFilesystemFreezeMethod)
+}
+
+// FilesystemUnfreeze thaws the filesystem frozen by FilesystemFreeze
+func (c *ShareManagerClient) FilesystemUnfreeze() error {
+	return c.invoke(shareManagerFilesystemUnfreezeMethod)
+}
+
+func (c *ShareManagerClient) invoke(method string) error {
+	conn, err := grpc.NewClient(c.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect share manager service to %v", c.address)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), smtypes.GRPCServiceTimeout)
+	defer cancel()
+
+	return conn.Invoke(ctx, method, &emptypb.Empty{}, &emptypb.Empty{})
+}
@@ -0,0 +1,57 @@
+package engineapi
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// newFakeShareManagerServer starts a gRPC server serving the share manager methods by the handler
+func newFakeShareManagerServer(t *testing.T, handler grpc.StreamHandler) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	opts := []grpc.ServerOption{}
+	if handler != nil {
+		opts = append(opts, grpc.UnknownServiceHandler(handler))
+	}
+	server := grpc.NewServer(opts...)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestShareManagerClientFilesystemFreeze(t *testing.T) {
+	assert := require.New(t)
+
+	var lock sync.Mutex
+	methods := []string{}
+	address := newFakeShareManagerServer(t, func(srv interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		lock.Lock()
+		methods = append(methods, method)
+		lock.Unlock()
+		if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+			return err
+		}
+		return stream.SendMsg(&emptypb.Empty{})
+	})
+
+	client := &ShareManagerClient{address: address}
+	assert.NoError(client.FilesystemFreeze())
+	assert.NoError(client.FilesystemUnfreeze())
+	assert.Equal([]string{shareManagerFilesystemFreezeMethod, shareManagerFilesystemUnfreezeMethod}, methods)
+
+	// The share managers not supporting the freeze return Unimplemented, so the caller can fall back
+	client = &ShareManagerClient{address: newFakeShareManagerServer(t, nil)}
+	assert.Equal(codes.Unimplemented, status.Code(client.FilesystemFreeze()))
+}
@@ -3,6 +3,7 @@ package engineapi
 import (
 	"context"
 	"fmt"
+	"net"
 	"strings"
 	"time"
 
@@ -61,6 +62,13 @@ type Replica struct {
 	Mode longhorn.ReplicaMode
 }
 
+// ReplicaInfo is the info of a running v1 replica retrieved through the engine proxy
+type ReplicaInfo struct {
+	RevisionCounter int64
+	LastModifyTime  time.Time
+	Snapshots       []string
+}
+
 type Controller struct {
 	URL    string
 	NodeID string
@@ -93,6 +101,7 @@ type EngineClient interface {
 	ReplicaRebuildStatus(*longhorn.Engine) (map[string]*longhorn.RebuildStatus, error)
 	ReplicaRebuildVerify(engine *longhorn.Engine, replicaName, url string) error
 	ReplicaModeUpdate(engine *longhorn.Engine, url string, mode string) error
+	ReplicaInfoGet(engine *longhorn.Engine, replicaName, replicaAddress string) (*ReplicaInfo, error)
 
 	SnapshotCreate(engine *longhorn.Engine, name string, labels map[string]string, freezeFilesystem bool) (string, error)
 	SnapshotList(engine *longhorn.Engine) (map[string]*longhorn.SnapshotInfo, error)
@@ -310,7 +319,8 @@ func GetEngineEndpoint(volume *Volume, ip string) (string, error) {
 
 		// it will looks like this in the end
 		// iscsi://10.42.0.12:3260/iqn.2014-09.com.rancher:vol-name/1
-		return EndpointISCSIPrefix + ip + ":" + DefaultISCSIPort + "/" + volume.Endpoint + "/" + DefaultISCSILUN, nil
+		// or iscsi://[fd00:10:42::c]:3260/iqn.2014-09.com.rancher:vol-name/1 for IPv6
+		return EndpointISCSIPrefix + net.JoinHostPort(ip, DefaultISCSIPort) + "/" + volume.Endpoint + "/" + DefaultISCSILUN, nil
 	case spdkdevtypes.FrontendSPDKTCPNvmf, "ublk":
 		return volume.Endpoint, nil
 	}
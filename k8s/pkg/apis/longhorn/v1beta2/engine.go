@@ -94,6 +94,22 @@ type SnapshotCloneStatus struct {
 	SnapshotName string `json:"snapshotName"`
 }
 
+// ReplicaSalvageInfo is the info of a replica used to rank the salvage candidates when all the replicas of the volume
+// fail. It's recorded while the replica is running, since it cannot be retrieved once the replica is stopped.
+type ReplicaSalvageInfo struct {
+	// +kubebuilder:validation:Type=string
+	// +optional
+	RevisionCounter int64 `json:"revisionCounter,string"`
+	// The last time the data of the replica was written.
+	// +optional
+	LastModifiedAt string `json:"lastModifiedAt"`
+	// The number of the snapshots of the replica having a checksum.
+	// +optional
+	SnapshotChecksumCount int `json:"snapshotChecksumCount"`
+	// +optional
+	RecordedAt string `json:"recordedAt"`
+}
+
 type SnapshotInfo struct {
 	// +optional
 	Name string `json:"name"`
@@ -143,6 +159,9 @@ type EngineSpec struct {
 	// +kubebuilder:validation:Type=string
 	// +optional
 	SnapshotMaxSize int64 `json:"snapshotMaxSize,string"`
+	// The time until which the IO of the engine is paused. The IO is resumed once it passes.
+	// +optional
+	IOPauseDeadline string `json:"ioPauseDeadline"`
 }
 
 // EngineStatus defines the observed state of the Longhorn engine
@@ -199,6 +218,12 @@ type EngineStatus struct {
 	// +kubebuilder:validation:Type=string
 	// +optional
 	SnapshotMaxSize int64 `json:"snapshotMaxSize,string"`
+	// Whether the IO of the engine is paused.
+	// +optional
+	IOPaused bool `json:"ioPaused"`
+	// +optional
+	// +nullable
+	ReplicaSalvageInfoMap map[string]*ReplicaSalvageInfo `json:"replicaSalvageInfoMap"`
 }
 
 // +genclient
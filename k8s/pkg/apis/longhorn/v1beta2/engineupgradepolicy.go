@@ -0,0 +1,71 @@
+package v1beta2
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// EngineUpgradeMaintenanceWindow is the recurring period in which the volumes are allowed to be upgraded.
+type EngineUpgradeMaintenanceWindow struct {
+	// The cron schedule of the start of the window in UTC, e.g. "0 2 * * 6" for 2am UTC every Saturday.
+	// +optional
+	Cron string `json:"cron"`
+	// The length of the window in minutes.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Duration int `json:"duration"`
+}
+
+// EngineUpgradePolicySpec defines the desired state of the Longhorn engine upgrade policy
+type EngineUpgradePolicySpec struct {
+	// The labels of the Longhorn volumes the policy applies to. An empty selector matches all the volumes.
+	// A volume matching several policies follows the first one in the order of their names.
+	// +optional
+	Selector map[string]string `json:"selector"`
+	// The volumes are only upgraded in the maintenance window. The volumes can be upgraded at any time if it's not set.
+	// +optional
+	MaintenanceWindow *EngineUpgradeMaintenanceWindow `json:"maintenanceWindow,omitempty"`
+	// The maximum number of the volumes of the policy upgrading at the same time.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrent int `json:"maxConcurrent"`
+}
+
+// EngineUpgradePolicyStatus defines the observed state of the Longhorn engine upgrade policy
+type EngineUpgradePolicyStatus struct {
+	// Whether the policy is in its maintenance window.
+	// +optional
+	InMaintenanceWindow bool `json:"inMaintenanceWindow"`
+	// The names of the volumes of the policy not using the default engine image yet.
+	// +optional
+	// +nullable
+	PendingVolumes []string `json:"pendingVolumes"`
+	// The names of the volumes of the policy upgrading the engine image.
+	// +optional
+	// +nullable
+	UpgradingVolumes []string `json:"upgradingVolumes"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=lheup
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Max Concurrent",type=integer,JSONPath=`.spec.maxConcurrent`,description="The maximum number of the volumes upgrading at the same time"
+// +kubebuilder:printcolumn:name="In Maintenance Window",type=boolean,JSONPath=`.status.inMaintenanceWindow`,description="Whether the policy is in its maintenance window"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// EngineUpgradePolicy is where Longhorn stores how the volumes matching its selector are automatically upgraded to the
+// default engine image.
+type EngineUpgradePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EngineUpgradePolicySpec   `json:"spec,omitempty"`
+	Status EngineUpgradePolicyStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EngineUpgradePolicyList is a list of engine upgrade policies.
+type EngineUpgradePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EngineUpgradePolicy `json:"items"`
+}
@@ -38,6 +38,12 @@ const (
 	InstanceConditionReasonInstanceCreationFailure = "InstanceCreationFailure"
 )
 
+const (
+	InstanceManagerConditionTypeDegraded = "Degraded"
+
+	InstanceManagerConditionReasonCircuitBreakerOpen = "CircuitBreakerOpen"
+)
+
 type InstanceProcess struct {
 	// +optional
 	Spec InstanceProcessSpec `json:"spec"`
@@ -85,6 +91,10 @@ type InstanceSpec struct {
 	LogRequested bool `json:"logRequested"`
 	// +optional
 	SalvageRequested bool `json:"salvageRequested"`
+	// The time the instance was lost with the restart of its instance manager. The lost instance is started again
+	// in the restarted instance manager as it is, instead of being stopped first.
+	// +optional
+	RebindRequestedAt string `json:"rebindRequestedAt"`
 	// Deprecated:Replaced by field `dataEngine`.
 	// +optional
 	BackendStoreDriver BackendStoreDriverType `json:"backendStoreDriver"`
@@ -206,6 +216,9 @@ type InstanceManagerStatus struct {
 	ProxyAPIVersion int `json:"proxyApiVersion"`
 	// +optional
 	DataEngineStatus DataEngineStatus `json:"dataEngineStatus"`
+	// +optional
+	// +nullable
+	Conditions []Condition `json:"conditions"`
 
 	// Deprecated: Replaced by InstanceEngines and InstanceReplicas
 	// +optional
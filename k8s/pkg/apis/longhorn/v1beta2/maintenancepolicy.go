@@ -0,0 +1,94 @@
+package v1beta2
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+type MaintenancePolicyResourceType string
+
+const (
+	MaintenancePolicyResourceTypeVolume = MaintenancePolicyResourceType("volume")
+	MaintenancePolicyResourceTypeNode   = MaintenancePolicyResourceType("node")
+)
+
+// MaintenancePolicyVolumeRules are applied to the matching volumes. The rules not set leave the volumes as they are.
+type MaintenancePolicyVolumeRules struct {
+	// +optional
+	NumberOfReplicas *int `json:"numberOfReplicas,omitempty"`
+	// +kubebuilder:validation:Enum=disabled;best-effort;best-effort-load-aware;strict-local
+	// +optional
+	DataLocality *DataLocality `json:"dataLocality,omitempty"`
+	// +optional
+	SnapshotMaxCount *int `json:"snapshotMaxCount,omitempty"`
+	// +optional
+	TrimFilesystemOnUnstage *bool `json:"trimFilesystemOnUnstage,omitempty"`
+	// +kubebuilder:validation:Enum=ignored;disabled;enabled
+	// +optional
+	UnmapMarkSnapChainRemoved *UnmapMarkSnapChainRemoved `json:"unmapMarkSnapChainRemoved,omitempty"`
+	// Skip the volumes in the recurring backup jobs.
+	// +optional
+	RecurringBackupDisabled *bool `json:"recurringBackupDisabled,omitempty"`
+}
+
+// MaintenancePolicyNodeRules are applied to the matching nodes. The rules not set leave the nodes as they are.
+type MaintenancePolicyNodeRules struct {
+	// +optional
+	AllowScheduling *bool `json:"allowScheduling,omitempty"`
+	// Never schedule a replica to the node if the node would hold the only replica of the volume, e.g. for the spot
+	// instances that can be reclaimed at any time.
+	// +optional
+	SoleReplicaDisallowed *bool `json:"soleReplicaDisallowed,omitempty"`
+}
+
+// MaintenancePolicySpec defines the desired state of the Longhorn maintenance policy
+type MaintenancePolicySpec struct {
+	// The type of the resources the policy applies to.
+	// Can be "volume" or "node".
+	// +kubebuilder:validation:Enum=volume;node
+	// +optional
+	ResourceType MaintenancePolicyResourceType `json:"resourceType"`
+	// The labels of the resources the policy applies to. The volumes are matched by the labels of the Longhorn
+	// volumes, and the nodes by the labels of the Kubernetes nodes. An empty selector matches all the resources.
+	// +optional
+	Selector map[string]string `json:"selector"`
+	// The rules of the policies with a higher priority override the rules of the other policies applying to the
+	// same resource. The policies of the same priority are applied in the order of their names.
+	// +optional
+	Priority int `json:"priority"`
+	// +optional
+	VolumeRules *MaintenancePolicyVolumeRules `json:"volumeRules,omitempty"`
+	// +optional
+	NodeRules *MaintenancePolicyNodeRules `json:"nodeRules,omitempty"`
+}
+
+// MaintenancePolicyStatus defines the observed state of the Longhorn maintenance policy
+type MaintenancePolicyStatus struct {
+	// The names of the resources the policy applies to.
+	// +optional
+	// +nullable
+	MatchedResources []string `json:"matchedResources"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=lhmp
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Resource Type",type=string,JSONPath=`.spec.resourceType`,description="The type of the resources the policy applies to"
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`,description="The priority of the policy"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// MaintenancePolicy is where Longhorn stores the rules applied to the volumes or nodes matching its selector.
+type MaintenancePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MaintenancePolicySpec   `json:"spec,omitempty"`
+	Status MaintenancePolicyStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MaintenancePolicyList is a list of maintenance policies.
+type MaintenancePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MaintenancePolicy `json:"items"`
+}
@@ -11,6 +11,7 @@ const (
 	NodeConditionTypeNFSClientInstalled  = "NFSClientInstalled"
 	NodeConditionTypeSchedulable         = "Schedulable"
 	NodeConditionTypeHugePagesAvailable  = "HugePagesAvailable"
+	NodeConditionTypeDrainReady          = "DrainReady"
 )
 
 const (
@@ -30,8 +31,11 @@ const (
 	NodeConditionReasonNFSClientIsNotFound       = "NFSClientIsNotFound"
 	NodeConditionReasonNFSClientIsMisconfigured  = "NFSClientIsMisconfigured"
 	NodeConditionReasonKubernetesNodeCordoned    = "KubernetesNodeCordoned"
+	NodeConditionReasonKubernetesNodeFlapping    = "KubernetesNodeFlapping"
 	NodeConditionReasonHugePagesNotConfigured    = "HugePagesNotConfigured"
 	NodeConditionReasonInsufficientHugePages     = "InsufficientHugePages"
+	NodeConditionReasonReplicasNotEvicted        = "ReplicasNotEvicted"
+	NodeConditionReasonShareManagersNotMigrated  = "ShareManagersNotMigrated"
 )
 
 const (
@@ -61,6 +65,7 @@ const (
 	ErrorReplicaScheduleSchedulingFailed                 = "replica scheduling failed"
 	ErrorReplicaSchedulePrecheckNewReplicaFailed         = "precheck new replica failed"
 	ErrorReplicaScheduleEvictReplicaFailed               = "evict replica failed"
+	ErrorReplicaScheduleSoleReplicaDisallowed            = "nodes cannot hold the only replica of the volume"
 )
 
 type DiskType string
@@ -102,6 +107,10 @@ type DiskSpec struct {
 	StorageReserved int64 `json:"storageReserved"`
 	// +optional
 	Tags []string `json:"tags"`
+	// The maximum number of replicas rebuilding on the disk at the same time. 0 means only the
+	// concurrent replica rebuild per node limit setting applies.
+	// +optional
+	ConcurrentReplicaRebuildLimit int `json:"concurrentReplicaRebuildLimit"`
 }
 
 type DiskStatus struct {
@@ -134,6 +143,14 @@ type DiskStatus struct {
 	FSType string `json:"filesystemType"`
 	// +optional
 	InstanceManagerName string `json:"instanceManagerName"`
+	// The percentage of the time the device of the disk was busy during the last disk monitoring period.
+	// It's only collected for filesystem-type disks.
+	// +optional
+	IOUtilization int `json:"ioUtilization"`
+	// The average milliseconds a read or write on the device of the disk took during the last disk monitoring period.
+	// It's only collected for filesystem-type disks.
+	// +optional
+	IOLatency int64 `json:"ioLatency"`
 }
 
 // NodeSpec defines the desired state of the Longhorn node
@@ -150,6 +167,9 @@ type NodeSpec struct {
 	Tags []string `json:"tags"`
 	// +optional
 	InstanceManagerCPURequest int `json:"instanceManagerCPURequest"`
+	// The memory request of the instance manager pods on the node, in MiB. 0 means no request.
+	// +optional
+	InstanceManagerMemoryRequest int `json:"instanceManagerMemoryRequest"`
 }
 
 // NodeStatus defines the observed state of the Longhorn node
@@ -168,6 +188,13 @@ type NodeStatus struct {
 	SnapshotCheckStatus SnapshotCheckStatus `json:"snapshotCheckStatus"`
 	// +optional
 	AutoEvicting bool `json:"autoEvicting"`
+	// The recent times the Kubernetes node transitioned between Ready and NotReady, used to detect a flapping node.
+	// +optional
+	// +nullable
+	ReadyTransitionTimes []string `json:"readyTransitionTimes"`
+	// Replica scheduling is disabled on the flapping node until this time.
+	// +optional
+	SchedulingCooldownUntil string `json:"schedulingCooldownUntil"`
 }
 
 // +genclient
@@ -5,7 +5,10 @@ import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 type OrphanType string
 
 const (
-	OrphanTypeReplica = OrphanType("replica")
+	OrphanTypeReplica         = OrphanType("replica")
+	OrphanTypeEngineInstance  = OrphanType("engine-instance")
+	OrphanTypeReplicaInstance = OrphanType("replica-instance")
+	OrphanTypeBackingImage    = OrphanType("backing-image")
 )
 
 const (
@@ -25,6 +28,11 @@ const (
 	OrphanDiskUUID = "DiskUUID"
 	OrphanDiskPath = "DiskPath"
 	OrphanDiskType = "DiskType"
+	OrphanDataSize = "DataSize"
+
+	OrphanInstanceName    = "InstanceName"
+	OrphanInstanceManager = "InstanceManager"
+	OrphanDataEngine      = "DataEngine"
 )
 
 // OrphanSpec defines the desired state of the Longhorn orphaned data
@@ -33,7 +41,7 @@ type OrphanSpec struct {
 	// +optional
 	NodeID string `json:"nodeID"`
 	// The type of the orphaned data.
-	// Can be "replica".
+	// Can be "replica", "engine-instance", "replica-instance" or "backing-image".
 	// +optional
 	Type OrphanType `json:"orphanType"`
 
@@ -39,8 +39,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&EngineList{},
 		&EngineImage{},
 		&EngineImageList{},
+		&EngineUpgradePolicy{},
+		&EngineUpgradePolicyList{},
 		&InstanceManager{},
 		&InstanceManagerList{},
+		&MaintenancePolicy{},
+		&MaintenancePolicyList{},
 		&Node{},
 		&NodeList{},
 		&Orphan{},
@@ -77,6 +77,15 @@ type ReplicaSpec struct {
 	UnmapMarkDiskChainRemovedEnabled bool `json:"unmapMarkDiskChainRemovedEnabled"`
 	// +optional
 	RebuildRetryCount int `json:"rebuildRetryCount"`
+	// RebuildPaused stops the in progress rebuild of the replica, and keeps the replica from being rebuilt until it is
+	// cleared. The data transferred already is reused once the rebuild is resumed.
+	// +optional
+	RebuildPaused bool `json:"rebuildPaused"`
+	// RebuildPriority bumps or demotes the rebuild of the replica relative to the other rebuilding replicas on the same
+	// node. The replicas with a higher value take the rebuilding slots first, regardless of the replica rebuild priority
+	// policy.
+	// +optional
+	RebuildPriority int `json:"rebuildPriority"`
 	// +optional
 	EvictionRequested bool `json:"evictionRequested"`
 	// +optional
@@ -86,12 +95,30 @@ type ReplicaSpec struct {
 	SnapshotMaxSize int64 `json:"snapshotMaxSize,string"`
 }
 
+type ReplicaScrubResult string
+
+const (
+	ReplicaScrubResultPassed    = ReplicaScrubResult("passed")
+	ReplicaScrubResultDivergent = ReplicaScrubResult("divergent")
+	ReplicaScrubResultFailed    = ReplicaScrubResult("failed")
+)
+
 // ReplicaStatus defines the observed state of the Longhorn replica
 type ReplicaStatus struct {
 	InstanceStatus `json:""`
 	// Deprecated: Replaced by field `spec.evictionRequested`.
 	// +optional
 	EvictionRequested bool `json:"evictionRequested"`
+	// The time the in progress integrity scrubbing of the replica started.
+	// +optional
+	ScrubStartedAt string `json:"scrubStartedAt"`
+	// The time the last integrity scrubbing of the replica finished.
+	// +optional
+	LastScrubbedAt string `json:"lastScrubbedAt"`
+	// The result of the last integrity scrubbing of the replica.
+	// +kubebuilder:validation:Enum="";passed;divergent;failed
+	// +optional
+	LastScrubResult ReplicaScrubResult `json:"lastScrubResult"`
 }
 
 // +genclient
@@ -2,6 +2,19 @@ package v1beta2
 
 import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+const (
+	SettingConditionTypeUpgradeCompatible        = "UpgradeCompatible"
+	SettingConditionTypeCapacityRebalancePlanned = "CapacityRebalancePlanned"
+
+	SettingConditionReasonIncompatibleEngineImage = "IncompatibleEngineImage"
+	SettingConditionReasonStaleCRDStoredVersion   = "StaleCRDStoredVersion"
+
+	SettingConditionReasonCapacityRebalanceDisabled = "Disabled"
+	SettingConditionReasonCapacityRebalanceBalanced = "Balanced"
+	SettingConditionReasonCapacityRebalanceDryRun   = "DryRun"
+	SettingConditionReasonCapacityRebalanceMoving   = "Moving"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:resource:shortName=lhs
@@ -27,6 +40,10 @@ type Setting struct {
 type SettingStatus struct {
 	// The setting is applied.
 	Applied bool `json:"applied"`
+	// The conditions of the setting.
+	// +optional
+	// +nullable
+	Conditions []Condition `json:"conditions"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -31,6 +31,9 @@ type ShareManagerStatus struct {
 	// NFS endpoint that can access the mounted filesystem of the volume
 	// +optional
 	Endpoint string `json:"endpoint"`
+	// The volume size the exported filesystem has been resized to since the share manager started
+	// +optional
+	FilesystemSize int64 `json:"filesystemSize,string"`
 }
 
 // +genclient
@@ -13,6 +13,16 @@ const (
 	SnapshotHashStatusError      = SnapshotHashStatus("error")
 )
 
+type SnapshotRevertState string
+
+const (
+	SnapshotRevertStateNone       = SnapshotRevertState("")
+	SnapshotRevertStatePending    = SnapshotRevertState("pending")
+	SnapshotRevertStateInProgress = SnapshotRevertState("in-progress")
+	SnapshotRevertStateCompleted  = SnapshotRevertState("completed")
+	SnapshotRevertStateError      = SnapshotRevertState("error")
+)
+
 // SnapshotSpec defines the desired state of Longhorn Snapshot
 type SnapshotSpec struct {
 	// the volume that this snapshot belongs to.
@@ -25,6 +35,10 @@ type SnapshotSpec struct {
 	// +optional
 	// +nullable
 	Labels map[string]string `json:"labels"`
+	// request reverting the volume to this snapshot. The revert waits until the volume isn't used by any workload,
+	// and is done only once until the request is removed.
+	// +optional
+	RevertRequested bool `json:"revertRequested"`
 }
 
 // SnapshotStatus defines the observed state of Longhorn Snapshot
@@ -55,6 +69,12 @@ type SnapshotStatus struct {
 	ReadyToUse bool `json:"readyToUse"`
 	// +optional
 	Checksum string `json:"checksum"`
+	// The progress of the revert requested by spec.revertRequested
+	// +optional
+	RevertState SnapshotRevertState `json:"revertState"`
+	// The reason the revert is pending, retried or failed
+	// +optional
+	RevertMessage string `json:"revertMessage"`
 }
 
 // +genclient
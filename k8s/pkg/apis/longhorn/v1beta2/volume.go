@@ -45,13 +45,16 @@ const (
 	VolumeDataSourceTypeVolume   = VolumeDataSourceType("volume")
 )
 
-// +kubebuilder:validation:Enum=disabled;best-effort;strict-local
+// +kubebuilder:validation:Enum=disabled;best-effort;best-effort-load-aware;strict-local
 type DataLocality string
 
 const (
 	DataLocalityDisabled    = DataLocality("disabled")
 	DataLocalityBestEffort  = DataLocality("best-effort")
 	DataLocalityStrictLocal = DataLocality("strict-local")
+	// DataLocalityBestEffortLoadAware is best-effort, except that the local replica is only created when the disk
+	// usage and the IO utilization of the attached node are below the thresholds.
+	DataLocalityBestEffortLoadAware = DataLocality("best-effort-load-aware")
 )
 
 // +kubebuilder:validation:Enum=rwo;rwx
@@ -103,11 +106,134 @@ type VolumeCloneStatus struct {
 	NextAllowedAttemptAt string `json:"nextAllowedAttemptAt"`
 }
 
+type VolumePassphraseRotationState string
+
 const (
-	VolumeConditionTypeScheduled           = "Scheduled"
-	VolumeConditionTypeRestore             = "Restore"
-	VolumeConditionTypeTooManySnapshots    = "TooManySnapshots"
-	VolumeConditionTypeWaitForBackingImage = "WaitForBackingImage"
+	VolumePassphraseRotationStatePending   = VolumePassphraseRotationState("pending")
+	VolumePassphraseRotationStateCompleted = VolumePassphraseRotationState("completed")
+	VolumePassphraseRotationStateFailed    = VolumePassphraseRotationState("failed")
+)
+
+type VolumeOperationType string
+
+const (
+	VolumeOperationTypeExpansion     = VolumeOperationType("expansion")
+	VolumeOperationTypeRebuild       = VolumeOperationType("rebuild")
+	VolumeOperationTypeSnapshotPurge = VolumeOperationType("snapshotPurge")
+)
+
+// QueuedVolumeOperation is an operation of the volume waiting for a conflicting operation to complete
+type QueuedVolumeOperation struct {
+	// +optional
+	Type VolumeOperationType `json:"type"`
+	// The operation in progress or with a higher priority which the operation waits for.
+	// +optional
+	BlockedBy VolumeOperationType `json:"blockedBy"`
+}
+
+// SalvageCandidate is a failed replica of which the data can be used to salvage the volume
+type SalvageCandidate struct {
+	// +optional
+	ReplicaName string `json:"replicaName"`
+	// +optional
+	NodeID string `json:"nodeID"`
+	// +optional
+	FailedAt string `json:"failedAt"`
+	// +kubebuilder:validation:Type=string
+	// +optional
+	RevisionCounter int64 `json:"revisionCounter,string"`
+	// +optional
+	LastModifiedAt string `json:"lastModifiedAt"`
+	// +optional
+	SnapshotChecksumCount int `json:"snapshotChecksumCount"`
+}
+
+// ReplicaSchedulingFailure is the breakdown of why a replica of the volume cannot be scheduled, by the number of nodes
+// and disks excluded for each reason. The disks are only counted on the nodes not excluded.
+type ReplicaSchedulingFailure struct {
+	// Empty if the replica is not created yet.
+	// +optional
+	Replica string `json:"replica"`
+	// +optional
+	UnavailableNodes int `json:"unavailableNodes"`
+	// +optional
+	TagMismatchNodes int `json:"tagMismatchNodes"`
+	// +optional
+	NodeAntiAffinityNodes int `json:"nodeAntiAffinityNodes"`
+	// +optional
+	ZoneAntiAffinityNodes int `json:"zoneAntiAffinityNodes"`
+	// +optional
+	UnavailableDisks int `json:"unavailableDisks"`
+	// +optional
+	IncompatibleDisks int `json:"incompatibleDisks"`
+	// +optional
+	InsufficientStorageDisks int `json:"insufficientStorageDisks"`
+	// +optional
+	TagMismatchDisks int `json:"tagMismatchDisks"`
+	// +optional
+	DiskAntiAffinityDisks int `json:"diskAntiAffinityDisks"`
+}
+
+type VolumePassphraseRotationStatus struct {
+	// The rotation request handled by the current state.
+	// +optional
+	RequestedAt string `json:"requestedAt"`
+	// +optional
+	State VolumePassphraseRotationState `json:"state"`
+	// The node on which the passphrase was rotated.
+	// +optional
+	NodeID string `json:"nodeID"`
+	// +optional
+	LastRotatedAt string `json:"lastRotatedAt"`
+	// +optional
+	Error string `json:"error"`
+}
+
+type VolumeDiskTagMigrationState string
+
+const (
+	VolumeDiskTagMigrationStateInProgress = VolumeDiskTagMigrationState("in-progress")
+	VolumeDiskTagMigrationStateCompleted  = VolumeDiskTagMigrationState("completed")
+)
+
+type VolumeDiskTagMigrationStatus struct {
+	// The migration request handled by the current state.
+	// +optional
+	RequestedAt string `json:"requestedAt"`
+	// +optional
+	State VolumeDiskTagMigrationState `json:"state"`
+	// The disk selector the replicas are migrated to.
+	// +optional
+	DiskSelector []string `json:"diskSelector"`
+	// The replica being replaced by a replica on the disks matching the disk selector.
+	// +optional
+	ReplacingReplica string `json:"replacingReplica"`
+	// The number of healthy replicas on the disks matching the disk selector.
+	// +optional
+	MigratedReplicas int `json:"migratedReplicas"`
+	// +optional
+	TotalReplicas int `json:"totalReplicas"`
+	// +optional
+	CompletedAt string `json:"completedAt"`
+}
+
+const (
+	VolumeConditionTypeScheduled              = "Scheduled"
+	VolumeConditionTypeRestore                = "Restore"
+	VolumeConditionTypeTooManySnapshots       = "TooManySnapshots"
+	VolumeConditionTypeWaitForBackingImage    = "WaitForBackingImage"
+	VolumeConditionTypeFilesystemResizeFailed = "FilesystemResizeFailed"
+	VolumeConditionTypeFIPSNonCompliant       = "FIPSNonCompliant"
+	VolumeConditionTypeExpansionPending       = "ExpansionPending"
+	// VolumeConditionTypeSnapshotQuotaExceeded is true while the snapshots of the volume reach the snapshot max count
+	// or the snapshot max size of the volume, so no new snapshot can be requested until some snapshots are removed.
+	VolumeConditionTypeSnapshotQuotaExceeded = "SnapshotQuotaExceeded"
+	// VolumeConditionTypeFilesystemCheckRequired is true after the volume is detached uncleanly, until a read-only
+	// check of its filesystem passes
+	VolumeConditionTypeFilesystemCheckRequired = "FilesystemCheckRequired"
+	// VolumeConditionTypeBackupFresh is false while the last completed backup of the volume is older than the backup
+	// max age of the volume
+	VolumeConditionTypeBackupFresh = "BackupFresh"
 )
 
 const (
@@ -118,6 +244,18 @@ const (
 	VolumeConditionReasonTooManySnapshots              = "TooManySnapshots"
 	VolumeConditionReasonWaitForBackingImageFailed     = "GetBackingImageFailed"
 	VolumeConditionReasonWaitForBackingImageWaiting    = "Waiting"
+	VolumeConditionReasonFilesystemResizeFailure       = "FilesystemResizeFailure"
+	VolumeConditionReasonFIPSNonCompliantEncryption    = "FIPSNonCompliantEncryption"
+	VolumeConditionReasonEngineExpansionInProgress     = "EngineExpansionInProgress"
+	VolumeConditionReasonShareManagerResizePending     = "ShareManagerResizePending"
+	VolumeConditionReasonForceDetached                 = "ForceDetached"
+	VolumeConditionReasonCorruptedMount                = "CorruptedMount"
+	VolumeConditionReasonFilesystemCheckFailure        = "FilesystemCheckFailure"
+	VolumeConditionReasonFilesystemCheckPassed         = "FilesystemCheckPassed"
+	VolumeConditionReasonSnapshotCountQuotaExceeded    = "SnapshotCountQuotaExceeded"
+	VolumeConditionReasonSnapshotSizeQuotaExceeded     = "SnapshotSizeQuotaExceeded"
+	VolumeConditionReasonBackupStale                   = "BackupStale"
+	VolumeConditionReasonBackupNotFound                = "BackupNotFound"
 )
 
 type SnapshotDataIntegrity string
@@ -174,6 +312,14 @@ const (
 	FreezeFilesystemForSnapshotDisabled = FreezeFilesystemForSnapshot("disabled")
 )
 
+// +kubebuilder:validation:Enum=full-copy;colocate-with-source
+type VolumeCloneMode string
+
+const (
+	VolumeCloneModeFullCopy           = VolumeCloneMode("full-copy")
+	VolumeCloneModeColocateWithSource = VolumeCloneMode("colocate-with-source")
+)
+
 // Deprecated.
 type BackendStoreDriverType string
 
@@ -233,8 +379,18 @@ type VolumeSpec struct {
 	FromBackup string `json:"fromBackup"`
 	// +optional
 	RestoreVolumeRecurringJob RestoreVolumeRecurringJobType `json:"restoreVolumeRecurringJob"`
+	// The secret in the Longhorn namespace holding the backup store credential used to restore the volume from backup
+	// in place of the credential secret of the backup target.
+	// +optional
+	RestoreCredentialSecret string `json:"restoreCredentialSecret"`
 	// +optional
 	DataSource VolumeDataSource `json:"dataSource"`
+	// The placement of the replicas when the volume is created from another volume or snapshot. The data is always
+	// fully copied. colocate-with-source places the replicas on the disks of the source replicas when possible, so
+	// the data is copied within the disks instead of across nodes.
+	// +kubebuilder:validation:Enum=full-copy;colocate-with-source
+	// +optional
+	CloneMode VolumeCloneMode `json:"cloneMode"`
 	// +optional
 	DataLocality DataLocality `json:"dataLocality"`
 	// +optional
@@ -279,6 +435,9 @@ type VolumeSpec struct {
 	Migratable bool `json:"migratable"`
 	// +optional
 	Encrypted bool `json:"encrypted"`
+	// Requests rotating the LUKS passphrase of the encrypted volume to the one in the node stage secret.
+	// +optional
+	PassphraseRotationRequestedAt string `json:"passphraseRotationRequestedAt"`
 	// +optional
 	NumberOfReplicas int `json:"numberOfReplicas"`
 	// +optional
@@ -300,14 +459,70 @@ type VolumeSpec struct {
 	// +kubebuilder:validation:Type=string
 	// +optional
 	SnapshotMaxSize int64 `json:"snapshotMaxSize,string"`
+	// The maximum length of the snapshot chain of the volume. The oldest user created snapshots exceeding the
+	// length are deleted and coalesced into their children automatically. 0 means unlimited.
+	// +optional
+	SnapshotMaxChainLength int `json:"snapshotMaxChainLength"`
+	// The number of the newest snapshots of the volume kept by the snapshot retention policy. The older snapshots
+	// are deleted and purged automatically. 0 means unlimited.
+	// +optional
+	SnapshotRetentionKeepLast int `json:"snapshotRetentionKeepLast"`
+	// The maximum age of the snapshots of the volume kept by the snapshot retention policy, in the format of Go
+	// durations, e.g. "168h". The older snapshots are deleted and purged automatically. Empty means unlimited.
+	// +optional
+	SnapshotRetentionMaxAge string `json:"snapshotRetentionMaxAge"`
+	// The maximum total size of the snapshots of the volume kept by the snapshot retention policy. The oldest
+	// snapshots exceeding the size are deleted and purged automatically. 0 means unlimited.
+	// +kubebuilder:validation:Type=string
+	// +optional
+	SnapshotRetentionMaxSize int64 `json:"snapshotRetentionMaxSize,string"`
 	// Setting that freezes the filesystem on the root partition before a snapshot is created.
 	// +optional
 	FreezeFilesystemForSnapshot FreezeFilesystemForSnapshot `json:"freezeFilesystemForSnapshot"`
+	// Trim the filesystem of the volume before it's unstaged from a node, so the space freed by the workload is
+	// reclaimed without waiting for a recurring trim job.
+	// +optional
+	TrimFilesystemOnUnstage bool `json:"trimFilesystemOnUnstage"`
+	// The priority of the replica rebuilding of the volume. The replicas of the volumes with a higher priority are
+	// rebuilt first if the replica rebuild priority policy setting is volume-priority.
+	// +optional
+	RebuildPriority int `json:"rebuildPriority"`
+	// The time until which the IO of the attached volume is paused. The IO is resumed automatically once it passes.
+	// +optional
+	IOPauseDeadline string `json:"ioPauseDeadline"`
+	// The volume is expanded through its PVC automatically when its filesystem is filling up. It's disabled if not set.
+	// +optional
+	AutoResize *VolumeAutoResizePolicy `json:"autoResize,omitempty"`
+	// The maximum age in minutes of the last completed backup of the volume, over which the BackupFresh condition of
+	// the volume turns false. 0 means the backup freshness isn't tracked.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackupMaxAge int `json:"backupMaxAge"`
+	// Requests replacing the replicas not on the disks matching the disk selector, one replica at a time.
+	// +optional
+	DiskTagMigrationRequestedAt string `json:"diskTagMigrationRequestedAt"`
 	// The backup target name that the volume will be backed up to or is synced.
 	// +optional
 	BackupTargetName string `json:"backupTargetName"`
 }
 
+// VolumeAutoResizePolicy defines when and how much the volume is expanded automatically
+type VolumeAutoResizePolicy struct {
+	// The filesystem usage percentage over which the volume is expanded.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	// +optional
+	ThresholdPercentage int `json:"thresholdPercentage"`
+	// The size in bytes added to the volume in each expansion.
+	// +kubebuilder:validation:Type=string
+	// +optional
+	Increment int64 `json:"increment,string"`
+	// The size in bytes the volume is never expanded over.
+	// +kubebuilder:validation:Type=string
+	// +optional
+	MaxSize int64 `json:"maxSize,string"`
+}
+
 // VolumeStatus defines the observed state of the Longhorn volume
 type VolumeStatus struct {
 	// +optional
@@ -325,6 +540,10 @@ type VolumeStatus struct {
 	// +optional
 	// +nullable
 	Conditions []Condition `json:"conditions"`
+	// The breakdown of why the replicas of the volume cannot be scheduled. Empty once the volume is scheduled.
+	// +optional
+	// +nullable
+	ReplicaSchedulingFailures []ReplicaSchedulingFailure `json:"replicaSchedulingFailures"`
 	// +optional
 	LastBackup string `json:"lastBackup"`
 	// +optional
@@ -343,6 +562,14 @@ type VolumeStatus struct {
 	RestoreInitiated bool `json:"restoreInitiated"`
 	// +optional
 	CloneStatus VolumeCloneStatus `json:"cloneStatus"`
+	// The volumes being cloned from this volume. The volume cannot be deleted until the cloning completes.
+	// +optional
+	// +nullable
+	CloneTargets []string `json:"cloneTargets"`
+	// +optional
+	PassphraseRotationStatus VolumePassphraseRotationStatus `json:"passphraseRotationStatus"`
+	// +optional
+	DiskTagMigrationStatus VolumeDiskTagMigrationStatus `json:"diskTagMigrationStatus"`
 	// +optional
 	RemountRequestedAt string `json:"remountRequestedAt"`
 	// +optional
@@ -357,6 +584,22 @@ type VolumeStatus struct {
 	ShareEndpoint string `json:"shareEndpoint"`
 	// +optional
 	ShareState ShareManagerState `json:"shareState"`
+	// The operations waiting for a conflicting operation, since the expansion, the replica rebuild and the snapshot
+	// purge of the volume are not run concurrently.
+	// +optional
+	// +nullable
+	QueuedOperations []QueuedVolumeOperation `json:"queuedOperations"`
+	// The candidates of the last salvage when all the replicas failed, ranked by the revision counter, the last
+	// modified time and the snapshot checksum count.
+	// +optional
+	// +nullable
+	SalvageCandidates []SalvageCandidate `json:"salvageCandidates"`
+	// The replicas chosen by the last salvage and the reason.
+	// +optional
+	SalvageDecision string `json:"salvageDecision"`
+	// The last time the filesystem of the volume was trimmed by the volume controller.
+	// +optional
+	LastFilesystemTrimAt string `json:"lastFilesystemTrimAt"`
 }
 
 // +genclient
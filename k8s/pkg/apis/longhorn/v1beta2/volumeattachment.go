@@ -52,6 +52,9 @@ const (
 	AttacherTypeVolumeExpansionController        = AttacherType("volume-expansion-controller")
 	AttacherTypeBackingImageDataSourceController = AttacherType("bim-ds-controller")
 	AttacherTypeVolumeRebuildingController       = AttacherType("volume-rebuilding-controller")
+	AttacherTypeLiveMigrationController          = AttacherType("live-migration-controller")
+	AttacherTypeSnapshotRevertController         = AttacherType("snapshot-revert-controller")
+	AttacherTypeCrossDataEngineCloner            = AttacherType("cross-data-engine-cloner")
 )
 
 const (
@@ -67,6 +70,9 @@ const (
 	AttacherPriorityLevelVolumeEvictionController         = 800
 	AttacherPriorityLevelBackingImageDataSourceController = 800
 	AttachedPriorityLevelVolumeRebuildingController       = 800
+	AttacherPriorityLevelLiveMigrationController          = 800
+	AttacherPriorityLevelSnapshotRevertController         = 800
+	AttacherPriorityLevelCrossDataEngineCloner            = 800
 )
 
 const (
@@ -110,6 +116,12 @@ func GetAttacherPriorityLevel(t AttacherType) int {
 		return AttacherPriorityLevelVolumeExpansionController
 	case AttacherTypeBackingImageDataSourceController:
 		return AttacherPriorityLevelBackingImageDataSourceController
+	case AttacherTypeLiveMigrationController:
+		return AttacherPriorityLevelLiveMigrationController
+	case AttacherTypeSnapshotRevertController:
+		return AttacherPriorityLevelSnapshotRevertController
+	case AttacherTypeCrossDataEngineCloner:
+		return AttacherPriorityLevelCrossDataEngineCloner
 	default:
 		return 0
 	}
@@ -1379,6 +1379,21 @@ func (in *EngineStatus) DeepCopyInto(out *EngineStatus) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.ReplicaSalvageInfoMap != nil {
+		in, out := &in.ReplicaSalvageInfoMap, &out.ReplicaSalvageInfoMap
+		*out = make(map[string]*ReplicaSalvageInfo, len(*in))
+		for key, val := range *in {
+			var outVal *ReplicaSalvageInfo
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(ReplicaSalvageInfo)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
 	return
 }
 
@@ -1392,6 +1407,137 @@ func (in *EngineStatus) DeepCopy() *EngineStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EngineUpgradeMaintenanceWindow) DeepCopyInto(out *EngineUpgradeMaintenanceWindow) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EngineUpgradeMaintenanceWindow.
+func (in *EngineUpgradeMaintenanceWindow) DeepCopy() *EngineUpgradeMaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(EngineUpgradeMaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EngineUpgradePolicy) DeepCopyInto(out *EngineUpgradePolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EngineUpgradePolicy.
+func (in *EngineUpgradePolicy) DeepCopy() *EngineUpgradePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(EngineUpgradePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EngineUpgradePolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EngineUpgradePolicyList) DeepCopyInto(out *EngineUpgradePolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EngineUpgradePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EngineUpgradePolicyList.
+func (in *EngineUpgradePolicyList) DeepCopy() *EngineUpgradePolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(EngineUpgradePolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EngineUpgradePolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EngineUpgradePolicySpec) DeepCopyInto(out *EngineUpgradePolicySpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(EngineUpgradeMaintenanceWindow)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EngineUpgradePolicySpec.
+func (in *EngineUpgradePolicySpec) DeepCopy() *EngineUpgradePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EngineUpgradePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EngineUpgradePolicyStatus) DeepCopyInto(out *EngineUpgradePolicyStatus) {
+	*out = *in
+	if in.PendingVolumes != nil {
+		in, out := &in.PendingVolumes, &out.PendingVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UpgradingVolumes != nil {
+		in, out := &in.UpgradingVolumes, &out.UpgradingVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EngineUpgradePolicyStatus.
+func (in *EngineUpgradePolicyStatus) DeepCopy() *EngineUpgradePolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EngineUpgradePolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EngineVersionDetails) DeepCopyInto(out *EngineVersionDetails) {
 	*out = *in
@@ -1527,6 +1673,11 @@ func (in *InstanceManagerStatus) DeepCopyInto(out *InstanceManagerStatus) {
 		}
 	}
 	out.DataEngineStatus = in.DataEngineStatus
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		copy(*out, *in)
+	}
 	if in.Instances != nil {
 		in, out := &in.Instances, &out.Instances
 		*out = make(map[string]InstanceProcess, len(*in))
@@ -1662,6 +1813,193 @@ func (in *KubernetesStatus) DeepCopy() *KubernetesStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenancePolicy) DeepCopyInto(out *MaintenancePolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenancePolicy.
+func (in *MaintenancePolicy) DeepCopy() *MaintenancePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenancePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MaintenancePolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenancePolicyList) DeepCopyInto(out *MaintenancePolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MaintenancePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenancePolicyList.
+func (in *MaintenancePolicyList) DeepCopy() *MaintenancePolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenancePolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MaintenancePolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenancePolicyNodeRules) DeepCopyInto(out *MaintenancePolicyNodeRules) {
+	*out = *in
+	if in.AllowScheduling != nil {
+		in, out := &in.AllowScheduling, &out.AllowScheduling
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SoleReplicaDisallowed != nil {
+		in, out := &in.SoleReplicaDisallowed, &out.SoleReplicaDisallowed
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenancePolicyNodeRules.
+func (in *MaintenancePolicyNodeRules) DeepCopy() *MaintenancePolicyNodeRules {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenancePolicyNodeRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenancePolicySpec) DeepCopyInto(out *MaintenancePolicySpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VolumeRules != nil {
+		in, out := &in.VolumeRules, &out.VolumeRules
+		*out = new(MaintenancePolicyVolumeRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeRules != nil {
+		in, out := &in.NodeRules, &out.NodeRules
+		*out = new(MaintenancePolicyNodeRules)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenancePolicySpec.
+func (in *MaintenancePolicySpec) DeepCopy() *MaintenancePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenancePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenancePolicyStatus) DeepCopyInto(out *MaintenancePolicyStatus) {
+	*out = *in
+	if in.MatchedResources != nil {
+		in, out := &in.MatchedResources, &out.MatchedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenancePolicyStatus.
+func (in *MaintenancePolicyStatus) DeepCopy() *MaintenancePolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenancePolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenancePolicyVolumeRules) DeepCopyInto(out *MaintenancePolicyVolumeRules) {
+	*out = *in
+	if in.NumberOfReplicas != nil {
+		in, out := &in.NumberOfReplicas, &out.NumberOfReplicas
+		*out = new(int)
+		**out = **in
+	}
+	if in.DataLocality != nil {
+		in, out := &in.DataLocality, &out.DataLocality
+		*out = new(DataLocality)
+		**out = **in
+	}
+	if in.SnapshotMaxCount != nil {
+		in, out := &in.SnapshotMaxCount, &out.SnapshotMaxCount
+		*out = new(int)
+		**out = **in
+	}
+	if in.TrimFilesystemOnUnstage != nil {
+		in, out := &in.TrimFilesystemOnUnstage, &out.TrimFilesystemOnUnstage
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UnmapMarkSnapChainRemoved != nil {
+		in, out := &in.UnmapMarkSnapChainRemoved, &out.UnmapMarkSnapChainRemoved
+		*out = new(UnmapMarkSnapChainRemoved)
+		**out = **in
+	}
+	if in.RecurringBackupDisabled != nil {
+		in, out := &in.RecurringBackupDisabled, &out.RecurringBackupDisabled
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenancePolicyVolumeRules.
+func (in *MaintenancePolicyVolumeRules) DeepCopy() *MaintenancePolicyVolumeRules {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenancePolicyVolumeRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Node) DeepCopyInto(out *Node) {
 	*out = *in
@@ -1775,6 +2113,11 @@ func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
 		}
 	}
 	in.SnapshotCheckStatus.DeepCopyInto(&out.SnapshotCheckStatus)
+	if in.ReadyTransitionTimes != nil {
+		in, out := &in.ReadyTransitionTimes, &out.ReadyTransitionTimes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1909,6 +2252,22 @@ func (in *PurgeStatus) DeepCopy() *PurgeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueuedVolumeOperation) DeepCopyInto(out *QueuedVolumeOperation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueuedVolumeOperation.
+func (in *QueuedVolumeOperation) DeepCopy() *QueuedVolumeOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(QueuedVolumeOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RebuildStatus) DeepCopyInto(out *RebuildStatus) {
 	*out = *in
@@ -2098,6 +2457,38 @@ func (in *ReplicaList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaSalvageInfo) DeepCopyInto(out *ReplicaSalvageInfo) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaSalvageInfo.
+func (in *ReplicaSalvageInfo) DeepCopy() *ReplicaSalvageInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaSalvageInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaSchedulingFailure) DeepCopyInto(out *ReplicaSchedulingFailure) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaSchedulingFailure.
+func (in *ReplicaSchedulingFailure) DeepCopy() *ReplicaSchedulingFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaSchedulingFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplicaSpec) DeepCopyInto(out *ReplicaSpec) {
 	*out = *in
@@ -2148,12 +2539,28 @@ func (in *RestoreStatus) DeepCopy() *RestoreStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SalvageCandidate) DeepCopyInto(out *SalvageCandidate) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SalvageCandidate.
+func (in *SalvageCandidate) DeepCopy() *SalvageCandidate {
+	if in == nil {
+		return nil
+	}
+	out := new(SalvageCandidate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Setting) DeepCopyInto(out *Setting) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -2211,6 +2618,11 @@ func (in *SettingList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SettingStatus) DeepCopyInto(out *SettingStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -2973,6 +3385,22 @@ func (in *VolumeAttachmentStatus) DeepCopy() *VolumeAttachmentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeAutoResizePolicy) DeepCopyInto(out *VolumeAutoResizePolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeAutoResizePolicy.
+func (in *VolumeAutoResizePolicy) DeepCopy() *VolumeAutoResizePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeAutoResizePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeCloneStatus) DeepCopyInto(out *VolumeCloneStatus) {
 	*out = *in
@@ -2989,6 +3417,27 @@ func (in *VolumeCloneStatus) DeepCopy() *VolumeCloneStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeDiskTagMigrationStatus) DeepCopyInto(out *VolumeDiskTagMigrationStatus) {
+	*out = *in
+	if in.DiskSelector != nil {
+		in, out := &in.DiskSelector, &out.DiskSelector
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeDiskTagMigrationStatus.
+func (in *VolumeDiskTagMigrationStatus) DeepCopy() *VolumeDiskTagMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeDiskTagMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeList) DeepCopyInto(out *VolumeList) {
 	*out = *in
@@ -3060,6 +3509,22 @@ func (in *VolumeRecurringJobInfo) DeepCopy() *VolumeRecurringJobInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumePassphraseRotationStatus) DeepCopyInto(out *VolumePassphraseRotationStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumePassphraseRotationStatus.
+func (in *VolumePassphraseRotationStatus) DeepCopy() *VolumePassphraseRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumePassphraseRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeSpec) DeepCopyInto(out *VolumeSpec) {
 	*out = *in
@@ -3073,6 +3538,11 @@ func (in *VolumeSpec) DeepCopyInto(out *VolumeSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.AutoResize != nil {
+		in, out := &in.AutoResize, &out.AutoResize
+		*out = new(VolumeAutoResizePolicy)
+		**out = **in
+	}
 	return
 }
 
@@ -3095,7 +3565,29 @@ func (in *VolumeStatus) DeepCopyInto(out *VolumeStatus) {
 		*out = make([]Condition, len(*in))
 		copy(*out, *in)
 	}
+	if in.ReplicaSchedulingFailures != nil {
+		in, out := &in.ReplicaSchedulingFailures, &out.ReplicaSchedulingFailures
+		*out = make([]ReplicaSchedulingFailure, len(*in))
+		copy(*out, *in)
+	}
 	out.CloneStatus = in.CloneStatus
+	if in.CloneTargets != nil {
+		in, out := &in.CloneTargets, &out.CloneTargets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.PassphraseRotationStatus = in.PassphraseRotationStatus
+	in.DiskTagMigrationStatus.DeepCopyInto(&out.DiskTagMigrationStatus)
+	if in.QueuedOperations != nil {
+		in, out := &in.QueuedOperations, &out.QueuedOperations
+		*out = make([]QueuedVolumeOperation, len(*in))
+		copy(*out, *in)
+	}
+	if in.SalvageCandidates != nil {
+		in, out := &in.SalvageCandidates, &out.SalvageCandidates
+		*out = make([]SalvageCandidate, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
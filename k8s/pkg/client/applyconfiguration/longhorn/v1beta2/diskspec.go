@@ -25,13 +25,14 @@ import (
 // DiskSpecApplyConfiguration represents a declarative configuration of the DiskSpec type for use
 // with apply.
 type DiskSpecApplyConfiguration struct {
-	Type              *longhornv1beta2.DiskType   `json:"diskType,omitempty"`
-	Path              *string                     `json:"path,omitempty"`
-	DiskDriver        *longhornv1beta2.DiskDriver `json:"diskDriver,omitempty"`
-	AllowScheduling   *bool                       `json:"allowScheduling,omitempty"`
-	EvictionRequested *bool                       `json:"evictionRequested,omitempty"`
-	StorageReserved   *int64                      `json:"storageReserved,omitempty"`
-	Tags              []string                    `json:"tags,omitempty"`
+	Type                          *longhornv1beta2.DiskType   `json:"diskType,omitempty"`
+	Path                          *string                     `json:"path,omitempty"`
+	DiskDriver                    *longhornv1beta2.DiskDriver `json:"diskDriver,omitempty"`
+	AllowScheduling               *bool                       `json:"allowScheduling,omitempty"`
+	EvictionRequested             *bool                       `json:"evictionRequested,omitempty"`
+	StorageReserved               *int64                      `json:"storageReserved,omitempty"`
+	Tags                          []string                    `json:"tags,omitempty"`
+	ConcurrentReplicaRebuildLimit *int                        `json:"concurrentReplicaRebuildLimit,omitempty"`
 }
 
 // DiskSpecApplyConfiguration constructs a declarative configuration of the DiskSpec type for use with
@@ -97,3 +98,11 @@ func (b *DiskSpecApplyConfiguration) WithTags(values ...string) *DiskSpecApplyCo
 	}
 	return b
 }
+
+// WithConcurrentReplicaRebuildLimit sets the ConcurrentReplicaRebuildLimit field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ConcurrentReplicaRebuildLimit field is set to the value of the last call.
+func (b *DiskSpecApplyConfiguration) WithConcurrentReplicaRebuildLimit(value int) *DiskSpecApplyConfiguration {
+	b.ConcurrentReplicaRebuildLimit = &value
+	return b
+}
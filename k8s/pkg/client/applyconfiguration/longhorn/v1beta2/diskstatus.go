@@ -38,6 +38,8 @@ type DiskStatusApplyConfiguration struct {
 	DiskDriver            *longhornv1beta2.DiskDriver   `json:"diskDriver,omitempty"`
 	FSType                *string                       `json:"filesystemType,omitempty"`
 	InstanceManagerName   *string                       `json:"instanceManagerName,omitempty"`
+	IOUtilization         *int                          `json:"ioUtilization,omitempty"`
+	IOLatency             *int64                        `json:"ioLatency,omitempty"`
 }
 
 // DiskStatusApplyConfiguration constructs a declarative configuration of the DiskStatus type for use with
@@ -166,3 +168,19 @@ func (b *DiskStatusApplyConfiguration) WithInstanceManagerName(value string) *Di
 	b.InstanceManagerName = &value
 	return b
 }
+
+// WithIOUtilization sets the IOUtilization field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the IOUtilization field is set to the value of the last call.
+func (b *DiskStatusApplyConfiguration) WithIOUtilization(value int) *DiskStatusApplyConfiguration {
+	b.IOUtilization = &value
+	return b
+}
+
+// WithIOLatency sets the IOLatency field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the IOLatency field is set to the value of the last call.
+func (b *DiskStatusApplyConfiguration) WithIOLatency(value int64) *DiskStatusApplyConfiguration {
+	b.IOLatency = &value
+	return b
+}
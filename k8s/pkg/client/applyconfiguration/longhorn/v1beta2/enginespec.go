@@ -37,6 +37,7 @@ type EngineSpecApplyConfiguration struct {
 	Active                           *bool                             `json:"active,omitempty"`
 	SnapshotMaxCount                 *int                              `json:"snapshotMaxCount,omitempty"`
 	SnapshotMaxSize                  *int64                            `json:"snapshotMaxSize,omitempty"`
+	IOPauseDeadline                  *string                           `json:"ioPauseDeadline,omitempty"`
 }
 
 // EngineSpecApplyConfiguration constructs a declarative configuration of the EngineSpec type for use with
@@ -152,3 +153,11 @@ func (b *EngineSpecApplyConfiguration) WithSnapshotMaxSize(value int64) *EngineS
 	b.SnapshotMaxSize = &value
 	return b
 }
+
+// WithIOPauseDeadline sets the IOPauseDeadline field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the IOPauseDeadline field is set to the value of the last call.
+func (b *EngineSpecApplyConfiguration) WithIOPauseDeadline(value string) *EngineSpecApplyConfiguration {
+	b.IOPauseDeadline = &value
+	return b
+}
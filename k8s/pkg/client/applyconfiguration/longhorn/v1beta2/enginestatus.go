@@ -44,6 +44,8 @@ type EngineStatusApplyConfiguration struct {
 	UnmapMarkSnapChainRemovedEnabled *bool                                           `json:"unmapMarkSnapChainRemovedEnabled,omitempty"`
 	SnapshotMaxCount                 *int                                            `json:"snapshotMaxCount,omitempty"`
 	SnapshotMaxSize                  *int64                                          `json:"snapshotMaxSize,omitempty"`
+	IOPaused                         *bool                                           `json:"ioPaused,omitempty"`
+	ReplicaSalvageInfoMap            map[string]*longhornv1beta2.ReplicaSalvageInfo  `json:"replicaSalvageInfoMap,omitempty"`
 }
 
 // EngineStatusApplyConfiguration constructs a declarative configuration of the EngineStatus type for use with
@@ -257,3 +259,25 @@ func (b *EngineStatusApplyConfiguration) WithSnapshotMaxSize(value int64) *Engin
 	b.SnapshotMaxSize = &value
 	return b
 }
+
+// WithIOPaused sets the IOPaused field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the IOPaused field is set to the value of the last call.
+func (b *EngineStatusApplyConfiguration) WithIOPaused(value bool) *EngineStatusApplyConfiguration {
+	b.IOPaused = &value
+	return b
+}
+
+// WithReplicaSalvageInfoMap puts the entries into the ReplicaSalvageInfoMap field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the ReplicaSalvageInfoMap field,
+// overwriting an existing map entries in ReplicaSalvageInfoMap field with the same key.
+func (b *EngineStatusApplyConfiguration) WithReplicaSalvageInfoMap(entries map[string]*longhornv1beta2.ReplicaSalvageInfo) *EngineStatusApplyConfiguration {
+	if b.ReplicaSalvageInfoMap == nil && len(entries) > 0 {
+		b.ReplicaSalvageInfoMap = make(map[string]*longhornv1beta2.ReplicaSalvageInfo, len(entries))
+	}
+	for k, v := range entries {
+		b.ReplicaSalvageInfoMap[k] = v
+	}
+	return b
+}
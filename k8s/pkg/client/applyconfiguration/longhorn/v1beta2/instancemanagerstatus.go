@@ -36,6 +36,7 @@ type InstanceManagerStatusApplyConfiguration struct {
 	ProxyAPIMinVersion *int                                                `json:"proxyApiMinVersion,omitempty"`
 	ProxyAPIVersion    *int                                                `json:"proxyApiVersion,omitempty"`
 	DataEngineStatus   *DataEngineStatusApplyConfiguration                 `json:"dataEngineStatus,omitempty"`
+	Conditions         []ConditionApplyConfiguration                       `json:"conditions,omitempty"`
 	Instances          map[string]InstanceProcessApplyConfiguration        `json:"instances,omitempty"`
 }
 
@@ -151,6 +152,19 @@ func (b *InstanceManagerStatusApplyConfiguration) WithDataEngineStatus(value *Da
 	return b
 }
 
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *InstanceManagerStatusApplyConfiguration) WithConditions(values ...*ConditionApplyConfiguration) *InstanceManagerStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConditions")
+		}
+		b.Conditions = append(b.Conditions, *values[i])
+	}
+	return b
+}
+
 // WithInstances puts the entries into the Instances field in the declarative configuration
 // and returns the receiver, so that objects can be build by chaining "With" function invocations.
 // If called multiple times, the entries provided by each call will be put on the Instances field,
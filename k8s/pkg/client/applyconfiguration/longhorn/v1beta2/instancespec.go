@@ -33,6 +33,7 @@ type InstanceSpecApplyConfiguration struct {
 	DesireState        *longhornv1beta2.InstanceState          `json:"desireState,omitempty"`
 	LogRequested       *bool                                   `json:"logRequested,omitempty"`
 	SalvageRequested   *bool                                   `json:"salvageRequested,omitempty"`
+	RebindRequestedAt  *string                                 `json:"rebindRequestedAt,omitempty"`
 	BackendStoreDriver *longhornv1beta2.BackendStoreDriverType `json:"backendStoreDriver,omitempty"`
 	DataEngine         *longhornv1beta2.DataEngineType         `json:"dataEngine,omitempty"`
 }
@@ -107,6 +108,14 @@ func (b *InstanceSpecApplyConfiguration) WithSalvageRequested(value bool) *Insta
 	return b
 }
 
+// WithRebindRequestedAt sets the RebindRequestedAt field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RebindRequestedAt field is set to the value of the last call.
+func (b *InstanceSpecApplyConfiguration) WithRebindRequestedAt(value string) *InstanceSpecApplyConfiguration {
+	b.RebindRequestedAt = &value
+	return b
+}
+
 // WithBackendStoreDriver sets the BackendStoreDriver field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the BackendStoreDriver field is set to the value of the last call.
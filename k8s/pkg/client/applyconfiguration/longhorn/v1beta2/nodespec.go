@@ -21,12 +21,13 @@ package v1beta2
 // NodeSpecApplyConfiguration represents a declarative configuration of the NodeSpec type for use
 // with apply.
 type NodeSpecApplyConfiguration struct {
-	Name                      *string                               `json:"name,omitempty"`
-	Disks                     map[string]DiskSpecApplyConfiguration `json:"disks,omitempty"`
-	AllowScheduling           *bool                                 `json:"allowScheduling,omitempty"`
-	EvictionRequested         *bool                                 `json:"evictionRequested,omitempty"`
-	Tags                      []string                              `json:"tags,omitempty"`
-	InstanceManagerCPURequest *int                                  `json:"instanceManagerCPURequest,omitempty"`
+	Name                         *string                               `json:"name,omitempty"`
+	Disks                        map[string]DiskSpecApplyConfiguration `json:"disks,omitempty"`
+	AllowScheduling              *bool                                 `json:"allowScheduling,omitempty"`
+	EvictionRequested            *bool                                 `json:"evictionRequested,omitempty"`
+	Tags                         []string                              `json:"tags,omitempty"`
+	InstanceManagerCPURequest    *int                                  `json:"instanceManagerCPURequest,omitempty"`
+	InstanceManagerMemoryRequest *int                                  `json:"instanceManagerMemoryRequest,omitempty"`
 }
 
 // NodeSpecApplyConfiguration constructs a declarative configuration of the NodeSpec type for use with
@@ -90,3 +91,11 @@ func (b *NodeSpecApplyConfiguration) WithInstanceManagerCPURequest(value int) *N
 	b.InstanceManagerCPURequest = &value
 	return b
 }
+
+// WithInstanceManagerMemoryRequest sets the InstanceManagerMemoryRequest field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the InstanceManagerMemoryRequest field is set to the value of the last call.
+func (b *NodeSpecApplyConfiguration) WithInstanceManagerMemoryRequest(value int) *NodeSpecApplyConfiguration {
+	b.InstanceManagerMemoryRequest = &value
+	return b
+}
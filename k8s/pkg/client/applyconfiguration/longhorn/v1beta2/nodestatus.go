@@ -25,12 +25,14 @@ import (
 // NodeStatusApplyConfiguration represents a declarative configuration of the NodeStatus type for use
 // with apply.
 type NodeStatusApplyConfiguration struct {
-	Conditions          []ConditionApplyConfiguration          `json:"conditions,omitempty"`
-	DiskStatus          map[string]*longhornv1beta2.DiskStatus `json:"diskStatus,omitempty"`
-	Region              *string                                `json:"region,omitempty"`
-	Zone                *string                                `json:"zone,omitempty"`
-	SnapshotCheckStatus *SnapshotCheckStatusApplyConfiguration `json:"snapshotCheckStatus,omitempty"`
-	AutoEvicting        *bool                                  `json:"autoEvicting,omitempty"`
+	Conditions              []ConditionApplyConfiguration          `json:"conditions,omitempty"`
+	DiskStatus              map[string]*longhornv1beta2.DiskStatus `json:"diskStatus,omitempty"`
+	Region                  *string                                `json:"region,omitempty"`
+	Zone                    *string                                `json:"zone,omitempty"`
+	SnapshotCheckStatus     *SnapshotCheckStatusApplyConfiguration `json:"snapshotCheckStatus,omitempty"`
+	AutoEvicting            *bool                                  `json:"autoEvicting,omitempty"`
+	ReadyTransitionTimes    []string                               `json:"readyTransitionTimes,omitempty"`
+	SchedulingCooldownUntil *string                                `json:"schedulingCooldownUntil,omitempty"`
 }
 
 // NodeStatusApplyConfiguration constructs a declarative configuration of the NodeStatus type for use with
@@ -97,3 +99,21 @@ func (b *NodeStatusApplyConfiguration) WithAutoEvicting(value bool) *NodeStatusA
 	b.AutoEvicting = &value
 	return b
 }
+
+// WithReadyTransitionTimes adds the given value to the ReadyTransitionTimes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ReadyTransitionTimes field.
+func (b *NodeStatusApplyConfiguration) WithReadyTransitionTimes(values ...string) *NodeStatusApplyConfiguration {
+	for i := range values {
+		b.ReadyTransitionTimes = append(b.ReadyTransitionTimes, values[i])
+	}
+	return b
+}
+
+// WithSchedulingCooldownUntil sets the SchedulingCooldownUntil field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SchedulingCooldownUntil field is set to the value of the last call.
+func (b *NodeStatusApplyConfiguration) WithSchedulingCooldownUntil(value string) *NodeStatusApplyConfiguration {
+	b.SchedulingCooldownUntil = &value
+	return b
+}
@@ -36,6 +36,8 @@ type ReplicaSpecApplyConfiguration struct {
 	RevisionCounterDisabled          *bool   `json:"revisionCounterDisabled,omitempty"`
 	UnmapMarkDiskChainRemovedEnabled *bool   `json:"unmapMarkDiskChainRemovedEnabled,omitempty"`
 	RebuildRetryCount                *int    `json:"rebuildRetryCount,omitempty"`
+	RebuildPaused                    *bool   `json:"rebuildPaused,omitempty"`
+	RebuildPriority                  *int    `json:"rebuildPriority,omitempty"`
 	EvictionRequested                *bool   `json:"evictionRequested,omitempty"`
 	SnapshotMaxCount                 *int    `json:"snapshotMaxCount,omitempty"`
 	SnapshotMaxSize                  *int64  `json:"snapshotMaxSize,omitempty"`
@@ -167,6 +169,22 @@ func (b *ReplicaSpecApplyConfiguration) WithRebuildRetryCount(value int) *Replic
 	return b
 }
 
+// WithRebuildPaused sets the RebuildPaused field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RebuildPaused field is set to the value of the last call.
+func (b *ReplicaSpecApplyConfiguration) WithRebuildPaused(value bool) *ReplicaSpecApplyConfiguration {
+	b.RebuildPaused = &value
+	return b
+}
+
+// WithRebuildPriority sets the RebuildPriority field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RebuildPriority field is set to the value of the last call.
+func (b *ReplicaSpecApplyConfiguration) WithRebuildPriority(value int) *ReplicaSpecApplyConfiguration {
+	b.RebuildPriority = &value
+	return b
+}
+
 // WithEvictionRequested sets the EvictionRequested field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the EvictionRequested field is set to the value of the last call.
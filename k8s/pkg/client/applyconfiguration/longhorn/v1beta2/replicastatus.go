@@ -18,10 +18,17 @@ limitations under the License.
 
 package v1beta2
 
+import (
+	longhornv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
 // ReplicaStatusApplyConfiguration represents a declarative configuration of the ReplicaStatus type for use
 // with apply.
 type ReplicaStatusApplyConfiguration struct {
-	EvictionRequested *bool `json:"evictionRequested,omitempty"`
+	EvictionRequested *bool                               `json:"evictionRequested,omitempty"`
+	ScrubStartedAt    *string                             `json:"scrubStartedAt,omitempty"`
+	LastScrubbedAt    *string                             `json:"lastScrubbedAt,omitempty"`
+	LastScrubResult   *longhornv1beta2.ReplicaScrubResult `json:"lastScrubResult,omitempty"`
 }
 
 // ReplicaStatusApplyConfiguration constructs a declarative configuration of the ReplicaStatus type for use with
@@ -37,3 +44,27 @@ func (b *ReplicaStatusApplyConfiguration) WithEvictionRequested(value bool) *Rep
 	b.EvictionRequested = &value
 	return b
 }
+
+// WithScrubStartedAt sets the ScrubStartedAt field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ScrubStartedAt field is set to the value of the last call.
+func (b *ReplicaStatusApplyConfiguration) WithScrubStartedAt(value string) *ReplicaStatusApplyConfiguration {
+	b.ScrubStartedAt = &value
+	return b
+}
+
+// WithLastScrubbedAt sets the LastScrubbedAt field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastScrubbedAt field is set to the value of the last call.
+func (b *ReplicaStatusApplyConfiguration) WithLastScrubbedAt(value string) *ReplicaStatusApplyConfiguration {
+	b.LastScrubbedAt = &value
+	return b
+}
+
+// WithLastScrubResult sets the LastScrubResult field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastScrubResult field is set to the value of the last call.
+func (b *ReplicaStatusApplyConfiguration) WithLastScrubResult(value longhornv1beta2.ReplicaScrubResult) *ReplicaStatusApplyConfiguration {
+	b.LastScrubResult = &value
+	return b
+}
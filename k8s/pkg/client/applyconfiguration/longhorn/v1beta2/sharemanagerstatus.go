@@ -25,9 +25,10 @@ import (
 // ShareManagerStatusApplyConfiguration represents a declarative configuration of the ShareManagerStatus type for use
 // with apply.
 type ShareManagerStatusApplyConfiguration struct {
-	OwnerID  *string                            `json:"ownerID,omitempty"`
-	State    *longhornv1beta2.ShareManagerState `json:"state,omitempty"`
-	Endpoint *string                            `json:"endpoint,omitempty"`
+	OwnerID        *string                            `json:"ownerID,omitempty"`
+	State          *longhornv1beta2.ShareManagerState `json:"state,omitempty"`
+	Endpoint       *string                            `json:"endpoint,omitempty"`
+	FilesystemSize *int64                             `json:"filesystemSize,omitempty"`
 }
 
 // ShareManagerStatusApplyConfiguration constructs a declarative configuration of the ShareManagerStatus type for use with
@@ -59,3 +60,11 @@ func (b *ShareManagerStatusApplyConfiguration) WithEndpoint(value string) *Share
 	b.Endpoint = &value
 	return b
 }
+
+// WithFilesystemSize sets the FilesystemSize field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FilesystemSize field is set to the value of the last call.
+func (b *ShareManagerStatusApplyConfiguration) WithFilesystemSize(value int64) *ShareManagerStatusApplyConfiguration {
+	b.FilesystemSize = &value
+	return b
+}
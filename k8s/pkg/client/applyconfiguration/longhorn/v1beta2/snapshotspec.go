@@ -21,9 +21,10 @@ package v1beta2
 // SnapshotSpecApplyConfiguration represents a declarative configuration of the SnapshotSpec type for use
 // with apply.
 type SnapshotSpecApplyConfiguration struct {
-	Volume         *string           `json:"volume,omitempty"`
-	CreateSnapshot *bool             `json:"createSnapshot,omitempty"`
-	Labels         map[string]string `json:"labels,omitempty"`
+	Volume          *string           `json:"volume,omitempty"`
+	CreateSnapshot  *bool             `json:"createSnapshot,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	RevertRequested *bool             `json:"revertRequested,omitempty"`
 }
 
 // SnapshotSpecApplyConfiguration constructs a declarative configuration of the SnapshotSpec type for use with
@@ -61,3 +62,11 @@ func (b *SnapshotSpecApplyConfiguration) WithLabels(entries map[string]string) *
 	}
 	return b
 }
+
+// WithRevertRequested sets the RevertRequested field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RevertRequested field is set to the value of the last call.
+func (b *SnapshotSpecApplyConfiguration) WithRevertRequested(value bool) *SnapshotSpecApplyConfiguration {
+	b.RevertRequested = &value
+	return b
+}
@@ -18,21 +18,27 @@ limitations under the License.
 
 package v1beta2
 
+import (
+	longhornv1beta2 "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
 // SnapshotStatusApplyConfiguration represents a declarative configuration of the SnapshotStatus type for use
 // with apply.
 type SnapshotStatusApplyConfiguration struct {
-	Parent       *string           `json:"parent,omitempty"`
-	Children     map[string]bool   `json:"children,omitempty"`
-	MarkRemoved  *bool             `json:"markRemoved,omitempty"`
-	UserCreated  *bool             `json:"userCreated,omitempty"`
-	CreationTime *string           `json:"creationTime,omitempty"`
-	Size         *int64            `json:"size,omitempty"`
-	Labels       map[string]string `json:"labels,omitempty"`
-	OwnerID      *string           `json:"ownerID,omitempty"`
-	Error        *string           `json:"error,omitempty"`
-	RestoreSize  *int64            `json:"restoreSize,omitempty"`
-	ReadyToUse   *bool             `json:"readyToUse,omitempty"`
-	Checksum     *string           `json:"checksum,omitempty"`
+	Parent        *string                              `json:"parent,omitempty"`
+	Children      map[string]bool                      `json:"children,omitempty"`
+	MarkRemoved   *bool                                `json:"markRemoved,omitempty"`
+	UserCreated   *bool                                `json:"userCreated,omitempty"`
+	CreationTime  *string                              `json:"creationTime,omitempty"`
+	Size          *int64                               `json:"size,omitempty"`
+	Labels        map[string]string                    `json:"labels,omitempty"`
+	OwnerID       *string                              `json:"ownerID,omitempty"`
+	Error         *string                              `json:"error,omitempty"`
+	RestoreSize   *int64                               `json:"restoreSize,omitempty"`
+	ReadyToUse    *bool                                `json:"readyToUse,omitempty"`
+	Checksum      *string                              `json:"checksum,omitempty"`
+	RevertState   *longhornv1beta2.SnapshotRevertState `json:"revertState,omitempty"`
+	RevertMessage *string                              `json:"revertMessage,omitempty"`
 }
 
 // SnapshotStatusApplyConfiguration constructs a declarative configuration of the SnapshotStatus type for use with
@@ -148,3 +154,19 @@ func (b *SnapshotStatusApplyConfiguration) WithChecksum(value string) *SnapshotS
 	b.Checksum = &value
 	return b
 }
+
+// WithRevertState sets the RevertState field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RevertState field is set to the value of the last call.
+func (b *SnapshotStatusApplyConfiguration) WithRevertState(value longhornv1beta2.SnapshotRevertState) *SnapshotStatusApplyConfiguration {
+	b.RevertState = &value
+	return b
+}
+
+// WithRevertMessage sets the RevertMessage field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RevertMessage field is set to the value of the last call.
+func (b *SnapshotStatusApplyConfiguration) WithRevertMessage(value string) *SnapshotStatusApplyConfiguration {
+	b.RevertMessage = &value
+	return b
+}
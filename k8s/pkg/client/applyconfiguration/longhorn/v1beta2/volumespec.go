@@ -25,41 +25,54 @@ import (
 // VolumeSpecApplyConfiguration represents a declarative configuration of the VolumeSpec type for use
 // with apply.
 type VolumeSpecApplyConfiguration struct {
-	Size                        *int64                                         `json:"size,omitempty"`
-	Frontend                    *longhornv1beta2.VolumeFrontend                `json:"frontend,omitempty"`
-	FromBackup                  *string                                        `json:"fromBackup,omitempty"`
-	RestoreVolumeRecurringJob   *longhornv1beta2.RestoreVolumeRecurringJobType `json:"restoreVolumeRecurringJob,omitempty"`
-	DataSource                  *longhornv1beta2.VolumeDataSource              `json:"dataSource,omitempty"`
-	DataLocality                *longhornv1beta2.DataLocality                  `json:"dataLocality,omitempty"`
-	StaleReplicaTimeout         *int                                           `json:"staleReplicaTimeout,omitempty"`
-	NodeID                      *string                                        `json:"nodeID,omitempty"`
-	MigrationNodeID             *string                                        `json:"migrationNodeID,omitempty"`
-	EngineImage                 *string                                        `json:"engineImage,omitempty"`
-	Image                       *string                                        `json:"image,omitempty"`
-	BackingImage                *string                                        `json:"backingImage,omitempty"`
-	Standby                     *bool                                          `json:"Standby,omitempty"`
-	DiskSelector                []string                                       `json:"diskSelector,omitempty"`
-	NodeSelector                []string                                       `json:"nodeSelector,omitempty"`
-	DisableFrontend             *bool                                          `json:"disableFrontend,omitempty"`
-	RevisionCounterDisabled     *bool                                          `json:"revisionCounterDisabled,omitempty"`
-	UnmapMarkSnapChainRemoved   *longhornv1beta2.UnmapMarkSnapChainRemoved     `json:"unmapMarkSnapChainRemoved,omitempty"`
-	ReplicaSoftAntiAffinity     *longhornv1beta2.ReplicaSoftAntiAffinity       `json:"replicaSoftAntiAffinity,omitempty"`
-	ReplicaZoneSoftAntiAffinity *longhornv1beta2.ReplicaZoneSoftAntiAffinity   `json:"replicaZoneSoftAntiAffinity,omitempty"`
-	ReplicaDiskSoftAntiAffinity *longhornv1beta2.ReplicaDiskSoftAntiAffinity   `json:"replicaDiskSoftAntiAffinity,omitempty"`
-	LastAttachedBy              *string                                        `json:"lastAttachedBy,omitempty"`
-	AccessMode                  *longhornv1beta2.AccessMode                    `json:"accessMode,omitempty"`
-	Migratable                  *bool                                          `json:"migratable,omitempty"`
-	Encrypted                   *bool                                          `json:"encrypted,omitempty"`
-	NumberOfReplicas            *int                                           `json:"numberOfReplicas,omitempty"`
-	ReplicaAutoBalance          *longhornv1beta2.ReplicaAutoBalance            `json:"replicaAutoBalance,omitempty"`
-	SnapshotDataIntegrity       *longhornv1beta2.SnapshotDataIntegrity         `json:"snapshotDataIntegrity,omitempty"`
-	BackupCompressionMethod     *longhornv1beta2.BackupCompressionMethod       `json:"backupCompressionMethod,omitempty"`
-	BackendStoreDriver          *longhornv1beta2.BackendStoreDriverType        `json:"backendStoreDriver,omitempty"`
-	DataEngine                  *longhornv1beta2.DataEngineType                `json:"dataEngine,omitempty"`
-	SnapshotMaxCount            *int                                           `json:"snapshotMaxCount,omitempty"`
-	SnapshotMaxSize             *int64                                         `json:"snapshotMaxSize,omitempty"`
-	FreezeFilesystemForSnapshot *longhornv1beta2.FreezeFilesystemForSnapshot   `json:"freezeFilesystemForSnapshot,omitempty"`
-	BackupTargetName            *string                                        `json:"backupTargetName,omitempty"`
+	Size                          *int64                                         `json:"size,omitempty"`
+	Frontend                      *longhornv1beta2.VolumeFrontend                `json:"frontend,omitempty"`
+	FromBackup                    *string                                        `json:"fromBackup,omitempty"`
+	RestoreVolumeRecurringJob     *longhornv1beta2.RestoreVolumeRecurringJobType `json:"restoreVolumeRecurringJob,omitempty"`
+	RestoreCredentialSecret       *string                                        `json:"restoreCredentialSecret,omitempty"`
+	DataSource                    *longhornv1beta2.VolumeDataSource              `json:"dataSource,omitempty"`
+	CloneMode                     *longhornv1beta2.VolumeCloneMode               `json:"cloneMode,omitempty"`
+	DataLocality                  *longhornv1beta2.DataLocality                  `json:"dataLocality,omitempty"`
+	StaleReplicaTimeout           *int                                           `json:"staleReplicaTimeout,omitempty"`
+	NodeID                        *string                                        `json:"nodeID,omitempty"`
+	MigrationNodeID               *string                                        `json:"migrationNodeID,omitempty"`
+	EngineImage                   *string                                        `json:"engineImage,omitempty"`
+	Image                         *string                                        `json:"image,omitempty"`
+	BackingImage                  *string                                        `json:"backingImage,omitempty"`
+	Standby                       *bool                                          `json:"Standby,omitempty"`
+	DiskSelector                  []string                                       `json:"diskSelector,omitempty"`
+	NodeSelector                  []string                                       `json:"nodeSelector,omitempty"`
+	DisableFrontend               *bool                                          `json:"disableFrontend,omitempty"`
+	RevisionCounterDisabled       *bool                                          `json:"revisionCounterDisabled,omitempty"`
+	UnmapMarkSnapChainRemoved     *longhornv1beta2.UnmapMarkSnapChainRemoved     `json:"unmapMarkSnapChainRemoved,omitempty"`
+	ReplicaSoftAntiAffinity       *longhornv1beta2.ReplicaSoftAntiAffinity       `json:"replicaSoftAntiAffinity,omitempty"`
+	ReplicaZoneSoftAntiAffinity   *longhornv1beta2.ReplicaZoneSoftAntiAffinity   `json:"replicaZoneSoftAntiAffinity,omitempty"`
+	ReplicaDiskSoftAntiAffinity   *longhornv1beta2.ReplicaDiskSoftAntiAffinity   `json:"replicaDiskSoftAntiAffinity,omitempty"`
+	LastAttachedBy                *string                                        `json:"lastAttachedBy,omitempty"`
+	AccessMode                    *longhornv1beta2.AccessMode                    `json:"accessMode,omitempty"`
+	Migratable                    *bool                                          `json:"migratable,omitempty"`
+	Encrypted                     *bool                                          `json:"encrypted,omitempty"`
+	PassphraseRotationRequestedAt *string                                        `json:"passphraseRotationRequestedAt,omitempty"`
+	NumberOfReplicas              *int                                           `json:"numberOfReplicas,omitempty"`
+	ReplicaAutoBalance            *longhornv1beta2.ReplicaAutoBalance            `json:"replicaAutoBalance,omitempty"`
+	SnapshotDataIntegrity         *longhornv1beta2.SnapshotDataIntegrity         `json:"snapshotDataIntegrity,omitempty"`
+	BackupCompressionMethod       *longhornv1beta2.BackupCompressionMethod       `json:"backupCompressionMethod,omitempty"`
+	BackendStoreDriver            *longhornv1beta2.BackendStoreDriverType        `json:"backendStoreDriver,omitempty"`
+	DataEngine                    *longhornv1beta2.DataEngineType                `json:"dataEngine,omitempty"`
+	SnapshotMaxCount              *int                                           `json:"snapshotMaxCount,omitempty"`
+	SnapshotMaxSize               *int64                                         `json:"snapshotMaxSize,omitempty"`
+	SnapshotMaxChainLength        *int                                           `json:"snapshotMaxChainLength,omitempty"`
+	SnapshotRetentionKeepLast     *int                                           `json:"snapshotRetentionKeepLast,omitempty"`
+	SnapshotRetentionMaxAge       *string                                        `json:"snapshotRetentionMaxAge,omitempty"`
+	SnapshotRetentionMaxSize      *int64                                         `json:"snapshotRetentionMaxSize,omitempty"`
+	FreezeFilesystemForSnapshot   *longhornv1beta2.FreezeFilesystemForSnapshot   `json:"freezeFilesystemForSnapshot,omitempty"`
+	TrimFilesystemOnUnstage       *bool                                          `json:"trimFilesystemOnUnstage,omitempty"`
+	RebuildPriority               *int                                           `json:"rebuildPriority,omitempty"`
+	IOPauseDeadline               *string                                        `json:"ioPauseDeadline,omitempty"`
+	AutoResize                    *VolumeAutoResizePolicyApplyConfiguration      `json:"autoResize,omitempty"`
+	BackupMaxAge                  *int                                           `json:"backupMaxAge,omitempty"`
+	DiskTagMigrationRequestedAt   *string                                        `json:"diskTagMigrationRequestedAt,omitempty"`
+	BackupTargetName              *string                                        `json:"backupTargetName,omitempty"`
 }
 
 // VolumeSpecApplyConfiguration constructs a declarative configuration of the VolumeSpec type for use with
@@ -100,6 +113,14 @@ func (b *VolumeSpecApplyConfiguration) WithRestoreVolumeRecurringJob(value longh
 	return b
 }
 
+// WithRestoreCredentialSecret sets the RestoreCredentialSecret field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RestoreCredentialSecret field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithRestoreCredentialSecret(value string) *VolumeSpecApplyConfiguration {
+	b.RestoreCredentialSecret = &value
+	return b
+}
+
 // WithDataSource sets the DataSource field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the DataSource field is set to the value of the last call.
@@ -108,6 +129,14 @@ func (b *VolumeSpecApplyConfiguration) WithDataSource(value longhornv1beta2.Volu
 	return b
 }
 
+// WithCloneMode sets the CloneMode field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CloneMode field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithCloneMode(value longhornv1beta2.VolumeCloneMode) *VolumeSpecApplyConfiguration {
+	b.CloneMode = &value
+	return b
+}
+
 // WithDataLocality sets the DataLocality field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the DataLocality field is set to the value of the last call.
@@ -272,6 +301,14 @@ func (b *VolumeSpecApplyConfiguration) WithEncrypted(value bool) *VolumeSpecAppl
 	return b
 }
 
+// WithPassphraseRotationRequestedAt sets the PassphraseRotationRequestedAt field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PassphraseRotationRequestedAt field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithPassphraseRotationRequestedAt(value string) *VolumeSpecApplyConfiguration {
+	b.PassphraseRotationRequestedAt = &value
+	return b
+}
+
 // WithNumberOfReplicas sets the NumberOfReplicas field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the NumberOfReplicas field is set to the value of the last call.
@@ -336,6 +373,38 @@ func (b *VolumeSpecApplyConfiguration) WithSnapshotMaxSize(value int64) *VolumeS
 	return b
 }
 
+// WithSnapshotMaxChainLength sets the SnapshotMaxChainLength field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SnapshotMaxChainLength field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithSnapshotMaxChainLength(value int) *VolumeSpecApplyConfiguration {
+	b.SnapshotMaxChainLength = &value
+	return b
+}
+
+// WithSnapshotRetentionKeepLast sets the SnapshotRetentionKeepLast field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SnapshotRetentionKeepLast field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithSnapshotRetentionKeepLast(value int) *VolumeSpecApplyConfiguration {
+	b.SnapshotRetentionKeepLast = &value
+	return b
+}
+
+// WithSnapshotRetentionMaxAge sets the SnapshotRetentionMaxAge field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SnapshotRetentionMaxAge field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithSnapshotRetentionMaxAge(value string) *VolumeSpecApplyConfiguration {
+	b.SnapshotRetentionMaxAge = &value
+	return b
+}
+
+// WithSnapshotRetentionMaxSize sets the SnapshotRetentionMaxSize field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SnapshotRetentionMaxSize field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithSnapshotRetentionMaxSize(value int64) *VolumeSpecApplyConfiguration {
+	b.SnapshotRetentionMaxSize = &value
+	return b
+}
+
 // WithFreezeFilesystemForSnapshot sets the FreezeFilesystemForSnapshot field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the FreezeFilesystemForSnapshot field is set to the value of the last call.
@@ -344,6 +413,54 @@ func (b *VolumeSpecApplyConfiguration) WithFreezeFilesystemForSnapshot(value lon
 	return b
 }
 
+// WithTrimFilesystemOnUnstage sets the TrimFilesystemOnUnstage field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TrimFilesystemOnUnstage field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithTrimFilesystemOnUnstage(value bool) *VolumeSpecApplyConfiguration {
+	b.TrimFilesystemOnUnstage = &value
+	return b
+}
+
+// WithRebuildPriority sets the RebuildPriority field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RebuildPriority field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithRebuildPriority(value int) *VolumeSpecApplyConfiguration {
+	b.RebuildPriority = &value
+	return b
+}
+
+// WithIOPauseDeadline sets the IOPauseDeadline field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the IOPauseDeadline field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithIOPauseDeadline(value string) *VolumeSpecApplyConfiguration {
+	b.IOPauseDeadline = &value
+	return b
+}
+
+// WithAutoResize sets the AutoResize field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AutoResize field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithAutoResize(value *VolumeAutoResizePolicyApplyConfiguration) *VolumeSpecApplyConfiguration {
+	b.AutoResize = value
+	return b
+}
+
+// WithDiskTagMigrationRequestedAt sets the DiskTagMigrationRequestedAt field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DiskTagMigrationRequestedAt field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithDiskTagMigrationRequestedAt(value string) *VolumeSpecApplyConfiguration {
+	b.DiskTagMigrationRequestedAt = &value
+	return b
+}
+
+// WithBackupMaxAge sets the BackupMaxAge field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the BackupMaxAge field is set to the value of the last call.
+func (b *VolumeSpecApplyConfiguration) WithBackupMaxAge(value int) *VolumeSpecApplyConfiguration {
+	b.BackupMaxAge = &value
+	return b
+}
+
 // WithBackupTargetName sets the BackupTargetName field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the BackupTargetName field is set to the value of the last call.
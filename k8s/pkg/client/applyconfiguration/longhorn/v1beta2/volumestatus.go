@@ -25,28 +25,35 @@ import (
 // VolumeStatusApplyConfiguration represents a declarative configuration of the VolumeStatus type for use
 // with apply.
 type VolumeStatusApplyConfiguration struct {
-	OwnerID                *string                              `json:"ownerID,omitempty"`
-	State                  *longhornv1beta2.VolumeState         `json:"state,omitempty"`
-	Robustness             *longhornv1beta2.VolumeRobustness    `json:"robustness,omitempty"`
-	CurrentNodeID          *string                              `json:"currentNodeID,omitempty"`
-	CurrentImage           *string                              `json:"currentImage,omitempty"`
-	KubernetesStatus       *KubernetesStatusApplyConfiguration  `json:"kubernetesStatus,omitempty"`
-	Conditions             []ConditionApplyConfiguration        `json:"conditions,omitempty"`
-	LastBackup             *string                              `json:"lastBackup,omitempty"`
-	LastBackupAt           *string                              `json:"lastBackupAt,omitempty"`
-	PendingNodeID          *string                              `json:"pendingNodeID,omitempty"`
-	CurrentMigrationNodeID *string                              `json:"currentMigrationNodeID,omitempty"`
-	FrontendDisabled       *bool                                `json:"frontendDisabled,omitempty"`
-	RestoreRequired        *bool                                `json:"restoreRequired,omitempty"`
-	RestoreInitiated       *bool                                `json:"restoreInitiated,omitempty"`
-	CloneStatus            *VolumeCloneStatusApplyConfiguration `json:"cloneStatus,omitempty"`
-	RemountRequestedAt     *string                              `json:"remountRequestedAt,omitempty"`
-	ExpansionRequired      *bool                                `json:"expansionRequired,omitempty"`
-	IsStandby              *bool                                `json:"isStandby,omitempty"`
-	ActualSize             *int64                               `json:"actualSize,omitempty"`
-	LastDegradedAt         *string                              `json:"lastDegradedAt,omitempty"`
-	ShareEndpoint          *string                              `json:"shareEndpoint,omitempty"`
-	ShareState             *longhornv1beta2.ShareManagerState   `json:"shareState,omitempty"`
+	OwnerID                  *string                                           `json:"ownerID,omitempty"`
+	State                    *longhornv1beta2.VolumeState                      `json:"state,omitempty"`
+	Robustness               *longhornv1beta2.VolumeRobustness                 `json:"robustness,omitempty"`
+	CurrentNodeID            *string                                           `json:"currentNodeID,omitempty"`
+	CurrentImage             *string                                           `json:"currentImage,omitempty"`
+	KubernetesStatus         *KubernetesStatusApplyConfiguration               `json:"kubernetesStatus,omitempty"`
+	Conditions               []ConditionApplyConfiguration                     `json:"conditions,omitempty"`
+	LastBackup               *string                                           `json:"lastBackup,omitempty"`
+	LastBackupAt             *string                                           `json:"lastBackupAt,omitempty"`
+	PendingNodeID            *string                                           `json:"pendingNodeID,omitempty"`
+	CurrentMigrationNodeID   *string                                           `json:"currentMigrationNodeID,omitempty"`
+	FrontendDisabled         *bool                                             `json:"frontendDisabled,omitempty"`
+	RestoreRequired          *bool                                             `json:"restoreRequired,omitempty"`
+	RestoreInitiated         *bool                                             `json:"restoreInitiated,omitempty"`
+	CloneStatus              *VolumeCloneStatusApplyConfiguration              `json:"cloneStatus,omitempty"`
+	CloneTargets             []string                                          `json:"cloneTargets,omitempty"`
+	PassphraseRotationStatus *VolumePassphraseRotationStatusApplyConfiguration `json:"passphraseRotationStatus,omitempty"`
+	DiskTagMigrationStatus   *VolumeDiskTagMigrationStatusApplyConfiguration   `json:"diskTagMigrationStatus,omitempty"`
+	RemountRequestedAt       *string                                           `json:"remountRequestedAt,omitempty"`
+	ExpansionRequired        *bool                                             `json:"expansionRequired,omitempty"`
+	IsStandby                *bool                                             `json:"isStandby,omitempty"`
+	ActualSize               *int64                                            `json:"actualSize,omitempty"`
+	LastDegradedAt           *string                                           `json:"lastDegradedAt,omitempty"`
+	ShareEndpoint            *string                                           `json:"shareEndpoint,omitempty"`
+	ShareState               *longhornv1beta2.ShareManagerState                `json:"shareState,omitempty"`
+	QueuedOperations         []QueuedVolumeOperationApplyConfiguration         `json:"queuedOperations,omitempty"`
+	SalvageCandidates        []SalvageCandidateApplyConfiguration              `json:"salvageCandidates,omitempty"`
+	SalvageDecision          *string                                           `json:"salvageDecision,omitempty"`
+	LastFilesystemTrimAt     *string                                           `json:"lastFilesystemTrimAt,omitempty"`
 }
 
 // VolumeStatusApplyConfiguration constructs a declarative configuration of the VolumeStatus type for use with
@@ -180,6 +187,32 @@ func (b *VolumeStatusApplyConfiguration) WithCloneStatus(value *VolumeCloneStatu
 	return b
 }
 
+// WithCloneTargets adds the given value to the CloneTargets field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the CloneTargets field.
+func (b *VolumeStatusApplyConfiguration) WithCloneTargets(values ...string) *VolumeStatusApplyConfiguration {
+	for i := range values {
+		b.CloneTargets = append(b.CloneTargets, values[i])
+	}
+	return b
+}
+
+// WithPassphraseRotationStatus sets the PassphraseRotationStatus field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PassphraseRotationStatus field is set to the value of the last call.
+func (b *VolumeStatusApplyConfiguration) WithPassphraseRotationStatus(value *VolumePassphraseRotationStatusApplyConfiguration) *VolumeStatusApplyConfiguration {
+	b.PassphraseRotationStatus = value
+	return b
+}
+
+// WithDiskTagMigrationStatus sets the DiskTagMigrationStatus field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DiskTagMigrationStatus field is set to the value of the last call.
+func (b *VolumeStatusApplyConfiguration) WithDiskTagMigrationStatus(value *VolumeDiskTagMigrationStatusApplyConfiguration) *VolumeStatusApplyConfiguration {
+	b.DiskTagMigrationStatus = value
+	return b
+}
+
 // WithRemountRequestedAt sets the RemountRequestedAt field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the RemountRequestedAt field is set to the value of the last call.
@@ -235,3 +268,45 @@ func (b *VolumeStatusApplyConfiguration) WithShareState(value longhornv1beta2.Sh
 	b.ShareState = &value
 	return b
 }
+
+// WithQueuedOperations adds the given value to the QueuedOperations field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the QueuedOperations field.
+func (b *VolumeStatusApplyConfiguration) WithQueuedOperations(values ...*QueuedVolumeOperationApplyConfiguration) *VolumeStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithQueuedOperations")
+		}
+		b.QueuedOperations = append(b.QueuedOperations, *values[i])
+	}
+	return b
+}
+
+// WithSalvageCandidates adds the given value to the SalvageCandidates field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the SalvageCandidates field.
+func (b *VolumeStatusApplyConfiguration) WithSalvageCandidates(values ...*SalvageCandidateApplyConfiguration) *VolumeStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithSalvageCandidates")
+		}
+		b.SalvageCandidates = append(b.SalvageCandidates, *values[i])
+	}
+	return b
+}
+
+// WithSalvageDecision sets the SalvageDecision field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SalvageDecision field is set to the value of the last call.
+func (b *VolumeStatusApplyConfiguration) WithSalvageDecision(value string) *VolumeStatusApplyConfiguration {
+	b.SalvageDecision = &value
+	return b
+}
+
+// WithLastFilesystemTrimAt sets the LastFilesystemTrimAt field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastFilesystemTrimAt field is set to the value of the last call.
+func (b *VolumeStatusApplyConfiguration) WithLastFilesystemTrimAt(value string) *VolumeStatusApplyConfiguration {
+	b.LastFilesystemTrimAt = &value
+	return b
+}
@@ -244,6 +244,14 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &longhornv1beta2.EngineStatusApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("EngineVersionDetails"):
 		return &longhornv1beta2.EngineVersionDetailsApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("EngineUpgradeMaintenanceWindow"):
+		return &longhornv1beta2.EngineUpgradeMaintenanceWindowApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("EngineUpgradePolicy"):
+		return &longhornv1beta2.EngineUpgradePolicyApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("EngineUpgradePolicySpec"):
+		return &longhornv1beta2.EngineUpgradePolicySpecApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("EngineUpgradePolicyStatus"):
+		return &longhornv1beta2.EngineUpgradePolicyStatusApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("InstanceManager"):
 		return &longhornv1beta2.InstanceManagerApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("InstanceManagerSpec"):
@@ -262,6 +270,16 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &longhornv1beta2.InstanceStatusApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("KubernetesStatus"):
 		return &longhornv1beta2.KubernetesStatusApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("MaintenancePolicy"):
+		return &longhornv1beta2.MaintenancePolicyApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("MaintenancePolicyNodeRules"):
+		return &longhornv1beta2.MaintenancePolicyNodeRulesApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("MaintenancePolicySpec"):
+		return &longhornv1beta2.MaintenancePolicySpecApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("MaintenancePolicyStatus"):
+		return &longhornv1beta2.MaintenancePolicyStatusApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("MaintenancePolicyVolumeRules"):
+		return &longhornv1beta2.MaintenancePolicyVolumeRulesApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("Node"):
 		return &longhornv1beta2.NodeApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("NodeSpec"):
@@ -276,6 +294,8 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &longhornv1beta2.OrphanStatusApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("PurgeStatus"):
 		return &longhornv1beta2.PurgeStatusApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("QueuedVolumeOperation"):
+		return &longhornv1beta2.QueuedVolumeOperationApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("RebuildStatus"):
 		return &longhornv1beta2.RebuildStatusApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("RecurringJob"):
@@ -286,12 +306,16 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &longhornv1beta2.RecurringJobStatusApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("Replica"):
 		return &longhornv1beta2.ReplicaApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("ReplicaSalvageInfo"):
+		return &longhornv1beta2.ReplicaSalvageInfoApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("ReplicaSpec"):
 		return &longhornv1beta2.ReplicaSpecApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("ReplicaStatus"):
 		return &longhornv1beta2.ReplicaStatusApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("RestoreStatus"):
 		return &longhornv1beta2.RestoreStatusApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("SalvageCandidate"):
+		return &longhornv1beta2.SalvageCandidateApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("Setting"):
 		return &longhornv1beta2.SettingApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("SettingStatus"):
@@ -344,8 +368,14 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &longhornv1beta2.VolumeAttachmentSpecApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("VolumeAttachmentStatus"):
 		return &longhornv1beta2.VolumeAttachmentStatusApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("VolumeAutoResizePolicy"):
+		return &longhornv1beta2.VolumeAutoResizePolicyApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("VolumeCloneStatus"):
 		return &longhornv1beta2.VolumeCloneStatusApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("VolumeDiskTagMigrationStatus"):
+		return &longhornv1beta2.VolumeDiskTagMigrationStatusApplyConfiguration{}
+	case v1beta2.SchemeGroupVersion.WithKind("VolumePassphraseRotationStatus"):
+		return &longhornv1beta2.VolumePassphraseRotationStatusApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("VolumeSpec"):
 		return &longhornv1beta2.VolumeSpecApplyConfiguration{}
 	case v1beta2.SchemeGroupVersion.WithKind("VolumeStatus"):
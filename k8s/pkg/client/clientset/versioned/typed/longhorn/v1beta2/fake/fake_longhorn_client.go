@@ -64,10 +64,18 @@ func (c *FakeLonghornV1beta2) EngineImages(namespace string) v1beta2.EngineImage
 	return newFakeEngineImages(c, namespace)
 }
 
+func (c *FakeLonghornV1beta2) EngineUpgradePolicies(namespace string) v1beta2.EngineUpgradePolicyInterface {
+	return newFakeEngineUpgradePolicies(c, namespace)
+}
+
 func (c *FakeLonghornV1beta2) InstanceManagers(namespace string) v1beta2.InstanceManagerInterface {
 	return newFakeInstanceManagers(c, namespace)
 }
 
+func (c *FakeLonghornV1beta2) MaintenancePolicies(namespace string) v1beta2.MaintenancePolicyInterface {
+	return newFakeMaintenancePolicies(c, namespace)
+}
+
 func (c *FakeLonghornV1beta2) Nodes(namespace string) v1beta2.NodeInterface {
 	return newFakeNodes(c, namespace)
 }
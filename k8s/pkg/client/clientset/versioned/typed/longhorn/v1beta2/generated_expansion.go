@@ -36,8 +36,12 @@ type EngineExpansion interface{}
 
 type EngineImageExpansion interface{}
 
+type EngineUpgradePolicyExpansion interface{}
+
 type InstanceManagerExpansion interface{}
 
+type MaintenancePolicyExpansion interface{}
+
 type NodeExpansion interface{}
 
 type OrphanExpansion interface{}
@@ -37,7 +37,9 @@ type LonghornV1beta2Interface interface {
 	BackupVolumesGetter
 	EnginesGetter
 	EngineImagesGetter
+	EngineUpgradePoliciesGetter
 	InstanceManagersGetter
+	MaintenancePoliciesGetter
 	NodesGetter
 	OrphansGetter
 	RecurringJobsGetter
@@ -93,10 +95,18 @@ func (c *LonghornV1beta2Client) EngineImages(namespace string) EngineImageInterf
 	return newEngineImages(c, namespace)
 }
 
+func (c *LonghornV1beta2Client) EngineUpgradePolicies(namespace string) EngineUpgradePolicyInterface {
+	return newEngineUpgradePolicies(c, namespace)
+}
+
 func (c *LonghornV1beta2Client) InstanceManagers(namespace string) InstanceManagerInterface {
 	return newInstanceManagers(c, namespace)
 }
 
+func (c *LonghornV1beta2Client) MaintenancePolicies(namespace string) MaintenancePolicyInterface {
+	return newMaintenancePolicies(c, namespace)
+}
+
 func (c *LonghornV1beta2Client) Nodes(namespace string) NodeInterface {
 	return newNodes(c, namespace)
 }
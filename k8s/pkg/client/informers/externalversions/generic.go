@@ -104,8 +104,12 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1beta2().Engines().Informer()}, nil
 	case v1beta2.SchemeGroupVersion.WithResource("engineimages"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1beta2().EngineImages().Informer()}, nil
+	case v1beta2.SchemeGroupVersion.WithResource("engineupgradepolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1beta2().EngineUpgradePolicies().Informer()}, nil
 	case v1beta2.SchemeGroupVersion.WithResource("instancemanagers"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1beta2().InstanceManagers().Informer()}, nil
+	case v1beta2.SchemeGroupVersion.WithResource("maintenancepolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1beta2().MaintenancePolicies().Informer()}, nil
 	case v1beta2.SchemeGroupVersion.WithResource("nodes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Longhorn().V1beta2().Nodes().Informer()}, nil
 	case v1beta2.SchemeGroupVersion.WithResource("orphans"):
@@ -42,8 +42,12 @@ type Interface interface {
 	Engines() EngineInformer
 	// EngineImages returns a EngineImageInformer.
 	EngineImages() EngineImageInformer
+	// EngineUpgradePolicies returns a EngineUpgradePolicyInformer.
+	EngineUpgradePolicies() EngineUpgradePolicyInformer
 	// InstanceManagers returns a InstanceManagerInformer.
 	InstanceManagers() InstanceManagerInformer
+	// MaintenancePolicies returns a MaintenancePolicyInformer.
+	MaintenancePolicies() MaintenancePolicyInformer
 	// Nodes returns a NodeInformer.
 	Nodes() NodeInformer
 	// Orphans returns a OrphanInformer.
@@ -126,11 +130,21 @@ func (v *version) EngineImages() EngineImageInformer {
 	return &engineImageInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// EngineUpgradePolicies returns a EngineUpgradePolicyInformer.
+func (v *version) EngineUpgradePolicies() EngineUpgradePolicyInformer {
+	return &engineUpgradePolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // InstanceManagers returns a InstanceManagerInformer.
 func (v *version) InstanceManagers() InstanceManagerInformer {
 	return &instanceManagerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// MaintenancePolicies returns a MaintenancePolicyInformer.
+func (v *version) MaintenancePolicies() MaintenancePolicyInformer {
+	return &maintenancePolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Nodes returns a NodeInformer.
 func (v *version) Nodes() NodeInformer {
 	return &nodeInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
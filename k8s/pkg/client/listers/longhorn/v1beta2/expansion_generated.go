@@ -90,6 +90,14 @@ type EngineImageListerExpansion interface{}
 // EngineImageNamespaceLister.
 type EngineImageNamespaceListerExpansion interface{}
 
+// EngineUpgradePolicyListerExpansion allows custom methods to be added to
+// EngineUpgradePolicyLister.
+type EngineUpgradePolicyListerExpansion interface{}
+
+// EngineUpgradePolicyNamespaceListerExpansion allows custom methods to be added to
+// EngineUpgradePolicyNamespaceLister.
+type EngineUpgradePolicyNamespaceListerExpansion interface{}
+
 // InstanceManagerListerExpansion allows custom methods to be added to
 // InstanceManagerLister.
 type InstanceManagerListerExpansion interface{}
@@ -98,6 +106,14 @@ type InstanceManagerListerExpansion interface{}
 // InstanceManagerNamespaceLister.
 type InstanceManagerNamespaceListerExpansion interface{}
 
+// MaintenancePolicyListerExpansion allows custom methods to be added to
+// MaintenancePolicyLister.
+type MaintenancePolicyListerExpansion interface{}
+
+// MaintenancePolicyNamespaceListerExpansion allows custom methods to be added to
+// MaintenancePolicyNamespaceLister.
+type MaintenancePolicyNamespaceListerExpansion interface{}
+
 // NodeListerExpansion allows custom methods to be added to
 // NodeLister.
 type NodeListerExpansion interface{}
@@ -67,6 +67,7 @@ func main() {
 		app.PostUpgradeCmd(),
 		app.UninstallCmd(),
 		app.SystemRolloutCmd(),
+		app.ReplicaDataCmd(),
 		// TODO: Remove MigrateForPre070VolumesCmd() after v0.8.1
 		app.MigrateForPre070VolumesCmd(),
 	}
@@ -95,6 +95,10 @@ func (m *VolumeManager) CreateSnapshot(snapshotName string, labels map[string]st
 		return nil, err
 	}
 
+	if err := m.ds.CheckVolumeSnapshotQuota(volumeName); err != nil {
+		return nil, err
+	}
+
 	engineCliClient, err := engineapi.GetEngineBinaryClient(m.ds, volumeName, m.currentNodeID)
 	if err != nil {
 		return nil, err
@@ -205,6 +209,14 @@ func (m *VolumeManager) RevertSnapshot(snapshotName, volumeName string) error {
 		return fmt.Errorf("not revert to snapshot '%s' for volume '%s' since it's marked as Removed", snapshotName, volumeName)
 	}
 
+	v, err := m.ds.GetVolumeRO(volumeName)
+	if err != nil {
+		return err
+	}
+	if err := engineapi.TakeSafetySnapshot(m.ds, engineClientProxy, engine, v, "snapshot-revert"); err != nil {
+		return err
+	}
+
 	if err := engineClientProxy.SnapshotRevert(engine, snapshotName); err != nil {
 		return err
 	}
@@ -213,6 +225,62 @@ func (m *VolumeManager) RevertSnapshot(snapshotName, volumeName string) error {
 	return nil
 }
 
+// HashSnapshot requests the engine to hash the snapshot on all replicas again regardless of the recorded checksum, so
+// the data of the snapshot is read and verified. The result is returned by GetSnapshotHashStatus.
+func (m *VolumeManager) HashSnapshot(snapshotName, volumeName string) error {
+	if volumeName == "" || snapshotName == "" {
+		return fmt.Errorf("volume and snapshot name required")
+	}
+
+	engineCliClient, err := engineapi.GetEngineBinaryClient(m.ds, volumeName, m.currentNodeID)
+	if err != nil {
+		return err
+	}
+
+	engine, err := m.GetRunningEngineByVolume(volumeName)
+	if err != nil {
+		return err
+	}
+
+	engineClientProxy, err := engineapi.GetCompatibleClient(engine, engineCliClient, m.ds, nil, m.proxyConnCounter)
+	if err != nil {
+		return err
+	}
+	defer engineClientProxy.Close()
+
+	if err := engineClientProxy.SnapshotHash(engine, snapshotName, true); err != nil {
+		return err
+	}
+
+	logrus.Infof("Started hashing snapshot %v for volume %v", snapshotName, volumeName)
+	return nil
+}
+
+// GetSnapshotHashStatus returns the hash status of the snapshot of each replica, indexed by the replica address
+func (m *VolumeManager) GetSnapshotHashStatus(snapshotName, volumeName string) (map[string]*longhorn.HashStatus, error) {
+	if volumeName == "" || snapshotName == "" {
+		return nil, fmt.Errorf("volume and snapshot name required")
+	}
+
+	engineCliClient, err := engineapi.GetEngineBinaryClient(m.ds, volumeName, m.currentNodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := m.GetRunningEngineByVolume(volumeName)
+	if err != nil {
+		return nil, err
+	}
+
+	engineClientProxy, err := engineapi.GetCompatibleClient(engine, engineCliClient, m.ds, nil, m.proxyConnCounter)
+	if err != nil {
+		return nil, err
+	}
+	defer engineClientProxy.Close()
+
+	return engineClientProxy.SnapshotHashStatus(engine, snapshotName)
+}
+
 func (m *VolumeManager) PurgeSnapshot(volumeName string) error {
 	if volumeName == "" {
 		return fmt.Errorf("volume name required")
@@ -280,6 +348,31 @@ func (m *VolumeManager) BackupSnapshot(backupName, backupTargetName, volumeName,
 	return err
 }
 
+// takeSafetySnapshot takes a safety snapshot of the volume through its running engine right before the destructive
+// operation. See engineapi.TakeSafetySnapshot.
+func (m *VolumeManager) takeSafetySnapshot(v *longhorn.Volume, operation string) error {
+	isRequired, err := engineapi.IsSafetySnapshotRequired(m.ds, v)
+	if err != nil || !isRequired {
+		return err
+	}
+
+	engineCliClient, err := engineapi.GetEngineBinaryClient(m.ds, v.Name, m.currentNodeID)
+	if err != nil {
+		return err
+	}
+	e, err := m.GetRunningEngineByVolume(v.Name)
+	if err != nil {
+		return err
+	}
+	engineClientProxy, err := engineapi.GetCompatibleClient(e, engineCliClient, m.ds, nil, m.proxyConnCounter)
+	if err != nil {
+		return err
+	}
+	defer engineClientProxy.Close()
+
+	return engineapi.TakeSafetySnapshot(m.ds, engineClientProxy, e, v, operation)
+}
+
 func (m *VolumeManager) checkVolumeNotInMigration(volumeName string) error {
 	v, err := m.ds.GetVolume(volumeName)
 	if err != nil {
@@ -0,0 +1,221 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/constant"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// ForceCleanupResult reports the steps taken by a force cleanup, and the ones pending on running instances
+type ForceCleanupResult struct {
+	Actions []string
+	Pending []string
+}
+
+// ForceCleanupVolume cleans up a volume stuck in deletion, replacing the manual removal of the finalizers. The
+// dependents are cleaned up in order: the attachment tickets are released, then the engines and the replicas are
+// deleted. The finalizer of an engine or a replica is only removed if its node is down or deleted, so no running
+// instance is left behind. Otherwise it's left to the controller of the node, and the replicas are only handled once
+// the engines are gone. The finalizer of the volume is removed last. The cleanup can be repeated until nothing is pending, and each run is
+// recorded as an event of the volume.
+func (m *VolumeManager) ForceCleanupVolume(name, reason string) (result *ForceCleanupResult, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "failed to force cleanup volume %v", name)
+	}()
+
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+
+	v, err := m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+	if v.DeletionTimestamp == nil {
+		return nil, fmt.Errorf("volume is not being deleted")
+	}
+
+	result = &ForceCleanupResult{}
+	defer func() {
+		if err == nil {
+			m.recordForceCleanup(v, reason, result)
+		}
+	}()
+
+	if err := m.releaseAttachmentTickets(name, result); err != nil {
+		return nil, err
+	}
+
+	engines, err := m.ds.ListVolumeEngines(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range engines {
+		if err := m.forceCleanupInstance(e, getForceCleanupNodeID(e.Spec.NodeID, e.Status.OwnerID), result); err != nil {
+			return nil, err
+		}
+	}
+
+	replicas, err := m.ds.ListVolumeReplicas(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Pending) > 0 {
+		for _, r := range replicas {
+			result.Pending = append(result.Pending, fmt.Sprintf("replica %v is waiting for the engines to be cleaned up", r.Name))
+		}
+	} else {
+		for _, r := range replicas {
+			if err := m.forceCleanupInstance(r, getForceCleanupNodeID(r.Spec.NodeID, r.Status.OwnerID), result); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(result.Pending) > 0 {
+		return result, nil
+	}
+	if err := m.ds.RemoveFinalizerForVolume(v); err != nil {
+		return nil, err
+	}
+	result.Actions = append(result.Actions, "removed finalizer of volume")
+	return result, nil
+}
+
+func (m *VolumeManager) releaseAttachmentTickets(volumeName string, result *ForceCleanupResult) error {
+	va, err := m.ds.GetLHVolumeAttachmentByVolumeName(volumeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if len(va.Spec.AttachmentTickets) > 0 {
+		ticketIDs := []string{}
+		for id := range va.Spec.AttachmentTickets {
+			ticketIDs = append(ticketIDs, id)
+		}
+		va.Spec.AttachmentTickets = map[string]*longhorn.AttachmentTicket{}
+		if va, err = m.ds.UpdateLHVolumeAttachment(va); err != nil {
+			return err
+		}
+		result.Actions = append(result.Actions, fmt.Sprintf("released attachment tickets %v", strings.Join(ticketIDs, ", ")))
+	}
+
+	if va.DeletionTimestamp != nil {
+		if err := m.ds.RemoveFinalizerForLHVolumeAttachment(va); err != nil {
+			return err
+		}
+		result.Actions = append(result.Actions, fmt.Sprintf("removed finalizer of volume attachment %v", va.Name))
+	}
+	return nil
+}
+
+// getForceCleanupNodeID returns the node of the instance, or the node of the controller owning the object if the
+// instance isn't scheduled to any node
+func getForceCleanupNodeID(nodeID, ownerID string) string {
+	if nodeID != "" {
+		return nodeID
+	}
+	return ownerID
+}
+
+// forceCleanupInstance deletes the engine or the replica. Its finalizer is only removed if the node is down or
+// deleted, since the controller on a node that is up stops the instance and removes the finalizer itself.
+func (m *VolumeManager) forceCleanupInstance(obj runtime.Object, nodeID string, result *ForceCleanupResult) error {
+	var (
+		kind              string
+		objMeta           *metav1.ObjectMeta
+		deleteFunc        func(string) error
+		removeFinalizerFn func() error
+	)
+	switch o := obj.(type) {
+	case *longhorn.Engine:
+		kind, objMeta = "engine", &o.ObjectMeta
+		deleteFunc = m.ds.DeleteEngine
+		removeFinalizerFn = func() error { return m.ds.RemoveFinalizerForEngine(o) }
+	case *longhorn.Replica:
+		kind, objMeta = "replica", &o.ObjectMeta
+		deleteFunc = m.ds.DeleteReplica
+		removeFinalizerFn = func() error { return m.ds.RemoveFinalizerForReplica(o) }
+	default:
+		return fmt.Errorf("BUG: unsupported object %T for force cleanup", obj)
+	}
+
+	if objMeta.DeletionTimestamp == nil {
+		if err := deleteFunc(objMeta.Name); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		result.Actions = append(result.Actions, fmt.Sprintf("deleted %v %v", kind, objMeta.Name))
+	}
+
+	if nodeID == "" {
+		result.Pending = append(result.Pending, fmt.Sprintf("%v %v is waiting for a controller to clean it up", kind, objMeta.Name))
+		return nil
+	}
+	isNodeDown, err := m.ds.IsNodeDownOrDeleted(nodeID)
+	if err != nil {
+		return err
+	}
+	if !isNodeDown {
+		result.Pending = append(result.Pending, fmt.Sprintf("%v %v is waiting for the controller on node %v to clean it up", kind, objMeta.Name, nodeID))
+		return nil
+	}
+
+	if !util.FinalizerExists(longhorn.SchemeGroupVersion.Group, obj) {
+		return nil
+	}
+	if err := removeFinalizerFn(); err != nil {
+		return err
+	}
+	result.Actions = append(result.Actions, fmt.Sprintf("removed finalizer of %v %v", kind, objMeta.Name))
+	return nil
+}
+
+func (m *VolumeManager) recordForceCleanup(v *longhorn.Volume, reason string, result *ForceCleanupResult) {
+	message := fmt.Sprintf("Force cleanup requested for reason %q: actions [%v], pending [%v]",
+		reason, strings.Join(result.Actions, "; "), strings.Join(result.Pending, "; "))
+	logrus.Infof("Volume %v: %v", v.Name, message)
+
+	now := metav1.Time{Time: time.Now()}
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: v.Name + "-",
+			Namespace:    v.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      longhorn.SchemeGroupVersion.String(),
+			Kind:            types.LonghornKindVolume,
+			Namespace:       v.Namespace,
+			Name:            v.Name,
+			UID:             v.UID,
+			ResourceVersion: v.ResourceVersion,
+		},
+		Reason:         constant.EventReasonForceCleanup,
+		Message:        message,
+		Type:           corev1.EventTypeWarning,
+		Source:         corev1.EventSource{Component: types.LonghornManagerDaemonSetName, Host: m.currentNodeID},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := m.ds.CreateEvent(event); err != nil {
+		logrus.WithError(err).Warnf("Failed to record force cleanup of volume %v", v.Name)
+	}
+}
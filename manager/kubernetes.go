@@ -2,11 +2,15 @@ package manager
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"k8s.io/apimachinery/pkg/util/validation"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 
@@ -18,7 +22,20 @@ import (
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
 
-func (m *VolumeManager) PVCreate(name, pvName, fsType, secretNamespace, secretName, storageClassName string) (v *longhorn.Volume, err error) {
+const (
+	// The StorageClass parameters of the secrets passed to the CSI driver, suffixed by -name and -namespace
+	csiProvisionerSecretParameter = "csi.storage.k8s.io/provisioner-secret"
+	csiNodeStageSecretParameter   = "csi.storage.k8s.io/node-stage-secret"
+	csiNodePublishSecretParameter = "csi.storage.k8s.io/node-publish-secret"
+	csiNodeExpandSecretParameter  = "csi.storage.k8s.io/node-expand-secret"
+
+	defaultCryptoSecretName      = "longhorn-crypto"
+	defaultCryptoSecretNamespace = "longhorn-system"
+)
+
+// PVCreate creates the PV of the volume. The PVC the PV is going to be bound to resolves the templates of the secrets
+// in the StorageClass parameters; the last PVC of the volume is used if it's not specified.
+func (m *VolumeManager) PVCreate(name, pvName, fsType, secretNamespace, secretName, storageClassName, pvcNamespace, pvcName string) (v *longhorn.Volume, err error) {
 	defer func() {
 		err = errors.Wrapf(err, "unable to create PV for volume %v", name)
 	}()
@@ -78,20 +95,32 @@ func (m *VolumeManager) PVCreate(name, pvName, fsType, secretNamespace, secretNa
 
 	pv := datastore.NewPVManifestForVolume(v, pvName, storageClassName, fsType)
 	if v.Spec.Encrypted {
-		if secretName == "" {
-			secretName = "longhorn-crypto"
+		if secretName == "" && secretNamespace == "" {
+			if pvcName == "" && pvcNamespace == "" {
+				pvcName = v.Status.KubernetesStatus.PVCName
+				pvcNamespace = v.Status.KubernetesStatus.Namespace
+			}
+			if err := m.setPVSecretRefsFromStorageClass(pv, storageClassName, pvcNamespace, pvcName); err != nil {
+				return nil, err
+			}
 		}
 
-		if secretNamespace == "" {
-			secretNamespace = "longhorn-system"
-		}
+		if pv.Spec.CSI.NodeStageSecretRef == nil {
+			if secretName == "" {
+				secretName = defaultCryptoSecretName
+			}
+
+			if secretNamespace == "" {
+				secretNamespace = defaultCryptoSecretNamespace
+			}
 
-		secretRef := &corev1.SecretReference{
-			Name:      secretName,
-			Namespace: secretNamespace,
+			secretRef := &corev1.SecretReference{
+				Name:      secretName,
+				Namespace: secretNamespace,
+			}
+			pv.Spec.CSI.NodeStageSecretRef = secretRef
+			pv.Spec.CSI.NodePublishSecretRef = secretRef
 		}
-		pv.Spec.CSI.NodeStageSecretRef = secretRef
-		pv.Spec.CSI.NodePublishSecretRef = secretRef
 	}
 
 	_, err = m.ds.CreatePersistentVolume(pv)
@@ -103,6 +132,124 @@ func (m *VolumeManager) PVCreate(name, pvName, fsType, secretNamespace, secretNa
 	return v, nil
 }
 
+// setPVSecretRefsFromStorageClass sets the secrets of the PV from the StorageClass parameters the same way the CSI
+// external-provisioner does, so the templated secrets, e.g. a secret per namespace, work for the PVs created by the
+// API. The node stage secret falls back to the provisioner secret, and the node stage and publish secrets to each other.
+func (m *VolumeManager) setPVSecretRefsFromStorageClass(pv *corev1.PersistentVolume, storageClassName, pvcNamespace, pvcName string) error {
+	sc, err := m.ds.GetStorageClassRO(storageClassName)
+	if err != nil {
+		if datastore.ErrorIsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get storage class %v", storageClassName)
+	}
+
+	nameParams := map[string]string{"pv.name": pv.Name}
+	namespaceParams := map[string]string{"pv.name": pv.Name}
+	if pvcName != "" && pvcNamespace != "" {
+		nameParams["pvc.name"] = pvcName
+		nameParams["pvc.namespace"] = pvcNamespace
+		namespaceParams["pvc.namespace"] = pvcNamespace
+	}
+	// The provisioner secret cannot refer to the annotations of the PVC
+	provisionerNameParams := map[string]string{}
+	for k, v := range nameParams {
+		provisionerNameParams[k] = v
+	}
+	if pvcName != "" && pvcNamespace != "" {
+		pvc, err := m.ds.GetPersistentVolumeClaimRO(pvcNamespace, pvcName)
+		if err != nil && !datastore.ErrorIsNotFound(err) {
+			return errors.Wrapf(err, "failed to get PVC %v/%v", pvcNamespace, pvcName)
+		}
+		if pvc != nil {
+			for k, v := range pvc.Annotations {
+				nameParams[fmt.Sprintf("pvc.annotations['%s']", k)] = v
+			}
+		}
+	}
+
+	provisionerSecretRef, err := getStorageClassSecretRef(sc.Parameters, csiProvisionerSecretParameter, provisionerNameParams, namespaceParams)
+	if err != nil {
+		return err
+	}
+	nodeStageSecretRef, err := getStorageClassSecretRef(sc.Parameters, csiNodeStageSecretParameter, nameParams, namespaceParams)
+	if err != nil {
+		return err
+	}
+	nodePublishSecretRef, err := getStorageClassSecretRef(sc.Parameters, csiNodePublishSecretParameter, nameParams, namespaceParams)
+	if err != nil {
+		return err
+	}
+	nodeExpandSecretRef, err := getStorageClassSecretRef(sc.Parameters, csiNodeExpandSecretParameter, nameParams, namespaceParams)
+	if err != nil {
+		return err
+	}
+
+	if nodeStageSecretRef == nil {
+		nodeStageSecretRef = provisionerSecretRef
+	}
+	if nodeStageSecretRef == nil {
+		nodeStageSecretRef = nodePublishSecretRef
+	}
+	if nodePublishSecretRef == nil {
+		nodePublishSecretRef = nodeStageSecretRef
+	}
+	pv.Spec.CSI.NodeStageSecretRef = nodeStageSecretRef
+	pv.Spec.CSI.NodePublishSecretRef = nodePublishSecretRef
+	pv.Spec.CSI.NodeExpandSecretRef = nodeExpandSecretRef
+	return nil
+}
+
+// getStorageClassSecretRef returns the secret of the StorageClass parameter with its name and namespace templates
+// resolved, or nil if the StorageClass doesn't have the secret
+func getStorageClassSecretRef(parameters map[string]string, secretParameter string, nameParams, namespaceParams map[string]string) (*corev1.SecretReference, error) {
+	nameTemplate, nameExists := parameters[secretParameter+"-name"]
+	namespaceTemplate, namespaceExists := parameters[secretParameter+"-namespace"]
+	if !nameExists && !namespaceExists {
+		return nil, nil
+	}
+	if !nameExists || !namespaceExists {
+		return nil, fmt.Errorf("either both or none of %v-name and %v-namespace must be specified", secretParameter, secretParameter)
+	}
+
+	namespace, err := resolveSecretTemplate(namespaceTemplate, namespaceParams)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve %v-namespace", secretParameter)
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid %v-namespace %q resolved from %q: %v", secretParameter, namespace, namespaceTemplate, strings.Join(errs, ", "))
+	}
+
+	name, err := resolveSecretTemplate(nameTemplate, nameParams)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve %v-name", secretParameter)
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid %v-name %q resolved from %q: %v", secretParameter, name, nameTemplate, strings.Join(errs, ", "))
+	}
+
+	return &corev1.SecretReference{
+		Name:      name,
+		Namespace: namespace,
+	}, nil
+}
+
+// resolveSecretTemplate replaces the ${...} tokens of the template, e.g. ${pvc.namespace}, with the parameters
+func resolveSecretTemplate(template string, params map[string]string) (string, error) {
+	missingParams := []string{}
+	resolved := os.Expand(template, func(key string) string {
+		value, exists := params[key]
+		if !exists {
+			missingParams = append(missingParams, key)
+		}
+		return value
+	})
+	if len(missingParams) > 0 {
+		return "", fmt.Errorf("invalid tokens %v in template %q", strings.Join(missingParams, ", "), template)
+	}
+	return resolved, nil
+}
+
 func (m *VolumeManager) PVCCreate(name, namespace, pvcName string) (v *longhorn.Volume, err error) {
 	defer func() {
 		err = errors.Wrapf(err, "unable to create PVC for volume %v", name)
@@ -0,0 +1,115 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// ListReplicaPlacements returns the volumes and their replicas by volume name, which form the replica placement map
+// of the cluster.
+func (m *VolumeManager) ListReplicaPlacements() ([]*longhorn.Volume, map[string][]*longhorn.Replica, error) {
+	volumes, err := m.ds.ListVolumesRO()
+	if err != nil {
+		return nil, nil, err
+	}
+	replicas, err := m.ds.ListReplicasRO()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	volumeReplicas := map[string][]*longhorn.Replica{}
+	for _, r := range replicas {
+		volumeReplicas[r.Spec.VolumeName] = append(volumeReplicas[r.Spec.VolumeName], r)
+	}
+	return volumes, volumeReplicas, nil
+}
+
+func (m *VolumeManager) GetReplicaPlacement(name string) (*longhorn.Volume, []*longhorn.Replica, error) {
+	volume, err := m.ds.GetVolumeRO(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	replicaMap, err := m.ds.ListVolumeReplicasRO(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	replicas := []*longhorn.Replica{}
+	for _, r := range replicaMap {
+		replicas = append(replicas, r)
+	}
+	return volume, replicas, nil
+}
+
+// ImportReplicaPlacement sets the replica placement hints of the volume, so the replicas are scheduled to the data
+// directories left on the disks. The volume is created if it doesn't exist, which is the case after the cluster is
+// rebuilt. The automatic deletion of the orphaned replica data should be disabled until the data is adopted.
+func (m *VolumeManager) ImportReplicaPlacement(name string, spec *longhorn.VolumeSpec, hints []types.ReplicaPlacementHint) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to import replica placement of volume %v", name)
+	}()
+
+	for _, hint := range hints {
+		if hint.NodeID == "" || hint.DiskID == "" || hint.DataDirectoryName == "" {
+			return nil, fmt.Errorf("invalid replica placement hint %+v: node, disk and data directory are required", hint)
+		}
+	}
+	value, err := json.Marshal(hints)
+	if err != nil {
+		return nil, err
+	}
+	annotationKey := types.GetLonghornLabelKey(types.LonghornAnnotationReplicaPlacementHint)
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+
+		// The volume is created directly rather than by Create, so the hints are available for the first replica
+		// scheduling
+		v = &longhorn.Volume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					annotationKey: string(value),
+				},
+			},
+			Spec: longhorn.VolumeSpec{
+				Size:             spec.Size,
+				AccessMode:       spec.AccessMode,
+				Encrypted:        spec.Encrypted,
+				Frontend:         spec.Frontend,
+				NumberOfReplicas: spec.NumberOfReplicas,
+				DataEngine:       spec.DataEngine,
+				BackupTargetName: types.DefaultBackupTargetName,
+			},
+		}
+		v, err = m.ds.CreateVolume(v)
+		if err != nil {
+			return nil, err
+		}
+		logrus.Infof("Created volume %v with replica placement hints %v", v.Name, string(value))
+		return v, nil
+	}
+
+	if v.Annotations == nil {
+		v.Annotations = map[string]string{}
+	}
+	v.Annotations[annotationKey] = string(value)
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Infof("Updated volume %v replica placement hints to %v", v.Name, string(value))
+	return v, nil
+}
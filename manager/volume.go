@@ -1,20 +1,26 @@
 package manager
 
 import (
+	"context"
 	"fmt"
-	"io"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/longhorn/backupstore"
+
 	"github.com/longhorn/longhorn-manager/datastore"
 	"github.com/longhorn/longhorn-manager/engineapi"
 	"github.com/longhorn/longhorn-manager/scheduler"
@@ -127,7 +133,11 @@ func (m *VolumeManager) GetReplicasSorted(vName string) ([]*longhorn.Replica, er
 	return replicas, nil
 }
 
-func (m *VolumeManager) Create(name string, spec *longhorn.VolumeSpec, recurringJobSelector []longhorn.VolumeRecurringJob) (v *longhorn.Volume, err error) {
+// Create creates the volume. The restore credential is the backup store credential used to restore the volume from
+// backup in place of the one of the backup target, e.g. the namespace-scoped credential of the CSI provisioner secret.
+// If adoptWarmPoolVolume is set, a matching pooled volume is handed out in place of creating the volume, and the
+// returned volume has the name of the pooled volume. Only the CSI CreateVolume call sets it.
+func (m *VolumeManager) Create(name string, spec *longhorn.VolumeSpec, recurringJobSelector []longhorn.VolumeRecurringJob, restoreCredential map[string]string, adoptWarmPoolVolume bool) (v *longhorn.Volume, err error) {
 	defer func() {
 		err = errors.Wrapf(err, "unable to create volume %v", name)
 		if err != nil {
@@ -151,6 +161,14 @@ func (m *VolumeManager) Create(name string, spec *longhorn.VolumeSpec, recurring
 		}
 	}
 
+	restoreCredentialSecret := ""
+	if len(restoreCredential) > 0 {
+		if spec.FromBackup == "" {
+			return nil, fmt.Errorf("restore credential is only supported for volumes restored from backup")
+		}
+		restoreCredentialSecret = types.GetRestoreCredentialSecretName(name)
+	}
+
 	backupTargetName := spec.BackupTargetName
 	if spec.BackupTargetName == "" {
 		backupTargetName = types.DefaultBackupTargetName
@@ -163,6 +181,22 @@ func (m *VolumeManager) Create(name string, spec *longhorn.VolumeSpec, recurring
 		return nil, errors.Wrapf(err, "failed to restore backing image %v when create volume %v", spec.BackingImage, name)
 	}
 
+	if spec.FromBackup != "" {
+		if err := m.checkRestoreReplicaPlacement(name, spec); err != nil {
+			return nil, err
+		}
+	}
+
+	if adoptWarmPoolVolume {
+		v, err = m.adoptWarmPoolVolume(name, spec, labels)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			return v, nil
+		}
+	}
+
 	v = &longhorn.Volume{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   name,
@@ -177,7 +211,9 @@ func (m *VolumeManager) Create(name string, spec *longhorn.VolumeSpec, recurring
 			Image:                       "",
 			FromBackup:                  spec.FromBackup,
 			RestoreVolumeRecurringJob:   spec.RestoreVolumeRecurringJob,
+			RestoreCredentialSecret:     restoreCredentialSecret,
 			DataSource:                  spec.DataSource,
+			CloneMode:                   spec.CloneMode,
 			NumberOfReplicas:            spec.NumberOfReplicas,
 			ReplicaAutoBalance:          spec.ReplicaAutoBalance,
 			DataLocality:                spec.DataLocality,
@@ -190,6 +226,7 @@ func (m *VolumeManager) Create(name string, spec *longhorn.VolumeSpec, recurring
 			SnapshotDataIntegrity:       spec.SnapshotDataIntegrity,
 			SnapshotMaxCount:            spec.SnapshotMaxCount,
 			SnapshotMaxSize:             spec.SnapshotMaxSize,
+			SnapshotMaxChainLength:      spec.SnapshotMaxChainLength,
 			BackupCompressionMethod:     spec.BackupCompressionMethod,
 			UnmapMarkSnapChainRemoved:   spec.UnmapMarkSnapChainRemoved,
 			ReplicaSoftAntiAffinity:     spec.ReplicaSoftAntiAffinity,
@@ -197,7 +234,13 @@ func (m *VolumeManager) Create(name string, spec *longhorn.VolumeSpec, recurring
 			ReplicaDiskSoftAntiAffinity: spec.ReplicaDiskSoftAntiAffinity,
 			DataEngine:                  spec.DataEngine,
 			FreezeFilesystemForSnapshot: spec.FreezeFilesystemForSnapshot,
+			TrimFilesystemOnUnstage:     spec.TrimFilesystemOnUnstage,
+			RebuildPriority:             spec.RebuildPriority,
 			BackupTargetName:            backupTargetName,
+
+			SnapshotRetentionKeepLast: spec.SnapshotRetentionKeepLast,
+			SnapshotRetentionMaxAge:   spec.SnapshotRetentionMaxAge,
+			SnapshotRetentionMaxSize:  spec.SnapshotRetentionMaxSize,
 		},
 	}
 
@@ -206,9 +249,162 @@ func (m *VolumeManager) Create(name string, spec *longhorn.VolumeSpec, recurring
 		return nil, err
 	}
 	logrus.Infof("Created volume %v: %+v", v.Name, v.Spec)
+
+	if restoreCredentialSecret != "" {
+		if err := m.createRestoreCredentialSecret(v, restoreCredential); err != nil {
+			return nil, err
+		}
+	}
 	return v, nil
 }
 
+// checkRestoreReplicaPlacement rejects the restore of the volume from backup if the replica placement check is
+// enabled and the replicas cannot be scheduled. The size and the number of replicas are defaulted the same way the
+// volume mutator does.
+func (m *VolumeManager) checkRestoreReplicaPlacement(name string, spec *longhorn.VolumeSpec) error {
+	enabled, err := m.ds.GetSettingAsBool(types.SettingNameRestoreReplicaPlacementCheck)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+
+	v := &longhorn.Volume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: *spec.DeepCopy(),
+	}
+
+	backupName, _, _, err := backupstore.DecodeBackupURL(spec.FromBackup)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode backup URL %v", spec.FromBackup)
+	}
+	backup, err := m.ds.GetBackupRO(backupName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get backup %v", backupName)
+	}
+	size, err := util.ConvertSize(backup.Status.VolumeSize)
+	if err != nil {
+		return errors.Wrapf(err, "invalid size %v of backup %v", backup.Status.VolumeSize, backupName)
+	}
+	v.Spec.Size = util.RoundUpSize(size)
+
+	if v.Spec.NumberOfReplicas == 0 {
+		numberOfReplicas, err := m.ds.GetSettingAsInt(types.SettingNameDefaultReplicaCount)
+		if err != nil {
+			return err
+		}
+		v.Spec.NumberOfReplicas = int(numberOfReplicas)
+	}
+
+	return m.checkReplicaPlacement(v)
+}
+
+// checkReplicaPlacement returns an error with the reasons the missing replicas of the volume cannot be scheduled, if
+// any
+func (m *VolumeManager) checkReplicaPlacement(v *longhorn.Volume) error {
+	placementErrors, err := m.scheduler.CheckReplicaPlacement([]*longhorn.Volume{v})
+	if err != nil {
+		return errors.Wrap(err, "failed to check replica placement")
+	}
+	if len(placementErrors) > 0 {
+		return fmt.Errorf("cannot schedule the replicas of %v", scheduler.FormatReplicaPlacementErrors(placementErrors))
+	}
+	return nil
+}
+
+// createRestoreCredentialSecret stores the restore credential in a secret owned by the volume, so the secret is
+// removed along with the volume. The restoration waits for the secret to be created. A leftover secret of a previous
+// volume of the same name is taken over with the new credential.
+func (m *VolumeManager) createRestoreCredentialSecret(v *longhorn.Volume, credential map[string]string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            v.Spec.RestoreCredentialSecret,
+			Namespace:       v.Namespace,
+			OwnerReferences: datastore.GetOwnerReferencesForVolume(v),
+		},
+		StringData: credential,
+	}
+	_, err := m.ds.CreateSecret(v.Namespace, secret)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create restore credential secret %v", secret.Name)
+	}
+
+	existing, err := m.ds.GetSecret(v.Namespace, secret.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get restore credential secret %v", secret.Name)
+	}
+	existing.OwnerReferences = secret.OwnerReferences
+	existing.Data = nil
+	existing.StringData = credential
+	if _, err := m.ds.UpdateSecret(v.Namespace, existing); err != nil {
+		return errors.Wrapf(err, "failed to update restore credential secret %v", secret.Name)
+	}
+	return nil
+}
+
+// adoptWarmPoolVolume hands out a detached pooled volume created with the same spec as the requested one.
+// The adopted volume keeps its own name and is labeled with the requested name, so that retried requests
+// get the same volume. It returns nil if there is no matching pooled volume.
+func (m *VolumeManager) adoptWarmPoolVolume(name string, spec *longhorn.VolumeSpec, volumeLabels map[string]string) (*longhorn.Volume, error) {
+	claimedSelector, err := labels.ValidatedSelectorFromSet(map[string]string{
+		types.GetLonghornLabelKey(types.LonghornLabelVolumeWarmPoolClaimedBy): name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	claimedVolumes, err := m.ds.ListVolumesBySelectorRO(claimedSelector)
+	if err != nil {
+		return nil, err
+	}
+	if len(claimedVolumes) > 0 {
+		return claimedVolumes[0].DeepCopy(), nil
+	}
+
+	pooledSelector, err := labels.ValidatedSelectorFromSet(types.GetVolumeWarmPoolLabels(*spec))
+	if err != nil {
+		return nil, err
+	}
+	pooledVolumes, err := m.ds.ListVolumesBySelectorRO(pooledSelector)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pooledVolumes, func(i, j int) bool {
+		return pooledVolumes[i].CreationTimestamp.Before(&pooledVolumes[j].CreationTimestamp)
+	})
+
+	for _, pooledVolume := range pooledVolumes {
+		if pooledVolume.DeletionTimestamp != nil || pooledVolume.Status.State != longhorn.VolumeStateDetached {
+			continue
+		}
+
+		v := pooledVolume.DeepCopy()
+		delete(v.Labels, types.GetLonghornLabelKey(types.LonghornLabelVolumeWarmPool))
+		v.Labels[types.GetLonghornLabelKey(types.LonghornLabelVolumeWarmPoolClaimedBy)] = name
+		for key, value := range volumeLabels {
+			v.Labels[key] = value
+		}
+
+		v, err = m.ds.UpdateVolume(v)
+		if err != nil {
+			if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+				logrus.WithError(err).Warnf("Failed to adopt pooled volume %v for volume %v, trying the next one", pooledVolume.Name, name)
+				continue
+			}
+			return nil, err
+		}
+		logrus.Infof("Adopted pooled volume %v for volume %v", v.Name, name)
+		return v, nil
+	}
+
+	return nil, nil
+}
+
 func (m *VolumeManager) Delete(name string) error {
 	if err := m.ds.DeleteVolume(name); err != nil {
 		return err
@@ -252,7 +448,9 @@ func (m *VolumeManager) Attach(name, nodeID string, disableFrontend bool, attach
 		return nil, fmt.Errorf("volume %v is pending restoring", name)
 	}
 
-	if v.Spec.MigrationNodeID == node.Name {
+	// The CSI ticket of a live migrated RWO volume on the target node is still required, it's satisfied once the
+	// migration is confirmed
+	if util.IsMigratableVolume(v) && v.Spec.MigrationNodeID == node.Name {
 		logrus.Infof("Volume %v is already migrating to node %v from node %v", v.Name, node.Name, v.Spec.NodeID)
 		return v, nil
 	}
@@ -285,6 +483,84 @@ func (m *VolumeManager) Attach(name, nodeID string, disableFrontend bool, attach
 	return v, nil
 }
 
+// LiveMigrate starts the migration of the engine of an attached RWO volume to another node, before the workload is
+// rescheduled there. The migration is confirmed once the workload leaves the current node, and it can be cancelled by
+// detaching the live migration attachment ticket.
+func (m *VolumeManager) LiveMigrate(name, nodeID string) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to live migrate volume %v to %v", name, nodeID)
+	}()
+
+	node, err := m.ds.GetNode(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	readyCondition := types.GetCondition(node.Status.Conditions, longhorn.NodeConditionTypeReady)
+	if readyCondition.Status != longhorn.ConditionStatusTrue {
+		return nil, fmt.Errorf("node %v is not ready, couldn't migrate volume %v to it", node.Name, name)
+	}
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Spec.AccessMode != longhorn.AccessModeReadWriteOnce {
+		return nil, fmt.Errorf("live migration is only supported by %v volumes, the migratable %v volumes are migrated by the CSI driver",
+			longhorn.AccessModeReadWriteOnce, longhorn.AccessModeReadWriteMany)
+	}
+	if v.Status.State != longhorn.VolumeStateAttached || v.Spec.NodeID == "" {
+		return nil, fmt.Errorf("volume %v is not attached", v.Name)
+	}
+	if util.IsVolumeMigrating(v) {
+		return nil, fmt.Errorf("volume %v is already migrating to node %v", v.Name, v.Spec.MigrationNodeID)
+	}
+	if v.Spec.NodeID == node.Name {
+		return nil, fmt.Errorf("volume %v is already attached to node %v", v.Name, node.Name)
+	}
+
+	if isReady, err := m.ds.CheckDataEngineImageReadyOnAtLeastOneVolumeReplica(v.Spec.Image, v.Name, node.Name, v.Spec.DataLocality, v.Spec.DataEngine); !isReady {
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot migrate volume %v with image %v", v.Name, v.Spec.Image)
+		}
+		return nil, fmt.Errorf("cannot migrate volume %v because the data engine image %v is not deployed on at least one of the the replicas' nodes or the node that the volume is going to migrate to", v.Name, v.Spec.Image)
+	}
+
+	va, err := m.ds.GetLHVolumeAttachmentByVolumeName(v.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// the migration is driven by the workload leaving the current node
+	hasCSIAttachmentTicket := false
+	for _, ticket := range va.Spec.AttachmentTickets {
+		if ticket.Type == longhorn.AttacherTypeCSIAttacher && ticket.NodeID == v.Spec.NodeID {
+			hasCSIAttachmentTicket = true
+			break
+		}
+	}
+	if !hasCSIAttachmentTicket {
+		return nil, fmt.Errorf("volume %v is not used by a workload on node %v", v.Name, v.Spec.NodeID)
+	}
+
+	attachmentID := longhorn.GetAttachmentTicketID(longhorn.AttacherTypeLiveMigrationController, v.Name)
+	va.Spec.AttachmentTickets[attachmentID] = &longhorn.AttachmentTicket{
+		ID:     attachmentID,
+		Type:   longhorn.AttacherTypeLiveMigrationController,
+		NodeID: node.Name,
+		Parameters: map[string]string{
+			longhorn.AttachmentParameterDisableFrontend: longhorn.FalseValue,
+		},
+	}
+
+	if _, err := m.ds.UpdateLHVolumeAttachment(va); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Requested live migration of volume %v from node %v to node %v", v.Name, v.Spec.NodeID, node.Name)
+	return v, nil
+}
+
 // Detach will handle regular detachment as well as cleaning up attachment Ticket created by upgrade path
 func (m *VolumeManager) Detach(name, attachmentID, hostID string, forceDetach bool) (v *longhorn.Volume, err error) {
 	defer func() {
@@ -305,12 +581,36 @@ func (m *VolumeManager) Detach(name, attachmentID, hostID string, forceDetach bo
 		return nil, err
 	}
 
+	ticket, ok := va.Spec.AttachmentTickets[attachmentID]
+	if !forceDetach && !ok {
+		// the ticket is already removed, e.g. by a retried detach request
+		return v, nil
+	}
+
+	// refuse to detach the volume from under the running workloads unless it is forced, the tickets of the other
+	// attachers (e.g. CSI) are removed by their owners once the workloads are gone
+	if !forceDetach && ticket.Type == longhorn.AttacherTypeLonghornAPI {
+		pods, err := m.getRunningWorkloadPods(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(pods) > 0 {
+			return nil, fmt.Errorf("cannot detach volume %v since it is used by running pods %v, force detach is required", v.Name, strings.Join(pods, ", "))
+		}
+	}
+
 	// if force detach, detach from all nodes by clearing the volumeattachment spec
 	if forceDetach {
 		va.Spec.AttachmentTickets = make(map[string]*longhorn.AttachmentTicket)
 		if _, err := m.ds.UpdateLHVolumeAttachment(va); err != nil {
 			return nil, err
 		}
+		if v.Status.State == longhorn.VolumeStateAttached {
+			v, err = m.requireFilesystemCheck(v)
+			if err != nil {
+				return nil, err
+			}
+		}
 		return v, nil
 	}
 
@@ -323,6 +623,78 @@ func (m *VolumeManager) Detach(name, attachmentID, hostID string, forceDetach bo
 	return v, nil
 }
 
+// requireFilesystemCheck sets the volume condition FilesystemCheckRequired, since the filesystem may be left
+// inconsistent by the force detach. The CSI plugin checks the filesystem on the next stage if it's requested by the
+// filesystemCheckAfterUncleanDetach parameter.
+func (m *VolumeManager) requireFilesystemCheck(v *longhorn.Volume) (*longhorn.Volume, error) {
+	v.Status.Conditions = types.SetCondition(v.Status.Conditions, longhorn.VolumeConditionTypeFilesystemCheckRequired, longhorn.ConditionStatusTrue,
+		longhorn.VolumeConditionReasonForceDetached, fmt.Sprintf("Volume was force detached from node %v", v.Status.CurrentNodeID))
+	return m.ds.UpdateVolumeStatus(v)
+}
+
+// UpdateFilesystemCondition updates the filesystem condition of the volume reported by the CSI plugin after it checks
+// or resizes the filesystem of the volume. A false condition without a reason clears the condition, which is skipped
+// if the condition isn't true, so the volumes never having the condition don't get it.
+func (m *VolumeManager) UpdateFilesystemCondition(volumeName, conditionType string, status longhorn.ConditionStatus, reason, message string) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update condition %v of volume %v", conditionType, volumeName)
+	}()
+
+	if conditionType != longhorn.VolumeConditionTypeFilesystemCheckRequired && conditionType != longhorn.VolumeConditionTypeFilesystemResizeFailed {
+		return nil, fmt.Errorf("condition %v isn't a filesystem condition", conditionType)
+	}
+	if status != longhorn.ConditionStatusTrue && status != longhorn.ConditionStatusFalse {
+		return nil, fmt.Errorf("invalid condition status %v", status)
+	}
+
+	v, err = m.ds.GetVolume(volumeName)
+	if err != nil {
+		return nil, err
+	}
+
+	condition := types.GetCondition(v.Status.Conditions, conditionType)
+	if status == longhorn.ConditionStatusFalse && reason == "" && condition.Status != longhorn.ConditionStatusTrue {
+		return v, nil
+	}
+	if condition.Status == status && condition.Reason == reason && condition.Message == message {
+		return v, nil
+	}
+
+	v.Status.Conditions = types.SetCondition(v.Status.Conditions, conditionType, status, reason, message)
+	return m.ds.UpdateVolumeStatus(v)
+}
+
+// getRunningWorkloadPods returns the namespaced names of the running pods that mount the PVC of the volume
+func (m *VolumeManager) getRunningWorkloadPods(v *longhorn.Volume) ([]string, error) {
+	ks := v.Status.KubernetesStatus
+	if ks.PVCName == "" || ks.LastPVCRefAt != "" {
+		return nil, nil
+	}
+
+	pods, err := m.ds.ListPodsRO(ks.Namespace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list pods in namespace %v", ks.Namespace)
+	}
+
+	runningPods := []string{}
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, vol := range pod.Spec.Volumes {
+			// The name of the PVC of a generic ephemeral volume is deterministic:
+			// https://kubernetes.io/docs/concepts/storage/ephemeral-volumes/#persistentvolumeclaim-naming.
+			if (vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == ks.PVCName) ||
+				(vol.Ephemeral != nil && fmt.Sprintf("%s-%s", pod.Name, vol.Name) == ks.PVCName) {
+				runningPods = append(runningPods, pod.Namespace+"/"+pod.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(runningPods)
+	return runningPods, nil
+}
+
 func (m *VolumeManager) Salvage(volumeName string, replicaNames []string) (v *longhorn.Volume, err error) {
 	defer func() {
 		err = errors.Wrapf(err, "unable to salvage volume %v", volumeName)
@@ -389,6 +761,36 @@ func (m *VolumeManager) Salvage(volumeName string, replicaNames []string) (v *lo
 	return v, nil
 }
 
+// PickSalvageCandidate salvages the faulted volume using the picked candidate only, instead of the ones chosen by the
+// auto salvage.
+func (m *VolumeManager) PickSalvageCandidate(volumeName, replicaName string) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to pick salvage candidate %v for volume %v", replicaName, volumeName)
+	}()
+
+	v, err = m.ds.GetVolume(volumeName)
+	if err != nil {
+		return nil, err
+	}
+	isCandidate := false
+	for _, candidate := range v.Status.SalvageCandidates {
+		if candidate.ReplicaName == replicaName {
+			isCandidate = true
+			break
+		}
+	}
+	if !isCandidate {
+		return nil, fmt.Errorf("replica %v is not a salvage candidate of volume %v", replicaName, v.Name)
+	}
+
+	v.Status.SalvageDecision = fmt.Sprintf("Replica %v was picked manually at %v", replicaName, util.Now())
+	if _, err := m.ds.UpdateVolumeStatus(v); err != nil {
+		return nil, err
+	}
+
+	return m.Salvage(volumeName, []string{replicaName})
+}
+
 func (m *VolumeManager) Activate(volumeName string, frontend string) (v *longhorn.Volume, err error) {
 	defer func() {
 		err = errors.Wrapf(err, "unable to activate volume %v", volumeName)
@@ -415,6 +817,16 @@ func (m *VolumeManager) Activate(volumeName string, frontend string) (v *longhor
 		return nil, err
 	}
 
+	checkReplicaPlacement, err := m.ds.GetSettingAsBool(types.SettingNameRestoreReplicaPlacementCheck)
+	if err != nil {
+		return nil, err
+	}
+	if checkReplicaPlacement {
+		if err := m.checkReplicaPlacement(v); err != nil {
+			return nil, err
+		}
+	}
+
 	// Trigger a backup volume update to get the latest backup
 	// and will confirm recovery completion in volume state reconciliation
 	if err := m.triggerBackupVolumeToSync(v); err != nil {
@@ -432,6 +844,49 @@ func (m *VolumeManager) Activate(volumeName string, frontend string) (v *longhor
 	return v, nil
 }
 
+// ActivateWithFinalSync activates the DR volume in one step. Like Activate, it syncs the backup volume so the volume
+// restores the latest backup before the activation completes. Then it creates the PV and the PVC of the volume if they
+// are requested and don't exist yet, so the workload can use the volume right after the failover.
+func (m *VolumeManager) ActivateWithFinalSync(volumeName, frontend, pvName, fsType, pvcNamespace, pvcName string) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to activate volume %v with final sync", volumeName)
+	}()
+
+	if pvcName != "" && pvcNamespace == "" {
+		return nil, fmt.Errorf("PVC namespace is required for PVC %v", pvcName)
+	}
+
+	v, err = m.ds.GetVolume(volumeName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The activation may have been done by a previous call that failed to create the PV or PVC
+	if v.Spec.Standby {
+		if v, err = m.Activate(volumeName, frontend); err != nil {
+			return nil, err
+		}
+	} else if v.Spec.FromBackup == "" {
+		return nil, fmt.Errorf("volume %v is not a DR volume", v.Name)
+	}
+
+	if pvName == "" && pvcName == "" {
+		return v, nil
+	}
+
+	if v.Status.KubernetesStatus.PVName == "" {
+		if v, err = m.PVCreate(volumeName, pvName, fsType, "", "", "", pvcNamespace, pvcName); err != nil {
+			return nil, err
+		}
+	}
+
+	ks := v.Status.KubernetesStatus
+	if pvcName == "" || (ks.PVCName != "" && ks.LastPVCRefAt == "") {
+		return v, nil
+	}
+	return m.PVCCreate(volumeName, pvcNamespace, pvcName)
+}
+
 func (m *VolumeManager) triggerBackupVolumeToSync(volume *longhorn.Volume) error {
 	if volume.Spec.BackupTargetName == "" {
 		return errors.Errorf("failed to find the backup target label for volume: %v", volume.Name)
@@ -506,6 +961,10 @@ func (m *VolumeManager) Expand(volumeName string, size int64) (v *longhorn.Volum
 		logrus.Infof("CSI plugin call to expand volume %v to size %v", v.Name, size)
 	}
 
+	if err := m.takeSafetySnapshot(v, "expansion"); err != nil {
+		return nil, err
+	}
+
 	previousSize := v.Spec.Size
 	v.Spec.Size = size
 
@@ -603,68 +1062,90 @@ func (m *VolumeManager) CancelExpansion(volumeName string) (v *longhorn.Volume,
 	return v, nil
 }
 
-func (m *VolumeManager) TrimFilesystem(name string) (v *longhorn.Volume, err error) {
+// RotatePassphrase requests rotating the LUKS passphrase of the encrypted volume to the one in its node stage secret.
+// The previous passphrase should be kept in the secret until the rotation is completed.
+func (m *VolumeManager) RotatePassphrase(volumeName string) (v *longhorn.Volume, err error) {
 	defer func() {
-		err = errors.Wrapf(err, "unable to trim filesystem for volume %v", name)
+		err = errors.Wrapf(err, "unable to rotate passphrase for volume %v", volumeName)
 	}()
 
-	v, err = m.ds.GetVolume(name)
+	v, err = m.ds.GetVolume(volumeName)
 	if err != nil {
 		return nil, err
 	}
-	if v.Status.State != longhorn.VolumeStateAttached {
-		return nil, fmt.Errorf("volume is not attached")
+	if !v.Spec.Encrypted {
+		return nil, fmt.Errorf("volume is not encrypted")
 	}
-	if v.Status.FrontendDisabled {
-		return nil, fmt.Errorf("volume frontend is disabled")
+	if v.Status.KubernetesStatus.PVName == "" {
+		return nil, fmt.Errorf("volume has no PV referencing the node stage secret")
 	}
 
-	// Blocks degraded v2 volume from being trimmed to maintain reliable volume
-	// head size for failed usable replica candidate selection.
-	if types.IsDataEngineV2(v.Spec.DataEngine) {
-		if v.Status.Robustness == longhorn.VolumeRobustnessDegraded {
-			return nil, fmt.Errorf("volume is degraded")
-		}
+	v.Spec.PassphraseRotationRequestedAt = util.Now()
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
 	}
 
-	if v.Spec.AccessMode == longhorn.AccessModeReadWriteMany {
-		return v, m.trimRWXVolumeFilesystem(name, v.Spec.Encrypted)
-	}
-	return v, m.trimNonRWXVolumeFilesystem(name, v.Spec.Encrypted)
+	logrus.Infof("Requested passphrase rotation for volume %v", v.Name)
+	return v, nil
 }
 
-func (m *VolumeManager) trimNonRWXVolumeFilesystem(volumeName string, encryptedDevice bool) error {
-	return util.TrimFilesystem(volumeName, encryptedDevice)
-}
+// MigrateToDiskTag requests replacing the replicas of the volume with the ones on the disks matching the disk
+// selector. The replicas are replaced one at a time while the volume is attached.
+func (m *VolumeManager) MigrateToDiskTag(volumeName string, diskSelector []string) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to migrate volume %v to disk tags %v", volumeName, diskSelector)
+	}()
 
-func (m *VolumeManager) trimRWXVolumeFilesystem(volumeName string, encryptedDevice bool) error {
-	sm, err := m.ds.GetShareManager(volumeName)
+	v, err = m.ds.GetVolume(volumeName)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get share manager for trimming volume %v", volumeName)
+		return nil, err
 	}
-	pod, err := m.ds.GetPodRO(sm.Namespace, types.GetShareManagerPodNameFromShareManagerName(sm.Name))
-	if err != nil {
-		return errors.Wrapf(err, "failed to get share manager pod for trimming volume %v in namespace", volumeName)
+	if len(diskSelector) == 0 {
+		return nil, fmt.Errorf("disk selector is required")
 	}
-	if pod == nil {
-		return fmt.Errorf("share manager pod is not found for trimming volume %v in namespace", volumeName)
+	if v.Spec.DataEngine != longhorn.DataEngineTypeV1 && v.Spec.DataEngine != longhorn.DataEngineTypeV2 {
+		return nil, fmt.Errorf("unknown data engine %v", v.Spec.DataEngine)
 	}
 
-	if sm.Status.State != longhorn.ShareManagerStateRunning {
-		return fmt.Errorf("share manager %v is not running", sm.Name)
+	v.Spec.DiskSelector = diskSelector
+	v.Spec.DiskTagMigrationRequestedAt = util.Now()
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
 	}
 
-	client, err := engineapi.NewShareManagerClient(sm, pod)
+	logrus.Infof("Requested migrating volume %v to disks with tags %v", v.Name, diskSelector)
+	return v, nil
+}
+
+func (m *VolumeManager) TrimFilesystem(name string) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to trim filesystem for volume %v", name)
+	}()
+
+	v, err = m.ds.GetVolume(name)
 	if err != nil {
-		return errors.Wrapf(err, "failed to launch gRPC client for share manager before trimming volume %v", volumeName)
+		return nil, err
+	}
+	if v.Status.State != longhorn.VolumeStateAttached {
+		return nil, fmt.Errorf("volume is not attached")
 	}
-	defer func(client io.Closer) {
-		if closeErr := client.Close(); closeErr != nil {
-			logrus.WithError(closeErr).Warn("Failed to close share manager client")
+	if v.Status.FrontendDisabled {
+		return nil, fmt.Errorf("volume frontend is disabled")
+	}
+
+	// Blocks degraded v2 volume from being trimmed to maintain reliable volume
+	// head size for failed usable replica candidate selection.
+	if types.IsDataEngineV2(v.Spec.DataEngine) {
+		if v.Status.Robustness == longhorn.VolumeRobustnessDegraded {
+			return nil, fmt.Errorf("volume is degraded")
 		}
-	}(client)
+	}
 
-	return client.FilesystemTrim(encryptedDevice)
+	ctx, cancel := context.WithTimeout(context.Background(), engineapi.FilesystemTrimTimeout)
+	defer cancel()
+	return v, engineapi.TrimVolumeFilesystem(ctx, m.ds, v)
 }
 
 func (m *VolumeManager) AddVolumeRecurringJob(volumeName string, name string, isGroup bool) (volumeRecurringJob map[string]*longhorn.VolumeRecurringJob, err error) {
@@ -749,6 +1230,102 @@ func (m *VolumeManager) DeleteReplica(volumeName, replicaName string) error {
 	return nil
 }
 
+// getVolumeReplica returns the replica of the volume
+func (m *VolumeManager) getVolumeReplica(volumeName, replicaName string) (*longhorn.Replica, error) {
+	r, err := m.ds.GetReplica(replicaName)
+	if err != nil {
+		return nil, err
+	}
+	if r.Spec.VolumeName != volumeName {
+		return nil, fmt.Errorf("cannot find replica %v of volume %v", replicaName, volumeName)
+	}
+	return r, nil
+}
+
+// PauseReplicaRebuild stops the in progress rebuild of the replica, and keeps the replica from being rebuilt until the
+// rebuild is resumed by ResumeReplicaRebuild.
+func (m *VolumeManager) PauseReplicaRebuild(volumeName, replicaName string) (r *longhorn.Replica, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to pause rebuild of replica %s for volume %s", replicaName, volumeName)
+	}()
+
+	r, err = m.getVolumeReplica(volumeName, replicaName)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Spec.RebuildPaused {
+		logrus.Debugf("Replica %s rebuild is already paused", r.Name)
+		return r, nil
+	}
+	if r.Spec.HealthyAt != "" || r.Spec.FailedAt != "" {
+		return nil, fmt.Errorf("replica %v is not rebuilding", r.Name)
+	}
+
+	r.Spec.RebuildPaused = true
+	r, err = m.ds.UpdateReplica(r)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Paused rebuild of replica %s for volume %s", r.Name, volumeName)
+	return r, nil
+}
+
+// ResumeReplicaRebuild resumes the paused rebuild of the replica.
+func (m *VolumeManager) ResumeReplicaRebuild(volumeName, replicaName string) (r *longhorn.Replica, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to resume rebuild of replica %s for volume %s", replicaName, volumeName)
+	}()
+
+	r, err = m.getVolumeReplica(volumeName, replicaName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.Spec.RebuildPaused {
+		logrus.Debugf("Replica %s rebuild is not paused", r.Name)
+		return r, nil
+	}
+
+	r.Spec.RebuildPaused = false
+	r, err = m.ds.UpdateReplica(r)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Resumed rebuild of replica %s for volume %s", r.Name, volumeName)
+	return r, nil
+}
+
+// UpdateReplicaRebuildPriority bumps or demotes the rebuild of the replica relative to the other rebuilding replicas
+// on the same node.
+func (m *VolumeManager) UpdateReplicaRebuildPriority(volumeName, replicaName string, rebuildPriority int) (r *longhorn.Replica, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update field RebuildPriority of replica %s for volume %s", replicaName, volumeName)
+	}()
+
+	r, err = m.getVolumeReplica(volumeName, replicaName)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Spec.RebuildPriority == rebuildPriority {
+		logrus.Debugf("Replica %s already set field RebuildPriority to %d", r.Name, rebuildPriority)
+		return r, nil
+	}
+
+	oldRebuildPriority := r.Spec.RebuildPriority
+	r.Spec.RebuildPriority = rebuildPriority
+	r, err = m.ds.UpdateReplica(r)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Updated replica %s field RebuildPriority from %d to %d", r.Name, oldRebuildPriority, rebuildPriority)
+	return r, nil
+}
+
 func (m *VolumeManager) GetManagerNodeIPMap() (map[string]string, error) {
 	podList, err := m.ds.ListManagerPods()
 	if err != nil {
@@ -760,7 +1337,7 @@ func (m *VolumeManager) GetManagerNodeIPMap() (map[string]string, error) {
 		if nodeIPMap[pod.Spec.NodeName] != "" {
 			return nil, fmt.Errorf("multiple managers on the node %v", pod.Spec.NodeName)
 		}
-		nodeIPMap[pod.Spec.NodeName] = pod.Status.PodIP
+		nodeIPMap[pod.Spec.NodeName] = m.ds.GetPodIP(pod)
 	}
 	return nodeIPMap, nil
 }
@@ -835,6 +1412,10 @@ func (m *VolumeManager) EngineUpgrade(volumeName, image string) (v *longhorn.Vol
 		return nil, fmt.Errorf("cannot do live upgrade for an attached strict-local volume %v", v.Name)
 	}
 
+	if err := m.takeSafetySnapshot(v, "engine-upgrade"); err != nil {
+		return nil, err
+	}
+
 	oldImage := v.Spec.Image
 	v.Spec.Image = image
 
@@ -1168,6 +1749,32 @@ func (m *VolumeManager) UpdateSnapshotMaxCount(name string, snapshotMaxCount int
 	return v, nil
 }
 
+func (m *VolumeManager) UpdateSnapshotMaxChainLength(name string, snapshotMaxChainLength int) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update field SnapshotMaxChainLength for volume %s", name)
+	}()
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Spec.SnapshotMaxChainLength == snapshotMaxChainLength {
+		logrus.Debugf("Volume %s already set field SnapshotMaxChainLength to %d", v.Name, snapshotMaxChainLength)
+		return v, nil
+	}
+
+	oldSnapshotMaxChainLength := v.Spec.SnapshotMaxChainLength
+	v.Spec.SnapshotMaxChainLength = snapshotMaxChainLength
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Updated volume %s field SnapshotMaxChainLength from %d to %d", v.Name, oldSnapshotMaxChainLength, snapshotMaxChainLength)
+	return v, nil
+}
+
 func (m *VolumeManager) UpdateSnapshotMaxSize(name string, snapshotMaxSize int64) (v *longhorn.Volume, err error) {
 	defer func() {
 		err = errors.Wrapf(err, "unable to update field SnapshotMaxSize for volume %s", name)
@@ -1194,6 +1801,180 @@ func (m *VolumeManager) UpdateSnapshotMaxSize(name string, snapshotMaxSize int64
 	return v, nil
 }
 
+func (m *VolumeManager) UpdateRebuildPriority(name string, rebuildPriority int) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update field RebuildPriority for volume %s", name)
+	}()
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Spec.RebuildPriority == rebuildPriority {
+		logrus.Debugf("Volume %s already set field RebuildPriority to %d", v.Name, rebuildPriority)
+		return v, nil
+	}
+
+	oldRebuildPriority := v.Spec.RebuildPriority
+	v.Spec.RebuildPriority = rebuildPriority
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Updated volume %s field RebuildPriority from %d to %d", v.Name, oldRebuildPriority, rebuildPriority)
+	return v, nil
+}
+
+func (m *VolumeManager) UpdateSnapshotRetentionPolicy(name string, keepLast int, maxAge string, maxSize int64) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update snapshot retention policy for volume %s", name)
+	}()
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Spec.SnapshotRetentionKeepLast == keepLast &&
+		v.Spec.SnapshotRetentionMaxAge == maxAge &&
+		v.Spec.SnapshotRetentionMaxSize == maxSize {
+		logrus.Debugf("Volume %s already set snapshot retention policy to keep last %d, max age %q and max size %d", v.Name, keepLast, maxAge, maxSize)
+		return v, nil
+	}
+
+	v.Spec.SnapshotRetentionKeepLast = keepLast
+	v.Spec.SnapshotRetentionMaxAge = maxAge
+	v.Spec.SnapshotRetentionMaxSize = maxSize
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Updated volume %s snapshot retention policy to keep last %d, max age %q and max size %d", v.Name, keepLast, maxAge, maxSize)
+	return v, nil
+}
+
+// UpdateAutoResizePolicy updates the policy expanding the volume automatically when its filesystem is filling up. Nil
+// disables the auto resize.
+func (m *VolumeManager) UpdateAutoResizePolicy(name string, policy *longhorn.VolumeAutoResizePolicy) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update auto resize policy for volume %s", name)
+	}()
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if reflect.DeepEqual(v.Spec.AutoResize, policy) {
+		logrus.Debugf("Volume %s already set auto resize policy to %+v", v.Name, policy)
+		return v, nil
+	}
+
+	v.Spec.AutoResize = policy
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy == nil {
+		logrus.Infof("Disabled volume %s auto resize", v.Name)
+	} else {
+		logrus.Infof("Updated volume %s auto resize policy to threshold %d%%, increment %d and max size %d",
+			v.Name, policy.ThresholdPercentage, policy.Increment, policy.MaxSize)
+	}
+	return v, nil
+}
+
+// UpdateBackupMaxAge updates the maximum age in minutes of the last completed backup of the volume, over which the
+// BackupFresh condition of the volume turns false. 0 disables the tracking.
+func (m *VolumeManager) UpdateBackupMaxAge(name string, backupMaxAge int) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to update field BackupMaxAge for volume %s", name)
+	}()
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Spec.BackupMaxAge == backupMaxAge {
+		logrus.Debugf("Volume %s already set field BackupMaxAge to %d", v.Name, backupMaxAge)
+		return v, nil
+	}
+
+	oldBackupMaxAge := v.Spec.BackupMaxAge
+	v.Spec.BackupMaxAge = backupMaxAge
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Updated volume %s field BackupMaxAge from %d to %d", v.Name, oldBackupMaxAge, backupMaxAge)
+	return v, nil
+}
+
+// PauseIO pauses the IO of the attached volume for short maintenance operations. The IO is resumed by ResumeIO, or
+// automatically once the timeout in seconds passes.
+func (m *VolumeManager) PauseIO(name string, timeout int64) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to pause IO for volume %s", name)
+	}()
+
+	if err := types.ValidateVolumeIOPauseTimeout(timeout); err != nil {
+		return nil, err
+	}
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !types.IsDataEngineV2(v.Spec.DataEngine) {
+		return nil, fmt.Errorf("pausing IO is not supported for data engine %v", v.Spec.DataEngine)
+	}
+	if v.Status.State != longhorn.VolumeStateAttached {
+		return nil, fmt.Errorf("volume %v is not attached", v.Name)
+	}
+
+	v.Spec.IOPauseDeadline = util.TimestampAfterDuration(time.Duration(timeout) * time.Second)
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Pausing IO of volume %s until %s", v.Name, v.Spec.IOPauseDeadline)
+	return v, nil
+}
+
+// ResumeIO resumes the paused IO of the volume before the pause timeout passes.
+func (m *VolumeManager) ResumeIO(name string) (v *longhorn.Volume, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "unable to resume IO for volume %s", name)
+	}()
+
+	v, err = m.ds.GetVolume(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Spec.IOPauseDeadline == "" {
+		logrus.Debugf("Volume %s IO is not paused", v.Name)
+		return v, nil
+	}
+
+	v.Spec.IOPauseDeadline = ""
+	v, err = m.ds.UpdateVolume(v)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Resuming IO of volume %s", v.Name)
+	return v, nil
+}
+
 func (m *VolumeManager) restoreBackingImage(backupTargetName, biName, secret, secretNamespace, dataEngine string) error {
 	if secret != "" || secretNamespace != "" {
 		_, err := m.ds.GetSecretRO(secretNamespace, secret)
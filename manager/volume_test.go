@@ -0,0 +1,680 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+)
+
+const (
+	testNamespace    = "longhorn-system"
+	testPodNamespace = "default"
+	testVolumeName   = "test-volume"
+	testPVCName      = "test-pvc"
+	testNodeName     = "test-node"
+)
+
+type fakeVolumeManager struct {
+	*VolumeManager
+
+	lhClient          *lhfake.Clientset
+	informerFactories *util.InformerFactories
+}
+
+func newFakeVolumeManager() *fakeVolumeManager {
+	lhClient := lhfake.NewSimpleClientset()
+	kubeClient := fake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(testNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+	ds := datastore.NewDataStore(testNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+
+	return &fakeVolumeManager{
+		VolumeManager:     NewVolumeManager(testNodeName, ds, util.NewAtomicCounter()),
+		lhClient:          lhClient,
+		informerFactories: informerFactories,
+	}
+}
+
+func (m *fakeVolumeManager) addVolume(t *testing.T, v *longhorn.Volume, va *longhorn.VolumeAttachment) {
+	lhInformerFactory := m.informerFactories.LhInformerFactory.Longhorn().V1beta2()
+
+	v, err := m.lhClient.LonghornV1beta2().Volumes(testNamespace).Create(context.TODO(), v, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, lhInformerFactory.Volumes().Informer().GetIndexer().Add(v))
+
+	va, err = m.lhClient.LonghornV1beta2().VolumeAttachments(testNamespace).Create(context.TODO(), va, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, lhInformerFactory.VolumeAttachments().Informer().GetIndexer().Add(va))
+}
+
+func (m *fakeVolumeManager) addPod(t *testing.T, pod *corev1.Pod) {
+	require.NoError(t, m.informerFactories.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod))
+}
+
+func (m *fakeVolumeManager) getAttachmentTickets(t *testing.T) map[string]*longhorn.AttachmentTicket {
+	va, err := m.lhClient.LonghornV1beta2().VolumeAttachments(testNamespace).Get(context.TODO(),
+		types.GetLHVolumeAttachmentNameFromVolumeName(testVolumeName), metav1.GetOptions{})
+	require.NoError(t, err)
+	return va.Spec.AttachmentTickets
+}
+
+func newTestVolume(pvcName, lastPVCRefAt string) *longhorn.Volume {
+	return &longhorn.Volume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testVolumeName,
+			Namespace: testNamespace,
+		},
+		Status: longhorn.VolumeStatus{
+			State: longhorn.VolumeStateAttached,
+			KubernetesStatus: longhorn.KubernetesStatus{
+				Namespace:    testPodNamespace,
+				PVCName:      pvcName,
+				LastPVCRefAt: lastPVCRefAt,
+			},
+		},
+	}
+}
+
+func newTestVolumeAttachment(tickets ...*longhorn.AttachmentTicket) *longhorn.VolumeAttachment {
+	va := &longhorn.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      types.GetLHVolumeAttachmentNameFromVolumeName(testVolumeName),
+			Namespace: testNamespace,
+		},
+		Spec: longhorn.VolumeAttachmentSpec{
+			AttachmentTickets: map[string]*longhorn.AttachmentTicket{},
+			Volume:            testVolumeName,
+		},
+	}
+	for _, ticket := range tickets {
+		va.Spec.AttachmentTickets[ticket.ID] = ticket
+	}
+	return va
+}
+
+func newTestWorkloadPod(name string, phase corev1.PodPhase, volumes ...corev1.Volume) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testPodNamespace,
+		},
+		Spec: corev1.PodSpec{
+			Volumes: volumes,
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func newTestPVCVolume(claimName string) corev1.Volume {
+	return corev1.Volume{
+		Name: "data",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+		},
+	}
+}
+
+func TestGetRunningWorkloadPods(t *testing.T) {
+	ephemeralVolume := corev1.Volume{
+		Name: "scratch",
+		VolumeSource: corev1.VolumeSource{
+			Ephemeral: &corev1.EphemeralVolumeSource{},
+		},
+	}
+	deletingPod := newTestWorkloadPod("deleting", corev1.PodRunning, newTestPVCVolume(testPVCName))
+	deletingPod.DeletionTimestamp = &metav1.Time{}
+
+	tests := map[string]struct {
+		volume       *longhorn.Volume
+		pods         []*corev1.Pod
+		expectedPods []string
+	}{
+		"volume without PVC": {
+			volume: newTestVolume("", ""),
+			pods: []*corev1.Pod{
+				newTestWorkloadPod("pod-1", corev1.PodRunning, newTestPVCVolume(testPVCName)),
+			},
+		},
+		"PVC no longer referenced": {
+			volume: newTestVolume(testPVCName, "2024-01-01T00:00:00Z"),
+			pods: []*corev1.Pod{
+				newTestWorkloadPod("pod-1", corev1.PodRunning, newTestPVCVolume(testPVCName)),
+			},
+		},
+		"running pods using the PVC": {
+			volume: newTestVolume(testPVCName, ""),
+			pods: []*corev1.Pod{
+				newTestWorkloadPod("pod-2", corev1.PodRunning, newTestPVCVolume(testPVCName)),
+				newTestWorkloadPod("pod-1", corev1.PodRunning, newTestPVCVolume(testPVCName)),
+				newTestWorkloadPod("pending", corev1.PodPending, newTestPVCVolume(testPVCName)),
+				newTestWorkloadPod("succeeded", corev1.PodSucceeded, newTestPVCVolume(testPVCName)),
+				newTestWorkloadPod("other", corev1.PodRunning, newTestPVCVolume("other-pvc")),
+				deletingPod,
+			},
+			expectedPods: []string{"default/pod-1", "default/pod-2"},
+		},
+		"running pod using the generic ephemeral volume": {
+			volume: newTestVolume("ephemeral-scratch", ""),
+			pods: []*corev1.Pod{
+				newTestWorkloadPod("ephemeral", corev1.PodRunning, ephemeralVolume),
+				newTestWorkloadPod("other", corev1.PodRunning, ephemeralVolume),
+			},
+			expectedPods: []string{"default/ephemeral"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newFakeVolumeManager()
+			for _, pod := range tc.pods {
+				m.addPod(t, pod)
+			}
+
+			pods, err := m.getRunningWorkloadPods(tc.volume)
+			require.NoError(t, err)
+			if tc.expectedPods == nil {
+				require.Empty(t, pods)
+				return
+			}
+			require.Equal(t, tc.expectedPods, pods)
+		})
+	}
+}
+
+func TestDetach(t *testing.T) {
+	apiTicket := &longhorn.AttachmentTicket{ID: "api", Type: longhorn.AttacherTypeLonghornAPI, NodeID: testNodeName}
+	csiTicket := &longhorn.AttachmentTicket{ID: "csi", Type: longhorn.AttacherTypeCSIAttacher, NodeID: testNodeName}
+
+	tests := map[string]struct {
+		attachmentID    string
+		forceDetach     bool
+		expectError     bool
+		expectedTickets []string
+	}{
+		"absent ticket": {
+			attachmentID:    "absent",
+			expectedTickets: []string{"api", "csi"},
+		},
+		"API ticket of the volume used by running pods": {
+			attachmentID:    "api",
+			expectError:     true,
+			expectedTickets: []string{"api", "csi"},
+		},
+		"CSI ticket": {
+			attachmentID:    "csi",
+			expectedTickets: []string{"api"},
+		},
+		"force detach": {
+			attachmentID: "api",
+			forceDetach:  true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newFakeVolumeManager()
+			m.addVolume(t, newTestVolume(testPVCName, ""), newTestVolumeAttachment(apiTicket.DeepCopy(), csiTicket.DeepCopy()))
+			m.addPod(t, newTestWorkloadPod("pod-1", corev1.PodRunning, newTestPVCVolume(testPVCName)))
+
+			_, err := m.Detach(testVolumeName, tc.attachmentID, testNodeName, tc.forceDetach)
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			tickets := []string{}
+			for id := range m.getAttachmentTickets(t) {
+				tickets = append(tickets, id)
+			}
+			require.ElementsMatch(t, tc.expectedTickets, tickets)
+		})
+	}
+}
+
+func TestActivateWithFinalSync(t *testing.T) {
+	newDRVolume := func(standby bool, pvName, pvcName string) *longhorn.Volume {
+		v := newTestVolume(pvcName, "")
+		v.Labels = map[string]string{types.LonghornLabelBackupVolume: testVolumeName}
+		v.Spec.FromBackup = "s3://backupbucket@us-east-1/?backup=backup-1&volume=" + testVolumeName
+		v.Spec.BackupTargetName = types.DefaultBackupTargetName
+		v.Spec.Standby = standby
+		v.Status.IsStandby = standby
+		v.Status.KubernetesStatus.PVName = pvName
+		return v
+	}
+
+	tests := map[string]struct {
+		volume       *longhorn.Volume
+		pvName       string
+		pvcNamespace string
+		pvcName      string
+		expectError  bool
+	}{
+		"PVC without namespace": {
+			volume:      newDRVolume(true, "", ""),
+			pvName:      "test-pv",
+			pvcName:     testPVCName,
+			expectError: true,
+		},
+		"volume not restored from backup": {
+			volume:      newTestVolume("", ""),
+			expectError: true,
+		},
+		"standby volume": {
+			volume: newDRVolume(true, "", ""),
+		},
+		"activated volume with PV and PVC": {
+			volume:       newDRVolume(false, "test-pv", testPVCName),
+			pvName:       "test-pv",
+			pvcNamespace: testPodNamespace,
+			pvcName:      testPVCName,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newFakeVolumeManager()
+			m.addVolume(t, tc.volume, newTestVolumeAttachment())
+
+			v, err := m.ActivateWithFinalSync(testVolumeName, string(longhorn.VolumeFrontendBlockDev), tc.pvName, "ext4", tc.pvcNamespace, tc.pvcName)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.False(t, v.Spec.Standby)
+			require.Equal(t, tc.volume.Status.KubernetesStatus.PVName, v.Status.KubernetesStatus.PVName)
+			require.Equal(t, tc.volume.Status.KubernetesStatus.PVCName, v.Status.KubernetesStatus.PVCName)
+		})
+	}
+}
+
+func TestPauseIO(t *testing.T) {
+	tests := map[string]struct {
+		dataEngine  longhorn.DataEngineType
+		state       longhorn.VolumeState
+		expectError bool
+	}{
+		"v1 volume": {
+			dataEngine:  longhorn.DataEngineTypeV1,
+			state:       longhorn.VolumeStateAttached,
+			expectError: true,
+		},
+		"detached v2 volume": {
+			dataEngine:  longhorn.DataEngineTypeV2,
+			state:       longhorn.VolumeStateDetached,
+			expectError: true,
+		},
+		"attached v2 volume": {
+			dataEngine: longhorn.DataEngineTypeV2,
+			state:      longhorn.VolumeStateAttached,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := newTestVolume("", "")
+			v.Spec.DataEngine = tc.dataEngine
+			v.Status.State = tc.state
+
+			m := newFakeVolumeManager()
+			m.addVolume(t, v, newTestVolumeAttachment())
+
+			v, err := m.PauseIO(testVolumeName, 60)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, v.Spec.IOPauseDeadline)
+		})
+	}
+}
+
+func (m *fakeVolumeManager) addReplica(t *testing.T, r *longhorn.Replica) {
+	r, err := m.lhClient.LonghornV1beta2().Replicas(testNamespace).Create(context.TODO(), r, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, m.informerFactories.LhInformerFactory.Longhorn().V1beta2().Replicas().Informer().GetIndexer().Add(r))
+}
+
+func (m *fakeVolumeManager) getReplica(t *testing.T, name string) *longhorn.Replica {
+	r, err := m.lhClient.LonghornV1beta2().Replicas(testNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return r
+}
+
+func newTestReplica(name, volumeName string) *longhorn.Replica {
+	return &longhorn.Replica{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+			Labels:    types.GetVolumeLabels(volumeName),
+		},
+		Spec: longhorn.ReplicaSpec{
+			InstanceSpec: longhorn.InstanceSpec{
+				VolumeName:  volumeName,
+				NodeID:      testNodeName,
+				DesireState: longhorn.InstanceStateRunning,
+			},
+		},
+	}
+}
+
+func TestPauseReplicaRebuild(t *testing.T) {
+	tests := map[string]struct {
+		replicaName   string
+		volumeName    string
+		healthyAt     string
+		failedAt      string
+		paused        bool
+		expectError   bool
+		expectedPause bool
+	}{
+		"rebuilding replica": {
+			replicaName:   "replica-1",
+			volumeName:    testVolumeName,
+			expectedPause: true,
+		},
+		"paused already": {
+			replicaName:   "replica-1",
+			volumeName:    testVolumeName,
+			paused:        true,
+			expectedPause: true,
+		},
+		"healthy replica": {
+			replicaName: "replica-1",
+			volumeName:  testVolumeName,
+			healthyAt:   "2026-01-01T00:00:00Z",
+			expectError: true,
+		},
+		"failed replica": {
+			replicaName: "replica-1",
+			volumeName:  testVolumeName,
+			failedAt:    "2026-01-01T00:00:00Z",
+			expectError: true,
+		},
+		"replica of another volume": {
+			replicaName: "replica-1",
+			volumeName:  "other-volume",
+			expectError: true,
+		},
+		"replica not found": {
+			replicaName: "missing",
+			volumeName:  testVolumeName,
+			expectError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newTestReplica("replica-1", tc.volumeName)
+			r.Spec.HealthyAt = tc.healthyAt
+			r.Spec.FailedAt = tc.failedAt
+			r.Spec.RebuildPaused = tc.paused
+
+			m := newFakeVolumeManager()
+			m.addReplica(t, r)
+
+			_, err := m.PauseReplicaRebuild(testVolumeName, tc.replicaName)
+			if tc.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expectedPause, m.getReplica(t, "replica-1").Spec.RebuildPaused)
+		})
+	}
+}
+
+func TestResumeReplicaRebuild(t *testing.T) {
+	tests := map[string]struct {
+		volumeName  string
+		paused      bool
+		expectError bool
+	}{
+		"paused replica": {
+			volumeName: testVolumeName,
+			paused:     true,
+		},
+		"replica not paused": {
+			volumeName: testVolumeName,
+		},
+		"replica of another volume": {
+			volumeName:  "other-volume",
+			paused:      true,
+			expectError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newTestReplica("replica-1", tc.volumeName)
+			r.Spec.RebuildPaused = tc.paused
+
+			m := newFakeVolumeManager()
+			m.addReplica(t, r)
+
+			_, err := m.ResumeReplicaRebuild(testVolumeName, "replica-1")
+			if tc.expectError {
+				require.Error(t, err)
+				require.True(t, m.getReplica(t, "replica-1").Spec.RebuildPaused)
+				return
+			}
+			require.NoError(t, err)
+			require.False(t, m.getReplica(t, "replica-1").Spec.RebuildPaused)
+		})
+	}
+}
+
+func TestUpdateReplicaRebuildPriority(t *testing.T) {
+	m := newFakeVolumeManager()
+	m.addReplica(t, newTestReplica("replica-1", testVolumeName))
+	m.addReplica(t, newTestReplica("replica-2", "other-volume"))
+
+	r, err := m.UpdateReplicaRebuildPriority(testVolumeName, "replica-1", 10)
+	require.NoError(t, err)
+	require.Equal(t, 10, r.Spec.RebuildPriority)
+	require.Equal(t, 10, m.getReplica(t, "replica-1").Spec.RebuildPriority)
+
+	_, err = m.UpdateReplicaRebuildPriority(testVolumeName, "replica-2", 10)
+	require.Error(t, err)
+	require.Equal(t, 0, m.getReplica(t, "replica-2").Spec.RebuildPriority)
+}
+
+func TestCreateAdoptWarmPoolVolume(t *testing.T) {
+	datastore.SkipListerCheck = true
+	defer func() { datastore.SkipListerCheck = false }()
+
+	spec := &longhorn.VolumeSpec{
+		Size:             util.RoundUpSize(1024 * 1024 * 1024),
+		NumberOfReplicas: 3,
+		Frontend:         longhorn.VolumeFrontendBlockDev,
+	}
+	newPooledVolume := func() *longhorn.Volume {
+		return &longhorn.Volume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pool-test",
+				Namespace: testNamespace,
+				Labels:    types.GetVolumeWarmPoolLabels(*spec),
+			},
+			Spec:   *spec.DeepCopy(),
+			Status: longhorn.VolumeStatus{State: longhorn.VolumeStateDetached},
+		}
+	}
+
+	tests := map[string]struct {
+		adoptWarmPoolVolume bool
+		expectedName        string
+	}{
+		"apiCreateKeepsRequestedName": {
+			expectedName: testVolumeName,
+		},
+		"csiCreateAdoptsPooledVolume": {
+			adoptWarmPoolVolume: true,
+			expectedName:        "pool-test",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newFakeVolumeManager()
+			m.addVolume(t, newPooledVolume(), newTestVolumeAttachment())
+
+			v, err := m.Create(testVolumeName, spec.DeepCopy(), nil, nil, tc.adoptWarmPoolVolume)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedName, v.Name)
+
+			pooledVolume, err := m.lhClient.LonghornV1beta2().Volumes(testNamespace).Get(context.TODO(), "pool-test", metav1.GetOptions{})
+			require.NoError(t, err)
+			_, isPooled := pooledVolume.Labels[types.GetLonghornLabelKey(types.LonghornLabelVolumeWarmPool)]
+			require.Equal(t, !tc.adoptWarmPoolVolume, isPooled)
+			if tc.adoptWarmPoolVolume {
+				require.Equal(t, testVolumeName, pooledVolume.Labels[types.GetLonghornLabelKey(types.LonghornLabelVolumeWarmPoolClaimedBy)])
+			}
+		})
+	}
+}
+
+func TestForceCleanupVolume(t *testing.T) {
+	ticket := &longhorn.AttachmentTicket{ID: "csi", Type: longhorn.AttacherTypeCSIAttacher, NodeID: testNodeName}
+	downNodeName := "down-node"
+
+	tests := map[string]struct {
+		engineNodeID  string
+		replicaNodeID string
+		instanceState longhorn.InstanceState
+
+		expectedPending       []string
+		expectedFinalizers    []string
+		expectVolumeFinalizer bool
+	}{
+		"engine on a node that is up": {
+			engineNodeID:  testNodeName,
+			replicaNodeID: downNodeName,
+			instanceState: longhorn.InstanceStateRunning,
+			expectedPending: []string{
+				"engine test-engine is waiting for the controller on node test-node to clean it up",
+				"replica test-replica is waiting for the engines to be cleaned up",
+			},
+			expectedFinalizers:    []string{"test-engine", "test-replica"},
+			expectVolumeFinalizer: true,
+		},
+		"stopped engine on a node that is up": {
+			engineNodeID:  testNodeName,
+			replicaNodeID: downNodeName,
+			instanceState: longhorn.InstanceStateStopped,
+			expectedPending: []string{
+				"engine test-engine is waiting for the controller on node test-node to clean it up",
+				"replica test-replica is waiting for the engines to be cleaned up",
+			},
+			expectedFinalizers:    []string{"test-engine", "test-replica"},
+			expectVolumeFinalizer: true,
+		},
+		"replica on a node that is up": {
+			engineNodeID:  downNodeName,
+			replicaNodeID: testNodeName,
+			instanceState: longhorn.InstanceStateRunning,
+			expectedPending: []string{
+				"replica test-replica is waiting for the controller on node test-node to clean it up",
+			},
+			expectedFinalizers:    []string{"test-replica"},
+			expectVolumeFinalizer: true,
+		},
+		"instances on a node that is down": {
+			engineNodeID:  downNodeName,
+			replicaNodeID: downNodeName,
+			instanceState: longhorn.InstanceStateRunning,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newFakeVolumeManager()
+			lhInformerFactory := m.informerFactories.LhInformerFactory.Longhorn().V1beta2()
+			now := metav1.Now()
+
+			v := newTestVolume("", "")
+			v.Finalizers = []string{longhorn.SchemeGroupVersion.Group}
+			v.DeletionTimestamp = &now
+			m.addVolume(t, v, newTestVolumeAttachment(ticket.DeepCopy()))
+
+			node := &longhorn.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: testNodeName, Namespace: testNamespace},
+				Status: longhorn.NodeStatus{
+					Conditions: []longhorn.Condition{{Type: longhorn.NodeConditionTypeReady, Status: longhorn.ConditionStatusTrue}},
+				},
+			}
+			require.NoError(t, lhInformerFactory.Nodes().Informer().GetIndexer().Add(node))
+
+			// The instances are being deleted already, and the down node is gone
+			e := &longhorn.Engine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-engine",
+					Namespace:         testNamespace,
+					Labels:            types.GetVolumeLabels(testVolumeName),
+					Finalizers:        []string{longhorn.SchemeGroupVersion.Group},
+					DeletionTimestamp: &now,
+				},
+				Spec: longhorn.EngineSpec{
+					InstanceSpec: longhorn.InstanceSpec{VolumeName: testVolumeName, NodeID: tc.engineNodeID},
+				},
+				Status: longhorn.EngineStatus{
+					InstanceStatus: longhorn.InstanceStatus{CurrentState: tc.instanceState},
+				},
+			}
+			e, err := m.lhClient.LonghornV1beta2().Engines(testNamespace).Create(context.TODO(), e, metav1.CreateOptions{})
+			require.NoError(t, err)
+			require.NoError(t, lhInformerFactory.Engines().Informer().GetIndexer().Add(e))
+
+			r := newTestReplica("test-replica", testVolumeName)
+			r.Finalizers = []string{longhorn.SchemeGroupVersion.Group}
+			r.DeletionTimestamp = &now
+			r.Spec.NodeID = tc.replicaNodeID
+			r.Status.CurrentState = tc.instanceState
+			m.addReplica(t, r)
+
+			result, err := m.ForceCleanupVolume(testVolumeName, "stuck in deletion")
+			require.NoError(t, err)
+			require.ElementsMatch(t, tc.expectedPending, result.Pending)
+
+			// The attachment tickets are released first
+			require.Empty(t, m.getAttachmentTickets(t))
+			require.Contains(t, result.Actions, "released attachment tickets csi")
+
+			// The finalizers are only removed from the instances on the down node, and from the replicas once the
+			// engines are gone
+			finalizers := []string{}
+			e, err = m.lhClient.LonghornV1beta2().Engines(testNamespace).Get(context.TODO(), e.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			if len(e.Finalizers) > 0 {
+				finalizers = append(finalizers, e.Name)
+			}
+			if len(m.getReplica(t, r.Name).Finalizers) > 0 {
+				finalizers = append(finalizers, r.Name)
+			}
+			require.ElementsMatch(t, tc.expectedFinalizers, finalizers)
+
+			v, err = m.lhClient.LonghornV1beta2().Volumes(testNamespace).Get(context.TODO(), testVolumeName, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, tc.expectVolumeFinalizer, len(v.Finalizers) > 0)
+		})
+	}
+}
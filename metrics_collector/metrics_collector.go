@@ -54,6 +54,12 @@ func InitMetricsCollectorSystem(logger logrus.FieldLogger, currentNodeID string,
 		logger.WithField("collector", subsystemBackupBackingImage).WithError(err).Warn("Failed to register collector")
 	}
 
+	if sloCollector, err := NewSLOCollector(logger, currentNodeID, ds); err != nil {
+		logger.WithField("collector", subsystemSLO).WithError(err).Warn("Failed to initialize collector")
+	} else if err := registry.Register(sloCollector); err != nil {
+		logger.WithField("collector", subsystemSLO).WithError(err).Warn("Failed to register collector")
+	}
+
 	namespace := os.Getenv(types.EnvPodNamespace)
 	if namespace == "" {
 		logger.Warnf("Cannot detect pod namespace, environment variable %v is missing, "+
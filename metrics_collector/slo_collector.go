@@ -0,0 +1,245 @@
+package metricscollector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/client-go/tools/cache"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// SLOCollector observes the end-to-end latency of the storage operations visible to the users, by correlating the
+// transitions of the Kubernetes objects driven by the CSI calls with the Longhorn volumes:
+//   - provisioning: from the PVC creation to the PVC bound to the volume created by CreateVolume.
+//   - attach: from the VolumeAttachment creation to the volume attached by ControllerPublishVolume.
+//   - mount: from the workload pod scheduled to its volumes mounted, i.e. the pod ready to start containers.
+//   - detach: from the VolumeAttachment deletion requested to the volume detached by ControllerUnpublishVolume.
+//   - deletion: from the volume deletion requested to the volume purged.
+//
+// Each operation is observed by a single manager, the one of the volume owner or of the node of the attachment or
+// the pod, so the histograms can be summed across the managers.
+type SLOCollector struct {
+	*baseCollector
+
+	provisioningDuration *prometheus.HistogramVec
+	attachDuration       *prometheus.HistogramVec
+	mountDuration        *prometheus.HistogramVec
+	detachDuration       *prometheus.HistogramVec
+	deletionDuration     *prometheus.HistogramVec
+}
+
+func NewSLOCollector(
+	logger logrus.FieldLogger,
+	nodeID string,
+	ds *datastore.DataStore) (*SLOCollector, error) {
+
+	sc := &SLOCollector{
+		baseCollector: newBaseCollector(subsystemSLO, logger, nodeID, ds),
+	}
+
+	// The operations take from seconds to many minutes
+	buckets := prometheus.ExponentialBuckets(0.5, 2, 12)
+	newHistogramVec := func(name, help string) *prometheus.HistogramVec {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: longhornName,
+			Subsystem: subsystemSLO,
+			Name:      name,
+			Help:      help,
+			Buckets:   buckets,
+		}, []string{nodeLabel})
+	}
+	sc.provisioningDuration = newHistogramVec("provisioning_duration_seconds",
+		"Duration in seconds from a PVC created to the PVC bound to a Longhorn volume")
+	sc.attachDuration = newHistogramVec("attach_duration_seconds",
+		"Duration in seconds from a VolumeAttachment created to the Longhorn volume attached")
+	sc.mountDuration = newHistogramVec("mount_duration_seconds",
+		"Duration in seconds from a workload pod scheduled to its Longhorn volumes mounted")
+	sc.detachDuration = newHistogramVec("detach_duration_seconds",
+		"Duration in seconds from a VolumeAttachment deletion requested to the Longhorn volume detached")
+	sc.deletionDuration = newHistogramVec("deletion_duration_seconds",
+		"Duration in seconds from a Longhorn volume deletion requested to the volume purged")
+
+	if _, err := ds.PersistentVolumeClaimInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: sc.onPersistentVolumeClaimUpdate,
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := ds.VolumeAttachmentInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: sc.onVolumeAttachmentUpdate,
+		DeleteFunc: sc.onVolumeAttachmentDelete,
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := ds.PodInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: sc.onPodUpdate,
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := ds.VolumeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: sc.onVolumeDelete,
+	}); err != nil {
+		return nil, err
+	}
+
+	return sc, nil
+}
+
+func (sc *SLOCollector) Describe(ch chan<- *prometheus.Desc) {
+	sc.provisioningDuration.Describe(ch)
+	sc.attachDuration.Describe(ch)
+	sc.mountDuration.Describe(ch)
+	sc.detachDuration.Describe(ch)
+	sc.deletionDuration.Describe(ch)
+}
+
+func (sc *SLOCollector) Collect(ch chan<- prometheus.Metric) {
+	sc.provisioningDuration.Collect(ch)
+	sc.attachDuration.Collect(ch)
+	sc.mountDuration.Collect(ch)
+	sc.detachDuration.Collect(ch)
+	sc.deletionDuration.Collect(ch)
+}
+
+func (sc *SLOCollector) onPersistentVolumeClaimUpdate(oldObj, newObj interface{}) {
+	oldPVC, ok := oldObj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		return
+	}
+	newPVC, ok := newObj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		return
+	}
+	if oldPVC.Status.Phase == corev1.ClaimBound || newPVC.Status.Phase != corev1.ClaimBound {
+		return
+	}
+
+	// The PV provisioned by the CSI plugin is named after the Longhorn volume
+	volume, err := sc.ds.GetVolumeRO(newPVC.Spec.VolumeName)
+	if err != nil || volume.Status.OwnerID != sc.currentNodeID {
+		return
+	}
+	sc.provisioningDuration.WithLabelValues(sc.currentNodeID).Observe(time.Since(newPVC.CreationTimestamp.Time).Seconds())
+}
+
+func (sc *SLOCollector) onVolumeAttachmentUpdate(oldObj, newObj interface{}) {
+	oldVA, ok := oldObj.(*storagev1.VolumeAttachment)
+	if !ok {
+		return
+	}
+	newVA, ok := newObj.(*storagev1.VolumeAttachment)
+	if !ok {
+		return
+	}
+	if !sc.isLonghornVolumeAttachment(newVA) || oldVA.Status.Attached || !newVA.Status.Attached {
+		return
+	}
+	sc.attachDuration.WithLabelValues(sc.currentNodeID).Observe(time.Since(newVA.CreationTimestamp.Time).Seconds())
+}
+
+func (sc *SLOCollector) onVolumeAttachmentDelete(obj interface{}) {
+	va, ok := obj.(*storagev1.VolumeAttachment)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if va, ok = deletedState.Obj.(*storagev1.VolumeAttachment); !ok {
+			return
+		}
+	}
+	if !sc.isLonghornVolumeAttachment(va) || va.DeletionTimestamp == nil {
+		return
+	}
+	sc.detachDuration.WithLabelValues(sc.currentNodeID).Observe(time.Since(va.DeletionTimestamp.Time).Seconds())
+}
+
+func (sc *SLOCollector) isLonghornVolumeAttachment(va *storagev1.VolumeAttachment) bool {
+	return va.Spec.Attacher == types.LonghornDriverName && va.Spec.NodeName == sc.currentNodeID
+}
+
+func (sc *SLOCollector) onPodUpdate(oldObj, newObj interface{}) {
+	oldPod, ok := oldObj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	newPod, ok := newObj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	if newPod.Spec.NodeName != sc.currentNodeID {
+		return
+	}
+
+	// kubelet creates the pod sandbox once the volumes of the pod are attached and mounted
+	if isPodConditionTrue(oldPod, corev1.PodReadyToStartContainers) {
+		return
+	}
+	mounted := getPodCondition(newPod, corev1.PodReadyToStartContainers)
+	if mounted == nil || mounted.Status != corev1.ConditionTrue {
+		return
+	}
+	scheduled := getPodCondition(newPod, corev1.PodScheduled)
+	if scheduled == nil || scheduled.Status != corev1.ConditionTrue {
+		return
+	}
+	if !sc.isPodUsingLonghornVolume(newPod) {
+		return
+	}
+	sc.mountDuration.WithLabelValues(sc.currentNodeID).Observe(mounted.LastTransitionTime.Sub(scheduled.LastTransitionTime.Time).Seconds())
+}
+
+func (sc *SLOCollector) isPodUsingLonghornVolume(pod *corev1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := sc.ds.GetPersistentVolumeClaimRO(pod.Namespace, volume.PersistentVolumeClaim.ClaimName)
+		if err != nil || pvc.Spec.VolumeName == "" {
+			continue
+		}
+		if _, err := sc.ds.GetVolumeRO(pvc.Spec.VolumeName); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (sc *SLOCollector) onVolumeDelete(obj interface{}) {
+	volume, ok := obj.(*longhorn.Volume)
+	if !ok {
+		deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if volume, ok = deletedState.Obj.(*longhorn.Volume); !ok {
+			return
+		}
+	}
+	if volume.DeletionTimestamp == nil || volume.Status.OwnerID != sc.currentNodeID {
+		return
+	}
+	sc.deletionDuration.WithLabelValues(sc.currentNodeID).Observe(time.Since(volume.DeletionTimestamp.Time).Seconds())
+}
+
+func getPodCondition(pod *corev1.Pod, conditionType corev1.PodConditionType) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == conditionType {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func isPodConditionTrue(pod *corev1.Pod, conditionType corev1.PodConditionType) bool {
+	condition := getPodCondition(pod, conditionType)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
@@ -18,6 +18,7 @@ const (
 	subsystemSnapshot           = "snapshot"
 	subsystemBackingImage       = "backing_image"
 	subsystemBackupBackingImage = "backup_backing_image"
+	subsystemSLO                = "slo"
 
 	nodeLabel               = "node"
 	diskLabel               = "disk"
@@ -26,6 +26,7 @@ type VolumeCollector struct {
 	stateMetric              metricInfo
 	robustnessMetric         metricInfo
 	fileSystemReadOnlyMetric metricInfo
+	backupFreshMetric        metricInfo
 
 	volumePerfMetrics
 }
@@ -61,6 +62,16 @@ func NewVolumeCollector(
 		Type: prometheus.GaugeValue,
 	}
 
+	vc.backupFreshMetric = metricInfo{
+		Desc: prometheus.NewDesc(
+			prometheus.BuildFQName(longhornName, subsystemVolume, "backup_fresh"),
+			"Whether the last completed backup of this volume is within its backup max age: 1 means fresh, 0 means stale",
+			[]string{nodeLabel, volumeLabel, pvcLabel, pvcNamespaceLabel},
+			nil,
+		),
+		Type: prometheus.GaugeValue,
+	}
+
 	vc.capacityMetric = metricInfo{
 		Desc: prometheus.NewDesc(
 			prometheus.BuildFQName(longhornName, subsystemVolume, "capacity_bytes"),
@@ -170,6 +181,7 @@ func (vc *VolumeCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- vc.stateMetric.Desc
 	ch <- vc.robustnessMetric.Desc
 	ch <- vc.fileSystemReadOnlyMetric.Desc
+	ch <- vc.backupFreshMetric.Desc
 }
 
 func (vc *VolumeCollector) Collect(ch chan<- prometheus.Metric) {
@@ -204,6 +216,16 @@ func (vc *VolumeCollector) collectMetrics(ch chan<- prometheus.Metric, v *longho
 	ch <- prometheus.MustNewConstMetric(vc.stateMetric.Desc, vc.stateMetric.Type, float64(getVolumeStateValue(v)), vc.currentNodeID, v.Name, v.Status.KubernetesStatus.PVCName, v.Status.KubernetesStatus.Namespace)
 	ch <- prometheus.MustNewConstMetric(vc.robustnessMetric.Desc, vc.robustnessMetric.Type, float64(getVolumeRobustnessValue(v)), vc.currentNodeID, v.Name, v.Status.KubernetesStatus.PVCName, v.Status.KubernetesStatus.Namespace)
 
+	// The backup freshness is only tracked for the volumes with a backup max age
+	backupFreshCondition := types.GetCondition(v.Status.Conditions, longhorn.VolumeConditionTypeBackupFresh)
+	if v.Spec.BackupMaxAge > 0 && backupFreshCondition.Status != longhorn.ConditionStatusUnknown {
+		backupFresh := 0
+		if backupFreshCondition.Status == longhorn.ConditionStatusTrue {
+			backupFresh = 1
+		}
+		ch <- prometheus.MustNewConstMetric(vc.backupFreshMetric.Desc, vc.backupFreshMetric.Type, float64(backupFresh), vc.currentNodeID, v.Name, v.Status.KubernetesStatus.PVCName, v.Status.KubernetesStatus.Namespace)
+	}
+
 	e, err := vc.ds.GetVolumeCurrentEngine(v.Name)
 	if err != nil {
 		vc.logger.WithError(err).Debugf("Failed to get engine for volume %v", v.Name)
@@ -23,6 +23,7 @@ const (
 	UnfinishedWorkKey          = "unfinished_work_seconds"
 	LongestRunningProcessorKey = "longest_running_processor_seconds"
 	RetriesKey                 = "retries_total"
+	WorkersKey                 = "workers"
 )
 
 var (
@@ -81,8 +82,15 @@ var (
 		Help:      "Total number of retries handled by workqueue",
 	}, []string{"name"})
 
+	workers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: LonghornName,
+		Subsystem: WorkQueueSubsystem,
+		Name:      WorkersKey,
+		Help:      "Number of workers processing items from workqueue",
+	}, []string{"name"})
+
 	metrics = []prometheus.Collector{
-		depth, adds, latency, workDuration, unfinished, longestRunningProcessor, retries,
+		depth, adds, latency, workDuration, unfinished, longestRunningProcessor, retries, workers,
 	}
 )
 
@@ -125,3 +133,8 @@ func (prometheusMetricsProvider) NewLongestRunningProcessorSecondsMetric(name st
 func (prometheusMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
 	return retries.WithLabelValues(name)
 }
+
+// SetWorkers records the number of the workers processing the items from the workqueue
+func SetWorkers(name string, count int) {
+	workers.WithLabelValues(name).Set(float64(count))
+}
@@ -0,0 +1,252 @@
+package scheduler
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// CapacityRebalanceMove is a replica move planned by the capacity rebalance. The utilizations are the scheduled storage
+// utilizations in percentage of the disks before the move.
+type CapacityRebalanceMove struct {
+	VolumeName  string
+	ReplicaName string
+	Size        int64
+
+	SourceNodeID      string
+	SourceDiskID      string
+	SourceUtilization int64
+
+	TargetNodeID      string
+	TargetDiskID      string
+	TargetUtilization int64
+}
+
+type capacityRebalanceDisk struct {
+	nodeID   string
+	zone     string
+	nodeTags []string
+
+	diskUUID string
+	diskType longhorn.DiskType
+	diskTags []string
+
+	// The storage maximum without the reserved storage
+	capacity  int64
+	scheduled int64
+	// Whether new replicas can be scheduled to the disk
+	schedulable bool
+
+	// The replicas that can be moved off the disk
+	replicas []*longhorn.Replica
+}
+
+func (d *capacityRebalanceDisk) utilization() float64 {
+	return float64(d.scheduled) / float64(d.capacity)
+}
+
+// PlanCapacityRebalance plans up to maxMoves replica moves of the volumes, from the most utilized disks to the least
+// utilized schedulable disks, to lower the maximum scheduled storage utilization of the disks. A replica is only moved
+// when the utilization of the source disk exceeds the one of the target disk by the utilization gap in percentage, and
+// the target disk stays less utilized than the source disk after the move. The volumes are expected to be healthy
+// without replicas being rebuilt, and a volume has at most one replica moved.
+func (rcs *ReplicaScheduler) PlanCapacityRebalance(volumes map[string]*longhorn.Volume, maxMoves int, utilizationGap int64) ([]*CapacityRebalanceMove, error) {
+	if maxMoves <= 0 {
+		return nil, nil
+	}
+
+	allowEmptyNodeSelectorVolume, err := rcs.ds.GetSettingAsBool(types.SettingNameAllowEmptyNodeSelectorVolume)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %v setting", types.SettingNameAllowEmptyNodeSelectorVolume)
+	}
+	allowEmptyDiskSelectorVolume, err := rcs.ds.GetSettingAsBool(types.SettingNameAllowEmptyDiskSelectorVolume)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %v setting", types.SettingNameAllowEmptyDiskSelectorVolume)
+	}
+
+	nodes, err := rcs.ds.ListNodesRO()
+	if err != nil {
+		return nil, err
+	}
+	replicas, err := rcs.ds.ListReplicasRO()
+	if err != nil {
+		return nil, err
+	}
+
+	disks := map[string]*capacityRebalanceDisk{}
+	for _, node := range nodes {
+		if node.DeletionTimestamp != nil {
+			continue
+		}
+		nodeSchedulable := node.Spec.AllowScheduling && !node.Spec.EvictionRequested &&
+			types.GetCondition(node.Status.Conditions, longhorn.NodeConditionTypeReady).Status == longhorn.ConditionStatusTrue &&
+			types.GetCondition(node.Status.Conditions, longhorn.NodeConditionTypeSchedulable).Status == longhorn.ConditionStatusTrue
+		for diskName, diskStatus := range node.Status.DiskStatus {
+			diskSpec, exists := node.Spec.Disks[diskName]
+			if !exists || diskStatus.DiskUUID == "" {
+				continue
+			}
+			capacity := diskStatus.StorageMaximum - diskSpec.StorageReserved
+			if capacity <= 0 {
+				continue
+			}
+			disks[diskStatus.DiskUUID] = &capacityRebalanceDisk{
+				nodeID:   node.Name,
+				zone:     node.Status.Zone,
+				nodeTags: node.Spec.Tags,
+
+				diskUUID: diskStatus.DiskUUID,
+				diskType: diskSpec.Type,
+				diskTags: diskSpec.Tags,
+
+				capacity:  capacity,
+				scheduled: diskStatus.StorageScheduled,
+				schedulable: nodeSchedulable && diskSpec.AllowScheduling && !diskSpec.EvictionRequested &&
+					types.GetCondition(diskStatus.Conditions, longhorn.DiskConditionTypeSchedulable).Status == longhorn.ConditionStatusTrue,
+			}
+		}
+	}
+
+	for _, r := range replicas {
+		if _, ok := volumes[r.Spec.VolumeName]; !ok {
+			continue
+		}
+		if r.DeletionTimestamp != nil || !r.Spec.Active || r.Spec.HealthyAt == "" || r.Spec.FailedAt != "" || r.Spec.EvictionRequested {
+			continue
+		}
+		if disk, ok := disks[r.Spec.DiskID]; ok && disk.nodeID == r.Spec.NodeID {
+			disk.replicas = append(disk.replicas, r)
+		}
+	}
+
+	diskList := []*capacityRebalanceDisk{}
+	for _, disk := range disks {
+		diskList = append(diskList, disk)
+	}
+	return planCapacityRebalance(diskList, volumes, maxMoves, utilizationGap, allowEmptyNodeSelectorVolume, allowEmptyDiskSelectorVolume), nil
+}
+
+func planCapacityRebalance(disks []*capacityRebalanceDisk, volumes map[string]*longhorn.Volume, maxMoves int, utilizationGap int64,
+	allowEmptyNodeSelectorVolume, allowEmptyDiskSelectorVolume bool) []*CapacityRebalanceMove {
+	gap := float64(utilizationGap) / 100
+	movedVolumes := map[string]bool{}
+	moves := []*CapacityRebalanceMove{}
+
+	for len(moves) < maxMoves {
+		sort.Slice(disks, func(i, j int) bool {
+			if disks[i].utilization() != disks[j].utilization() {
+				return disks[i].utilization() > disks[j].utilization()
+			}
+			return disks[i].diskUUID < disks[j].diskUUID
+		})
+
+		move := findCapacityRebalanceMove(disks, volumes, movedVolumes, gap, allowEmptyNodeSelectorVolume, allowEmptyDiskSelectorVolume)
+		if move == nil {
+			break
+		}
+		moves = append(moves, move)
+		movedVolumes[move.VolumeName] = true
+	}
+	return moves
+}
+
+// findCapacityRebalanceMove finds the move of the largest replica on the most utilized disk having one, to the least
+// utilized disk that can take it. The disks are sorted by the utilization in descending order. The found move is
+// applied to the disks.
+func findCapacityRebalanceMove(disks []*capacityRebalanceDisk, volumes map[string]*longhorn.Volume, movedVolumes map[string]bool,
+	gap float64, allowEmptyNodeSelectorVolume, allowEmptyDiskSelectorVolume bool) *CapacityRebalanceMove {
+	for _, source := range disks {
+		replicas := append([]*longhorn.Replica{}, source.replicas...)
+		sort.Slice(replicas, func(i, j int) bool {
+			if replicas[i].Spec.VolumeSize != replicas[j].Spec.VolumeSize {
+				return replicas[i].Spec.VolumeSize > replicas[j].Spec.VolumeSize
+			}
+			return replicas[i].Name < replicas[j].Name
+		})
+
+		for _, r := range replicas {
+			volume := volumes[r.Spec.VolumeName]
+			if movedVolumes[volume.Name] {
+				continue
+			}
+			for i := len(disks) - 1; i >= 0; i-- {
+				target := disks[i]
+				if source.utilization()-target.utilization() < gap {
+					break
+				}
+				if !isCapacityRebalanceTargetDisk(target, source, disks, r, volume, allowEmptyNodeSelectorVolume, allowEmptyDiskSelectorVolume) {
+					continue
+				}
+				if float64(target.scheduled+r.Spec.VolumeSize)/float64(target.capacity) >= source.utilization() {
+					continue
+				}
+
+				move := &CapacityRebalanceMove{
+					VolumeName:  volume.Name,
+					ReplicaName: r.Name,
+					Size:        r.Spec.VolumeSize,
+
+					SourceNodeID:      source.nodeID,
+					SourceDiskID:      source.diskUUID,
+					SourceUtilization: int64(source.utilization() * 100),
+
+					TargetNodeID:      target.nodeID,
+					TargetDiskID:      target.diskUUID,
+					TargetUtilization: int64(target.utilization() * 100),
+				}
+
+				source.scheduled -= r.Spec.VolumeSize
+				target.scheduled += r.Spec.VolumeSize
+				for j := range source.replicas {
+					if source.replicas[j].Name == r.Name {
+						source.replicas = append(source.replicas[:j], source.replicas[j+1:]...)
+						break
+					}
+				}
+				target.replicas = append(target.replicas, r)
+				return move
+			}
+		}
+	}
+	return nil
+}
+
+// isCapacityRebalanceTargetDisk returns true if the replica can be moved from the source disk to the target disk. The
+// target node cannot hold any replica of the volume, and the target zone cannot hold another replica of the volume
+// unless it's the zone of the source disk.
+func isCapacityRebalanceTargetDisk(target, source *capacityRebalanceDisk, disks []*capacityRebalanceDisk, r *longhorn.Replica, volume *longhorn.Volume,
+	allowEmptyNodeSelectorVolume, allowEmptyDiskSelectorVolume bool) bool {
+	if target.nodeID == source.nodeID || !target.schedulable {
+		return false
+	}
+
+	isV1EngineFilesystemDisk := types.IsDataEngineV1(volume.Spec.DataEngine) && target.diskType == longhorn.DiskTypeFilesystem
+	isV2EngineBlockDisk := types.IsDataEngineV2(volume.Spec.DataEngine) && target.diskType == longhorn.DiskTypeBlock
+	if !isV1EngineFilesystemDisk && !isV2EngineBlockDisk {
+		return false
+	}
+
+	if !types.IsSelectorsInTags(target.nodeTags, volume.Spec.NodeSelector, allowEmptyNodeSelectorVolume) ||
+		!types.IsSelectorsInTags(target.diskTags, volume.Spec.DiskSelector, allowEmptyDiskSelectorVolume) {
+		return false
+	}
+
+	for _, disk := range disks {
+		for _, replica := range disk.replicas {
+			if replica.Spec.VolumeName != volume.Name || replica.Name == r.Name {
+				continue
+			}
+			if disk.nodeID == target.nodeID {
+				return false
+			}
+			if disk.zone == target.zone && target.zone != source.zone {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestPlanCapacityRebalance(c *C) {
+	const gib = int64(1024 * 1024 * 1024)
+
+	newDisk := func(nodeID, diskUUID, zone string, capacityGiB int64) *capacityRebalanceDisk {
+		return &capacityRebalanceDisk{
+			nodeID:      nodeID,
+			zone:        zone,
+			diskUUID:    diskUUID,
+			diskType:    longhorn.DiskTypeFilesystem,
+			capacity:    capacityGiB * gib,
+			schedulable: true,
+		}
+	}
+	volumes := map[string]*longhorn.Volume{}
+	addReplica := func(disk *capacityRebalanceDisk, volumeName, replicaName string, sizeGiB int64) {
+		if _, ok := volumes[volumeName]; !ok {
+			volumes[volumeName] = &longhorn.Volume{
+				ObjectMeta: metav1.ObjectMeta{Name: volumeName},
+				Spec:       longhorn.VolumeSpec{DataEngine: longhorn.DataEngineTypeV1},
+			}
+		}
+		disk.replicas = append(disk.replicas, &longhorn.Replica{
+			ObjectMeta: metav1.ObjectMeta{Name: replicaName},
+			Spec: longhorn.ReplicaSpec{
+				InstanceSpec: longhorn.InstanceSpec{VolumeName: volumeName, VolumeSize: sizeGiB * gib},
+			},
+		})
+		disk.scheduled += sizeGiB * gib
+	}
+
+	full := newDisk(TestNode1, "disk-1", "", 100)
+	half := newDisk(TestNode2, "disk-2", "", 100)
+	empty := newDisk(TestNode2, "disk-3", "", 100)
+	addReplica(full, "vol-a", "vol-a-r1", 40)
+	addReplica(full, "vol-b", "vol-b-r1", 30)
+	addReplica(full, "vol-c", "vol-c-r1", 20)
+	addReplica(half, "vol-a", "vol-a-r2", 40)
+	addReplica(half, "vol-d", "vol-d-r1", 10)
+	disks := []*capacityRebalanceDisk{full, half, empty}
+
+	// The utilization of the disks doesn't differ enough
+	c.Assert(planCapacityRebalance([]*capacityRebalanceDisk{half, empty}, volumes, 5, 60, false, false), HasLen, 0)
+
+	moves := planCapacityRebalance(disks, volumes, 5, 20, false, false)
+	c.Assert(moves, HasLen, 2)
+	// The largest replica is moved to the least utilized disk first, but the node already holding another replica of
+	// the volume cannot take it
+	c.Assert(*moves[0], DeepEquals, CapacityRebalanceMove{
+		VolumeName:        "vol-b",
+		ReplicaName:       "vol-b-r1",
+		Size:              30 * gib,
+		SourceNodeID:      TestNode1,
+		SourceDiskID:      "disk-1",
+		SourceUtilization: 90,
+		TargetNodeID:      TestNode2,
+		TargetDiskID:      "disk-3",
+		TargetUtilization: 0,
+	})
+	c.Assert(moves[1].ReplicaName, Equals, "vol-c-r1")
+	c.Assert(moves[1].SourceUtilization, Equals, int64(60))
+	c.Assert(moves[1].TargetDiskID, Equals, "disk-3")
+	c.Assert(moves[1].TargetUtilization, Equals, int64(30))
+	c.Assert(full.scheduled, Equals, 40*gib)
+	c.Assert(half.scheduled, Equals, 50*gib)
+	c.Assert(empty.scheduled, Equals, 50*gib)
+
+	// The moves are bounded
+	full = newDisk(TestNode1, "disk-1", "", 100)
+	empty = newDisk(TestNode2, "disk-2", "", 100)
+	volumes = map[string]*longhorn.Volume{}
+	addReplica(full, "vol-a", "vol-a-r1", 30)
+	addReplica(full, "vol-b", "vol-b-r1", 30)
+	addReplica(full, "vol-c", "vol-c-r1", 30)
+	c.Assert(planCapacityRebalance([]*capacityRebalanceDisk{full, empty}, volumes, 1, 20, false, false), HasLen, 1)
+
+	// The unschedulable disks, the disks of another zone holding a replica of the volume, and the disks not matching
+	// the disk selector of the volume are skipped
+	full = newDisk(TestNode1, "disk-1", TestZone1, 100)
+	other := newDisk(TestNode2, "disk-2", TestZone2, 100)
+	unschedulable := newDisk(TestNode3, "disk-3", TestZone1, 100)
+	unschedulable.schedulable = false
+	sameZone := newDisk("test-node-name-4", "disk-4", TestZone2, 100)
+	untagged := newDisk("test-node-name-5", "disk-5", TestZone1, 100)
+	tagged := newDisk("test-node-name-6", "disk-6", TestZone1, 100)
+	tagged.diskTags = []string{"ssd"}
+	tagged.scheduled = 10 * gib
+	volumes = map[string]*longhorn.Volume{}
+	addReplica(full, "vol-a", "vol-a-r1", 40)
+	addReplica(full, "vol-b", "vol-b-r1", 40)
+	addReplica(other, "vol-a", "vol-a-r2", 40)
+	volumes["vol-a"].Spec.DiskSelector = []string{"ssd"}
+	moves = planCapacityRebalance([]*capacityRebalanceDisk{full, other, unschedulable, sameZone, untagged, tagged}, volumes, 1, 20, false, false)
+	c.Assert(moves, HasLen, 1)
+	c.Assert(moves[0].ReplicaName, Equals, "vol-a-r1")
+	c.Assert(moves[0].TargetDiskID, Equals, "disk-6")
+}
@@ -2,11 +2,17 @@ package scheduler
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"k8s.io/utils/clock"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/longhorn/longhorn-manager/datastore"
 	"github.com/longhorn/longhorn-manager/types"
 	"github.com/longhorn/longhorn-manager/util"
@@ -51,8 +57,14 @@ func NewReplicaScheduler(ds *datastore.DataStore) *ReplicaScheduler {
 	return rcScheduler
 }
 
-// ScheduleReplica will return (nil, nil) for unschedulable replica
-func (rcs *ReplicaScheduler) ScheduleReplica(replica *longhorn.Replica, replicas map[string]*longhorn.Replica, volume *longhorn.Volume) (*longhorn.Replica, util.MultiError, error) {
+// SetClock replaces the source of the current time of the scheduler, e.g. with a fake clock in simulations
+func (rcs *ReplicaScheduler) SetClock(clock clock.PassiveClock) {
+	rcs.nowHandler = clock.Now
+}
+
+// ScheduleReplica will return (nil, nil) for unschedulable replica. If failure is not nil, the nodes and disks excluded
+// from scheduling the replica are counted in it by reason.
+func (rcs *ReplicaScheduler) ScheduleReplica(replica *longhorn.Replica, replicas map[string]*longhorn.Replica, volume *longhorn.Volume, failure *longhorn.ReplicaSchedulingFailure) (*longhorn.Replica, util.MultiError, error) {
 	// only called when replica is starting for the first time
 	if replica.Spec.NodeID != "" {
 		return nil, nil, fmt.Errorf("BUG: Replica %v has been scheduled to node %v", replica.Name, replica.Spec.NodeID)
@@ -64,7 +76,7 @@ func (rcs *ReplicaScheduler) ScheduleReplica(replica *longhorn.Replica, replicas
 		return nil, nil, nil
 	}
 
-	diskCandidates, multiError, err := rcs.FindDiskCandidates(replica, replicas, volume)
+	diskCandidates, multiError, err := rcs.FindDiskCandidates(replica, replicas, volume, failure)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -75,6 +87,17 @@ func (rcs *ReplicaScheduler) ScheduleReplica(replica *longhorn.Replica, replicas
 		return nil, multiError, nil
 	}
 
+	if rcs.scheduleReplicaToCapacityRebalanceTarget(replica, volume, diskCandidates) {
+		return replica, nil, nil
+	}
+
+	if rcs.scheduleReplicaToPlacementHint(replica, replicas, volume, diskCandidates) {
+		return replica, nil, nil
+	}
+
+	diskCandidates = rcs.filterDisksForColocatedClone(volume, diskCandidates)
+	diskCandidates = rcs.filterDisksForRebuilding(replica, diskCandidates)
+
 	rcs.scheduleReplicaToDisk(replica, diskCandidates)
 
 	return replica, nil, nil
@@ -86,21 +109,54 @@ func (rcs *ReplicaScheduler) ScheduleReplica(replica *longhorn.Replica, replicas
 // - replica: The replica for which to find disk candidates.
 // - replicas: The map of existing replicas.
 // - volume: The volume associated with the replica.
+// - failure: If not nil, the nodes and disks excluded are counted in it by reason.
 //
 // Returns:
 // - Map of disk candidates (disk UUID to Disk).
 // - MultiError for non-fatal errors encountered.
 // - Error for any fatal errors encountered.
-func (rcs *ReplicaScheduler) FindDiskCandidates(replica *longhorn.Replica, replicas map[string]*longhorn.Replica, volume *longhorn.Volume) (map[string]*Disk, util.MultiError, error) {
+func (rcs *ReplicaScheduler) FindDiskCandidates(replica *longhorn.Replica, replicas map[string]*longhorn.Replica, volume *longhorn.Volume, failure *longhorn.ReplicaSchedulingFailure) (map[string]*Disk, util.MultiError, error) {
 	nodesInfo, err := rcs.getNodeInfo()
 	if err != nil {
 		return nil, nil, err
 	}
 
-	nodeCandidates, multiError := rcs.getNodeCandidates(nodesInfo, replica)
+	if failure != nil {
+		// The nodes not ready or not allowing scheduling are not passed to the filters
+		nodes, err := rcs.ds.ListNodesRO()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, node := range nodes {
+			if replica.Spec.HardNodeAffinity != "" && node.Name != replica.Spec.HardNodeAffinity {
+				continue
+			}
+			if _, ok := nodesInfo[node.Name]; !ok {
+				failure.UnavailableNodes++
+			}
+		}
+	}
+
+	diskCandidates, multiError := rcs.findDiskCandidates(nodesInfo, replica, replicas, volume, failure)
+	return diskCandidates, multiError, nil
+}
+
+// findDiskCandidates returns the disks the replica can be scheduled to. If failure is not nil, the nodes and disks
+// excluded by the filters are counted in it by reason.
+func (rcs *ReplicaScheduler) findDiskCandidates(nodesInfo map[string]*longhorn.Node, replica *longhorn.Replica, replicas map[string]*longhorn.Replica, volume *longhorn.Volume, failure *longhorn.ReplicaSchedulingFailure) (map[string]*Disk, util.MultiError) {
+	nodeCandidates, multiError := rcs.getNodeCandidates(nodesInfo, replica, failure)
 	if len(nodeCandidates) == 0 {
 		logrus.Errorf("There's no available node for replica %v, size %v", replica.Name, replica.Spec.VolumeSize)
-		return nil, multiError, nil
+		return nil, multiError
+	}
+	soleReplicaNodeCandidates := filterNodesForSoleReplica(nodeCandidates, nodesInfo, replica, replicas)
+	if failure != nil {
+		failure.UnavailableNodes += len(nodeCandidates) - len(soleReplicaNodeCandidates)
+	}
+	nodeCandidates = soleReplicaNodeCandidates
+	if len(nodeCandidates) == 0 {
+		logrus.Errorf("There's no node allowed to hold the only replica %v of volume %v", replica.Name, volume.Name)
+		return nil, util.NewMultiError(longhorn.ErrorReplicaScheduleSoleReplicaDisallowed)
 	}
 
 	nodeDisksMap := map[string]map[string]struct{}{}
@@ -122,11 +178,10 @@ func (rcs *ReplicaScheduler) FindDiskCandidates(replica *longhorn.Replica, repli
 		nodeDisksMap[node.Name] = disks
 	}
 
-	diskCandidates, multiError := rcs.getDiskCandidates(nodeCandidates, nodeDisksMap, replicas, volume, true, false)
-	return diskCandidates, multiError, nil
+	return rcs.getDiskCandidates(nodeCandidates, nodeDisksMap, replicas, volume, true, false, failure)
 }
 
-func (rcs *ReplicaScheduler) getNodeCandidates(nodesInfo map[string]*longhorn.Node, schedulingReplica *longhorn.Replica) (nodeCandidates map[string]*longhorn.Node, multiError util.MultiError) {
+func (rcs *ReplicaScheduler) getNodeCandidates(nodesInfo map[string]*longhorn.Node, schedulingReplica *longhorn.Replica, failure *longhorn.ReplicaSchedulingFailure) (nodeCandidates map[string]*longhorn.Node, multiError util.MultiError) {
 	if schedulingReplica.Spec.HardNodeAffinity != "" {
 		node, exist := nodesInfo[schedulingReplica.Spec.HardNodeAffinity]
 		if !exist {
@@ -149,6 +204,9 @@ func (rcs *ReplicaScheduler) getNodeCandidates(nodesInfo map[string]*longhorn.No
 				return nil, util.NewMultiError(longhorn.ErrorReplicaScheduleSchedulingFailed)
 			}
 			if disabled {
+				if failure != nil {
+					failure.UnavailableNodes++
+				}
 				continue
 			}
 		}
@@ -163,6 +221,9 @@ func (rcs *ReplicaScheduler) getNodeCandidates(nodesInfo map[string]*longhorn.No
 				log = log.WithError(err)
 			}
 			log.Debugf("Excluding node in node candidates because instance manager on node is not ready")
+			if failure != nil {
+				failure.UnavailableNodes++
+			}
 			continue
 		}
 
@@ -173,6 +234,9 @@ func (rcs *ReplicaScheduler) getNodeCandidates(nodesInfo map[string]*longhorn.No
 				log = log.WithError(err)
 			}
 			log.Debugf("Excluding node in node candidates because data engine image on node is not ready")
+			if failure != nil {
+				failure.UnavailableNodes++
+			}
 		}
 	}
 
@@ -183,17 +247,45 @@ func (rcs *ReplicaScheduler) getNodeCandidates(nodesInfo map[string]*longhorn.No
 	return nodeCandidates, nil
 }
 
+func isSoleReplicaDisallowedNode(node *longhorn.Node) bool {
+	return node.Labels[types.GetLonghornLabelKey(types.LonghornLabelSoleReplicaDisallowed)] == types.LonghornLabelValueEnabled
+}
+
+// filterNodesForSoleReplica drops the nodes labeled by the maintenance policies as not allowed to hold the only
+// replica of a volume, unless another healthy replica of the volume is already on an unrestricted node.
+func filterNodesForSoleReplica(nodeCandidates, nodesInfo map[string]*longhorn.Node, schedulingReplica *longhorn.Replica, replicas map[string]*longhorn.Replica) map[string]*longhorn.Node {
+	for _, r := range replicas {
+		if r.Name == schedulingReplica.Name || r.Spec.NodeID == "" || r.Spec.FailedAt != "" || r.DeletionTimestamp != nil {
+			continue
+		}
+		if node, ok := nodesInfo[r.Spec.NodeID]; ok && !isSoleReplicaDisallowedNode(node) {
+			return nodeCandidates
+		}
+	}
+
+	filtered := map[string]*longhorn.Node{}
+	for name, node := range nodeCandidates {
+		if !isSoleReplicaDisallowedNode(node) {
+			filtered[name] = node
+		}
+	}
+	return filtered
+}
+
 // getDiskCandidates returns a map of the most appropriate disks a replica can be scheduled to (assuming it can be
 // scheduled at all). For example, consider a case in which there are two disks on nodes without a replica for a volume
 // and two disks on nodes with a replica for the same volume. getDiskCandidates only returns the disks without a
 // replica, even if the replica can legally be scheduled on all four disks.
 // Some callers (e.g. CheckAndReuseFailedReplicas) do not consider a node or zone to be used if it contains a failed
 // replica. ignoreFailedReplicas == true supports this use case.
+// If failure is not nil, the nodes and disks excluded are counted in it by reason. Each node is counted once, even if
+// it is considered again by a fallback of the anti-affinity.
 func (rcs *ReplicaScheduler) getDiskCandidates(nodeInfo map[string]*longhorn.Node,
 	nodeDisksMap map[string]map[string]struct{},
 	replicas map[string]*longhorn.Replica,
 	volume *longhorn.Volume,
-	requireSchedulingCheck, ignoreFailedReplicas bool) (map[string]*Disk, util.MultiError) {
+	requireSchedulingCheck, ignoreFailedReplicas bool,
+	failure *longhorn.ReplicaSchedulingFailure) (map[string]*Disk, util.MultiError) {
 	multiError := util.NewMultiError()
 
 	biNodeSelector := []string{}
@@ -209,39 +301,11 @@ func (rcs *ReplicaScheduler) getDiskCandidates(nodeInfo map[string]*longhorn.Nod
 		biDiskSelector = bi.Spec.DiskSelector
 	}
 
-	nodeSoftAntiAffinity, err := rcs.ds.GetSettingAsBool(types.SettingNameReplicaSoftAntiAffinity)
-	if err != nil {
-		err = errors.Wrapf(err, "failed to get %v setting", types.SettingNameReplicaSoftAntiAffinity)
-		multiError.Append(util.NewMultiError(err.Error()))
-		return map[string]*Disk{}, multiError
-	}
-
-	if volume.Spec.ReplicaSoftAntiAffinity != longhorn.ReplicaSoftAntiAffinityDefault &&
-		volume.Spec.ReplicaSoftAntiAffinity != "" {
-		nodeSoftAntiAffinity = volume.Spec.ReplicaSoftAntiAffinity == longhorn.ReplicaSoftAntiAffinityEnabled
-	}
-
-	zoneSoftAntiAffinity, err := rcs.ds.GetSettingAsBool(types.SettingNameReplicaZoneSoftAntiAffinity)
-	if err != nil {
-		err = errors.Wrapf(err, "failed to get %v setting", types.SettingNameReplicaZoneSoftAntiAffinity)
-		multiError.Append(util.NewMultiError(err.Error()))
-		return map[string]*Disk{}, multiError
-	}
-	if volume.Spec.ReplicaZoneSoftAntiAffinity != longhorn.ReplicaZoneSoftAntiAffinityDefault &&
-		volume.Spec.ReplicaZoneSoftAntiAffinity != "" {
-		zoneSoftAntiAffinity = volume.Spec.ReplicaZoneSoftAntiAffinity == longhorn.ReplicaZoneSoftAntiAffinityEnabled
-	}
-
-	diskSoftAntiAffinity, err := rcs.ds.GetSettingAsBool(types.SettingNameReplicaDiskSoftAntiAffinity)
+	nodeSoftAntiAffinity, zoneSoftAntiAffinity, diskSoftAntiAffinity, err := rcs.getSoftAntiAffinities(volume)
 	if err != nil {
-		err = errors.Wrapf(err, "failed to get %v setting", types.SettingNameReplicaDiskSoftAntiAffinity)
 		multiError.Append(util.NewMultiError(err.Error()))
 		return map[string]*Disk{}, multiError
 	}
-	if volume.Spec.ReplicaDiskSoftAntiAffinity != longhorn.ReplicaDiskSoftAntiAffinityDefault &&
-		volume.Spec.ReplicaDiskSoftAntiAffinity != "" {
-		diskSoftAntiAffinity = volume.Spec.ReplicaDiskSoftAntiAffinity == longhorn.ReplicaDiskSoftAntiAffinityEnabled
-	}
 
 	creatingNewReplicasForReplenishment := false
 	if volume.Status.Robustness == longhorn.VolumeRobustnessDegraded {
@@ -254,12 +318,22 @@ func (rcs *ReplicaScheduler) getDiskCandidates(nodeInfo map[string]*longhorn.Nod
 		creatingNewReplicasForReplenishment = timeToReplacementReplica == 0
 	}
 
+	countedNodes := map[string]bool{}
 	getDiskCandidatesFromNodes := func(nodes map[string]*longhorn.Node) (diskCandidates map[string]*Disk, multiError util.MultiError) {
 		diskCandidates = map[string]*Disk{}
 		multiError = util.NewMultiError()
 		for _, node := range nodes {
+			var nodeFailure *longhorn.ReplicaSchedulingFailure
+			if failure != nil && !countedNodes[node.Name] {
+				nodeFailure = failure
+				countedNodes[node.Name] = true
+			}
 			diskCandidatesFromNode, errors := rcs.filterNodeDisksForReplica(node, nodeDisksMap[node.Name], replicas,
-				volume, requireSchedulingCheck, biDiskSelector)
+				volume, requireSchedulingCheck, biDiskSelector, nodeFailure)
+			if nodeFailure != nil {
+				nodeFailure.DiskAntiAffinityDisks += len(diskCandidatesFromNode) -
+					len(filterDisksWithMatchingReplicas(diskCandidatesFromNode, replicas, diskSoftAntiAffinity, ignoreFailedReplicas))
+			}
 			for k, v := range diskCandidatesFromNode {
 				diskCandidates[k] = v
 			}
@@ -289,20 +363,29 @@ func (rcs *ReplicaScheduler) getDiskCandidates(nodeInfo map[string]*longhorn.Nod
 	// requirements). Track nodes that are evicting all their replicas in case we can reuse one.
 	unusedNodesAfterEviction := map[string]*longhorn.Node{}
 	unusedNodesInUnusedZonesAfterEviction := map[string]*longhorn.Node{}
+	taggedNodes := map[string]bool{}
 
 	for nodeName, node := range nodeInfo {
 		// Filter Nodes. If the Nodes don't match the tags, don't bother marking them as candidates.
 		if !types.IsSelectorsInTags(node.Spec.Tags, volume.Spec.NodeSelector, allowEmptyNodeSelectorVolume) {
+			if failure != nil {
+				failure.TagMismatchNodes++
+			}
 			continue
 		}
 		// If the Nodes don't match the tags of the backing image of this volume,
 		// don't schedule the replica on it because it will hang there
 		if volume.Spec.BackingImage != "" {
 			if !types.IsSelectorsInTags(node.Spec.Tags, biNodeSelector, allowEmptyNodeSelectorVolume) {
+				if failure != nil {
+					failure.TagMismatchNodes++
+				}
 				continue
 			}
 		}
 
+		taggedNodes[nodeName] = true
+
 		if _, ok := usedNodes[nodeName]; !ok {
 			unusedNodes[nodeName] = node
 		} else if replicaAutoBalance == longhorn.ReplicaAutoBalanceBestEffort {
@@ -362,13 +445,69 @@ func (rcs *ReplicaScheduler) getDiskCandidates(nodeInfo map[string]*longhorn.Nod
 		}
 		multiError.Append(errors)
 	}
+
+	if failure != nil {
+		// The nodes matching the tags but never considered above are excluded by the anti-affinity
+		for nodeName := range taggedNodes {
+			if countedNodes[nodeName] {
+				continue
+			}
+			if _, used := usedNodes[nodeName]; used && !nodeSoftAntiAffinity {
+				failure.NodeAntiAffinityNodes++
+			} else {
+				failure.ZoneAntiAffinityNodes++
+			}
+		}
+	}
 	return map[string]*Disk{}, multiError
 }
 
-func (rcs *ReplicaScheduler) filterNodeDisksForReplica(node *longhorn.Node, disks map[string]struct{}, replicas map[string]*longhorn.Replica, volume *longhorn.Volume, requireSchedulingCheck bool, biDiskSelector []string) (preferredDisks map[string]*Disk, multiError util.MultiError) {
+// getSoftAntiAffinities returns whether the node, zone and disk anti-affinity of the replicas of the volume are soft.
+// The volume spec overrides the global settings.
+func (rcs *ReplicaScheduler) getSoftAntiAffinities(volume *longhorn.Volume) (nodeSoftAntiAffinity, zoneSoftAntiAffinity, diskSoftAntiAffinity bool, err error) {
+	nodeSoftAntiAffinity, err = rcs.ds.GetSettingAsBool(types.SettingNameReplicaSoftAntiAffinity)
+	if err != nil {
+		return false, false, false, errors.Wrapf(err, "failed to get %v setting", types.SettingNameReplicaSoftAntiAffinity)
+	}
+	if volume.Spec.ReplicaSoftAntiAffinity != longhorn.ReplicaSoftAntiAffinityDefault &&
+		volume.Spec.ReplicaSoftAntiAffinity != "" {
+		nodeSoftAntiAffinity = volume.Spec.ReplicaSoftAntiAffinity == longhorn.ReplicaSoftAntiAffinityEnabled
+	}
+
+	zoneSoftAntiAffinity, err = rcs.ds.GetSettingAsBool(types.SettingNameReplicaZoneSoftAntiAffinity)
+	if err != nil {
+		return false, false, false, errors.Wrapf(err, "failed to get %v setting", types.SettingNameReplicaZoneSoftAntiAffinity)
+	}
+	if volume.Spec.ReplicaZoneSoftAntiAffinity != longhorn.ReplicaZoneSoftAntiAffinityDefault &&
+		volume.Spec.ReplicaZoneSoftAntiAffinity != "" {
+		zoneSoftAntiAffinity = volume.Spec.ReplicaZoneSoftAntiAffinity == longhorn.ReplicaZoneSoftAntiAffinityEnabled
+	}
+
+	diskSoftAntiAffinity, err = rcs.ds.GetSettingAsBool(types.SettingNameReplicaDiskSoftAntiAffinity)
+	if err != nil {
+		return false, false, false, errors.Wrapf(err, "failed to get %v setting", types.SettingNameReplicaDiskSoftAntiAffinity)
+	}
+	if volume.Spec.ReplicaDiskSoftAntiAffinity != longhorn.ReplicaDiskSoftAntiAffinityDefault &&
+		volume.Spec.ReplicaDiskSoftAntiAffinity != "" {
+		diskSoftAntiAffinity = volume.Spec.ReplicaDiskSoftAntiAffinity == longhorn.ReplicaDiskSoftAntiAffinityEnabled
+	}
+
+	return nodeSoftAntiAffinity, zoneSoftAntiAffinity, diskSoftAntiAffinity, nil
+}
+
+func (rcs *ReplicaScheduler) filterNodeDisksForReplica(node *longhorn.Node, disks map[string]struct{}, replicas map[string]*longhorn.Replica, volume *longhorn.Volume, requireSchedulingCheck bool, biDiskSelector []string, failure *longhorn.ReplicaSchedulingFailure) (preferredDisks map[string]*Disk, multiError util.MultiError) {
 	multiError = util.NewMultiError()
 	preferredDisks = map[string]*Disk{}
 
+	if failure != nil {
+		// The disks of the node not passed in are not available for scheduling
+		for _, diskStatus := range node.Status.DiskStatus {
+			if _, ok := disks[diskStatus.DiskUUID]; !ok {
+				failure.UnavailableDisks++
+			}
+		}
+	}
+
 	allowEmptyDiskSelectorVolume, err := rcs.ds.GetSettingAsBool(types.SettingNameAllowEmptyDiskSelectorVolume)
 	if err != nil {
 		err = errors.Wrapf(err, "failed to get %v setting", types.SettingNameAllowEmptyDiskSelectorVolume)
@@ -400,6 +539,9 @@ func (rcs *ReplicaScheduler) filterNodeDisksForReplica(node *longhorn.Node, disk
 		if !diskFound {
 			logrus.Errorf("Cannot find the spec or the status for disk %v when scheduling replica", diskUUID)
 			multiError.Append(util.NewMultiError(longhorn.ErrorReplicaScheduleDiskNotFound))
+			if failure != nil {
+				failure.UnavailableDisks++
+			}
 			continue
 		}
 
@@ -407,11 +549,17 @@ func (rcs *ReplicaScheduler) filterNodeDisksForReplica(node *longhorn.Node, disk
 		isV2EngineBlockDisk := types.IsDataEngineV2(volume.Spec.DataEngine) && diskSpec.Type == longhorn.DiskTypeBlock
 		if !isV1EngineFilesystemDisk && !isV2EngineBlockDisk {
 			logrus.Debugf("Volume %v is not compatible with disk %v", volume.Name, diskName)
+			if failure != nil {
+				failure.IncompatibleDisks++
+			}
 			continue
 		}
 
 		if !datastore.IsSupportedVolumeSize(volume.Spec.DataEngine, diskStatus.FSType, volume.Spec.Size) {
 			logrus.Debugf("Volume %v size %v is not compatible with the file system %v of the disk %v", volume.Name, volume.Spec.Size, diskStatus.Type, diskName)
+			if failure != nil {
+				failure.IncompatibleDisks++
+			}
 			continue
 		}
 
@@ -435,6 +583,9 @@ func (rcs *ReplicaScheduler) filterNodeDisksForReplica(node *longhorn.Node, disk
 			}
 			if !rcs.IsSchedulableToDisk(volume.Spec.Size, volume.Status.ActualSize, info) {
 				multiError.Append(util.NewMultiError(longhorn.ErrorReplicaScheduleInsufficientStorage))
+				if failure != nil {
+					failure.InsufficientStorageDisks++
+				}
 				continue
 			}
 		}
@@ -442,6 +593,9 @@ func (rcs *ReplicaScheduler) filterNodeDisksForReplica(node *longhorn.Node, disk
 		// Check if the Disk's Tags are valid.
 		if !types.IsSelectorsInTags(diskSpec.Tags, volume.Spec.DiskSelector, allowEmptyDiskSelectorVolume) {
 			multiError.Append(util.NewMultiError(longhorn.ErrorReplicaScheduleTagsNotFulfilled))
+			if failure != nil {
+				failure.TagMismatchDisks++
+			}
 			continue
 		}
 
@@ -450,6 +604,9 @@ func (rcs *ReplicaScheduler) filterNodeDisksForReplica(node *longhorn.Node, disk
 			// don't schedule the replica on it because it will hang there
 			if !types.IsSelectorsInTags(diskSpec.Tags, biDiskSelector, allowEmptyDiskSelectorVolume) {
 				multiError.Append(util.NewMultiError(longhorn.ErrorReplicaScheduleTagsNotFulfilled))
+				if failure != nil {
+					failure.TagMismatchDisks++
+				}
 				continue
 			}
 		}
@@ -498,6 +655,87 @@ func filterDisksWithMatchingReplicas(disks map[string]*Disk, replicas map[string
 	return disks
 }
 
+// filterDisksForColocatedClone narrows the disk candidates of a colocate-with-source clone target volume down to the
+// disks holding the source volume replicas, so the cloned data is copied within the same disk instead of across nodes.
+// It's only a placement hint. The data is still fully copied, since the engine can't share snapshots between volumes.
+func (rcs *ReplicaScheduler) filterDisksForColocatedClone(volume *longhorn.Volume, disks map[string]*Disk) map[string]*Disk {
+	if volume.Spec.CloneMode != longhorn.VolumeCloneModeColocateWithSource || !types.IsDataFromVolume(volume.Spec.DataSource) {
+		return disks
+	}
+
+	sourceVolumeName := types.GetVolumeName(volume.Spec.DataSource)
+	sourceReplicas, err := rcs.ds.ListVolumeReplicasRO(sourceVolumeName)
+	if err != nil {
+		logrus.WithError(err).Warnf("Failed to list replicas of clone source volume %v, fall back to regular scheduling", sourceVolumeName)
+		return disks
+	}
+
+	return filterDisksWithSourceReplicas(disks, sourceReplicas)
+}
+
+// filterDisksWithSourceReplicas returns the disks holding a healthy source replica.
+// Otherwise, it returns the input disks map.
+func filterDisksWithSourceReplicas(disks map[string]*Disk, sourceReplicas map[string]*longhorn.Replica) map[string]*Disk {
+	preferredDisks := map[string]*Disk{}
+	for _, r := range sourceReplicas {
+		if r.Spec.FailedAt != "" || r.Spec.HealthyAt == "" {
+			continue
+		}
+		if disk, ok := disks[r.Spec.DiskID]; ok {
+			preferredDisks[r.Spec.DiskID] = disk
+		}
+	}
+
+	if len(preferredDisks) == 0 {
+		return disks
+	}
+	return preferredDisks
+}
+
+// filterDisksForRebuilding leaves out the disks reaching their concurrent replica rebuild limit for a rebuilding
+// replica, so the rebuildings are spread over the idle disks instead of waiting for a busy one.
+func (rcs *ReplicaScheduler) filterDisksForRebuilding(replica *longhorn.Replica, disks map[string]*Disk) map[string]*Disk {
+	if !datastore.IsReplicaRebuilding(replica) {
+		return disks
+	}
+
+	rebuildingCounts := map[string]int{}
+	for diskUUID, disk := range disks {
+		if disk.ConcurrentReplicaRebuildLimit <= 0 {
+			continue
+		}
+		replicas, err := rcs.ds.ListReplicasByDiskUUID(diskUUID)
+		if err != nil {
+			logrus.WithError(err).Warnf("Failed to list replicas on disk %v, scheduling replica %v without the concurrent rebuild limit", diskUUID, replica.Name)
+			return disks
+		}
+		for _, r := range replicas {
+			if datastore.IsReplicaRebuilding(r) {
+				rebuildingCounts[diskUUID]++
+			}
+		}
+	}
+
+	return filterDisksBelowRebuildLimit(disks, rebuildingCounts)
+}
+
+// filterDisksBelowRebuildLimit returns the disks rebuilding fewer replicas than their concurrent replica rebuild limit.
+// If all the disks reach their limit, it returns the input disks map, and the rebuilding waits on the disk instead.
+func filterDisksBelowRebuildLimit(disks map[string]*Disk, rebuildingCounts map[string]int) map[string]*Disk {
+	preferredDisks := map[string]*Disk{}
+	for diskUUID, disk := range disks {
+		if disk.ConcurrentReplicaRebuildLimit > 0 && rebuildingCounts[diskUUID] >= disk.ConcurrentReplicaRebuildLimit {
+			continue
+		}
+		preferredDisks[diskUUID] = disk
+	}
+
+	if len(preferredDisks) == 0 {
+		return disks
+	}
+	return preferredDisks
+}
+
 func (rcs *ReplicaScheduler) getNodeInfo() (map[string]*longhorn.Node, error) {
 	nodeInfo, err := rcs.ds.ListNodes()
 	if err != nil {
@@ -544,6 +782,84 @@ func (rcs *ReplicaScheduler) scheduleReplicaToDisk(replica *longhorn.Replica, di
 	}).Infof("Schedule replica to node %v", replica.Spec.NodeID)
 }
 
+// scheduleReplicaToPlacementHint schedules the replica to the data directory of a replica placement hint of the volume,
+// so the replica adopts the data left on the disk. The hints whose disk is not a candidate or whose data directory is
+// used by another replica of the volume are skipped.
+func (rcs *ReplicaScheduler) scheduleReplicaToPlacementHint(replica *longhorn.Replica, replicas map[string]*longhorn.Replica, volume *longhorn.Volume, diskCandidates map[string]*Disk) bool {
+	log := logrus.WithField("replica", replica.Name)
+
+	hints, err := types.GetReplicaPlacementHints(volume)
+	if err != nil {
+		log.WithError(err).Warn("Failed to get replica placement hints, scheduling replica without them")
+		return false
+	}
+
+	for _, hint := range hints {
+		disk, exists := diskCandidates[hint.DiskID]
+		if !exists || disk.NodeID != hint.NodeID {
+			continue
+		}
+		if isDataDirectoryUsed(replicas, hint.DiskID, hint.DataDirectoryName) {
+			continue
+		}
+
+		replica.Spec.NodeID = disk.NodeID
+		replica.Spec.DiskID = disk.DiskUUID
+		replica.Spec.DiskPath = disk.Path
+		replica.Spec.DataDirectoryName = hint.DataDirectoryName
+
+		log.WithFields(logrus.Fields{
+			"disk":              replica.Spec.DiskID,
+			"diskPath":          replica.Spec.DiskPath,
+			"dataDirectoryName": replica.Spec.DataDirectoryName,
+		}).Infof("Schedule replica to node %v by replica placement hint", replica.Spec.NodeID)
+		return true
+	}
+	return false
+}
+
+// scheduleReplicaToCapacityRebalanceTarget schedules the replica to the target disk of the capacity rebalance move of
+// the volume. The replica is scheduled by the normal way if the target disk is not a candidate anymore.
+func (rcs *ReplicaScheduler) scheduleReplicaToCapacityRebalanceTarget(replica *longhorn.Replica, volume *longhorn.Volume, diskCandidates map[string]*Disk) bool {
+	log := logrus.WithField("replica", replica.Name)
+
+	move, err := types.GetCapacityRebalanceMove(volume)
+	if err != nil {
+		log.WithError(err).Warn("Failed to get capacity rebalance move, scheduling replica without it")
+		return false
+	}
+	if move == nil {
+		return false
+	}
+
+	disk, exists := diskCandidates[move.TargetDiskID]
+	if !exists || disk.NodeID != move.TargetNodeID {
+		log.Infof("Capacity rebalance target disk %v on node %v is not a candidate, scheduling replica without it", move.TargetDiskID, move.TargetNodeID)
+		return false
+	}
+
+	replica.Spec.NodeID = disk.NodeID
+	replica.Spec.DiskID = disk.DiskUUID
+	replica.Spec.DiskPath = disk.Path
+	replica.Spec.DataDirectoryName = replica.Spec.VolumeName + "-" + util.RandomID()
+
+	log.WithFields(logrus.Fields{
+		"disk":              replica.Spec.DiskID,
+		"diskPath":          replica.Spec.DiskPath,
+		"dataDirectoryName": replica.Spec.DataDirectoryName,
+	}).Infof("Schedule replica to node %v for capacity rebalance", replica.Spec.NodeID)
+	return true
+}
+
+func isDataDirectoryUsed(replicas map[string]*longhorn.Replica, diskID, dataDirectoryName string) bool {
+	for _, r := range replicas {
+		if r.Spec.DiskID == diskID && r.Spec.DataDirectoryName == dataDirectoryName {
+			return true
+		}
+	}
+	return false
+}
+
 // Investigate
 func (rcs *ReplicaScheduler) getDiskWithMostUsableStorage(disks map[string]*Disk) *Disk {
 	diskWithMostUsableStorage := &Disk{}
@@ -624,7 +940,7 @@ func (rcs *ReplicaScheduler) CheckAndReuseFailedReplica(replicas map[string]*lon
 
 	// Call getDiskCandidates with ignoreFailedReplicas == true since we want the list of candidates to include disks
 	// that already contain a failed replica.
-	diskCandidates, _ := rcs.getDiskCandidates(availableNodesInfo, availableNodeDisksMap, replicas, volume, false, true)
+	diskCandidates, _ := rcs.getDiskCandidates(availableNodesInfo, availableNodeDisksMap, replicas, volume, false, true, nil)
 
 	var reusedReplica *longhorn.Replica
 	for _, suggestDisk := range diskCandidates {
@@ -965,6 +1281,107 @@ func (rcs *ReplicaScheduler) CheckReplicasSizeExpansion(v *longhorn.Volume, oldS
 	return nil, nil
 }
 
+// CheckReplicaPlacement checks if the missing replicas of the volumes can be scheduled with the current capacity of
+// the disks, the node and disk selectors and the anti-affinity of the volumes, e.g. before the volumes are restored.
+// The volumes are placed one after the other, so the storage taken by the replicas of a volume is not available to
+// the following volumes. It returns the scheduling failures by volume name for the volumes that cannot be placed.
+func (rcs *ReplicaScheduler) CheckReplicaPlacement(volumes []*longhorn.Volume) (map[string]util.MultiError, error) {
+	nodesInfo, err := rcs.getNodeInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	placementErrors := map[string]util.MultiError{}
+	for _, volume := range volumes {
+		replicas := map[string]*longhorn.Replica{}
+		existingReplicas, err := rcs.ds.ListVolumeReplicasRO(volume.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range existingReplicas {
+			if r.Spec.NodeID != "" && r.Spec.FailedAt == "" {
+				replicas[r.Name] = r
+			}
+		}
+
+		// The image of the volume is defaulted by the volume mutator once the volume is created
+		image := volume.Spec.Image
+		if image == "" && types.IsDataEngineV1(volume.Spec.DataEngine) {
+			image, err = rcs.ds.GetSettingValueExisted(types.SettingNameDefaultEngineImage)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		placedReplicas := []*longhorn.Replica{}
+		for count := len(replicas); count < volume.Spec.NumberOfReplicas; count++ {
+			replica := &longhorn.Replica{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: fmt.Sprintf("%v-placement-%v", volume.Name, count),
+				},
+				Spec: longhorn.ReplicaSpec{
+					InstanceSpec: longhorn.InstanceSpec{
+						VolumeName: volume.Name,
+						VolumeSize: volume.Spec.Size,
+						Image:      image,
+						DataEngine: volume.Spec.DataEngine,
+					},
+				},
+			}
+
+			diskCandidates, multiError := rcs.findDiskCandidates(nodesInfo, replica, replicas, volume, nil)
+			if len(diskCandidates) == 0 {
+				if len(multiError) == 0 {
+					multiError = util.NewMultiError(longhorn.ErrorReplicaScheduleSchedulingFailed)
+				}
+				placementErrors[volume.Name] = multiError
+				break
+			}
+
+			disk := rcs.getDiskWithMostUsableStorage(diskCandidates)
+			replica.Spec.NodeID = disk.NodeID
+			replica.Spec.DiskID = disk.DiskUUID
+			replicas[replica.Name] = replica
+			placedReplicas = append(placedReplicas, replica)
+		}
+		if _, failed := placementErrors[volume.Name]; failed {
+			continue
+		}
+
+		// Account the placed replicas on the disks, the same way the scheduled replicas are accounted in the disk
+		// status
+		for _, r := range placedReplicas {
+			for _, diskStatus := range nodesInfo[r.Spec.NodeID].Status.DiskStatus {
+				if diskStatus.DiskUUID != r.Spec.DiskID {
+					continue
+				}
+				if diskStatus.ScheduledReplica == nil {
+					diskStatus.ScheduledReplica = map[string]int64{}
+				}
+				diskStatus.ScheduledReplica[r.Name] = r.Spec.VolumeSize
+				diskStatus.StorageScheduled += r.Spec.VolumeSize
+			}
+		}
+	}
+	return placementErrors, nil
+}
+
+// FormatReplicaPlacementErrors returns the scheduling failures returned by CheckReplicaPlacement as a report sorted by
+// volume name
+func FormatReplicaPlacementErrors(placementErrors map[string]util.MultiError) string {
+	volumeNames := make([]string, 0, len(placementErrors))
+	for volumeName := range placementErrors {
+		volumeNames = append(volumeNames, volumeName)
+	}
+	sort.Strings(volumeNames)
+
+	report := make([]string, 0, len(volumeNames))
+	for _, volumeName := range volumeNames {
+		report = append(report, fmt.Sprintf("volume %v: %v", volumeName, placementErrors[volumeName].Join()))
+	}
+	return strings.Join(report, ", ")
+}
+
 func findDiskSpecAndDiskStatusInNode(diskUUID string, node *longhorn.Node) (longhorn.DiskSpec, longhorn.DiskStatus, bool) {
 	for diskName, diskStatus := range node.Status.DiskStatus {
 		if diskStatus.DiskUUID == diskUUID {
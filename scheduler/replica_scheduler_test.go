@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -55,6 +56,7 @@ const (
 
 	TestZone1 = "test-zone-1"
 	TestZone2 = "test-zone-2"
+	TestZone3 = "test-zone-3"
 
 	TestTimeNow          = "2015-01-02T00:00:00Z"
 	TestTimeOneMinuteAgo = "2015-01-01T23:59:00Z"
@@ -1218,7 +1220,7 @@ func (s *TestSuite) TestReplicaScheduler(c *C) {
 			err = rIndexer.Add(r)
 			c.Assert(err, IsNil)
 
-			sr, _, err := s.ScheduleReplica(r, tc.allReplicas, volume)
+			sr, _, err := s.ScheduleReplica(r, tc.allReplicas, volume, nil)
 			if tc.err {
 				c.Assert(err, NotNil)
 			} else {
@@ -1474,6 +1476,163 @@ func (s *TestSuite) TestFilterDisksWithMatchingReplicas(c *C) {
 	}
 }
 
+func (s *TestSuite) TestScheduleReplicaToPlacementHint(c *C) {
+	rcs := &ReplicaScheduler{}
+	diskUUID1 := getDiskID(TestNode1, "1")
+	diskUUID2 := getDiskID(TestNode2, "2")
+	diskCandidates := map[string]*Disk{
+		diskUUID1: {DiskSpec: longhorn.DiskSpec{Path: TestDefaultDataPath}, DiskStatus: &longhorn.DiskStatus{DiskUUID: diskUUID1}, NodeID: TestNode1},
+		diskUUID2: {DiskSpec: longhorn.DiskSpec{Path: TestDefaultDataPath}, DiskStatus: &longhorn.DiskStatus{DiskUUID: diskUUID2}, NodeID: TestNode2},
+	}
+
+	// The replica is scheduled by the normal way without hints
+	v := newVolume(TestVolumeName, 2)
+	replica1 := newReplicaForVolume(v)
+	c.Assert(rcs.scheduleReplicaToPlacementHint(replica1, map[string]*longhorn.Replica{}, v, diskCandidates), Equals, false)
+
+	// The hints with the disk not being a candidate or on another node are skipped
+	hints := []types.ReplicaPlacementHint{
+		{NodeID: TestNode1, DiskID: getDiskID(TestNode1, "3"), DiskPath: TestDefaultDataPath, DataDirectoryName: TestVolumeName + "-a"},
+		{NodeID: TestNode1, DiskID: diskUUID2, DiskPath: TestDefaultDataPath, DataDirectoryName: TestVolumeName + "-b"},
+		{NodeID: TestNode1, DiskID: diskUUID1, DiskPath: TestDefaultDataPath, DataDirectoryName: TestVolumeName + "-c"},
+		{NodeID: TestNode2, DiskID: diskUUID2, DiskPath: TestDefaultDataPath, DataDirectoryName: TestVolumeName + "-d"},
+	}
+	value, err := json.Marshal(hints)
+	c.Assert(err, IsNil)
+	v.Annotations = map[string]string{types.GetLonghornLabelKey(types.LonghornAnnotationReplicaPlacementHint): string(value)}
+	c.Assert(rcs.scheduleReplicaToPlacementHint(replica1, map[string]*longhorn.Replica{}, v, diskCandidates), Equals, true)
+	c.Assert(replica1.Spec.NodeID, Equals, TestNode1)
+	c.Assert(replica1.Spec.DiskID, Equals, diskUUID1)
+	c.Assert(replica1.Spec.DataDirectoryName, Equals, TestVolumeName+"-c")
+
+	// The data directory used by another replica is skipped
+	replica2 := newReplicaForVolume(v)
+	replicas := map[string]*longhorn.Replica{replica1.Name: replica1}
+	c.Assert(rcs.scheduleReplicaToPlacementHint(replica2, replicas, v, diskCandidates), Equals, true)
+	c.Assert(replica2.Spec.NodeID, Equals, TestNode2)
+	c.Assert(replica2.Spec.DiskID, Equals, diskUUID2)
+	c.Assert(replica2.Spec.DataDirectoryName, Equals, TestVolumeName+"-d")
+
+	replica3 := newReplicaForVolume(v)
+	replicas[replica2.Name] = replica2
+	c.Assert(rcs.scheduleReplicaToPlacementHint(replica3, replicas, v, diskCandidates), Equals, false)
+}
+
+func (s *TestSuite) TestFilterDisksWithSourceReplicas(c *C) {
+	type testCase struct {
+		inputDiskUUIDs []string
+		sourceReplicas map[string]*longhorn.Replica
+
+		expectDiskUUIDs []string
+	}
+	tests := map[string]testCase{}
+
+	diskUUID1 := getDiskID(TestNode1, "1")
+	diskUUID2 := getDiskID(TestNode2, "2")
+	diskUUID3 := getDiskID(TestNode2, "3")
+	v := newVolume(TestVolumeName, 2)
+
+	tc := testCase{}
+	tc.inputDiskUUIDs = []string{diskUUID1, diskUUID2, diskUUID3}
+	replica1 := newReplicaForVolume(v)
+	replica1.Spec.DiskID = diskUUID1
+	replica1.Spec.HealthyAt = TestTimeNow
+	replica2 := newReplicaForVolume(v)
+	replica2.Spec.DiskID = diskUUID2
+	replica2.Spec.HealthyAt = TestTimeNow
+	tc.sourceReplicas = map[string]*longhorn.Replica{
+		replica1.Name: replica1,
+		replica2.Name: replica2,
+	}
+	tc.expectDiskUUIDs = []string{diskUUID1, diskUUID2}
+	tests["only schedule to disks with source replicas"] = tc
+
+	tc = testCase{}
+	tc.inputDiskUUIDs = []string{diskUUID1, diskUUID2, diskUUID3}
+	replica1 = newReplicaForVolume(v)
+	replica1.Spec.DiskID = diskUUID1
+	replica1.Spec.HealthyAt = TestTimeNow
+	replica1.Spec.FailedAt = TestTimeNow
+	replica2 = newReplicaForVolume(v)
+	replica2.Spec.DiskID = diskUUID2
+	tc.sourceReplicas = map[string]*longhorn.Replica{
+		replica1.Name: replica1,
+		replica2.Name: replica2,
+	}
+	tc.expectDiskUUIDs = []string{diskUUID1, diskUUID2, diskUUID3} // No healthy source replica.
+	tests["ignore failed and never healthy source replicas"] = tc
+
+	tc = testCase{}
+	tc.inputDiskUUIDs = []string{diskUUID2, diskUUID3}
+	replica1 = newReplicaForVolume(v)
+	replica1.Spec.DiskID = diskUUID1
+	replica1.Spec.HealthyAt = TestTimeNow
+	tc.sourceReplicas = map[string]*longhorn.Replica{
+		replica1.Name: replica1,
+	}
+	tc.expectDiskUUIDs = []string{diskUUID2, diskUUID3} // The source disk is not a candidate.
+	tests["fall back to all candidates"] = tc
+
+	for name, tc := range tests {
+		fmt.Printf("testing %v\n", name)
+		inputDisks := map[string]*Disk{}
+		for _, UUID := range tc.inputDiskUUIDs {
+			inputDisks[UUID] = &Disk{}
+		}
+		outputDiskUUIDs := filterDisksWithSourceReplicas(inputDisks, tc.sourceReplicas)
+		c.Assert(len(outputDiskUUIDs), Equals, len(tc.expectDiskUUIDs))
+		for _, UUID := range tc.expectDiskUUIDs {
+			_, ok := outputDiskUUIDs[UUID]
+			c.Assert(ok, Equals, true)
+		}
+	}
+}
+
+func (s *TestSuite) TestFilterDisksBelowRebuildLimit(c *C) {
+	type testCase struct {
+		diskLimits       map[string]int
+		rebuildingCounts map[string]int
+		expectDiskUUIDs  []string
+	}
+	tests := map[string]testCase{}
+
+	diskUUID1 := getDiskID(TestNode1, "1")
+	diskUUID2 := getDiskID(TestNode1, "2")
+	diskUUID3 := getDiskID(TestNode2, "1")
+
+	tc := testCase{}
+	tc.diskLimits = map[string]int{diskUUID1: 0, diskUUID2: 0}
+	tc.rebuildingCounts = map[string]int{diskUUID1: 3}
+	tc.expectDiskUUIDs = []string{diskUUID1, diskUUID2}
+	tests["disks without limit"] = tc
+
+	tc = testCase{}
+	tc.diskLimits = map[string]int{diskUUID1: 1, diskUUID2: 2, diskUUID3: 0}
+	tc.rebuildingCounts = map[string]int{diskUUID1: 1, diskUUID2: 1, diskUUID3: 5}
+	tc.expectDiskUUIDs = []string{diskUUID2, diskUUID3}
+	tests["skip disks reaching the limit"] = tc
+
+	tc = testCase{}
+	tc.diskLimits = map[string]int{diskUUID1: 1, diskUUID2: 1}
+	tc.rebuildingCounts = map[string]int{diskUUID1: 1, diskUUID2: 2}
+	tc.expectDiskUUIDs = []string{diskUUID1, diskUUID2}
+	tests["fall back to all candidates"] = tc
+
+	for name, tc := range tests {
+		fmt.Printf("testing %v\n", name)
+		inputDisks := map[string]*Disk{}
+		for UUID, limit := range tc.diskLimits {
+			inputDisks[UUID] = &Disk{DiskSpec: longhorn.DiskSpec{ConcurrentReplicaRebuildLimit: limit}}
+		}
+		outputDisks := filterDisksBelowRebuildLimit(inputDisks, tc.rebuildingCounts)
+		c.Assert(len(outputDisks), Equals, len(tc.expectDiskUUIDs), Commentf("test case %v", name))
+		for _, UUID := range tc.expectDiskUUIDs {
+			_, ok := outputDisks[UUID]
+			c.Assert(ok, Equals, true, Commentf("test case %v", name))
+		}
+	}
+}
+
 // TestGetCurrentNodesAndZones can easily be extended with additional test cases. However, it was originally written to
 // verify the behavior of getCurrentNodesAndZones when replicas with different values of
 // replica.Status.EvictionRequested were considered in different orders.
@@ -1685,6 +1844,177 @@ func (s *TestSuite) TestIsSchedulableToDiskConsiderDiskPressure(c *C) {
 	}
 }
 
+func (s *TestSuite) TestCheckReplicaPlacement(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+	nIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Nodes().Informer().GetIndexer()
+	eiIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().EngineImages().Informer().GetIndexer()
+	imIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().InstanceManagers().Informer().GetIndexer()
+	sIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Settings().Informer().GetIndexer()
+
+	rcs := newReplicaScheduler(lhClient, kubeClient, extensionsClient, informerFactories)
+
+	engineImage := newEngineImage(TestEngineImage, longhorn.EngineImageStateDeployed)
+	for _, nodeName := range []string{TestNode1, TestNode2} {
+		node := newNode(nodeName, TestNamespace, TestZone1, true, longhorn.ConditionStatusTrue)
+		node.Spec.Disks = map[string]longhorn.DiskSpec{
+			getDiskID(nodeName, "1"): newDisk(TestDefaultDataPath, true, 0),
+		}
+		node.Status.DiskStatus = map[string]*longhorn.DiskStatus{
+			getDiskID(nodeName, "1"): {
+				StorageAvailable: TestDiskAvailableSize,
+				StorageMaximum:   TestDiskSize,
+				Conditions: []longhorn.Condition{
+					newCondition(longhorn.DiskConditionTypeSchedulable, longhorn.ConditionStatusTrue),
+				},
+				DiskUUID: getDiskID(nodeName, "1"),
+				Type:     longhorn.DiskTypeFilesystem,
+			},
+		}
+		n, err := lhClient.LonghornV1beta2().Nodes(TestNamespace).Create(context.TODO(), node, metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		err = nIndexer.Add(n)
+		c.Assert(err, IsNil)
+
+		im, err := lhClient.LonghornV1beta2().InstanceManagers(TestNamespace).Create(context.TODO(), newInstanceManager(nodeName), metav1.CreateOptions{})
+		c.Assert(err, IsNil)
+		err = imIndexer.Add(im)
+		c.Assert(err, IsNil)
+
+		engineImage.Status.NodeDeploymentMap[nodeName] = true
+	}
+	ei, err := lhClient.LonghornV1beta2().EngineImages(TestNamespace).Create(context.TODO(), engineImage, metav1.CreateOptions{})
+	c.Assert(err, IsNil)
+	err = eiIndexer.Add(ei)
+	c.Assert(err, IsNil)
+	setSettings(generateSchedulerTestCase(), lhClient, sIndexer, c)
+
+	// The disks can take 5000000000 bytes of replicas. The volumes placed first take the storage of the disks, and the
+	// volumes that cannot be placed don't.
+	volume1 := newVolume("volume-1", 2)
+	volume2 := newVolume("volume-2", 2)
+	volume2.Spec.Size = 2 * TestVolumeSize
+	volume3 := newVolume("volume-3", 2)
+	volume3.Spec.Size = 2 * TestVolumeSize
+	volume4 := newVolume("volume-4", 3)
+	volume5 := newVolume("volume-5", 2)
+
+	placementErrors, err := rcs.CheckReplicaPlacement([]*longhorn.Volume{volume1, volume2, volume3, volume4, volume5})
+	c.Assert(err, IsNil)
+	c.Assert(placementErrors, HasLen, 2)
+	c.Assert(placementErrors["volume-3"], DeepEquals, util.NewMultiError(longhorn.ErrorReplicaScheduleInsufficientStorage))
+	c.Assert(placementErrors["volume-4"], NotNil)
+	c.Assert(FormatReplicaPlacementErrors(placementErrors), Matches, "volume volume-3: .*, volume volume-4: .*")
+
+	// The cached nodes are not changed by the check
+	placementErrors, err = rcs.CheckReplicaPlacement([]*longhorn.Volume{volume3})
+	c.Assert(err, IsNil)
+	c.Assert(placementErrors, HasLen, 0)
+}
+
+func (s *TestSuite) TestReplicaSchedulingFailure(c *C) {
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+
+	informerFactories := util.NewInformerFactories(TestNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+
+	nIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Nodes().Informer().GetIndexer()
+	eiIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().EngineImages().Informer().GetIndexer()
+	imIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().InstanceManagers().Informer().GetIndexer()
+	sIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Settings().Informer().GetIndexer()
+
+	rcs := newReplicaScheduler(lhClient, kubeClient, extensionsClient, informerFactories)
+	setSettings(generateSchedulerTestCase(), lhClient, sIndexer, c)
+
+	volume := newVolume(TestVolumeName, 3)
+	volume.Spec.DiskSelector = []string{"ssd"}
+	volume.Spec.ReplicaZoneSoftAntiAffinity = longhorn.ReplicaZoneSoftAntiAffinityDisabled
+	volume.Spec.ReplicaDiskSoftAntiAffinity = longhorn.ReplicaDiskSoftAntiAffinityDisabled
+	scheduledReplica := newReplicaForVolume(volume)
+	scheduledReplica.Spec.NodeID = "node-1"
+	evictingReplica := newReplicaForVolume(volume)
+	evictingReplica.Spec.NodeID = "node-8"
+	evictingReplica.Spec.DiskID = getDiskID("node-8", "1")
+	evictingReplica.Spec.EvictionRequested = true
+	replica := newReplicaForVolume(volume)
+	replicas := map[string]*longhorn.Replica{
+		scheduledReplica.Name: scheduledReplica,
+		evictingReplica.Name:  evictingReplica,
+		replica.Name:          replica,
+	}
+
+	// node-1 holds a replica, node-2 is in the same zone, node-3 doesn't allow scheduling, the disks of node-4,
+	// node-5 and node-10 are full, the disk of node-6 doesn't have the disk tag, the disk of node-7 is a block disk,
+	// the only disk of node-8 holds the evicting replica and the instance manager of node-9 is missing
+	engineImage := newEngineImage(TestEngineImage, longhorn.EngineImageStateDeployed)
+	for _, n := range []struct {
+		name                string
+		zone                string
+		allowScheduling     bool
+		storageAvailable    int64
+		diskType            longhorn.DiskType
+		diskTags            []string
+		withInstanceManager bool
+	}{
+		{"node-1", TestZone1, true, TestDiskAvailableSize, longhorn.DiskTypeFilesystem, nil, true},
+		{"node-2", TestZone1, true, TestDiskAvailableSize, longhorn.DiskTypeFilesystem, nil, true},
+		{"node-3", TestZone2, false, TestDiskAvailableSize, longhorn.DiskTypeFilesystem, nil, true},
+		{"node-4", TestZone2, true, 0, longhorn.DiskTypeFilesystem, nil, true},
+		{"node-5", TestZone2, true, 0, longhorn.DiskTypeFilesystem, nil, true},
+		{"node-6", TestZone2, true, TestDiskAvailableSize, longhorn.DiskTypeFilesystem, nil, true},
+		{"node-7", TestZone2, true, TestDiskAvailableSize, longhorn.DiskTypeBlock, []string{"ssd"}, true},
+		{"node-8", TestZone3, true, TestDiskAvailableSize, longhorn.DiskTypeFilesystem, []string{"ssd"}, true},
+		{"node-9", TestZone2, true, TestDiskAvailableSize, longhorn.DiskTypeFilesystem, []string{"ssd"}, false},
+		{"node-10", TestZone2, true, 0, longhorn.DiskTypeFilesystem, []string{"ssd"}, true},
+	} {
+		node := newNode(n.name, TestNamespace, n.zone, n.allowScheduling, longhorn.ConditionStatusTrue)
+		disk := newDisk(TestDefaultDataPath, true, 0)
+		disk.Type = n.diskType
+		disk.Tags = n.diskTags
+		node.Spec.Disks = map[string]longhorn.DiskSpec{
+			getDiskID(n.name, "1"): disk,
+		}
+		node.Status.DiskStatus = map[string]*longhorn.DiskStatus{
+			getDiskID(n.name, "1"): {
+				StorageAvailable: n.storageAvailable,
+				StorageMaximum:   TestDiskSize,
+				Conditions: []longhorn.Condition{
+					newCondition(longhorn.DiskConditionTypeSchedulable, longhorn.ConditionStatusTrue),
+				},
+				DiskUUID: getDiskID(n.name, "1"),
+				Type:     n.diskType,
+			},
+		}
+		c.Assert(nIndexer.Add(node), IsNil)
+
+		if n.withInstanceManager {
+			c.Assert(imIndexer.Add(newInstanceManager(n.name)), IsNil)
+		}
+		engineImage.Status.NodeDeploymentMap[n.name] = true
+	}
+	c.Assert(eiIndexer.Add(engineImage), IsNil)
+
+	failure := &longhorn.ReplicaSchedulingFailure{Replica: replica.Name}
+	sr, _, err := rcs.ScheduleReplica(replica, replicas, volume, failure)
+	c.Assert(err, IsNil)
+	c.Assert(sr, IsNil)
+	c.Assert(failure, DeepEquals, &longhorn.ReplicaSchedulingFailure{
+		Replica:                  replica.Name,
+		UnavailableNodes:         2,
+		NodeAntiAffinityNodes:    1,
+		ZoneAntiAffinityNodes:    1,
+		IncompatibleDisks:        1,
+		InsufficientStorageDisks: 3,
+		TagMismatchDisks:         1,
+		DiskAntiAffinityDisks:    1,
+	})
+}
+
 func getTestNow() time.Time {
 	now, _ := time.Parse(time.RFC3339, TestTimeNow)
 	return now
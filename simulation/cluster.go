@@ -0,0 +1,357 @@
+// Package simulation provides an in-memory Longhorn cluster to drive the controllers step by step without a live
+// Kubernetes cluster. The objects are kept in fake clientsets, the datastore reads them from informer caches that are
+// filled by the cluster instead of by watches, and the time is controlled by a virtual clock. The instance managers
+// are simulated by SyncVolumeInstances, which runs the engines and the replicas as desired, and the engine clients are
+// served by engine simulators. The admission webhooks are not run, except for the volume labels the controllers select
+// the objects by. It's meant for the tests of the scheduling and rebuilding behavior by the users embedding
+// longhorn-manager.
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/longhorn/longhorn-manager/controller"
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/engineapi"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+)
+
+// Cluster is an in-memory Longhorn cluster. The objects are created and updated with the fake clientsets or Add. The
+// changes made with the typed clients are visible to the datastore and the controllers right away, and the changes
+// made to the trackers directly after Refresh.
+type Cluster struct {
+	Namespace string
+
+	KubeClient        *fake.Clientset
+	LonghornClient    *lhfake.Clientset
+	ExtensionsClient  *apiextensionsfake.Clientset
+	InformerFactories *util.InformerFactories
+	DataStore         *datastore.DataStore
+
+	// Clock is the virtual clock of the controllers created by the cluster
+	Clock *clocktesting.FakeClock
+	// Engines simulates the engine clients for the controllers taking an engine client collection
+	Engines *engineapi.EngineSimulatorCollection
+
+	Logger logrus.FieldLogger
+
+	lhScheme *runtime.Scheme
+	// kubeResources are the Kubernetes resources added to the cluster, which are refreshed with the Longhorn ones
+	kubeResources map[schema.GroupVersionResource]schema.GroupVersionKind
+}
+
+// NewCluster creates an empty cluster with the Longhorn system in the namespace, and the virtual clock set to now
+func NewCluster(namespace string, now time.Time) (*Cluster, error) {
+	lhScheme := runtime.NewScheme()
+	if err := longhorn.AddToScheme(lhScheme); err != nil {
+		return nil, err
+	}
+
+	kubeClient := fake.NewSimpleClientset()
+	lhClient := lhfake.NewSimpleClientset()
+	extensionsClient := apiextensionsfake.NewSimpleClientset()
+	informerFactories := util.NewInformerFactories(namespace, kubeClient, lhClient, 0)
+
+	c := &Cluster{
+		Namespace: namespace,
+
+		KubeClient:        kubeClient,
+		LonghornClient:    lhClient,
+		ExtensionsClient:  extensionsClient,
+		InformerFactories: informerFactories,
+		DataStore:         datastore.NewDataStore(namespace, lhClient, kubeClient, extensionsClient, informerFactories),
+
+		Clock:   clocktesting.NewFakeClock(now),
+		Engines: engineapi.NewEngineSimulatorCollection(),
+
+		Logger: logrus.StandardLogger(),
+
+		lhScheme:      lhScheme,
+		kubeResources: map[schema.GroupVersionResource]schema.GroupVersionKind{},
+	}
+	c.DataStore.SetClock(c.Clock)
+	lhClient.PrependReactor("*", "*", c.newCacheReactor(lhClient.Tracker(), lhScheme))
+	kubeClient.PrependReactor("*", "*", c.newCacheReactor(kubeClient.Tracker(), kubescheme.Scheme))
+	return c, nil
+}
+
+// newCacheReactor returns a reactor applying the action to the tracker, and then to the informer caches of the
+// datastore, since the datastore verifies the changes it makes with the caches right away
+func (c *Cluster) newCacheReactor(tracker clienttesting.ObjectTracker, scheme *runtime.Scheme) clienttesting.ReactionFunc {
+	objectReaction := clienttesting.ObjectReaction(tracker)
+	return func(action clienttesting.Action) (bool, runtime.Object, error) {
+		switch a := action.(type) {
+		case clienttesting.CreateActionImpl:
+			a.Object = mutate(a.Object)
+			action = a
+		case clienttesting.UpdateActionImpl:
+			a.Object = mutate(a.Object)
+			action = a
+		}
+
+		handled, ret, err := objectReaction(action)
+		if err != nil {
+			return handled, ret, err
+		}
+
+		switch action.(type) {
+		case clienttesting.CreateAction, clienttesting.UpdateAction, clienttesting.PatchAction:
+			if ret == nil {
+				break
+			}
+			// The tracker stores the object in the namespace of the action, but returns it as it was passed in
+			if objMeta, metaErr := meta.Accessor(ret); metaErr == nil && objMeta.GetNamespace() == "" {
+				ret = ret.DeepCopyObject()
+				objMeta, _ = meta.Accessor(ret)
+				objMeta.SetNamespace(action.GetNamespace())
+			}
+			gvks, _, kindErr := scheme.ObjectKinds(ret)
+			if kindErr != nil {
+				break
+			}
+			if scheme != c.lhScheme {
+				gvr, _ := meta.UnsafeGuessKindToResource(gvks[0])
+				c.kubeResources[gvr] = gvks[0]
+			}
+			for _, informer := range c.getInformers(gvks[0]) {
+				if informer.contains(ret) {
+					if err := informer.GetIndexer().Update(ret); err != nil {
+						return true, nil, err
+					}
+				}
+			}
+		case clienttesting.DeleteAction, clienttesting.DeleteCollectionAction:
+			if err := c.Refresh(); err != nil {
+				return true, nil, err
+			}
+		}
+		return handled, ret, err
+	}
+}
+
+// Add creates the Longhorn or Kubernetes objects in the cluster, and makes them visible to the datastore
+func (c *Cluster) Add(objs ...runtime.Object) error {
+	for _, obj := range objs {
+		obj = mutate(obj)
+		if gvks, _, err := c.lhScheme.ObjectKinds(obj); err == nil {
+			if err := c.LonghornClient.Tracker().Add(obj); err != nil {
+				return err
+			}
+			if err := c.addToInformerCache(gvks[0], obj); err != nil {
+				return err
+			}
+			continue
+		}
+
+		gvks, _, err := kubescheme.Scheme.ObjectKinds(obj)
+		if err != nil {
+			return fmt.Errorf("unsupported object %T", obj)
+		}
+		if err := c.KubeClient.Tracker().Add(obj); err != nil {
+			return err
+		}
+		gvr, _ := meta.UnsafeGuessKindToResource(gvks[0])
+		c.kubeResources[gvr] = gvks[0]
+		if err := c.addToInformerCache(gvks[0], obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) addToInformerCache(gvk schema.GroupVersionKind, obj runtime.Object) error {
+	for _, informer := range c.getInformers(gvk) {
+		if !informer.contains(obj) {
+			continue
+		}
+		if err := informer.GetIndexer().Add(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Refresh replaces the informer caches with the objects in the fake clientsets, so the changes made by the controllers
+// or the test are visible to the datastore
+func (c *Cluster) Refresh() error {
+	for gvk := range c.lhScheme.AllKnownTypes() {
+		if gvk.GroupVersion() != longhorn.SchemeGroupVersion {
+			continue
+		}
+		if err := c.refreshInformerCaches(c.LonghornClient.Tracker(), gvk); err != nil {
+			return err
+		}
+	}
+	for _, gvk := range c.kubeResources {
+		if err := c.refreshInformerCaches(c.KubeClient.Tracker(), gvk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) refreshInformerCaches(tracker clienttesting.ObjectTracker, gvk schema.GroupVersionKind) error {
+	informers := c.getInformers(gvk)
+	if len(informers) == 0 {
+		return nil
+	}
+
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+	list, err := tracker.List(gvr, gvk, "")
+	if err != nil {
+		return err
+	}
+	objs, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for _, informer := range informers {
+		items := []interface{}{}
+		for _, obj := range objs {
+			if informer.contains(obj) {
+				items = append(items, obj)
+			}
+		}
+		if err := informer.GetIndexer().Replace(items, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mutate sets the labels the controllers select the objects by, which are set by the admission webhooks in a live
+// cluster
+func mutate(obj runtime.Object) runtime.Object {
+	volumeName := ""
+	switch o := obj.(type) {
+	case *longhorn.Volume:
+		volumeName = o.Name
+	case *longhorn.Engine:
+		volumeName = o.Spec.VolumeName
+	case *longhorn.Replica:
+		volumeName = o.Spec.VolumeName
+	}
+	if volumeName == "" {
+		return obj
+	}
+
+	obj = obj.DeepCopyObject()
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return obj
+	}
+	labels := objMeta.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range types.GetVolumeLabels(util.AutoCorrectName(volumeName, datastore.NameMaximumLength)) {
+		labels[k] = v
+	}
+	objMeta.SetLabels(labels)
+	return obj
+}
+
+// informer is an informer whose cache is filled by the cluster instead of by a watch
+type informer struct {
+	cache.SharedIndexInformer
+	// namespace is the only namespace watched by the informer, or empty for all namespaces
+	namespace string
+}
+
+func (i *informer) contains(obj runtime.Object) bool {
+	if i.namespace == "" {
+		return true
+	}
+	objMeta, err := meta.Accessor(obj)
+	return err == nil && objMeta.GetNamespace() == i.namespace
+}
+
+// getInformers returns the informers of the kind the datastore may read from, or none for the kinds without informers
+func (c *Cluster) getInformers(gvk schema.GroupVersionKind) []*informer {
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+
+	if gvk.GroupVersion() == longhorn.SchemeGroupVersion {
+		genericInformer, err := c.InformerFactories.LhInformerFactory.ForResource(gvr)
+		if err != nil {
+			return nil
+		}
+		return []*informer{{SharedIndexInformer: genericInformer.Informer()}}
+	}
+
+	informers := []*informer{}
+	if genericInformer, err := c.InformerFactories.KubeInformerFactory.ForResource(gvr); err == nil {
+		informers = append(informers, &informer{SharedIndexInformer: genericInformer.Informer()})
+	}
+	if genericInformer, err := c.InformerFactories.KubeNamespaceFilteredInformerFactory.ForResource(gvr); err == nil {
+		informers = append(informers, &informer{SharedIndexInformer: genericInformer.Informer(), namespace: c.Namespace})
+	}
+	return informers
+}
+
+// SetSetting sets the value of the setting. The settings not set in the cluster take their default values.
+func (c *Cluster) SetSetting(name types.SettingName, value string) error {
+	setting, err := c.LonghornClient.LonghornV1beta2().Settings(c.Namespace).Get(context.TODO(), string(name), metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return c.Add(&longhorn.Setting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      string(name),
+				Namespace: c.Namespace,
+			},
+			Value: value,
+		})
+	}
+
+	setting.Value = value
+	if _, err := c.LonghornClient.LonghornV1beta2().Settings(c.Namespace).Update(context.TODO(), setting, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	return c.Refresh()
+}
+
+// NewVolumeController creates a volume controller of the node on the virtual clock of the cluster
+func (c *Cluster) NewVolumeController(controllerID string) (*controller.VolumeController, error) {
+	vc, err := controller.NewVolumeController(c.Logger, c.DataStore, kubescheme.Scheme, c.KubeClient, c.Namespace,
+		controllerID, "", util.NewAtomicCounter())
+	if err != nil {
+		return nil, err
+	}
+	vc.SetClock(c.Clock)
+	return vc, nil
+}
+
+// SyncVolume reconciles the volume once with the volume controller, and refreshes the informer caches with the result
+func (c *Cluster) SyncVolume(vc *controller.VolumeController, name string) error {
+	if err := vc.Sync(c.Namespace + "/" + name); err != nil {
+		return err
+	}
+	return c.Refresh()
+}
+
+// Step advances the virtual clock
+func (c *Cluster) Step(d time.Duration) {
+	c.Clock.Step(d)
+}
@@ -0,0 +1,237 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	testNamespace   = "longhorn-system"
+	testNode        = "test-node-1"
+	testEngineImage = "longhorn-engine:latest"
+
+	testInstanceManagerImage = "longhorn-instance-manager:latest"
+	testVolume               = "test-volume"
+)
+
+// newTestCluster returns a cluster of three nodes with a volume of three replicas owned by testNode
+func newTestCluster(t *testing.T, now time.Time) *Cluster {
+	assert := require.New(t)
+
+	cluster, err := NewCluster(testNamespace, now)
+	assert.NoError(err)
+
+	assert.NoError(cluster.SetSetting(types.SettingNameDefaultEngineImage, testEngineImage))
+	assert.NoError(cluster.SetSetting(types.SettingNameDefaultInstanceManagerImage, testInstanceManagerImage))
+
+	// The replicas of a volume are scheduled to different nodes by default
+	nodeNames := []string{testNode, "test-node-2", "test-node-3"}
+	for i, nodeName := range nodeNames {
+		ip := fmt.Sprintf("10.0.0.%d", i+1)
+		im, err := cluster.NewInstanceManager(nodeName, testInstanceManagerImage, ip)
+		assert.NoError(err)
+		assert.NoError(cluster.Add(
+			cluster.NewManagerPod(nodeName, ip),
+			cluster.NewNode(nodeName, "disk-1", 100<<30),
+			im,
+		))
+	}
+	assert.NoError(cluster.Add(
+		cluster.NewEngineImage(testEngineImage, nodeNames...),
+		&longhorn.Volume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       testVolume,
+				Namespace:  testNamespace,
+				Finalizers: []string{longhorn.SchemeGroupVersion.Group},
+			},
+			Spec: longhorn.VolumeSpec{
+				Frontend:         longhorn.VolumeFrontendBlockDev,
+				NumberOfReplicas: 3,
+				Size:             1 << 30,
+				Image:            testEngineImage,
+				DataEngine:       longhorn.DataEngineTypeV1,
+				BackupTargetName: types.DefaultBackupTargetName,
+			},
+			Status: longhorn.VolumeStatus{
+				OwnerID: testNode,
+			},
+		},
+	))
+	return cluster
+}
+
+func TestClusterVolumeCreation(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	cluster := newTestCluster(t, now)
+
+	vc, err := cluster.NewVolumeController(testNode)
+	assert.NoError(err)
+	assert.NoError(cluster.SyncVolume(vc, testVolume))
+
+	// The objects created by the controller are visible to the datastore after the sync
+	engines, err := cluster.DataStore.ListVolumeEnginesRO(testVolume)
+	assert.NoError(err)
+	assert.Len(engines, 1)
+	replicas, err := cluster.DataStore.ListVolumeReplicasRO(testVolume)
+	assert.NoError(err)
+	assert.Len(replicas, 3)
+
+	v, err := cluster.DataStore.GetVolumeRO(testVolume)
+	assert.NoError(err)
+	assert.Equal(longhorn.VolumeStateCreating, v.Status.State)
+
+	// Syncing again doesn't create the objects twice
+	assert.NoError(cluster.SyncVolume(vc, testVolume))
+	engines, err = cluster.DataStore.ListVolumeEnginesRO(testVolume)
+	assert.NoError(err)
+	assert.Len(engines, 1)
+	replicas, err = cluster.DataStore.ListVolumeReplicasRO(testVolume)
+	assert.NoError(err)
+	assert.Len(replicas, 3)
+
+	// The changes made with the fake clientsets are visible right away
+	v = v.DeepCopy()
+	v.Spec.NumberOfReplicas = 2
+	_, err = cluster.LonghornClient.LonghornV1beta2().Volumes(testNamespace).Update(context.TODO(), v, metav1.UpdateOptions{})
+	assert.NoError(err)
+	v, err = cluster.DataStore.GetVolumeRO(testVolume)
+	assert.NoError(err)
+	assert.Equal(2, v.Spec.NumberOfReplicas)
+
+	// The controllers follow the virtual clock
+	cluster.Step(time.Hour)
+	assert.Equal(now.Add(time.Hour), cluster.Clock.Now())
+}
+
+func TestClusterReplicaRebuild(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	cluster := newTestCluster(t, now)
+
+	vc, err := cluster.NewVolumeController(testNode)
+	assert.NoError(err)
+
+	// syncVolumeUntil reconciles the volume and its instances until the volume reaches the state and robustness
+	syncVolumeUntil := func(state longhorn.VolumeState, robustness longhorn.VolumeRobustness) *longhorn.Volume {
+		var v *longhorn.Volume
+		for i := 0; i < 10; i++ {
+			assert.NoError(cluster.SyncVolume(vc, testVolume))
+			assert.NoError(cluster.SyncVolumeInstances(testVolume))
+			v, err = cluster.DataStore.GetVolumeRO(testVolume)
+			assert.NoError(err)
+			if v.Status.State == state && v.Status.Robustness == robustness {
+				return v
+			}
+		}
+		assert.Failf("volume not reconciled", "volume is %v and %v instead of %v and %v",
+			v.Status.State, v.Status.Robustness, state, robustness)
+		return v
+	}
+	syncVolumeUntil(longhorn.VolumeStateDetached, longhorn.VolumeRobustnessUnknown)
+
+	// The volume is attached to the node by the volume attachment controller in a live cluster
+	v, err := cluster.DataStore.GetVolume(testVolume)
+	assert.NoError(err)
+	v.Spec.NodeID = testNode
+	_, err = cluster.DataStore.UpdateVolume(v)
+	assert.NoError(err)
+	syncVolumeUntil(longhorn.VolumeStateAttached, longhorn.VolumeRobustnessHealthy)
+
+	replicas, err := cluster.DataStore.ListVolumeReplicasRO(testVolume)
+	assert.NoError(err)
+	assert.Len(replicas, 3)
+	failedReplicaName := ""
+	for _, r := range replicas {
+		assert.Equal(longhorn.InstanceStateRunning, r.Status.CurrentState)
+		assert.NotEmpty(r.Spec.HealthyAt)
+		if r.Spec.NodeID != testNode {
+			failedReplicaName = r.Name
+		}
+	}
+	engine, err := cluster.DataStore.GetVolumeCurrentEngine(testVolume)
+	assert.NoError(err)
+	assert.Len(engine.Status.ReplicaModeMap, 3)
+	_, err = cluster.Engines.GetEngineSimulator(testVolume)
+	assert.NoError(err)
+
+	// The failed replica is marked as failed and reused by the rebuilding, instead of being replaced by a new one
+	assert.NoError(cluster.FailReplica(failedReplicaName))
+	assert.NoError(cluster.SyncVolume(vc, testVolume))
+	v, err = cluster.DataStore.GetVolumeRO(testVolume)
+	assert.NoError(err)
+	assert.Equal(longhorn.VolumeRobustnessDegraded, v.Status.Robustness)
+	r, err := cluster.DataStore.GetReplicaRO(failedReplicaName)
+	assert.NoError(err)
+	assert.NotEmpty(r.Spec.FailedAt)
+	assert.Equal(longhorn.InstanceStateStopped, r.Spec.DesireState)
+
+	cluster.Step(time.Minute)
+	syncVolumeUntil(longhorn.VolumeStateAttached, longhorn.VolumeRobustnessHealthy)
+
+	replicas, err = cluster.DataStore.ListVolumeReplicasRO(testVolume)
+	assert.NoError(err)
+	assert.Len(replicas, 3)
+	r, err = cluster.DataStore.GetReplicaRO(failedReplicaName)
+	assert.NoError(err)
+	assert.Empty(r.Spec.FailedAt)
+	assert.Equal(now.Add(time.Minute).Format(time.RFC3339), r.Spec.HealthyAt)
+	engine, err = cluster.DataStore.GetVolumeCurrentEngine(testVolume)
+	assert.NoError(err)
+	assert.Equal(longhorn.ReplicaModeRW, engine.Status.ReplicaModeMap[failedReplicaName])
+}
+
+func TestClusterManagerHandoff(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	cluster := newTestCluster(t, now)
+	assert.NoError(cluster.SetSetting(types.SettingNameManagerHandoffGracePeriod, "60"))
+
+	// The manager pod of the node shuts down gracefully, and is reported missing until the new one is running
+	assert.NoError(cluster.DataStore.SetNodeManagerHandoff(testNode))
+	node, err := cluster.DataStore.GetNode(testNode)
+	assert.NoError(err)
+	node.Status.Conditions = types.SetCondition(node.Status.Conditions, longhorn.NodeConditionTypeReady,
+		longhorn.ConditionStatusFalse, string(longhorn.NodeConditionReasonManagerPodMissing), "")
+	_, err = cluster.DataStore.UpdateNodeStatus(node)
+	assert.NoError(err)
+
+	// The handed-off node stays available within the grace period, so its resources aren't taken over
+	isUnavailable, err := cluster.DataStore.IsNodeDownOrDeletedOrMissingManager(testNode)
+	assert.NoError(err)
+	assert.False(isUnavailable)
+
+	cluster.Step(59 * time.Second)
+	isUnavailable, err = cluster.DataStore.IsNodeDownOrDeletedOrMissingManager(testNode)
+	assert.NoError(err)
+	assert.False(isUnavailable)
+
+	// The node is unavailable once the grace period is over without a new manager pod
+	cluster.Step(2 * time.Second)
+	isUnavailable, err = cluster.DataStore.IsNodeDownOrDeletedOrMissingManager(testNode)
+	assert.NoError(err)
+	assert.True(isUnavailable)
+
+	// A missing manager pod without a handoff makes the node unavailable right away
+	assert.NoError(cluster.DataStore.SetNodeManagerHandoff(testNode))
+	isUnavailable, err = cluster.DataStore.IsNodeDownOrDeletedOrMissingManager(testNode)
+	assert.NoError(err)
+	assert.False(isUnavailable)
+	assert.NoError(cluster.DataStore.ClearNodeManagerHandoff(testNode))
+	isUnavailable, err = cluster.DataStore.IsNodeDownOrDeletedOrMissingManager(testNode)
+	assert.NoError(err)
+	assert.True(isUnavailable)
+}
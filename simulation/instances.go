@@ -0,0 +1,183 @@
+package simulation
+
+import (
+	"fmt"
+	"time"
+
+	imutil "github.com/longhorn/longhorn-instance-manager/pkg/util"
+
+	"github.com/longhorn/longhorn-manager/engineapi"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+const (
+	simulatedReplicaPort = 10000
+	simulatedEnginePort  = 20000
+)
+
+// SyncVolumeInstances simulates the instance managers running the replicas and the engine of the volume as desired.
+// The replicas are started first, so the engine is started with the replicas running by then. The engine simulator of
+// the running engine serves the engine clients of Engines.
+func (c *Cluster) SyncVolumeInstances(volumeName string) error {
+	replicas, err := c.DataStore.ListVolumeReplicas(volumeName)
+	if err != nil {
+		return err
+	}
+	replicaAddresses := map[string]string{}
+	for _, r := range replicas {
+		if c.syncInstance(&r.Spec.InstanceSpec, &r.Status.InstanceStatus) {
+			if err := c.startInstance(&r.Spec.InstanceSpec, &r.Status.InstanceStatus, simulatedReplicaPort); err != nil {
+				return err
+			}
+		}
+		if r, err = c.DataStore.UpdateReplicaStatus(r); err != nil {
+			return err
+		}
+		if r.Status.CurrentState == longhorn.InstanceStateRunning {
+			replicaAddresses[r.Name] = imutil.GetURL(r.Status.StorageIP, r.Status.Port)
+		}
+	}
+
+	engines, err := c.DataStore.ListVolumeEngines(volumeName)
+	if err != nil {
+		return err
+	}
+	for _, e := range engines {
+		if !c.syncInstance(&e.Spec.InstanceSpec, &e.Status.InstanceStatus) {
+			if e.Status.CurrentState == longhorn.InstanceStateRunning {
+				c.syncEngineReplicas(e, replicaAddresses)
+			} else {
+				e.Status.Endpoint = ""
+				e.Status.CurrentReplicaAddressMap = nil
+				e.Status.ReplicaModeMap = nil
+				_ = c.Engines.DeleteEngineSimulator(volumeName)
+			}
+			if _, err := c.DataStore.UpdateEngineStatus(e); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := c.startInstance(&e.Spec.InstanceSpec, &e.Status.InstanceStatus, simulatedEnginePort); err != nil {
+			return err
+		}
+		e.Status.Endpoint = "/dev/longhorn/" + volumeName
+		e.Status.CurrentSize = e.Spec.VolumeSize
+		c.syncEngineReplicas(e, replicaAddresses)
+		if _, err := c.DataStore.UpdateEngineStatus(e); err != nil {
+			return err
+		}
+
+		addrs := []string{}
+		for _, addr := range e.Status.CurrentReplicaAddressMap {
+			addrs = append(addrs, addr)
+		}
+		_ = c.Engines.DeleteEngineSimulator(volumeName)
+		if err := c.Engines.CreateEngineSimulator(&engineapi.EngineSimulatorRequest{
+			VolumeName:     volumeName,
+			VolumeSize:     e.Spec.VolumeSize,
+			ControllerAddr: imutil.GetURL(e.Status.IP, e.Status.Port),
+			ReplicaAddrs:   addrs,
+		}); err != nil {
+			return err
+		}
+	}
+	return c.Refresh()
+}
+
+// FailReplica simulates the replica process failing, and the engine marking the replica as failed
+func (c *Cluster) FailReplica(replicaName string) error {
+	r, err := c.DataStore.GetReplica(replicaName)
+	if err != nil {
+		return err
+	}
+	r.Status.CurrentState = longhorn.InstanceStateError
+	r.Status.IP = ""
+	r.Status.StorageIP = ""
+	r.Status.Port = 0
+	if _, err := c.DataStore.UpdateReplicaStatus(r); err != nil {
+		return err
+	}
+
+	engines, err := c.DataStore.ListVolumeEngines(r.Spec.VolumeName)
+	if err != nil {
+		return err
+	}
+	for _, e := range engines {
+		if _, exists := e.Status.ReplicaModeMap[replicaName]; !exists {
+			continue
+		}
+		e.Status.ReplicaModeMap[replicaName] = longhorn.ReplicaModeERR
+		e.Status.ReplicaTransitionTimeMap[replicaName] = c.Clock.Now().UTC().Format(time.RFC3339)
+		if _, err := c.DataStore.UpdateEngineStatus(e); err != nil {
+			return err
+		}
+	}
+	return c.Refresh()
+}
+
+// syncEngineReplicas simulates the engine adding the running replicas of its spec, which are rebuilt right away, and
+// removing the ones no longer in its spec. The failed replicas are kept until they are removed from the spec.
+func (c *Cluster) syncEngineReplicas(e *longhorn.Engine, replicaAddresses map[string]string) {
+	now := c.Clock.Now().UTC().Format(time.RFC3339)
+	currentReplicaAddressMap := map[string]string{}
+	replicaModeMap := map[string]longhorn.ReplicaMode{}
+	replicaTransitionTimeMap := map[string]string{}
+	for replicaName, addr := range e.Spec.ReplicaAddressMap {
+		if mode, exists := e.Status.ReplicaModeMap[replicaName]; exists {
+			currentReplicaAddressMap[replicaName] = e.Status.CurrentReplicaAddressMap[replicaName]
+			replicaModeMap[replicaName] = mode
+			replicaTransitionTimeMap[replicaName] = e.Status.ReplicaTransitionTimeMap[replicaName]
+			continue
+		}
+		if replicaAddresses[replicaName] != addr {
+			continue
+		}
+		currentReplicaAddressMap[replicaName] = addr
+		replicaModeMap[replicaName] = longhorn.ReplicaModeRW
+		replicaTransitionTimeMap[replicaName] = now
+	}
+	for replicaName, mode := range e.Status.ReplicaModeMap {
+		if _, exists := replicaModeMap[replicaName]; !exists && mode == longhorn.ReplicaModeERR {
+			if _, exists := e.Spec.ReplicaAddressMap[replicaName]; exists {
+				replicaModeMap[replicaName] = mode
+			}
+		}
+	}
+	e.Status.CurrentReplicaAddressMap = currentReplicaAddressMap
+	e.Status.ReplicaModeMap = replicaModeMap
+	e.Status.ReplicaTransitionTimeMap = replicaTransitionTimeMap
+}
+
+// syncInstance stops the instance if it's not desired to be running, and returns true if the instance is to be started
+func (c *Cluster) syncInstance(spec *longhorn.InstanceSpec, status *longhorn.InstanceStatus) bool {
+	if spec.DesireState == longhorn.InstanceStateRunning {
+		return status.CurrentState != longhorn.InstanceStateRunning && status.CurrentState != longhorn.InstanceStateError
+	}
+	status.CurrentState = longhorn.InstanceStateStopped
+	status.IP = ""
+	status.StorageIP = ""
+	status.Port = 0
+	status.Started = false
+	return false
+}
+
+// startInstance sets the status of a running instance on the default instance manager of the node of the instance
+func (c *Cluster) startInstance(spec *longhorn.InstanceSpec, status *longhorn.InstanceStatus, port int) error {
+	if spec.NodeID == "" {
+		return fmt.Errorf("instance of volume %v is not scheduled to a node", spec.VolumeName)
+	}
+	im, err := c.DataStore.GetDefaultInstanceManagerByNodeRO(spec.NodeID, spec.DataEngine)
+	if err != nil {
+		return err
+	}
+	status.InstanceManagerName = im.Name
+	status.CurrentState = longhorn.InstanceStateRunning
+	status.CurrentImage = spec.Image
+	status.IP = im.Status.IP
+	status.StorageIP = im.Status.IP
+	status.Port = port
+	status.Started = true
+	return nil
+}
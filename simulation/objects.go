@@ -0,0 +1,139 @@
+package simulation
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	emeta "github.com/longhorn/longhorn-engine/pkg/meta"
+
+	"github.com/longhorn/longhorn-manager/engineapi"
+	"github.com/longhorn/longhorn-manager/types"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+// NewManagerPod returns a running longhorn-manager pod of the node, which makes the node a Longhorn node
+func (c *Cluster) NewManagerPod(nodeName, podIP string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "longhorn-manager-" + nodeName,
+			Namespace: c.Namespace,
+			Labels:    types.GetManagerLabels(),
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			PodIP: podIP,
+			Conditions: []corev1.PodCondition{
+				{
+					Type:   corev1.PodReady,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		},
+	}
+}
+
+// NewNode returns a ready and schedulable node with a schedulable disk of the size
+func (c *Cluster) NewNode(name, diskName string, diskSize int64) *longhorn.Node {
+	diskPath := "/var/lib/longhorn-" + diskName
+	return &longhorn.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.Namespace,
+		},
+		Spec: longhorn.NodeSpec{
+			Name:            name,
+			AllowScheduling: true,
+			Disks: map[string]longhorn.DiskSpec{
+				diskName: {
+					Type:            longhorn.DiskTypeFilesystem,
+					Path:            diskPath,
+					DiskDriver:      longhorn.DiskDriverNone,
+					AllowScheduling: true,
+				},
+			},
+		},
+		Status: longhorn.NodeStatus{
+			Conditions: []longhorn.Condition{
+				{Type: longhorn.NodeConditionTypeReady, Status: longhorn.ConditionStatusTrue},
+				{Type: longhorn.NodeConditionTypeSchedulable, Status: longhorn.ConditionStatusTrue},
+			},
+			DiskStatus: map[string]*longhorn.DiskStatus{
+				diskName: {
+					Conditions: []longhorn.Condition{
+						{Type: longhorn.DiskConditionTypeReady, Status: longhorn.ConditionStatusTrue},
+						{Type: longhorn.DiskConditionTypeSchedulable, Status: longhorn.ConditionStatusTrue},
+					},
+					StorageAvailable: diskSize,
+					StorageMaximum:   diskSize,
+					DiskUUID:         diskName,
+					Type:             longhorn.DiskTypeFilesystem,
+					DiskPath:         diskPath,
+				},
+			},
+		},
+	}
+}
+
+// NewEngineImage returns a ready engine image deployed on the nodes
+func (c *Cluster) NewEngineImage(image string, nodeNames ...string) *longhorn.EngineImage {
+	nodeDeploymentMap := map[string]bool{}
+	for _, nodeName := range nodeNames {
+		nodeDeploymentMap[nodeName] = true
+	}
+	return &longhorn.EngineImage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      types.GetEngineImageChecksumName(image),
+			Namespace: c.Namespace,
+		},
+		Spec: longhorn.EngineImageSpec{
+			Image: image,
+		},
+		Status: longhorn.EngineImageStatus{
+			State: longhorn.EngineImageStateDeployed,
+			EngineVersionDetails: longhorn.EngineVersionDetails{
+				CLIAPIVersion:           emeta.CLIAPIVersion,
+				CLIAPIMinVersion:        emeta.CLIAPIMinVersion,
+				ControllerAPIVersion:    emeta.ControllerAPIVersion,
+				ControllerAPIMinVersion: emeta.ControllerAPIMinVersion,
+				DataFormatVersion:       emeta.DataFormatVersion,
+				DataFormatMinVersion:    emeta.DataFormatMinVersion,
+			},
+			Conditions: []longhorn.Condition{
+				{Type: longhorn.EngineImageConditionTypeReady, Status: longhorn.ConditionStatusTrue},
+			},
+			NodeDeploymentMap: nodeDeploymentMap,
+		},
+	}
+}
+
+// NewInstanceManager returns a running v1 instance manager of the node with the image
+func (c *Cluster) NewInstanceManager(nodeName, image, ip string) (*longhorn.InstanceManager, error) {
+	name, err := types.GetInstanceManagerName(longhorn.InstanceManagerTypeAllInOne, nodeName, image, string(longhorn.DataEngineTypeV1))
+	if err != nil {
+		return nil, err
+	}
+	return &longhorn.InstanceManager{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.Namespace,
+			Labels:    types.GetInstanceManagerLabels(nodeName, image, longhorn.InstanceManagerTypeAllInOne, longhorn.DataEngineTypeV1),
+		},
+		Spec: longhorn.InstanceManagerSpec{
+			Image:      image,
+			NodeID:     nodeName,
+			Type:       longhorn.InstanceManagerTypeAllInOne,
+			DataEngine: longhorn.DataEngineTypeV1,
+		},
+		Status: longhorn.InstanceManagerStatus{
+			OwnerID:       nodeName,
+			CurrentState:  longhorn.InstanceManagerStateRunning,
+			IP:            ip,
+			APIMinVersion: engineapi.MinInstanceManagerAPIVersion,
+			APIVersion:    engineapi.CurrentInstanceManagerAPIVersion,
+		},
+	}, nil
+}
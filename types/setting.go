@@ -1,7 +1,9 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -14,6 +16,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/longhorn/longhorn-manager/meta"
 	"github.com/longhorn/longhorn-manager/util"
@@ -61,6 +64,8 @@ const (
 	SettingNameReplicaSoftAntiAffinity                                  = SettingName("replica-soft-anti-affinity")
 	SettingNameReplicaAutoBalance                                       = SettingName("replica-auto-balance")
 	SettingNameReplicaAutoBalanceDiskPressurePercentage                 = SettingName("replica-auto-balance-disk-pressure-percentage")
+	SettingNameReplicaAutoBalanceDiskIOLatencyThreshold                 = SettingName("replica-auto-balance-disk-io-latency-threshold")
+	SettingNameReplicaAutoBalanceDiskPressureInterval                   = SettingName("replica-auto-balance-disk-pressure-interval")
 	SettingNameStorageOverProvisioningPercentage                        = SettingName("storage-over-provisioning-percentage")
 	SettingNameStorageMinimalAvailablePercentage                        = SettingName("storage-minimal-available-percentage")
 	SettingNameStorageReservedPercentageForDefaultDisk                  = SettingName("storage-reserved-percentage-for-default-disk")
@@ -88,6 +93,7 @@ const (
 	SettingNameDisableRevisionCounter                                   = SettingName("disable-revision-counter")
 	SettingNameReplicaReplenishmentWaitInterval                         = SettingName("replica-replenishment-wait-interval")
 	SettingNameConcurrentReplicaRebuildPerNodeLimit                     = SettingName("concurrent-replica-rebuild-per-node-limit")
+	SettingNameReplicaRebuildPriorityPolicy                             = SettingName("replica-rebuild-priority-policy")
 	SettingNameConcurrentBackingImageCopyReplenishPerNodeLimit          = SettingName("concurrent-backing-image-replenish-per-node-limit")
 	SettingNameConcurrentBackupRestorePerNodeLimit                      = SettingName("concurrent-volume-backup-restore-per-node-limit")
 	SettingNameSystemManagedPodsImagePullPolicy                         = SettingName("system-managed-pods-image-pull-policy")
@@ -102,6 +108,7 @@ const (
 	SettingNameOrphanAutoDeletion                                       = SettingName("orphan-auto-deletion")
 	SettingNameStorageNetwork                                           = SettingName("storage-network")
 	SettingNameStorageNetworkForRWXVolumeEnabled                        = SettingName("storage-network-for-rwx-volume-enabled")
+	SettingNameIPFamily                                                 = SettingName("ip-family")
 	SettingNameFailedBackupTTL                                          = SettingName("failed-backup-ttl")
 	SettingNameRecurringSuccessfulJobsHistoryLimit                      = SettingName("recurring-successful-jobs-history-limit")
 	SettingNameRecurringFailedJobsHistoryLimit                          = SettingName("recurring-failed-jobs-history-limit")
@@ -141,6 +148,46 @@ const (
 	SettingNameDefaultMinNumberOfBackingImageCopies                     = SettingName("default-min-number-of-backing-image-copies")
 	SettingNameBackupExecutionTimeout                                   = SettingName("backup-execution-timeout")
 	SettingNameRWXVolumeFastFailover                                    = SettingName("rwx-volume-fast-failover")
+	SettingNameVolumeWarmPool                                           = SettingName("volume-warm-pool")
+	SettingNameBlockMountOnFilesystemResizeFailure                      = SettingName("block-mount-on-filesystem-resize-failure")
+	SettingNameCSIMaxVolumesPerNode                                     = SettingName("csi-max-volumes-per-node")
+	SettingNameSafetySnapshotPolicy                                     = SettingName("safety-snapshot-policy")
+	SettingNameSafetySnapshotRetentionPeriod                            = SettingName("safety-snapshot-retention-period")
+	SettingNameManagerHandoffGracePeriod                                = SettingName("manager-handoff-grace-period")
+	SettingNameBackupTargetUnavailablePolicy                            = SettingName("backup-target-unavailable-policy")
+	SettingNameBackupTargetUnavailableTimeout                           = SettingName("backup-target-unavailable-timeout")
+	SettingNameFIPSModeEnabled                                          = SettingName("fips-mode-enabled")
+	SettingNameNodeZoneLabelKeys                                        = SettingName("node-zone-label-keys")
+	SettingNameNodeRegionLabelKeys                                      = SettingName("node-region-label-keys")
+	SettingNameNodeTagLabels                                            = SettingName("node-tag-labels")
+	SettingNameRestoreReplicaPlacementCheck                             = SettingName("restore-replica-placement-check")
+	SettingNameConcurrentReplicaScrubPerClusterLimit                    = SettingName("concurrent-replica-scrub-per-cluster-limit")
+	SettingNameDataLocalityLoadAwareDiskUsageThreshold                  = SettingName("data-locality-load-aware-disk-usage-threshold")
+	SettingNameDataLocalityLoadAwareIOUtilizationThreshold              = SettingName("data-locality-load-aware-io-utilization-threshold")
+	SettingNameEngineImageGarbageCollection                             = SettingName("engine-image-garbage-collection")
+	SettingNameEngineImageGarbageCollectionWaitInterval                 = SettingName("engine-image-garbage-collection-wait-interval")
+	SettingNameEngineImageGarbageCollectionKeepCount                    = SettingName("engine-image-garbage-collection-keep-count")
+	SettingNameAutoFilesystemTrim                                       = SettingName("auto-filesystem-trim")
+	SettingNameAutoFilesystemTrimInterval                               = SettingName("auto-filesystem-trim-interval")
+	SettingNameAutoFilesystemTrimDeletedSpaceThreshold                  = SettingName("auto-filesystem-trim-deleted-space-threshold")
+	SettingNameConcurrentAutoFilesystemTrimPerNodeLimit                 = SettingName("concurrent-auto-filesystem-trim-per-node-limit")
+	SettingNameNodeDownStaleAttachmentCleanup                           = SettingName("node-down-stale-attachment-cleanup")
+	SettingNameInstanceManagerCPUPerInstance                            = SettingName("instance-manager-cpu-per-instance")
+	SettingNameInstanceManagerMemoryPerInstance                         = SettingName("instance-manager-memory-per-instance")
+	SettingNameSystemManagedComponentsSchedulingOverrides               = SettingName("system-managed-components-scheduling-overrides")
+	SettingNameVolumeReconciliationSharding                             = SettingName("volume-reconciliation-sharding")
+	SettingNameControllerWorkers                                        = SettingName("controller-workers")
+	SettingNameOrphanInstanceAutoDeletionGracePeriod                    = SettingName("orphan-instance-auto-deletion-grace-period")
+	SettingNameOrphanBackingImageAutoDeletion                           = SettingName("orphan-backing-image-auto-deletion")
+	SettingNameEngineRebindTimeout                                      = SettingName("engine-rebind-timeout")
+	SettingNameCapacityRebalance                                        = SettingName("capacity-rebalance")
+	SettingNameCapacityRebalanceInterval                                = SettingName("capacity-rebalance-interval")
+	SettingNameCapacityRebalanceUtilizationGap                          = SettingName("capacity-rebalance-utilization-gap")
+	SettingNameConcurrentCapacityRebalancePerClusterLimit               = SettingName("concurrent-capacity-rebalance-per-cluster-limit")
+	SettingNameNodeFlappingThreshold                                    = SettingName("node-flapping-threshold")
+	SettingNameNodeFlappingDetectionWindow                              = SettingName("node-flapping-detection-window")
+	SettingNameNodeFlappingSchedulingCooldown                           = SettingName("node-flapping-scheduling-cooldown")
+	SettingNameVolumeImportAllowedSourceDirectories                     = SettingName("volume-import-allowed-source-directories")
 	// These three backup target parameters are used in the "longhorn-default-resource" ConfigMap
 	// to update the default BackupTarget resource.
 	// Longhorn won't create the Setting resources for these three parameters.
@@ -161,6 +208,8 @@ var (
 		SettingNameReplicaSoftAntiAffinity,
 		SettingNameReplicaAutoBalance,
 		SettingNameReplicaAutoBalanceDiskPressurePercentage,
+		SettingNameReplicaAutoBalanceDiskIOLatencyThreshold,
+		SettingNameReplicaAutoBalanceDiskPressureInterval,
 		SettingNameStorageOverProvisioningPercentage,
 		SettingNameStorageMinimalAvailablePercentage,
 		SettingNameStorageReservedPercentageForDefaultDisk,
@@ -188,6 +237,7 @@ var (
 		SettingNameDisableRevisionCounter,
 		SettingNameReplicaReplenishmentWaitInterval,
 		SettingNameConcurrentReplicaRebuildPerNodeLimit,
+		SettingNameReplicaRebuildPriorityPolicy,
 		SettingNameConcurrentBackingImageCopyReplenishPerNodeLimit,
 		SettingNameConcurrentBackupRestorePerNodeLimit,
 		SettingNameSystemManagedPodsImagePullPolicy,
@@ -202,6 +252,7 @@ var (
 		SettingNameOrphanAutoDeletion,
 		SettingNameStorageNetwork,
 		SettingNameStorageNetworkForRWXVolumeEnabled,
+		SettingNameIPFamily,
 		SettingNameFailedBackupTTL,
 		SettingNameRecurringSuccessfulJobsHistoryLimit,
 		SettingNameRecurringFailedJobsHistoryLimit,
@@ -241,6 +292,46 @@ var (
 		SettingNameDefaultMinNumberOfBackingImageCopies,
 		SettingNameBackupExecutionTimeout,
 		SettingNameRWXVolumeFastFailover,
+		SettingNameVolumeWarmPool,
+		SettingNameBlockMountOnFilesystemResizeFailure,
+		SettingNameCSIMaxVolumesPerNode,
+		SettingNameSafetySnapshotPolicy,
+		SettingNameSafetySnapshotRetentionPeriod,
+		SettingNameManagerHandoffGracePeriod,
+		SettingNameBackupTargetUnavailablePolicy,
+		SettingNameBackupTargetUnavailableTimeout,
+		SettingNameFIPSModeEnabled,
+		SettingNameNodeZoneLabelKeys,
+		SettingNameNodeRegionLabelKeys,
+		SettingNameNodeTagLabels,
+		SettingNameRestoreReplicaPlacementCheck,
+		SettingNameConcurrentReplicaScrubPerClusterLimit,
+		SettingNameDataLocalityLoadAwareDiskUsageThreshold,
+		SettingNameDataLocalityLoadAwareIOUtilizationThreshold,
+		SettingNameEngineImageGarbageCollection,
+		SettingNameEngineImageGarbageCollectionWaitInterval,
+		SettingNameEngineImageGarbageCollectionKeepCount,
+		SettingNameAutoFilesystemTrim,
+		SettingNameAutoFilesystemTrimInterval,
+		SettingNameAutoFilesystemTrimDeletedSpaceThreshold,
+		SettingNameConcurrentAutoFilesystemTrimPerNodeLimit,
+		SettingNameNodeDownStaleAttachmentCleanup,
+		SettingNameInstanceManagerCPUPerInstance,
+		SettingNameInstanceManagerMemoryPerInstance,
+		SettingNameSystemManagedComponentsSchedulingOverrides,
+		SettingNameVolumeReconciliationSharding,
+		SettingNameControllerWorkers,
+		SettingNameOrphanInstanceAutoDeletionGracePeriod,
+		SettingNameOrphanBackingImageAutoDeletion,
+		SettingNameEngineRebindTimeout,
+		SettingNameCapacityRebalance,
+		SettingNameCapacityRebalanceInterval,
+		SettingNameCapacityRebalanceUtilizationGap,
+		SettingNameConcurrentCapacityRebalancePerClusterLimit,
+		SettingNameNodeFlappingThreshold,
+		SettingNameNodeFlappingDetectionWindow,
+		SettingNameNodeFlappingSchedulingCooldown,
+		SettingNameVolumeImportAllowedSourceDirectories,
 	}
 )
 
@@ -283,6 +374,8 @@ var (
 		SettingNameReplicaSoftAntiAffinity:                                  SettingDefinitionReplicaSoftAntiAffinity,
 		SettingNameReplicaAutoBalance:                                       SettingDefinitionReplicaAutoBalance,
 		SettingNameReplicaAutoBalanceDiskPressurePercentage:                 SettingDefinitionReplicaAutoBalanceDiskPressurePercentage,
+		SettingNameReplicaAutoBalanceDiskIOLatencyThreshold:                 SettingDefinitionReplicaAutoBalanceDiskIOLatencyThreshold,
+		SettingNameReplicaAutoBalanceDiskPressureInterval:                   SettingDefinitionReplicaAutoBalanceDiskPressureInterval,
 		SettingNameStorageOverProvisioningPercentage:                        SettingDefinitionStorageOverProvisioningPercentage,
 		SettingNameStorageMinimalAvailablePercentage:                        SettingDefinitionStorageMinimalAvailablePercentage,
 		SettingNameStorageReservedPercentageForDefaultDisk:                  SettingDefinitionStorageReservedPercentageForDefaultDisk,
@@ -310,6 +403,7 @@ var (
 		SettingNameDisableRevisionCounter:                                   SettingDefinitionDisableRevisionCounter,
 		SettingNameReplicaReplenishmentWaitInterval:                         SettingDefinitionReplicaReplenishmentWaitInterval,
 		SettingNameConcurrentReplicaRebuildPerNodeLimit:                     SettingDefinitionConcurrentReplicaRebuildPerNodeLimit,
+		SettingNameReplicaRebuildPriorityPolicy:                             SettingDefinitionReplicaRebuildPriorityPolicy,
 		SettingNameConcurrentBackingImageCopyReplenishPerNodeLimit:          SettingDefinitionConcurrentBackingImageCopyReplenishPerNodeLimit,
 		SettingNameConcurrentBackupRestorePerNodeLimit:                      SettingDefinitionConcurrentVolumeBackupRestorePerNodeLimit,
 		SettingNameSystemManagedPodsImagePullPolicy:                         SettingDefinitionSystemManagedPodsImagePullPolicy,
@@ -324,6 +418,7 @@ var (
 		SettingNameOrphanAutoDeletion:                                       SettingDefinitionOrphanAutoDeletion,
 		SettingNameStorageNetwork:                                           SettingDefinitionStorageNetwork,
 		SettingNameStorageNetworkForRWXVolumeEnabled:                        SettingDefinitionStorageNetworkForRWXVolumeEnabled,
+		SettingNameIPFamily:                                                 SettingDefinitionIPFamily,
 		SettingNameFailedBackupTTL:                                          SettingDefinitionFailedBackupTTL,
 		SettingNameRecurringSuccessfulJobsHistoryLimit:                      SettingDefinitionRecurringSuccessfulJobsHistoryLimit,
 		SettingNameRecurringFailedJobsHistoryLimit:                          SettingDefinitionRecurringFailedJobsHistoryLimit,
@@ -363,6 +458,46 @@ var (
 		SettingNameDefaultMinNumberOfBackingImageCopies:                     SettingDefinitionDefaultMinNumberOfBackingImageCopies,
 		SettingNameBackupExecutionTimeout:                                   SettingDefinitionBackupExecutionTimeout,
 		SettingNameRWXVolumeFastFailover:                                    SettingDefinitionRWXVolumeFastFailover,
+		SettingNameVolumeWarmPool:                                           SettingDefinitionVolumeWarmPool,
+		SettingNameBlockMountOnFilesystemResizeFailure:                      SettingDefinitionBlockMountOnFilesystemResizeFailure,
+		SettingNameCSIMaxVolumesPerNode:                                     SettingDefinitionCSIMaxVolumesPerNode,
+		SettingNameSafetySnapshotPolicy:                                     SettingDefinitionSafetySnapshotPolicy,
+		SettingNameSafetySnapshotRetentionPeriod:                            SettingDefinitionSafetySnapshotRetentionPeriod,
+		SettingNameManagerHandoffGracePeriod:                                SettingDefinitionManagerHandoffGracePeriod,
+		SettingNameBackupTargetUnavailablePolicy:                            SettingDefinitionBackupTargetUnavailablePolicy,
+		SettingNameBackupTargetUnavailableTimeout:                           SettingDefinitionBackupTargetUnavailableTimeout,
+		SettingNameFIPSModeEnabled:                                          SettingDefinitionFIPSModeEnabled,
+		SettingNameNodeZoneLabelKeys:                                        SettingDefinitionNodeZoneLabelKeys,
+		SettingNameNodeRegionLabelKeys:                                      SettingDefinitionNodeRegionLabelKeys,
+		SettingNameNodeTagLabels:                                            SettingDefinitionNodeTagLabels,
+		SettingNameRestoreReplicaPlacementCheck:                             SettingDefinitionRestoreReplicaPlacementCheck,
+		SettingNameConcurrentReplicaScrubPerClusterLimit:                    SettingDefinitionConcurrentReplicaScrubPerClusterLimit,
+		SettingNameDataLocalityLoadAwareDiskUsageThreshold:                  SettingDefinitionDataLocalityLoadAwareDiskUsageThreshold,
+		SettingNameDataLocalityLoadAwareIOUtilizationThreshold:              SettingDefinitionDataLocalityLoadAwareIOUtilizationThreshold,
+		SettingNameEngineImageGarbageCollection:                             SettingDefinitionEngineImageGarbageCollection,
+		SettingNameEngineImageGarbageCollectionWaitInterval:                 SettingDefinitionEngineImageGarbageCollectionWaitInterval,
+		SettingNameEngineImageGarbageCollectionKeepCount:                    SettingDefinitionEngineImageGarbageCollectionKeepCount,
+		SettingNameAutoFilesystemTrim:                                       SettingDefinitionAutoFilesystemTrim,
+		SettingNameAutoFilesystemTrimInterval:                               SettingDefinitionAutoFilesystemTrimInterval,
+		SettingNameAutoFilesystemTrimDeletedSpaceThreshold:                  SettingDefinitionAutoFilesystemTrimDeletedSpaceThreshold,
+		SettingNameConcurrentAutoFilesystemTrimPerNodeLimit:                 SettingDefinitionConcurrentAutoFilesystemTrimPerNodeLimit,
+		SettingNameNodeDownStaleAttachmentCleanup:                           SettingDefinitionNodeDownStaleAttachmentCleanup,
+		SettingNameInstanceManagerCPUPerInstance:                            SettingDefinitionInstanceManagerCPUPerInstance,
+		SettingNameInstanceManagerMemoryPerInstance:                         SettingDefinitionInstanceManagerMemoryPerInstance,
+		SettingNameSystemManagedComponentsSchedulingOverrides:               SettingDefinitionSystemManagedComponentsSchedulingOverrides,
+		SettingNameVolumeReconciliationSharding:                             SettingDefinitionVolumeReconciliationSharding,
+		SettingNameControllerWorkers:                                        SettingDefinitionControllerWorkers,
+		SettingNameOrphanInstanceAutoDeletionGracePeriod:                    SettingDefinitionOrphanInstanceAutoDeletionGracePeriod,
+		SettingNameOrphanBackingImageAutoDeletion:                           SettingDefinitionOrphanBackingImageAutoDeletion,
+		SettingNameEngineRebindTimeout:                                      SettingDefinitionEngineRebindTimeout,
+		SettingNameCapacityRebalance:                                        SettingDefinitionCapacityRebalance,
+		SettingNameCapacityRebalanceInterval:                                SettingDefinitionCapacityRebalanceInterval,
+		SettingNameCapacityRebalanceUtilizationGap:                          SettingDefinitionCapacityRebalanceUtilizationGap,
+		SettingNameConcurrentCapacityRebalancePerClusterLimit:               SettingDefinitionConcurrentCapacityRebalancePerClusterLimit,
+		SettingNameNodeFlappingThreshold:                                    SettingDefinitionNodeFlappingThreshold,
+		SettingNameNodeFlappingDetectionWindow:                              SettingDefinitionNodeFlappingDetectionWindow,
+		SettingNameNodeFlappingSchedulingCooldown:                           SettingDefinitionNodeFlappingSchedulingCooldown,
+		SettingNameVolumeImportAllowedSourceDirectories:                     SettingDefinitionVolumeImportAllowedSourceDirectories,
 	}
 
 	SettingDefinitionAllowRecurringJobWhileVolumeDetached = SettingDefinition{
@@ -540,6 +675,37 @@ var (
 		Default:  "90",
 	}
 
+	SettingDefinitionReplicaAutoBalanceDiskIOLatencyThreshold = SettingDefinition{
+		DisplayName: "Replica Auto Balance Disk IO Latency Threshold (ms)",
+		Description: "Average IO latency in milliseconds of a disk that triggers automatic replica rebalancing.\n\n" +
+			"When the average latency of the reads and writes observed on a disk during the last disk monitoring period reaches the threshold, Longhorn treats the disk as under pressure and rebuilds its replicas on another disk within the same node, the same way as for the **Replica Auto Balance Disk Pressure Threshold (%)** setting.\n\n" +
+			"To disable this feature, set the value to 0.\n\n" +
+			"**Note:** The IO latency is only collected for filesystem-type disks. This setting takes effect only when **Replica Auto Balance** is set to **best-effort**.",
+		Category: SettingCategoryScheduling,
+		Type:     SettingTypeInt,
+		Required: true,
+		ReadOnly: false,
+		Default:  "0",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+		},
+	}
+
+	SettingDefinitionReplicaAutoBalanceDiskPressureInterval = SettingDefinition{
+		DisplayName: "Replica Auto Balance Disk Pressure Interval",
+		Description: "Minimum interval in seconds between two replicas being moved off the same disk under space or IO latency pressure.\n\n" +
+			"The interval starts when the move is admitted, and a disk isn't rebalanced again while the replica moved off it is still rebuilding. " +
+			"This limits the extra rebuilding traffic caused by the replica rebalancing. Set the value to 0 to move the next replica once the previous one is rebuilt.",
+		Category: SettingCategoryScheduling,
+		Type:     SettingTypeInt,
+		Required: true,
+		ReadOnly: false,
+		Default:  "300",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+		},
+	}
+
 	SettingDefinitionStorageOverProvisioningPercentage = SettingDefinition{
 		DisplayName: "Storage Over Provisioning Percentage",
 		Description: "The over-provisioning percentage defines how much storage can be allocated relative to the hard drive's capacity",
@@ -661,6 +827,7 @@ var (
 			"The available modes are: \n\n" +
 			"- **disabled**. This is the default option. There may or may not be a replica on the same node as the attached volume (workload)\n" +
 			"- **best-effort**. This option instructs Longhorn to try to keep a replica on the same node as the attached volume (workload). Longhorn will not stop the volume, even if it cannot keep a replica local to the attached volume (workload) due to environment limitation, e.g. not enough disk space, incompatible disk tags, etc.\n" +
+			"- **best-effort-load-aware**. This option is the same as **best-effort**, except that the replica is only moved to the node of the attached volume (workload) when the disk usage and the IO utilization of the node are below the thresholds of the settings \"Data Locality Load Aware Disk Usage Threshold\" and \"Data Locality Load Aware IO Utilization Threshold\". Otherwise, it's deferred until the node is less busy.\n" +
 			"- **strict-local**. This option enforces Longhorn keep the only one replica on the same node as the attached volume.\n",
 		Category: SettingCategoryGeneral,
 		Type:     SettingTypeString,
@@ -670,6 +837,7 @@ var (
 		Choices: []string{
 			string(longhorn.DataLocalityDisabled),
 			string(longhorn.DataLocalityBestEffort),
+			string(longhorn.DataLocalityBestEffortLoadAware),
 			string(longhorn.DataLocalityStrictLocal),
 		},
 	}
@@ -775,7 +943,7 @@ var (
 
 	SettingDefinitionReplicaZoneSoftAntiAffinity = SettingDefinition{
 		DisplayName: "Replica Zone Level Soft Anti-Affinity",
-		Description: "Allow scheduling new Replicas of Volume to the Nodes in the same Zone as existing healthy Replicas. Nodes don't belong to any Zone will be treated as in the same Zone. Notice that Longhorn relies on label `topology.kubernetes.io/zone=<Zone name of the node>` in the Kubernetes node object to identify the zone.",
+		Description: "Allow scheduling new Replicas of Volume to the Nodes in the same Zone as existing healthy Replicas. Nodes don't belong to any Zone will be treated as in the same Zone. Notice that Longhorn relies on the labels of the **Node Zone Label Keys** setting, by default `topology.kubernetes.io/zone=<Zone name of the node>`, in the Kubernetes node object to identify the zone.",
 		Category:    SettingCategoryScheduling,
 		Type:        SettingTypeBool,
 		Required:    true,
@@ -874,6 +1042,7 @@ var (
 		DisplayName: "Concurrent Replica Rebuild Per Node Limit",
 		Description: "This setting controls how many replicas on a node can be rebuilt simultaneously. \n\n" +
 			"Typically, Longhorn can block the replica starting once the current rebuilding count on a node exceeds the limit. But when the value is 0, it means disabling the replica rebuilding. \n\n" +
+			"The rebuilding count on a disk can be limited further by the field \"ConcurrentReplicaRebuildLimit\" of the disk. \n\n" +
 			"WARNING: \n\n" +
 			"  - The old setting \"Disable Replica Rebuild\" is replaced by this setting. \n\n" +
 			"  - Different from relying on replica starting delay to limit the concurrent rebuilding, if the rebuilding is disabled, replica object replenishment will be directly skipped. \n\n" +
@@ -888,6 +1057,28 @@ var (
 		},
 	}
 
+	SettingDefinitionReplicaRebuildPriorityPolicy = SettingDefinition{
+		DisplayName: "Replica Rebuild Priority Policy",
+		Description: "This setting decides which replicas on a node are rebuilt first when the rebuildings reach the concurrent replica rebuild per node limit.\n\n" +
+			"The available options are: \n\n" +
+			"- **queue-order**. This is the default option. The replicas are rebuilt in the order they are processed.\n" +
+			"- **volume-priority**. The replicas of the volumes with a higher rebuild priority in the volume spec are rebuilt first.\n" +
+			"- **attached-first**. The replicas of the attached volumes are rebuilt before the ones of the detached volumes.\n" +
+			"- **io-activity**. The replicas of the volumes with the highest IOPS are rebuilt first.\n\n" +
+			"The volumes with the same priority under the policy are rebuilt in the order they are processed.",
+		Category: SettingCategoryDangerZone,
+		Type:     SettingTypeString,
+		Required: true,
+		ReadOnly: false,
+		Default:  string(ReplicaRebuildPriorityPolicyQueueOrder),
+		Choices: []string{
+			string(ReplicaRebuildPriorityPolicyQueueOrder),
+			string(ReplicaRebuildPriorityPolicyVolumePriority),
+			string(ReplicaRebuildPriorityPolicyAttachedFirst),
+			string(ReplicaRebuildPriorityPolicyIOActivity),
+		},
+	}
+
 	SettingDefinitionConcurrentBackingImageCopyReplenishPerNodeLimit = SettingDefinition{
 		DisplayName: "Concurrent Backing Image Replenish Per Node Limit",
 		Description: "This setting controls how many backing images copy on a node can be replenished simultaneously. \n\n" +
@@ -1049,7 +1240,8 @@ var (
 	SettingDefinitionOrphanAutoDeletion = SettingDefinition{
 		DisplayName: "Orphan Auto-Deletion",
 		Description: "This setting allows Longhorn to delete the orphan resource and its corresponding orphaned data automatically. \n\n" +
-			"Orphan resources on down or unknown nodes will not be cleaned up automatically. \n\n",
+			"Orphan resources on down or unknown nodes will not be cleaned up automatically. \n\n" +
+			"The orphaned engine and replica instances are cleaned up after the setting **Orphan Instance Auto-Deletion Grace Period**. \n\n",
 		Category: SettingCategoryOrphan,
 		Type:     SettingTypeBool,
 		Required: true,
@@ -1085,6 +1277,28 @@ var (
 		Default:  "false",
 	}
 
+	SettingDefinitionIPFamily = SettingDefinition{
+		DisplayName: "IP Family",
+		Description: "This setting decides which IP family Longhorn uses for the addresses of the instance managers, backing image managers, backing image data sources and share managers on a dual-stack cluster, and which unspecified address their servers listen on.\n\n" +
+			"The available options are: \n\n" +
+			"- **auto**. This is the default option. Longhorn uses the primary IP of the pods, and listens on the IP family of the primary IP of the Longhorn manager pod.\n" +
+			"- **ipv4**. Longhorn uses the IPv4 address of the pods.\n" +
+			"- **ipv6**. Longhorn uses the IPv6 address of the pods.\n\n" +
+			"If a pod does not have an IP of the chosen family, Longhorn falls back to its primary IP.\n\n" +
+			"WARNING: \n\n" +
+			"  - This setting should change after all Longhorn volumes are detached. The new listen address applies to the Longhorn component pods created after the change.",
+		Category: SettingCategoryDangerZone,
+		Type:     SettingTypeString,
+		Required: true,
+		ReadOnly: false,
+		Default:  string(IPFamilyAuto),
+		Choices: []string{
+			string(IPFamilyAuto),
+			string(IPFamilyIPv4),
+			string(IPFamilyIPv6),
+		},
+	}
+
 	SettingDefinitionRecurringSuccessfulJobsHistoryLimit = SettingDefinition{
 		DisplayName: "Cronjob Successful Jobs History Limit",
 		Description: "This setting specifies how many successful backup or snapshot job histories should be retained. \n\n" +
@@ -1514,6 +1728,555 @@ var (
 		ReadOnly:    false,
 		Default:     "false",
 	}
+
+	SettingDefinitionVolumeWarmPool = SettingDefinition{
+		DisplayName: "Volume Warm Pool",
+		Description: "Pre-create detached volumes so that the CSI CreateVolume call can adopt a pooled volume instead of provisioning a new one. " +
+			"The value is a semicolon separated list of `<storage class name>:<size>:<count>` entries, for example `longhorn:10Gi:5;longhorn-fast:1Gi:20`. \n\n" +
+			"A pooled volume is created with the parameters of the referenced StorageClass and is only adopted by a new PVC of the same StorageClass parameters and size. " +
+			"The volumes created by the Longhorn UI or API never adopt a pooled volume. \n\n" +
+			"An adopted volume keeps its `pool-` prefixed name, so the Longhorn volume name and the CSI volume handle of the PV differ from the PV name. \n\n" +
+			"Leave it empty to disable the warm pool. Unclaimed pooled volumes that are no longer configured will be deleted.",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeString,
+		Required: false,
+		ReadOnly: false,
+		Default:  "",
+	}
+
+	SettingDefinitionBlockMountOnFilesystemResizeFailure = SettingDefinition{
+		DisplayName: "Block Mount On Filesystem Resize Failure",
+		Description: "If enabled, the mount of a volume fails when its filesystem is smaller than the volume and cannot be resized online or offline. " +
+			"If disabled, the smaller filesystem is mounted. The volume condition FilesystemResizeFailed is set in both cases.",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeBool,
+		Required: true,
+		ReadOnly: false,
+		Default:  "true",
+	}
+
+	SettingDefinitionCSIMaxVolumesPerNode = SettingDefinition{
+		DisplayName: "CSI Max Volumes Per Node",
+		Description: "The maximum number of Longhorn volumes the Kubernetes scheduler places on a node. 0 means no limit. \n\n" +
+			"The limit of a node can be overridden by the Kubernetes node annotation \"node.longhorn.io/csi-max-volumes-per-node\". \n\n" +
+			"WARNING: The limit is reported to Kubernetes when the Longhorn CSI plugin is registered on the node, so the Longhorn CSI plugin pods need to be restarted for a change to take effect.",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeInt,
+		Required: true,
+		ReadOnly: false,
+		Default:  "0",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+		},
+	}
+
+	SettingDefinitionSafetySnapshotPolicy = SettingDefinition{
+		DisplayName: "Safety Snapshot Policy",
+		Description: "This setting allows Longhorn to automatically take a snapshot of an attached volume right before a destructive operation, which are snapshot revert, volume expansion and engine upgrade. The operation fails if the snapshot cannot be taken. \n\n" +
+			"Available options are: \n\n" +
+			"- **disabled**: No safety snapshot is taken. \n\n" +
+			"- **snapshot**: A safety snapshot is taken. \n\n" +
+			"- **snapshot-and-backup**: A safety snapshot is taken and backed up to the backup target of the volume. The backup is kept until it's deleted by the user. \n\n" +
+			"Safety snapshots are labeled with \"longhorn.io/safety-snapshot\" and deleted after the safety snapshot retention period.",
+		Category: SettingCategorySnapshot,
+		Type:     SettingTypeString,
+		Required: true,
+		ReadOnly: false,
+		Default:  string(SafetySnapshotPolicyDisabled),
+		Choices: []string{
+			string(SafetySnapshotPolicyDisabled),
+			string(SafetySnapshotPolicySnapshot),
+			string(SafetySnapshotPolicySnapshotAndBackup),
+		},
+	}
+
+	SettingDefinitionSafetySnapshotRetentionPeriod = SettingDefinition{
+		DisplayName: "Safety Snapshot Retention Period",
+		Description: "The number of hours a safety snapshot taken before a destructive operation is kept. 0 means safety snapshots are kept until they're deleted by the user.",
+		Category:    SettingCategorySnapshot,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "24",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+		},
+	}
+
+	SettingDefinitionManagerHandoffGracePeriod = SettingDefinition{
+		DisplayName: "Manager Handoff Grace Period",
+		Description: "In seconds. The time the Longhorn managers on the other nodes wait for a restarting Longhorn manager pod, e.g. during a rolling upgrade, before taking over the ownership of the Longhorn resources of its node. " +
+			"A Longhorn manager pod shutting down gracefully hands off its node, so the new Longhorn manager pod of the node resumes the reconciliation of the resources and adopts the in-progress operations instead of the resources moving to other nodes and back. \n\n" +
+			"0 means the ownership is taken over as soon as the Longhorn manager pod is missing.",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeInt,
+		Required: true,
+		ReadOnly: false,
+		Default:  "60",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+		},
+	}
+
+	SettingDefinitionBackupTargetUnavailablePolicy = SettingDefinition{
+		DisplayName: "Backup Target Unavailable Policy",
+		Description: "Defines the Longhorn action when the backup target becomes unreachable during a backup or a restore.\n" +
+			"- **fail-fast** Longhorn fails the backup or the restore immediately.\n" +
+			"- **pause-and-resume** Longhorn pauses the backup or the restore and resumes it once the backup target is reachable again. The operation fails if the backup target is still unreachable after the **Backup Target Unavailable Timeout**.\n" +
+			"- **restore-pending** Longhorn fails the backups immediately, but holds the restoring volumes, including the DR volumes, in the restore pending state until the backup target is reachable again, instead of failing their replicas.\n",
+		Category: SettingCategoryBackup,
+		Type:     SettingTypeString,
+		Required: true,
+		ReadOnly: false,
+		Default:  string(BackupTargetUnavailablePolicyFailFast),
+		Choices: []string{
+			string(BackupTargetUnavailablePolicyFailFast),
+			string(BackupTargetUnavailablePolicyPauseAndResume),
+			string(BackupTargetUnavailablePolicyRestorePending),
+		},
+	}
+
+	SettingDefinitionBackupTargetUnavailableTimeout = SettingDefinition{
+		DisplayName: "Backup Target Unavailable Timeout",
+		Description: "Number of minutes Longhorn pauses a backup or a restore while the backup target is unreachable, when the **Backup Target Unavailable Policy** is **pause-and-resume**. The operation fails once the timeout elapses.",
+		Category:    SettingCategoryBackup,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "30",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 1,
+		},
+	}
+
+	SettingDefinitionFIPSModeEnabled = SettingDefinition{
+		DisplayName: "FIPS Mode Enabled",
+		Description: "If enabled, Longhorn restricts its cryptographic operations to the algorithms approved by FIPS 140-3. \n\n" +
+			"  - New encrypted volumes must be formatted with an approved cipher, hash, key size and PBKDF. Since the default PBKDF argon2i is not approved, CRYPTO_PBKDF in the encryption secret must be set to pbkdf2. \n\n" +
+			"  - The existing encrypted volumes with the parameters not approved are still attached, and get the volume condition FIPSNonCompliant. \n\n" +
+			"  - The webhook servers only negotiate TLS 1.2 or later with the approved cipher suites and curves. It takes effect after the Longhorn manager pods are restarted. \n\n" +
+			"Build Longhorn with FIPS=true to use the FIPS 140-3 Go cryptographic module.",
+		Category: SettingCategoryDangerZone,
+		Type:     SettingTypeBool,
+		Required: true,
+		ReadOnly: false,
+		Default:  "false",
+	}
+
+	SettingDefinitionNodeZoneLabelKeys = SettingDefinition{
+		DisplayName: "Node Zone Label Keys",
+		Description: "The keys of the Kubernetes node labels the zone of the Longhorn node is synchronized from, separated by semicolon. The first label found on the node is used, for example `topology.kubernetes.io/zone;failure-domain.beta.kubernetes.io/zone`. \n\n" +
+			"The annotation `" + KubeNodeZoneOverrideAnnotationKey + "` of the Kubernetes node takes precedence over the labels.",
+		Category: SettingCategoryScheduling,
+		Type:     SettingTypeString,
+		Required: false,
+		ReadOnly: false,
+		Default:  KubernetesTopologyZoneLabelKey,
+	}
+
+	SettingDefinitionNodeRegionLabelKeys = SettingDefinition{
+		DisplayName: "Node Region Label Keys",
+		Description: "The keys of the Kubernetes node labels the region of the Longhorn node is synchronized from, separated by semicolon. The first label found on the node is used, for example `topology.kubernetes.io/region;failure-domain.beta.kubernetes.io/region`. \n\n" +
+			"The annotation `" + KubeNodeRegionOverrideAnnotationKey + "` of the Kubernetes node takes precedence over the labels.",
+		Category: SettingCategoryScheduling,
+		Type:     SettingTypeString,
+		Required: false,
+		ReadOnly: false,
+		Default:  KubernetesTopologyRegionLabelKey,
+	}
+
+	SettingDefinitionNodeTagLabels = SettingDefinition{
+		DisplayName: "Node Tag Labels",
+		Description: "The Kubernetes node labels the tags of the Longhorn node are synchronized from, separated by semicolon. " +
+			"An entry `<label key>` adds the value of the label as a tag, and an entry `<label key>=<tag>` adds the tag if the label exists, for example `node.kubernetes.io/instance-type;example.com/nvme=fast`. \n\n" +
+			"The tags added manually are kept. The synchronized tags are removed once their label is removed, and added back if they are removed manually while their label exists.",
+		Category: SettingCategoryScheduling,
+		Type:     SettingTypeString,
+		Required: false,
+		ReadOnly: false,
+		Default:  "",
+	}
+
+	SettingDefinitionRestoreReplicaPlacementCheck = SettingDefinition{
+		DisplayName: "Restore Replica Placement Check",
+		Description: "If enabled, Longhorn checks that the replicas of the volumes can be scheduled with the current disk capacity, the node and disk selectors and the replica anti-affinity before they are restored from backup, activated from disaster recovery, or restored by a system restore. \n\n" +
+			"  - The restore from backup and the activation are rejected with the reasons the replicas cannot be scheduled. \n\n" +
+			"  - The system restore skips the volumes whose replicas cannot be scheduled, and reports them in its error. The volumes are checked one after the other, so the storage taken by a volume is not available to the following ones.",
+		Category: SettingCategoryBackup,
+		Type:     SettingTypeBool,
+		Required: true,
+		ReadOnly: false,
+		Default:  "false",
+	}
+
+	SettingDefinitionConcurrentReplicaScrubPerClusterLimit = SettingDefinition{
+		DisplayName: "Concurrent Replica Scrub Per Cluster Limit",
+		Description: "This setting controls how many volumes in the cluster can be scrubbed simultaneously by the periodic snapshot data integrity check, which recalculates the checksums of the snapshots on all healthy replicas of a volume and rebuilds the divergent replicas.",
+		Category:    SettingCategorySnapshot,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "1",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 1,
+		},
+	}
+
+	SettingDefinitionDataLocalityLoadAwareDiskUsageThreshold = SettingDefinition{
+		DisplayName: "Data Locality Load Aware Disk Usage Threshold",
+		Description: "In percentage. The local replica of a volume using the data locality mode **best-effort-load-aware** is only created when the node of the attached volume has a schedulable disk used less than this threshold.",
+		Category:    SettingCategoryScheduling,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "80",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+			ValueIntRangeMaximum: 100,
+		},
+	}
+
+	SettingDefinitionDataLocalityLoadAwareIOUtilizationThreshold = SettingDefinition{
+		DisplayName: "Data Locality Load Aware IO Utilization Threshold",
+		Description: "In percentage. The local replica of a volume using the data locality mode **best-effort-load-aware** is only created when the node of the attached volume has a schedulable disk whose device was busy less than this threshold of the time during the last disk monitoring period.",
+		Category:    SettingCategoryScheduling,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "50",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+			ValueIntRangeMaximum: 100,
+		},
+	}
+
+	SettingDefinitionEngineImageGarbageCollection = SettingDefinition{
+		DisplayName: "Engine Image Garbage Collection",
+		Description: "If enabled, Longhorn deletes the engine images and their daemonsets once they are not used by any volume, engine or replica for the time set by \"Engine Image Garbage Collection Wait Interval\". " +
+			"The default engine image and the most recently used engine images counted by \"Engine Image Garbage Collection Keep Count\" are never deleted.",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeBool,
+		Required: true,
+		ReadOnly: false,
+		Default:  "false",
+	}
+
+	SettingDefinitionEngineImageGarbageCollectionWaitInterval = SettingDefinition{
+		DisplayName: "Engine Image Garbage Collection Wait Interval",
+		Description: "In minutes. The time an engine image has to be unused before it is deleted by the engine image garbage collection.",
+		Category:    SettingCategoryGeneral,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "60",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+		},
+	}
+
+	SettingDefinitionEngineImageGarbageCollectionKeepCount = SettingDefinition{
+		DisplayName: "Engine Image Garbage Collection Keep Count",
+		Description: "The number of the most recently used engine images, besides the default engine image, kept by the engine image garbage collection even if they are unused. " +
+			"The kept engine images allow rolling back an engine upgrade without pulling the image again.",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeInt,
+		Required: true,
+		ReadOnly: false,
+		Default:  "1",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 1,
+		},
+	}
+
+	SettingDefinitionAutoFilesystemTrim = SettingDefinition{
+		DisplayName: "Auto Filesystem Trim",
+		Description: "Periodically trim the filesystem of the attached volumes, independently of the recurring jobs, to reclaim the space of the deleted files. " +
+			"A volume is only trimmed if the snapshots are removed during the filesystem trim for it, see setting **Remove Snapshots During Filesystem Trim**, " +
+			"and the space of the snapshots marked as removed exceeds the setting **Auto Filesystem Trim Deleted Space Threshold**.",
+		Category: SettingCategorySnapshot,
+		Type:     SettingTypeBool,
+		Required: true,
+		ReadOnly: false,
+		Default:  "false",
+	}
+
+	SettingDefinitionAutoFilesystemTrimInterval = SettingDefinition{
+		DisplayName: "Auto Filesystem Trim Interval",
+		Description: "In minutes. The minimum interval between two automatic filesystem trims of a volume.",
+		Category:    SettingCategorySnapshot,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "1440",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 1,
+		},
+	}
+
+	SettingDefinitionAutoFilesystemTrimDeletedSpaceThreshold = SettingDefinition{
+		DisplayName: "Auto Filesystem Trim Deleted Space Threshold",
+		Description: "In MiB. The filesystem of a volume is automatically trimmed only if the snapshots marked as removed take more space than the threshold.",
+		Category:    SettingCategorySnapshot,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "1024",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+		},
+	}
+
+	SettingDefinitionConcurrentAutoFilesystemTrimPerNodeLimit = SettingDefinition{
+		DisplayName: "Concurrent Auto Filesystem Trim Per Node Limit",
+		Description: "The maximum number of the volumes attached to a node whose filesystems are automatically trimmed at the same time.",
+		Category:    SettingCategorySnapshot,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "1",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 1,
+		},
+	}
+
+	SettingDefinitionNodeDownStaleAttachmentCleanup = SettingDefinition{
+		DisplayName: "Stale Attachment Cleanup When Node is Down",
+		Description: "If enabled, once the pods using an RWO volume are gone from a node that is down and a replacement pod is scheduled to another node, " +
+			"Longhorn deletes the stale attachments of the volume to the node that is down, so that the volume is attached to the new node without waiting for Kubernetes to time out the detachment. " +
+			"It's usually combined with the setting **Pod Deletion Policy When Node is Down**, or with the pods being force deleted manually.",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeBool,
+		Required: true,
+		ReadOnly: false,
+		Default:  "false",
+	}
+
+	SettingDefinitionInstanceManagerCPUPerInstance = SettingDefinition{
+		DisplayName: "Instance Manager CPU Per Instance",
+		Description: "In millicpu. If set, the CPU request of each instance manager pod is scaled with the number of the engine and replica processes running in it, " +
+			"on top of the request set by the setting **Guaranteed Instance Manager CPU** or the node. " +
+			"The pods are resized in place, which needs the in-place pod resize support of Kubernetes. 0 means disabled.",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeInt,
+		Required: true,
+		ReadOnly: false,
+		Default:  "0",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+		},
+	}
+
+	SettingDefinitionInstanceManagerMemoryPerInstance = SettingDefinition{
+		DisplayName: "Instance Manager Memory Per Instance",
+		Description: "In MiB. If set, the memory request of each instance manager pod is scaled with the number of the engine and replica processes running in it, " +
+			"on top of the request set by the node. " +
+			"The pods are resized in place, which needs the in-place pod resize support of Kubernetes. 0 means disabled.",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeInt,
+		Required: true,
+		ReadOnly: false,
+		Default:  "0",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+		},
+	}
+
+	SettingDefinitionSystemManagedComponentsSchedulingOverrides = SettingDefinition{
+		DisplayName: "System Managed Components Scheduling Overrides",
+		Description: "The Priority Class, the taint tolerations and the topology spread constraints of a group of the system managed components, " +
+			"overriding the settings **Priority Class** and **Kubernetes Taint Toleration** for the group. " +
+			"The groups are `instance-manager`, `share-manager`, `backing-image` and `csi`. " +
+			"The taint tolerations are in the same format as the setting **Kubernetes Taint Toleration**. " +
+			"The topology spread constraints are applied to the share manager pods and the CSI deployments only, since the other components run on specific nodes. " +
+			"All Longhorn volumes should be detached before modifying this setting. For example: \n\n" +
+			"* `{\"instance-manager\": {\"priorityClass\": \"storage-critical\", \"taintToleration\": \"storage=true:NoSchedule\"}, " +
+			"\"csi\": {\"topologySpreadConstraints\": [{\"maxSkew\": 1, \"topologyKey\": \"topology.kubernetes.io/zone\", \"whenUnsatisfiable\": \"ScheduleAnyway\"}]}}`",
+		Category: SettingCategoryDangerZone,
+		Type:     SettingTypeString,
+		Required: false,
+		ReadOnly: false,
+	}
+
+	SettingDefinitionVolumeReconciliationSharding = SettingDefinition{
+		DisplayName: "Volume Reconciliation Sharding",
+		Description: "If enabled, the detached volumes are reconciled by the Longhorn managers picked by consistent hashing of the volume names " +
+			"over the nodes with a running Longhorn manager, instead of by whichever manager takes the detached volume over first. " +
+			"When a manager joins or leaves, only the detached volumes picked by it are moved. " +
+			"The attached volumes are always reconciled by the manager of the node they are attached to.",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeBool,
+		Required: true,
+		ReadOnly: false,
+		Default:  "false",
+	}
+
+	SettingDefinitionOrphanInstanceAutoDeletionGracePeriod = SettingDefinition{
+		DisplayName: "Orphan Instance Auto-Deletion Grace Period",
+		Description: "In seconds. The time an orphaned engine or replica instance, which is running in an instance manager without a corresponding engine or replica, " +
+			"is kept before Longhorn deletes it automatically when the setting **Orphan Auto-Deletion** is enabled. " +
+			"The orphan resource is removed without touching the instance if the engine or replica shows up again within the grace period.",
+		Category: SettingCategoryOrphan,
+		Type:     SettingTypeInt,
+		Required: true,
+		ReadOnly: false,
+		Default:  "300",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+		},
+	}
+
+	SettingDefinitionOrphanBackingImageAutoDeletion = SettingDefinition{
+		DisplayName: "Orphan Backing Image Auto-Deletion",
+		Description: "This setting allows Longhorn to delete the orphan resource and its corresponding orphaned backing image files automatically. \n\n" +
+			"The backing image files on a disk are orphaned when the backing image is gone or no longer uses the disk. \n\n" +
+			"Orphan resources on down or unknown nodes will not be cleaned up automatically. \n\n",
+		Category: SettingCategoryOrphan,
+		Type:     SettingTypeBool,
+		Required: true,
+		ReadOnly: false,
+		Default:  "false",
+	}
+
+	SettingDefinitionEngineRebindTimeout = SettingDefinition{
+		DisplayName: "Engine Rebind Timeout",
+		Description: "In seconds. The time Longhorn waits for a restarted instance manager to run again, so the engine lost with the restart is started again against the same replicas while the volume stays attached. " +
+			"The volume is detached and reattached if the instance manager doesn't run again in time or no healthy replica is left on the other nodes. " +
+			"Since the restarted engine publishes a new frontend device, the workload pods using the volume are still restarted to remount it, the same as after the volume is reattached. " +
+			"The rebinding only saves the detachment and reattachment of the volume, and it doesn't keep the workloads running. " +
+			"Setting it to 0 disables the rebinding, and the volume is always detached and reattached.",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeInt,
+		Required: true,
+		ReadOnly: false,
+		Default:  "0",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+		},
+	}
+
+	SettingDefinitionControllerWorkers = SettingDefinition{
+		DisplayName: "Controller Workers",
+		Description: "The number of the workers reconciling the objects concurrently for each Longhorn manager controller. " +
+			"The controllers are named as the `name` label of the `longhorn_workqueue_*` metrics, and the controllers not in the list use 5 workers. " +
+			"The change takes effect after the Longhorn manager pods restart. For example: `longhorn-volume:10;longhorn-engine:10;longhorn-replica:10`",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeString,
+		Required: false,
+		ReadOnly: false,
+	}
+
+	SettingDefinitionCapacityRebalance = SettingDefinition{
+		DisplayName: "Capacity Rebalance",
+		Description: "Periodically move the replicas of the attached healthy volumes from the most utilized disks to the least utilized ones, to lower the maximum scheduled storage utilization of the disks in the cluster.\n\n" +
+			"The available options are: \n\n" +
+			"- **disabled**. This is the default option. No replica is moved.\n" +
+			"- **dry-run**. The planned moves are only reported in the `CapacityRebalancePlanned` condition of this setting, without moving any replica.\n" +
+			"- **enabled**. The planned moves are executed, up to the **Concurrent Capacity Rebalance Per Cluster Limit** at a time. " +
+			"A replica is moved by rebuilding a new replica on the target disk first, then removing the replica on the source disk.",
+		Category: SettingCategoryScheduling,
+		Type:     SettingTypeString,
+		Required: true,
+		ReadOnly: false,
+		Default:  string(CapacityRebalanceModeDisabled),
+		Choices: []string{
+			string(CapacityRebalanceModeDisabled),
+			string(CapacityRebalanceModeDryRun),
+			string(CapacityRebalanceModeEnabled),
+		},
+	}
+
+	SettingDefinitionCapacityRebalanceInterval = SettingDefinition{
+		DisplayName: "Capacity Rebalance Interval",
+		Description: "In minutes. The minimum interval between two rounds of the capacity rebalance starting replica moves.",
+		Category:    SettingCategoryScheduling,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "60",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 1,
+		},
+	}
+
+	SettingDefinitionCapacityRebalanceUtilizationGap = SettingDefinition{
+		DisplayName: "Capacity Rebalance Utilization Gap (%)",
+		Description: "The capacity rebalance only moves a replica when the scheduled storage utilization of the most utilized disk exceeds the one of the least utilized schedulable disk by this percentage.",
+		Category:    SettingCategoryScheduling,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "20",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 1,
+			ValueIntRangeMaximum: 100,
+		},
+	}
+
+	SettingDefinitionConcurrentCapacityRebalancePerClusterLimit = SettingDefinition{
+		DisplayName: "Concurrent Capacity Rebalance Per Cluster Limit",
+		Description: "The maximum number of the replicas moved by the capacity rebalance at the same time in the cluster.",
+		Category:    SettingCategoryScheduling,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "1",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 1,
+		},
+	}
+
+	SettingDefinitionNodeFlappingThreshold = SettingDefinition{
+		DisplayName: "Node Flapping Threshold",
+		Description: "The number of the transitions of the Kubernetes node between Ready and NotReady within the **Node Flapping Detection Window**, at which the node is considered flapping. " +
+			"Replica scheduling is disabled on a flapping node for the **Node Flapping Scheduling Cooldown**.",
+		Category: SettingCategoryScheduling,
+		Type:     SettingTypeInt,
+		Required: true,
+		ReadOnly: false,
+		Default:  "4",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 2,
+		},
+	}
+
+	SettingDefinitionNodeFlappingDetectionWindow = SettingDefinition{
+		DisplayName: "Node Flapping Detection Window",
+		Description: "In minutes. The period in which the transitions of the Kubernetes node between Ready and NotReady are counted to detect a flapping node.",
+		Category:    SettingCategoryScheduling,
+		Type:        SettingTypeInt,
+		Required:    true,
+		ReadOnly:    false,
+		Default:     "10",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 1,
+		},
+	}
+
+	SettingDefinitionNodeFlappingSchedulingCooldown = SettingDefinition{
+		DisplayName: "Node Flapping Scheduling Cooldown",
+		Description: "In minutes. The period Longhorn temporarily disables replica scheduling on a flapping node, which stops replicas from being repeatedly scheduled onto and failed off an unstable node. " +
+			"The period restarts if the node keeps flapping, and scheduling is re-enabled automatically afterwards. " +
+			"Set the value to 0 to disable the automatic scheduling cooldown.",
+		Category: SettingCategoryScheduling,
+		Type:     SettingTypeInt,
+		Required: true,
+		ReadOnly: false,
+		Default:  "30",
+		ValueIntRange: map[string]int{
+			ValueIntRangeMinimum: 0,
+		},
+	}
+
+	SettingDefinitionVolumeImportAllowedSourceDirectories = SettingDefinition{
+		DisplayName: "Volume Import Allowed Source Directories",
+		Description: "The host directories, separated by semicolon, the StorageClass parameter `importSource` is allowed to import a block device or a file image from, for example `/dev/disk/by-id;/var/lib/images`. \n\n" +
+			"The import source must be inside one of the directories. The volume import is disabled if the value is empty.",
+		Category: SettingCategoryGeneral,
+		Type:     SettingTypeString,
+		Required: false,
+		ReadOnly: false,
+		Default:  "",
+	}
 )
 
 type NodeDownPodDeletionPolicy string
@@ -1525,6 +2288,22 @@ const (
 	NodeDownPodDeletionPolicyDeleteBothStatefulsetAndDeploymentPod = NodeDownPodDeletionPolicy("delete-both-statefulset-and-deployment-pod")
 )
 
+type BackupTargetUnavailablePolicy string
+
+const (
+	BackupTargetUnavailablePolicyFailFast       = BackupTargetUnavailablePolicy("fail-fast")
+	BackupTargetUnavailablePolicyPauseAndResume = BackupTargetUnavailablePolicy("pause-and-resume")
+	BackupTargetUnavailablePolicyRestorePending = BackupTargetUnavailablePolicy("restore-pending")
+)
+
+type SafetySnapshotPolicy string
+
+const (
+	SafetySnapshotPolicyDisabled          = SafetySnapshotPolicy("disabled")
+	SafetySnapshotPolicySnapshot          = SafetySnapshotPolicy("snapshot")
+	SafetySnapshotPolicySnapshotAndBackup = SafetySnapshotPolicy("snapshot-and-backup")
+)
+
 type NodeDrainPolicy string
 
 const (
@@ -1535,6 +2314,31 @@ const (
 	NodeDrainPolicyAlwaysAllow                           = NodeDrainPolicy("always-allow")
 )
 
+type ReplicaRebuildPriorityPolicy string
+
+const (
+	ReplicaRebuildPriorityPolicyQueueOrder     = ReplicaRebuildPriorityPolicy("queue-order")
+	ReplicaRebuildPriorityPolicyVolumePriority = ReplicaRebuildPriorityPolicy("volume-priority")
+	ReplicaRebuildPriorityPolicyAttachedFirst  = ReplicaRebuildPriorityPolicy("attached-first")
+	ReplicaRebuildPriorityPolicyIOActivity     = ReplicaRebuildPriorityPolicy("io-activity")
+)
+
+type CapacityRebalanceMode string
+
+const (
+	CapacityRebalanceModeDisabled = CapacityRebalanceMode("disabled")
+	CapacityRebalanceModeDryRun   = CapacityRebalanceMode("dry-run")
+	CapacityRebalanceModeEnabled  = CapacityRebalanceMode("enabled")
+)
+
+type IPFamily string
+
+const (
+	IPFamilyAuto = IPFamily("auto")
+	IPFamilyIPv4 = IPFamily("ipv4")
+	IPFamilyIPv6 = IPFamily("ipv6")
+)
+
 type SystemManagedPodsImagePullPolicy string
 
 const (
@@ -1734,6 +2538,224 @@ func UnmarshalNodeSelector(nodeSelectorSetting string) (map[string]string, error
 	return nodeSelector, nil
 }
 
+// UnmarshalControllerWorkers parses the controller workers setting in the format of
+// `<controller>:<workers>;<controller>:<workers>`
+func UnmarshalControllerWorkers(controllerWorkersSetting string) (map[string]int, error) {
+	controllerWorkers := map[string]int{}
+
+	controllerWorkersSetting = strings.Trim(controllerWorkersSetting, " ")
+	if controllerWorkersSetting == "" {
+		return controllerWorkers, nil
+	}
+	for _, item := range strings.Split(controllerWorkersSetting, ";") {
+		parts := strings.Split(item, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid controller workers %v", item)
+		}
+		name := strings.Trim(parts[0], " ")
+		if name == "" {
+			return nil, fmt.Errorf("invalid controller workers %v: empty controller name", item)
+		}
+		workers, err := strconv.Atoi(strings.Trim(parts[1], " "))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid controller workers %v", item)
+		}
+		if workers < 1 {
+			return nil, fmt.Errorf("invalid controller workers %v: workers should be at least 1", item)
+		}
+		controllerWorkers[name] = workers
+	}
+	return controllerWorkers, nil
+}
+
+// SystemManagedComponent is a group of the system managed components, whose scheduling can be overridden by the
+// system managed components scheduling overrides setting
+type SystemManagedComponent string
+
+const (
+	SystemManagedComponentInstanceManager = SystemManagedComponent("instance-manager")
+	SystemManagedComponentShareManager    = SystemManagedComponent("share-manager")
+	SystemManagedComponentBackingImage    = SystemManagedComponent("backing-image")
+	SystemManagedComponentCSI             = SystemManagedComponent("csi")
+)
+
+// SystemManagedComponentScheduling overrides the scheduling of a group of the system managed components. The empty
+// fields follow the global settings.
+type SystemManagedComponentScheduling struct {
+	PriorityClass             string                            `json:"priorityClass,omitempty"`
+	TaintToleration           string                            `json:"taintToleration,omitempty"`
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+}
+
+// UnmarshalSystemManagedComponentsSchedulingOverrides parses the system managed components scheduling overrides
+// setting in the format of `{"<component>": {"priorityClass": "<name>", "taintToleration": "<tolerations>",
+// "topologySpreadConstraints": [...]}}`
+func UnmarshalSystemManagedComponentsSchedulingOverrides(overridesSetting string) (map[SystemManagedComponent]SystemManagedComponentScheduling, error) {
+	overrides := map[SystemManagedComponent]SystemManagedComponentScheduling{}
+
+	overridesSetting = strings.TrimSpace(overridesSetting)
+	if overridesSetting == "" {
+		return overrides, nil
+	}
+
+	if err := json.Unmarshal([]byte(overridesSetting), &overrides); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal system managed components scheduling overrides")
+	}
+	for component, scheduling := range overrides {
+		switch component {
+		case SystemManagedComponentInstanceManager, SystemManagedComponentShareManager, SystemManagedComponentBackingImage, SystemManagedComponentCSI:
+		default:
+			return nil, fmt.Errorf("invalid system managed component %v", component)
+		}
+		if _, err := UnmarshalTolerations(scheduling.TaintToleration); err != nil {
+			return nil, errors.Wrapf(err, "invalid taint toleration of system managed component %v", component)
+		}
+		for _, constraint := range scheduling.TopologySpreadConstraints {
+			if constraint.MaxSkew <= 0 || constraint.TopologyKey == "" {
+				return nil, fmt.Errorf("invalid topology spread constraint of system managed component %v: max skew and topology key are required", component)
+			}
+		}
+	}
+	return overrides, nil
+}
+
+// GetSystemManagedComponentByLabels returns the group of the system managed component by the labels of its pod,
+// deployment or daemon set. The CSI deployments and daemon set are the only system managed objects without the
+// component label. It returns empty for the components not in any group.
+func GetSystemManagedComponentByLabels(labels map[string]string) SystemManagedComponent {
+	component, ok := labels[GetLonghornLabelComponentKey()]
+	if !ok {
+		return SystemManagedComponentCSI
+	}
+	switch component {
+	case LonghornLabelInstanceManager:
+		return SystemManagedComponentInstanceManager
+	case LonghornLabelShareManager:
+		return SystemManagedComponentShareManager
+	case LonghornLabelBackingImageManager, LonghornLabelBackingImageDataSource:
+		return SystemManagedComponentBackingImage
+	}
+	return ""
+}
+
+// UnmarshalNodeLabelKeys parses the semicolon separated label keys of the node zone and region label keys settings
+func UnmarshalNodeLabelKeys(labelKeysSetting string) ([]string, error) {
+	keys := []string{}
+	for _, key := range strings.Split(labelKeysSetting, ";") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if errList := validation.IsQualifiedName(key); len(errList) > 0 {
+			return nil, fmt.Errorf("invalid label key %v: %v", key, errList[0])
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// UnmarshalNodeTagLabels parses the node tag labels setting in the format of `<label key>;<label key>=<tag>` into the
+// map of the label keys to their tags. The tag is empty if the value of the label is used as the tag.
+func UnmarshalNodeTagLabels(tagLabelsSetting string) (map[string]string, error) {
+	tagLabels := map[string]string{}
+	for _, entry := range strings.Split(tagLabelsSetting, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, tag, _ := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		tag = strings.TrimSpace(tag)
+		if errList := validation.IsQualifiedName(key); len(errList) > 0 {
+			return nil, fmt.Errorf("invalid label key %v: %v", key, errList[0])
+		}
+		if strings.Contains(entry, "=") {
+			if errList := validation.IsQualifiedName(tag); len(errList) > 0 {
+				return nil, fmt.Errorf("invalid tag %v of label %v: %v", tag, key, errList[0])
+			}
+		}
+		tagLabels[key] = tag
+	}
+	return tagLabels, nil
+}
+
+// UnmarshalVolumeImportAllowedSourceDirectories parses the semicolon separated absolute paths of the volume import
+// allowed source directories setting
+func UnmarshalVolumeImportAllowedSourceDirectories(directoriesSetting string) ([]string, error) {
+	directories := []string{}
+	for _, directory := range strings.Split(directoriesSetting, ";") {
+		directory = strings.TrimSpace(directory)
+		if directory == "" {
+			continue
+		}
+		if !filepath.IsAbs(directory) {
+			return nil, fmt.Errorf("directory %v is not an absolute path", directory)
+		}
+		directories = append(directories, filepath.Clean(directory))
+	}
+	return directories, nil
+}
+
+// VolumeWarmPoolEntry describes the number of pre-created volumes of a given size for a StorageClass
+type VolumeWarmPoolEntry struct {
+	StorageClassName string
+	Size             int64
+	Count            int
+}
+
+// UnmarshalVolumeWarmPool parses the volume warm pool setting in the format of
+// `<storage class name>:<size>:<count>;<storage class name>:<size>:<count>`
+func UnmarshalVolumeWarmPool(warmPoolSetting string) ([]VolumeWarmPoolEntry, error) {
+	entries := []VolumeWarmPoolEntry{}
+
+	warmPoolSetting = strings.ReplaceAll(warmPoolSetting, " ", "")
+	if warmPoolSetting == "" {
+		return entries, nil
+	}
+
+	existing := map[string]bool{}
+	for _, item := range strings.Split(warmPoolSetting, ";") {
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid warm pool entry %v: should be in the format of <storage class name>:<size>:<count>", item)
+		}
+		if parts[0] == "" {
+			return nil, fmt.Errorf("invalid warm pool entry %v: missing storage class name", item)
+		}
+		size, err := util.ConvertSize(parts[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid warm pool entry %v", item)
+		}
+		if size <= 0 {
+			return nil, fmt.Errorf("invalid warm pool entry %v: size should be positive", item)
+		}
+		count, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid warm pool entry %v", item)
+		}
+		if count < 0 {
+			return nil, fmt.Errorf("invalid warm pool entry %v: count should not be negative", item)
+		}
+
+		size = util.RoundUpSize(size)
+		key := fmt.Sprintf("%v:%v", parts[0], size)
+		if existing[key] {
+			return nil, fmt.Errorf("duplicate warm pool entry %v", item)
+		}
+		existing[key] = true
+
+		entries = append(entries, VolumeWarmPoolEntry{
+			StorageClassName: parts[0],
+			Size:             size,
+			Count:            count,
+		})
+	}
+	return entries, nil
+}
+
 // GetSettingDefinition gets the setting definition in `settingDefinitions` by the parameter `name`
 func GetSettingDefinition(name SettingName) (SettingDefinition, bool) {
 	settingDefinitionsLock.RLock()
@@ -1829,6 +2851,14 @@ func validateString(sName SettingName, definition SettingDefinition, value strin
 		if _, err := UnmarshalNodeSelector(value); err != nil {
 			return errors.Wrapf(err, "the value of %v is invalid", sName)
 		}
+	case SettingNameSystemManagedComponentsSchedulingOverrides:
+		if _, err := UnmarshalSystemManagedComponentsSchedulingOverrides(value); err != nil {
+			return errors.Wrapf(err, "the value of %v is invalid", sName)
+		}
+	case SettingNameControllerWorkers:
+		if _, err := UnmarshalControllerWorkers(value); err != nil {
+			return errors.Wrapf(err, "the value of %v is invalid", sName)
+		}
 
 	case SettingNameStorageNetwork:
 		if err := ValidateStorageNetwork(value); err != nil {
@@ -1839,6 +2869,26 @@ func validateString(sName SettingName, definition SettingDefinition, value strin
 		if err := ValidateV2DataEngineLogFlags(value); err != nil {
 			return errors.Wrapf(err, "failed to validate v2 data engine log flags %v", value)
 		}
+
+	case SettingNameVolumeWarmPool:
+		if _, err := UnmarshalVolumeWarmPool(value); err != nil {
+			return errors.Wrapf(err, "the value of %v is invalid", sName)
+		}
+
+	case SettingNameNodeZoneLabelKeys, SettingNameNodeRegionLabelKeys:
+		if _, err := UnmarshalNodeLabelKeys(value); err != nil {
+			return errors.Wrapf(err, "the value of %v is invalid", sName)
+		}
+
+	case SettingNameNodeTagLabels:
+		if _, err := UnmarshalNodeTagLabels(value); err != nil {
+			return errors.Wrapf(err, "the value of %v is invalid", sName)
+		}
+
+	case SettingNameVolumeImportAllowedSourceDirectories:
+		if _, err := UnmarshalVolumeImportAllowedSourceDirectories(value); err != nil {
+			return errors.Wrapf(err, "the value of %v is invalid", sName)
+		}
 	}
 
 	return nil
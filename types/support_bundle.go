@@ -1,6 +1,8 @@
 package types
 
 import (
+	"net"
+	"strconv"
 	"time"
 
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
@@ -13,12 +15,18 @@ const (
 	SupportBundleManagerLabelKey = "rancher/supportbundle"
 
 	SupportBundleURLPort        = 8080
-	SupportBundleURLStatusFmt   = "http://%s:%v/status"
-	SupportBundleURLDownloadFmt = "http://%s:%v/bundle"
+	SupportBundleURLStatusFmt   = "http://%s/status"
+	SupportBundleURLDownloadFmt = "http://%s/bundle"
 
 	SupportBundleDownloadTimeout = 24 * time.Hour
 )
 
+// GetSupportBundleManagerAddress returns the host:port of the support bundle
+// manager server on the given pod IP.
+func GetSupportBundleManagerAddress(ip string) string {
+	return net.JoinHostPort(ip, strconv.Itoa(SupportBundleURLPort))
+}
+
 func IsSupportBundleControllerDeleting(supportBundle *longhorn.SupportBundle) bool {
 	switch supportBundle.Status.State {
 	case longhorn.SupportBundleStatePurging,
@@ -41,6 +41,8 @@ const (
 	LonghornKindBackingImage        = "BackingImage"
 	LonghornKindBackingImageManager = "BackingImageManager"
 	LonghornKindRecurringJob        = "RecurringJob"
+	LonghornKindMaintenancePolicy   = "MaintenancePolicy"
+	LonghornKindEngineUpgradePolicy = "EngineUpgradePolicy"
 	LonghornKindSetting             = "Setting"
 	LonghornKindSupportBundle       = "SupportBundle"
 	LonghornKindSystemBackup        = "SystemBackup"
@@ -49,12 +51,14 @@ const (
 
 	LonghornKindBackingImageDataSource = "BackingImageDataSource"
 
-	LonghornKindEngineImageList  = "EngineImageList"
-	LonghornKindRecurringJobList = "RecurringJobList"
-	LonghornKindSettingList      = "SettingList"
-	LonghornKindVolumeList       = "VolumeList"
-	LonghornKindBackingImageList = "BackingImageList"
-	LonghornKindBackupTargetList = "BackupTargetList"
+	LonghornKindEngineImageList         = "EngineImageList"
+	LonghornKindRecurringJobList        = "RecurringJobList"
+	LonghornKindMaintenancePolicyList   = "MaintenancePolicyList"
+	LonghornKindEngineUpgradePolicyList = "EngineUpgradePolicyList"
+	LonghornKindSettingList             = "SettingList"
+	LonghornKindVolumeList              = "VolumeList"
+	LonghornKindBackingImageList        = "BackingImageList"
+	LonghornKindBackupTargetList        = "BackupTargetList"
 
 	KubernetesKindClusterRole           = "ClusterRole"
 	KubernetesKindClusterRoleBinding    = "ClusterRoleBinding"
@@ -108,6 +112,11 @@ const (
 	UnixDomainSocketDirectoryInContainer = "/host/var/lib/longhorn/unix-domain-socket/"
 	UnixDomainSocketDirectoryOnHost      = "/var/lib/longhorn/unix-domain-socket/"
 
+	CSIMountJournalDirectoryOnHost = "/var/lib/longhorn/csi-mount-journal/"
+
+	WebIdentityTokenDirectoryInContainer = "/host/var/lib/longhorn/web-identity-tokens/"
+	WebIdentityTokenDirectoryOnHost      = "/var/lib/longhorn/web-identity-tokens/"
+
 	BackingImageManagerDirectory = "/backing-images/"
 	BackingImageFileName         = "backing"
 
@@ -129,6 +138,11 @@ const (
 	NodeDisableV2DataEngineLabelKeyTrue       = "true"
 	KubeNodeDefaultDiskConfigAnnotationKey    = "node.longhorn.io/default-disks-config"
 	KubeNodeDefaultNodeTagConfigAnnotationKey = "node.longhorn.io/default-node-tags"
+	KubeNodeZoneOverrideAnnotationKey         = "node.longhorn.io/zone"
+	KubeNodeRegionOverrideAnnotationKey       = "node.longhorn.io/region"
+	KubeNodeCSIMaxVolumesPerNodeAnnotationKey = "node.longhorn.io/csi-max-volumes-per-node"
+	KubeNodeDrainAnnotationKey                = "node.longhorn.io/drain"
+	KubeNodeDrainAnnotationValueTrue          = "true"
 
 	LastAppliedTolerationAnnotationKeySuffix = "last-applied-tolerations"
 
@@ -167,6 +181,7 @@ const (
 	LonghornLabelBackingImageManager        = "backing-image-manager"
 	LonghornLabelManagedBy                  = "managed-by"
 	LonghornLabelSnapshotForCloningVolume   = "for-cloning-volume"
+	LonghornLabelSafetySnapshot             = "safety-snapshot"
 	LonghornLabelBackingImageDataSource     = "backing-image-data-source"
 	LonghornLabelBackupTarget               = "backup-target"
 	LonghornLabelBackupVolume               = "backup-volume"
@@ -189,6 +204,20 @@ const (
 	LonghornLabelVersion                    = "version"
 	LonghornLabelAdmissionWebhook           = "admission-webhook"
 	LonghornLabelConversionWebhook          = "conversion-webhook"
+	LonghornLabelVolumeWarmPool             = "volume-warm-pool"
+	LonghornLabelVolumeWarmPoolClaimedBy    = "volume-warm-pool-claimed-by"
+	LonghornLabelRecurringBackupDisabled    = "recurring-backup-disabled"
+	LonghornLabelSoleReplicaDisallowed      = "sole-replica-disallowed"
+	LonghornLabelCrossDataEngineCloneSource = "cross-data-engine-clone-source"
+
+	LonghornAnnotationVolumeWarmPoolStorageClass = "volume-warm-pool-storage-class"
+	LonghornAnnotationManagerHandoffAt           = "manager-handoff-at"
+	LonghornAnnotationReplicaPlacementHint       = "replica-placement-hint"
+	LonghornAnnotationSyncedNodeTags             = "synced-node-tags"
+	LonghornAnnotationDrainEvictionRequested     = "drain-eviction-requested"
+	LonghornAnnotationCapacityRebalanceMove      = "capacity-rebalance-move"
+	LonghornAnnotationDiskPressureRebalanceFrom  = "disk-pressure-rebalance-from"
+	LonghornAnnotationDiskPressureRebalanceAt    = "disk-pressure-rebalance-at"
 
 	LonghornRecoveryBackendServiceName = "longhorn-recovery-backend"
 
@@ -220,6 +249,8 @@ const (
 	DefaultRecurringJobConcurrency = 10
 
 	PVAnnotationLonghornVolumeSchedulingError = "longhorn.io/volume-scheduling-error"
+	// PVCAnnotationLonghornForceUnmountTimeout overrides the forceUnmountTimeout parameter of the StorageClass
+	PVCAnnotationLonghornForceUnmountTimeout = "longhorn.io/force-unmount-timeout"
 
 	CniNetworkNone          = ""
 	StorageNetworkInterface = "lhnet1"
@@ -237,6 +268,8 @@ const (
 const (
 	RecurringJobParameterFullBackupInterval = "full-backup-interval"
 	RecurringJobParameterVolumeBackupPolicy = "volume-backup-policy"
+	RecurringJobParameterBackupTarget       = "backup-target"
+	RecurringJobParameterMaintenanceWindow  = "maintenance-window"
 )
 
 const (
@@ -255,12 +288,15 @@ const (
 	BackupStoreTypeNFS    = "nfs"
 	BackupStoreTypeAZBlob = "azblob"
 
-	AWSIAMRoleAnnotation = "iam.amazonaws.com/role"
-	AWSIAMRoleArn        = "AWS_IAM_ROLE_ARN"
-	AWSAccessKey         = "AWS_ACCESS_KEY_ID"
-	AWSSecretKey         = "AWS_SECRET_ACCESS_KEY"
-	AWSEndPoint          = "AWS_ENDPOINTS"
-	AWSCert              = "AWS_CERT"
+	AWSIAMRoleAnnotation    = "iam.amazonaws.com/role"
+	AWSIAMRoleArn           = "AWS_IAM_ROLE_ARN"
+	AWSAccessKey            = "AWS_ACCESS_KEY_ID"
+	AWSSecretKey            = "AWS_SECRET_ACCESS_KEY"
+	AWSEndPoint             = "AWS_ENDPOINTS"
+	AWSCert                 = "AWS_CERT"
+	AWSWebIdentityToken     = "AWS_WEB_IDENTITY_TOKEN"
+	AWSWebIdentityTokenFile = "AWS_WEB_IDENTITY_TOKEN_FILE"
+	AWSRoleArn              = "AWS_ROLE_ARN"
 
 	CIFSUsername = "CIFS_USERNAME"
 	CIFSPassword = "CIFS_PASSWORD"
@@ -300,6 +336,8 @@ const (
 	CryptoKeyHash     = "CRYPTO_KEY_HASH"
 	CryptoKeySize     = "CRYPTO_KEY_SIZE"
 	CryptoPBKDF       = "CRYPTO_PBKDF"
+	// CryptoPreviousKeyValue is the passphrase replaced by CryptoKeyValue during a passphrase rotation
+	CryptoPreviousKeyValue = "CRYPTO_PREVIOUS_KEY_VALUE"
 )
 
 // SettingsRelatedToVolume should match the items in datastore.GetLabelsForVolumesFollowsGlobalSettings
@@ -344,6 +382,8 @@ const (
 	instanceManagerPrefix = "instance-manager-"
 	engineManagerPrefix   = instanceManagerPrefix + "e-"
 	replicaManagerPrefix  = instanceManagerPrefix + "r-"
+
+	restoreCredentialPrefix = "restore-credential-"
 )
 
 func GenerateEngineNameForVolume(vName, currentEngineName string) string {
@@ -410,6 +450,15 @@ func GetBackingImageDirectoryName(backingImageName, backingImageUUID string) str
 	return fmt.Sprintf("%s-%s", backingImageName, backingImageUUID)
 }
 
+// GetBackingImageNameAndUUIDFromDirectoryName is the reverse of GetBackingImageDirectoryName.
+func GetBackingImageNameAndUUIDFromDirectoryName(directoryName string) (string, string, error) {
+	index := len(directoryName) - util.RandomIDLength - 1
+	if index <= 0 || directoryName[index] != '-' {
+		return "", "", fmt.Errorf("invalid backing image directory name %v", directoryName)
+	}
+	return directoryName[:index], directoryName[index+1:], nil
+}
+
 func GetBackingImageManagerDirectoryOnHost(diskPath string) string {
 	return filepath.Join(diskPath, BackingImageManagerDirectory)
 }
@@ -610,6 +659,24 @@ func GetVolumeLabels(volumeName string) map[string]string {
 	}
 }
 
+// GetVolumeWarmPoolSpecHash returns the hash used to match a pooled volume with a volume creation request.
+// The backup target is filled in by the manager on creation and is therefore excluded.
+func GetVolumeWarmPoolSpecHash(spec longhorn.VolumeSpec) string {
+	spec.BackupTargetName = ""
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+	return util.GetStringChecksumSHA256(string(specJSON))[:32]
+}
+
+// GetVolumeWarmPoolLabels returns the labels of an unclaimed pooled volume with the given spec
+func GetVolumeWarmPoolLabels(spec longhorn.VolumeSpec) map[string]string {
+	return map[string]string{
+		GetLonghornLabelKey(LonghornLabelVolumeWarmPool): GetVolumeWarmPoolSpecHash(spec),
+	}
+}
+
 func GetRecurringJobLabelKeyByType(name string, isGroup bool) string {
 	if isGroup {
 		return GetRecurringJobLabelKey(LonghornLabelRecurringJobGroup, name)
@@ -650,11 +717,20 @@ func IsRecurringJobSourceLabel(key string) bool {
 	return key == GetRecurringJobSourceLabelKey()
 }
 
-func GetOrphanLabelsForOrphanedDirectory(nodeID, diskUUID string) map[string]string {
+func GetOrphanLabelsForOrphanedDirectory(nodeID, diskUUID string, orphanType longhorn.OrphanType) map[string]string {
+	labels := GetBaseLabelsForSystemManagedComponent()
+	labels[GetLonghornLabelComponentKey()] = LonghornLabelOrphan
+	labels[LonghornNodeKey] = nodeID
+	labels[GetLonghornLabelKey(LonghornLabelOrphanType)] = string(orphanType)
+	return labels
+}
+
+func GetOrphanLabelsForOrphanedInstance(nodeID, instanceManager string, orphanType longhorn.OrphanType) map[string]string {
 	labels := GetBaseLabelsForSystemManagedComponent()
 	labels[GetLonghornLabelComponentKey()] = LonghornLabelOrphan
 	labels[LonghornNodeKey] = nodeID
-	labels[GetLonghornLabelKey(LonghornLabelOrphanType)] = string(longhorn.OrphanTypeReplica)
+	labels[GetLonghornLabelKey(LonghornLabelOrphanType)] = string(orphanType)
+	labels[GetLonghornLabelKey(LonghornLabelInstanceManager)] = instanceManager
 	return labels
 }
 
@@ -698,16 +774,47 @@ func GetVersionLabelKey() string {
 	return GetLonghornLabelKey(LonghornLabelVersion)
 }
 
-func GetRegionAndZone(labels map[string]string) (string, string) {
-	region := ""
-	zone := ""
-	if v, ok := labels[KubernetesTopologyRegionLabelKey]; ok {
-		region = v
+// GetRegionAndZone returns the region and the zone of a Kubernetes node from the override annotations, or else from
+// the first label found of regionLabelKeys and zoneLabelKeys
+func GetRegionAndZone(labels, annotations map[string]string, regionLabelKeys, zoneLabelKeys []string) (string, string) {
+	getValue := func(overrideAnnotationKey string, labelKeys []string) string {
+		if v, ok := annotations[overrideAnnotationKey]; ok {
+			return v
+		}
+		for _, key := range labelKeys {
+			if v, ok := labels[key]; ok {
+				return v
+			}
+		}
+		return ""
+	}
+	return getValue(KubeNodeRegionOverrideAnnotationKey, regionLabelKeys), getValue(KubeNodeZoneOverrideAnnotationKey, zoneLabelKeys)
+}
+
+// GetNodeTagsFromLabels returns the sorted node tags of the Kubernetes node labels found in tagLabels, which maps the
+// label keys to their tags, or to an empty string if the label value is the tag. The label values which are not valid
+// tags are skipped.
+func GetNodeTagsFromLabels(labels, tagLabels map[string]string) []string {
+	tags := []string{}
+	for key, tag := range tagLabels {
+		value, ok := labels[key]
+		if !ok {
+			continue
+		}
+		if tag == "" {
+			tag = value
+		}
+		if _, err := util.ValidateTags([]string{tag}); err != nil {
+			logrus.WithError(err).Warnf("Skipped invalid node tag %v of label %v", tag, key)
+			continue
+		}
+		tags = append(tags, tag)
 	}
-	if v, ok := labels[KubernetesTopologyZoneLabelKey]; ok {
-		zone = v
+	validTags, _ := util.ValidateTags(tags)
+	if validTags == nil {
+		return []string{}
 	}
-	return region, zone
+	return validTags
 }
 
 func GetEngineImageChecksumName(image string) string {
@@ -726,6 +833,14 @@ func GetOrphanChecksumNameForOrphanedDataStore(nodeID, diskName, diskPath, diskU
 	return orphanPrefix + util.GetStringChecksumSHA256(strings.TrimSpace(fmt.Sprintf("%s-%s-%s-%s-%s", nodeID, diskName, diskPath, diskUUID, dataStore)))
 }
 
+func GetOrphanChecksumNameForOrphanedBackingImage(nodeID, diskName, diskPath, diskUUID, directoryName string) string {
+	return orphanPrefix + util.GetStringChecksumSHA256(strings.TrimSpace(fmt.Sprintf("%s-%s-%s-%s-%s-%s", longhorn.OrphanTypeBackingImage, nodeID, diskName, diskPath, diskUUID, directoryName)))
+}
+
+func GetOrphanChecksumNameForOrphanedInstance(instanceName, instanceManager, dataEngine string) string {
+	return orphanPrefix + util.GetStringChecksumSHA256(strings.TrimSpace(fmt.Sprintf("%s-%s-%s", instanceName, instanceManager, dataEngine)))
+}
+
 func GetShareManagerPodNameFromShareManagerName(smName string) string {
 	return shareManagerPrefix + smName
 }
@@ -742,6 +857,12 @@ func GetShareManagerNameFromShareManagerPodName(podName string) string {
 	return strings.TrimPrefix(podName, shareManagerPrefix)
 }
 
+// GetRestoreCredentialSecretName returns the name of the secret holding the backup store credential used to restore
+// the volume from backup
+func GetRestoreCredentialSecretName(volumeName string) string {
+	return restoreCredentialPrefix + volumeName
+}
+
 func GetBackupVolumeNameFromVolumeName(volumeName string) string {
 	return volumeName + "-" + util.RandomID()
 }
@@ -891,7 +1012,8 @@ func ValidateReplicaAutoBalance(option longhorn.ReplicaAutoBalance) error {
 }
 
 func ValidateDataLocality(mode longhorn.DataLocality) error {
-	if mode != longhorn.DataLocalityDisabled && mode != longhorn.DataLocalityBestEffort && mode != longhorn.DataLocalityStrictLocal {
+	if mode != longhorn.DataLocalityDisabled && mode != longhorn.DataLocalityBestEffort &&
+		mode != longhorn.DataLocalityBestEffortLoadAware && mode != longhorn.DataLocalityStrictLocal {
 		return fmt.Errorf("invalid data locality mode: %v", mode)
 	}
 	return nil
@@ -985,6 +1107,95 @@ func ValidateFreezeFilesystemForSnapshot(value longhorn.FreezeFilesystemForSnaps
 	return nil
 }
 
+// ValidateSnapshotMaxChainLength validates the maximum length of the snapshot chain of a volume. 0 means unlimited.
+func ValidateSnapshotMaxChainLength(value int) error {
+	if value != 0 && (value < 2 || value > MaxSnapshotNum) {
+		return fmt.Errorf("snapshot max chain length should be 0 for unlimited or between 2 to %v", MaxSnapshotNum)
+	}
+	return nil
+}
+
+// MaxVolumeIOPauseTimeout is the longest time the IO of a volume can be paused before it's resumed automatically.
+const MaxVolumeIOPauseTimeout = 10 * time.Minute
+
+// ValidateVolumeIOPauseTimeout validates the timeout in seconds after which the paused IO of a volume is resumed
+// automatically.
+func ValidateVolumeIOPauseTimeout(timeout int64) error {
+	if timeout < 1 || time.Duration(timeout)*time.Second > MaxVolumeIOPauseTimeout {
+		return fmt.Errorf("IO pause timeout should be between 1 and %v seconds", int64(MaxVolumeIOPauseTimeout.Seconds()))
+	}
+	return nil
+}
+
+// ValidateSnapshotRetentionKeepLast validates the number of the newest snapshots kept by the snapshot retention
+// policy of a volume. 0 means unlimited.
+func ValidateSnapshotRetentionKeepLast(value int) error {
+	if value < 0 || value > MaxSnapshotNum {
+		return fmt.Errorf("snapshot retention keep last should be 0 for unlimited or between 1 to %v", MaxSnapshotNum)
+	}
+	return nil
+}
+
+// ValidateSnapshotRetentionMaxAge validates the maximum age of the snapshots kept by the snapshot retention policy
+// of a volume. Empty means unlimited.
+func ValidateSnapshotRetentionMaxAge(value string) error {
+	if value == "" {
+		return nil
+	}
+	maxAge, err := time.ParseDuration(value)
+	if err != nil {
+		return errors.Wrapf(err, "invalid snapshot retention max age %v", value)
+	}
+	if maxAge <= 0 {
+		return fmt.Errorf("snapshot retention max age should be empty for unlimited or greater than 0")
+	}
+	return nil
+}
+
+// ValidateSnapshotRetentionMaxSize validates the maximum total size of the snapshots kept by the snapshot retention
+// policy of a volume. 0 means unlimited.
+func ValidateSnapshotRetentionMaxSize(value int64) error {
+	if value < 0 {
+		return fmt.Errorf("snapshot retention max size should be greater than or equal to 0")
+	}
+	return nil
+}
+
+// ValidateBackupMaxAge validates the maximum age in minutes of the last completed backup of a volume. 0 means
+// disabled.
+func ValidateBackupMaxAge(value int) error {
+	if value < 0 {
+		return fmt.Errorf("backup max age should be greater than or equal to 0")
+	}
+	return nil
+}
+
+// ValidateVolumeAutoResizePolicy validates the policy expanding a volume automatically when its filesystem is filling
+// up. Nil means disabled.
+func ValidateVolumeAutoResizePolicy(policy *longhorn.VolumeAutoResizePolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.ThresholdPercentage < 1 || policy.ThresholdPercentage > 99 {
+		return fmt.Errorf("auto resize threshold percentage should be between 1 to 99")
+	}
+	if policy.Increment <= 0 {
+		return fmt.Errorf("auto resize increment should be greater than 0")
+	}
+	if policy.MaxSize <= 0 {
+		return fmt.Errorf("auto resize max size should be greater than 0")
+	}
+	return nil
+}
+
+func ValidateCloneMode(value longhorn.VolumeCloneMode) error {
+	if value != longhorn.VolumeCloneModeFullCopy &&
+		value != longhorn.VolumeCloneModeColocateWithSource {
+		return fmt.Errorf("invalid CloneMode setting: %v", value)
+	}
+	return nil
+}
+
 func GetDaemonSetNameFromEngineImageName(engineImageName string) string {
 	return "engine-image-" + engineImageName
 }
@@ -1357,3 +1568,77 @@ func GetBackingImageMonitorName(imName string) string {
 func GetV2BackingImageWithDiskUUIDName(biName, v2DiskUUID string) string {
 	return fmt.Sprintf("%v-%v", biName, v2DiskUUID)
 }
+
+// ReplicaPlacementHint is the placement of a replica data directory on a disk, which the replica scheduler reuses for
+// a new replica of the volume so the data left on the disk is adopted, e.g. after the cluster is rebuilt.
+type ReplicaPlacementHint struct {
+	NodeID            string `json:"nodeID"`
+	DiskID            string `json:"diskID"`
+	DiskPath          string `json:"diskPath"`
+	DataDirectoryName string `json:"dataDirectoryName"`
+}
+
+// GetReplicaPlacementHints returns the replica placement hints in the annotation of the volume
+func GetReplicaPlacementHints(volume *longhorn.Volume) ([]ReplicaPlacementHint, error) {
+	value, ok := volume.Annotations[GetLonghornLabelKey(LonghornAnnotationReplicaPlacementHint)]
+	if !ok || value == "" {
+		return nil, nil
+	}
+	hints := []ReplicaPlacementHint{}
+	if err := json.Unmarshal([]byte(value), &hints); err != nil {
+		return nil, errors.Wrapf(err, "invalid replica placement hint %v of volume %v", value, volume.Name)
+	}
+	for _, hint := range hints {
+		if hint.NodeID == "" || hint.DiskID == "" || hint.DataDirectoryName == "" {
+			return nil, fmt.Errorf("invalid replica placement hint %+v of volume %v: node, disk and data directory are required", hint, volume.Name)
+		}
+	}
+	return hints, nil
+}
+
+// CapacityRebalanceMove is a replica of the volume being moved by the capacity rebalance to the target disk. A new
+// replica is scheduled to the target disk, then the moved replica is removed once the new one is healthy.
+type CapacityRebalanceMove struct {
+	ReplicaName  string `json:"replicaName"`
+	TargetNodeID string `json:"targetNodeID"`
+	TargetDiskID string `json:"targetDiskID"`
+	StartedAt    string `json:"startedAt"`
+}
+
+// GetCapacityRebalanceMove returns the capacity rebalance move in the annotation of the volume, or nil if the volume
+// isn't being moved
+func GetCapacityRebalanceMove(volume *longhorn.Volume) (*CapacityRebalanceMove, error) {
+	value, ok := volume.Annotations[GetLonghornLabelKey(LonghornAnnotationCapacityRebalanceMove)]
+	if !ok || value == "" {
+		return nil, nil
+	}
+	move := &CapacityRebalanceMove{}
+	if err := json.Unmarshal([]byte(value), move); err != nil {
+		return nil, errors.Wrapf(err, "invalid capacity rebalance move %v of volume %v", value, volume.Name)
+	}
+	if move.ReplicaName == "" || move.TargetNodeID == "" || move.TargetDiskID == "" {
+		return nil, fmt.Errorf("invalid capacity rebalance move %+v of volume %v: replica, target node and target disk are required", move, volume.Name)
+	}
+	return move, nil
+}
+
+// SetDiskPressureRebalance marks the replica as created to replace a replica on the disk under pressure
+func SetDiskPressureRebalance(replica *longhorn.Replica, diskUUID string, rebalancedAt time.Time) {
+	if replica.Annotations == nil {
+		replica.Annotations = map[string]string{}
+	}
+	replica.Annotations[GetLonghornLabelKey(LonghornAnnotationDiskPressureRebalanceFrom)] = diskUUID
+	replica.Annotations[GetLonghornLabelKey(LonghornAnnotationDiskPressureRebalanceAt)] = rebalancedAt.UTC().Format(time.RFC3339)
+}
+
+// GetDiskPressureRebalance returns the UUID of the disk under pressure the replica is created to move off and when
+// the move was admitted, or an empty UUID if the replica isn't created for the disk pressure
+func GetDiskPressureRebalance(replica *longhorn.Replica) (string, time.Time) {
+	diskUUID := replica.Annotations[GetLonghornLabelKey(LonghornAnnotationDiskPressureRebalanceFrom)]
+	if diskUUID == "" {
+		return "", time.Time{}
+	}
+	// An invalid time is treated as the zero time, so the disk is limited only while the replica is rebuilding
+	rebalancedAt, _ := time.Parse(time.RFC3339, replica.Annotations[GetLonghornLabelKey(LonghornAnnotationDiskPressureRebalanceAt)])
+	return diskUUID, rebalancedAt
+}
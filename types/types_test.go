@@ -10,6 +10,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	. "gopkg.in/check.v1"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
 )
 
 const (
@@ -121,6 +123,121 @@ func (s *TestSuite) TestParseToleration(c *C) {
 	}
 }
 
+func (s *TestSuite) TestParseSystemManagedComponentsSchedulingOverrides(c *C) {
+	type testCase struct {
+		input string
+
+		expectedOverrides map[SystemManagedComponent]SystemManagedComponentScheduling
+		expectError       bool
+	}
+	testCases := map[string]testCase{
+		"valid empty setting": {
+			input:             "",
+			expectedOverrides: map[SystemManagedComponent]SystemManagedComponentScheduling{},
+		},
+		"valid priority class and toleration": {
+			input: `{"instance-manager": {"priorityClass": "storage-critical", "taintToleration": "key=value:NoSchedule"}}`,
+			expectedOverrides: map[SystemManagedComponent]SystemManagedComponentScheduling{
+				SystemManagedComponentInstanceManager: {
+					PriorityClass:   "storage-critical",
+					TaintToleration: "key=value:NoSchedule",
+				},
+			},
+		},
+		"valid topology spread constraints": {
+			input: `{"csi": {"topologySpreadConstraints": [{"maxSkew": 1, "topologyKey": "zone", "whenUnsatisfiable": "ScheduleAnyway"}]}}`,
+			expectedOverrides: map[SystemManagedComponent]SystemManagedComponentScheduling{
+				SystemManagedComponentCSI: {
+					TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+						{
+							MaxSkew:           1,
+							TopologyKey:       "zone",
+							WhenUnsatisfiable: corev1.ScheduleAnyway,
+						},
+					},
+				},
+			},
+		},
+		"invalid component": {
+			input:       `{"engine-image": {"priorityClass": "storage-critical"}}`,
+			expectError: true,
+		},
+		"invalid toleration": {
+			input:       `{"share-manager": {"taintToleration": "key:InvalidEffect"}}`,
+			expectError: true,
+		},
+		"invalid topology spread constraint without topology key": {
+			input:       `{"share-manager": {"topologySpreadConstraints": [{"maxSkew": 1}]}}`,
+			expectError: true,
+		},
+		"invalid json": {
+			input:       "instance-manager=storage-critical",
+			expectError: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		fmt.Printf("testing %v\n", testName)
+
+		overrides, err := UnmarshalSystemManagedComponentsSchedulingOverrides(testCase.input)
+		if !testCase.expectError {
+			c.Assert(err, IsNil, Commentf(TestErrErrorFmt, testName, err))
+		} else {
+			c.Assert(err, NotNil)
+		}
+
+		c.Assert(reflect.DeepEqual(overrides, testCase.expectedOverrides), Equals, true, Commentf(TestErrResultFmt, testName))
+	}
+}
+
+func (s *TestSuite) TestParseControllerWorkers(c *C) {
+	type testCase struct {
+		input string
+
+		expectedWorkers map[string]int
+		expectError     bool
+	}
+	testCases := map[string]testCase{
+		"valid empty setting": {
+			input:           "",
+			expectedWorkers: map[string]int{},
+		},
+		"valid controllers": {
+			input:           "longhorn-volume:10; longhorn-engine : 8",
+			expectedWorkers: map[string]int{"longhorn-volume": 10, "longhorn-engine": 8},
+		},
+		"invalid format": {
+			input:       "longhorn-volume=10",
+			expectError: true,
+		},
+		"invalid empty controller name": {
+			input:       ":10",
+			expectError: true,
+		},
+		"invalid workers": {
+			input:       "longhorn-volume:ten",
+			expectError: true,
+		},
+		"invalid zero workers": {
+			input:       "longhorn-volume:0",
+			expectError: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		fmt.Printf("testing %v\n", testName)
+
+		workers, err := UnmarshalControllerWorkers(testCase.input)
+		if !testCase.expectError {
+			c.Assert(err, IsNil, Commentf(TestErrErrorFmt, testName, err))
+		} else {
+			c.Assert(err, NotNil)
+		}
+
+		c.Assert(reflect.DeepEqual(workers, testCase.expectedWorkers), Equals, true, Commentf(TestErrResultFmt, testName))
+	}
+}
+
 func (s *TestSuite) TestIsSelectorsInTags(c *C) {
 	type testCase struct {
 		inputTags          []string
@@ -233,3 +350,273 @@ func (s *TestSuite) TestGenerateEngineNameForVolume(c *C) {
 		c.Assert(actual, Equals, testCase.expectedEngineName, Commentf(TestErrResultFmt, testName))
 	}
 }
+
+func (s *TestSuite) TestParseVolumeParameters(c *C) {
+	type testCase struct {
+		parameters map[string]string
+
+		expectedSpec *longhorn.VolumeSpec
+		expectError  bool
+	}
+	testCases := map[string]testCase{
+		"defaults": {
+			parameters: map[string]string{},
+			expectedSpec: &longhorn.VolumeSpec{
+				StaleReplicaTimeout:     2880,
+				RevisionCounterDisabled: true,
+				DataEngine:              longhorn.DataEngineTypeV1,
+			},
+		},
+		"migratable rwx volume": {
+			parameters: map[string]string{
+				"share":            "true",
+				"migratable":       "true",
+				"numberOfReplicas": "2",
+				"diskSelector":     "ssd,fast",
+				"dataLocality":     "",
+			},
+			expectedSpec: &longhorn.VolumeSpec{
+				StaleReplicaTimeout:     2880,
+				AccessMode:              longhorn.AccessModeReadWriteMany,
+				Migratable:              true,
+				NumberOfReplicas:        2,
+				RevisionCounterDisabled: true,
+				DataEngine:              longhorn.DataEngineTypeV1,
+				DiskSelector:            []string{"ssd", "fast"},
+			},
+		},
+		"migratable rwo volume": {
+			parameters: map[string]string{"migratable": "true"},
+			expectedSpec: &longhorn.VolumeSpec{
+				StaleReplicaTimeout:     2880,
+				RevisionCounterDisabled: true,
+				DataEngine:              longhorn.DataEngineTypeV1,
+			},
+		},
+		"invalid bool": {
+			parameters:  map[string]string{"encrypted": "maybe"},
+			expectError: true,
+		},
+		"invalid replica auto balance": {
+			parameters:  map[string]string{"replicaAutoBalance": "invalid"},
+			expectError: true,
+		},
+		"unmap mark snap chain removed depends on data engine": {
+			parameters: map[string]string{
+				"dataEngine":                "v2",
+				"unmapMarkSnapChainRemoved": "enabled",
+			},
+			expectError: true,
+		},
+		"invalid mkfs params": {
+			parameters:  map[string]string{"mkfsParams": "-I 256; reboot"},
+			expectError: true,
+		},
+		"invalid force unmount timeout": {
+			parameters:  map[string]string{"forceUnmountTimeout": "-1m"},
+			expectError: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		fmt.Printf("testing %v\n", testName)
+
+		spec, err := ParseVolumeParameters(testCase.parameters)
+		if testCase.expectError {
+			c.Assert(err, NotNil, Commentf(TestErrResultFmt, testName))
+			continue
+		}
+		c.Assert(err, IsNil, Commentf(TestErrErrorFmt, testName, err))
+		c.Assert(spec, DeepEquals, testCase.expectedSpec, Commentf(TestErrResultFmt, testName))
+	}
+}
+
+func (s *TestSuite) TestValidateVolumeSpecParametersUpdate(c *C) {
+	oldSpec := &longhorn.VolumeSpec{DataEngine: longhorn.DataEngineTypeV1, CloneMode: longhorn.VolumeCloneModeFullCopy}
+
+	newSpec := oldSpec.DeepCopy()
+	newSpec.NumberOfReplicas = 2
+	c.Assert(ValidateVolumeSpecParametersUpdate(oldSpec, newSpec), IsNil)
+
+	newSpec = oldSpec.DeepCopy()
+	newSpec.DataEngine = longhorn.DataEngineTypeV2
+	c.Assert(ValidateVolumeSpecParametersUpdate(oldSpec, newSpec), NotNil)
+
+	newSpec = oldSpec.DeepCopy()
+	newSpec.Encrypted = true
+	c.Assert(ValidateVolumeSpecParametersUpdate(oldSpec, newSpec), NotNil)
+
+	// A field not set yet can be filled in
+	oldSpec.CloneMode = ""
+	newSpec = oldSpec.DeepCopy()
+	newSpec.CloneMode = longhorn.VolumeCloneModeColocateWithSource
+	c.Assert(ValidateVolumeSpecParametersUpdate(oldSpec, newSpec), IsNil)
+}
+
+func (s *TestSuite) TestGetPVCVolumeParameterOverrides(c *C) {
+	type testCase struct {
+		annotations map[string]string
+
+		expectedOverrides map[string]string
+		expectError       bool
+	}
+	testCases := map[string]testCase{
+		"no annotations": {
+			annotations:       nil,
+			expectedOverrides: map[string]string{},
+		},
+		"overridable parameters": {
+			annotations: map[string]string{
+				"parameter.longhorn.io/numberOfReplicas":    "2",
+				"parameter.longhorn.io/dataLocality":        "best-effort",
+				"parameter.longhorn.io/staleReplicaTimeout": "30",
+				"parameter.longhorn.io/diskSelector":        "",
+				"volume.kubernetes.io/storage-provisioner":  "driver.longhorn.io",
+			},
+			expectedOverrides: map[string]string{
+				"numberOfReplicas":    "2",
+				"dataLocality":        "best-effort",
+				"staleReplicaTimeout": "30",
+				"diskSelector":        "",
+			},
+		},
+		"parameter not overridable": {
+			annotations: map[string]string{"parameter.longhorn.io/encrypted": "true"},
+			expectError: true,
+		},
+		"host parameters not overridable": {
+			annotations: map[string]string{
+				"parameter.longhorn.io/importSource": "/dev/sdb",
+				"parameter.longhorn.io/importNode":   "node-1",
+			},
+			expectError: true,
+		},
+		"per StorageClass parameter not overridable": {
+			annotations: map[string]string{"parameter.longhorn.io/filesystemCheckAfterUncleanDetach": "true"},
+			expectError: true,
+		},
+		"unknown parameter": {
+			annotations: map[string]string{"parameter.longhorn.io/unknown": "true"},
+			expectError: true,
+		},
+		"invalid number of replicas": {
+			annotations: map[string]string{"parameter.longhorn.io/numberOfReplicas": "two"},
+			expectError: true,
+		},
+		"invalid data locality": {
+			annotations: map[string]string{"parameter.longhorn.io/dataLocality": "invalid"},
+			expectError: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		fmt.Printf("testing %v\n", testName)
+
+		overrides, err := GetPVCVolumeParameterOverrides(testCase.annotations)
+		if testCase.expectError {
+			c.Assert(err, NotNil, Commentf(TestErrResultFmt, testName))
+			continue
+		}
+		c.Assert(err, IsNil, Commentf(TestErrErrorFmt, testName, err))
+		c.Assert(overrides, DeepEquals, testCase.expectedOverrides, Commentf(TestErrResultFmt, testName))
+	}
+}
+
+func (s *TestSuite) TestValidateNFSOptions(c *C) {
+	validOptions := []string{
+		"vers=4.1,noresvport,timeo=600,retrans=5,softerr",
+		"vers=4.2,nconnect=4,hard",
+		"nfsvers=3,proto=tcp,lookupcache=none",
+	}
+	for _, options := range validOptions {
+		c.Assert(ValidateNFSOptions(options), IsNil, Commentf(TestErrResultFmt, options))
+	}
+
+	invalidOptions := []string{
+		"",
+		"vers=4.1,,hard",
+		"vers=5",
+		"timeo=0",
+		"retrans=many",
+		"nconnect=17",
+		"hard,soft",
+		"vers=4.1 hard",
+		"vers=4.1;reboot",
+	}
+	for _, options := range invalidOptions {
+		c.Assert(ValidateNFSOptions(options), NotNil, Commentf(TestErrResultFmt, options))
+	}
+}
+
+func (s *TestSuite) TestValidateVolumeImportSource(c *C) {
+	for _, source := range []string{"/dev/sdb", "/var/lib/images/disk.img"} {
+		c.Assert(ValidateVolumeImportSource(source), IsNil, Commentf(TestErrResultFmt, source))
+	}
+
+	for _, source := range []string{"", "/", "dev/sdb", "/dev/../etc/shadow", "/var/lib/images/"} {
+		c.Assert(ValidateVolumeImportSource(source), NotNil, Commentf(TestErrResultFmt, source))
+	}
+}
+
+func (s *TestSuite) TestIsVolumeImportSourceAllowed(c *C) {
+	directories, err := UnmarshalVolumeImportAllowedSourceDirectories(" /var/lib/images/ ; /dev/disk/by-id;")
+	c.Assert(err, IsNil)
+	c.Assert(directories, DeepEquals, []string{"/var/lib/images", "/dev/disk/by-id"})
+
+	_, err = UnmarshalVolumeImportAllowedSourceDirectories("/var/lib/images;images")
+	c.Assert(err, NotNil)
+
+	for _, source := range []string{"/var/lib/images/disk.img", "/dev/disk/by-id/nvme-disk-1"} {
+		c.Assert(IsVolumeImportSourceAllowed(source, directories), Equals, true, Commentf(TestErrResultFmt, source))
+	}
+	for _, source := range []string{"/var/lib/images", "/var/lib/images-private/disk.img", "/var/lib/images/../../../etc/shadow", "/dev/sdb", "disk.img"} {
+		c.Assert(IsVolumeImportSourceAllowed(source, directories), Equals, false, Commentf(TestErrResultFmt, source))
+	}
+	c.Assert(IsVolumeImportSourceAllowed("/var/lib/images/disk.img", []string{}), Equals, false)
+}
+
+func (s *TestSuite) TestGetNodeTopologyAndTagsFromLabels(c *C) {
+	labels := map[string]string{
+		KubernetesFailureDomainRegionLabelKey: "region-a",
+		KubernetesTopologyZoneLabelKey:        "zone-a",
+		"node.kubernetes.io/instance-type":    "m5.large",
+		"example.com/nvme":                    "",
+		"example.com/invalid":                 "not valid",
+	}
+
+	region, zone := GetRegionAndZone(labels, nil,
+		[]string{KubernetesTopologyRegionLabelKey, KubernetesFailureDomainRegionLabelKey},
+		[]string{KubernetesTopologyZoneLabelKey, KubernetesFailureDomainZoneLabelKey})
+	c.Assert(region, Equals, "region-a")
+	c.Assert(zone, Equals, "zone-a")
+
+	region, zone = GetRegionAndZone(labels, map[string]string{KubeNodeZoneOverrideAnnotationKey: "zone-b"},
+		[]string{KubernetesTopologyRegionLabelKey}, []string{KubernetesTopologyZoneLabelKey})
+	c.Assert(region, Equals, "")
+	c.Assert(zone, Equals, "zone-b")
+
+	tagLabels, err := UnmarshalNodeTagLabels(" node.kubernetes.io/instance-type ; example.com/nvme=fast;example.com/invalid;example.com/missing=slow")
+	c.Assert(err, IsNil)
+	c.Assert(GetNodeTagsFromLabels(labels, tagLabels), DeepEquals, []string{"fast", "m5.large"})
+
+	for _, value := range []string{"invalid key", "example.com/nvme=", "example.com/nvme=not valid"} {
+		_, err = UnmarshalNodeTagLabels(value)
+		c.Assert(err, NotNil, Commentf(TestErrResultFmt, value))
+	}
+
+	keys, err := UnmarshalNodeLabelKeys(KubernetesTopologyZoneLabelKey + "; " + KubernetesFailureDomainZoneLabelKey + ";")
+	c.Assert(err, IsNil)
+	c.Assert(keys, DeepEquals, []string{KubernetesTopologyZoneLabelKey, KubernetesFailureDomainZoneLabelKey})
+}
+
+func (s *TestSuite) TestGetBackingImageNameAndUUIDFromDirectoryName(c *C) {
+	name, uuid, err := GetBackingImageNameAndUUIDFromDirectoryName(GetBackingImageDirectoryName("parrot-image", "a1b2c3d4"))
+	c.Assert(err, IsNil)
+	c.Assert(name, Equals, "parrot-image")
+	c.Assert(uuid, Equals, "a1b2c3d4")
+
+	for _, directoryName := range []string{"", "a1b2c3d4", "-a1b2c3d4", "parrot-image_a1b2c3d4", "parrot-image-a1b2c3"} {
+		_, _, err = GetBackingImageNameAndUUIDFromDirectoryName(directoryName)
+		c.Assert(err, NotNil, Commentf(TestErrResultFmt, directoryName))
+	}
+}
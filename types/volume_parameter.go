@@ -0,0 +1,692 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+)
+
+type VolumeParameterType string
+
+const (
+	VolumeParameterTypeString = VolumeParameterType("string")
+	VolumeParameterTypeInt    = VolumeParameterType("int")
+	VolumeParameterTypeBool   = VolumeParameterType("bool")
+	VolumeParameterTypeFloat  = VolumeParameterType("float")
+	VolumeParameterTypeJSON   = VolumeParameterType("json")
+	// VolumeParameterTypeList is a comma separated list of strings
+	VolumeParameterTypeList = VolumeParameterType("list")
+)
+
+// PVCVolumeParameterAnnotationPrefix is the prefix of the PVC annotations overriding the StorageClass parameters,
+// e.g. parameter.longhorn.io/numberOfReplicas
+const PVCVolumeParameterAnnotationPrefix = "parameter.longhorn.io/"
+
+// VolumeParameterDefinition describes a volume parameter accepted in a StorageClass. The same definitions are used
+// by the CSI plugin and the volume warm pool to build a volume from the parameters, and by the volume webhook to
+// validate the volume spec, so a value accepted by one path is accepted by the others.
+type VolumeParameterDefinition struct {
+	Name string
+	Type VolumeParameterType
+	// Default is applied when the parameter is not given. An empty default leaves the field to the volume mutator.
+	Default string
+	// Mutable is false if the value cannot be changed once it's set in the volume spec
+	Mutable bool
+	// PVCOverridable is true if the value can be overridden by a PVC annotation with PVCVolumeParameterAnnotationPrefix.
+	// The PVC is owned by the tenant, so it's only set for the parameters that affect the volume itself and not the host
+	// or the other volumes.
+	PVCOverridable bool
+	// Validate is an additional check after the value is parsed as Type. The spec contains the parameters
+	// defined before this one, e.g. the data engine.
+	Validate func(spec *longhorn.VolumeSpec, value string) error
+	// Apply sets the validated value in the volume spec. It's nil if the parameter is only consumed by the CSI plugin.
+	Apply func(spec *longhorn.VolumeSpec, value string)
+	// Get returns the value of the parameter from the volume spec. It's nil if the parameter is not kept in
+	// the spec or cannot be converted back.
+	Get func(spec *longhorn.VolumeSpec) string
+}
+
+// volumeParameterDefinitions is ordered so that the parameters another parameter depends on come first
+var volumeParameterDefinitions = []VolumeParameterDefinition{
+	{
+		Name:           "staleReplicaTimeout",
+		Type:           VolumeParameterTypeInt,
+		Default:        "2880", // 48 hours
+		Mutable:        true,
+		PVCOverridable: true,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			if staleReplicaTimeout, _ := strconv.Atoi(value); staleReplicaTimeout > 0 {
+				spec.StaleReplicaTimeout = staleReplicaTimeout
+			}
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return strconv.Itoa(spec.StaleReplicaTimeout) },
+	},
+	{
+		Name:    "share",
+		Type:    VolumeParameterTypeBool,
+		Mutable: true,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.AccessMode = longhorn.AccessModeReadWriteOnce
+			if parseVolumeParameterBool(value) {
+				spec.AccessMode = longhorn.AccessModeReadWriteMany
+			}
+		},
+	},
+	{
+		Name:    "migratable",
+		Type:    VolumeParameterTypeBool,
+		Mutable: true,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.Migratable = parseVolumeParameterBool(value) && spec.AccessMode == longhorn.AccessModeReadWriteMany
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return strconv.FormatBool(spec.Migratable) },
+	},
+	{
+		Name:    "encrypted",
+		Type:    VolumeParameterTypeBool,
+		Mutable: false,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.Encrypted = parseVolumeParameterBool(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return strconv.FormatBool(spec.Encrypted) },
+	},
+	{
+		Name:           "numberOfReplicas",
+		Type:           VolumeParameterTypeInt,
+		Mutable:        true,
+		PVCOverridable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			if numberOfReplicas, _ := strconv.Atoi(value); numberOfReplicas < 0 {
+				return fmt.Errorf("number of replicas %v should not be negative", value)
+			}
+			return nil
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.NumberOfReplicas, _ = strconv.Atoi(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return strconv.Itoa(spec.NumberOfReplicas) },
+	},
+	{
+		Name:    "replicaAutoBalance",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateReplicaAutoBalance(longhorn.ReplicaAutoBalance(value))
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.ReplicaAutoBalance = longhorn.ReplicaAutoBalance(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return string(spec.ReplicaAutoBalance) },
+	},
+	{
+		Name:           "dataLocality",
+		Type:           VolumeParameterTypeString,
+		Mutable:        true,
+		PVCOverridable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateDataLocality(longhorn.DataLocality(value))
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.DataLocality = longhorn.DataLocality(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return string(spec.DataLocality) },
+	},
+	{
+		Name:    "disableRevisionCounter",
+		Type:    VolumeParameterTypeBool,
+		Default: "true",
+		Mutable: true,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.RevisionCounterDisabled = parseVolumeParameterBool(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return strconv.FormatBool(spec.RevisionCounterDisabled) },
+	},
+	{
+		Name:    "dataEngine",
+		Type:    VolumeParameterTypeString,
+		Default: string(longhorn.DataEngineTypeV1),
+		Mutable: false,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateDataEngine(longhorn.DataEngineType(value))
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.DataEngine = longhorn.DataEngineType(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return string(spec.DataEngine) },
+	},
+	{
+		Name:    "unmapMarkSnapChainRemoved",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateUnmapMarkSnapChainRemoved(spec.DataEngine, longhorn.UnmapMarkSnapChainRemoved(value))
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.UnmapMarkSnapChainRemoved = longhorn.UnmapMarkSnapChainRemoved(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return string(spec.UnmapMarkSnapChainRemoved) },
+	},
+	{
+		Name:    "replicaSoftAntiAffinity",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateReplicaSoftAntiAffinity(longhorn.ReplicaSoftAntiAffinity(value))
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.ReplicaSoftAntiAffinity = longhorn.ReplicaSoftAntiAffinity(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return string(spec.ReplicaSoftAntiAffinity) },
+	},
+	{
+		Name:    "replicaZoneSoftAntiAffinity",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateReplicaZoneSoftAntiAffinity(longhorn.ReplicaZoneSoftAntiAffinity(value))
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.ReplicaZoneSoftAntiAffinity = longhorn.ReplicaZoneSoftAntiAffinity(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return string(spec.ReplicaZoneSoftAntiAffinity) },
+	},
+	{
+		Name:    "replicaDiskSoftAntiAffinity",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateReplicaDiskSoftAntiAffinity(longhorn.ReplicaDiskSoftAntiAffinity(value))
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.ReplicaDiskSoftAntiAffinity = longhorn.ReplicaDiskSoftAntiAffinity(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return string(spec.ReplicaDiskSoftAntiAffinity) },
+	},
+	{
+		Name:    "fromBackup",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.FromBackup = value
+		},
+	},
+	{
+		Name:    "backupTargetName",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.BackupTargetName = value
+		},
+	},
+	{
+		// snapshotBackupTargetName is the backup target of the backups taken by the CSI snapshots of the volume, unless
+		// the VolumeSnapshotClass selects another one. It's only consumed by the CSI plugin.
+		Name:    "snapshotBackupTargetName",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+	},
+	{
+		Name:    "dataSource",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.DataSource = longhorn.VolumeDataSource(value)
+		},
+	},
+	{
+		Name:    "cloneMode",
+		Type:    VolumeParameterTypeString,
+		Mutable: false,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateCloneMode(longhorn.VolumeCloneMode(value))
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.CloneMode = longhorn.VolumeCloneMode(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return string(spec.CloneMode) },
+	},
+	{
+		Name:    longhorn.BackingImageParameterName,
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.BackingImage = value
+		},
+	},
+	{
+		// The recurring jobs are applied as volume labels by the CSI plugin
+		Name:    "recurringJobSelector",
+		Type:    VolumeParameterTypeJSON,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return json.Unmarshal([]byte(value), &[]longhorn.VolumeRecurringJob{})
+		},
+	},
+	{
+		Name:           "diskSelector",
+		Type:           VolumeParameterTypeList,
+		Mutable:        true,
+		PVCOverridable: true,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.DiskSelector = strings.Split(value, ",")
+		},
+	},
+	{
+		Name:    "nodeSelector",
+		Type:    VolumeParameterTypeList,
+		Mutable: true,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.NodeSelector = strings.Split(value, ",")
+		},
+	},
+	{
+		Name:    "frontend",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateVolumeFrontend(longhorn.VolumeFrontend(value))
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.Frontend = longhorn.VolumeFrontend(value)
+		},
+	},
+	{
+		Name:    "mkfsParams",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateMkfsParams(value)
+		},
+	},
+	{
+		Name:           "nfsOptions",
+		Type:           VolumeParameterTypeList,
+		Mutable:        true,
+		PVCOverridable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateNFSOptions(value)
+		},
+	},
+	{
+		Name: "importSource",
+		Type: VolumeParameterTypeString,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateVolumeImportSource(value)
+		},
+	},
+	{
+		Name: "importNode",
+		Type: VolumeParameterTypeString,
+	},
+	{
+		Name:    "ext4ReservedBlocksPercentage",
+		Type:    VolumeParameterTypeFloat,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateExt4ReservedBlocksPercentage(value)
+		},
+	},
+	{
+		Name:    "xfsProjectQuotaPercentage",
+		Type:    VolumeParameterTypeFloat,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateXFSProjectQuotaPercentage(value)
+		},
+	},
+	{
+		Name:    "freezeFilesystemForSnapshot",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateFreezeFilesystemForSnapshot(longhorn.FreezeFilesystemForSnapshot(value))
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.FreezeFilesystemForSnapshot = longhorn.FreezeFilesystemForSnapshot(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return string(spec.FreezeFilesystemForSnapshot) },
+	},
+	{
+		Name:    "forceUnmountTimeout",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			_, err := ParseForceUnmountTimeout(value)
+			return err
+		},
+	},
+	{
+		Name:    "trimFilesystemOnUnstage",
+		Type:    VolumeParameterTypeBool,
+		Mutable: true,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.TrimFilesystemOnUnstage = parseVolumeParameterBool(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return strconv.FormatBool(spec.TrimFilesystemOnUnstage) },
+	},
+	{
+		Name:    "filesystemCheckAfterUncleanDetach",
+		Type:    VolumeParameterTypeBool,
+		Mutable: true,
+	},
+	{
+		Name:    "rebuildPriority",
+		Type:    VolumeParameterTypeInt,
+		Mutable: true,
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.RebuildPriority, _ = strconv.Atoi(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return strconv.Itoa(spec.RebuildPriority) },
+	},
+	{
+		Name:    "snapshotMaxChainLength",
+		Type:    VolumeParameterTypeInt,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			length, _ := strconv.Atoi(value)
+			return ValidateSnapshotMaxChainLength(length)
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.SnapshotMaxChainLength, _ = strconv.Atoi(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return strconv.Itoa(spec.SnapshotMaxChainLength) },
+	},
+	{
+		Name:    "snapshotRetentionKeepLast",
+		Type:    VolumeParameterTypeInt,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			keepLast, _ := strconv.Atoi(value)
+			return ValidateSnapshotRetentionKeepLast(keepLast)
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.SnapshotRetentionKeepLast, _ = strconv.Atoi(value)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return strconv.Itoa(spec.SnapshotRetentionKeepLast) },
+	},
+	{
+		Name:    "snapshotRetentionMaxAge",
+		Type:    VolumeParameterTypeString,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			return ValidateSnapshotRetentionMaxAge(value)
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.SnapshotRetentionMaxAge = value
+		},
+		Get: func(spec *longhorn.VolumeSpec) string { return spec.SnapshotRetentionMaxAge },
+	},
+	{
+		Name:    "snapshotRetentionMaxSize",
+		Type:    VolumeParameterTypeInt,
+		Mutable: true,
+		Validate: func(spec *longhorn.VolumeSpec, value string) error {
+			maxSize, _ := strconv.ParseInt(value, 10, 64)
+			return ValidateSnapshotRetentionMaxSize(maxSize)
+		},
+		Apply: func(spec *longhorn.VolumeSpec, value string) {
+			spec.SnapshotRetentionMaxSize, _ = strconv.ParseInt(value, 10, 64)
+		},
+		Get: func(spec *longhorn.VolumeSpec) string {
+			return strconv.FormatInt(spec.SnapshotRetentionMaxSize, 10)
+		},
+	},
+}
+
+func GetVolumeParameterDefinition(name string) (VolumeParameterDefinition, bool) {
+	for _, definition := range volumeParameterDefinitions {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return VolumeParameterDefinition{}, false
+}
+
+// ParseVolumeParameters validates the StorageClass parameters and converts them to a volume spec, with the
+// defaults applied to the parameters that are not given. An empty value is treated as not given.
+// Unknown parameters are ignored since a StorageClass also carries the parameters of other components.
+func ParseVolumeParameters(parameters map[string]string) (*longhorn.VolumeSpec, error) {
+	spec := &longhorn.VolumeSpec{}
+	for _, definition := range volumeParameterDefinitions {
+		value := parameters[definition.Name]
+		if value == "" {
+			value = definition.Default
+		}
+		if value == "" {
+			continue
+		}
+		if err := definition.validate(spec, value); err != nil {
+			return nil, errors.Wrapf(err, "invalid parameter %v", definition.Name)
+		}
+		if definition.Apply != nil {
+			definition.Apply(spec, value)
+		}
+	}
+	return spec, nil
+}
+
+// GetPVCVolumeParameterOverrides validates the PVC annotations overriding the StorageClass parameters and returns
+// the overridden parameters. An empty value overrides the StorageClass parameter as not given.
+func GetPVCVolumeParameterOverrides(annotations map[string]string) (map[string]string, error) {
+	overrides := map[string]string{}
+	for key, value := range annotations {
+		if !strings.HasPrefix(key, PVCVolumeParameterAnnotationPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(key, PVCVolumeParameterAnnotationPrefix)
+		definition, ok := GetVolumeParameterDefinition(name)
+		if !ok || !definition.PVCOverridable {
+			return nil, fmt.Errorf("parameter %v cannot be overridden by PVC annotation %v", name, key)
+		}
+		if value != "" {
+			if err := definition.validate(&longhorn.VolumeSpec{}, value); err != nil {
+				return nil, errors.Wrapf(err, "invalid PVC annotation %v", key)
+			}
+		}
+		overrides[name] = value
+	}
+	return overrides, nil
+}
+
+// ValidateVolumeSpecParameters validates the fields of the volume spec that are set by the volume parameters
+func ValidateVolumeSpecParameters(spec *longhorn.VolumeSpec) error {
+	for _, definition := range volumeParameterDefinitions {
+		if definition.Get == nil {
+			continue
+		}
+		if err := definition.validate(spec, definition.Get(spec)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateVolumeSpecParametersUpdate rejects changes to the fields set by immutable volume parameters.
+// A field that was not set yet can still be filled in.
+func ValidateVolumeSpecParametersUpdate(oldSpec, newSpec *longhorn.VolumeSpec) error {
+	for _, definition := range volumeParameterDefinitions {
+		if definition.Mutable || definition.Get == nil {
+			continue
+		}
+		oldValue := definition.Get(oldSpec)
+		if oldValue != "" && oldValue != definition.Get(newSpec) {
+			return fmt.Errorf("changing %v from %v to %v is not supported", definition.Name, oldValue, definition.Get(newSpec))
+		}
+	}
+	return nil
+}
+
+func (definition VolumeParameterDefinition) validate(spec *longhorn.VolumeSpec, value string) error {
+	switch definition.Type {
+	case VolumeParameterTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return err
+		}
+	case VolumeParameterTypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return err
+		}
+	case VolumeParameterTypeFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return err
+		}
+	case VolumeParameterTypeJSON:
+		if !json.Valid([]byte(value)) {
+			return fmt.Errorf("invalid json format of %v", value)
+		}
+	}
+	if definition.Validate != nil {
+		return definition.Validate(spec, value)
+	}
+	return nil
+}
+
+func parseVolumeParameterBool(value string) bool {
+	b, _ := strconv.ParseBool(value)
+	return b
+}
+
+func ValidateDataEngine(dataEngine longhorn.DataEngineType) error {
+	if dataEngine != longhorn.DataEngineTypeV1 && dataEngine != longhorn.DataEngineTypeV2 {
+		return fmt.Errorf("invalid data engine: %v", dataEngine)
+	}
+	return nil
+}
+
+func ValidateVolumeFrontend(frontend longhorn.VolumeFrontend) error {
+	if frontend != longhorn.VolumeFrontendBlockDev &&
+		frontend != longhorn.VolumeFrontendISCSI &&
+		frontend != longhorn.VolumeFrontendNvmf &&
+		frontend != longhorn.VolumeFrontendUblk {
+		return fmt.Errorf("invalid volume frontend specified: %v", frontend)
+	}
+	return nil
+}
+
+// ValidateMkfsParams makes sure the user provided mkfs parameters only consist of options,
+// since they are prepended to the mkfs command arguments and the device path is always appended by the driver.
+func ValidateMkfsParams(mkfsParams string) error {
+	if strings.ContainsAny(mkfsParams, "\n\r;&|`$<>\\\"'") {
+		return fmt.Errorf("mkfsParams %q contains unsupported characters", mkfsParams)
+	}
+
+	fields := strings.Fields(mkfsParams)
+	if len(fields) > 0 && !strings.HasPrefix(fields[0], "-") {
+		return fmt.Errorf("mkfsParams %q should start with an option", mkfsParams)
+	}
+	return nil
+}
+
+var nfsOptionRegex = regexp.MustCompile(`^[a-z0-9_]+(=[A-Za-z0-9_.:/-]+)?$`)
+
+// ValidateNFSOptions validates the comma separated NFS mount options of a RWX volume, which replace the default
+// options the CSI plugin mounts the share manager export with.
+func ValidateNFSOptions(nfsOptions string) error {
+	recoveryModes := []string{}
+	for _, option := range strings.Split(nfsOptions, ",") {
+		if !nfsOptionRegex.MatchString(option) {
+			return fmt.Errorf("invalid NFS option %q", option)
+		}
+
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "vers", "nfsvers":
+			if !slices.Contains([]string{"3", "4", "4.0", "4.1", "4.2"}, value) {
+				return fmt.Errorf("unsupported NFS version %q", value)
+			}
+		case "timeo", "retrans":
+			if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+				return fmt.Errorf("NFS option %v should be a positive integer", key)
+			}
+		case "nconnect":
+			// The Linux NFS client allows up to 16 connections to a server
+			if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 16 {
+				return fmt.Errorf("NFS option nconnect should be between 1 and 16")
+			}
+		case "hard", "soft", "softerr":
+			recoveryModes = append(recoveryModes, key)
+		}
+	}
+	if len(recoveryModes) > 1 {
+		return fmt.Errorf("NFS options %v are mutually exclusive", strings.Join(recoveryModes, ","))
+	}
+	return nil
+}
+
+// ValidateVolumeImportSource makes sure the import source is an absolute host path of a block device or a file
+// image, whose data is copied into the volume by the CSI plugin
+func ValidateVolumeImportSource(source string) error {
+	if !filepath.IsAbs(source) || filepath.Clean(source) != source {
+		return fmt.Errorf("import source %q should be a clean absolute path", source)
+	}
+	if source == "/" {
+		return fmt.Errorf("import source %q should be a block device or a file image", source)
+	}
+	return nil
+}
+
+// IsVolumeImportSourceAllowed returns true if the import source is an absolute path inside one of the directories
+func IsVolumeImportSourceAllowed(source string, directories []string) bool {
+	if !filepath.IsAbs(source) {
+		return false
+	}
+	source = filepath.Clean(source)
+	for _, directory := range directories {
+		rel, err := filepath.Rel(directory, source)
+		if err != nil || rel == "." {
+			continue
+		}
+		if rel != ".." && !strings.HasPrefix(rel, "../") {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateExt4ReservedBlocksPercentage makes sure the reserved blocks percentage is accepted by mke2fs -m,
+// which allows a value between 0 and 50.
+func ValidateExt4ReservedBlocksPercentage(value string) error {
+	percentage, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(percentage) || percentage < 0 || percentage > 50 {
+		return fmt.Errorf("ext4 reserved blocks percentage %v should be between 0 and 50", value)
+	}
+	return nil
+}
+
+// ValidateXFSProjectQuotaPercentage makes sure the XFS project quota limits the volume to less than its size,
+// which the filesystem size already caps.
+func ValidateXFSProjectQuotaPercentage(value string) error {
+	percentage, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(percentage) || percentage <= 0 || percentage >= 100 {
+		return fmt.Errorf("xfs project quota percentage %v should be greater than 0 and less than 100", value)
+	}
+	return nil
+}
+
+// ParseForceUnmountTimeout parses the timeout of the force unmounts of a volume, given as a duration like 2m
+func ParseForceUnmountTimeout(value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("force unmount timeout %v should be positive", value)
+	}
+	return timeout, nil
+}
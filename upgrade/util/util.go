@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -216,9 +217,66 @@ func CheckUpgradePath(namespace string, lhClient lhclientset.Interface, eventRec
 		return err
 	}
 
+	if err := checkUpgradeCompatibility(namespace, lhClient); err != nil {
+		return err
+	}
+
 	return checkEngineUpgradePath(namespace, lhClient, emeta.GetVersion())
 }
 
+// checkUpgradeCompatibility returns error if the manager of the current version has found the cluster not compatible
+// for the upgrade. The result is recorded as the UpgradeCompatible condition of the current-longhorn-version setting.
+func checkUpgradeCompatibility(namespace string, lhClient lhclientset.Interface) error {
+	currentLHVersionSetting, err := lhClient.LonghornV1beta2().Settings(namespace).Get(context.TODO(), string(types.SettingNameCurrentLonghornVersion), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	// The condition is only checked when upgrading, so that the manager of the current version can still restart
+	// and refresh the condition once the problems are fixed
+	lhCurrentVersion := currentLHVersionSetting.Value
+	if lhCurrentVersion == "" || semver.Compare(lhCurrentVersion, meta.Version) >= 0 {
+		return nil
+	}
+
+	condition := types.GetCondition(currentLHVersionSetting.Status.Conditions, longhorn.SettingConditionTypeUpgradeCompatible)
+	if condition.Status == longhorn.ConditionStatusFalse {
+		return fmt.Errorf("failed to upgrade from %v to %v since the cluster is not compatible for the upgrade: %v: %v",
+			lhCurrentVersion, meta.Version, condition.Reason, condition.Message)
+	}
+
+	return checkSettingsCompatibility(namespace, lhClient, lhCurrentVersion)
+}
+
+// checkSettingsCompatibility returns error if the values of the existing settings aren't supported by the upgrade
+// version. The settings that aren't recognized are skipped, since they are deleted during the upgrade.
+func checkSettingsCompatibility(namespace string, lhClient lhclientset.Interface, lhCurrentVersion string) error {
+	settings, err := lhClient.LonghornV1beta2().Settings(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	problems := []string{}
+	for _, setting := range settings.Items {
+		if _, ok := types.GetSettingDefinition(types.SettingName(setting.Name)); !ok {
+			continue
+		}
+		if err := types.ValidateSetting(setting.Name, setting.Value); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("failed to upgrade from %v to %v since the settings are not supported by the upgrade version: %v",
+			lhCurrentVersion, meta.Version, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
 // checkLHUpgradePath returns if the upgrade path from lhCurrentVersion to meta.Version is supported.
 //
 //	For example: upgrade path is from x.y.z to a.b.c,
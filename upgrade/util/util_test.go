@@ -193,6 +193,108 @@ func newCheckLHUpgradePathSupported(lhClient lhclientset.Interface) error {
 	return checkLHUpgradePath(TestNamespace, lhClient)
 }
 
+func TestCheckUpgradeCompatibility(t *testing.T) {
+	testCases := []struct {
+		name            string
+		currentVersion  string
+		upgradeVersion  string
+		conditionStatus longhorn.ConditionStatus
+		settings        map[string]string
+		expectError     bool
+	}{
+		{
+			name:            "compatible",
+			currentVersion:  "v1.8.0",
+			upgradeVersion:  "v1.9.0",
+			conditionStatus: longhorn.ConditionStatusTrue,
+			expectError:     false,
+		},
+		{
+			name:            "not checked yet",
+			currentVersion:  "v1.8.0",
+			upgradeVersion:  "v1.9.0",
+			conditionStatus: "",
+			expectError:     false,
+		},
+		{
+			name:            "incompatible",
+			currentVersion:  "v1.8.0",
+			upgradeVersion:  "v1.9.0",
+			conditionStatus: longhorn.ConditionStatusFalse,
+			expectError:     true,
+		},
+		{
+			name:            "unsupported setting value",
+			currentVersion:  "v1.8.0",
+			upgradeVersion:  "v1.9.0",
+			conditionStatus: longhorn.ConditionStatusTrue,
+			settings:        map[string]string{string(types.SettingNameOrphanBackingImageAutoDeletion): "invalid"},
+			expectError:     true,
+		},
+		{
+			name:            "unrecognized setting",
+			currentVersion:  "v1.8.0",
+			upgradeVersion:  "v1.9.0",
+			conditionStatus: longhorn.ConditionStatusTrue,
+			settings:        map[string]string{"deprecated-setting": "invalid"},
+			expectError:     false,
+		},
+		{
+			name:            "unsupported setting value without upgrade",
+			currentVersion:  "v1.9.0",
+			upgradeVersion:  "v1.9.0",
+			conditionStatus: longhorn.ConditionStatusTrue,
+			settings:        map[string]string{string(types.SettingNameOrphanBackingImageAutoDeletion): "invalid"},
+			expectError:     false,
+		},
+		{
+			name:            "incompatible without upgrade",
+			currentVersion:  "v1.9.0",
+			upgradeVersion:  "v1.9.0",
+			conditionStatus: longhorn.ConditionStatusFalse,
+			expectError:     false,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			lhClient := lhfake.NewSimpleClientset()
+			setting := &longhorn.Setting{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: string(types.SettingNameCurrentLonghornVersion),
+				},
+				Value: tt.currentVersion,
+			}
+			if tt.conditionStatus != "" {
+				setting.Status.Conditions = types.SetCondition(nil, longhorn.SettingConditionTypeUpgradeCompatible,
+					tt.conditionStatus, longhorn.SettingConditionReasonIncompatibleEngineImage, "")
+			}
+			_, err := lhClient.LonghornV1beta2().Settings(TestNamespace).Create(context.TODO(), setting, metav1.CreateOptions{})
+			assert.Nil(err)
+			for name, value := range tt.settings {
+				setting := &longhorn.Setting{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: name,
+					},
+					Value: value,
+				}
+				_, err := lhClient.LonghornV1beta2().Settings(TestNamespace).Create(context.TODO(), setting, metav1.CreateOptions{})
+				assert.Nil(err)
+			}
+
+			meta.Version = tt.upgradeVersion
+			err = checkUpgradeCompatibility(TestNamespace, lhClient)
+			if tt.expectError {
+				assert.NotNil(err)
+			} else {
+				assert.Nil(err)
+			}
+		})
+	}
+}
+
 func Test(t *testing.T) { TestingT(t) }
 
 type TestSuite struct {
@@ -0,0 +1,226 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	replicaDataVolumeMetaFile      = "volume.meta"
+	replicaDataRevisionCounterFile = "revision.counter"
+	replicaDataImageMetaSuffix     = ".meta"
+)
+
+// ReplicaDataImageMeta is the metadata of a snapshot or the volume head image in a replica data directory
+type ReplicaDataImageMeta struct {
+	Name        string
+	Parent      string
+	Removed     bool
+	UserCreated bool
+	Created     string
+}
+
+// ReplicaDataChain is the validated snapshot chain of a replica data directory. The data of the volume is the
+// merge of the images, where the data of an image overrides the data of its parents.
+type ReplicaDataChain struct {
+	Directory string
+	Size      int64
+	// Images are the image file names from the oldest snapshot to the volume head
+	Images          []string
+	HeadModifiedAt  time.Time
+	RevisionCounter int64
+}
+
+// LoadReplicaDataChain reads and validates the snapshot chain of the replica data directory, without relying on
+// any state of the cluster the replica belonged to.
+func LoadReplicaDataChain(dir string) (chain *ReplicaDataChain, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "invalid replica data directory %v", dir)
+	}()
+
+	meta, err := readReplicaDataVolumeMeta(dir)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Rebuilding {
+		return nil, fmt.Errorf("replica was rebuilding")
+	}
+	if meta.BackingFilePath != "" {
+		return nil, fmt.Errorf("replica is based on backing file %v, which is not supported", meta.BackingFilePath)
+	}
+	if meta.Size <= 0 {
+		return nil, fmt.Errorf("invalid volume size %v", meta.Size)
+	}
+	if meta.Head == "" {
+		return nil, fmt.Errorf("volume head is missing")
+	}
+
+	images := []string{}
+	visited := map[string]bool{}
+	for image := meta.Head; image != ""; {
+		if visited[image] {
+			return nil, fmt.Errorf("snapshot chain has a loop at %v", image)
+		}
+		visited[image] = true
+
+		info, err := os.Stat(filepath.Join(dir, image))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to find image %v", image)
+		}
+		if info.Size() > meta.Size {
+			return nil, fmt.Errorf("image %v size %v is larger than volume size %v", image, info.Size(), meta.Size)
+		}
+		imageMeta, err := readReplicaDataImageMeta(dir, image)
+		if err != nil {
+			return nil, err
+		}
+
+		images = append([]string{image}, images...)
+		image = imageMeta.Parent
+	}
+
+	headInfo, err := os.Stat(filepath.Join(dir, meta.Head))
+	if err != nil {
+		return nil, err
+	}
+	revisionCounter, err := readReplicaDataRevisionCounter(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReplicaDataChain{
+		Directory:       dir,
+		Size:            meta.Size,
+		Images:          images,
+		HeadModifiedAt:  headInfo.ModTime(),
+		RevisionCounter: revisionCounter,
+	}, nil
+}
+
+// PickReplicaDataChain validates the replica data directories of a volume and picks the one with the latest data,
+// the same way as the salvage of the volume: by the revision counter if it's enabled, otherwise by the last
+// modification of the volume head. The invalid directories are skipped.
+func PickReplicaDataChain(dirs []string) (*ReplicaDataChain, error) {
+	var picked *ReplicaDataChain
+	for _, dir := range dirs {
+		chain, err := LoadReplicaDataChain(dir)
+		if err != nil {
+			logrus.WithError(err).Warnf("Skipped replica data directory %v", dir)
+			continue
+		}
+		if picked != nil && picked.Size != chain.Size {
+			return nil, fmt.Errorf("replica data directories %v and %v have different volume sizes %v and %v",
+				picked.Directory, dir, picked.Size, chain.Size)
+		}
+		if picked == nil || isReplicaDataChainNewer(chain, picked) {
+			picked = chain
+		}
+	}
+	if picked == nil {
+		return nil, fmt.Errorf("no valid replica data directory in %v", strings.Join(dirs, ", "))
+	}
+	return picked, nil
+}
+
+func isReplicaDataChainNewer(chain, other *ReplicaDataChain) bool {
+	if chain.RevisionCounter != other.RevisionCounter {
+		return chain.RevisionCounter > other.RevisionCounter
+	}
+	return chain.HeadModifiedAt.After(other.HeadModifiedAt)
+}
+
+// WriteTo writes the merged data of the chain to the file or the block device at the same offsets. Only the data
+// extents of the sparse images are copied, so the holes of an exported file stay unallocated.
+func (c *ReplicaDataChain) WriteTo(dst *os.File) error {
+	for _, image := range c.Images {
+		if err := copyDataExtents(filepath.Join(c.Directory, image), dst); err != nil {
+			return errors.Wrapf(err, "failed to copy data of image %v", image)
+		}
+	}
+	return nil
+}
+
+func copyDataExtents(path string, dst *os.File) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	for offset := int64(0); offset < info.Size(); {
+		dataStart, err := unix.Seek(int(src.Fd()), offset, unix.SEEK_DATA)
+		if err != nil {
+			if errors.Is(err, unix.ENXIO) {
+				// No more data after the offset
+				return nil
+			}
+			return err
+		}
+		dataEnd, err := unix.Seek(int(src.Fd()), dataStart, unix.SEEK_HOLE)
+		if err != nil {
+			return err
+		}
+
+		section := io.NewSectionReader(src, dataStart, dataEnd-dataStart)
+		if _, err := io.Copy(io.NewOffsetWriter(dst, dataStart), section); err != nil {
+			return err
+		}
+		offset = dataEnd
+	}
+	return nil
+}
+
+func readReplicaDataVolumeMeta(dir string) (*VolumeMeta, error) {
+	content, err := os.ReadFile(filepath.Join(dir, replicaDataVolumeMetaFile))
+	if err != nil {
+		return nil, err
+	}
+	meta := &VolumeMeta{}
+	if err := json.Unmarshal(content, meta); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %v", replicaDataVolumeMetaFile)
+	}
+	return meta, nil
+}
+
+func readReplicaDataImageMeta(dir, image string) (*ReplicaDataImageMeta, error) {
+	content, err := os.ReadFile(filepath.Join(dir, image+replicaDataImageMetaSuffix))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find metadata of image %v", image)
+	}
+	meta := &ReplicaDataImageMeta{}
+	if err := json.Unmarshal(content, meta); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse metadata of image %v", image)
+	}
+	return meta, nil
+}
+
+// readReplicaDataRevisionCounter returns 0 if the revision counter is disabled for the replica
+func readReplicaDataRevisionCounter(dir string) (int64, error) {
+	content, err := os.ReadFile(filepath.Join(dir, replicaDataRevisionCounterFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	counter, err := strconv.ParseInt(strings.TrimSpace(strings.TrimRight(string(content), "\x00")), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse %v", replicaDataRevisionCounterFile)
+	}
+	return counter, nil
+}
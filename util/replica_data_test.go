@@ -0,0 +1,89 @@
+package util
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeReplicaDataDirectory(t *testing.T, dir string, size int64, images map[string]map[int64]string, chain []string, revisionCounter string) {
+	assert := require.New(t)
+
+	for i, image := range chain {
+		f, err := os.Create(filepath.Join(dir, image))
+		assert.NoError(err)
+		assert.NoError(f.Truncate(size))
+		for offset, data := range images[image] {
+			_, err := f.WriteAt([]byte(data), offset)
+			assert.NoError(err)
+		}
+		assert.NoError(f.Close())
+
+		parent := ""
+		if i > 0 {
+			parent = chain[i-1]
+		}
+		content, err := json.Marshal(ReplicaDataImageMeta{Name: image, Parent: parent})
+		assert.NoError(err)
+		assert.NoError(os.WriteFile(filepath.Join(dir, image+replicaDataImageMetaSuffix), content, 0644))
+	}
+
+	content, err := json.Marshal(VolumeMeta{Size: size, Head: chain[len(chain)-1], Parent: chain[len(chain)-2]})
+	assert.NoError(err)
+	assert.NoError(os.WriteFile(filepath.Join(dir, replicaDataVolumeMetaFile), content, 0644))
+
+	if revisionCounter != "" {
+		assert.NoError(os.WriteFile(filepath.Join(dir, replicaDataRevisionCounterFile), []byte(revisionCounter), 0644))
+	}
+}
+
+func TestReplicaDataChain(t *testing.T) {
+	assert := require.New(t)
+
+	size := int64(1 << 20)
+	chain := []string{"volume-snap-1.img", "volume-snap-2.img", "volume-head-003.img"}
+	images := map[string]map[int64]string{
+		"volume-snap-1.img":   {0: "aaaa", 8192: "bbbb"},
+		"volume-snap-2.img":   {8192: "cccc", 65536: "dddd"},
+		"volume-head-003.img": {0: "eeee"},
+	}
+
+	oldDir := t.TempDir()
+	writeReplicaDataDirectory(t, oldDir, size, map[string]map[int64]string{}, chain, "5")
+	newDir := t.TempDir()
+	writeReplicaDataDirectory(t, newDir, size, images, chain, "10\x00\x00")
+	invalidDir := t.TempDir()
+	writeReplicaDataDirectory(t, invalidDir, size, images, chain, "")
+	assert.NoError(os.Remove(filepath.Join(invalidDir, "volume-snap-2.img")))
+
+	_, err := LoadReplicaDataChain(invalidDir)
+	assert.Error(err)
+
+	// The directory with the largest revision counter is picked, and the invalid one is skipped
+	picked, err := PickReplicaDataChain([]string{oldDir, invalidDir, newDir})
+	assert.NoError(err)
+	assert.Equal(newDir, picked.Directory)
+	assert.Equal(chain, picked.Images)
+	assert.Equal(int64(10), picked.RevisionCounter)
+
+	_, err = PickReplicaDataChain([]string{invalidDir})
+	assert.Error(err)
+
+	output, err := os.Create(filepath.Join(t.TempDir(), "volume.raw"))
+	assert.NoError(err)
+	defer output.Close()
+	assert.NoError(output.Truncate(picked.Size))
+	assert.NoError(picked.WriteTo(output))
+
+	// The data of the newer images overrides the data of the older ones
+	expected := map[int64]string{0: "eeee", 8192: "cccc", 65536: "dddd"}
+	for offset, data := range expected {
+		buf := make([]byte, len(data))
+		_, err := output.ReadAt(buf, offset)
+		assert.NoError(err)
+		assert.Equal(data, string(buf))
+	}
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"crypto/sha256"
 	"crypto/sha512"
+	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -41,10 +42,13 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientset "k8s.io/client-go/kubernetes"
 
+	lhexec "github.com/longhorn/go-common-libs/exec"
 	lhio "github.com/longhorn/go-common-libs/io"
 	lhns "github.com/longhorn/go-common-libs/ns"
+	lhproc "github.com/longhorn/go-common-libs/proc"
 	lhtypes "github.com/longhorn/go-common-libs/types"
 
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
@@ -67,6 +71,9 @@ const (
 	EncryptedDeviceDirectory     = "/dev/mapper/"
 	TemporaryMountPointDirectory = "/tmp/mnt/"
 
+	ShareManagerProcessName = "longhorn-share-manager"
+	BinaryFsfreeze          = "fsfreeze"
+
 	DefaultKubernetesTolerationKey = "kubernetes.io"
 
 	DiskConfigFile = "longhorn-disk.cfg"
@@ -211,7 +218,7 @@ func GetLocalIPs() ([]string, error) {
 	}
 	for _, addr := range addrs {
 		if ip, ok := addr.(*net.IPNet); ok && !ip.IP.IsLoopback() {
-			if ip.IP.To4() != nil {
+			if ip.IP.To4() != nil || ip.IP.IsGlobalUnicast() {
 				results = append(results, ip.IP.String())
 			}
 		}
@@ -219,6 +226,52 @@ func GetLocalIPs() ([]string, error) {
 	return results, nil
 }
 
+// IsIPv6 returns true if the given string is an IPv6 address. IPv4-mapped
+// IPv6 addresses are considered as IPv4.
+func IsIPv6(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && parsed.To4() == nil
+}
+
+// GetIPByFamily returns the first IP of the given family in ips, or an empty
+// string if there is none.
+func GetIPByFamily(ips []string, ipv6 bool) string {
+	for _, ip := range ips {
+		if net.ParseIP(ip) == nil {
+			continue
+		}
+		if IsIPv6(ip) == ipv6 {
+			return ip
+		}
+	}
+	return ""
+}
+
+// GetUnspecifiedIP returns the address for a server to listen on all the
+// interfaces of the given IP family.
+func GetUnspecifiedIP(ipv6 bool) string {
+	if ipv6 {
+		return net.IPv6unspecified.String()
+	}
+	return net.IPv4zero.String()
+}
+
+// GetFIPSTLSConfig returns the TLS config negotiating only TLS 1.2 or later with the cipher suites and curves
+// approved by FIPS 140-3. The TLS 1.3 cipher suites are not configurable, they are restricted by the FIPS 140-3 Go
+// cryptographic module instead.
+func GetFIPSTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		},
+		CurvePreferences: []tls.CurveID{tls.CurveP256, tls.CurveP384},
+	}
+}
+
 // WaitForAPI timeout in second
 func WaitForAPI(url string, timeout int) error {
 	for i := 0; i < timeout; i++ {
@@ -422,6 +475,24 @@ func GetStringHash(data string) string {
 	return fmt.Sprint(strconv.FormatInt(int64(hash.Sum32()), 16))
 }
 
+// GetConsistentHashMember picks the member for the key by rendezvous hashing, which is a consistent hashing. When a
+// member joins or leaves, only the keys picked by the member are moved to or from it. It returns empty if there is
+// no member.
+func GetConsistentHashMember(key string, members []string) string {
+	picked := ""
+	var pickedWeight uint64
+	for _, member := range members {
+		hash := fnv.New64a()
+		hash.Write([]byte(key + "/" + member))
+		weight := hash.Sum64()
+		if picked == "" || weight > pickedWeight || (weight == pickedWeight && member < picked) {
+			picked = member
+			pickedWeight = weight
+		}
+	}
+	return picked
+}
+
 func CheckBackupType(backupTarget string) (string, error) {
 	u, err := url.Parse(backupTarget)
 	if err != nil {
@@ -610,6 +681,23 @@ func GetDistinctTolerations(tolerationList []corev1.Toleration) []corev1.Tolerat
 	return res
 }
 
+// GetTopologySpreadConstraintsWithDefaultSelector returns a copy of the topology spread constraints, in which the
+// constraints without a label selector select the pods with the given labels.
+func GetTopologySpreadConstraintsWithDefaultSelector(constraints []corev1.TopologySpreadConstraint, matchLabels map[string]string) []corev1.TopologySpreadConstraint {
+	if len(constraints) == 0 {
+		return nil
+	}
+	res := []corev1.TopologySpreadConstraint{}
+	for _, constraint := range constraints {
+		constraint = *constraint.DeepCopy()
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{MatchLabels: matchLabels}
+		}
+		res = append(res, constraint)
+	}
+	return res
+}
+
 func TolerationListToMap(tolerationList []corev1.Toleration) map[string]corev1.Toleration {
 	res := map[string]corev1.Toleration{}
 	for _, t := range tolerationList {
@@ -696,6 +784,30 @@ func GetPossibleReplicaDirectoryNames(diskPath string) (replicaDirectoryNames ma
 	return replicaDirectoryNames, nil
 }
 
+func GetPossibleBackingImageDirectoryNames(diskPath string) (backingImageDirectoryNames map[string]string, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "cannot list backing image directories in the disk %v", diskPath)
+	}()
+
+	backingImageDirectoryNames = make(map[string]string, 0)
+	path := filepath.Join(diskPath, "backing-images")
+
+	files, err := lhns.ReadDirectory(path)
+	if err != nil {
+		return backingImageDirectoryNames, err
+	}
+
+	pattern := regexp.MustCompile(`.*-[a-zA-Z0-9]{8}$`)
+
+	for _, file := range files {
+		if file.IsDir() && pattern.MatchString(file.Name()) {
+			backingImageDirectoryNames[file.Name()] = ""
+		}
+	}
+
+	return backingImageDirectoryNames, nil
+}
+
 type VolumeMeta struct {
 	Size            int64
 	Head            string
@@ -732,7 +844,8 @@ func GetPodIP(pod *corev1.Pod) (string, error) {
 	return pod.Status.PodIP, nil
 }
 
-func TrimFilesystem(volumeName string, encryptedDevice bool) error {
+// TrimFilesystem trims the filesystem of the volume mounted on the host, killing fstrim once the timeout is reached.
+func TrimFilesystem(volumeName string, encryptedDevice bool, timeout time.Duration) error {
 	var err error
 	defer func() {
 		err = errors.Wrapf(err, "failed to trim filesystem for Volume %v", volumeName)
@@ -749,7 +862,7 @@ func TrimFilesystem(volumeName string, encryptedDevice bool) error {
 		return err
 	}
 
-	_, err = nsexec.Execute(nil, lhtypes.BinaryFstrim, []string{validMountpoint}, time.Hour)
+	_, err = nsexec.Execute(nil, lhtypes.BinaryFstrim, []string{validMountpoint}, timeout)
 	if err != nil {
 		return errors.Wrapf(err, "cannot find volume %v mount info on host", volumeName)
 	}
@@ -757,6 +870,78 @@ func TrimFilesystem(volumeName string, encryptedDevice bool) error {
 	return nil
 }
 
+// GetFilesystemUsage returns the total and available space of the filesystem of the volume mounted on the host.
+func GetFilesystemUsage(volumeName string, encryptedDevice bool) (total, available int64, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "failed to get filesystem usage for volume %v", volumeName)
+	}()
+
+	validMountpoint, err := getValidMountPoint(volumeName, lhtypes.HostProcDirectory, encryptedDevice)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	diskStat, err := lhns.GetDiskStat(validMountpoint)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return diskStat.StorageMaximum, diskStat.StorageAvailable, nil
+}
+
+// FreezeShareManagerFilesystem flushes and freezes the filesystem of a RWX volume before its snapshot is taken. The
+// filesystem is mounted by the share manager in the mount namespace of its pod rather than the host one, where the
+// engine looks for the filesystem to freeze. It returns the function to thaw the filesystem.
+func FreezeShareManagerFilesystem(volumeName string, encryptedDevice bool) (thaw func() error, err error) {
+	defer func() {
+		err = errors.Wrapf(err, "failed to freeze filesystem of share manager for volume %v", volumeName)
+	}()
+
+	devicePath := RegularDeviceDirectory + volumeName
+	if encryptedDevice {
+		devicePath = EncryptedDeviceDirectory + volumeName
+	}
+
+	pids, err := lhproc.GetProcessPIDs(ShareManagerProcessName, lhtypes.HostProcDirectory)
+	if err != nil {
+		return nil, err
+	}
+	for _, pid := range pids {
+		pidDir := filepath.Join(lhtypes.HostProcDirectory, strconv.FormatUint(pid, 10))
+		mountPoint, err := getDeviceMountPoint(filepath.Join(pidDir, "mounts"), devicePath)
+		if err != nil {
+			continue
+		}
+
+		executor := lhexec.NewExecutor()
+		nsenterArgs := []string{"--mount=" + filepath.Join(pidDir, "ns", lhtypes.NamespaceMnt.String()), BinaryFsfreeze}
+		// fsfreeze flushes the dirty data of the filesystem before blocking the writes
+		if _, err := executor.Execute(nil, lhtypes.NsBinary, append(nsenterArgs, "--freeze", mountPoint), lhtypes.ExecuteDefaultTimeout); err != nil {
+			return nil, err
+		}
+		return func() error {
+			_, err := executor.Execute(nil, lhtypes.NsBinary, append(nsenterArgs, "--unfreeze", mountPoint), lhtypes.ExecuteDefaultTimeout)
+			return errors.Wrapf(err, "failed to thaw filesystem of share manager for volume %v", volumeName)
+		}, nil
+	}
+	return nil, fmt.Errorf("failed to find the mount point of device %v in share manager", devicePath)
+}
+
+// getDeviceMountPoint returns the mount point of the device in the mounts file of a process
+func getDeviceMountPoint(mountsPath, devicePath string) (string, error) {
+	content, err := lhio.ReadFileContent(mountsPath)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == devicePath {
+			return fields[1], nil
+		}
+	}
+	return "", fmt.Errorf("device %v is not mounted", devicePath)
+}
+
 func getValidMountPoint(volumeName, procDir string, encryptedDevice bool) (string, error) {
 	procMountsPath := filepath.Join(procDir, "1", "mounts")
 	content, err := lhio.ReadFileContent(procMountsPath)
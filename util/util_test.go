@@ -80,6 +80,34 @@ func TestDeterministicUUID(t *testing.T) {
 	assert.Equal(DeterministicUUID(dataUsedToGenerate), DeterministicUUID(dataUsedToGenerate))
 }
 
+func TestIsIPv6(t *testing.T) {
+	assert := require.New(t)
+
+	assert.False(IsIPv6("10.42.0.12"))
+	assert.False(IsIPv6("::ffff:10.42.0.12"))
+	assert.True(IsIPv6("fd00:10:42::c"))
+	assert.False(IsIPv6("not-an-ip"))
+	assert.False(IsIPv6(""))
+}
+
+func TestGetIPByFamily(t *testing.T) {
+	assert := require.New(t)
+
+	ips := []string{"10.42.0.12", "fd00:10:42::c"}
+	assert.Equal("10.42.0.12", GetIPByFamily(ips, false))
+	assert.Equal("fd00:10:42::c", GetIPByFamily(ips, true))
+	assert.Equal("", GetIPByFamily([]string{"10.42.0.12"}, true))
+	assert.Equal("", GetIPByFamily([]string{"invalid", "fd00:10:42::c"}, false))
+	assert.Equal("", GetIPByFamily(nil, false))
+}
+
+func TestGetUnspecifiedIP(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("0.0.0.0", GetUnspecifiedIP(false))
+	assert.Equal("::", GetUnspecifiedIP(true))
+}
+
 func (s *TestSuite) TestGetValidMountPoint(c *C) {
 	// Check if the /host/proc directory exists in container
 	if _, err := os.Stat(lhtypes.HostProcDirectory); os.IsNotExist(err) {
@@ -184,6 +212,52 @@ func (s *TestSuite) TestGetValidMountPoint(c *C) {
 	}
 }
 
+func TestGetConsistentHashMember(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("", GetConsistentHashMember("volume", nil))
+
+	members := []string{"node-1", "node-2", "node-3", "node-4"}
+	picked := map[string]string{}
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("volume-%d", i)
+		picked[key] = GetConsistentHashMember(key, members)
+		assert.Contains(members, picked[key])
+		// The order of the members doesn't matter
+		assert.Equal(picked[key], GetConsistentHashMember(key, []string{"node-4", "node-3", "node-2", "node-1"}))
+	}
+
+	// Only the keys of the leaving member are moved
+	for key, member := range picked {
+		newMember := GetConsistentHashMember(key, members[:3])
+		if member != "node-4" {
+			assert.Equal(member, newMember)
+		} else {
+			assert.NotEqual("node-4", newMember)
+		}
+	}
+}
+
+func TestGetDeviceMountPoint(t *testing.T) {
+	assert := require.New(t)
+
+	mountsPath := filepath.Join(t.TempDir(), "mounts")
+	content := "overlay / overlay rw,relatime 0 0\n" +
+		"/dev/longhorn/volume-10 /export/volume-10 ext4 rw,relatime 0 0\n" +
+		"/dev/longhorn/volume-1 /export/volume-1 ext4 rw,relatime 0 0\n"
+	assert.NoError(os.WriteFile(mountsPath, []byte(content), 0644))
+
+	mountPoint, err := getDeviceMountPoint(mountsPath, "/dev/longhorn/volume-1")
+	assert.NoError(err)
+	assert.Equal("/export/volume-1", mountPoint)
+
+	_, err = getDeviceMountPoint(mountsPath, "/dev/mapper/volume-1")
+	assert.Error(err)
+
+	_, err = getDeviceMountPoint(filepath.Join(t.TempDir(), "missing"), "/dev/longhorn/volume-1")
+	assert.Error(err)
+}
+
 func TestTimestampAfterTimestamp(t *testing.T) {
 	tests := map[string]struct {
 		timestamp1 string
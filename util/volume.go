@@ -14,3 +14,10 @@ func IsVolumeMigrating(v *longhorn.Volume) bool {
 func IsMigratableVolume(v *longhorn.Volume) bool {
 	return v.Spec.Migratable && v.Spec.AccessMode == longhorn.AccessModeReadWriteMany
 }
+
+// IsRWXBlockVolume returns true for a ReadWriteMany volume exported to the nodes as a raw block device over iSCSI
+// by its engine, instead of being shared over NFS by a share manager.
+func IsRWXBlockVolume(v *longhorn.Volume) bool {
+	return v.Spec.AccessMode == longhorn.AccessModeReadWriteMany && !v.Spec.Migratable &&
+		v.Spec.Frontend == longhorn.VolumeFrontendISCSI
+}
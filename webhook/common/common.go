@@ -6,6 +6,8 @@ import (
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/longhorn/longhorn-manager/datastore"
@@ -109,6 +111,38 @@ func ValidateRequiredDataEngineEnabled(ds *datastore.DataStore, dataEngine longh
 	return nil
 }
 
+// GetInstanceSpecDefaultPatchOps returns the patches filling the unset fields of the spec of an engine or a replica
+// created directly rather than by the volume controller, from the spec of its volume. The spec is updated with the
+// default values as well.
+func GetInstanceSpecDefaultPatchOps(ds *datastore.DataStore, spec *longhorn.InstanceSpec) ([]string, error) {
+	if spec.VolumeName == "" {
+		return nil, nil
+	}
+	volume, err := ds.GetVolumeRO(spec.VolumeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		err = errors.Wrapf(err, "failed to get volume %v", spec.VolumeName)
+		return nil, werror.NewInternalError(err.Error())
+	}
+
+	var patchOps []string
+	if spec.DataEngine == "" && volume.Spec.DataEngine != "" {
+		spec.DataEngine = volume.Spec.DataEngine
+		patchOps = append(patchOps, fmt.Sprintf(`{"op": "replace", "path": "/spec/dataEngine", "value": "%s"}`, spec.DataEngine))
+	}
+	if spec.Image == "" && volume.Spec.Image != "" {
+		spec.Image = volume.Spec.Image
+		patchOps = append(patchOps, fmt.Sprintf(`{"op": "replace", "path": "/spec/image", "value": "%s"}`, spec.Image))
+	}
+	if spec.VolumeSize == 0 && volume.Spec.Size != 0 {
+		spec.VolumeSize = volume.Spec.Size
+		patchOps = append(patchOps, fmt.Sprintf(`{"op": "replace", "path": "/spec/volumeSize", "value": "%v"}`, spec.VolumeSize))
+	}
+	return patchOps, nil
+}
+
 func IsRemovingLonghornFinalizer(oldObj runtime.Object, newObj runtime.Object) (bool, error) {
 	oldMeta, err := meta.Accessor(oldObj)
 	if err != nil {
@@ -41,7 +41,22 @@ func (e *engineMutator) Resource() admission.Resource {
 }
 
 func (e *engineMutator) Create(request *admission.Request, newObj runtime.Object) (admission.PatchOps, error) {
-	return mutate(newObj)
+	engine, ok := newObj.(*longhorn.Engine)
+	if !ok {
+		return nil, werror.NewInvalidError(fmt.Sprintf("%v is not a *longhorn.Engine", newObj), "")
+	}
+
+	// The fields of a engine created directly are filled from its volume, the same as the volume controller does
+	patchOps, err := common.GetInstanceSpecDefaultPatchOps(e.ds, &engine.Spec.InstanceSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	patchOpsInCommon, err := mutate(newObj)
+	if err != nil {
+		return nil, err
+	}
+	return append(patchOps, patchOpsInCommon...), nil
 }
 
 func (e *engineMutator) Update(request *admission.Request, oldObj runtime.Object, newObj runtime.Object) (admission.PatchOps, error) {
@@ -10,6 +10,7 @@ import (
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 
 	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/util"
 	"github.com/longhorn/longhorn-manager/webhook/admission"
 
 	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
@@ -90,12 +91,15 @@ func (e *engineValidator) validateNumberOfEngines(newEngine *longhorn.Engine, vo
 		return werror.NewInternalError(err.Error())
 	}
 
+	// a RWO volume has the migration engine as well when it's live migrated
+	migratable := volume.Spec.Migratable || util.IsVolumeMigrating(volume)
+
 	newNumVolumeEngines := len(volumeEngines) + 1
-	if volume.Spec.Migratable && newNumVolumeEngines > 2 {
+	if migratable && newNumVolumeEngines > 2 {
 		message := fmt.Sprintf("engine creation would result in %d engines for migratable volume", newNumVolumeEngines)
 		return werror.NewInvalidError(message, "")
 	}
-	if !volume.Spec.Migratable && newNumVolumeEngines > 1 {
+	if !migratable && newNumVolumeEngines > 1 {
 		message := fmt.Sprintf("engine creation would result in %d engines for non-migratable volume", newNumVolumeEngines)
 		return werror.NewInvalidError(message, "")
 	}
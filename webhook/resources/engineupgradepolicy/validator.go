@@ -0,0 +1,81 @@
+package engineupgradepolicy
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/webhook/admission"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	werror "github.com/longhorn/longhorn-manager/webhook/error"
+)
+
+type engineUpgradePolicyValidator struct {
+	admission.DefaultValidator
+	ds *datastore.DataStore
+}
+
+func NewValidator(ds *datastore.DataStore) admission.Validator {
+	return &engineUpgradePolicyValidator{ds: ds}
+}
+
+func (e *engineUpgradePolicyValidator) Resource() admission.Resource {
+	return admission.Resource{
+		Name:       "engineupgradepolicies",
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   longhorn.SchemeGroupVersion.Group,
+		APIVersion: longhorn.SchemeGroupVersion.Version,
+		ObjectType: &longhorn.EngineUpgradePolicy{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (e *engineUpgradePolicyValidator) Create(request *admission.Request, newObj runtime.Object) error {
+	policy, ok := newObj.(*longhorn.EngineUpgradePolicy)
+	if !ok {
+		return werror.NewInvalidError(fmt.Sprintf("%v is not a *longhorn.EngineUpgradePolicy", newObj), "")
+	}
+
+	if err := validateEngineUpgradePolicySpec(&policy.Spec); err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("invalid engine upgrade policy %v: %v", policy.Name, err), "")
+	}
+	return nil
+}
+
+func (e *engineUpgradePolicyValidator) Update(request *admission.Request, oldObj runtime.Object, newObj runtime.Object) error {
+	policy, ok := newObj.(*longhorn.EngineUpgradePolicy)
+	if !ok {
+		return werror.NewInvalidError(fmt.Sprintf("%v is not a *longhorn.EngineUpgradePolicy", newObj), "")
+	}
+
+	if err := validateEngineUpgradePolicySpec(&policy.Spec); err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("invalid engine upgrade policy %v: %v", policy.Name, err), "")
+	}
+	return nil
+}
+
+func validateEngineUpgradePolicySpec(spec *longhorn.EngineUpgradePolicySpec) error {
+	if spec.MaxConcurrent < 1 {
+		return fmt.Errorf("max concurrent %v should be at least 1", spec.MaxConcurrent)
+	}
+	if spec.MaintenanceWindow == nil {
+		return nil
+	}
+	if _, err := cron.ParseStandard(spec.MaintenanceWindow.Cron); err != nil {
+		return errors.Wrapf(err, "invalid maintenance window cron %v", spec.MaintenanceWindow.Cron)
+	}
+	if spec.MaintenanceWindow.Duration < 1 {
+		return fmt.Errorf("maintenance window duration %v should be at least 1 minute", spec.MaintenanceWindow.Duration)
+	}
+	return nil
+}
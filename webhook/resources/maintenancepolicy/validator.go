@@ -0,0 +1,114 @@
+package maintenancepolicy
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/webhook/admission"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	werror "github.com/longhorn/longhorn-manager/webhook/error"
+)
+
+type maintenancePolicyValidator struct {
+	admission.DefaultValidator
+	ds *datastore.DataStore
+}
+
+func NewValidator(ds *datastore.DataStore) admission.Validator {
+	return &maintenancePolicyValidator{ds: ds}
+}
+
+func (m *maintenancePolicyValidator) Resource() admission.Resource {
+	return admission.Resource{
+		Name:       "maintenancepolicies",
+		Scope:      admissionregv1.NamespacedScope,
+		APIGroup:   longhorn.SchemeGroupVersion.Group,
+		APIVersion: longhorn.SchemeGroupVersion.Version,
+		ObjectType: &longhorn.MaintenancePolicy{},
+		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
+			admissionregv1.Update,
+		},
+	}
+}
+
+func (m *maintenancePolicyValidator) Create(request *admission.Request, newObj runtime.Object) error {
+	policy, ok := newObj.(*longhorn.MaintenancePolicy)
+	if !ok {
+		return werror.NewInvalidError(fmt.Sprintf("%v is not a *longhorn.MaintenancePolicy", newObj), "")
+	}
+
+	if err := validateMaintenancePolicySpec(&policy.Spec); err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("invalid maintenance policy %v: %v", policy.Name, err), "")
+	}
+	return nil
+}
+
+func (m *maintenancePolicyValidator) Update(request *admission.Request, oldObj runtime.Object, newObj runtime.Object) error {
+	policy, ok := newObj.(*longhorn.MaintenancePolicy)
+	if !ok {
+		return werror.NewInvalidError(fmt.Sprintf("%v is not a *longhorn.MaintenancePolicy", newObj), "")
+	}
+
+	if err := validateMaintenancePolicySpec(&policy.Spec); err != nil {
+		return werror.NewInvalidError(fmt.Sprintf("invalid maintenance policy %v: %v", policy.Name, err), "")
+	}
+	return nil
+}
+
+func validateMaintenancePolicySpec(spec *longhorn.MaintenancePolicySpec) error {
+	switch spec.ResourceType {
+	case longhorn.MaintenancePolicyResourceTypeVolume:
+		if spec.NodeRules != nil {
+			return fmt.Errorf("node rules cannot be set for resource type %v", spec.ResourceType)
+		}
+		return validateMaintenancePolicyVolumeRules(spec.VolumeRules)
+	case longhorn.MaintenancePolicyResourceTypeNode:
+		if spec.VolumeRules != nil {
+			return fmt.Errorf("volume rules cannot be set for resource type %v", spec.ResourceType)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown resource type %v", spec.ResourceType)
+	}
+}
+
+func validateMaintenancePolicyVolumeRules(rules *longhorn.MaintenancePolicyVolumeRules) error {
+	if rules == nil {
+		return nil
+	}
+	if rules.NumberOfReplicas != nil {
+		if err := types.ValidateReplicaCount(*rules.NumberOfReplicas); err != nil {
+			return err
+		}
+	}
+	if rules.DataLocality != nil {
+		if err := types.ValidateDataLocality(*rules.DataLocality); err != nil {
+			return err
+		}
+		if rules.NumberOfReplicas != nil {
+			if err := types.ValidateDataLocalityAndReplicaCount(*rules.DataLocality, *rules.NumberOfReplicas); err != nil {
+				return err
+			}
+		}
+	}
+	if rules.SnapshotMaxCount != nil && (*rules.SnapshotMaxCount < 2 || *rules.SnapshotMaxCount > 250) {
+		return fmt.Errorf("snapshot max count should be between 2 to 250")
+	}
+	if rules.UnmapMarkSnapChainRemoved != nil {
+		switch *rules.UnmapMarkSnapChainRemoved {
+		case longhorn.UnmapMarkSnapChainRemovedIgnored,
+			longhorn.UnmapMarkSnapChainRemovedDisabled,
+			longhorn.UnmapMarkSnapChainRemovedEnabled:
+		default:
+			return fmt.Errorf("invalid unmap mark snapshot chain removed option: %v", *rules.UnmapMarkSnapChainRemoved)
+		}
+	}
+	return nil
+}
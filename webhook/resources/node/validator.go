@@ -54,6 +54,10 @@ func (n *nodeValidator) Create(request *admission.Request, newObj runtime.Object
 		return werror.NewInvalidError("instanceManagerCPURequest should be greater than or equal to 0", "")
 	}
 
+	if node.Spec.InstanceManagerMemoryRequest < 0 {
+		return werror.NewInvalidError("instanceManagerMemoryRequest should be greater than or equal to 0", "")
+	}
+
 	v2DataEngineEnabled, err := n.ds.GetSettingAsBool(types.SettingNameV2DataEngine)
 	if err != nil {
 		err = errors.Wrapf(err, "failed to get spdk setting")
@@ -61,6 +65,10 @@ func (n *nodeValidator) Create(request *admission.Request, newObj runtime.Object
 	}
 
 	for name, disk := range node.Spec.Disks {
+		if disk.ConcurrentReplicaRebuildLimit < 0 {
+			return werror.NewInvalidError(fmt.Sprintf("disk %v concurrentReplicaRebuildLimit should be greater than or equal to 0", name), "")
+		}
+
 		if !v2DataEngineEnabled {
 			if disk.Type == longhorn.DiskTypeBlock {
 				return werror.NewInvalidError(fmt.Sprintf("disk %v type %v is not supported since v2 data engine is disabled", name, disk.Type), "")
@@ -100,6 +108,10 @@ func (n *nodeValidator) Update(request *admission.Request, oldObj runtime.Object
 		return werror.NewInvalidError("instanceManagerCPURequest should be greater than or equal to 0", "")
 	}
 
+	if newNode.Spec.InstanceManagerMemoryRequest < 0 {
+		return werror.NewInvalidError("instanceManagerMemoryRequest should be greater than or equal to 0", "")
+	}
+
 	// Only scheduling disabled node can be evicted
 	// Can not enable scheduling on an evicting node
 	if newNode.Spec.EvictionRequested && newNode.Spec.AllowScheduling {
@@ -157,12 +169,16 @@ func (n *nodeValidator) Update(request *admission.Request, oldObj runtime.Object
 		return werror.NewInvalidError(err.Error(), "")
 	}
 
-	// Validate Disks StorageReserved, Tags and Type
+	// Validate Disks StorageReserved, ConcurrentReplicaRebuildLimit, Tags and Type
 	for name, disk := range newNode.Spec.Disks {
 		if disk.StorageReserved < 0 {
 			return werror.NewInvalidError(fmt.Sprintf("update disk on node %v error: The storageReserved setting of disk %v(%v) is not valid, should be positive and no more than storageMaximum and storageAvailable",
 				newNode.Name, name, disk.Path), "")
 		}
+		if disk.ConcurrentReplicaRebuildLimit < 0 {
+			return werror.NewInvalidError(fmt.Sprintf("update disk on node %v error: The concurrentReplicaRebuildLimit of disk %v(%v) should be greater than or equal to 0",
+				newNode.Name, name, disk.Path), "")
+		}
 		_, err := util.ValidateTags(disk.Tags)
 		if err != nil {
 			return werror.NewInvalidError(err.Error(), "")
@@ -56,8 +56,11 @@ func (o *orphanMutator) Create(request *admission.Request, newObj runtime.Object
 
 	// Add labels according to the orphan type
 	var longhornLabels map[string]string
-	if orphan.Spec.Type == longhorn.OrphanTypeReplica {
-		longhornLabels = types.GetOrphanLabelsForOrphanedDirectory(orphan.Spec.NodeID, orphan.Spec.Parameters[longhorn.OrphanDiskUUID])
+	switch orphan.Spec.Type {
+	case longhorn.OrphanTypeReplica, longhorn.OrphanTypeBackingImage:
+		longhornLabels = types.GetOrphanLabelsForOrphanedDirectory(orphan.Spec.NodeID, orphan.Spec.Parameters[longhorn.OrphanDiskUUID], orphan.Spec.Type)
+	case longhorn.OrphanTypeEngineInstance, longhorn.OrphanTypeReplicaInstance:
+		longhornLabels = types.GetOrphanLabelsForOrphanedInstance(orphan.Spec.NodeID, orphan.Spec.Parameters[longhorn.OrphanInstanceManager], orphan.Spec.Type)
 	}
 	if longhornLabels == nil {
 		return nil, werror.NewInvalidError("invalid orphan labels", "")
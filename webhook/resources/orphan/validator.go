@@ -45,8 +45,10 @@ func (o *orphanValidator) Create(request *admission.Request, newObj runtime.Obje
 
 	var err error
 	switch orphan.Spec.Type {
-	case longhorn.OrphanTypeReplica:
-		err = checkOrphanForReplicaDataStore(orphan)
+	case longhorn.OrphanTypeReplica, longhorn.OrphanTypeBackingImage:
+		err = checkOrphanForDiskData(orphan)
+	case longhorn.OrphanTypeEngineInstance, longhorn.OrphanTypeReplicaInstance:
+		err = checkOrphanForInstance(orphan)
 	default:
 		return werror.NewInvalidError(fmt.Sprintf("unknown orphan type %v for orphan %v", orphan.Spec.Type, orphan.Name), "")
 	}
@@ -71,14 +73,17 @@ func (o *orphanValidator) Update(request *admission.Request, oldObj runtime.Obje
 }
 
 func checkOrphanParameters(orphan *longhorn.Orphan) error {
-	if orphan.Spec.Type == longhorn.OrphanTypeReplica {
-		return checkOrphanForReplicaDataStore(orphan)
+	switch orphan.Spec.Type {
+	case longhorn.OrphanTypeReplica, longhorn.OrphanTypeBackingImage:
+		return checkOrphanForDiskData(orphan)
+	case longhorn.OrphanTypeEngineInstance, longhorn.OrphanTypeReplicaInstance:
+		return checkOrphanForInstance(orphan)
 	}
 
 	return werror.NewInvalidError(fmt.Sprintf("unknown orphan type %v for orphan %v", orphan.Spec.Type, orphan.Name), "")
 }
 
-func checkOrphanForReplicaDataStore(orphan *longhorn.Orphan) error {
+func checkOrphanForDiskData(orphan *longhorn.Orphan) error {
 	params := []string{
 		longhorn.OrphanDataName,
 		longhorn.OrphanDiskName,
@@ -96,3 +101,20 @@ func checkOrphanForReplicaDataStore(orphan *longhorn.Orphan) error {
 
 	return nil
 }
+
+func checkOrphanForInstance(orphan *longhorn.Orphan) error {
+	params := []string{
+		longhorn.OrphanInstanceName,
+		longhorn.OrphanInstanceManager,
+		longhorn.OrphanDataEngine,
+	}
+
+	for _, param := range params {
+		_, ok := orphan.Spec.Parameters[param]
+		if !ok {
+			return fmt.Errorf("parameter %v for orphan %v is missing", param, orphan.Name)
+		}
+	}
+
+	return nil
+}
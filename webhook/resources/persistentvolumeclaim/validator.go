@@ -2,6 +2,8 @@ package persistentvolumeclaim
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -34,11 +36,21 @@ func (v *pvcValidator) Resource() admission.Resource {
 		APIVersion: corev1.SchemeGroupVersion.Version,
 		ObjectType: &corev1.PersistentVolumeClaim{},
 		OperationTypes: []admissionregv1.OperationType{
+			admissionregv1.Create,
 			admissionregv1.Update,
 		},
 	}
 }
 
+func (v *pvcValidator) Create(request *admission.Request, newObj runtime.Object) error {
+	pvc, ok := newObj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		return werror.NewInvalidError(fmt.Sprintf("invalid object: expected *corev1.PersistentVolumeClaim, got %T", newObj), "")
+	}
+
+	return validateVolumeParameterOverrides(pvc)
+}
+
 func (v *pvcValidator) Update(request *admission.Request, oldObj runtime.Object, newObj runtime.Object) error {
 	oldPVC, ok := oldObj.(*corev1.PersistentVolumeClaim)
 	if !ok {
@@ -50,6 +62,10 @@ func (v *pvcValidator) Update(request *admission.Request, oldObj runtime.Object,
 		return werror.NewInvalidError(fmt.Sprintf("invalid new object: expected *corev1.PersistentVolumeClaim, got %T", newObj), "")
 	}
 
+	if err := validateVolumeParameterOverridesUpdate(oldPVC, newPVC); err != nil {
+		return err
+	}
+
 	// Handle only PVC size expansion.
 	oldSize := oldPVC.Spec.Resources.Requests[corev1.ResourceStorage]
 	newSize := newPVC.Spec.Resources.Requests[corev1.ResourceStorage]
@@ -76,6 +92,38 @@ func (v *pvcValidator) Update(request *admission.Request, oldObj runtime.Object,
 	return v.validateExpansionSize(oldPVC, newPVC, volume)
 }
 
+func validateVolumeParameterOverrides(pvc *corev1.PersistentVolumeClaim) error {
+	if _, err := types.GetPVCVolumeParameterOverrides(pvc.Annotations); err != nil {
+		return werror.NewInvalidError(err.Error(), "metadata.annotations")
+	}
+	return nil
+}
+
+// validateVolumeParameterOverridesUpdate validates the parameter annotations only if they are changed, so that the
+// PVCs created before the validation can still be updated. The annotations cannot be changed once the PVC is bound,
+// since they are only consumed when the volume is provisioned.
+func validateVolumeParameterOverridesUpdate(oldPVC *corev1.PersistentVolumeClaim, newPVC *corev1.PersistentVolumeClaim) error {
+	if reflect.DeepEqual(getVolumeParameterAnnotations(oldPVC), getVolumeParameterAnnotations(newPVC)) {
+		return nil
+	}
+
+	if oldPVC.Spec.VolumeName != "" {
+		return werror.NewForbiddenError(fmt.Sprintf("cannot change the annotations with prefix %v after PVC %v is bound to volume %v",
+			types.PVCVolumeParameterAnnotationPrefix, newPVC.Name, oldPVC.Spec.VolumeName))
+	}
+	return validateVolumeParameterOverrides(newPVC)
+}
+
+func getVolumeParameterAnnotations(pvc *corev1.PersistentVolumeClaim) map[string]string {
+	annotations := map[string]string{}
+	for key, value := range pvc.Annotations {
+		if strings.HasPrefix(key, types.PVCVolumeParameterAnnotationPrefix) {
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
+
 func (v *pvcValidator) validateExpansionSize(oldPVC *corev1.PersistentVolumeClaim, newPVC *corev1.PersistentVolumeClaim, volume *longhorn.Volume) error {
 	oldSize := oldPVC.Spec.Resources.Requests[corev1.ResourceStorage]
 	oldSizeInt64, ok := oldSize.AsInt64()
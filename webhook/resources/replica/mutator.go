@@ -41,7 +41,22 @@ func (e *replicaMutator) Resource() admission.Resource {
 }
 
 func (e *replicaMutator) Create(request *admission.Request, newObj runtime.Object) (admission.PatchOps, error) {
-	return mutate(newObj)
+	replica, ok := newObj.(*longhorn.Replica)
+	if !ok {
+		return nil, werror.NewInvalidError(fmt.Sprintf("%v is not a *longhorn.Replica", newObj), "")
+	}
+
+	// The fields of a replica created directly are filled from its volume, the same as the volume controller does
+	patchOps, err := common.GetInstanceSpecDefaultPatchOps(e.ds, &replica.Spec.InstanceSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	patchOpsInCommon, err := mutate(newObj)
+	if err != nil {
+		return nil, err
+	}
+	return append(patchOps, patchOpsInCommon...), nil
 }
 
 func (e *replicaMutator) Update(request *admission.Request, oldObj runtime.Object, newObj runtime.Object) (admission.PatchOps, error) {
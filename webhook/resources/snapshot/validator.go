@@ -53,6 +53,12 @@ func (o *snapshotValidator) Create(request *admission.Request, newObj runtime.Ob
 		return werror.NewInvalidError("spec.volume is required", "spec.volume")
 	}
 
+	if snapshot.Spec.CreateSnapshot {
+		if err := o.ds.CheckVolumeSnapshotQuota(snapshot.Spec.Volume); err != nil && !datastore.ErrorIsNotFound(err) {
+			return werror.NewInvalidError(err.Error(), "")
+		}
+	}
+
 	return nil
 }
 
@@ -136,6 +136,11 @@ func (v *volumeMutator) Create(request *admission.Request, newObj runtime.Object
 		patchOps = append(patchOps, fmt.Sprintf(`{"op": "replace", "path": "/spec/accessMode", "value": "%s"}`, string(accessModeFromBackup)))
 	}
 
+	// The API and the CSI plugin default the frontend of the volumes except the standby ones
+	if volume.Spec.Frontend == "" && !volume.Spec.Standby {
+		patchOps = append(patchOps, fmt.Sprintf(`{"op": "replace", "path": "/spec/frontend", "value": "%s"}`, longhorn.VolumeFrontendBlockDev))
+	}
+
 	moreLabels := map[string]string{}
 	size := volume.Spec.Size
 	backupTargetName := volume.Spec.BackupTargetName
@@ -388,6 +393,9 @@ func mutate(newObj runtime.Object, moreLabels map[string]string) (admission.Patc
 	if string(volume.Spec.FreezeFilesystemForSnapshot) == "" {
 		patchOps = append(patchOps, fmt.Sprintf(`{"op": "replace", "path": "/spec/freezeFilesystemForSnapshot", "value": "%s"}`, longhorn.FreezeFilesystemForSnapshotDefault))
 	}
+	if string(volume.Spec.CloneMode) == "" {
+		patchOps = append(patchOps, fmt.Sprintf(`{"op": "replace", "path": "/spec/cloneMode", "value": "%s"}`, longhorn.VolumeCloneModeFullCopy))
+	}
 
 	labels := volume.Labels
 	if labels == nil {
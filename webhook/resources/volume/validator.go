@@ -44,6 +44,7 @@ func (v *volumeValidator) Resource() admission.Resource {
 		OperationTypes: []admissionregv1.OperationType{
 			admissionregv1.Create,
 			admissionregv1.Update,
+			admissionregv1.Delete,
 		},
 	}
 }
@@ -58,7 +59,7 @@ func (v *volumeValidator) Create(request *admission.Request, newObj runtime.Obje
 		return werror.NewInvalidError(fmt.Sprintf("invalid name %v", volume.Name), "")
 	}
 
-	if err := types.ValidateDataLocality(volume.Spec.DataLocality); err != nil {
+	if err := types.ValidateVolumeSpecParameters(&volume.Spec); err != nil {
 		return werror.NewInvalidError(err.Error(), "")
 	}
 
@@ -78,26 +79,6 @@ func (v *volumeValidator) Create(request *admission.Request, newObj runtime.Obje
 		return werror.NewInvalidError(err.Error(), "")
 	}
 
-	if err := types.ValidateReplicaAutoBalance(volume.Spec.ReplicaAutoBalance); err != nil {
-		return werror.NewInvalidError(err.Error(), "")
-	}
-
-	if err := types.ValidateUnmapMarkSnapChainRemoved(volume.Spec.DataEngine, volume.Spec.UnmapMarkSnapChainRemoved); err != nil {
-		return werror.NewInvalidError(err.Error(), "")
-	}
-
-	if err := types.ValidateReplicaSoftAntiAffinity(volume.Spec.ReplicaSoftAntiAffinity); err != nil {
-		return werror.NewInvalidError(err.Error(), "")
-	}
-
-	if err := types.ValidateReplicaZoneSoftAntiAffinity(volume.Spec.ReplicaZoneSoftAntiAffinity); err != nil {
-		return werror.NewInvalidError(err.Error(), "")
-	}
-
-	if err := types.ValidateReplicaDiskSoftAntiAffinity(volume.Spec.ReplicaDiskSoftAntiAffinity); err != nil {
-		return werror.NewInvalidError(err.Error(), "")
-	}
-
 	if volume.Spec.BackingImage != "" {
 		backingImage, err := v.ds.GetBackingImage(volume.Spec.BackingImage)
 		if err != nil {
@@ -121,11 +102,8 @@ func (v *volumeValidator) Create(request *admission.Request, newObj runtime.Obje
 	}
 
 	if !volume.Spec.Standby {
-		if volume.Spec.Frontend != longhorn.VolumeFrontendBlockDev &&
-			volume.Spec.Frontend != longhorn.VolumeFrontendISCSI &&
-			volume.Spec.Frontend != longhorn.VolumeFrontendNvmf &&
-			volume.Spec.Frontend != longhorn.VolumeFrontendUblk {
-			return werror.NewInvalidError(fmt.Sprintf("invalid volume frontend specified: %v", volume.Spec.Frontend), "")
+		if err := types.ValidateVolumeFrontend(volume.Spec.Frontend); err != nil {
+			return werror.NewInvalidError(err.Error(), "")
 		}
 	}
 
@@ -133,6 +111,10 @@ func (v *volumeValidator) Create(request *admission.Request, newObj runtime.Obje
 		return werror.NewInvalidError("migratable volumes are only supported in ReadWriteMany (rwx) access mode", "")
 	}
 
+	if err := validateRWXBlockVolume(volume); err != nil {
+		return werror.NewInvalidError(err.Error(), "")
+	}
+
 	// Check engine version before disable revision counter
 	if volume.Spec.RevisionCounterDisabled {
 		if ok, err := v.canDisableRevisionCounter(volume.Spec.Image, volume.Spec.DataEngine); !ok {
@@ -154,6 +136,34 @@ func (v *volumeValidator) Create(request *admission.Request, newObj runtime.Obje
 		return werror.NewInvalidError(err.Error(), "spec.snapshotMaxCount")
 	}
 
+	if err := types.ValidateSnapshotMaxChainLength(volume.Spec.SnapshotMaxChainLength); err != nil {
+		return werror.NewInvalidError(err.Error(), "spec.snapshotMaxChainLength")
+	}
+
+	if err := types.ValidateVolumeAutoResizePolicy(volume.Spec.AutoResize); err != nil {
+		return werror.NewInvalidError(err.Error(), "spec.autoResize")
+	}
+	if volume.Spec.AutoResize != nil && volume.Spec.AccessMode == longhorn.AccessModeReadWriteMany {
+		err := fmt.Errorf("auto resize for volume %v is not supported for access mode %v", volume.Name, volume.Spec.AccessMode)
+		return werror.NewInvalidError(err.Error(), "spec.autoResize")
+	}
+
+	if err := types.ValidateBackupMaxAge(volume.Spec.BackupMaxAge); err != nil {
+		return werror.NewInvalidError(err.Error(), "spec.backupMaxAge")
+	}
+
+	if err := types.ValidateSnapshotRetentionKeepLast(volume.Spec.SnapshotRetentionKeepLast); err != nil {
+		return werror.NewInvalidError(err.Error(), "spec.snapshotRetentionKeepLast")
+	}
+
+	if err := types.ValidateSnapshotRetentionMaxAge(volume.Spec.SnapshotRetentionMaxAge); err != nil {
+		return werror.NewInvalidError(err.Error(), "spec.snapshotRetentionMaxAge")
+	}
+
+	if err := types.ValidateSnapshotRetentionMaxSize(volume.Spec.SnapshotRetentionMaxSize); err != nil {
+		return werror.NewInvalidError(err.Error(), "spec.snapshotRetentionMaxSize")
+	}
+
 	if err := validateSnapshotMaxSize(volume.Spec.Size, volume.Spec.SnapshotMaxSize); err != nil {
 		return werror.NewInvalidError(err.Error(), "spec.snapshotMaxSize")
 	}
@@ -202,23 +212,20 @@ func (v *volumeValidator) Update(request *admission.Request, oldObj runtime.Obje
 		return werror.NewInvalidError(err.Error(), "")
 	}
 
-	if err := types.ValidateReplicaAutoBalance(newVolume.Spec.ReplicaAutoBalance); err != nil {
-		return werror.NewInvalidError(err.Error(), "")
-	}
-
-	if err := types.ValidateUnmapMarkSnapChainRemoved(newVolume.Spec.DataEngine, newVolume.Spec.UnmapMarkSnapChainRemoved); err != nil {
-		return werror.NewInvalidError(err.Error(), "")
-	}
-
-	if err := types.ValidateReplicaSoftAntiAffinity(newVolume.Spec.ReplicaSoftAntiAffinity); err != nil {
+	// A ReadWriteMany iSCSI volume is exported as a raw block device instead of being shared by a share manager, so
+	// the access mode or frontend change must not silently switch the volume between the two
+	if util.IsRWXBlockVolume(oldVolume) != util.IsRWXBlockVolume(newVolume) {
+		err := fmt.Errorf("changing volume %v between a ReadWriteMany block volume exported over %v and a volume without it is not supported",
+			newVolume.Name, longhorn.VolumeFrontendISCSI)
 		return werror.NewInvalidError(err.Error(), "")
 	}
 
-	if err := types.ValidateReplicaZoneSoftAntiAffinity(newVolume.Spec.ReplicaZoneSoftAntiAffinity); err != nil {
+	if err := types.ValidateVolumeSpecParameters(&newVolume.Spec); err != nil {
 		return werror.NewInvalidError(err.Error(), "")
 	}
 
-	if err := types.ValidateReplicaDiskSoftAntiAffinity(newVolume.Spec.ReplicaDiskSoftAntiAffinity); err != nil {
+	if err := types.ValidateVolumeSpecParametersUpdate(&oldVolume.Spec, &newVolume.Spec); err != nil {
+		err = errors.Wrapf(err, "failed to update volume %v", oldVolume.Name)
 		return werror.NewInvalidError(err.Error(), "")
 	}
 
@@ -251,13 +258,6 @@ func (v *volumeValidator) Update(request *admission.Request, oldObj runtime.Obje
 		}
 	}
 
-	if oldVolume.Spec.DataEngine != "" {
-		if oldVolume.Spec.DataEngine != newVolume.Spec.DataEngine {
-			err := fmt.Errorf("changing data engine for volume %v is not supported", oldVolume.Name)
-			return werror.NewInvalidError(err.Error(), "")
-		}
-	}
-
 	if types.IsDataEngineV2(newVolume.Spec.DataEngine) {
 		// TODO: remove this check when we support the following features for SPDK volumes
 		if oldVolume.Spec.Size != newVolume.Spec.Size {
@@ -330,6 +330,48 @@ func (v *volumeValidator) Update(request *admission.Request, oldObj runtime.Obje
 		return werror.NewInvalidError(err.Error(), "spec.snapshotMaxCount")
 	}
 
+	if err := types.ValidateSnapshotMaxChainLength(newVolume.Spec.SnapshotMaxChainLength); err != nil {
+		return werror.NewInvalidError(err.Error(), "spec.snapshotMaxChainLength")
+	}
+
+	if err := types.ValidateVolumeAutoResizePolicy(newVolume.Spec.AutoResize); err != nil {
+		return werror.NewInvalidError(err.Error(), "spec.autoResize")
+	}
+	if newVolume.Spec.AutoResize != nil && newVolume.Spec.AccessMode == longhorn.AccessModeReadWriteMany {
+		err := fmt.Errorf("auto resize for volume %v is not supported for access mode %v", newVolume.Name, newVolume.Spec.AccessMode)
+		return werror.NewInvalidError(err.Error(), "spec.autoResize")
+	}
+
+	if err := types.ValidateBackupMaxAge(newVolume.Spec.BackupMaxAge); err != nil {
+		return werror.NewInvalidError(err.Error(), "spec.backupMaxAge")
+	}
+
+	if newVolume.Spec.IOPauseDeadline != "" && newVolume.Spec.IOPauseDeadline != oldVolume.Spec.IOPauseDeadline {
+		if !types.IsDataEngineV2(newVolume.Spec.DataEngine) {
+			err := fmt.Errorf("pausing IO for volume %v is not supported for data engine %v",
+				newVolume.Name, newVolume.Spec.DataEngine)
+			return werror.NewInvalidError(err.Error(), "spec.ioPauseDeadline")
+		}
+		if _, err := util.ParseTime(newVolume.Spec.IOPauseDeadline); err != nil {
+			return werror.NewInvalidError(fmt.Sprintf("invalid IO pause deadline: %v", err), "spec.ioPauseDeadline")
+		}
+		if oldVolume.Status.State != longhorn.VolumeStateAttached {
+			return werror.NewInvalidError(fmt.Sprintf("cannot pause IO of volume %v in state %v", newVolume.Name, oldVolume.Status.State), "spec.ioPauseDeadline")
+		}
+	}
+
+	if err := types.ValidateSnapshotRetentionKeepLast(newVolume.Spec.SnapshotRetentionKeepLast); err != nil {
+		return werror.NewInvalidError(err.Error(), "spec.snapshotRetentionKeepLast")
+	}
+
+	if err := types.ValidateSnapshotRetentionMaxAge(newVolume.Spec.SnapshotRetentionMaxAge); err != nil {
+		return werror.NewInvalidError(err.Error(), "spec.snapshotRetentionMaxAge")
+	}
+
+	if err := types.ValidateSnapshotRetentionMaxSize(newVolume.Spec.SnapshotRetentionMaxSize); err != nil {
+		return werror.NewInvalidError(err.Error(), "spec.snapshotRetentionMaxSize")
+	}
+
 	if err := validateSnapshotMaxSize(newVolume.Spec.Size, newVolume.Spec.SnapshotMaxSize); err != nil {
 		return werror.NewInvalidError(err.Error(), "spec.snapshotMaxSize")
 	}
@@ -347,6 +389,46 @@ func (v *volumeValidator) Update(request *admission.Request, oldObj runtime.Obje
 	return nil
 }
 
+func (v *volumeValidator) Delete(request *admission.Request, oldObj runtime.Object) error {
+	volume, ok := oldObj.(*longhorn.Volume)
+	if !ok {
+		return werror.NewInvalidError(fmt.Sprintf("%v is not a *longhorn.Volume", oldObj), "")
+	}
+
+	cloneTargets, err := v.getActiveCloneTargets(volume)
+	if err != nil {
+		return werror.NewInternalError(fmt.Sprintf("cannot delete volume %v since the error %v", volume.Name, err.Error()))
+	}
+	if len(cloneTargets) != 0 {
+		return werror.NewInvalidError(fmt.Sprintf("cannot delete volume %v since volumes %v are being cloned from it, "+
+			"wait for the cloning to complete or delete the cloned volumes first", volume.Name, cloneTargets), "")
+	}
+	return nil
+}
+
+// getActiveCloneTargets returns the volumes being cloned from the volume. The clone targets being deleted don't depend
+// on the volume anymore.
+func (v *volumeValidator) getActiveCloneTargets(volume *longhorn.Volume) ([]string, error) {
+	names, err := v.ds.ListActiveCloneTargetVolumeNamesRO(volume.Name)
+	if err != nil {
+		return nil, err
+	}
+	cloneTargets := []string{}
+	for _, name := range names {
+		target, err := v.ds.GetVolumeRO(name)
+		if err != nil {
+			if datastore.ErrorIsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if target.DeletionTimestamp == nil {
+			cloneTargets = append(cloneTargets, name)
+		}
+	}
+	return cloneTargets, nil
+}
+
 func (v *volumeValidator) validateExpansionSize(oldVolume *longhorn.Volume, newVolume *longhorn.Volume) error {
 	oldSize := oldVolume.Spec.Size
 	newSize := newVolume.Spec.Size
@@ -356,7 +438,6 @@ func (v *volumeValidator) validateExpansionSize(oldVolume *longhorn.Volume, newV
 	if newSize < oldSize && !newVolume.Status.ExpansionRequired {
 		return fmt.Errorf("shrinking volume %v size from %v to %v is not supported", newVolume.Name, oldSize, newSize)
 	}
-
 	replicas, err := v.ds.ListVolumeReplicasRO(newVolume.Name)
 	if err != nil {
 		return err
@@ -557,3 +638,19 @@ func (v *volumeValidator) validateUpdatingSnapshotMaxCountAndSize(oldVolume, new
 	}
 	return nil
 }
+
+// validateRWXBlockVolume rejects the ReadWriteMany volumes with the iSCSI frontend which cannot be exported to the
+// nodes as a raw block device. A non-migratable ReadWriteMany volume is always exported that way with the iSCSI
+// frontend, so a misconfigured one would otherwise neither be exported nor shared by a share manager.
+func validateRWXBlockVolume(volume *longhorn.Volume) error {
+	if !util.IsRWXBlockVolume(volume) {
+		return nil
+	}
+	if volume.Spec.DataEngine != longhorn.DataEngineTypeV1 {
+		return fmt.Errorf("ReadWriteMany block volumes are not supported for data engine %v", volume.Spec.DataEngine)
+	}
+	if volume.Spec.Encrypted {
+		return fmt.Errorf("ReadWriteMany block volumes cannot be encrypted")
+	}
+	return nil
+}
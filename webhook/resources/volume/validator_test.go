@@ -0,0 +1,91 @@
+package volume
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/controller"
+
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/longhorn/longhorn-manager/datastore"
+	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
+
+	longhorn "github.com/longhorn/longhorn-manager/k8s/pkg/apis/longhorn/v1beta2"
+	lhfake "github.com/longhorn/longhorn-manager/k8s/pkg/client/clientset/versioned/fake"
+)
+
+const (
+	testNamespace  = "longhorn-system"
+	testVolumeName = "test-volume"
+)
+
+func newTestVolume(name string) *longhorn.Volume {
+	return &longhorn.Volume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+		},
+	}
+}
+
+func newTestCloneTargetVolume(name string, state longhorn.VolumeCloneState, deleting bool) *longhorn.Volume {
+	v := newTestVolume(name)
+	v.Spec.DataSource = types.NewVolumeDataSourceTypeVolume(testVolumeName)
+	v.Status.CloneStatus.State = state
+	if deleting {
+		now := metav1.Now()
+		v.DeletionTimestamp = &now
+	}
+	return v
+}
+
+func TestDelete(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := map[string]struct {
+		targets []*longhorn.Volume
+		wantErr bool
+	}{
+		"noClone": {},
+		"activeClone": {
+			targets: []*longhorn.Volume{newTestCloneTargetVolume("target", longhorn.VolumeCloneStateInitiated, false)},
+			wantErr: true,
+		},
+		"completedClone": {
+			targets: []*longhorn.Volume{newTestCloneTargetVolume("target", longhorn.VolumeCloneStateCompleted, false)},
+		},
+		"failedClone": {
+			targets: []*longhorn.Volume{newTestCloneTargetVolume("target", longhorn.VolumeCloneStateFailed, false)},
+		},
+		"cloneTargetBeingDeleted": {
+			targets: []*longhorn.Volume{newTestCloneTargetVolume("target", longhorn.VolumeCloneStateInitiated, true)},
+		},
+	}
+
+	for name, tc := range tests {
+		kubeClient := fake.NewSimpleClientset()
+		lhClient := lhfake.NewSimpleClientset()
+		extensionsClient := apiextensionsfake.NewSimpleClientset()
+		informerFactories := util.NewInformerFactories(testNamespace, kubeClient, lhClient, controller.NoResyncPeriodFunc())
+		ds := datastore.NewDataStore(testNamespace, lhClient, kubeClient, extensionsClient, informerFactories)
+
+		volumeIndexer := informerFactories.LhInformerFactory.Longhorn().V1beta2().Volumes().Informer().GetIndexer()
+		source := newTestVolume(testVolumeName)
+		assert.NoError(volumeIndexer.Add(source), name)
+		for _, target := range tc.targets {
+			assert.NoError(volumeIndexer.Add(target), name)
+		}
+
+		err := NewValidator(ds, "").Delete(nil, source)
+		if tc.wantErr {
+			assert.Error(err, name)
+		} else {
+			assert.NoError(err, name)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -17,6 +18,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/longhorn/longhorn-manager/types"
+	"github.com/longhorn/longhorn-manager/util"
 	"github.com/longhorn/longhorn-manager/util/client"
 	"github.com/longhorn/longhorn-manager/webhook/admission"
 )
@@ -177,7 +179,8 @@ func (s *WebhookServer) runAdmissionWebhookListenAndServe(handler http.Handler,
 			SANs: []string{
 				tlsName,
 			},
-			FilterCN: dynamiclistener.OnlyAllow(tlsName),
+			FilterCN:  dynamiclistener.OnlyAllow(tlsName),
+			TLSConfig: s.getTLSConfig(),
 		},
 	})
 }
@@ -236,11 +239,26 @@ func (s *WebhookServer) runConversionWebhookListenAndServe(handler http.Handler,
 			SANs: []string{
 				tlsName,
 			},
-			FilterCN: dynamiclistener.OnlyAllow(tlsName),
+			FilterCN:  dynamiclistener.OnlyAllow(tlsName),
+			TLSConfig: s.getTLSConfig(),
 		},
 	})
 }
 
+// getTLSConfig returns the FIPS TLS config if FIPS mode is enabled, or nil for the default TLS config
+func (s *WebhookServer) getTLSConfig() *tls.Config {
+	fipsModeEnabled, err := s.clients.Datastore.GetSettingAsBool(types.SettingNameFIPSModeEnabled)
+	if err != nil {
+		logrus.WithError(err).Warnf("Failed to get %v setting, use the default TLS config", types.SettingNameFIPSModeEnabled)
+		return nil
+	}
+	if !fipsModeEnabled {
+		return nil
+	}
+	logrus.Infof("Using the FIPS TLS config for the %v webhook server", s.webhookType)
+	return util.GetFIPSTLSConfig()
+}
+
 func (s *WebhookServer) buildRules(resources []admission.Resource) []admissionregv1.RuleWithOperations {
 	rules := []admissionregv1.RuleWithOperations{}
 	for _, rsc := range resources {